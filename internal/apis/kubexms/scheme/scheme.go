@@ -0,0 +1,56 @@
+// Package scheme decodes cluster configurations of every supported apiVersion into the v1alpha1
+// Cluster the rest of kubexm works with, so that the configuration API can evolve without
+// breaking existing files.
+package scheme
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha2"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// UnmarshalFunc decodes data into v, such as yaml.Unmarshal or json.Unmarshal.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// SupportedVersions returns the apiVersions Decode accepts, oldest first.
+func SupportedVersions() []string {
+	return []string{common.DefaultAPIVersion, v1alpha2.APIVersion}
+}
+
+// APIVersion returns the apiVersion of the configuration data, empty if it has none.
+func APIVersion(data []byte, unmarshal UnmarshalFunc) (string, error) {
+	var meta struct {
+		APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	}
+	if err := unmarshal(data, &meta); err != nil {
+		return "", err
+	}
+	return meta.APIVersion, nil
+}
+
+// Decode decodes the configuration data according to its apiVersion and converts it to v1alpha1.
+// Data of any other apiVersion, or of none, is decoded as v1alpha1 so that validation reports it.
+func Decode(data []byte, unmarshal UnmarshalFunc) (*v1alpha1.Cluster, error) {
+	version, err := APIVersion(data, unmarshal)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.Cluster{}
+	switch version {
+	case v1alpha2.APIVersion:
+		in := &v1alpha2.Cluster{}
+		if err := unmarshal(data, in); err != nil {
+			return nil, err
+		}
+		if err := v1alpha2.Convert_v1alpha2_Cluster_To_v1alpha1_Cluster(in, out); err != nil {
+			return nil, fmt.Errorf("failed to convert %s configuration: %w", version, err)
+		}
+	default:
+		if err := unmarshal(data, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
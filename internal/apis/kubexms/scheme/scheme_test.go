@@ -0,0 +1,90 @@
+package scheme
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+const v1alpha2ClusterYAML = `apiVersion: kubexm.io/v1alpha2
+kind: Cluster
+metadata:
+  name: prod
+spec:
+  hosts:
+    - name: master-1
+      address: 10.0.0.11
+  network:
+    plugin: calico
+    podCIDR: 10.244.0.0/16
+    serviceCIDR: 10.96.0.0/12
+  controlPlaneEndpoint:
+    address: 10.0.0.100
+    port: 6443
+    loadBalancer:
+      mode: internal
+      provider: haproxy
+  addons:
+    ingress-nginx:
+      dependsOn: [metallb]
+    metallb: {}
+`
+
+func TestDecode_v1alpha2(t *testing.T) {
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(v1alpha2ClusterYAML), &doc); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, input := range map[string]struct {
+		data      []byte
+		unmarshal UnmarshalFunc
+	}{
+		"yaml": {[]byte(v1alpha2ClusterYAML), yaml.Unmarshal},
+		"json": {jsonData, json.Unmarshal},
+	} {
+		cluster, err := Decode(input.data, input.unmarshal)
+		if err != nil {
+			t.Fatalf("%s: Decode() error = %v", name, err)
+		}
+		if cluster.APIVersion != common.DefaultAPIVersion || cluster.Name != "prod" {
+			t.Errorf("%s: apiVersion = %s, name = %s", name, cluster.APIVersion, cluster.Name)
+		}
+		if cluster.Spec.Network.KubePodsCIDR != "10.244.0.0/16" {
+			t.Errorf("%s: kubePodsCIDR = %s", name, cluster.Spec.Network.KubePodsCIDR)
+		}
+		if got := cluster.Spec.ControlPlaneEndpoint.InternalLoadBalancerType; got != common.InternalLBTypeHAProxy {
+			t.Errorf("%s: internalLoadbalancer = %s, want haproxy", name, got)
+		}
+		if addons := cluster.Spec.Addons; len(addons) != 2 || addons[0].Name != "metallb" || addons[1].Name != "ingress-nginx" {
+			t.Errorf("%s: addons = %+v, want metallb before ingress-nginx", name, addons)
+		}
+	}
+}
+
+func TestDecode_v1alpha1(t *testing.T) {
+	data := []byte(`{"apiVersion": "kubexm.io/v1alpha1", "kind": "Cluster", "spec": {"network": {"kubePodsCIDR": "10.244.0.0/16"}}}`)
+	cluster, err := Decode(data, json.Unmarshal)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if cluster.APIVersion != common.DefaultAPIVersion || cluster.Spec.Network.KubePodsCIDR != "10.244.0.0/16" {
+		t.Errorf("v1alpha1 configuration not decoded as written: %+v", cluster)
+	}
+
+	if _, err := Decode([]byte(`apiVersion: kubexm.io/v1alpha2
+spec:
+  controlPlaneEndpoint:
+    loadBalancer:
+      provider: kube-vip
+`), yaml.Unmarshal); err == nil {
+		t.Error("expected a conversion error for kube-vip without a mode")
+	}
+}
@@ -3,8 +3,10 @@ package v1alpha1
 import (
 	"fmt"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -17,6 +19,19 @@ type Addon struct {
 	PreInstall     []string      `json:"preInstall,omitempty" yaml:"preInstall,omitempty"`
 	PostInstall    []string      `json:"postInstall,omitempty" yaml:"postInstall,omitempty"`
 	TimeoutSeconds *int32        `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+	// Values are key=value chart values applied after those of every chart source, so built-in
+	// addons can be tuned without redefining their source.
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+	// Wait is checked once all sources of the addon are installed.
+	Wait *AddonWait `json:"wait,omitempty" yaml:"wait,omitempty"`
+}
+
+// AddonWait lists the workloads, as kind/name, that must meet Condition before the addon counts as
+// installed. Without a condition kubexm waits for their rollout.
+type AddonWait struct {
+	Namespace string   `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Condition string   `json:"condition,omitempty" yaml:"condition,omitempty"`
 }
 
 type AddonSource struct {
@@ -33,11 +48,15 @@ type ChartSource struct {
 	ValuesFile string   `json:"valuesFile,omitempty" yaml:"valuesFile,omitempty"`
 	Values     []string `json:"values,omitempty" yaml:"values,omitempty"`
 	Wait       *bool    `json:"wait,omitempty" yaml:"wait,omitempty"`
+	// ValuesFiles are further local values files, applied in order after ValuesFile.
+	ValuesFiles []string `json:"valuesFiles,omitempty" yaml:"valuesFiles,omitempty"`
 }
 
 type YamlSource struct {
 	Version string   `json:"version,omitempty" yaml:"version,omitempty"`
 	Path    []string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Directory is a local directory whose .yaml, .yml and .json manifests are applied in name order.
+	Directory string `json:"directory,omitempty" yaml:"directory,omitempty"`
 }
 
 func SetDefaults_Addon(cfg *Addon) {
@@ -55,7 +74,7 @@ func SetDefaults_Addon(cfg *Addon) {
 		cfg.Delay = helpers.Int32Ptr(5)
 	}
 	if cfg.TimeoutSeconds == nil {
-		cfg.TimeoutSeconds = helpers.Int32Ptr(300)
+		cfg.TimeoutSeconds = helpers.Int32Ptr(common.DefaultAddonTimeoutSeconds)
 	}
 
 	if cfg.Sources == nil {
@@ -100,6 +119,11 @@ func SetDefaults_AddonSource(cfg *AddonSource, addonName string, index int) {
 	}
 }
 
+// RegisteredAddons returns the addons that can be installed by name alone. The addon task package
+// replaces it with a lookup in its registry when it is initialized, so addons registered there are
+// accepted without sources; until then only common.SupportedAddons are known.
+var RegisteredAddons = func() []string { return common.SupportedAddons }
+
 func Validate_Addon(cfg *Addon, verrs *validation.ValidationErrors, pathPrefix string) {
 	if cfg == nil {
 		return
@@ -122,8 +146,24 @@ func Validate_Addon(cfg *Addon, verrs *validation.ValidationErrors, pathPrefix s
 		verrs.Add(p + ".timeoutSeconds: must be positive")
 	}
 
+	for i, val := range cfg.Values {
+		if !strings.Contains(val, "=") {
+			verrs.Add(fmt.Sprintf("%s.values[%d]: invalid format '%s', expected key=value", p, i, val))
+		}
+	}
+
+	if cfg.Wait != nil {
+		Validate_AddonWait(cfg.Wait, verrs, path.Join(p, "wait"))
+	}
+
+	// Supported addons come with default sources; any other addon must define its own.
 	sourcesPath := path.Join(p, "sources")
-	if cfg.Enabled != nil && *cfg.Enabled && len(cfg.Sources) == 0 {
+	registered := RegisteredAddons()
+	builtin := helpers.ContainsString(registered, cfg.Name)
+	if !builtin && len(cfg.Sources) == 0 && cfg.Name != "" {
+		verrs.Add(fmt.Sprintf("%s.name: unknown addon '%s' without sources, supported addons are: %s",
+			p, cfg.Name, strings.Join(registered, ", ")))
+	} else if !builtin && cfg.Enabled != nil && *cfg.Enabled && len(cfg.Sources) == 0 {
 		verrs.Add(sourcesPath + ": must contain at least one source when addon is enabled")
 	}
 
@@ -133,6 +173,37 @@ func Validate_Addon(cfg *Addon, verrs *validation.ValidationErrors, pathPrefix s
 	}
 }
 
+// validateNFSProvisionerAddon checks that the NFS export the provisioner addon mounts is configured in
+// spec.storage.nfs, and that the storage module is not installing the same provisioner.
+func validateNFSProvisionerAddon(storage *Storage, verrs *validation.ValidationErrors, pathPrefix string) {
+	if storage == nil || storage.NFS == nil || strings.TrimSpace(storage.NFS.Server) == "" || strings.TrimSpace(storage.NFS.Path) == "" {
+		verrs.Add(fmt.Sprintf("%s: addon '%s' requires spec.storage.nfs.server and spec.storage.nfs.path", pathPrefix, common.AddonNFSProvisioner))
+		return
+	}
+	if storage.NFS.Enabled != nil && *storage.NFS.Enabled {
+		verrs.Add(fmt.Sprintf("%s: addon '%s' conflicts with spec.storage.nfs.enabled, which installs the same provisioner",
+			pathPrefix, common.AddonNFSProvisioner))
+	}
+}
+
+func Validate_AddonWait(cfg *AddonWait, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg.Namespace != "" && !helpers.IsValidK8sName(cfg.Namespace) {
+		verrs.Add(fmt.Sprintf("%s.namespace: '%s' is not a valid Kubernetes namespace name", pathPrefix, cfg.Namespace))
+	}
+	if len(cfg.Resources) == 0 {
+		verrs.Add(pathPrefix + ".resources: must contain at least one resource to wait for")
+	}
+	for i, resource := range cfg.Resources {
+		kind, name, ok := strings.Cut(resource, "/")
+		if !ok || kind == "" || !helpers.IsValidK8sName(name) {
+			verrs.Add(fmt.Sprintf("%s.resources[%d]: invalid resource '%s', expected kind/name", pathPrefix, i, resource))
+		}
+	}
+	if strings.ContainsAny(cfg.Condition, " '\"") {
+		verrs.Add(fmt.Sprintf("%s.condition: invalid condition '%s'", pathPrefix, cfg.Condition))
+	}
+}
+
 func Validate_AddonSource(cfg *AddonSource, verrs *validation.ValidationErrors, pathPrefix string) {
 	if cfg == nil {
 		return
@@ -189,6 +260,12 @@ func Validate_ChartSource(cfg *ChartSource, verrs *validation.ValidationErrors,
 		verrs.Add(fmt.Sprintf("%s.repo: invalid URL format for '%s'", pathPrefix, cfg.Repo))
 	}
 
+	for i, file := range cfg.ValuesFiles {
+		if !filepath.IsAbs(file) {
+			verrs.Add(fmt.Sprintf("%s.valuesFiles[%d]: '%s' must be an absolute path", pathPrefix, i, file))
+		}
+	}
+
 	if cfg.Version != "" && !helpers.IsValidChartVersion(cfg.Version) {
 		verrs.Add(fmt.Sprintf("%s.version: invalid chart version format for '%s'", pathPrefix, cfg.Version))
 	}
@@ -205,8 +282,11 @@ func Validate_YamlSource(cfg *YamlSource, verrs *validation.ValidationErrors, pa
 		return
 	}
 
-	if len(cfg.Path) == 0 {
-		verrs.Add(pathPrefix + ".path: must contain at least one YAML path or URL")
+	if len(cfg.Path) == 0 && cfg.Directory == "" {
+		verrs.Add(pathPrefix + ".path: must contain at least one YAML path or URL, or a directory must be set")
+	}
+	if cfg.Directory != "" && !filepath.IsAbs(cfg.Directory) {
+		verrs.Add(fmt.Sprintf("%s.directory: '%s' must be an absolute path", pathPrefix, cfg.Directory))
 	}
 	for i, p := range cfg.Path {
 		if strings.TrimSpace(p) == "" {
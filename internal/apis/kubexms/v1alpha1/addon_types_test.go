@@ -0,0 +1,77 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestValidate_Addon_Names(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name          string
+		addon         Addon
+		expectedError string
+	}{
+		{name: "supported addon without sources", addon: Addon{Name: "metrics-server", Enabled: &enabled}},
+		{name: "custom addon with sources", addon: Addon{Name: "my-operator", Sources: []AddonSource{
+			{Namespace: "my-operator", Yaml: &YamlSource{Path: []string{"https://example.com/operator.yaml"}}},
+		}}},
+		{name: "unknown addon without sources", addon: Addon{Name: "metric-server"}, expectedError: "spec.addons[0].name: unknown addon 'metric-server'"},
+		{name: "cert-manager with values", addon: Addon{Name: "cert-manager", Values: []string{"replicaCount=2"}}},
+		{name: "custom addon from a manifest directory", addon: Addon{Name: "my-operator", Sources: []AddonSource{
+			{Namespace: "my-operator", Yaml: &YamlSource{Directory: "/opt/kubexm/manifests"}},
+		}, Wait: &AddonWait{Resources: []string{"deployment/my-operator"}, Condition: "Available"}}},
+		{name: "relative manifest directory", addon: Addon{Name: "my-operator", Sources: []AddonSource{
+			{Namespace: "my-operator", Yaml: &YamlSource{Directory: "manifests"}},
+		}}, expectedError: "directory: 'manifests' must be an absolute path"},
+		{name: "wait without kind", addon: Addon{Name: "cert-manager", Wait: &AddonWait{Resources: []string{"my-operator"}}}, expectedError: "expected kind/name"},
+		{name: "values without a key", addon: Addon{Name: "cert-manager", Values: []string{"replicaCount"}}, expectedError: "spec.addons[0].values[0]: invalid format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_Addon(&tt.addon, verrs, "spec.addons[0]")
+			if tt.expectedError == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestValidateNFSProvisionerAddon(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name          string
+		storage       *Storage
+		expectedError string
+	}{
+		{name: "export configured", storage: &Storage{NFS: &NFSConfig{Server: "10.0.0.50", Path: "/exports"}}},
+		{name: "no export", storage: &Storage{}, expectedError: "requires spec.storage.nfs.server"},
+		{name: "storage module enabled", storage: &Storage{NFS: &NFSConfig{Enabled: &enabled, Server: "10.0.0.50", Path: "/exports"}}, expectedError: "conflicts with spec.storage.nfs.enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			validateNFSProvisionerAddon(tt.storage, verrs, "spec.addons[0]")
+			if tt.expectedError == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, verrs.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// BinarySources selects where component binaries are downloaded from. Each list is tried in order
+// until a source serves the file with the expected checksum.
+type BinarySources struct {
+	// Default is used for every component without its own entry in Components.
+	Default []BinarySource `json:"default,omitempty" yaml:"default,omitempty"`
+	// Components overrides Default per component, keyed by component name such as "etcd" or "kubelet".
+	Components map[string][]BinarySource `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type BinarySource struct {
+	// Type is one of official, cn, aliyun, huawei, custom or local.
+	Type string `json:"type" yaml:"type"`
+	// BaseURL is the root URL of a custom or local source.
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+}
+
+func SetDefaults_BinarySources(cfg *BinarySources) {
+	if cfg == nil {
+		return
+	}
+	if len(cfg.Default) == 0 {
+		cfg.Default = []BinarySource{{Type: common.BinarySourceOfficial}}
+	}
+	for i := range cfg.Default {
+		SetDefaults_BinarySource(&cfg.Default[i])
+	}
+	for _, sources := range cfg.Components {
+		for i := range sources {
+			SetDefaults_BinarySource(&sources[i])
+		}
+	}
+}
+
+func SetDefaults_BinarySource(cfg *BinarySource) {
+	cfg.Type = strings.ToLower(strings.TrimSpace(cfg.Type))
+	cfg.BaseURL = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+}
+
+func Validate_BinarySources(cfg *BinarySources, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg == nil {
+		return
+	}
+	for i := range cfg.Default {
+		Validate_BinarySource(&cfg.Default[i], verrs, fmt.Sprintf("%s.default[%d]", pathPrefix, i))
+	}
+	for component, sources := range cfg.Components {
+		p := fmt.Sprintf("%s.components[%s]", pathPrefix, component)
+		if strings.TrimSpace(component) == "" {
+			verrs.Add(pathPrefix + ".components: component name cannot be empty")
+		}
+		if len(sources) == 0 {
+			verrs.Add(p + ": must list at least one source")
+		}
+		for i := range sources {
+			Validate_BinarySource(&sources[i], verrs, fmt.Sprintf("%s[%d]", p, i))
+		}
+	}
+}
+
+func Validate_BinarySource(cfg *BinarySource, verrs *validation.ValidationErrors, pathPrefix string) {
+	if !helpers.ContainsString(common.SupportedBinarySources, cfg.Type) {
+		verrs.Add(fmt.Sprintf("%s.type: unsupported source '%s', must be one of %v", pathPrefix, cfg.Type, common.SupportedBinarySources))
+		return
+	}
+	if cfg.Type != common.BinarySourceCustom && cfg.Type != common.BinarySourceLocal {
+		if cfg.BaseURL != "" {
+			verrs.Add(fmt.Sprintf("%s.baseURL: only used by custom and local sources", pathPrefix))
+		}
+		return
+	}
+	if cfg.BaseURL == "" {
+		verrs.Add(fmt.Sprintf("%s.baseURL: is required for a %s source", pathPrefix, cfg.Type))
+		return
+	}
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		verrs.Add(fmt.Sprintf("%s.baseURL: '%s' must be an absolute http or https URL", pathPrefix, cfg.BaseURL))
+	}
+}
@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// userNameRegex matches the user names useradd accepts by default.
+var userNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// BootstrapSpec configures `kubexm bootstrap`, which logs in to the hosts with the credentials of
+// spec.hosts, typically root or the initial user of an image, and creates a dedicated user that
+// kubexm logs in as from then on: its password is unusable, it is authorized with the public key
+// of PrivateKeyPath and it may run any command through sudo without a password.
+type BootstrapSpec struct {
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// PrivateKeyPath is the key the user logs in with. It defaults to spec.global.privateKeyPath,
+	// and a new ed25519 key is generated there if the file does not exist.
+	PrivateKeyPath string `json:"privateKeyPath,omitempty" yaml:"privateKeyPath,omitempty"`
+	// DisableRootLogin and DisablePasswordAuthentication harden sshd once the user can log in
+	// with its key. Both default to true.
+	DisableRootLogin              *bool `json:"disableRootLogin,omitempty" yaml:"disableRootLogin,omitempty"`
+	DisablePasswordAuthentication *bool `json:"disablePasswordAuthentication,omitempty" yaml:"disablePasswordAuthentication,omitempty"`
+}
+
+func SetDefaults_BootstrapSpec(spec *BootstrapSpec, cluster *Cluster) {
+	if spec.User == "" {
+		spec.User = common.DefaultBootstrapUser
+	}
+	if spec.PrivateKeyPath == "" {
+		spec.PrivateKeyPath = cluster.Spec.Global.PrivateKeyPath
+	}
+	if spec.PrivateKeyPath == "" && cluster.Spec.Global.WorkDir != "" {
+		spec.PrivateKeyPath = filepath.Join(cluster.Spec.Global.WorkDir, cluster.Name, "ssh", "id_ed25519")
+	}
+	if spec.DisableRootLogin == nil {
+		spec.DisableRootLogin = helpers.BoolPtr(true)
+	}
+	if spec.DisablePasswordAuthentication == nil {
+		spec.DisablePasswordAuthentication = helpers.BoolPtr(true)
+	}
+}
+
+func Validate_BootstrapSpec(spec *BootstrapSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if !userNameRegex.MatchString(spec.User) {
+		verrs.Add(fmt.Sprintf("%s.user: '%s' is not a valid user name", pathPrefix, spec.User))
+	} else if spec.User == "root" {
+		verrs.Add(pathPrefix + ".user: must not be root")
+	}
+	if spec.PrivateKeyPath == "" {
+		verrs.Add(pathPrefix + ".privateKeyPath: is a required field")
+	}
+}
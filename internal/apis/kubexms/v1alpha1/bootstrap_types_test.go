@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestBootstrapSpec_Defaults(t *testing.T) {
+	cluster := &Cluster{Spec: &ClusterSpec{Global: &GlobalSpec{User: "root", WorkDir: "/work"}}}
+	cluster.Name = "lab"
+	spec := &BootstrapSpec{}
+	SetDefaults_BootstrapSpec(spec, cluster)
+
+	if spec.User != common.DefaultBootstrapUser || spec.PrivateKeyPath != filepath.Join("/work", "lab", "ssh", "id_ed25519") {
+		t.Errorf("user = %s, privateKeyPath = %s", spec.User, spec.PrivateKeyPath)
+	}
+	if spec.DisableRootLogin == nil || !*spec.DisableRootLogin || spec.DisablePasswordAuthentication == nil || !*spec.DisablePasswordAuthentication {
+		t.Error("sshd should be hardened by default")
+	}
+
+	cluster.Spec.Global.PrivateKeyPath = "/keys/id_rsa"
+	spec = &BootstrapSpec{}
+	SetDefaults_BootstrapSpec(spec, cluster)
+	if spec.PrivateKeyPath != "/keys/id_rsa" {
+		t.Errorf("privateKeyPath = %s, want the global key", spec.PrivateKeyPath)
+	}
+}
+
+func TestBootstrapSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec BootstrapSpec
+		want string
+	}{
+		{name: "valid", spec: BootstrapSpec{User: "kubexm", PrivateKeyPath: "/keys/id"}},
+		{name: "root", spec: BootstrapSpec{User: "root", PrivateKeyPath: "/keys/id"}, want: "must not be root"},
+		{name: "invalid name", spec: BootstrapSpec{User: "ops.admin", PrivateKeyPath: "/keys/id"}, want: "not a valid user name"},
+		{name: "no key", spec: BootstrapSpec{User: "kubexm"}, want: "privateKeyPath"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_BootstrapSpec(&tt.spec, verrs, "spec.bootstrap")
+			if tt.want == "" {
+				if verrs.HasErrors() {
+					t.Errorf("unexpected errors: %v", verrs)
+				}
+				return
+			}
+			if !strings.Contains(verrs.Error(), tt.want) {
+				t.Errorf("errors = %v, want %q", verrs, tt.want)
+			}
+		})
+	}
+}
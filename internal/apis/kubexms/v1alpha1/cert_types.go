@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// ExternalCASpec lists the CAs the user brings. A component without an entry keeps a self-signed CA.
+type ExternalCASpec struct {
+	Etcd       *ExternalCA `json:"etcd,omitempty" yaml:"etcd,omitempty"`
+	Kubernetes *ExternalCA `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty"`
+	FrontProxy *ExternalCA `json:"frontProxy,omitempty" yaml:"frontProxy,omitempty"`
+}
+
+// ExternalCA is a root or intermediate CA given either as file paths on the control node or as
+// inline PEM. Chain holds the issuers of an intermediate CA up to and including the root.
+type ExternalCA struct {
+	CertFile  string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile   string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	ChainFile string `json:"chainFile,omitempty" yaml:"chainFile,omitempty"`
+	Cert      string `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Key       string `json:"key,omitempty" yaml:"key,omitempty"`
+	Chain     string `json:"chain,omitempty" yaml:"chain,omitempty"`
+}
+
+// PEM returns the certificate, key and chain of the CA, reading them from their files when given as paths.
+func (c *ExternalCA) PEM() (cert, key, chain []byte, err error) {
+	read := func(file, inline string) ([]byte, error) {
+		if file == "" {
+			return []byte(inline), nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", file, err)
+		}
+		return data, nil
+	}
+	if cert, err = read(c.CertFile, c.Cert); err != nil {
+		return nil, nil, nil, err
+	}
+	if key, err = read(c.KeyFile, c.Key); err != nil {
+		return nil, nil, nil, err
+	}
+	if chain, err = read(c.ChainFile, c.Chain); err != nil {
+		return nil, nil, nil, err
+	}
+	return cert, key, chain, nil
+}
+
+func Validate_CertSpec(cfg *CertSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg == nil || cfg.ExternalCA == nil {
+		return
+	}
+	p := pathPrefix + ".externalCA"
+	if cfg.ExternalCA.Etcd != nil {
+		Validate_ExternalCA(cfg.ExternalCA.Etcd, verrs, p+".etcd")
+	}
+	if cfg.ExternalCA.Kubernetes != nil {
+		Validate_ExternalCA(cfg.ExternalCA.Kubernetes, verrs, p+".kubernetes")
+	}
+	if cfg.ExternalCA.FrontProxy != nil {
+		Validate_ExternalCA(cfg.ExternalCA.FrontProxy, verrs, p+".frontProxy")
+	}
+}
+
+func Validate_ExternalCA(cfg *ExternalCA, verrs *validation.ValidationErrors, pathPrefix string) {
+	validatePair := func(field, file, inline string, required bool) {
+		switch {
+		case file != "" && strings.TrimSpace(inline) != "":
+			verrs.Add(fmt.Sprintf("%s: %sFile and %s are mutually exclusive", pathPrefix, field, field))
+		case file == "" && strings.TrimSpace(inline) == "" && required:
+			verrs.Add(fmt.Sprintf("%s: one of %sFile or %s is required", pathPrefix, field, field))
+		case inline != "" && !strings.Contains(inline, "-----BEGIN "):
+			verrs.Add(fmt.Sprintf("%s.%s: must be PEM encoded", pathPrefix, field))
+		}
+	}
+	validatePair("cert", cfg.CertFile, cfg.Cert, true)
+	validatePair("key", cfg.KeyFile, cfg.Key, true)
+	validatePair("chain", cfg.ChainFile, cfg.Chain, false)
+}
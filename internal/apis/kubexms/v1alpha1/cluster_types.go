@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"github.com/mensylisir/kubexm/internal/cache"
@@ -11,6 +12,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"text/template"
 	"time"
 )
 
@@ -40,11 +42,22 @@ type ClusterSpec struct {
 	Preflight *Preflight   `json:"preflight,omitempty" yaml:"preflight,omitempty"`
 	Extra     *Extra       `json:"extra,omitempty" yaml:"extra,omitempty"`
 	Certs     *CertSpec    `json:"certs,omitempty" yaml:"certs,omitempty"`
+
+	BinarySources *BinarySources `json:"binarySources,omitempty" yaml:"binarySources,omitempty"`
+	// Hooks run local commands or webhooks on execution events.
+	Hooks []Hook `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Infrastructure describes the machines `kubexm vm provision` and `kubexm infra provision`
+	// create for the hosts.
+	Infrastructure *Infrastructure `json:"infrastructure,omitempty" yaml:"infrastructure,omitempty"`
+	// Bootstrap describes the user `kubexm bootstrap` creates on the hosts.
+	Bootstrap *BootstrapSpec `json:"bootstrap,omitempty" yaml:"bootstrap,omitempty"`
 }
 
 type CertSpec struct {
 	CADuration   string `json:"CADuration,omitempty" yaml:"CADuration,omitempty"`
 	CertDuration string `json:"CertDuration,omitempty" yaml:"CertDuration,omitempty"`
+	// ExternalCA supplies existing CAs that sign the leaf certificates instead of self-signed ones.
+	ExternalCA *ExternalCASpec `json:"externalCA,omitempty" yaml:"externalCA,omitempty"`
 }
 
 type HostSpec struct {
@@ -58,14 +71,40 @@ type HostSpec struct {
 	PrivateKeyPath  string            `json:"privateKeyPath,omitempty" yaml:"privateKeyPath,omitempty"`
 	Labels          map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 	Taints          []TaintSpec       `json:"taints,omitempty" yaml:"taints,omitempty"`
-	Type            string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Type            string            `json:"type,omitempty" yaml:"type,omitempty"` // ssh (default) or local to run on the machine running kubexm
 	Arch            string            `json:"arch,omitempty" yaml:"arch,omitempty"`
+	OS              string            `json:"os,omitempty" yaml:"os,omitempty"` // linux (default) or windows; Windows hosts can only be kubeadm workers and must already run containerd
 	Timeout         int64             `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	SSH             *HostSSHSpec      `json:"ssh,omitempty" yaml:"ssh,omitempty"`
+	Bastion         *BastionSpec      `json:"bastion,omitempty" yaml:"bastion,omitempty"`
 	Roles           []string          `json:"-"`
 	RoleTable       map[string]bool   `json:"-"`
 	Cache           *cache.StepCache  `json:"-"`
 }
 
+// HostSSHSpec tunes the SSH transport of a single host. All durations are in seconds.
+// ServerAliveInterval and ServerAliveCountMax follow the OpenSSH options of the same name:
+// a connection is considered dead after ServerAliveCountMax unanswered keepalives.
+// MaxSessions bounds the commands run concurrently over the single connection to the host and
+// should not exceed the MaxSessions setting of its sshd.
+type HostSSHSpec struct {
+	ConnectTimeout      int64 `json:"connectTimeout,omitempty" yaml:"connectTimeout,omitempty"`
+	ServerAliveInterval int64 `json:"serverAliveInterval,omitempty" yaml:"serverAliveInterval,omitempty"`
+	ServerAliveCountMax int   `json:"serverAliveCountMax,omitempty" yaml:"serverAliveCountMax,omitempty"`
+	MaxSessions         int   `json:"maxSessions,omitempty" yaml:"maxSessions,omitempty"`
+}
+
+// BastionSpec is an SSH jump host through which the host is reached. Commands and SFTP file
+// transfers are tunneled through it. User and credentials default to those of the host.
+type BastionSpec struct {
+	Address        string `json:"address" yaml:"address"`
+	Port           int    `json:"port,omitempty" yaml:"port,omitempty"`
+	User           string `json:"user,omitempty" yaml:"user,omitempty"`
+	Password       string `json:"password,omitempty" yaml:"password,omitempty"`
+	PrivateKey     string `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+	PrivateKeyPath string `json:"privateKeyPath,omitempty" yaml:"privateKeyPath,omitempty"`
+}
+
 type RoleGroupsSpec struct {
 	Master       []string `json:"master,omitempty" yaml:"master,omitempty"`
 	Worker       []string `json:"worker,omitempty" yaml:"worker,omitempty"`
@@ -115,6 +154,30 @@ type SystemSpec struct {
 	SkipConfigureOS    bool              `json:"skipConfigureOS,omitempty" yaml:"skipConfigureOS,omitempty"`
 	Modules            []string          `json:"modules,omitempty" yaml:"modules,omitempty"`
 	SysctlParams       map[string]string `json:"sysctlParams,omitempty" yaml:"sysctlParams,omitempty"`
+	// SELinux is the mode SELinux is put in on the hosts that have it: disabled, permissive or
+	// enforcing. Unset, SELinux is disabled if spec.preflight.disableSelinux is set and left alone
+	// otherwise.
+	SELinux string `json:"selinux,omitempty" yaml:"selinux,omitempty"`
+	// TimeSync configures the daemon that syncs the clocks with NTPServers.
+	TimeSync *TimeSyncSpec `json:"timeSync,omitempty" yaml:"timeSync,omitempty"`
+	// PackageRepository sets up local repositories to install the OS packages from.
+	PackageRepository *PackageRepositorySpec `json:"packageRepository,omitempty" yaml:"packageRepository,omitempty"`
+	// Retry is the default retry policy for every step of a run. Unset means failed steps are not retried.
+	Retry *RetrySpec `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// RetrySpec configures how often a failed step is run again on a host, e.g. after an SSH
+// connection drop or while another process holds the package manager lock.
+type RetrySpec struct {
+	// MaxAttempts is the total number of runs of a step, including the first one.
+	MaxAttempts int `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+	// BaseDelay is the wait before the first retry; it doubles for every further retry.
+	BaseDelay time.Duration `json:"baseDelay,omitempty" yaml:"baseDelay,omitempty"`
+	// MaxDelay caps the wait between two attempts.
+	MaxDelay time.Duration `json:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`
+	// RetryOn lists regular expressions for the errors worth retrying. Empty retries only transient
+	// errors such as SSH connection drops, network timeouts and package manager locks.
+	RetryOn []string `json:"retryOn,omitempty" yaml:"retryOn,omitempty"`
 }
 
 type GlobalSpec struct {
@@ -129,7 +192,15 @@ type GlobalSpec struct {
 	Verbose           bool          `json:"verbose,omitempty" yaml:"verbose,omitempty"`
 	IgnoreErr         bool          `json:"ignoreErr,omitempty" yaml:"ignoreErr,omitempty"`
 	SkipPreflight     bool          `json:"skipPreflight,omitempty" yaml:"skipPreflight,omitempty"`
-	OfflineMode       bool          `json:"offlineMode,omitempty" yaml:"offlineMode,omitempty"`
+	// OfflineMode is the "offline: true" setting: every asset comes from the local work dir or an
+	// artifacts bundle. --artifacts turns it on.
+	OfflineMode bool `json:"offlineMode,omitempty" yaml:"offlineMode,omitempty"`
+	// CommandWrapper is a Go template wrapped around every command run on a host, e.g.
+	// "myvault exec -- {{.cmd}}". {{.quoted}} is the command as a single shell-quoted word.
+	CommandWrapper string `json:"commandWrapper,omitempty" yaml:"commandWrapper,omitempty"`
+	// MaxWorkers bounds how many graph nodes are executed concurrently. Zero picks a default
+	// based on the number of hosts.
+	MaxWorkers int `json:"maxWorkers,omitempty" yaml:"maxWorkers,omitempty"`
 }
 
 type TaintSpec struct {
@@ -144,6 +215,12 @@ type GatewaySpec struct {
 
 type IngressNginxSpec struct {
 	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// ServiceType, HTTPPort and HTTPSPort configure the ingress-nginx addon installed from spec.addons.
+	// ServiceType exposes the controller through a NodePort or LoadBalancer Service, or directly on the node network (HostNetwork).
+	ServiceType string `json:"serviceType,omitempty" yaml:"serviceType,omitempty"`
+	// HTTPPort and HTTPSPort are the node ports for NodePort, the Service ports for LoadBalancer and the host ports for HostNetwork.
+	HTTPPort  *int32 `json:"httpPort,omitempty" yaml:"httpPort,omitempty"`
+	HTTPSPort *int32 `json:"httpsPort,omitempty" yaml:"httpsPort,omitempty"`
 }
 
 func SetDefaults_Cluster(obj *Cluster) {
@@ -199,6 +276,7 @@ func SetDefaults_ClusterSpec(cluster *Cluster) {
 		cluster.Spec.Registry = &Registry{}
 	}
 	SetDefaults_Registry(cluster.Spec.Registry)
+	SetDefaults_LocalRegistryAddress(cluster.Spec)
 
 	if cluster.Spec.Etcd == nil {
 		cluster.Spec.Etcd = &Etcd{}
@@ -216,13 +294,41 @@ func SetDefaults_ClusterSpec(cluster *Cluster) {
 		cluster.Spec.Gateway = &GatewaySpec{}
 	}
 	SetDefault_Gateway(cluster.Spec.Gateway)
+	SetDefaults_BinarySources(cluster.Spec.BinarySources)
+	for i := range cluster.Spec.Hooks {
+		SetDefaults_Hook(&cluster.Spec.Hooks[i])
+	}
+	if cluster.Spec.Infrastructure != nil {
+		SetDefaults_Infrastructure(cluster.Spec.Infrastructure, cluster)
+	}
+	if cluster.Spec.Bootstrap != nil {
+		SetDefaults_BootstrapSpec(cluster.Spec.Bootstrap, cluster)
+	}
 }
 
 func SetDefault_Gateway(spec *GatewaySpec) {
 	if spec.IngressNginx == nil {
 		spec.IngressNginx = &IngressNginxSpec{Enabled: helpers.BoolPtr(false)}
 	}
+	SetDefaults_IngressNginxSpec(spec.IngressNginx)
 }
+
+func SetDefaults_IngressNginxSpec(spec *IngressNginxSpec) {
+	if spec.ServiceType == "" {
+		spec.ServiceType = common.DefaultIngressNginxServiceType
+	}
+	httpPort, httpsPort := int32(common.DefaultIngressNginxHTTPPort), int32(common.DefaultIngressNginxHTTPSPort)
+	if spec.ServiceType == common.IngressNginxServiceTypeNodePort {
+		httpPort, httpsPort = common.DefaultIngressNginxHTTPNodePort, common.DefaultIngressNginxHTTPSNodePort
+	}
+	if spec.HTTPPort == nil {
+		spec.HTTPPort = helpers.Int32Ptr(httpPort)
+	}
+	if spec.HTTPSPort == nil {
+		spec.HTTPSPort = helpers.Int32Ptr(httpsPort)
+	}
+}
+
 func SetDefaults_GlobalSpec(spec *GlobalSpec) {
 	if spec.User == "" {
 		spec.User = common.DefaultUser
@@ -239,34 +345,73 @@ func SetDefaults_GlobalSpec(spec *GlobalSpec) {
 	}
 }
 
+// SetDefaults_HostSpec fills connection settings the host does not set from the global spec.
+// Credentials are inherited as a group: a host that sets any of password, privateKey or
+// privateKeyPath keeps only its own credentials, so a per-host key is not mixed with a
+// cluster-wide password.
 func SetDefaults_HostSpec(spec *HostSpec, cluster *Cluster) {
 	if spec.User == "" && cluster.Spec.Global.User != "" {
 		spec.User = cluster.Spec.Global.User
 	}
-	if spec.Password == "" && cluster.Spec.Global.Password != "" {
-		spec.Password = cluster.Spec.Global.Password
-	}
 	if spec.Port == 0 && cluster.Spec.Global.Port != 0 {
 		spec.Port = cluster.Spec.Global.Port
 	} else if spec.Port == 0 && cluster.Spec.Global.Port == 0 {
 		spec.Port = common.DefaultPort
 	}
-	if spec.PrivateKey == "" && cluster.Spec.Global.PrivateKey != "" {
+	if spec.Password == "" && spec.PrivateKey == "" && spec.PrivateKeyPath == "" {
+		spec.Password = cluster.Spec.Global.Password
 		spec.PrivateKey = cluster.Spec.Global.PrivateKey
-	}
-	if spec.PrivateKeyPath == "" && cluster.Spec.Global.PrivateKeyPath != "" {
 		spec.PrivateKeyPath = cluster.Spec.Global.PrivateKeyPath
 	}
 	if spec.Arch == "" {
 		spec.Arch = common.ArchAMD64
 	}
+	if spec.Bastion != nil {
+		SetDefaults_BastionSpec(spec.Bastion, spec)
+	}
 	_, _ = helpers.GenerateHostWorkDir(cluster.ObjectMeta.Name, cluster.Spec.Global.WorkDir, spec.Name)
 }
 
+func SetDefaults_BastionSpec(spec *BastionSpec, host *HostSpec) {
+	if spec.Port == 0 {
+		spec.Port = common.DefaultPort
+	}
+	if spec.User == "" {
+		spec.User = host.User
+	}
+	if spec.Password == "" && spec.PrivateKey == "" && spec.PrivateKeyPath == "" {
+		spec.Password = host.Password
+		spec.PrivateKey = host.PrivateKey
+		spec.PrivateKeyPath = host.PrivateKeyPath
+	}
+}
+
 func SetDefaults_SystemSpec(spec *SystemSpec) {
 	if spec.Timezone == "" {
 		spec.Timezone = "UTC"
 	}
+	if spec.Retry != nil {
+		SetDefaults_RetrySpec(spec.Retry)
+	}
+	if spec.TimeSync == nil {
+		spec.TimeSync = &TimeSyncSpec{}
+	}
+	SetDefaults_TimeSyncSpec(spec.TimeSync)
+	if spec.PackageRepository != nil {
+		SetDefaults_PackageRepositorySpec(spec.PackageRepository)
+	}
+}
+
+func SetDefaults_RetrySpec(spec *RetrySpec) {
+	if spec.MaxAttempts == 0 {
+		spec.MaxAttempts = common.DefaultStepRetryAttempts
+	}
+	if spec.BaseDelay == 0 {
+		spec.BaseDelay = common.DefaultStepRetryBaseDelay
+	}
+	if spec.MaxDelay == 0 {
+		spec.MaxDelay = common.DefaultStepRetryMaxDelay
+	}
 }
 
 func Validate_Cluster(obj *Cluster, verrs *validation.ValidationErrors) {
@@ -282,6 +427,96 @@ func Validate_Cluster(obj *Cluster, verrs *validation.ValidationErrors) {
 	Validate_ClusterSpec(obj.Spec, verrs, "spec")
 }
 
+// HasWindowsHosts reports whether any host of the cluster runs Windows.
+func HasWindowsHosts(spec *ClusterSpec) bool {
+	for _, host := range spec.Hosts {
+		if host.OS == common.OSWindows {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWindowsHosts checks what Windows hosts depend on: they are joined by kubeadm as workers
+// running containerd, and are left out of every other role group.
+func validateWindowsHosts(spec *ClusterSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if spec.Kubernetes != nil && spec.Kubernetes.Type != "" && spec.Kubernetes.Type != string(common.KubernetesDeploymentTypeKubeadm) {
+		verrs.Add(fmt.Sprintf("%s.kubernetes.type: Windows hosts can only be joined by '%s', got '%s'", pathPrefix, common.KubernetesDeploymentTypeKubeadm, spec.Kubernetes.Type))
+	}
+	if spec.Kubernetes != nil && spec.Kubernetes.ContainerRuntime != nil {
+		if rt := spec.Kubernetes.ContainerRuntime.Type; rt != "" && rt != common.RuntimeTypeContainerd {
+			verrs.Add(fmt.Sprintf("%s.kubernetes.containerRuntime.type: Windows hosts require '%s', got '%s'", pathPrefix, common.RuntimeTypeContainerd, rt))
+		}
+	}
+	windowsHosts := make(map[string]bool)
+	for i, host := range spec.Hosts {
+		if host.OS != common.OSWindows {
+			continue
+		}
+		windowsHosts[host.Name] = true
+		for _, role := range host.Roles {
+			if role != common.RoleWorker {
+				verrs.Add(fmt.Sprintf("%s.hosts[%d].roles: host '%s' runs Windows and can only be a worker", pathPrefix, i, host.Name))
+			}
+		}
+	}
+	if spec.RoleGroups == nil {
+		return
+	}
+	for _, group := range []struct {
+		name  string
+		hosts []string
+	}{
+		{"master", spec.RoleGroups.Master},
+		{"etcd", spec.RoleGroups.Etcd},
+		{"registry", spec.RoleGroups.Registry},
+		{"loadbalancer", spec.RoleGroups.LoadBalancer},
+		{"storage", spec.RoleGroups.Storage},
+	} {
+		for _, hostName := range group.hosts {
+			if windowsHosts[hostName] {
+				verrs.Add(fmt.Sprintf("%s.roleGroups.%s: host '%s' runs Windows and can only be a worker", pathPrefix, group.name, hostName))
+			}
+		}
+	}
+}
+
+// validateLocalRegistryHost checks that a local registry has a host to run on, and that the host is
+// a cluster node, since the registry runs in that node's container runtime.
+func validateLocalRegistryHost(spec *ClusterSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if spec.RoleGroups == nil || len(spec.RoleGroups.Registry) == 0 {
+		verrs.Add(pathPrefix + ".type: 'local' requires at least one host in roleGroups.registry")
+		return
+	}
+	name := spec.RoleGroups.Registry[0]
+	for _, h := range append(append([]string{}, spec.RoleGroups.Master...), spec.RoleGroups.Worker...) {
+		if h == name {
+			return
+		}
+	}
+	verrs.Add(fmt.Sprintf("%s.type: registry host '%s' must also be a master or worker so it has a container runtime", pathPrefix, name))
+}
+
+// validateKubeProxyReplacement checks that Cilium takes over service load balancing when
+// kube-proxy is not deployed; only its strict replacement mode handles services on its own.
+func validateKubeProxyReplacement(spec *ClusterSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if KubeProxyEnabled(spec) || spec.Network == nil {
+		return
+	}
+	// Only Cilium implements Services itself; any other plugin would leave them unreachable.
+	if spec.Network.Plugin != string(common.CNITypeCilium) {
+		verrs.Add(fmt.Sprintf("%s.plugin: '%s' does not replace kube-proxy; kube-proxy mode '%s' requires '%s'", pathPrefix, spec.Network.Plugin, common.KubeProxyModeNone, common.CNITypeCilium))
+		return
+	}
+	if spec.Network.Cilium == nil {
+		return
+	}
+	if kpr := spec.Network.Cilium.KubeProxy; kpr != nil && kpr.ReplacementMode != "" && kpr.ReplacementMode != common.CiliumKPRStrictModes {
+		verrs.Add(fmt.Sprintf("%s.cilium.kubeProxy.replacement: must be '%s' when kube-proxy mode is '%s'",
+			pathPrefix, common.CiliumKPRStrictModes, common.KubeProxyModeNone))
+	}
+}
+
 func Validate_ClusterSpec(spec *ClusterSpec, verrs *validation.ValidationErrors, pathPrefix string) {
 	if spec == nil {
 		verrs.Add(pathPrefix + ": spec section cannot be nil")
@@ -294,9 +529,16 @@ func Validate_ClusterSpec(spec *ClusterSpec, verrs *validation.ValidationErrors,
 	}
 	hostNames := make(map[string]bool)
 	hostAddresses := make(map[string]bool)
+	localHost := ""
 	for i, host := range spec.Hosts {
 		hostPath := fmt.Sprintf("%s.hosts[%d]", p, i)
 		Validate_HostSpec(&host, verrs, hostPath)
+		if host.Type == string(common.HostTypeLocal) {
+			if localHost != "" {
+				verrs.Add(fmt.Sprintf("%s.type: host '%s' is already the local host; only one host can be local", hostPath, localHost))
+			}
+			localHost = host.Name
+		}
 		if host.Name != "" {
 			if hostNames[host.Name] {
 				verrs.Add(fmt.Sprintf("%s.name: duplicate host name '%s'", hostPath, host.Name))
@@ -341,6 +583,10 @@ func Validate_ClusterSpec(spec *ClusterSpec, verrs *validation.ValidationErrors,
 		}
 	}
 
+	if HasWindowsHosts(spec) {
+		validateWindowsHosts(spec, verrs, p)
+	}
+
 	if spec.Global != nil {
 		Validate_GlobalSpec(spec.Global, verrs, path.Join(p, "global"))
 	}
@@ -359,6 +605,7 @@ func Validate_ClusterSpec(spec *ClusterSpec, verrs *validation.ValidationErrors,
 
 	if spec.Network != nil {
 		Validate_Network(spec.Network, verrs, path.Join(p, "network"))
+		validateKubeProxyReplacement(spec, verrs, path.Join(p, "network"))
 	} else {
 		verrs.Add(p + ".network: is a required section")
 	}
@@ -385,10 +632,26 @@ func Validate_ClusterSpec(spec *ClusterSpec, verrs *validation.ValidationErrors,
 
 	if spec.Registry != nil {
 		Validate_Registry(spec.Registry, verrs, path.Join(p, "registry"))
+		if spec.Registry.Type == common.RegistryDeployTypeLocal {
+			validateLocalRegistryHost(spec, verrs, path.Join(p, "registry"))
+		}
 	}
 
+	seenAddons := make(map[string]bool)
 	for i, addon := range spec.Addons {
-		Validate_Addon(&addon, verrs, fmt.Sprintf("%s.addons[%d]", p, i))
+		addonPath := fmt.Sprintf("%s.addons[%d]", p, i)
+		Validate_Addon(&addon, verrs, addonPath)
+		if seenAddons[addon.Name] {
+			verrs.Add(fmt.Sprintf("%s.name: addon '%s' is listed more than once", addonPath, addon.Name))
+		}
+		seenAddons[addon.Name] = true
+		if addon.Name == common.AddonNFSProvisioner && len(addon.Sources) == 0 && (addon.Enabled == nil || *addon.Enabled) {
+			validateNFSProvisionerAddon(spec.Storage, verrs, addonPath)
+		}
+	}
+
+	if spec.Gateway != nil && spec.Gateway.IngressNginx != nil {
+		Validate_IngressNginxSpec(spec.Gateway.IngressNginx, verrs, path.Join(p, "gateway", "ingressNginx"))
 	}
 
 	if spec.Preflight != nil {
@@ -398,6 +661,23 @@ func Validate_ClusterSpec(spec *ClusterSpec, verrs *validation.ValidationErrors,
 	if spec.Extra != nil {
 		Validate_Extra(spec.Extra, verrs, path.Join(p, "extra"))
 	}
+
+	if spec.BinarySources != nil {
+		Validate_BinarySources(spec.BinarySources, verrs, path.Join(p, "binarySources"))
+	}
+
+	if spec.Certs != nil {
+		Validate_CertSpec(spec.Certs, verrs, path.Join(p, "certs"))
+	}
+
+	Validate_Hooks(spec.Hooks, verrs, path.Join(p, "hooks"))
+
+	if spec.Infrastructure != nil {
+		Validate_Infrastructure(spec.Infrastructure, verrs, path.Join(p, "infrastructure"))
+	}
+	if spec.Bootstrap != nil {
+		Validate_BootstrapSpec(spec.Bootstrap, verrs, path.Join(p, "bootstrap"))
+	}
 }
 
 func Validate_HostSpec(spec *HostSpec, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -440,6 +720,11 @@ func Validate_HostSpec(spec *HostSpec, verrs *validation.ValidationErrors, pathP
 		}
 	}
 
+	isLocal := spec.Type == string(common.HostTypeLocal)
+	if spec.Type != "" && spec.Type != string(common.HostTypeSSH) && !isLocal {
+		verrs.Add(fmt.Sprintf("%s.type: unsupported value '%s', must be one of [%s, %s]", pathPrefix, spec.Type, common.HostTypeSSH, common.HostTypeLocal))
+	}
+
 	authMethods := 0
 	if spec.Password != "" {
 		authMethods++
@@ -453,13 +738,90 @@ func Validate_HostSpec(spec *HostSpec, verrs *validation.ValidationErrors, pathP
 	if authMethods > 1 {
 		verrs.Add(pathPrefix + ": only one of password, privateKey, or privateKeyPath can be set")
 	}
-	if authMethods == 0 {
+	if authMethods == 0 && !isLocal {
 		verrs.Add(pathPrefix + ": one of password, privateKey, or privateKeyPath is required for SSH authentication")
 	}
+	if spec.PrivateKey != "" {
+		if _, err := base64.StdEncoding.DecodeString(spec.PrivateKey); err != nil {
+			verrs.Add(pathPrefix + ".privateKey: must be base64 encoded")
+		}
+	}
+	if spec.User == "" && !isLocal {
+		verrs.Add(pathPrefix + ".user: is a required field")
+	}
+	if spec.Port < 0 || spec.Port > 65535 {
+		verrs.Add(fmt.Sprintf("%s.port: %d is not a valid port", pathPrefix, spec.Port))
+	}
+	if spec.OS != "" && spec.OS != common.OSLinux && spec.OS != common.OSWindows {
+		verrs.Add(fmt.Sprintf("%s.os: unsupported value '%s', must be one of [%s, %s]", pathPrefix, spec.OS, common.OSLinux, common.OSWindows))
+	}
 
 	for i, taint := range spec.Taints {
 		Validate_TaintSpec(&taint, verrs, fmt.Sprintf("%s.taints[%d]", pathPrefix, i))
 	}
+
+	if spec.SSH != nil {
+		Validate_HostSSHSpec(spec.SSH, verrs, pathPrefix+".ssh")
+	}
+	if spec.Bastion != nil {
+		Validate_BastionSpec(spec.Bastion, verrs, pathPrefix+".bastion")
+	}
+	if isLocal {
+		if spec.Bastion != nil {
+			verrs.Add(pathPrefix + ".bastion: cannot be used with a local host")
+		}
+		if spec.OS == common.OSWindows {
+			verrs.Add(pathPrefix + ".os: a local host cannot be a Windows host")
+		}
+	}
+}
+
+func Validate_BastionSpec(spec *BastionSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	addr := strings.TrimSpace(spec.Address)
+	if addr == "" {
+		verrs.Add(pathPrefix + ".address: is a required field")
+	} else if net.ParseIP(addr) == nil && !helpers.IsValidDomainName(addr) {
+		verrs.Add(fmt.Sprintf("%s.address: invalid format for '%s', must be a valid IP or hostname", pathPrefix, spec.Address))
+	}
+	if spec.Port < 0 || spec.Port > 65535 {
+		verrs.Add(fmt.Sprintf("%s.port: %d is not a valid port", pathPrefix, spec.Port))
+	}
+	if spec.User == "" {
+		verrs.Add(pathPrefix + ".user: is a required field")
+	}
+
+	authMethods := 0
+	for _, v := range []string{spec.Password, spec.PrivateKey, spec.PrivateKeyPath} {
+		if v != "" {
+			authMethods++
+		}
+	}
+	if authMethods > 1 {
+		verrs.Add(pathPrefix + ": only one of password, privateKey, or privateKeyPath can be set")
+	}
+	if authMethods == 0 {
+		verrs.Add(pathPrefix + ": one of password, privateKey, or privateKeyPath is required for SSH authentication")
+	}
+	if spec.PrivateKey != "" {
+		if _, err := base64.StdEncoding.DecodeString(spec.PrivateKey); err != nil {
+			verrs.Add(pathPrefix + ".privateKey: must be base64 encoded")
+		}
+	}
+}
+
+func Validate_HostSSHSpec(spec *HostSSHSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if spec.ConnectTimeout < 0 {
+		verrs.Add(fmt.Sprintf("%s.connectTimeout: must be non-negative, got %d", pathPrefix, spec.ConnectTimeout))
+	}
+	if spec.ServerAliveInterval < 0 {
+		verrs.Add(fmt.Sprintf("%s.serverAliveInterval: must be non-negative, got %d", pathPrefix, spec.ServerAliveInterval))
+	}
+	if spec.ServerAliveCountMax < 0 {
+		verrs.Add(fmt.Sprintf("%s.serverAliveCountMax: must be non-negative, got %d", pathPrefix, spec.ServerAliveCountMax))
+	}
+	if spec.MaxSessions < 0 {
+		verrs.Add(fmt.Sprintf("%s.maxSessions: must be non-negative, got %d", pathPrefix, spec.MaxSessions))
+	}
 }
 
 func Validate_TaintSpec(spec *TaintSpec, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -510,6 +872,18 @@ func Validate_GlobalSpec(spec *GlobalSpec, verrs *validation.ValidationErrors, p
 	} else if !path.IsAbs(spec.WorkDir) {
 		verrs.Add(fmt.Sprintf("%s.workDir: must be an absolute path, got '%s'", p, spec.WorkDir))
 	}
+
+	if spec.MaxWorkers < 0 {
+		verrs.Add(fmt.Sprintf("%s.maxWorkers: must be positive, got %d", p, spec.MaxWorkers))
+	}
+
+	if spec.CommandWrapper != "" {
+		if _, err := template.New("commandWrapper").Parse(spec.CommandWrapper); err != nil {
+			verrs.Add(fmt.Sprintf("%s.commandWrapper: invalid template: %v", p, err))
+		} else if !strings.Contains(spec.CommandWrapper, ".cmd") && !strings.Contains(spec.CommandWrapper, ".quoted") {
+			verrs.Add(fmt.Sprintf("%s.commandWrapper: must reference the command as {{.cmd}} or {{.quoted}}", p))
+		}
+	}
 }
 
 func Validate_SystemSpec(spec *SystemSpec, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -551,6 +925,13 @@ func Validate_SystemSpec(spec *SystemSpec, verrs *validation.ValidationErrors, p
 		}
 	}
 
+	moduleNameRegex := regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	for i, module := range spec.Modules {
+		if !moduleNameRegex.MatchString(module) {
+			verrs.Add(fmt.Sprintf("%s.modules[%d]: invalid kernel module name '%s'", p, i, module))
+		}
+	}
+
 	sysctlKeyRegex := regexp.MustCompile(`^[a-z0-9\._-]+$`)
 	for key, value := range spec.SysctlParams {
 		if !sysctlKeyRegex.MatchString(key) {
@@ -558,6 +939,57 @@ func Validate_SystemSpec(spec *SystemSpec, verrs *validation.ValidationErrors, p
 		}
 		if strings.TrimSpace(value) == "" {
 			verrs.Add(fmt.Sprintf("%s.sysctlParams['%s']: value cannot be empty", p, key))
+		} else if strings.ContainsAny(value, "\n`") {
+			verrs.Add(fmt.Sprintf("%s.sysctlParams['%s']: value cannot contain newlines or backquotes", p, key))
 		}
 	}
+
+	if !helpers.ContainsString(common.ValidSELinuxModes, spec.SELinux) {
+		verrs.Add(fmt.Sprintf("%s.selinux: invalid mode '%s', must be one of disabled, permissive or enforcing", p, spec.SELinux))
+	}
+
+	if spec.Retry != nil {
+		Validate_RetrySpec(spec.Retry, verrs, p+".retry")
+	}
+	if spec.TimeSync != nil {
+		Validate_TimeSyncSpec(spec.TimeSync, verrs, p+".timeSync")
+	}
+	if spec.PackageRepository != nil {
+		Validate_PackageRepositorySpec(spec.PackageRepository, verrs, p+".packageRepository")
+	}
+}
+
+func Validate_RetrySpec(spec *RetrySpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if spec.MaxAttempts < 1 {
+		verrs.Add(fmt.Sprintf("%s.maxAttempts: must be at least 1, got %d", pathPrefix, spec.MaxAttempts))
+	}
+	if spec.BaseDelay < 0 {
+		verrs.Add(fmt.Sprintf("%s.baseDelay: cannot be negative, got %s", pathPrefix, spec.BaseDelay))
+	}
+	if spec.MaxDelay < 0 {
+		verrs.Add(fmt.Sprintf("%s.maxDelay: cannot be negative, got %s", pathPrefix, spec.MaxDelay))
+	}
+	if spec.MaxDelay > 0 && spec.MaxDelay < spec.BaseDelay {
+		verrs.Add(fmt.Sprintf("%s.maxDelay: %s is shorter than baseDelay %s", pathPrefix, spec.MaxDelay, spec.BaseDelay))
+	}
+	for i, pattern := range spec.RetryOn {
+		if _, err := regexp.Compile(pattern); err != nil {
+			verrs.Add(fmt.Sprintf("%s.retryOn[%d]: invalid regular expression '%s': %v", pathPrefix, i, pattern, err))
+		}
+	}
+}
+
+func Validate_IngressNginxSpec(spec *IngressNginxSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if spec.ServiceType != "" && !helpers.ContainsString(common.SupportedIngressNginxServiceTypes, spec.ServiceType) {
+		verrs.Add(fmt.Sprintf("%s.serviceType: invalid service type '%s', must be one of %v", pathPrefix, spec.ServiceType, common.SupportedIngressNginxServiceTypes))
+	}
+	if spec.HTTPPort != nil && (*spec.HTTPPort <= 0 || *spec.HTTPPort > 65535) {
+		verrs.Add(fmt.Sprintf("%s.httpPort: invalid port %d, must be between 1 and 65535", pathPrefix, *spec.HTTPPort))
+	}
+	if spec.HTTPSPort != nil && (*spec.HTTPSPort <= 0 || *spec.HTTPSPort > 65535) {
+		verrs.Add(fmt.Sprintf("%s.httpsPort: invalid port %d, must be between 1 and 65535", pathPrefix, *spec.HTTPSPort))
+	}
+	if spec.HTTPPort != nil && spec.HTTPSPort != nil && *spec.HTTPPort == *spec.HTTPSPort {
+		verrs.Add(fmt.Sprintf("%s: httpPort and httpsPort must differ, both are %d", pathPrefix, *spec.HTTPPort))
+	}
 }
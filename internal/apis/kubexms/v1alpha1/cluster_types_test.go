@@ -0,0 +1,417 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestSetDefaults_HostSpec_PerHostOverrides(t *testing.T) {
+	cluster := &Cluster{Spec: &ClusterSpec{
+		Global: &GlobalSpec{User: "kubexm", Port: 22, Password: "global-secret"},
+		Hosts: []HostSpec{
+			{Name: "default", Address: "192.168.1.10"},
+			{Name: "override", Address: "192.168.1.11", User: "admin", Port: 2222, PrivateKeyPath: "/keys/override"},
+			{Name: "password", Address: "192.168.1.12", Password: "host-secret"},
+		},
+	}}
+	SetDefaults_ClusterSpec(cluster)
+
+	tests := []struct {
+		name               string
+		host               HostSpec
+		expectedUser       string
+		expectedPort       int
+		expectedPassword   string
+		expectedPrivateKey string
+	}{
+		{name: "inherits global settings", host: cluster.Spec.Hosts[0], expectedUser: "kubexm", expectedPort: 22, expectedPassword: "global-secret"},
+		{name: "per-host key replaces global password", host: cluster.Spec.Hosts[1], expectedUser: "admin", expectedPort: 2222, expectedPrivateKey: "/keys/override"},
+		{name: "per-host password wins", host: cluster.Spec.Hosts[2], expectedUser: "kubexm", expectedPort: 22, expectedPassword: "host-secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.host.User != tt.expectedUser {
+				t.Errorf("expected user %q, got %q", tt.expectedUser, tt.host.User)
+			}
+			if tt.host.Port != tt.expectedPort {
+				t.Errorf("expected port %d, got %d", tt.expectedPort, tt.host.Port)
+			}
+			if tt.host.Password != tt.expectedPassword {
+				t.Errorf("expected password %q, got %q", tt.expectedPassword, tt.host.Password)
+			}
+			if tt.host.PrivateKeyPath != tt.expectedPrivateKey {
+				t.Errorf("expected private key path %q, got %q", tt.expectedPrivateKey, tt.host.PrivateKeyPath)
+			}
+
+			verrs := &validation.ValidationErrors{}
+			Validate_HostSpec(&tt.host, verrs, "spec.hosts[0]")
+			if verrs.HasErrors() {
+				t.Errorf("expected host to be valid after defaulting, got %v", verrs.Error())
+			}
+		})
+	}
+}
+
+func TestValidate_HostSpec_Credentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        HostSpec
+		expectedErr string
+	}{
+		{
+			name:        "missing all credentials",
+			host:        HostSpec{Name: "node1", Address: "192.168.1.10", User: "root", Port: 22},
+			expectedErr: "one of password, privateKey, or privateKeyPath is required",
+		},
+		{
+			name:        "multiple credentials",
+			host:        HostSpec{Name: "node1", Address: "192.168.1.10", User: "root", Port: 22, Password: "secret", PrivateKeyPath: "/keys/id"},
+			expectedErr: "only one of password, privateKey, or privateKeyPath can be set",
+		},
+		{
+			name:        "private key not base64",
+			host:        HostSpec{Name: "node1", Address: "192.168.1.10", User: "root", Port: 22, PrivateKey: "-----BEGIN KEY-----"},
+			expectedErr: "privateKey: must be base64 encoded",
+		},
+		{
+			name:        "missing user",
+			host:        HostSpec{Name: "node1", Address: "192.168.1.10", Port: 22, Password: "secret"},
+			expectedErr: "user: is a required field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_HostSpec(&tt.host, verrs, "spec.hosts[0]")
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestSetDefaults_HostSpec_MissingCredentialsRejected(t *testing.T) {
+	cluster := &Cluster{Spec: &ClusterSpec{
+		Global: &GlobalSpec{},
+		Hosts:  []HostSpec{{Name: "node1", Address: "192.168.1.10"}},
+	}}
+	SetDefaults_ClusterSpec(cluster)
+
+	verrs := &validation.ValidationErrors{}
+	Validate_HostSpec(&cluster.Spec.Hosts[0], verrs, "spec.hosts[0]")
+	if !verrs.HasErrors() || !strings.Contains(verrs.Error(), "spec.hosts[0]: one of password, privateKey, or privateKeyPath is required") {
+		t.Errorf("expected a host without credentials to be rejected, got %v", verrs.Error())
+	}
+}
+
+func TestBastionSpec_DefaultsAndValidation(t *testing.T) {
+	cluster := &Cluster{Spec: &ClusterSpec{
+		Global: &GlobalSpec{User: "kubexm", Port: 22, Password: "global-secret"},
+		Hosts: []HostSpec{
+			{Name: "inherit", Address: "10.0.0.10", Bastion: &BastionSpec{Address: "jump.example.com"}},
+			{Name: "own", Address: "10.0.0.11", Bastion: &BastionSpec{Address: "192.168.1.1", Port: 2222, User: "jump", PrivateKeyPath: "/keys/jump"}},
+		},
+	}}
+	SetDefaults_ClusterSpec(cluster)
+
+	inherited := cluster.Spec.Hosts[0].Bastion
+	if inherited.Port != 22 || inherited.User != "kubexm" || inherited.Password != "global-secret" {
+		t.Errorf("expected the bastion to inherit the host connection settings, got %+v", inherited)
+	}
+	own := cluster.Spec.Hosts[1].Bastion
+	if own.Port != 2222 || own.User != "jump" || own.Password != "" || own.PrivateKeyPath != "/keys/jump" {
+		t.Errorf("expected explicit bastion settings to be kept, got %+v", own)
+	}
+	for i := range cluster.Spec.Hosts {
+		verrs := &validation.ValidationErrors{}
+		Validate_HostSpec(&cluster.Spec.Hosts[i], verrs, "spec.hosts[0]")
+		if verrs.HasErrors() {
+			t.Errorf("expected host %s to be valid, got %v", cluster.Spec.Hosts[i].Name, verrs.Error())
+		}
+	}
+
+	tests := []struct {
+		name        string
+		bastion     BastionSpec
+		expectedErr string
+	}{
+		{name: "missing address", bastion: BastionSpec{Port: 22, User: "jump", Password: "secret"}, expectedErr: "spec.hosts[0].bastion.address: is a required field"},
+		{name: "invalid port", bastion: BastionSpec{Address: "10.0.0.1", Port: 70000, User: "jump", Password: "secret"}, expectedErr: "spec.hosts[0].bastion.port"},
+		{name: "multiple credentials", bastion: BastionSpec{Address: "10.0.0.1", Port: 22, User: "jump", Password: "secret", PrivateKeyPath: "/keys/jump"}, expectedErr: "only one of password, privateKey, or privateKeyPath can be set"},
+		{name: "private key not base64", bastion: BastionSpec{Address: "10.0.0.1", Port: 22, User: "jump", PrivateKey: "-----BEGIN KEY-----"}, expectedErr: "spec.hosts[0].bastion.privateKey: must be base64 encoded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := HostSpec{Name: "node1", Address: "10.0.0.10", User: "root", Port: 22, Password: "secret", Bastion: &tt.bastion}
+			verrs := &validation.ValidationErrors{}
+			Validate_HostSpec(&host, verrs, "spec.hosts[0]")
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestValidate_ClusterSpec_WindowsHosts(t *testing.T) {
+	host := HostSpec{Name: "win1", Address: "10.0.0.20", User: "Administrator", Port: 22, Password: "secret", OS: common.OSWindows}
+	verrs := &validation.ValidationErrors{}
+	Validate_HostSpec(&host, verrs, "spec.hosts[0]")
+	if verrs.HasErrors() {
+		t.Errorf("expected a windows host to be valid, got %v", verrs.Error())
+	}
+
+	host.OS = "darwin"
+	verrs = &validation.ValidationErrors{}
+	Validate_HostSpec(&host, verrs, "spec.hosts[0]")
+	if !strings.Contains(verrs.Error(), "spec.hosts[0].os: unsupported value 'darwin'") {
+		t.Errorf("expected an unsupported os error, got %v", verrs.Error())
+	}
+
+	workers := RoleGroupsSpec{Master: []string{"linux1"}, Etcd: []string{"linux1"}, Worker: []string{"win1"}}
+	tests := []struct {
+		name        string
+		roles       RoleGroupsSpec
+		kubernetes  *Kubernetes
+		expectedErr string
+	}{
+		{name: "worker", roles: workers},
+		{name: "master", roles: RoleGroupsSpec{Master: []string{"linux1", "win1"}, Etcd: []string{"linux1"}}, expectedErr: "spec.roleGroups.master: host 'win1' runs Windows and can only be a worker"},
+		{name: "etcd", roles: RoleGroupsSpec{Master: []string{"linux1"}, Etcd: []string{"win1"}, Worker: []string{"win1"}}, expectedErr: "spec.roleGroups.etcd: host 'win1' runs Windows and can only be a worker"},
+		{name: "kubexm", roles: workers, kubernetes: &Kubernetes{Type: string(common.KubernetesDeploymentTypeKubexm)}, expectedErr: "Windows hosts can only be joined by 'kubeadm'"},
+		{name: "docker", roles: workers, kubernetes: &Kubernetes{ContainerRuntime: &ContainerRuntime{Type: common.RuntimeTypeDocker}}, expectedErr: "Windows hosts require 'containerd'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ClusterSpec{
+				Hosts: []HostSpec{
+					{Name: "linux1", Address: "10.0.0.10", User: "root", Port: 22, Password: "secret"},
+					{Name: "win1", Address: "10.0.0.20", User: "Administrator", Port: 22, Password: "secret", OS: common.OSWindows},
+				},
+				RoleGroups: &tt.roles,
+				Kubernetes: tt.kubernetes,
+			}
+			verrs := &validation.ValidationErrors{}
+			Validate_ClusterSpec(spec, verrs, "spec")
+			if tt.expectedErr == "" {
+				if strings.Contains(verrs.Error(), "Windows") {
+					t.Errorf("expected no windows errors, got %v", verrs.Error())
+				}
+			} else if !strings.Contains(verrs.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestValidate_HostSpec_LocalType(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        HostSpec
+		expectedErr string
+	}{
+		{name: "no credentials needed", host: HostSpec{Name: "node1", Address: "10.0.0.10", Type: "local"}},
+		{name: "explicit ssh", host: HostSpec{Name: "node1", Address: "10.0.0.10", Type: "ssh", User: "root", Password: "secret"}},
+		{name: "ssh needs credentials", host: HostSpec{Name: "node1", Address: "10.0.0.10", Type: "ssh", User: "root"}, expectedErr: "is required for SSH authentication"},
+		{name: "unknown type", host: HostSpec{Name: "node1", Address: "10.0.0.10", Type: "winrm", User: "root", Password: "secret"}, expectedErr: "spec.hosts[0].type: unsupported value 'winrm'"},
+		{name: "no bastion", host: HostSpec{Name: "node1", Address: "10.0.0.10", Type: "local", Bastion: &BastionSpec{Address: "10.0.0.1", Port: 22, User: "jump", Password: "secret"}}, expectedErr: "bastion: cannot be used with a local host"},
+		{name: "not windows", host: HostSpec{Name: "node1", Address: "10.0.0.10", Type: "local", OS: common.OSWindows}, expectedErr: "a local host cannot be a Windows host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_HostSpec(&tt.host, verrs, "spec.hosts[0]")
+			if tt.expectedErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !strings.Contains(verrs.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, verrs.Error())
+			}
+		})
+	}
+
+	spec := &ClusterSpec{
+		Hosts: []HostSpec{
+			{Name: "node1", Address: "10.0.0.10", Type: "local"},
+			{Name: "node2", Address: "10.0.0.11", Type: "local"},
+		},
+		RoleGroups: &RoleGroupsSpec{Master: []string{"node1"}, Etcd: []string{"node1"}, Worker: []string{"node2"}},
+	}
+	verrs := &validation.ValidationErrors{}
+	Validate_ClusterSpec(spec, verrs, "spec")
+	if !strings.Contains(verrs.Error(), "only one host can be local") {
+		t.Errorf("expected a second local host to be rejected, got %v", verrs.Error())
+	}
+}
+
+func TestValidate_GlobalSpec_MaxWorkers(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxWorkers int
+		expectErr  bool
+	}{
+		{name: "unset uses the default", maxWorkers: 0},
+		{name: "positive", maxWorkers: 4},
+		{name: "negative", maxWorkers: -1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &GlobalSpec{User: "kubexm", Port: 22, ConnectionTimeout: 30, WorkDir: "/tmp/kubexm", MaxWorkers: tt.maxWorkers}
+			verrs := &validation.ValidationErrors{}
+			Validate_GlobalSpec(spec, verrs, "spec.global")
+			hasErr := verrs.HasErrors() && strings.Contains(verrs.Error(), "spec.global.maxWorkers")
+			if hasErr != tt.expectErr {
+				t.Errorf("expected maxWorkers error=%v, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestRetrySpec_DefaultsAndValidation(t *testing.T) {
+	defaulted := &SystemSpec{Retry: &RetrySpec{}}
+	SetDefaults_SystemSpec(defaulted)
+	if defaulted.Retry.MaxAttempts != common.DefaultStepRetryAttempts || defaulted.Retry.BaseDelay != common.DefaultStepRetryBaseDelay || defaulted.Retry.MaxDelay != common.DefaultStepRetryMaxDelay {
+		t.Errorf("expected an empty retry section to get the defaults, got %+v", defaulted.Retry)
+	}
+
+	tests := []struct {
+		name      string
+		retry     RetrySpec
+		expectErr string
+	}{
+		{name: "valid", retry: RetrySpec{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second, RetryOn: []string{`ssh: .*EOF`}}},
+		{name: "no attempts", retry: RetrySpec{MaxAttempts: 0}, expectErr: "spec.system.retry.maxAttempts"},
+		{name: "negative delay", retry: RetrySpec{MaxAttempts: 2, BaseDelay: -time.Second}, expectErr: "spec.system.retry.baseDelay"},
+		{name: "max below base", retry: RetrySpec{MaxAttempts: 2, BaseDelay: 5 * time.Second, MaxDelay: time.Second}, expectErr: "spec.system.retry.maxDelay"},
+		{name: "invalid pattern", retry: RetrySpec{MaxAttempts: 2, RetryOn: []string{"("}}, expectErr: "spec.system.retry.retryOn[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_SystemSpec(&SystemSpec{Retry: &tt.retry}, verrs, "spec.system")
+			if tt.expectErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectErr) {
+				t.Errorf("expected an error for %s, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestSystemSpec_ValidateOSPreparation(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      SystemSpec
+		expectErr string
+	}{
+		{name: "valid", spec: SystemSpec{Modules: []string{"ip_vs", "nf-conntrack"}, SysctlParams: map[string]string{"vm.swappiness": "0"}, SELinux: "permissive"}},
+		{name: "invalid module", spec: SystemSpec{Modules: []string{"ip_vs; reboot"}}, expectErr: "spec.system.modules[0]"},
+		{name: "multiline sysctl value", spec: SystemSpec{SysctlParams: map[string]string{"vm.swappiness": "0\nkernel.panic = 1"}}, expectErr: "spec.system.sysctlParams['vm.swappiness']"},
+		{name: "invalid selinux mode", spec: SystemSpec{SELinux: "off"}, expectErr: "spec.system.selinux"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_SystemSpec(&tt.spec, verrs, "spec.system")
+			if tt.expectErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectErr) {
+				t.Errorf("expected an error for %s, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestIngressNginxSpec_DefaultsAndValidation(t *testing.T) {
+	port := func(p int32) *int32 { return &p }
+	tests := []struct {
+		name          string
+		spec          IngressNginxSpec
+		expectedHTTP  int32
+		expectedHTTPS int32
+		expectErr     string
+	}{
+		{name: "NodePort defaults to node ports", spec: IngressNginxSpec{}, expectedHTTP: 30080, expectedHTTPS: 30443},
+		{name: "HostNetwork defaults to standard ports", spec: IngressNginxSpec{ServiceType: "HostNetwork"}, expectedHTTP: 80, expectedHTTPS: 443},
+		{name: "unknown service type", spec: IngressNginxSpec{ServiceType: "ClusterIP"}, expectedHTTP: 80, expectedHTTPS: 443, expectErr: "serviceType"},
+		{name: "port out of range", spec: IngressNginxSpec{ServiceType: "LoadBalancer", HTTPPort: port(70000)}, expectedHTTP: 70000, expectedHTTPS: 443, expectErr: "httpPort"},
+		{name: "same ports", spec: IngressNginxSpec{HTTPPort: port(30443)}, expectedHTTP: 30443, expectedHTTPS: 30443, expectErr: "must differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			SetDefaults_IngressNginxSpec(&spec)
+			if *spec.HTTPPort != tt.expectedHTTP || *spec.HTTPSPort != tt.expectedHTTPS {
+				t.Errorf("expected ports %d/%d, got %d/%d", tt.expectedHTTP, tt.expectedHTTPS, *spec.HTTPPort, *spec.HTTPSPort)
+			}
+			verrs := &validation.ValidationErrors{}
+			Validate_IngressNginxSpec(&spec, verrs, "spec.gateway.ingressNginx")
+			if tt.expectErr == "" && verrs.HasErrors() {
+				t.Errorf("unexpected validation error: %v", verrs.Error())
+			}
+			if tt.expectErr != "" && !strings.Contains(verrs.Error(), tt.expectErr) {
+				t.Errorf("expected validation error containing %q, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestLocalRegistry_DefaultsAndValidation(t *testing.T) {
+	spec := &ClusterSpec{
+		Hosts: []HostSpec{
+			{Name: "node1", Address: "192.168.1.10", InternalAddress: "10.0.0.10", User: "root", Port: 22, Password: "secret"},
+		},
+		RoleGroups: &RoleGroupsSpec{Master: []string{"node1"}, Etcd: []string{"node1"}, Registry: []string{"node1"}},
+		Registry:   &Registry{Type: common.RegistryDeployTypeLocal},
+	}
+	SetDefaults_Registry(spec.Registry)
+	SetDefaults_LocalRegistryAddress(spec)
+
+	if got := spec.Registry.LocalDeployment.Type; got != common.RegistryTypeDockerRegistry {
+		t.Errorf("expected local deployment type %s, got %s", common.RegistryTypeDockerRegistry, got)
+	}
+	if got := spec.Registry.MirroringAndRewriting.PrivateRegistry; got != "10.0.0.10:5000" {
+		t.Errorf("expected private registry 10.0.0.10:5000, got %s", got)
+	}
+	if !spec.Registry.IsPlainHTTP("10.0.0.10:5000") || spec.Registry.IsPlainHTTP("docker.io") {
+		t.Error("expected only the local registry to be reached over plain HTTP")
+	}
+
+	verrs := &validation.ValidationErrors{}
+	validateLocalRegistryHost(spec, verrs, "spec.registry")
+	if verrs.HasErrors() {
+		t.Errorf("expected a valid local registry, got %v", verrs.Error())
+	}
+
+	spec.RoleGroups.Registry = []string{"node2"}
+	verrs = &validation.ValidationErrors{}
+	validateLocalRegistryHost(spec, verrs, "spec.registry")
+	if !strings.Contains(verrs.Error(), "must also be a master or worker") {
+		t.Errorf("expected a registry host role error, got %v", verrs.Error())
+	}
+
+	spec.Registry.LocalDeployment.Type = common.RegistryTypeHarbor
+	verrs = &validation.ValidationErrors{}
+	Validate_Registry(spec.Registry, verrs, "spec.registry")
+	if !strings.Contains(verrs.Error(), "spec.registry.local.type") {
+		t.Errorf("expected a local.type error for harbor, got %v", verrs.Error())
+	}
+}
@@ -14,6 +14,9 @@ type ContainerRuntime struct {
 	Containerd *Containerd                 `json:"containerd,omitempty" yaml:"containerd,omitempty"`
 	Crio       *Crio                       `json:"crio,omitempty" yaml:"crio,omitempty"`
 	Isulad     *Isulad                     `json:"isulad,omitempty" yaml:"isulad,omitempty"`
+	// ConfigBackupRetention is the number of timestamped backups kept whenever the runtime
+	// config file is rewritten. Zero disables backups.
+	ConfigBackupRetention *int `json:"configBackupRetention,omitempty" yaml:"configBackupRetention,omitempty"`
 }
 
 func SetDefaults_ContainerRuntimeConfig(cfg *ContainerRuntime) {
@@ -23,6 +26,13 @@ func SetDefaults_ContainerRuntimeConfig(cfg *ContainerRuntime) {
 	if cfg.Type == "" {
 		cfg.Type = common.RuntimeTypeContainerd
 	}
+	// "crio" is accepted as shorthand for "cri-o".
+	if cfg.Type == "crio" {
+		cfg.Type = common.RuntimeTypeCRIO
+	}
+	if cfg.ConfigBackupRetention == nil {
+		cfg.ConfigBackupRetention = helpers.IntPtr(common.DefaultConfigBackupRetention)
+	}
 
 	switch cfg.Type {
 	case common.RuntimeTypeDocker:
@@ -71,6 +81,9 @@ func Validate_ContainerRuntimeConfig(cfg *ContainerRuntime, verrs *validation.Va
 	if cfg.Version != "" && !helpers.IsValidSemanticVersion(cfg.Version) {
 		verrs.Add(pathPrefix+".version", "invalid version format")
 	}
+	if cfg.ConfigBackupRetention != nil && *cfg.ConfigBackupRetention < 0 {
+		verrs.Add(pathPrefix+".configBackupRetention", "must be non-negative")
+	}
 
 	switch cfg.Type {
 	case common.RuntimeTypeDocker:
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"encoding/base64"
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
@@ -23,11 +24,23 @@ type Containerd struct {
 	Root            *string             `json:"root,omitempty" yaml:"root,omitempty"`
 	State           *string             `json:"state,omitempty" yaml:"state,omitempty"`
 	Pause           string              `json:"pause,omitempty" yaml:"pause,omitempty"`
+	NvidiaRuntime   *NvidiaRuntime      `json:"nvidiaRuntime,omitempty" yaml:"nvidiaRuntime,omitempty"`
+}
+
+// NvidiaRuntime controls registration of the nvidia containerd runtime on nodes where an NVIDIA GPU is detected.
+type NvidiaRuntime struct {
+	Enabled      *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	SetAsDefault bool   `json:"setAsDefault,omitempty" yaml:"setAsDefault,omitempty"`
+	BinaryName   string `json:"binaryName,omitempty" yaml:"binaryName,omitempty"`
 }
 
 type ContainerdRegistry struct {
 	Mirrors map[ServerAddress]MirrorConfig `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
 	Configs map[ServerAddress]AuthConfig   `json:"configs,omitempty" yaml:"configs,omitempty"`
+	// ConfigPath switches containerd to the hosts.toml based registry setup: mirrors and TLS settings are
+	// written to <configPath>/<registry>/hosts.toml instead of the main config, e.g. /etc/containerd/certs.d.
+	// Containerd 2.0 and later use /etc/containerd/certs.d when it is left empty.
+	ConfigPath string `json:"configPath,omitempty" yaml:"configPath,omitempty"`
 }
 
 type MirrorConfig struct {
@@ -96,6 +109,15 @@ func SetDefaults_ContainerdConfig(cfg *Containerd) {
 	if cfg.Pause == "" {
 		cfg.Pause = common.DefaultPauseImage
 	}
+	if cfg.NvidiaRuntime == nil {
+		cfg.NvidiaRuntime = &NvidiaRuntime{}
+	}
+	if cfg.NvidiaRuntime.Enabled == nil {
+		cfg.NvidiaRuntime.Enabled = helpers.BoolPtr(true)
+	}
+	if cfg.NvidiaRuntime.BinaryName == "" {
+		cfg.NvidiaRuntime.BinaryName = common.DefaultNvidiaRuntimeBinary
+	}
 }
 
 func Validate_ContainerdConfig(cfg *Containerd, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -160,6 +182,12 @@ func Validate_ContainerdConfig(cfg *Containerd, verrs *validation.ValidationErro
 				}
 			}
 		}
+		if cfg.Registry.ConfigPath != "" && !strings.HasPrefix(cfg.Registry.ConfigPath, "/") {
+			verrs.Add(registryPath+".configPath", "must be an absolute path, got '"+cfg.Registry.ConfigPath+"'")
+		}
+		if cfg.Registry.ConfigPath != "" && cfg.Version != "" && !containerdVersionAtLeast(cfg.Version, common.ContainerdRegistryHostsMinVersion) {
+			verrs.Add(registryPath+".configPath", "requires containerd "+common.ContainerdRegistryHostsMinVersion+" or later, got '"+cfg.Version+"'")
+		}
 	}
 	if cfg.ConfigPath != nil && strings.TrimSpace(*cfg.ConfigPath) == "" {
 		verrs.Add(pathPrefix+".configPath", "cannot be empty if specified")
@@ -199,3 +227,31 @@ func Validate_ContainerdConfig(cfg *Containerd, verrs *validation.ValidationErro
 		}
 	}
 }
+
+// RegistryHostsDir returns the directory the per-registry hosts.toml files go to, or "" when mirrors
+// and TLS settings stay inline in config.toml. An explicit registry configPath always wins; without
+// one, containerd 2.0 and later use the default certs.d directory and older releases the inline layout.
+func (cfg *Containerd) RegistryHostsDir() string {
+	if cfg == nil {
+		return ""
+	}
+	if cfg.Registry != nil && cfg.Registry.ConfigPath != "" {
+		return cfg.Registry.ConfigPath
+	}
+	version := cfg.Version
+	if version == "" {
+		version = common.DefaultContainerdVersion
+	}
+	if containerdVersionAtLeast(version, common.ContainerdRegistryHostsDefaultVersion) {
+		return common.ContainerdDefaultCertsDir
+	}
+	return ""
+}
+
+func containerdVersionAtLeast(version, minimum string) bool {
+	v, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return false
+	}
+	return !v.LessThan(semver.MustParse(minimum))
+}
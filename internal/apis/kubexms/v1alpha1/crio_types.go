@@ -65,7 +65,7 @@ func SetDefaults_CrioConfig(cfg *Crio) {
 		cfg.ManageNetwork = helpers.BoolPtr(false)
 	}
 	if cfg.NetworkDir == nil {
-		cfg.NetworkDir = helpers.StrPtr(common.DefaultCNIBinDirTarget)
+		cfg.NetworkDir = helpers.StrPtr(common.DefaultCNIConfDirTarget)
 	}
 	if cfg.PluginDirs == nil {
 		cfg.PluginDirs = []string{common.DefaultCNIBinDirTarget}
@@ -122,7 +122,7 @@ func Validate_CrioConfig(cfg *Crio, verrs *validation.ValidationErrors, pathPref
 		}
 	}
 	if cfg.LogLevel != nil {
-		validLevels := []string{"fatal", "panic", "error", "warn", "info", "debug"}
+		validLevels := []string{"fatal", "panic", "error", "warn", "info", "debug", "trace"}
 		if !helpers.IsInStringSlice(validLevels, *cfg.LogLevel) {
 			verrs.Add(pathPrefix+".logLevel", "invalid log level")
 		}
@@ -21,7 +21,26 @@ type CoreDNS struct {
 	AdditionalConfigs  string                    `json:"additionalConfigs,omitempty" yaml:"additionalConfigs,omitempty"`
 	RewriteBlock       string                    `json:"rewriteBlock,omitempty" yaml:"rewriteBlock,omitempty"`
 	UpstreamForwarding *UpstreamForwardingConfig `json:"upstream,omitempty" yaml:"upstream,omitempty"`
-	ExternalZones      []ExternalZone            `json:"externalZones,omitempty" yaml:"externalZones,omitempty"`
+	// ExternalZones are the stub domains, resolved by their own nameservers instead of the upstream ones.
+	ExternalZones []ExternalZone `json:"externalZones,omitempty" yaml:"externalZones,omitempty"`
+	// Rewrite rules apply to the names queried in every zone served by the main server block.
+	Rewrite []RewriteRule `json:"rewrite,omitempty" yaml:"rewrite,omitempty"`
+	// Replicas is the fixed number of CoreDNS pods. Unset keeps the two of kubeadm; it cannot be
+	// combined with Autoscaler.
+	Replicas   *int               `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	Autoscaler *CoreDNSAutoscaler `json:"autoscaler,omitempty" yaml:"autoscaler,omitempty"`
+}
+
+// CoreDNSAutoscaler scales CoreDNS with the size of the cluster through cluster-proportional-autoscaler
+// in linear mode: one replica per CoresPerReplica cores or per NodesPerReplica nodes, whichever
+// needs more, between MinReplicas and MaxReplicas.
+type CoreDNSAutoscaler struct {
+	Enabled     *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	MinReplicas int   `json:"minReplicas,omitempty" yaml:"minReplicas,omitempty"`
+	// MaxReplicas of 0 leaves the number of replicas unbounded.
+	MaxReplicas     int `json:"maxReplicas,omitempty" yaml:"maxReplicas,omitempty"`
+	CoresPerReplica int `json:"coresPerReplica,omitempty" yaml:"coresPerReplica,omitempty"`
+	NodesPerReplica int `json:"nodesPerReplica,omitempty" yaml:"nodesPerReplica,omitempty"`
 }
 
 type UpstreamForwardingConfig struct {
@@ -80,6 +99,32 @@ func SetDefaults_CoreDNS(cfg *CoreDNS) {
 	for i := range cfg.ExternalZones {
 		SetDefaults_ExternalZone(&cfg.ExternalZones[i])
 	}
+
+	if cfg.Autoscaler == nil {
+		cfg.Autoscaler = &CoreDNSAutoscaler{}
+	}
+	SetDefaults_CoreDNSAutoscaler(cfg.Autoscaler)
+}
+
+func SetDefaults_CoreDNSAutoscaler(cfg *CoreDNSAutoscaler) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Enabled == nil {
+		cfg.Enabled = helpers.BoolPtr(false)
+	}
+	if !*cfg.Enabled {
+		return
+	}
+	if cfg.MinReplicas == 0 {
+		cfg.MinReplicas = common.DefaultCoreDNSReplicas
+	}
+	if cfg.CoresPerReplica == 0 {
+		cfg.CoresPerReplica = common.DefaultCoreDNSAutoscalerCoresPerReplica
+	}
+	if cfg.NodesPerReplica == 0 {
+		cfg.NodesPerReplica = common.DefaultCoreDNSAutoscalerNodesPerReplica
+	}
 }
 
 func SetDefaults_UpstreamForwardingConfig(cfg *UpstreamForwardingConfig) {
@@ -139,6 +184,22 @@ func SetDefaults_ExternalZone(cfg *ExternalZone) {
 	}
 }
 
+// NodeLocalDNSIP returns the address NodeLocal DNSCache listens on, which the kubelets hand to
+// the pods as their nameserver, or "" if NodeLocal DNSCache is not deployed.
+func NodeLocalDNSIP(spec *ClusterSpec) string {
+	if spec == nil || spec.DNS == nil || spec.DNS.NodeLocalDNS == nil {
+		return ""
+	}
+	nodeLocalDNS := spec.DNS.NodeLocalDNS
+	if nodeLocalDNS.Enabled == nil || !*nodeLocalDNS.Enabled {
+		return ""
+	}
+	if nodeLocalDNS.IP == "" {
+		return common.DefaultLocalDNS
+	}
+	return nodeLocalDNS.IP
+}
+
 func Validate_DNS(cfg *DNS, verrs *validation.ValidationErrors, pathPrefix string) {
 	if cfg == nil {
 		return
@@ -174,6 +235,40 @@ func Validate_CoreDNS(cfg *CoreDNS, verrs *validation.ValidationErrors, pathPref
 	for i, ez := range cfg.ExternalZones {
 		Validate_ExternalZone(&ez, verrs, fmt.Sprintf("%s.externalZones[%d]", p, i))
 	}
+
+	for i, rule := range cfg.Rewrite {
+		Validate_RewriteRule(&rule, verrs, fmt.Sprintf("%s.rewrite[%d]", p, i))
+	}
+
+	if cfg.Replicas != nil && *cfg.Replicas < 1 {
+		verrs.Add(fmt.Sprintf("%s.replicas: must be at least 1, got %d", p, *cfg.Replicas))
+	}
+	if cfg.Autoscaler != nil && cfg.Autoscaler.Enabled != nil && *cfg.Autoscaler.Enabled {
+		if cfg.Replicas != nil {
+			verrs.Add(p + ".replicas: cannot be set when the autoscaler is enabled")
+		}
+		Validate_CoreDNSAutoscaler(cfg.Autoscaler, verrs, path.Join(p, "autoscaler"))
+	}
+}
+
+func Validate_CoreDNSAutoscaler(cfg *CoreDNSAutoscaler, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg == nil {
+		return
+	}
+	p := path.Join(pathPrefix)
+
+	if cfg.MinReplicas < 1 {
+		verrs.Add(fmt.Sprintf("%s.minReplicas: must be at least 1, got %d", p, cfg.MinReplicas))
+	}
+	if cfg.MaxReplicas != 0 && cfg.MaxReplicas < cfg.MinReplicas {
+		verrs.Add(fmt.Sprintf("%s.maxReplicas: must be 0 or at least minReplicas (%d), got %d", p, cfg.MinReplicas, cfg.MaxReplicas))
+	}
+	if cfg.CoresPerReplica < 1 {
+		verrs.Add(fmt.Sprintf("%s.coresPerReplica: must be at least 1, got %d", p, cfg.CoresPerReplica))
+	}
+	if cfg.NodesPerReplica < 1 {
+		verrs.Add(fmt.Sprintf("%s.nodesPerReplica: must be at least 1, got %d", p, cfg.NodesPerReplica))
+	}
 }
 
 func Validate_UpstreamForwardingConfig(cfg *UpstreamForwardingConfig, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -211,6 +306,9 @@ func Validate_NodeLocalDNS(cfg *NodeLocalDNS, verrs *validation.ValidationErrors
 	p := path.Join(pathPrefix)
 
 	if cfg.Enabled != nil && *cfg.Enabled {
+		if cfg.IP != "" && !helpers.IsValidIP(cfg.IP) {
+			verrs.Add(fmt.Sprintf("%s.ip: invalid IP address '%s'", p, cfg.IP))
+		}
 		for i, ez := range cfg.ExternalZones {
 			Validate_ExternalZone(&ez, verrs, fmt.Sprintf("%s.externalZones[%d]", p, i))
 		}
@@ -248,12 +346,15 @@ func Validate_ExternalZone(cfg *ExternalZone, verrs *validation.ValidationErrors
 	}
 
 	for i, rule := range cfg.Rewrite {
-		rulePath := fmt.Sprintf("%s.rewrite[%d]", p, i)
-		if strings.TrimSpace(rule.FromPattern) == "" {
-			verrs.Add(rulePath + ".fromPattern: cannot be empty")
-		}
-		if strings.TrimSpace(rule.ToTemplate) == "" {
-			verrs.Add(rulePath + ".toTemplate: cannot be empty")
-		}
+		Validate_RewriteRule(&rule, verrs, fmt.Sprintf("%s.rewrite[%d]", p, i))
+	}
+}
+
+func Validate_RewriteRule(cfg *RewriteRule, verrs *validation.ValidationErrors, pathPrefix string) {
+	if strings.TrimSpace(cfg.FromPattern) == "" {
+		verrs.Add(pathPrefix + ".fromPattern: cannot be empty")
+	}
+	if strings.TrimSpace(cfg.ToTemplate) == "" {
+		verrs.Add(pathPrefix + ".toTemplate: cannot be empty")
 	}
 }
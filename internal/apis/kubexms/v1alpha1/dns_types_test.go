@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestNodeLocalDNSIP(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *ClusterSpec
+		want string
+	}{
+		{name: "no dns", spec: &ClusterSpec{}},
+		{name: "disabled", spec: &ClusterSpec{DNS: &DNS{NodeLocalDNS: &NodeLocalDNS{Enabled: helpers.BoolPtr(false), IP: "169.254.20.10"}}}},
+		{name: "default ip", spec: &ClusterSpec{DNS: &DNS{NodeLocalDNS: &NodeLocalDNS{Enabled: helpers.BoolPtr(true)}}}, want: common.DefaultLocalDNS},
+		{name: "custom ip", spec: &ClusterSpec{DNS: &DNS{NodeLocalDNS: &NodeLocalDNS{Enabled: helpers.BoolPtr(true), IP: "169.254.20.10"}}}, want: "169.254.20.10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NodeLocalDNSIP(tt.spec); got != tt.want {
+				t.Errorf("NodeLocalDNSIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvConfConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ResolvConfConfig
+		want string
+	}{
+		{name: "valid", cfg: ResolvConfConfig{Nameservers: []string{"10.0.0.2"}, Searches: []string{"corp.example.com"}, Options: []string{"timeout:1"}}},
+		{name: "too many nameservers", cfg: ResolvConfConfig{Nameservers: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}}, want: "at most 3 nameservers"},
+		{name: "invalid nameserver", cfg: ResolvConfConfig{Nameservers: []string{"dns.local"}}, want: "nameservers[0]"},
+		{name: "invalid option", cfg: ResolvConfConfig{Options: []string{"timeout:1 attempts:2"}}, want: "options[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_ResolvConfConfig(&tt.cfg, verrs, "spec.extra.resolves")
+			if tt.want == "" {
+				if verrs.HasErrors() {
+					t.Errorf("unexpected errors: %v", verrs)
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.want) {
+				t.Errorf("errors = %v, want one containing %q", verrs, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoreDNSAutoscaler_Defaults(t *testing.T) {
+	cfg := &CoreDNS{Autoscaler: &CoreDNSAutoscaler{Enabled: helpers.BoolPtr(true)}}
+	SetDefaults_CoreDNS(cfg)
+
+	a := cfg.Autoscaler
+	if a.MinReplicas != common.DefaultCoreDNSReplicas || a.MaxReplicas != 0 ||
+		a.CoresPerReplica != common.DefaultCoreDNSAutoscalerCoresPerReplica || a.NodesPerReplica != common.DefaultCoreDNSAutoscalerNodesPerReplica {
+		t.Errorf("autoscaler defaults = %+v", a)
+	}
+}
+
+func TestCoreDNS_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  CoreDNS
+		want string
+	}{
+		{name: "replicas", cfg: CoreDNS{Replicas: helpers.IntPtr(3)}},
+		{name: "autoscaler", cfg: CoreDNS{Autoscaler: &CoreDNSAutoscaler{Enabled: helpers.BoolPtr(true), MinReplicas: 2, MaxReplicas: 8, CoresPerReplica: 256, NodesPerReplica: 16}}},
+		{name: "zero replicas", cfg: CoreDNS{Replicas: helpers.IntPtr(0)}, want: "replicas: must be at least 1"},
+		{name: "replicas with autoscaler", cfg: CoreDNS{Replicas: helpers.IntPtr(3), Autoscaler: &CoreDNSAutoscaler{Enabled: helpers.BoolPtr(true), MinReplicas: 2, CoresPerReplica: 256, NodesPerReplica: 16}}, want: "cannot be set when the autoscaler is enabled"},
+		{name: "max below min", cfg: CoreDNS{Autoscaler: &CoreDNSAutoscaler{Enabled: helpers.BoolPtr(true), MinReplicas: 4, MaxReplicas: 2, CoresPerReplica: 256, NodesPerReplica: 16}}, want: "maxReplicas"},
+		{name: "empty rewrite", cfg: CoreDNS{Rewrite: []RewriteRule{{FromPattern: "a.example.com"}}}, want: "rewrite[0].toTemplate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_CoreDNS(&tt.cfg, verrs, "spec.dns.coredns")
+			if tt.want == "" {
+				if verrs.HasErrors() {
+					t.Errorf("unexpected errors: %v", verrs)
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.want) {
+				t.Errorf("errors = %v, want one containing %q", verrs, tt.want)
+			}
+		})
+	}
+}
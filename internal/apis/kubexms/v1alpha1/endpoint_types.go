@@ -15,6 +15,7 @@ type ControlPlaneEndpointSpec struct {
 	ExternalDNS              *bool                           `json:"externalDNS,omitempty" yaml:"externalDNS,omitempty"`
 	ExternalLoadBalancerType common.ExternalLoadBalancerType `json:"externalLoadBalancerType,omitempty" yaml:"externalLoadBalancer,omitempty"`
 	InternalLoadBalancerType common.InternalLoadBalancerType `json:"internalLoadBalancerType,omitempty" yaml:"internalLoadbalancer,omitempty"`
+	HAMode                   common.HAMode                   `json:"haMode,omitempty" yaml:"haMode,omitempty"`
 	HighAvailability         *HighAvailability               `json:"highAvailability,omitempty" yaml:"highAvailability,omitempty"`
 }
 
@@ -25,6 +26,14 @@ func SetDefaults_ControlPlaneEndpointSpec(cfg *ControlPlaneEndpointSpec) {
 	if cfg.Port == 0 {
 		cfg.Port = 6443
 	}
+	if cfg.ExternalLoadBalancerType == "" && cfg.InternalLoadBalancerType == "" {
+		switch cfg.HAMode {
+		case common.HAModeKubeVIP:
+			cfg.ExternalLoadBalancerType = common.ExternalLBTypeKubeVIP
+		case common.HAModeKeepalived:
+			cfg.ExternalLoadBalancerType = common.ExternalLBTypeKubexmKH
+		}
+	}
 	if cfg.ExternalLoadBalancerType == "" && cfg.InternalLoadBalancerType == "" {
 		cfg.InternalLoadBalancerType = common.InternalLBTypeHAProxy
 	}
@@ -66,6 +75,10 @@ func SetDefaults_ControlPlaneEndpointSpec(cfg *ControlPlaneEndpointSpec) {
 	}
 	if cfg.HighAvailability != nil {
 		SetDefaults_HighAvailabilityConfig(cfg.HighAvailability)
+		if ext := cfg.HighAvailability.External; ext != nil && ext.KubeVIP != nil &&
+			(ext.KubeVIP.VIP == nil || *ext.KubeVIP.VIP == "") && cfg.Address != "" {
+			ext.KubeVIP.VIP = helpers.StrPtr(cfg.Address)
+		}
 	}
 }
 
@@ -113,10 +126,57 @@ func Validate_ControlPlaneEndpointSpec(cfg *ControlPlaneEndpointSpec, verrs *val
 			pathPrefix, cfg.InternalLoadBalancerType, common.SupportedInternalLoadBalancerTypes))
 	}
 
-	if *cfg.HighAvailability.Enabled && *cfg.HighAvailability.External.Enabled && cfg.Address == "" {
+	if cfg.HAMode != "" {
+		Validate_HAMode(cfg, verrs, pathPrefix)
+	}
+
+	if cfg.HighAvailability != nil && cfg.HighAvailability.Enabled != nil && *cfg.HighAvailability.Enabled &&
+		cfg.HighAvailability.External != nil && cfg.HighAvailability.External.Enabled != nil &&
+		*cfg.HighAvailability.External.Enabled && cfg.Address == "" {
 		verrs.Add(fmt.Sprintf("%s.highAvailability.external.enabled and address cannot be empty", pathPrefix))
 	}
 	if cfg.ExternalDNS == helpers.BoolPtr(true) && cfg.Address != "" {
 		verrs.Add(fmt.Sprintf("%s.externalDNS: cannot be true when address is provided", pathPrefix))
 	}
 }
+
+// Validate_HAMode checks that haMode agrees with the load balancer types, so that exactly one
+// HA provider ends up enabled for the control plane endpoint.
+func Validate_HAMode(cfg *ControlPlaneEndpointSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	path := pathPrefix + ".haMode"
+	if !helpers.ContainsString(common.SupportedHAModes, string(cfg.HAMode)) {
+		verrs.Add(fmt.Sprintf("%s: invalid mode '%s', must be one of %v", path, cfg.HAMode, common.SupportedHAModes))
+		return
+	}
+	if cfg.InternalLoadBalancerType != "" {
+		verrs.Add(fmt.Sprintf("%s: '%s' conflicts with internalLoadBalancerType '%s', only one HA provider can be enabled",
+			path, cfg.HAMode, cfg.InternalLoadBalancerType))
+	}
+	if strings.TrimSpace(cfg.Address) == "" {
+		verrs.Add(fmt.Sprintf("%s: address must be specified as the VIP of '%s'", path, cfg.HAMode))
+	}
+
+	var ext *ExternalLoadBalancerConfig
+	if cfg.HighAvailability != nil {
+		ext = cfg.HighAvailability.External
+	}
+	switch cfg.HAMode {
+	case common.HAModeKubeVIP:
+		if cfg.ExternalLoadBalancerType != "" && cfg.ExternalLoadBalancerType != common.ExternalLBTypeKubeVIP {
+			verrs.Add(fmt.Sprintf("%s: 'kube-vip' conflicts with externalLoadBalancerType '%s', only one HA provider can be enabled",
+				path, cfg.ExternalLoadBalancerType))
+		}
+		if ext != nil && (ext.Keepalived != nil || ext.HAProxy != nil || ext.NginxLB != nil) {
+			verrs.Add(fmt.Sprintf("%s: keepalived, haproxy and nginxLB must not be configured when haMode is 'kube-vip'", path))
+		}
+	case common.HAModeKeepalived:
+		if cfg.ExternalLoadBalancerType != "" && cfg.ExternalLoadBalancerType != common.ExternalLBTypeKubexmKH &&
+			cfg.ExternalLoadBalancerType != common.ExternalLBTypeKubexmKN {
+			verrs.Add(fmt.Sprintf("%s: 'keepalived' conflicts with externalLoadBalancerType '%s', only one HA provider can be enabled",
+				path, cfg.ExternalLoadBalancerType))
+		}
+		if ext != nil && ext.KubeVIP != nil {
+			verrs.Add(fmt.Sprintf("%s: kubevip must not be configured when haMode is 'keepalived'", path))
+		}
+	}
+}
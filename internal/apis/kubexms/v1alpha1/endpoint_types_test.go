@@ -0,0 +1,88 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestSetDefaults_ControlPlaneEndpointSpec_HAMode(t *testing.T) {
+	cfg := &ControlPlaneEndpointSpec{HAMode: common.HAModeKubeVIP, Address: "10.0.0.100"}
+	SetDefaults_ControlPlaneEndpointSpec(cfg)
+
+	if cfg.ExternalLoadBalancerType != common.ExternalLBTypeKubeVIP || cfg.InternalLoadBalancerType != "" {
+		t.Fatalf("expected kube-vip as the only provider, got external=%q internal=%q", cfg.ExternalLoadBalancerType, cfg.InternalLoadBalancerType)
+	}
+	ext := cfg.HighAvailability.External
+	if ext == nil || !*ext.Enabled || ext.Type != string(common.ExternalLBTypeKubeVIP) || ext.KubeVIP == nil {
+		t.Fatalf("expected kube-vip external HA to be enabled, got %+v", ext)
+	}
+	if *ext.KubeVIP.VIP != "10.0.0.100" || *ext.KubeVIP.Mode != common.KubeVIPModeARP {
+		t.Errorf("unexpected kube-vip defaults: vip=%s mode=%s", *ext.KubeVIP.VIP, *ext.KubeVIP.Mode)
+	}
+
+	verrs := &validation.ValidationErrors{}
+	Validate_ControlPlaneEndpointSpec(cfg, verrs, "spec.controlPlaneEndpoint")
+	Validate_HighAvailabilityConfig(cfg.HighAvailability, verrs, "spec.controlPlaneEndpoint.highAvailability")
+	if verrs.HasErrors() {
+		t.Errorf("expected a valid kube-vip endpoint, got %v", verrs.Error())
+	}
+
+	keepalived := &ControlPlaneEndpointSpec{HAMode: common.HAModeKeepalived, Address: "10.0.0.100"}
+	SetDefaults_ControlPlaneEndpointSpec(keepalived)
+	if keepalived.ExternalLoadBalancerType != common.ExternalLBTypeKubexmKH || keepalived.HighAvailability.External.Keepalived == nil {
+		t.Errorf("expected haMode keepalived to select kubexm-kh, got %q", keepalived.ExternalLoadBalancerType)
+	}
+}
+
+func TestValidate_ControlPlaneEndpointSpec_HAModeConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ControlPlaneEndpointSpec
+		wantErr string
+	}{
+		{
+			name:    "unknown mode",
+			cfg:     ControlPlaneEndpointSpec{HAMode: "vrrp", Address: "10.0.0.100"},
+			wantErr: "invalid mode 'vrrp'",
+		},
+		{
+			name:    "kube-vip with an internal load balancer",
+			cfg:     ControlPlaneEndpointSpec{HAMode: common.HAModeKubeVIP, Address: "10.0.0.100", InternalLoadBalancerType: common.InternalLBTypeHAProxy},
+			wantErr: "only one HA provider can be enabled",
+		},
+		{
+			name:    "kube-vip with keepalived external load balancer",
+			cfg:     ControlPlaneEndpointSpec{HAMode: common.HAModeKubeVIP, Address: "10.0.0.100", ExternalLoadBalancerType: common.ExternalLBTypeKubexmKH},
+			wantErr: "only one HA provider can be enabled",
+		},
+		{
+			name: "kube-vip with keepalived settings",
+			cfg: ControlPlaneEndpointSpec{HAMode: common.HAModeKubeVIP, Address: "10.0.0.100",
+				HighAvailability: &HighAvailability{External: &ExternalLoadBalancerConfig{Keepalived: &KeepalivedConfig{}}}},
+			wantErr: "must not be configured when haMode is 'kube-vip'",
+		},
+		{
+			name:    "keepalived with kube-vip external load balancer",
+			cfg:     ControlPlaneEndpointSpec{HAMode: common.HAModeKeepalived, Address: "10.0.0.100", ExternalLoadBalancerType: common.ExternalLBTypeKubeVIP},
+			wantErr: "only one HA provider can be enabled",
+		},
+		{
+			name:    "kube-vip without a VIP",
+			cfg:     ControlPlaneEndpointSpec{HAMode: common.HAModeKubeVIP},
+			wantErr: "address must be specified as the VIP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_HAMode(&tt.cfg, verrs, "spec.controlPlaneEndpoint")
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, verrs.Error())
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
+	"net/url"
 	"path"
 	"strings"
 )
@@ -18,6 +19,9 @@ type Etcd struct {
 	PerformanceTuning *EtcdPerformanceTuning `json:"performance,omitempty" yaml:"performance,omitempty"`
 }
 
+// ExternalEtcdConfig points the cluster at an etcd cluster that kubexm does not manage.
+// CAFile, CertFile and KeyFile are paths on the control machine; the files are copied
+// to every master under common.DefaultExternalEtcdPKIDir.
 type ExternalEtcdConfig struct {
 	Endpoints []string `json:"endpoints" yaml:"endpoints"`
 	CAFile    string   `json:"caFile,omitempty" yaml:"caFile,omitempty"`
@@ -25,6 +29,24 @@ type ExternalEtcdConfig struct {
 	KeyFile   string   `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
 }
 
+// RemoteCertPaths returns where the CA, client certificate and client key are placed on the
+// masters. A path is empty when the corresponding file is not configured.
+func (c *ExternalEtcdConfig) RemoteCertPaths() (caFile, certFile, keyFile string) {
+	if c == nil {
+		return "", "", ""
+	}
+	if c.CAFile != "" {
+		caFile = path.Join(common.DefaultExternalEtcdPKIDir, common.ExternalEtcdCaFileName)
+	}
+	if c.CertFile != "" {
+		certFile = path.Join(common.DefaultExternalEtcdPKIDir, common.ExternalEtcdClientCertFileName)
+	}
+	if c.KeyFile != "" {
+		keyFile = path.Join(common.DefaultExternalEtcdPKIDir, common.ExternalEtcdClientKeyFileName)
+	}
+	return caFile, certFile, keyFile
+}
+
 type EtcdClusterConfig struct {
 	ClientPort   *int              `json:"clientPort,omitempty" yaml:"clientPort,omitempty"`
 	PeerPort     *int              `json:"peerPort,omitempty" yaml:"peerPort,omitempty"`
@@ -197,6 +219,11 @@ func Validate_ExternalEtcdConfig(cfg *ExternalEtcdConfig, verrs *validation.Vali
 	for i, ep := range cfg.Endpoints {
 		if strings.TrimSpace(ep) == "" {
 			verrs.Add(fmt.Sprintf("%s.endpoints[%d]: endpoint cannot be empty", pathPrefix, i))
+			continue
+		}
+		u, err := url.Parse(ep)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			verrs.Add(fmt.Sprintf("%s.endpoints[%d]: invalid endpoint '%s', must be an http:// or https:// URL", pathPrefix, i, ep))
 		}
 	}
 	// Validate mTLS configuration
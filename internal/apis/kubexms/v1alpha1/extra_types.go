@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"fmt"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"net"
 	"path"
@@ -65,7 +66,7 @@ func Validate_Extra(cfg *Extra, verrs *validation.ValidationErrors, pathPrefix s
 	}
 
 	if cfg.ResolvConf != nil {
-		Validate_ResolvConfConfig(cfg.ResolvConf, verrs, path.Join(p, "resolvConf"))
+		Validate_ResolvConfConfig(cfg.ResolvConf, verrs, path.Join(p, "resolves"))
 	}
 }
 
@@ -94,6 +95,11 @@ func Validate_ResolvConfConfig(cfg *ResolvConfConfig, verrs *validation.Validati
 	if cfg == nil {
 		return
 	}
+	// The resolver of glibc only uses the first three nameservers.
+	if len(cfg.Nameservers) > common.MaxResolvConfNameservers {
+		verrs.Add(fmt.Sprintf("%s.nameservers: at most %d nameservers are used by the resolver, got %d",
+			pathPrefix, common.MaxResolvConfNameservers, len(cfg.Nameservers)))
+	}
 	for i, ns := range cfg.Nameservers {
 		if strings.TrimSpace(ns) == "" {
 			verrs.Add(fmt.Sprintf("%s.nameservers[%d]: nameserver cannot be empty", pathPrefix, i))
@@ -102,6 +108,12 @@ func Validate_ResolvConfConfig(cfg *ResolvConfConfig, verrs *validation.Validati
 		}
 	}
 
+	for i, option := range cfg.Options {
+		if strings.TrimSpace(option) == "" || strings.ContainsAny(option, " \t\n") {
+			verrs.Add(fmt.Sprintf("%s.options[%d]: invalid resolver option '%s'", pathPrefix, i, option))
+		}
+	}
+
 	for i, search := range cfg.Searches {
 		if strings.TrimSpace(search) == "" {
 			verrs.Add(fmt.Sprintf("%s.searches[%d]: search domain cannot be empty", pathPrefix, i))
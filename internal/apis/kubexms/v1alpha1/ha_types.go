@@ -181,6 +181,9 @@ func Validate_ExternalLoadBalancerConfig(cfg *ExternalLoadBalancerConfig, verrs
 		if cfg.NginxLB != nil {
 			verrs.Add(pathPrefix+".nginxLB", "must not be configured for the selected type")
 		}
+		if cfg.KubeVIP != nil {
+			verrs.Add(pathPrefix+".kubevip", "must not be configured for the selected type")
+		}
 	case string(common.ExternalLBTypeKubexmKN):
 		if cfg.Keepalived == nil {
 			verrs.Add(pathPrefix+".keepalived", "must be configured for the selected type")
@@ -195,19 +198,26 @@ func Validate_ExternalLoadBalancerConfig(cfg *ExternalLoadBalancerConfig, verrs
 		if cfg.HAProxy != nil {
 			verrs.Add(pathPrefix+".haproxy", "must not be configured for the selected type")
 		}
+		if cfg.KubeVIP != nil {
+			verrs.Add(pathPrefix+".kubevip", "must not be configured for the selected type")
+		}
 	case string(common.ExternalLBTypeKubeVIP):
 		if cfg.KubeVIP == nil {
 			verrs.Add(pathPrefix+".kubevip", "must be configured for the selected type")
 		} else {
 			Validate_KubeVIPConfig(cfg.KubeVIP, verrs, pathPrefix+".kubevip")
 		}
+		if cfg.Keepalived != nil || cfg.HAProxy != nil || cfg.NginxLB != nil {
+			verrs.Add(pathPrefix, "keepalived, haproxy and nginxLB must not be configured when kube-vip is the HA provider")
+		}
 	case string(common.ExternalLBTypeExternal):
 		if cfg.Keepalived != nil || cfg.HAProxy != nil || cfg.NginxLB != nil {
 			verrs.Add(pathPrefix, "no specific LB configurations (keepalived, haproxy, nginxLB) should be provided when type is 'UserProvided'")
 		}
 	}
 
-	if cfg.Type != string(common.ExternalLBTypeExternal) {
+	// kube-vip runs on the masters and needs no dedicated loadbalancer hosts.
+	if cfg.Type != string(common.ExternalLBTypeExternal) && cfg.Type != string(common.ExternalLBTypeKubeVIP) {
 		if cfg.LoadBalancerHostGroupName == nil || !helpers.IsValidNonEmptyString(*cfg.LoadBalancerHostGroupName) {
 			verrs.Add(pathPrefix+".loadBalancerHostGroupName", "must be specified for managed external load balancers")
 		}
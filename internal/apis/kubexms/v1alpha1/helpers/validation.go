@@ -3,6 +3,7 @@ package helpers
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"gopkg.in/yaml.v3"
@@ -211,6 +212,38 @@ func IsValidCIDR(cidr string) bool {
 	return err == nil
 }
 
+// SplitCIDRs splits a comma-separated CIDR list such as a dual-stack pod or service range.
+func SplitCIDRs(value string) []string {
+	var cidrs []string
+	for _, raw := range strings.Split(value, ",") {
+		if cidr := strings.TrimSpace(raw); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
+
+// ParseDualStackCIDRs parses a single CIDR or a dual-stack pair such as "10.244.0.0/16,fd00:10:244::/56".
+// A pair must contain one IPv4 and one IPv6 CIDR; the first one determines the primary IP family.
+func ParseDualStackCIDRs(value string) ([]*net.IPNet, error) {
+	cidrs := SplitCIDRs(value)
+	if len(cidrs) == 0 || len(cidrs) > 2 {
+		return nil, fmt.Errorf("expected one CIDR or a comma-separated IPv4/IPv6 pair, got '%s'", value)
+	}
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR format '%s'", cidr)
+		}
+		nets = append(nets, ipNet)
+	}
+	if len(nets) == 2 && (nets[0].IP.To4() != nil) == (nets[1].IP.To4() != nil) {
+		return nil, fmt.Errorf("dual-stack CIDRs '%s' must contain one IPv4 and one IPv6 CIDR", value)
+	}
+	return nets, nil
+}
+
 func IsValidPort(port int) bool {
 	return port >= 1 && port <= 65535
 }
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// Hook runs a local command or calls a webhook when the engine reports one of Events. Hooks run
+// on the machine running kubexm, one at a time in the order of the events; a failing hook is
+// logged and never fails the run.
+type Hook struct {
+	Name string `json:"name" yaml:"name"`
+	// Events lists the event types that trigger the hook, such as NodeFinished or GraphFinished.
+	// Defaults to GraphFinished.
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+	// Status, if set, limits the hook to events with one of these statuses, e.g. Failed.
+	Status []string `json:"status,omitempty" yaml:"status,omitempty"`
+	// Command runs through "sh -c" with the event as JSON on stdin and in KUBEXM_EVENT_* variables.
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Webhook receives the event as JSON in a POST request.
+	Webhook *WebhookSpec `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	// Timeout bounds a single run of the command or call of the webhook.
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type WebhookSpec struct {
+	URL     string            `json:"url" yaml:"url"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+func SetDefaults_Hook(cfg *Hook) {
+	if len(cfg.Events) == 0 {
+		cfg.Events = []string{common.HookEventGraphFinished}
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = common.DefaultHookTimeout
+	}
+}
+
+func Validate_Hooks(hooks []Hook, verrs *validation.ValidationErrors, pathPrefix string) {
+	seen := make(map[string]bool)
+	for i := range hooks {
+		p := fmt.Sprintf("%s[%d]", pathPrefix, i)
+		Validate_Hook(&hooks[i], verrs, p)
+		if name := hooks[i].Name; name != "" {
+			if seen[name] {
+				verrs.Add(fmt.Sprintf("%s.name: hook '%s' is listed more than once", p, name))
+			}
+			seen[name] = true
+		}
+	}
+}
+
+func Validate_Hook(cfg *Hook, verrs *validation.ValidationErrors, pathPrefix string) {
+	if strings.TrimSpace(cfg.Name) == "" {
+		verrs.Add(pathPrefix + ".name: is a required field")
+	}
+	for i, event := range cfg.Events {
+		if !helpers.ContainsString(common.SupportedHookEvents, event) {
+			verrs.Add(fmt.Sprintf("%s.events[%d]: unsupported event '%s', must be one of %v", pathPrefix, i, event, common.SupportedHookEvents))
+		}
+	}
+	if cfg.Timeout < 0 {
+		verrs.Add(fmt.Sprintf("%s.timeout: must not be negative, got %v", pathPrefix, cfg.Timeout))
+	}
+	hasCommand := strings.TrimSpace(cfg.Command) != ""
+	if hasCommand == (cfg.Webhook != nil) {
+		verrs.Add(pathPrefix + ": exactly one of command or webhook must be set")
+		return
+	}
+	if cfg.Webhook != nil {
+		u, err := url.Parse(cfg.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			verrs.Add(fmt.Sprintf("%s.webhook.url: '%s' must be an absolute http or https URL", pathPrefix, cfg.Webhook.URL))
+		}
+	}
+}
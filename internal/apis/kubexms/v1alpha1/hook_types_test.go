@@ -0,0 +1,47 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestHook_DefaultsAndValidation(t *testing.T) {
+	defaulted := &Hook{Name: "notify", Command: "true"}
+	SetDefaults_Hook(defaulted)
+	if len(defaulted.Events) != 1 || defaulted.Events[0] != common.HookEventGraphFinished || defaulted.Timeout != common.DefaultHookTimeout {
+		t.Errorf("expected a hook to default to GraphFinished and the default timeout, got %+v", defaulted)
+	}
+
+	tests := []struct {
+		name      string
+		hooks     []Hook
+		expectErr string
+	}{
+		{name: "command", hooks: []Hook{{Name: "a", Events: []string{"NodeFinished"}, Command: "echo done"}}},
+		{name: "webhook", hooks: []Hook{{Name: "a", Webhook: &WebhookSpec{URL: "https://hooks.example.com/kubexm"}}}},
+		{name: "no name", hooks: []Hook{{Command: "true"}}, expectErr: "spec.hooks[0].name"},
+		{name: "unknown event", hooks: []Hook{{Name: "a", Events: []string{"NodeExploded"}, Command: "true"}}, expectErr: "spec.hooks[0].events[0]"},
+		{name: "nothing to run", hooks: []Hook{{Name: "a"}}, expectErr: "exactly one of command or webhook"},
+		{name: "both", hooks: []Hook{{Name: "a", Command: "true", Webhook: &WebhookSpec{URL: "https://example.com"}}}, expectErr: "exactly one of command or webhook"},
+		{name: "bad url", hooks: []Hook{{Name: "a", Webhook: &WebhookSpec{URL: "example.com/hook"}}}, expectErr: "spec.hooks[0].webhook.url"},
+		{name: "duplicate", hooks: []Hook{{Name: "a", Command: "true"}, {Name: "a", Command: "true"}}, expectErr: "spec.hooks[1].name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_Hooks(tt.hooks, verrs, "spec.hooks")
+			if tt.expectErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectErr) {
+				t.Errorf("expected an error for %s, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// Infrastructure describes machines kubexm creates before it installs the cluster on them.
+// At most one provider can be configured.
+type Infrastructure struct {
+	Libvirt   *LibvirtInfrastructure   `json:"libvirt,omitempty" yaml:"libvirt,omitempty"`
+	OpenStack *OpenStackInfrastructure `json:"openstack,omitempty" yaml:"openstack,omitempty"`
+}
+
+// LibvirtInfrastructure makes `kubexm vm provision` create the cluster hosts as VMs on libvirt
+// hypervisors. Each VM boots a copy-on-write disk backed by Image and gets a cloud-init seed
+// creating User with the SSH keys; once sshd answers, its address is written to a hosts file
+// that is merged with the cluster configuration. Roles are given in spec.roleGroups by VM name.
+type LibvirtInfrastructure struct {
+	// Hypervisors are the libvirt hosts, reached over SSH like cluster hosts.
+	Hypervisors []HostSpec `json:"hypervisors" yaml:"hypervisors"`
+	// Pool is the storage pool holding Image and the VM disks.
+	Pool string `json:"pool,omitempty" yaml:"pool,omitempty"`
+	// Image is the volume of Pool, a cloud image with cloud-init, the VM disks are backed by.
+	Image       string `json:"image" yaml:"image"`
+	ImageFormat string `json:"imageFormat,omitempty" yaml:"imageFormat,omitempty"`
+	// OSVariant is the osinfo id of Image, such as ubuntu22.04.
+	OSVariant string `json:"osVariant,omitempty" yaml:"osVariant,omitempty"`
+	// Network is the libvirt network the VMs are attached to, unless Bridge names a host bridge.
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+	Bridge  string `json:"bridge,omitempty" yaml:"bridge,omitempty"`
+	// User is created on the VMs and used by kubexm to log in with PrivateKeyPath.
+	User           string `json:"user,omitempty" yaml:"user,omitempty"`
+	PrivateKeyPath string `json:"privateKeyPath,omitempty" yaml:"privateKeyPath,omitempty"`
+	// SSHAuthorizedKeys are the public keys authorized for User; the ".pub" file next to
+	// PrivateKeyPath is used when none are given.
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty" yaml:"sshAuthorizedKeys,omitempty"`
+	// JumpThroughHypervisor makes each VM reached through its hypervisor as a bastion. It
+	// defaults to true on a libvirt network, whose NAT addresses are only routable from the
+	// hypervisor, and false on a bridge.
+	JumpThroughHypervisor *bool `json:"jumpThroughHypervisor,omitempty" yaml:"jumpThroughHypervisor,omitempty"`
+	// WaitTimeout bounds how long a VM may take to get an address and start sshd.
+	WaitTimeout time.Duration `json:"waitTimeout,omitempty" yaml:"waitTimeout,omitempty"`
+	VMs         []LibvirtVM   `json:"vms" yaml:"vms"`
+}
+
+type LibvirtVM struct {
+	// Name may be a range such as worker[1:3], which creates one VM per name.
+	Name string `json:"name" yaml:"name"`
+	// Hypervisor is the name of the hypervisor the VM runs on, the first one by default.
+	Hypervisor string `json:"hypervisor,omitempty" yaml:"hypervisor,omitempty"`
+	VCPUs      uint   `json:"vcpus,omitempty" yaml:"vcpus,omitempty"`
+	MemoryMB   uint   `json:"memoryMB,omitempty" yaml:"memoryMB,omitempty"`
+	DiskSizeGB uint   `json:"diskSizeGB,omitempty" yaml:"diskSizeGB,omitempty"`
+}
+
+// OpenStackInfrastructure makes `kubexm infra provision` create the cluster hosts as servers on
+// an OpenStack cloud. The servers boot Image with Flavor on Network and the key pair KeyPair; once
+// they are active, their addresses are written to a hosts file that is merged with the cluster
+// configuration. Roles are given in spec.roleGroups by machine name.
+type OpenStackInfrastructure struct {
+	// AuthURL is the Keystone endpoint. It and the credentials below default to the
+	// OS_AUTH_URL, OS_REGION_NAME, OS_PROJECT_NAME, OS_PROJECT_DOMAIN_NAME, OS_USERNAME,
+	// OS_USER_DOMAIN_NAME and OS_PASSWORD environment variables of an openrc file when unset.
+	AuthURL           string `json:"authURL,omitempty" yaml:"authURL,omitempty"`
+	Region            string `json:"region,omitempty" yaml:"region,omitempty"`
+	ProjectName       string `json:"projectName,omitempty" yaml:"projectName,omitempty"`
+	ProjectDomainName string `json:"projectDomainName,omitempty" yaml:"projectDomainName,omitempty"`
+	UserName          string `json:"userName,omitempty" yaml:"userName,omitempty"`
+	UserDomainName    string `json:"userDomainName,omitempty" yaml:"userDomainName,omitempty"`
+	Password          string `json:"password,omitempty" yaml:"password,omitempty"`
+	// Image, Flavor and Network are given by name or ID.
+	Image          string   `json:"image" yaml:"image"`
+	Flavor         string   `json:"flavor" yaml:"flavor"`
+	Network        string   `json:"network" yaml:"network"`
+	KeyPair        string   `json:"keyPair" yaml:"keyPair"`
+	SecurityGroups []string `json:"securityGroups,omitempty" yaml:"securityGroups,omitempty"`
+	// User is the login user of Image, authorized with the private key of KeyPair at
+	// PrivateKeyPath.
+	User           string `json:"user,omitempty" yaml:"user,omitempty"`
+	PrivateKeyPath string `json:"privateKeyPath,omitempty" yaml:"privateKeyPath,omitempty"`
+	// Bastion is set on the hosts written for the servers, whose fixed addresses are usually
+	// only reachable from inside the cloud. Servers with a floating IP are reached at it instead.
+	Bastion *BastionSpec `json:"bastion,omitempty" yaml:"bastion,omitempty"`
+	// WaitTimeout bounds how long a server may take to become active.
+	WaitTimeout time.Duration      `json:"waitTimeout,omitempty" yaml:"waitTimeout,omitempty"`
+	Machines    []OpenStackMachine `json:"machines" yaml:"machines"`
+}
+
+type OpenStackMachine struct {
+	// Name may be a range such as worker[1:3], which creates one server per name.
+	Name string `json:"name" yaml:"name"`
+	// Flavor overrides the flavor of the provider for this machine.
+	Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+}
+
+func SetDefaults_Infrastructure(cfg *Infrastructure, cluster *Cluster) {
+	if cfg.Libvirt != nil {
+		SetDefaults_LibvirtInfrastructure(cfg.Libvirt, cluster)
+	}
+	if cfg.OpenStack != nil {
+		SetDefaults_OpenStackInfrastructure(cfg.OpenStack, cluster)
+	}
+}
+
+func SetDefaults_LibvirtInfrastructure(cfg *LibvirtInfrastructure, cluster *Cluster) {
+	for i := range cfg.Hypervisors {
+		SetDefaults_HostSpec(&cfg.Hypervisors[i], cluster)
+	}
+	if cfg.Pool == "" {
+		cfg.Pool = common.DefaultLibvirtPool
+	}
+	if cfg.ImageFormat == "" {
+		cfg.ImageFormat = common.DefaultLibvirtImageFormat
+	}
+	if cfg.Network == "" && cfg.Bridge == "" {
+		cfg.Network = common.DefaultLibvirtNetwork
+	}
+	if cfg.User == "" {
+		cfg.User = cluster.Spec.Global.User
+	}
+	if cfg.PrivateKeyPath == "" {
+		cfg.PrivateKeyPath = cluster.Spec.Global.PrivateKeyPath
+	}
+	if cfg.JumpThroughHypervisor == nil {
+		cfg.JumpThroughHypervisor = helpers.BoolPtr(cfg.Bridge == "")
+	}
+	if cfg.WaitTimeout == 0 {
+		cfg.WaitTimeout = common.DefaultLibvirtWaitTimeout
+	}
+	for i := range cfg.VMs {
+		vm := &cfg.VMs[i]
+		if vm.Hypervisor == "" && len(cfg.Hypervisors) > 0 {
+			vm.Hypervisor = cfg.Hypervisors[0].Name
+		}
+		if vm.VCPUs == 0 {
+			vm.VCPUs = common.DefaultLibvirtVMVCPUs
+		}
+		if vm.MemoryMB == 0 {
+			vm.MemoryMB = common.DefaultLibvirtVMMemoryMB
+		}
+		if vm.DiskSizeGB == 0 {
+			vm.DiskSizeGB = common.DefaultLibvirtVMDiskGB
+		}
+	}
+}
+
+// ExpandVMs returns one VM per name, with the ranges in the VM names expanded.
+func (cfg *LibvirtInfrastructure) ExpandVMs() ([]LibvirtVM, error) {
+	var vms []LibvirtVM
+	for _, vm := range cfg.VMs {
+		names, err := helpers.ExpandHostRange(vm.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VM name '%s': %w", vm.Name, err)
+		}
+		for _, name := range names {
+			expanded := vm
+			expanded.Name = name
+			vms = append(vms, expanded)
+		}
+	}
+	return vms, nil
+}
+
+func SetDefaults_OpenStackInfrastructure(cfg *OpenStackInfrastructure, cluster *Cluster) {
+	if cfg.User == "" {
+		cfg.User = cluster.Spec.Global.User
+	}
+	if cfg.PrivateKeyPath == "" {
+		cfg.PrivateKeyPath = cluster.Spec.Global.PrivateKeyPath
+	}
+	if cfg.Bastion != nil {
+		SetDefaults_BastionSpec(cfg.Bastion, &HostSpec{
+			User:           cluster.Spec.Global.User,
+			Password:       cluster.Spec.Global.Password,
+			PrivateKey:     cluster.Spec.Global.PrivateKey,
+			PrivateKeyPath: cluster.Spec.Global.PrivateKeyPath,
+		})
+	}
+	if cfg.WaitTimeout == 0 {
+		cfg.WaitTimeout = common.DefaultOpenStackWaitTimeout
+	}
+	for i := range cfg.Machines {
+		if cfg.Machines[i].Flavor == "" {
+			cfg.Machines[i].Flavor = cfg.Flavor
+		}
+	}
+}
+
+// ExpandMachines returns one machine per name, with the ranges in the machine names expanded.
+func (cfg *OpenStackInfrastructure) ExpandMachines() ([]OpenStackMachine, error) {
+	var machines []OpenStackMachine
+	for _, m := range cfg.Machines {
+		names, err := helpers.ExpandHostRange(m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid machine name '%s': %w", m.Name, err)
+		}
+		for _, name := range names {
+			expanded := m
+			expanded.Name = name
+			machines = append(machines, expanded)
+		}
+	}
+	return machines, nil
+}
+
+func Validate_Infrastructure(cfg *Infrastructure, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg.Libvirt != nil {
+		Validate_LibvirtInfrastructure(cfg.Libvirt, verrs, pathPrefix+".libvirt")
+	}
+	if cfg.OpenStack != nil {
+		if cfg.Libvirt != nil {
+			verrs.Add(pathPrefix + ": only one of libvirt or openstack can be set")
+		}
+		Validate_OpenStackInfrastructure(cfg.OpenStack, verrs, pathPrefix+".openstack")
+	}
+}
+
+func Validate_LibvirtInfrastructure(cfg *LibvirtInfrastructure, verrs *validation.ValidationErrors, pathPrefix string) {
+	if len(cfg.Hypervisors) == 0 {
+		verrs.Add(pathPrefix + ".hypervisors: at least one hypervisor must be defined")
+	}
+	hypervisors := make(map[string]bool)
+	for i := range cfg.Hypervisors {
+		p := fmt.Sprintf("%s.hypervisors[%d]", pathPrefix, i)
+		Validate_HostSpec(&cfg.Hypervisors[i], verrs, p)
+		if name := cfg.Hypervisors[i].Name; name != "" {
+			if hypervisors[name] {
+				verrs.Add(fmt.Sprintf("%s.name: duplicate hypervisor name '%s'", p, name))
+			}
+			hypervisors[name] = true
+		}
+	}
+	if strings.TrimSpace(cfg.Image) == "" {
+		verrs.Add(pathPrefix + ".image: is a required field")
+	}
+	if cfg.Network != "" && cfg.Bridge != "" {
+		verrs.Add(pathPrefix + ": only one of network or bridge can be set")
+	}
+	if cfg.User == "" {
+		verrs.Add(pathPrefix + ".user: is a required field")
+	}
+	if cfg.PrivateKeyPath == "" {
+		verrs.Add(pathPrefix + ".privateKeyPath: is required to log in to the VMs")
+	}
+	if cfg.WaitTimeout < 0 {
+		verrs.Add(fmt.Sprintf("%s.waitTimeout: must not be negative, got %v", pathPrefix, cfg.WaitTimeout))
+	}
+
+	if len(cfg.VMs) == 0 {
+		verrs.Add(pathPrefix + ".vms: at least one VM must be defined")
+	}
+	names := make(map[string]bool)
+	for i, vm := range cfg.VMs {
+		p := fmt.Sprintf("%s.vms[%d]", pathPrefix, i)
+		expanded, err := helpers.ExpandHostRange(vm.Name)
+		if err != nil {
+			verrs.Add(fmt.Sprintf("%s.name: %v", p, err))
+		}
+		for _, name := range expanded {
+			if !helpers.IsValidDomainName(name) || strings.Contains(name, ".") {
+				verrs.Add(fmt.Sprintf("%s.name: '%s' is not a valid host name", p, name))
+			}
+			if names[name] {
+				verrs.Add(fmt.Sprintf("%s.name: duplicate VM name '%s'", p, name))
+			}
+			names[name] = true
+		}
+		if vm.Hypervisor != "" && !hypervisors[vm.Hypervisor] {
+			verrs.Add(fmt.Sprintf("%s.hypervisor: '%s' is not defined in hypervisors", p, vm.Hypervisor))
+		}
+		if vm.VCPUs == 0 || vm.MemoryMB == 0 || vm.DiskSizeGB == 0 {
+			verrs.Add(p + ": vcpus, memoryMB and diskSizeGB must be positive")
+		}
+	}
+}
+
+func Validate_OpenStackInfrastructure(cfg *OpenStackInfrastructure, verrs *validation.ValidationErrors, pathPrefix string) {
+	required := []struct{ field, value string }{
+		{"image", cfg.Image}, {"flavor", cfg.Flavor}, {"network", cfg.Network}, {"keyPair", cfg.KeyPair}, {"user", cfg.User},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			verrs.Add(fmt.Sprintf("%s.%s: is a required field", pathPrefix, r.field))
+		}
+	}
+	if cfg.PrivateKeyPath == "" {
+		verrs.Add(pathPrefix + ".privateKeyPath: is required to log in to the servers")
+	}
+	if cfg.Bastion != nil {
+		Validate_BastionSpec(cfg.Bastion, verrs, pathPrefix+".bastion")
+	}
+	if cfg.WaitTimeout < 0 {
+		verrs.Add(fmt.Sprintf("%s.waitTimeout: must not be negative, got %v", pathPrefix, cfg.WaitTimeout))
+	}
+
+	if len(cfg.Machines) == 0 {
+		verrs.Add(pathPrefix + ".machines: at least one machine must be defined")
+	}
+	names := make(map[string]bool)
+	for i, m := range cfg.Machines {
+		p := fmt.Sprintf("%s.machines[%d]", pathPrefix, i)
+		expanded, err := helpers.ExpandHostRange(m.Name)
+		if err != nil {
+			verrs.Add(fmt.Sprintf("%s.name: %v", p, err))
+		}
+		for _, name := range expanded {
+			if !helpers.IsValidDomainName(name) || strings.Contains(name, ".") {
+				verrs.Add(fmt.Sprintf("%s.name: '%s' is not a valid host name", p, name))
+			}
+			if names[name] {
+				verrs.Add(fmt.Sprintf("%s.name: duplicate machine name '%s'", p, name))
+			}
+			names[name] = true
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func newLibvirtCluster() *Cluster {
+	return &Cluster{Spec: &ClusterSpec{
+		Global: &GlobalSpec{User: "root", Port: 22, PrivateKeyPath: "/keys/id_ed25519"},
+		Infrastructure: &Infrastructure{Libvirt: &LibvirtInfrastructure{
+			Hypervisors: []HostSpec{{Name: "kvm1", Address: "10.0.0.2"}, {Name: "kvm2", Address: "10.0.0.3"}},
+			Image:       "ubuntu-22.04.qcow2",
+			VMs:         []LibvirtVM{{Name: "cp1"}, {Name: "worker[1:2]", Hypervisor: "kvm2", MemoryMB: 8192}},
+		}},
+	}}
+}
+
+func TestLibvirtInfrastructure_Defaults(t *testing.T) {
+	cluster := newLibvirtCluster()
+	SetDefaults_Infrastructure(cluster.Spec.Infrastructure, cluster)
+	cfg := cluster.Spec.Infrastructure.Libvirt
+
+	if cfg.Pool != common.DefaultLibvirtPool || cfg.Network != common.DefaultLibvirtNetwork || cfg.ImageFormat != common.DefaultLibvirtImageFormat {
+		t.Errorf("pool = %s, network = %s, imageFormat = %s", cfg.Pool, cfg.Network, cfg.ImageFormat)
+	}
+	if cfg.User != "root" || cfg.PrivateKeyPath != "/keys/id_ed25519" || cfg.WaitTimeout != common.DefaultLibvirtWaitTimeout {
+		t.Errorf("user = %s, privateKeyPath = %s, waitTimeout = %v", cfg.User, cfg.PrivateKeyPath, cfg.WaitTimeout)
+	}
+	if cfg.JumpThroughHypervisor == nil || !*cfg.JumpThroughHypervisor {
+		t.Error("VMs on a libvirt network should be reached through their hypervisor")
+	}
+	if cfg.Hypervisors[0].User != "root" || cfg.Hypervisors[0].PrivateKeyPath != "/keys/id_ed25519" {
+		t.Errorf("hypervisor connection not defaulted from global: %+v", cfg.Hypervisors[0])
+	}
+
+	vms, err := cfg.ExpandVMs()
+	if err != nil {
+		t.Fatalf("ExpandVMs() error = %v", err)
+	}
+	var got []string
+	for _, vm := range vms {
+		got = append(got, vm.Name+"@"+vm.Hypervisor)
+	}
+	if strings.Join(got, ",") != "cp1@kvm1,worker1@kvm2,worker2@kvm2" {
+		t.Errorf("VMs = %v", got)
+	}
+	if vms[0].VCPUs != common.DefaultLibvirtVMVCPUs || vms[0].MemoryMB != common.DefaultLibvirtVMMemoryMB || vms[2].MemoryMB != 8192 {
+		t.Errorf("VM sizes = %+v", vms)
+	}
+
+	bridged := newLibvirtCluster()
+	bridged.Spec.Infrastructure.Libvirt.Bridge = "br0"
+	SetDefaults_Infrastructure(bridged.Spec.Infrastructure, bridged)
+	if l := bridged.Spec.Infrastructure.Libvirt; l.Network != "" || *l.JumpThroughHypervisor {
+		t.Errorf("bridged VMs: network = %q, jumpThroughHypervisor = %v", l.Network, *l.JumpThroughHypervisor)
+	}
+}
+
+func TestLibvirtInfrastructure_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*LibvirtInfrastructure)
+		expectErr string
+	}{
+		{name: "valid", mutate: func(*LibvirtInfrastructure) {}},
+		{name: "no image", mutate: func(l *LibvirtInfrastructure) { l.Image = "" }, expectErr: "libvirt.image"},
+		{name: "network and bridge", mutate: func(l *LibvirtInfrastructure) { l.Bridge = "br0" }, expectErr: "only one of network or bridge"},
+		{name: "unknown hypervisor", mutate: func(l *LibvirtInfrastructure) { l.VMs[0].Hypervisor = "kvm9" }, expectErr: "vms[0].hypervisor"},
+		{name: "duplicate VM", mutate: func(l *LibvirtInfrastructure) { l.VMs[0].Name = "worker2" }, expectErr: "duplicate VM name 'worker2'"},
+		{name: "invalid VM name", mutate: func(l *LibvirtInfrastructure) { l.VMs[0].Name = "cp_1" }, expectErr: "not a valid host name"},
+		{name: "no hypervisors", mutate: func(l *LibvirtInfrastructure) { l.Hypervisors = nil; l.VMs = l.VMs[:1]; l.VMs[0].Hypervisor = "" }, expectErr: "at least one hypervisor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newLibvirtCluster()
+			SetDefaults_Infrastructure(cluster.Spec.Infrastructure, cluster)
+			tt.mutate(cluster.Spec.Infrastructure.Libvirt)
+			verrs := &validation.ValidationErrors{}
+			Validate_Infrastructure(cluster.Spec.Infrastructure, verrs, "spec.infrastructure")
+			if tt.expectErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectErr) {
+				t.Errorf("expected an error for %s, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
+
+func newOpenStackCluster() *Cluster {
+	return &Cluster{Spec: &ClusterSpec{
+		Global: &GlobalSpec{User: "ubuntu", Port: 22, PrivateKeyPath: "/keys/id_ed25519"},
+		Infrastructure: &Infrastructure{OpenStack: &OpenStackInfrastructure{
+			Image:    "ubuntu-22.04",
+			Flavor:   "m1.large",
+			Network:  "private",
+			KeyPair:  "kubexm",
+			Bastion:  &BastionSpec{Address: "203.0.113.10"},
+			Machines: []OpenStackMachine{{Name: "cp1"}, {Name: "worker[1:2]", Flavor: "m1.xlarge"}},
+		}},
+	}}
+}
+
+func TestOpenStackInfrastructure_Defaults(t *testing.T) {
+	cluster := newOpenStackCluster()
+	SetDefaults_Infrastructure(cluster.Spec.Infrastructure, cluster)
+	cfg := cluster.Spec.Infrastructure.OpenStack
+
+	if cfg.User != "ubuntu" || cfg.PrivateKeyPath != "/keys/id_ed25519" || cfg.WaitTimeout != common.DefaultOpenStackWaitTimeout {
+		t.Errorf("user = %s, privateKeyPath = %s, waitTimeout = %v", cfg.User, cfg.PrivateKeyPath, cfg.WaitTimeout)
+	}
+	if cfg.Bastion.Port != common.DefaultPort || cfg.Bastion.User != "ubuntu" || cfg.Bastion.PrivateKeyPath != "/keys/id_ed25519" {
+		t.Errorf("bastion not defaulted from global: %+v", cfg.Bastion)
+	}
+
+	machines, err := cfg.ExpandMachines()
+	if err != nil {
+		t.Fatalf("ExpandMachines() error = %v", err)
+	}
+	var got []string
+	for _, m := range machines {
+		got = append(got, m.Name+"@"+m.Flavor)
+	}
+	if strings.Join(got, ",") != "cp1@m1.large,worker1@m1.xlarge,worker2@m1.xlarge" {
+		t.Errorf("machines = %v", got)
+	}
+}
+
+func TestOpenStackInfrastructure_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*Infrastructure)
+		expectErr string
+	}{
+		{name: "valid", mutate: func(*Infrastructure) {}},
+		{name: "no flavor", mutate: func(i *Infrastructure) { i.OpenStack.Flavor = "" }, expectErr: "openstack.flavor: is a required field"},
+		{name: "no key pair", mutate: func(i *Infrastructure) { i.OpenStack.KeyPair = "" }, expectErr: "openstack.keyPair"},
+		{name: "no machines", mutate: func(i *Infrastructure) { i.OpenStack.Machines = nil }, expectErr: "at least one machine"},
+		{name: "duplicate machine", mutate: func(i *Infrastructure) { i.OpenStack.Machines[0].Name = "worker1" }, expectErr: "duplicate machine name 'worker1'"},
+		{name: "invalid bastion", mutate: func(i *Infrastructure) { i.OpenStack.Bastion.Address = "" }, expectErr: "bastion.address"},
+		{name: "two providers", mutate: func(i *Infrastructure) { i.Libvirt = newLibvirtCluster().Spec.Infrastructure.Libvirt }, expectErr: "only one of libvirt or openstack"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newOpenStackCluster()
+			tt.mutate(cluster.Spec.Infrastructure)
+			SetDefaults_Infrastructure(cluster.Spec.Infrastructure, cluster)
+			verrs := &validation.ValidationErrors{}
+			Validate_Infrastructure(cluster.Spec.Infrastructure, verrs, "spec.infrastructure")
+			if tt.expectErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectErr) {
+				t.Errorf("expected an error for %s, got %v", tt.expectErr, verrs.Error())
+			}
+		})
+	}
+}
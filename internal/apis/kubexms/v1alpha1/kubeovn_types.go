@@ -52,6 +52,15 @@ func SetDefaults_KubeOvnConfig(cfg *KubeOvnConfig) {
 	if cfg.Controller == nil {
 		cfg.Controller = &KubeOvnControllerConfig{}
 	}
+	if cfg.Controller.JoinCIDR == "" {
+		cfg.Controller.JoinCIDR = common.KubeOvnControllerConfigJoinCIDR
+	}
+	if cfg.Controller.NodeSwitchCIDR == "" {
+		cfg.Controller.NodeSwitchCIDR = common.KubeOvnControllerConfigNodeSwitchCIDR
+	}
+	if cfg.Controller.PodDefaultSubnetCIDR == "" {
+		cfg.Controller.PodDefaultSubnetCIDR = common.KubeOvnControllerConfigPodDefaultSubnetCIDR
+	}
 
 	if cfg.AdvancedFeatures == nil {
 		cfg.AdvancedFeatures = &KubeOvnAdvancedFeatures{}
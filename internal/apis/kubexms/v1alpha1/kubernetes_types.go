@@ -6,6 +6,7 @@ import (
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
 	"net"
 	"path"
 	"regexp"
@@ -28,6 +29,8 @@ type Kubernetes struct {
 	KubeProxy         *KubeProxyConfig         `json:"kubeProxy,omitempty" yaml:"kubeProxy,omitempty"`
 
 	Addons *KubernetesAddons `json:"addons,omitempty" yaml:"addons,omitempty"`
+
+	ImagePreload *ImagePreloadConfig `json:"imagePreload,omitempty" yaml:"imagePreload,omitempty"`
 }
 
 type APIServerConfig struct {
@@ -82,19 +85,31 @@ type KubeletConfig struct {
 }
 
 type KubeProxyConfig struct {
-	Enable        *bool                 `json:"enable,omitempty" yaml:"enable,omitempty"`
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Mode is iptables, ipvs or none. With none kube-proxy is not deployed, e.g. when Cilium replaces it.
 	Mode          string                `json:"mode,omitempty" yaml:"mode,omitempty"`
 	MasqueradeAll *bool                 `json:"masqueradeAll,omitempty" yaml:"masqueradeAll,omitempty"`
+	IPVS          *KubeProxyIPVSConfig  `json:"ipvs,omitempty" yaml:"ipvs,omitempty"`
 	FeatureGates  map[string]bool       `json:"featureGates,omitempty" yaml:"featureGates,omitempty"`
 	ExtraArgs     map[string]string     `json:"extraArgs,omitempty" yaml:"extraArgs,omitempty"`
 	Configuration *runtime.RawExtension `json:"configuration,omitempty" yaml:"configuration,omitempty"`
 }
 
+// KubeProxyIPVSConfig tunes kube-proxy in ipvs mode.
+type KubeProxyIPVSConfig struct {
+	// Scheduler is the IPVS scheduler, e.g. rr, wrr, lc or sh. Its kernel module is loaded on every node.
+	Scheduler string `json:"scheduler,omitempty" yaml:"scheduler,omitempty"`
+	// ExcludeCIDRs lists the ranges kube-proxy leaves alone when it cleans up IPVS rules.
+	ExcludeCIDRs []string `json:"excludeCIDRs,omitempty" yaml:"excludeCIDRs,omitempty"`
+}
+
 type KubernetesAddons struct {
 	Nodelocaldns         *NodelocaldnsConfig         `json:"nodelocaldns,omitempty" yaml:"nodelocaldns,omitempty"`
 	NodeFeatureDiscovery *NodeFeatureDiscoveryConfig `json:"nodeFeatureDiscovery,omitempty" yaml:"nodeFeatureDiscovery,omitempty"`
 	Kata                 *KataConfig                 `json:"kata,omitempty" yaml:"kata,omitempty"`
 	NvidiaRuntime        *NvidiaRuntimeConfig        `json:"nvidiaRuntime,omitempty" yaml:"nvidiaRuntime,omitempty"`
+	// MetricsServer tunes the metrics-server addon, installed when it is listed in spec.addons.
+	MetricsServer *MetricsServerConfig `json:"metricsServer,omitempty" yaml:"metricsServer,omitempty"`
 }
 
 type AuditConfig struct {
@@ -125,6 +140,20 @@ type NvidiaRuntimeConfig struct {
 	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 }
 
+type MetricsServerConfig struct {
+	// KubeletInsecureTLS skips verification of kubelet serving certificates, which are self-signed by default.
+	KubeletInsecureTLS    *bool    `json:"kubeletInsecureTLS,omitempty" yaml:"kubeletInsecureTLS,omitempty"`
+	PreferredAddressTypes []string `json:"preferredAddressTypes,omitempty" yaml:"preferredAddressTypes,omitempty"`
+}
+
+// ImagePreloadConfig controls pulling the cluster images onto every node before kubeadm init/join.
+type ImagePreloadConfig struct {
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// ArchiveDir is a directory on the control node holding image tarballs named by
+	// images.ArchiveFileName. Images with an archive there are loaded instead of pulled.
+	ArchiveDir string `json:"archiveDir,omitempty" yaml:"archiveDir,omitempty"`
+}
+
 func SetDefaults_Kubernetes(cfg *Kubernetes) {
 	if cfg == nil {
 		return
@@ -191,6 +220,27 @@ func SetDefaults_Kubernetes(cfg *Kubernetes) {
 	if cfg.Addons.NvidiaRuntime.Enabled == nil {
 		cfg.Addons.NvidiaRuntime.Enabled = helpers.BoolPtr(false)
 	}
+
+	if cfg.Addons.MetricsServer == nil {
+		cfg.Addons.MetricsServer = &MetricsServerConfig{}
+	}
+	SetDefaults_MetricsServerConfig(cfg.Addons.MetricsServer)
+
+	if cfg.ImagePreload == nil {
+		cfg.ImagePreload = &ImagePreloadConfig{}
+	}
+	if cfg.ImagePreload.Enabled == nil {
+		cfg.ImagePreload.Enabled = helpers.BoolPtr(common.DefaultImagePreloadEnabled)
+	}
+}
+
+func SetDefaults_MetricsServerConfig(cfg *MetricsServerConfig) {
+	if cfg.KubeletInsecureTLS == nil {
+		cfg.KubeletInsecureTLS = helpers.BoolPtr(common.DefaultMetricsServerKubeletInsecureTLS)
+	}
+	if len(cfg.PreferredAddressTypes) == 0 {
+		cfg.PreferredAddressTypes = append([]string{}, common.DefaultMetricsServerPreferredAddressTypes...)
+	}
 }
 
 func SetDefaults_APIServerConfig(cfg *APIServerConfig) {
@@ -308,12 +358,59 @@ func SetDefaults_KubeProxyConfig(cfg *KubeProxyConfig) {
 	if cfg.Enable == nil {
 		cfg.Enable = helpers.BoolPtr(common.KubeProxyEnable)
 	}
-	if cfg.Mode == "" {
+	if cfg.Mode == "" && *cfg.Enable {
 		cfg.Mode = common.DefaultKubeProxyMode
 	}
 	if cfg.MasqueradeAll == nil {
 		cfg.MasqueradeAll = helpers.BoolPtr(common.DefaultMasqueradeAll)
 	}
+	if cfg.Mode == common.KubeProxyModeIPVS {
+		if cfg.IPVS == nil {
+			cfg.IPVS = &KubeProxyIPVSConfig{}
+		}
+		if cfg.IPVS.Scheduler == "" {
+			cfg.IPVS.Scheduler = common.DefaultIPVSScheduler
+		}
+	}
+}
+
+// KubeProxyMode returns the mode kube-proxy runs in: iptables, ipvs, or none when it is not deployed.
+func KubeProxyMode(spec *ClusterSpec) string {
+	if spec == nil || spec.Kubernetes == nil || spec.Kubernetes.KubeProxy == nil {
+		return common.DefaultKubeProxyMode
+	}
+	proxy := spec.Kubernetes.KubeProxy
+	if proxy.Enable != nil && !*proxy.Enable {
+		return common.KubeProxyModeNone
+	}
+	if proxy.Mode == "" {
+		return common.DefaultKubeProxyMode
+	}
+	return proxy.Mode
+}
+
+// KubeProxyEnabled reports whether kube-proxy is deployed to the nodes.
+func KubeProxyEnabled(spec *ClusterSpec) bool {
+	return KubeProxyMode(spec) != common.KubeProxyModeNone
+}
+
+// KubeProxyKernelModules returns the kernel modules kube-proxy needs on every node: the IPVS
+// modules, including the one of the configured scheduler, in ipvs mode and none otherwise.
+func KubeProxyKernelModules(spec *ClusterSpec) []string {
+	if KubeProxyMode(spec) != common.KubeProxyModeIPVS {
+		return nil
+	}
+	modules := append([]string(nil), common.IPVSKernelModules...)
+	scheduler := common.DefaultIPVSScheduler
+	if spec.Kubernetes != nil && spec.Kubernetes.KubeProxy != nil {
+		if ipvs := spec.Kubernetes.KubeProxy.IPVS; ipvs != nil && ipvs.Scheduler != "" {
+			scheduler = ipvs.Scheduler
+		}
+	}
+	if module := common.KernelModuleIpvs + "_" + scheduler; !helpers.ContainsString(modules, module) {
+		modules = append(modules, module)
+	}
+	return modules
 }
 
 func SetDefaults_NodelocaldnsConfig(cfg *NodelocaldnsConfig) {
@@ -379,6 +476,40 @@ func Validate_Kubernetes(cfg *Kubernetes, verrs *validation.ValidationErrors, pa
 
 		if cfg.Addons.NvidiaRuntime != nil {
 		}
+
+		if cfg.Addons.MetricsServer != nil {
+			Validate_MetricsServerConfig(cfg.Addons.MetricsServer, verrs, path.Join(addonsPath, "metricsServer"))
+		}
+	}
+
+	if cfg.ImagePreload != nil && cfg.ImagePreload.ArchiveDir != "" && !path.IsAbs(cfg.ImagePreload.ArchiveDir) {
+		verrs.Add(fmt.Sprintf("%s.imagePreload.archiveDir: must be an absolute path, got '%s'", p, cfg.ImagePreload.ArchiveDir))
+	}
+}
+
+// Validate_KubernetesUpgrade checks that the cluster at cfg.Version can be upgraded to targetVersion.
+// kubeadm only upgrades one minor version at a time and never downgrades.
+func Validate_KubernetesUpgrade(cfg *Kubernetes, targetVersion string, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg == nil {
+		verrs.Add(pathPrefix + ": kubernetes configuration section cannot be nil")
+		return
+	}
+	p := path.Join(pathPrefix)
+	if !helpers.IsValidKubernetesVersion(targetVersion) {
+		verrs.Add(fmt.Sprintf("target version '%s' is not a supported Kubernetes version", targetVersion))
+		return
+	}
+	current, err := version.ParseSemantic(cfg.Version)
+	if err != nil {
+		verrs.Add(fmt.Sprintf("%s.version: invalid semantic version format for '%s'", p, cfg.Version))
+		return
+	}
+	target := version.MustParseSemantic(targetVersion)
+	switch {
+	case current.AtLeast(target):
+		verrs.Add(fmt.Sprintf("target version '%s' must be newer than the current version '%s'", targetVersion, cfg.Version))
+	case target.Major() != current.Major() || target.Minor() > current.Minor()+1:
+		verrs.Add(fmt.Sprintf("cannot upgrade from '%s' to '%s': upgrades may skip at most one minor version at a time", cfg.Version, targetVersion))
 	}
 }
 
@@ -532,8 +663,34 @@ func Validate_KubeProxyConfig(cfg *KubeProxyConfig, verrs *validation.Validation
 				pathPrefix, cfg.Mode, strings.Join(common.ValidKubeProxyModes, ", ")))
 		}
 	} else {
-		if cfg.Mode != "" {
-			verrs.Add(fmt.Sprintf("%s.mode: should be ' ' or empty when kube-proxy is disabled", pathPrefix))
+		if cfg.Mode != "" && cfg.Mode != common.KubeProxyModeNone {
+			verrs.Add(fmt.Sprintf("%s.mode: should be '%s' or empty when kube-proxy is disabled", pathPrefix, common.KubeProxyModeNone))
+		}
+	}
+	if cfg.IPVS == nil {
+		return
+	}
+	ipvsPath := path.Join(pathPrefix, "ipvs")
+	if !*cfg.Enable || cfg.Mode != common.KubeProxyModeIPVS {
+		verrs.Add(fmt.Sprintf("%s: can only be set when mode is '%s'", ipvsPath, common.KubeProxyModeIPVS))
+		return
+	}
+	if !helpers.ContainsStringWithEmpty(common.ValidIPVSSchedulers, cfg.IPVS.Scheduler) {
+		verrs.Add(fmt.Sprintf("%s.scheduler: invalid scheduler '%s', must be one of [%s]",
+			ipvsPath, cfg.IPVS.Scheduler, strings.Join(common.ValidIPVSSchedulers, ", ")))
+	}
+	for i, cidr := range cfg.IPVS.ExcludeCIDRs {
+		if !helpers.IsValidCIDR(cidr) {
+			verrs.Add(fmt.Sprintf("%s.excludeCIDRs[%d]: invalid CIDR format '%s'", ipvsPath, i, cidr))
+		}
+	}
+}
+
+func Validate_MetricsServerConfig(cfg *MetricsServerConfig, verrs *validation.ValidationErrors, pathPrefix string) {
+	for i, addressType := range cfg.PreferredAddressTypes {
+		if !helpers.ContainsString(common.ValidNodeAddressTypes, addressType) {
+			verrs.Add(fmt.Sprintf("%s.preferredAddressTypes[%d]: invalid address type '%s', must be one of [%s]",
+				pathPrefix, i, addressType, strings.Join(common.ValidNodeAddressTypes, ", ")))
 		}
 	}
 }
@@ -0,0 +1,140 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestValidate_KubernetesUpgrade(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       string
+		target        string
+		expectedError string
+	}{
+		{name: "patch upgrade", current: "v1.28.2", target: "v1.28.9"},
+		{name: "one minor upgrade", current: "v1.28.9", target: "v1.29.0"},
+		{name: "skips a minor", current: "v1.27.3", target: "v1.29.0", expectedError: "at most one minor version"},
+		{name: "downgrade", current: "v1.29.2", target: "v1.29.0", expectedError: "must be newer"},
+		{name: "same version", current: "v1.29.2", target: "v1.29.2", expectedError: "must be newer"},
+		{name: "unsupported target", current: "v1.29.2", target: "v1.31.0", expectedError: "not a supported Kubernetes version"},
+		{name: "invalid current", current: "latest", target: "v1.29.2", expectedError: "spec.kubernetes.version: invalid semantic version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_KubernetesUpgrade(&Kubernetes{Version: tt.current}, tt.target, verrs, "spec.kubernetes")
+			if tt.expectedError == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestKubeProxyMode(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name        string
+		spec        *ClusterSpec
+		wantMode    string
+		wantModules []string
+	}{
+		{name: "defaults", spec: &ClusterSpec{}, wantMode: common.KubeProxyModeIPVS, wantModules: common.IPVSKernelModules},
+		{name: "iptables", spec: &ClusterSpec{Kubernetes: &Kubernetes{KubeProxy: &KubeProxyConfig{Mode: common.KubeProxyModeIPTables}}}, wantMode: common.KubeProxyModeIPTables},
+		{name: "none", spec: &ClusterSpec{Kubernetes: &Kubernetes{KubeProxy: &KubeProxyConfig{Mode: common.KubeProxyModeNone}}}, wantMode: common.KubeProxyModeNone},
+		{name: "disabled", spec: &ClusterSpec{Kubernetes: &Kubernetes{KubeProxy: &KubeProxyConfig{Enable: &disabled}}}, wantMode: common.KubeProxyModeNone},
+		{
+			name:        "ipvs scheduler",
+			spec:        &ClusterSpec{Kubernetes: &Kubernetes{KubeProxy: &KubeProxyConfig{Mode: common.KubeProxyModeIPVS, IPVS: &KubeProxyIPVSConfig{Scheduler: "lc"}}}},
+			wantMode:    common.KubeProxyModeIPVS,
+			wantModules: append(append([]string(nil), common.IPVSKernelModules...), "ip_vs_lc"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KubeProxyMode(tt.spec); got != tt.wantMode {
+				t.Errorf("KubeProxyMode() = %q, want %q", got, tt.wantMode)
+			}
+			if got := KubeProxyKernelModules(tt.spec); strings.Join(got, ",") != strings.Join(tt.wantModules, ",") {
+				t.Errorf("KubeProxyKernelModules() = %v, want %v", got, tt.wantModules)
+			}
+		})
+	}
+}
+
+func TestValidate_KubeProxyConfig(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name          string
+		cfg           KubeProxyConfig
+		expectedError string
+	}{
+		{name: "ipvs", cfg: KubeProxyConfig{Mode: common.KubeProxyModeIPVS, IPVS: &KubeProxyIPVSConfig{Scheduler: "wrr", ExcludeCIDRs: []string{"10.10.0.0/16"}}}},
+		{name: "none", cfg: KubeProxyConfig{Mode: common.KubeProxyModeNone}},
+		{name: "disabled", cfg: KubeProxyConfig{Enable: &disabled}},
+		{name: "invalid mode", cfg: KubeProxyConfig{Mode: "userspace"}, expectedError: "mode: invalid mode 'userspace'"},
+		{name: "disabled with mode", cfg: KubeProxyConfig{Enable: &disabled, Mode: common.KubeProxyModeIPVS}, expectedError: "should be 'none' or empty"},
+		{name: "ipvs settings in iptables mode", cfg: KubeProxyConfig{Mode: common.KubeProxyModeIPTables, IPVS: &KubeProxyIPVSConfig{Scheduler: "rr"}}, expectedError: "ipvs: can only be set when mode is 'ipvs'"},
+		{name: "invalid scheduler", cfg: KubeProxyConfig{Mode: common.KubeProxyModeIPVS, IPVS: &KubeProxyIPVSConfig{Scheduler: "random"}}, expectedError: "ipvs.scheduler: invalid scheduler 'random'"},
+		{name: "invalid exclude cidr", cfg: KubeProxyConfig{Mode: common.KubeProxyModeIPVS, IPVS: &KubeProxyIPVSConfig{ExcludeCIDRs: []string{"10.10.0.0"}}}, expectedError: "ipvs.excludeCIDRs[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDefaults_KubeProxyConfig(&tt.cfg)
+			verrs := &validation.ValidationErrors{}
+			Validate_KubeProxyConfig(&tt.cfg, verrs, "spec.kubernetes.kubeProxy")
+			if tt.expectedError == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, verrs.Error())
+			}
+		})
+	}
+}
+
+func TestValidateKubeProxyReplacement(t *testing.T) {
+	none := &Kubernetes{KubeProxy: &KubeProxyConfig{Mode: common.KubeProxyModeNone}}
+	tests := []struct {
+		name          string
+		spec          *ClusterSpec
+		expectedError string
+	}{
+		{name: "kube-proxy enabled", spec: &ClusterSpec{Network: &Network{Plugin: string(common.CNITypeCalico)}}},
+		{name: "cilium replacement", spec: &ClusterSpec{Kubernetes: none, Network: &Network{Plugin: string(common.CNITypeCilium)}}},
+		{name: "calico without kube-proxy", spec: &ClusterSpec{Kubernetes: none, Network: &Network{Plugin: string(common.CNITypeCalico)}}, expectedError: "does not replace kube-proxy"},
+		{
+			name:          "cilium probe mode",
+			spec:          &ClusterSpec{Kubernetes: none, Network: &Network{Plugin: string(common.CNITypeCilium), Cilium: &CiliumConfig{KubeProxy: &CiliumKubeProxyConfig{ReplacementMode: common.CiliumKPRProbeModes}}}},
+			expectedError: "must be 'strict'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			validateKubeProxyReplacement(tt.spec, verrs, "spec.network")
+			if tt.expectedError == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, verrs.Error())
+			}
+		})
+	}
+}
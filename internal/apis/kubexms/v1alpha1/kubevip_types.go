@@ -64,7 +64,7 @@ func Validate_KubeVIPConfig(cfg *KubeVIPConfig, verrs *validation.ValidationErro
 		verrs.Add(pathPrefix+".mode", fmt.Sprintf("invalid mode '%s', must be one of %v", *cfg.Mode, common.ValidKubeVIPModes))
 	}
 
-	if !helpers.IsValidNonEmptyString(*cfg.VIP) {
+	if cfg.VIP == nil || !helpers.IsValidNonEmptyString(*cfg.VIP) {
 		verrs.Add(pathPrefix+".vip", "virtual IP address must be specified")
 	} else if !helpers.IsValidIP(*cfg.VIP) {
 		verrs.Add(pathPrefix+".vip", fmt.Sprintf("invalid IP address format '%s'", *cfg.VIP))
@@ -76,8 +76,9 @@ func Validate_KubeVIPConfig(cfg *KubeVIPConfig, verrs *validation.ValidationErro
 
 	switch *cfg.Mode {
 	case common.KubeVIPModeARP:
-		if !helpers.IsValidNonEmptyString(*cfg.Interface) {
-			verrs.Add(pathPrefix+".interface", "network interface must be specified for ARP mode")
+		// The interface falls back to the default interface of each master when it is not set.
+		if cfg.Interface != nil && !helpers.IsValidNonEmptyString(*cfg.Interface) {
+			verrs.Add(pathPrefix+".interface", "cannot be empty if specified")
 		}
 	case common.KubeVIPModeBGP:
 		if cfg.BGPConfig == nil {
@@ -89,9 +90,8 @@ func Validate_KubeVIPConfig(cfg *KubeVIPConfig, verrs *validation.ValidationErro
 }
 
 func Validate_KubeVIPBGPConfig(cfg *KubeVIPBGPConfig, verrs *validation.ValidationErrors, path string) {
-	if !helpers.IsValidNonEmptyString(cfg.RouterID) {
-		verrs.Add(path+".routerID", "must be specified for BGP mode")
-	} else if !helpers.IsValidIP(cfg.RouterID) {
+	// Every master peers with its own router ID, which defaults to the node address.
+	if helpers.IsValidNonEmptyString(cfg.RouterID) && !helpers.IsValidIP(cfg.RouterID) {
 		verrs.Add(path+".routerID", fmt.Sprintf("invalid IP address format '%s'", cfg.RouterID))
 	}
 
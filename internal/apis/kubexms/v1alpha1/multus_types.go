@@ -1,14 +1,27 @@
 package v1alpha1
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"path/filepath"
+	"strings"
+
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
 )
 
 type MultusConfig struct {
+	// Enabled installs Multus after the primary CNI plugin. It is a shorthand for installation.enabled.
+	Enabled      *bool                     `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 	Source       AddonSource               `json:"source,omitempty" yaml:"sources,omitempty"`
 	Installation *MultusInstallationConfig `json:"installation,omitempty" yaml:"installation,omitempty"`
+	// NetworkAttachments are rendered to NetworkAttachmentDefinitions once Multus is running.
+	NetworkAttachments []MultusNetworkAttachment `json:"networkAttachments,omitempty" yaml:"networkAttachments,omitempty"`
+	// AdditionalPlugins are CNI plugin binaries on the control node that are copied to /opt/cni/bin on every node.
+	AdditionalPlugins []string `json:"additionalPlugins,omitempty" yaml:"additionalPlugins,omitempty"`
 }
 
 type MultusInstallationConfig struct {
@@ -17,6 +30,26 @@ type MultusInstallationConfig struct {
 	Image     string `json:"image,omitempty" yaml:"image,omitempty"`
 }
 
+// MultusNetworkAttachment describes a secondary network. Either Config holds the raw CNI configuration,
+// or it is built from Type, Master, Mode and IPAM.
+type MultusNetworkAttachment struct {
+	Name      string                `json:"name" yaml:"name"`
+	Namespace string                `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Type      string                `json:"type,omitempty" yaml:"type,omitempty"`
+	Master    string                `json:"master,omitempty" yaml:"master,omitempty"`
+	Mode      string                `json:"mode,omitempty" yaml:"mode,omitempty"`
+	IPAM      *MultusAttachmentIPAM `json:"ipam,omitempty" yaml:"ipam,omitempty"`
+	Config    string                `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+type MultusAttachmentIPAM struct {
+	Type       string `json:"type,omitempty" yaml:"type,omitempty"`
+	Subnet     string `json:"subnet,omitempty" yaml:"subnet,omitempty"`
+	RangeStart string `json:"rangeStart,omitempty" yaml:"rangeStart,omitempty"`
+	RangeEnd   string `json:"rangeEnd,omitempty" yaml:"rangeEnd,omitempty"`
+	Gateway    string `json:"gateway,omitempty" yaml:"gateway,omitempty"`
+}
+
 func SetDefaults_MultusConfig(cfg *MultusConfig) {
 	if cfg == nil {
 		return
@@ -27,7 +60,14 @@ func SetDefaults_MultusConfig(cfg *MultusConfig) {
 	}
 
 	if cfg.Installation.Enabled == nil {
-		cfg.Installation.Enabled = helpers.BoolPtr(false)
+		if cfg.Enabled != nil {
+			cfg.Installation.Enabled = helpers.BoolPtr(*cfg.Enabled)
+		} else {
+			cfg.Installation.Enabled = helpers.BoolPtr(false)
+		}
+	}
+	if cfg.Enabled == nil {
+		cfg.Enabled = helpers.BoolPtr(*cfg.Installation.Enabled)
 	}
 
 	if cfg.Installation.Namespace == "" {
@@ -37,6 +77,16 @@ func SetDefaults_MultusConfig(cfg *MultusConfig) {
 	if cfg.Installation.Image == "" {
 		cfg.Installation.Image = common.MultusInstallationConfigImage
 	}
+
+	for i := range cfg.NetworkAttachments {
+		attachment := &cfg.NetworkAttachments[i]
+		if attachment.Namespace == "" {
+			attachment.Namespace = common.DefaultMultusNetworkAttachmentNamespace
+		}
+		if attachment.IPAM != nil && attachment.IPAM.Type == "" {
+			attachment.IPAM.Type = common.MultusIPAMTypeHostLocal
+		}
+	}
 }
 
 func Validate_MultusConfig(cfg *MultusConfig, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -44,6 +94,75 @@ func Validate_MultusConfig(cfg *MultusConfig, verrs *validation.ValidationErrors
 		return
 	}
 
-	if cfg.Installation != nil {
+	if cfg.Enabled != nil && cfg.Installation != nil && cfg.Installation.Enabled != nil && *cfg.Enabled != *cfg.Installation.Enabled {
+		verrs.Add(fmt.Sprintf("%s.enabled: conflicts with %s.installation.enabled", pathPrefix, pathPrefix))
+	}
+
+	seen := make(map[string]bool)
+	for i, attachment := range cfg.NetworkAttachments {
+		p := fmt.Sprintf("%s.networkAttachments[%d]", pathPrefix, i)
+		if !helpers.IsValidK8sName(attachment.Name) {
+			verrs.Add(fmt.Sprintf("%s.name: '%s' is not a valid Kubernetes object name", p, attachment.Name))
+		}
+		key := attachment.Namespace + "/" + attachment.Name
+		if seen[key] {
+			verrs.Add(fmt.Sprintf("%s.name: duplicate network attachment '%s'", p, key))
+		}
+		seen[key] = true
+
+		if attachment.Config != "" {
+			if attachment.Type != "" || attachment.Master != "" || attachment.IPAM != nil {
+				verrs.Add(fmt.Sprintf("%s.config: cannot be combined with type, master or ipam", p))
+			}
+			if !json.Valid([]byte(attachment.Config)) {
+				verrs.Add(fmt.Sprintf("%s.config: is not valid JSON", p))
+			}
+			continue
+		}
+		if attachment.Type == "" {
+			verrs.Add(fmt.Sprintf("%s.type: is required when config is not set", p))
+		}
+		if helpers.ContainsString(common.MultusMasterPluginTypes, attachment.Type) && attachment.Master == "" {
+			verrs.Add(fmt.Sprintf("%s.master: is required for type '%s'", p, attachment.Type))
+		}
+		if attachment.IPAM != nil {
+			Validate_MultusAttachmentIPAM(attachment.IPAM, verrs, path.Join(p, "ipam"))
+		}
+	}
+
+	for i, plugin := range cfg.AdditionalPlugins {
+		if !filepath.IsAbs(plugin) {
+			verrs.Add(fmt.Sprintf("%s.additionalPlugins[%d]: '%s' must be an absolute path", pathPrefix, i, plugin))
+		}
+	}
+}
+
+func Validate_MultusAttachmentIPAM(cfg *MultusAttachmentIPAM, verrs *validation.ValidationErrors, pathPrefix string) {
+	if !helpers.ContainsStringWithEmpty(common.ValidMultusIPAMTypes, cfg.Type) {
+		verrs.Add(fmt.Sprintf("%s.type: invalid type '%s', must be one of [%s]",
+			pathPrefix, cfg.Type, strings.Join(common.ValidMultusIPAMTypes, ", ")))
+	}
+	if cfg.Type == common.MultusIPAMTypeDHCP {
+		return
+	}
+	if cfg.Subnet == "" {
+		verrs.Add(fmt.Sprintf("%s.subnet: is required for ipam type '%s'", pathPrefix, cfg.Type))
+		return
+	}
+	_, subnet, err := net.ParseCIDR(cfg.Subnet)
+	if err != nil {
+		verrs.Add(fmt.Sprintf("%s.subnet: invalid CIDR '%s'", pathPrefix, cfg.Subnet))
+		return
+	}
+	addresses := []struct{ field, value string }{
+		{"rangeStart", cfg.RangeStart}, {"rangeEnd", cfg.RangeEnd}, {"gateway", cfg.Gateway},
+	}
+	for _, addr := range addresses {
+		if addr.value == "" {
+			continue
+		}
+		if ip := net.ParseIP(addr.value); ip == nil || !subnet.Contains(ip) {
+			verrs.Add(fmt.Sprintf("%s.%s: '%s' is not an address in subnet %s", pathPrefix, addr.field, addr.value, cfg.Subnet))
+		}
 	}
 }
@@ -0,0 +1,63 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestSetDefaults_MultusConfig_Enabled(t *testing.T) {
+	enabled := true
+	cfg := &MultusConfig{Enabled: &enabled, NetworkAttachments: []MultusNetworkAttachment{
+		{Name: "macvlan", Type: "macvlan", Master: "eth1", IPAM: &MultusAttachmentIPAM{Subnet: "192.168.10.0/24"}},
+	}}
+	SetDefaults_MultusConfig(cfg)
+
+	if cfg.Installation.Enabled == nil || !*cfg.Installation.Enabled {
+		t.Error("expected enabled to turn on the installation")
+	}
+	attachment := cfg.NetworkAttachments[0]
+	if attachment.Namespace != common.DefaultMultusNetworkAttachmentNamespace || attachment.IPAM.Type != common.MultusIPAMTypeHostLocal {
+		t.Errorf("unexpected attachment defaults %+v", attachment)
+	}
+
+	disabled := &MultusConfig{}
+	SetDefaults_MultusConfig(disabled)
+	if *disabled.Enabled || *disabled.Installation.Enabled {
+		t.Error("expected multus to stay disabled by default")
+	}
+}
+
+func TestValidate_MultusConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		attachment MultusNetworkAttachment
+		wantErr    string
+	}{
+		{name: "valid", attachment: MultusNetworkAttachment{Name: "storage", Type: "macvlan", Master: "eth1",
+			IPAM: &MultusAttachmentIPAM{Type: "host-local", Subnet: "192.168.10.0/24", Gateway: "192.168.10.1"}}},
+		{name: "invalid name", attachment: MultusNetworkAttachment{Name: "Storage_Net", Type: "bridge"}, wantErr: "not a valid Kubernetes object name"},
+		{name: "missing master", attachment: MultusNetworkAttachment{Name: "storage", Type: "ipvlan"}, wantErr: "master: is required"},
+		{name: "missing type", attachment: MultusNetworkAttachment{Name: "storage"}, wantErr: "type: is required"},
+		{name: "invalid config", attachment: MultusNetworkAttachment{Name: "storage", Config: "{"}, wantErr: "not valid JSON"},
+		{name: "gateway outside subnet", attachment: MultusNetworkAttachment{Name: "storage", Type: "bridge",
+			IPAM: &MultusAttachmentIPAM{Type: "host-local", Subnet: "192.168.10.0/24", Gateway: "10.0.0.1"}}, wantErr: "not an address in subnet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_MultusConfig(&MultusConfig{NetworkAttachments: []MultusNetworkAttachment{tt.attachment}}, verrs, "spec.network.multus")
+			if tt.wantErr == "" {
+				if verrs.HasErrors() {
+					t.Errorf("expected no errors, got %v", verrs.Error())
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, verrs.Error())
+			}
+		})
+	}
+}
@@ -88,16 +88,16 @@ func Validate_Network(cfg *Network, verrs *validation.ValidationErrors, pathPref
 	if strings.TrimSpace(cfg.KubePodsCIDR) == "" {
 		verrs.Add(p + ".kubePodsCIDR: cannot be empty")
 	} else {
-		if !helpers.IsValidCIDR(cfg.KubePodsCIDR) {
-			verrs.Add(fmt.Sprintf("%s.kubePodsCIDR: invalid CIDR format '%s'", p, cfg.KubePodsCIDR))
+		if _, err := helpers.ParseDualStackCIDRs(cfg.KubePodsCIDR); err != nil {
+			verrs.Add(fmt.Sprintf("%s.kubePodsCIDR: %v", p, err))
 		}
 	}
 
 	if strings.TrimSpace(cfg.KubeServiceCIDR) == "" {
 		verrs.Add(p + ".kubeServiceCIDR: cannot be empty")
 	} else {
-		if !helpers.IsValidCIDR(cfg.KubeServiceCIDR) {
-			verrs.Add(fmt.Sprintf("%s.kubeServiceCIDR: invalid CIDR format '%s'", p, cfg.KubeServiceCIDR))
+		if _, err := helpers.ParseDualStackCIDRs(cfg.KubeServiceCIDR); err != nil {
+			verrs.Add(fmt.Sprintf("%s.kubeServiceCIDR: %v", p, err))
 		}
 	}
 
@@ -106,11 +106,22 @@ func Validate_Network(cfg *Network, verrs *validation.ValidationErrors, pathPref
 			p, cfg.Plugin, strings.Join(common.SupportedCNIPlugins, ", ")))
 	}
 
-	if _, podsNet, podsErr := net.ParseCIDR(cfg.KubePodsCIDR); podsErr == nil {
-		if _, serviceNet, serviceErr := net.ParseCIDR(cfg.KubeServiceCIDR); serviceErr == nil {
-			if podsNet.Contains(serviceNet.IP) || serviceNet.Contains(podsNet.IP) {
-				verrs.Add(fmt.Sprintf("%s: kubePodsCIDR (%s) and kubeServiceCIDR (%s) must not overlap",
-					p, cfg.KubePodsCIDR, cfg.KubeServiceCIDR))
+	podsNets, podsErr := helpers.ParseDualStackCIDRs(cfg.KubePodsCIDR)
+	serviceNets, serviceErr := helpers.ParseDualStackCIDRs(cfg.KubeServiceCIDR)
+	if podsErr == nil && serviceErr == nil {
+		if len(podsNets) != len(serviceNets) {
+			verrs.Add(fmt.Sprintf("%s: kubePodsCIDR (%s) and kubeServiceCIDR (%s) must both be single-stack or both be dual-stack",
+				p, cfg.KubePodsCIDR, cfg.KubeServiceCIDR))
+		} else if isIPv4(podsNets[0]) != isIPv4(serviceNets[0]) {
+			verrs.Add(fmt.Sprintf("%s: kubePodsCIDR (%s) and kubeServiceCIDR (%s) must list the same primary IP family first",
+				p, cfg.KubePodsCIDR, cfg.KubeServiceCIDR))
+		}
+		for _, podsNet := range podsNets {
+			for _, serviceNet := range serviceNets {
+				if podsNet.Contains(serviceNet.IP) || serviceNet.Contains(podsNet.IP) {
+					verrs.Add(fmt.Sprintf("%s: kubePodsCIDR (%s) and kubeServiceCIDR (%s) must not overlap",
+						p, podsNet, serviceNet))
+				}
 			}
 		}
 	}
@@ -158,3 +169,7 @@ func Validate_Network(cfg *Network, verrs *validation.ValidationErrors, pathPref
 		Validate_HybridnetConfig(cfg.Hybridnet, verrs, path.Join(p, "hybridnet"))
 	}
 }
+
+func isIPv4(n *net.IPNet) bool {
+	return n.IP.To4() != nil
+}
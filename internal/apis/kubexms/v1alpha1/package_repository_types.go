@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// repositoryNameRegex matches the names usable as a yum repository id.
+var repositoryNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// PackageRepositorySpec configures local yum or apt repositories the hosts install their OS
+// packages from, so that package installation works without internet access. The repositories
+// are set up before the packages are installed and removed again afterwards.
+type PackageRepositorySpec struct {
+	// ISO is the path on the hosts of an OS installation image that is mounted and used as a
+	// repository.
+	ISO string `json:"iso,omitempty" yaml:"iso,omitempty"`
+	// MountPath is where the ISO is mounted. It defaults to /mnt/kubexm-iso.
+	MountPath string `json:"mountPath,omitempty" yaml:"mountPath,omitempty"`
+	// Mirrors are internal mirrors of the distribution repositories.
+	Mirrors []PackageMirror `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	// DisableExternal disables the other repositories of the hosts while packages are installed.
+	// It defaults to true.
+	DisableExternal *bool `json:"disableExternal,omitempty" yaml:"disableExternal,omitempty"`
+}
+
+// PackageMirror is a yum or apt repository served over http(s), ftp or from a local directory.
+type PackageMirror struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+	// Suite and Components are used on apt hosts only. Suite defaults to the codename of the
+	// host's release and Components to main.
+	Suite      string   `json:"suite,omitempty" yaml:"suite,omitempty"`
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+	// GPGKey is the URL of the key the packages are signed with. Without it, signatures are not
+	// checked.
+	GPGKey string `json:"gpgKey,omitempty" yaml:"gpgKey,omitempty"`
+}
+
+func SetDefaults_PackageRepositorySpec(spec *PackageRepositorySpec) {
+	if spec.ISO != "" && spec.MountPath == "" {
+		spec.MountPath = common.DefaultPackageRepositoryISOMountPath
+	}
+	if spec.DisableExternal == nil {
+		spec.DisableExternal = helpers.BoolPtr(true)
+	}
+	for i := range spec.Mirrors {
+		spec.Mirrors[i].URL = strings.TrimRight(strings.TrimSpace(spec.Mirrors[i].URL), "/")
+	}
+}
+
+func Validate_PackageRepositorySpec(spec *PackageRepositorySpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if spec.ISO == "" && len(spec.Mirrors) == 0 {
+		verrs.Add(pathPrefix + ": must set an iso or at least one mirror")
+	}
+	if spec.ISO != "" && !filepath.IsAbs(spec.ISO) {
+		verrs.Add(fmt.Sprintf("%s.iso: '%s' must be an absolute path", pathPrefix, spec.ISO))
+	}
+	if spec.MountPath != "" && (!filepath.IsAbs(spec.MountPath) || filepath.Clean(spec.MountPath) == "/") {
+		verrs.Add(fmt.Sprintf("%s.mountPath: '%s' must be an absolute path other than /", pathPrefix, spec.MountPath))
+	}
+
+	names := make(map[string]bool)
+	for i, mirror := range spec.Mirrors {
+		p := fmt.Sprintf("%s.mirrors[%d]", pathPrefix, i)
+		if !repositoryNameRegex.MatchString(mirror.Name) {
+			verrs.Add(fmt.Sprintf("%s.name: '%s' must consist of letters, digits, '_', '.' or '-'", p, mirror.Name))
+		} else if names[mirror.Name] {
+			verrs.Add(fmt.Sprintf("%s.name: duplicate mirror '%s'", p, mirror.Name))
+		}
+		names[mirror.Name] = true
+		if !isValidMirrorURL(mirror.URL) {
+			verrs.Add(fmt.Sprintf("%s.url: '%s' must be a %v URL", p, mirror.URL, common.SupportedPackageMirrorSchemes))
+		}
+		if mirror.GPGKey != "" && !isValidMirrorURL(mirror.GPGKey) {
+			verrs.Add(fmt.Sprintf("%s.gpgKey: '%s' must be a %v URL", p, mirror.GPGKey, common.SupportedPackageMirrorSchemes))
+		}
+		for j, component := range mirror.Components {
+			if strings.TrimSpace(component) == "" || strings.ContainsAny(component, " \t\n") {
+				verrs.Add(fmt.Sprintf("%s.components[%d]: invalid component '%s'", p, j, component))
+			}
+		}
+		if strings.ContainsAny(mirror.Suite, " \t\n") {
+			verrs.Add(fmt.Sprintf("%s.suite: invalid suite '%s'", p, mirror.Suite))
+		}
+	}
+}
+
+// isValidMirrorURL reports whether rawURL can be used as the location of a repository. file URLs
+// have no host, the others need one.
+func isValidMirrorURL(rawURL string) bool {
+	if strings.ContainsAny(rawURL, " \t\n'\"") {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || !helpers.ContainsString(common.SupportedPackageMirrorSchemes, u.Scheme) {
+		return false
+	}
+	if u.Scheme == "file" {
+		return u.Host == "" && strings.HasPrefix(u.Path, "/")
+	}
+	return u.Host != ""
+}
@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+func TestPackageRepositorySpec_Defaults(t *testing.T) {
+	spec := &PackageRepositorySpec{ISO: "/data/Rocky-9.iso", Mirrors: []PackageMirror{{Name: "epel", URL: " http://mirror.lab/epel/ "}}}
+	SetDefaults_PackageRepositorySpec(spec)
+
+	if spec.MountPath != common.DefaultPackageRepositoryISOMountPath {
+		t.Errorf("mountPath = %s", spec.MountPath)
+	}
+	if spec.DisableExternal == nil || !*spec.DisableExternal {
+		t.Error("external repositories should be disabled by default")
+	}
+	if spec.Mirrors[0].URL != "http://mirror.lab/epel" {
+		t.Errorf("url = %q", spec.Mirrors[0].URL)
+	}
+}
+
+func TestPackageRepositorySpec_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec PackageRepositorySpec
+		want string
+	}{
+		{name: "iso", spec: PackageRepositorySpec{ISO: "/data/os.iso", MountPath: "/mnt/iso"}},
+		{name: "mirrors", spec: PackageRepositorySpec{Mirrors: []PackageMirror{
+			{Name: "base", URL: "http://mirror.lab/rocky/9/BaseOS/x86_64/os"},
+			{Name: "local", URL: "file:///srv/repo"},
+		}}},
+		{name: "empty", spec: PackageRepositorySpec{}, want: "must set an iso or at least one mirror"},
+		{name: "relative iso", spec: PackageRepositorySpec{ISO: "os.iso"}, want: "must be an absolute path"},
+		{name: "root mount path", spec: PackageRepositorySpec{ISO: "/os.iso", MountPath: "/"}, want: "mountPath"},
+		{name: "duplicate mirror", spec: PackageRepositorySpec{Mirrors: []PackageMirror{
+			{Name: "base", URL: "http://a.lab/os"}, {Name: "base", URL: "http://b.lab/os"},
+		}}, want: "duplicate mirror"},
+		{name: "invalid name", spec: PackageRepositorySpec{Mirrors: []PackageMirror{{Name: "my repo", URL: "http://a.lab/os"}}}, want: ".name"},
+		{name: "invalid url", spec: PackageRepositorySpec{Mirrors: []PackageMirror{{Name: "base", URL: "mirror.lab/os"}}}, want: ".url"},
+		{name: "relative file url", spec: PackageRepositorySpec{Mirrors: []PackageMirror{{Name: "base", URL: "file://srv/repo"}}}, want: ".url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := &validation.ValidationErrors{}
+			Validate_PackageRepositorySpec(&tt.spec, verrs, "spec.system.packageRepository")
+			if tt.want == "" {
+				if verrs.HasErrors() {
+					t.Errorf("unexpected errors: %v", verrs)
+				}
+				return
+			}
+			if !verrs.HasErrors() || !strings.Contains(verrs.Error(), tt.want) {
+				t.Errorf("errors = %v, want one containing %q", verrs, tt.want)
+			}
+		})
+	}
+}
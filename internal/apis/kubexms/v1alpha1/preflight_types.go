@@ -14,9 +14,23 @@ type Preflight struct {
 	DisableFirewalld *bool   `json:"disableFirewalld,omitempty" yaml:"disableFirewalld,omitempty"`
 	DisableSelinux   *bool   `json:"disableSelinux,omitempty" yaml:"disableSelinux,omitempty"`
 
+	// ControlPlaneResources and WorkerResources set the CPU and memory thresholds checked on
+	// control-plane and worker nodes respectively.
+	ControlPlaneResources *ResourceThresholds `json:"controlPlaneResources,omitempty" yaml:"controlPlaneResources,omitempty"`
+	WorkerResources       *ResourceThresholds `json:"workerResources,omitempty" yaml:"workerResources,omitempty"`
+
 	SkipChecks []string `json:"skipChecks,omitempty" yaml:"skipChecks,omitempty"`
 }
 
+// ResourceThresholds is the minimum and recommended size of a node. A node below the minimum
+// fails the preflight; a node below the recommended size only produces a warning.
+type ResourceThresholds struct {
+	MinCPUCores         *int32  `json:"minCPUCores,omitempty" yaml:"minCPUCores,omitempty"`
+	MinMemoryMB         *uint64 `json:"minMemoryMB,omitempty" yaml:"minMemoryMB,omitempty"`
+	RecommendedCPUCores *int32  `json:"recommendedCPUCores,omitempty" yaml:"recommendedCPUCores,omitempty"`
+	RecommendedMemoryMB *uint64 `json:"recommendedMemoryMB,omitempty" yaml:"recommendedMemoryMB,omitempty"`
+}
+
 func SetDefaults_Preflight(cfg *Preflight) {
 	if cfg == nil {
 		return
@@ -36,6 +50,34 @@ func SetDefaults_Preflight(cfg *Preflight) {
 	if cfg.MinCPUCores == nil {
 		cfg.MinCPUCores = helpers.Int32Ptr(common.DefaultMinCPUCores)
 	}
+	if cfg.ControlPlaneResources == nil {
+		cfg.ControlPlaneResources = &ResourceThresholds{}
+	}
+	SetDefaults_ResourceThresholds(cfg.ControlPlaneResources, common.DefaultControlPlaneMinCPUCores, common.DefaultControlPlaneMinMemoryMB,
+		common.DefaultControlPlaneRecommendedCPUCores, common.DefaultControlPlaneRecommendedMemoryMB)
+	if cfg.WorkerResources == nil {
+		cfg.WorkerResources = &ResourceThresholds{}
+	}
+	SetDefaults_ResourceThresholds(cfg.WorkerResources, common.DefaultWorkerMinCPUCores, common.DefaultWorkerMinMemoryMB,
+		common.DefaultWorkerRecommendedCPUCores, common.DefaultWorkerRecommendedMemoryMB)
+}
+
+func SetDefaults_ResourceThresholds(cfg *ResourceThresholds, minCPU int32, minMemoryMB uint64, recommendedCPU int32, recommendedMemoryMB uint64) {
+	if cfg == nil {
+		return
+	}
+	if cfg.MinCPUCores == nil {
+		cfg.MinCPUCores = helpers.Int32Ptr(minCPU)
+	}
+	if cfg.MinMemoryMB == nil {
+		cfg.MinMemoryMB = helpers.Uint64Ptr(minMemoryMB)
+	}
+	if cfg.RecommendedCPUCores == nil {
+		cfg.RecommendedCPUCores = helpers.Int32Ptr(max(recommendedCPU, *cfg.MinCPUCores))
+	}
+	if cfg.RecommendedMemoryMB == nil {
+		cfg.RecommendedMemoryMB = helpers.Uint64Ptr(max(recommendedMemoryMB, *cfg.MinMemoryMB))
+	}
 }
 
 func Validate_Preflight(cfg *Preflight, verrs *validation.ValidationErrors, pathPrefix string) {
@@ -48,6 +90,8 @@ func Validate_Preflight(cfg *Preflight, verrs *validation.ValidationErrors, path
 	if cfg.MinMemoryMB != nil && *cfg.MinMemoryMB <= 0 {
 		verrs.Add(pathPrefix + ".minMemoryMB: must be positive if specified, got " + fmt.Sprintf("%d", *cfg.MinMemoryMB))
 	}
+	Validate_ResourceThresholds(cfg.ControlPlaneResources, verrs, pathPrefix+".controlPlaneResources")
+	Validate_ResourceThresholds(cfg.WorkerResources, verrs, pathPrefix+".workerResources")
 	for i, checkToSkip := range cfg.SkipChecks {
 		if !helpers.ContainsString(common.SupportedChecks, checkToSkip) {
 			verrs.Add(fmt.Sprintf("%s.skipChecks[%d]: unsupported check '%s', must be one of %v",
@@ -56,3 +100,23 @@ func Validate_Preflight(cfg *Preflight, verrs *validation.ValidationErrors, path
 	}
 
 }
+
+func Validate_ResourceThresholds(cfg *ResourceThresholds, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg == nil {
+		return
+	}
+	if cfg.MinCPUCores != nil && *cfg.MinCPUCores <= 0 {
+		verrs.Add(fmt.Sprintf("%s.minCPUCores: must be positive if specified, got %d", pathPrefix, *cfg.MinCPUCores))
+	}
+	if cfg.MinMemoryMB != nil && *cfg.MinMemoryMB == 0 {
+		verrs.Add(pathPrefix + ".minMemoryMB: must be positive if specified, got 0")
+	}
+	if cfg.MinCPUCores != nil && cfg.RecommendedCPUCores != nil && *cfg.RecommendedCPUCores < *cfg.MinCPUCores {
+		verrs.Add(fmt.Sprintf("%s.recommendedCPUCores: must not be less than minCPUCores (%d), got %d",
+			pathPrefix, *cfg.MinCPUCores, *cfg.RecommendedCPUCores))
+	}
+	if cfg.MinMemoryMB != nil && cfg.RecommendedMemoryMB != nil && *cfg.RecommendedMemoryMB < *cfg.MinMemoryMB {
+		verrs.Add(fmt.Sprintf("%s.recommendedMemoryMB: must not be less than minMemoryMB (%d), got %d",
+			pathPrefix, *cfg.MinMemoryMB, *cfg.RecommendedMemoryMB))
+	}
+}
@@ -3,14 +3,21 @@ package v1alpha1
 import (
 	"encoding/base64"
 	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
-	"path"
-	"strings"
 )
 
 type Registry struct {
+	// Type is "external" to use mirroring.privateRegistry as is, or "local" to have kubexm run a
+	// registry on the first registry role host, push the cluster images into it and point every
+	// node at it.
+	Type                  string                         `json:"type,omitempty" yaml:"type,omitempty"`
 	MirroringAndRewriting *RegistryMirroringAndRewriting `json:"mirroring,omitempty" yaml:"mirroring,omitempty"`
 	Auths                 map[string]RegistryAuth        `json:"auths,omitempty" yaml:"auths,omitempty"`
 	LocalDeployment       *LocalRegistryDeployment       `json:"local,omitempty" yaml:"local,omitempty"`
@@ -26,6 +33,7 @@ type LocalRegistryDeployment struct {
 	Type              string `json:"type,omitempty" yaml:"type,omitempty"`
 	DeleteDataOnClean bool   `json:"deleteDataOnClean,omitempty" yaml:"deleteDataOnClean,omitempty"`
 	DataRoot          string `json:"dataRoot,omitempty" yaml:"registryDataDir,omitempty"`
+	Port              int    `json:"port,omitempty" yaml:"port,omitempty"`
 }
 
 type RegistryAuth struct {
@@ -52,6 +60,9 @@ func SetDefaults_Registry(cfg *Registry) {
 	if cfg == nil {
 		return
 	}
+	if cfg.Type == "" {
+		cfg.Type = common.RegistryDeployTypeExternal
+	}
 	if cfg.MirroringAndRewriting == nil {
 		cfg.MirroringAndRewriting = &RegistryMirroringAndRewriting{}
 	}
@@ -69,9 +80,63 @@ func SetDefaults_Registry(cfg *Registry) {
 	if cfg.LocalDeployment == nil {
 		cfg.LocalDeployment = &LocalRegistryDeployment{}
 	}
+	if cfg.Type == common.RegistryDeployTypeLocal && cfg.LocalDeployment.Type == "" {
+		cfg.LocalDeployment.Type = common.RegistryTypeDockerRegistry
+	}
 	SetDefaults_LocalRegistryDeployment(cfg.LocalDeployment)
 }
 
+// SetDefaults_LocalRegistryAddress points mirroring.privateRegistry at the registry kubexm deploys
+// when registry.type is local and no address was given.
+func SetDefaults_LocalRegistryAddress(spec *ClusterSpec) {
+	if spec.Registry == nil || spec.Registry.Type != common.RegistryDeployTypeLocal {
+		return
+	}
+	if spec.Registry.MirroringAndRewriting == nil {
+		spec.Registry.MirroringAndRewriting = &RegistryMirroringAndRewriting{}
+	}
+	if spec.Registry.MirroringAndRewriting.PrivateRegistry != "" {
+		return
+	}
+	host := LocalRegistryHost(spec)
+	if host == nil || spec.Registry.LocalDeployment == nil {
+		return
+	}
+	address := host.InternalAddress
+	if address == "" {
+		address = host.Address
+	}
+	spec.Registry.MirroringAndRewriting.PrivateRegistry = net.JoinHostPort(address, strconv.Itoa(spec.Registry.LocalDeployment.Port))
+}
+
+// LocalRegistryHost returns the host that runs the local registry, which is the first host of the
+// registry role group, or nil if there is none.
+func LocalRegistryHost(spec *ClusterSpec) *HostSpec {
+	if spec == nil || spec.RoleGroups == nil || len(spec.RoleGroups.Registry) == 0 {
+		return nil
+	}
+	for i := range spec.Hosts {
+		if spec.Hosts[i].Name == spec.RoleGroups.Registry[0] {
+			return &spec.Hosts[i]
+		}
+	}
+	return nil
+}
+
+// IsPlainHTTP reports whether the registry at server is reached over plain HTTP, either because its
+// auth entry says so or because it is the registry:2 container of a local deployment.
+func (r *Registry) IsPlainHTTP(server string) bool {
+	if r == nil {
+		return false
+	}
+	if auth, ok := r.Auths[server]; ok && auth.PlainHTTP != nil && *auth.PlainHTTP {
+		return true
+	}
+	return r.Type == common.RegistryDeployTypeLocal &&
+		r.LocalDeployment != nil && r.LocalDeployment.Type == common.RegistryTypeDockerRegistry &&
+		r.MirroringAndRewriting != nil && r.MirroringAndRewriting.PrivateRegistry == server
+}
+
 func SetDefaults_RegistryMirroringAndRewriting(cfg *RegistryMirroringAndRewriting) {
 	if cfg == nil {
 		return
@@ -108,7 +173,10 @@ func SetDefaults_LocalRegistryDeployment(cfg *LocalRegistryDeployment) {
 		cfg.Type = common.DefaultRegistryType
 	}
 	if cfg.DataRoot == "" {
-		cfg.DataRoot = fmt.Sprintf("%s/%s", common.DefaultInstallRoot, common.DefaultRegistryType)
+		cfg.DataRoot = fmt.Sprintf("%s/%s", common.DefaultInstallRoot, cfg.Type)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = common.DefaultLocalRegistryPort
 	}
 }
 
@@ -118,6 +186,13 @@ func Validate_Registry(cfg *Registry, verrs *validation.ValidationErrors, pathPr
 	}
 	p := path.Join(pathPrefix)
 
+	if cfg.Type != "" && cfg.Type != common.RegistryDeployTypeExternal && cfg.Type != common.RegistryDeployTypeLocal {
+		verrs.Add(fmt.Sprintf("%s.type: invalid type '%s', must be one of [%s %s]", p, cfg.Type, common.RegistryDeployTypeExternal, common.RegistryDeployTypeLocal))
+	}
+	if cfg.Type == common.RegistryDeployTypeLocal && cfg.LocalDeployment != nil && cfg.LocalDeployment.Type != common.RegistryTypeDockerRegistry {
+		verrs.Add(fmt.Sprintf("%s.local.type: '%s' cannot be deployed with the cluster, only '%s' can; create other registries with 'kubexm create registry'", p, cfg.LocalDeployment.Type, common.RegistryTypeDockerRegistry))
+	}
+
 	if cfg.MirroringAndRewriting != nil {
 		Validate_RegistryMirroringAndRewriting(cfg.MirroringAndRewriting, verrs, path.Join(p, "mirroring"))
 	}
@@ -203,4 +278,7 @@ func Validate_LocalRegistryDeployment(cfg *LocalRegistryDeployment, verrs *valid
 	if strings.TrimSpace(cfg.DataRoot) == "" {
 		verrs.Add(pathPrefix + ".dataRoot: is required for local deployment")
 	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		verrs.Add(fmt.Sprintf("%s.port: invalid port %d", pathPrefix, cfg.Port))
+	}
 }
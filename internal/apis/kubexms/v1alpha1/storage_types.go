@@ -15,6 +15,8 @@ type Storage struct {
 	NFS                 *NFSConfig      `json:"nfs,omitempty" yaml:"nfs,omitempty"`
 	RookCeph            *RookCephConfig `json:"rookCeph,omitempty" yaml:"rookCeph,omitempty"`
 	Longhorn            *LonghornConfig `json:"longhorn,omitempty" yaml:"longhorn,omitempty"`
+	// LocalPathProvisioner configures the local-path-provisioner addon, installed when it is listed in spec.addons.
+	LocalPathProvisioner *LocalPathProvisionerConfig `json:"localPathProvisioner,omitempty" yaml:"localPathProvisioner,omitempty"`
 }
 
 type OpenEBSConfig struct {
@@ -74,6 +76,12 @@ type LonghornConfig struct {
 	PurgeDataOnUninstall *bool       `json:"purgeDataOnUninstall,omitempty" yaml:"purgeDataOnUninstall,omitempty"`
 }
 
+type LocalPathProvisionerConfig struct {
+	StoragePath      *string `json:"storagePath,omitempty" yaml:"storagePath,omitempty"`
+	StorageClassName *string `json:"storageClassName,omitempty" yaml:"storageClassName,omitempty"`
+	IsDefaultClass   *bool   `json:"isDefaultClass,omitempty" yaml:"isDefaultClass,omitempty"`
+}
+
 func SetDefaults_Storage(cfg *Storage) {
 	if cfg == nil {
 		return
@@ -101,6 +109,11 @@ func SetDefaults_Storage(cfg *Storage) {
 		cfg.Longhorn = &LonghornConfig{}
 	}
 	SetDefaults_Longhorn(cfg.Longhorn)
+
+	if cfg.LocalPathProvisioner == nil {
+		cfg.LocalPathProvisioner = &LocalPathProvisionerConfig{}
+	}
+	SetDefaults_LocalPathProvisioner(cfg.LocalPathProvisioner)
 }
 
 func SetDefaults_OpenEBS(cfg *OpenEBSConfig) {
@@ -166,6 +179,18 @@ func SetDefaults_Longhorn(cfg *LonghornConfig) {
 	}
 }
 
+func SetDefaults_LocalPathProvisioner(cfg *LocalPathProvisionerConfig) {
+	if cfg.StoragePath == nil {
+		cfg.StoragePath = helpers.StrPtr(common.DefaultLocalPathProvisionerStoragePath)
+	}
+	if cfg.StorageClassName == nil {
+		cfg.StorageClassName = helpers.StrPtr(common.DefaultLocalPathProvisionerStorageClassName)
+	}
+	if cfg.IsDefaultClass == nil {
+		cfg.IsDefaultClass = helpers.BoolPtr(common.DefaultLocalPathProvisionerIsDefaultClass)
+	}
+}
+
 func Validate_Storage(cfg *Storage, verrs *validation.ValidationErrors, pathPrefix string) {
 	if cfg == nil {
 		return
@@ -190,6 +215,10 @@ func Validate_Storage(cfg *Storage, verrs *validation.ValidationErrors, pathPref
 		Validate_Longhorn(cfg.Longhorn, verrs, path.Join(p, common.StorageComponentLonghorn))
 	}
 
+	if cfg.LocalPathProvisioner != nil {
+		Validate_LocalPathProvisioner(cfg.LocalPathProvisioner, verrs, path.Join(p, "localPathProvisioner"))
+	}
+
 	if cfg.DefaultStorageClass != nil && *cfg.DefaultStorageClass != "" {
 		if len(enabledProviders) == 0 {
 			verrs.Add(fmt.Sprintf("%s.defaultStorageClass: cannot be set when no storage provider is enabled", p))
@@ -256,3 +285,12 @@ func Validate_Longhorn(cfg *LonghornConfig, verrs *validation.ValidationErrors,
 		verrs.Add(pathPrefix + ".defaultDataPath: cannot be empty if specified")
 	}
 }
+
+func Validate_LocalPathProvisioner(cfg *LocalPathProvisionerConfig, verrs *validation.ValidationErrors, pathPrefix string) {
+	if cfg.StoragePath != nil && !strings.HasPrefix(*cfg.StoragePath, "/") {
+		verrs.Add(fmt.Sprintf("%s.storagePath: must be an absolute path, got '%s'", pathPrefix, *cfg.StoragePath))
+	}
+	if cfg.StorageClassName != nil && !helpers.IsValidK8sName(*cfg.StorageClassName) {
+		verrs.Add(fmt.Sprintf("%s.storageClassName: '%s' is not a valid Kubernetes name", pathPrefix, *cfg.StorageClassName))
+	}
+}
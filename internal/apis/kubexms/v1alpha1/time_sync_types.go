@@ -0,0 +1,40 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// TimeSyncSpec configures the time synchronization of the hosts. Clock skew between the hosts
+// breaks etcd and the validation of TLS certificates.
+type TimeSyncSpec struct {
+	// Service is the daemon that syncs the clock with spec.system.ntpServers: chrony, the
+	// default, or systemd-timesyncd. Hosts listed in ntpServers serve time to the others, which
+	// only chrony can do.
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+	// MaxOffset is the largest offset between the clock of a host and its NTP source that
+	// preflight accepts when ntpServers are set. It defaults to 500ms.
+	MaxOffset time.Duration `json:"maxOffset,omitempty" yaml:"maxOffset,omitempty"`
+}
+
+func SetDefaults_TimeSyncSpec(spec *TimeSyncSpec) {
+	if spec.Service == "" {
+		spec.Service = common.TimeSyncServiceChrony
+	}
+	if spec.MaxOffset == 0 {
+		spec.MaxOffset = common.DefaultMaxClockOffset
+	}
+}
+
+func Validate_TimeSyncSpec(spec *TimeSyncSpec, verrs *validation.ValidationErrors, pathPrefix string) {
+	if !helpers.ContainsString(common.SupportedTimeSyncServices, spec.Service) {
+		verrs.Add(fmt.Sprintf("%s.service: unsupported service '%s', must be one of %v", pathPrefix, spec.Service, common.SupportedTimeSyncServices))
+	}
+	if spec.MaxOffset <= 0 {
+		verrs.Add(fmt.Sprintf("%s.maxOffset: must be positive, got %s", pathPrefix, spec.MaxOffset))
+	}
+}
@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// compatibility lists the oldest component versions known to work with a Kubernetes minor release.
+type compatibility struct {
+	minEtcd       string
+	minContainerd string
+}
+
+// compatibilityMatrix is keyed by Kubernetes minor version. The etcd minimums are the versions
+// kubeadm ships with each release. Releases before 1.26 still speak CRI v1alpha2, which containerd
+// 1.5 serves; containerd 1.6 is the first release serving CRI v1, the only version 1.26 accepts.
+var compatibilityMatrix = map[string]compatibility{
+	"1.22": {minEtcd: "3.5.0", minContainerd: "1.5.0"},
+	"1.23": {minEtcd: "3.5.1", minContainerd: "1.5.0"},
+	"1.24": {minEtcd: "3.5.3", minContainerd: "1.5.0"},
+	"1.25": {minEtcd: "3.5.4", minContainerd: "1.5.0"},
+	"1.26": {minEtcd: "3.5.6", minContainerd: "1.6.0"},
+	"1.27": {minEtcd: "3.5.7", minContainerd: "1.6.0"},
+	"1.28": {minEtcd: "3.5.9", minContainerd: "1.6.0"},
+	"1.29": {minEtcd: "3.5.10", minContainerd: "1.6.0"},
+	"1.30": {minEtcd: "3.5.12", minContainerd: "1.6.0"},
+	"1.31": {minEtcd: "3.5.15", minContainerd: "1.6.0"},
+	"1.32": {minEtcd: "3.5.16", minContainerd: "1.6.0"},
+	"1.33": {minEtcd: "3.5.21", minContainerd: "1.6.0"},
+}
+
+// supportedMinors returns the Kubernetes minor versions of the matrix in ascending order.
+func supportedMinors() []string {
+	minors := make([]string, 0, len(compatibilityMatrix))
+	for minor := range compatibilityMatrix {
+		minors = append(minors, minor)
+	}
+	sort.Slice(minors, func(i, j int) bool {
+		return semver.MustParse(minors[i]).LessThan(semver.MustParse(minors[j]))
+	})
+	return minors
+}
+
+// parseVersion parses a version such as "v3.5.9-0" or "1.7.13", ignoring pre-release and build
+// suffixes, which image tags use for packaging revisions.
+func parseVersion(version string) (*semver.Version, error) {
+	v, err := semver.NewVersion(strings.TrimSpace(version))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version '%s': %w", version, err)
+	}
+	return semver.New(v.Major(), v.Minor(), v.Patch(), "", ""), nil
+}
+
+// atLeast reports whether version is minimum or newer. Versions that cannot be parsed are left to
+// the field validation and reported as compatible here.
+func atLeast(version, minimum string) bool {
+	v, err := parseVersion(version)
+	if err != nil {
+		return true
+	}
+	return !v.LessThan(semver.MustParse(minimum))
+}
@@ -0,0 +1,223 @@
+// Package validation checks a v1alpha1 Cluster as a whole before anything runs. On top of the
+// field validation of v1alpha1.Validate_Cluster it checks constraints that span sections, such as
+// host addresses inside the pod or service CIDR, the etcd member count, role assignments and the
+// component version compatibility matrix. Every problem is reported with its field path, all at once.
+package validation
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+	verrors "github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+// DefaultAndValidate applies the v1alpha1 defaults to cluster and validates the result.
+func DefaultAndValidate(cluster *v1alpha1.Cluster) error {
+	if cluster == nil {
+		return fmt.Errorf("cluster configuration is nil")
+	}
+	v1alpha1.SetDefaults_Cluster(cluster)
+	return Validate(cluster)
+}
+
+// Validate runs the field validation and the semantic checks of cluster. It returns nil or a
+// *verrors.ValidationErrors holding every problem found, one "field.path: message" per line.
+func Validate(cluster *v1alpha1.Cluster) error {
+	if cluster == nil {
+		return fmt.Errorf("cluster configuration is nil")
+	}
+	verrs := &verrors.ValidationErrors{}
+	v1alpha1.Validate_Cluster(cluster, verrs)
+	if spec := cluster.Spec; spec != nil {
+		validateHostIdentity(spec, verrs)
+		validateAddressesOutsideCIDRs(spec, verrs)
+		validateRoles(spec, verrs)
+		validateEtcdMembers(spec, verrs)
+		validateVersions(spec, verrs)
+	}
+	if verrs.HasErrors() {
+		return verrs
+	}
+	return nil
+}
+
+// hostAddresses returns the address and the internal addresses of host, without duplicates.
+func hostAddresses(host *v1alpha1.HostSpec) []string {
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, raw := range append([]string{host.Address}, strings.Split(host.InternalAddress, ",")...) {
+		addr := strings.TrimSpace(raw)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// addressField is the field of host that holds addr.
+func addressField(hostPath string, host *v1alpha1.HostSpec, addr string) string {
+	if strings.TrimSpace(host.Address) == addr {
+		return hostPath + ".address"
+	}
+	return hostPath + ".internalAddress"
+}
+
+// validateHostIdentity rejects host names that differ only in case, as they resolve to the same
+// node name, and addresses claimed by two hosts. Exact duplicates of names and of the address
+// field are already reported by the field validation.
+func validateHostIdentity(spec *v1alpha1.ClusterSpec, verrs *verrors.ValidationErrors) {
+	names := make(map[string]string)
+	owners := make(map[string]int)
+	for i := range spec.Hosts {
+		host := &spec.Hosts[i]
+		hostPath := fmt.Sprintf("spec.hosts[%d]", i)
+		if host.Name != "" {
+			key := strings.ToLower(host.Name)
+			if other, ok := names[key]; ok && other != host.Name {
+				verrs.AddError(hostPath+".name", fmt.Sprintf("host name '%s' differs from '%s' only in case", host.Name, other))
+			} else if !ok {
+				names[key] = host.Name
+			}
+		}
+		for _, addr := range hostAddresses(host) {
+			j, ok := owners[addr]
+			if !ok {
+				owners[addr] = i
+				continue
+			}
+			other := &spec.Hosts[j]
+			if strings.TrimSpace(other.Address) == addr && strings.TrimSpace(host.Address) == addr {
+				continue
+			}
+			verrs.AddError(addressField(hostPath, host, addr), fmt.Sprintf("address '%s' is already used by host '%s'", addr, other.Name))
+		}
+	}
+}
+
+// validateAddressesOutsideCIDRs rejects host and control plane endpoint addresses inside the pod or
+// service CIDR, which would be routed into the cluster network instead of reaching the node.
+func validateAddressesOutsideCIDRs(spec *v1alpha1.ClusterSpec, verrs *verrors.ValidationErrors) {
+	if spec.Network == nil {
+		return
+	}
+	type cidr struct {
+		field string
+		net   *net.IPNet
+	}
+	var cidrs []cidr
+	if nets, err := helpers.ParseDualStackCIDRs(spec.Network.KubePodsCIDR); err == nil {
+		for _, n := range nets {
+			cidrs = append(cidrs, cidr{field: "kubePodsCIDR", net: n})
+		}
+	}
+	if nets, err := helpers.ParseDualStackCIDRs(spec.Network.KubeServiceCIDR); err == nil {
+		for _, n := range nets {
+			cidrs = append(cidrs, cidr{field: "kubeServiceCIDR", net: n})
+		}
+	}
+	check := func(fieldPath, addr string) {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return
+		}
+		for _, c := range cidrs {
+			if c.net.Contains(ip) {
+				verrs.AddError(fieldPath, fmt.Sprintf("address '%s' overlaps spec.network.%s (%s)", addr, c.field, c.net))
+			}
+		}
+	}
+	for i := range spec.Hosts {
+		host := &spec.Hosts[i]
+		hostPath := fmt.Sprintf("spec.hosts[%d]", i)
+		for _, addr := range hostAddresses(host) {
+			check(addressField(hostPath, host, addr), addr)
+		}
+	}
+	if spec.ControlPlaneEndpoint != nil && spec.ControlPlaneEndpoint.Address != "" {
+		check("spec.controlPlaneEndpoint.address", spec.ControlPlaneEndpoint.Address)
+	}
+}
+
+// validateRoles checks that the etcd role group matches the etcd deployment type.
+func validateRoles(spec *v1alpha1.ClusterSpec, verrs *verrors.ValidationErrors) {
+	if spec.Etcd == nil || spec.RoleGroups == nil {
+		return
+	}
+	switch spec.Etcd.Type {
+	case string(common.EtcdDeploymentTypeKubexm):
+		if len(spec.RoleGroups.Etcd) == 0 {
+			verrs.AddError("spec.roleGroups.etcd", "at least one host is required when etcd.type is 'kubexm'")
+		}
+	case string(common.EtcdDeploymentTypeExternal):
+		if len(spec.RoleGroups.Etcd) > 0 {
+			verrs.AddError("spec.roleGroups.etcd", "must be empty when etcd.type is 'external', as kubexm does not manage the etcd hosts")
+		}
+	}
+}
+
+// validateEtcdMembers requires an odd number of etcd members: an even member count needs the same
+// quorum as the next odd count, so the extra member adds load without tolerating more failures.
+// Stacked etcd deployed by kubeadm runs one member per master.
+func validateEtcdMembers(spec *v1alpha1.ClusterSpec, verrs *verrors.ValidationErrors) {
+	if spec.Etcd == nil || spec.RoleGroups == nil {
+		return
+	}
+	var field string
+	var members int
+	switch spec.Etcd.Type {
+	case string(common.EtcdDeploymentTypeKubexm):
+		field, members = "spec.roleGroups.etcd", len(spec.RoleGroups.Etcd)
+	case string(common.EtcdDeploymentTypeKubeadm):
+		field, members = "spec.roleGroups.master", len(spec.RoleGroups.Master)
+	default:
+		return
+	}
+	if members > 0 && members%2 == 0 {
+		verrs.AddError(field, fmt.Sprintf("etcd needs an odd number of members, got %d", members))
+	}
+}
+
+// validateVersions checks the Kubernetes version against the compatibility matrix and the etcd and
+// containerd versions against its minimums.
+func validateVersions(spec *v1alpha1.ClusterSpec, verrs *verrors.ValidationErrors) {
+	if spec.Kubernetes == nil || spec.Kubernetes.Version == "" {
+		return
+	}
+	kubeVersion, err := parseVersion(spec.Kubernetes.Version)
+	if err != nil {
+		return
+	}
+	minor := fmt.Sprintf("%d.%d", kubeVersion.Major(), kubeVersion.Minor())
+	compat, ok := compatibilityMatrix[minor]
+	if !ok {
+		minors := supportedMinors()
+		verrs.AddError("spec.kubernetes.version", fmt.Sprintf("version '%s' is not in the compatibility matrix, supported minor versions are v%s to v%s",
+			spec.Kubernetes.Version, minors[0], minors[len(minors)-1]))
+		return
+	}
+
+	if spec.Etcd != nil && spec.Etcd.Type != string(common.EtcdDeploymentTypeExternal) && spec.Etcd.Version != "" &&
+		!atLeast(spec.Etcd.Version, compat.minEtcd) {
+		verrs.AddError("spec.etcd.version", fmt.Sprintf("etcd '%s' is older than v%s, the minimum for Kubernetes v%s",
+			spec.Etcd.Version, compat.minEtcd, minor))
+	}
+
+	cr := spec.Kubernetes.ContainerRuntime
+	if cr == nil || cr.Type != common.RuntimeTypeContainerd {
+		return
+	}
+	field, version := "spec.kubernetes.containerRuntime.version", cr.Version
+	if version == "" && cr.Containerd != nil {
+		field, version = "spec.kubernetes.containerRuntime.containerd.version", cr.Containerd.Version
+	}
+	if version != "" && !atLeast(version, compat.minContainerd) {
+		verrs.AddError(field, fmt.Sprintf("containerd '%s' is older than v%s, the minimum for Kubernetes v%s",
+			version, compat.minContainerd, minor))
+	}
+}
@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+func testCluster() *v1alpha1.Cluster {
+	cluster := &v1alpha1.Cluster{
+		Spec: &v1alpha1.ClusterSpec{
+			Hosts: []v1alpha1.HostSpec{
+				{Name: "master1", Address: "192.168.1.11", Password: "secret"},
+				{Name: "master2", Address: "192.168.1.12", Password: "secret"},
+				{Name: "master3", Address: "192.168.1.13", Password: "secret"},
+				{Name: "worker1", Address: "192.168.1.21", Password: "secret"},
+			},
+			RoleGroups: &v1alpha1.RoleGroupsSpec{
+				Master: []string{"master1", "master2", "master3"},
+				Etcd:   []string{"master1", "master2", "master3"},
+				Worker: []string{"worker1"},
+			},
+			ControlPlaneEndpoint: &v1alpha1.ControlPlaneEndpointSpec{Address: "192.168.1.100"},
+			Kubernetes:           &v1alpha1.Kubernetes{Version: "v1.28.5", ClusterName: "cluster.local"},
+			Network: &v1alpha1.Network{
+				Plugin:          string(common.CNITypeCalico),
+				KubePodsCIDR:    "10.244.0.0/16",
+				KubeServiceCIDR: "10.96.0.0/12",
+			},
+		},
+	}
+	cluster.APIVersion = common.DefaultAPIVersion
+	cluster.Kind = common.DefaultKind
+	cluster.Name = "test"
+	return cluster
+}
+
+func TestDefaultAndValidate_Valid(t *testing.T) {
+	if err := DefaultAndValidate(testCluster()); err != nil {
+		t.Fatalf("expected the cluster to be valid, got:\n%v", err)
+	}
+	for _, version := range []string{"v1.22.17", "v1.31.0", "v1.33.1"} {
+		cluster := testCluster()
+		cluster.Spec.Kubernetes.Version = version
+		if err := DefaultAndValidate(cluster); err != nil {
+			t.Errorf("expected Kubernetes %s to be valid, got:\n%v", version, err)
+		}
+	}
+}
+
+func TestDefaultAndValidate_SemanticErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *v1alpha1.Cluster)
+		want   string
+	}{
+		{
+			name:   "host address in pod CIDR",
+			mutate: func(c *v1alpha1.Cluster) { c.Spec.Hosts[3].Address = "10.244.1.5" },
+			want:   "spec.hosts[3].address: address '10.244.1.5' overlaps spec.network.kubePodsCIDR (10.244.0.0/16)",
+		},
+		{
+			name:   "control plane endpoint in service CIDR",
+			mutate: func(c *v1alpha1.Cluster) { c.Spec.ControlPlaneEndpoint.Address = "10.96.0.10" },
+			want:   "spec.controlPlaneEndpoint.address: address '10.96.0.10' overlaps spec.network.kubeServiceCIDR",
+		},
+		{
+			name:   "even etcd member count",
+			mutate: func(c *v1alpha1.Cluster) { c.Spec.RoleGroups.Etcd = []string{"master1", "master2"} },
+			want:   "spec.roleGroups.etcd: etcd needs an odd number of members, got 2",
+		},
+		{
+			name: "even stacked etcd member count",
+			mutate: func(c *v1alpha1.Cluster) {
+				c.Spec.Etcd = &v1alpha1.Etcd{Type: string(common.EtcdDeploymentTypeKubeadm)}
+				c.Spec.RoleGroups.Master = []string{"master1", "master2"}
+				c.Spec.RoleGroups.Worker = []string{"worker1", "master3"}
+			},
+			want: "spec.roleGroups.master: etcd needs an odd number of members, got 2",
+		},
+		{
+			name: "kubexm etcd without etcd hosts",
+			mutate: func(c *v1alpha1.Cluster) {
+				c.Spec.RoleGroups.Etcd = nil
+				c.Spec.RoleGroups.Worker = append(c.Spec.RoleGroups.Worker, "master1")
+			},
+			want: "spec.roleGroups.etcd: at least one host is required when etcd.type is 'kubexm'",
+		},
+		{
+			name:   "kubernetes version outside the matrix",
+			mutate: func(c *v1alpha1.Cluster) { c.Spec.Kubernetes.Version = "v1.21.14" },
+			want:   "spec.kubernetes.version: version 'v1.21.14' is not in the compatibility matrix, supported minor versions are v1.22 to v1.33",
+		},
+		{
+			name:   "etcd older than the matrix minimum",
+			mutate: func(c *v1alpha1.Cluster) { c.Spec.Etcd = &v1alpha1.Etcd{Version: "v3.5.6-0"} },
+			want:   "spec.etcd.version: etcd 'v3.5.6-0' is older than v3.5.9, the minimum for Kubernetes v1.28",
+		},
+		{
+			name: "internal address used by another host",
+			mutate: func(c *v1alpha1.Cluster) {
+				c.Spec.Hosts[1].InternalAddress = "192.168.1.11"
+			},
+			want: "spec.hosts[1].internalAddress: address '192.168.1.11' is already used by host 'master1'",
+		},
+		{
+			name: "host names differing in case",
+			mutate: func(c *v1alpha1.Cluster) {
+				c.Spec.Hosts = append(c.Spec.Hosts, v1alpha1.HostSpec{Name: "Worker1", Address: "192.168.1.22", Password: "secret"})
+			},
+			want: "spec.hosts[4].name: host name 'Worker1' differs from 'worker1' only in case",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := testCluster()
+			tt.mutate(cluster)
+			err := DefaultAndValidate(cluster)
+			if err == nil {
+				t.Fatalf("expected a validation error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q in:\n%v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_AggregatesErrors(t *testing.T) {
+	cluster := testCluster()
+	cluster.Spec.Kubernetes.Version = "v1.28.5"
+	cluster.Spec.RoleGroups.Etcd = []string{"master1", "master2"}
+	cluster.Spec.Hosts[3].Address = "10.244.1.5"
+
+	err := DefaultAndValidate(cluster)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) < 2 {
+		t.Errorf("expected every problem to be reported at once, got:\n%v", err)
+	}
+}
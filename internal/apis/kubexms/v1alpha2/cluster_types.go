@@ -0,0 +1,103 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              *ClusterSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// ClusterSpec differs from v1alpha1 in the network CIDR field names, the control plane load
+// balancer and the addons; the other sections are the v1alpha1 types.
+type ClusterSpec struct {
+	Hosts      []v1alpha1.HostSpec      `json:"hosts" yaml:"hosts"`
+	RoleGroups *v1alpha1.RoleGroupsSpec `json:"roleGroups,omitempty" yaml:"roleGroups,omitempty"`
+	Global     *v1alpha1.GlobalSpec     `json:"global,omitempty" yaml:"global,omitempty"`
+	System     *v1alpha1.SystemSpec     `json:"system,omitempty" yaml:"system,omitempty"`
+	Kubernetes *v1alpha1.Kubernetes     `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty"`
+	Etcd       *v1alpha1.Etcd           `json:"etcd,omitempty" yaml:"etcd,omitempty"`
+	DNS        *v1alpha1.DNS            `json:"dns,omitempty" yaml:"dns,omitempty"`
+
+	Network              *Network              `json:"network,omitempty" yaml:"network,omitempty"`
+	ControlPlaneEndpoint *ControlPlaneEndpoint `json:"controlPlaneEndpoint,omitempty" yaml:"controlPlaneEndpoint,omitempty"`
+
+	Storage   *v1alpha1.Storage     `json:"storage,omitempty" yaml:"storage,omitempty"`
+	Registry  *v1alpha1.Registry    `json:"registry,omitempty" yaml:"registry,omitempty"`
+	Gateway   *v1alpha1.GatewaySpec `json:"gateway,omitempty" yaml:"gateway,omitempty"`
+	HelmRepo  *v1alpha1.HelmRepo    `json:"helmRepo,omitempty" yaml:"helmRepo,omitempty"`
+	Addons    map[string]Addon      `json:"addons,omitempty" yaml:"addons,omitempty"`
+	Preflight *v1alpha1.Preflight   `json:"preflight,omitempty" yaml:"preflight,omitempty"`
+	Extra     *v1alpha1.Extra       `json:"extra,omitempty" yaml:"extra,omitempty"`
+	Certs     *v1alpha1.CertSpec    `json:"certs,omitempty" yaml:"certs,omitempty"`
+
+	BinarySources *v1alpha1.BinarySources `json:"binarySources,omitempty" yaml:"binarySources,omitempty"`
+	Hooks         []v1alpha1.Hook         `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+
+	Infrastructure *v1alpha1.Infrastructure `json:"infrastructure,omitempty" yaml:"infrastructure,omitempty"`
+	Bootstrap      *v1alpha1.BootstrapSpec  `json:"bootstrap,omitempty" yaml:"bootstrap,omitempty"`
+}
+
+// Network renames kubePodsCIDR and kubeServiceCIDR to podCIDR and serviceCIDR.
+type Network struct {
+	Plugin      string                    `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	PodCIDR     string                    `json:"podCIDR,omitempty" yaml:"podCIDR,omitempty"`
+	ServiceCIDR string                    `json:"serviceCIDR,omitempty" yaml:"serviceCIDR,omitempty"`
+	Calico      *v1alpha1.CalicoConfig    `json:"calico,omitempty" yaml:"calico,omitempty"`
+	Cilium      *v1alpha1.CiliumConfig    `json:"cilium,omitempty" yaml:"cilium,omitempty"`
+	Flannel     *v1alpha1.FlannelConfig   `json:"flannel,omitempty" yaml:"flannel,omitempty"`
+	KubeOvn     *v1alpha1.KubeOvnConfig   `json:"kubeovn,omitempty" yaml:"kubeovn,omitempty"`
+	Hybridnet   *v1alpha1.HybridnetConfig `json:"hybridnet,omitempty" yaml:"hybridnet,omitempty"`
+	Multus      *v1alpha1.MultusConfig    `json:"multus,omitempty" yaml:"multus,omitempty"`
+}
+
+// ControlPlaneEndpoint uses the same field names in YAML and JSON and replaces the load balancer
+// types, haMode and highAvailability of v1alpha1 with a single load balancer.
+type ControlPlaneEndpoint struct {
+	Domain       string        `json:"domain,omitempty" yaml:"domain,omitempty"`
+	Address      string        `json:"address,omitempty" yaml:"address,omitempty"`
+	Port         int           `json:"port,omitempty" yaml:"port,omitempty"`
+	ExternalDNS  *bool         `json:"externalDNS,omitempty" yaml:"externalDNS,omitempty"`
+	LoadBalancer *LoadBalancer `json:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty"`
+}
+
+// Load balancer modes.
+const (
+	// LoadBalancerModeExternal serves the endpoint address from load balancer hosts or a VIP.
+	LoadBalancerModeExternal = "external"
+	// LoadBalancerModeInternal runs a local proxy to the masters on every worker.
+	LoadBalancerModeInternal = "internal"
+)
+
+// LoadBalancer keeps the control plane endpoint available. Provider is one of kubexm-kh
+// (keepalived and haproxy), kubexm-kn (keepalived and nginx), kube-vip or external in the external
+// mode, and haproxy, nginx or kube-vip in the internal mode. Mode may be left out for every provider
+// but kube-vip: haproxy and nginx are internal, the others external.
+type LoadBalancer struct {
+	Mode       string                     `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Provider   string                     `json:"provider,omitempty" yaml:"provider,omitempty"`
+	HostGroup  string                     `json:"hostGroup,omitempty" yaml:"hostGroup,omitempty"`
+	Keepalived *v1alpha1.KeepalivedConfig `json:"keepalived,omitempty" yaml:"keepalived,omitempty"`
+	HAProxy    *v1alpha1.HAProxyConfig    `json:"haproxy,omitempty" yaml:"haproxy,omitempty"`
+	NginxLB    *v1alpha1.NginxLBConfig    `json:"nginxLB,omitempty" yaml:"nginxLB,omitempty"`
+	KubeVIP    *v1alpha1.KubeVIPConfig    `json:"kubevip,omitempty" yaml:"kubevip,omitempty"`
+}
+
+// Addon is keyed by its name in ClusterSpec.Addons. Addons are installed after the addons they
+// depend on and otherwise in name order.
+type Addon struct {
+	Enabled        *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	DependsOn      []string               `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	Retries        *int32                 `json:"retries,omitempty" yaml:"retries,omitempty"`
+	Delay          *int32                 `json:"delay,omitempty" yaml:"delay,omitempty"`
+	Sources        []v1alpha1.AddonSource `json:"sources,omitempty" yaml:"sources,omitempty"`
+	PreInstall     []string               `json:"preInstall,omitempty" yaml:"preInstall,omitempty"`
+	PostInstall    []string               `json:"postInstall,omitempty" yaml:"postInstall,omitempty"`
+	TimeoutSeconds *int32                 `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+	Values         []string               `json:"values,omitempty" yaml:"values,omitempty"`
+	Wait           *v1alpha1.AddonWait    `json:"wait,omitempty" yaml:"wait,omitempty"`
+}
@@ -0,0 +1,320 @@
+package v1alpha2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// Convert_v1alpha2_Cluster_To_v1alpha1_Cluster converts in to the v1alpha1 Cluster kubexm works with.
+// Sections shared by both versions are not copied, so out refers to the same values as in.
+func Convert_v1alpha2_Cluster_To_v1alpha1_Cluster(in *Cluster, out *v1alpha1.Cluster) error {
+	out.TypeMeta = in.TypeMeta
+	out.APIVersion = common.DefaultAPIVersion
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = nil
+	if in.Spec == nil {
+		return nil
+	}
+	spec := in.Spec
+	out.Spec = &v1alpha1.ClusterSpec{
+		Hosts:         spec.Hosts,
+		RoleGroups:    spec.RoleGroups,
+		Global:        spec.Global,
+		System:        spec.System,
+		Kubernetes:    spec.Kubernetes,
+		Etcd:          spec.Etcd,
+		DNS:           spec.DNS,
+		Storage:       spec.Storage,
+		Registry:      spec.Registry,
+		Gateway:       spec.Gateway,
+		HelmRepo:      spec.HelmRepo,
+		Preflight:     spec.Preflight,
+		Extra:         spec.Extra,
+		Certs:         spec.Certs,
+		BinarySources: spec.BinarySources,
+		Hooks:         spec.Hooks,
+	}
+	out.Spec.Infrastructure = spec.Infrastructure
+	out.Spec.Bootstrap = spec.Bootstrap
+	if n := spec.Network; n != nil {
+		out.Spec.Network = &v1alpha1.Network{
+			Plugin:          n.Plugin,
+			KubePodsCIDR:    n.PodCIDR,
+			KubeServiceCIDR: n.ServiceCIDR,
+			Calico:          n.Calico,
+			Cilium:          n.Cilium,
+			Flannel:         n.Flannel,
+			KubeOvn:         n.KubeOvn,
+			Hybridnet:       n.Hybridnet,
+			Multus:          n.Multus,
+		}
+	}
+	if spec.ControlPlaneEndpoint != nil {
+		endpoint, err := convertControlPlaneEndpointToV1alpha1(spec.ControlPlaneEndpoint)
+		if err != nil {
+			return err
+		}
+		out.Spec.ControlPlaneEndpoint = endpoint
+	}
+	addons, err := convertAddonsToV1alpha1(spec.Addons)
+	if err != nil {
+		return err
+	}
+	out.Spec.Addons = addons
+	return nil
+}
+
+// loadBalancerMode returns the mode of lb, derived from its provider when it is not set.
+func loadBalancerMode(lb *LoadBalancer) (string, error) {
+	if lb.Mode != "" {
+		return lb.Mode, nil
+	}
+	switch lb.Provider {
+	case string(common.InternalLBTypeHAProxy), string(common.InternalLBTypeNginx):
+		return LoadBalancerModeInternal, nil
+	case string(common.ExternalLBTypeKubeVIP):
+		return "", fmt.Errorf("spec.controlPlaneEndpoint.loadBalancer.mode: must be set to '%s' or '%s' for provider '%s'",
+			LoadBalancerModeExternal, LoadBalancerModeInternal, lb.Provider)
+	default:
+		return LoadBalancerModeExternal, nil
+	}
+}
+
+func convertControlPlaneEndpointToV1alpha1(in *ControlPlaneEndpoint) (*v1alpha1.ControlPlaneEndpointSpec, error) {
+	out := &v1alpha1.ControlPlaneEndpointSpec{
+		Domain:      in.Domain,
+		Address:     in.Address,
+		Port:        in.Port,
+		ExternalDNS: in.ExternalDNS,
+	}
+	lb := in.LoadBalancer
+	if lb == nil {
+		return out, nil
+	}
+	if lb.Provider == "" {
+		return nil, fmt.Errorf("spec.controlPlaneEndpoint.loadBalancer.provider: is required when loadBalancer is set")
+	}
+	mode, err := loadBalancerMode(lb)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case LoadBalancerModeExternal:
+		out.ExternalLoadBalancerType = common.ExternalLoadBalancerType(lb.Provider)
+		external := &v1alpha1.ExternalLoadBalancerConfig{
+			Type:       lb.Provider,
+			Keepalived: lb.Keepalived,
+			HAProxy:    lb.HAProxy,
+			NginxLB:    lb.NginxLB,
+			KubeVIP:    lb.KubeVIP,
+		}
+		if lb.HostGroup != "" {
+			external.LoadBalancerHostGroupName = helpers.StrPtr(lb.HostGroup)
+		}
+		out.HighAvailability = &v1alpha1.HighAvailability{External: external}
+	case LoadBalancerModeInternal:
+		if lb.Keepalived != nil || lb.KubeVIP != nil || lb.HostGroup != "" {
+			return nil, fmt.Errorf("spec.controlPlaneEndpoint.loadBalancer: keepalived, kubevip and hostGroup only apply to the '%s' mode", LoadBalancerModeExternal)
+		}
+		out.InternalLoadBalancerType = common.InternalLoadBalancerType(lb.Provider)
+		out.HighAvailability = &v1alpha1.HighAvailability{Internal: &v1alpha1.InternalLoadBalancerConfig{
+			Type:              lb.Provider,
+			WorkerNodeHAProxy: lb.HAProxy,
+			WorkerNodeNginxLB: lb.NginxLB,
+		}}
+	default:
+		return nil, fmt.Errorf("spec.controlPlaneEndpoint.loadBalancer.mode: invalid mode '%s', must be '%s' or '%s'",
+			mode, LoadBalancerModeExternal, LoadBalancerModeInternal)
+	}
+	return out, nil
+}
+
+// convertAddonsToV1alpha1 lists addons in install order: every addon after those it depends on,
+// otherwise in name order.
+func convertAddonsToV1alpha1(addons map[string]Addon) ([]v1alpha1.Addon, error) {
+	if len(addons) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(addons))
+	for name, addon := range addons {
+		for _, dep := range addon.DependsOn {
+			if _, ok := addons[dep]; !ok {
+				return nil, fmt.Errorf("spec.addons.%s.dependsOn: addon '%s' is not defined", name, dep)
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	placed := make(map[string]bool, len(names))
+	out := make([]v1alpha1.Addon, 0, len(names))
+	for len(out) < len(names) {
+		progress := false
+		for _, name := range names {
+			addon := addons[name]
+			if placed[name] || !allPlaced(addon.DependsOn, placed) {
+				continue
+			}
+			placed[name] = true
+			progress = true
+			out = append(out, v1alpha1.Addon{
+				Name:           name,
+				Enabled:        addon.Enabled,
+				Retries:        addon.Retries,
+				Delay:          addon.Delay,
+				Sources:        addon.Sources,
+				PreInstall:     addon.PreInstall,
+				PostInstall:    addon.PostInstall,
+				TimeoutSeconds: addon.TimeoutSeconds,
+				Values:         addon.Values,
+				Wait:           addon.Wait,
+			})
+			break
+		}
+		if !progress {
+			var cycle []string
+			for _, name := range names {
+				if !placed[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			return nil, fmt.Errorf("spec.addons: dependency cycle between %s", strings.Join(cycle, ", "))
+		}
+	}
+	return out, nil
+}
+
+func allPlaced(names []string, placed map[string]bool) bool {
+	for _, name := range names {
+		if !placed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Convert_v1alpha1_Cluster_To_v1alpha2_Cluster converts in to v1alpha2, for migrating existing
+// configurations. Sections shared by both versions are not copied.
+func Convert_v1alpha1_Cluster_To_v1alpha2_Cluster(in *v1alpha1.Cluster, out *Cluster) error {
+	out.TypeMeta = in.TypeMeta
+	out.APIVersion = APIVersion
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = nil
+	if in.Spec == nil {
+		return nil
+	}
+	spec := in.Spec
+	out.Spec = &ClusterSpec{
+		Hosts:         spec.Hosts,
+		RoleGroups:    spec.RoleGroups,
+		Global:        spec.Global,
+		System:        spec.System,
+		Kubernetes:    spec.Kubernetes,
+		Etcd:          spec.Etcd,
+		DNS:           spec.DNS,
+		Storage:       spec.Storage,
+		Registry:      spec.Registry,
+		Gateway:       spec.Gateway,
+		HelmRepo:      spec.HelmRepo,
+		Preflight:     spec.Preflight,
+		Extra:         spec.Extra,
+		Certs:         spec.Certs,
+		BinarySources: spec.BinarySources,
+		Hooks:         spec.Hooks,
+	}
+	out.Spec.Infrastructure = spec.Infrastructure
+	out.Spec.Bootstrap = spec.Bootstrap
+	if n := spec.Network; n != nil {
+		out.Spec.Network = &Network{
+			Plugin:      n.Plugin,
+			PodCIDR:     n.KubePodsCIDR,
+			ServiceCIDR: n.KubeServiceCIDR,
+			Calico:      n.Calico,
+			Cilium:      n.Cilium,
+			Flannel:     n.Flannel,
+			KubeOvn:     n.KubeOvn,
+			Hybridnet:   n.Hybridnet,
+			Multus:      n.Multus,
+		}
+	}
+	if spec.ControlPlaneEndpoint != nil {
+		out.Spec.ControlPlaneEndpoint = convertControlPlaneEndpointToV1alpha2(spec.ControlPlaneEndpoint)
+	}
+	addons, err := convertAddonsToV1alpha2(spec.Addons)
+	if err != nil {
+		return err
+	}
+	out.Spec.Addons = addons
+	return nil
+}
+
+func convertControlPlaneEndpointToV1alpha2(in *v1alpha1.ControlPlaneEndpointSpec) *ControlPlaneEndpoint {
+	out := &ControlPlaneEndpoint{
+		Domain:      in.Domain,
+		Address:     in.Address,
+		Port:        in.Port,
+		ExternalDNS: in.ExternalDNS,
+	}
+	lb := &LoadBalancer{Mode: LoadBalancerModeExternal}
+	switch {
+	case in.ExternalLoadBalancerType != "":
+		lb.Provider = string(in.ExternalLoadBalancerType)
+	case in.InternalLoadBalancerType != "":
+		lb.Mode, lb.Provider = LoadBalancerModeInternal, string(in.InternalLoadBalancerType)
+	case in.HAMode == common.HAModeKubeVIP:
+		lb.Provider = string(common.ExternalLBTypeKubeVIP)
+	case in.HAMode == common.HAModeKeepalived:
+		lb.Provider = string(common.ExternalLBTypeKubexmKH)
+	default:
+		return out
+	}
+	if ha := in.HighAvailability; ha != nil {
+		if ext := ha.External; ext != nil && lb.Mode == LoadBalancerModeExternal {
+			lb.Keepalived, lb.HAProxy, lb.NginxLB, lb.KubeVIP = ext.Keepalived, ext.HAProxy, ext.NginxLB, ext.KubeVIP
+			if ext.LoadBalancerHostGroupName != nil {
+				lb.HostGroup = *ext.LoadBalancerHostGroupName
+			}
+		}
+		if internal := ha.Internal; internal != nil && lb.Mode == LoadBalancerModeInternal {
+			lb.HAProxy, lb.NginxLB = internal.WorkerNodeHAProxy, internal.WorkerNodeNginxLB
+		}
+	}
+	out.LoadBalancer = lb
+	return out
+}
+
+// convertAddonsToV1alpha2 keys addons by name. When the v1alpha1 list is not in name order, each
+// addon depends on the one listed before it, so the install order is kept.
+func convertAddonsToV1alpha2(addons []v1alpha1.Addon) (map[string]Addon, error) {
+	if len(addons) == 0 {
+		return nil, nil
+	}
+	ordered := sort.SliceIsSorted(addons, func(i, j int) bool { return addons[i].Name < addons[j].Name })
+	out := make(map[string]Addon, len(addons))
+	for i, addon := range addons {
+		if _, ok := out[addon.Name]; ok {
+			return nil, fmt.Errorf("spec.addons[%d]: addon '%s' is defined twice", i, addon.Name)
+		}
+		converted := Addon{
+			Enabled:        addon.Enabled,
+			Retries:        addon.Retries,
+			Delay:          addon.Delay,
+			Sources:        addon.Sources,
+			PreInstall:     addon.PreInstall,
+			PostInstall:    addon.PostInstall,
+			TimeoutSeconds: addon.TimeoutSeconds,
+			Values:         addon.Values,
+			Wait:           addon.Wait,
+		}
+		if !ordered && i > 0 {
+			converted.DependsOn = []string{addons[i-1].Name}
+		}
+		out[addon.Name] = converted
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+package v1alpha2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+func addonNames(addons []v1alpha1.Addon) string {
+	names := make([]string, 0, len(addons))
+	for _, addon := range addons {
+		names = append(names, addon.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestConvert_v1alpha2_Cluster_To_v1alpha1_Cluster(t *testing.T) {
+	in := &Cluster{Spec: &ClusterSpec{
+		Network: &Network{Plugin: "calico", PodCIDR: "10.244.0.0/16", ServiceCIDR: "10.96.0.0/12"},
+		ControlPlaneEndpoint: &ControlPlaneEndpoint{
+			Address:      "10.0.0.100",
+			Port:         6443,
+			LoadBalancer: &LoadBalancer{Provider: "kubexm-kh", HostGroup: "lb"},
+		},
+		Addons: map[string]Addon{
+			"ingress-nginx":  {DependsOn: []string{"metallb"}},
+			"metallb":        {},
+			"cert-manager":   {},
+			"metrics-server": {},
+		},
+	}}
+	in.Name = "prod"
+
+	out := &v1alpha1.Cluster{}
+	if err := Convert_v1alpha2_Cluster_To_v1alpha1_Cluster(in, out); err != nil {
+		t.Fatalf("conversion error = %v", err)
+	}
+	if out.APIVersion != common.DefaultAPIVersion || out.Name != "prod" {
+		t.Errorf("apiVersion = %s, name = %s", out.APIVersion, out.Name)
+	}
+	if n := out.Spec.Network; n.KubePodsCIDR != "10.244.0.0/16" || n.KubeServiceCIDR != "10.96.0.0/12" {
+		t.Errorf("network CIDRs not converted: %+v", n)
+	}
+	endpoint := out.Spec.ControlPlaneEndpoint
+	if endpoint.ExternalLoadBalancerType != common.ExternalLBTypeKubexmKH || endpoint.Address != "10.0.0.100" {
+		t.Errorf("endpoint = %+v, want external kubexm-kh", endpoint)
+	}
+	if ext := endpoint.HighAvailability.External; ext == nil || ext.LoadBalancerHostGroupName == nil || *ext.LoadBalancerHostGroupName != "lb" {
+		t.Errorf("external load balancer host group not converted: %+v", ext)
+	}
+	if got, want := addonNames(out.Spec.Addons), "cert-manager,metallb,ingress-nginx,metrics-server"; got != want {
+		t.Errorf("addons = %s, want %s", got, want)
+	}
+
+	back := &Cluster{}
+	if err := Convert_v1alpha1_Cluster_To_v1alpha2_Cluster(out, back); err != nil {
+		t.Fatalf("reverse conversion error = %v", err)
+	}
+	lb := back.Spec.ControlPlaneEndpoint.LoadBalancer
+	if back.APIVersion != APIVersion || lb.Mode != LoadBalancerModeExternal || lb.Provider != "kubexm-kh" || lb.HostGroup != "lb" {
+		t.Errorf("round trip load balancer = %+v", lb)
+	}
+	if back.Spec.Network.PodCIDR != "10.244.0.0/16" {
+		t.Errorf("round trip podCIDR = %s", back.Spec.Network.PodCIDR)
+	}
+
+	again := &v1alpha1.Cluster{}
+	if err := Convert_v1alpha2_Cluster_To_v1alpha1_Cluster(back, again); err != nil {
+		t.Fatalf("second conversion error = %v", err)
+	}
+	if got, want := addonNames(again.Spec.Addons), addonNames(out.Spec.Addons); got != want {
+		t.Errorf("addon order after round trip = %s, want %s", got, want)
+	}
+}
+
+func TestConvert_LoadBalancerModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		lb            *LoadBalancer
+		wantExternal  common.ExternalLoadBalancerType
+		wantInternal  common.InternalLoadBalancerType
+		expectedError string
+	}{
+		{name: "internal provider defaults to internal", lb: &LoadBalancer{Provider: "haproxy"}, wantInternal: common.InternalLBTypeHAProxy},
+		{name: "external kube-vip", lb: &LoadBalancer{Mode: LoadBalancerModeExternal, Provider: "kube-vip"}, wantExternal: common.ExternalLBTypeKubeVIP},
+		{name: "kube-vip needs a mode", lb: &LoadBalancer{Provider: "kube-vip"}, expectedError: "mode: must be set"},
+		{name: "missing provider", lb: &LoadBalancer{Mode: LoadBalancerModeInternal}, expectedError: "provider: is required"},
+		{name: "invalid mode", lb: &LoadBalancer{Mode: "both", Provider: "haproxy"}, expectedError: "invalid mode 'both'"},
+		{name: "host group in internal mode", lb: &LoadBalancer{Provider: "nginx", HostGroup: "lb"}, expectedError: "only apply to the 'external' mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &Cluster{Spec: &ClusterSpec{ControlPlaneEndpoint: &ControlPlaneEndpoint{Address: "10.0.0.100", LoadBalancer: tt.lb}}}
+			out := &v1alpha1.Cluster{}
+			err := Convert_v1alpha2_Cluster_To_v1alpha1_Cluster(in, out)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("conversion error = %v", err)
+			}
+			endpoint := out.Spec.ControlPlaneEndpoint
+			if endpoint.ExternalLoadBalancerType != tt.wantExternal || endpoint.InternalLoadBalancerType != tt.wantInternal {
+				t.Errorf("external = %q, internal = %q", endpoint.ExternalLoadBalancerType, endpoint.InternalLoadBalancerType)
+			}
+		})
+	}
+}
+
+func TestConvert_AddonErrors(t *testing.T) {
+	for name, addons := range map[string]map[string]Addon{
+		"is not defined":   {"ingress-nginx": {DependsOn: []string{"metallb"}}},
+		"dependency cycle": {"a": {DependsOn: []string{"b"}}, "b": {DependsOn: []string{"a"}}},
+	} {
+		err := Convert_v1alpha2_Cluster_To_v1alpha1_Cluster(&Cluster{Spec: &ClusterSpec{Addons: addons}}, &v1alpha1.Cluster{})
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("error = %v, want one containing %q", err, name)
+		}
+	}
+
+	in := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Addons: []v1alpha1.Addon{{Name: "metallb"}, {Name: "metallb"}}}}
+	if err := Convert_v1alpha1_Cluster_To_v1alpha2_Cluster(in, &Cluster{}); err == nil || !strings.Contains(err.Error(), "defined twice") {
+		t.Errorf("error = %v, want a duplicate addon error", err)
+	}
+}
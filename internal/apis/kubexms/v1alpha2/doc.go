@@ -0,0 +1,14 @@
+// +groupName=kubexms.io
+
+// Package v1alpha2 contains API Schema definitions for the KubeXMS v1alpha2 API group. It reuses the
+// v1alpha1 types of every section that did not change and is converted to v1alpha1, the version the
+// rest of kubexm works with, when a configuration is loaded.
+package v1alpha2
+
+import "github.com/mensylisir/kubexm/internal/common"
+
+// APIVersion is the apiVersion of v1alpha2 cluster configurations.
+const APIVersion = "kubexm.io/v1alpha2"
+
+// Kind is the kind of a cluster configuration, the same in every version.
+const Kind = common.DefaultKind
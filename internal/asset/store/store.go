@@ -0,0 +1,443 @@
+// Package store is a content-addressed cache of downloaded artifacts shared by every kubexm run and
+// cluster on a machine. Files are kept once per sha256 under blobs/sha256/, and an index maps the
+// references they were downloaded as, such as "binary/etcd/v3.5.13/amd64/etcd-v3.5.13-linux-amd64.tar.gz",
+// to their digest.
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/tool"
+)
+
+const (
+	// EnvCacheDir overrides the cache directory.
+	EnvCacheDir = "KUBEXM_CACHE_DIR"
+	// EnvCacheMaxSize overrides the cache size limit, as a quantity such as "50Gi". "0" disables the limit.
+	EnvCacheMaxSize = "KUBEXM_CACHE_MAX_SIZE"
+
+	indexFileName = "index.json"
+	blobsDirName  = "blobs"
+	digestAlgo    = "sha256"
+)
+
+// Entry describes one cached blob.
+type Entry struct {
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	Refs     []string  `json:"refs,omitempty"`
+	Added    time.Time `json:"added"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+type index struct {
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// Store is a content-addressed artifact cache rooted at a directory. It is safe for concurrent use
+// within a process; processes sharing a directory may lose each other's index updates, but never
+// blobs, and blobs missing from the index are picked up again by List.
+type Store struct {
+	mu      sync.Mutex
+	root    string
+	maxSize int64
+}
+
+// DefaultDir returns $KUBEXM_CACHE_DIR, or $HOME/.kubexm/cache.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvCacheDir); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, common.KubexmRootDirName, common.DefaultCacheDir), nil
+}
+
+// DefaultMaxSize returns $KUBEXM_CACHE_MAX_SIZE, or common.DefaultArtifactCacheMaxSize.
+func DefaultMaxSize() (int64, error) {
+	value := os.Getenv(EnvCacheMaxSize)
+	if value == "" {
+		value = common.DefaultArtifactCacheMaxSize
+	}
+	return ParseSize(value)
+}
+
+// ParseSize parses a size such as "20Gi" or "500M" into bytes.
+func ParseSize(value string) (int64, error) {
+	q, err := tool.ParseStorage(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache size %q: %w", value, err)
+	}
+	return q.Value(), nil
+}
+
+// Open returns the store rooted at root, creating it if needed. A maxSize above zero makes Put
+// evict the least recently used blobs once the cache grows past it.
+func Open(root string, maxSize int64) (*Store, error) {
+	if root == "" {
+		return nil, fmt.Errorf("artifact cache directory cannot be empty")
+	}
+	if err := os.MkdirAll(filepath.Join(root, blobsDirName, digestAlgo), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact cache %s: %w", root, err)
+	}
+	return &Store{root: root, maxSize: maxSize}, nil
+}
+
+// OpenDefault opens the store at DefaultDir with DefaultMaxSize.
+func OpenDefault() (*Store, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	maxSize, err := DefaultMaxSize()
+	if err != nil {
+		return nil, err
+	}
+	return Open(dir, maxSize)
+}
+
+// Root returns the cache directory.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// BlobPath returns where the blob of digest is stored.
+func (s *Store) BlobPath(digest string) string {
+	return filepath.Join(s.root, blobsDirName, digestAlgo, digest)
+}
+
+// Lookup returns the entry stored for ref, if its blob is still present.
+func (s *Store) Lookup(ref string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.load()
+	if err != nil {
+		return nil, false
+	}
+	for _, e := range idx.Entries {
+		if containsRef(e.Refs, ref) && s.blobExists(e.Digest) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Has reports whether a blob with digest is cached.
+func (s *Store) Has(digest string) bool {
+	return s.blobExists(strings.ToLower(digest))
+}
+
+// Link makes dest a copy of the cached blob of digest, preferring a hard link, and records ref for it.
+func (s *Store) Link(digest, dest, ref string) error {
+	digest = strings.ToLower(digest)
+	src := s.BlobPath(digest)
+	if !s.blobExists(digest) {
+		return fmt.Errorf("blob %s:%s is not cached", digestAlgo, digest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+	tmp := dest + ".cache-tmp"
+	_ = os.Remove(tmp)
+	if err := os.Link(src, tmp); err != nil {
+		if err := copyFile(src, tmp); err != nil {
+			return fmt.Errorf("failed to copy cached blob to %s: %w", dest, err)
+		}
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to move cached blob to %s: %w", dest, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.load()
+	if err != nil {
+		return err
+	}
+	e := s.entry(idx, digest)
+	e.LastUsed = time.Now()
+	if ref != "" && !containsRef(e.Refs, ref) {
+		e.Refs = append(e.Refs, ref)
+	}
+	return s.save(idx)
+}
+
+// Put adds the file at path to the store under ref and returns its entry. The file itself is left
+// in place; the store keeps a hard link to it, or a copy across file systems.
+func (s *Store) Put(path, ref string) (*Entry, error) {
+	digest, size, err := fileDigest(path)
+	if err != nil {
+		return nil, err
+	}
+	if !s.blobExists(digest) {
+		blob := s.BlobPath(digest)
+		tmp := blob + ".tmp"
+		_ = os.Remove(tmp)
+		if err := os.Link(path, tmp); err != nil {
+			if err := copyFile(path, tmp); err != nil {
+				return nil, fmt.Errorf("failed to add %s to the artifact cache: %w", path, err)
+			}
+		}
+		if err := os.Rename(tmp, blob); err != nil {
+			_ = os.Remove(tmp)
+			return nil, fmt.Errorf("failed to add %s to the artifact cache: %w", path, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	e := s.entry(idx, digest)
+	e.Size = size
+	e.LastUsed = time.Now()
+	for _, other := range idx.Entries {
+		if other != e {
+			other.Refs = removeRef(other.Refs, ref)
+		}
+	}
+	if ref != "" && !containsRef(e.Refs, ref) {
+		e.Refs = append(e.Refs, ref)
+	}
+	if s.maxSize > 0 {
+		s.evict(idx, s.maxSize, digest)
+	}
+	if err := s.save(idx); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// List returns all cached blobs, most recently used first.
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.After(entries[j].LastUsed) })
+	return entries, nil
+}
+
+// PruneOptions selects the blobs Prune removes. With no option set, Prune removes everything.
+type PruneOptions struct {
+	// OlderThan removes blobs not used for at least this long.
+	OlderThan time.Duration
+	// MaxSize removes the least recently used blobs until the cache fits in this many bytes.
+	MaxSize int64
+}
+
+// Prune removes blobs from the cache and returns the removed entries.
+func (s *Store) Prune(opts PruneOptions) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Entry
+	all := opts.OlderThan <= 0 && opts.MaxSize <= 0
+	if all || opts.OlderThan > 0 {
+		cutoff := time.Now().Add(-opts.OlderThan)
+		for digest, e := range idx.Entries {
+			if all || e.LastUsed.Before(cutoff) {
+				removed = append(removed, *e)
+				s.remove(idx, digest)
+			}
+		}
+	}
+	if opts.MaxSize > 0 {
+		removed = append(removed, s.evict(idx, opts.MaxSize, "")...)
+	}
+	return removed, s.save(idx)
+}
+
+// evict removes least recently used blobs, except keep, until the total size fits in maxSize.
+func (s *Store) evict(idx *index, maxSize int64, keep string) []Entry {
+	var total int64
+	entries := make([]*Entry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		total += e.Size
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.Before(entries[j].LastUsed) })
+
+	var removed []Entry
+	for _, e := range entries {
+		if total <= maxSize {
+			break
+		}
+		if e.Digest == keep {
+			continue
+		}
+		total -= e.Size
+		removed = append(removed, *e)
+		s.remove(idx, e.Digest)
+	}
+	return removed
+}
+
+func (s *Store) remove(idx *index, digest string) {
+	_ = os.Remove(s.BlobPath(digest))
+	delete(idx.Entries, digest)
+}
+
+func (s *Store) entry(idx *index, digest string) *Entry {
+	e, ok := idx.Entries[digest]
+	if !ok {
+		now := time.Now()
+		e = &Entry{Digest: digest, Added: now, LastUsed: now}
+		if fi, err := os.Stat(s.BlobPath(digest)); err == nil {
+			e.Size = fi.Size()
+		}
+		idx.Entries[digest] = e
+	}
+	return e
+}
+
+func (s *Store) blobExists(digest string) bool {
+	if !isDigest(digest) {
+		return false
+	}
+	_, err := os.Stat(s.BlobPath(digest))
+	return err == nil
+}
+
+// load reads the index, dropping entries whose blob is gone and adding blobs it does not know.
+func (s *Store) load() (*index, error) {
+	idx := &index{Entries: make(map[string]*Entry)}
+	data, err := os.ReadFile(filepath.Join(s.root, indexFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read artifact cache index: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, idx); err != nil {
+			return nil, fmt.Errorf("failed to parse artifact cache index: %w", err)
+		}
+		if idx.Entries == nil {
+			idx.Entries = make(map[string]*Entry)
+		}
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(s.root, blobsDirName, digestAlgo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact cache blobs: %w", err)
+	}
+	present := make(map[string]bool, len(blobs))
+	for _, b := range blobs {
+		if !isDigest(b.Name()) {
+			continue
+		}
+		present[b.Name()] = true
+		if _, ok := idx.Entries[b.Name()]; !ok {
+			info, err := b.Info()
+			if err != nil {
+				continue
+			}
+			idx.Entries[b.Name()] = &Entry{Digest: b.Name(), Size: info.Size(), Added: info.ModTime(), LastUsed: info.ModTime()}
+		}
+	}
+	for digest := range idx.Entries {
+		if !present[digest] {
+			delete(idx.Entries, digest)
+		}
+	}
+	return idx, nil
+}
+
+func (s *Store) save(idx *index) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode artifact cache index: %w", err)
+	}
+	path := filepath.Join(s.root, indexFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write artifact cache index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write artifact cache index: %w", err)
+	}
+	return nil
+}
+
+func fileDigest(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		_ = os.Remove(dest)
+		return err
+	}
+	return out.Close()
+}
+
+func isDigest(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func containsRef(refs []string, ref string) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func removeRef(refs []string, ref string) []string {
+	out := refs[:0]
+	for _, r := range refs {
+		if r != ref {
+			out = append(out, r)
+		}
+	}
+	return out
+}
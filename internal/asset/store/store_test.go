@@ -0,0 +1,132 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(len(name) + i)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStore_PutLookupLink(t *testing.T) {
+	s, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	src := writeFile(t, work, "etcd.tar.gz", 4096)
+
+	entry, err := s.Put(src, "binary/etcd/v3.5.13/amd64/etcd.tar.gz")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if entry.Size != 4096 || !s.Has(entry.Digest) {
+		t.Fatalf("unexpected entry after Put: %+v", entry)
+	}
+
+	got, ok := s.Lookup("binary/etcd/v3.5.13/amd64/etcd.tar.gz")
+	if !ok || got.Digest != entry.Digest {
+		t.Fatalf("expected Lookup to find %s, got %+v", entry.Digest, got)
+	}
+	if _, ok := s.Lookup("binary/etcd/v3.5.12/amd64/etcd.tar.gz"); ok {
+		t.Error("expected no entry for a reference that was never stored")
+	}
+
+	// Another cluster's work directory gets the same content without a download.
+	dest := filepath.Join(t.TempDir(), "other-cluster", "etcd.tar.gz")
+	if err := s.Link(entry.Digest, dest, "binary/etcd/v3.5.13/amd64/etcd.tar.gz"); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	want, _ := os.ReadFile(src)
+	if linked, _ := os.ReadFile(dest); string(linked) != string(want) {
+		t.Error("linked file differs from the stored one")
+	}
+
+	// Storing the same content under a second reference keeps a single blob.
+	if _, err := s.Put(dest, "binary/etcd/latest/amd64/etcd.tar.gz"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].Refs) != 2 {
+		t.Errorf("expected one blob with two references, got %+v", entries)
+	}
+}
+
+func TestStore_SizeLimitAndPrune(t *testing.T) {
+	s, err := Open(t.TempDir(), 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	var digests []string
+	for _, name := range []string{"a", "bb", "ccc"} {
+		e, err := s.Put(writeFile(t, work, name, 4000), "binary/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests = append(digests, e.Digest)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.Has(digests[0]) || !s.Has(digests[1]) || !s.Has(digests[2]) {
+		t.Fatal("expected the least recently used blob to be evicted past the size limit")
+	}
+	if _, ok := s.Lookup("binary/a"); ok {
+		t.Error("expected no lookup result for an evicted blob")
+	}
+
+	removed, err := s.Prune(PruneOptions{MaxSize: 5000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Digest != digests[1] {
+		t.Errorf("expected %s to be pruned, got %+v", digests[1], removed)
+	}
+
+	if removed, err := s.Prune(PruneOptions{OlderThan: time.Hour}); err != nil || len(removed) != 0 {
+		t.Errorf("expected nothing older than an hour, got %+v, %v", removed, err)
+	}
+	if removed, err := s.Prune(PruneOptions{}); err != nil || len(removed) != 1 {
+		t.Errorf("expected an empty prune to remove everything, got %+v, %v", removed, err)
+	}
+	if entries, _ := s.List(); len(entries) != 0 {
+		t.Errorf("expected an empty cache, got %+v", entries)
+	}
+}
+
+func TestStore_PicksUpBlobsMissingFromIndex(t *testing.T) {
+	root := t.TempDir()
+	s, err := Open(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := s.Put(writeFile(t, t.TempDir(), "helm.tar.gz", 100), "binary/helm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, indexFileName)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Digest != e.Digest || entries[0].Size != 100 {
+		t.Errorf("expected the blob to be listed without an index, got %+v", entries)
+	}
+}
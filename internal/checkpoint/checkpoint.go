@@ -89,6 +89,8 @@ type HostState struct {
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	StartTime time.Time   `json:"startTime,omitempty"`
 	EndTime   time.Time   `json:"endTime,omitempty"`
+	LogFile   string      `json:"logFile,omitempty"`
+	Satisfied bool        `json:"satisfied,omitempty"`
 }
 
 // ModuleState tracks module-level progress for coarse-grained resume decisions.
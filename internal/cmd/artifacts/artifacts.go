@@ -0,0 +1,17 @@
+package artifacts
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ArtifactsCmd represents the artifacts command group
+var ArtifactsCmd = &cobra.Command{
+	Use:   "artifacts",
+	Short: "Manage offline installation artifacts",
+	Long:  `Commands for exporting the binaries, images and charts of a cluster into an offline bundle.`,
+}
+
+// AddArtifactsCommand adds the artifacts command to the parent command.
+func AddArtifactsCommand(parentCmd *cobra.Command) {
+	parentCmd.AddCommand(ArtifactsCmd)
+}
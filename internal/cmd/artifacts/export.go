@@ -0,0 +1,90 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/assets"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+type ExportOptions struct {
+	ClusterConfigFile string
+	OutputPath        string
+	DryRun            bool
+}
+
+var exportOptions = &ExportOptions{}
+
+func init() {
+	ArtifactsCmd.AddCommand(exportCmd)
+	exportCmd.Flags().VarP(config.NewPathsValue(&exportOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	exportCmd.Flags().StringVarP(&exportOptions.OutputPath, "output", "o", "kubexm-bundle.tar.gz", "Path of the bundle to write")
+	exportCmd.Flags().BoolVar(&exportOptions.DryRun, "dry-run", false, "Show what would be exported without downloading anything")
+
+	if err := exportCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required: %v\n", err)
+	}
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all artifacts a cluster needs into a single bundle",
+	Long: `Download every binary, container image and Helm chart the cluster configuration needs and
+pack them into one tarball. Install from it without internet access with:
+
+  kubexm apply -f cluster.yaml --artifacts kubexm-bundle.tar.gz`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		absPath, err := filepath.Abs(exportOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file %s: %w", exportOptions.ClusterConfigFile, err)
+		}
+		outputPath, err := filepath.Abs(exportOptions.OutputPath)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for output %s: %w", exportOptions.OutputPath, err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration from %s: %w", absPath, err)
+		}
+		// Exporting needs internet access even for a cluster that will be installed offline.
+		clusterConfig.Spec.Global.OfflineMode = false
+
+		runtimeCtx, cleanupFunc, err := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipHostConnect(true).
+			WithSkipConfigValidation(true).
+			Build(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment for export: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := assets.NewDownloadAssetsPipeline(outputPath)
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("pipeline planning failed: %w", err)
+		}
+		result, err := p.Run(runtimeCtx, graph, exportOptions.DryRun)
+		if err != nil {
+			return fmt.Errorf("artifacts export failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("artifacts export failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+		if !exportOptions.DryRun {
+			log.Infof("Artifacts exported to %s", outputPath)
+		}
+		return nil
+	},
+}
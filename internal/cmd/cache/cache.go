@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// CacheCmd represents the cache command group
+var CacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local artifact cache",
+	Long: `Commands for the content-addressed cache of downloaded artifacts that is shared by every run
+and cluster on this machine. The cache lives in $HOME/.kubexm/cache, or $KUBEXM_CACHE_DIR, and is
+limited to 20Gi unless $KUBEXM_CACHE_MAX_SIZE says otherwise.`,
+}
+
+// AddCacheCommand adds the cache command to the parent command.
+func AddCacheCommand(parentCmd *cobra.Command) {
+	parentCmd.AddCommand(CacheCmd)
+}
+
+// formatSize renders a byte count in binary units, e.g. 1.5GiB.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
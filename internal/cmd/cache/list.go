@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/asset/store"
+)
+
+func init() {
+	CacheCmd.AddCommand(listCmd)
+}
+
+var listCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List cached artifacts",
+	Long:    `Lists every artifact in the cache with its digest, size, last use and the references it was downloaded as.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := store.OpenDefault()
+		if err != nil {
+			return fmt.Errorf("failed to open artifact cache: %w", err)
+		}
+		entries, err := s.List()
+		if err != nil {
+			return fmt.Errorf("failed to list artifact cache: %w", err)
+		}
+		if len(entries) == 0 {
+			fmt.Printf("Artifact cache %s is empty.\n", s.Root())
+			return nil
+		}
+
+		var total int64
+		rows := make([][]string, 0, len(entries))
+		for _, e := range entries {
+			total += e.Size
+			rows = append(rows, []string{
+				e.Digest[:12],
+				formatSize(e.Size),
+				e.LastUsed.Format(time.RFC3339),
+				strings.Join(e.Refs, "\n"),
+			})
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"DIGEST", "SIZE", "LAST USED", "REFERENCES"})
+		table.SetBorder(false)
+		table.SetAutoWrapText(false)
+		table.AppendBulk(rows)
+		table.Render()
+		fmt.Printf("\n%d artifacts, %s in %s\n", len(entries), formatSize(total), s.Root())
+		return nil
+	},
+}
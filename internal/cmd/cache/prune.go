@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/asset/store"
+)
+
+type PruneOptions struct {
+	All       bool
+	OlderThan time.Duration
+	MaxSize   string
+}
+
+var pruneOptions = &PruneOptions{}
+
+func init() {
+	CacheCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVar(&pruneOptions.All, "all", false, "Remove every cached artifact")
+	pruneCmd.Flags().DurationVar(&pruneOptions.OlderThan, "older-than", 0, "Remove artifacts not used for this long, e.g. 720h")
+	pruneCmd.Flags().StringVar(&pruneOptions.MaxSize, "max-size", "", "Remove the least recently used artifacts until the cache fits in this size, e.g. 10Gi")
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached artifacts",
+	Long: `Removes artifacts from the cache. Without flags the cache is trimmed to its configured size
+limit, least recently used artifacts first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := store.OpenDefault()
+		if err != nil {
+			return fmt.Errorf("failed to open artifact cache: %w", err)
+		}
+
+		var opts store.PruneOptions
+		switch {
+		case pruneOptions.All:
+		case pruneOptions.OlderThan > 0 || pruneOptions.MaxSize != "":
+			opts.OlderThan = pruneOptions.OlderThan
+			if pruneOptions.MaxSize != "" {
+				if opts.MaxSize, err = store.ParseSize(pruneOptions.MaxSize); err != nil {
+					return err
+				}
+				if opts.MaxSize <= 0 {
+					return fmt.Errorf("--max-size must be above zero, use --all to empty the cache")
+				}
+			}
+		default:
+			if opts.MaxSize, err = store.DefaultMaxSize(); err != nil {
+				return err
+			}
+			if opts.MaxSize <= 0 {
+				fmt.Println("The artifact cache has no size limit, nothing to prune.")
+				return nil
+			}
+		}
+
+		removed, err := s.Prune(opts)
+		if err != nil {
+			return fmt.Errorf("failed to prune artifact cache: %w", err)
+		}
+		var freed int64
+		for _, e := range removed {
+			freed += e.Size
+		}
+		fmt.Printf("Removed %d artifacts, freed %s.\n", len(removed), formatSize(freed))
+		return nil
+	},
+}
@@ -30,7 +30,7 @@ var renewOptions = &RenewOptions{}
 
 func init() {
 	CertsCmd.AddCommand(renewCmd)
-	renewCmd.Flags().StringVarP(&renewOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	renewCmd.Flags().VarP(config.NewPathsValue(&renewOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	renewCmd.Flags().StringVarP(&renewOptions.CertType, "type", "t", "all", "Certificate type to renew: kubernetes-ca, etcd-ca, kubernetes-certs, etcd-certs, all (default: all)")
 	renewCmd.Flags().BoolVar(&renewOptions.Force, "force", false, "Force renewal without confirmation")
 	renewCmd.Flags().BoolVar(&renewOptions.DryRun, "dry-run", false, "Simulate the certificate renewal without making changes")
@@ -45,22 +45,29 @@ var renewCmd = &cobra.Command{
 	Short: "Renew cluster certificates",
 	Long: `Renew certificates for Kubernetes cluster components.
 
+Renewal is rolling: new certificates are generated on the control node and distributed one node
+at a time, and the components using them (kube-apiserver, kube-controller-manager, kube-scheduler,
+kubelet, kube-proxy and etcd) are restarted on that node before the next one is updated. A CA is
+first rolled out as a bundle with the old CA so that nodes keep trusting each other throughout.
+
 Certificate types:
   kubernetes-ca    - Renew Kubernetes CA certificate and key
-  etcd-ca         - Renew etcd CA certificate and key
+  etcd-ca          - Renew etcd CA certificate and key
   kubernetes-certs - Renew all Kubernetes component certificates (apiserver, controller-manager, scheduler, kubelet)
-  etcd-certs      - Renew all etcd certificates
-  all             - Renew all certificates (default)
+  etcd-certs       - Renew all etcd certificates
+  all              - Renew all certificates (default)
+
+Run 'kubexm certs check-expiration' first to see which certificates are due.
 
 Examples:
   # Renew all certificates
-  kubexm certs renew --cluster my-cluster --type all
+  kubexm certs renew -f config.yaml --type all
 
   # Renew only Kubernetes CA
-  kubexm certs renew --cluster my-cluster --type kubernetes-ca
+  kubexm certs renew -f config.yaml --type kubernetes-ca
 
   # Dry run to see what would be renewed
-  kubexm certs renew --cluster my-cluster --type all --dry-run`,
+  kubexm certs renew -f config.yaml --type all --dry-run`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log := logger.Get()
 		defer logger.SyncGlobal()
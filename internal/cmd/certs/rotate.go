@@ -32,7 +32,7 @@ var rotateOptions = &RotateOptions{}
 
 func init() {
 	CertsCmd.AddCommand(rotateCmd)
-	rotateCmd.Flags().StringVarP(&rotateOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	rotateCmd.Flags().VarP(config.NewPathsValue(&rotateOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	rotateCmd.Flags().StringVarP(&rotateOptions.CertType, "type", "t", "all", "Certificate type to rotate: kubernetes-ca, etcd-ca, kubernetes-certs, etcd-certs, all (default: all)")
 	rotateCmd.Flags().StringVar(&rotateOptions.ServiceName, "service", "", "Service name for targeted certificate rotation (e.g., 'apiserver', 'etcd', 'kubelet')")
 	rotateCmd.Flags().BoolVar(&rotateOptions.Force, "force", false, "Force rotation without confirmation")
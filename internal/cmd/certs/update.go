@@ -31,7 +31,7 @@ var updateCertOptions = &UpdateCertOptions{}
 
 func init() {
 	CertsCmd.AddCommand(updateCertCmd)
-	updateCertCmd.Flags().StringVarP(&updateCertOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	updateCertCmd.Flags().VarP(config.NewPathsValue(&updateCertOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	updateCertCmd.Flags().StringVarP(&updateCertOptions.CertType, "type", "t", "all", "Certificate type to update: kubernetes-ca, etcd-ca, kubernetes-certs, etcd-certs, all (default: all)")
 	updateCertCmd.Flags().BoolVar(&updateCertOptions.Force, "force", false, "Force update without confirmation")
 	updateCertCmd.Flags().BoolVar(&updateCertOptions.DryRun, "dry-run", false, "Simulate the certificate update without making changes")
@@ -24,20 +24,23 @@ type AddNodesOptions struct {
 var addNodesOptions = &AddNodesOptions{}
 
 func init() {
-	ClusterCmd.AddCommand(addNodesCmd)
-	addNodesCmd.Flags().StringVarP(&addNodesOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
-	addNodesCmd.Flags().BoolVar(&addNodesOptions.SkipPreflight, "skip-preflight", false, "Skip preflight checks")
-	addNodesCmd.Flags().BoolVar(&addNodesOptions.DryRun, "dry-run", false, "Simulate the node addition without making any changes")
+	AddNodesCmd.Flags().VarP(config.NewPathsValue(&addNodesOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	AddNodesCmd.Flags().BoolVar(&addNodesOptions.SkipPreflight, "skip-preflight", false, "Skip preflight checks")
+	AddNodesCmd.Flags().BoolVar(&addNodesOptions.DryRun, "dry-run", false, "Simulate the node addition without making any changes")
 
-	if err := addNodesCmd.MarkFlagRequired("config"); err != nil {
+	if err := AddNodesCmd.MarkFlagRequired("config"); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required: %v\n", err)
 	}
 }
 
-var addNodesCmd = &cobra.Command{
+// AddNodesCmd joins the hosts in the configuration file that are not yet part of the running cluster.
+var AddNodesCmd = &cobra.Command{
 	Use:   "add-nodes",
 	Short: "Add new worker or control-plane nodes to an existing cluster",
-	Long:  `Add new worker or control-plane nodes to an existing Kubernetes cluster based on a provided configuration file.`,
+	Long: `Add new worker or control-plane nodes to an existing Kubernetes cluster based on a provided configuration file.
+The hosts in the file are compared with the nodes of the running cluster and only the missing ones
+are prepared and joined.`,
+	Example: `  kubexm add-nodes -f cluster.yaml`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log := logger.Get()
 		defer logger.SyncGlobal()
@@ -29,7 +29,7 @@ var backupOptions = &BackupOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(backupCmd)
-	backupCmd.Flags().StringVarP(&backupOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	backupCmd.Flags().VarP(config.NewPathsValue(&backupOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	backupCmd.Flags().StringVarP(&backupOptions.BackupType, "type", "t", "all", "Backup type: all, pki, etcd, kubernetes (default: all)")
 	backupCmd.Flags().StringVarP(&backupOptions.OutputPath, "output", "o", "", "Output path for backup (default: uses cluster work dir)")
 	backupCmd.Flags().BoolVar(&backupOptions.DryRun, "dry-run", false, "Simulate the backup without making changes")
@@ -0,0 +1,203 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// bootstrapHostsFile is the overlay the new login is written to, next to the cluster configuration.
+const bootstrapHostsFile = "bootstrap-hosts.yaml"
+
+type BootstrapOptions struct {
+	ClusterConfigFile string
+	OutputFile        string
+	Timeout           time.Duration
+}
+
+var bootstrapOptions = &BootstrapOptions{}
+
+func init() {
+	BootstrapCmd.Flags().VarP(config.NewPathsValue(&bootstrapOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	BootstrapCmd.Flags().StringVarP(&bootstrapOptions.OutputFile, "output", "o", "", "File to write the new login of the hosts to (default: "+bootstrapHostsFile+" next to the configuration, or in it if it is a directory)")
+	BootstrapCmd.Flags().DurationVar(&bootstrapOptions.Timeout, "timeout", 15*time.Minute, "Timeout for bootstrapping the hosts")
+
+	if err := BootstrapCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for bootstrap command: %v\n", err)
+	}
+}
+
+// BootstrapCmd creates the kubexm login user on the hosts and hardens sshd.
+var BootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Create a dedicated kubexm user on the hosts and harden SSH",
+	Long: `Log in to every host with the credentials of spec.hosts, typically as root or the
+initial user of an image, and create the user of spec.bootstrap (kubexm by default):
+  - its password is disabled and it logs in with the key of spec.bootstrap.privateKeyPath,
+    which is generated if it does not exist
+  - it may run any command through sudo without a password (/etc/sudoers.d/<user>)
+
+Once a login as the user works, sshd is hardened over it: root logins and password
+authentication are disabled unless spec.bootstrap says otherwise.
+
+The new login of the hosts is written to the output file as a configuration overlay, to be
+merged with the cluster configuration so that all later commands log in as the new user.
+If some hosts fail, the overlay still lists the hosts that were switched over.
+Hosts that already log in as the user are left alone, so the command can be run again.
+
+Examples:
+  # Bootstrap the hosts and then create the cluster as the new user
+  kubexm bootstrap -f cluster.yaml
+  kubexm create cluster -f cluster.yaml -f bootstrap-hosts.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		if bootstrapOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+
+		absPath, err := filepath.Abs(bootstrapOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+		if clusterConfig.Spec.Bootstrap == nil {
+			clusterConfig.Spec.Bootstrap = &v1alpha1.BootstrapSpec{}
+			v1alpha1.SetDefaults_BootstrapSpec(clusterConfig.Spec.Bootstrap, clusterConfig)
+			verrs := &validation.ValidationErrors{}
+			v1alpha1.Validate_BootstrapSpec(clusterConfig.Spec.Bootstrap, verrs, "spec.bootstrap")
+			if verrs.HasErrors() {
+				return fmt.Errorf("invalid bootstrap configuration: %w", verrs)
+			}
+		}
+		bootstrap := clusterConfig.Spec.Bootstrap
+
+		outputFile := bootstrapOptions.OutputFile
+		if outputFile == "" {
+			outputFile = bootstrapOutputFile(absPath)
+		}
+
+		log.Infof("Bootstrapping %d hosts of cluster '%s' with user '%s'", len(clusterConfig.Spec.Hosts), clusterConfig.Name, bootstrap.User)
+
+		goCtx, cancel := context.WithTimeout(context.Background(), bootstrapOptions.Timeout)
+		defer cancel()
+
+		// The hosts are connected to with their initial credentials; the bootstrap steps switch
+		// these connections over to the new user.
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewBootstrapHostsPipeline()
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("bootstrap pipeline planning failed: %w", err)
+		}
+
+		result, runErr := p.Run(runtimeCtx, graph, false)
+
+		// HardenSSH may already have locked out the initial login of the hosts that switched over,
+		// so their overlay entries are written even if the run failed on other hosts.
+		hosts := runtimeCtx.GetHostsByRole("")
+		switched := switchedHosts(hosts, bootstrap)
+		if len(switched) > 0 {
+			if err := writeBootstrapOverlay(outputFile, switched, bootstrap); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d of %d hosts now log in as %s, overlay written to %s\n", len(switched), len(hosts), bootstrap.User, outputFile)
+		}
+
+		if runErr != nil {
+			return fmt.Errorf("bootstrap pipeline execution failed: %w", runErr)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("bootstrap failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+		return nil
+	},
+}
+
+// switchedHosts returns the spec of every host that logs in as the bootstrap user, which
+// BootstrapUser sets on a host once the new login works.
+func switchedHosts(hosts []remotefw.Host, bootstrap *v1alpha1.BootstrapSpec) []v1alpha1.HostSpec {
+	var switched []v1alpha1.HostSpec
+	for _, host := range hosts {
+		spec := host.GetHostSpec()
+		if spec.User == bootstrap.User && spec.Password == "" && spec.PrivateKeyPath == bootstrap.PrivateKeyPath {
+			switched = append(switched, spec)
+		}
+	}
+	return switched
+}
+
+func writeBootstrapOverlay(outputFile string, hosts []v1alpha1.HostSpec, bootstrap *v1alpha1.BootstrapSpec) error {
+	data, err := sigsyaml.Marshal(bootstrapOverlay(hosts, bootstrap))
+	if err != nil {
+		return fmt.Errorf("failed to encode bootstrap overlay: %w", err)
+	}
+	if err := os.WriteFile(outputFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write bootstrap overlay %s: %w", outputFile, err)
+	}
+	return nil
+}
+
+// bootstrapOverlay returns the configuration overlay that makes every host log in as the bootstrap
+// user with its key. The initial password and inline key of the hosts are removed by setting them
+// to null. Bastions are not bootstrapped, so they keep the login they were defaulted to from the
+// host.
+func bootstrapOverlay(hosts []v1alpha1.HostSpec, bootstrap *v1alpha1.BootstrapSpec) map[string]interface{} {
+	overlayHosts := make([]map[string]interface{}, 0, len(hosts))
+	for _, host := range hosts {
+		overlayHost := map[string]interface{}{
+			"name":           host.Name,
+			"user":           bootstrap.User,
+			"privateKeyPath": bootstrap.PrivateKeyPath,
+			"password":       nil,
+			"privateKey":     nil,
+		}
+		if host.Bastion != nil {
+			overlayHost["bastion"] = host.Bastion
+		}
+		overlayHosts = append(overlayHosts, overlayHost)
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{"hosts": overlayHosts},
+	}
+}
+
+// bootstrapOutputFile returns where the overlay is written when no output file is given: into the
+// first configuration source if it is a directory, so that it is merged when it is read again,
+// and next to it otherwise.
+func bootstrapOutputFile(configPaths string) string {
+	first := configPaths
+	if paths := filepath.SplitList(configPaths); len(paths) > 0 {
+		first = paths[0]
+	}
+	if info, err := os.Stat(first); err == nil && info.IsDir() {
+		return filepath.Join(first, bootstrapHostsFile)
+	}
+	return filepath.Join(filepath.Dir(first), bootstrapHostsFile)
+}
@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+func TestBootstrapOverlay(t *testing.T) {
+	hosts := []v1alpha1.HostSpec{
+		{Name: "node1", User: "root", Password: "secret"},
+		{Name: "node2", User: "root", Password: "secret", Bastion: &v1alpha1.BastionSpec{Address: "10.0.0.1", Port: 22, User: "root", Password: "secret"}},
+	}
+	data, err := sigsyaml.Marshal(bootstrapOverlay(hosts, &v1alpha1.BootstrapSpec{User: "kubexm", PrivateKeyPath: "/keys/id"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `spec:
+  hosts:
+  - name: node1
+    password: null
+    privateKey: null
+    privateKeyPath: /keys/id
+    user: kubexm
+  - bastion:
+      address: 10.0.0.1
+      password: secret
+      port: 22
+      user: root
+    name: node2
+    password: null
+    privateKey: null
+    privateKeyPath: /keys/id
+    user: kubexm
+`
+	if strings.TrimSpace(string(data)) != strings.TrimSpace(want) {
+		t.Errorf("overlay =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestWriteBootstrapOverlay_PartialFailure(t *testing.T) {
+	bootstrap := &v1alpha1.BootstrapSpec{User: "kubexm", PrivateKeyPath: "/keys/id"}
+	switchedHost := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.11", User: "root", Password: "secret"})
+	failedHost := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node2", Address: "10.0.0.12", User: "root", Password: "secret"})
+	// BootstrapUser switched node1 over; node2 failed before its login changed.
+	switchedHost.SetUser(bootstrap.User)
+	switchedHost.SetPassword("")
+	switchedHost.SetPrivateKeyPath(bootstrap.PrivateKeyPath)
+
+	switched := switchedHosts([]remotefw.Host{switchedHost, failedHost}, bootstrap)
+	if len(switched) != 1 || switched[0].Name != "node1" {
+		t.Fatalf("expected only node1 to have switched over, got %+v", switched)
+	}
+
+	outputFile := filepath.Join(t.TempDir(), bootstrapHostsFile)
+	if err := writeBootstrapOverlay(outputFile, switched, bootstrap); err != nil {
+		t.Fatalf("failed to write overlay: %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "name: node1") || strings.Contains(string(data), "node2") {
+		t.Errorf("expected the overlay to list node1 only, got:\n%s", data)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/spf13/cobra"
 
@@ -13,13 +14,23 @@ import (
 	"github.com/mensylisir/kubexm/internal/logger"
 	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
 	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/preflight"
 	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/drift"
 )
 
 type CreateOptions struct {
-	ClusterConfigFile string
-	SkipPreflight     bool
-	DryRun            bool
+	ClusterConfigFile     string
+	SkipPreflight         bool
+	IgnorePreflightErrors []string
+	DryRun                bool
+	SmokeTest             bool
+	Preview               bool
+	MaxWorkers            int
+	ReportFile            string
+	ReportFormat          string
+	ArtifactsBundle       string
+	Progress              string
 	// Verbose and YesAssume will use global flags from root.go
 }
 
@@ -27,105 +38,252 @@ var createOptions = &CreateOptions{} // Verbose and YesAssume removed
 
 func init() {
 	ClusterCmd.AddCommand(createCmd)
-	createCmd.Flags().StringVarP(&createOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
-	createCmd.Flags().BoolVar(&createOptions.SkipPreflight, "skip-preflight", false, "Skip preflight checks")
-	createCmd.Flags().BoolVar(&createOptions.DryRun, "dry-run", false, "Simulate the cluster creation without making any changes")
+	addCreateFlags(createCmd)
+	addCreateFlags(ApplyCmd)
+}
+
+// addCreateFlags registers the create flags on cmd. "cluster create" and "apply" share them.
+func addCreateFlags(cmd *cobra.Command) {
+	cmd.Flags().VarP(config.NewPathsValue(&createOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	cmd.Flags().BoolVar(&createOptions.SkipPreflight, "skip-preflight", false, "Skip preflight checks")
+	cmd.Flags().StringSliceVar(&createOptions.IgnorePreflightErrors, "ignore-preflight-errors", nil, "Preflight checks whose failures are shown but do not stop the creation, e.g. ports,swap, or 'all'")
+	cmd.Flags().BoolVar(&createOptions.DryRun, "dry-run", false, "Render configs, units and manifests into <workDir>/dryrun/<cluster> and diff them against the hosts without making any changes")
+	cmd.Flags().BoolVar(&createOptions.SmokeTest, "smoke-test", false, "Deploy and remove a smoke-test workload after installation to verify scheduling and service endpoints")
+	cmd.Flags().BoolVar(&createOptions.Preview, "preview", false, "Evaluate the prechecks of all steps on all hosts concurrently before execution and report which would change")
+	cmd.Flags().IntVar(&createOptions.MaxWorkers, "max-workers", 0, "Maximum number of steps executed concurrently (defaults to spec.global.maxWorkers, or a value based on the host count)")
+	cmd.Flags().StringVar(&createOptions.ReportFile, "report-file", "", "Write a report of the run (status, durations, per-host results and artifacts) to this path")
+	cmd.Flags().StringVarP(&createOptions.ReportFormat, "output", "o", plan.ReportFormatJSON, "Format of the --report-file report: json, junit (one test case per node and host) or sarif (failures only)")
+	cmd.Flags().StringVar(&createOptions.ArtifactsBundle, "artifacts", "", "Install offline from a bundle written by 'kubexm artifacts export'; implies spec.global.offlineMode: true")
+	cmd.Flags().StringVar(&createOptions.Progress, "progress", progressAuto, "How to show execution progress: auto (live on a terminal, plain otherwise), live, plain or none")
 	// Local verbose and yes flags are removed, will use global ones from rootCmd
 
 	// Mark flags as required if necessary
-	if err := createCmd.MarkFlagRequired("config"); err != nil {
+	if err := cmd.MarkFlagRequired("config"); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required: %v\n", err)
 		// Depending on desired strictness, could os.Exit(1) here or let Cobra handle it
 	}
 }
 
+// ApplyCmd is "cluster create" under the name used with offline bundles:
+// kubexm apply -f cluster.yaml --artifacts kubexm-bundle.tar.gz
+var ApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create a Kubernetes cluster, optionally from an offline artifacts bundle",
+	Long:  `Create a new Kubernetes cluster based on a provided configuration file. With --artifacts every binary, image and chart comes from the given bundle and no internet access is needed.`,
+	RunE:  runCreate,
+}
+
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new Kubernetes cluster",
 	Long:  `Create a new Kubernetes cluster based on a provided configuration file.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		log := logger.Get()
-		defer logger.SyncGlobal()
+	RunE:  runCreate,
+}
 
-		// Get global flags
-		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+func runCreate(cmd *cobra.Command, args []string) error {
+	log := logger.Get()
+	defer logger.SyncGlobal()
 
-		log.Info("Starting cluster creation process...")
+	// Get global flags
+	assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
 
-		// Validate config file path
-		if createOptions.ClusterConfigFile == "" {
-			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
-		}
+	log.Info("Starting cluster creation process...")
 
-		absPath, err := filepath.Abs(createOptions.ClusterConfigFile)
-		if err != nil {
-			log.Errorf("Failed to get absolute path for config file %s: %v", createOptions.ClusterConfigFile, err)
-			return fmt.Errorf("failed to get absolute path for config file %s: %w", createOptions.ClusterConfigFile, err)
-		}
-		log.Infof("Using cluster configuration from: %s", absPath)
+	// Validate config file path
+	if createOptions.ClusterConfigFile == "" {
+		return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+	}
+	if err := validateReportFormat(createOptions.ReportFormat); err != nil {
+		return err
+	}
 
-		// Load and parse configuration
-		clusterConfig, err := config.ParseFromFile(absPath)
-		if err != nil {
-			log.Errorf("Failed to parse cluster configuration: %v", err)
-			return fmt.Errorf("failed to parse cluster configuration from %s: %w", absPath, err)
-		}
+	absPath, err := filepath.Abs(createOptions.ClusterConfigFile)
+	if err != nil {
+		log.Errorf("Failed to get absolute path for config file %s: %v", createOptions.ClusterConfigFile, err)
+		return fmt.Errorf("failed to get absolute path for config file %s: %w", createOptions.ClusterConfigFile, err)
+	}
+	log.Infof("Using cluster configuration from: %s", absPath)
 
-		// Apply CLI overrides
-		if createOptions.SkipPreflight {
-			if clusterConfig.Spec.Global == nil {
-				clusterConfig.Spec.Global = &v1alpha1.GlobalSpec{}
-			}
-			clusterConfig.Spec.Global.SkipPreflight = true
-			log.Info("Preflight checks will be skipped due to --skip-preflight flag.")
-		}
+	// Load and parse configuration
+	clusterConfig, err := config.ParseFromFile(absPath)
+	if err != nil {
+		log.Errorf("Failed to parse cluster configuration: %v", err)
+		return fmt.Errorf("failed to parse cluster configuration from %s: %w", absPath, err)
+	}
 
-		// Create runtime context
-		goCtx := context.Background()
-		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig)
+	// Apply CLI overrides
+	if createOptions.SkipPreflight {
+		if clusterConfig.Spec.Global == nil {
+			clusterConfig.Spec.Global = &v1alpha1.GlobalSpec{}
+		}
+		clusterConfig.Spec.Global.SkipPreflight = true
+		log.Info("Preflight checks will be skipped due to --skip-preflight flag.")
+	}
+	if err := applyMaxWorkers(clusterConfig, createOptions.MaxWorkers, cmd.Flags().Changed("max-workers")); err != nil {
+		return err
+	}
+	if err := preflight.ValidateCheckNames(createOptions.IgnorePreflightErrors); err != nil {
+		return fmt.Errorf("invalid --ignore-preflight-errors: %w", err)
+	}
+	progress, err := newProgress(createOptions.Progress)
+	if err != nil {
+		return err
+	}
 
-		log.Info("Building runtime environment...")
-		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
-		if err != nil {
-			log.Errorf("Failed to build runtime environment: %v", err)
-			return fmt.Errorf("failed to build runtime environment: %w", err)
+	// Create runtime context
+	goCtx := context.Background()
+	rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).WithPreview(createOptions.Preview)
+	if progress != nil {
+		rtBuilder = rtBuilder.WithEventHandler(progress.Handle)
+	}
+	if createOptions.ArtifactsBundle != "" {
+		if rtBuilder, err = withArtifactsBundle(rtBuilder, createOptions.ArtifactsBundle); err != nil {
+			return err
 		}
-		defer cleanupFunc()
+	}
 
-		log.Info("Runtime environment built successfully.")
+	log.Info("Building runtime environment...")
+	runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+	if err != nil {
+		log.Errorf("Failed to build runtime environment: %v", err)
+		return fmt.Errorf("failed to build runtime environment: %w", err)
+	}
+	defer cleanupFunc()
 
-		// Create and execute pipeline
-		createPipeline := cluster.NewCreateClusterPipeline(assumeYesGlobal)
-		log.Infof("Instantiated pipeline: %s", createPipeline.Name())
+	log.Info("Runtime environment built successfully.")
 
-		// Plan the pipeline
-		log.Info("Planning pipeline execution...")
-		executionGraph, err := createPipeline.Plan(runtimeCtx)
-		if err != nil {
-			log.Errorf("Pipeline planning failed: %v", err)
-			return fmt.Errorf("pipeline planning failed: %w", err)
+	if global := clusterConfig.Spec.Global; global == nil || !global.SkipPreflight {
+		if err := runPreflightChecks(goCtx, runtimeCtx, createOptions.IgnorePreflightErrors); err != nil {
+			return err
 		}
+	}
+
+	// Create and execute pipeline
+	createPipeline := cluster.NewCreateClusterPipeline(assumeYesGlobal).WithSmokeTest(createOptions.SmokeTest)
+	log.Infof("Instantiated pipeline: %s", createPipeline.Name())
+
+	// Plan the pipeline
+	log.Info("Planning pipeline execution...")
+	executionGraph, err := createPipeline.Plan(runtimeCtx)
+	if err != nil {
+		log.Errorf("Pipeline planning failed: %v", err)
+		return fmt.Errorf("pipeline planning failed: %w", err)
+	}
 
-		// Execute the pipeline
-		log.Info("Executing pipeline...")
-		result, err := createPipeline.Run(runtimeCtx, executionGraph, createOptions.DryRun)
-		if err != nil {
-			log.Errorf("Cluster creation pipeline failed: %v", err)
-			if result != nil {
-				log.Infof("Pipeline final status: %s", result.Status)
-				if result.Message != "" {
-					log.Errorf("Pipeline error message: %s", result.Message)
-				}
+	// Execute the pipeline
+	log.Info("Executing pipeline...")
+	var result *plan.GraphExecutionResult
+	withProgress(progress, func() {
+		result, err = createPipeline.Run(runtimeCtx, executionGraph, createOptions.DryRun)
+	})
+	writeRunReport(log, createOptions.ReportFile, createOptions.ReportFormat, result)
+	if err != nil {
+		log.Errorf("Cluster creation pipeline failed: %v", err)
+		if result != nil {
+			log.Infof("Pipeline final status: %s", result.Status)
+			if result.Message != "" {
+				log.Errorf("Pipeline error message: %s", result.Message)
 			}
-			return fmt.Errorf("cluster creation pipeline execution failed: %w", err)
 		}
+		return fmt.Errorf("cluster creation pipeline execution failed: %w", err)
+	}
 
-		if result.Status == plan.StatusFailed {
-			log.Errorf("Cluster creation pipeline reported failure. Status: %s. Message: %s", result.Status, result.Message)
-			return fmt.Errorf("cluster creation pipeline failed with status: %s. Message: %s", result.Status, result.Message)
-		}
+	if result.Status == plan.StatusFailed {
+		log.Errorf("Cluster creation pipeline reported failure. Status: %s. Message: %s", result.Status, result.Message)
+		return fmt.Errorf("cluster creation pipeline failed with status: %s. Message: %s", result.Status, result.Message)
+	}
 
-		log.Infof("Cluster creation pipeline completed successfully! Status: %s", result.Status)
+	for _, warning := range result.Warnings {
+		log.Warnf("Optional component failed: %s", warning)
+	}
+	for _, d := range drift.ConfigDrifts(runtimeCtx.GetPipelineCache(), runtimeCtx.GetRunID()) {
+		log.Warnf("Node %s: %s config %s had drifted and was reconciled.", d.Node, d.Component, d.Path)
+	}
+
+	if createOptions.SmokeTest && !createOptions.DryRun {
+		log.Info("Smoke test passed: workload was scheduled, became Ready and was served by its Service.")
+	}
+	if result.HasWarnings() {
+		log.Warnf("Cluster creation pipeline completed with %d warning(s); the optional components above were not installed.", len(result.Warnings))
 		return nil
-	},
+	}
+	if report := plan.NewRunReport(result); report.NoOp {
+		log.Infof("Nothing to change: all %d node(s) that ran were already satisfied.", len(report.Summary.SatisfiedNodes))
+	}
+	log.Infof("Cluster creation pipeline completed successfully! Status: %s", result.Status)
+	return nil
+}
+
+// applyMaxWorkers overrides spec.global.maxWorkers with the --max-workers flag if it was set.
+func applyMaxWorkers(clusterConfig *v1alpha1.Cluster, maxWorkers int, set bool) error {
+	if !set {
+		return nil
+	}
+	if maxWorkers <= 0 {
+		return fmt.Errorf("--max-workers must be positive, got %d", maxWorkers)
+	}
+	if clusterConfig.Spec.Global == nil {
+		clusterConfig.Spec.Global = &v1alpha1.GlobalSpec{}
+	}
+	clusterConfig.Spec.Global.MaxWorkers = maxWorkers
+	return nil
+}
+
+// validateReportFormat rejects an unknown --output before anything runs, as the report is only
+// written at the end.
+func validateReportFormat(format string) error {
+	if !slices.Contains(plan.ReportFormats, format) {
+		return fmt.Errorf("invalid --output '%s', must be one of %v", format, plan.ReportFormats)
+	}
+	return nil
+}
+
+// writeRunReport archives the pipeline result to path in format when a report file was requested.
+// Failing to write the report is logged but never changes the outcome of the run.
+func writeRunReport(log *logger.Logger, path, format string, result *plan.GraphExecutionResult) {
+	if path == "" || result == nil {
+		return
+	}
+	if err := plan.WriteReportAs(path, format, result); err != nil {
+		log.Errorf("Failed to write run report: %v", err)
+		return
+	}
+	log.Infof("Run report written to %s", path)
+}
+
+// runPreflightChecks runs the preflight checks on all hosts, prints the results and fails on any
+// failure not listed in ignoreErrors.
+func runPreflightChecks(goCtx context.Context, runtimeCtx *runtime.Context, ignoreErrors []string) error {
+	log := logger.Get()
+	targets, err := preflight.TargetsFromContext(runtimeCtx)
+	if err != nil {
+		return fmt.Errorf("failed to prepare preflight checks: %w", err)
+	}
+	opts := preflight.Options{IgnoreErrors: ignoreErrors}
+	if pf := runtimeCtx.GetClusterConfig().Spec.Preflight; pf != nil {
+		opts.SkipChecks = pf.SkipChecks
+	}
+
+	log.Infof("Running preflight checks on %d host(s)...", len(targets))
+	report := preflight.Run(goCtx, targets, preflight.DefaultChecks(), opts)
+	report.PrintTable(os.Stdout)
+	if err := report.Err(); err != nil {
+		return err
+	}
+	log.Info("Preflight checks passed.")
+	return nil
+}
+
+// withArtifactsBundle makes rtBuilder install offline from the bundle given with --artifacts, which
+// the preflight ExtractBundle task extracts before anything is installed.
+func withArtifactsBundle(rtBuilder *runtime.Builder, bundle string) (*runtime.Builder, error) {
+	bundlePath, err := filepath.Abs(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for artifacts bundle %s: %w", bundle, err)
+	}
+	if _, err := os.Stat(bundlePath); err != nil {
+		return nil, fmt.Errorf("artifacts bundle %s is not readable: %w", bundlePath, err)
+	}
+	logger.Get().Infof("Installing offline from artifacts bundle %s", bundlePath)
+	return rtBuilder.WithArtifactsBundle(bundlePath), nil
 }
@@ -0,0 +1,100 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	offlinestep "github.com/mensylisir/kubexm/internal/step/offline"
+	taskpreflight "github.com/mensylisir/kubexm/internal/task/preflight"
+)
+
+func TestApplyMaxWorkers(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxWorkers int
+		set        bool
+		expected   int
+		expectErr  bool
+	}{
+		{name: "flag not set keeps the config", maxWorkers: 0, expected: 5},
+		{name: "flag overrides the config", maxWorkers: 8, set: true, expected: 8},
+		{name: "zero is rejected", maxWorkers: 0, set: true, expectErr: true},
+		{name: "negative is rejected", maxWorkers: -2, set: true, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Global: &v1alpha1.GlobalSpec{MaxWorkers: 5}}}
+			err := applyMaxWorkers(cfg, tt.maxWorkers, tt.set)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected --max-workers=%d to be rejected", tt.maxWorkers)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Spec.Global.MaxWorkers != tt.expected {
+				t.Errorf("expected maxWorkers %d, got %d", tt.expected, cfg.Spec.Global.MaxWorkers)
+			}
+		})
+	}
+}
+
+func TestWithArtifactsBundle_ReachesExtractBundle(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "kubexm-bundle.tar.gz")
+	if err := os.WriteFile(bundlePath, []byte("bundle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	addCreateFlags(cmd)
+	if err := cmd.Flags().Set("artifacts", bundlePath); err != nil {
+		t.Fatalf("failed to set --artifacts: %v", err)
+	}
+	t.Cleanup(func() { createOptions.ArtifactsBundle = "" })
+
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "offline"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts:      []v1alpha1.HostSpec{{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster}}},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.29.3"},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+
+	rtBuilder, err := withArtifactsBundle(runtime.NewBuilderFromConfig(cfg), createOptions.ArtifactsBundle)
+	if err != nil {
+		t.Fatalf("withArtifactsBundle failed: %v", err)
+	}
+	ctx := runtimetest.NewRuntimeFromBuilder(t, rtBuilder)
+	if !ctx.IsOfflineMode() || ctx.GetArtifactsBundlePath() != bundlePath {
+		t.Fatalf("expected offline mode with bundle %s, got offline=%v bundle=%s", bundlePath, ctx.IsOfflineMode(), ctx.GetArtifactsBundlePath())
+	}
+
+	fragment, err := taskpreflight.NewExtractBundleTask("").Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	node := fragment.Nodes["ExtractBundleOnControlNode"]
+	if node == nil {
+		t.Fatalf("expected an ExtractBundleOnControlNode node, got %v", fragment.Nodes)
+	}
+	if s, ok := node.Step.(*offlinestep.ExtractBundleStep); !ok || s.BundlePath != bundlePath {
+		t.Errorf("expected the step to extract %s, got %+v", bundlePath, node.Step)
+	}
+}
+
+func TestWithArtifactsBundle_MissingBundle(t *testing.T) {
+	cfg := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{}}
+	if _, err := withArtifactsBundle(runtime.NewBuilderFromConfig(cfg), filepath.Join(t.TempDir(), "missing.tar.gz")); err == nil {
+		t.Fatal("expected a missing bundle to be rejected")
+	}
+}
@@ -5,33 +5,42 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/config"
 	"github.com/mensylisir/kubexm/internal/logger"
-	"github.com/mensylisir/kubexm/internal/plan"
 	kubexmcluster "github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 )
 
 type DeleteClusterOptions struct {
-	ClusterName string
-	Force     bool
-	DryRun    bool
+	ClusterName       string
+	ClusterConfigFile string
+	Force             bool
+	DryRun            bool
+	ReportFile        string
+	ReportFormat      string
 }
 
 var deleteClusterOpts = &DeleteClusterOptions{}
 
 func init() {
-	DeleteClusterCmd.Flags().StringVarP(&deleteClusterOpts.ClusterName, "name", "n", "", "Cluster name (required)")
+	DeleteClusterCmd.Flags().StringVarP(&deleteClusterOpts.ClusterName, "name", "n", "", "Name of a cluster created by kubexm")
+	DeleteClusterCmd.Flags().VarP(config.NewPathsValue(&deleteClusterOpts.ClusterConfigFile), "config", "f", "Path to the cluster configuration YAML file")
 	DeleteClusterCmd.Flags().BoolVar(&deleteClusterOpts.Force, "force", false, "Force deletion without confirmation")
 	DeleteClusterCmd.Flags().BoolVar(&deleteClusterOpts.DryRun, "dry-run", false, "Simulate without making changes")
-	DeleteClusterCmd.MarkFlagRequired("name")
+	DeleteClusterCmd.Flags().StringVar(&deleteClusterOpts.ReportFile, "report-file", "", "Write a report of the run (status, durations and per-host results) to this path")
+	DeleteClusterCmd.Flags().StringVarP(&deleteClusterOpts.ReportFormat, "output", "o", plan.ReportFormatJSON, "Format of the --report-file report: json, junit (one test case per node and host) or sarif (failures only)")
+	DeleteClusterCmd.MarkFlagsMutuallyExclusive("name", "config")
 }
 
-// DeleteClusterCmd - kubexm delete cluster --name=xxx
+// DeleteClusterCmd - kubexm delete cluster --name=xxx | -f cluster.yaml
 var DeleteClusterCmd = &cobra.Command{
 	Use:   "cluster",
 	Short: "Delete a Kubernetes cluster",
@@ -42,15 +51,15 @@ var DeleteClusterCmd = &cobra.Command{
 
 		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
 
-		log.Info("Starting cluster deletion process...")
-
-		if deleteClusterOpts.ClusterName == "" {
-			return fmt.Errorf("cluster name must be provided via --name or -n flag")
+		if err := validateReportFormat(deleteClusterOpts.ReportFormat); err != nil {
+			return err
 		}
 
-		clusterConfig, err := LoadClusterConfig(deleteClusterOpts.ClusterName)
+		log.Info("Starting cluster deletion process...")
+
+		clusterConfig, err := loadDeleteClusterConfig(deleteClusterOpts)
 		if err != nil {
-			return fmt.Errorf("failed to load cluster configuration: %w", err)
+			return err
 		}
 		log.Infof("Configuration loaded for cluster: %s", clusterConfig.Name)
 
@@ -80,6 +89,8 @@ var DeleteClusterCmd = &cobra.Command{
 
 		deletePipeline := kubexmcluster.NewDeleteClusterPipeline(assumeYesGlobal)
 		result, err := deletePipeline.Run(runtimeCtx, nil, deleteClusterOpts.DryRun)
+		writeRunReport(log, deleteClusterOpts.ReportFile, deleteClusterOpts.ReportFormat, result)
+		logHostResults(log, result)
 		if err != nil {
 			return fmt.Errorf("cluster deletion failed: %w", err)
 		}
@@ -92,3 +103,40 @@ var DeleteClusterCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// loadDeleteClusterConfig loads the cluster to delete from --config, or by name from the kubexm work dirs.
+func loadDeleteClusterConfig(opts *DeleteClusterOptions) (*v1alpha1.Cluster, error) {
+	if opts.ClusterConfigFile != "" {
+		absPath, err := filepath.Abs(opts.ClusterConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for config file %s: %w", opts.ClusterConfigFile, err)
+		}
+		clusterConfig, err := config.ParseFromFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cluster configuration from %s: %w", absPath, err)
+		}
+		return clusterConfig, nil
+	}
+	if opts.ClusterName == "" {
+		return nil, fmt.Errorf("the cluster must be provided via -f/--config or -n/--name")
+	}
+	clusterConfig, err := LoadClusterConfig(opts.ClusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster configuration: %w", err)
+	}
+	return clusterConfig, nil
+}
+
+// logHostResults prints the outcome of a run for every host it touched.
+func logHostResults(log *logger.Logger, result *plan.GraphExecutionResult) {
+	if result == nil {
+		return
+	}
+	for _, host := range plan.NewRunReport(result).Hosts {
+		if host.Status == plan.StatusFailed {
+			log.Errorf("Host %s: %s after %d steps, failed steps: %s", host.Host, host.Status, host.Steps, strings.Join(host.FailedSteps, ", "))
+			continue
+		}
+		log.Infof("Host %s: %s (%d steps)", host.Host, host.Status, host.Steps)
+	}
+}
@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+type DeleteNodeOptions struct {
+	ClusterConfigFile string
+	Nodes             []string
+	Force             bool
+	DryRun            bool
+}
+
+var deleteNodeOptions = &DeleteNodeOptions{}
+
+func init() {
+	DeleteNodeCmd.Flags().VarP(config.NewPathsValue(&deleteNodeOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	DeleteNodeCmd.Flags().StringSliceVar(&deleteNodeOptions.Nodes, "node", nil, "Name of a host in the configuration to remove (repeatable, required)")
+	DeleteNodeCmd.Flags().BoolVar(&deleteNodeOptions.Force, "force", false, "Force delete without confirmation")
+	DeleteNodeCmd.Flags().BoolVar(&deleteNodeOptions.DryRun, "dry-run", false, "Simulate the node removal without making any changes")
+
+	for _, flag := range []string{"config", "node"} {
+		if err := DeleteNodeCmd.MarkFlagRequired(flag); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to mark '%s' flag as required: %v\n", flag, err)
+		}
+	}
+}
+
+// DeleteNodeCmd drains the given nodes and removes them from the cluster.
+var DeleteNodeCmd = &cobra.Command{
+	Use:   "delete-node",
+	Short: "Drain a node and remove it from an existing cluster",
+	Long: `Remove nodes from an existing Kubernetes cluster. Each node is cordoned and drained, removed
+from the etcd member list if it is a control-plane node, reset with kubeadm on the host and finally
+deleted from the API server. Nodes are removed one at a time through a master that stays in the
+cluster. Remove the hosts from the configuration file afterwards.`,
+	Example: `  kubexm delete-node -f cluster.yaml --node worker3
+  kubexm delete-node -f cluster.yaml --node master3 --node worker4`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+
+		absPath, err := filepath.Abs(deleteNodeOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file %s: %w", deleteNodeOptions.ClusterConfigFile, err)
+		}
+		log.Infof("Using cluster configuration from: %s", absPath)
+
+		clusterConfig, err := config.ParseFromFile(absPath)
+		if err != nil {
+			log.Errorf("Failed to parse cluster configuration: %v", err)
+			return fmt.Errorf("failed to parse cluster configuration from %s: %w", absPath, err)
+		}
+
+		if !deleteNodeOptions.Force && !assumeYesGlobal {
+			fmt.Printf("WARNING: This action will remove nodes %s from cluster '%s'.\n", strings.Join(deleteNodeOptions.Nodes, ", "), clusterConfig.Name)
+			fmt.Println("The nodes will be drained, reset and deleted from the cluster.")
+			fmt.Print("Are you sure you want to proceed? (yes/no): ")
+			reader := bufio.NewReader(os.Stdin)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				input = "no"
+			}
+			if strings.TrimSpace(strings.ToLower(input)) != "yes" {
+				log.Info("Delete node aborted by user.")
+				return nil
+			}
+		}
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig)
+		log.Info("Building runtime environment...")
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(context.Background())
+		if err != nil {
+			log.Errorf("Failed to build runtime environment: %v", err)
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		deleteNodePipeline := cluster.NewDeleteNodePipeline(deleteNodeOptions.Nodes)
+		log.Info("Planning pipeline execution...")
+		executionGraph, err := deleteNodePipeline.Plan(runtimeCtx)
+		if err != nil {
+			log.Errorf("Pipeline planning failed: %v", err)
+			return fmt.Errorf("pipeline planning failed: %w", err)
+		}
+
+		log.Info("Executing pipeline...")
+		result, err := deleteNodePipeline.Run(runtimeCtx, executionGraph, deleteNodeOptions.DryRun)
+		if err != nil {
+			log.Errorf("Delete node pipeline failed: %v", err)
+			return fmt.Errorf("delete node pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("delete node pipeline failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		log.Infof("Nodes %s removed from the cluster. Status: %s", strings.Join(deleteNodeOptions.Nodes, ", "), result.Status)
+		return nil
+	},
+}
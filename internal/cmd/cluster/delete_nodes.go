@@ -27,7 +27,7 @@ var deleteNodesOptions = &DeleteNodesOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(deleteNodesCmd)
-	deleteNodesCmd.Flags().StringVarP(&deleteNodesOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	deleteNodesCmd.Flags().VarP(config.NewPathsValue(&deleteNodesOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	deleteNodesCmd.Flags().BoolVar(&deleteNodesOptions.Force, "force", false, "Force delete without confirmation")
 	deleteNodesCmd.Flags().BoolVar(&deleteNodesOptions.DryRun, "dry-run", false, "Simulate the node deletion without making any changes")
 
@@ -0,0 +1,28 @@
+package cluster
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDeleteClusterConfigErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DeleteClusterOptions
+		expectedErr string
+	}{
+		{name: "neither config nor name", expectedErr: "-f/--config or -n/--name"},
+		{name: "missing config file", opts: DeleteClusterOptions{ClusterConfigFile: filepath.Join(t.TempDir(), "missing.yaml")}, expectedErr: "failed to parse cluster configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			_, err := loadDeleteClusterConfig(&opts)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
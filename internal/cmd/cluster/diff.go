@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/drift"
+)
+
+type DiffOptions struct {
+	ClusterConfigFile string
+	Timeout           time.Duration
+	ExitCode          bool
+	Output            string
+}
+
+// diffOutputFormats are the formats accepted by `kubexm diff -o`.
+var diffOutputFormats = []string{"text", "json", "yaml"}
+
+var diffOptions = &DiffOptions{}
+
+func init() {
+	DiffCmd.Flags().VarP(config.NewPathsValue(&diffOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	DiffCmd.Flags().DurationVar(&diffOptions.Timeout, "timeout", 5*time.Minute, "Timeout for collecting live cluster state")
+	DiffCmd.Flags().BoolVar(&diffOptions.ExitCode, "exit-code", false, "Return an error when drift is detected")
+	DiffCmd.Flags().StringVarP(&diffOptions.Output, "output", "o", "text", fmt.Sprintf("Output format of the drift report (%s)", strings.Join(diffOutputFormats, "|")))
+
+	if err := DiffCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for diff command: %v\n", err)
+	}
+}
+
+// DiffCmd reports drift between the desired configuration and the live cluster.
+var DiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show drift between the cluster configuration and the live cluster",
+	Long: `Compare the desired cluster configuration with the live cluster state and print a drift report.
+
+The following are compared:
+  - node registration, labels and taints
+  - kube-apiserver, kubelet and container runtime versions
+  - containerd and kubelet config files and systemd units rendered from the configuration
+  - enabled addons installed from helm charts
+
+No drift means that apply would be a no-op. Hosts are only read, never changed.
+
+Examples:
+  # Show drift for a cluster
+  kubexm diff -f config.yaml
+
+  # Fail when drift is detected (useful in CI)
+  kubexm diff -f config.yaml --exit-code
+
+  # Print the report as JSON
+  kubexm diff -f config.yaml -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		if diffOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+		if !slices.Contains(diffOutputFormats, diffOptions.Output) {
+			return fmt.Errorf("invalid output format '%s', must be one of: %s", diffOptions.Output, strings.Join(diffOutputFormats, ", "))
+		}
+
+		absPath, err := filepath.Abs(diffOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		log.Infof("Collecting drift report for cluster '%s'", clusterConfig.Name)
+
+		goCtx, cancel := context.WithTimeout(context.Background(), diffOptions.Timeout)
+		defer cancel()
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewDiffPipeline()
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("diff pipeline planning failed: %w", err)
+		}
+
+		result, err := p.Run(runtimeCtx, graph, false)
+		if err != nil {
+			return fmt.Errorf("diff pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("diff failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		cached, ok := runtimeCtx.GetPipelineCache().Get(fmt.Sprintf(common.CacheKeyClusterDriftReport, runtimeCtx.GetRunID()))
+		if !ok {
+			return fmt.Errorf("diff pipeline did not produce a drift report")
+		}
+		report, ok := cached.(*drift.Report)
+		if !ok {
+			return fmt.Errorf("unexpected drift report type %T", cached)
+		}
+
+		if err := printDriftReport(cmd.OutOrStdout(), report, diffOptions.Output); err != nil {
+			return err
+		}
+		if diffOptions.ExitCode && report.HasDrift() {
+			return fmt.Errorf("drift detected: %d difference(s)", len(report.Items))
+		}
+		return nil
+	},
+}
+
+func printDriftReport(w io.Writer, report *drift.Report, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode drift report: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode drift report: %w", err)
+		}
+		fmt.Fprint(w, string(data))
+	default:
+		fmt.Fprint(w, report.String())
+	}
+	return nil
+}
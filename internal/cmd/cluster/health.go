@@ -26,7 +26,7 @@ var healthOptions = &HealthOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(healthCmd)
-	healthCmd.Flags().StringVarP(&healthOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	healthCmd.Flags().VarP(config.NewPathsValue(&healthOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	healthCmd.Flags().StringVarP(&healthOptions.Component, "component", "c", "all", "Component to check: all, apiserver, scheduler, controller-manager, kubelet, cluster (default: all)")
 	healthCmd.Flags().DurationVar(&healthOptions.WaitTimeout, "wait", 5*time.Minute, "Timeout to wait for health check")
 
@@ -66,7 +66,7 @@ var manifestsCmd = &cobra.Command{
 
 func init() {
 	ClusterCmd.AddCommand(manifestsCmd)
-	manifestsCmd.Flags().StringVarP(&manifestsOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	manifestsCmd.Flags().VarP(config.NewPathsValue(&manifestsOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	manifestsCmd.Flags().StringVarP(&manifestsOptions.OutputPath, "output", "o", "", "Output directory for manifests (default: <cluster-name>-manifests)")
 	manifestsCmd.Flags().BoolVar(&manifestsOptions.DryRun, "dry-run", false, "Show what would be generated without creating files")
 
@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	pipelinecluster "github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+type MigrateRuntimeOptions struct {
+	ClusterConfigFile string
+	Timeout           time.Duration
+	DryRun            bool
+}
+
+var migrateRuntimeOptions = &MigrateRuntimeOptions{}
+
+func init() {
+	ClusterCmd.AddCommand(migrateRuntimeCmd)
+	migrateRuntimeCmd.Flags().VarP(config.NewPathsValue(&migrateRuntimeOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file, with spec.kubernetes.containerRuntime.type set to containerd (required)")
+	migrateRuntimeCmd.Flags().DurationVar(&migrateRuntimeOptions.Timeout, "timeout", 2*time.Hour, "Timeout for migrating all nodes")
+	migrateRuntimeCmd.Flags().BoolVar(&migrateRuntimeOptions.DryRun, "dry-run", false, "Show the migration plan without making changes")
+
+	if err := migrateRuntimeCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for migrate-runtime command: %v\n", err)
+	}
+}
+
+var migrateRuntimeCmd = &cobra.Command{
+	Use:   "migrate-runtime",
+	Short: "Migrate cluster nodes from docker to containerd",
+	Long: `Move the nodes of a cluster running the kubelet on docker/cri-dockerd over to containerd.
+
+Nodes are migrated one at a time: each node is cordoned and drained, docker and cri-dockerd are
+stopped and disabled, containerd is configured and started, the kubelet is pointed at the
+containerd socket, and the node is uncordoned once it is Ready on containerd.
+
+Set spec.kubernetes.containerRuntime.type to containerd in the configuration before running it.
+
+Examples:
+  kubexm cluster migrate-runtime -f config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+
+		if migrateRuntimeOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+
+		absPath, err := filepath.Abs(migrateRuntimeOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		log.Infof("Starting runtime migration for cluster '%s'", clusterConfig.Name)
+
+		if !assumeYesGlobal && !migrateRuntimeOptions.DryRun {
+			fmt.Printf("WARNING: This will drain every node of cluster '%s' in turn and replace docker with containerd.\n", clusterConfig.Name)
+			fmt.Print("Are you sure you want to proceed? (yes/no): ")
+			reader := bufio.NewReader(os.Stdin)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				input = "no"
+			}
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input != "yes" {
+				log.Info("Runtime migration aborted by user.")
+				return nil
+			}
+		}
+
+		goCtx, cancel := context.WithTimeout(context.Background(), migrateRuntimeOptions.Timeout)
+		defer cancel()
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipHostConnect(true).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := pipelinecluster.NewMigrateRuntimePipeline()
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("runtime migration pipeline planning failed: %w", err)
+		}
+
+		result, err := p.Run(runtimeCtx, graph, migrateRuntimeOptions.DryRun)
+		if err != nil {
+			if result != nil && result.Status == plan.StatusFailed {
+				return fmt.Errorf("runtime migration failed: %s", result.Message)
+			}
+			return fmt.Errorf("runtime migration pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("runtime migration failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		log.Infof("Runtime migration completed successfully. Status: %s", result.Status)
+		return nil
+	},
+}
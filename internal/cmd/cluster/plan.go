@@ -0,0 +1,138 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/plan/export"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+type PlanOptions struct {
+	ClusterConfigFile string
+	Output            string
+	SkipPreflight     bool
+	SmokeTest         bool
+}
+
+var planOptions = &PlanOptions{}
+
+func init() {
+	PlanCmd.Flags().VarP(config.NewPathsValue(&planOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	PlanCmd.Flags().StringVarP(&planOptions.Output, "output", "o", "text", "Output format: text, json, dot or mermaid")
+	PlanCmd.Flags().BoolVar(&planOptions.SkipPreflight, "skip-preflight", false, "Plan as if preflight checks were skipped")
+	PlanCmd.Flags().BoolVar(&planOptions.SmokeTest, "smoke-test", false, "Include the post-install smoke test in the plan")
+
+	if err := PlanCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for plan command: %v\n", err)
+	}
+}
+
+// PlanCmd prints the execution graph of a cluster creation without running any step.
+var PlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the execution plan for creating a cluster without running it",
+	Long: `Build the cluster creation pipeline from a configuration file and print its execution graph:
+every node with the step it runs, the hosts it targets and the nodes it waits for. Hosts are
+connected to while planning, but no step is executed.
+
+Examples:
+  # Review the plan as a numbered list
+  kubexm plan -f config.yaml
+
+  # Render the plan with Graphviz
+  kubexm plan -f config.yaml -o dot | dot -Tsvg > plan.svg
+
+  # Embed the plan in Markdown documentation
+  kubexm plan -f config.yaml -o mermaid`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+
+		if !isPlanOutputFormat(planOptions.Output) {
+			return fmt.Errorf("unsupported output format %q, must be one of text, json, dot or mermaid", planOptions.Output)
+		}
+		if planOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+
+		absPath, err := filepath.Abs(planOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file %s: %w", planOptions.ClusterConfigFile, err)
+		}
+		log.Infof("Using cluster configuration from: %s", absPath)
+
+		clusterConfig, err := config.ParseFromFile(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse cluster configuration from %s: %w", absPath, err)
+		}
+		if planOptions.SkipPreflight {
+			if clusterConfig.Spec.Global == nil {
+				clusterConfig.Spec.Global = &v1alpha1.GlobalSpec{}
+			}
+			clusterConfig.Spec.Global.SkipPreflight = true
+		}
+
+		runtimeCtx, cleanupFunc, err := runtime.NewBuilderFromConfig(clusterConfig).Build(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		createPipeline := cluster.NewCreateClusterPipeline(assumeYesGlobal).WithSmokeTest(planOptions.SmokeTest)
+		graph, err := createPipeline.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("pipeline planning failed: %w", err)
+		}
+		return writePlan(cmd.OutOrStdout(), graph, planOptions.Output)
+	},
+}
+
+func isPlanOutputFormat(format string) bool {
+	switch format {
+	case "text", "json", "dot", "mermaid":
+		return true
+	}
+	return false
+}
+
+// writePlan renders graph to w in the given output format.
+func writePlan(w io.Writer, graph *plan.ExecutionGraph, format string) error {
+	var diagram string
+	var err error
+	switch format {
+	case "dot":
+		diagram, err = export.ToDOT(graph)
+	case "mermaid":
+		diagram, err = export.ToMermaid(graph)
+	default:
+		planned, planErr := plan.NewPlannedGraph(graph)
+		if planErr != nil {
+			return fmt.Errorf("cannot render execution graph: %w", planErr)
+		}
+		if format == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(planned)
+		}
+		return planned.WriteText(w)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot render execution graph: %w", err)
+	}
+	_, err = io.WriteString(w, diagram)
+	return err
+}
@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+)
+
+func TestWritePlan(t *testing.T) {
+	g := plan.NewExecutionGraph("CreateCluster")
+	g.Nodes["Init"] = &plan.ExecutionNode{Name: "Init", StepName: "KubeadmInit", Hostnames: []string{"master1"}}
+	g.Nodes["Join"] = &plan.ExecutionNode{Name: "Join", StepName: "KubeadmJoin", Hostnames: []string{"worker1"}, Dependencies: []plan.NodeID{"Init"}}
+	g.CalculateEntryAndExitNodes()
+
+	var out bytes.Buffer
+	if err := writePlan(&out, g, "json"); err != nil {
+		t.Fatalf("writePlan failed: %v", err)
+	}
+	var planned plan.PlannedGraph
+	if err := json.Unmarshal(out.Bytes(), &planned); err != nil {
+		t.Fatalf("expected valid JSON, got %v:\n%s", err, out.String())
+	}
+	if len(planned.Nodes) != 2 || planned.Nodes[1].ID != "Join" || planned.Nodes[1].Hosts[0] != "worker1" || planned.Nodes[1].Dependencies[0] != "Init" {
+		t.Errorf("unexpected planned graph: %+v", planned)
+	}
+
+	out.Reset()
+	if err := writePlan(&out, g, "dot"); err != nil {
+		t.Fatalf("writePlan failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `"Init" -> "Join";`) {
+		t.Errorf("expected a DOT edge from Init to Join, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := writePlan(&out, g, "mermaid"); err != nil {
+		t.Fatalf("writePlan failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "flowchart LR\n") || !strings.Contains(out.String(), "n1 --> n2") {
+		t.Errorf("expected a Mermaid flowchart from Init to Join, got:\n%s", out.String())
+	}
+
+	if isPlanOutputFormat("yaml") {
+		t.Error("expected yaml to be rejected as an output format")
+	}
+}
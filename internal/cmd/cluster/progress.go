@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/ui"
+)
+
+const (
+	progressAuto  = "auto"
+	progressLive  = "live"
+	progressPlain = "plain"
+	progressNone  = "none"
+)
+
+// newProgress returns the progress display selected by the --progress flag, or nil for "none".
+// "auto" shows the live display when stdout is a terminal and plain lines otherwise.
+func newProgress(mode string) (*ui.Progress, error) {
+	switch mode {
+	case progressAuto, "":
+		return ui.NewProgress(os.Stdout, ui.IsTerminal(os.Stdout)), nil
+	case progressLive:
+		return ui.NewProgress(os.Stdout, true), nil
+	case progressPlain:
+		return ui.NewProgress(os.Stdout, false), nil
+	case progressNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid --progress %q, must be one of %s, %s, %s or %s", mode, progressAuto, progressLive, progressPlain, progressNone)
+	}
+}
+
+// withProgress runs fn while p is displayed. Console logs are printed above the live display
+// instead of through it.
+func withProgress(p *ui.Progress, fn func()) {
+	if p == nil {
+		fn()
+		return
+	}
+	restore := logger.SetConsoleWriter(p)
+	p.Start()
+	defer func() {
+		p.Stop()
+		restore()
+	}()
+	fn()
+}
@@ -31,7 +31,7 @@ var reconfigureOptions = &ReconfigureOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(reconfigureCmd)
-	reconfigureCmd.Flags().StringVarP(&reconfigureOptions.ClusterConfigFile, "config", "f", "", "Path to current cluster configuration YAML file (required)")
+	reconfigureCmd.Flags().VarP(config.NewPathsValue(&reconfigureOptions.ClusterConfigFile), "config", "f", "Path to current cluster configuration YAML file (required)")
 	reconfigureCmd.Flags().StringVarP(&reconfigureOptions.NewConfigFile, "new-config", "n", "", "Path to new cluster configuration YAML file (required for full reconfigure)")
 	reconfigureCmd.Flags().StringVarP(&reconfigureOptions.Component, "component", "c", "all", "Component to reconfigure: all, apiserver, scheduler, controller-manager, kubelet, proxy (default: all)")
 	reconfigureCmd.Flags().BoolVar(&reconfigureOptions.RestartServices, "restart", true, "Restart affected services after reconfiguration (default: true)")
@@ -30,7 +30,7 @@ var restoreOptions = &RestoreOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(restoreCmd)
-	restoreCmd.Flags().StringVarP(&restoreOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	restoreCmd.Flags().VarP(config.NewPathsValue(&restoreOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	restoreCmd.Flags().StringVarP(&restoreOptions.BackupPath, "backup", "b", "", "Path to backup archive to restore from (required)")
 	restoreCmd.Flags().StringVarP(&restoreOptions.RestoreType, "type", "t", "all", "Restore type: all, pki, etcd, kubernetes (default: all)")
 	restoreCmd.Flags().StringVarP(&restoreOptions.SnapshotPath, "snapshot", "s", "", "Path to etcd snapshot file (required for etcd restore type)")
@@ -29,7 +29,7 @@ var scaleOptions = &ScaleOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(scaleCmd)
-	scaleCmd.Flags().StringVarP(&scaleOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	scaleCmd.Flags().VarP(config.NewPathsValue(&scaleOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	scaleCmd.Flags().StringVar(&scaleOptions.Direction, "direction", "", "Scale direction: 'in' (remove nodes) or 'out' (add nodes) (required)")
 	scaleCmd.Flags().BoolVar(&scaleOptions.SkipPreflight, "skip-preflight", false, "Skip preflight checks")
 	scaleCmd.Flags().BoolVar(&scaleOptions.DryRun, "dry-run", false, "Simulate the scaling operation without making any changes")
@@ -11,16 +11,23 @@ import (
 
 	"github.com/mensylisir/kubexm/internal/config"
 	"github.com/mensylisir/kubexm/internal/logger"
-	"github.com/mensylisir/kubexm/internal/plan"
 	kubexmcluster "github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/spf13/cobra"
 )
 
-var upgradeCmd = &cobra.Command{
-	Use:   "upgrade",
+// UpgradeClusterCmd - kubexm upgrade cluster -f cluster.yaml --to-version v1.x.y
+var UpgradeClusterCmd = &cobra.Command{
+	Use:   "cluster",
 	Short: "Upgrade an existing Kubernetes cluster",
-	Long:  `Upgrade an existing Kubernetes cluster to a newer version or apply updates.`,
+	Long: `Upgrade an existing Kubernetes cluster to a newer Kubernetes version. Control-plane nodes are
+upgraded one at a time with kubeadm upgrade apply/node, followed by workers in batches of
+--worker-batch-size. Every node is drained while its kubelet and kubectl binaries are replaced and
+uncordoned afterwards. The target version must be supported and at most one minor version ahead of
+the version in the configuration file.`,
+	Example: `  kubexm upgrade cluster -f cluster.yaml --to-version v1.29.4
+  kubexm upgrade -f cluster.yaml --to-version v1.29.4 --worker-batch-size 3`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log := logger.Get() // Use global logger
 		defer logger.SyncGlobal()
@@ -32,8 +39,11 @@ var upgradeCmd = &cobra.Command{
 		if upgradeOptions.ClusterConfigFile == "" {
 			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag for upgrade")
 		}
+		if upgradeOptions.WorkerBatchSize < 1 {
+			return fmt.Errorf("--worker-batch-size must be at least 1, got %d", upgradeOptions.WorkerBatchSize)
+		}
 		if upgradeOptions.TargetVersion == "" {
-			return fmt.Errorf("target Kubernetes version must be provided via --version flag for upgrade")
+			return fmt.Errorf("target Kubernetes version must be provided via --to-version flag for upgrade")
 		}
 
 		absPath, err := filepath.Abs(upgradeOptions.ClusterConfigFile)
@@ -50,9 +60,6 @@ var upgradeCmd = &cobra.Command{
 		}
 		log.Infof("Configuration loaded for cluster: %s", clusterConfig.Name)
 
-		// Apply CLI overrides if any (e.g., target version if it can also be in config)
-		// For now, TargetVersion from flag is primary.
-
 		if !assumeYesGlobal {
 			fmt.Printf("WARNING: This action will attempt to upgrade the Kubernetes cluster '%s' to version '%s'.\n", clusterConfig.Name, upgradeOptions.TargetVersion)
 			fmt.Print("Ensure you have backed up your cluster and reviewed upgrade compatibility. Proceed? (yes/no): ")
@@ -79,8 +86,7 @@ var upgradeCmd = &cobra.Command{
 		defer cleanupFunc()
 		log.Info("Runtime environment built successfully for upgrade.")
 
-		// Pass TargetVersion and assumeYesGlobal to the pipeline constructor
-		upgradePipeline := kubexmcluster.NewUpgradeClusterPipeline(upgradeOptions.TargetVersion, assumeYesGlobal)
+		upgradePipeline := kubexmcluster.NewUpgradeClusterPipeline(upgradeOptions.TargetVersion, upgradeOptions.WorkerBatchSize, assumeYesGlobal)
 		log.Infof("Instantiated pipeline: %s", upgradePipeline.Name())
 
 		log.Info("Executing upgrade pipeline run...")
@@ -106,22 +112,21 @@ var upgradeCmd = &cobra.Command{
 type UpgradeOptions struct {
 	ClusterConfigFile string
 	TargetVersion     string
+	WorkerBatchSize   int
 	DryRun            bool
 }
 
 var upgradeOptions = &UpgradeOptions{}
 
-func init() {
-	ClusterCmd.AddCommand(upgradeCmd)
-	upgradeCmd.Flags().StringVarP(&upgradeOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required for context, may not be modified)")
-	upgradeCmd.Flags().StringVarP(&upgradeOptions.TargetVersion, "version", "t", "", "Target Kubernetes version for the upgrade (e.g., v1.24.3) (required)")
-	upgradeCmd.Flags().BoolVar(&upgradeOptions.DryRun, "dry-run", false, "Simulate the cluster upgrade without making changes")
-
-
-	if err := upgradeCmd.MarkFlagRequired("config"); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for upgrade command: %v\n", err)
-	}
-	if err := upgradeCmd.MarkFlagRequired("version"); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to mark 'version' flag as required for upgrade command: %v\n", err)
-	}
+// AddUpgradeFlags declares the upgrade flags once, as persistent flags of the upgrade command group,
+// so that both "kubexm upgrade" and "kubexm upgrade cluster" accept them. --config is not marked
+// required, which would also reject a bare "kubexm upgrade"; RunE checks it instead.
+func AddUpgradeFlags(group *cobra.Command) {
+	flags := group.PersistentFlags()
+	flags.VarP(config.NewPathsValue(&upgradeOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration YAML file (required for context, may not be modified)")
+	flags.StringVarP(&upgradeOptions.TargetVersion, "to-version", "t", "", "Target Kubernetes version for the upgrade (e.g., v1.24.3) (required)")
+	flags.StringVar(&upgradeOptions.TargetVersion, "version", "", "Target Kubernetes version for the upgrade")
+	flags.IntVar(&upgradeOptions.WorkerBatchSize, "worker-batch-size", 1, "Number of worker nodes to drain and upgrade at the same time")
+	flags.BoolVar(&upgradeOptions.DryRun, "dry-run", false, "Simulate the cluster upgrade without making changes")
+	_ = flags.MarkDeprecated("version", "use --to-version instead")
 }
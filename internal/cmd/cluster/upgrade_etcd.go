@@ -27,7 +27,7 @@ var upgradeEtcdOptions = &UpgradeEtcdOptions{}
 
 func init() {
 	ClusterCmd.AddCommand(upgradeEtcdCmd)
-	upgradeEtcdCmd.Flags().StringVarP(&upgradeEtcdOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	upgradeEtcdCmd.Flags().VarP(config.NewPathsValue(&upgradeEtcdOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	upgradeEtcdCmd.Flags().StringVarP(&upgradeEtcdOptions.TargetVersion, "to-version", "t", "", "Target etcd version for the upgrade (e.g., v3.5.9) (required)")
 	upgradeEtcdCmd.Flags().BoolVar(&upgradeEtcdOptions.DryRun, "dry-run", false, "Simulate the etcd upgrade without making changes")
 
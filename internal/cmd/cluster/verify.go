@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+type VerifyOptions struct {
+	ClusterConfigFile string
+	Timeout           time.Duration
+	ReportFile        string
+	ReportFormat      string
+}
+
+var verifyOptions = &VerifyOptions{}
+
+func init() {
+	VerifyCmd.Flags().VarP(config.NewPathsValue(&verifyOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	VerifyCmd.Flags().DurationVar(&verifyOptions.Timeout, "timeout", 15*time.Minute, "Timeout for the whole verification")
+	VerifyCmd.Flags().StringVar(&verifyOptions.ReportFile, "report-file", "", "Write a report of the checks (status, durations and per-host results) to this path")
+	VerifyCmd.Flags().StringVarP(&verifyOptions.ReportFormat, "output", "o", plan.ReportFormatJSON, "Format of the --report-file report: json, junit (one test case per node and host) or sarif (failures only)")
+
+	if err := VerifyCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for verify command: %v\n", err)
+	}
+}
+
+// VerifyCmd runs post-install checks against a running cluster and prints one row per check.
+var VerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that an installed cluster works end to end",
+	Long: `Run post-install checks from the first control plane node and print a table of the results.
+
+The following are checked:
+  - every master and worker is registered and Ready
+  - CoreDNS resolves Services from inside a pod
+  - a pod on every node reaches a Service and the pod on another node
+  - the default StorageClass binds a PersistentVolumeClaim (skipped without one)
+  - the API server answers /readyz with an acceptable latency
+
+Checks run independently, so a failing check does not hide the others. Test workloads are
+created in the kubexm-verify namespaces and removed afterwards.
+
+Examples:
+  # Verify a cluster
+  kubexm verify -f config.yaml
+
+  # Keep a JSON report for CI
+  kubexm verify -f config.yaml --report-file verify.json
+
+  # Publish the checks as JUnit test results
+  kubexm verify -f config.yaml --report-file verify.xml -o junit`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		if verifyOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+		if err := validateReportFormat(verifyOptions.ReportFormat); err != nil {
+			return err
+		}
+
+		absPath, err := filepath.Abs(verifyOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		log.Infof("Verifying cluster '%s'", clusterConfig.Name)
+
+		goCtx, cancel := context.WithTimeout(context.Background(), verifyOptions.Timeout)
+		defer cancel()
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipHostConnect(true).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewVerifyClusterPipeline()
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("verify pipeline planning failed: %w", err)
+		}
+
+		result, runErr := p.Run(runtimeCtx, graph, false)
+		writeRunReport(log, verifyOptions.ReportFile, verifyOptions.ReportFormat, result)
+		if result != nil {
+			report := plan.NewRunReport(result)
+			report.WriteTable(cmd.OutOrStdout())
+			fmt.Fprint(cmd.OutOrStdout(), report.SummaryText)
+		}
+		if runErr != nil {
+			if result != nil && result.Status == plan.StatusFailed {
+				return fmt.Errorf("cluster verification failed: %s", result.Message)
+			}
+			return fmt.Errorf("verify pipeline execution failed: %w", runErr)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("cluster verification failed: %s", result.Message)
+		}
+		return nil
+	},
+}
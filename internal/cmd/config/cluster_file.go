@@ -0,0 +1,227 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/scheme"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/validation"
+	clusterconfig "github.com/mensylisir/kubexm/internal/config"
+)
+
+// loadClusterFile reads the cluster configuration at path as written, without defaults or
+// validation. Overlays in path are merged as clusterconfig.ReadMerged does; secret references are
+// shown by their source rather than resolved.
+func loadClusterFile(path string) (*v1alpha1.Cluster, error) {
+	data, err := clusterconfig.ReadMerged(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = clusterconfig.RedactReferences(data); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster configuration %s: %w", path, err)
+	}
+	cluster, err := scheme.Decode(data, func(data []byte, v interface{}) error { return sigsyaml.Unmarshal(data, v) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cluster configuration %s: %w", path, err)
+	}
+	return cluster, nil
+}
+
+// validateClusterFile parses the cluster configuration at path the way `kubexm create cluster`
+// does and runs the semantic validation on the result.
+func validateClusterFile(path string) error {
+	cluster, err := clusterconfig.ParseFromFile(path)
+	if err != nil {
+		return err
+	}
+	return validation.Validate(cluster)
+}
+
+// pathSegment is one dot-separated element of a field path such as "spec.hosts[master-1].address".
+// A bracketed selector picks a list item either by index or by the value of its "name" field.
+type pathSegment struct {
+	key      string
+	selector string
+	hasItem  bool
+}
+
+// parseFieldPath splits path into its segments.
+func parseFieldPath(path string) ([]pathSegment, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("field path cannot be empty")
+	}
+	var segments []pathSegment
+	for _, part := range splitFieldPath(path) {
+		seg := pathSegment{key: part}
+		if open := strings.Index(part, "["); open >= 0 {
+			if !strings.HasSuffix(part, "]") || open == len(part)-2 {
+				return nil, fmt.Errorf("invalid field path '%s': malformed list selector in '%s'", path, part)
+			}
+			seg.key, seg.selector, seg.hasItem = part[:open], part[open+1:len(part)-1], true
+		}
+		if seg.key == "" {
+			return nil, fmt.Errorf("invalid field path '%s': empty field name", path)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// splitFieldPath splits path at the dots outside of list selectors, so that names such as
+// "node-1.example.com" can be selected.
+func splitFieldPath(path string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range path {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// setFieldValue sets the field at path of the YAML document doc to value, which is parsed as YAML
+// so that numbers, booleans and flow lists keep their type. Missing mappings are created on the
+// way. List items are selected by index, where the index one past the end appends an item, or by
+// name, where an unknown name appends an item with that name, as a strategic merge patch does.
+// Comments of the replaced node are kept.
+func setFieldValue(doc *yaml.Node, path, value string) error {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if doc.Kind != yaml.DocumentNode {
+		return fmt.Errorf("expected a YAML document")
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	node := doc.Content[0]
+	parts := splitFieldPath(path)
+	for i, seg := range segments {
+		at := strings.Join(append(parts[:i:i], seg.key), ".")
+		if node, err = mappingValue(node, seg.key, at); err != nil {
+			return err
+		}
+		if seg.hasItem {
+			if node, err = sequenceItem(node, seg.selector, at); err != nil {
+				return err
+			}
+		}
+	}
+
+	newValue, err := parseValue(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for '%s': %w", path, err)
+	}
+	newValue.HeadComment, newValue.LineComment, newValue.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = *newValue
+	return nil
+}
+
+// isEmpty reports whether node holds no value yet, so it may be turned into a mapping or list.
+func isEmpty(node *yaml.Node) bool {
+	return node.Kind == 0 || (node.Kind == yaml.ScalarNode && (node.Tag == "!!null" || node.Value == ""))
+}
+
+// mappingValue returns the value of key in the mapping node, adding the key when it is missing.
+func mappingValue(node *yaml.Node, key, at string) (*yaml.Node, error) {
+	if isEmpty(node) {
+		*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: node.HeadComment, LineComment: node.LineComment}
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cannot set '%s': parent is not a mapping", at)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1], nil
+		}
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value, nil
+}
+
+// sequenceItem returns the item of the list node chosen by selector, appending one when the
+// selector is the next index or a name no item has.
+func sequenceItem(node *yaml.Node, selector, at string) (*yaml.Node, error) {
+	if isEmpty(node) {
+		*node = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", HeadComment: node.HeadComment, LineComment: node.LineComment}
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("cannot select '%s[%s]': field is not a list", at, selector)
+	}
+	if index, err := strconv.Atoi(selector); err == nil {
+		switch {
+		case index >= 0 && index < len(node.Content):
+			return node.Content[index], nil
+		case index == len(node.Content):
+			item := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			node.Content = append(node.Content, item)
+			return item, nil
+		default:
+			return nil, fmt.Errorf("cannot select '%s[%d]': the list has %d item(s)", at, index, len(node.Content))
+		}
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Value == selector {
+				return item, nil
+			}
+		}
+	}
+	item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name"},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: selector},
+	}}
+	node.Content = append(node.Content, item)
+	return item, nil
+}
+
+// parseValue parses value as a YAML node. An empty value is the empty string.
+func parseValue(value string) (*yaml.Node, error) {
+	if value == "" {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "", Style: yaml.DoubleQuotedStyle}, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 {
+		return nil, fmt.Errorf("expected a single YAML value, got '%s'", value)
+	}
+	node := doc.Content[0]
+	blockStyle(node)
+	return node, nil
+}
+
+// blockStyle renders the lists and mappings of a value given in flow style, such as [a,b], in the
+// block style of the rest of the file.
+func blockStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/validation"
+)
+
+const testClusterYAML = `apiVersion: kubexm.io/v1alpha1
+kind: Cluster
+spec:
+  hosts:
+    # the only master
+    - name: master-1
+      address: 192.168.10.11
+  kubernetes:
+    version: v1.28.5 # pinned
+`
+
+func patch(t *testing.T, assignments ...string) (string, error) {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(testClusterYAML), &doc); err != nil {
+		t.Fatalf("failed to parse test YAML: %v", err)
+	}
+	for _, assignment := range assignments {
+		field, value, _ := strings.Cut(assignment, "=")
+		if err := setFieldValue(&doc, field, value); err != nil {
+			return "", err
+		}
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatalf("failed to encode patched YAML: %v", err)
+	}
+	return string(out), nil
+}
+
+func TestSetFieldValue(t *testing.T) {
+	out, err := patch(t,
+		"spec.kubernetes.version=v1.29.4",
+		"spec.hosts[master-1].port=2222",
+		"spec.hosts[node-1.example.com].address=192.168.10.21",
+		"spec.hosts[0].labels.zone=a",
+		"spec.network.plugin=cilium",
+		"spec.roleGroups.master=[master-1]",
+	)
+	if err != nil {
+		t.Fatalf("setFieldValue() error = %v", err)
+	}
+
+	var got struct {
+		Spec struct {
+			Hosts []struct {
+				Name    string
+				Address string
+				Port    int
+				Labels  map[string]string
+			}
+			Kubernetes struct{ Version string }
+			Network    struct{ Plugin string }
+			RoleGroups struct{ Master []string } `yaml:"roleGroups"`
+		}
+	}
+	if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("patched YAML does not parse: %v\n%s", err, out)
+	}
+	spec := got.Spec
+	if spec.Kubernetes.Version != "v1.29.4" || spec.Network.Plugin != "cilium" {
+		t.Errorf("scalar fields not set:\n%s", out)
+	}
+	if len(spec.Hosts) != 2 || spec.Hosts[0].Port != 2222 || spec.Hosts[0].Labels["zone"] != "a" {
+		t.Errorf("master-1 not patched in place:\n%s", out)
+	}
+	if len(spec.Hosts) == 2 && (spec.Hosts[1].Name != "node-1.example.com" || spec.Hosts[1].Address != "192.168.10.21") {
+		t.Errorf("unknown host name should append a host:\n%s", out)
+	}
+	if len(spec.RoleGroups.Master) != 1 || strings.Contains(out, "[master-1]") {
+		t.Errorf("list value should be set in block style:\n%s", out)
+	}
+	for _, comment := range []string{"# the only master", "# pinned"} {
+		if !strings.Contains(out, comment) {
+			t.Errorf("comment %q was lost:\n%s", comment, out)
+		}
+	}
+}
+
+func TestSetFieldValue_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		assignment string
+		want       string
+	}{
+		{"spec.hosts[5].address=10.0.0.1", "the list has 1 item(s)"},
+		{"spec.kubernetes.version.major=1", "parent is not a mapping"},
+		{"spec.kubernetes[0]=x", "field is not a list"},
+		{"spec..version=x", "empty field name"},
+		{"spec.hosts[.address=x", "malformed list selector"},
+	} {
+		if _, err := patch(t, tc.assignment); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error = %v, want it to contain %q", tc.assignment, err, tc.want)
+		}
+	}
+}
+
+func TestGenerateCluster(t *testing.T) {
+	opts := &GenerateOptions{
+		Name:              "demo",
+		KubernetesVersion: "v1.28.5",
+		Masters:           []string{"192.168.10.11"},
+		Workers:           []string{"192.168.10.21", "edge=192.168.10.22"},
+		User:              "root",
+		Password:          "secret",
+		ContainerRuntime:  "containerd",
+		NetworkPlugin:     "calico",
+		KubePodsCIDR:      "10.244.0.0/16",
+		KubeServiceCIDR:   "10.96.0.0/12",
+	}
+	cluster, err := generateCluster(opts)
+	if err != nil {
+		t.Fatalf("generateCluster() error = %v", err)
+	}
+	var names []string
+	for _, host := range cluster.Spec.Hosts {
+		names = append(names, host.Name)
+	}
+	if got := strings.Join(names, ","); got != "master-1,worker-1,edge" {
+		t.Errorf("host names = %s, want master-1,worker-1,edge", got)
+	}
+	if err := validation.DefaultAndValidate(cluster); err != nil {
+		t.Errorf("generated cluster does not validate: %v", err)
+	}
+
+	opts.Masters = nil
+	if _, err := generateCluster(opts); err == nil {
+		t.Error("expected an error without master hosts")
+	}
+	opts.Masters = []string{"a=10.0.0.1", "a=10.0.0.2"}
+	if _, err := generateCluster(opts); err == nil {
+		t.Error("expected an error for a duplicate host name")
+	}
+}
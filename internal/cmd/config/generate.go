@@ -0,0 +1,240 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/validation"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+type GenerateOptions struct {
+	Name              string
+	KubernetesVersion string
+	Masters           []string
+	Workers           []string
+	User              string
+	Password          string
+	PrivateKeyPath    string
+	ContainerRuntime  string
+	NetworkPlugin     string
+	KubePodsCIDR      string
+	KubeServiceCIDR   string
+	OutputFile        string
+	Interactive       bool
+	Force             bool
+}
+
+var generateOptions = &GenerateOptions{}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a skeleton cluster configuration file",
+	Long: `Generate a skeleton cluster configuration from flags or, with --interactive,
+from answers to a few questions. Hosts are given as <address> or <name>=<address>;
+unnamed hosts are called master-1, worker-1 and so on. The masters also run etcd.
+
+Only the settings asked for are written; everything else keeps its default, which
+'kubexm config view -f <file> --defaults' shows. The result is validated and
+problems are reported, so they can be fixed before the first run.
+
+Examples:
+  # Write a single-master cluster with two workers
+  kubexm config generate --name demo --master 192.168.10.11 \
+    --worker 192.168.10.21 --worker 192.168.10.22 \
+    --private-key-path ~/.ssh/id_rsa -o cluster.yaml
+
+  # Answer questions instead of passing flags
+  kubexm config generate -i -o cluster.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if generateOptions.Interactive {
+			if err := promptGenerateOptions(bufio.NewReader(os.Stdin), os.Stdout, generateOptions); err != nil {
+				return err
+			}
+		}
+		cluster, err := generateCluster(generateOptions)
+		if err != nil {
+			return err
+		}
+		data, err := sigsyaml.Marshal(cluster)
+		if err != nil {
+			return fmt.Errorf("failed to encode cluster configuration: %w", err)
+		}
+
+		if generateOptions.OutputFile == "" {
+			if _, err := os.Stdout.Write(data); err != nil {
+				return err
+			}
+		} else {
+			if _, err := os.Stat(generateOptions.OutputFile); err == nil && !generateOptions.Force {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", generateOptions.OutputFile)
+			}
+			if err := os.WriteFile(generateOptions.OutputFile, data, 0600); err != nil {
+				return fmt.Errorf("failed to write cluster configuration %s: %w", generateOptions.OutputFile, err)
+			}
+			fmt.Printf("Cluster configuration written to %s\n", generateOptions.OutputFile)
+		}
+
+		// Validate a copy: the defaults are applied for the check only and are not written.
+		check := &v1alpha1.Cluster{}
+		if err := sigsyaml.Unmarshal(data, check); err != nil {
+			return fmt.Errorf("failed to decode generated cluster configuration: %w", err)
+		}
+		if err := validation.DefaultAndValidate(check); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: the generated configuration does not pass validation yet:\n%v\n", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(generateCmd)
+	flags := generateCmd.Flags()
+	flags.StringVar(&generateOptions.Name, "name", "kubexm-cluster", "Name of the cluster")
+	flags.StringVar(&generateOptions.KubernetesVersion, "kubernetes-version", common.DefaultK8sVersion, "Kubernetes version")
+	flags.StringArrayVar(&generateOptions.Masters, "master", nil, "Master host as <address> or <name>=<address> (repeatable)")
+	flags.StringArrayVar(&generateOptions.Workers, "worker", nil, "Worker host as <address> or <name>=<address> (repeatable)")
+	flags.StringVar(&generateOptions.User, "user", common.DefaultUser, "SSH user of all hosts")
+	flags.StringVar(&generateOptions.Password, "password", "", "SSH password of all hosts")
+	flags.StringVar(&generateOptions.PrivateKeyPath, "private-key-path", "", "SSH private key of all hosts")
+	flags.StringVar(&generateOptions.ContainerRuntime, "container-runtime", string(common.RuntimeTypeContainerd), "Container runtime")
+	flags.StringVar(&generateOptions.NetworkPlugin, "network-plugin", string(common.CNITypeCalico), "Network plugin")
+	flags.StringVar(&generateOptions.KubePodsCIDR, "pod-cidr", common.DefaultKubePodsCIDR, "Pod network CIDR")
+	flags.StringVar(&generateOptions.KubeServiceCIDR, "service-cidr", common.DefaultKubeServiceCIDR, "Service network CIDR")
+	flags.StringVarP(&generateOptions.OutputFile, "output", "o", "", "File to write the configuration to (default stdout)")
+	flags.BoolVarP(&generateOptions.Interactive, "interactive", "i", false, "Ask for the settings instead of taking them from flags")
+	flags.BoolVar(&generateOptions.Force, "force", false, "Overwrite the output file if it exists")
+}
+
+// promptGenerateOptions asks for each setting on out, offering the current value of opts as the
+// default, and reads the answers from in.
+func promptGenerateOptions(in *bufio.Reader, out io.Writer, opts *GenerateOptions) error {
+	ask := func(question string, value *string) error {
+		if *value != "" {
+			fmt.Fprintf(out, "%s [%s]: ", question, *value)
+		} else {
+			fmt.Fprintf(out, "%s: ", question)
+		}
+		answer, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read answer: %w", err)
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			*value = answer
+		}
+		return nil
+	}
+	askList := func(question string, values *[]string) error {
+		answer := strings.Join(*values, ",")
+		if err := ask(question+" (comma separated)", &answer); err != nil {
+			return err
+		}
+		*values = nil
+		for _, item := range strings.Split(answer, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*values = append(*values, item)
+			}
+		}
+		return nil
+	}
+
+	steps := []func() error{
+		func() error { return ask("Cluster name", &opts.Name) },
+		func() error { return ask("Kubernetes version", &opts.KubernetesVersion) },
+		func() error { return askList("Master hosts", &opts.Masters) },
+		func() error { return askList("Worker hosts", &opts.Workers) },
+		func() error { return ask("SSH user", &opts.User) },
+		func() error { return ask("SSH private key path (empty to use a password)", &opts.PrivateKeyPath) },
+		func() error {
+			if opts.PrivateKeyPath != "" {
+				return nil
+			}
+			return ask("SSH password", &opts.Password)
+		},
+		func() error { return ask("Container runtime", &opts.ContainerRuntime) },
+		func() error { return ask("Network plugin", &opts.NetworkPlugin) },
+		func() error { return ask("Pod network CIDR", &opts.KubePodsCIDR) },
+		func() error { return ask("Service network CIDR", &opts.KubeServiceCIDR) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateCluster builds the skeleton cluster described by opts.
+func generateCluster(opts *GenerateOptions) (*v1alpha1.Cluster, error) {
+	if len(opts.Masters) == 0 {
+		return nil, fmt.Errorf("at least one master host is required, use --master or --interactive")
+	}
+	spec := &v1alpha1.ClusterSpec{
+		RoleGroups: &v1alpha1.RoleGroupsSpec{},
+		Global: &v1alpha1.GlobalSpec{
+			User:           opts.User,
+			Password:       opts.Password,
+			PrivateKeyPath: opts.PrivateKeyPath,
+		},
+		Kubernetes: &v1alpha1.Kubernetes{
+			Version:     opts.KubernetesVersion,
+			ClusterName: common.DefaultClusterLocal,
+			ContainerRuntime: &v1alpha1.ContainerRuntime{
+				Type: common.ContainerRuntimeType(opts.ContainerRuntime),
+			},
+		},
+		Etcd: &v1alpha1.Etcd{Type: string(common.EtcdDeploymentTypeKubexm)},
+		Network: &v1alpha1.Network{
+			Plugin:          opts.NetworkPlugin,
+			KubePodsCIDR:    opts.KubePodsCIDR,
+			KubeServiceCIDR: opts.KubeServiceCIDR,
+		},
+	}
+
+	names := make(map[string]bool)
+	addHosts := func(role string, hosts []string) ([]string, error) {
+		var roleNames []string
+		for i, host := range hosts {
+			name, address, ok := strings.Cut(host, "=")
+			if !ok {
+				name, address = fmt.Sprintf("%s-%d", role, i+1), host
+			}
+			name, address = strings.TrimSpace(name), strings.TrimSpace(address)
+			if name == "" || address == "" {
+				return nil, fmt.Errorf("invalid %s host '%s', expected <address> or <name>=<address>", role, host)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("host name '%s' is used twice", name)
+			}
+			names[name] = true
+			spec.Hosts = append(spec.Hosts, v1alpha1.HostSpec{Name: name, Address: address})
+			roleNames = append(roleNames, name)
+		}
+		return roleNames, nil
+	}
+	var err error
+	if spec.RoleGroups.Master, err = addHosts("master", opts.Masters); err != nil {
+		return nil, err
+	}
+	if spec.RoleGroups.Worker, err = addHosts("worker", opts.Workers); err != nil {
+		return nil, err
+	}
+	spec.RoleGroups.Etcd = append([]string(nil), spec.RoleGroups.Master...)
+
+	return &v1alpha1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: common.DefaultAPIVersion,
+			Kind:       common.DefaultKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+		Spec:       spec,
+	}, nil
+}
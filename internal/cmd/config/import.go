@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+type ImportOptions struct {
+	FromAnsible       string
+	FromTerraform     string
+	FromSSHConfig     string
+	GroupRoles        []string
+	TerraformMappings []string
+	OutputFile        string
+	Force             bool
+}
+
+var importOptions = &ImportOptions{}
+
+var importCmd = &cobra.Command{
+	Use:   "import [host...]",
+	Short: "Import the hosts of an Ansible inventory, a Terraform state or an SSH config",
+	Long: `Produce the hosts section of a cluster configuration from an existing inventory.
+
+--from-ansible reads an Ansible inventory in INI format. Host addresses, ports,
+users, passwords and keys come from ansible_host, ansible_port, ansible_user,
+ansible_password and ansible_ssh_private_key_file, set on the host line or in
+[group:vars]; ip becomes the internal address. Groups, including those reached
+through [group:children], become roles: kube_control_plane, masters and the like
+map to master, kube_node and workers to worker, etcd to etcd; --group-role adds or
+overrides a mapping.
+
+--from-terraform reads a Terraform state file or the output of 'terraform output
+-json'. Every instance of aws_instance, openstack_compute_instance_v2,
+proxmox_vm_qemu and proxmox_virtual_environment_vm in a state becomes a host of
+the group named after the resource. --terraform-mapping changes where a field
+(name, address, internalAddress, user, port or group) is read from, or adds a
+resource type, e.g. aws_instance.address=private_ip or
+hcloud_server.address=ipv4_address. In outputs, the output name is the group and
+its value an address, a list of addresses or objects, or a map of host names to
+those; objects are read with the "output" mapping (address, name, user, role...).
+
+--from-ssh-config reads an OpenSSH client configuration. Alone, it imports the
+given host aliases, or every alias that is not a pattern, without roles. With
+another source, it resolves the hosts the way ssh would: HostName, User, Port,
+IdentityFile and a single-hop ProxyJump, which becomes the bastion.
+
+The result is a cluster configuration overlay holding spec.hosts and
+spec.roleGroups, to be merged onto a base with 'kubexm create cluster -f base.yaml
+-f hosts.yaml' or copied into cluster.yaml.
+
+Examples:
+  # Import a kubespray inventory
+  kubexm config import --from-ansible inventory/mycluster/hosts.ini -o hosts.yaml
+
+  # Map a custom group and resolve connection details through ~/.ssh/config
+  kubexm config import --from-ansible hosts.ini --group-role gpu=worker \
+    --from-ssh-config ~/.ssh/config
+
+  # Chain provisioning and installation
+  terraform output -json | kubexm config import --from-terraform - \
+    --group-role control_plane_ips=master --group-role worker_ips=worker -o hosts.yaml
+
+  # Import two hosts from ~/.ssh/config
+  kubexm config import --from-ssh-config ~/.ssh/config node-a node-b`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hosts, err := importHosts(importOptions, args)
+		if err != nil {
+			return err
+		}
+		overlay := map[string]interface{}{"spec": map[string]interface{}{"hosts": hosts.Hosts}}
+		if hosts.RoleGroups != nil {
+			overlay["spec"].(map[string]interface{})["roleGroups"] = hosts.RoleGroups
+		}
+		data, err := sigsyaml.Marshal(overlay)
+		if err != nil {
+			return fmt.Errorf("failed to encode hosts: %w", err)
+		}
+
+		if importOptions.OutputFile == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if _, err := os.Stat(importOptions.OutputFile); err == nil && !importOptions.Force {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", importOptions.OutputFile)
+		}
+		if err := os.WriteFile(importOptions.OutputFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write hosts %s: %w", importOptions.OutputFile, err)
+		}
+		fmt.Printf("%d hosts written to %s\n", len(hosts.Hosts), importOptions.OutputFile)
+		return nil
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(importCmd)
+	flags := importCmd.Flags()
+	flags.StringVar(&importOptions.FromAnsible, "from-ansible", "", "Ansible inventory file in INI format")
+	flags.StringVar(&importOptions.FromTerraform, "from-terraform", "", "Terraform state file or saved 'terraform output -json', - for stdin")
+	flags.StringVar(&importOptions.FromSSHConfig, "from-ssh-config", "", "OpenSSH client configuration file, such as ~/.ssh/config")
+	flags.StringArrayVar(&importOptions.GroupRoles, "group-role", nil, "Map an Ansible group or a Terraform resource or output name to a role as <group>=<role> (repeatable)")
+	flags.StringArrayVar(&importOptions.TerraformMappings, "terraform-mapping", nil, "Map a host field to resource attributes as <type>.<field>=<path>[|<path>...] (repeatable)")
+	flags.StringVarP(&importOptions.OutputFile, "output", "o", "", "File to write the hosts to (default stdout)")
+	flags.BoolVar(&importOptions.Force, "force", false, "Overwrite the output file if it exists")
+}
+
+// importHosts reads the sources of opts. names selects the SSH config aliases to import when no
+// inventory or Terraform state is given.
+func importHosts(opts *ImportOptions, names []string) (*importedHosts, error) {
+	if opts.FromAnsible != "" && opts.FromTerraform != "" {
+		return nil, fmt.Errorf("--from-ansible and --from-terraform cannot be used together")
+	}
+	if opts.FromAnsible == "" && opts.FromTerraform == "" && opts.FromSSHConfig == "" {
+		return nil, fmt.Errorf("one of --from-ansible, --from-terraform or --from-ssh-config is required")
+	}
+	if (opts.FromAnsible != "" || opts.FromTerraform != "") && len(names) > 0 {
+		return nil, fmt.Errorf("host arguments select SSH config aliases and cannot be used with --from-ansible or --from-terraform")
+	}
+
+	var sshCfg *sshConfig
+	if opts.FromSSHConfig != "" {
+		f, err := os.Open(expandHome(opts.FromSSHConfig))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH config: %w", err)
+		}
+		defer f.Close()
+		if sshCfg, err = parseSSHConfig(f); err != nil {
+			return nil, fmt.Errorf("failed to parse SSH config %s: %w", opts.FromSSHConfig, err)
+		}
+	}
+	if opts.FromAnsible == "" && opts.FromTerraform == "" {
+		return hostsFromSSHConfig(sshCfg, names)
+	}
+
+	groupRoles := make(map[string]string, len(defaultGroupRoles)+len(opts.GroupRoles))
+	for group, role := range defaultGroupRoles {
+		groupRoles[group] = role
+	}
+	for _, mapping := range opts.GroupRoles {
+		group, role, ok := strings.Cut(mapping, "=")
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid --group-role '%s', expected <group>=<role>", mapping)
+		}
+		groupRoles[group] = role
+	}
+
+	if opts.FromTerraform != "" {
+		return importTerraform(opts, groupRoles, sshCfg)
+	}
+	f, err := os.Open(opts.FromAnsible)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Ansible inventory: %w", err)
+	}
+	defer f.Close()
+	inv, err := parseAnsibleInventory(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Ansible inventory %s: %w", opts.FromAnsible, err)
+	}
+	return hostsFromAnsible(inv, groupRoles, sshCfg)
+}
+
+// importTerraform reads the Terraform state or outputs of opts, from stdin for "-".
+func importTerraform(opts *ImportOptions, groupRoles map[string]string, sshCfg *sshConfig) (*importedHosts, error) {
+	mappings, err := terraformMappings(opts.TerraformMappings)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if opts.FromTerraform == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(opts.FromTerraform)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Terraform state: %w", err)
+	}
+	hosts, err := hostsFromTerraform(data, mappings, groupRoles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import hosts from Terraform %s: %w", opts.FromTerraform, err)
+	}
+	if sshCfg != nil {
+		for i := range hosts.Hosts {
+			if err := applySSHConfig(&hosts.Hosts[i], hosts.Hosts[i].Address, sshCfg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,583 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// defaultGroupRoles maps the usual Ansible group names, including those of kubespray, to kubexm
+// roles. Hosts of other groups get no role unless --group-role maps them.
+var defaultGroupRoles = map[string]string{
+	"kube_control_plane": common.RoleMaster,
+	"kube-master":        common.RoleMaster,
+	"kube_master":        common.RoleMaster,
+	"control_plane":      common.RoleMaster,
+	"masters":            common.RoleMaster,
+	"master":             common.RoleMaster,
+	"kube_node":          common.RoleWorker,
+	"kube-node":          common.RoleWorker,
+	"nodes":              common.RoleWorker,
+	"workers":            common.RoleWorker,
+	"worker":             common.RoleWorker,
+	"etcd":               common.RoleEtcd,
+	"loadbalancer":       common.RoleLoadBalancer,
+	"lb":                 common.RoleLoadBalancer,
+	"haproxy":            common.RoleLoadBalancer,
+	"registry":           common.RoleRegistry,
+	"storage":            common.RoleStorage,
+}
+
+// inventoryHost is a host read from an inventory, before it is turned into a HostSpec.
+type inventoryHost struct {
+	name   string
+	vars   map[string]string
+	groups map[string]bool
+}
+
+// ansibleInventory is a parsed Ansible INI inventory.
+type ansibleInventory struct {
+	hosts    []*inventoryHost
+	byName   map[string]*inventoryHost
+	children map[string][]string
+	vars     map[string]map[string]string
+	groups   []string
+}
+
+// parseAnsibleInventory reads an Ansible inventory in INI format: [group] sections listing hosts
+// with their inline variables, [group:vars] sections and [group:children] sections. Host ranges
+// such as node[01:03] are expanded.
+func parseAnsibleInventory(r io.Reader) (*ansibleInventory, error) {
+	inv := &ansibleInventory{
+		byName:   make(map[string]*inventoryHost),
+		children: make(map[string][]string),
+		vars:     make(map[string]map[string]string),
+	}
+	addGroup := func(group string) {
+		if _, ok := inv.vars[group]; !ok {
+			inv.vars[group] = make(map[string]string)
+			inv.groups = append(inv.groups, group)
+		}
+	}
+	group, kind := "ungrouped", "hosts"
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group, kind = line[1:len(line)-1], "hosts"
+			if name, suffix, ok := strings.Cut(group, ":"); ok {
+				group, kind = name, suffix
+			}
+			if kind != "hosts" && kind != "vars" && kind != "children" {
+				return nil, fmt.Errorf("line %d: unknown section type '%s'", lineNo, kind)
+			}
+			addGroup(group)
+			continue
+		}
+		fields, err := splitInventoryLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		switch kind {
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected <key>=<value> in [%s:vars]", lineNo, group)
+			}
+			inv.vars[group][strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		case "children":
+			addGroup(fields[0])
+			inv.children[group] = append(inv.children[group], fields[0])
+		default:
+			addGroup(group)
+			vars := make(map[string]string)
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected <key>=<value> after host %s, got '%s'", lineNo, fields[0], field)
+				}
+				vars[key] = value
+			}
+			names, err := helpers.ExpandHostRange(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			for _, name := range names {
+				host, ok := inv.byName[name]
+				if !ok {
+					host = &inventoryHost{name: name, vars: make(map[string]string), groups: make(map[string]bool)}
+					inv.byName[name] = host
+					inv.hosts = append(inv.hosts, host)
+				}
+				host.groups[group] = true
+				for k, v := range vars {
+					host.vars[k] = v
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// splitInventoryLine splits a host or group line into words the way Ansible does with shlex:
+// words are separated by whitespace, quotes group words and are removed, and a backslash escapes
+// the next character outside single quotes. ansible_ssh_common_args="-o X" is a single word.
+func splitInventoryLine(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			// Inside double quotes only a quote or a backslash can be escaped.
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// memberOf returns every group host belongs to, directly or through [group:children], with the
+// depth of the group below "all".
+func (inv *ansibleInventory) memberOf(host *inventoryHost) map[string]int {
+	parents := make(map[string][]string)
+	for parent, children := range inv.children {
+		for _, child := range children {
+			parents[child] = append(parents[child], parent)
+		}
+	}
+	depth := func(group string) int {
+		d, seen := 1, map[string]bool{group: true}
+		for current := []string{group}; ; d++ {
+			var next []string
+			for _, g := range current {
+				for _, p := range parents[g] {
+					if !seen[p] {
+						seen[p] = true
+						next = append(next, p)
+					}
+				}
+			}
+			if len(next) == 0 {
+				return d
+			}
+			current = next
+		}
+	}
+	groups := map[string]int{"all": 0}
+	var visit func(group string)
+	visit = func(group string) {
+		if _, ok := groups[group]; ok {
+			return
+		}
+		groups[group] = depth(group)
+		for _, parent := range parents[group] {
+			visit(parent)
+		}
+	}
+	for group := range host.groups {
+		visit(group)
+	}
+	return groups
+}
+
+// hostVars returns the variables of host the way Ansible resolves them: [all:vars] first, then
+// the groups by depth and name, so that child groups override their parents, then the host line.
+func (inv *ansibleInventory) hostVars(host *inventoryHost, groups map[string]int) map[string]string {
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if groups[names[i]] != groups[names[j]] {
+			return groups[names[i]] < groups[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	vars := make(map[string]string)
+	for _, group := range names {
+		for k, v := range inv.vars[group] {
+			vars[k] = v
+		}
+	}
+	for k, v := range host.vars {
+		vars[k] = v
+	}
+	return vars
+}
+
+// firstVar returns the first of the keys set in vars.
+func firstVar(vars map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := vars[key]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// sshHostConfig is the SSH client configuration that applies to one host alias.
+type sshHostConfig struct {
+	HostName     string
+	User         string
+	Port         int
+	IdentityFile string
+	ProxyJump    string
+}
+
+type sshConfigBlock struct {
+	patterns []string
+	options  [][2]string
+}
+
+// sshConfig is a parsed OpenSSH client configuration.
+type sshConfig struct {
+	blocks []sshConfigBlock
+}
+
+// parseSSHConfig reads an OpenSSH client configuration. Match blocks and Include are skipped.
+func parseSSHConfig(r io.Reader) (*sshConfig, error) {
+	cfg := &sshConfig{blocks: []sshConfigBlock{{patterns: []string{"*"}}}}
+	current := &cfg.blocks[0]
+	skipping := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.Replace(line, "=", " ", 1), " ")
+		key, value = strings.ToLower(key), unquote(strings.TrimSpace(value))
+		switch key {
+		case "host":
+			cfg.blocks = append(cfg.blocks, sshConfigBlock{patterns: strings.Fields(value)})
+			current, skipping = &cfg.blocks[len(cfg.blocks)-1], false
+		case "match":
+			skipping = true
+		case "include":
+		default:
+			if !skipping {
+				current.options = append(current.options, [2]string{key, value})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// matches reports whether alias matches the patterns of a Host line, honouring negated patterns.
+func (b sshConfigBlock) matches(alias string) bool {
+	matched := false
+	for _, pattern := range b.patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		if ok, _ := path.Match(strings.TrimPrefix(pattern, "!"), alias); ok {
+			if negated {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+// aliases returns the host aliases of the configuration that are not patterns.
+func (c *sshConfig) aliases() []string {
+	var aliases []string
+	seen := make(map[string]bool)
+	for _, block := range c.blocks[1:] {
+		for _, pattern := range block.patterns {
+			if !strings.ContainsAny(pattern, "*?!") && !seen[pattern] {
+				seen[pattern] = true
+				aliases = append(aliases, pattern)
+			}
+		}
+	}
+	return aliases
+}
+
+// lookup resolves the configuration of alias as ssh does: the first value found for an option wins.
+func (c *sshConfig) lookup(alias string) (sshHostConfig, error) {
+	var host sshHostConfig
+	seen := make(map[string]bool)
+	for _, block := range c.blocks {
+		if !block.matches(alias) {
+			continue
+		}
+		for _, option := range block.options {
+			key, value := option[0], option[1]
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			switch key {
+			case "hostname":
+				host.HostName = strings.ReplaceAll(value, "%h", alias)
+			case "user":
+				host.User = value
+			case "port":
+				port, err := strconv.Atoi(value)
+				if err != nil {
+					return host, fmt.Errorf("host %s: invalid Port '%s'", alias, value)
+				}
+				host.Port = port
+			case "identityfile":
+				host.IdentityFile = expandHome(value)
+			case "proxyjump":
+				host.ProxyJump = value
+			}
+		}
+	}
+	return host, nil
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
+
+// parseJumpHost turns a single-hop ProxyJump, [user@]host[:port], into a bastion.
+func parseJumpHost(jump string, cfg *sshConfig) (*v1alpha1.BastionSpec, error) {
+	if jump == "" || strings.EqualFold(jump, "none") {
+		return nil, nil
+	}
+	if strings.Contains(jump, ",") {
+		return nil, fmt.Errorf("ProxyJump '%s' has several hops, kubexm supports a single bastion", jump)
+	}
+	bastion := &v1alpha1.BastionSpec{}
+	if user, rest, ok := strings.Cut(jump, "@"); ok {
+		bastion.User, jump = user, rest
+	}
+	if host, port, ok := strings.Cut(jump, ":"); ok {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port in ProxyJump '%s'", jump)
+		}
+		jump, bastion.Port = host, p
+	}
+	resolved, err := cfg.lookup(jump)
+	if err != nil {
+		return nil, err
+	}
+	bastion.Address = jump
+	if resolved.HostName != "" {
+		bastion.Address = resolved.HostName
+	}
+	if bastion.User == "" {
+		bastion.User = resolved.User
+	}
+	if bastion.Port == 0 {
+		bastion.Port = resolved.Port
+	}
+	bastion.PrivateKeyPath = resolved.IdentityFile
+	return bastion, nil
+}
+
+// applySSHConfig fills in what the SSH configuration says about the host reached as alias, keeping
+// the values host already has.
+func applySSHConfig(host *v1alpha1.HostSpec, alias string, cfg *sshConfig) error {
+	resolved, err := cfg.lookup(alias)
+	if err != nil {
+		return err
+	}
+	if resolved.HostName != "" {
+		host.Address = resolved.HostName
+	}
+	if host.User == "" {
+		host.User = resolved.User
+	}
+	if host.Port == 0 {
+		host.Port = resolved.Port
+	}
+	if host.PrivateKeyPath == "" {
+		host.PrivateKeyPath = resolved.IdentityFile
+	}
+	if host.Bastion == nil {
+		if host.Bastion, err = parseJumpHost(resolved.ProxyJump, cfg); err != nil {
+			return fmt.Errorf("host %s: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+// importedHosts is the hosts section produced by `kubexm config import`.
+type importedHosts struct {
+	Hosts      []v1alpha1.HostSpec
+	RoleGroups *v1alpha1.RoleGroupsSpec
+}
+
+// newImportedHosts returns an empty hosts section, failing if groupRoles maps a group to a role
+// kubexm does not have.
+func newImportedHosts(groupRoles map[string]string) (*importedHosts, error) {
+	out := &importedHosts{RoleGroups: &v1alpha1.RoleGroupsSpec{}}
+	for group, role := range groupRoles {
+		if out.roleList(role) == nil {
+			return nil, fmt.Errorf("group %s: unknown role '%s'", group, role)
+		}
+	}
+	return out, nil
+}
+
+func (h *importedHosts) roleList(role string) *[]string {
+	switch role {
+	case common.RoleMaster:
+		return &h.RoleGroups.Master
+	case common.RoleWorker:
+		return &h.RoleGroups.Worker
+	case common.RoleEtcd:
+		return &h.RoleGroups.Etcd
+	case common.RoleLoadBalancer:
+		return &h.RoleGroups.LoadBalancer
+	case common.RoleStorage:
+		return &h.RoleGroups.Storage
+	case common.RoleRegistry:
+		return &h.RoleGroups.Registry
+	}
+	return nil
+}
+
+// addHost appends host and adds it to the roles groupRoles gives its groups.
+func (h *importedHosts) addHost(host v1alpha1.HostSpec, groups []string, groupRoles map[string]string) {
+	h.Hosts = append(h.Hosts, host)
+	added := make(map[string]bool)
+	for _, group := range groups {
+		role, ok := groupRoles[group]
+		if !ok || added[role] {
+			continue
+		}
+		added[role] = true
+		list := h.roleList(role)
+		*list = append(*list, host.Name)
+	}
+}
+
+// hostsFromAnsible converts the hosts of inv. groupRoles maps group names to kubexm roles; when
+// sshCfg is set, it resolves the address and credentials of each host as ansible's ssh would.
+func hostsFromAnsible(inv *ansibleInventory, groupRoles map[string]string, sshCfg *sshConfig) (*importedHosts, error) {
+	out, err := newImportedHosts(groupRoles)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range inv.hosts {
+		groups := inv.memberOf(host)
+		vars := inv.hostVars(host, groups)
+		spec := v1alpha1.HostSpec{
+			Name:            host.name,
+			Address:         firstVar(vars, "ansible_host", "ansible_ssh_host"),
+			InternalAddress: firstVar(vars, "ip"),
+			User:            firstVar(vars, "ansible_user", "ansible_ssh_user"),
+			Password:        firstVar(vars, "ansible_password", "ansible_ssh_pass"),
+			PrivateKeyPath:  expandHome(firstVar(vars, "ansible_ssh_private_key_file", "ansible_private_key_file")),
+		}
+		if port := firstVar(vars, "ansible_port", "ansible_ssh_port"); port != "" {
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				return nil, fmt.Errorf("host %s: invalid ansible_port '%s'", host.name, port)
+			}
+			spec.Port = p
+		}
+		if firstVar(vars, "ansible_connection") == "local" {
+			spec.Type = string(common.HostConnectionTypeLocal)
+		}
+		alias := spec.Address
+		if alias == "" {
+			alias, spec.Address = host.name, host.name
+		}
+		if sshCfg != nil && spec.Type != string(common.HostConnectionTypeLocal) {
+			if err := applySSHConfig(&spec, alias, sshCfg); err != nil {
+				return nil, err
+			}
+		}
+		var hostGroups []string
+		for _, group := range inv.groups {
+			if _, member := groups[group]; member {
+				hostGroups = append(hostGroups, group)
+			}
+		}
+		out.addHost(spec, hostGroups, groupRoles)
+	}
+	return out, nil
+}
+
+// hostsFromSSHConfig converts the host aliases of cfg, or only those in names if it is not empty.
+// SSH configurations have no groups, so the hosts get no role.
+func hostsFromSSHConfig(cfg *sshConfig, names []string) (*importedHosts, error) {
+	if len(names) == 0 {
+		names = cfg.aliases()
+	}
+	out := &importedHosts{}
+	for _, alias := range names {
+		spec := v1alpha1.HostSpec{Name: alias, Address: alias}
+		if err := applySSHConfig(&spec, alias, cfg); err != nil {
+			return nil, err
+		}
+		out.Hosts = append(out.Hosts, spec)
+	}
+	return out, nil
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const kubesprayInventory = `# kubespray layout
+node1 ansible_host=95.54.0.12 ip=10.3.0.1
+node[2:3] ansible_user=ubuntu
+
+[kube_control_plane]
+node1
+
+[etcd]
+node1
+
+[kube_node]
+node2
+node3
+gpu1 ansible_port=2222
+
+[gpu]
+gpu1
+
+[k8s_cluster:children]
+kube_control_plane
+kube_node
+
+[all:vars]
+ansible_user=root
+
+[k8s_cluster:vars]
+ansible_ssh_private_key_file="/keys/cluster"
+`
+
+const sshClientConfig = `Host node2
+  HostName 95.54.0.13
+  User admin
+
+Host gpu1
+  HostName 95.54.0.20
+  ProxyJump jump@bastion:2200
+  IdentityFile /keys/gpu
+
+Host bastion
+  HostName 95.54.0.1
+
+Host *
+  User fallback
+  Port 22
+`
+
+func TestHostsFromAnsible(t *testing.T) {
+	inv, err := parseAnsibleInventory(strings.NewReader(kubesprayInventory))
+	if err != nil {
+		t.Fatalf("parseAnsibleInventory() error = %v", err)
+	}
+	sshCfg, err := parseSSHConfig(strings.NewReader(sshClientConfig))
+	if err != nil {
+		t.Fatalf("parseSSHConfig() error = %v", err)
+	}
+	groupRoles := map[string]string{"gpu": "storage"}
+	for group, role := range defaultGroupRoles {
+		groupRoles[group] = role
+	}
+	out, err := hostsFromAnsible(inv, groupRoles, sshCfg)
+	if err != nil {
+		t.Fatalf("hostsFromAnsible() error = %v", err)
+	}
+
+	var got []string
+	for _, h := range out.Hosts {
+		got = append(got, strings.Join([]string{h.Name, h.Address, h.InternalAddress, h.User, h.PrivateKeyPath}, "|"))
+	}
+	want := []string{
+		"node1|95.54.0.12|10.3.0.1|root|/keys/cluster",
+		"node2|95.54.0.13||ubuntu|/keys/cluster",
+		"node3|node3||ubuntu|/keys/cluster",
+		"gpu1|95.54.0.20||root|/keys/cluster",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hosts =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if gpu := out.Hosts[3]; gpu.Port != 2222 || gpu.Bastion == nil || gpu.Bastion.Address != "95.54.0.1" || gpu.Bastion.User != "jump" || gpu.Bastion.Port != 2200 {
+		t.Errorf("gpu1 port = %d, bastion = %+v", gpu.Port, gpu.Bastion)
+	}
+
+	groups := out.RoleGroups
+	if strings.Join(groups.Master, ",") != "node1" || strings.Join(groups.Etcd, ",") != "node1" ||
+		strings.Join(groups.Worker, ",") != "node2,node3,gpu1" || strings.Join(groups.Storage, ",") != "gpu1" {
+		t.Errorf("roleGroups = %+v", groups)
+	}
+
+	if _, err := hostsFromAnsible(inv, map[string]string{"gpu": "gpu"}, nil); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestHostsFromSSHConfig(t *testing.T) {
+	cfg, err := parseSSHConfig(strings.NewReader(sshClientConfig))
+	if err != nil {
+		t.Fatalf("parseSSHConfig() error = %v", err)
+	}
+	out, err := hostsFromSSHConfig(cfg, nil)
+	if err != nil {
+		t.Fatalf("hostsFromSSHConfig() error = %v", err)
+	}
+	var names []string
+	for _, h := range out.Hosts {
+		names = append(names, h.Name)
+	}
+	if strings.Join(names, ",") != "node2,gpu1,bastion" {
+		t.Errorf("hosts = %v, want the aliases that are not patterns", names)
+	}
+	if node2 := out.Hosts[0]; node2.Address != "95.54.0.13" || node2.User != "admin" || node2.Port != 22 {
+		t.Errorf("node2 = %+v, want the first value of each option", node2)
+	}
+	if out.RoleGroups != nil {
+		t.Errorf("roleGroups = %+v, want none from an SSH config", out.RoleGroups)
+	}
+}
+
+func TestParseAnsibleInventory_Errors(t *testing.T) {
+	for name, inventory := range map[string]string{
+		"unknown section type":   "[web:hostvars]\n",
+		"expected <key>=<value>": "node1 ansible_host\n",
+		"unterminated \" quote":  "node1 ansible_ssh_common_args=\"-o X\n",
+	} {
+		if _, err := parseAnsibleInventory(strings.NewReader(inventory)); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("error = %v, want one containing %q", err, name)
+		}
+	}
+}
+
+func TestParseAnsibleInventory_QuotedVars(t *testing.T) {
+	inv, err := parseAnsibleInventory(strings.NewReader(
+		`node1 ansible_host=10.0.0.1 ansible_ssh_common_args="-o ProxyCommand='ssh -W %h:%p jump'" note='a b' path=C:\\keys`))
+	if err != nil {
+		t.Fatalf("parseAnsibleInventory() error = %v", err)
+	}
+	want := map[string]string{
+		"ansible_host":            "10.0.0.1",
+		"ansible_ssh_common_args": "-o ProxyCommand='ssh -W %h:%p jump'",
+		"note":                    "a b",
+		"path":                    `C:\keys`,
+	}
+	vars := inv.byName["node1"].vars
+	if len(vars) != len(want) {
+		t.Errorf("vars = %+v, want %+v", vars, want)
+	}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("vars[%s] = %q, want %q", k, vars[k], v)
+		}
+	}
+}
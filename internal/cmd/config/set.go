@@ -1,16 +1,35 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	clusterconfig "github.com/mensylisir/kubexm/internal/config"
 )
 
+type SetOptions struct {
+	ClusterConfigFile string
+}
+
+var setOptions = &SetOptions{}
+
 var setCmd = &cobra.Command{
-	Use:   "set <key> <value>",
-	Short: "Set a KubeXM CLI configuration value",
+	Use:   "set <key> <value> | set <field.path>=<value>... -f <cluster.yaml>",
+	Short: "Set a KubeXM CLI configuration value or a field of a cluster configuration",
 	Long: `Set a specific configuration key to a new value for the KubeXM CLI.
 
+With -f, set fields of a cluster configuration file instead. Each argument is a
+<field.path>=<value> pair; the value is parsed as YAML, so numbers, booleans and
+lists such as [a,b] keep their type. Missing fields are created. List items are
+selected by index, e.g. spec.hosts[0], or by name, e.g. spec.hosts[master-1]; an
+unknown name or the next index adds an item. Comments in the file are kept. The
+patched file is validated and problems are reported.
+
 Supported keys:
   default-package-dir  - Default directory for packages (e.g., /tmp/kubexm/packages)
   verbose              - Enable verbose output (true/false)
@@ -20,9 +39,24 @@ Examples:
   kubexm config set default-package-dir /opt/kubexm/packages
 
   # Enable verbose mode
-  kubexm config set verbose true`,
-	Args: cobra.ExactArgs(2),
+  kubexm config set verbose true
+
+  # Upgrade the Kubernetes version of a cluster configuration
+  kubexm config set spec.kubernetes.version=v1.29.4 -f cluster.yaml
+
+  # Change the address of a host and add a worker label
+  kubexm config set 'spec.hosts[worker-1].address=192.168.10.31' 'spec.hosts[worker-1].labels.zone=a' -f cluster.yaml`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if setOptions.ClusterConfigFile != "" {
+			return cobra.MinimumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if setOptions.ClusterConfigFile != "" {
+			return setClusterFields(setOptions.ClusterConfigFile, args)
+		}
+
 		key := args[0]
 		value := args[1]
 
@@ -55,6 +89,55 @@ Examples:
 	},
 }
 
+// setClusterFields applies the <field.path>=<value> assignments to the cluster configuration at path.
+func setClusterFields(path string, assignments []string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster configuration %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster configuration %s: %w", path, err)
+	}
+	if clusterconfig.IsSOPSEncrypted(data) {
+		return fmt.Errorf("%s is encrypted with SOPS, edit it with 'sops %s' instead", path, path)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse cluster configuration %s: %w", path, err)
+	}
+
+	for _, assignment := range assignments {
+		field, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return fmt.Errorf("invalid assignment '%s', expected <field.path>=<value>", assignment)
+		}
+		if err := setFieldValue(&doc, strings.TrimSpace(field), value); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode cluster configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode cluster configuration: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write cluster configuration %s: %w", path, err)
+	}
+	fmt.Printf("Set %d field(s) in %s\n", len(assignments), path)
+
+	if err := validateClusterFile(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s does not pass validation:\n%v\n", path, err)
+	}
+	return nil
+}
+
 func init() {
 	ConfigCmd.AddCommand(setCmd)
+	setCmd.Flags().StringVarP(&setOptions.ClusterConfigFile, "config", "f", "", "Path to a cluster configuration file to patch instead of the CLI configuration")
 }
\ No newline at end of file
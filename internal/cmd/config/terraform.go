@@ -0,0 +1,271 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+// terraformOutputMapping is the mapping key for the objects found in `terraform output -json`.
+const terraformOutputMapping = "output"
+
+// terraformMapping gives, for each host field, the attribute paths of a resource or output object
+// tried in order; the first non-empty value is used. Paths are dot-separated, with list indexes as
+// numbers, such as "network.0.fixed_ip_v4". The "group" field adds the host to that group, whose
+// role is looked up like an Ansible group.
+type terraformMapping map[string][]string
+
+var terraformHostFields = map[string]bool{
+	"name": true, "address": true, "internalAddress": true, "user": true, "port": true, "group": true,
+}
+
+// defaultTerraformMappings covers the instances of the AWS, OpenStack and both Proxmox providers.
+// Resources of other types are ignored unless a mapping is given for them.
+var defaultTerraformMappings = map[string]terraformMapping{
+	"aws_instance": {
+		"name":            {"tags.Name"},
+		"address":         {"public_ip", "private_ip"},
+		"internalAddress": {"private_ip"},
+	},
+	"openstack_compute_instance_v2": {
+		"name":            {"name"},
+		"address":         {"access_ip_v4", "network.0.fixed_ip_v4"},
+		"internalAddress": {"network.0.fixed_ip_v4"},
+	},
+	"proxmox_vm_qemu": {
+		"name":    {"name"},
+		"address": {"ssh_host", "default_ipv4_address"},
+		"port":    {"ssh_port"},
+	},
+	"proxmox_virtual_environment_vm": {
+		"name":    {"name"},
+		"address": {"ipv4_addresses.1.0"},
+	},
+	terraformOutputMapping: {
+		"name":            {"name", "hostname"},
+		"address":         {"address", "public_ip", "ip", "access_ip_v4"},
+		"internalAddress": {"private_ip", "internal_address"},
+		"user":            {"user"},
+		"port":            {"port"},
+		"group":           {"role", "group"},
+	},
+}
+
+// terraformMappings returns the default mappings with overrides applied. Each override is
+// <type>.<field>=<path>[|<path>...] and replaces the paths of that field.
+func terraformMappings(overrides []string) (map[string]terraformMapping, error) {
+	mappings := make(map[string]terraformMapping, len(defaultTerraformMappings))
+	for resourceType, mapping := range defaultTerraformMappings {
+		mappings[resourceType] = make(terraformMapping, len(mapping))
+		for field, paths := range mapping {
+			mappings[resourceType][field] = paths
+		}
+	}
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		dot := strings.LastIndex(key, ".")
+		if !ok || dot <= 0 || value == "" {
+			return nil, fmt.Errorf("invalid --terraform-mapping '%s', expected <type>.<field>=<path>[|<path>...]", override)
+		}
+		resourceType, field := key[:dot], key[dot+1:]
+		if !terraformHostFields[field] {
+			return nil, fmt.Errorf("invalid --terraform-mapping '%s': unknown field '%s'", override, field)
+		}
+		if mappings[resourceType] == nil {
+			mappings[resourceType] = terraformMapping{}
+		}
+		mappings[resourceType][field] = strings.Split(value, "|")
+	}
+	return mappings, nil
+}
+
+// attribute returns the value at the dot-separated path of value as a string.
+func attribute(value interface{}, path string) string {
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return ""
+			}
+			value = v[i]
+		default:
+			return ""
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// mapHost builds the host described by attributes. name and group are used when the mapping
+// finds none.
+func (m terraformMapping) mapHost(attributes interface{}, name, group string) (v1alpha1.HostSpec, []string, error) {
+	field := func(f string) string {
+		for _, path := range m[f] {
+			if v := attribute(attributes, path); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	host := v1alpha1.HostSpec{
+		Name:            field("name"),
+		Address:         field("address"),
+		InternalAddress: field("internalAddress"),
+		User:            field("user"),
+	}
+	if host.Name == "" {
+		host.Name = name
+	}
+	if host.Address == "" {
+		return host, nil, fmt.Errorf("host %s: no address found at %s", host.Name, strings.Join(m["address"], ", "))
+	}
+	if port := field("port"); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return host, nil, fmt.Errorf("host %s: invalid port '%s'", host.Name, port)
+		}
+		host.Port = p
+	}
+	groups := []string{group}
+	if g := field("group"); g != "" && g != group {
+		groups = append(groups, g)
+	}
+	return host, groups, nil
+}
+
+type terraformState struct {
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+type terraformOutput struct {
+	Value interface{} `json:"value"`
+}
+
+// hostsFromTerraform converts a Terraform state file, or the output of `terraform output -json`,
+// into hosts. In a state, every instance of a managed resource with a mapping becomes a host of
+// the group named after the resource; in outputs, the output name is the group and its value is
+// an address, a list of addresses or objects, or a map of names to those. Addresses are only taken
+// from outputs whose name has a role, so that unrelated outputs are not mistaken for hosts.
+func hostsFromTerraform(data []byte, mappings map[string]terraformMapping, groupRoles map[string]string) (*importedHosts, error) {
+	out, err := newImportedHosts(groupRoles)
+	if err != nil {
+		return nil, err
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("not a Terraform state or output JSON: %w", err)
+	}
+	names := make(map[string]bool)
+	add := func(host v1alpha1.HostSpec, groups []string) error {
+		if names[host.Name] {
+			return fmt.Errorf("host name '%s' is used twice", host.Name)
+		}
+		names[host.Name] = true
+		out.addHost(host, groups, groupRoles)
+		return nil
+	}
+
+	if _, ok := top["resources"]; ok {
+		var state terraformState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("failed to decode Terraform state: %w", err)
+		}
+		for _, resource := range state.Resources {
+			mapping, ok := mappings[resource.Type]
+			if resource.Mode != "managed" || !ok {
+				continue
+			}
+			for _, instance := range resource.Instances {
+				name := resource.Name
+				switch key := instance.IndexKey.(type) {
+				case float64:
+					name = fmt.Sprintf("%s-%d", resource.Name, int(key)+1)
+				case string:
+					name = fmt.Sprintf("%s-%s", resource.Name, key)
+				}
+				host, groups, err := mapping.mapHost(instance.Attributes, name, resource.Name)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", resource.Type, resource.Name, err)
+				}
+				if err := add(host, groups); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return out, nil
+	}
+
+	mapping := mappings[terraformOutputMapping]
+	outputNames := make([]string, 0, len(top))
+	for name := range top {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+	for _, group := range outputNames {
+		var output terraformOutput
+		if err := json.Unmarshal(top[group], &output); err != nil {
+			return nil, fmt.Errorf("output %s: %w", group, err)
+		}
+		hostOf := func(value interface{}, name string) (v1alpha1.HostSpec, []string, error) {
+			if address, ok := value.(string); ok {
+				return v1alpha1.HostSpec{Name: name, Address: address}, []string{group}, nil
+			}
+			return mapping.mapHost(value, name, group)
+		}
+		var items []interface{}
+		var itemNames []string
+		switch value := output.Value.(type) {
+		case string:
+			items, itemNames = []interface{}{value}, []string{group + "-1"}
+		case []interface{}:
+			for i, item := range value {
+				items = append(items, item)
+				itemNames = append(itemNames, fmt.Sprintf("%s-%d", group, i+1))
+			}
+		case map[string]interface{}:
+			for name := range value {
+				itemNames = append(itemNames, name)
+			}
+			sort.Strings(itemNames)
+			for _, name := range itemNames {
+				items = append(items, value[name])
+			}
+		}
+		for i, item := range items {
+			if _, isAddress := item.(string); isAddress && groupRoles[group] == "" {
+				// Plain values of outputs that are not host groups, such as a VPC id.
+				continue
+			}
+			host, groups, err := hostOf(item, itemNames[i])
+			if err != nil {
+				return nil, fmt.Errorf("output %s: %w", group, err)
+			}
+			if err := add(host, groups); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const terraformStateJSON = `{
+  "version": 4,
+  "terraform_version": "1.7.5",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "master",
+      "instances": [
+        {"index_key": 0, "attributes": {"public_ip": "3.0.0.1", "private_ip": "10.0.0.11", "tags": {"Name": "cp-a"}}},
+        {"index_key": 1, "attributes": {"public_ip": "", "private_ip": "10.0.0.12", "tags": {}}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "aws_instance",
+      "name": "existing",
+      "instances": [{"attributes": {"private_ip": "10.0.9.9"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_security_group",
+      "name": "cluster",
+      "instances": [{"attributes": {"id": "sg-1"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "proxmox_vm_qemu",
+      "name": "gpu",
+      "instances": [{"index_key": "a", "attributes": {"name": "gpu-a", "ssh_host": "192.168.1.50", "ssh_port": "2222"}}]
+    }
+  ]
+}`
+
+const terraformOutputsJSON = `{
+  "vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"},
+  "masters": {"sensitive": false, "type": ["list", "string"], "value": ["10.0.0.11", "10.0.0.12"]},
+  "nodes": {"sensitive": false, "type": ["map", "object"], "value": {
+    "node-b": {"address": "10.0.1.2", "role": "storage"},
+    "node-a": {"address": "10.0.1.1", "user": "ubuntu", "port": 2200}
+  }}
+}`
+
+func TestHostsFromTerraform_State(t *testing.T) {
+	mappings, err := terraformMappings([]string{"proxmox_vm_qemu.group=tags.role"})
+	if err != nil {
+		t.Fatalf("terraformMappings() error = %v", err)
+	}
+	groupRoles := map[string]string{"gpu": "worker"}
+	for group, role := range defaultGroupRoles {
+		groupRoles[group] = role
+	}
+	out, err := hostsFromTerraform([]byte(terraformStateJSON), mappings, groupRoles)
+	if err != nil {
+		t.Fatalf("hostsFromTerraform() error = %v", err)
+	}
+	var got []string
+	for _, h := range out.Hosts {
+		got = append(got, strings.Join([]string{h.Name, h.Address, h.InternalAddress}, "|"))
+	}
+	want := "cp-a|3.0.0.1|10.0.0.11,master-2|10.0.0.12|10.0.0.12,gpu-a|192.168.1.50|"
+	if strings.Join(got, ",") != want {
+		t.Errorf("hosts = %s, want %s", strings.Join(got, ","), want)
+	}
+	if out.Hosts[2].Port != 2222 {
+		t.Errorf("gpu-a port = %d, want 2222", out.Hosts[2].Port)
+	}
+	if strings.Join(out.RoleGroups.Master, ",") != "cp-a,master-2" || strings.Join(out.RoleGroups.Worker, ",") != "gpu-a" {
+		t.Errorf("roleGroups = %+v", out.RoleGroups)
+	}
+
+	mappings, err = terraformMappings([]string{"aws_instance.address=public_ip"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hostsFromTerraform([]byte(terraformStateJSON), mappings, groupRoles); err == nil || !strings.Contains(err.Error(), "no address found at public_ip") {
+		t.Errorf("error = %v, want a missing address error", err)
+	}
+}
+
+func TestHostsFromTerraform_Outputs(t *testing.T) {
+	mappings, err := terraformMappings(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := hostsFromTerraform([]byte(terraformOutputsJSON), mappings, defaultGroupRoles)
+	if err != nil {
+		t.Fatalf("hostsFromTerraform() error = %v", err)
+	}
+	var got []string
+	for _, h := range out.Hosts {
+		got = append(got, h.Name+"="+h.Address)
+	}
+	if want := "masters-1=10.0.0.11,masters-2=10.0.0.12,node-a=10.0.1.1,node-b=10.0.1.2"; strings.Join(got, ",") != want {
+		t.Errorf("hosts = %s, want %s", strings.Join(got, ","), want)
+	}
+	if nodeA := out.Hosts[2]; nodeA.User != "ubuntu" || nodeA.Port != 2200 {
+		t.Errorf("node-a = %+v", nodeA)
+	}
+	if strings.Join(out.RoleGroups.Worker, ",") != "node-a,node-b" || strings.Join(out.RoleGroups.Storage, ",") != "node-b" {
+		t.Errorf("roleGroups = %+v", out.RoleGroups)
+	}
+}
+
+func TestTerraformMappings_Errors(t *testing.T) {
+	for _, override := range []string{"aws_instance.address", "address=public_ip", "aws_instance.zone=az"} {
+		if _, err := terraformMappings([]string{override}); err == nil {
+			t.Errorf("terraformMappings(%q) succeeded, want an error", override)
+		}
+	}
+}
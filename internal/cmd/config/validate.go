@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	clusterconfig "github.com/mensylisir/kubexm/internal/config"
+)
+
+type ValidateOptions struct {
+	ClusterConfigFile string
+}
+
+var validateOptions = &ValidateOptions{}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a cluster configuration file",
+	Long: `Validate a cluster configuration file without connecting to any host.
+
+The file is defaulted and checked the same way 'kubexm create cluster' checks it:
+field values, host addresses against the pod and service CIDRs, role assignments,
+the etcd member count and the component version compatibility matrix.
+
+Examples:
+  # Validate a cluster configuration
+  kubexm config validate -f cluster.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateClusterFile(validateOptions.ClusterConfigFile); err != nil {
+			return fmt.Errorf("cluster configuration %s is invalid:\n%w", validateOptions.ClusterConfigFile, err)
+		}
+		fmt.Printf("Cluster configuration %s is valid.\n", validateOptions.ClusterConfigFile)
+		return nil
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(validateCmd)
+	validateCmd.Flags().VarP(clusterconfig.NewPathsValue(&validateOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	if err := validateCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for validate command: %v\n", err)
+	}
+}
@@ -1,18 +1,48 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	clusterconfig "github.com/mensylisir/kubexm/internal/config"
 )
 
+type ViewOptions struct {
+	ClusterConfigFile string
+	Defaults          bool
+	Output            string
+}
+
+var viewOptions = &ViewOptions{}
+
 var viewCmd = &cobra.Command{
 	Use:   "view",
-	Short: "View current KubeXM CLI configuration settings",
-	Long:  `Displays the current configuration settings for the KubeXM CLI.`,
+	Short: "View current KubeXM CLI configuration settings or a cluster configuration",
+	Long: `Displays the current configuration settings for the KubeXM CLI.
+
+With -f, print a cluster configuration instead. --defaults fills in every default
+kubexm applies before a run, showing the spec a deployment would actually use.
+
+Examples:
+  # Show the CLI configuration
+  kubexm config view
+
+  # Show a cluster configuration with all defaults applied
+  kubexm config view -f cluster.yaml --defaults`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if viewOptions.ClusterConfigFile != "" {
+			return viewClusterFile(viewOptions.ClusterConfigFile, viewOptions.Defaults, viewOptions.Output)
+		}
+		if viewOptions.Defaults {
+			return fmt.Errorf("--defaults requires a cluster configuration file given with -f")
+		}
+
 		config, err := LoadLocalConfig()
 		if err != nil {
 			return fmt.Errorf("failed to load configuration: %w", err)
@@ -63,7 +93,36 @@ var viewCmd = &cobra.Command{
 
 func init() {
 	ConfigCmd.AddCommand(viewCmd)
-	viewCmd.Flags().StringP("output", "o", "yaml", "Output format (currently only yaml supported)")
+	viewCmd.Flags().StringVarP(&viewOptions.Output, "output", "o", "yaml", "Output format of a cluster configuration (yaml|json)")
+	viewCmd.Flags().VarP(clusterconfig.NewPathsValue(&viewOptions.ClusterConfigFile), "config", "f", "Path to a cluster configuration file to print")
+	viewCmd.Flags().BoolVar(&viewOptions.Defaults, "defaults", false, "Apply the defaults to the cluster configuration before printing it")
+}
+
+// viewClusterFile prints the cluster configuration at path, with defaults applied if requested.
+func viewClusterFile(path string, defaults bool, output string) error {
+	cluster, err := loadClusterFile(path)
+	if err != nil {
+		return err
+	}
+	if defaults {
+		v1alpha1.SetDefaults_Cluster(cluster)
+	}
+
+	var data []byte
+	switch output {
+	case "yaml":
+		data, err = sigsyaml.Marshal(cluster)
+	case "json":
+		data, err = json.MarshalIndent(cluster, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("invalid output format '%s', must be one of: yaml, json", output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode cluster configuration: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
 }
 
 func mustGetConfigPath() string {
@@ -0,0 +1,17 @@
+package conformance
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ConformanceCmd represents the conformance command group
+var ConformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Run Kubernetes conformance tests",
+	Long:  `Commands for running the Kubernetes conformance suite against a cluster managed by kubexm with Sonobuoy.`,
+}
+
+// AddConformanceCommand adds the conformance command group to rootCmd.
+func AddConformanceCommand(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(ConformanceCmd)
+}
@@ -0,0 +1,140 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/conformance"
+)
+
+// RunOptions holds options for the conformance run command
+type RunOptions struct {
+	ClusterConfigFile string
+	conformance.Options
+}
+
+var runOptions = &RunOptions{Options: conformance.DefaultOptions()}
+
+func init() {
+	ConformanceCmd.AddCommand(runCmd)
+	runCmd.Flags().VarP(config.NewPathsValue(&runOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	runCmd.Flags().StringVar(&runOptions.Mode, "mode", runOptions.Mode, "Sonobuoy mode: "+strings.Join(conformance.ValidModes(), ", "))
+	runCmd.Flags().StringVar(&runOptions.SonobuoyImage, "sonobuoy-image", "", "Sonobuoy aggregator image, e.g. from a private registry")
+	runCmd.Flags().StringVar(&runOptions.ConformanceImage, "conformance-image", "", "Kubernetes conformance image, e.g. from a private registry")
+	runCmd.Flags().DurationVar(&runOptions.Timeout, "timeout", runOptions.Timeout, "Timeout for the conformance run")
+	runCmd.Flags().StringVar(&runOptions.OutputDir, "output-dir", "", "Directory on the control machine to store the results tarball in (default: <workdir>/conformance)")
+	runCmd.Flags().BoolVar(&runOptions.KeepResources, "keep", false, "Keep the Sonobuoy namespace in the cluster after fetching the results")
+
+	if err := runCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'conformance run': %v\n", err)
+	}
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the conformance suite with Sonobuoy",
+	Long: `Installs Sonobuoy on the first master, runs the conformance suite against the cluster, waits
+for it to finish, fetches the results tarball to the control machine and prints a summary of the
+failed tests. The command fails when any test failed.
+
+The certified-conformance suite takes one to two hours. Use --mode quick to check the setup first.
+
+Examples:
+  # Run the certified conformance suite
+  kubexm conformance run -f config.yaml
+
+  # Smoke-test the Sonobuoy setup with a single test
+  kubexm conformance run -f config.yaml --mode quick
+
+  # Pull the images from a private registry
+  kubexm conformance run -f config.yaml --sonobuoy-image registry.local/sonobuoy/sonobuoy:v0.57.2 \
+    --conformance-image registry.local/conformance:v1.29.0`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		if runOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+		if !slices.Contains(conformance.ValidModes(), runOptions.Mode) {
+			return fmt.Errorf("invalid --mode %q, must be one of: %s", runOptions.Mode, strings.Join(conformance.ValidModes(), ", "))
+		}
+		if runOptions.Timeout <= 0 {
+			return fmt.Errorf("--timeout must be positive")
+		}
+		if runOptions.OutputDir != "" {
+			outputDir, err := filepath.Abs(runOptions.OutputDir)
+			if err != nil {
+				return fmt.Errorf("failed to get absolute path for output directory: %w", err)
+			}
+			runOptions.OutputDir = outputDir
+		}
+
+		absPath, err := filepath.Abs(runOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		log.Infof("Running %s conformance tests against cluster '%s'", runOptions.Mode, clusterConfig.Name)
+
+		// Leave room for installing Sonobuoy and fetching the results around the run itself.
+		goCtx, cancel := context.WithTimeout(context.Background(), runOptions.Timeout+30*time.Minute)
+		defer cancel()
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipHostConnect(true).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewConformancePipeline(runOptions.Options)
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("conformance pipeline planning failed: %w", err)
+		}
+
+		result, err := p.Run(runtimeCtx, graph, false)
+		if err != nil {
+			return fmt.Errorf("conformance pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("conformance run failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		cached, ok := runtimeCtx.GetPipelineCache().Get(fmt.Sprintf(common.CacheKeyConformanceSummary, runtimeCtx.GetRunID()))
+		if !ok {
+			return fmt.Errorf("conformance pipeline did not produce a summary")
+		}
+		summary, ok := cached.(*conformance.Summary)
+		if !ok {
+			return fmt.Errorf("unexpected conformance summary type %T", cached)
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), summary.String())
+		if !summary.Succeeded() {
+			return fmt.Errorf("conformance tests did not pass: %d of %d failed", summary.Failed, summary.Total)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,244 @@
+package debug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// ExecOptions holds options for the exec command
+type ExecOptions struct {
+	ClusterConfigFile string
+	TargetRole        string
+	TargetHosts       []string
+	Sudo              bool
+	Interactive       bool
+	Timeout           time.Duration
+}
+
+var execOptions = &ExecOptions{}
+
+func init() {
+	ExecCmd.Flags().VarP(config.NewPathsValue(&execOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	ExecCmd.Flags().StringVar(&execOptions.TargetRole, "target-role", "", "Only run on hosts with this role (e.g. master, worker, etcd). Empty selects all hosts")
+	ExecCmd.Flags().StringSliceVar(&execOptions.TargetHosts, "target-host", nil, "Only run on these hosts (comma-separated names)")
+	ExecCmd.Flags().BoolVar(&execOptions.Sudo, "sudo", false, "Run the command with sudo")
+	ExecCmd.Flags().BoolVarP(&execOptions.Interactive, "interactive", "i", false, "Attach the local terminal to the command on a PTY (requires exactly one target host)")
+	ExecCmd.Flags().DurationVar(&execOptions.Timeout, "timeout", 2*time.Minute, "Timeout for the command on each host (ignored with --interactive)")
+
+	if err := ExecCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for exec command: %v\n", err)
+	}
+}
+
+// ExecCmd runs a command on the selected hosts of a cluster.
+var ExecCmd = &cobra.Command{
+	Use:   "exec [flags] -- COMMAND [ARGS...]",
+	Short: "Run a diagnostic command on cluster hosts",
+	Long: `Run a command on the hosts of a cluster concurrently and print the output of each host,
+ordered by host name. The exit code is non-zero if the command failed on any host.
+
+Examples:
+  # Check disk usage on all workers
+  kubexm exec -f config.yaml --target-role worker -- df -h /var/lib
+
+  # Inspect the kubelet on two hosts
+  kubexm exec -f config.yaml --target-host node1,node2 --sudo -- systemctl status kubelet
+
+  # Open a shell on a host
+  kubexm exec -f config.yaml --target-host node1 -i`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		command := joinArgs(args)
+		if command == "" && !execOptions.Interactive {
+			return fmt.Errorf("a command must be given after --, or use --interactive to open a shell")
+		}
+
+		absPath, err := filepath.Abs(execOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+		clusterConfig, err := config.ParseFromFile(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		runtimeCtx, cleanupFunc, err := runtime.NewBuilderFromConfig(clusterConfig).Build(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		hosts, err := selectHosts(runtimeCtx.GetHostsByRole(""), execOptions.TargetRole, execOptions.TargetHosts)
+		if err != nil {
+			return err
+		}
+		targets := make([]execTarget, 0, len(hosts))
+		for _, host := range hosts {
+			conn, err := runtime.ForHost(runtimeCtx, host).GetCurrentHostConnector()
+			if err != nil {
+				return fmt.Errorf("failed to get connector for host %s: %w", host.GetName(), err)
+			}
+			targets = append(targets, execTarget{name: host.GetName(), conn: conn})
+		}
+
+		if execOptions.Interactive {
+			if len(targets) != 1 {
+				return fmt.Errorf("--interactive requires exactly one target host, %d selected", len(targets))
+			}
+			return targets[0].conn.ExecInteractive(context.Background(), command, &connector.InteractiveOptions{Sudo: execOptions.Sudo})
+		}
+
+		log.Infof("Running '%s' on %d host(s)...", command, len(targets))
+		outputs := runOnHosts(context.Background(), targets, command, &connector.ExecOptions{Sudo: execOptions.Sudo, Timeout: execOptions.Timeout})
+		if failed := printHostOutputs(cmd.OutOrStdout(), outputs); failed > 0 {
+			return fmt.Errorf("command failed on %d of %d host(s)", failed, len(outputs))
+		}
+		return nil
+	},
+}
+
+// execTarget is a host to run the command on.
+type execTarget struct {
+	name string
+	conn connector.Connector
+}
+
+// HostOutput is the outcome of the command on one host.
+type HostOutput struct {
+	Host     string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+}
+
+// selectHosts returns the hosts with role, restricted to names if given, sorted by name.
+func selectHosts(all []remotefw.Host, role string, names []string) ([]remotefw.Host, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	found := make(map[string]bool, len(names))
+	var selected []remotefw.Host
+	for _, host := range all {
+		if role != "" && !hasRole(host, role) {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[host.GetName()] {
+			continue
+		}
+		found[host.GetName()] = true
+		selected = append(selected, host)
+	}
+	var missing []string
+	for name := range wanted {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("target host(s) not found or not matching role '%s': %s", role, strings.Join(missing, ", "))
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no hosts match role '%s'", role)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].GetName() < selected[j].GetName() })
+	return selected, nil
+}
+
+// joinArgs builds a shell command line from args, quoting each argument so that it reaches the
+// remote shell as a single word, e.g. "sh -c 'a b'" stays three words.
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if !shellSafe(arg) {
+			quoted[i] = connector.ShellEscape(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellSafe reports whether s only contains characters the shell does not interpret, so that it
+// can be left unquoted to keep the command readable.
+func shellSafe(s string) bool {
+	return s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == ""
+}
+
+func hasRole(host remotefw.Host, role string) bool {
+	for _, r := range host.GetRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// runOnHosts runs command on all targets concurrently and returns their outputs ordered by host name.
+func runOnHosts(ctx context.Context, targets []execTarget, command string, opts *connector.ExecOptions) []HostOutput {
+	outputs := make([]HostOutput, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target execTarget) {
+			defer wg.Done()
+			stdout, stderr, err := target.conn.Exec(ctx, command, opts)
+			out := HostOutput{Host: target.name, Stdout: string(stdout), Stderr: string(stderr), Err: err}
+			if err != nil {
+				out.ExitCode = -1
+				var cmdErr *connector.CommandError
+				if errors.As(err, &cmdErr) && cmdErr.ExitCode >= 0 {
+					out.ExitCode = cmdErr.ExitCode
+				}
+			}
+			outputs[i] = out
+		}(i, target)
+	}
+	wg.Wait()
+	sort.SliceStable(outputs, func(i, j int) bool { return outputs[i].Host < outputs[j].Host })
+	return outputs
+}
+
+// printHostOutputs writes the output of each host under a header and returns the number of failed hosts.
+func printHostOutputs(w io.Writer, outputs []HostOutput) int {
+	failed := 0
+	for _, out := range outputs {
+		status := fmt.Sprintf("exit %d", out.ExitCode)
+		if out.Err != nil {
+			failed++
+			if out.ExitCode < 0 {
+				status = fmt.Sprintf("error: %v", out.Err)
+			}
+		}
+		fmt.Fprintf(w, "==> %s (%s) <==\n", out.Host, status)
+		for _, text := range []string{out.Stdout, out.Stderr} {
+			if text == "" {
+				continue
+			}
+			fmt.Fprint(w, text)
+			if !strings.HasSuffix(text, "\n") {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,147 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+// fakeExecConnector returns canned output for a host and records the commands it ran.
+type fakeExecConnector struct {
+	connector.Connector
+	mu       sync.Mutex
+	stdout   string
+	stderr   string
+	err      error
+	commands []string
+}
+
+func (c *fakeExecConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commands = append(c.commands, cmd)
+	return []byte(c.stdout), []byte(c.stderr), c.err
+}
+
+func newTestHost(name string, roles ...string) remotefw.Host {
+	return connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Address: name, Roles: roles})
+}
+
+func TestSelectHosts(t *testing.T) {
+	all := []remotefw.Host{
+		newTestHost("worker2", "worker"),
+		newTestHost("master1", "master", "etcd"),
+		newTestHost("worker1", "worker"),
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		names         []string
+		expected      []string
+		expectedError string
+	}{
+		{name: "all hosts", expected: []string{"master1", "worker1", "worker2"}},
+		{name: "by role", role: "worker", expected: []string{"worker1", "worker2"}},
+		{name: "by role and name", role: "worker", names: []string{"worker2"}, expected: []string{"worker2"}},
+		{name: "unknown host", names: []string{"worker9"}, expectedError: "worker9"},
+		{name: "name outside role", role: "worker", names: []string{"master1"}, expectedError: "master1"},
+		{name: "no hosts in role", role: "registry", expectedError: "no hosts match role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, err := selectHosts(all, tt.role, tt.names)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, h := range hosts {
+				got = append(got, h.GetName())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected hosts %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunOnHosts(t *testing.T) {
+	conns := map[string]*fakeExecConnector{
+		"worker2": {stdout: "up 3 days\n"},
+		"worker1": {stdout: "up 1 day"},
+		"worker3": {stderr: "uptime: not found\n", err: &connector.CommandError{Cmd: "uptime", ExitCode: 127}},
+		"worker4": {err: fmt.Errorf("connection reset")},
+	}
+	var targets []execTarget
+	for _, name := range []string{"worker3", "worker2", "worker4", "worker1"} {
+		targets = append(targets, execTarget{name: name, conn: conns[name]})
+	}
+
+	outputs := runOnHosts(context.Background(), targets, "uptime", &connector.ExecOptions{})
+
+	for name, conn := range conns {
+		if len(conn.commands) != 1 || conn.commands[0] != "uptime" {
+			t.Errorf("expected uptime to run once on %s, got %v", name, conn.commands)
+		}
+	}
+	var order []string
+	exitCodes := map[string]int{}
+	for _, out := range outputs {
+		order = append(order, out.Host)
+		exitCodes[out.Host] = out.ExitCode
+	}
+	if strings.Join(order, ",") != "worker1,worker2,worker3,worker4" {
+		t.Errorf("expected outputs ordered by host name, got %v", order)
+	}
+	expectedCodes := map[string]int{"worker1": 0, "worker2": 0, "worker3": 127, "worker4": -1}
+	for host, code := range expectedCodes {
+		if exitCodes[host] != code {
+			t.Errorf("expected exit code %d on %s, got %d", code, host, exitCodes[host])
+		}
+	}
+
+	var buf bytes.Buffer
+	failed := printHostOutputs(&buf, outputs)
+	if failed != 2 {
+		t.Errorf("expected 2 failed hosts, got %d", failed)
+	}
+	expected := "==> worker1 (exit 0) <==\nup 1 day\n" +
+		"==> worker2 (exit 0) <==\nup 3 days\n" +
+		"==> worker3 (exit 127) <==\nuptime: not found\n" +
+		"==> worker4 (error: connection reset) <==\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"df", "-h", "/var/lib"}, "df -h /var/lib"},
+		{[]string{"sh", "-c", "a b"}, "sh -c 'a b'"},
+		{[]string{"echo", "it's"}, `echo 'it'\''s'`},
+		{[]string{"grep", "", "file"}, "grep '' file"},
+		{[]string{"ls", "*.log"}, "ls '*.log'"},
+	}
+	for _, tt := range tests {
+		if got := joinArgs(tt.args); got != tt.expected {
+			t.Errorf("joinArgs(%q) = %q, expected %q", tt.args, got, tt.expected)
+		}
+	}
+}
@@ -13,7 +13,16 @@ func newDeleteCommand() *cobra.Command {
 		Use:   "delete",
 		Short: "Delete cluster resources",
 		Long:  `Commands for deleting Kubernetes clusters, nodes, and registries.`,
+		// kubexm delete -f cluster.yaml is shorthand for kubexm delete cluster -f cluster.yaml.
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("config") {
+				return cmd.Help()
+			}
+			return cluster.DeleteClusterCmd.RunE(cmd, args)
+		},
 	}
+	DeleteCmd.Flags().AddFlagSet(cluster.DeleteClusterCmd.Flags())
 
 	DeleteCmd.AddCommand(cluster.DeleteClusterCmd)
 	DeleteCmd.AddCommand(cluster.DeleteNodesCmd)
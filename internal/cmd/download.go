@@ -77,7 +77,7 @@ var downloadCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(downloadCmd)
-	downloadCmd.Flags().StringVarP(&downloadOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML/JSON file (required)")
+	downloadCmd.Flags().VarP(config.NewPathsValue(&downloadOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	downloadCmd.Flags().StringVarP(&downloadOptions.OutputPath, "output", "o", "", "Output path for offline bundle (.tar.gz)")
 	downloadCmd.Flags().BoolVar(&downloadOptions.DryRun, "dry-run", false, "Simulate the download without making any changes")
 
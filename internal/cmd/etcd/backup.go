@@ -0,0 +1,115 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// BackupOptions holds options for the etcd backup command
+type BackupOptions struct {
+	ClusterConfigFile string
+	Retain            int
+	Timeout           time.Duration
+}
+
+var backupOptions = &BackupOptions{}
+
+func init() {
+	EtcdCmd.AddCommand(backupCmd)
+	backupCmd.Flags().VarP(config.NewPathsValue(&backupOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	backupCmd.Flags().IntVar(&backupOptions.Retain, "retain", 0, "Number of snapshots to keep on the control machine (default: spec.etcd.backup.keepNumber)")
+	backupCmd.Flags().DurationVar(&backupOptions.Timeout, "timeout", 30*time.Minute, "Timeout for the backup")
+
+	if err := backupCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'etcd backup': %v\n", err)
+	}
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Save an etcd snapshot to the control machine",
+	Long: `Takes a snapshot from the current etcd leader with 'etcdctl snapshot save', verifies it and
+fetches it to <workdir>/<cluster>/etcd/snapshots on the control machine. Only the newest
+--retain snapshots are kept there.
+
+Examples:
+  # Take a snapshot and keep the number of snapshots configured in spec.etcd.backup.keepNumber
+  kubexm etcd backup -f config.yaml
+
+  # Take a snapshot and keep the newest 3
+  kubexm etcd backup -f config.yaml --retain 3`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		if backupOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+		if backupOptions.Retain < 0 {
+			return fmt.Errorf("--retain must not be negative")
+		}
+
+		absPath, err := filepath.Abs(backupOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		retain := backupOptions.Retain
+		if retain == 0 {
+			retain = common.DefaultEtcdKeepBackups
+			if etcdSpec := clusterConfig.Spec.Etcd; etcdSpec != nil && etcdSpec.BackupConfig != nil && etcdSpec.BackupConfig.KeepNumber != nil {
+				retain = *etcdSpec.BackupConfig.KeepNumber
+			}
+		}
+
+		log.Infof("Backing up etcd of cluster '%s' (keeping %d snapshots)", clusterConfig.Name, retain)
+
+		goCtx, cancel := context.WithTimeout(context.Background(), backupOptions.Timeout)
+		defer cancel()
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipHostConnect(true).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewEtcdBackupPipeline(retain)
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("etcd backup pipeline planning failed: %w", err)
+		}
+
+		result, err := p.Run(runtimeCtx, graph, false)
+		if err != nil {
+			return fmt.Errorf("etcd backup pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("etcd backup failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		if snapshotPath, ok := runtimeCtx.GetPipelineCache().Get(fmt.Sprintf(common.CacheKeyEtcdSnapshotPath, runtimeCtx.GetRunID())); ok {
+			fmt.Fprintf(cmd.OutOrStdout(), "Etcd snapshot saved to %v\n", snapshotPath)
+		}
+		return nil
+	},
+}
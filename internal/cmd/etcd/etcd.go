@@ -0,0 +1,17 @@
+package etcd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// EtcdCmd represents the etcd command group
+var EtcdCmd = &cobra.Command{
+	Use:   "etcd",
+	Short: "Operate the etcd cluster",
+	Long:  `Commands for backing up and restoring the etcd cluster of a Kubernetes cluster managed by kubexm.`,
+}
+
+// AddEtcdCommand adds the etcd command group to rootCmd.
+func AddEtcdCommand(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(EtcdCmd)
+}
@@ -0,0 +1,133 @@
+package etcd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// RestoreOptions holds options for the etcd restore command
+type RestoreOptions struct {
+	ClusterConfigFile string
+	SnapshotPath      string
+	Timeout           time.Duration
+}
+
+var restoreOptions = &RestoreOptions{}
+
+func init() {
+	EtcdCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().VarP(config.NewPathsValue(&restoreOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	restoreCmd.Flags().StringVar(&restoreOptions.SnapshotPath, "snapshot", "", "Path to the etcd snapshot on the control machine (required)")
+	restoreCmd.Flags().DurationVar(&restoreOptions.Timeout, "timeout", 60*time.Minute, "Timeout for the restore")
+
+	if err := restoreCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'etcd restore': %v\n", err)
+	}
+	if err := restoreCmd.MarkFlagRequired("snapshot"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'snapshot' flag as required for 'etcd restore': %v\n", err)
+	}
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Rebuild the etcd cluster from a snapshot",
+	Long: `Rebuilds the etcd cluster from a snapshot taken with 'kubexm etcd backup'.
+
+The restore stops kube-apiserver on all masters and etcd on all etcd nodes, replaces the data
+directory of every member with the snapshot, renders the member configs again, starts etcd,
+waits for the cluster to become healthy and starts kube-apiserver again. All writes made after
+the snapshot was taken are lost. Only etcd deployed by kubexm (spec.etcd.type: kubexm) is supported.
+
+Examples:
+  kubexm etcd restore -f config.yaml --snapshot ~/.kubexm/mycluster/etcd/snapshots/etcd-snapshot-20240501T100000Z.db`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+
+		if restoreOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+		if restoreOptions.SnapshotPath == "" {
+			return fmt.Errorf("snapshot must be provided via --snapshot flag")
+		}
+
+		absPath, err := filepath.Abs(restoreOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+		absSnapshotPath, err := filepath.Abs(restoreOptions.SnapshotPath)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for snapshot: %w", err)
+		}
+		if info, err := os.Stat(absSnapshotPath); err != nil || info.IsDir() {
+			return fmt.Errorf("snapshot file does not exist: %s", absSnapshotPath)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+
+		if !assumeYesGlobal {
+			fmt.Printf("WARNING: This will REPLACE the etcd data of cluster '%s' with snapshot '%s'.\n", clusterConfig.Name, absSnapshotPath)
+			fmt.Printf("kube-apiserver will be stopped during the restore and all changes made after the snapshot will be lost.\n")
+			fmt.Print("Are you sure you want to proceed? (yes/no): ")
+			reader := bufio.NewReader(os.Stdin)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				input = "no"
+			}
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input != "yes" {
+				log.Info("Etcd restore aborted by user.")
+				return nil
+			}
+		}
+
+		log.Infof("Restoring etcd of cluster '%s' from '%s'", clusterConfig.Name, absSnapshotPath)
+
+		goCtx, cancel := context.WithTimeout(context.Background(), restoreOptions.Timeout)
+		defer cancel()
+
+		rtBuilder := runtime.NewBuilderFromConfig(clusterConfig).
+			WithSkipHostConnect(true).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewEtcdRestorePipeline(absSnapshotPath)
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("etcd restore pipeline planning failed: %w", err)
+		}
+
+		result, err := p.Run(runtimeCtx, graph, false)
+		if err != nil {
+			return fmt.Errorf("etcd restore pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("etcd restore failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		log.Infof("Etcd restore completed successfully. Status: %s", result.Status)
+		return nil
+	},
+}
@@ -0,0 +1,80 @@
+package infra
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+)
+
+// DestroyOptions holds options for the infra destroy command
+type DestroyOptions struct {
+	ClusterConfigFile string
+	Force             bool
+	Timeout           time.Duration
+}
+
+var destroyOptions = &DestroyOptions{}
+
+func init() {
+	InfraCmd.AddCommand(destroyCmd)
+	destroyCmd.Flags().VarP(config.NewPathsValue(&destroyOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	destroyCmd.Flags().BoolVar(&destroyOptions.Force, "force", false, "Force destruction without confirmation")
+	destroyCmd.Flags().DurationVar(&destroyOptions.Timeout, "timeout", 15*time.Minute, "Timeout for deleting the machines")
+
+	if err := destroyCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'infra destroy': %v\n", err)
+	}
+}
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy [MACHINE...]",
+	Short: "Delete the machines of the cluster on the cloud provider",
+	Long: `Deletes the machines of the cluster, or only the given ones, on the provider of
+spec.infrastructure. Only machines kubexm created for the cluster are deleted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+
+		_, provider, err := loadProvider(destroyOptions.ClusterConfigFile)
+		if err != nil {
+			return err
+		}
+
+		if !destroyOptions.Force && !assumeYesGlobal {
+			target := "all machines of the cluster"
+			if len(args) > 0 {
+				target = "machine(s) " + strings.Join(args, ", ")
+			}
+			fmt.Printf("WARNING: This action will delete %s on %s.\n", target, provider.Name())
+			fmt.Print("Are you sure you want to proceed? (yes/no): ")
+			reader := bufio.NewReader(os.Stdin)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				input = "no"
+			}
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input != "yes" {
+				log.Info("Machine deletion aborted by user.")
+				return nil
+			}
+		}
+
+		goCtx, cancel := context.WithTimeout(context.Background(), destroyOptions.Timeout)
+		defer cancel()
+		if err := provider.Destroy(goCtx, args); err != nil {
+			return fmt.Errorf("%s destroy failed: %w", provider.Name(), err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Machines deleted.")
+		return nil
+	},
+}
@@ -0,0 +1,18 @@
+package infra
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// InfraCmd represents the infra command group
+var InfraCmd = &cobra.Command{
+	Use:   "infra",
+	Short: "Manage the cloud machines kubexm creates for a cluster",
+	Long: `Commands for creating, listing and deleting the cluster hosts on the cloud provider
+configured in spec.infrastructure. VMs on libvirt hypervisors are managed with "kubexm vm".`,
+}
+
+// AddInfraCommand adds the infra command group to rootCmd.
+func AddInfraCommand(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(InfraCmd)
+}
@@ -0,0 +1,60 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/config"
+)
+
+// ListOptions holds options for the infra list command
+type ListOptions struct {
+	ClusterConfigFile string
+}
+
+var listOptions = &ListOptions{}
+
+func init() {
+	InfraCmd.AddCommand(listCmd)
+	listCmd.Flags().VarP(config.NewPathsValue(&listOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+
+	if err := listCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'infra list': %v\n", err)
+	}
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the machines of the cluster on the cloud provider",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, provider, err := loadProvider(listOptions.ClusterConfigFile)
+		if err != nil {
+			return err
+		}
+		goCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		machines, err := provider.ListHosts(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to list the machines on %s: %w", provider.Name(), err)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSTATE\tADDRESS\tINTERNAL ADDRESS\tID")
+		for _, m := range machines {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Name, m.State, orDash(m.Address), orDash(m.InternalAddress), m.ID)
+		}
+		return w.Flush()
+	},
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
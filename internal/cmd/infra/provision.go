@@ -0,0 +1,122 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/config"
+	infraprovider "github.com/mensylisir/kubexm/internal/infra"
+	"github.com/mensylisir/kubexm/internal/logger"
+)
+
+// ProvisionOptions holds options for the infra provision command
+type ProvisionOptions struct {
+	ClusterConfigFile string
+	OutputFile        string
+	Timeout           time.Duration
+}
+
+var provisionOptions = &ProvisionOptions{}
+
+func init() {
+	InfraCmd.AddCommand(provisionCmd)
+	provisionCmd.Flags().VarP(config.NewPathsValue(&provisionOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	provisionCmd.Flags().StringVarP(&provisionOptions.OutputFile, "output", "o", "", "File to write the hosts to (default: <provider>-hosts.yaml next to the configuration, or in it if it is a directory)")
+	provisionCmd.Flags().DurationVar(&provisionOptions.Timeout, "timeout", 30*time.Minute, "Timeout for creating the machines")
+
+	if err := provisionCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'infra provision': %v\n", err)
+	}
+}
+
+var provisionCmd = &cobra.Command{
+	Use:   "provision",
+	Short: "Create the cluster hosts on the cloud provider",
+	Long: `Creates the machines of spec.infrastructure on its provider and waits until they are
+running. Their hosts are written to the output file as a configuration overlay, to be merged
+with the cluster configuration. Roles are given by machine name in spec.roleGroups as usual.
+
+Machines that already exist are left alone, so the command can be run again to add machines
+or to refresh the addresses. Provider credentials not in the configuration are read from the
+environment, e.g. the OS_* variables of an OpenStack openrc file.
+
+Examples:
+  # Create the servers and then the cluster on them
+  source lab-openrc.sh
+  kubexm infra provision -f cluster.yaml
+  kubexm create cluster -f cluster.yaml -f openstack-hosts.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		absPath, provider, err := loadProvider(provisionOptions.ClusterConfigFile)
+		if err != nil {
+			return err
+		}
+		outputFile := provisionOptions.OutputFile
+		if outputFile == "" {
+			outputFile = defaultOutputFile(absPath, provider.Name()+"-hosts.yaml")
+		}
+
+		goCtx, cancel := context.WithTimeout(context.Background(), provisionOptions.Timeout)
+		defer cancel()
+		log.Infof("Provisioning the cluster hosts on %s", provider.Name())
+		hosts, err := provider.Provision(goCtx)
+		if err != nil {
+			return fmt.Errorf("%s provision failed: %w", provider.Name(), err)
+		}
+
+		data, err := sigsyaml.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{"hosts": hosts},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode hosts: %w", err)
+		}
+		if err := os.WriteFile(outputFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write hosts %s: %w", outputFile, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d machines are ready, hosts written to %s\n", len(hosts), outputFile)
+		return nil
+	},
+}
+
+// loadProvider loads the cluster configuration and returns its absolute path and the provider
+// configured in spec.infrastructure.
+func loadProvider(configFile string) (string, infraprovider.Provider, error) {
+	if configFile == "" {
+		return "", nil, fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+	}
+	absPath, err := filepath.Abs(configFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get absolute path for config file: %w", err)
+	}
+	clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load cluster configuration: %w", err)
+	}
+	provider, err := infraprovider.NewProvider(clusterConfig)
+	if err != nil {
+		return "", nil, err
+	}
+	return absPath, provider, nil
+}
+
+// defaultOutputFile returns where the hosts are written when no output file is given: into the
+// first configuration source if it is a directory, so that the hosts are merged when it is read
+// again, and next to it otherwise.
+func defaultOutputFile(configPaths, name string) string {
+	first := configPaths
+	if paths := filepath.SplitList(configPaths); len(paths) > 0 {
+		first = paths[0]
+	}
+	if info, err := os.Stat(first); err == nil && info.IsDir() {
+		return filepath.Join(first, name)
+	}
+	return filepath.Join(filepath.Dir(first), name)
+}
@@ -25,7 +25,7 @@ type CreateOptions struct {
 var createOptions = &CreateOptions{}
 
 func init() {
-	CreateRegistryCmd.Flags().StringVarP(&createOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	CreateRegistryCmd.Flags().VarP(config.NewPathsValue(&createOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	CreateRegistryCmd.Flags().StringVar(&createOptions.RegistryType, "type", "registry", "Registry type: registry (default: registry)")
 	CreateRegistryCmd.Flags().IntVar(&createOptions.RegistryPort, "port", 5000, "Registry port (default: 5000)")
 	CreateRegistryCmd.Flags().BoolVar(&createOptions.DryRun, "dry-run", false, "Simulate the registry creation without making changes")
@@ -27,7 +27,7 @@ type DeleteOptions struct {
 var deleteOptions = &DeleteOptions{}
 
 func init() {
-	DeleteRegistryCmd.Flags().StringVarP(&deleteOptions.ClusterConfigFile, "config", "f", "", "Path to the cluster configuration YAML file (required)")
+	DeleteRegistryCmd.Flags().VarP(config.NewPathsValue(&deleteOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
 	DeleteRegistryCmd.Flags().BoolVar(&deleteOptions.Force, "force", false, "Force deletion without confirmation")
 	DeleteRegistryCmd.Flags().BoolVar(&deleteOptions.DeleteImages, "delete-images", false, "Also delete stored images")
 	DeleteRegistryCmd.Flags().BoolVar(&deleteOptions.DryRun, "dry-run", false, "Simulate the registry deletion without making changes")
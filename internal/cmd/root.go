@@ -1,7 +1,16 @@
 package cmd
 
 import (
+	"github.com/mensylisir/kubexm/internal/cmd/artifacts"
+	"github.com/mensylisir/kubexm/internal/cmd/cache"
+	"github.com/mensylisir/kubexm/internal/cmd/certs"
+	"github.com/mensylisir/kubexm/internal/cmd/cluster"
 	"github.com/mensylisir/kubexm/internal/cmd/config"
+	"github.com/mensylisir/kubexm/internal/cmd/conformance"
+	"github.com/mensylisir/kubexm/internal/cmd/debug"
+	"github.com/mensylisir/kubexm/internal/cmd/etcd"
+	"github.com/mensylisir/kubexm/internal/cmd/infra"
+	"github.com/mensylisir/kubexm/internal/cmd/vm"
 	"github.com/mensylisir/kubexm/internal/logger"
 
 	"github.com/spf13/cobra"
@@ -9,21 +18,29 @@ import (
 
 var (
 	// Commands - all verb-first
-	CreateCmd   *cobra.Command // kubexm create
-	BuildCmd    *cobra.Command // kubexm build
-	DeleteCmd   *cobra.Command // kubexm delete
-	InstallCmd  *cobra.Command // kubexm install
-	UpdateCmd   *cobra.Command // kubexm update
-	UpgradeCmd  *cobra.Command // kubexm upgrade
-	DrainCmd    *cobra.Command // kubexm drain
-	CordonCmd   *cobra.Command // kubexm cordon
-	UncordonCmd *cobra.Command // kubexm uncordon
-	ListCmd     *cobra.Command // kubexm list
-	GetCmd      *cobra.Command // kubexm get
-	CheckCmd    *cobra.Command // kubexm check
-	RenewCmd    *cobra.Command // kubexm renew
-	RotateCmd   *cobra.Command // kubexm rotate
-	PushCmd     *cobra.Command // kubexm push
+	CreateCmd     *cobra.Command // kubexm create
+	BuildCmd      *cobra.Command // kubexm build
+	DeleteCmd     *cobra.Command // kubexm delete
+	InstallCmd    *cobra.Command // kubexm install
+	UpdateCmd     *cobra.Command // kubexm update
+	UpgradeCmd    *cobra.Command // kubexm upgrade
+	DrainCmd      *cobra.Command // kubexm drain
+	CordonCmd     *cobra.Command // kubexm cordon
+	UncordonCmd   *cobra.Command // kubexm uncordon
+	ListCmd       *cobra.Command // kubexm list
+	GetCmd        *cobra.Command // kubexm get
+	CheckCmd      *cobra.Command // kubexm check
+	RenewCmd      *cobra.Command // kubexm renew
+	RotateCmd     *cobra.Command // kubexm rotate
+	PushCmd       *cobra.Command // kubexm push
+	DiffCmd       *cobra.Command // kubexm diff
+	PlanCmd       *cobra.Command // kubexm plan
+	ApplyCmd      *cobra.Command // kubexm apply
+	AddNodesCmd   *cobra.Command // kubexm add-nodes
+	DeleteNodeCmd *cobra.Command // kubexm delete-node
+	ExecCmd       *cobra.Command // kubexm exec
+	VerifyCmd     *cobra.Command // kubexm verify
+	BootstrapCmd  *cobra.Command // kubexm bootstrap
 )
 
 var (
@@ -123,8 +140,39 @@ func init() {
 	PushCmd = newPushCommand()
 	rootCmd.AddCommand(PushCmd)
 
+	DiffCmd = cluster.DiffCmd
+	rootCmd.AddCommand(DiffCmd)
+
+	PlanCmd = cluster.PlanCmd
+	rootCmd.AddCommand(PlanCmd)
+
+	ApplyCmd = cluster.ApplyCmd
+	rootCmd.AddCommand(ApplyCmd)
+
+	AddNodesCmd = cluster.AddNodesCmd
+	rootCmd.AddCommand(AddNodesCmd)
+
+	DeleteNodeCmd = cluster.DeleteNodeCmd
+	rootCmd.AddCommand(DeleteNodeCmd)
+
+	ExecCmd = debug.ExecCmd
+	rootCmd.AddCommand(ExecCmd)
+
+	VerifyCmd = cluster.VerifyCmd
+	rootCmd.AddCommand(VerifyCmd)
+
+	BootstrapCmd = cluster.BootstrapCmd
+	rootCmd.AddCommand(BootstrapCmd)
+
 	// Noun commands
 	config.AddConfigCommand(rootCmd)
+	artifacts.AddArtifactsCommand(rootCmd)
+	cache.AddCacheCommand(rootCmd)
+	certs.AddCertsCommand(rootCmd)
+	etcd.AddEtcdCommand(rootCmd)
+	vm.AddVMCommand(rootCmd)
+	infra.AddInfraCommand(rootCmd)
+	conformance.AddConformanceCommand(rootCmd)
 }
 
 func EnsureInitialized() {
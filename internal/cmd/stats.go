@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+	"github.com/mensylisir/kubexm/internal/stats"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type StatsOptions struct {
+	ClusterName string
+	WorkDir     string
+	Top         int
+}
+
+var statsOptions = &StatsOptions{}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringVarP(&statsOptions.ClusterName, "cluster", "c", "", "Name of the cluster whose step statistics to show (required)")
+	statsCmd.Flags().StringVar(&statsOptions.WorkDir, "work-dir", "", "Global work directory of kubexm (defaults to the .kubexm directory next to the binary)")
+	statsCmd.Flags().IntVar(&statsOptions.Top, "top", 10, "Number of slowest step types to show, 0 shows all")
+	_ = statsCmd.MarkFlagRequired("cluster")
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the slowest step types recorded for a cluster",
+	Long:  `Prints the step types with the highest mean duration, based on the step durations recorded by earlier runs against the cluster.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workDir := statsOptions.WorkDir
+		if workDir == "" {
+			dir, err := helpers.GenerateWorkDir()
+			if err != nil {
+				return fmt.Errorf("could not determine work directory: %w", err)
+			}
+			workDir = dir
+		}
+		path := stats.Path(filepath.Join(workDir, statsOptions.ClusterName))
+		store, err := stats.Load(path)
+		if err != nil {
+			return err
+		}
+
+		slowest := store.Slowest(statsOptions.Top)
+		if len(slowest) == 0 {
+			fmt.Printf("No step durations recorded for cluster '%s' (%s).\n", statsOptions.ClusterName, path)
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"STEP TYPE", "SAMPLES", "MEAN", "MAX"})
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, s := range slowest {
+			table.Append([]string{s.Step, strconv.Itoa(s.Samples), s.Mean.Round(time.Second).String(), s.Max.Round(time.Second).String()})
+		}
+		table.Render()
+		return nil
+	},
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/cmd/cluster"
+)
+
+// newUpgradeCommand creates and returns the upgrade command group
+func newUpgradeCommand() *cobra.Command {
+	UpgradeCmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade cluster resources",
+		Long:  `Commands for upgrading Kubernetes clusters to a newer version.`,
+		// kubexm upgrade -f cluster.yaml --to-version v1.x.y is shorthand for kubexm upgrade cluster.
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("config") {
+				return cmd.Help()
+			}
+			return cluster.UpgradeClusterCmd.RunE(cmd, args)
+		},
+	}
+	cluster.AddUpgradeFlags(UpgradeCmd)
+
+	UpgradeCmd.AddCommand(cluster.UpgradeClusterCmd)
+
+	return UpgradeCmd
+}
@@ -0,0 +1,75 @@
+package vm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/step/libvirt"
+)
+
+// DestroyOptions holds options for the vm destroy command
+type DestroyOptions struct {
+	LifecycleOptions
+	Force bool
+}
+
+var destroyOptions = &DestroyOptions{}
+
+func init() {
+	VMCmd.AddCommand(destroyCmd)
+	addLifecycleFlags(destroyCmd, &destroyOptions.LifecycleOptions, 15*time.Minute)
+	destroyCmd.Flags().BoolVar(&destroyOptions.Force, "force", false, "Force destruction without confirmation")
+}
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy [VM...]",
+	Short: "Delete the VMs of the cluster with their disks",
+	Long: `Powers off and undefines the VMs of spec.infrastructure.libvirt, or only the given ones,
+and deletes their snapshots, disk volumes and cloud-init seeds. VMs that do not exist are
+skipped, so "kubexm vm provision" can recreate the cluster afterwards.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		assumeYesGlobal, _ := cmd.Flags().GetBool("yes")
+
+		goCtx, cancel := context.WithTimeout(context.Background(), destroyOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, destroyOptions.ClusterConfigFile, args)
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		if !destroyOptions.Force && !assumeYesGlobal {
+			names := make([]string, 0, len(session.targets))
+			for _, t := range session.targets {
+				names = append(names, t.vm.Name)
+			}
+			fmt.Printf("WARNING: This action will delete VM(s) %s with their disks and snapshots.\n", strings.Join(names, ", "))
+			fmt.Print("Are you sure you want to proceed? (yes/no): ")
+			reader := bufio.NewReader(os.Stdin)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				input = "no"
+			}
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input != "yes" {
+				log.Info("VM destruction aborted by user.")
+				return nil
+			}
+		}
+
+		outcomes := session.forEach(goCtx, func(ctx context.Context, _ int, t vmTarget) error {
+			return libvirt.RemoveVM(ctx, session.runner, t.conn, session.libvirt.Pool, t.vm.Name)
+		})
+		return reportOutcomes(cmd.OutOrStdout(), outcomes, "destroyed", "destroy")
+	},
+}
@@ -0,0 +1,311 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// LifecycleOptions holds the options shared by the commands managing existing VMs.
+type LifecycleOptions struct {
+	ClusterConfigFile string
+	Timeout           time.Duration
+}
+
+// StopOptions holds options for the vm stop command
+type StopOptions struct {
+	LifecycleOptions
+	Force           bool
+	ShutdownTimeout time.Duration
+}
+
+var (
+	listOptions  = &LifecycleOptions{}
+	startOptions = &LifecycleOptions{}
+	stopOptions  = &StopOptions{}
+)
+
+func init() {
+	VMCmd.AddCommand(listCmd, startCmd, stopCmd)
+	addLifecycleFlags(listCmd, listOptions, 2*time.Minute)
+	addLifecycleFlags(startCmd, startOptions, 5*time.Minute)
+	addLifecycleFlags(stopCmd, &stopOptions.LifecycleOptions, 10*time.Minute)
+	stopCmd.Flags().BoolVar(&stopOptions.Force, "force", false, "Power the VMs off if they do not shut down within --shutdown-timeout")
+	stopCmd.Flags().DurationVar(&stopOptions.ShutdownTimeout, "shutdown-timeout", 2*time.Minute, "Time to wait for each VM to shut down")
+}
+
+// addLifecycleFlags adds the configuration and timeout flags to a command managing existing VMs.
+func addLifecycleFlags(cmd *cobra.Command, opts *LifecycleOptions, timeout time.Duration) {
+	cmd.Flags().VarP(config.NewPathsValue(&opts.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", timeout, "Timeout for the whole operation")
+
+	if err := cmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'vm %s': %v\n", cmd.Name(), err)
+	}
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list [VM...]",
+	Short: "List the VMs of the cluster and their state",
+	Long: `Lists the VMs of spec.infrastructure.libvirt, or only the given ones, with their
+state on their hypervisor. VMs that have not been provisioned are listed as not created.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), listOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, listOptions.ClusterConfigFile, args)
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		rows := make([]runner.VMInfo, len(session.targets))
+		outcomes := session.forEach(goCtx, func(ctx context.Context, i int, t vmTarget) error {
+			rows[i] = runner.VMInfo{Name: t.vm.Name, State: "not created"}
+			exists, err := session.runner.VMExists(ctx, t.conn, t.vm.Name)
+			if err != nil || !exists {
+				return err
+			}
+			info, err := session.runner.GetVMInfo(ctx, t.conn, t.vm.Name)
+			if err != nil {
+				return err
+			}
+			rows[i] = info.VMInfo
+			return nil
+		})
+		for i, outcome := range outcomes {
+			if outcome.Err != nil {
+				rows[i].State = "unknown"
+				rows[i].Error = outcome.Err.Error()
+			}
+		}
+		printVMTable(cmd.OutOrStdout(), session.targets, rows)
+		return nil
+	},
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start [VM...]",
+	Short: "Start the VMs of the cluster",
+	Long:  `Starts the VMs of spec.infrastructure.libvirt, or only the given ones. Running VMs are left alone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), startOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, startOptions.ClusterConfigFile, args)
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		outcomes := session.forEach(goCtx, func(ctx context.Context, _ int, t vmTarget) error {
+			return session.runner.StartVM(ctx, t.conn, t.vm.Name)
+		})
+		return reportOutcomes(cmd.OutOrStdout(), outcomes, "started", "start")
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [VM...]",
+	Short: "Shut down the VMs of the cluster",
+	Long: `Shuts down the VMs of spec.infrastructure.libvirt, or only the given ones, through ACPI
+and waits until they are off. With --force, VMs that do not shut down in time are powered off.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), stopOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, stopOptions.ClusterConfigFile, args)
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		outcomes := session.forEach(goCtx, func(ctx context.Context, _ int, t vmTarget) error {
+			return session.runner.ShutdownVM(ctx, t.conn, t.vm.Name, stopOptions.Force, stopOptions.ShutdownTimeout)
+		})
+		return reportOutcomes(cmd.OutOrStdout(), outcomes, "stopped", "stop")
+	},
+}
+
+// vmTarget is a VM with the connector to its hypervisor.
+type vmTarget struct {
+	vm   v1alpha1.LibvirtVM
+	conn connector.Connector
+}
+
+// vmSession holds the VMs a command operates on, connected to through their hypervisors.
+type vmSession struct {
+	libvirt *v1alpha1.LibvirtInfrastructure
+	runner  runner.Runner
+	targets []vmTarget
+	cleanup func()
+}
+
+// VMOutcome is the outcome of an operation on one VM.
+type VMOutcome struct {
+	VM  string
+	Err error
+}
+
+// openVMSession loads the configuration, selects the VMs named, or all of them, and connects to
+// the hypervisors they are on.
+func openVMSession(goCtx context.Context, configFile string, names []string) (*vmSession, error) {
+	if configFile == "" {
+		return nil, fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+	}
+	absPath, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for config file: %w", err)
+	}
+	clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster configuration: %w", err)
+	}
+	if clusterConfig.Spec.Infrastructure == nil || clusterConfig.Spec.Infrastructure.Libvirt == nil {
+		return nil, fmt.Errorf("the cluster configuration has no spec.infrastructure.libvirt section")
+	}
+	libvirt := clusterConfig.Spec.Infrastructure.Libvirt
+	all, err := libvirt.ExpandVMs()
+	if err != nil {
+		return nil, err
+	}
+	vms, err := selectVMs(all, names)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the hypervisors of the selected VMs are connected to, so that the VMs on the others
+	// can be managed while one of them is down.
+	used := make(map[string]bool)
+	for _, vm := range vms {
+		used[vm.Hypervisor] = true
+	}
+	hvCluster := hypervisorCluster(clusterConfig)
+	hvCluster.Spec.Hosts = nil
+	for _, hv := range libvirt.Hypervisors {
+		if used[hv.Name] {
+			hvCluster.Spec.Hosts = append(hvCluster.Spec.Hosts, hv)
+		}
+	}
+
+	runtimeCtx, cleanupFunc, err := runtime.NewBuilderFromConfig(hvCluster).WithSkipConfigValidation(true).Build(goCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build runtime environment: %w", err)
+	}
+	conns := make(map[string]connector.Connector)
+	for _, host := range runtimeCtx.GetHostsByRole("") {
+		conn, err := runtime.ForHost(runtimeCtx, host).GetCurrentHostConnector()
+		if err != nil {
+			cleanupFunc()
+			return nil, fmt.Errorf("failed to get connector for hypervisor %s: %w", host.GetName(), err)
+		}
+		conns[host.GetName()] = conn
+	}
+
+	session := &vmSession{libvirt: libvirt, runner: runtimeCtx.GetRunner(), cleanup: cleanupFunc}
+	for _, vm := range vms {
+		session.targets = append(session.targets, vmTarget{vm: vm, conn: conns[vm.Hypervisor]})
+	}
+	return session, nil
+}
+
+// selectVMs returns the VMs named, or all VMs if no names are given, sorted by name.
+func selectVMs(vms []v1alpha1.LibvirtVM, names []string) ([]v1alpha1.LibvirtVM, error) {
+	byName := make(map[string]v1alpha1.LibvirtVM, len(vms))
+	for _, vm := range vms {
+		byName[vm.Name] = vm
+	}
+	var selected []v1alpha1.LibvirtVM
+	if len(names) == 0 {
+		selected = append(selected, vms...)
+	} else {
+		var unknown []string
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			vm, ok := byName[name]
+			if !ok {
+				unknown = append(unknown, name)
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				selected = append(selected, vm)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("VM(s) not found in spec.infrastructure.libvirt: %s", strings.Join(unknown, ", "))
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("spec.infrastructure.libvirt has no VMs")
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+	return selected, nil
+}
+
+// forEach runs fn on all VMs of the session concurrently and returns the outcomes in the order of
+// the VMs.
+func (s *vmSession) forEach(ctx context.Context, fn func(ctx context.Context, i int, t vmTarget) error) []VMOutcome {
+	outcomes := make([]VMOutcome, len(s.targets))
+	var wg sync.WaitGroup
+	for i, target := range s.targets {
+		wg.Add(1)
+		go func(i int, target vmTarget) {
+			defer wg.Done()
+			outcomes[i] = VMOutcome{VM: target.vm.Name, Err: fn(ctx, i, target)}
+		}(i, target)
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// reportOutcomes prints a line per VM and returns an error if the operation failed on any VM.
+func reportOutcomes(w io.Writer, outcomes []VMOutcome, done, verb string) error {
+	failed := 0
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			failed++
+			fmt.Fprintf(w, "%s: failed: %v\n", outcome.VM, outcome.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", outcome.VM, done)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d VMs", verb, failed, len(outcomes))
+	}
+	return nil
+}
+
+// printVMTable prints the VMs with their hypervisor and the state libvirt reports for them.
+func printVMTable(w io.Writer, targets []vmTarget, rows []runner.VMInfo) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tHYPERVISOR\tSTATE\tVCPUS\tMEMORY")
+	for i, row := range rows {
+		vcpus, memory := "-", "-"
+		if row.CPUs > 0 {
+			vcpus = fmt.Sprint(row.CPUs)
+		}
+		if row.Memory > 0 {
+			memory = fmt.Sprintf("%dMi", row.Memory)
+		}
+		state := row.State
+		if row.Error != "" {
+			state += " (" + row.Error + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", targets[i].vm.Name, targets[i].vm.Hypervisor, state, vcpus, memory)
+	}
+	tw.Flush()
+}
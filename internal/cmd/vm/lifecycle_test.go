@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+func TestSelectVMs(t *testing.T) {
+	vms := []v1alpha1.LibvirtVM{{Name: "worker1"}, {Name: "cp1"}, {Name: "worker2"}}
+	names := func(vms []v1alpha1.LibvirtVM) string {
+		var out []string
+		for _, vm := range vms {
+			out = append(out, vm.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	all, err := selectVMs(vms, nil)
+	if err != nil || names(all) != "cp1,worker1,worker2" {
+		t.Errorf("selectVMs(all) = %s, %v", names(all), err)
+	}
+	some, err := selectVMs(vms, []string{"worker2", "cp1", "worker2"})
+	if err != nil || names(some) != "cp1,worker2" {
+		t.Errorf("selectVMs(worker2, cp1) = %s, %v", names(some), err)
+	}
+	if _, err := selectVMs(vms, []string{"cp1", "worker9", "etcd1"}); err == nil || !strings.Contains(err.Error(), "etcd1, worker9") {
+		t.Errorf("expected an error naming the unknown VMs, got %v", err)
+	}
+	if _, err := selectVMs(nil, nil); err == nil {
+		t.Error("expected an error without VMs")
+	}
+}
+
+func TestReportOutcomes(t *testing.T) {
+	var out bytes.Buffer
+	err := reportOutcomes(&out, []VMOutcome{{VM: "cp1"}, {VM: "worker1", Err: errors.New("boom")}}, "started", "start")
+	if err == nil || err.Error() != "failed to start 1 of 2 VMs" {
+		t.Errorf("error = %v", err)
+	}
+	if out.String() != "cp1: started\nworker1: failed: boom\n" {
+		t.Errorf("output =\n%s", out.String())
+	}
+	if err := reportOutcomes(&out, []VMOutcome{{VM: "cp1"}}, "started", "start"); err != nil {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestVMsWithoutSnapshot(t *testing.T) {
+	snapshots := map[string][]runner.VMSnapshotInfo{
+		"cp1":     {{Name: "clean"}, {Name: "installed"}},
+		"worker2": {{Name: "installed"}},
+		"worker1": nil,
+	}
+	if got := vmsWithoutSnapshot(snapshots, "clean"); strings.Join(got, ",") != "worker1,worker2" {
+		t.Errorf("VMs without clean = %v", got)
+	}
+	if got := vmsWithoutSnapshot(map[string][]runner.VMSnapshotInfo{"cp1": {{Name: "clean"}}}, "clean"); len(got) != 0 {
+		t.Errorf("VMs without clean = %v", got)
+	}
+}
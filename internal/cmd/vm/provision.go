@@ -0,0 +1,197 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/config"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/pipeline/cluster"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// defaultHostsFile is the file the VM hosts are written to, next to the cluster configuration.
+const defaultHostsFile = "libvirt-hosts.yaml"
+
+// ProvisionOptions holds options for the vm provision command
+type ProvisionOptions struct {
+	ClusterConfigFile string
+	OutputFile        string
+	Timeout           time.Duration
+}
+
+var provisionOptions = &ProvisionOptions{}
+
+func init() {
+	VMCmd.AddCommand(provisionCmd)
+	provisionCmd.Flags().VarP(config.NewPathsValue(&provisionOptions.ClusterConfigFile), "config", "f", "Path to the cluster configuration file or directory, repeat to merge overlays in order (required)")
+	provisionCmd.Flags().StringVarP(&provisionOptions.OutputFile, "output", "o", "", "File to write the VM hosts to (default: "+defaultHostsFile+" next to the configuration, or in it if it is a directory)")
+	provisionCmd.Flags().DurationVar(&provisionOptions.Timeout, "timeout", 30*time.Minute, "Timeout for creating the VMs")
+
+	if err := provisionCmd.MarkFlagRequired("config"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark 'config' flag as required for 'vm provision': %v\n", err)
+	}
+}
+
+var provisionCmd = &cobra.Command{
+	Use:   "provision",
+	Short: "Create the cluster hosts as VMs on libvirt hypervisors",
+	Long: `Creates the VMs of spec.infrastructure.libvirt. On its hypervisor, each VM gets a
+copy-on-write disk backed by the template image and a cloud-init seed creating the
+login user with the SSH keys. Once the VM has an address and sshd answers, its host
+is written to the output file as a configuration overlay, to be merged with the
+cluster configuration. Roles are given by VM name in spec.roleGroups as usual.
+
+VMs that already exist are left alone, so the command can be run again to add VMs
+or to refresh the addresses.
+
+Examples:
+  # Create the VMs and then the cluster on them
+  kubexm vm provision -f cluster.yaml
+  kubexm create cluster -f cluster.yaml -f libvirt-hosts.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.Get()
+		defer logger.SyncGlobal()
+
+		if provisionOptions.ClusterConfigFile == "" {
+			return fmt.Errorf("cluster configuration file must be provided via -f or --config flag")
+		}
+
+		absPath, err := filepath.Abs(provisionOptions.ClusterConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for config file: %w", err)
+		}
+
+		clusterConfig, err := config.ParseFromFileWithOptions(absPath, config.ParseOptions{SkipHostValidation: true})
+		if err != nil {
+			return fmt.Errorf("failed to load cluster configuration: %w", err)
+		}
+		if clusterConfig.Spec.Infrastructure == nil || clusterConfig.Spec.Infrastructure.Libvirt == nil {
+			return fmt.Errorf("the cluster configuration has no spec.infrastructure.libvirt section")
+		}
+		libvirt := clusterConfig.Spec.Infrastructure.Libvirt
+		vms, err := libvirt.ExpandVMs()
+		if err != nil {
+			return err
+		}
+
+		outputFile := provisionOptions.OutputFile
+		if outputFile == "" {
+			outputFile = defaultOutputFile(absPath)
+		}
+
+		log.Infof("Provisioning %d VMs for cluster '%s' on %d hypervisors", len(vms), clusterConfig.Name, len(libvirt.Hypervisors))
+
+		goCtx, cancel := context.WithTimeout(context.Background(), provisionOptions.Timeout)
+		defer cancel()
+
+		// The hypervisors are connected to and their facts gathered, which the VM definitions need.
+		rtBuilder := runtime.NewBuilderFromConfig(hypervisorCluster(clusterConfig)).
+			WithSkipConfigValidation(true)
+		runtimeCtx, cleanupFunc, err := rtBuilder.Build(goCtx)
+		if err != nil {
+			return fmt.Errorf("failed to build runtime environment: %w", err)
+		}
+		defer cleanupFunc()
+
+		p := cluster.NewVMProvisionPipeline()
+		graph, err := p.Plan(runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("vm provision pipeline planning failed: %w", err)
+		}
+
+		result, err := p.Run(runtimeCtx, graph, false)
+		if err != nil {
+			return fmt.Errorf("vm provision pipeline execution failed: %w", err)
+		}
+		if result.Status == plan.StatusFailed {
+			return fmt.Errorf("vm provision failed with status: %s. Message: %s", result.Status, result.Message)
+		}
+
+		addresses := make(map[string]string, len(vms))
+		for _, vm := range vms {
+			address, ok := runtimeCtx.GetPipelineCache().Get(fmt.Sprintf(common.CacheKeyVMAddress, runtimeCtx.GetRunID(), vm.Name))
+			if !ok {
+				return fmt.Errorf("no address was found for VM %s", vm.Name)
+			}
+			addresses[vm.Name] = fmt.Sprint(address)
+		}
+
+		data, err := sigsyaml.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{"hosts": vmHosts(libvirt, vms, addresses)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode VM hosts: %w", err)
+		}
+		if err := os.WriteFile(outputFile, data, 0600); err != nil {
+			return fmt.Errorf("failed to write VM hosts %s: %w", outputFile, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d VMs are ready, hosts written to %s\n", len(vms), outputFile)
+		return nil
+	},
+}
+
+// defaultOutputFile returns where the VM hosts are written when no output file is given: into the
+// first configuration source if it is a directory, so that the hosts are merged when it is read
+// again, and next to it otherwise.
+func defaultOutputFile(configPaths string) string {
+	first := configPaths
+	if paths := filepath.SplitList(configPaths); len(paths) > 0 {
+		first = paths[0]
+	}
+	if info, err := os.Stat(first); err == nil && info.IsDir() {
+		return filepath.Join(first, defaultHostsFile)
+	}
+	return filepath.Join(filepath.Dir(first), defaultHostsFile)
+}
+
+// hypervisorCluster returns a copy of cfg whose hosts are the hypervisors, for the runtime the
+// VMs are created with.
+func hypervisorCluster(cfg *v1alpha1.Cluster) *v1alpha1.Cluster {
+	out := *cfg
+	spec := *cfg.Spec
+	spec.Hosts = append([]v1alpha1.HostSpec(nil), cfg.Spec.Infrastructure.Libvirt.Hypervisors...)
+	spec.RoleGroups = &v1alpha1.RoleGroupsSpec{}
+	out.Spec = &spec
+	return &out
+}
+
+// vmHosts returns the host of each VM at its address, logging in as the user created by
+// cloud-init and, unless the VMs are bridged, through their hypervisor.
+func vmHosts(libvirt *v1alpha1.LibvirtInfrastructure, vms []v1alpha1.LibvirtVM, addresses map[string]string) []v1alpha1.HostSpec {
+	hypervisors := make(map[string]v1alpha1.HostSpec, len(libvirt.Hypervisors))
+	for _, hv := range libvirt.Hypervisors {
+		hypervisors[hv.Name] = hv
+	}
+	hosts := make([]v1alpha1.HostSpec, 0, len(vms))
+	for _, vm := range vms {
+		host := v1alpha1.HostSpec{
+			Name:           vm.Name,
+			Address:        addresses[vm.Name],
+			User:           libvirt.User,
+			PrivateKeyPath: libvirt.PrivateKeyPath,
+		}
+		if libvirt.JumpThroughHypervisor != nil && *libvirt.JumpThroughHypervisor {
+			hv := hypervisors[vm.Hypervisor]
+			host.Bastion = &v1alpha1.BastionSpec{
+				Address:        hv.Address,
+				Port:           hv.Port,
+				User:           hv.User,
+				Password:       hv.Password,
+				PrivateKey:     hv.PrivateKey,
+				PrivateKeyPath: hv.PrivateKeyPath,
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+func TestVMHosts(t *testing.T) {
+	jump, direct := true, false
+	libvirt := &v1alpha1.LibvirtInfrastructure{
+		Hypervisors:           []v1alpha1.HostSpec{{Name: "kvm1", Address: "10.0.0.2", Port: 2222, User: "admin", PrivateKeyPath: "/keys/kvm"}},
+		User:                  "ubuntu",
+		PrivateKeyPath:        "/keys/vm",
+		JumpThroughHypervisor: &jump,
+	}
+	vms := []v1alpha1.LibvirtVM{{Name: "cp1", Hypervisor: "kvm1"}}
+	hosts := vmHosts(libvirt, vms, map[string]string{"cp1": "192.168.122.31"})
+	if len(hosts) != 1 {
+		t.Fatalf("hosts = %+v", hosts)
+	}
+	h := hosts[0]
+	if h.Name != "cp1" || h.Address != "192.168.122.31" || h.User != "ubuntu" || h.PrivateKeyPath != "/keys/vm" {
+		t.Errorf("host = %+v", h)
+	}
+	if h.Bastion == nil || h.Bastion.Address != "10.0.0.2" || h.Bastion.Port != 2222 || h.Bastion.User != "admin" || h.Bastion.PrivateKeyPath != "/keys/kvm" {
+		t.Errorf("bastion = %+v, want the hypervisor", h.Bastion)
+	}
+
+	libvirt.JumpThroughHypervisor = &direct
+	if hosts := vmHosts(libvirt, vms, map[string]string{"cp1": "10.0.0.31"}); hosts[0].Bastion != nil {
+		t.Errorf("bridged VM has bastion %+v", hosts[0].Bastion)
+	}
+}
+
+func TestDefaultOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cluster.yaml")
+	if err := os.WriteFile(file, []byte("spec: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultOutputFile(file + string(filepath.ListSeparator) + "/other/prod.yaml"); got != filepath.Join(dir, defaultHostsFile) {
+		t.Errorf("output next to a file = %s", got)
+	}
+	if got := defaultOutputFile(dir); got != filepath.Join(dir, defaultHostsFile) {
+		t.Errorf("output for a directory = %s", got)
+	}
+}
+
+func TestHypervisorCluster(t *testing.T) {
+	cfg := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+		Hosts:          []v1alpha1.HostSpec{{Name: "local-1"}},
+		RoleGroups:     &v1alpha1.RoleGroupsSpec{Master: []string{"local-1"}},
+		Infrastructure: &v1alpha1.Infrastructure{Libvirt: &v1alpha1.LibvirtInfrastructure{Hypervisors: []v1alpha1.HostSpec{{Name: "kvm1"}}}},
+	}}
+	out := hypervisorCluster(cfg)
+	if len(out.Spec.Hosts) != 1 || out.Spec.Hosts[0].Name != "kvm1" || len(out.Spec.RoleGroups.Master) != 0 {
+		t.Errorf("hypervisor cluster hosts = %+v, roleGroups = %+v", out.Spec.Hosts, out.Spec.RoleGroups)
+	}
+	if cfg.Spec.Hosts[0].Name != "local-1" || len(cfg.Spec.RoleGroups.Master) != 1 {
+		t.Error("hypervisorCluster modified the cluster configuration")
+	}
+}
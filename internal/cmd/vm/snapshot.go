@@ -0,0 +1,197 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+// snapshotDescription marks the snapshots taken by kubexm.
+const snapshotDescription = "kubexm cluster snapshot"
+
+var (
+	snapshotCreateOptions = &LifecycleOptions{}
+	snapshotListOptions   = &LifecycleOptions{}
+	snapshotRevertOptions = &LifecycleOptions{}
+	snapshotDeleteOptions = &LifecycleOptions{}
+)
+
+func init() {
+	VMCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd, snapshotListCmd, snapshotRevertCmd, snapshotDeleteCmd)
+	addLifecycleFlags(snapshotCreateCmd, snapshotCreateOptions, 15*time.Minute)
+	addLifecycleFlags(snapshotListCmd, snapshotListOptions, 2*time.Minute)
+	addLifecycleFlags(snapshotRevertCmd, snapshotRevertOptions, 15*time.Minute)
+	addLifecycleFlags(snapshotDeleteCmd, snapshotDeleteOptions, 15*time.Minute)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Snapshot the VMs of the cluster and revert them",
+	Long: `Commands for cluster-wide VM snapshots. A snapshot is taken of all VMs, or of the
+VMs given, under the same name, so that the whole cluster can be reverted to it, e.g. to
+reset a lab to a clean state between test installs.
+
+Examples:
+  # Snapshot the freshly provisioned VMs, install, and start over
+  kubexm vm snapshot create clean -f cluster.yaml
+  kubexm create cluster -f cluster.yaml -f libvirt-hosts.yaml
+  kubexm vm snapshot revert clean -f cluster.yaml`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create NAME [VM...]",
+	Short: "Take a snapshot of the VMs",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), snapshotCreateOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, snapshotCreateOptions.ClusterConfigFile, args[1:])
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		outcomes := session.forEach(goCtx, func(ctx context.Context, _ int, t vmTarget) error {
+			return session.runner.CreateSnapshot(ctx, t.conn, t.vm.Name, args[0], snapshotDescription, nil, false, false, false, false, false, true)
+		})
+		return reportOutcomes(cmd.OutOrStdout(), outcomes, "snapshot "+args[0]+" created", "snapshot")
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list [VM...]",
+	Short: "List the snapshots of the VMs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), snapshotListOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, snapshotListOptions.ClusterConfigFile, args)
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		snapshots, outcomes := session.listSnapshots(goCtx)
+		printSnapshotTable(cmd.OutOrStdout(), snapshots)
+		for _, outcome := range outcomes {
+			if outcome.Err != nil {
+				return fmt.Errorf("failed to list the snapshots of VM %s: %w", outcome.VM, outcome.Err)
+			}
+		}
+		return nil
+	},
+}
+
+var snapshotRevertCmd = &cobra.Command{
+	Use:   "revert NAME [VM...]",
+	Short: "Revert the VMs to a snapshot and start them",
+	Long: `Reverts the VMs to a snapshot and starts them. Nothing is reverted unless every VM
+has the snapshot, so that the cluster is not left half reverted.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), snapshotRevertOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, snapshotRevertOptions.ClusterConfigFile, args[1:])
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		snapshots, outcomes := session.listSnapshots(goCtx)
+		for _, outcome := range outcomes {
+			if outcome.Err != nil {
+				return fmt.Errorf("failed to list the snapshots of VM %s: %w", outcome.VM, outcome.Err)
+			}
+		}
+		if missing := vmsWithoutSnapshot(snapshots, args[0]); len(missing) > 0 {
+			return fmt.Errorf("snapshot %s does not exist on VM(s) %s, nothing was reverted", args[0], strings.Join(missing, ", "))
+		}
+
+		outcomes = session.forEach(goCtx, func(ctx context.Context, _ int, t vmTarget) error {
+			return session.runner.RevertToSnapshot(ctx, t.conn, t.vm.Name, args[0], true, true)
+		})
+		return reportOutcomes(cmd.OutOrStdout(), outcomes, "reverted to "+args[0], "revert")
+	},
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete NAME [VM...]",
+	Short: "Delete a snapshot of the VMs",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goCtx, cancel := context.WithTimeout(context.Background(), snapshotDeleteOptions.Timeout)
+		defer cancel()
+		session, err := openVMSession(goCtx, snapshotDeleteOptions.ClusterConfigFile, args[1:])
+		if err != nil {
+			return err
+		}
+		defer session.cleanup()
+
+		outcomes := session.forEach(goCtx, func(ctx context.Context, _ int, t vmTarget) error {
+			return session.runner.DeleteSnapshot(ctx, t.conn, t.vm.Name, args[0], false, false)
+		})
+		return reportOutcomes(cmd.OutOrStdout(), outcomes, "snapshot "+args[0]+" deleted", "delete the snapshot of")
+	},
+}
+
+// listSnapshots returns the snapshots of each VM of the session by VM name.
+func (s *vmSession) listSnapshots(ctx context.Context) (map[string][]runner.VMSnapshotInfo, []VMOutcome) {
+	lists := make([][]runner.VMSnapshotInfo, len(s.targets))
+	outcomes := s.forEach(ctx, func(ctx context.Context, i int, t vmTarget) error {
+		var err error
+		lists[i], err = s.runner.ListSnapshots(ctx, t.conn, t.vm.Name)
+		return err
+	})
+	snapshots := make(map[string][]runner.VMSnapshotInfo, len(s.targets))
+	for i, t := range s.targets {
+		snapshots[t.vm.Name] = lists[i]
+	}
+	return snapshots, outcomes
+}
+
+// vmsWithoutSnapshot returns the VMs that have no snapshot called name, sorted by name.
+func vmsWithoutSnapshot(snapshots map[string][]runner.VMSnapshotInfo, name string) []string {
+	var missing []string
+	for vm, list := range snapshots {
+		found := false
+		for _, snap := range list {
+			if snap.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, vm)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// printSnapshotTable prints the snapshots of each VM, ordered by VM name and creation time.
+func printSnapshotTable(w io.Writer, snapshots map[string][]runner.VMSnapshotInfo) {
+	vms := make([]string, 0, len(snapshots))
+	for vm := range snapshots {
+		vms = append(vms, vm)
+	}
+	sort.Strings(vms)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "VM\tSNAPSHOT\tCREATED\tSTATE")
+	for _, vm := range vms {
+		list := append([]runner.VMSnapshotInfo(nil), snapshots[vm]...)
+		sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt < list[j].CreatedAt })
+		for _, snap := range list {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", vm, snap.Name, snap.CreatedAt, snap.State)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,18 @@
+package vm
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// VMCmd represents the vm command group
+var VMCmd = &cobra.Command{
+	Use:   "vm",
+	Short: "Manage the VMs kubexm creates for a cluster",
+	Long: `Commands for creating the cluster hosts as VMs, as described in spec.infrastructure, and for
+starting, stopping, snapshotting and destroying them.`,
+}
+
+// AddVMCommand adds the vm command group to rootCmd.
+func AddVMCommand(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(VMCmd)
+}
@@ -0,0 +1,85 @@
+package common
+
+import "time"
+
+const (
+	AddonMetricsServer        = "metrics-server"
+	AddonIngressNginx         = "ingress-nginx"
+	AddonDashboard            = "dashboard"
+	AddonLocalPathProvisioner = "local-path-provisioner"
+	AddonCertManager          = "cert-manager"
+	AddonNFSProvisioner       = "nfs-subdir-external-provisioner"
+
+	// DefaultAddonTimeoutSeconds bounds the install of an addon and the wait for its readiness checks.
+	DefaultAddonTimeoutSeconds = 300
+
+	DefaultMetricsServerChartRepo    = "https://kubernetes-sigs.github.io/metrics-server/"
+	DefaultMetricsServerChartName    = "metrics-server"
+	DefaultMetricsServerChartVersion = "3.12.1"
+	DefaultMetricsServerNamespace    = "kube-system"
+	// kubeadm kubelets serve self-signed certificates unless serverTLSBootstrap is enabled.
+	DefaultMetricsServerKubeletInsecureTLS = true
+	DefaultMetricsServerVerifyRetries      = 12
+	DefaultMetricsServerVerifyDelay        = 10 * time.Second
+
+	DefaultIngressNginxChartRepo    = "https://kubernetes.github.io/ingress-nginx"
+	DefaultIngressNginxChartName    = "ingress-nginx"
+	DefaultIngressNginxChartVersion = "4.10.1"
+	DefaultIngressNginxNamespace    = "ingress-nginx"
+	DefaultIngressNginxReadyTimeout = 5 * time.Minute
+
+	IngressNginxServiceTypeNodePort     = "NodePort"
+	IngressNginxServiceTypeLoadBalancer = "LoadBalancer"
+	IngressNginxServiceTypeHostNetwork  = "HostNetwork"
+	DefaultIngressNginxServiceType      = IngressNginxServiceTypeNodePort
+	DefaultIngressNginxHTTPPort         = 80
+	DefaultIngressNginxHTTPSPort        = 443
+	DefaultIngressNginxHTTPNodePort     = 30080
+	DefaultIngressNginxHTTPSNodePort    = 30443
+
+	DefaultDashboardChartRepo    = "https://kubernetes.github.io/dashboard/"
+	DefaultDashboardChartName    = "kubernetes-dashboard"
+	DefaultDashboardChartVersion = "7.5.0"
+	DefaultDashboardNamespace    = "kubernetes-dashboard"
+
+	DefaultLocalPathProvisionerNamespace        = "local-path-storage"
+	DefaultLocalPathProvisionerStoragePath      = "/opt/local-path-provisioner"
+	DefaultLocalPathProvisionerStorageClassName = "local-path"
+	DefaultLocalPathProvisionerIsDefaultClass   = false
+
+	DefaultCertManagerChartRepo    = "https://charts.jetstack.io"
+	DefaultCertManagerChartName    = "cert-manager"
+	DefaultCertManagerChartVersion = "v1.14.5"
+	DefaultCertManagerNamespace    = "cert-manager"
+
+	DefaultNFSProvisionerChartRepo    = "https://kubernetes-sigs.github.io/nfs-subdir-external-provisioner/"
+	DefaultNFSProvisionerChartName    = "nfs-subdir-external-provisioner"
+	DefaultNFSProvisionerChartVersion = "4.0.18"
+	DefaultNFSProvisionerNamespace    = "nfs-provisioner"
+)
+
+var (
+	DefaultMetricsServerPreferredAddressTypes = []string{"InternalIP", "ExternalIP", "Hostname"}
+	ValidNodeAddressTypes                     = []string{"InternalIP", "ExternalIP", "Hostname", "InternalDNS", "ExternalDNS"}
+	SupportedIngressNginxServiceTypes         = []string{IngressNginxServiceTypeNodePort, IngressNginxServiceTypeLoadBalancer, IngressNginxServiceTypeHostNetwork}
+)
+
+// SupportedAddons lists the built-in addons kubexm knows how to install by name alone.
+// Validation asks the addon registry, which may hold more; this list only stands in for it
+// when the registry is not linked. Any other addon must define its own sources.
+var SupportedAddons = []string{
+	AddonMetricsServer,
+	AddonIngressNginx,
+	AddonDashboard,
+	AddonLocalPathProvisioner,
+	AddonCertManager,
+	AddonNFSProvisioner,
+}
+
+// AddonImages lists the image BOM names pulled by each addon, so they are only saved and pushed when it is enabled.
+var AddonImages = map[string][]string{
+	AddonDashboard:            {"dashboard-api", "dashboard-auth", "dashboard-web", "dashboard-metrics-scraper", "dashboard-kong"},
+	AddonLocalPathProvisioner: {"local-path-provisioner", "local-path-helper"},
+	AddonCertManager:          {"cert-manager-controller", "cert-manager-cainjector", "cert-manager-webhook", "cert-manager-startupapicheck"},
+	AddonNFSProvisioner:       {"nfs-plugin"},
+}
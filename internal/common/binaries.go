@@ -0,0 +1,38 @@
+package common
+
+// Binary source types. A source decides where component binaries are downloaded from; sources are
+// tried in the order they are listed until one serves a file with the expected checksum.
+const (
+	// BinarySourceOfficial downloads from the upstream project release URLs.
+	BinarySourceOfficial = "official"
+	// BinarySourceCN downloads from the qingstor mirror used when KXZONE=cn.
+	BinarySourceCN = "cn"
+	// BinarySourceAliyun and BinarySourceHuawei download from the public mirrors of those clouds.
+	// They only carry some components; for the rest the next source is used.
+	BinarySourceAliyun = "aliyun"
+	BinarySourceHuawei = "huawei"
+	// BinarySourceCustom prefixes the upstream host and path with baseURL, for proxies and
+	// artifact repositories that mirror several upstream hosts.
+	BinarySourceCustom = "custom"
+	// BinarySourceLocal reads from a file server laid out like the kubexm work directory.
+	BinarySourceLocal = "local"
+)
+
+var SupportedBinarySources = []string{
+	BinarySourceOfficial,
+	BinarySourceCN,
+	BinarySourceAliyun,
+	BinarySourceHuawei,
+	BinarySourceCustom,
+	BinarySourceLocal,
+}
+
+// BinaryChecksumFileSuffix is appended to a file URL to find its checksum on local file servers.
+const BinaryChecksumFileSuffix = ".sha256"
+
+// DefaultDownloadConcurrency is the default limit of HTTP streams downloading binaries at once,
+// counting every segment of a file downloaded in parallel.
+const DefaultDownloadConcurrency = 8
+
+// DefaultArtifactCacheMaxSize is the default size limit of the artifact cache shared by all runs.
+const DefaultArtifactCacheMaxSize = "20Gi"
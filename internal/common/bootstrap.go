@@ -0,0 +1,10 @@
+package common
+
+// Defaults of spec.bootstrap, the login user `kubexm bootstrap` creates on the hosts.
+const (
+	DefaultBootstrapUser = "kubexm"
+	// BootstrapSSHDConfigBegin and BootstrapSSHDConfigEnd delimit the block of sshd settings
+	// kubexm manages at the top of /etc/ssh/sshd_config.
+	BootstrapSSHDConfigBegin = "# BEGIN kubexm managed settings"
+	BootstrapSSHDConfigEnd   = "# END kubexm managed settings"
+)
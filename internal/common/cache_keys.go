@@ -24,7 +24,7 @@ const (
 	CacheKubexmK8sCACertRenew        = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubexm.kubernetes.cacert.renew"
 	CacheKubexmK8sLeafCertRenew      = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubexm.kubernetes.leaftcert.renew"
 	CacheKubexmEtcdCACertRenew       = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubexm.etcd.cacert.renew"
-	CacheKubexmEtcdLeafCertRenew    = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubexm.etcd.leafcert.renew"
+	CacheKubexmEtcdLeafCertRenew     = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubexm.etcd.leafcert.renew"
 	CacheKubeconfigsIsBackup         = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubeconfigs.backup"
 	CacheKubeconfigsBackupPath       = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubeconfigs.backup.path"
 	CacheKubeCertsIsBackup           = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubecerts.backup"
@@ -35,4 +35,12 @@ const (
 	CacheKeyRemoteBackupPath         = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].k8s.remote.backup.path.%s"
 	CacheKeyKubeadmBackupPath        = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubeadm.upgrade.backup.path.%s"
 	CacheKeyTargetVersion            = "kubexm.run[%s].pipeline[%s].module[%s].task[%s].kubeadm.upgrade.target.version"
+	CacheKeyClusterDriftReport       = "kubexm.run[%s].cluster.drift.report"
+	CacheKeyConformanceSummary       = "kubexm.run[%s].conformance.summary"
+	CacheKeyHostCertExpiration       = "kubexm.run[%s].host[%s].certs.expiration"
+	CacheKeyEtcdSnapshotPath         = "kubexm.run[%s].etcd.snapshot.path"
+	CacheKeyNodeConfigDrift          = "kubexm.run[%s].node[%s].config[%s].drift"
+	CacheKeyHostConfigDrift          = "kubexm.run[%s].host[%s].config.drift.items"
+	CacheKeyHostGPUDevices           = "kubexm.run[%s].pipeline[%s].facts.host.%s.gpus"
+	CacheKeyVMAddress                = "kubexm.run[%s].vm[%s].address"
 )
@@ -8,3 +8,6 @@ const (
 	RuntimeTypeCRIO       ContainerRuntimeType = "cri-o"
 	RuntimeTypeIsula      ContainerRuntimeType = "isula"
 )
+
+// DefaultConfigBackupRetention is the number of timestamped runtime config backups kept on each host.
+const DefaultConfigBackupRetention = 5
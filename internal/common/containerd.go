@@ -5,6 +5,7 @@ const (
 	ContainerdSocketPath         = "unix:///run/containerd/containerd.sock"
 	ContainerdDefaultConfDir     = "/etc/containerd"
 	ContainerdDefaultConfigFile  = "/etc/containerd/config.toml"
+	ContainerdDefaultCertsDir    = "/etc/containerd/certs.d"
 	CrictlDefaultConfigFile      = "/etc/crictl.yaml"
 	ContainerdDefaultSystemdFile = "/etc/systemd/system/containerd.service"
 	ContainerdDefaultDropInFile  = "/etc/systemd/system/containerd.service.d/kubexm.conf"
@@ -17,4 +18,14 @@ const (
 	DefaultContainerdConfig      = "config.toml"
 	DefaultContainerdPauseImage  = "registry.k8s.io/pause:3.9"
 	ContainerdDefaultServiceName = "containerd.service"
+	ContainerdNvidiaRuntimeName  = "nvidia"
+	DefaultNvidiaRuntimeBinary   = "/usr/bin/nvidia-container-runtime"
+)
+
+const (
+	// ContainerdRegistryHostsMinVersion is the first containerd release that reads hosts.toml files.
+	ContainerdRegistryHostsMinVersion = "1.5.0"
+	// ContainerdRegistryHostsDefaultVersion is the release from which hosts.toml is used even without an
+	// explicit registry configPath, since the inline CRI mirrors are no longer honoured.
+	ContainerdRegistryHostsDefaultVersion = "2.0.0"
 )
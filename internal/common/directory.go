@@ -5,6 +5,7 @@ const (
 	DefaultRemoteWorkDir   = "/tmp/kubexm"
 	DefaultLocalWorkDir    = ".kubexm"
 	DefaultLogsDir         = "logs"
+	DefaultDryRunDir       = "dryrun"
 	DefaultConfigDir       = "configs"
 	DefaultCacheDir        = "cache"
 	DefaultCertificatesDir = "certificates"
@@ -16,6 +16,10 @@ const (
 	UpstreamForwardingConfigRoundRobin = "round_robin"
 	UpstreamForwardingConfigSequential = "sequential"
 	DefaultMaxConcurrent               = 1000
+	DefaultCoreDNSReplicas             = 2
+	// Defaults of the linear mode of cluster-proportional-autoscaler, as used by the upstream dns-autoscaler.
+	DefaultCoreDNSAutoscalerCoresPerReplica = 256
+	DefaultCoreDNSAutoscalerNodesPerReplica = 16
 )
 
 const (
@@ -23,4 +27,15 @@ const (
 	CoreDNSDeploymentName          = "coredns"
 	CoreDNSServiceName             = "kube-dns"
 	CoreDNSAutoscalerConfigMapName = "coredns-autoscaler"
+	CoreDNSAutoscalerName          = "coredns-autoscaler"
+)
+
+// Files through which the hosts' resolver is configured from spec.extra.resolves.
+const (
+	ResolvConfPath                  = "/etc/resolv.conf"
+	SystemdResolvedStubResolvConf   = "/run/systemd/resolve/stub-resolv.conf"
+	SystemdResolvedUplinkResolvConf = "/run/systemd/resolve/resolv.conf"
+	SystemdResolvedDropInPath       = "/etc/systemd/resolved.conf.d/kubexm.conf"
+	NetworkManagerDNSDropInPath     = "/etc/NetworkManager/conf.d/90-kubexm-dns.conf"
+	MaxResolvConfNameservers        = 3
 )
@@ -37,6 +37,7 @@ const (
 	DefaultEtcdPath             = "/var/lib/etcd"
 	DefaultEtcdConfig           = "/etc/etcd.conf"
 	DefaultEtcdBackupDir        = "/var/backups/etcd"
+	DefaultExternalEtcdPKIDir   = "/etc/kubernetes/pki/etcd-external"
 	DefaultEtcdScriptPath       = "/usr/local/kubexm/bin/etcd.sh"
 )
 
@@ -51,6 +52,10 @@ const (
 	EtcdAdminCertFileNamePattern  = "admin-%s.pem"      // etcd admin certificate pattern (admin-aa1.pem)
 	EtcdAdminKeyFileNamePattern   = "admin-%s-key.pem"  // etcd admin key pattern (admin-aa1-key.pem)
 
+	ExternalEtcdCaFileName         = "ca.crt"
+	ExternalEtcdClientCertFileName = "client.crt"
+	ExternalEtcdClientKeyFileName  = "client.key"
+
 	EtcdCaCertFileName           = "ca.crt"
 	EtcdCaKeyFileName            = "ca.key"
 	EtcdServerCertFileName       = "server.crt"
@@ -0,0 +1,23 @@
+package common
+
+import "time"
+
+// Event types a hook can subscribe to. They match the runtime.EventType values sent by the engine.
+const (
+	HookEventGraphStarted    = "GraphStarted"
+	HookEventNodeScheduled   = "NodeScheduled"
+	HookEventNodeStarted     = "NodeStarted"
+	HookEventHostStarted     = "HostStarted"
+	HookEventHostOutputChunk = "HostOutputChunk"
+	HookEventHostFinished    = "HostFinished"
+	HookEventNodeFinished    = "NodeFinished"
+	HookEventGraphFinished   = "GraphFinished"
+)
+
+const (
+	// DefaultHookTimeout bounds a single run of a hook command or webhook call.
+	DefaultHookTimeout = 30 * time.Second
+)
+
+var SupportedHookEvents = []string{HookEventGraphStarted, HookEventNodeScheduled, HookEventNodeStarted, HookEventHostStarted,
+	HookEventHostOutputChunk, HookEventHostFinished, HookEventNodeFinished, HookEventGraphFinished}
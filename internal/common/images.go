@@ -15,3 +15,9 @@ const (
 	CnNamespaceOverride       = "kubexmio"
 	DefaultKubeImageNamespace = "kubexm"
 )
+
+const (
+	DefaultImagePreloadEnabled = true
+	// ContainerdK8sNamespace is the containerd namespace the CRI plugin, and therefore the kubelet, reads images from.
+	ContainerdK8sNamespace = "k8s.io"
+)
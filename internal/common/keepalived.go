@@ -30,6 +30,8 @@ const (
 	DefaultKeepalivedUDPProtocol               = "UDP"
 	DefaultKeepalivedVRID                      = 51  // Default Virtual Router ID for Keepalived.
 	DefaultKeepalivedPriorityMaster            = 110 // Default priority for master node in Keepalived.
+	DefaultKeepalivedPriorityStep              = 10  // Priority decrement between consecutive backup nodes.
+	DefaultKeepalivedTrackWeight               = -20 // Priority adjustment applied when the local LB process is down.
 	DefaultKeepalivedRouterID                  = "LVS_DEVEL"
 	DefaultKeepaliveMaster                     = "MASTER"
 	DefaultKeepaliveBackup                     = "BACKUP"
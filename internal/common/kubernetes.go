@@ -17,6 +17,8 @@ const (
 	DefaultAuditEnable          = false
 	KubeProxyModeIPTables       = "iptables"
 	KubeProxyModeIPVS           = "ipvs"
+	KubeProxyModeNone           = "none"
+	DefaultIPVSScheduler        = "rr"
 	KubeProxyEnable             = true
 	DefaultKubeProxyMode        = "ipvs"
 	DefaultKubeletHairpinMode   = "promiscuous-bridge"
@@ -137,6 +139,21 @@ const (
 	KubeadmUpgradeCommand   = "upgrade"
 )
 
+const (
+	KubeadmAPIVersionV1Beta3  = "kubeadm.k8s.io/v1beta3"
+	KubeadmAPIVersionV1Beta4  = "kubeadm.k8s.io/v1beta4"
+	KubeletConfigAPIVersion   = "kubelet.config.k8s.io/v1beta1"
+	KubeProxyConfigAPIVersion = "kubeproxy.config.k8s.io/v1alpha1"
+
+	// KubeadmV1Beta3MinK8sVersion is the oldest Kubernetes version kubexm can render kubeadm config for.
+	KubeadmV1Beta3MinK8sVersion = "1.22.0"
+	// KubeadmV1Beta4MinK8sVersion is the first Kubernetes version whose kubeadm defaults to v1beta4.
+	KubeadmV1Beta4MinK8sVersion = "1.31.0"
+
+	// KubeadmPhaseAddonKubeProxy is the kubeadm init phase that deploys the kube-proxy DaemonSet.
+	KubeadmPhaseAddonKubeProxy = "addon/kube-proxy"
+)
+
 const (
 	DefaultKubeApiserverServiceFile         = "/etc/systemd/system/kube-apiserver.service"
 	DefaultKubeControllerManagerServiceFile = "/etc/systemd/system/kube-controller-manager.service"
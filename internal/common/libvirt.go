@@ -0,0 +1,16 @@
+package common
+
+import "time"
+
+// Defaults of spec.infrastructure.libvirt, the VMs created by `kubexm vm provision`.
+const (
+	DefaultLibvirtPool        = "default"
+	DefaultLibvirtNetwork     = "default"
+	DefaultLibvirtImageFormat = "qcow2"
+	DefaultLibvirtVMVCPUs     = 2
+	DefaultLibvirtVMMemoryMB  = 4096
+	DefaultLibvirtVMDiskGB    = 40
+	DefaultLibvirtWaitTimeout = 10 * time.Minute
+	// LibvirtVMPollInterval is how often a new VM is checked for an address and a listening sshd.
+	LibvirtVMPollInterval = 5 * time.Second
+)
@@ -17,3 +17,12 @@ const (
 	ExternalLBTypeExternal ExternalLoadBalancerType = "external"
 	ExternalLBTypeNone     ExternalLoadBalancerType = ""
 )
+
+// HAMode selects the provider that keeps the control plane endpoint highly available.
+type HAMode string
+
+const (
+	HAModeKeepalived HAMode = "keepalived" // keepalived + haproxy on the loadbalancer nodes
+	HAModeKubeVIP    HAMode = "kube-vip"   // kube-vip static pods on the master nodes
+	HAModeNone       HAMode = ""
+)
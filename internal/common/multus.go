@@ -4,4 +4,10 @@ const (
 	MultusInstallationConfigEnabled   = false
 	MultusInstallationConfigNamespace = "kube-system"
 	MultusInstallationConfigImage     = "ghcr.io/k8snetworkplumbingwg/multus-cni:v4.0.2"
+
+	DefaultMultusNetworkAttachmentNamespace = "default"
+	DefaultMultusCNIVersion                 = "0.3.1"
+	MultusIPAMTypeHostLocal                 = "host-local"
+	MultusIPAMTypeDHCP                      = "dhcp"
+	MultusIPAMTypeWhereabouts               = "whereabouts"
 )
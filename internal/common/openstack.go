@@ -0,0 +1,15 @@
+package common
+
+import "time"
+
+// Defaults of spec.infrastructure.openstack, the servers created by `kubexm infra provision`.
+const (
+	DefaultOpenStackDomainName  = "Default"
+	DefaultOpenStackWaitTimeout = 10 * time.Minute
+	// OpenStackPollInterval is how often new servers are checked for being active, and deleted
+	// ones for being gone.
+	OpenStackPollInterval = 5 * time.Second
+	// OpenStackClusterMetadataKey is the server metadata naming the cluster kubexm created a
+	// server for. Servers without it are never listed or deleted.
+	OpenStackClusterMetadataKey = "kubexm-cluster"
+)
@@ -0,0 +1,15 @@
+package common
+
+// Defaults of spec.system.packageRepository, the local OS package repositories packages are
+// installed from on hosts without internet access.
+const (
+	DefaultPackageRepositoryISOMountPath = "/mnt/kubexm-iso"
+	// PackageRepositoryFileName is the name, without extension, of the repository file kubexm
+	// writes to /etc/yum.repos.d or /etc/apt/sources.list.d.
+	PackageRepositoryFileName = "kubexm-local"
+	// PackageRepositoryDisabledSuffix is appended to the repository files that are disabled while
+	// kubexm installs packages, and removed again to restore them.
+	PackageRepositoryDisabledSuffix = ".kubexm-disabled"
+)
+
+var SupportedPackageMirrorSchemes = []string{"http", "https", "ftp", "file"}
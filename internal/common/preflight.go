@@ -3,4 +3,15 @@ package common
 const (
 	DefaultMinCPUCores = 2
 	DefaultMinMemoryMB = uint64(2048)
+
+	// Control-plane nodes fail below kubeadm's own minimum and warn below the recommended size.
+	DefaultControlPlaneMinCPUCores         = 2
+	DefaultControlPlaneMinMemoryMB         = uint64(1700)
+	DefaultControlPlaneRecommendedCPUCores = 4
+	DefaultControlPlaneRecommendedMemoryMB = uint64(4096)
+
+	DefaultWorkerMinCPUCores         = 1
+	DefaultWorkerMinMemoryMB         = uint64(1024)
+	DefaultWorkerRecommendedCPUCores = 2
+	DefaultWorkerRecommendedMemoryMB = uint64(2048)
 )
@@ -8,4 +8,26 @@ const (
 	DefaultNamespaceOverride   = "kubexmio"
 	DefaultInstallRoot         = "/opt"
 	DefaultRegistryDomain      = "registry.kubexm.io"
+
+	// RegistryDeployTypeExternal uses mirroring.privateRegistry as is; RegistryDeployTypeLocal makes
+	// kubexm run a registry on the first registry role host and point the cluster at it.
+	RegistryDeployTypeExternal = "external"
+	RegistryDeployTypeLocal    = "local"
+
+	DefaultLocalRegistryPort          = 5000
+	LocalRegistryServiceName          = "kubexm-registry"
+	LocalRegistryContainerdNamespace  = "kubexm"
+	LocalRegistryContainerStoragePath = "/var/lib/registry"
+)
+
+const (
+	DockerDefaultCertsDir = "/etc/docker/certs.d"
+	CRIODefaultCertsDir   = "/etc/containers/certs.d"
+	RegistryCACertName    = "ca.crt"
+
+	// Locations and refresh commands of the OS certificate trust store per distribution family.
+	DebianCATrustDir       = "/usr/local/share/ca-certificates"
+	DebianCATrustUpdateCmd = "update-ca-certificates"
+	RHELCATrustDir         = "/etc/pki/ca-trust/source/anchors"
+	RHELCATrustUpdateCmd   = "update-ca-trust extract"
 )
@@ -5,6 +5,12 @@ import "time"
 const (
 	DefaultSSHPort           = 22
 	DefaultConnectionTimeout = 30 * time.Second
+
+	DefaultSSHServerAliveInterval = 15 * time.Second
+	DefaultSSHServerAliveCountMax = 3
+	// DefaultSSHMaxSessions matches the OpenSSH MaxSessions default. Command sessions and the
+	// SFTP subsystem of every connector sharing a connection both count against it.
+	DefaultSSHMaxSessions = 10
 )
 
 type HostConnectionType string
@@ -1,5 +1,7 @@
 package common
 
+import "time"
+
 // System configuration constants
 
 const (
@@ -44,6 +46,13 @@ const (
 	DefaultBackupInterval      = 24
 )
 
+// Defaults for spec.system.retry, applied once the section is present.
+const (
+	DefaultStepRetryAttempts  = 3
+	DefaultStepRetryBaseDelay = 2 * time.Second
+	DefaultStepRetryMaxDelay  = 30 * time.Second
+)
+
 const (
 	DefaultMinDiskGB        = 20
 	DefaultMaxPods          = 110
@@ -107,6 +116,32 @@ const (
 	KernelModuleIpvs        = "ip_vs"
 )
 
+// MinKernelVersionCilium is the oldest kernel the Cilium eBPF dataplane supports.
+const MinKernelVersionCilium = "4.19.57"
+
+// MinKernelVersion is the oldest kernel kubeadm supports; nodes below RecommendedKernelVersion only warn.
+const (
+	MinKernelVersion         = "3.10"
+	RecommendedKernelVersion = "4.19"
+)
+
+// Names of the preflight checks, as used in spec.preflight.skipChecks and --ignore-preflight-errors.
+const (
+	PreflightCheckAll           = "all"
+	PreflightCheckCPU           = "cpu"
+	PreflightCheckMemory        = "memory"
+	PreflightCheckSwap          = "swap"
+	PreflightCheckFirewalld     = "firewalld"
+	PreflightCheckSELinux       = "selinux"
+	PreflightCheckKernelVersion = "kernel_version"
+	PreflightCheckKernelModules = "kernel_modules"
+	PreflightCheckPorts         = "ports"
+	PreflightCheckDiskSpace     = "disk_space"
+	PreflightCheckTimeSync      = "time_sync"
+	PreflightCheckCgroup        = "cgroup"
+	PreflightCheckRuntime       = "runtime"
+)
+
 var (
 	ValidContainerRuntimeTypes = []ContainerRuntimeType{
 		RuntimeTypeContainerd,
@@ -119,12 +154,16 @@ var (
 		UpstreamForwardingConfigRoundRobin,
 		UpstreamForwardingConfigSequential,
 	}
-	SupportedChecks                     = []string{"cpu", "memory", "swap", "firewalld", "selinux"}
+	SupportedChecks = []string{PreflightCheckAll, PreflightCheckCPU, PreflightCheckMemory, PreflightCheckSwap, PreflightCheckFirewalld,
+		PreflightCheckSELinux, PreflightCheckKernelVersion, PreflightCheckKernelModules, PreflightCheckPorts, PreflightCheckDiskSpace,
+		PreflightCheckTimeSync, PreflightCheckCgroup, PreflightCheckRuntime}
 	ValidPMs                            = []string{"yum", "dnf", "apt"}
 	ValidRegistryTypes                  = []string{RegistryTypeHarbor, RegistryTypeDockerRegistry, RegistryTypeRegistry}
 	ValidEtcdMetricsLevels              = []string{"basic", "extensive"}
 	ValidEtcdLogLevels                  = []string{"debug", "info", "warn", "error", "panic", "fatal"}
-	ValidKubeProxyModes                 = []string{KubeProxyModeIPTables, KubeProxyModeIPVS}
+	ValidKubeProxyModes                 = []string{KubeProxyModeIPTables, KubeProxyModeIPVS, KubeProxyModeNone}
+	ValidIPVSSchedulers                 = []string{"rr", "wrr", "lc", "wlc", "lblc", "lblcr", "dh", "sh", "sed", "nq"}
+	IPVSKernelModules                   = []string{KernelModuleIpvs, "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh", "nf_conntrack"}
 	ValidCgroupDrivers                  = []string{CgroupDriverSystemd, CgroupDriverCgroupfs}
 	ValidHybridnetNetworkTypes          = []string{HybridnetDefaultNetworkConfigTypeUnderlay, HybridnetDefaultNetworkConfigTypeOverlay}
 	ValidKubeOvnTunnelTypes             = []string{KubeOvnNetworkingTunnelTypeGeneve, KubeOvnNetworkingTunnelVXLAN}
@@ -136,6 +175,8 @@ var (
 	ValidCiliumIPAMModes                = []string{CiliumIPAMClusterPoolsMode, CiliumIPAMKubernetesMode}
 	ValidCiliumKPRModes                 = []string{CiliumKPRProbeModes, CiliumKPRStrictModes, CiliumKPRDisabledModes}
 	ValidCiliumIdentModes               = []string{CiliumIdentCrdModes, CiliumIdentKvstoreModes}
+	ValidMultusIPAMTypes                = []string{MultusIPAMTypeHostLocal, MultusIPAMTypeDHCP, MultusIPAMTypeWhereabouts}
+	MultusMasterPluginTypes             = []string{"macvlan", "ipvlan"}
 	ValidInternalLoadbalancerTypes      = []string{string(InternalLBTypeHAProxy), string(InternalLBTypeNginx), string(InternalLBTypeKubeVIP)}
 	ValidKubeVIPModes                   = []string{KubeVIPModeARP, KubeVIPModeBGP}
 	ValidNginxLBModes                   = []string{NginxLBTCPModes, NginxLBHTTPModes}
@@ -187,6 +228,11 @@ var (
 		string(InternalLBTypeKubeVIP),
 	}
 
+	SupportedHAModes = []string{
+		string(HAModeKeepalived),
+		string(HAModeKubeVIP),
+	}
+
 	SupportedExternalLoadBalancerTypes = []string{
 		string(ExternalLBTypeKubeVIP),
 		string(ExternalLBTypeKubexmKH),
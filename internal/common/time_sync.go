@@ -0,0 +1,15 @@
+package common
+
+import "time"
+
+// Defaults of spec.system.timeSync, how the hosts keep their clocks in sync with
+// spec.system.ntpServers.
+const (
+	TimeSyncServiceChrony    = "chrony"
+	TimeSyncServiceTimesyncd = "timesyncd"
+	// DefaultMaxClockOffset is the largest clock offset of a host preflight accepts. etcd warns
+	// about clock differences of a second between its members.
+	DefaultMaxClockOffset = 500 * time.Millisecond
+)
+
+var SupportedTimeSyncServices = []string{TimeSyncServiceChrony, TimeSyncServiceTimesyncd}
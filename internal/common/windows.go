@@ -0,0 +1,21 @@
+package common
+
+// Layout of Windows worker nodes. kubeadm resolves its Linux paths (such as /etc/kubernetes and
+// /var/lib/kubelet) against the system drive on Windows, so those directories mirror the Linux
+// ones under C:\; kubexm's own binaries go to C:\k as in the upstream sig-windows tooling.
+const (
+	WindowsBinDir              = `C:\k`
+	WindowsKubernetesConfigDir = `C:\etc\kubernetes`
+	WindowsKubernetesPKIDir    = `C:\etc\kubernetes\pki`
+	WindowsKubeletDir          = `C:\var\lib\kubelet`
+
+	WindowsKubeletServiceName    = "kubelet"
+	WindowsContainerdServiceName = "containerd"
+	// WindowsKubeletFirewallRule is the inbound firewall rule opening KubeletDefaultPort.
+	WindowsKubeletFirewallRule = "kubexm-kubelet"
+
+	// ContainerdWindowsEndpoint is the named pipe containerd serves CRI on, and ContainerdWindowsPipe
+	// the same pipe as a Windows path.
+	ContainerdWindowsEndpoint = "npipe:////./pipe/containerd-containerd"
+	ContainerdWindowsPipe     = `\\.\pipe\containerd-containerd`
+)
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/scheme"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"github.com/mensylisir/kubexm/internal/logger"
@@ -25,6 +26,9 @@ func ParseFromFileWithOptions(filePath string, opts ParseOptions) (*v1alpha1.Clu
 	if strings.TrimSpace(filePath) == "" {
 		return nil, fmt.Errorf("config file path cannot be empty")
 	}
+	if isOverlay(filePath) {
+		return parseMergedWithOptions(filePath, opts)
+	}
 
 	ext := strings.ToLower(filepath.Ext(filePath))
 	switch ext {
@@ -49,19 +53,55 @@ func ParseYAMLWithOptions(filePath string, opts ParseOptions) (*v1alpha1.Cluster
 	if err != nil {
 		return nil, fmt.Errorf("failed to read YAML file %s: %w", filePath, err)
 	}
-
-	var clusterConfig v1alpha1.Cluster
-	log.Debugf("Unmarshalling YAML content into struct...")
-	if err := yaml.Unmarshal(data, &clusterConfig); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML from '%s': %w", filePath, err)
+	if data, err = decryptIfEncrypted(filePath, data, "yaml"); err != nil {
+		return nil, err
+	}
+	if data, err = resolveData(data, filepath.Dir(filePath), false); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML file %s: %w", filePath, err)
 	}
 
-	if err := processClusterConfig(&clusterConfig, opts); err != nil {
-		return nil, fmt.Errorf("failed to process configuration from YAML file %s: %w", filePath, err)
+	clusterConfig, err := parseYAMLData(data, filePath, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	log.Infof("Successfully parsed and processed YAML configuration from: %s", filePath)
-	return &clusterConfig, nil
+	return clusterConfig, nil
+}
+
+// parseMergedWithOptions parses the deep merge of the configuration sources in filePath, see ReadMerged.
+func parseMergedWithOptions(filePath string, opts ParseOptions) (*v1alpha1.Cluster, error) {
+	log := logger.Get()
+	log.Infof("Merging configuration sources: %s", filePath)
+	data, err := ReadMerged(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = resolveData(data, "", false); err != nil {
+		return nil, fmt.Errorf("failed to parse merged configuration %s: %w", filePath, err)
+	}
+
+	clusterConfig, err := parseYAMLData(data, filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Successfully parsed and processed merged configuration from: %s", filePath)
+	return clusterConfig, nil
+}
+
+func parseYAMLData(data []byte, source string, opts ParseOptions) (*v1alpha1.Cluster, error) {
+	log := logger.Get()
+	log.Debugf("Unmarshalling YAML content into struct...")
+	clusterConfig, err := scheme.Decode(data, yaml.Unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML from '%s': %w", source, err)
+	}
+
+	if err := processClusterConfig(clusterConfig, opts); err != nil {
+		return nil, fmt.Errorf("failed to process configuration from YAML file %s: %w", source, err)
+	}
+	return clusterConfig, nil
 }
 
 func ParseJSON(filePath string) (*v1alpha1.Cluster, error) {
@@ -75,19 +115,25 @@ func ParseJSONWithOptions(filePath string, opts ParseOptions) (*v1alpha1.Cluster
 	if err != nil {
 		return nil, fmt.Errorf("failed to read JSON file %s: %w", filePath, err)
 	}
+	if data, err = decryptIfEncrypted(filePath, data, "json"); err != nil {
+		return nil, err
+	}
+	if data, err = resolveData(data, filepath.Dir(filePath), true); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON file %s: %w", filePath, err)
+	}
 
-	var clusterConfig v1alpha1.Cluster
 	log.Debugf("Unmarshalling JSON content into struct...")
-	if err := json.Unmarshal(data, &clusterConfig); err != nil {
+	clusterConfig, err := scheme.Decode(data, json.Unmarshal)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON from '%s': %w", filePath, err)
 	}
 
-	if err := processClusterConfig(&clusterConfig, opts); err != nil {
+	if err := processClusterConfig(clusterConfig, opts); err != nil {
 		return nil, fmt.Errorf("failed to process configuration from JSON file %s: %w", filePath, err)
 	}
 
 	log.Infof("Successfully parsed and processed JSON configuration from: %s", filePath)
-	return &clusterConfig, nil
+	return clusterConfig, nil
 }
 
 func processClusterConfig(clusterConfig *v1alpha1.Cluster, opts ParseOptions) error {
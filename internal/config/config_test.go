@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+const v1alpha2ClusterYAML = `apiVersion: kubexm.io/v1alpha2
+kind: Cluster
+metadata:
+  name: prod
+spec:
+  global:
+    user: root
+    password: secret
+  hosts:
+    - name: master-1
+      address: 10.0.0.11
+    - name: worker-1
+      address: 10.0.0.21
+  roleGroups:
+    master: [master-1]
+    etcd: [master-1]
+    worker: [worker-1]
+  kubernetes:
+    version: v1.28.5
+    clusterName: cluster.local
+  network:
+    plugin: calico
+    podCIDR: 10.244.0.0/16
+    serviceCIDR: 10.96.0.0/12
+`
+
+func TestParseFromFile_v1alpha2(t *testing.T) {
+	cluster, err := ParseFromFile(writeFile(t, t.TempDir(), "cluster.yaml", v1alpha2ClusterYAML))
+	if err != nil {
+		t.Fatalf("ParseFromFile() error = %v", err)
+	}
+	if cluster.APIVersion != common.DefaultAPIVersion {
+		t.Errorf("apiVersion = %s, want the configuration converted to %s", cluster.APIVersion, common.DefaultAPIVersion)
+	}
+	if cluster.Spec.Network.KubePodsCIDR != "10.244.0.0/16" || cluster.Spec.Network.KubeServiceCIDR != "10.96.0.0/12" {
+		t.Errorf("network CIDRs not converted: pods=%s services=%s", cluster.Spec.Network.KubePodsCIDR, cluster.Spec.Network.KubeServiceCIDR)
+	}
+}
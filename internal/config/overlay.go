@@ -0,0 +1,300 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// A cluster configuration may be split over several sources: a path given to ParseFromFile can be
+// a list joined with the OS path list separator, as with KUBECONFIG, each entry can be a directory,
+// whose *.yaml, *.yml and *.json files are read in name order, and each file can hold several YAML
+// documents. All documents are deep-merged in that order, so a shared base is followed by
+// per-environment overlays:
+//
+//   - mappings are merged key by key; a key set to null in an overlay is removed
+//   - lists whose items are all mappings with a "name", such as spec.hosts and spec.addons, are
+//     merged by name: an item with a known name is merged into it, an unknown name is appended and
+//     an item containing "$patch: delete" removes the item of that name
+//   - any other list, such as spec.roleGroups.worker, and any scalar is replaced
+const patchDirective = "$patch"
+
+// splitConfigPaths splits filePath into its entries, dropping empty ones.
+func splitConfigPaths(filePath string) []string {
+	var paths []string
+	for _, path := range filepath.SplitList(filePath) {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// isOverlay reports whether filePath has to be read with ReadMerged: it lists several paths, names
+// a directory or holds more than one YAML document.
+func isOverlay(filePath string) bool {
+	paths := splitConfigPaths(filePath)
+	if len(paths) != 1 {
+		return len(paths) > 1
+	}
+	info, err := os.Stat(paths[0])
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return true
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		return false
+	}
+	docs, err := decodeDocuments(data, "")
+	return err == nil && len(docs) > 1
+}
+
+// configFiles expands the directories of paths into their configuration files.
+func configFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration source %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration directory %s: %w", path, err)
+		}
+		var dirFiles []string
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					dirFiles = append(dirFiles, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+		if len(dirFiles) == 0 {
+			return nil, fmt.Errorf("configuration directory %s contains no .yaml, .yml or .json files", path)
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+// decodeDocuments decodes every non-empty YAML document of data. Relative valueFrom files are made
+// relative to baseDir, the directory of the file data was read from.
+func decodeDocuments(data []byte, baseDir string) ([]map[string]interface{}, error) {
+	var docs []map[string]interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := absolutizeFileReferences(&node, baseDir); err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := node.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// ReadMerged reads every document of the sources in filePath and returns their deep merge as YAML.
+func ReadMerged(filePath string) ([]byte, error) {
+	paths := splitConfigPaths(filePath)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("config file path cannot be empty")
+	}
+	files, err := configFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var merged interface{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration file %s: %w", file, err)
+		}
+		format := "yaml"
+		if strings.EqualFold(filepath.Ext(file), ".json") {
+			format = "json"
+		}
+		if data, err = decryptIfEncrypted(file, data, format); err != nil {
+			return nil, err
+		}
+		docs, err := decodeDocuments(data, filepath.Dir(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal YAML from '%s': %w", file, err)
+		}
+		for _, doc := range docs {
+			merged = mergeValues(merged, doc)
+		}
+	}
+	if merged == nil {
+		return nil, fmt.Errorf("configuration sources %s contain no documents", strings.Join(files, ", "))
+	}
+	return yaml.Marshal(merged)
+}
+
+// mergeValues merges overlay into base following the rules above. base is not modified.
+func mergeValues(base, overlay interface{}) interface{} {
+	switch o := overlay.(type) {
+	case map[string]interface{}:
+		b, ok := base.(map[string]interface{})
+		if _, ref := o[valueFromKey]; ref && len(o) == 1 {
+			// A secret reference replaces the value it overrides instead of merging with it.
+			return o
+		}
+		if !ok {
+			return mergeValues(map[string]interface{}{}, o)
+		}
+		out := make(map[string]interface{}, len(b)+len(o))
+		for k, v := range b {
+			out[k] = v
+		}
+		for k, v := range o {
+			if v == nil {
+				delete(out, k)
+				continue
+			}
+			out[k] = mergeValues(out[k], v)
+		}
+		return out
+	case []interface{}:
+		b, ok := base.([]interface{})
+		if !ok || !namedItems(b) || !namedItems(o) {
+			return withoutDeleted(o)
+		}
+		return mergeNamedLists(b, o)
+	default:
+		return overlay
+	}
+}
+
+// itemName returns the name of a list item that is a mapping with a string "name".
+func itemName(item interface{}) (string, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	name, ok := m["name"].(string)
+	return name, ok && name != ""
+}
+
+// namedItems reports whether every item of list is a mapping with a name.
+func namedItems(list []interface{}) bool {
+	for _, item := range list {
+		if _, ok := itemName(item); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isDeleted reports whether item carries the "$patch: delete" directive.
+func isDeleted(item interface{}) bool {
+	m, ok := item.(map[string]interface{})
+	return ok && m[patchDirective] == "delete"
+}
+
+// withoutDeleted returns list without the items marked for deletion, which have nothing to delete
+// when the list is not merged.
+func withoutDeleted(list []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if !isDeleted(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// mergeNamedLists merges the items of overlay into base by name, keeping the order of base and
+// appending new names in the order of overlay.
+func mergeNamedLists(base, overlay []interface{}) []interface{} {
+	out := make([]interface{}, len(base))
+	copy(out, base)
+	index := make(map[string]int, len(out))
+	for i, item := range out {
+		name, _ := itemName(item)
+		index[name] = i
+	}
+	deleted := make(map[string]bool)
+	for _, item := range overlay {
+		name, _ := itemName(item)
+		if isDeleted(item) {
+			deleted[name] = true
+			continue
+		}
+		if i, ok := index[name]; ok {
+			out[i] = mergeValues(out[i], item)
+			delete(deleted, name)
+			continue
+		}
+		index[name] = len(out)
+		out = append(out, item)
+	}
+	if len(deleted) == 0 {
+		return out
+	}
+	kept := out[:0]
+	for _, item := range out {
+		if name, _ := itemName(item); !deleted[name] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// pathsValue is a string flag that may be repeated; the values are joined into one path list.
+type pathsValue struct {
+	target  *string
+	changed bool
+}
+
+// NewPathsValue returns a flag value storing its paths in target. Use it for the -f flag of
+// commands reading a cluster configuration, so that a base and its overlays can be given as
+// "-f base.yaml -f prod.yaml".
+func NewPathsValue(target *string) pflag.Value {
+	return &pathsValue{target: target}
+}
+
+func (v *pathsValue) Set(value string) error {
+	if !v.changed {
+		*v.target = value
+		v.changed = true
+		return nil
+	}
+	*v.target += string(filepath.ListSeparator) + value
+	return nil
+}
+
+func (v *pathsValue) String() string {
+	return *v.target
+}
+
+func (v *pathsValue) Type() string {
+	return "paths"
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const baseClusterYAML = `apiVersion: kubexm.io/v1alpha1
+kind: Cluster
+metadata:
+  name: base
+spec:
+  global:
+    user: root
+    password: secret
+  hosts:
+    - name: master-1
+      address: 10.0.0.11
+    - name: worker-1
+      address: 10.0.0.21
+    - name: worker-2
+      address: 10.0.0.22
+  roleGroups:
+    master: [master-1]
+    etcd: [master-1]
+    worker: [worker-1, worker-2]
+  kubernetes:
+    version: v1.28.5
+    clusterName: cluster.local
+  network:
+    plugin: calico
+    kubePodsCIDR: 10.244.0.0/16
+    kubeServiceCIDR: 10.96.0.0/12
+`
+
+const prodOverlayYAML = `spec:
+  hosts:
+    - name: worker-1
+      address: 10.1.0.21
+    - name: worker-2
+      $patch: delete
+    - name: worker-3
+      address: 10.1.0.23
+  roleGroups:
+    worker: [worker-1, worker-3]
+---
+metadata:
+  name: prod
+spec:
+  global:
+    password: null
+    privateKeyPath: /root/.ssh/id_rsa
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestReadMerged(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseClusterYAML)
+	prod := writeFile(t, dir, "prod.yaml", prodOverlayYAML)
+
+	data, err := ReadMerged(base + string(filepath.ListSeparator) + prod)
+	if err != nil {
+		t.Fatalf("ReadMerged() error = %v", err)
+	}
+	var merged struct {
+		Metadata struct{ Name string }
+		Spec     struct {
+			Global     map[string]string
+			Hosts      []map[string]string
+			RoleGroups map[string][]string `yaml:"roleGroups"`
+			Network    map[string]string
+		}
+	}
+	if err := yaml.Unmarshal(data, &merged); err != nil {
+		t.Fatalf("merged YAML does not parse: %v\n%s", err, data)
+	}
+
+	if merged.Metadata.Name != "prod" {
+		t.Errorf("metadata.name = %s, want prod", merged.Metadata.Name)
+	}
+	var hosts []string
+	for _, host := range merged.Spec.Hosts {
+		hosts = append(hosts, host["name"]+"="+host["address"])
+	}
+	if got, want := strings.Join(hosts, ","), "master-1=10.0.0.11,worker-1=10.1.0.21,worker-3=10.1.0.23"; got != want {
+		t.Errorf("hosts = %s, want %s", got, want)
+	}
+	if got := strings.Join(merged.Spec.RoleGroups["worker"], ","); got != "worker-1,worker-3" {
+		t.Errorf("roleGroups.worker = %s, want the overlay list", got)
+	}
+	if _, ok := merged.Spec.Global["password"]; ok || merged.Spec.Global["user"] != "root" || merged.Spec.Global["privateKeyPath"] == "" {
+		t.Errorf("global = %v, want password removed, user kept and privateKeyPath added", merged.Spec.Global)
+	}
+	if merged.Spec.Network["plugin"] != "calico" {
+		t.Errorf("network.plugin = %s, want the base value kept", merged.Spec.Network["plugin"])
+	}
+}
+
+func TestParseFromFile_Overlays(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "00-base.yaml", baseClusterYAML)
+	writeFile(t, dir, "10-prod.yaml", prodOverlayYAML)
+	writeFile(t, dir, "README.md", "not a configuration file")
+
+	for name, path := range map[string]string{
+		"directory":   dir,
+		"single file": writeFile(t, t.TempDir(), "cluster.yaml", baseClusterYAML+"---\n"+prodOverlayYAML),
+	} {
+		cluster, err := ParseFromFile(path)
+		if err != nil {
+			t.Fatalf("%s: ParseFromFile() error = %v", name, err)
+		}
+		if cluster.Name != "prod" || len(cluster.Spec.Hosts) != 3 || cluster.Spec.Global.Password != "" {
+			t.Errorf("%s: overlay not applied: name=%s hosts=%d", name, cluster.Name, len(cluster.Spec.Hosts))
+		}
+	}
+
+	if _, err := ParseFromFile(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without configuration files")
+	}
+}
+
+func TestPathsValue(t *testing.T) {
+	var paths string
+	value := NewPathsValue(&paths)
+	for _, path := range []string{"base.yaml", "prod.yaml"} {
+		if err := value.Set(path); err != nil {
+			t.Fatalf("Set(%s) error = %v", path, err)
+		}
+	}
+	if got := splitConfigPaths(paths); strings.Join(got, ",") != "base.yaml,prod.yaml" {
+		t.Errorf("paths = %v, want [base.yaml prod.yaml]", got)
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secrets such as SSH passwords, registry credentials and cloud keys need not be written into the
+// cluster configuration. Any string value may use environment variables as ${NAME}; "$${" is a
+// literal "${". Any value may instead be a mapping holding only valueFrom, which reads it from an
+// environment variable or from a file, trailing newlines removed:
+//
+//	password:
+//	  valueFrom:
+//	    env: SSH_PASSWORD
+//	privateKey:
+//	  valueFrom:
+//	    file: keys/id_rsa.b64 # relative to the configuration file
+//
+// A file is used verbatim, so it must hold the value in the form the field expects: privateKey
+// takes a base64-encoded key (e.g. the output of "base64 -w0 id_rsa"), while a raw PEM key file
+// belongs in privateKeyPath instead.
+// References are resolved when the configuration is parsed. An unset variable or an unreadable
+// file fails the parse, naming the field that refers to it.
+const valueFromKey = "valueFrom"
+
+var envReference = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// valueReference is the target of a valueFrom mapping.
+type valueReference struct {
+	Env  string `yaml:"env"`
+	File string `yaml:"file"`
+}
+
+// valueFrom returns the reference of node if it is a valueFrom mapping.
+func valueFrom(node *yaml.Node, path string) (*valueReference, error) {
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 || node.Content[0].Value != valueFromKey {
+		return nil, nil
+	}
+	ref := &valueReference{}
+	if err := node.Content[1].Decode(ref); err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", path, valueFromKey, err)
+	}
+	if (ref.Env == "") == (ref.File == "") {
+		return nil, fmt.Errorf("%s.%s: exactly one of 'env' or 'file' must be set", path, valueFromKey)
+	}
+	return ref, nil
+}
+
+// walkValues calls fn for every value below node, parents first, with its field path. fn may
+// replace the node it is given; the children of the replacement are walked.
+func walkValues(node *yaml.Node, path string, fn func(node *yaml.Node, path string) error) error {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			if err := walkValues(child, path, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := fn(node, path); err != nil {
+		return err
+	}
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := walkValues(node.Content[i+1], join(node.Content[i].Value), fn); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := walkValues(item, fmt.Sprintf("%s[%d]", path, i), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setString replaces node with the string value, keeping its comments.
+func setString(node *yaml.Node, value string) {
+	*node = yaml.Node{
+		Kind:        yaml.ScalarNode,
+		Tag:         "!!str",
+		Value:       value,
+		HeadComment: node.HeadComment,
+		LineComment: node.LineComment,
+		FootComment: node.FootComment,
+	}
+}
+
+// absolutizeFileReferences makes the relative valueFrom files of node relative to baseDir, so that
+// they still resolve after documents from several directories are merged.
+func absolutizeFileReferences(node *yaml.Node, baseDir string) error {
+	return walkValues(node, "", func(n *yaml.Node, path string) error {
+		ref, err := valueFrom(n, path)
+		if err != nil || ref == nil || ref.File == "" || filepath.IsAbs(ref.File) || baseDir == "" {
+			return err
+		}
+		for i := 0; i+1 < len(n.Content[1].Content); i += 2 {
+			if n.Content[1].Content[i].Value == "file" {
+				n.Content[1].Content[i+1].Value = filepath.Join(baseDir, ref.File)
+			}
+		}
+		return nil
+	})
+}
+
+// expandEnv replaces the ${NAME} references of value.
+func expandEnv(value, path string) (string, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		name := match[2 : len(match)-1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s: environment variable %s is not set (write $${ for a literal ${)", path, strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// resolveReferences replaces the valueFrom mappings and ${NAME} references of node by their values.
+func resolveReferences(node *yaml.Node, baseDir string) error {
+	return walkValues(node, "", func(n *yaml.Node, path string) error {
+		ref, err := valueFrom(n, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case ref != nil && ref.Env != "":
+			value, ok := os.LookupEnv(ref.Env)
+			if !ok {
+				return fmt.Errorf("%s: environment variable %s is not set", path, ref.Env)
+			}
+			setString(n, value)
+		case ref != nil:
+			file := ref.File
+			if !filepath.IsAbs(file) && baseDir != "" {
+				file = filepath.Join(baseDir, file)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("%s: failed to read value from file: %w", path, err)
+			}
+			setString(n, strings.TrimRight(string(data), "\r\n"))
+		case n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str" && strings.Contains(n.Value, "${"):
+			value, err := expandEnv(n.Value, path)
+			if err != nil {
+				return err
+			}
+			n.Value = value
+		}
+		return nil
+	})
+}
+
+// resolveData resolves the references of the YAML or JSON configuration data, whose relative
+// valueFrom files are relative to baseDir, and returns it in the same format.
+func resolveData(data []byte, baseDir string, asJSON bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind == 0 {
+		// Leave the reporting of malformed input to the decoder of the caller.
+		return data, nil
+	}
+	if err := resolveReferences(&doc, baseDir); err != nil {
+		return nil, fmt.Errorf("failed to resolve configuration references: %w", err)
+	}
+	if !asJSON {
+		return yaml.Marshal(&doc)
+	}
+	var value interface{}
+	if err := doc.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// RedactReferences replaces the valueFrom mappings of the YAML configuration data with a
+// placeholder naming their source, for printing the configuration without reading any secret.
+// ${NAME} references are left as written.
+func RedactReferences(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return data, nil
+	}
+	err := walkValues(&doc, "", func(n *yaml.Node, path string) error {
+		ref, err := valueFrom(n, path)
+		switch {
+		case err != nil || ref == nil:
+			return err
+		case ref.Env != "":
+			setString(n, fmt.Sprintf("<from env %s>", ref.Env))
+		default:
+			setString(n, fmt.Sprintf("<from file %s>", ref.File))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&doc)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const secretClusterYAML = `apiVersion: kubexm.io/v1alpha1
+kind: Cluster
+metadata:
+  name: ${KUBEXM_TEST_CLUSTER}
+spec:
+  global:
+    user: root
+    password:
+      valueFrom:
+        env: KUBEXM_TEST_PASSWORD
+  hosts:
+    - name: master-1
+      address: 10.0.0.11
+      privateKey:
+        valueFrom:
+          file: secrets/id_rsa
+  roleGroups:
+    master: [master-1]
+    etcd: [master-1]
+    worker: [master-1]
+  kubernetes:
+    version: v1.28.5
+    clusterName: cluster.local
+  network:
+    plugin: calico
+    kubePodsCIDR: 10.244.0.0/16
+    kubeServiceCIDR: 10.96.0.0/12
+  hooks:
+    - name: notify
+      events: [GraphFinished]
+      command: echo $${KUBEXM_EVENT_NAME}
+`
+
+func TestParseFromFile_ResolvesReferences(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "secrets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "secrets"), "id_rsa", "UFJJVkFURSBLRVk=\n")
+	path := writeFile(t, dir, "cluster.yaml", secretClusterYAML)
+	t.Setenv("KUBEXM_TEST_CLUSTER", "from-env")
+	t.Setenv("KUBEXM_TEST_PASSWORD", "s3cret")
+
+	cluster, err := ParseFromFile(path)
+	if err != nil {
+		t.Fatalf("ParseFromFile() error = %v", err)
+	}
+	if cluster.Name != "from-env" {
+		t.Errorf("metadata.name = %s, want from-env", cluster.Name)
+	}
+	if cluster.Spec.Global.Password != "s3cret" {
+		t.Errorf("global.password = %q, want the value of KUBEXM_TEST_PASSWORD", cluster.Spec.Global.Password)
+	}
+	if got := cluster.Spec.Hosts[0].PrivateKey; got != "UFJJVkFURSBLRVk=" {
+		t.Errorf("hosts[0].privateKey = %q, want the file content without the trailing newline", got)
+	}
+	if len(cluster.Spec.Hooks) != 1 || cluster.Spec.Hooks[0].Command != "echo ${KUBEXM_EVENT_NAME}" {
+		t.Errorf("escaped reference not kept literally: %+v", cluster.Spec.Hooks)
+	}
+
+	// Overlays from another directory keep resolving files relative to their own file.
+	overlay := writeFile(t, t.TempDir(), "prod.yaml", "spec:\n  global:\n    user: admin\n")
+	if _, err := ParseFromFile(path + string(filepath.ListSeparator) + overlay); err != nil {
+		t.Errorf("ParseFromFile() with overlay error = %v", err)
+	}
+}
+
+func TestParseFromFile_UnresolvedReferences(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "cluster.yaml", secretClusterYAML)
+	t.Setenv("KUBEXM_TEST_CLUSTER", "from-env")
+
+	_, err := ParseFromFile(path)
+	if err == nil || !strings.Contains(err.Error(), "spec.global.password: environment variable KUBEXM_TEST_PASSWORD is not set") {
+		t.Errorf("error = %v, want the unset variable reported with its field", err)
+	}
+
+	t.Setenv("KUBEXM_TEST_PASSWORD", "s3cret")
+	_, err = ParseFromFile(path)
+	if err == nil || !strings.Contains(err.Error(), "spec.hosts[0].privateKey: failed to read value from file") {
+		t.Errorf("error = %v, want the missing file reported with its field", err)
+	}
+}
+
+func TestRedactReferences(t *testing.T) {
+	t.Setenv("KUBEXM_TEST_PASSWORD", "s3cret")
+	data, err := RedactReferences([]byte(secretClusterYAML))
+	if err != nil {
+		t.Fatalf("RedactReferences() error = %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{"<from env KUBEXM_TEST_PASSWORD>", "<from file secrets/id_rsa>", "${KUBEXM_TEST_CLUSTER}"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("redacted config does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("redacted config contains the secret:\n%s", out)
+	}
+}
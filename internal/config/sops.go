@@ -0,0 +1,51 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sopsCommand is the sops binary used to decrypt SOPS-encrypted configuration files. The keys are
+// found the way sops finds them: SOPS_AGE_KEY_FILE or ~/.config/sops/age/keys.txt for age, the gpg
+// agent for PGP and the cloud SDK credentials for KMS.
+var sopsCommand = "sops"
+
+// IsSOPSEncrypted reports whether a document of the YAML or JSON data carries the top-level "sops"
+// metadata that sops adds when it encrypts a file.
+func IsSOPSEncrypted(data []byte) bool {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc struct {
+			SOPS map[string]interface{} `yaml:"sops"`
+		}
+		if err := decoder.Decode(&doc); err != nil {
+			return false
+		}
+		if _, ok := doc.SOPS["mac"]; ok {
+			return true
+		}
+	}
+}
+
+// decryptIfEncrypted returns data, read from filePath, decrypted with sops if it is SOPS-encrypted.
+// format is "yaml" or "json".
+func decryptIfEncrypted(filePath string, data []byte, format string) ([]byte, error) {
+	if !IsSOPSEncrypted(data) {
+		return data, nil
+	}
+	if _, err := exec.LookPath(sopsCommand); err != nil {
+		return nil, fmt.Errorf("%s is encrypted with SOPS but the sops binary was not found in PATH: %w", filePath, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(sopsCommand, "--decrypt", "--input-type", format, "--output-type", format, filePath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s with sops: %w: %s", filePath, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const encryptedClusterYAML = `apiVersion: kubexm.io/v1alpha1
+kind: Cluster
+spec:
+  global:
+    password: ENC[AES256_GCM,data:c2VjcmV0,iv:aXY=,tag:dGFn,type:str]
+sops:
+  age:
+    - recipient: age1qyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqs3290gq
+  mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+  version: 3.8.1
+`
+
+// fakeSOPS installs a sops stand-in that prints plain and records its arguments.
+func fakeSOPS(t *testing.T, plain string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, dir, "plain.yaml", plain)
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat " + filepath.Join(dir, "plain.yaml") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "sops"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := sopsCommand
+	sopsCommand = filepath.Join(dir, "sops")
+	t.Cleanup(func() { sopsCommand = old })
+	return filepath.Join(dir, "args")
+}
+
+func TestIsSOPSEncrypted(t *testing.T) {
+	if !IsSOPSEncrypted([]byte(encryptedClusterYAML)) {
+		t.Error("expected the sops metadata to be detected")
+	}
+	if IsSOPSEncrypted([]byte(baseClusterYAML)) {
+		t.Error("plain configuration detected as encrypted")
+	}
+	if IsSOPSEncrypted([]byte("spec:\n  sops: {mac: x}\n")) {
+		t.Error("a nested sops key is not sops metadata")
+	}
+}
+
+func TestParseFromFile_DecryptsSOPS(t *testing.T) {
+	argsFile := fakeSOPS(t, baseClusterYAML)
+	path := writeFile(t, t.TempDir(), "cluster.enc.yaml", encryptedClusterYAML)
+
+	cluster, err := ParseFromFile(path)
+	if err != nil {
+		t.Fatalf("ParseFromFile() error = %v", err)
+	}
+	if cluster.Spec.Global.Password != "secret" || cluster.Name != "base" {
+		t.Errorf("decrypted configuration not used: name=%s", cluster.Name)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if got := strings.TrimSpace(string(args)); got != "--decrypt --input-type yaml --output-type yaml "+path {
+		t.Errorf("sops arguments = %q", got)
+	}
+
+	// Encrypted files can be a base or an overlay.
+	overlay := writeFile(t, t.TempDir(), "prod.yaml", "metadata:\n  name: prod\n")
+	cluster, err = ParseFromFile(path + string(filepath.ListSeparator) + overlay)
+	if err != nil {
+		t.Fatalf("ParseFromFile() with overlay error = %v", err)
+	}
+	if cluster.Name != "prod" || cluster.Spec.Global.Password != "secret" {
+		t.Errorf("overlay not merged onto the decrypted base: name=%s", cluster.Name)
+	}
+}
+
+func TestParseFromFile_SOPSMissing(t *testing.T) {
+	old := sopsCommand
+	sopsCommand = filepath.Join(t.TempDir(), "no-sops")
+	t.Cleanup(func() { sopsCommand = old })
+	path := writeFile(t, t.TempDir(), "cluster.yaml", encryptedClusterYAML)
+
+	if _, err := ParseFromFile(path); err == nil || !strings.Contains(err.Error(), "sops binary was not found") {
+		t.Errorf("error = %v, want the missing sops binary reported", err)
+	}
+}
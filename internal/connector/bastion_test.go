@@ -0,0 +1,196 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestServerConfig(t *testing.T, password string) *ssh.ServerConfig {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) != password {
+				return nil, fmt.Errorf("wrong password for %s", c.User())
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+	return config
+}
+
+// startTestSFTPServer serves the sftp subsystem from an in-memory filesystem.
+func startTestSFTPServer(t *testing.T, password string) *net.TCPAddr {
+	t.Helper()
+	config := newTestServerConfig(t, password)
+	handlers := sftp.InMemHandler()
+	return startTestListener(t, func(conn net.Conn) {
+		sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		defer sconn.Close()
+		go ssh.DiscardRequests(reqs)
+		for newCh := range chans {
+			if newCh.ChannelType() != "session" {
+				newCh.Reject(ssh.UnknownChannelType, "only sessions are supported")
+				continue
+			}
+			ch, chReqs, err := newCh.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer ch.Close()
+				for req := range chReqs {
+					var payload struct{ Name string }
+					_ = ssh.Unmarshal(req.Payload, &payload)
+					if req.Type != "subsystem" || payload.Name != "sftp" {
+						req.Reply(false, nil)
+						continue
+					}
+					req.Reply(true, nil)
+					_ = sftp.NewRequestServer(ch, handlers).Serve()
+					return
+				}
+			}()
+		}
+	})
+}
+
+// startTestBastion forwards direct-tcpip channels to their destination, like an OpenSSH jump
+// host, and counts the forwarded connections.
+func startTestBastion(t *testing.T, password string, forwarded *atomic.Int32) *net.TCPAddr {
+	t.Helper()
+	config := newTestServerConfig(t, password)
+	return startTestListener(t, func(conn net.Conn) {
+		sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		defer sconn.Close()
+		go ssh.DiscardRequests(reqs)
+		for newCh := range chans {
+			if newCh.ChannelType() != "direct-tcpip" {
+				newCh.Reject(ssh.Prohibited, "only port forwarding is allowed")
+				continue
+			}
+			var dest struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newCh.ExtraData(), &dest); err != nil {
+				newCh.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			target, err := net.Dial("tcp", net.JoinHostPort(dest.Host, fmt.Sprint(dest.Port)))
+			if err != nil {
+				newCh.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			ch, chReqs, err := newCh.Accept()
+			if err != nil {
+				target.Close()
+				continue
+			}
+			forwarded.Add(1)
+			go ssh.DiscardRequests(chReqs)
+			go func() {
+				defer ch.Close()
+				defer target.Close()
+				go io.Copy(target, ch)
+				io.Copy(ch, target)
+			}()
+		}
+	})
+}
+
+func TestSSHConnector_ConnectViaBastion(t *testing.T) {
+	var forwarded atomic.Int32
+	target := startTestSFTPServer(t, "target-secret")
+	bastion := startTestBastion(t, "jump-secret", &forwarded)
+
+	cfg := ConnectionCfg{
+		Host:     target.IP.String(),
+		Port:     target.Port,
+		User:     "ops",
+		Password: "target-secret",
+		Timeout:  5 * time.Second,
+		BastionCfg: &BastionCfg{
+			Host:     bastion.IP.String(),
+			Port:     bastion.Port,
+			User:     "jump",
+			Password: "jump-secret",
+			Timeout:  5 * time.Second,
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	cfg.BastionCfg.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s := NewSSHConnector(nil)
+	if err := s.Connect(ctx, cfg); err != nil {
+		t.Fatalf("Connect via bastion failed: %v", err)
+	}
+	defer s.Close()
+	if forwarded.Load() != 1 {
+		t.Fatalf("expected the connection to be forwarded by the bastion once, got %d", forwarded.Load())
+	}
+
+	content := []byte("tunneled through the bastion\n")
+	if err := s.writeFileViaSFTP(ctx, bytes.NewReader(content), "/etc/kubexm/bastion.txt", ""); err != nil {
+		t.Fatalf("upload via bastion failed: %v", err)
+	}
+	got, err := s.ReadFile(ctx, "/etc/kubexm/bastion.txt")
+	if err != nil {
+		t.Fatalf("fetch via bastion failed: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("expected %q, got %q", content, got)
+	}
+}
+
+func TestSSHConnector_ConnectViaBastionAuthFailure(t *testing.T) {
+	var forwarded atomic.Int32
+	target := startTestSFTPServer(t, "target-secret")
+	bastion := startTestBastion(t, "jump-secret", &forwarded)
+
+	s := NewSSHConnector(nil)
+	err := s.Connect(context.Background(), ConnectionCfg{
+		Host:       target.IP.String(),
+		Port:       target.Port,
+		User:       "ops",
+		Password:   "target-secret",
+		Timeout:    5 * time.Second,
+		BastionCfg: &BastionCfg{Host: bastion.IP.String(), Port: bastion.Port, User: "jump", Password: "wrong", Timeout: 5 * time.Second},
+	})
+	connErr, ok := err.(*ConnectionError)
+	if !ok || connErr.Host != bastion.IP.String() {
+		t.Fatalf("expected a ConnectionError for the bastion, got %v", err)
+	}
+	if forwarded.Load() != 0 {
+		t.Errorf("expected nothing to be forwarded, got %d", forwarded.Load())
+	}
+}
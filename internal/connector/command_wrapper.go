@@ -0,0 +1,50 @@
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// CommandWrapper wraps every command executed on a host, e.g. to run it through firejail or a
+// site-specific sudo wrapper. The template sees the command as {{.cmd}} and, for wrappers that
+// need a single shell word such as `sh -c`, as {{.quoted}}:
+//
+//	myvault exec -- {{.cmd}}
+//	firejail --quiet sh -c {{.quoted}}
+//
+// A nil CommandWrapper leaves commands unchanged.
+type CommandWrapper struct {
+	text string
+	tmpl *template.Template
+}
+
+// NewCommandWrapper parses a wrapper template. An empty template yields the identity wrapper (nil).
+func NewCommandWrapper(text string) (*CommandWrapper, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("commandWrapper").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command wrapper %q: %w", text, err)
+	}
+	w := &CommandWrapper{text: text, tmpl: tmpl}
+	if _, err := w.Wrap("true"); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Wrap returns cmd wrapped by the template.
+func (w *CommandWrapper) Wrap(cmd string) (string, error) {
+	if w == nil {
+		return cmd, nil
+	}
+	var buf bytes.Buffer
+	data := map[string]string{"cmd": cmd, "quoted": ShellEscape(cmd)}
+	if err := w.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to apply command wrapper %q: %w", w.text, err)
+	}
+	return buf.String(), nil
+}
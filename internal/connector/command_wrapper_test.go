@@ -0,0 +1,82 @@
+package connector
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCommandWrapper_Wrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		cmd      string
+		expected string
+	}{
+		{
+			name:     "identity default",
+			template: "",
+			cmd:      "systemctl restart containerd && echo ok",
+			expected: "systemctl restart containerd && echo ok",
+		},
+		{
+			name:     "exec style wrapper",
+			template: "myvault exec -- {{.cmd}}",
+			cmd:      "cat /etc/os-release",
+			expected: "myvault exec -- cat /etc/os-release",
+		},
+		{
+			name:     "shell quoted wrapper",
+			template: "firejail --quiet sh -c {{.quoted}}",
+			cmd:      "echo 'a' && ls",
+			expected: `firejail --quiet sh -c 'echo '\''a'\'' && ls'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewCommandWrapper(tt.template)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := w.Wrap(tt.cmd)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommandWrapper_Invalid(t *testing.T) {
+	for _, tmpl := range []string{"wrap {{.cmd", "wrap {{.command}}"} {
+		if _, err := NewCommandWrapper(tmpl); err == nil {
+			t.Errorf("expected %q to be rejected", tmpl)
+		}
+	}
+}
+
+func TestLocalConnector_CommandWrapper(t *testing.T) {
+	ctx := context.Background()
+	conn, err := NewLocalConnector()
+	if err != nil {
+		t.Fatalf("Failed to create LocalConnector: %v", err)
+	}
+	if err := conn.Connect(ctx, ConnectionCfg{CommandWrapper: "echo wrapped: {{.quoted}}"}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	stdout, _, err := conn.Exec(ctx, "uname -s", nil)
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(stdout)); got != "wrapped: uname -s" {
+		t.Errorf("expected the wrapper to receive the command, got %q", got)
+	}
+
+	if err := conn.Connect(ctx, ConnectionCfg{CommandWrapper: "wrap {{.cmd"}); err == nil {
+		t.Error("expected Connect to reject an invalid command wrapper")
+	}
+}
@@ -1,6 +1,9 @@
 package connector
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type CommandError struct {
 	Cmd        string
@@ -39,3 +42,15 @@ func (e *ConnectionError) Error() string {
 func (e *ConnectionError) Unwrap() error {
 	return e.Err
 }
+
+// ConnectionStalledError is returned when a host stops answering SSH keepalive requests.
+// The underlying connection has already been closed when this error is reported.
+type ConnectionStalledError struct {
+	Host     string
+	Interval time.Duration
+	CountMax int
+}
+
+func (e *ConnectionStalledError) Error() string {
+	return fmt.Sprintf("connection to host %s stalled: %d keepalive(s) sent every %v went unanswered", e.Host, e.CountMax, e.Interval)
+}
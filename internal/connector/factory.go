@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -30,12 +32,20 @@ func (f *defaultFactory) NewLocalConnector() (Connector, error) {
 }
 
 func (f *defaultFactory) NewConnectorForHost(host Host, pool *ConnectionPool) (Connector, error) {
+	// A local host is the machine running kubexm itself, so its commands need no SSH server.
+	if host.GetHostSpec().Type == string(common.HostTypeLocal) {
+		return f.NewLocalConnector()
+	}
+
 	address := host.GetAddress()
 	if strings.EqualFold(address, "localhost") || address == "127.0.0.1" {
 		return nil, fmt.Errorf("localhost/127.0.0.1 is not allowed for host address; use a routable IP")
 	}
 
 	// Allow nil pool for direct connections
+	if host.GetHostSpec().OS == common.OSWindows {
+		return NewWindowsConnector(pool), nil
+	}
 	return f.NewSSHConnector(pool), nil
 }
 
@@ -51,12 +61,32 @@ func (f *defaultFactory) NewConnectionCfg(host Host, globalTimeout time.Duration
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 	}
 
-	if globalTimeout > 0 {
+	// A per-host timeout takes precedence over the cluster-wide one.
+	if connCfg.Timeout <= 0 {
 		connCfg.Timeout = globalTimeout
-	} else if connCfg.Timeout <= 0 {
+	}
+	if connCfg.Timeout <= 0 {
 		connCfg.Timeout = 30 * time.Second
 	}
 
+	connCfg.ServerAliveInterval = common.DefaultSSHServerAliveInterval
+	connCfg.ServerAliveCountMax = common.DefaultSSHServerAliveCountMax
+	connCfg.MaxSessions = common.DefaultSSHMaxSessions
+	if sshSpec := host.GetHostSpec().SSH; sshSpec != nil {
+		if sshSpec.ConnectTimeout > 0 {
+			connCfg.ConnectTimeout = time.Duration(sshSpec.ConnectTimeout) * time.Second
+		}
+		if sshSpec.ServerAliveInterval > 0 {
+			connCfg.ServerAliveInterval = time.Duration(sshSpec.ServerAliveInterval) * time.Second
+		}
+		if sshSpec.ServerAliveCountMax > 0 {
+			connCfg.ServerAliveCountMax = sshSpec.ServerAliveCountMax
+		}
+		if sshSpec.MaxSessions > 0 {
+			connCfg.MaxSessions = sshSpec.MaxSessions
+		}
+	}
+
 	if host.GetPrivateKey() != "" {
 		decodedKey, err := base64.StdEncoding.DecodeString(host.GetPrivateKey())
 		if err != nil {
@@ -65,7 +95,40 @@ func (f *defaultFactory) NewConnectionCfg(host Host, globalTimeout time.Duration
 		connCfg.PrivateKey = decodedKey
 	}
 
+	if bastion := host.GetHostSpec().Bastion; bastion != nil {
+		bastionCfg, err := newBastionCfg(bastion, connCfg)
+		if err != nil {
+			return ConnectionCfg{}, fmt.Errorf("host %s: %w", host.GetName(), err)
+		}
+		connCfg.BastionCfg = bastionCfg
+	}
+
 	return connCfg, nil
 }
 
+// newBastionCfg builds the jump host settings of a host. The bastion shares the host's timeout and
+// host key policy.
+func newBastionCfg(spec *v1alpha1.BastionSpec, target ConnectionCfg) (*BastionCfg, error) {
+	cfg := &BastionCfg{
+		Host:            spec.Address,
+		Port:            spec.Port,
+		User:            spec.User,
+		Password:        spec.Password,
+		PrivateKeyPath:  spec.PrivateKeyPath,
+		Timeout:         connectTimeoutFor(target),
+		HostKeyCallback: target.HostKeyCallback,
+	}
+	if cfg.Port == 0 {
+		cfg.Port = common.DefaultPort
+	}
+	if spec.PrivateKey != "" {
+		decodedKey, err := base64.StdEncoding.DecodeString(spec.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 private key of bastion %s: %w", spec.Address, err)
+		}
+		cfg.PrivateKey = decodedKey
+	}
+	return cfg, nil
+}
+
 var _ Factory = (*defaultFactory)(nil)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
 )
 
 // MockHost implements Host interface for testing
@@ -18,6 +19,10 @@ type MockHost struct {
 	PrivateKey     string
 	PrivateKeyPath string
 	Timeout        int64
+	SSH            *v1alpha1.HostSSHSpec
+	Bastion        *v1alpha1.BastionSpec
+	OS             string
+	Type           string
 }
 
 func (m *MockHost) GetName() string                 { return m.Name }
@@ -45,7 +50,9 @@ func (m *MockHost) SetTimeout(timeout int64)        { m.Timeout = timeout }
 func (m *MockHost) GetRoles() []string              { return nil }
 func (m *MockHost) SetRoles(roles []string)         {}
 func (m *MockHost) IsRole(role string) bool         { return false }
-func (m *MockHost) GetHostSpec() v1alpha1.HostSpec  { return v1alpha1.HostSpec{} }
+func (m *MockHost) GetHostSpec() v1alpha1.HostSpec {
+	return v1alpha1.HostSpec{SSH: m.SSH, Bastion: m.Bastion, OS: m.OS, Type: m.Type}
+}
 
 func TestDefaultFactory_NewConnectorForHost(t *testing.T) {
 	f := NewFactory()
@@ -68,6 +75,28 @@ func TestDefaultFactory_NewConnectorForHost(t *testing.T) {
 			t.Errorf("Expected *SSHConnector, got %T", conn)
 		}
 	})
+
+	t.Run("LocalType", func(t *testing.T) {
+		host := &MockHost{Address: "192.168.1.100", Type: string(common.HostTypeLocal)}
+		conn, err := f.NewConnectorForHost(host, nil)
+		if err != nil {
+			t.Fatalf("Failed to create local connector: %v", err)
+		}
+		if _, ok := conn.(*LocalConnector); !ok {
+			t.Errorf("Expected *LocalConnector, got %T", conn)
+		}
+	})
+
+	t.Run("WindowsHost", func(t *testing.T) {
+		host := &MockHost{Address: "192.168.1.101", OS: common.OSWindows}
+		conn, err := f.NewConnectorForHost(host, nil)
+		if err != nil {
+			t.Fatalf("Failed to create windows connector: %v", err)
+		}
+		if _, ok := conn.(*WindowsConnector); !ok {
+			t.Errorf("Expected *WindowsConnector, got %T", conn)
+		}
+	})
 }
 
 func TestDefaultFactory_NewConnectionCfg(t *testing.T) {
@@ -96,6 +125,24 @@ func TestDefaultFactory_NewConnectionCfg(t *testing.T) {
 		}
 	})
 
+	t.Run("HostTimeoutOverridesGlobal", func(t *testing.T) {
+		cfg, err := f.NewConnectionCfg(&MockHost{Address: "192.168.1.100", Timeout: 10}, 60*time.Second)
+		if err != nil {
+			t.Fatalf("NewConnectionCfg failed: %v", err)
+		}
+		if cfg.Timeout != 10*time.Second {
+			t.Errorf("Expected per-host timeout 10s, got %v", cfg.Timeout)
+		}
+
+		cfg, err = f.NewConnectionCfg(&MockHost{Address: "192.168.1.100"}, 60*time.Second)
+		if err != nil {
+			t.Fatalf("NewConnectionCfg failed: %v", err)
+		}
+		if cfg.Timeout != 60*time.Second {
+			t.Errorf("Expected global timeout 60s, got %v", cfg.Timeout)
+		}
+	})
+
 	t.Run("PrivateKeyBase64", func(t *testing.T) {
 		keyContent := "test-private-key"
 		encodedKey := base64.StdEncoding.EncodeToString([]byte(keyContent))
@@ -110,4 +157,90 @@ func TestDefaultFactory_NewConnectionCfg(t *testing.T) {
 			t.Errorf("Expected private key %s, got %s", keyContent, string(cfg.PrivateKey))
 		}
 	})
+	t.Run("KeepaliveDefaults", func(t *testing.T) {
+		cfg, err := f.NewConnectionCfg(&MockHost{Address: "192.168.1.100"}, 0)
+		if err != nil {
+			t.Fatalf("NewConnectionCfg failed: %v", err)
+		}
+		if cfg.ServerAliveInterval != common.DefaultSSHServerAliveInterval {
+			t.Errorf("Expected default keepalive interval %v, got %v", common.DefaultSSHServerAliveInterval, cfg.ServerAliveInterval)
+		}
+		if cfg.ServerAliveCountMax != common.DefaultSSHServerAliveCountMax {
+			t.Errorf("Expected default keepalive count %d, got %d", common.DefaultSSHServerAliveCountMax, cfg.ServerAliveCountMax)
+		}
+		if cfg.ConnectTimeout != 0 {
+			t.Errorf("Expected no explicit connect timeout, got %v", cfg.ConnectTimeout)
+		}
+		if cfg.MaxSessions != common.DefaultSSHMaxSessions {
+			t.Errorf("Expected default max sessions %d, got %d", common.DefaultSSHMaxSessions, cfg.MaxSessions)
+		}
+	})
+
+	t.Run("PerHostSSHSettings", func(t *testing.T) {
+		host := &MockHost{
+			Address: "192.168.1.100",
+			SSH: &v1alpha1.HostSSHSpec{
+				ConnectTimeout:      5,
+				ServerAliveInterval: 10,
+				ServerAliveCountMax: 6,
+				MaxSessions:         4,
+			},
+		}
+		cfg, err := f.NewConnectionCfg(host, 0)
+		if err != nil {
+			t.Fatalf("NewConnectionCfg failed: %v", err)
+		}
+		if cfg.ConnectTimeout != 5*time.Second {
+			t.Errorf("Expected connect timeout 5s, got %v", cfg.ConnectTimeout)
+		}
+		if cfg.ServerAliveInterval != 10*time.Second {
+			t.Errorf("Expected keepalive interval 10s, got %v", cfg.ServerAliveInterval)
+		}
+		if cfg.ServerAliveCountMax != 6 {
+			t.Errorf("Expected keepalive count 6, got %d", cfg.ServerAliveCountMax)
+		}
+		if cfg.MaxSessions != 4 {
+			t.Errorf("Expected max sessions 4, got %d", cfg.MaxSessions)
+		}
+		if got := connectTimeoutFor(cfg); got != 5*time.Second {
+			t.Errorf("Expected dial timeout 5s, got %v", got)
+		}
+	})
+
+	t.Run("Bastion", func(t *testing.T) {
+		keyContent := "bastion-private-key"
+		host := &MockHost{
+			Address:  "10.0.0.10",
+			Port:     22,
+			User:     "ops",
+			Password: "password",
+			Bastion: &v1alpha1.BastionSpec{
+				Address:    "jump.example.com",
+				User:       "jump",
+				PrivateKey: base64.StdEncoding.EncodeToString([]byte(keyContent)),
+			},
+		}
+		cfg, err := f.NewConnectionCfg(host, 20*time.Second)
+		if err != nil {
+			t.Fatalf("NewConnectionCfg failed: %v", err)
+		}
+		if cfg.BastionCfg == nil {
+			t.Fatal("Expected a bastion config")
+		}
+		b := cfg.BastionCfg
+		if b.Host != "jump.example.com" || b.Port != 22 || b.User != "jump" {
+			t.Errorf("Unexpected bastion endpoint %s@%s:%d", b.User, b.Host, b.Port)
+		}
+		if string(b.PrivateKey) != keyContent {
+			t.Errorf("Expected decoded bastion key %s, got %s", keyContent, string(b.PrivateKey))
+		}
+		if b.Timeout != 20*time.Second {
+			t.Errorf("Expected bastion timeout 20s, got %v", b.Timeout)
+		}
+
+		host.Bastion.PrivateKey = "%%%"
+		if _, err := f.NewConnectionCfg(host, 0); err == nil {
+			t.Error("Expected an invalid bastion key to be rejected")
+		}
+	})
 }
@@ -0,0 +1,196 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const (
+	defaultPtyTerm   = "xterm-256color"
+	defaultPtyWidth  = 80
+	defaultPtyHeight = 24
+)
+
+// interactiveIO resolves the streams of opts, defaulting to the local terminal.
+func interactiveIO(opts InteractiveOptions) (io.Reader, io.Writer, io.Writer) {
+	stdin, stdout, stderr := opts.Stdin, opts.Stdout, opts.Stderr
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return stdin, stdout, stderr
+}
+
+// interactiveCommand builds the command line for cmd; an empty cmd starts a login shell.
+func interactiveCommand(cmd string, opts InteractiveOptions) string {
+	finalCmd := cmd
+	if opts.Dir != "" {
+		if finalCmd == "" {
+			finalCmd = "exec ${SHELL:-/bin/sh} -l"
+		}
+		finalCmd = fmt.Sprintf("cd %s && %s", ShellEscape(opts.Dir), finalCmd)
+	}
+	if opts.Sudo {
+		if finalCmd == "" {
+			return "sudo -i"
+		}
+		return "sudo -E -- /bin/sh -c " + ShellEscape(finalCmd)
+	}
+	return finalCmd
+}
+
+// makeLocalTerminalRaw puts stdin into raw mode if it is a terminal, so keystrokes reach the remote
+// PTY unprocessed. It returns the terminal size and a function restoring the previous state.
+func makeLocalTerminalRaw(stdin io.Reader) (width, height int, restore func()) {
+	width, height, restore = defaultPtyWidth, defaultPtyHeight, func() {}
+	f, ok := stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return
+	}
+	if w, h, err := term.GetSize(int(f.Fd())); err == nil {
+		width, height = w, h
+	}
+	if state, err := term.MakeRaw(int(f.Fd())); err == nil {
+		restore = func() { _ = term.Restore(int(f.Fd()), state) }
+	}
+	return
+}
+
+// ExecInteractive runs cmd on a PTY allocated on the remote host and proxies the local
+// stdin/stdout/stderr to it until the command exits. With sudo, any password prompt is
+// answered by the user at the terminal.
+func (s *SSHConnector) ExecInteractive(ctx context.Context, cmd string, opts *InteractiveOptions) error {
+	if stallErr := s.stalled(); stallErr != nil {
+		return stallErr
+	}
+	if !s.IsConnected() {
+		return &ConnectionError{Host: s.connCfg.Host, Err: fmt.Errorf("not connected")}
+	}
+	effectiveOptions := InteractiveOptions{}
+	if opts != nil {
+		effectiveOptions = *opts
+	}
+
+	session, release, err := s.newSession(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer release()
+	defer session.Close()
+
+	for _, envVar := range effectiveOptions.Env {
+		parts := strings.SplitN(envVar, "=", 2)
+		if len(parts) == 2 {
+			_ = session.Setenv(parts[0], parts[1])
+		}
+	}
+
+	stdin, stdout, stderr := interactiveIO(effectiveOptions)
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	width, height, restore := makeLocalTerminalRaw(stdin)
+	defer restore()
+
+	termName := effectiveOptions.Term
+	if termName == "" {
+		termName = os.Getenv("TERM")
+	}
+	if termName == "" {
+		termName = defaultPtyTerm
+	}
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := session.RequestPty(termName, height, width, modes); err != nil {
+		return fmt.Errorf("failed to request PTY on host %s: %w", s.connCfg.Host, err)
+	}
+
+	finalCmd := interactiveCommand(cmd, effectiveOptions)
+	if finalCmd == "" {
+		err = session.Shell()
+	} else {
+		if finalCmd, err = s.cmdWrapper.Wrap(finalCmd); err != nil {
+			return err
+		}
+		err = session.Start(finalCmd)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start interactive session on host %s: %w", s.connCfg.Host, err)
+	}
+
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- session.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = session.Signal(ssh.SIGKILL)
+		select {
+		case <-doneCh:
+		case <-time.After(100 * time.Millisecond):
+		}
+		return ctx.Err()
+	case err = <-doneCh:
+	}
+	if err == nil {
+		return nil
+	}
+	if stallErr := s.stalled(); stallErr != nil {
+		return stallErr
+	}
+	exitCode := -1
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		exitCode = exitErr.ExitStatus()
+	}
+	return &CommandError{Cmd: cmd, ExitCode: exitCode, Underlying: err}
+}
+
+// ExecInteractive runs cmd attached to the local terminal. The process inherits the terminal
+// directly, so no PTY needs to be allocated.
+func (l *LocalConnector) ExecInteractive(ctx context.Context, cmd string, opts *InteractiveOptions) error {
+	effectiveOptions := InteractiveOptions{}
+	if opts != nil {
+		effectiveOptions = *opts
+	}
+
+	finalCmd := interactiveCommand(cmd, effectiveOptions)
+	if finalCmd == "" {
+		finalCmd = "exec ${SHELL:-/bin/sh} -l"
+	}
+	finalCmd, err := l.cmdWrapper.Wrap(finalCmd)
+	if err != nil {
+		return err
+	}
+
+	actualCmd := exec.CommandContext(ctx, "/bin/sh", "-c", finalCmd)
+	if len(effectiveOptions.Env) > 0 {
+		actualCmd.Env = append(os.Environ(), effectiveOptions.Env...)
+	}
+	actualCmd.Stdin, actualCmd.Stdout, actualCmd.Stderr = interactiveIO(effectiveOptions)
+	if err := actualCmd.Run(); err != nil {
+		exitCode := -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		return &CommandError{Cmd: cmd, ExitCode: exitCode, Underlying: err}
+	}
+	return nil
+}
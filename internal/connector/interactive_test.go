@@ -0,0 +1,181 @@
+package connector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ptySessionRecord captures what a client requested on a session of the test server.
+type ptySessionRecord struct {
+	mu      sync.Mutex
+	ptyTerm string
+	ptyCols uint32
+	ptyRows uint32
+	command string
+	shell   bool
+}
+
+// startTestPtySSHServer serves sessions that record PTY and exec requests, then echo one line of
+// input back to stdout prefixed with "echo: " and exit with status 0.
+func startTestPtySSHServer(t *testing.T, rec *ptySessionRecord) *net.TCPAddr {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	return startTestListener(t, func(conn net.Conn) {
+		sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		defer sconn.Close()
+		go ssh.DiscardRequests(reqs)
+		for newCh := range chans {
+			if newCh.ChannelType() != "session" {
+				newCh.Reject(ssh.UnknownChannelType, "only sessions")
+				continue
+			}
+			ch, chReqs, err := newCh.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestPtySession(ch, chReqs, rec)
+		}
+	})
+}
+
+func serveTestPtySession(ch ssh.Channel, reqs <-chan *ssh.Request, rec *ptySessionRecord) {
+	defer ch.Close()
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req":
+			var pty struct {
+				Term          string
+				Columns, Rows uint32
+				Width, Height uint32
+				Modes         string
+			}
+			if err := ssh.Unmarshal(req.Payload, &pty); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			rec.mu.Lock()
+			rec.ptyTerm, rec.ptyCols, rec.ptyRows = pty.Term, pty.Columns, pty.Rows
+			rec.mu.Unlock()
+			req.Reply(true, nil)
+		case "exec", "shell":
+			rec.mu.Lock()
+			if req.Type == "exec" {
+				var payload struct{ Command string }
+				_ = ssh.Unmarshal(req.Payload, &payload)
+				rec.command = payload.Command
+			} else {
+				rec.shell = true
+			}
+			rec.mu.Unlock()
+			req.Reply(true, nil)
+
+			line, _ := bufio.NewReader(ch).ReadString('\n')
+			ch.Write([]byte("echo: " + line))
+			ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func connectTestPtyServer(t *testing.T, addr *net.TCPAddr) *SSHConnector {
+	t.Helper()
+	s := NewSSHConnector(nil)
+	err := s.Connect(context.Background(), ConnectionCfg{
+		Host:            addr.IP.String(),
+		Port:            addr.Port,
+		User:            "root",
+		Password:        "secret",
+		Timeout:         5 * time.Second,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test server: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSSHConnector_ExecInteractive(t *testing.T) {
+	tests := []struct {
+		name            string
+		cmd             string
+		opts            InteractiveOptions
+		expectedCommand string
+		expectedShell   bool
+	}{
+		{name: "command", cmd: "vi /etc/hosts", expectedCommand: "vi /etc/hosts"},
+		{name: "login shell", expectedShell: true},
+		{name: "sudo command", cmd: "top", opts: InteractiveOptions{Sudo: true}, expectedCommand: "sudo -E -- /bin/sh -c 'top'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &ptySessionRecord{}
+			s := connectTestPtyServer(t, startTestPtySSHServer(t, rec))
+
+			var stdout bytes.Buffer
+			opts := tt.opts
+			opts.Term = "xterm-test"
+			opts.Stdin = strings.NewReader("hello\n")
+			opts.Stdout = &stdout
+			opts.Stderr = &bytes.Buffer{}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := s.ExecInteractive(ctx, tt.cmd, &opts); err != nil {
+				t.Fatalf("ExecInteractive failed: %v", err)
+			}
+
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			if rec.ptyTerm != "xterm-test" || rec.ptyCols != defaultPtyWidth || rec.ptyRows != defaultPtyHeight {
+				t.Errorf("expected a %dx%d xterm-test PTY to be requested, got %q %dx%d",
+					defaultPtyWidth, defaultPtyHeight, rec.ptyTerm, rec.ptyCols, rec.ptyRows)
+			}
+			if rec.command != tt.expectedCommand || rec.shell != tt.expectedShell {
+				t.Errorf("expected command %q (shell=%v), got %q (shell=%v)", tt.expectedCommand, tt.expectedShell, rec.command, rec.shell)
+			}
+			if got := stdout.String(); got != "echo: hello\n" {
+				t.Errorf("expected stdin to be proxied and echoed back, got %q", got)
+			}
+		})
+	}
+}
+
+func TestSSHConnector_ExecInteractiveNotConnected(t *testing.T) {
+	s := NewSSHConnector(nil)
+	s.connCfg = ConnectionCfg{Host: "192.168.1.100"}
+	err := s.ExecInteractive(context.Background(), "true", nil)
+	if _, ok := err.(*ConnectionError); !ok {
+		t.Fatalf("expected *ConnectionError, got %v", err)
+	}
+}
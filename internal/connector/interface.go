@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"io"
 	"io/fs"
 	"time"
 
@@ -44,6 +45,20 @@ type ConnectionCfg struct {
 	BastionCfg      *BastionCfg
 	ProxyCfg        *ProxyCfg
 	HostKeyCallback ssh.HostKeyCallback `json:"-" yaml:"-"`
+
+	// ConnectTimeout bounds the TCP dial and SSH handshake. Timeout is used when it is zero.
+	ConnectTimeout time.Duration
+	// ServerAliveInterval is the interval between keepalive requests. Zero disables keepalives.
+	ServerAliveInterval time.Duration
+	// ServerAliveCountMax is the number of unanswered keepalives after which the connection is
+	// closed and reported as stalled.
+	ServerAliveCountMax int
+	// MaxSessions bounds the sessions open concurrently on the connection; further commands wait
+	// for a free slot. Zero uses the pool default. The SFTP subsystem is not counted.
+	MaxSessions int
+	// CommandWrapper is a template applied to every executed command, see CommandWrapper.
+	// Empty leaves commands unchanged.
+	CommandWrapper string
 }
 
 type FileStat struct {
@@ -58,6 +73,11 @@ type FileStat struct {
 type Connector interface {
 	Connect(ctx context.Context, cfg ConnectionCfg) error
 	Exec(ctx context.Context, cmd string, opts *ExecOptions) (stdout, stderr []byte, err error)
+	// ExecInteractive runs cmd on a PTY wired to the local terminal, or a login shell if cmd is empty.
+	ExecInteractive(ctx context.Context, cmd string, opts *InteractiveOptions) error
+	// ExecStream starts cmd and returns its stdout and stderr as they are produced. Both readers must
+	// be read to EOF or closed; wait blocks until the command exits.
+	ExecStream(ctx context.Context, cmd string, opts *ExecOptions) (stdout, stderr io.ReadCloser, wait func() error, err error)
 	Upload(ctx context.Context, localPath, remotePath string, opts *FileTransferOptions) error
 	Download(ctx context.Context, remotePath, localPath string, opts *FileTransferOptions) error
 	Fetch(ctx context.Context, remotePath, localPath string, opts *FileTransferOptions) error
@@ -0,0 +1,88 @@
+package connector
+
+import (
+	"sync"
+	"time"
+)
+
+const keepaliveRequestName = "keepalive@openssh.com"
+
+// keepaliveSender is the subset of *ssh.Client used to probe a connection.
+type keepaliveSender interface {
+	SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error)
+}
+
+// keepalive periodically sends keepalive requests over an SSH connection and calls onStall
+// once countMax consecutive requests have gone unanswered. A request that never returns,
+// as happens when the peer silently disappears, counts as unanswered on every tick.
+type keepalive struct {
+	sender   keepaliveSender
+	interval time.Duration
+	countMax int
+	onStall  func()
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func startKeepalive(sender keepaliveSender, interval time.Duration, countMax int, onStall func()) *keepalive {
+	if countMax < 1 {
+		countMax = 1
+	}
+	k := &keepalive{
+		sender:   sender,
+		interval: interval,
+		countMax: countMax,
+		onStall:  onStall,
+		stopCh:   make(chan struct{}),
+	}
+	go k.run()
+	return k
+}
+
+func (k *keepalive) stop() {
+	k.stopOnce.Do(func() { close(k.stopCh) })
+}
+
+func (k *keepalive) run() {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	var reply chan error
+	missed := 0
+	for {
+		if reply == nil {
+			reply = make(chan error, 1)
+			go func(ch chan<- error) {
+				_, _, err := k.sender.SendRequest(keepaliveRequestName, true, nil)
+				ch <- err
+			}(reply)
+		}
+
+		select {
+		case <-k.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		select {
+		case err := <-reply:
+			reply = nil
+			if err == nil {
+				missed = 0
+				continue
+			}
+			missed++
+		default:
+			missed++
+		}
+
+		if missed >= k.countMax {
+			select {
+			case <-k.stopCh:
+			default:
+				k.onStall()
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeKeepaliveSender struct {
+	mu       sync.Mutex
+	requests []string
+	block    chan struct{}
+}
+
+func (f *fakeKeepaliveSender) SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error) {
+	f.mu.Lock()
+	if wantReply {
+		f.requests = append(f.requests, name)
+	}
+	f.mu.Unlock()
+	if f.block != nil {
+		<-f.block
+		return false, nil, errors.New("connection closed")
+	}
+	return true, nil, nil
+}
+
+func (f *fakeKeepaliveSender) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func TestKeepalive_HealthyConnection(t *testing.T) {
+	sender := &fakeKeepaliveSender{}
+	stalled := make(chan struct{})
+	k := startKeepalive(sender, 5*time.Millisecond, 2, func() { close(stalled) })
+	defer k.stop()
+
+	select {
+	case <-stalled:
+		t.Fatal("healthy connection was reported as stalled")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if sender.count() < 2 {
+		t.Errorf("expected repeated keepalive requests, got %d", sender.count())
+	}
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	for _, name := range sender.requests {
+		if name != keepaliveRequestName {
+			t.Errorf("expected request %q, got %q", keepaliveRequestName, name)
+		}
+	}
+}
+
+func TestKeepalive_DetectsStalledConnection(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		countMax int
+	}{
+		{name: "single missed keepalive", interval: 10 * time.Millisecond, countMax: 1},
+		{name: "three missed keepalives", interval: 10 * time.Millisecond, countMax: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeKeepaliveSender{block: make(chan struct{})}
+			defer close(sender.block)
+
+			stalled := make(chan struct{})
+			start := time.Now()
+			k := startKeepalive(sender, tt.interval, tt.countMax, func() { close(stalled) })
+			defer k.stop()
+
+			window := tt.interval * time.Duration(tt.countMax)
+			select {
+			case <-stalled:
+			case <-time.After(window + 500*time.Millisecond):
+				t.Fatalf("stalled connection was not detected within %v", window)
+			}
+			if elapsed := time.Since(start); elapsed < window {
+				t.Errorf("stall reported after %v, before the configured window of %v", elapsed, window)
+			}
+			if sender.count() != 1 {
+				t.Errorf("expected a single outstanding keepalive request, got %d", sender.count())
+			}
+		})
+	}
+}
+
+func TestSSHConnector_StalledConnectionSurfacesDistinctError(t *testing.T) {
+	s := NewSSHConnector(nil)
+	s.connCfg = ConnectionCfg{
+		Host:                "192.168.1.100",
+		ServerAliveInterval: 5 * time.Millisecond,
+		ServerAliveCountMax: 2,
+	}
+	s.isConnected = true
+
+	sender := &fakeKeepaliveSender{block: make(chan struct{})}
+	defer close(sender.block)
+	closed := make(chan struct{})
+	s.startKeepalive(sender, func() error {
+		close(closed)
+		return nil
+	})
+	defer s.stopKeepalive()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("stalled connection was not closed")
+	}
+
+	if s.IsConnected() {
+		t.Error("expected stalled connector to report disconnected")
+	}
+	_, _, err := s.Exec(context.Background(), "true", nil)
+	var stallErr *ConnectionStalledError
+	if !errors.As(err, &stallErr) {
+		t.Fatalf("expected *ConnectionStalledError, got %v", err)
+	}
+	if stallErr.Host != "192.168.1.100" || stallErr.CountMax != 2 || stallErr.Interval != 5*time.Millisecond {
+		t.Errorf("unexpected stall error details: %+v", stallErr)
+	}
+}
+
+func TestSSHConnector_KeepaliveDisabled(t *testing.T) {
+	s := NewSSHConnector(nil)
+	s.connCfg = ConnectionCfg{Host: "192.168.1.100"}
+	s.startKeepalive(&fakeKeepaliveSender{}, func() error { return nil })
+	if s.keepalive != nil {
+		t.Error("expected no keepalive when ServerAliveInterval is zero")
+	}
+}
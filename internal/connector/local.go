@@ -21,13 +21,15 @@ import (
 	"github.com/mensylisir/kubexm/internal/logger"
 )
 
-func shellEscape(s string) string {
+// ShellEscape quotes s as a single POSIX shell word.
+func ShellEscape(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
 }
 
 type LocalConnector struct {
-	connCfg  ConnectionCfg
-	cachedOS *OS
+	connCfg    ConnectionCfg
+	cmdWrapper *CommandWrapper
+	cachedOS   *OS
 }
 
 func NewLocalConnector() (*LocalConnector, error) {
@@ -35,7 +37,12 @@ func NewLocalConnector() (*LocalConnector, error) {
 }
 
 func (l *LocalConnector) Connect(ctx context.Context, cfg ConnectionCfg) error {
+	wrapper, err := NewCommandWrapper(cfg.CommandWrapper)
+	if err != nil {
+		return err
+	}
 	l.connCfg = cfg
+	l.cmdWrapper = wrapper
 	return nil
 }
 
@@ -61,6 +68,10 @@ func (l *LocalConnector) Exec(ctx context.Context, cmd string, options *ExecOpti
 			fullCmdString = "sudo -E -- " + cmd
 		}
 	}
+	fullCmdString, err = l.cmdWrapper.Wrap(fullCmdString)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	runOnce := func(runCtx context.Context) ([]byte, []byte, error) {
 		shell := []string{"/bin/sh", "-c"}
@@ -190,14 +201,14 @@ func (l *LocalConnector) Copy(ctx context.Context, srcPath, dstPath string, opti
 
 	if srcStat.IsDir() {
 		// Use sudo cp -r for directories
-		cpCmd := fmt.Sprintf("cp -r %s %s", shellEscape(srcPath), shellEscape(stagedPath))
+		cpCmd := fmt.Sprintf("cp -r %s %s", ShellEscape(srcPath), ShellEscape(stagedPath))
 		_, stderr, err := l.Exec(ctx, cpCmd, &ExecOptions{Sudo: true})
 		if err != nil {
 			return fmt.Errorf("failed to stage directory %s to %s with sudo: %s (underlying error %w)", srcPath, stagedPath, string(stderr), err)
 		}
 	} else {
 		// Use sudo cp for files
-		cpCmd := fmt.Sprintf("cp %s %s", shellEscape(srcPath), shellEscape(stagedPath))
+		cpCmd := fmt.Sprintf("cp %s %s", ShellEscape(srcPath), ShellEscape(stagedPath))
 		_, stderr, err := l.Exec(ctx, cpCmd, &ExecOptions{Sudo: true})
 		if err != nil {
 			return fmt.Errorf("failed to stage file %s to %s with sudo: %s (underlying error %w)", srcPath, stagedPath, string(stderr), err)
@@ -206,14 +217,14 @@ func (l *LocalConnector) Copy(ctx context.Context, srcPath, dstPath string, opti
 
 	destParentDir := filepath.Dir(dstPath)
 	if destParentDir != "." && destParentDir != "/" && destParentDir != "" {
-		mkdirCmd := fmt.Sprintf("mkdir -p %s", shellEscape(destParentDir))
+		mkdirCmd := fmt.Sprintf("mkdir -p %s", ShellEscape(destParentDir))
 		_, stderr, mkdirErr := l.Exec(ctx, mkdirCmd, &ExecOptions{Sudo: true})
 		if mkdirErr != nil {
 			return fmt.Errorf("failed to create destination parent directory %s with sudo: %s (underlying error %w)", destParentDir, string(stderr), mkdirErr)
 		}
 	}
 
-	mvCmd := fmt.Sprintf("mv %s %s", shellEscape(stagedPath), shellEscape(dstPath))
+	mvCmd := fmt.Sprintf("mv %s %s", ShellEscape(stagedPath), ShellEscape(dstPath))
 	_, stderr, mvErr := l.Exec(ctx, mvCmd, &ExecOptions{Sudo: true})
 	if mvErr != nil {
 		return fmt.Errorf("failed to move staged content from %s to %s with sudo: %s (underlying error %w)", stagedPath, dstPath, string(stderr), mvErr)
@@ -291,7 +302,7 @@ func (l *LocalConnector) applySudoPermissions(ctx context.Context, path string,
 		if _, parseErr := strconv.ParseUint(opts.Permissions, 8, 32); parseErr != nil {
 			return fmt.Errorf("invalid permissions format '%s' for applySudoPermissions on %s: %w", opts.Permissions, path, parseErr)
 		}
-		chmodCmd := fmt.Sprintf("chmod %s %s", shellEscape(opts.Permissions), shellEscape(path))
+		chmodCmd := fmt.Sprintf("chmod %s %s", ShellEscape(opts.Permissions), ShellEscape(path))
 		_, stderr, err := l.Exec(ctx, chmodCmd, &ExecOptions{Sudo: true})
 		if err != nil {
 			return fmt.Errorf("failed to set permissions on %s with sudo chmod: %s (underlying error %w)", path, string(stderr), err)
@@ -314,7 +325,7 @@ func (l *LocalConnector) applySudoPermissions(ctx context.Context, path string,
 		chownFlags = "-R"
 	}
 
-	chownCmd := fmt.Sprintf("chown %s %s %s", chownFlags, shellEscape(ownerAndGroup), shellEscape(path))
+	chownCmd := fmt.Sprintf("chown %s %s %s", chownFlags, ShellEscape(ownerAndGroup), ShellEscape(path))
 	chownCmd = strings.TrimSpace(strings.ReplaceAll(chownCmd, "  ", " "))
 
 	_, stderr, err := l.Exec(ctx, chownCmd, &ExecOptions{Sudo: true})
@@ -368,14 +379,14 @@ func (l *LocalConnector) CopyContent(ctx context.Context, content []byte, dstPat
 
 	destParentDir := filepath.Dir(dstPath)
 	if destParentDir != "." && destParentDir != "/" && destParentDir != "" {
-		mkdirCmd := fmt.Sprintf("mkdir -p %s", shellEscape(destParentDir))
+		mkdirCmd := fmt.Sprintf("mkdir -p %s", ShellEscape(destParentDir))
 		_, stderr, mkdirErr := l.Exec(ctx, mkdirCmd, &ExecOptions{Sudo: true})
 		if mkdirErr != nil {
 			return fmt.Errorf("failed to create destination parent directory %s with sudo: %s (underlying error %w)", destParentDir, string(stderr), mkdirErr)
 		}
 	}
 
-	mvCmd := fmt.Sprintf("mv %s %s", shellEscape(tmpFile.Name()), shellEscape(dstPath))
+	mvCmd := fmt.Sprintf("mv %s %s", ShellEscape(tmpFile.Name()), ShellEscape(dstPath))
 	_, stderr, mvErr := l.Exec(ctx, mvCmd, &ExecOptions{Sudo: true})
 	if mvErr != nil {
 		return fmt.Errorf("failed to move temporary file from %s to %s with sudo: %s (underlying error %w)", tmpFile.Name(), dstPath, string(stderr), mvErr)
@@ -618,7 +629,7 @@ func (l *LocalConnector) WriteFile(ctx context.Context, content []byte, destPath
 
 		destDir := filepath.Dir(destPath)
 		if destDir != "." && destDir != "/" && destDir != "" {
-			mkdirCmd := fmt.Sprintf("mkdir -p %s", shellEscape(destDir))
+			mkdirCmd := fmt.Sprintf("mkdir -p %s", ShellEscape(destDir))
 			_, stderr, err := l.Exec(ctx, mkdirCmd, &ExecOptions{Sudo: true})
 			if err != nil {
 				return fmt.Errorf("failed to create parent directory %s with sudo: %s (underlying error: %w)", destDir, string(stderr), err)
@@ -642,7 +653,7 @@ func (l *LocalConnector) WriteFile(ctx context.Context, content []byte, destPath
 		}
 
 		// Move the temporary file to the destination with sudo
-		mvCmd := fmt.Sprintf("mv %s %s", shellEscape(tmpPath), shellEscape(destPath))
+		mvCmd := fmt.Sprintf("mv %s %s", ShellEscape(tmpPath), ShellEscape(destPath))
 		_, stderr, mvErr := l.Exec(ctx, mvCmd, &ExecOptions{Sudo: true})
 		if mvErr != nil {
 			return fmt.Errorf("failed to move temporary file from %s to %s with sudo: %s (underlying error: %w)", tmpPath, destPath, string(stderr), mvErr)
@@ -725,7 +736,7 @@ func (l *LocalConnector) Remove(ctx context.Context, path string, opts RemoveOpt
 			cmdParts = append(cmdParts, "-r")
 		}
 		cmdParts = append(cmdParts, "-f")
-		cmdParts = append(cmdParts, shellEscape(path))
+		cmdParts = append(cmdParts, ShellEscape(path))
 		rmCmd := strings.Join(cmdParts, " ")
 
 		_, stderr, err := l.Exec(ctx, rmCmd, &ExecOptions{Sudo: true})
@@ -841,9 +852,9 @@ func (l *LocalConnector) GetFileOwner(ctx context.Context, path string) (string,
 	// BSD stat: stat -f "%Su %Sg"
 	var cmd string
 	if runtime.GOOS == "darwin" { // BSD stat
-		cmd = fmt.Sprintf("stat -f '%%Su %%Sg' %s", shellEscape(path))
+		cmd = fmt.Sprintf("stat -f '%%Su %%Sg' %s", ShellEscape(path))
 	} else { // Assume GNU stat (Linux)
-		cmd = fmt.Sprintf("stat -c '%%U %%G' %s", shellEscape(path))
+		cmd = fmt.Sprintf("stat -c '%%U %%G' %s", ShellEscape(path))
 	}
 
 	stdout, _, err := l.Exec(ctx, cmd, nil)
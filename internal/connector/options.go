@@ -19,6 +19,19 @@ type RunOptions struct {
 	Dir        string
 }
 
+// InteractiveOptions for interactive command execution on a PTY
+type InteractiveOptions struct {
+	Sudo bool
+	Env  []string
+	Dir  string
+	// Term is the terminal type of the remote PTY. Defaults to $TERM, or xterm-256color if unset.
+	Term string
+	// Stdin, Stdout and Stderr default to the local terminal.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
 // RunResult contains command execution result
 type RunResult struct {
 	Stdout   []byte
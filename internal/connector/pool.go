@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"github.com/mensylisir/kubexm/internal/common"
 	"golang.org/x/crypto/ssh"
 	"sort"
 	"strings"
@@ -20,6 +21,24 @@ type ManagedConnection struct {
 	poolKey       string
 	lastUsed      time.Time
 	createdAt     time.Time
+	// sessions holds one token per open session; nil means sessions are not limited.
+	sessions chan struct{}
+	// refs counts the connectors sharing a connection handed out by Acquire.
+	refs int
+}
+
+func newManagedConnection(client, bastionClient *ssh.Client, poolKey string, maxSessions int) *ManagedConnection {
+	mc := &ManagedConnection{
+		client:        client,
+		bastionClient: bastionClient,
+		poolKey:       poolKey,
+		lastUsed:      time.Now(),
+		createdAt:     time.Now(),
+	}
+	if maxSessions > 0 {
+		mc.sessions = make(chan struct{}, maxSessions)
+	}
+	return mc
 }
 
 func (mc *ManagedConnection) Client() *ssh.Client {
@@ -39,6 +58,38 @@ func (mc *ManagedConnection) Close() {
 	}
 }
 
+// NewSession opens a session once fewer than the maximum number of sessions are open on the
+// connection, waiting for one to finish otherwise. The returned release func must be called after
+// the session is closed.
+func (mc *ManagedConnection) NewSession(ctx context.Context) (*ssh.Session, func(), error) {
+	release, err := mc.AcquireSessionSlot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := mc.client.NewSession()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return session, release, nil
+}
+
+// AcquireSessionSlot reserves one of the session slots of the connection for a channel that is
+// not opened through NewSession, such as the SFTP subsystem, waiting while all slots are taken.
+// The returned release func frees the slot and is safe to call more than once.
+func (mc *ManagedConnection) AcquireSessionSlot(ctx context.Context) (func(), error) {
+	if mc.sessions == nil {
+		return func() {}, nil
+	}
+	select {
+	case mc.sessions <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free session slot: %w", ctx.Err())
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-mc.sessions }) }, nil
+}
+
 func (mc *ManagedConnection) IsHealthy() bool {
 	if mc.client == nil {
 		return false
@@ -54,6 +105,9 @@ type PoolConfig struct {
 	IdleTimeout         time.Duration
 	HealthCheckInterval time.Duration
 	ConnectTimeout      time.Duration
+	// MaxSessionsPerConn bounds the concurrent sessions on a connection whose ConnectionCfg does
+	// not set MaxSessions.
+	MaxSessionsPerConn int
 }
 
 func DefaultPoolConfig() *PoolConfig {
@@ -64,6 +118,7 @@ func DefaultPoolConfig() *PoolConfig {
 		IdleTimeout:         10 * time.Minute,
 		HealthCheckInterval: 1 * time.Minute,
 		ConnectTimeout:      15 * time.Second,
+		MaxSessionsPerConn:  common.DefaultSSHMaxSessions,
 	}
 }
 
@@ -71,6 +126,8 @@ type hostConnectionPool struct {
 	sync.Mutex
 	idle      []*ManagedConnection
 	numActive int
+	// shared is the connection handed out by Acquire to every connector of the key.
+	shared *ManagedConnection
 }
 
 type ConnectionPool struct {
@@ -99,6 +156,9 @@ func NewConnectionPool(config *PoolConfig) *ConnectionPool {
 	if config.HealthCheckInterval == 0 {
 		config.HealthCheckInterval = DefaultPoolConfig().HealthCheckInterval
 	}
+	if config.MaxSessionsPerConn == 0 {
+		config.MaxSessionsPerConn = DefaultPoolConfig().MaxSessionsPerConn
+	}
 
 	cp := &ConnectionPool{
 		pools:  make(map[string]*hostConnectionPool),
@@ -211,25 +271,85 @@ func (cp *ConnectionPool) Get(ctx context.Context, cfg ConnectionCfg) (*ManagedC
 	hcp.numActive++
 	hcp.Unlock()
 
-	targetClient, bastionClient, err := currentDialer(ctx, cfg, cp.config.ConnectTimeout)
+	mc, err := cp.dial(ctx, cfg, poolKey)
 	if err != nil {
 		hcp.Lock()
 		hcp.numActive--
 		hcp.Unlock()
 		return nil, err
 	}
+	return mc, nil
+}
 
-	mc := &ManagedConnection{
-		client:        targetClient,
-		bastionClient: bastionClient,
-		poolKey:       poolKey,
-		lastUsed:      time.Now(),
-		createdAt:     time.Now(),
+func (cp *ConnectionPool) dial(ctx context.Context, cfg ConnectionCfg, poolKey string) (*ManagedConnection, error) {
+	connectTimeout := cp.config.ConnectTimeout
+	if cfg.ConnectTimeout > 0 {
+		connectTimeout = cfg.ConnectTimeout
+	}
+	targetClient, bastionClient, err := currentDialer(ctx, cfg, connectTimeout)
+	if err != nil {
+		return nil, err
 	}
+	maxSessions := cp.config.MaxSessionsPerConn
+	if cfg.MaxSessions > 0 {
+		maxSessions = cfg.MaxSessions
+	}
+	return newManagedConnection(targetClient, bastionClient, poolKey, maxSessions), nil
+}
 
+// Acquire returns the connection shared by all connectors of cfg's host and credentials, dialing
+// it on first use. Unlike Get, the connection is not handed out exclusively: sessions are
+// multiplexed over it, bounded by its session limit. Callers must Release it when done. Shared
+// connections stay open between uses and are closed by Shutdown.
+func (cp *ConnectionPool) Acquire(ctx context.Context, cfg ConnectionCfg) (*ManagedConnection, error) {
+	poolKey := generatePoolKey(cfg)
+	hcp := cp.getOrCreateHostPool(poolKey)
+
+	// Dialing under the lock makes concurrent first users of a host wait for a single connection.
+	hcp.Lock()
+	defer hcp.Unlock()
+
+	if mc := hcp.shared; mc != nil {
+		if mc.IsHealthy() {
+			mc.refs++
+			mc.lastUsed = time.Now()
+			return mc, nil
+		}
+		hcp.shared = nil
+		if mc.refs == 0 {
+			mc.Close()
+		}
+	}
+
+	mc, err := cp.dial(ctx, cfg, poolKey)
+	if err != nil {
+		return nil, err
+	}
+	mc.refs = 1
+	hcp.shared = mc
 	return mc, nil
 }
 
+// Release hands back a connection obtained from Acquire. A connection that has been replaced
+// because it became unhealthy is closed once its last user releases it.
+func (cp *ConnectionPool) Release(mc *ManagedConnection) {
+	if mc == nil || mc.poolKey == "" {
+		return
+	}
+	hcp := cp.getOrCreateHostPool(mc.poolKey)
+
+	hcp.Lock()
+	defer hcp.Unlock()
+
+	if mc.refs > 0 {
+		mc.refs--
+	}
+	mc.lastUsed = time.Now()
+	if hcp.shared != mc && mc.refs == 0 {
+		mc.Close()
+	}
+}
+
 func (cp *ConnectionPool) Put(mc *ManagedConnection, isHealthy bool) {
 	if mc == nil || mc.client == nil {
 		// If mc is nil or its client is nil, there's nothing to pool or close explicitly here.
@@ -344,6 +464,10 @@ func (cp *ConnectionPool) Shutdown() {
 		}
 		hcp.idle = nil
 		hcp.numActive = 0
+		if hcp.shared != nil {
+			hcp.shared.Close()
+			hcp.shared = nil
+		}
 		hcp.Unlock()
 	}
 	cp.pools = make(map[string]*hostConnectionPool)
@@ -2,8 +2,13 @@ package connector
 
 import (
 	"context"
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 func TestConnectionPool(t *testing.T) {
@@ -89,3 +94,191 @@ func TestConnectionPool(t *testing.T) {
 		pool.Put(c2, true)
 	})
 }
+
+// testSessionServer counts the SSH connections it accepts and the exec sessions running at once.
+type testSessionServer struct {
+	conns       atomic.Int32
+	openConns   atomic.Int32
+	mu          sync.Mutex
+	running     int
+	maxRunning  int
+	sessionHold time.Duration
+}
+
+func (srv *testSessionServer) enter() {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.running++
+	if srv.running > srv.maxRunning {
+		srv.maxRunning = srv.running
+	}
+}
+
+func (srv *testSessionServer) leave() {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.running--
+}
+
+func (srv *testSessionServer) start(t *testing.T) *net.TCPAddr {
+	t.Helper()
+	config := newTestServerConfig(t, "secret")
+	return startTestListener(t, func(conn net.Conn) {
+		sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		srv.conns.Add(1)
+		srv.openConns.Add(1)
+		defer srv.openConns.Add(-1)
+		defer sconn.Close()
+		go ssh.DiscardRequests(reqs)
+		for newCh := range chans {
+			ch, chReqs, err := newCh.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer ch.Close()
+				for req := range chReqs {
+					if req.Type != "exec" {
+						req.Reply(false, nil)
+						continue
+					}
+					req.Reply(true, nil)
+					srv.enter()
+					time.Sleep(srv.sessionHold)
+					srv.leave()
+					ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+					return
+				}
+			}()
+		}
+	})
+}
+
+func testPoolConnectionCfg(addr *net.TCPAddr) ConnectionCfg {
+	return ConnectionCfg{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		User:     "ops",
+		Password: "secret",
+		Timeout:  5 * time.Second,
+	}
+}
+
+func TestConnectionPool_AcquireSharesOneConnectionPerHost(t *testing.T) {
+	srv := &testSessionServer{}
+	addr := srv.start(t)
+	pool := NewConnectionPool(&PoolConfig{HealthCheckInterval: -1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	first, second := NewSSHConnector(pool), NewSSHConnector(pool)
+	for _, s := range []*SSHConnector{first, second} {
+		if err := s.Connect(ctx, testPoolConnectionCfg(addr)); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		if _, _, err := s.Exec(ctx, "true", nil); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+	if got := srv.conns.Load(); got != 1 {
+		t.Fatalf("expected both connectors to share one connection, server saw %d", got)
+	}
+
+	first.Close()
+	second.Close()
+	third := NewSSHConnector(pool)
+	if err := third.Connect(ctx, testPoolConnectionCfg(addr)); err != nil {
+		t.Fatalf("Connect after release failed: %v", err)
+	}
+	third.Close()
+	if got := srv.conns.Load(); got != 1 {
+		t.Errorf("expected the released connection to be reused, server saw %d connections", got)
+	}
+
+	pool.Shutdown()
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.openConns.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := srv.openConns.Load(); got != 0 {
+		t.Errorf("expected Shutdown to close the shared connection, %d still open", got)
+	}
+}
+
+func TestConnectionPool_MaxSessions(t *testing.T) {
+	srv := &testSessionServer{sessionHold: 50 * time.Millisecond}
+	addr := srv.start(t)
+	pool := NewConnectionPool(&PoolConfig{HealthCheckInterval: -1})
+	defer pool.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := testPoolConnectionCfg(addr)
+	cfg.MaxSessions = 2
+	s := NewSSHConnector(pool)
+	if err := s.Connect(ctx, cfg); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := s.Exec(ctx, "sleep", nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Exec failed: %v", err)
+		}
+	}
+	if srv.maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent sessions, server saw %d", srv.maxRunning)
+	}
+	if srv.maxRunning < 2 {
+		t.Errorf("expected sessions to run concurrently up to the limit, server saw %d", srv.maxRunning)
+	}
+}
+
+func TestManagedConnection_NewSessionWaitsForSlot(t *testing.T) {
+	mc := newManagedConnection(nil, nil, "", 1)
+	mc.sessions <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := mc.NewSession(ctx); err == nil {
+		t.Fatal("expected NewSession to give up when no slot frees before the deadline")
+	}
+}
+
+func TestManagedConnection_AcquireSessionSlotCountsAgainstLimit(t *testing.T) {
+	mc := newManagedConnection(nil, nil, "", 1)
+	release, err := mc.AcquireSessionSlot(context.Background())
+	if err != nil {
+		t.Fatalf("AcquireSessionSlot failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := mc.AcquireSessionSlot(ctx); err == nil {
+		t.Fatal("expected the held slot to block a second acquisition")
+	}
+
+	release()
+	release()
+	if len(mc.sessions) != 0 {
+		t.Fatalf("expected release to free exactly one slot, %d still held", len(mc.sessions))
+	}
+	if _, err := mc.AcquireSessionSlot(context.Background()); err != nil {
+		t.Errorf("expected the released slot to be available again: %v", err)
+	}
+}
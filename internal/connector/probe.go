@@ -0,0 +1,273 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// ProbeStage identifies how far a reachability probe got before it stopped.
+type ProbeStage string
+
+const (
+	// ProbeStageResolve means the host name could not be resolved to an address.
+	ProbeStageResolve ProbeStage = "resolve"
+	// ProbeStageDial means the TCP connection to the SSH port could not be established.
+	ProbeStageDial ProbeStage = "dial"
+	// ProbeStageHandshake means the TCP connection was open but the SSH transport
+	// handshake (version exchange, key exchange) did not complete.
+	ProbeStageHandshake ProbeStage = "handshake"
+	// ProbeStageAuth means the SSH server rejected the configured credentials.
+	ProbeStageAuth ProbeStage = "auth"
+	// ProbeStageConnected means every stage succeeded.
+	ProbeStageConnected ProbeStage = "connected"
+)
+
+// ProbeResult describes the outcome of Probe. Stage is the stage that failed, or
+// ProbeStageConnected on success. Durations are only set for stages that were attempted.
+type ProbeResult struct {
+	Host     string
+	Address  string
+	Stage    ProbeStage
+	TimedOut bool
+	Err      error
+	// Local is set for hosts of type local, which run commands without SSH and are not probed.
+	Local bool
+
+	ResolveDuration   time.Duration
+	DialDuration      time.Duration
+	HandshakeDuration time.Duration
+	AuthDuration      time.Duration
+	Total             time.Duration
+}
+
+// OK reports whether the host was reachable and accepted the credentials.
+func (r ProbeResult) OK() bool {
+	return r.Stage == ProbeStageConnected && r.Err == nil
+}
+
+// Reason returns a short human-readable explanation of the result.
+func (r ProbeResult) Reason() string {
+	if r.OK() && r.Local {
+		return fmt.Sprintf("%s is the local host, no SSH connection needed", r.Host)
+	}
+	if r.OK() {
+		return fmt.Sprintf("connected to %s in %v", r.Address, r.Total)
+	}
+	var reason string
+	switch r.Stage {
+	case ProbeStageResolve:
+		reason = "DNS resolution failed"
+	case ProbeStageDial:
+		reason = "TCP connection failed"
+		if isConnectionRefused(r.Err) {
+			reason = "TCP connection refused"
+		}
+	case ProbeStageHandshake:
+		reason = "SSH handshake failed"
+	case ProbeStageAuth:
+		reason = "SSH authentication failed"
+	default:
+		reason = "probe failed"
+	}
+	if r.TimedOut {
+		reason += " (timed out)"
+	}
+	return fmt.Sprintf("%s for %s: %v", reason, r.Address, r.Err)
+}
+
+// prober holds the network primitives used by Probe so tests can replace them.
+type prober struct {
+	lookupHost  func(ctx context.Context, host string) ([]string, error)
+	dialContext func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+var defaultProber = prober{
+	lookupHost:  net.DefaultResolver.LookupHost,
+	dialContext: (&net.Dialer{}).DialContext,
+}
+
+// Probe checks whether host can be reached over SSH and classifies a failure by the
+// stage at which it happened: name resolution, TCP dial, SSH handshake or authentication.
+// The SSH session is closed as soon as authentication succeeds. Hosts behind a bastion are
+// probed through it, and any failure to reach or log in to the bastion counts as a dial failure.
+// Local hosts run commands without SSH, so they are reported as connected without a probe.
+func Probe(ctx context.Context, host Host) ProbeResult {
+	return defaultProber.probe(ctx, host)
+}
+
+func (p prober) probe(ctx context.Context, host Host) (result ProbeResult) {
+	start := time.Now()
+	result = ProbeResult{
+		Host:    host.GetName(),
+		Address: net.JoinHostPort(host.GetAddress(), strconv.Itoa(host.GetPort())),
+	}
+	defer func() { result.Total = time.Since(start) }()
+
+	if host.GetHostSpec().Type == string(common.HostTypeLocal) {
+		result.Stage = ProbeStageConnected
+		result.Local = true
+		return result
+	}
+
+	cfg, err := NewFactory().NewConnectionCfg(host, 0)
+	if err != nil {
+		return p.fail(&result, ProbeStageAuth, err)
+	}
+	authMethods, err := buildAuthMethods(cfg)
+	if err != nil {
+		return p.fail(&result, ProbeStageAuth, err)
+	}
+
+	if timeout := connectTimeoutFor(cfg); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var conn net.Conn
+	if cfg.BastionCfg != nil {
+		// The bastion resolves and dials the target, which is usually not routable from here.
+		stageStart := time.Now()
+		bastion, err := p.dialBastion(ctx, cfg.BastionCfg)
+		if err != nil {
+			result.DialDuration = time.Since(stageStart)
+			return p.fail(&result, ProbeStageDial, err)
+		}
+		defer bastion.Close()
+		conn, err = bastion.DialContext(ctx, "tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)))
+		result.DialDuration = time.Since(stageStart)
+		if err != nil {
+			return p.fail(&result, ProbeStageDial, fmt.Errorf("dial via bastion %s failed: %w", cfg.BastionCfg.Host, err))
+		}
+	} else {
+		stageStart := time.Now()
+		address := cfg.Host
+		if net.ParseIP(address) == nil {
+			addrs, err := p.lookupHost(ctx, address)
+			result.ResolveDuration = time.Since(stageStart)
+			if err != nil {
+				return p.fail(&result, ProbeStageResolve, err)
+			}
+			if len(addrs) == 0 {
+				return p.fail(&result, ProbeStageResolve, fmt.Errorf("no addresses found for %s", address))
+			}
+			address = addrs[0]
+		}
+
+		stageStart = time.Now()
+		conn, err = p.dialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(cfg.Port)))
+		result.DialDuration = time.Since(stageStart)
+		if err != nil {
+			return p.fail(&result, ProbeStageDial, err)
+		}
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// The host key callback runs once key exchange has completed, which separates
+	// transport handshake failures from authentication failures.
+	stageStart := time.Now()
+	var handshakeDone time.Time
+	hostKeyCallback := cfg.HostKeyCallback
+	sshConfig := &ssh.ClientConfig{
+		User: cfg.User,
+		Auth: authMethods,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			handshakeDone = time.Now()
+			if hostKeyCallback == nil {
+				return nil
+			}
+			return hostKeyCallback(hostname, remote, key)
+		},
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, result.Address, sshConfig)
+	if handshakeDone.IsZero() {
+		result.HandshakeDuration = time.Since(stageStart)
+		if err != nil {
+			return p.fail(&result, ProbeStageHandshake, err)
+		}
+	} else {
+		result.HandshakeDuration = handshakeDone.Sub(stageStart)
+		result.AuthDuration = time.Since(handshakeDone)
+		if err != nil {
+			stage := ProbeStageHandshake
+			if strings.Contains(err.Error(), "unable to authenticate") {
+				stage = ProbeStageAuth
+			}
+			return p.fail(&result, stage, err)
+		}
+	}
+	ssh.NewClient(clientConn, chans, reqs).Close()
+
+	result.Stage = ProbeStageConnected
+	return result
+}
+
+// dialBastion opens an SSH client to the bastion that the target connection is tunnelled through.
+func (p prober) dialBastion(ctx context.Context, bastion *BastionCfg) (*ssh.Client, error) {
+	authMethods, err := buildAuthMethods(ConnectionCfg{
+		Host:           bastion.Host,
+		Password:       bastion.Password,
+		PrivateKey:     bastion.PrivateKey,
+		PrivateKeyPath: bastion.PrivateKeyPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bastion %s: %w", bastion.Host, err)
+	}
+	address := net.JoinHostPort(bastion.Host, strconv.Itoa(bastion.Port))
+	conn, err := p.dialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("bastion %s: %w", bastion.Host, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	hostKeyCallback := bastion.HostKeyCallback
+	if hostKeyCallback == nil {
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	}
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, address, &ssh.ClientConfig{
+		User:            bastion.User,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("bastion %s: %w", bastion.Host, err)
+	}
+	// The deadline only bounds the handshake; the tunnel carries the target's own deadline.
+	_ = conn.SetDeadline(time.Time{})
+	return ssh.NewClient(clientConn, chans, reqs), nil
+}
+
+func (p prober) fail(result *ProbeResult, stage ProbeStage, err error) ProbeResult {
+	result.Stage = stage
+	result.Err = err
+	result.TimedOut = isTimeout(err)
+	return *result
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
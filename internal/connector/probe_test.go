@@ -0,0 +1,252 @@
+package connector
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// startTestSSHServer serves the SSH protocol on a local listener, accepting only the given password.
+func startTestSSHServer(t *testing.T, password string) *net.TCPAddr {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %s", c.User())
+		},
+	}
+	config.AddHostKey(signer)
+
+	return startTestListener(t, func(conn net.Conn) {
+		sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		go func() {
+			for ch := range chans {
+				ch.Reject(ssh.Prohibited, "no sessions")
+			}
+		}()
+		sconn.Wait()
+	})
+}
+
+func startTestListener(t *testing.T, handle func(net.Conn)) *net.TCPAddr {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestProbe(t *testing.T) {
+	sshAddr := startTestSSHServer(t, "secret")
+	garbageAddr := startTestListener(t, func(conn net.Conn) {
+		conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+	})
+
+	tests := []struct {
+		name          string
+		host          *MockHost
+		prober        prober
+		expectedStage ProbeStage
+		expectTimeout bool
+	}{
+		{
+			name: "DNS resolution failure",
+			host: &MockHost{Name: "node1", Address: "node1.invalid", Port: 22, User: "root", Password: "secret"},
+			prober: prober{
+				lookupHost: func(ctx context.Context, host string) ([]string, error) {
+					return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+				},
+				dialContext: defaultProber.dialContext,
+			},
+			expectedStage: ProbeStageResolve,
+		},
+		{
+			name:          "TCP connection refused",
+			host:          &MockHost{Name: "node1", Address: "127.0.0.1", Port: closedPort(t), User: "root", Password: "secret"},
+			prober:        defaultProber,
+			expectedStage: ProbeStageDial,
+		},
+		{
+			name: "TCP dial timeout",
+			host: &MockHost{Name: "node1", Address: "10.255.255.1", Port: 22, User: "root", Password: "secret"},
+			prober: prober{
+				lookupHost: defaultProber.lookupHost,
+				dialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+					return nil, &net.OpError{Op: "dial", Net: network, Err: timeoutError{}}
+				},
+			},
+			expectedStage: ProbeStageDial,
+			expectTimeout: true,
+		},
+		{
+			name:          "SSH handshake failure",
+			host:          &MockHost{Name: "node1", Address: "127.0.0.1", Port: garbageAddr.Port, User: "root", Password: "secret"},
+			prober:        defaultProber,
+			expectedStage: ProbeStageHandshake,
+		},
+		{
+			name:          "SSH authentication failure",
+			host:          &MockHost{Name: "node1", Address: "127.0.0.1", Port: sshAddr.Port, User: "root", Password: "wrong"},
+			prober:        defaultProber,
+			expectedStage: ProbeStageAuth,
+		},
+		{
+			name:          "connected",
+			host:          &MockHost{Name: "node1", Address: "127.0.0.1", Port: sshAddr.Port, User: "root", Password: "secret"},
+			prober:        defaultProber,
+			expectedStage: ProbeStageConnected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.host.Timeout = 5
+			result := tt.prober.probe(context.Background(), tt.host)
+
+			if result.Stage != tt.expectedStage {
+				t.Fatalf("expected stage %q, got %q (err: %v)", tt.expectedStage, result.Stage, result.Err)
+			}
+			if result.TimedOut != tt.expectTimeout {
+				t.Errorf("expected TimedOut %v, got %v", tt.expectTimeout, result.TimedOut)
+			}
+			if tt.expectedStage == ProbeStageConnected {
+				if !result.OK() {
+					t.Errorf("expected OK result, got %s", result.Reason())
+				}
+			} else if result.Err == nil || result.OK() {
+				t.Errorf("expected a failed result with an error, got %+v", result)
+			}
+			if want := net.JoinHostPort(tt.host.Address, strconv.Itoa(tt.host.Port)); result.Address != want {
+				t.Errorf("expected address %q, got %q", want, result.Address)
+			}
+			if result.Total <= 0 {
+				t.Error("expected the total duration to be recorded")
+			}
+		})
+	}
+}
+
+func TestProbe_ViaBastion(t *testing.T) {
+	var forwarded atomic.Int32
+	target := startTestSSHServer(t, "secret")
+	bastion := startTestBastion(t, "jump-secret", &forwarded)
+	// The target is only reachable through the bastion.
+	p := prober{
+		lookupHost: defaultProber.lookupHost,
+		dialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if address != bastion.String() {
+				return nil, fmt.Errorf("%s is not routable", address)
+			}
+			return defaultProber.dialContext(ctx, network, address)
+		},
+	}
+	host := &MockHost{
+		Name: "node1", Address: "127.0.0.1", Port: target.Port, User: "root", Password: "secret", Timeout: 5,
+		Bastion: &v1alpha1.BastionSpec{Address: "127.0.0.1", Port: bastion.Port, User: "jump", Password: "jump-secret"},
+	}
+
+	if result := p.probe(context.Background(), host); !result.OK() || forwarded.Load() != 1 {
+		t.Fatalf("expected the host to be reached through the bastion, got %s (forwarded %d)", result.Reason(), forwarded.Load())
+	}
+
+	host.Bastion.Password = "wrong"
+	if result := p.probe(context.Background(), host); result.Stage != ProbeStageDial || !strings.Contains(result.Err.Error(), "bastion") {
+		t.Errorf("expected a bastion login failure to be a dial failure, got stage %q err=%v", result.Stage, result.Err)
+	}
+}
+
+func TestProbe_LocalHost(t *testing.T) {
+	host := &MockHost{Name: "deploy", Address: "192.168.1.30", Port: closedPort(t), Type: string(common.HostTypeLocal)}
+	result := Probe(context.Background(), host)
+	if !result.OK() || !result.Local {
+		t.Fatalf("expected the local host not to be probed over SSH, got %s", result.Reason())
+	}
+}
+
+func TestProbe_HandshakeTimeout(t *testing.T) {
+	// A server that accepts the connection but never speaks SSH.
+	silentAddr := startTestListener(t, func(conn net.Conn) {
+		time.Sleep(3 * time.Second)
+	})
+	host := &MockHost{Name: "node1", Address: "127.0.0.1", Port: silentAddr.Port, User: "root", Password: "secret", Timeout: 1}
+
+	result := Probe(context.Background(), host)
+	if result.Stage != ProbeStageHandshake || !result.TimedOut {
+		t.Fatalf("expected a timed out handshake, got stage %q timedOut=%v err=%v", result.Stage, result.TimedOut, result.Err)
+	}
+}
+
+func TestProbeResult_Reason(t *testing.T) {
+	refused := ProbeResult{
+		Address: "10.0.0.1:22",
+		Stage:   ProbeStageDial,
+		Err:     &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+	}
+	if got := refused.Reason(); !strings.HasPrefix(got, "TCP connection refused for 10.0.0.1:22") {
+		t.Errorf("unexpected reason %q", got)
+	}
+
+	timedOut := ProbeResult{Address: "10.0.0.1:22", Stage: ProbeStageHandshake, TimedOut: true, Err: timeoutError{}}
+	if got := timedOut.Reason(); !strings.HasPrefix(got, "SSH handshake failed (timed out)") {
+		t.Errorf("unexpected reason %q", got)
+	}
+}
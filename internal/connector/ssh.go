@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -14,6 +13,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/logger"
@@ -26,12 +26,17 @@ type SSHConnector struct {
 	client        *ssh.Client
 	bastionClient *ssh.Client
 	sftpClient    *sftp.Client
+	sftpRelease   func()
 	connCfg       ConnectionCfg
+	cmdWrapper    *CommandWrapper
 	cachedOS      *OS
 	isConnected   bool
 	pool          *ConnectionPool
 	isFromPool    bool
 	managedConn   *ManagedConnection
+	keepalive     *keepalive
+	stallMu       sync.Mutex
+	stallErr      error
 }
 
 func NewSSHConnector(pool *ConnectionPool) *SSHConnector {
@@ -41,41 +46,101 @@ func NewSSHConnector(pool *ConnectionPool) *SSHConnector {
 }
 
 func (s *SSHConnector) Connect(ctx context.Context, cfg ConnectionCfg) error {
-	log := logger.Get()
+	wrapper, err := NewCommandWrapper(cfg.CommandWrapper)
+	if err != nil {
+		return err
+	}
 	s.connCfg = cfg
+	s.cmdWrapper = wrapper
 	s.isFromPool = false
+	s.setStallErr(nil)
 
-	if s.pool != nil && cfg.BastionCfg == nil {
-		mc, err := s.pool.Get(ctx, cfg)
-		if err == nil && mc != nil && mc.Client() != nil {
-			s.managedConn = mc
-			s.client = mc.Client()
-			s.isFromPool = true
-			s.isConnected = true
-			return nil
-		}
-		if err != nil && !errors.Is(err, ErrPoolExhausted) {
-			log.Error(os.Stderr, "SSHConnector: Failed to get connection from pool for %s (will attempt direct dial): %v\n", cfg.Host, err)
+	if s.pool != nil {
+		mc, err := s.pool.Acquire(ctx, cfg)
+		if err != nil {
+			return err
 		}
+		s.managedConn = mc
+		s.client = mc.Client()
+		s.isFromPool = true
+		s.isConnected = true
+		s.startKeepalive(s.client, s.client.Close)
+		return nil
 	}
 
-	s.isFromPool = false
-	s.managedConn = nil
-	client, bastionClient, err := currentDialer(ctx, cfg, cfg.Timeout)
+	client, bastionClient, err := currentDialer(ctx, cfg, connectTimeoutFor(cfg))
 	if err != nil {
 		return err
 	}
 	s.client = client
 	s.bastionClient = bastionClient
+	s.managedConn = newManagedConnection(client, bastionClient, "", cfg.MaxSessions)
 	s.isConnected = true
+	s.startKeepalive(s.client, s.client.Close)
 	return nil
 }
 
+// connectTimeoutFor returns the dial timeout for cfg, preferring ConnectTimeout over Timeout.
+func connectTimeoutFor(cfg ConnectionCfg) time.Duration {
+	if cfg.ConnectTimeout > 0 {
+		return cfg.ConnectTimeout
+	}
+	return cfg.Timeout
+}
+
+// startKeepalive begins probing the connection when ServerAliveInterval is set. Once the
+// connection is considered stalled it is closed, which unblocks any in-flight sessions, and
+// subsequent operations return a *ConnectionStalledError.
+func (s *SSHConnector) startKeepalive(sender keepaliveSender, closeConn func() error) {
+	if s.connCfg.ServerAliveInterval <= 0 {
+		return
+	}
+	stallErr := &ConnectionStalledError{
+		Host:     s.connCfg.Host,
+		Interval: s.connCfg.ServerAliveInterval,
+		CountMax: s.connCfg.ServerAliveCountMax,
+	}
+	s.keepalive = startKeepalive(sender, s.connCfg.ServerAliveInterval, s.connCfg.ServerAliveCountMax, func() {
+		s.setStallErr(stallErr)
+		logger.Get().Warnf("SSHConnector: %v, closing connection", stallErr)
+		_ = closeConn()
+	})
+}
+
+func (s *SSHConnector) stopKeepalive() {
+	if s.keepalive != nil {
+		s.keepalive.stop()
+		s.keepalive = nil
+	}
+}
+
+func (s *SSHConnector) setStallErr(err error) {
+	s.stallMu.Lock()
+	defer s.stallMu.Unlock()
+	s.stallErr = err
+}
+
+func (s *SSHConnector) stalled() error {
+	s.stallMu.Lock()
+	defer s.stallMu.Unlock()
+	return s.stallErr
+}
+
+// newSession opens a session on the connection, waiting while its session limit is reached. The
+// returned release func must be called once the session is closed.
+func (s *SSHConnector) newSession(ctx context.Context) (*ssh.Session, func(), error) {
+	if s.managedConn == nil {
+		session, err := s.client.NewSession()
+		return session, func() {}, err
+	}
+	return s.managedConn.NewSession(ctx)
+}
+
 func (s *SSHConnector) IsConnected() bool {
-	if s.client == nil || !s.isConnected {
+	if s.client == nil || !s.isConnected || s.stalled() != nil {
 		return false
 	}
-	_, _, err := s.client.SendRequest("keepalive@openssh.com", true, nil)
+	_, _, err := s.client.SendRequest(keepaliveRequestName, true, nil)
 	if err != nil {
 		s.isConnected = false
 		return false
@@ -85,6 +150,7 @@ func (s *SSHConnector) IsConnected() bool {
 
 func (s *SSHConnector) Close() error {
 	s.isConnected = false
+	s.stopKeepalive()
 	var firstErr error
 	log := logger.Get()
 	if s.sftpClient != nil {
@@ -93,12 +159,12 @@ func (s *SSHConnector) Close() error {
 			log.Errorf("%v %v", os.Stderr, firstErr)
 		}
 		s.sftpClient = nil
+		s.sftpRelease()
 	}
 
 	if s.client != nil {
 		if s.isFromPool && s.managedConn != nil && s.pool != nil {
-			isHealthy := s.managedConn.IsHealthy()
-			s.pool.Put(s.managedConn, isHealthy)
+			s.pool.Release(s.managedConn)
 		} else {
 			if err := s.client.Close(); err != nil {
 				if firstErr == nil {
@@ -132,6 +198,9 @@ func (s *SSHConnector) Close() error {
 }
 
 func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOptions) (stdout, stderr []byte, err error) {
+	if stallErr := s.stalled(); stallErr != nil {
+		return nil, nil, stallErr
+	}
 	if !s.IsConnected() {
 		return nil, nil, &ConnectionError{Host: s.connCfg.Host, Err: fmt.Errorf("not connected")}
 	}
@@ -142,10 +211,11 @@ func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOption
 	}
 
 	runOnce := func(runCtx context.Context, stdinPipe io.Reader) ([]byte, []byte, error) {
-		session, err := s.client.NewSession()
+		session, release, err := s.newSession(runCtx)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create session: %w", err)
 		}
+		defer release()
 		defer session.Close()
 
 		if len(effectiveOptions.Env) > 0 {
@@ -159,7 +229,7 @@ func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOption
 
 		finalCmd := cmd
 		if effectiveOptions.Dir != "" {
-			finalCmd = fmt.Sprintf("cd %s && %s", shellEscape(effectiveOptions.Dir), cmd)
+			finalCmd = fmt.Sprintf("cd %s && %s", ShellEscape(effectiveOptions.Dir), cmd)
 		}
 
 		if effectiveOptions.Sudo {
@@ -173,6 +243,10 @@ func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOption
 		} else {
 			session.Stdin = stdinPipe
 		}
+		finalCmd, err = s.cmdWrapper.Wrap(finalCmd)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		var stdoutBuf, stderrBuf bytes.Buffer
 		if effectiveOptions.Stream != nil {
@@ -235,7 +309,7 @@ func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOption
 			return stdout, stderr, nil
 		}
 
-		if ctx.Err() != nil {
+		if ctx.Err() != nil || s.stalled() != nil {
 			break
 		}
 
@@ -252,6 +326,10 @@ func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOption
 		}
 	}
 
+	if stallErr := s.stalled(); stallErr != nil {
+		return stdout, stderr, stallErr
+	}
+
 	exitCode := -1
 	if exitErr, ok := err.(*ssh.ExitError); ok {
 		exitCode = exitErr.ExitStatus()
@@ -259,16 +337,26 @@ func (s *SSHConnector) Exec(ctx context.Context, cmd string, options *ExecOption
 	return stdout, stderr, &CommandError{Cmd: cmd, ExitCode: exitCode, Stdout: string(stdout), Stderr: string(stderr), Underlying: err}
 }
 
-func (s *SSHConnector) ensureSftp() error {
+// ensureSftp opens the SFTP client on first use. The SFTP subsystem is a session channel of its
+// own, so it holds a session slot of the connection until the connector is closed.
+func (s *SSHConnector) ensureSftp(ctx context.Context) error {
 	if s.sftpClient == nil {
 		if !s.IsConnected() {
 			return &ConnectionError{Host: s.connCfg.Host, Err: fmt.Errorf("not connected, cannot initialize SFTP")}
 		}
-		var err error
-		s.sftpClient, err = sftp.NewClient(s.client)
+		release := func() {}
+		if s.managedConn != nil {
+			var err error
+			if release, err = s.managedConn.AcquireSessionSlot(ctx); err != nil {
+				return fmt.Errorf("failed to open SFTP session: %w", err)
+			}
+		}
+		client, err := sftp.NewClient(s.client)
 		if err != nil {
+			release()
 			return fmt.Errorf("failed to create SFTP client: %w", err)
 		}
+		s.sftpClient, s.sftpRelease = client, release
 	}
 	return nil
 }
@@ -291,7 +379,7 @@ func (s *SSHConnector) writeFileFromReader(ctx context.Context, content io.Reade
 		opts = *options
 	}
 
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return err
 	}
 
@@ -307,7 +395,7 @@ func (s *SSHConnector) sudoWrite(ctx context.Context, content io.Reader, dstPath
 	defer func() {
 		cleanupCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancel()
-		_, _, err := s.Exec(cleanupCtx, fmt.Sprintf("rm -f %s", shellEscape(tmpPath)), nil)
+		_, _, err := s.Exec(cleanupCtx, fmt.Sprintf("rm -f %s", ShellEscape(tmpPath)), nil)
 		if err != nil {
 			log.Errorf("%v Warning: failed to remove temporary file %s on host %s: %v\n", os.Stderr, tmpPath, s.connCfg.Host, err)
 		}
@@ -320,14 +408,14 @@ func (s *SSHConnector) sudoWrite(ctx context.Context, content io.Reader, dstPath
 
 	destDir := filepath.Dir(dstPath)
 	if destDir != "." && destDir != "/" && destDir != "" {
-		mkdirCmd := fmt.Sprintf("mkdir -p %s", shellEscape(destDir))
+		mkdirCmd := fmt.Sprintf("mkdir -p %s", ShellEscape(destDir))
 		_, stderr, mkdirErr := s.Exec(ctx, mkdirCmd, &ExecOptions{Sudo: true})
 		if mkdirErr != nil {
 			return fmt.Errorf("failed to create destination directory %s with sudo: %s (underlying error %w)", destDir, string(stderr), mkdirErr)
 		}
 	}
 
-	mvCmd := fmt.Sprintf("mv %s %s", shellEscape(tmpPath), shellEscape(dstPath))
+	mvCmd := fmt.Sprintf("mv %s %s", ShellEscape(tmpPath), ShellEscape(dstPath))
 	_, stderr, mvErr := s.Exec(ctx, mvCmd, &ExecOptions{Sudo: true})
 	if mvErr != nil {
 		return fmt.Errorf("failed to move file to %s with sudo: %s (underlying error %w)", dstPath, string(stderr), mvErr)
@@ -337,7 +425,7 @@ func (s *SSHConnector) sudoWrite(ctx context.Context, content io.Reader, dstPath
 		if _, err := strconv.ParseUint(opts.Permissions, 8, 32); err != nil {
 			return fmt.Errorf("invalid permissions format '%s': %w", opts.Permissions, err)
 		}
-		chmodCmd := fmt.Sprintf("chmod %s %s", shellEscape(opts.Permissions), shellEscape(dstPath))
+		chmodCmd := fmt.Sprintf("chmod %s %s", ShellEscape(opts.Permissions), ShellEscape(dstPath))
 		_, stderr, chmodErr := s.Exec(ctx, chmodCmd, &ExecOptions{Sudo: true})
 		if chmodErr != nil {
 			return fmt.Errorf("failed to set permissions on %s with sudo: %s (underlying error %w)", dstPath, string(stderr), chmodErr)
@@ -349,7 +437,7 @@ func (s *SSHConnector) sudoWrite(ctx context.Context, content io.Reader, dstPath
 		if opts.Group != "" {
 			ownerAndGroup = fmt.Sprintf("%s:%s", opts.Owner, opts.Group)
 		}
-		chownCmd := fmt.Sprintf("chown %s %s", shellEscape(ownerAndGroup), shellEscape(dstPath))
+		chownCmd := fmt.Sprintf("chown %s %s", ShellEscape(ownerAndGroup), ShellEscape(dstPath))
 		_, stderr, chownErr := s.Exec(ctx, chownCmd, &ExecOptions{Sudo: true})
 		if chownErr != nil {
 			return fmt.Errorf("failed to set ownership on %s with sudo: %s (underlying error %w)", dstPath, string(stderr), chownErr)
@@ -358,7 +446,7 @@ func (s *SSHConnector) sudoWrite(ctx context.Context, content io.Reader, dstPath
 		// If Sudo is true and no owner specified, default to root:root (or just root)
 		// This mimics 'sudo tee' behavior which creates file as root.
 		// Since 'mv' might preserve ownership (if rename), we must force it.
-		chownCmd := fmt.Sprintf("chown root %s", shellEscape(dstPath))
+		chownCmd := fmt.Sprintf("chown root %s", ShellEscape(dstPath))
 		_, stderr, chownErr := s.Exec(ctx, chownCmd, &ExecOptions{Sudo: true})
 		if chownErr != nil {
 			// Try chown 0 if root fails? No, root should exist.
@@ -375,7 +463,7 @@ func (s *SSHConnector) nonSudoWrite(ctx context.Context, content io.Reader, dstP
 
 func (s *SSHConnector) writeFileViaSFTP(ctx context.Context, content io.Reader, destPath, permissions string) error {
 	log := logger.Get()
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return fmt.Errorf("sftp client not available for writeFileViaSFTP on host %s: %w", s.connCfg.Host, err)
 	}
 
@@ -410,7 +498,7 @@ func (s *SSHConnector) writeFileViaSFTP(ctx context.Context, content io.Reader,
 }
 
 func (s *SSHConnector) Stat(ctx context.Context, path string) (*FileStat, error) {
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return nil, err
 	}
 	fi, err := s.sftpClient.Lstat(path)
@@ -433,7 +521,7 @@ func (s *SSHConnector) Stat(ctx context.Context, path string) (*FileStat, error)
 func (s *SSHConnector) StatWithOptions(ctx context.Context, path string, opts *StatOptions) (*FileStat, error) {
 	useSudo := opts != nil && opts.Sudo
 
-	if err := s.ensureSftp(); err == nil {
+	if err := s.ensureSftp(ctx); err == nil {
 		fi, err := s.sftpClient.Lstat(path)
 		if err == nil {
 			return &FileStat{
@@ -481,7 +569,7 @@ func (s *SSHConnector) LookPath(ctx context.Context, file string) (string, error
 		return "", fmt.Errorf("invalid characters in executable name for LookPath: %q", file)
 	}
 
-	cmd := fmt.Sprintf("which %s", shellEscape(file))
+	cmd := fmt.Sprintf("which %s", ShellEscape(file))
 	stdout, stderr, err := s.Exec(ctx, cmd, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to find executable '%s': %s (underlying error: %w)", file, string(stderr), err)
@@ -498,7 +586,7 @@ func (s *SSHConnector) LookPathWithOptions(ctx context.Context, file string, opt
 		return "", fmt.Errorf("invalid characters in executable name for LookPath: %q", file)
 	}
 
-	cmd := fmt.Sprintf("which %s", shellEscape(file))
+	cmd := fmt.Sprintf("which %s", ShellEscape(file))
 
 	useSudo := opts != nil && opts.Sudo
 
@@ -571,7 +659,7 @@ func (s *SSHConnector) GetOS(ctx context.Context) (*OS, error) {
 }
 
 func (s *SSHConnector) ReadFile(ctx context.Context, path string) ([]byte, error) {
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return nil, fmt.Errorf("sftp client not available for ReadFile on host %s: %w", s.connCfg.Host, err)
 	}
 	file, err := s.sftpClient.Open(path)
@@ -593,7 +681,7 @@ func (s *SSHConnector) ReadFileWithOptions(ctx context.Context, path string, opt
 		useSudo = true
 	}
 	if !useSudo {
-		if err := s.ensureSftp(); err != nil {
+		if err := s.ensureSftp(ctx); err != nil {
 			return nil, fmt.Errorf("sftp client not available for ReadFile on host %s: %w", s.connCfg.Host, err)
 		}
 		file, err := s.sftpClient.Open(path)
@@ -619,7 +707,7 @@ func (s *SSHConnector) ReadFileWithOptions(ctx context.Context, path string, opt
 }
 
 func (s *SSHConnector) Mkdir(ctx context.Context, path string, perm string) error {
-	escapedPath := shellEscape(path)
+	escapedPath := ShellEscape(path)
 
 	cmd := fmt.Sprintf("mkdir -p %s", escapedPath)
 	_, stderr, err := s.Exec(ctx, cmd, &ExecOptions{Sudo: false})
@@ -631,7 +719,7 @@ func (s *SSHConnector) Mkdir(ctx context.Context, path string, perm string) erro
 		if _, errP := strconv.ParseUint(perm, 8, 32); errP != nil {
 			return fmt.Errorf("invalid permission format '%s' for Mkdir: %w", perm, errP)
 		}
-		chmodCmd := fmt.Sprintf("chmod %s %s", shellEscape(perm), escapedPath)
+		chmodCmd := fmt.Sprintf("chmod %s %s", ShellEscape(perm), escapedPath)
 		_, chmodStderr, chmodErr := s.Exec(ctx, chmodCmd, &ExecOptions{Sudo: false})
 		if chmodErr != nil {
 			return fmt.Errorf("failed to chmod directory %s to %s: %s (underlying error: %w)", path, perm, string(chmodStderr), chmodErr)
@@ -654,7 +742,7 @@ func (s *SSHConnector) Remove(ctx context.Context, path string, opts RemoveOptio
 	if opts.Recursive {
 		flags += "r"
 	}
-	cmd := fmt.Sprintf("rm %s %s", flags, shellEscape(path))
+	cmd := fmt.Sprintf("rm %s %s", flags, ShellEscape(path))
 
 	_, stderr, err := s.Exec(ctx, cmd, &ExecOptions{Sudo: opts.Sudo})
 	if err != nil {
@@ -665,7 +753,7 @@ func (s *SSHConnector) Remove(ctx context.Context, path string, opts RemoveOptio
 
 func (s *SSHConnector) GetFileChecksum(ctx context.Context, path string, checksumType string) (string, error) {
 	var checksumCmd string
-	escapedPath := shellEscape(path)
+	escapedPath := ShellEscape(path)
 
 	switch strings.ToLower(checksumType) {
 	case "sha256":
@@ -832,7 +920,7 @@ func (s *SSHConnector) Upload(ctx context.Context, localPath, remotePath string,
 }
 
 func (s *SSHConnector) Download(ctx context.Context, remotePath, localPath string, options *FileTransferOptions) error {
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return err
 	}
 
@@ -866,7 +954,7 @@ func (s *SSHConnector) Download(ctx context.Context, remotePath, localPath strin
 func (s *SSHConnector) DownloadDir(ctx context.Context, remoteDir, localDir string, options *FileTransferOptions) error {
 	log := logger.Get()
 
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return err
 	}
 
@@ -1012,7 +1100,7 @@ func (s *SSHConnector) copyDirViaTar(ctx context.Context, srcDir, dstDir string,
 	defer func() {
 		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		_, _, rmErr := s.Exec(cleanupCtx, fmt.Sprintf("rm -f %s", shellEscape(tmpPath)), &ExecOptions{Sudo: false})
+		_, _, rmErr := s.Exec(cleanupCtx, fmt.Sprintf("rm -f %s", ShellEscape(tmpPath)), &ExecOptions{Sudo: false})
 		if rmErr != nil {
 			log.Errorf("%v Warning: failed to remove temporary archive %s on host %s: %v\n", os.Stderr, tmpPath, s.connCfg.Host, rmErr)
 		}
@@ -1022,20 +1110,20 @@ func (s *SSHConnector) copyDirViaTar(ctx context.Context, srcDir, dstDir string,
 
 	destParentDir := filepath.Dir(dstDir)
 	if destParentDir != "." && destParentDir != "/" && destParentDir != "" {
-		_, stderr, mkdirErr := s.Exec(ctx, fmt.Sprintf("mkdir -p %s", shellEscape(destParentDir)), execOptsSudo)
+		_, stderr, mkdirErr := s.Exec(ctx, fmt.Sprintf("mkdir -p %s", ShellEscape(destParentDir)), execOptsSudo)
 		if mkdirErr != nil {
 			return fmt.Errorf("failed to create remote parent directory %s (sudo: %t): %s (underlying error: %w)", destParentDir, opts.Sudo, string(stderr), mkdirErr)
 		}
 	}
 
-	_, _, _ = s.Exec(ctx, fmt.Sprintf("rm -rf %s", shellEscape(dstDir)), execOptsSudo)
+	_, _, _ = s.Exec(ctx, fmt.Sprintf("rm -rf %s", ShellEscape(dstDir)), execOptsSudo)
 
-	_, stderr, mkdirErr := s.Exec(ctx, fmt.Sprintf("mkdir -p %s", shellEscape(dstDir)), execOptsSudo)
+	_, stderr, mkdirErr := s.Exec(ctx, fmt.Sprintf("mkdir -p %s", ShellEscape(dstDir)), execOptsSudo)
 	if mkdirErr != nil {
 		return fmt.Errorf("failed to create remote destination directory %s (sudo: %t): %s (underlying error: %w)", dstDir, opts.Sudo, string(stderr), mkdirErr)
 	}
 
-	extractCmd := fmt.Sprintf("tar -xzf %s -C %s", shellEscape(tmpPath), shellEscape(dstDir))
+	extractCmd := fmt.Sprintf("tar -xzf %s -C %s", ShellEscape(tmpPath), ShellEscape(dstDir))
 	_, stderr, execErr := s.Exec(ctx, extractCmd, execOptsSudo)
 	if execErr != nil {
 		return fmt.Errorf("failed to extract remote archive %s to %s (sudo: %t): %s (underlying error: %w)", tmpPath, dstDir, opts.Sudo, string(stderr), execErr)
@@ -1045,7 +1133,7 @@ func (s *SSHConnector) copyDirViaTar(ctx context.Context, srcDir, dstDir string,
 		if _, errP := strconv.ParseUint(opts.Permissions, 8, 32); errP != nil {
 			return fmt.Errorf("invalid permissions format '%s' for %s: %w", opts.Permissions, dstDir, errP)
 		}
-		chmodCmd := fmt.Sprintf("chmod -R %s %s", shellEscape(opts.Permissions), shellEscape(dstDir))
+		chmodCmd := fmt.Sprintf("chmod -R %s %s", ShellEscape(opts.Permissions), ShellEscape(dstDir))
 		_, stderr, errChmod := s.Exec(ctx, chmodCmd, execOptsSudo)
 		if errChmod != nil {
 			return fmt.Errorf("failed to set permissions on %s to %s (sudo: %t): %s (underlying error: %w)", dstDir, opts.Permissions, opts.Sudo, string(stderr), errChmod)
@@ -1056,7 +1144,7 @@ func (s *SSHConnector) copyDirViaTar(ctx context.Context, srcDir, dstDir string,
 		if opts.Group != "" {
 			ownerAndGroup = fmt.Sprintf("%s:%s", opts.Owner, opts.Group)
 		}
-		chownCmd := fmt.Sprintf("chown -R %s %s", shellEscape(ownerAndGroup), shellEscape(dstDir))
+		chownCmd := fmt.Sprintf("chown -R %s %s", ShellEscape(ownerAndGroup), ShellEscape(dstDir))
 		_, stderr, errChown := s.Exec(ctx, chownCmd, execOptsSudo)
 		if errChown != nil {
 			return fmt.Errorf("failed to set ownership on %s to %s (sudo: %t): %s (underlying error: %w)", dstDir, ownerAndGroup, opts.Sudo, string(stderr), errChown)
@@ -1081,7 +1169,7 @@ func (s *SSHConnector) Fetch(ctx context.Context, remotePath, localPath string,
 	defer func() {
 		cleanupCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancel()
-		_, _, rmErr := s.Exec(cleanupCtx, fmt.Sprintf("rm -f %s", shellEscape(remoteTempPath)), &ExecOptions{Sudo: true})
+		_, _, rmErr := s.Exec(cleanupCtx, fmt.Sprintf("rm -f %s", ShellEscape(remoteTempPath)), &ExecOptions{Sudo: true})
 		if rmErr != nil {
 			log.Errorf("%v Warning: failed to remove temporary fetch file %s on host %s: %v\n", os.Stderr, remoteTempPath, s.connCfg.Host, rmErr)
 		}
@@ -1097,12 +1185,12 @@ func (s *SSHConnector) Fetch(ctx context.Context, remotePath, localPath string,
 		cpFlags += "r"
 	}
 
-	cpCmd := fmt.Sprintf("cp %s %s %s", cpFlags, shellEscape(remotePath), shellEscape(remoteTempPath))
+	cpCmd := fmt.Sprintf("cp %s %s %s", cpFlags, ShellEscape(remotePath), ShellEscape(remoteTempPath))
 	if _, stderr, err := s.Exec(ctx, cpCmd, &ExecOptions{Sudo: true}); err != nil {
 		return fmt.Errorf("failed to copy remote file to temporary path with sudo: %s (underlying error: %w)", string(stderr), err)
 	}
 
-	chownCmd := fmt.Sprintf("chown %s %s", shellEscape(s.connCfg.User), shellEscape(remoteTempPath))
+	chownCmd := fmt.Sprintf("chown %s %s", ShellEscape(s.connCfg.User), ShellEscape(remoteTempPath))
 	if _, stderr, err := s.Exec(ctx, chownCmd, &ExecOptions{Sudo: true}); err != nil {
 		log.Warnf("Failed to chown temporary file %s, SFTP download might fail: %s", remoteTempPath, string(stderr))
 	}
@@ -1166,7 +1254,7 @@ func (s *SSHConnector) Write(ctx context.Context, content []byte, path string, o
 }
 
 func (s *SSHConnector) IsFile(ctx context.Context, path string) (bool, error) {
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return false, err
 	}
 	fi, err := s.sftpClient.Lstat(path)
@@ -1180,7 +1268,7 @@ func (s *SSHConnector) IsFile(ctx context.Context, path string) (bool, error) {
 }
 
 func (s *SSHConnector) IsDir(ctx context.Context, path string) (bool, error) {
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return false, err
 	}
 	fi, err := s.sftpClient.Lstat(path)
@@ -1194,7 +1282,7 @@ func (s *SSHConnector) IsDir(ctx context.Context, path string) (bool, error) {
 }
 
 func (s *SSHConnector) GetFileMode(ctx context.Context, path string) (fs.FileMode, error) {
-	if err := s.ensureSftp(); err != nil {
+	if err := s.ensureSftp(ctx); err != nil {
 		return 0, err
 	}
 	fi, err := s.sftpClient.Lstat(path)
@@ -1205,7 +1293,7 @@ func (s *SSHConnector) GetFileMode(ctx context.Context, path string) (fs.FileMod
 }
 
 func (s *SSHConnector) GetFileOwner(ctx context.Context, path string) (string, string, error) {
-	cmd := fmt.Sprintf("stat -c '%%U %%G' %s", shellEscape(path))
+	cmd := fmt.Sprintf("stat -c '%%U %%G' %s", ShellEscape(path))
 	stdout, _, err := s.Exec(ctx, cmd, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get file owner for %s: %w", path, err)
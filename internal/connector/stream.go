@@ -0,0 +1,236 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// streamReader is one output stream of a command started by ExecStream. Closing it before EOF
+// discards the rest of the stream, so an unread stream never blocks the command.
+type streamReader struct {
+	r io.Reader
+	// closer, if set, is closed once the stream is exhausted or abandoned.
+	closer      io.Closer
+	closeOnce   sync.Once
+	releaseOnce sync.Once
+}
+
+func newStreamReader(r io.Reader, closer io.Closer) *streamReader {
+	return &streamReader{r: r, closer: closer}
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil {
+		s.release()
+	}
+	return n, err
+}
+
+func (s *streamReader) Close() error {
+	s.closeOnce.Do(func() {
+		go func() {
+			_, _ = io.Copy(io.Discard, s.r)
+			s.release()
+		}()
+	})
+	return nil
+}
+
+func (s *streamReader) release() {
+	s.releaseOnce.Do(func() {
+		if s.closer != nil {
+			_ = s.closer.Close()
+		}
+	})
+}
+
+// streamContext derives the context of a streamed command, bounded by timeout if set.
+func streamContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// streamCommand builds the command line for a streamed command. With sudo the whole command,
+// including the change of directory, runs in a root shell.
+func streamCommand(cmd string, opts ExecOptions, password string) string {
+	finalCmd := cmd
+	if opts.Dir != "" {
+		finalCmd = fmt.Sprintf("cd %s && %s", ShellEscape(opts.Dir), cmd)
+	}
+	if !opts.Sudo {
+		return finalCmd
+	}
+	if password != "" {
+		return "sudo -S -p '' -E -- /bin/sh -c " + ShellEscape(finalCmd)
+	}
+	return "sudo -E -- /bin/sh -c " + ShellEscape(finalCmd)
+}
+
+// streamStdin returns the stdin of a streamed command, feeding the sudo password first if needed.
+func streamStdin(opts ExecOptions, password string) io.Reader {
+	stdin := bytes.NewReader(opts.Stdin)
+	if opts.Sudo && password != "" {
+		return io.MultiReader(strings.NewReader(password+"\n"), stdin)
+	}
+	return stdin
+}
+
+// ExecStream starts cmd on the remote host and returns its stdout and stderr as they are produced.
+// Both readers must be read to EOF or closed; wait blocks until the command exits and returns a
+// *CommandError for a non-zero exit. Retries and Stream in opts are ignored.
+func (s *SSHConnector) ExecStream(ctx context.Context, cmd string, opts *ExecOptions) (stdout, stderr io.ReadCloser, wait func() error, err error) {
+	if stallErr := s.stalled(); stallErr != nil {
+		return nil, nil, nil, stallErr
+	}
+	if !s.IsConnected() {
+		return nil, nil, nil, &ConnectionError{Host: s.connCfg.Host, Err: fmt.Errorf("not connected")}
+	}
+	effectiveOptions := ExecOptions{}
+	if opts != nil {
+		effectiveOptions = *opts
+	}
+
+	finalCmd, err := s.cmdWrapper.Wrap(streamCommand(cmd, effectiveOptions, s.connCfg.Password))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	session, release, err := s.newSession(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	for _, envVar := range effectiveOptions.Env {
+		parts := strings.SplitN(envVar, "=", 2)
+		if len(parts) == 2 {
+			_ = session.Setenv(parts[0], parts[1])
+		}
+	}
+
+	session.Stdin = streamStdin(effectiveOptions, s.connCfg.Password)
+	stdoutPipe, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		release()
+		return nil, nil, nil, fmt.Errorf("failed to open stdout of command: %w", err)
+	}
+	stderrPipe, err := session.StderrPipe()
+	if err != nil {
+		session.Close()
+		release()
+		return nil, nil, nil, fmt.Errorf("failed to open stderr of command: %w", err)
+	}
+	if err := session.Start(finalCmd); err != nil {
+		session.Close()
+		release()
+		return nil, nil, nil, fmt.Errorf("failed to start command '%s': %w", finalCmd, err)
+	}
+
+	runCtx, cancel := streamContext(ctx, effectiveOptions.Timeout)
+	stop := context.AfterFunc(runCtx, func() {
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+		release()
+	})
+	wait = sync.OnceValue(func() error {
+		defer cancel()
+		defer release()
+		defer session.Close()
+		err := session.Wait()
+		if !stop() {
+			return runCtx.Err()
+		}
+		if err == nil {
+			return nil
+		}
+		if stallErr := s.stalled(); stallErr != nil {
+			return stallErr
+		}
+		exitCode := -1
+		if exitErr, ok := err.(*ssh.ExitError); ok {
+			exitCode = exitErr.ExitStatus()
+		}
+		return &CommandError{Cmd: cmd, ExitCode: exitCode, Underlying: err}
+	})
+	return newStreamReader(stdoutPipe, nil), newStreamReader(stderrPipe, nil), wait, nil
+}
+
+// ExecStream starts cmd locally and returns its stdout and stderr as they are produced. Both readers
+// must be read to EOF or closed; wait blocks until the command exits and returns a *CommandError for
+// a non-zero exit. Retries and Stream in opts are ignored.
+func (l *LocalConnector) ExecStream(ctx context.Context, cmd string, opts *ExecOptions) (stdout, stderr io.ReadCloser, wait func() error, err error) {
+	effectiveOptions := ExecOptions{}
+	if opts != nil {
+		effectiveOptions = *opts
+	}
+
+	finalCmd, err := l.cmdWrapper.Wrap(streamCommand(cmd, effectiveOptions, l.connCfg.Password))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Real pipes keep the streams independent: each reaches EOF as soon as the command closes it,
+	// whether or not the other one has been read.
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return nil, nil, nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	runCtx, cancel := streamContext(ctx, effectiveOptions.Timeout)
+	actualCmd := exec.CommandContext(runCtx, "/bin/sh", "-c", finalCmd)
+	if len(effectiveOptions.Env) > 0 {
+		actualCmd.Env = append(os.Environ(), effectiveOptions.Env...)
+	}
+	actualCmd.Stdin = streamStdin(effectiveOptions, l.connCfg.Password)
+	actualCmd.Stdout = stdoutW
+	actualCmd.Stderr = stderrW
+	err = actualCmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		cancel()
+		stdoutR.Close()
+		stderrR.Close()
+		return nil, nil, nil, fmt.Errorf("failed to start command '%s': %w", finalCmd, err)
+	}
+
+	stdoutStream, stderrStream := newStreamReader(stdoutR, stdoutR), newStreamReader(stderrR, stderrR)
+	// Children of the killed shell may still hold the pipes open, so cancellation closes them.
+	stop := context.AfterFunc(runCtx, func() {
+		stdoutStream.release()
+		stderrStream.release()
+	})
+	wait = sync.OnceValue(func() error {
+		defer cancel()
+		err := actualCmd.Wait()
+		if !stop() {
+			return runCtx.Err()
+		}
+		if err == nil {
+			return nil
+		}
+		exitCode := -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		return &CommandError{Cmd: cmd, ExitCode: exitCode, Underlying: err}
+	})
+	return stdoutStream, stderrStream, wait, nil
+}
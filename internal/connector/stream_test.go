@@ -0,0 +1,220 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestStreamCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ExecOptions
+		password string
+		expected string
+	}{
+		{name: "plain", expected: "tail -f /var/log/syslog"},
+		{name: "dir", opts: ExecOptions{Dir: "/var/log"}, expected: "cd '/var/log' && tail -f /var/log/syslog"},
+		{name: "sudo", opts: ExecOptions{Sudo: true}, expected: "sudo -E -- /bin/sh -c 'tail -f /var/log/syslog'"},
+		{name: "sudo with password", opts: ExecOptions{Sudo: true}, password: "secret", expected: "sudo -S -p '' -E -- /bin/sh -c 'tail -f /var/log/syslog'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamCommand("tail -f /var/log/syslog", tt.opts, tt.password); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLocalConnector_ExecStream(t *testing.T) {
+	conn, err := NewLocalConnector()
+	if err != nil {
+		t.Fatalf("Failed to create local connector: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stdout, stderr, wait, err := conn.ExecStream(ctx, "echo first; echo oops >&2; sleep 1; echo second; exit 3", nil)
+	if err != nil {
+		t.Fatalf("ExecStream failed: %v", err)
+	}
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- wait() }()
+
+	lines := bufio.NewScanner(stdout)
+	if !lines.Scan() || lines.Text() != "first" {
+		t.Fatalf("expected first line %q, got %q", "first", lines.Text())
+	}
+	select {
+	case <-waitErr:
+		t.Fatal("first line should arrive while the command is still running")
+	default:
+	}
+	if !lines.Scan() || lines.Text() != "second" {
+		t.Errorf("expected second line %q, got %q", "second", lines.Text())
+	}
+	if lines.Scan() {
+		t.Errorf("expected EOF after the last line, got %q", lines.Text())
+	}
+	errOut, _ := io.ReadAll(stderr)
+	if string(errOut) != "oops\n" {
+		t.Errorf("expected stderr %q, got %q", "oops\n", errOut)
+	}
+
+	var cmdErr *CommandError
+	if err := <-waitErr; !errors.As(err, &cmdErr) || cmdErr.ExitCode != 3 {
+		t.Errorf("expected a CommandError with exit code 3, got %v", err)
+	}
+}
+
+func TestLocalConnector_ExecStreamClosedReaderDoesNotBlock(t *testing.T) {
+	conn, err := NewLocalConnector()
+	if err != nil {
+		t.Fatalf("Failed to create local connector: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stdout, stderr, wait, err := conn.ExecStream(ctx, "head -c 1048576 /dev/zero", nil)
+	if err != nil {
+		t.Fatalf("ExecStream failed: %v", err)
+	}
+	stdout.Close()
+	stderr.Close()
+	if err := wait(); err != nil {
+		t.Errorf("expected the command to finish with its output discarded, got %v", err)
+	}
+}
+
+func TestLocalConnector_ExecStreamCancel(t *testing.T) {
+	conn, err := NewLocalConnector()
+	if err != nil {
+		t.Fatalf("Failed to create local connector: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stdout, _, wait, err := conn.ExecStream(ctx, "echo started; sleep 30", nil)
+	if err != nil {
+		t.Fatalf("ExecStream failed: %v", err)
+	}
+	if line, _ := bufio.NewReader(stdout).ReadString('\n'); line != "started\n" {
+		t.Fatalf("expected %q, got %q", "started\n", line)
+	}
+	cancel()
+
+	start := time.Now()
+	if err := wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancel should stop the command promptly, took %v", elapsed)
+	}
+}
+
+// startTestStreamSSHServer serves sessions that write "first" to stdout and "oops" to stderr, wait
+// for release, then write "second" and exit with status 3. The exec'd command is sent on commands.
+func startTestStreamSSHServer(t *testing.T, commands chan<- string, release <-chan struct{}) *net.TCPAddr {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	return startTestListener(t, func(conn net.Conn) {
+		sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		defer sconn.Close()
+		go ssh.DiscardRequests(reqs)
+		for newCh := range chans {
+			ch, chReqs, err := newCh.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer ch.Close()
+				for req := range chReqs {
+					if req.Type != "exec" {
+						req.Reply(false, nil)
+						continue
+					}
+					var payload struct{ Command string }
+					_ = ssh.Unmarshal(req.Payload, &payload)
+					req.Reply(true, nil)
+					commands <- payload.Command
+
+					ch.Write([]byte("first\n"))
+					ch.Stderr().Write([]byte("oops\n"))
+					<-release
+					ch.Write([]byte("second\n"))
+					ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{3}))
+					return
+				}
+			}()
+		}
+	})
+}
+
+func TestSSHConnector_ExecStream(t *testing.T) {
+	commands := make(chan string, 1)
+	release := make(chan struct{})
+	s := connectTestPtyServer(t, startTestStreamSSHServer(t, commands, release))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stdout, stderr, wait, err := s.ExecStream(ctx, "journalctl -f", &ExecOptions{Sudo: true})
+	if err != nil {
+		t.Fatalf("ExecStream failed: %v", err)
+	}
+	if got := <-commands; got != "sudo -S -p '' -E -- /bin/sh -c 'journalctl -f'" {
+		t.Errorf("unexpected command %q", got)
+	}
+
+	lines := bufio.NewReader(stdout)
+	if line, _ := lines.ReadString('\n'); line != "first\n" {
+		t.Fatalf("expected %q before the command finishes, got %q", "first\n", line)
+	}
+	close(release)
+	rest, _ := io.ReadAll(lines)
+	if string(rest) != "second\n" {
+		t.Errorf("expected %q, got %q", "second\n", rest)
+	}
+	if errOut, _ := io.ReadAll(stderr); strings.TrimSpace(string(errOut)) != "oops" {
+		t.Errorf("expected stderr %q, got %q", "oops", errOut)
+	}
+
+	var cmdErr *CommandError
+	if err := wait(); !errors.As(err, &cmdErr) || cmdErr.ExitCode != 3 {
+		t.Errorf("expected a CommandError with exit code 3, got %v", err)
+	}
+}
+
+func TestSSHConnector_ExecStreamNotConnected(t *testing.T) {
+	s := NewSSHConnector(nil)
+	s.connCfg = ConnectionCfg{Host: "192.168.1.100"}
+	_, _, _, err := s.ExecStream(context.Background(), "true", nil)
+	if _, ok := err.(*ConnectionError); !ok {
+		t.Fatalf("expected *ConnectionError, got %v", err)
+	}
+}
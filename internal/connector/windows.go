@@ -0,0 +1,356 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// WindowsConnector manages Windows hosts through the OpenSSH server shipped with Windows. Commands
+// are run as PowerShell scripts and files are transferred over SFTP. The connecting account must be
+// an administrator: Sudo is ignored, as are file owners and POSIX permissions.
+type WindowsConnector struct {
+	*SSHConnector
+}
+
+func NewWindowsConnector(pool *ConnectionPool) *WindowsConnector {
+	return &WindowsConnector{SSHConnector: NewSSHConnector(pool)}
+}
+
+// PowerShellQuote quotes s as a PowerShell single-quoted string, doubling any embedded single quote.
+func PowerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// encodePowerShell encodes script for powershell.exe -EncodedCommand, which expects base64 of the
+// UTF-16LE bytes and avoids any quoting by the remote cmd.exe.
+func encodePowerShell(script string) string {
+	units := utf16.Encode([]rune(script))
+	buf := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[2*i:], u)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// powershellCommand wraps script in a powershell.exe invocation that applies the environment and
+// working directory of opts. Failing cmdlets stop the script, and the exit code of the last native
+// command becomes the exit code of the invocation.
+func powershellCommand(script string, opts ExecOptions) string {
+	var b strings.Builder
+	b.WriteString("$ErrorActionPreference = 'Stop'\n")
+	b.WriteString("$ProgressPreference = 'SilentlyContinue'\n")
+	for _, envVar := range opts.Env {
+		parts := strings.SplitN(envVar, "=", 2)
+		if len(parts) == 2 {
+			fmt.Fprintf(&b, "${env:%s} = %s\n", parts[0], PowerShellQuote(parts[1]))
+		}
+	}
+	if opts.Dir != "" {
+		fmt.Fprintf(&b, "Set-Location -LiteralPath %s\n", PowerShellQuote(opts.Dir))
+	}
+	b.WriteString(script)
+	b.WriteString("\nif ($LASTEXITCODE) { exit $LASTEXITCODE }\n")
+	return "powershell.exe -NoLogo -NoProfile -NonInteractive -ExecutionPolicy Bypass -EncodedCommand " + encodePowerShell(b.String())
+}
+
+// sftpPath converts a Windows path such as C:\k\kubelet.exe to the /C:/k/kubelet.exe form expected
+// by the OpenSSH SFTP server.
+func sftpPath(path string) string {
+	p := strings.ReplaceAll(path, `\`, "/")
+	if len(p) >= 2 && p[1] == ':' {
+		p = "/" + p
+	}
+	return p
+}
+
+func (w *WindowsConnector) Exec(ctx context.Context, cmd string, opts *ExecOptions) (stdout, stderr []byte, err error) {
+	effective := ExecOptions{}
+	if opts != nil {
+		effective = *opts
+	}
+	cmd = powershellCommand(cmd, effective)
+	effective.Sudo, effective.Env, effective.Dir = false, nil, ""
+	return w.SSHConnector.Exec(ctx, cmd, &effective)
+}
+
+func (w *WindowsConnector) ExecStream(ctx context.Context, cmd string, opts *ExecOptions) (stdout, stderr io.ReadCloser, wait func() error, err error) {
+	effective := ExecOptions{}
+	if opts != nil {
+		effective = *opts
+	}
+	cmd = powershellCommand(cmd, effective)
+	effective.Sudo, effective.Env, effective.Dir = false, nil, ""
+	return w.SSHConnector.ExecStream(ctx, cmd, &effective)
+}
+
+func (w *WindowsConnector) ExecInteractive(ctx context.Context, cmd string, opts *InteractiveOptions) error {
+	effective := InteractiveOptions{}
+	if opts != nil {
+		effective = *opts
+	}
+	if cmd != "" || effective.Dir != "" {
+		if cmd == "" {
+			cmd = "powershell.exe -NoLogo"
+		}
+		cmd = powershellCommand(cmd, ExecOptions{Env: effective.Env, Dir: effective.Dir})
+	}
+	effective.Sudo = false
+	effective.Dir = ""
+	return w.SSHConnector.ExecInteractive(ctx, cmd, &effective)
+}
+
+func (w *WindowsConnector) Run(ctx context.Context, cmd string, opts *RunOptions) (RunResult, error) {
+	stdout, stderr, err := w.Exec(ctx, cmd, opts)
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		if cmdErr, ok := err.(*CommandError); ok {
+			exitCode = cmdErr.ExitCode
+		}
+	}
+	return RunResult{Stdout: stdout, Stderr: stderr, ExitCode: exitCode}, err
+}
+
+// windowsArch maps PROCESSOR_ARCHITECTURE to the Go architecture names used elsewhere.
+func windowsArch(arch string) string {
+	switch strings.ToUpper(strings.TrimSpace(arch)) {
+	case "AMD64", "X64":
+		return "amd64"
+	case "ARM64":
+		return "arm64"
+	case "X86":
+		return "386"
+	default:
+		return strings.ToLower(strings.TrimSpace(arch))
+	}
+}
+
+func (w *WindowsConnector) GetOSRelease(ctx context.Context) (map[string]string, error) {
+	script := `$os = Get-CimInstance -ClassName Win32_OperatingSystem
+"NAME=$($os.Caption)"
+"VERSION_ID=$($os.Version)"
+"BUILD=$($os.BuildNumber)"
+"ARCH=$($env:PROCESSOR_ARCHITECTURE)"`
+	stdout, stderr, err := w.Exec(ctx, script, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Windows version: %s (underlying error: %w)", string(stderr), err)
+	}
+	vars := parseKeyValues(strings.ReplaceAll(string(stdout), "\r\n", "\n"), "=", "")
+	vars["ID"] = "windows"
+	vars["PRETTY_NAME"] = vars["NAME"]
+	return vars, nil
+}
+
+func (w *WindowsConnector) GetOS(ctx context.Context) (*OS, error) {
+	if w.cachedOS != nil {
+		return w.cachedOS, nil
+	}
+	vars, err := w.GetOSRelease(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine OS: %w", err)
+	}
+	w.cachedOS = &OS{
+		ID:         vars["ID"],
+		VersionID:  vars["VERSION_ID"],
+		PrettyName: vars["PRETTY_NAME"],
+		Arch:       windowsArch(vars["ARCH"]),
+		Kernel:     vars["VERSION_ID"],
+	}
+	return w.cachedOS, nil
+}
+
+func (w *WindowsConnector) LookPath(ctx context.Context, file string) (string, error) {
+	if strings.ContainsAny(file, " \t\n\r`;&|$<>()!{}[]*?^~'\"") {
+		return "", fmt.Errorf("invalid characters in executable name for LookPath: %q", file)
+	}
+	cmd := fmt.Sprintf("(Get-Command -CommandType Application -Name %s | Select-Object -First 1).Source", PowerShellQuote(file))
+	stdout, stderr, err := w.Exec(ctx, cmd, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to find executable '%s': %s (underlying error: %w)", file, string(stderr), err)
+	}
+	path := strings.TrimSpace(string(stdout))
+	if path == "" {
+		return "", fmt.Errorf("executable %s not found in PATH", file)
+	}
+	return path, nil
+}
+
+func (w *WindowsConnector) LookPathWithOptions(ctx context.Context, file string, opts *LookPathOptions) (string, error) {
+	return w.LookPath(ctx, file)
+}
+
+func (w *WindowsConnector) Mkdir(ctx context.Context, path string, perm string) error {
+	cmd := fmt.Sprintf("New-Item -ItemType Directory -Force -Path %s | Out-Null", PowerShellQuote(path))
+	if _, stderr, err := w.Exec(ctx, cmd, nil); err != nil {
+		return fmt.Errorf("failed to create directory %s: %s (underlying error: %w)", path, string(stderr), err)
+	}
+	return nil
+}
+
+func (w *WindowsConnector) Remove(ctx context.Context, path string, opts RemoveOptions) error {
+	cmd := fmt.Sprintf("Remove-Item -LiteralPath %s -Force", PowerShellQuote(path))
+	if opts.Recursive {
+		cmd += " -Recurse"
+	}
+	if opts.IgnoreNotExist {
+		cmd = fmt.Sprintf("if (Test-Path -LiteralPath %s) { %s }", PowerShellQuote(path), cmd)
+	}
+	if _, stderr, err := w.Exec(ctx, cmd, &ExecOptions{Timeout: opts.Timeout}); err != nil {
+		return fmt.Errorf("failed to remove %s: %s (underlying error: %w)", path, string(stderr), err)
+	}
+	return nil
+}
+
+func (w *WindowsConnector) GetFileChecksum(ctx context.Context, path string, checksumType string) (string, error) {
+	var algorithm string
+	switch strings.ToLower(checksumType) {
+	case "sha256":
+		algorithm = "SHA256"
+	case "md5":
+		algorithm = "MD5"
+	default:
+		return "", fmt.Errorf("unsupported checksum type '%s' for remote file %s on host %s", checksumType, path, w.connCfg.Host)
+	}
+	cmd := fmt.Sprintf("(Get-FileHash -Algorithm %s -LiteralPath %s).Hash.ToLower()", algorithm, PowerShellQuote(path))
+	stdout, stderr, err := w.Exec(ctx, cmd, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute %s checksum of %s on %s: %s (underlying error: %w)", checksumType, path, w.connCfg.Host, string(stderr), err)
+	}
+	checksum := strings.TrimSpace(string(stdout))
+	if checksum == "" {
+		return "", fmt.Errorf("empty checksum for %s on %s", path, w.connCfg.Host)
+	}
+	return checksum, nil
+}
+
+func (w *WindowsConnector) GetFileOwner(ctx context.Context, path string) (string, string, error) {
+	cmd := fmt.Sprintf("$acl = Get-Acl -LiteralPath %s\n$acl.Owner\n$acl.Group", PowerShellQuote(path))
+	stdout, _, err := w.Exec(ctx, cmd, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get file owner for %s: %w", path, err)
+	}
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(stdout), "\r\n", "\n")), "\n")
+	if len(lines) < 2 {
+		return "", "", fmt.Errorf("unexpected output from Get-Acl: %s", string(stdout))
+	}
+	return strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1]), nil
+}
+
+func (w *WindowsConnector) Stat(ctx context.Context, path string) (*FileStat, error) {
+	return w.SSHConnector.Stat(ctx, sftpPath(path))
+}
+
+func (w *WindowsConnector) StatWithOptions(ctx context.Context, path string, opts *StatOptions) (*FileStat, error) {
+	return w.Stat(ctx, path)
+}
+
+func (w *WindowsConnector) IsFile(ctx context.Context, path string) (bool, error) {
+	return w.SSHConnector.IsFile(ctx, sftpPath(path))
+}
+
+func (w *WindowsConnector) IsDir(ctx context.Context, path string) (bool, error) {
+	return w.SSHConnector.IsDir(ctx, sftpPath(path))
+}
+
+func (w *WindowsConnector) GetFileMode(ctx context.Context, path string) (fs.FileMode, error) {
+	return w.SSHConnector.GetFileMode(ctx, sftpPath(path))
+}
+
+func (w *WindowsConnector) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	return w.SSHConnector.ReadFile(ctx, sftpPath(path))
+}
+
+func (w *WindowsConnector) ReadFileWithOptions(ctx context.Context, path string, opts *FileTransferOptions) ([]byte, error) {
+	return w.ReadFile(ctx, path)
+}
+
+func (w *WindowsConnector) Read(ctx context.Context, path string, opts *ReadOptions) ([]byte, error) {
+	return w.ReadFile(ctx, path)
+}
+
+func (w *WindowsConnector) WriteFile(ctx context.Context, content []byte, destPath string, options *FileTransferOptions) error {
+	return w.writeFileFromReader(ctx, bytes.NewReader(content), destPath)
+}
+
+func (w *WindowsConnector) writeFileFromReader(ctx context.Context, content io.Reader, destPath string) error {
+	return w.SSHConnector.writeFileFromReader(ctx, content, sftpPath(destPath), &FileTransferOptions{})
+}
+
+func (w *WindowsConnector) CopyContent(ctx context.Context, content []byte, dstPath string, options *FileTransferOptions) error {
+	return w.WriteFile(ctx, content, dstPath, options)
+}
+
+func (w *WindowsConnector) Write(ctx context.Context, content []byte, path string, opts *WriteOptions) error {
+	return w.WriteFile(ctx, content, path, nil)
+}
+
+// Copy uploads a local file or directory. Directories are copied file by file over SFTP since the
+// remote side has no POSIX tar.
+func (w *WindowsConnector) Copy(ctx context.Context, srcPath, dstPath string, options *FileTransferOptions) error {
+	srcStat, err := os.Stat(srcPath)
+	if err != nil {
+		return fmt.Errorf("source path %s not found or not accessible: %w", srcPath, err)
+	}
+	if !srcStat.IsDir() {
+		return w.uploadFile(ctx, srcPath, dstPath)
+	}
+	return filepath.WalkDir(srcPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(srcPath, path)
+		if err != nil {
+			return err
+		}
+		return w.uploadFile(ctx, path, strings.TrimRight(dstPath, `\/`)+"/"+filepath.ToSlash(relPath))
+	})
+}
+
+func (w *WindowsConnector) uploadFile(ctx context.Context, srcPath, dstPath string) error {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open source file %s: %w", srcPath, err)
+	}
+	defer srcFile.Close()
+	return w.writeFileFromReader(ctx, srcFile, dstPath)
+}
+
+func (w *WindowsConnector) Upload(ctx context.Context, localPath, remotePath string, options *FileTransferOptions) error {
+	return w.Copy(ctx, localPath, remotePath, options)
+}
+
+func (w *WindowsConnector) Download(ctx context.Context, remotePath, localPath string, options *FileTransferOptions) error {
+	return w.SSHConnector.Download(ctx, sftpPath(remotePath), localPath, options)
+}
+
+func (w *WindowsConnector) DownloadDir(ctx context.Context, remoteDir, localDir string, options *FileTransferOptions) error {
+	return w.SSHConnector.DownloadDir(ctx, sftpPath(remoteDir), localDir, options)
+}
+
+func (w *WindowsConnector) Fetch(ctx context.Context, remotePath, localPath string, options *FileTransferOptions) error {
+	stat, err := w.Stat(ctx, remotePath)
+	if err != nil || !stat.IsExist {
+		return fmt.Errorf("remote source path %s does not exist or is not accessible", remotePath)
+	}
+	if stat.IsDir {
+		return w.DownloadDir(ctx, remotePath, localPath, options)
+	}
+	return w.Download(ctx, remotePath, localPath, options)
+}
+
+var _ Connector = &WindowsConnector{}
@@ -0,0 +1,67 @@
+package connector
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func decodePowerShell(t *testing.T, cmd string) string {
+	t.Helper()
+	const prefix = "powershell.exe -NoLogo -NoProfile -NonInteractive -ExecutionPolicy Bypass -EncodedCommand "
+	if !strings.HasPrefix(cmd, prefix) {
+		t.Fatalf("unexpected command line: %s", cmd)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(cmd, prefix))
+	if err != nil {
+		t.Fatalf("failed to decode command: %v", err)
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(raw[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestPowershellCommand(t *testing.T) {
+	script := decodePowerShell(t, powershellCommand("Get-Service kubelet", ExecOptions{
+		Env: []string{"KUBECONFIG=C:\\k\\config", "NAME=it's"},
+		Dir: `C:\k`,
+	}))
+	for _, want := range []string{
+		"$ErrorActionPreference = 'Stop'\n",
+		"${env:KUBECONFIG} = 'C:\\k\\config'\n",
+		"${env:NAME} = 'it''s'\n",
+		"Set-Location -LiteralPath 'C:\\k'\n",
+		"Get-Service kubelet\nif ($LASTEXITCODE) { exit $LASTEXITCODE }\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestSftpPath(t *testing.T) {
+	tests := map[string]string{
+		`C:\k\kubelet.exe`: "/C:/k/kubelet.exe",
+		"C:/k":             "/C:/k",
+		"/already/posix":   "/already/posix",
+		`relative\dir`:     "relative/dir",
+	}
+	for in, want := range tests {
+		if got := sftpPath(in); got != want {
+			t.Errorf("sftpPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWindowsArch(t *testing.T) {
+	tests := map[string]string{"AMD64": "amd64", "ARM64": "arm64", "x86": "386", "IA64": "ia64"}
+	for in, want := range tests {
+		if got := windowsArch(in); got != want {
+			t.Errorf("windowsArch(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// newAlwaysRunTestGraph builds deploy -> (dns, connectivity) -> remove -> report, where remove is
+// an AlwaysRun node and touching the files of the broken nodes fails.
+func newAlwaysRunTestGraph(broken ...string) (*recordingConnector, *plan.ExecutionGraph) {
+	conn := &recordingConnector{files: map[string]bool{}, broken: map[string]bool{}}
+	for _, name := range broken {
+		conn.broken["/etc/"+name+".yaml"] = true
+	}
+	host := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "node1"})
+
+	g := plan.NewExecutionGraph("AlwaysRun")
+	for _, n := range []struct {
+		name      string
+		deps      []plan.NodeID
+		alwaysRun bool
+	}{
+		{name: "deploy"},
+		{name: "dns", deps: []plan.NodeID{"deploy"}},
+		{name: "connectivity", deps: []plan.NodeID{"deploy"}},
+		{name: "remove", deps: []plan.NodeID{"dns", "connectivity"}, alwaysRun: true},
+		{name: "report", deps: []plan.NodeID{"remove"}},
+	} {
+		s := &touchFileStep{path: "/etc/" + n.name + ".yaml", conns: map[string]*recordingConnector{"node1": conn}}
+		s.Base.Meta.Name = n.name
+		node := &plan.ExecutionNode{Name: n.name, StepName: n.name, Step: s, Hostnames: []string{"node1"}, Dependencies: n.deps, AlwaysRun: n.alwaysRun}
+		node.Hosts = append(node.Hosts, host)
+		g.Nodes[plan.NodeID(n.name)] = node
+	}
+	return conn, g
+}
+
+func TestExecute_AlwaysRunNode(t *testing.T) {
+	tests := []struct {
+		name     string
+		broken   []string
+		expected map[plan.NodeID]plan.Status
+	}{
+		{
+			name:   "runs after a failed dependency",
+			broken: []string{"dns"},
+			expected: map[plan.NodeID]plan.Status{
+				"deploy":       plan.StatusSuccess,
+				"dns":          plan.StatusFailed,
+				"connectivity": plan.StatusSuccess,
+				"remove":       plan.StatusSuccess,
+				"report":       plan.StatusSkipped,
+			},
+		},
+		{
+			name:   "runs after skipped dependencies",
+			broken: []string{"deploy"},
+			expected: map[plan.NodeID]plan.Status{
+				"deploy":       plan.StatusFailed,
+				"dns":          plan.StatusSkipped,
+				"connectivity": plan.StatusSkipped,
+				"remove":       plan.StatusSuccess,
+				"report":       plan.StatusSkipped,
+			},
+		},
+		{
+			name: "runs in order when everything succeeds",
+			expected: map[plan.NodeID]plan.Status{
+				"deploy":       plan.StatusSuccess,
+				"dns":          plan.StatusSuccess,
+				"connectivity": plan.StatusSuccess,
+				"remove":       plan.StatusSuccess,
+				"report":       plan.StatusSuccess,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, g := newAlwaysRunTestGraph(tt.broken...)
+			ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+
+			result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, false)
+			if err != nil {
+				t.Fatalf("execute failed: %v", err)
+			}
+			wantStatus := plan.StatusSuccess
+			if len(tt.broken) > 0 {
+				wantStatus = plan.StatusFailed
+			}
+			if result.Status != wantStatus {
+				t.Errorf("expected the run to be %s, got %s: %s", wantStatus, result.Status, result.Message)
+			}
+			for id, status := range tt.expected {
+				if got := result.NodeResults[id].Status; got != status {
+					t.Errorf("expected node %s to be %s, got %s", id, status, got)
+				}
+			}
+			if !conn.files["/etc/remove.yaml"] {
+				t.Error("expected the AlwaysRun node to run")
+			}
+		})
+	}
+}
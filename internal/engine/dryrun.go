@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/step"
+)
+
+// renderDryRun renders the files of every node whose step is a step.Renderer into
+// <work dir>/dryrun/<cluster>/<host>/<path> and diffs them against the files on the hosts. Hosts are
+// only read, never changed. It returns nil if the context has no work dir or cluster name.
+func (e *dagExecutor) renderDryRun(rootCtx *runtime.Context, g *plan.ExecutionGraph) *plan.DryRunOutput {
+	dir := dryRunDir(rootCtx)
+	if dir == "" {
+		return nil
+	}
+	log := rootCtx.GetLogger()
+	out := &plan.DryRunOutput{Dir: dir}
+	if err := os.RemoveAll(dir); err != nil {
+		out.Errors = append(out.Errors, fmt.Sprintf("failed to clear %s: %v", dir, err))
+		return out
+	}
+	log.Info("Rendering files of all nodes for dry run...", "graphName", g.Name, "dir", dir)
+
+	workers := e.maxWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	ids := make([]plan.NodeID, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		node := g.Nodes[id]
+		renderer, ok := node.Step.(step.Renderer)
+		if !ok {
+			continue
+		}
+		if node.Condition != nil {
+			shouldRun, err := node.Condition(rootCtx)
+			if err != nil {
+				out.Errors = append(out.Errors, fmt.Sprintf("%s: condition check failed: %v", node.Name, err))
+				continue
+			}
+			if !shouldRun {
+				continue
+			}
+		}
+
+		scopedCtx := scopedContext(rootCtx, node)
+		for _, host := range node.Hosts {
+			wg.Add(1)
+			go func(node *plan.ExecutionNode, renderer step.Renderer, host remotefw.Host) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				execCtx := runtime.ForHost(scopedCtx, host)
+				if rc, ok := execCtx.(*runtime.Context); ok {
+					execCtx = rc.SetRuntimeConfig(node.RuntimeConfig)
+				}
+				files, err := renderOnHost(execCtx, node.Step, renderer, dir)
+				for i := range files {
+					files[i].NodeName = node.Name
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				out.Files = append(out.Files, files...)
+				if err != nil {
+					out.Errors = append(out.Errors, fmt.Sprintf("%s on %s: %v", node.Name, host.GetName(), err))
+				}
+			}(node, renderer, host)
+		}
+	}
+	wg.Wait()
+
+	out.Sort()
+	log.Info("Dry run rendering finished.", "summary", out.Summary())
+	return out
+}
+
+// renderOnHost renders the files of s for the host of ctx, writes them below dir and compares them
+// with the host, within the step timeout.
+func renderOnHost(ctx runtime.ExecutionContext, s step.Step, renderer step.Renderer, dir string) ([]plan.RenderedFile, error) {
+	timeout := s.GetBase().Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	stepCtx := ctx.WithTimeout(timeout)
+	if sc, ok := stepCtx.(interface{ Cancel() }); ok {
+		defer sc.Cancel()
+	}
+
+	var rendered []step.RenderedFile
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("PANIC in RenderFiles of step '%s': %v", s.Meta().Name, r)
+			}
+		}()
+		rendered, err = renderer.RenderFiles(stepCtx)
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	hostName := ctx.GetHost().GetName()
+	files := make([]plan.RenderedFile, 0, len(rendered))
+	for _, rf := range rendered {
+		localPath := filepath.Join(dir, pathSafe(hostName), filepath.Clean("/"+rf.Path))
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return files, fmt.Errorf("failed to create directory for %s: %w", localPath, err)
+		}
+		if err := os.WriteFile(localPath, rf.Content, 0644); err != nil {
+			return files, fmt.Errorf("failed to write rendered file %s: %w", localPath, err)
+		}
+		files = append(files, compareWithHost(stepCtx, hostName, rf, localPath))
+	}
+	return files, nil
+}
+
+// compareWithHost reads rf.Path from the host of ctx and diffs it against the rendered content.
+func compareWithHost(ctx runtime.ExecutionContext, hostName string, rf step.RenderedFile, localPath string) plan.RenderedFile {
+	file := plan.RenderedFile{Host: hostName, Path: rf.Path, LocalPath: localPath}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		file.State = plan.FileUnknown
+		file.Message = fmt.Sprintf("no connection to host: %v", err)
+		return file
+	}
+	runner := ctx.GetRunner()
+	exists, err := runner.Exists(ctx.GoContext(), conn, rf.Path)
+	if err != nil {
+		file.State = plan.FileUnknown
+		file.Message = fmt.Sprintf("failed to check file: %v", err)
+		return file
+	}
+	if !exists {
+		file.State = plan.FileNew
+		file.Diff = plan.UnifiedDiff("/dev/null", localPath, "", string(rf.Content))
+		return file
+	}
+	live, err := runner.ReadFile(ctx.GoContext(), conn, rf.Path)
+	if err != nil {
+		file.State = plan.FileUnknown
+		file.Message = fmt.Sprintf("failed to read file: %v", err)
+		return file
+	}
+	file.Diff = plan.UnifiedDiff(hostName+":"+rf.Path, localPath, string(live), string(rf.Content))
+	if file.Diff == "" {
+		file.State = plan.FileUnchanged
+	} else {
+		file.State = plan.FileChanged
+	}
+	return file
+}
+
+// dryRunDir is <work dir>/dryrun/<cluster>, or "" if either is unknown.
+func dryRunDir(ctx *runtime.Context) string {
+	if ctx.GetGlobalWorkDir() == "" || ctx.ClusterConfig == nil || ctx.ClusterConfig.Name == "" {
+		return ""
+	}
+	return filepath.Join(ctx.GetGlobalWorkDir(), common.DefaultDryRunDir, pathSafe(ctx.ClusterConfig.Name))
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/step"
+)
+
+// renderFileStep renders a config naming the current host.
+type renderFileStep struct {
+	touchFileStep
+	err error
+}
+
+func (s *renderFileStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	content := fmt.Sprintf("host: %s\n", ctx.GetHost().GetName())
+	return []step.RenderedFile{{Path: s.path, Content: []byte(content)}}, nil
+}
+
+func TestExecute_DryRunRendersFiles(t *testing.T) {
+	conns, g, ctx := newPreviewTestFixture()
+	ctx.GlobalWorkDir = t.TempDir()
+	ctx.ClusterConfig = &v1alpha1.Cluster{}
+	ctx.ClusterConfig.Name = "demo"
+
+	config := &renderFileStep{touchFileStep: *g.Nodes["config"].Step.(*touchFileStep)}
+	g.Nodes["config"].Step = config
+	broken := &renderFileStep{touchFileStep: *g.Nodes["broken"].Step.(*touchFileStep), err: fmt.Errorf("no template")}
+	g.Nodes["broken"].Step = broken
+
+	result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, true)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	out := result.DryRun
+	if out == nil {
+		t.Fatal("expected the dry run to render files")
+	}
+	dir := filepath.Join(ctx.GlobalWorkDir, "dryrun", "demo")
+	if out.Dir != dir {
+		t.Errorf("expected files below %s, got %s", dir, out.Dir)
+	}
+	if len(out.Files) != 2 {
+		t.Fatalf("expected the config of both hosts, got %+v", out.Files)
+	}
+	for _, f := range out.Files {
+		data, err := os.ReadFile(filepath.Join(dir, f.Host, "etc", "app.conf"))
+		if err != nil {
+			t.Fatalf("expected the rendered file of %s: %v", f.Host, err)
+		}
+		if string(data) != "host: "+f.Host+"\n" {
+			t.Errorf("unexpected content for %s: %q", f.Host, data)
+		}
+		if f.State != plan.FileUnknown || f.NodeName != "config" {
+			t.Errorf("expected the file of %s to be unknown without a connection, got %+v", f.Host, f)
+		}
+	}
+	if len(out.Errors) != 1 || !strings.Contains(out.Errors[0], "no template") {
+		t.Errorf("expected the render error of broken, got %v", out.Errors)
+	}
+	for name, conn := range conns {
+		if m := conn.mutations(); len(m) != 0 {
+			t.Errorf("expected the dry run to leave %s untouched, got %v", name, m)
+		}
+	}
+}
+
+// fakeFileRunner serves reads from an in-memory file set.
+type fakeFileRunner struct {
+	runner.Runner
+	files map[string]string
+}
+
+func (r *fakeFileRunner) Exists(ctx context.Context, conn connector.Connector, path string) (bool, error) {
+	_, ok := r.files[path]
+	return ok, nil
+}
+
+func (r *fakeFileRunner) ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error) {
+	return []byte(r.files[path]), nil
+}
+
+func TestCompareWithHost(t *testing.T) {
+	ctx := &runtimetest.Context{
+		Runner: &fakeFileRunner{files: map[string]string{
+			"/etc/same.conf":    "a\nb\n",
+			"/etc/changed.conf": "a\nold\nc\n",
+		}},
+		Conn: &recordingConnector{},
+	}
+
+	cases := []struct {
+		path    string
+		content string
+		state   plan.FileState
+		diff    []string
+	}{
+		{"/etc/same.conf", "a\nb\n", plan.FileUnchanged, nil},
+		{"/etc/changed.conf", "a\nnew\nc\n", plan.FileChanged, []string{"--- node1:/etc/changed.conf", "@@ -1,3 +1,3 @@", " a", "-old", "+new", " c"}},
+		{"/etc/new.conf", "x\n", plan.FileNew, []string{"--- /dev/null", "@@ -0,0 +1,1 @@", "+x"}},
+	}
+	for _, tc := range cases {
+		got := compareWithHost(ctx, "node1", step.RenderedFile{Path: tc.path, Content: []byte(tc.content)}, "/local"+tc.path)
+		if got.State != tc.state {
+			t.Errorf("%s: expected %s, got %s", tc.path, tc.state, got.State)
+		}
+		for _, line := range tc.diff {
+			if !strings.Contains(got.Diff, line+"\n") {
+				t.Errorf("%s: expected %q in diff:\n%s", tc.path, line, got.Diff)
+			}
+		}
+	}
+}
+
+func TestDryRunDir_RequiresWorkDirAndCluster(t *testing.T) {
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+	if dir := dryRunDir(ctx); dir != "" {
+		t.Errorf("expected no dry run dir without a work dir, got %s", dir)
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+func TestExecute_Events(t *testing.T) {
+	_, g := newOptionalTestGraph(false)
+	var mu sync.Mutex
+	var events []runtime.Event
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get(), Events: func(ev runtime.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	}}
+
+	if _, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, false); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	if len(events) == 0 || events[0].Type != runtime.EventGraphStarted || len(events[0].Nodes) != 4 {
+		t.Fatalf("expected the first event to announce 4 nodes, got %+v", events)
+	}
+	if events[0].Nodes[0].ID != "runtime" || events[0].Nodes[0].Hosts[0] != "node1" {
+		t.Errorf("expected nodes in execution order with their hosts, got %+v", events[0].Nodes)
+	}
+	if last := events[len(events)-1]; last.Type != runtime.EventGraphFinished || last.Status != "Failed" {
+		t.Errorf("expected the last event to report the failed graph, got %+v", last)
+	}
+
+	finished := make(map[string]string)
+	scheduled := make(map[string]bool)
+	hostEvents, outputChunks := 0, 0
+	for _, ev := range events {
+		if ev.Time.IsZero() {
+			t.Errorf("expected every event to carry a time, got %+v", ev)
+		}
+		switch ev.Type {
+		case runtime.EventNodeScheduled:
+			scheduled[ev.NodeID] = true
+		case runtime.EventHostOutputChunk:
+			outputChunks++
+			if ev.Host != "node1" || !strings.Contains(ev.Message, `"msg"`) {
+				t.Errorf("expected a log entry of node1, got %+v", ev)
+			}
+		case runtime.EventNodeFinished:
+			finished[ev.NodeID] = ev.Status
+		case runtime.EventHostStarted, runtime.EventHostFinished:
+			hostEvents++
+			if ev.Host != "node1" {
+				t.Errorf("expected host events for node1, got %+v", ev)
+			}
+		}
+	}
+	want := map[string]string{"runtime": "Success", "metrics-server": "Failed", "metrics-config": "Skipped", "smoke-test": "Skipped"}
+	for id, status := range want {
+		if finished[id] != status {
+			t.Errorf("expected node %s to finish as %s, got %q", id, status, finished[id])
+		}
+	}
+	if hostEvents != 4 {
+		t.Errorf("expected a start and finish event for each of the 2 nodes that ran, got %d", hostEvents)
+	}
+	if !scheduled["runtime"] || !scheduled["metrics-server"] || scheduled["smoke-test"] {
+		t.Errorf("expected only the nodes that ran to be scheduled, got %v", scheduled)
+	}
+	if outputChunks == 0 {
+		t.Error("expected the step output to be reported as chunks")
+	}
+}
+
+func TestExecute_Hooks(t *testing.T) {
+	_, g := newOptionalTestGraph(false)
+	out := filepath.Join(t.TempDir(), "failed.log")
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+	opts := ExecutorOptions{Hooks: []v1alpha1.Hook{
+		{Name: "failed-nodes", Events: []string{"NodeFinished"}, Status: []string{"Failed"}, Command: `echo "$KUBEXM_EVENT_NODE_ID" >> ` + out},
+		{Name: "done", Command: `echo "graph $KUBEXM_EVENT_STATUS" >> ` + out},
+	}}
+
+	if _, err := NewCheckpointExecutor(opts).Execute(ctx, g, false); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected the hooks to run before Execute returns: %v", err)
+	}
+	if got := string(data); got != "metrics-server\ngraph Failed\n" {
+		t.Errorf("unexpected hook output %q", got)
+	}
+}
@@ -3,13 +3,20 @@ package engine
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/checkpoint"
-	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/hooks"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/stats"
 	"github.com/mensylisir/kubexm/internal/step"
 	"github.com/mensylisir/kubexm/internal/types"
 	"golang.org/x/sync/errgroup"
@@ -38,15 +45,30 @@ type ExecutorOptions struct {
 	// RetryMaxDelay is the maximum delay between retries.
 	// Default is 30 seconds.
 	RetryMaxDelay time.Duration
+	// RetryOn limits retries to errors matching one of these regular expressions.
+	// If empty, only the transient errors of plan.DefaultRetryOn are retried.
+	RetryOn []string
+	// MaxWorkers is the number of graph nodes executed concurrently.
+	// If zero or negative, DefaultMaxWorkers for the cluster's host count is used.
+	MaxWorkers int
+	// History supplies per-step durations of earlier runs for the progress ETA.
+	// If nil, progress is reported as a node count only.
+	History plan.DurationHistory
+	// Preview evaluates the precheck of every node on all its hosts concurrently before execution
+	// starts and attaches the outcome to the result.
+	Preview bool
+	// Stats, if set, records the durations of successful nodes and is saved after the run.
+	Stats *stats.Store
+	// Hooks run local commands or webhooks on the events of the execution.
+	Hooks []v1alpha1.Hook
 }
 
 type dagExecutor struct {
-	maxWorkers     int
-	opts           ExecutorOptions
-	persister      *checkpoint.CheckpointPersister
-	retryMaxRetries     int
-	retryBaseDelay      time.Duration
-	retryMaxDelay       time.Duration
+	maxWorkers int
+	opts       ExecutorOptions
+	persister  *checkpoint.CheckpointPersister
+	// retry applies to nodes that do not carry their own RetryPolicy.
+	retry plan.RetryPolicy
 }
 
 type workerResult struct {
@@ -58,10 +80,26 @@ type workerResult struct {
 	output      map[string]interface{}
 }
 
+const (
+	minDefaultWorkers = 10
+	maxDefaultWorkers = 50
+)
+
+// DefaultMaxWorkers returns the worker count used when none is configured: one per host,
+// but no fewer than 10 and no more than 50.
+func DefaultMaxWorkers(hostCount int) int {
+	if hostCount < minDefaultWorkers {
+		return minDefaultWorkers
+	}
+	if hostCount > maxDefaultWorkers {
+		return maxDefaultWorkers
+	}
+	return hostCount
+}
+
 func NewExecutor() Engine {
 	return &dagExecutor{
 		maxWorkers: 10,
-		retryMaxRetries: 0, // Disabled by default
 	}
 }
 
@@ -86,13 +124,21 @@ func NewCheckpointExecutor(opts ExecutorOptions) Engine {
 		retryMaxDelay = 30 * time.Second
 	}
 
+	maxWorkers := opts.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = DefaultMaxWorkers(0)
+	}
+
 	return &dagExecutor{
-		maxWorkers: 10,
+		maxWorkers: maxWorkers,
 		opts:       opts,
 		persister:  persister,
-		retryMaxRetries: retryMaxRetries,
-		retryBaseDelay:  retryBaseDelay,
-		retryMaxDelay:   retryMaxDelay,
+		retry: plan.RetryPolicy{
+			MaxAttempts: retryMaxRetries + 1,
+			BaseDelay:   retryBaseDelay,
+			MaxDelay:    retryMaxDelay,
+			RetryOn:     opts.RetryOn,
+		},
 	}
 }
 
@@ -100,11 +146,21 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 	result := plan.NewGraphExecutionResult(g.Name)
 	log := execCtx.GetLogger()
 
+	if e.opts.Preview {
+		result.Preview = e.preview(execCtx, g)
+	}
+
 	if dryRun {
-		e.dryRun(log, g, result)
+		e.dryRun(execCtx, g, result)
 		return result, nil
 	}
 
+	if len(e.opts.Hooks) > 0 {
+		dispatcher := hooks.NewDispatcher(e.opts.Hooks, log)
+		defer dispatcher.Close()
+		execCtx = execCtx.WithEventHandler(dispatcher.Handle)
+	}
+
 	// Apply pipeline-level timeout if configured
 	if e.opts.Timeout > 0 {
 		log.Info("Pipeline timeout configured.", "timeout", e.opts.Timeout)
@@ -141,6 +197,7 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 			dependents[depID] = append(dependents[depID], id)
 		}
 		result.NodeResults[id] = plan.NewNodeResult(node.Name, node.StepName)
+		result.NodeResults[id].StepType = node.StepType()
 
 		// Restore node result from checkpoint if available
 		if ckpt != nil && ckpt.NodeStates != nil {
@@ -194,13 +251,25 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 		}
 	}
 
+	// failedUpstream records, for the AlwaysRun nodes, the first dependency that failed or was
+	// skipped; their dependents are skipped even when they succeed.
+	failedUpstream := make(map[plan.NodeID]plan.NodeID)
+
 	// Count already-done nodes toward processedNodesCount
 	processedNodesCount := len(alreadyDone)
 
+	progress := plan.NewProgressTracker(g, e.opts.History)
+	execCtx.Emit(graphStartedEvent(g))
+	for id := range alreadyDone {
+		progress.MarkDone(id)
+		execCtx.Emit(nodeEvent(runtime.EventNodeFinished, g, id, result.NodeResults[id].Status, "Completed by a previous run."))
+	}
+
 	// Pre-populate task queue with entry nodes that aren't done
 	initialQueueSize := 0
 	for id, degree := range inDegree {
 		if degree == 0 && !alreadyDone[id] {
+			execCtx.Emit(nodeEvent(runtime.EventNodeScheduled, g, id, plan.StatusPending, ""))
 			tasks <- id
 			initialQueueSize++
 		}
@@ -252,13 +321,49 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 			}
 		}
 
+		progress.MarkDone(nodeID)
+		execCtx.Emit(nodeEvent(runtime.EventNodeFinished, g, nodeID, nodeRes.Status, nodeRes.Message))
 		log.Info("Node finished.", "nodeID", nodeID, "nodeName", g.Nodes[nodeID].Name, "status", nodeRes.Status)
 
-		if nodeRes.Status == plan.StatusFailed || nodeRes.Status == plan.StatusSkipped {
-			nodesToSkipQueue := dependents[nodeID]
+		if nodeRes.Status == plan.StatusFailed && g.Nodes[nodeID].Optional {
+			warning := fmt.Sprintf("Optional node '%s' failed: %s", g.Nodes[nodeID].Name, nodeRes.Message)
+			result.Warnings = append(result.Warnings, warning)
+			log.Warn("Optional node failed, continuing.", "nodeID", nodeID, "message", nodeRes.Message)
+		}
+
+		_, upstreamFailed := failedUpstream[nodeID]
+		if nodeRes.Status == plan.StatusFailed || nodeRes.Status == plan.StatusSkipped || upstreamFailed {
+			// Within an optional module the skip cascades as usual, but nodes outside of it
+			// are released so the rest of the graph continues.
+			type skipEdge struct{ from, to plan.NodeID }
+			var nodesToSkipQueue []skipEdge
+			for _, dependentID := range dependents[nodeID] {
+				nodesToSkipQueue = append(nodesToSkipQueue, skipEdge{from: nodeID, to: dependentID})
+			}
 			for len(nodesToSkipQueue) > 0 {
-				skipID := nodesToSkipQueue[0]
+				edge := nodesToSkipQueue[0]
 				nodesToSkipQueue = nodesToSkipQueue[1:]
+				skipID := edge.to
+
+				if g.Nodes[edge.from].Optional && !g.Nodes[skipID].Optional {
+					inDegree[skipID]--
+					if inDegree[skipID] == 0 && result.NodeResults[skipID].Status == plan.StatusPending {
+						execCtx.Emit(nodeEvent(runtime.EventNodeScheduled, g, skipID, plan.StatusPending, ""))
+						tasks <- skipID
+					}
+					continue
+				}
+				if g.Nodes[skipID].AlwaysRun {
+					if _, seen := failedUpstream[skipID]; !seen {
+						failedUpstream[skipID] = edge.from
+					}
+					inDegree[skipID]--
+					if inDegree[skipID] == 0 && result.NodeResults[skipID].Status == plan.StatusPending {
+						execCtx.Emit(nodeEvent(runtime.EventNodeScheduled, g, skipID, plan.StatusPending, ""))
+						tasks <- skipID
+					}
+					continue
+				}
 
 				skipNodeRes := result.NodeResults[skipID]
 				if skipNodeRes.Status == plan.StatusPending {
@@ -267,18 +372,25 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 					skipNodeRes.EndTime = time.Now()
 
 					processedNodesCount++
+					progress.MarkDone(skipID)
+					execCtx.Emit(nodeEvent(runtime.EventNodeFinished, g, skipID, plan.StatusSkipped, skipNodeRes.Message))
 					log.Info("Cascading skip.", "targetNodeID", skipID, "reasonNodeID", nodeID)
-					nodesToSkipQueue = append(nodesToSkipQueue, dependents[skipID]...)
+					for _, dependentID := range dependents[skipID] {
+						nodesToSkipQueue = append(nodesToSkipQueue, skipEdge{from: skipID, to: dependentID})
+					}
 				}
 			}
 		} else {
 			for _, dependentID := range dependents[nodeID] {
 				inDegree[dependentID]--
 				if inDegree[dependentID] == 0 {
+					execCtx.Emit(nodeEvent(runtime.EventNodeScheduled, g, dependentID, plan.StatusPending, ""))
 					tasks <- dependentID
 				}
 			}
 		}
+
+		log.Info("Graph progress.", "progress", progress.Snapshot(time.Now()).String())
 	}
 
 	log.Debug("All nodes processed. Shutting down workers...")
@@ -288,15 +400,26 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 
 	finalStatus := plan.StatusSuccess
 	finalMessage := "Graph execution completed successfully."
-	for _, nr := range result.NodeResults {
-		if nr.Status == plan.StatusFailed {
+	for id, nr := range result.NodeResults {
+		if nr.Status == plan.StatusFailed && !g.Nodes[id].Optional {
 			finalStatus = plan.StatusFailed
 			finalMessage = "Graph execution failed due to one or more node failures."
 			break
 		}
 	}
+	if finalStatus == plan.StatusSuccess && result.HasWarnings() {
+		sort.Strings(result.Warnings)
+		finalMessage = fmt.Sprintf("Graph execution completed with %d warning(s) from optional modules.", len(result.Warnings))
+	}
 	result.Finalize(finalStatus, finalMessage)
 
+	if e.opts.Stats != nil {
+		e.opts.Stats.Record(result)
+		if err := e.opts.Stats.Save(); err != nil {
+			log.Warn("Failed to save step duration stats.", "error", err)
+		}
+	}
+
 	// Update checkpoint status and delete on success
 	if ckpt != nil {
 		ckpt.Status = finalStatus
@@ -315,6 +438,7 @@ func (e *dagExecutor) Execute(execCtx *runtime.Context, g *plan.ExecutionGraph,
 		}
 	}
 
+	execCtx.Emit(runtime.Event{Type: runtime.EventGraphFinished, Graph: g.Name, Status: string(result.Status), Message: result.Message})
 	log.Info("Graph execution finished.", "graphName", g.Name, "status", result.Status, "duration", result.EndTime.Sub(result.StartTime))
 	return result, nil
 }
@@ -344,6 +468,8 @@ func (e *dagExecutor) restoreNodeResult(nodeRes *plan.NodeResult, nodeState *che
 			Metadata:  hostState.Metadata,
 			StartTime: hostState.StartTime,
 			EndTime:   hostState.EndTime,
+			LogFile:   hostState.LogFile,
+			Satisfied: hostState.Satisfied,
 		}
 		if nodeRes.HostResults == nil {
 			nodeRes.HostResults = make(map[string]*plan.HostResult)
@@ -383,6 +509,8 @@ func (e *dagExecutor) saveNodeState(ckpt *checkpoint.Checkpoint, nodeID plan.Nod
 			Metadata:  hr.Metadata,
 			StartTime: hr.StartTime,
 			EndTime:   hr.EndTime,
+			LogFile:   hr.LogFile,
+			Satisfied: hr.Satisfied,
 		}
 	}
 
@@ -427,34 +555,48 @@ func (e *dagExecutor) runNode(rootCtx *runtime.Context, g *plan.ExecutionGraph,
 	}
 
 	log.Info("Executing node on hosts...", "hosts", node.Hostnames)
+	rootCtx.Emit(nodeEvent(runtime.EventNodeStarted, g, nodeID, plan.StatusRunning, ""))
 
 	hostGroup, gctx := errgroup.WithContext(rootCtx.GoContext())
 	hostResults := make(map[string]*plan.HostResult)
 	var mu sync.Mutex
 
-	// Create scoped context
-	scopedCtx := rootCtx
-	if node.PipelineName != "" {
-		scopedCtx = scopedCtx.ForPipeline(node.PipelineName)
-	}
-	if node.ModuleName != "" {
-		scopedCtx = scopedCtx.ForModule(node.ModuleName)
-	}
-	if node.TaskName != "" {
-		scopedCtx = scopedCtx.ForTask(node.TaskName)
-	}
+	scopedCtx := scopedContext(rootCtx, node)
 
 	for _, host := range node.Hosts {
 		currentHost := host
 		hostGroup.Go(func() error {
 			// Use scopedCtx instead of rootCtx
 			execCtx := runtime.ForHost(scopedCtx, currentHost).WithGoContext(gctx)
+			hostEvent := nodeEvent(runtime.EventHostStarted, g, nodeID, plan.StatusRunning, "")
+			hostEvent.Host = currentHost.GetName()
+			var logFile string
 			if rc, ok := execCtx.(*runtime.Context); ok {
-				execCtx = rc.SetRuntimeConfig(node.RuntimeConfig)
+				rc = rc.SetRuntimeConfig(node.RuntimeConfig)
+				if path := nodeLogPath(rc, node, currentHost.GetName()); path != "" {
+					hostLog, closer, err := rc.GetLogger().WithFile(path)
+					if err != nil {
+						log.Warn("Could not open node log file, logging to the main log only.", "path", path, "error", err)
+					} else {
+						defer closer.Close()
+						rc = rc.WithLogger(hostLog)
+						logFile = path
+					}
+				}
+				if rc.Events != nil {
+					rc = rc.WithLogger(rc.GetLogger().WithWriter(&hostOutputWriter{ctx: rootCtx, event: hostEvent}))
+				}
+				execCtx = rc
 			} else {
 				log.Warn("Could not set runtime config: execCtx is not of type *runtime.Context")
 			}
-			hr := e.runStepOnHost(execCtx, node.Step)
+			rootCtx.Emit(hostEvent)
+			hr := e.runStepOnHost(execCtx, node.Step, e.retryPolicy(node))
+			hr.LogFile = logFile
+			hostEvent.Type = runtime.EventHostFinished
+			hostEvent.Status = string(hr.Status)
+			hostEvent.Message = hr.Message
+			rootCtx.Emit(hostEvent)
 			mu.Lock()
 			hostResults[currentHost.GetName()] = hr
 			mu.Unlock()
@@ -497,7 +639,105 @@ func (e *dagExecutor) runNode(rootCtx *runtime.Context, g *plan.ExecutionGraph,
 		output:      output,
 	}
 }
-func (e *dagExecutor) runStepOnHost(ctx runtime.ExecutionContext, s step.Step) *plan.HostResult {
+// scopedContext returns rootCtx scoped to the pipeline, module and task the node belongs to.
+func scopedContext(rootCtx *runtime.Context, node *plan.ExecutionNode) *runtime.Context {
+	scopedCtx := rootCtx
+	if node.PipelineName != "" {
+		scopedCtx = scopedCtx.ForPipeline(node.PipelineName)
+	}
+	if node.ModuleName != "" {
+		scopedCtx = scopedCtx.ForModule(node.ModuleName)
+	}
+	if node.TaskName != "" {
+		scopedCtx = scopedCtx.ForTask(node.TaskName)
+	}
+	return scopedCtx
+}
+
+// nodePhase names the part of the pipeline a node belongs to: its module, or its task if it has
+// no module.
+func nodePhase(node *plan.ExecutionNode) string {
+	switch {
+	case node.ModuleName != "":
+		return node.ModuleName
+	case node.TaskName != "":
+		return node.TaskName
+	default:
+		return node.PipelineName
+	}
+}
+
+// graphStartedEvent lists the nodes of g in execution order.
+func graphStartedEvent(g *plan.ExecutionGraph) runtime.Event {
+	ev := runtime.Event{Type: runtime.EventGraphStarted, Graph: g.Name}
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return ev
+	}
+	for _, id := range order {
+		node := g.Nodes[id]
+		ev.Nodes = append(ev.Nodes, runtime.EventNode{ID: string(id), Name: node.Name, Phase: nodePhase(node), Hosts: node.HostNames()})
+	}
+	return ev
+}
+
+// nodeEvent returns an event of type t about node id of g.
+func nodeEvent(t runtime.EventType, g *plan.ExecutionGraph, id plan.NodeID, status plan.Status, message string) runtime.Event {
+	node := g.Nodes[id]
+	return runtime.Event{
+		Type:     t,
+		Graph:    g.Name,
+		NodeID:   string(id),
+		NodeName: node.Name,
+		Phase:    nodePhase(node),
+		Status:   string(status),
+		Message:  message,
+	}
+}
+
+// hostOutputWriter emits every entry the step logs on a host as an EventHostOutputChunk.
+type hostOutputWriter struct {
+	ctx   *runtime.Context
+	event runtime.Event
+}
+
+func (w *hostOutputWriter) Write(p []byte) (int, error) {
+	ev := w.event
+	ev.Type = runtime.EventHostOutputChunk
+	ev.Message = strings.TrimRight(string(p), "\n")
+	w.ctx.Emit(ev)
+	return len(p), nil
+}
+
+// nodeLogPath returns the file that keeps the log output of node on host:
+// <workdir>/logs/<run>/<node>/<host>.log. It is empty when the run has no work directory or ID.
+func nodeLogPath(ctx *runtime.Context, node *plan.ExecutionNode, hostName string) string {
+	if ctx.GetGlobalWorkDir() == "" || ctx.GetRunID() == "" {
+		return ""
+	}
+	return filepath.Join(ctx.GetGlobalWorkDir(), common.DefaultLogsDir, pathSafe(ctx.GetRunID()), pathSafe(node.Name), pathSafe(hostName)+".log")
+}
+
+// pathSafe replaces characters that cannot appear in a single path element.
+func pathSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// retryPolicy returns the node's own retry policy, or the executor default if it has none.
+func (e *dagExecutor) retryPolicy(node *plan.ExecutionNode) *plan.RetryPolicy {
+	if node.Retry != nil {
+		return node.Retry
+	}
+	return &e.retry
+}
+
+func (e *dagExecutor) runStepOnHost(ctx runtime.ExecutionContext, s step.Step, retry *plan.RetryPolicy) *plan.HostResult {
 	host := ctx.GetHost()
 	hr := plan.NewHostResult(host.GetName())
 	hr.EndTime = time.Now()
@@ -537,6 +777,7 @@ func (e *dagExecutor) runStepOnHost(ctx runtime.ExecutionContext, s step.Step) *
 	}
 	if isDone {
 		hr.Status = plan.StatusSuccess
+		hr.Satisfied = true
 		hr.Message = "Skipped: Precheck condition already met."
 		hr.EndTime = time.Now()
 		log.Info("Step skipped by precheck (treated as success).")
@@ -550,16 +791,24 @@ func (e *dagExecutor) runStepOnHost(ctx runtime.ExecutionContext, s step.Step) *
 	var result *types.StepResult
 	var runErr error
 
+	maxRetries := retry.MaxAttempts - 1
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
 	// Execute step with retry logic
-	for attempt := 0; attempt <= e.retryMaxRetries; attempt++ {
+	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			// Calculate exponential backoff delay
-			delay := e.retryBaseDelay * time.Duration(1<<uint(attempt-1))
-			if delay > e.retryMaxDelay {
-				delay = e.retryMaxDelay
+			delay := retry.Backoff(attempt)
+			log.Info("Retrying step...", "attempt", attempt, "max_retries", maxRetries, "delay", delay)
+			select {
+			case <-time.After(delay):
+			case <-stepCtx.GoContext().Done():
+				runErr = fmt.Errorf("retry of step '%s' aborted: %w (last error: %v)", s.Meta().Name, stepCtx.GoContext().Err(), runErr)
+			}
+			if stepCtx.GoContext().Err() != nil {
+				break
 			}
-			log.Info("Retrying step...", "attempt", attempt, "max_retries", e.retryMaxRetries, "delay", delay)
-			time.Sleep(delay)
 		}
 
 		func() {
@@ -592,8 +841,15 @@ func (e *dagExecutor) runStepOnHost(ctx runtime.ExecutionContext, s step.Step) *
 		}
 
 		// Don't retry if this is the last attempt
-		if attempt >= e.retryMaxRetries {
-			log.Warn("Max retries exceeded for step.", "attempt", attempt, "max_retries", e.retryMaxRetries)
+		if attempt >= maxRetries {
+			if maxRetries > 0 {
+				log.Warn("Max retries exceeded for step.", "attempt", attempt, "max_retries", maxRetries)
+			}
+			break
+		}
+
+		if !retry.Retryable(runErr) {
+			log.Warn("Step failed with an error that is not retryable.", "error", runErr)
 			break
 		}
 
@@ -657,7 +913,10 @@ func (e *dagExecutor) runStepOnHost(ctx runtime.ExecutionContext, s step.Step) *
 	return hr
 }
 
-func (e *dagExecutor) dryRun(logger *logger.Logger, g *plan.ExecutionGraph, result *plan.GraphExecutionResult) {
+// dryRun prints the graph without running any step, then renders the files of the nodes that
+// implement step.Renderer locally and prints how they differ from the hosts.
+func (e *dagExecutor) dryRun(execCtx *runtime.Context, g *plan.ExecutionGraph, result *plan.GraphExecutionResult) {
+	logger := execCtx.GetLogger()
 	logger.Info("--- Dry Run Execution Graph ---", "graphName", g.Name)
 	for id, node := range g.Nodes {
 		nodeRes := plan.NewNodeResult(node.Name, node.StepName)
@@ -693,6 +952,10 @@ func (e *dagExecutor) dryRun(logger *logger.Logger, g *plan.ExecutionGraph, resu
 		fmt.Printf("  Dependencies: %v\n", node.Dependencies)
 		fmt.Printf("  Status: %s (Dry Run)\n", plan.StatusSkipped)
 	}
+
+	if result.DryRun = e.renderDryRun(execCtx, g); result.DryRun != nil {
+		result.DryRun.WriteDiff(os.Stdout)
+	}
 	logger.Info("--- End of Dry Run ---")
 }
 
@@ -715,6 +978,27 @@ func NewCheckpointExecutorForPipeline(engineCtx *runtime.Context, pipelineName s
 		CheckpointDir: clusterWorkDir,
 		ClusterName:   engineCtx.ClusterConfig.Name,
 		PipelineName:  pipelineName,
+		Preview:       engineCtx.GlobalPreview,
+	}
+	if spec := engineCtx.ClusterConfig.Spec; spec != nil {
+		opts.Hooks = spec.Hooks
+		opts.MaxWorkers = DefaultMaxWorkers(len(spec.Hosts))
+		if spec.Global != nil && spec.Global.MaxWorkers > 0 {
+			opts.MaxWorkers = spec.Global.MaxWorkers
+		}
+		if spec.System != nil && spec.System.Retry != nil {
+			retry := spec.System.Retry
+			opts.MaxRetries = retry.MaxAttempts - 1
+			opts.RetryBaseDelay = retry.BaseDelay
+			opts.RetryMaxDelay = retry.MaxDelay
+			opts.RetryOn = retry.RetryOn
+		}
+	}
+	if history, err := stats.Load(stats.Path(clusterWorkDir)); err != nil {
+		engineCtx.GetLogger().Warn("Failed to load step duration stats, progress will not include an ETA.", "error", err)
+	} else {
+		opts.History = history
+		opts.Stats = history
 	}
 	return NewCheckpointExecutor(opts)
 }
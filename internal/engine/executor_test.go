@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+func TestDefaultMaxWorkers(t *testing.T) {
+	tests := []struct {
+		hostCount int
+		expected  int
+	}{
+		{hostCount: 0, expected: 10},
+		{hostCount: 3, expected: 10},
+		{hostCount: 25, expected: 25},
+		{hostCount: 200, expected: 50},
+	}
+	for _, tt := range tests {
+		if got := DefaultMaxWorkers(tt.hostCount); got != tt.expected {
+			t.Errorf("DefaultMaxWorkers(%d) = %d, expected %d", tt.hostCount, got, tt.expected)
+		}
+	}
+}
+
+func TestNewCheckpointExecutorForPipeline_MaxWorkers(t *testing.T) {
+	hosts := make([]v1alpha1.HostSpec, 30)
+	tests := []struct {
+		name     string
+		global   *v1alpha1.GlobalSpec
+		expected int
+	}{
+		{name: "configured value", global: &v1alpha1.GlobalSpec{MaxWorkers: 3}, expected: 3},
+		{name: "default from host count", global: &v1alpha1.GlobalSpec{}, expected: 30},
+		{name: "no global spec", expected: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &runtime.Context{
+				Logger:        logger.Get(),
+				GlobalWorkDir: t.TempDir(),
+				ClusterConfig: &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Global: tt.global, Hosts: hosts}},
+			}
+			ctx.ClusterConfig.Name = "test"
+
+			e, ok := NewCheckpointExecutorForPipeline(ctx, "CreateCluster").(*dagExecutor)
+			if !ok {
+				t.Fatal("expected a *dagExecutor")
+			}
+			if e.maxWorkers != tt.expected {
+				t.Errorf("expected %d workers, got %d", tt.expected, e.maxWorkers)
+			}
+		})
+	}
+}
+
+func TestExecute_ReapplyIsSatisfiedNoOp(t *testing.T) {
+	conns, g, ctx := newPreviewTestFixture()
+	delete(g.Nodes, "broken")
+	e := NewCheckpointExecutor(ExecutorOptions{})
+
+	first, err := e.Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+	if report := plan.NewRunReport(first); report.NoOp {
+		t.Errorf("expected the first run to change node2, got satisfied nodes %v", report.Summary.SatisfiedNodes)
+	}
+	if !first.NodeResults["config"].HostResults["node1"].Satisfied || first.NodeResults["config"].HostResults["node2"].Satisfied {
+		t.Error("expected config to be satisfied on node1 only")
+	}
+
+	second, err := e.Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	report := plan.NewRunReport(second)
+	if !report.NoOp {
+		t.Errorf("expected the second run to be a no-op, got:\n%s", report.SummaryText)
+	}
+	if got := strings.Join(report.Summary.SatisfiedNodes, ","); got != "config,marker" {
+		t.Errorf("expected config and marker to be satisfied, got %s", got)
+	}
+	if m := conns["node2"].mutations(); len(m) != 1 {
+		t.Errorf("expected node2 to be touched once across both runs, got %v", m)
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+func TestExecute_NodeLogFiles(t *testing.T) {
+	_, g := newOptionalTestGraph(false)
+	workDir := t.TempDir()
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get(), GlobalWorkDir: workDir, RunID: "run1"}
+
+	result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	hr := result.NodeResults["metrics-server"].HostResults["node1"]
+	want := filepath.Join(workDir, "logs", "run1", "metrics-server", "node1.log")
+	if hr.LogFile != want {
+		t.Fatalf("expected log file %s, got %q", want, hr.LogFile)
+	}
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatalf("failed to read node log: %v", err)
+	}
+	if !strings.Contains(string(data), `"step":"metrics-server"`) || !strings.Contains(string(data), "Step run failed.") {
+		t.Errorf("expected the node log to hold the step output, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "logs", "run1", "runtime", "node1.log")); err != nil {
+		t.Errorf("expected a log file for the runtime node: %v", err)
+	}
+
+	if summary := plan.NewRunReport(result).SummaryText; !strings.Contains(summary, "log: "+want) {
+		t.Errorf("expected the summary to point at the failed node's log, got:\n%s", summary)
+	}
+}
+
+func TestExecute_NoNodeLogsWithoutRunID(t *testing.T) {
+	_, g := newOptionalTestGraph(true)
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get(), GlobalWorkDir: t.TempDir()}
+
+	result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if lf := result.NodeResults["runtime"].HostResults["node1"].LogFile; lf != "" {
+		t.Errorf("expected no log file without a run ID, got %s", lf)
+	}
+}
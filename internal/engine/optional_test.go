@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// newOptionalTestGraph chains runtime -> metrics-server -> metrics-config -> smoke-test, where the
+// metrics-server nodes belong to an addon module and touching the metrics-server manifest fails.
+func newOptionalTestGraph(addonOptional bool) (*recordingConnector, *plan.ExecutionGraph) {
+	conn := &recordingConnector{files: map[string]bool{}, broken: map[string]bool{"/etc/metrics-server.yaml": true}}
+	host := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "node1"})
+
+	g := plan.NewExecutionGraph("Optional")
+	var previous plan.NodeID
+	for _, n := range []struct {
+		name     string
+		optional bool
+	}{
+		{name: "runtime"},
+		{name: "metrics-server", optional: addonOptional},
+		{name: "metrics-config", optional: addonOptional},
+		{name: "smoke-test"},
+	} {
+		s := &touchFileStep{path: "/etc/" + n.name + ".yaml", conns: map[string]*recordingConnector{"node1": conn}}
+		s.Base.Meta.Name = n.name
+		node := &plan.ExecutionNode{Name: n.name, StepName: n.name, Step: s, Hostnames: []string{"node1"}, Optional: n.optional}
+		node.Hosts = append(node.Hosts, host)
+		if previous != "" {
+			node.Dependencies = []plan.NodeID{previous}
+		}
+		g.Nodes[plan.NodeID(n.name)] = node
+		previous = plan.NodeID(n.name)
+	}
+	return conn, g
+}
+
+func TestExecute_OptionalFailureIsWarning(t *testing.T) {
+	conn, g := newOptionalTestGraph(true)
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+
+	result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if result.Status != plan.StatusSuccess {
+		t.Fatalf("expected the run to succeed, got %s: %s", result.Status, result.Message)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "metrics-server") {
+		t.Fatalf("expected one warning for metrics-server, got %v", result.Warnings)
+	}
+	if !strings.Contains(result.Message, "1 warning(s)") {
+		t.Errorf("expected the message to mention the warning, got %q", result.Message)
+	}
+
+	expected := map[plan.NodeID]plan.Status{
+		"runtime":        plan.StatusSuccess,
+		"metrics-server": plan.StatusFailed,
+		"metrics-config": plan.StatusSkipped,
+		"smoke-test":     plan.StatusSuccess,
+	}
+	for id, status := range expected {
+		if got := result.NodeResults[id].Status; got != status {
+			t.Errorf("expected node %s to be %s, got %s", id, status, got)
+		}
+	}
+	if !conn.files["/etc/smoke-test.yaml"] {
+		t.Error("expected the required node after the optional module to run")
+	}
+
+	summary := plan.NewRunReport(result).SummaryText
+	if !strings.Contains(summary, "Success with 1 warning(s)") || !strings.Contains(summary, "WARNING: Optional node 'metrics-server' failed") {
+		t.Errorf("expected the summary to surface the warning, got:\n%s", summary)
+	}
+}
+
+func TestExecute_RequiredFailureFailsRun(t *testing.T) {
+	conn, g := newOptionalTestGraph(false)
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+
+	result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if result.Status != plan.StatusFailed {
+		t.Fatalf("expected the run to fail, got %s: %s", result.Status, result.Message)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+	if got := result.NodeResults["smoke-test"].Status; got != plan.StatusSkipped {
+		t.Errorf("expected smoke-test to be skipped, got %s", got)
+	}
+	if conn.files["/etc/smoke-test.yaml"] {
+		t.Error("expected nothing to run after the failed required module")
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/step"
+)
+
+// preview evaluates the precheck of every node on each of its hosts concurrently, without running
+// any step. Prechecks are read-only by contract, so this is safe before any mutation begins.
+func (e *dagExecutor) preview(rootCtx *runtime.Context, g *plan.ExecutionGraph) plan.Preview {
+	log := rootCtx.GetLogger()
+	log.Info("Previewing prechecks of all nodes...", "graphName", g.Name, "totalNodes", len(g.Nodes))
+
+	workers := e.maxWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	preview := make(plan.Preview, len(g.Nodes))
+	for id, node := range g.Nodes {
+		np := &plan.NodePreview{NodeName: node.Name, StepName: node.StepName, Hosts: make(map[string]*plan.HostPreview)}
+		preview[id] = np
+
+		if node.Condition != nil {
+			shouldRun, err := node.Condition(rootCtx)
+			if err != nil {
+				np.State = plan.PreviewUnknown
+				continue
+			}
+			if !shouldRun {
+				np.State = plan.PreviewSkipped
+				continue
+			}
+		}
+		if node.Step == nil {
+			np.State = plan.PreviewUnknown
+			continue
+		}
+
+		scopedCtx := scopedContext(rootCtx, node)
+		for _, host := range node.Hosts {
+			wg.Add(1)
+			go func(node *plan.ExecutionNode, host remotefw.Host, np *plan.NodePreview) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				execCtx := runtime.ForHost(scopedCtx, host)
+				if rc, ok := execCtx.(*runtime.Context); ok {
+					execCtx = rc.SetRuntimeConfig(node.RuntimeConfig)
+				}
+				hp := e.precheckOnHost(execCtx, node.Step)
+				mu.Lock()
+				np.Hosts[host.GetName()] = hp
+				mu.Unlock()
+			}(node, host, np)
+		}
+	}
+	wg.Wait()
+
+	for _, id := range sortedNodeIDs(preview) {
+		np := preview[id]
+		if np.State == "" {
+			np.AggregateState()
+		}
+		if np.State == plan.PreviewWouldChange || np.State == plan.PreviewUnknown {
+			log.Info("Preview.", "nodeID", id, "step", np.StepName, "state", np.State)
+		}
+	}
+	log.Info("Preview finished.", "summary", preview.Summary())
+	return preview
+}
+
+// precheckOnHost runs the precheck of s with the step timeout and maps its outcome to a preview state.
+func (e *dagExecutor) precheckOnHost(ctx runtime.ExecutionContext, s step.Step) *plan.HostPreview {
+	timeout := s.GetBase().Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	stepCtx := ctx.WithTimeout(timeout)
+	if sc, ok := stepCtx.(interface{ Cancel() }); ok {
+		defer sc.Cancel()
+	}
+
+	var isDone bool
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("PANIC in Precheck of step '%s': %v", s.Meta().Name, r)
+			}
+		}()
+		isDone, err = s.Precheck(stepCtx)
+	}()
+
+	switch {
+	case err != nil:
+		return &plan.HostPreview{State: plan.PreviewUnknown, Message: fmt.Sprintf("Precheck failed: %v", err)}
+	case isDone:
+		return &plan.HostPreview{State: plan.PreviewSatisfied}
+	default:
+		return &plan.HostPreview{State: plan.PreviewWouldChange}
+	}
+}
+
+func sortedNodeIDs(preview plan.Preview) []plan.NodeID {
+	ids := make([]plan.NodeID, 0, len(preview))
+	for id := range preview {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
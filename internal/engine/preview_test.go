@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// recordingConnector records every command and treats "test -f" and "touch" against an in-memory file set.
+// Touching a path listed in broken fails.
+type recordingConnector struct {
+	connector.Connector
+	mu       sync.Mutex
+	files    map[string]bool
+	broken   map[string]bool
+	commands []string
+}
+
+func (c *recordingConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commands = append(c.commands, cmd)
+	switch {
+	case strings.HasPrefix(cmd, "test -f "):
+		if !c.files[strings.TrimPrefix(cmd, "test -f ")] {
+			return nil, nil, fmt.Errorf("exit status 1")
+		}
+	case strings.HasPrefix(cmd, "touch "):
+		if c.broken[strings.TrimPrefix(cmd, "touch ")] {
+			return nil, nil, fmt.Errorf("exit status 1")
+		}
+		c.files[strings.TrimPrefix(cmd, "touch ")] = true
+	}
+	return nil, nil, nil
+}
+
+func (c *recordingConnector) mutations() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []string
+	for _, cmd := range c.commands {
+		if !strings.HasPrefix(cmd, "test -f ") {
+			out = append(out, cmd)
+		}
+	}
+	return out
+}
+
+// touchFileStep creates a file, and is done when the file exists.
+type touchFileStep struct {
+	step.Base
+	path        string
+	conns       map[string]*recordingConnector
+	precheckErr error
+}
+
+func (s *touchFileStep) Meta() *spec.StepMeta { return &s.Base.Meta }
+
+func (s *touchFileStep) Precheck(ctx runtime.ExecutionContext) (bool, error) {
+	if s.precheckErr != nil {
+		return false, s.precheckErr
+	}
+	_, _, err := s.conns[ctx.GetHost().GetName()].Exec(ctx.GoContext(), "test -f "+s.path, nil)
+	return err == nil, nil
+}
+
+func (s *touchFileStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	if _, _, err := s.conns[ctx.GetHost().GetName()].Exec(ctx.GoContext(), "touch "+s.path, nil); err != nil {
+		result.MarkFailed(err, "touch failed")
+		return result, err
+	}
+	result.MarkCompleted("file created")
+	return result, nil
+}
+
+func (s *touchFileStep) GetStatus(ctx runtime.ExecutionContext) (types.StepStatus, error) {
+	return types.StepStatusPending, nil
+}
+
+func newPreviewTestGraph(conns map[string]*recordingConnector, hosts map[string]remotefw.Host) *plan.ExecutionGraph {
+	newNode := func(name, path string, hostNames ...string) *plan.ExecutionNode {
+		s := &touchFileStep{path: path, conns: conns}
+		s.Base.Meta.Name = name
+		node := &plan.ExecutionNode{Name: name, StepName: name, Step: s, Hostnames: hostNames}
+		for _, h := range hostNames {
+			node.Hosts = append(node.Hosts, hosts[h])
+		}
+		return node
+	}
+
+	g := plan.NewExecutionGraph("Preview")
+	g.Nodes["config"] = newNode("config", "/etc/app.conf", "node1", "node2")
+	g.Nodes["marker"] = newNode("marker", "/etc/marker", "node1")
+	g.Nodes["marker"].Dependencies = []plan.NodeID{"config"}
+	g.Nodes["broken"] = newNode("broken", "/etc/broken", "node2")
+	g.Nodes["broken"].Step.(*touchFileStep).precheckErr = fmt.Errorf("cannot reach host")
+	g.Nodes["optional"] = newNode("optional", "/etc/optional", "node1")
+	g.Nodes["optional"].Condition = func(ctx runtime.ExecutionContext) (bool, error) { return false, nil }
+	return g
+}
+
+func newPreviewTestFixture() (map[string]*recordingConnector, *plan.ExecutionGraph, *runtime.Context) {
+	conns := map[string]*recordingConnector{
+		"node1": {files: map[string]bool{"/etc/app.conf": true, "/etc/marker": true}},
+		"node2": {files: map[string]bool{}},
+	}
+	hosts := map[string]remotefw.Host{}
+	for name := range conns {
+		hosts[name] = connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Address: name})
+	}
+	ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+	return conns, newPreviewTestGraph(conns, hosts), ctx
+}
+
+func TestExecute_PreviewReportsSatisfiedAndWouldChange(t *testing.T) {
+	conns, g, ctx := newPreviewTestFixture()
+	e := NewCheckpointExecutor(ExecutorOptions{Preview: true})
+
+	result, err := e.Execute(ctx, g, true)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if result.Preview == nil {
+		t.Fatal("expected a preview in the result")
+	}
+
+	expected := map[plan.NodeID]plan.PreviewState{
+		"config":   plan.PreviewWouldChange,
+		"marker":   plan.PreviewSatisfied,
+		"broken":   plan.PreviewUnknown,
+		"optional": plan.PreviewSkipped,
+	}
+	for id, state := range expected {
+		if got := result.Preview[id].State; got != state {
+			t.Errorf("expected node %s to be %s, got %s", id, state, got)
+		}
+	}
+	config := result.Preview["config"].Hosts
+	if config["node1"].State != plan.PreviewSatisfied || config["node2"].State != plan.PreviewWouldChange {
+		t.Errorf("expected config to be satisfied on node1 and to change node2, got node1=%s node2=%s",
+			config["node1"].State, config["node2"].State)
+	}
+	if got := result.Preview.Summary(); got != "1 would change, 1 satisfied, 1 skipped, 1 unknown" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	for name, conn := range conns {
+		if m := conn.mutations(); len(m) != 0 {
+			t.Errorf("expected the preview to be read-only, got %v on %s", m, name)
+		}
+	}
+}
+
+func TestExecute_PreviewBeforeExecution(t *testing.T) {
+	conns, g, ctx := newPreviewTestFixture()
+	delete(g.Nodes, "broken")
+	e := NewCheckpointExecutor(ExecutorOptions{Preview: true})
+
+	result, err := e.Execute(ctx, g, false)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if result.Status != plan.StatusSuccess {
+		t.Fatalf("expected the run to succeed, got %s: %s", result.Status, result.Message)
+	}
+	if got := result.Preview["config"].State; got != plan.PreviewWouldChange {
+		t.Errorf("expected the preview to be taken before config ran, got %s", got)
+	}
+	if m := conns["node2"].mutations(); len(m) != 1 || m[0] != "touch /etc/app.conf" {
+		t.Errorf("expected only config to change node2, got %v", m)
+	}
+	if m := conns["node1"].mutations(); len(m) != 0 {
+		t.Errorf("expected no changes on node1, got %v", m)
+	}
+}
+
+func TestExecute_NoPreviewByDefault(t *testing.T) {
+	_, g, ctx := newPreviewTestFixture()
+	result, err := NewCheckpointExecutor(ExecutorOptions{}).Execute(ctx, g, true)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if result.Preview != nil {
+		t.Errorf("expected no preview without the option, got %v", result.Preview)
+	}
+}
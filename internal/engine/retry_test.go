@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// flakyStep fails with err on its first failures runs and succeeds afterwards.
+type flakyStep struct {
+	step.Base
+	failures int32
+	err      error
+	runs     atomic.Int32
+}
+
+func (s *flakyStep) Meta() *spec.StepMeta { return &s.Base.Meta }
+
+func (s *flakyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	if s.runs.Add(1) <= s.failures {
+		result.MarkFailed(s.err, "flaky")
+		return result, s.err
+	}
+	result.MarkCompleted("done")
+	return result, nil
+}
+
+func (s *flakyStep) Rollback(ctx runtime.ExecutionContext) error { return nil }
+
+func TestExecute_RetryPolicy(t *testing.T) {
+	sshDrop := errors.New("ssh: unexpected EOF")
+	aptLock := errors.New("E: Could not get lock /var/lib/dpkg/lock-frontend")
+	fast := func(maxAttempts int, retryOn ...string) *plan.RetryPolicy {
+		return &plan.RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Millisecond, RetryOn: retryOn}
+	}
+
+	tests := []struct {
+		name       string
+		opts       ExecutorOptions
+		retry      *plan.RetryPolicy
+		failures   int32
+		err        error
+		wantStatus plan.Status
+		wantRuns   int32
+	}{
+		{name: "no retries by default", failures: 1, err: sshDrop, wantStatus: plan.StatusFailed, wantRuns: 1},
+		{name: "executor default retries", opts: ExecutorOptions{MaxRetries: 2, RetryBaseDelay: time.Millisecond}, failures: 2, err: sshDrop, wantStatus: plan.StatusSuccess, wantRuns: 3},
+		{name: "node policy overrides default", opts: ExecutorOptions{MaxRetries: 5, RetryBaseDelay: time.Millisecond}, retry: fast(2), failures: 2, err: sshDrop, wantStatus: plan.StatusFailed, wantRuns: 2},
+		{name: "matching error is retried", retry: fast(3, "unexpected EOF", "Could not get lock"), failures: 2, err: aptLock, wantStatus: plan.StatusSuccess, wantRuns: 3},
+		{name: "other errors are not retried", retry: fast(3, "Could not get lock"), failures: 2, err: sshDrop, wantStatus: plan.StatusFailed, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &flakyStep{failures: tt.failures, err: tt.err}
+			s.Base.Meta.Name = "flaky"
+			host := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "node1"})
+			g := plan.NewExecutionGraph("Retry")
+			g.Nodes["flaky"] = &plan.ExecutionNode{Name: "flaky", StepName: "flaky", Step: s, Hosts: []remotefw.Host{host}, Hostnames: []string{"node1"}, Retry: tt.retry}
+
+			ctx := &runtime.Context{GoCtx: context.Background(), Logger: logger.Get()}
+			result, err := NewCheckpointExecutor(tt.opts).Execute(ctx, g, false)
+			if err != nil {
+				t.Fatalf("execute failed: %v", err)
+			}
+			if got := result.NodeResults["flaky"].Status; got != tt.wantStatus {
+				t.Errorf("expected node status %s, got %s", tt.wantStatus, got)
+			}
+			if got := s.runs.Load(); got != tt.wantRuns {
+				t.Errorf("expected %d runs, got %d", tt.wantRuns, got)
+			}
+		})
+	}
+}
+
+func TestNewCheckpointExecutorForPipeline_RetryDefaults(t *testing.T) {
+	ctx := &runtime.Context{
+		Logger:        logger.Get(),
+		GlobalWorkDir: t.TempDir(),
+		ClusterConfig: &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+			System: &v1alpha1.SystemSpec{Retry: &v1alpha1.RetrySpec{MaxAttempts: 4, BaseDelay: time.Second, MaxDelay: 8 * time.Second, RetryOn: []string{"EOF"}}},
+		}},
+	}
+	ctx.ClusterConfig.Name = "test"
+
+	e, ok := NewCheckpointExecutorForPipeline(ctx, "CreateCluster").(*dagExecutor)
+	if !ok {
+		t.Fatal("expected a *dagExecutor")
+	}
+	if e.retry.MaxAttempts != 4 || e.retry.BaseDelay != time.Second || e.retry.MaxDelay != 8*time.Second || len(e.retry.RetryOn) != 1 {
+		t.Errorf("expected the system retry spec to become the default policy, got %+v", e.retry)
+	}
+}
@@ -0,0 +1,167 @@
+// Package hooks runs the user-defined hooks of a cluster config on engine events.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// queueSize is how many events may wait for slow hooks before Handle blocks the engine.
+const queueSize = 1024
+
+// Dispatcher runs hooks for the events passed to Handle. Hooks run on a single goroutine, so they
+// see events in the order they were sent and never run concurrently.
+type Dispatcher struct {
+	hooks  []v1alpha1.Hook
+	log    *logger.Logger
+	client *http.Client
+	queue  chan runtime.Event
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewDispatcher starts a dispatcher for hooks. Close must be called to wait for pending hooks.
+func NewDispatcher(hooks []v1alpha1.Hook, log *logger.Logger) *Dispatcher {
+	d := &Dispatcher{
+		hooks:  hooks,
+		log:    log,
+		client: &http.Client{},
+		queue:  make(chan runtime.Event, queueSize),
+		done:   make(chan struct{}),
+	}
+	go d.loop()
+	return d
+}
+
+// Handle queues ev if any hook subscribes to it. It can be used as a runtime.EventHandler.
+// Events sent after Close are dropped.
+func (d *Dispatcher) Handle(ev runtime.Event) {
+	if !slices.ContainsFunc(d.hooks, func(h v1alpha1.Hook) bool { return Matches(&h, ev) }) {
+		return
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	d.queue <- ev
+}
+
+// Close waits until the hooks for all queued events have run.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	<-d.done
+}
+
+func (d *Dispatcher) loop() {
+	defer close(d.done)
+	for ev := range d.queue {
+		for i := range d.hooks {
+			hook := &d.hooks[i]
+			if !Matches(hook, ev) {
+				continue
+			}
+			if err := d.run(hook, ev); err != nil {
+				d.log.Warnf("Hook '%s' failed on %s event: %v", hook.Name, ev.Type, err)
+			}
+		}
+	}
+}
+
+// Matches reports whether hook subscribes to ev.
+func Matches(hook *v1alpha1.Hook, ev runtime.Event) bool {
+	events := hook.Events
+	if len(events) == 0 {
+		events = []string{common.HookEventGraphFinished}
+	}
+	if !slices.Contains(events, string(ev.Type)) {
+		return false
+	}
+	return len(hook.Status) == 0 || slices.Contains(hook.Status, ev.Status)
+}
+
+func (d *Dispatcher) run(hook *v1alpha1.Hook, ev runtime.Event) error {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = common.DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if hook.Webhook != nil {
+		return d.callWebhook(ctx, hook.Webhook, payload)
+	}
+	return runCommand(ctx, hook.Command, ev, payload)
+}
+
+func runCommand(ctx context.Context, command string, ev runtime.Event, payload []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), EventEnv(ev)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command failed: %w, output: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func (d *Dispatcher) callWebhook(ctx context.Context, webhook *v1alpha1.WebhookSpec, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range webhook.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request to %s failed: %w", webhook.URL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", webhook.URL, resp.Status)
+	}
+	return nil
+}
+
+// EventEnv returns the environment variables describing ev to a hook command.
+func EventEnv(ev runtime.Event) []string {
+	return []string{
+		"KUBEXM_EVENT_TYPE=" + string(ev.Type),
+		"KUBEXM_EVENT_TIME=" + ev.Time.Format(time.RFC3339),
+		"KUBEXM_EVENT_GRAPH=" + ev.Graph,
+		"KUBEXM_EVENT_NODE_ID=" + ev.NodeID,
+		"KUBEXM_EVENT_NODE=" + ev.NodeName,
+		"KUBEXM_EVENT_PHASE=" + ev.Phase,
+		"KUBEXM_EVENT_HOST=" + ev.Host,
+		"KUBEXM_EVENT_STATUS=" + ev.Status,
+		"KUBEXM_EVENT_MESSAGE=" + ev.Message,
+	}
+}
@@ -0,0 +1,107 @@
+package hooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+func TestSupportedHookEventsMatchRuntime(t *testing.T) {
+	if len(common.SupportedHookEvents) != len(runtime.EventTypes) {
+		t.Fatalf("expected %d supported hook events, got %v", len(runtime.EventTypes), common.SupportedHookEvents)
+	}
+	for i, et := range runtime.EventTypes {
+		if common.SupportedHookEvents[i] != string(et) {
+			t.Errorf("expected hook event %d to be %s, got %s", i, et, common.SupportedHookEvents[i])
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	hook := &v1alpha1.Hook{Events: []string{"NodeFinished"}, Status: []string{"Failed"}}
+	if !Matches(hook, runtime.Event{Type: runtime.EventNodeFinished, Status: "Failed"}) {
+		t.Error("expected a failed node to match")
+	}
+	if Matches(hook, runtime.Event{Type: runtime.EventNodeFinished, Status: "Success"}) {
+		t.Error("expected a successful node not to match the status filter")
+	}
+	if Matches(hook, runtime.Event{Type: runtime.EventGraphFinished, Status: "Failed"}) {
+		t.Error("expected another event type not to match")
+	}
+	if !Matches(&v1alpha1.Hook{}, runtime.Event{Type: runtime.EventGraphFinished}) {
+		t.Error("expected a hook without events to match GraphFinished")
+	}
+}
+
+func TestDispatcher_Command(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "events.log")
+	d := NewDispatcher([]v1alpha1.Hook{{
+		Name:    "record",
+		Events:  []string{"NodeFinished"},
+		Command: `printf '%s %s ' "$KUBEXM_EVENT_NODE" "$KUBEXM_EVENT_STATUS" >> ` + out + ` && cat >> ` + out + ` && echo >> ` + out,
+	}, {
+		Name:    "broken",
+		Events:  []string{"NodeFinished"},
+		Command: "exit 3",
+	}}, logger.Get())
+
+	d.Handle(runtime.Event{Type: runtime.EventNodeStarted, NodeName: "InstallEtcd"})
+	d.Handle(runtime.Event{Type: runtime.EventNodeFinished, NodeName: "InstallEtcd", Status: "Success", Time: time.Now()})
+	d.Handle(runtime.Event{Type: runtime.EventNodeFinished, NodeName: "InstallKubelet", Status: "Failed", Time: time.Now()})
+	d.Close()
+	d.Handle(runtime.Event{Type: runtime.EventNodeFinished, NodeName: "late"})
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected the hook to run: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "InstallEtcd Success {") || !strings.HasPrefix(lines[1], "InstallKubelet Failed {") {
+		t.Fatalf("expected one line per finished node in order, got:\n%s", data)
+	}
+	var ev runtime.Event
+	if err := json.Unmarshal([]byte(strings.SplitN(lines[1], " ", 3)[2]), &ev); err != nil || ev.NodeName != "InstallKubelet" {
+		t.Errorf("expected the event as JSON on stdin, got %q (%v)", lines[1], err)
+	}
+}
+
+func TestDispatcher_Webhook(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		auth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	d := NewDispatcher([]v1alpha1.Hook{{
+		Name:    "notify",
+		Webhook: &v1alpha1.WebhookSpec{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+	}}, logger.Get())
+	d.Handle(runtime.Event{Type: runtime.EventGraphStarted, Graph: "CreateCluster"})
+	d.Handle(runtime.Event{Type: runtime.EventGraphFinished, Graph: "CreateCluster", Status: "Success"})
+	d.Close()
+
+	if len(bodies) != 1 || !strings.Contains(bodies[0], `"type":"GraphFinished"`) || !strings.Contains(bodies[0], `"status":"Success"`) {
+		t.Errorf("expected one GraphFinished call, got %v", bodies)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("expected the configured header, got %q", auth)
+	}
+}
@@ -0,0 +1,50 @@
+// Package infra creates the machines a cluster is installed on through the API of an
+// infrastructure provider, as configured in spec.infrastructure.
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+// Provider creates, lists and deletes the machines of a cluster. Machines are matched to the
+// cluster by name and by a marker the provider sets when it creates them, so machines it did
+// not create are never touched.
+type Provider interface {
+	// Name is the name of the provider, such as openstack.
+	Name() string
+	// Provision creates the configured machines that do not exist yet, waits until all of them
+	// are running and returns them as the hosts of the cluster, in configuration order.
+	Provision(ctx context.Context) ([]v1alpha1.HostSpec, error)
+	// Destroy deletes the machines named, or all machines of the cluster if none are named, and
+	// waits until they are gone. Machines that do not exist are skipped.
+	Destroy(ctx context.Context, names []string) error
+	// ListHosts returns the machines of the cluster that exist, sorted by name.
+	ListHosts(ctx context.Context) ([]Machine, error)
+}
+
+// Machine is a machine of the cluster as the provider reports it.
+type Machine struct {
+	Name  string
+	ID    string
+	State string
+	// Address is the address kubexm reaches the machine at, InternalAddress its address on the
+	// cluster network. They differ when the machine has a floating IP.
+	Address         string
+	InternalAddress string
+}
+
+// NewProvider returns the provider configured in spec.infrastructure of cluster.
+func NewProvider(cluster *v1alpha1.Cluster) (Provider, error) {
+	infra := cluster.Spec.Infrastructure
+	switch {
+	case infra != nil && infra.OpenStack != nil:
+		return NewOpenStackProvider(cluster.Name, infra.OpenStack), nil
+	case infra != nil && infra.Libvirt != nil:
+		return nil, fmt.Errorf("libvirt VMs are created over SSH on the hypervisors, use 'kubexm vm provision' for them")
+	default:
+		return nil, fmt.Errorf("the cluster configuration has no spec.infrastructure provider")
+	}
+}
@@ -0,0 +1,302 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/logger"
+)
+
+// OpenStackProvider creates the machines of spec.infrastructure.openstack as servers through the
+// Nova API. Servers carry the cluster name in their common.OpenStackClusterMetadataKey metadata.
+type OpenStackProvider struct {
+	clusterName string
+	cfg         *v1alpha1.OpenStackInfrastructure
+	httpClient  *http.Client
+	// probeSSH checks that sshd answers on a new server, which can only be done when it is
+	// reachable without a bastion.
+	probeSSH     func(ctx context.Context, address string) error
+	pollInterval time.Duration
+}
+
+func NewOpenStackProvider(clusterName string, cfg *v1alpha1.OpenStackInfrastructure) *OpenStackProvider {
+	return &OpenStackProvider{
+		clusterName:  clusterName,
+		cfg:          cfg,
+		httpClient:   &http.Client{Timeout: time.Minute},
+		probeSSH:     dialSSH,
+		pollInterval: common.OpenStackPollInterval,
+	}
+}
+
+func (p *OpenStackProvider) Name() string { return "openstack" }
+
+// credentials returns the configured credentials, with the unset ones taken from the OS_*
+// variables of an openrc file.
+func (p *OpenStackProvider) credentials() openStackCredentials {
+	pick := func(value, env, def string) string {
+		if value != "" {
+			return value
+		}
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+		return def
+	}
+	return openStackCredentials{
+		AuthURL:           pick(p.cfg.AuthURL, "OS_AUTH_URL", ""),
+		Region:            pick(p.cfg.Region, "OS_REGION_NAME", ""),
+		ProjectName:       pick(p.cfg.ProjectName, "OS_PROJECT_NAME", ""),
+		ProjectDomainName: pick(p.cfg.ProjectDomainName, "OS_PROJECT_DOMAIN_NAME", common.DefaultOpenStackDomainName),
+		UserName:          pick(p.cfg.UserName, "OS_USERNAME", ""),
+		UserDomainName:    pick(p.cfg.UserDomainName, "OS_USER_DOMAIN_NAME", common.DefaultOpenStackDomainName),
+		Password:          pick(p.cfg.Password, "OS_PASSWORD", ""),
+	}
+}
+
+// servers returns the servers of the cluster by name, only those named if names are given.
+func (p *OpenStackProvider) servers(ctx context.Context, client *openStackClient, names []string) (map[string]openStackServer, error) {
+	list, err := client.listServers(ctx, common.OpenStackClusterMetadataKey, p.clusterName, names)
+	if err != nil {
+		return nil, err
+	}
+	servers := make(map[string]openStackServer, len(list))
+	for _, s := range list {
+		servers[s.Name] = s
+	}
+	return servers, nil
+}
+
+func (p *OpenStackProvider) Provision(ctx context.Context) ([]v1alpha1.HostSpec, error) {
+	log := logger.Get()
+	machines, err := p.cfg.ExpandMachines()
+	if err != nil {
+		return nil, err
+	}
+	client, err := authenticateOpenStack(ctx, p.httpClient, p.credentials())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(machines))
+	for _, m := range machines {
+		names = append(names, m.Name)
+	}
+	existing, err := p.servers(ctx, client, names)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(machines))
+	var request *openStackServerRequest
+	flavors := make(map[string]string)
+	for _, m := range machines {
+		if s, ok := existing[m.Name]; ok {
+			log.Infof("Server %s already exists (%s).", m.Name, s.Status)
+			ids[m.Name] = s.ID
+			continue
+		}
+		if request == nil {
+			if request, err = p.serverRequest(ctx, client); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := flavors[m.Flavor]; !ok {
+			if flavors[m.Flavor], err = client.flavorID(ctx, m.Flavor); err != nil {
+				return nil, err
+			}
+		}
+		server := *request
+		server.Name = m.Name
+		server.FlavorRef = flavors[m.Flavor]
+		id, err := client.createServer(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Creating server %s (%s) with flavor %s.", m.Name, id, m.Flavor)
+		ids[m.Name] = id
+	}
+
+	deadline := time.Now().Add(p.cfg.WaitTimeout)
+	hosts := make([]v1alpha1.HostSpec, 0, len(machines))
+	for _, m := range machines {
+		host, err := p.waitForServer(ctx, client, m.Name, ids[m.Name], deadline)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// serverRequest returns the part of the create request that is the same for all servers.
+func (p *OpenStackProvider) serverRequest(ctx context.Context, client *openStackClient) (*openStackServerRequest, error) {
+	imageID, err := client.imageID(ctx, p.cfg.Image)
+	if err != nil {
+		return nil, err
+	}
+	networkID, err := client.networkID(ctx, p.cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	request := &openStackServerRequest{
+		ImageRef: imageID,
+		KeyName:  p.cfg.KeyPair,
+		Networks: []map[string]string{{"uuid": networkID}},
+		Metadata: map[string]string{common.OpenStackClusterMetadataKey: p.clusterName},
+	}
+	for _, sg := range p.cfg.SecurityGroups {
+		request.SecurityGroups = append(request.SecurityGroups, map[string]string{"name": sg})
+	}
+	return request, nil
+}
+
+// waitForServer waits until a server is active with an IPv4 address and, if it can be reached
+// directly, sshd answers on it, and returns it as a host.
+func (p *OpenStackProvider) waitForServer(ctx context.Context, client *openStackClient, name, id string, deadline time.Time) (v1alpha1.HostSpec, error) {
+	for {
+		server, err := client.getServer(ctx, id)
+		if err != nil {
+			return v1alpha1.HostSpec{}, fmt.Errorf("failed to get server %s: %w", name, err)
+		}
+		if server.Status == "ERROR" {
+			reason := "unknown reason"
+			if server.Fault != nil && server.Fault.Message != "" {
+				reason = server.Fault.Message
+			}
+			return v1alpha1.HostSpec{}, fmt.Errorf("server %s failed to build: %s", name, reason)
+		}
+		if server.Status == "ACTIVE" {
+			if host := p.hostSpec(server); host.Address != "" {
+				if host.Bastion != nil || p.probeSSH(ctx, host.Address) == nil {
+					return host, nil
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			return v1alpha1.HostSpec{}, fmt.Errorf("server %s was not ready within %s, its status is %s", name, p.cfg.WaitTimeout, server.Status)
+		}
+		select {
+		case <-ctx.Done():
+			return v1alpha1.HostSpec{}, ctx.Err()
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+// hostSpec returns the host of a server, reached at its floating IP if it has one and through
+// the bastion otherwise.
+func (p *OpenStackProvider) hostSpec(server *openStackServer) v1alpha1.HostSpec {
+	fixed, floating := server.addresses()
+	host := v1alpha1.HostSpec{
+		Name:            server.Name,
+		Address:         fixed,
+		InternalAddress: fixed,
+		User:            p.cfg.User,
+		PrivateKeyPath:  p.cfg.PrivateKeyPath,
+	}
+	if floating != "" {
+		host.Address = floating
+	} else if p.cfg.Bastion != nil {
+		bastion := *p.cfg.Bastion
+		host.Bastion = &bastion
+	}
+	return host
+}
+
+func (p *OpenStackProvider) Destroy(ctx context.Context, names []string) error {
+	log := logger.Get()
+	client, err := authenticateOpenStack(ctx, p.httpClient, p.credentials())
+	if err != nil {
+		return err
+	}
+	existing, err := p.servers(ctx, client, names)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		for name := range existing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var deleted []openStackServer
+	for _, name := range names {
+		server, ok := existing[name]
+		if !ok {
+			log.Infof("Server %s does not exist, skipping.", name)
+			continue
+		}
+		if err := client.deleteServer(ctx, server.ID); err != nil {
+			return fmt.Errorf("failed to delete server %s: %w", name, err)
+		}
+		log.Infof("Deleting server %s (%s).", name, server.ID)
+		deleted = append(deleted, server)
+	}
+
+	deadline := time.Now().Add(p.cfg.WaitTimeout)
+	for _, server := range deleted {
+		for {
+			_, err := client.getServer(ctx, server.ID)
+			if errors.Is(err, errNotFound) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get server %s: %w", server.Name, err)
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("server %s was not deleted within %s", server.Name, p.cfg.WaitTimeout)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.pollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+func (p *OpenStackProvider) ListHosts(ctx context.Context) ([]Machine, error) {
+	client, err := authenticateOpenStack(ctx, p.httpClient, p.credentials())
+	if err != nil {
+		return nil, err
+	}
+	servers, err := p.servers(ctx, client, nil)
+	if err != nil {
+		return nil, err
+	}
+	machines := make([]Machine, 0, len(servers))
+	for _, server := range servers {
+		host := p.hostSpec(&server)
+		machines = append(machines, Machine{
+			Name:            server.Name,
+			ID:              server.ID,
+			State:           server.Status,
+			Address:         host.Address,
+			InternalAddress: host.InternalAddress,
+		})
+	}
+	sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+	return machines, nil
+}
+
+// dialSSH checks that a TCP connection can be opened to port 22 of address.
+func dialSSH(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: 3 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, "22"))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+var _ Provider = (*OpenStackProvider)(nil)
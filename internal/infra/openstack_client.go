@@ -0,0 +1,353 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// errNotFound is returned by openStackClient.do for 404 responses.
+var errNotFound = errors.New("not found")
+
+// openStackCredentials are the Keystone v3 password credentials of a project.
+type openStackCredentials struct {
+	AuthURL           string
+	Region            string
+	ProjectName       string
+	ProjectDomainName string
+	UserName          string
+	UserDomainName    string
+	Password          string
+}
+
+// openStackClient calls the compute, image and network APIs of a cloud with a Keystone token.
+type openStackClient struct {
+	http    *http.Client
+	token   string
+	compute string
+	image   string
+	network string
+}
+
+type openStackServer struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Metadata  map[string]string `json:"metadata"`
+	Addresses map[string][]struct {
+		Addr    string `json:"addr"`
+		Version int    `json:"version"`
+		Type    string `json:"OS-EXT-IPS:type"`
+	} `json:"addresses"`
+	Fault *struct {
+		Message string `json:"message"`
+	} `json:"fault,omitempty"`
+}
+
+// addresses returns the fixed and the floating IPv4 address of the server, if it has them.
+func (s *openStackServer) addresses() (fixed, floating string) {
+	for _, addrs := range s.Addresses {
+		for _, a := range addrs {
+			if a.Version != 4 {
+				continue
+			}
+			if a.Type == "floating" {
+				if floating == "" {
+					floating = a.Addr
+				}
+			} else if fixed == "" {
+				fixed = a.Addr
+			}
+		}
+	}
+	return fixed, floating
+}
+
+// authenticateOpenStack gets a project-scoped token and looks up the public endpoints of the
+// region in the service catalog.
+func authenticateOpenStack(ctx context.Context, httpClient *http.Client, creds openStackCredentials) (*openStackClient, error) {
+	if creds.AuthURL == "" || creds.UserName == "" || creds.Password == "" || creds.ProjectName == "" {
+		return nil, fmt.Errorf("OpenStack credentials are incomplete: authURL, userName, password and projectName must be configured or set through OS_* environment variables")
+	}
+	authURL := strings.TrimRight(creds.AuthURL, "/")
+	if !strings.HasSuffix(authURL, "/v3") {
+		authURL += "/v3"
+	}
+
+	var body struct {
+		Auth struct {
+			Identity struct {
+				Methods  []string `json:"methods"`
+				Password struct {
+					User struct {
+						Name     string            `json:"name"`
+						Domain   map[string]string `json:"domain"`
+						Password string            `json:"password"`
+					} `json:"user"`
+				} `json:"password"`
+			} `json:"identity"`
+			Scope struct {
+				Project struct {
+					Name   string            `json:"name"`
+					Domain map[string]string `json:"domain"`
+				} `json:"project"`
+			} `json:"scope"`
+		} `json:"auth"`
+	}
+	body.Auth.Identity.Methods = []string{"password"}
+	body.Auth.Identity.Password.User.Name = creds.UserName
+	body.Auth.Identity.Password.User.Domain = map[string]string{"name": creds.UserDomainName}
+	body.Auth.Identity.Password.User.Password = creds.Password
+	body.Auth.Scope.Project.Name = creds.ProjectName
+	body.Auth.Scope.Project.Domain = map[string]string{"name": creds.ProjectDomainName}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, authURL+"/auth/tokens", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate to %s: %w", authURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("failed to authenticate to %s as %s: %s: %s", authURL, creds.UserName, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var token struct {
+		Token struct {
+			Catalog []struct {
+				Type      string `json:"type"`
+				Endpoints []struct {
+					Interface string `json:"interface"`
+					Region    string `json:"region"`
+					RegionID  string `json:"region_id"`
+					URL       string `json:"url"`
+				} `json:"endpoints"`
+			} `json:"catalog"`
+		} `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode the token of %s: %w", authURL, err)
+	}
+
+	client := &openStackClient{http: httpClient, token: resp.Header.Get("X-Subject-Token")}
+	endpoints := map[string]*string{"compute": &client.compute, "image": &client.image, "network": &client.network}
+	for _, service := range token.Token.Catalog {
+		target, ok := endpoints[service.Type]
+		if !ok {
+			continue
+		}
+		for _, ep := range service.Endpoints {
+			if ep.Interface != "public" || (creds.Region != "" && ep.RegionID != creds.Region && ep.Region != creds.Region) {
+				continue
+			}
+			*target = strings.TrimRight(ep.URL, "/")
+			break
+		}
+	}
+	for serviceType, target := range endpoints {
+		if *target == "" {
+			return nil, fmt.Errorf("the service catalog has no public %s endpoint in region '%s'", serviceType, creds.Region)
+		}
+	}
+	return client, nil
+}
+
+// do sends a request with the token and decodes the JSON response into out, if it is not nil.
+func (c *openStackClient) do(ctx context.Context, method, target string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Auth-Token", c.token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, target, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// resolve returns the ID of the resource named ref in a list API, or ref itself if it is the ID
+// of a resource there.
+func (c *openStackClient) resolve(ctx context.Context, kind, listURL, key, ref string) (string, error) {
+	// The list is under key, next to links and other fields of the API.
+	var list map[string]json.RawMessage
+	if err := c.do(ctx, http.MethodGet, listURL+"?name="+url.QueryEscape(ref), nil, &list); err != nil {
+		return "", fmt.Errorf("failed to look up %s '%s': %w", kind, ref, err)
+	}
+	var items []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if raw, ok := list[key]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return "", fmt.Errorf("failed to decode the %s list: %w", kind, err)
+		}
+	}
+	var ids []string
+	for _, item := range items {
+		if item.Name == ref || item.ID == ref {
+			ids = append(ids, item.ID)
+		}
+	}
+	switch len(ids) {
+	case 1:
+		return ids[0], nil
+	case 0:
+		if err := c.do(ctx, http.MethodGet, listURL+"/"+url.PathEscape(ref), nil, nil); err != nil {
+			if errors.Is(err, errNotFound) {
+				return "", fmt.Errorf("%s '%s' does not exist", kind, ref)
+			}
+			return "", fmt.Errorf("failed to look up %s '%s': %w", kind, ref, err)
+		}
+		return ref, nil
+	default:
+		return "", fmt.Errorf("%s name '%s' is ambiguous, use its ID", kind, ref)
+	}
+}
+
+func (c *openStackClient) imageID(ctx context.Context, ref string) (string, error) {
+	return c.resolve(ctx, "image", c.image+"/v2/images", "images", ref)
+}
+
+func (c *openStackClient) networkID(ctx context.Context, ref string) (string, error) {
+	return c.resolve(ctx, "network", c.network+"/v2.0/networks", "networks", ref)
+}
+
+// flavorID returns the ID of the flavor named or identified by ref. Flavors cannot be filtered
+// by name, so all of them are listed.
+func (c *openStackClient) flavorID(ctx context.Context, ref string) (string, error) {
+	var list struct {
+		Flavors []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"flavors"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.compute+"/flavors/detail", nil, &list); err != nil {
+		return "", fmt.Errorf("failed to list flavors: %w", err)
+	}
+	for _, f := range list.Flavors {
+		if f.ID == ref || f.Name == ref {
+			return f.ID, nil
+		}
+	}
+	return "", fmt.Errorf("flavor '%s' does not exist", ref)
+}
+
+// listServers returns the servers whose metadata key is value, following the next links of the
+// paginated list. Nova cannot filter on metadata; if names are given, the list is narrowed to
+// servers of these names on the server side.
+func (c *openStackClient) listServers(ctx context.Context, key, value string, names []string) ([]openStackServer, error) {
+	target := c.compute + "/servers/detail"
+	if len(names) > 0 {
+		// Nova matches the name filter as a regular expression.
+		quoted := make([]string, 0, len(names))
+		for _, name := range names {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+		target += "?name=" + url.QueryEscape("^("+strings.Join(quoted, "|")+")$")
+	}
+
+	var servers []openStackServer
+	for target != "" {
+		var page struct {
+			Servers []openStackServer `json:"servers"`
+			Links   []struct {
+				Rel  string `json:"rel"`
+				Href string `json:"href"`
+			} `json:"servers_links"`
+		}
+		if err := c.do(ctx, http.MethodGet, target, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list servers: %w", err)
+		}
+		for _, s := range page.Servers {
+			if s.Metadata[key] == value {
+				servers = append(servers, s)
+			}
+		}
+		target = ""
+		for _, link := range page.Links {
+			if link.Rel == "next" {
+				target = link.Href
+			}
+		}
+	}
+	return servers, nil
+}
+
+func (c *openStackClient) getServer(ctx context.Context, id string) (*openStackServer, error) {
+	var out struct {
+		Server openStackServer `json:"server"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.compute+"/servers/"+id, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Server, nil
+}
+
+// openStackServerRequest is the body of a server create request.
+type openStackServerRequest struct {
+	Name           string              `json:"name"`
+	ImageRef       string              `json:"imageRef"`
+	FlavorRef      string              `json:"flavorRef"`
+	KeyName        string              `json:"key_name"`
+	Networks       []map[string]string `json:"networks"`
+	SecurityGroups []map[string]string `json:"security_groups,omitempty"`
+	Metadata       map[string]string   `json:"metadata"`
+}
+
+func (c *openStackClient) createServer(ctx context.Context, server openStackServerRequest) (string, error) {
+	var out struct {
+		Server struct {
+			ID string `json:"id"`
+		} `json:"server"`
+	}
+	if err := c.do(ctx, http.MethodPost, c.compute+"/servers", map[string]interface{}{"server": server}, &out); err != nil {
+		return "", fmt.Errorf("failed to create server %s: %w", server.Name, err)
+	}
+	return out.Server.ID, nil
+}
+
+func (c *openStackClient) deleteServer(ctx context.Context, id string) error {
+	if err := c.do(ctx, http.MethodDelete, c.compute+"/servers/"+id, nil, nil); err != nil && !errors.Is(err, errNotFound) {
+		return err
+	}
+	return nil
+}
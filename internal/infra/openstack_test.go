@@ -0,0 +1,305 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// fakeOpenStack serves the parts of Keystone, Nova, Glance and Neutron the provider uses. New
+// servers become active on the second time they are read.
+type fakeOpenStack struct {
+	*httptest.Server
+	mu       sync.Mutex
+	servers  map[string]map[string]interface{}
+	reads    map[string]int
+	requests []map[string]interface{}
+	nextID   int
+	// nameFilters holds the name filter of every server list request.
+	nameFilters []string
+}
+
+// openStackPageSize is the number of servers per page of the fake server list.
+const openStackPageSize = 2
+
+func (f *fakeOpenStack) sortedServers() []map[string]interface{} {
+	ids := make([]string, 0, len(f.servers))
+	for id := range f.servers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	servers := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		servers = append(servers, f.servers[id])
+	}
+	return servers
+}
+
+func newFakeOpenStack(t *testing.T) *fakeOpenStack {
+	f := &fakeOpenStack{servers: map[string]map[string]interface{}{}, reads: map[string]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Auth struct {
+				Identity struct {
+					Password struct {
+						User struct {
+							Password string `json:"password"`
+						} `json:"user"`
+					} `json:"password"`
+				} `json:"identity"`
+			} `json:"auth"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Auth.Identity.Password.User.Password != "secret" {
+			http.Error(w, `{"error":{"message":"The request you have made requires authentication."}}`, http.StatusUnauthorized)
+			return
+		}
+		endpoint := func(path string) []map[string]string {
+			return []map[string]string{
+				{"interface": "internal", "region_id": "RegionOne", "url": "http://internal.invalid"},
+				{"interface": "public", "region_id": "RegionOne", "url": f.URL + path},
+			}
+		}
+		w.Header().Set("X-Subject-Token", "token-1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"token": map[string]interface{}{"catalog": []map[string]interface{}{
+			{"type": "compute", "endpoints": endpoint("/compute/v2.1")},
+			{"type": "image", "endpoints": endpoint("/image")},
+			{"type": "network", "endpoints": endpoint("/network")},
+		}}})
+	})
+	mux.HandleFunc("/image/v2/images", func(w http.ResponseWriter, r *http.Request) {
+		images := []map[string]string{}
+		if r.URL.Query().Get("name") == "ubuntu-22.04" {
+			images = append(images, map[string]string{"id": "img-1", "name": "ubuntu-22.04"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"images": images, "first": "/v2/images"})
+	})
+	mux.HandleFunc("/network/v2.0/networks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"networks": []map[string]string{}})
+	})
+	mux.HandleFunc("/network/v2.0/networks/net-1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"network": map[string]string{"id": "net-1"}})
+	})
+	mux.HandleFunc("/compute/v2.1/flavors/detail", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"flavors": []map[string]string{{"id": "f-2", "name": "m1.large"}, {"id": "f-3", "name": "m1.xlarge"}}})
+	})
+	mux.HandleFunc("/compute/v2.1/servers", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.nextID++
+		id := fmt.Sprintf("srv-%d", f.nextID)
+		f.requests = append(f.requests, body["server"])
+		f.servers[id] = map[string]interface{}{
+			"id": id, "name": body["server"]["name"], "status": "BUILD", "metadata": body["server"]["metadata"],
+			"addresses": map[string]interface{}{},
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"server": map[string]string{"id": id}})
+	})
+	mux.HandleFunc("/compute/v2.1/servers/detail", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		query := r.URL.Query()
+		f.nameFilters = append(f.nameFilters, query.Get("name"))
+		nameFilter := regexp.MustCompile(query.Get("name"))
+		servers := []map[string]interface{}{}
+		for _, s := range append([]map[string]interface{}{{"id": "other", "name": "cp1", "status": "ACTIVE", "metadata": map[string]string{}}}, f.sortedServers()...) {
+			if nameFilter.MatchString(s["name"].(string)) {
+				servers = append(servers, s)
+			}
+		}
+		if marker := query.Get("marker"); marker != "" {
+			for i, s := range servers {
+				if s["id"] == marker {
+					servers = servers[i+1:]
+					break
+				}
+			}
+		}
+		page := map[string]interface{}{"servers": servers}
+		if len(servers) > openStackPageSize {
+			page["servers"] = servers[:openStackPageSize]
+			query.Set("marker", servers[openStackPageSize-1]["id"].(string))
+			page["servers_links"] = []map[string]string{{"rel": "next", "href": f.URL + r.URL.Path + "?" + query.Encode()}}
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+	mux.HandleFunc("/compute/v2.1/servers/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/compute/v2.1/servers/")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		server, ok := f.servers[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.servers, id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if f.reads[id]++; f.reads[id] > 1 {
+			n := strings.TrimPrefix(id, "srv-")
+			server["status"] = "ACTIVE"
+			server["addresses"] = map[string]interface{}{"private": []map[string]interface{}{
+				{"addr": "fd00::" + n, "version": 6, "OS-EXT-IPS:type": "fixed"},
+				{"addr": "10.0.0." + n, "version": 4, "OS-EXT-IPS:type": "fixed"},
+			}}
+			if server["name"] == "cp1" {
+				server["addresses"].(map[string]interface{})["private"] = append(server["addresses"].(map[string]interface{})["private"].([]map[string]interface{}),
+					map[string]interface{}{"addr": "203.0.113.5", "version": 4, "OS-EXT-IPS:type": "floating"})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"server": server})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func newTestOpenStackProvider(f *fakeOpenStack) *OpenStackProvider {
+	cfg := &v1alpha1.OpenStackInfrastructure{
+		AuthURL:        f.URL + "/identity",
+		Region:         "RegionOne",
+		ProjectName:    "lab",
+		UserName:       "admin",
+		Password:       "secret",
+		Image:          "ubuntu-22.04",
+		Flavor:         "m1.large",
+		Network:        "net-1",
+		KeyPair:        "kubexm",
+		SecurityGroups: []string{"k8s"},
+		User:           "ubuntu",
+		PrivateKeyPath: "/keys/id_ed25519",
+		Bastion:        &v1alpha1.BastionSpec{Address: "203.0.113.10", User: "ubuntu"},
+		WaitTimeout:    time.Minute,
+		Machines:       []v1alpha1.OpenStackMachine{{Name: "cp1", Flavor: "m1.large"}, {Name: "worker[1:2]", Flavor: "m1.xlarge"}},
+	}
+	p := NewOpenStackProvider("lab", cfg)
+	p.probeSSH = func(context.Context, string) error { return nil }
+	p.pollInterval = time.Millisecond
+	return p
+}
+
+func TestOpenStackProvider_Provision(t *testing.T) {
+	f := newFakeOpenStack(t)
+	p := newTestOpenStackProvider(f)
+
+	hosts, err := p.Provision(context.Background())
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	var got []string
+	for _, h := range hosts {
+		bastion := ""
+		if h.Bastion != nil {
+			bastion = "@" + h.Bastion.Address
+		}
+		got = append(got, fmt.Sprintf("%s=%s/%s%s", h.Name, h.Address, h.InternalAddress, bastion))
+	}
+	want := "cp1=203.0.113.5/10.0.0.1,worker1=10.0.0.2/10.0.0.2@203.0.113.10,worker2=10.0.0.3/10.0.0.3@203.0.113.10"
+	if strings.Join(got, ",") != want {
+		t.Errorf("hosts = %v, want %s", got, want)
+	}
+	if hosts[0].User != "ubuntu" || hosts[0].PrivateKeyPath != "/keys/id_ed25519" {
+		t.Errorf("host login = %+v", hosts[0])
+	}
+
+	req := f.requests[1]
+	if req["imageRef"] != "img-1" || req["flavorRef"] != "f-3" || req["key_name"] != "kubexm" {
+		t.Errorf("create request = %v", req)
+	}
+	if md, _ := req["metadata"].(map[string]interface{}); md[common.OpenStackClusterMetadataKey] != "lab" {
+		t.Errorf("server metadata = %v", req["metadata"])
+	}
+
+	// Existing servers are reused, and servers of other clusters are ignored.
+	if _, err := p.Provision(context.Background()); err != nil {
+		t.Fatalf("second Provision() error = %v", err)
+	}
+	if len(f.requests) != 3 {
+		t.Errorf("%d servers were created, want 3", len(f.requests))
+	}
+
+	// The second Provision found its three servers across two pages, listing only the
+	// configured names.
+	if filter := f.nameFilters[len(f.nameFilters)-1]; filter != `^(cp1|worker1|worker2)$` {
+		t.Errorf("server list name filter = %q", filter)
+	}
+
+	machines, err := p.ListHosts(context.Background())
+	if err != nil {
+		t.Fatalf("ListHosts() error = %v", err)
+	}
+	if len(machines) != 3 || machines[0].Name != "cp1" || machines[0].State != "ACTIVE" || machines[0].Address != "203.0.113.5" {
+		t.Errorf("machines = %+v", machines)
+	}
+
+	if err := p.Destroy(context.Background(), []string{"worker2", "worker9"}); err != nil {
+		t.Fatalf("Destroy(worker2) error = %v", err)
+	}
+	if machines, _ := p.ListHosts(context.Background()); len(machines) != 2 {
+		t.Errorf("machines after destroying worker2 = %+v", machines)
+	}
+	if err := p.Destroy(context.Background(), nil); err != nil {
+		t.Fatalf("Destroy() error = %v", err)
+	}
+	if len(f.servers) != 0 {
+		t.Errorf("servers left = %v", f.servers)
+	}
+}
+
+func TestOpenStackProvider_Errors(t *testing.T) {
+	f := newFakeOpenStack(t)
+
+	p := newTestOpenStackProvider(f)
+	p.cfg.Password = "wrong"
+	if _, err := p.ListHosts(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+
+	p = newTestOpenStackProvider(f)
+	p.cfg.Image = "centos"
+	if _, err := p.Provision(context.Background()); err == nil || !strings.Contains(err.Error(), "image 'centos' does not exist") {
+		t.Errorf("expected an unknown image error, got %v", err)
+	}
+
+	p = newTestOpenStackProvider(f)
+	p.cfg.Region = "RegionTwo"
+	if _, err := p.ListHosts(context.Background()); err == nil || !strings.Contains(err.Error(), "RegionTwo") {
+		t.Errorf("expected a missing endpoint error, got %v", err)
+	}
+
+	t.Setenv("OS_AUTH_URL", "")
+	p = newTestOpenStackProvider(f)
+	p.cfg.AuthURL = ""
+	if _, err := p.ListHosts(context.Background()); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("expected incomplete credentials, got %v", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	cluster := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{}}
+	if _, err := NewProvider(cluster); err == nil {
+		t.Error("expected an error without spec.infrastructure")
+	}
+	cluster.Spec.Infrastructure = &v1alpha1.Infrastructure{OpenStack: &v1alpha1.OpenStackInfrastructure{}}
+	if p, err := NewProvider(cluster); err != nil || p.Name() != "openstack" {
+		t.Errorf("NewProvider() = %v, %v", p, err)
+	}
+}
@@ -5,6 +5,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -119,6 +120,35 @@ type Logger struct {
 var globalLogger *Logger
 var once sync.Once
 
+// console is where every logger writes its console output. It is stdout unless redirected with
+// SetConsoleWriter.
+var console = &consoleWriter{w: os.Stdout}
+
+type consoleWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (c *consoleWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.w.Write(p)
+}
+
+// SetConsoleWriter sends the console output of all loggers to w, for example to let a live
+// progress display print log lines above itself. The returned function restores the previous writer.
+func SetConsoleWriter(w io.Writer) (restore func()) {
+	console.mu.Lock()
+	defer console.mu.Unlock()
+	previous := console.w
+	console.w = w
+	return func() {
+		console.mu.Lock()
+		defer console.mu.Unlock()
+		console.w = previous
+	}
+}
+
 func Init(opts Options) {
 	once.Do(func() {
 		var err error
@@ -204,7 +234,7 @@ func NewLogger(opts Options) (*Logger, error) {
 		consoleEnabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
 			return lvl >= consoleStaticLevel
 		})
-		cores = append(cores, zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), consoleEnabler))
+		cores = append(cores, zapcore.NewCore(consoleEncoder, zapcore.AddSync(console), consoleEnabler))
 	}
 
 	if opts.FileOutput {
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithFile returns a logger that writes everything it logs to path as well; see WithWriter. The
+// file is appended to and its parent directories are created. The caller must close the returned
+// io.Closer once the logger is no longer used.
+func (l *Logger) WithFile(path string) (*Logger, io.Closer, error) {
+	if l == nil || l.SugaredLogger == nil {
+		return nil, nil, fmt.Errorf("logger is not initialized")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+	return l.WithWriter(f), f, nil
+}
+
+// WithWriter returns a logger that writes everything it logs to w as well, as JSON lines in the
+// same format as the main log file and at l's level. Each entry is a single Write call. Fields
+// added to l with With before the call are not repeated in w.
+func (l *Logger) WithWriter(w io.Writer) *Logger {
+	timestampFormat := l.opts.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339
+	}
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.TimeEncoderOfLayout(timestampFormat)
+	encoderCfg.TimeKey = "time"
+	encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	encoderCfg.CallerKey = "caller"
+	encoderCfg.MessageKey = "msg"
+	encoderCfg.NameKey = "logger"
+	encoderCfg.StacktraceKey = "stacktrace"
+	var enabler zapcore.LevelEnabler = l.atomicLevel
+	if l.atomicLevel == (zap.AtomicLevel{}) {
+		enabler = zapcore.InfoLevel
+	}
+	writerCore := zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.AddSync(w), enabler)
+
+	teed := l.SugaredLogger.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, writerCore)
+	}))
+	return &Logger{SugaredLogger: teed.Sugar(), opts: l.opts, atomicLevel: l.atomicLevel}
+}
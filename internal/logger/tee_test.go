@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger_WithFile(t *testing.T) {
+	base, err := NewLogger(Options{ConsoleOutput: true, ConsoleLevel: InfoLevel})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "run1", "node", "host1.log")
+
+	log, closer, err := base.WithFile(path)
+	if err != nil {
+		t.Fatalf("WithFile failed: %v", err)
+	}
+	log.Infof("installing %s", "etcd")
+	log.Debugf("below the logger level")
+	base.Infof("not for this file")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected exactly one entry, got:\n%s", data)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("expected a JSON entry, got %q: %v", lines[0], err)
+	}
+	if entry["msg"] != "installing etcd" || entry["level"] != "INFO" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
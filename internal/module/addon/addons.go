@@ -16,8 +16,10 @@ type AddonsModule struct {
 }
 
 // NewAddonsModule creates a new AddonsModule.
+// Addons are optional: a failed addon is reported as a warning and does not fail the install.
 func NewAddonsModule() module.Module {
 	base := module.NewBaseModule("ClusterAddonsDeployment", nil) // Tasks are dynamic via GetTasks
+	base.Optional = true
 	return &AddonsModule{BaseModule: base}
 }
 
@@ -41,7 +43,11 @@ func (m *AddonsModule) GetTasks(ctx runtime.ModuleContext) ([]task.Task, error)
 			enabled := true
 			addon.Enabled = &enabled
 		}
-		addonTasks = append(addonTasks, taskAddon.NewInstallAddonTask(addon))
+		addonTask, err := taskAddon.NewAddonTask(addon)
+		if err != nil {
+			return nil, err
+		}
+		addonTasks = append(addonTasks, addonTask)
 	}
 	return addonTasks, nil
 }
@@ -67,8 +73,9 @@ func (m *AddonsModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment,
 		return plan.NewEmptyFragment(m.Name()), nil
 	}
 
-	var allAddonEntryNodes []plan.NodeID
-	var allAddonExitNodes []plan.NodeID
+	// Custom addons may depend on the built-in ones, e.g. on cert-manager, so they are installed after them.
+	var coreEntryNodes, coreExitNodes []plan.NodeID
+	var customEntryNodes, customExitNodes []plan.NodeID
 
 	for _, addonTask := range definedTasks {
 		// Note: addonTask.Name() might be generic like "InstallAddon".
@@ -102,15 +109,30 @@ func (m *AddonsModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment,
 			return nil, fmt.Errorf("failed to merge fragment from addon task %s: %w", addonInstanceName, err)
 		}
 
-		// Addons can typically be installed in parallel.
-		// Their entry nodes become part of the module's entry nodes (if not dependent on prior module stages).
-		// Their exit nodes all contribute to the module's exit nodes.
-		allAddonEntryNodes = append(allAddonEntryNodes, taskFrag.EntryNodes...)
-		allAddonExitNodes = append(allAddonExitNodes, taskFrag.ExitNodes...)
+		// Addons of the same kind can typically be installed in parallel.
+		if _, custom := addonTask.(*taskAddon.InstallCustomAddonTask); custom {
+			customEntryNodes = append(customEntryNodes, taskFrag.EntryNodes...)
+			customExitNodes = append(customExitNodes, taskFrag.ExitNodes...)
+		} else {
+			coreEntryNodes = append(coreEntryNodes, taskFrag.EntryNodes...)
+			coreExitNodes = append(coreExitNodes, taskFrag.ExitNodes...)
+		}
 	}
 
-	moduleFragment.EntryNodes = plan.UniqueNodeIDs(allAddonEntryNodes)
-	moduleFragment.ExitNodes = plan.UniqueNodeIDs(allAddonExitNodes)
+	switch {
+	case len(coreEntryNodes) == 0:
+		moduleFragment.EntryNodes = plan.UniqueNodeIDs(customEntryNodes)
+		moduleFragment.ExitNodes = plan.UniqueNodeIDs(customExitNodes)
+	case len(customEntryNodes) == 0:
+		moduleFragment.EntryNodes = plan.UniqueNodeIDs(coreEntryNodes)
+		moduleFragment.ExitNodes = plan.UniqueNodeIDs(coreExitNodes)
+	default:
+		if err := plan.LinkFragments(moduleFragment, plan.UniqueNodeIDs(coreExitNodes), plan.UniqueNodeIDs(customEntryNodes)); err != nil {
+			return nil, fmt.Errorf("failed to order custom addons after the built-in addons: %w", err)
+		}
+		moduleFragment.EntryNodes = plan.UniqueNodeIDs(coreEntryNodes)
+		moduleFragment.ExitNodes = plan.UniqueNodeIDs(customExitNodes)
+	}
 
 	if len(moduleFragment.Nodes) == 0 {
 		logger.Info("AddonsModule planned no executable nodes.")
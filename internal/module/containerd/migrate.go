@@ -0,0 +1,44 @@
+package containerd
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskContainerd "github.com/mensylisir/kubexm/internal/task/containerd"
+)
+
+// MigrateRuntimeModule moves the nodes of a cluster running on docker/cri-dockerd over to containerd,
+// one node at a time.
+type MigrateRuntimeModule struct {
+	module.BaseModule
+}
+
+// NewMigrateRuntimeModule creates a new MigrateRuntimeModule.
+func NewMigrateRuntimeModule() module.Module {
+	tasks := []task.Task{
+		taskContainerd.NewMigrateDockerToContainerdTask(),
+	}
+	return &MigrateRuntimeModule{
+		BaseModule: module.NewBaseModule("MigrateRuntime", tasks),
+	}
+}
+
+// Plan generates the execution fragment for the runtime migration.
+func (m *MigrateRuntimeModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	logger := ctx.GetLogger().With("module", m.Name())
+
+	moduleFragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan runtime migration module: %w", err)
+	}
+
+	if len(moduleFragment.Nodes) == 0 {
+		logger.Info("Runtime migration planned no executable nodes; spec.kubernetes.containerRuntime.type must be containerd.")
+	}
+	return moduleFragment, nil
+}
+
+var _ module.Module = (*MigrateRuntimeModule)(nil)
@@ -0,0 +1,47 @@
+package dns
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	"github.com/mensylisir/kubexm/internal/task/dns/coredns"
+	"github.com/mensylisir/kubexm/internal/task/dns/nodelocandns"
+)
+
+// DNSModule customizes the CoreDNS installed by kubeadm with spec.dns.coredns and deploys
+// NodeLocal DNSCache when spec.dns.nodelocaldns.enabled is set. The kubelets are already
+// configured to hand the address of NodeLocal DNSCache to the pods as their nameserver.
+type DNSModule struct {
+	module.BaseModule
+}
+
+// NewDNSModule creates a new DNSModule.
+func NewDNSModule() module.Module {
+	tasks := []task.Task{
+		coredns.NewConfigureCoreDNSTask(),
+		nodelocandns.NewDeployNodeLocalDNSTask(),
+	}
+	return &DNSModule{
+		BaseModule: module.NewBaseModule("ClusterDNS", tasks),
+	}
+}
+
+// Plan generates the execution fragment for the DNS module.
+func (m *DNSModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	logger := ctx.GetLogger().With("module", m.Name())
+
+	moduleFragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan DNS module: %w", err)
+	}
+
+	if len(moduleFragment.Nodes) == 0 {
+		logger.Info("DNS module planned no executable nodes.")
+	}
+	return moduleFragment, nil
+}
+
+var _ module.Module = (*DNSModule)(nil)
@@ -0,0 +1,70 @@
+package etcd
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskEtcd "github.com/mensylisir/kubexm/internal/task/etcd"
+)
+
+// EtcdBackupModule saves an etcd snapshot to the control machine.
+type EtcdBackupModule struct {
+	module.BaseModule
+}
+
+func NewEtcdBackupModule(retain int) module.Module {
+	return &EtcdBackupModule{
+		BaseModule: module.NewBaseModule("EtcdBackup", []task.Task{
+			taskEtcd.NewBackupEtcdSnapshotTask(retain),
+		}),
+	}
+}
+
+func (m *EtcdBackupModule) Name() string { return "EtcdBackup" }
+func (m *EtcdBackupModule) Description() string {
+	return "Save an etcd snapshot to the control machine"
+}
+
+func (m *EtcdBackupModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+// EtcdRestoreModule rebuilds the etcd cluster from a snapshot on the control machine.
+type EtcdRestoreModule struct {
+	module.BaseModule
+}
+
+func NewEtcdRestoreModule(snapshotPath string) module.Module {
+	return &EtcdRestoreModule{
+		BaseModule: module.NewBaseModule("EtcdRestore", []task.Task{
+			taskEtcd.NewRestoreEtcdClusterTask(snapshotPath),
+		}),
+	}
+}
+
+func (m *EtcdRestoreModule) Name() string        { return "EtcdRestore" }
+func (m *EtcdRestoreModule) Description() string { return "Rebuild the etcd cluster from a snapshot" }
+
+func (m *EtcdRestoreModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*EtcdBackupModule)(nil)
+var _ module.Module = (*EtcdRestoreModule)(nil)
@@ -0,0 +1,44 @@
+package images
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskimages "github.com/mensylisir/kubexm/internal/task/images"
+)
+
+// ImagePreloadModule fills the container runtime of every node with the images computed from the
+// configured Kubernetes, CNI and addon versions. It runs after the runtime is installed and before
+// kubeadm init/join.
+type ImagePreloadModule struct {
+	module.BaseModule
+}
+
+func NewImagePreloadModule() module.Module {
+	return &ImagePreloadModule{
+		BaseModule: module.NewBaseModule("ImagePreload", []task.Task{
+			taskimages.NewPreloadImagesTask(),
+		}),
+	}
+}
+
+func (m *ImagePreloadModule) Name() string { return "ImagePreload" }
+func (m *ImagePreloadModule) Description() string {
+	return "Pull or load the cluster images on every node"
+}
+
+func (m *ImagePreloadModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*ImagePreloadModule)(nil)
@@ -35,7 +35,7 @@ func (m *InfrastructureModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionF
 	// Phase 1: OS Preparation (grouped by function)
 	osTasks := []task.Task{
 		taskos.NewConfigureHostTask(),      // SetHostname + UpdateEtcHosts
-		taskos.NewDisableServicesTask(),    // DisableSwap, DisableFirewall, DisableSelinux
+		taskos.NewDisableServicesTask(),    // DisableSwap, DisableFirewall, ConfigureSELinux
 		taskos.NewConfigureKernelTask(),    // LoadKernelModules, ConfigureSysctl
 	}
 
@@ -87,7 +87,19 @@ func (m *InfrastructureModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionF
 		return nil, fmt.Errorf("failed to link etcd install fragment: %w", err)
 	}
 
-	etcdExitNodes := installEtcdFrag.ExitNodes
+	// For external etcd the install tasks plan nothing; the masters only need the client certificates.
+	externalEtcdCertsFrag, err := planTask(ctx, taskEtcd.NewDistributeExternalEtcdCertsTask())
+	if err != nil {
+		return nil, err
+	}
+	if err := moduleFragment.MergeFragment(externalEtcdCertsFrag); err != nil {
+		return nil, err
+	}
+	if err := plan.LinkFragments(moduleFragment, lastOsTaskExitNodes, externalEtcdCertsFrag.EntryNodes); err != nil {
+		return nil, fmt.Errorf("failed to link external etcd certificates fragment: %w", err)
+	}
+
+	etcdExitNodes := append(installEtcdFrag.ExitNodes, externalEtcdCertsFrag.ExitNodes...)
 
 	// Phase 3: Container Runtime Setup
 	// Also depends on OS setup being complete, can run in parallel with ETCD setup
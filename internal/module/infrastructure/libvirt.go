@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskLibvirt "github.com/mensylisir/kubexm/internal/task/libvirt"
+)
+
+// LibvirtProvisionModule creates the VMs of spec.infrastructure.libvirt on their hypervisors.
+type LibvirtProvisionModule struct {
+	module.BaseModule
+}
+
+func NewLibvirtProvisionModule() module.Module {
+	return &LibvirtProvisionModule{
+		BaseModule: module.NewBaseModule("LibvirtProvision", []task.Task{
+			taskLibvirt.NewProvisionVMsTask(),
+		}),
+	}
+}
+
+func (m *LibvirtProvisionModule) Name() string { return "LibvirtProvision" }
+func (m *LibvirtProvisionModule) Description() string {
+	return "Create the cluster VMs on the libvirt hypervisors"
+}
+
+func (m *LibvirtProvisionModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*LibvirtProvisionModule)(nil)
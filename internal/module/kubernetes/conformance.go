@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/conformance"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskkubernetes "github.com/mensylisir/kubexm/internal/task/kubernetes"
+)
+
+// ConformanceModule runs the Kubernetes conformance suite against a running cluster.
+type ConformanceModule struct {
+	module.BaseModule
+}
+
+func NewConformanceModule(opts conformance.Options) module.Module {
+	return &ConformanceModule{
+		BaseModule: module.NewBaseModule("Conformance", []task.Task{
+			taskkubernetes.NewConformanceTask(opts),
+		}),
+	}
+}
+
+func (m *ConformanceModule) Name() string { return "Conformance" }
+func (m *ConformanceModule) Description() string {
+	return "Run the conformance suite with Sonobuoy"
+}
+
+func (m *ConformanceModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*ConformanceModule)(nil)
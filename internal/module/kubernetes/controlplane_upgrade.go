@@ -40,11 +40,7 @@ func (m *ControlPlaneUpgradeModule) Plan(ctx runtime.ModuleContext) (*plan.Execu
 	var previousTaskExitNodes []plan.NodeID
 
 	if kubeType == string(common.KubernetesDeploymentTypeKubeadm) {
-		// For kubeadm, we need to ensure binaries are distributed BEFORE upgrading.
-		// Ideally, we would distribute the kubeadm binary here.
-		// For now, we rely on the UpgradeControlPlaneTask which assumes binaries are present.
-		// TODO: Add binary distribution steps for targetVersion.
-		
+		// UpgradeControlPlaneTask distributes the target kubeadm, kubelet and kubectl binaries itself.
 		upgradeTask := taskKubeadm.NewUpgradeControlPlaneTask(m.targetVersion)
 		frag, err := upgradeTask.Plan(taskCtx)
 		if err != nil {
@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskkubernetes "github.com/mensylisir/kubexm/internal/task/kubernetes"
+)
+
+// DriftModule collects the differences between the desired configuration and the live cluster.
+type DriftModule struct {
+	module.BaseModule
+}
+
+func NewDriftModule() module.Module {
+	return &DriftModule{
+		BaseModule: module.NewBaseModule("DetectDrift", []task.Task{
+			taskkubernetes.NewDetectDriftTask(),
+		}),
+	}
+}
+
+func (m *DriftModule) Name() string { return "DetectDrift" }
+func (m *DriftModule) Description() string {
+	return "Compare desired configuration with live cluster state"
+}
+
+func (m *DriftModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*DriftModule)(nil)
@@ -20,6 +20,7 @@ func NewKubeadmWorkerModule() module.Module {
 	tasks := []task.Task{
 		taskKube.NewInstallKubeComponentsTask(),
 		taskKube.NewJoinWorkersTask(),
+		taskKube.NewJoinWindowsWorkersTask(),
 	}
 	base := module.NewBaseModule("KubeadmWorker", tasks)
 	return &KubeadmWorkerModule{BaseModule: base}
@@ -82,6 +83,25 @@ func (m *KubeadmWorkerModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFr
 		moduleFragment.ExitNodes = joinDependencies
 	}
 
+	// 3. Join Windows Worker Nodes. They do not use the Linux binaries, so they join alongside.
+	joinWindowsWorkersTask := taskKube.NewJoinWindowsWorkersTask()
+	joinWindowsWorkersRequired, err := joinWindowsWorkersTask.IsRequired(taskCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check IsRequired for %s: %w", joinWindowsWorkersTask.Name(), err)
+	}
+	if joinWindowsWorkersRequired {
+		logger.Info("Planning task", "task_name", joinWindowsWorkersTask.Name())
+		joinWindowsWorkersFrag, err := joinWindowsWorkersTask.Plan(taskCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan %s: %w", joinWindowsWorkersTask.Name(), err)
+		}
+		if err := moduleFragment.MergeFragment(joinWindowsWorkersFrag); err != nil {
+			return nil, err
+		}
+		moduleFragment.EntryNodes = append(moduleFragment.EntryNodes, joinWindowsWorkersFrag.EntryNodes...)
+		moduleFragment.ExitNodes = append(moduleFragment.ExitNodes, joinWindowsWorkersFrag.ExitNodes...)
+	}
+
 	moduleFragment.EntryNodes = plan.UniqueNodeIDs(moduleFragment.EntryNodes)
 	moduleFragment.ExitNodes = plan.UniqueNodeIDs(moduleFragment.ExitNodes)
 
@@ -0,0 +1,63 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskKubeadm "github.com/mensylisir/kubexm/internal/task/kubernetes/kubeadm"
+)
+
+// NodeRemovalModule removes nodes from a running cluster one after another, so that at most one
+// etcd member or control plane node is leaving at any time.
+type NodeRemovalModule struct {
+	module.BaseModule
+}
+
+// NewNodeRemovalModule creates a NodeRemovalModule that removes targets through operator, a master
+// that stays in the cluster.
+func NewNodeRemovalModule(targets []remotefw.Host, operator remotefw.Host) module.Module {
+	tasks := make([]task.Task, 0, len(targets))
+	for _, target := range targets {
+		tasks = append(tasks, taskKubeadm.NewRemoveNodeTask(target, operator))
+	}
+	return &NodeRemovalModule{BaseModule: module.NewBaseModule("KubernetesNodeRemoval", tasks)}
+}
+
+func (m *NodeRemovalModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	logger := ctx.GetLogger().With("module", m.Name())
+
+	taskCtx, ok := ctx.(runtime.TaskContext)
+	if !ok {
+		return nil, fmt.Errorf("module context cannot be asserted to runtime.TaskContext for %s", m.Name())
+	}
+
+	moduleFragment := plan.NewExecutionFragment(m.Name() + "-Fragment")
+	var previousExits []plan.NodeID
+	for _, t := range m.Tasks() {
+		taskFrag, err := m.PlanSingleTask(taskCtx, t)
+		if err != nil {
+			return nil, err
+		}
+		if err := moduleFragment.MergeFragment(taskFrag); err != nil {
+			return nil, fmt.Errorf("failed to merge fragment from task %s: %w", t.Name(), err)
+		}
+		if err := plan.LinkFragments(moduleFragment, previousExits, taskFrag.EntryNodes); err != nil {
+			return nil, fmt.Errorf("failed to link fragment of %s: %w", t.Name(), err)
+		}
+		previousExits = taskFrag.ExitNodes
+	}
+
+	if moduleFragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+
+	moduleFragment.CalculateEntryAndExitNodes()
+	logger.Info("NodeRemoval module planning complete", "total_nodes", len(moduleFragment.Nodes))
+	return moduleFragment, nil
+}
+
+var _ module.Module = (*NodeRemovalModule)(nil)
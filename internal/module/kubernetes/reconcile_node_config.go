@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskkubernetes "github.com/mensylisir/kubexm/internal/task/kubernetes"
+)
+
+// ReconcileNodeConfigModule rewrites drifted containerd and kubelet configs on the nodes.
+type ReconcileNodeConfigModule struct {
+	module.BaseModule
+}
+
+func NewReconcileNodeConfigModule() module.Module {
+	return &ReconcileNodeConfigModule{
+		BaseModule: module.NewBaseModule("ReconcileNodeConfig", []task.Task{
+			taskkubernetes.NewReconcileNodeConfigTask(),
+		}),
+	}
+}
+
+func (m *ReconcileNodeConfigModule) Name() string { return "ReconcileNodeConfig" }
+func (m *ReconcileNodeConfigModule) Description() string {
+	return "Reconcile per-node containerd and kubelet config drift"
+}
+
+func (m *ReconcileNodeConfigModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*ReconcileNodeConfigModule)(nil)
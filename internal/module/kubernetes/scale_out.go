@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskKube "github.com/mensylisir/kubexm/internal/task/kubernetes/kubeadm"
+)
+
+// ScaleOutModule installs the Kubernetes components on new nodes and joins them to a running
+// cluster through an existing master. The module context is expected to expose only the new hosts.
+type ScaleOutModule struct {
+	module.BaseModule
+}
+
+// NewScaleOutModule creates a ScaleOutModule for the given new masters and workers.
+func NewScaleOutModule(existingMaster remotefw.Host, newMasters, newWorkers []remotefw.Host) module.Module {
+	tasks := []task.Task{
+		taskKube.NewInstallKubeComponentsTask(),
+		taskKube.NewJoinNewNodesTask(existingMaster, newMasters, newWorkers),
+	}
+	return &ScaleOutModule{BaseModule: module.NewBaseModule("KubernetesScaleOut", tasks)}
+}
+
+func (m *ScaleOutModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	logger := ctx.GetLogger().With("module", m.Name())
+
+	taskCtx, ok := ctx.(runtime.TaskContext)
+	if !ok {
+		return nil, fmt.Errorf("module context cannot be asserted to runtime.TaskContext for %s", m.Name())
+	}
+
+	clusterCfg := taskCtx.GetClusterConfig()
+	if clusterCfg.Spec.Kubernetes != nil && clusterCfg.Spec.Kubernetes.Type != "" &&
+		clusterCfg.Spec.Kubernetes.Type != string(common.KubernetesDeploymentTypeKubeadm) {
+		return nil, fmt.Errorf("adding nodes is only supported for kubeadm clusters, got type %q", clusterCfg.Spec.Kubernetes.Type)
+	}
+
+	moduleFragment := plan.NewExecutionFragment(m.Name() + "-Fragment")
+	var previousExits []plan.NodeID
+	for _, t := range m.Tasks() {
+		required, err := t.IsRequired(taskCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check IsRequired for %s: %w", t.Name(), err)
+		}
+		if !required {
+			continue
+		}
+		logger.Info("Planning task", "task_name", t.Name())
+		taskFrag, err := t.Plan(taskCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan %s: %w", t.Name(), err)
+		}
+		if taskFrag.IsEmpty() {
+			continue
+		}
+		if err := moduleFragment.MergeFragment(taskFrag); err != nil {
+			return nil, err
+		}
+		if err := plan.LinkFragments(moduleFragment, previousExits, taskFrag.EntryNodes); err != nil {
+			return nil, fmt.Errorf("failed to link fragment of %s: %w", t.Name(), err)
+		}
+		previousExits = taskFrag.ExitNodes
+	}
+
+	if len(moduleFragment.Nodes) == 0 {
+		logger.Info("ScaleOut module planned no executable nodes.")
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+
+	moduleFragment.CalculateEntryAndExitNodes()
+	logger.Info("ScaleOut module planning complete.", "total_nodes", len(moduleFragment.Nodes))
+	return moduleFragment, nil
+}
+
+var _ module.Module = (*ScaleOutModule)(nil)
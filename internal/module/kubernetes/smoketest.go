@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskkubernetes "github.com/mensylisir/kubexm/internal/task/kubernetes"
+)
+
+// SmokeTestModule verifies a freshly installed cluster by running a throwaway workload.
+type SmokeTestModule struct {
+	module.BaseModule
+}
+
+func NewSmokeTestModule() module.Module {
+	return &SmokeTestModule{
+		BaseModule: module.NewBaseModule("SmokeTest", []task.Task{
+			taskkubernetes.NewSmokeTestTask(),
+		}),
+	}
+}
+
+func (m *SmokeTestModule) Name() string { return "SmokeTest" }
+func (m *SmokeTestModule) Description() string {
+	return "Deploy and remove a smoke-test workload"
+}
+
+func (m *SmokeTestModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*SmokeTestModule)(nil)
@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskkubernetes "github.com/mensylisir/kubexm/internal/task/kubernetes"
+)
+
+// VerifyModule runs the post-install verification checks against a running cluster.
+type VerifyModule struct {
+	module.BaseModule
+}
+
+func NewVerifyModule() module.Module {
+	return &VerifyModule{
+		BaseModule: module.NewBaseModule("Verify", []task.Task{
+			taskkubernetes.NewVerifyClusterTask(),
+		}),
+	}
+}
+
+func (m *VerifyModule) Name() string { return "Verify" }
+func (m *VerifyModule) Description() string {
+	return "Verify nodes, DNS, networking, storage and the API server of a running cluster"
+}
+
+func (m *VerifyModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*VerifyModule)(nil)
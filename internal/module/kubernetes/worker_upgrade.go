@@ -13,12 +13,15 @@ import (
 type WorkerUpgradeModule struct {
 	module.BaseModule
 	targetVersion string
+	batchSize     int
 }
 
-func NewWorkerUpgradeModule(targetVersion string) module.Module {
+// NewWorkerUpgradeModule creates a WorkerUpgradeModule that upgrades batchSize workers at a time.
+func NewWorkerUpgradeModule(targetVersion string, batchSize int) module.Module {
 	return &WorkerUpgradeModule{
 		BaseModule:    module.NewBaseModule("WorkerUpgrade", nil),
 		targetVersion: targetVersion,
+		batchSize:     batchSize,
 	}
 }
 
@@ -37,7 +40,7 @@ func (m *WorkerUpgradeModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFr
 	}
 
 	if kubeType == string(common.KubernetesDeploymentTypeKubeadm) {
-		upgradeTask := taskKubeadm.NewUpgradeWorkersTask(m.targetVersion)
+		upgradeTask := taskKubeadm.NewUpgradeWorkersTask(m.targetVersion, m.batchSize)
 		moduleFragment, err := m.PlanSingleTask(taskCtx, upgradeTask)
 		if err != nil {
 			return nil, fmt.Errorf("failed to plan worker upgrade: %w", err)
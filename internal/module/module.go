@@ -14,6 +14,9 @@ type BaseModule struct {
 	Meta        spec.ModuleMeta
 	Timeout     time.Duration
 	IgnoreError bool
+	// Optional modules, such as cluster addons, only record a warning when they fail
+	// and let the rest of the pipeline continue.
+	Optional    bool
 	ModuleTasks []task.Task
 }
 
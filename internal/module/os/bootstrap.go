@@ -0,0 +1,42 @@
+package os
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskos "github.com/mensylisir/kubexm/internal/task/os"
+)
+
+// BootstrapModule moves the hosts from their initial login to the user of spec.bootstrap.
+type BootstrapModule struct {
+	module.BaseModule
+}
+
+// NewBootstrapModule creates a new BootstrapModule.
+func NewBootstrapModule() module.Module {
+	tasks := []task.Task{
+		taskos.NewBootstrapHostsTask(), // BootstrapUser + HardenSSH
+	}
+	return &BootstrapModule{
+		BaseModule: module.NewBaseModule("HostBootstrap", tasks),
+	}
+}
+
+// Tasks returns the list of tasks for this module.
+func (m *BootstrapModule) Tasks() []task.Task {
+	return m.ModuleTasks
+}
+
+// Plan generates the execution fragment for the bootstrap module.
+func (m *BootstrapModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	moduleFragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan host bootstrap module: %w", err)
+	}
+	return moduleFragment, nil
+}
+
+var _ module.Module = (*BootstrapModule)(nil)
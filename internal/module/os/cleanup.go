@@ -19,6 +19,7 @@ type OsCleanupModule struct {
 func NewOsCleanupModule() module.Module {
 	tasks := []task.Task{
 		taskos.NewCleanOSNodesTask(),
+		taskos.NewCleanClusterPKITask(),
 	}
 	return &OsCleanupModule{
 		BaseModule: module.NewBaseModule("OsCleanup", tasks),
@@ -19,7 +19,7 @@ type OsModule struct {
 func NewOsModule() module.Module {
 	tasks := []task.Task{
 		taskos.NewConfigureHostTask(),   // SetHostname + UpdateEtcHosts
-		taskos.NewDisableServicesTask(), // DisableSwap + DisableFirewall + DisableSelinux
+		taskos.NewDisableServicesTask(), // DisableSwap + DisableFirewall + ConfigureSELinux
 		taskos.NewConfigureKernelTask(), // LoadKernelModules + ConfigureSysctl
 	}
 	return &OsModule{
@@ -0,0 +1,42 @@
+package pki
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	pkiCommon "github.com/mensylisir/kubexm/internal/task/pki/common"
+)
+
+// CertExpirationModule collects the expiration of the certificates on the cluster nodes.
+type CertExpirationModule struct {
+	module.BaseModule
+}
+
+func NewCertExpirationModule() module.Module {
+	return &CertExpirationModule{
+		BaseModule: module.NewBaseModule("CertExpiration", []task.Task{
+			pkiCommon.NewCollectCertExpirationTask(),
+		}),
+	}
+}
+
+func (m *CertExpirationModule) Name() string { return "CertExpiration" }
+func (m *CertExpirationModule) Description() string {
+	return "Collect control plane and etcd certificate expiration"
+}
+
+func (m *CertExpirationModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	fragment, _, err := m.PlanTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan module %s: %w", m.Name(), err)
+	}
+	if fragment.IsEmpty() {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	return fragment, nil
+}
+
+var _ module.Module = (*CertExpirationModule)(nil)
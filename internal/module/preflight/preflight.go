@@ -33,6 +33,7 @@ func (m *PreflightModule) GetTasks() []task.Task {
 		taskpre.NewConfirmTask("InitialConfirmation", "Proceed with KubeXM operations?", m.assumeYes),
 		taskpreflight.NewExtractBundleTask(""),
 		taskos.NewInstallPrerequisitesTask(),
+		taskos.NewSyncTimeTask(), // Clocks are synced before CheckClockOffset gates on them
 		taskpreflight.NewPrepareAssetsTask(),
 		taskpreflight.NewInstallToolBinariesTask(),
 		taskpreflight.NewPreflightChecksTask(), // The new balanced task
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/task"
+	taskpreflight "github.com/mensylisir/kubexm/internal/task/preflight"
+	registrytask "github.com/mensylisir/kubexm/internal/task/registry"
+)
+
+// LocalRegistryModule deploys the cluster's own image registry for registry.type local and pushes
+// the saved images into it. It runs after the container runtimes are configured to trust the
+// registry and before the images are preloaded, so every node pulls from it.
+type LocalRegistryModule struct {
+	module.BaseModule
+}
+
+func NewLocalRegistryModule() module.Module {
+	return &LocalRegistryModule{
+		BaseModule: module.NewBaseModule("LocalRegistry", []task.Task{
+			registrytask.NewDeployLocalRegistryTask(),
+			&localRegistryPushTask{taskpreflight.NewPushImagesToRegistryTask()},
+		}),
+	}
+}
+
+func (m *LocalRegistryModule) Name() string { return "LocalRegistry" }
+func (m *LocalRegistryModule) Description() string {
+	return "Deploy the local image registry and push the cluster images into it"
+}
+
+func (m *LocalRegistryModule) Plan(ctx runtime.ModuleContext) (*plan.ExecutionFragment, error) {
+	moduleFragment := plan.NewExecutionFragment(m.Name() + "-Fragment")
+
+	taskCtx, ok := ctx.(runtime.TaskContext)
+	if !ok {
+		return nil, fmt.Errorf("context does not implement runtime.TaskContext")
+	}
+
+	// The push needs the registry to be serving, so the tasks run one after the other.
+	var previousTaskExitNodes []plan.NodeID
+	for _, t := range m.Tasks() {
+		isRequired, err := t.IsRequired(taskCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if task %s is required: %w", t.Name(), err)
+		}
+		if !isRequired {
+			continue
+		}
+
+		taskFrag, err := t.Plan(taskCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan task %s: %w", t.Name(), err)
+		}
+		if taskFrag.IsEmpty() {
+			continue
+		}
+		if err := moduleFragment.MergeFragment(taskFrag); err != nil {
+			return nil, fmt.Errorf("failed to merge fragment from task %s: %w", t.Name(), err)
+		}
+		if len(previousTaskExitNodes) > 0 {
+			if err := plan.LinkFragments(moduleFragment, previousTaskExitNodes, taskFrag.EntryNodes); err != nil {
+				return nil, fmt.Errorf("failed to link fragments for task %s: %w", t.Name(), err)
+			}
+		}
+		previousTaskExitNodes = taskFrag.ExitNodes
+	}
+
+	if len(previousTaskExitNodes) == 0 {
+		return plan.NewEmptyFragment(m.Name()), nil
+	}
+	moduleFragment.CalculateEntryAndExitNodes()
+	return moduleFragment, nil
+}
+
+// localRegistryPushTask limits the image push to clusters that deploy the local registry; pushing to
+// an external registry stays with the registry commands.
+type localRegistryPushTask struct{ task.Task }
+
+func (t *localRegistryPushTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return registrytask.NewDeployLocalRegistryTask().IsRequired(ctx)
+}
+
+var _ module.Module = (*LocalRegistryModule)(nil)
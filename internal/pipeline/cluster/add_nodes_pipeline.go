@@ -2,16 +2,21 @@ package cluster
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 
+	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/engine"
 	"github.com/mensylisir/kubexm/internal/module"
-	"github.com/mensylisir/kubexm/internal/module/etcd"
+	"github.com/mensylisir/kubexm/internal/module/images"
 	"github.com/mensylisir/kubexm/internal/module/kubernetes"
 	moduleOs "github.com/mensylisir/kubexm/internal/module/os"
 	"github.com/mensylisir/kubexm/internal/module/preflight"
 	moduleRuntime "github.com/mensylisir/kubexm/internal/module/runtime"
 	"github.com/mensylisir/kubexm/internal/pipeline"
 	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
 	runtime2 "github.com/mensylisir/kubexm/internal/runtime"
 )
 
@@ -24,19 +29,18 @@ type AddNodesPipeline struct {
 
 // NewAddNodesPipeline creates a new AddNodesPipeline.
 func NewAddNodesPipeline(assumeYes bool) pipeline.Pipeline {
-	// Add nodes pipeline:
+	// Add nodes pipeline, planned only for hosts that are not yet part of the live cluster:
 	// 1. Preflight (verify connectivity, pre-checks)
 	// 2. OsModule (OS configuration on new nodes)
-	// 3. EtcdModule (ETCD PKI if needed)
-	// 4. RuntimeModule (container runtime on new nodes)
-	// 5. WorkerModule (join nodes to cluster)
+	// 3. RuntimeModule (container runtime on new nodes)
+	// 4. ImagePreloadModule (cluster images on new nodes)
+	// 5. ScaleOutModule (kube components, kubeadm join, wait for Ready), added during Plan
 	modules := []module.Module{
 		preflight.NewPreflightConnectivityModule(), // SSH connectivity check before anything
 		preflight.NewPreflightModule(assumeYes),
 		moduleOs.NewOsModule(),
-		etcd.NewEtcdModule(),
 		moduleRuntime.NewRuntimeModule(),
-		kubernetes.NewWorkerModule(),
+		images.NewImagePreloadModule(),
 	}
 
 	return &AddNodesPipeline{
@@ -71,12 +75,27 @@ func (p *AddNodesPipeline) Plan(ctx runtime2.PipelineContext) (*plan.ExecutionGr
 		finalGraph := plan.NewExecutionGraph(p.Name())
 		var previousModuleExitNodes []plan.NodeID
 
-		moduleCtx, ok := ctx.(runtime2.ModuleContext)
+		rootCtx, ok := ctx.(*runtime2.Context)
 		if !ok {
-			return nil, fmt.Errorf("pipeline context cannot be asserted to module.ModuleContext for pipeline %s", p.Name())
+			return nil, fmt.Errorf("pipeline context cannot be asserted to *runtime2.Context for pipeline %s", p.Name())
 		}
 
-		for i, mod := range p.Modules() {
+		existingMaster, liveNodes, err := discoverLiveNodes(rootCtx)
+		if err != nil {
+			return nil, err
+		}
+		newMasters, newWorkers := diffNodes(rootCtx.GetHostsByRole(common.RoleMaster), rootCtx.GetHostsByRole(common.RoleWorker), liveNodes)
+		if len(newMasters)+len(newWorkers) == 0 {
+			logger.Info("All configured hosts are already part of the cluster, nothing to add.")
+			finalGraph.CalculateEntryAndExitNodes()
+			return finalGraph, nil
+		}
+		logger.Info("Found new nodes to add.", "masters", hostNames(newMasters), "workers", hostNames(newWorkers), "via", existingMaster.GetName())
+
+		moduleCtx := newNodesContext(rootCtx, append(newMasters, newWorkers...))
+		modules := append(p.Modules(), kubernetes.NewScaleOutModule(existingMaster, newMasters, newWorkers))
+
+		for i, mod := range modules {
 			logger.Info("Planning module for adding nodes", "module_name", mod.Name(), "module_index", i)
 			moduleFragment, err := pipeline.SafeModulePlan(moduleCtx, p.Name(), mod)
 			if err != nil {
@@ -148,4 +167,104 @@ func (p *AddNodesPipeline) Run(ctx runtime2.PipelineContext, graph *plan.Executi
 	return result, nil
 }
 
+// discoverLiveNodes lists the nodes of the running cluster through the first configured master that
+// can answer, and returns that master along with the node names.
+func discoverLiveNodes(ctx *runtime2.Context) (remotefw.Host, map[string]bool, error) {
+	masters := ctx.GetHostsByRole(common.RoleMaster)
+	sort.Slice(masters, func(i, j int) bool { return masters[i].GetName() < masters[j].GetName() })
+
+	opts := runner.KubectlGetOptions{
+		KubeconfigPath: filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+		Sudo:           true,
+	}
+	var lastErr error
+	for _, master := range masters {
+		conn, err := runtime2.ForHost(ctx, master).GetCurrentHostConnector()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		nodes, err := ctx.GetRunner().KubectlGetNodes(ctx.GoContext(), conn, opts)
+		if err != nil {
+			ctx.GetLogger().Debug("Could not list nodes from master.", "host", master.GetName(), "error", err)
+			lastErr = err
+			continue
+		}
+		live := make(map[string]bool, len(nodes))
+		for _, n := range nodes {
+			live[n.Metadata.Name] = true
+		}
+		return master, live, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no master hosts configured")
+	}
+	return nil, nil, fmt.Errorf("failed to list nodes of the running cluster from any master: %w", lastErr)
+}
+
+// diffNodes returns the configured masters and workers that are not live cluster nodes. A host with
+// both roles is only returned as a master.
+func diffNodes(masters, workers []remotefw.Host, live map[string]bool) (newMasters, newWorkers []remotefw.Host) {
+	isMaster := make(map[string]bool, len(masters))
+	for _, h := range masters {
+		isMaster[h.GetName()] = true
+		if !live[h.GetName()] {
+			newMasters = append(newMasters, h)
+		}
+	}
+	for _, h := range workers {
+		if !isMaster[h.GetName()] && !live[h.GetName()] {
+			newWorkers = append(newWorkers, h)
+		}
+	}
+	sort.Slice(newMasters, func(i, j int) bool { return newMasters[i].GetName() < newMasters[j].GetName() })
+	sort.Slice(newWorkers, func(i, j int) bool { return newWorkers[i].GetName() < newWorkers[j].GetName() })
+	return newMasters, newWorkers
+}
+
+func hostNames(hosts []remotefw.Host) []string {
+	names := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		names = append(names, h.GetName())
+	}
+	return names
+}
+
+// scopedHostsContext restricts GetHostsByRole and GetWindowsHostsByRole to a subset of the configured hosts, so the modules
+// shared with cluster creation only plan work for the new nodes. It is the only host getter that is
+// scoped: GetClusterConfig still lists every host, and the *runtime.Context returned by ForModule,
+// ForTask, WithGoContext and WithTimeout sees all hosts again. Modules and tasks planned with it
+// must therefore pick their hosts through GetHostsByRole on the context they are given, and only
+// use the forked contexts to build steps for hosts picked that way.
+type scopedHostsContext struct {
+	*runtime2.Context
+	names map[string]bool
+}
+
+func newNodesContext(ctx *runtime2.Context, hosts []remotefw.Host) *scopedHostsContext {
+	names := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		names[h.GetName()] = true
+	}
+	return &scopedHostsContext{Context: ctx, names: names}
+}
+
+func (c *scopedHostsContext) GetHostsByRole(role string) []remotefw.Host {
+	return c.scope(c.Context.GetHostsByRole(role))
+}
+
+func (c *scopedHostsContext) GetWindowsHostsByRole(role string) []remotefw.Host {
+	return c.scope(c.Context.GetWindowsHostsByRole(role))
+}
+
+func (c *scopedHostsContext) scope(all []remotefw.Host) []remotefw.Host {
+	var hosts []remotefw.Host
+	for _, h := range all {
+		if c.names[h.GetName()] {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
 var _ pipeline.Pipeline = (*AddNodesPipeline)(nil)
@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+func testHosts(names ...string) []remotefw.Host {
+	hosts := make([]remotefw.Host, 0, len(names))
+	for _, name := range names {
+		hosts = append(hosts, connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Address: "10.0.0.1"}))
+	}
+	return hosts
+}
+
+func TestDiffNodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		masters     []string
+		workers     []string
+		live        []string
+		wantMasters []string
+		wantWorkers []string
+	}{
+		{
+			name:        "nothing new",
+			masters:     []string{"master1"},
+			workers:     []string{"worker1"},
+			live:        []string{"master1", "worker1"},
+			wantMasters: []string{},
+			wantWorkers: []string{},
+		},
+		{
+			name:        "new workers are sorted",
+			masters:     []string{"master1"},
+			workers:     []string{"worker3", "worker1", "worker2"},
+			live:        []string{"master1", "worker1"},
+			wantMasters: []string{},
+			wantWorkers: []string{"worker2", "worker3"},
+		},
+		{
+			name:        "new master with worker role is joined as master",
+			masters:     []string{"master1", "master2"},
+			workers:     []string{"master2", "worker1"},
+			live:        []string{"master1", "worker1"},
+			wantMasters: []string{"master2"},
+			wantWorkers: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := make(map[string]bool)
+			for _, name := range tt.live {
+				live[name] = true
+			}
+			newMasters, newWorkers := diffNodes(testHosts(tt.masters...), testHosts(tt.workers...), live)
+			if got := hostNames(newMasters); !reflect.DeepEqual(got, tt.wantMasters) {
+				t.Errorf("new masters = %v, want %v", got, tt.wantMasters)
+			}
+			if got := hostNames(newWorkers); !reflect.DeepEqual(got, tt.wantWorkers) {
+				t.Errorf("new workers = %v, want %v", got, tt.wantWorkers)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/engine"
+	"github.com/mensylisir/kubexm/internal/module"
+	osmodule "github.com/mensylisir/kubexm/internal/module/os"
+	"github.com/mensylisir/kubexm/internal/module/preflight"
+	"github.com/mensylisir/kubexm/internal/pipeline"
+	"github.com/mensylisir/kubexm/internal/plan"
+	runtime2 "github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// BootstrapHostsPipeline logs in to the hosts with their initial credentials, creates the user of
+// spec.bootstrap on them and switches the runtime over to it, so that the modules after the
+// bootstrap module run as that user.
+type BootstrapHostsPipeline struct {
+	*pipeline.Base
+	PipelineModules []module.Module
+}
+
+// NewBootstrapHostsPipeline creates a new BootstrapHostsPipeline.
+func NewBootstrapHostsPipeline() pipeline.Pipeline {
+	return &BootstrapHostsPipeline{
+		Base: pipeline.NewBase("BootstrapHosts", "Create the kubexm login user on the hosts and harden sshd"),
+		PipelineModules: []module.Module{
+			preflight.NewPreflightConnectivityModule(),
+			osmodule.NewBootstrapModule(),
+		},
+	}
+}
+
+func (p *BootstrapHostsPipeline) Name() string             { return p.Base.Meta.Name }
+func (p *BootstrapHostsPipeline) Description() string      { return p.Base.Meta.Description }
+func (p *BootstrapHostsPipeline) Modules() []module.Module { return p.PipelineModules }
+
+func (p *BootstrapHostsPipeline) Plan(ctx runtime2.PipelineContext) (*plan.ExecutionGraph, error) {
+	return pipeline.SafePlan(ctx, p.Name(), func() (*plan.ExecutionGraph, error) {
+		logger := ctx.GetLogger().With("pipeline", p.Name())
+		logger.Info("Planning host bootstrap pipeline...")
+
+		finalGraph := plan.NewExecutionGraph(p.Name())
+		var previousModuleExitNodes []plan.NodeID
+
+		moduleCtx, ok := ctx.(runtime2.ModuleContext)
+		if !ok {
+			return nil, fmt.Errorf("pipeline context cannot be asserted to module.ModuleContext for pipeline %s", p.Name())
+		}
+
+		for _, mod := range p.Modules() {
+			logger.Info("Planning module", "module", mod.Name())
+			moduleFragment, err := pipeline.SafeModulePlan(moduleCtx, p.Name(), mod)
+			if err != nil {
+				return nil, fmt.Errorf("failed to plan module %s: %w", mod.Name(), err)
+			}
+			if moduleFragment.IsEmpty() {
+				logger.Debug("Module produced empty fragment", "module", mod.Name())
+				continue
+			}
+			if err := finalGraph.MergeFragment(moduleFragment); err != nil {
+				return nil, fmt.Errorf("failed to merge fragment from module %s: %w", mod.Name(), err)
+			}
+
+			if len(previousModuleExitNodes) > 0 {
+				if err := plan.LinkFragments(finalGraph, previousModuleExitNodes, moduleFragment.EntryNodes); err != nil {
+					return nil, fmt.Errorf("failed to link fragments in pipeline %s: %w", p.Name(), err)
+				}
+			}
+			previousModuleExitNodes = moduleFragment.ExitNodes
+		}
+
+		finalGraph.CalculateEntryAndExitNodes()
+		if err := finalGraph.Validate(); err != nil {
+			return nil, fmt.Errorf("final execution graph for pipeline %s is invalid: %w", p.Name(), err)
+		}
+		logger.Info("Host bootstrap pipeline planning complete.", "total_nodes", len(finalGraph.Nodes))
+		return finalGraph, nil
+	})
+}
+
+func (p *BootstrapHostsPipeline) Run(ctx runtime2.PipelineContext, graph *plan.ExecutionGraph, dryRun bool) (*plan.GraphExecutionResult, error) {
+	logger := ctx.GetLogger().With("pipeline", p.Name())
+	logger.Info("Running host bootstrap pipeline...", "dryRun", dryRun)
+
+	engineCtx, ok := ctx.(*runtime2.Context)
+	if !ok {
+		return nil, fmt.Errorf("pipeline context cannot be asserted to *runtime2.Context")
+	}
+
+	var currentGraph *plan.ExecutionGraph
+	var err error
+	if graph == nil {
+		currentGraph, err = p.Plan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("planning phase for pipeline %s failed: %w", p.Name(), err)
+		}
+	} else {
+		currentGraph = graph
+	}
+
+	if currentGraph == nil || currentGraph.IsEmpty() {
+		logger.Info("Host bootstrap pipeline has no executable nodes.")
+		return &plan.GraphExecutionResult{
+			GraphName: p.Name(),
+			Status:    plan.StatusSuccess,
+		}, nil
+	}
+
+	// No checkpoints: a resumed run would skip the bootstrap nodes that already completed, and
+	// with them the switch of those hosts to the new user. The steps check the hosts instead.
+	execEngine := engine.NewExecutor()
+	result, execErr := execEngine.Execute(engineCtx, currentGraph, dryRun)
+	if execErr != nil {
+		if result == nil {
+			result = &plan.GraphExecutionResult{GraphName: p.Name(), Status: plan.StatusFailed}
+		}
+		return result, fmt.Errorf("execution phase for pipeline %s failed: %w", p.Name(), execErr)
+	}
+
+	logger.Info("Host bootstrap pipeline completed.", "status", result.Status)
+	return result, nil
+}
+
+var _ pipeline.Pipeline = (*BootstrapHostsPipeline)(nil)
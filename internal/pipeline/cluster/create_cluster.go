@@ -7,11 +7,14 @@ import (
 	"github.com/mensylisir/kubexm/internal/engine"
 	"github.com/mensylisir/kubexm/internal/module"
 	"github.com/mensylisir/kubexm/internal/module/addon"
+	"github.com/mensylisir/kubexm/internal/module/dns"
+	"github.com/mensylisir/kubexm/internal/module/images"
 	"github.com/mensylisir/kubexm/internal/module/infrastructure"
 	"github.com/mensylisir/kubexm/internal/module/kubernetes"
 	"github.com/mensylisir/kubexm/internal/module/loadbalancer"
 	"github.com/mensylisir/kubexm/internal/module/network"
 	"github.com/mensylisir/kubexm/internal/module/preflight"
+	"github.com/mensylisir/kubexm/internal/module/registry"
 	"github.com/mensylisir/kubexm/internal/pipeline"
 	"github.com/mensylisir/kubexm/internal/plan"
 )
@@ -32,9 +35,13 @@ func NewCreateClusterPipeline(assumeYes bool) *CreateClusterPipeline {
 		preflight.NewPreflightModule(assumeYes),    // System checks, initial OS setup, kernel setup
 		infrastructure.NewInfrastructureModule(),   // ETCD (PKI + install), Container Runtime
 		loadbalancer.NewLoadBalancerModule(),       // Load balancer setup (external/internal/kube-vip)
+		registry.NewLocalRegistryModule(),          // Local image registry for registry.type local
+		images.NewImagePreloadModule(),             // Pull or load cluster images on every node
 		kubernetes.NewControlPlaneModule(),         // Kube binaries, image pulls, kubeadm init
 		network.NewNetworkModule(),                 // CNI plugin
+		dns.NewDNSModule(),                         // NodeLocal DNSCache
 		kubernetes.NewWorkerModule(),               // Join worker nodes
+		kubernetes.NewReconcileNodeConfigModule(),  // Rewrite drifted containerd/kubelet configs
 		addon.NewAddonsModule(),                   // Cluster addons
 	}
 
@@ -45,6 +52,14 @@ func NewCreateClusterPipeline(assumeYes bool) *CreateClusterPipeline {
 	}
 }
 
+// WithSmokeTest appends a post-install smoke test that deploys and removes a throwaway workload.
+func (p *CreateClusterPipeline) WithSmokeTest(enabled bool) *CreateClusterPipeline {
+	if enabled {
+		p.modules = append(p.modules, kubernetes.NewSmokeTestModule())
+	}
+	return p
+}
+
 // Name returns the designated name of the pipeline.
 func (p *CreateClusterPipeline) Name() string {
 	return p.Base.Meta.Name
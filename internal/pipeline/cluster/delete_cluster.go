@@ -37,7 +37,7 @@ type DeleteClusterPipeline struct {
 // 7. EtcdCleanup - remove etcd (if managed)
 // 8. RuntimeCleanup - remove container runtime
 // 9. StorageCleanup - remove storage classes
-// 10. OsCleanup - restore OS-level changes
+// 10. OsCleanup - restore OS-level changes and remove cluster PKI from the nodes and the work dir
 func NewDeleteClusterPipeline(assumeYes bool) pipeline.Pipeline {
 	modules := []module.Module{
 		preflight.NewPreflightConnectivityModule(), // SSH connectivity check
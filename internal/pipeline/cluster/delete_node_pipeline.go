@@ -0,0 +1,153 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/engine"
+	"github.com/mensylisir/kubexm/internal/module"
+	"github.com/mensylisir/kubexm/internal/module/kubernetes"
+	"github.com/mensylisir/kubexm/internal/pipeline"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	runtime2 "github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// DeleteNodePipeline removes the named nodes from a running cluster: each one is drained, removed
+// from etcd if it is a control plane member, reset with kubeadm and deleted from the API server.
+type DeleteNodePipeline struct {
+	*pipeline.Base
+	NodeNames []string
+}
+
+// NewDeleteNodePipeline creates a new DeleteNodePipeline for the given host names.
+func NewDeleteNodePipeline(nodeNames []string) pipeline.Pipeline {
+	return &DeleteNodePipeline{
+		Base:      pipeline.NewBase("DeleteNode", "Drains and removes nodes from an existing Kubernetes cluster"),
+		NodeNames: nodeNames,
+	}
+}
+
+func (p *DeleteNodePipeline) Name() string {
+	return p.Base.Meta.Name
+}
+
+func (p *DeleteNodePipeline) Description() string {
+	return p.Base.Meta.Description
+}
+
+// Modules returns no static modules; the removal module depends on the hosts resolved during Plan.
+func (p *DeleteNodePipeline) Modules() []module.Module {
+	return []module.Module{}
+}
+
+func (p *DeleteNodePipeline) Plan(ctx runtime2.PipelineContext) (*plan.ExecutionGraph, error) {
+	return pipeline.SafePlan(ctx, p.Name(), func() (*plan.ExecutionGraph, error) {
+		logger := ctx.GetLogger().With("pipeline", p.Name())
+		logger.Info("Planning pipeline for deleting nodes...", "nodes", p.NodeNames)
+
+		moduleCtx, ok := ctx.(runtime2.ModuleContext)
+		if !ok {
+			return nil, fmt.Errorf("pipeline context cannot be asserted to module.ModuleContext for pipeline %s", p.Name())
+		}
+
+		targets, operator, err := resolveNodeRemoval(ctx.GetHostsByRole(""), p.NodeNames)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Nodes will be removed through master.", "master", operator.GetName())
+
+		finalGraph := plan.NewExecutionGraph(p.Name())
+		mod := kubernetes.NewNodeRemovalModule(targets, operator)
+		moduleFragment, err := pipeline.SafeModulePlan(moduleCtx, p.Name(), mod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan module %s in pipeline %s: %w", mod.Name(), p.Name(), err)
+		}
+		if err := finalGraph.MergeFragment(moduleFragment); err != nil {
+			return nil, fmt.Errorf("failed to merge fragment from module %s: %w", mod.Name(), err)
+		}
+
+		finalGraph.CalculateEntryAndExitNodes()
+		if err := finalGraph.Validate(); err != nil {
+			return nil, fmt.Errorf("final execution graph for pipeline %s is invalid: %w", p.Name(), err)
+		}
+		logger.Info("Delete node pipeline planning complete.", "total_nodes", len(finalGraph.Nodes))
+		return finalGraph, nil
+	})
+}
+
+func (p *DeleteNodePipeline) Run(ctx runtime2.PipelineContext, graph *plan.ExecutionGraph, dryRun bool) (*plan.GraphExecutionResult, error) {
+	logger := ctx.GetLogger().With("pipeline", p.Name())
+	logger.Info("Running delete node pipeline...", "dryRun", dryRun)
+
+	engineCtx, ok := ctx.(*runtime2.Context)
+	if !ok {
+		err := fmt.Errorf("pipeline context cannot be asserted to *runtime2.Context for pipeline %s", p.Name())
+		logger.With("error", err).Error("Context type assertion failed")
+		return nil, err
+	}
+
+	currentGraph := graph
+	if currentGraph == nil {
+		var err error
+		currentGraph, err = p.Plan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("planning phase for pipeline %s failed: %w", p.Name(), err)
+		}
+	}
+	if currentGraph.IsEmpty() {
+		logger.Info("Pipeline planned no executable nodes. Nothing to run.")
+		return &plan.GraphExecutionResult{GraphName: p.Name(), Status: plan.StatusSuccess}, nil
+	}
+
+	execEngine := engine.NewCheckpointExecutorForPipeline(engineCtx, p.Name())
+	result, execErr := execEngine.Execute(engineCtx, currentGraph, dryRun)
+	if execErr != nil {
+		if result == nil {
+			result = &plan.GraphExecutionResult{GraphName: p.Name(), Status: plan.StatusFailed}
+		}
+		return result, fmt.Errorf("execution phase for pipeline %s failed: %w", p.Name(), execErr)
+	}
+	logger.Info("Delete node pipeline run completed.", "status", result.Status)
+	return result, nil
+}
+
+// resolveNodeRemoval looks up the hosts to remove by name and picks the first master, by name, that
+// is not being removed to run the cluster-side steps.
+func resolveNodeRemoval(hosts []remotefw.Host, nodeNames []string) (targets []remotefw.Host, operator remotefw.Host, err error) {
+	if len(nodeNames) == 0 {
+		return nil, nil, fmt.Errorf("no nodes to delete were given")
+	}
+	byName := make(map[string]remotefw.Host, len(hosts))
+	for _, h := range hosts {
+		byName[h.GetName()] = h
+	}
+	removing := make(map[string]bool, len(nodeNames))
+	for _, name := range nodeNames {
+		h, ok := byName[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("node %q is not defined in the cluster configuration", name)
+		}
+		if removing[name] {
+			continue
+		}
+		removing[name] = true
+		targets = append(targets, h)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].GetName() < hosts[j].GetName() })
+	for _, h := range hosts {
+		if removing[h.GetName()] {
+			continue
+		}
+		for _, role := range h.GetRoles() {
+			if role == common.RoleMaster {
+				return targets, h, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("at least one master must remain in the cluster to delete nodes %v", nodeNames)
+}
+
+var _ pipeline.Pipeline = (*DeleteNodePipeline)(nil)
@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+func TestResolveNodeRemoval(t *testing.T) {
+	newHosts := func() []remotefw.Host {
+		return []remotefw.Host{
+			connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "worker1", Address: "10.0.0.3", Roles: []string{common.RoleWorker}}),
+			connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master2", Address: "10.0.0.2", Roles: []string{common.RoleMaster}}),
+			connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster}}),
+		}
+	}
+	tests := []struct {
+		name         string
+		nodes        []string
+		wantTargets  []string
+		wantOperator string
+		expectedErr  string
+	}{
+		{name: "worker", nodes: []string{"worker1"}, wantTargets: []string{"worker1"}, wantOperator: "master1"},
+		{name: "first master", nodes: []string{"master1", "master1"}, wantTargets: []string{"master1"}, wantOperator: "master2"},
+		{name: "unknown node", nodes: []string{"worker9"}, expectedErr: "not defined"},
+		{name: "all masters", nodes: []string{"master1", "master2"}, expectedErr: "at least one master"},
+		{name: "no nodes", expectedErr: "no nodes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, operator, err := resolveNodeRemoval(newHosts(), tt.nodes)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Join(hostNames(targets), ","); got != strings.Join(tt.wantTargets, ",") {
+				t.Errorf("targets = %s, want %v", got, tt.wantTargets)
+			}
+			if operator.GetName() != tt.wantOperator {
+				t.Errorf("operator = %s, want %s", operator.GetName(), tt.wantOperator)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package cluster
 import (
 	"fmt"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/engine"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"github.com/mensylisir/kubexm/internal/module"
 	"github.com/mensylisir/kubexm/internal/module/kubernetes"
 	"github.com/mensylisir/kubexm/internal/module/preflight"
@@ -17,6 +19,7 @@ type UpgradeClusterPipeline struct {
 	*pipeline.Base
 	PipelineModules []module.Module
 	TargetVersion   string
+	WorkerBatchSize int
 	AssumeYes       bool
 }
 
@@ -24,26 +27,27 @@ type UpgradeClusterPipeline struct {
 // Modules are ordered to follow safe upgrade procedures:
 // 1. Preflight (connectivity + user confirmation) - CRITICAL: requires user confirmation unless --yes is set
 // 2. ControlPlaneUpgrade - upgrade control plane nodes first (one by one, maxUnavailable=1)
-// 3. WorkerUpgrade - upgrade worker nodes (cordon, drain, upgrade, uncordon)
+// 3. WorkerUpgrade - upgrade worker nodes workerBatchSize at a time (cordon, drain, upgrade, uncordon)
 // 4. NetworkUpgrade - upgrade CNI plugin (helm upgrade --install)
-func NewUpgradeClusterPipeline(targetVersion string, assumeYes bool) pipeline.Pipeline {
+func NewUpgradeClusterPipeline(targetVersion string, workerBatchSize int, assumeYes bool) pipeline.Pipeline {
 	// CRITICAL: targetVersion must be explicitly provided - no silent defaults for upgrades
 	if targetVersion == "" {
 		targetVersion = "unknown" // Will fail validation later, never silently default to "latest"
 	}
 
 	modules := []module.Module{
-		preflight.NewPreflightConnectivityModule(), // SSH connectivity check
-		preflight.NewPreflightModule(assumeYes),                 // Connectivity + user confirmation (CRITICAL)
-		kubernetes.NewControlPlaneUpgradeModule(targetVersion),  // Upgrade control plane first
-		kubernetes.NewWorkerUpgradeModule(targetVersion),        // Upgrade worker nodes
-		kubernetes.NewNetworkUpgradeModule(),                    // Upgrade CNI plugin
+		preflight.NewPreflightConnectivityModule(),                        // SSH connectivity check
+		preflight.NewPreflightModule(assumeYes),                           // Connectivity + user confirmation (CRITICAL)
+		kubernetes.NewControlPlaneUpgradeModule(targetVersion),            // Upgrade control plane first
+		kubernetes.NewWorkerUpgradeModule(targetVersion, workerBatchSize), // Upgrade worker nodes
+		kubernetes.NewNetworkUpgradeModule(),                              // Upgrade CNI plugin
 	}
 
 	return &UpgradeClusterPipeline{
 		Base:            pipeline.NewBase("UpgradeCluster", "Upgrades an existing Kubernetes cluster to a target version"),
 		PipelineModules: modules,
 		TargetVersion:   targetVersion,
+		WorkerBatchSize: workerBatchSize,
 		AssumeYes:       assumeYes,
 	}
 }
@@ -74,6 +78,10 @@ func (p *UpgradeClusterPipeline) Plan(ctx runtime.PipelineContext) (*plan.Execut
 			return nil, fmt.Errorf("target version is required for cluster upgrade (use --to-version flag)")
 		}
 
+		if err := p.prepareTargetVersion(ctx); err != nil {
+			return nil, err
+		}
+
 		logger.Info("Planning cluster upgrade pipeline...")
 
 		finalGraph := plan.NewExecutionGraph(p.Name())
@@ -179,4 +187,21 @@ func (p *UpgradeClusterPipeline) Run(ctx runtime.PipelineContext, graph *plan.Ex
 	return result, nil
 }
 
+// prepareTargetVersion checks the version skew between the configured and the target version and
+// then points the cluster configuration at the target, so that asset preparation and the binary
+// install steps fetch the new kubeadm, kubelet and kubectl.
+func (p *UpgradeClusterPipeline) prepareTargetVersion(ctx runtime.PipelineContext) error {
+	cfg := ctx.GetClusterConfig()
+	if cfg == nil || cfg.Spec.Kubernetes == nil {
+		return fmt.Errorf("cluster configuration has no kubernetes section to upgrade")
+	}
+	verrs := &validation.ValidationErrors{}
+	v1alpha1.Validate_KubernetesUpgrade(cfg.Spec.Kubernetes, p.TargetVersion, verrs, "spec.kubernetes")
+	if verrs.HasErrors() {
+		return fmt.Errorf("upgrade to %s rejected: %w", p.TargetVersion, verrs)
+	}
+	cfg.Spec.Kubernetes.Version = p.TargetVersion
+	return nil
+}
+
 var _ pipeline.Pipeline = (*UpgradeClusterPipeline)(nil)
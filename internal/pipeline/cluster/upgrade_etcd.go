@@ -15,6 +15,7 @@ import (
 // UpgradeEtcdPipeline defines the pipeline for upgrading etcd in an existing Kubernetes cluster.
 type UpgradeEtcdPipeline struct {
 	*pipeline.Base
+	PipelineModules []module.Module
 	TargetVersion   string
 }
 
@@ -32,6 +32,7 @@ func SafePlan(
 }
 
 // SafeModulePlan wraps a module Plan call with panic recovery.
+// The nodes planned by an optional module are marked optional.
 func SafeModulePlan(
 	moduleCtx runtime.ModuleContext,
 	pipelineName string,
@@ -50,5 +51,8 @@ func SafeModulePlan(
 	}()
 
 	result, err = mod.Plan(moduleCtx)
+	if err == nil && result != nil && mod.GetBase().Optional {
+		result.MarkOptional()
+	}
 	return result, err
 }
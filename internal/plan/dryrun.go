@@ -0,0 +1,241 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FileState compares a file rendered by a dry run with the file on the host.
+type FileState string
+
+const (
+	// FileNew means the file does not exist on the host yet.
+	FileNew FileState = "New"
+	// FileChanged means the host has the file with a different content.
+	FileChanged FileState = "Changed"
+	// FileUnchanged means the host already has the rendered content.
+	FileUnchanged FileState = "Unchanged"
+	// FileUnknown means the file on the host could not be read, so no diff is available.
+	FileUnknown FileState = "Unknown"
+)
+
+// RenderedFile is a file a node would write on a host, rendered locally by a dry run.
+type RenderedFile struct {
+	NodeName  string    `json:"nodeName" yaml:"nodeName"`
+	Host      string    `json:"host" yaml:"host"`
+	Path      string    `json:"path" yaml:"path"`
+	LocalPath string    `json:"localPath" yaml:"localPath"`
+	State     FileState `json:"state" yaml:"state"`
+	Diff      string    `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Message   string    `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// DryRunOutput lists the files rendered by a dry run under Dir, one directory per host.
+type DryRunOutput struct {
+	Dir   string         `json:"dir" yaml:"dir"`
+	Files []RenderedFile `json:"files,omitempty" yaml:"files,omitempty"`
+	// Errors lists the nodes whose files could not be rendered.
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Sort orders the files by host and path.
+func (d *DryRunOutput) Sort() {
+	sort.SliceStable(d.Files, func(i, j int) bool {
+		if d.Files[i].Host != d.Files[j].Host {
+			return d.Files[i].Host < d.Files[j].Host
+		}
+		return d.Files[i].Path < d.Files[j].Path
+	})
+	sort.Strings(d.Errors)
+}
+
+// Count returns how many files are in state.
+func (d *DryRunOutput) Count(state FileState) int {
+	n := 0
+	for _, f := range d.Files {
+		if f.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary returns a one-line count of rendered files per state.
+func (d *DryRunOutput) Summary() string {
+	return fmt.Sprintf("%d files rendered to %s: %d new, %d changed, %d unchanged, %d unknown",
+		len(d.Files), d.Dir, d.Count(FileNew), d.Count(FileChanged), d.Count(FileUnchanged), d.Count(FileUnknown))
+}
+
+// WriteDiff writes the summary, the diff of every new or changed file and the render errors to w.
+func (d *DryRunOutput) WriteDiff(w io.Writer) {
+	fmt.Fprintln(w, d.Summary())
+	for _, f := range d.Files {
+		switch f.State {
+		case FileNew, FileChanged:
+			fmt.Fprint(w, f.Diff)
+		case FileUnknown:
+			fmt.Fprintf(w, "?? %s:%s: %s\n", f.Host, f.Path, f.Message)
+		}
+	}
+	for _, e := range d.Errors {
+		fmt.Fprintf(w, "!! %s\n", e)
+	}
+}
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+// maxDiffCells bounds the size of the table used to diff the changed middle of two files. Larger
+// changes are shown as the whole middle removed and added again.
+const maxDiffCells = 4 << 20
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff returns the unified diff turning from into to, or "" if they are equal.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	// aLine[k] and bLine[k] are the number of lines of from and to before ops[k].
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.kind != '+' {
+			aLine[k+1]++
+		}
+		if op.kind != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next < len(ops) && next-end <= 2*diffContext {
+				end = next
+				continue
+			}
+			end = min(end+diffContext, len(ops))
+			break
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aLine[start], aLine[end]), hunkRange(bLine[start], bLine[end]))
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func hunkRange(from, to int) string {
+	n := to - from
+	if n == 0 {
+		return fmt.Sprintf("%d,0", from)
+	}
+	return fmt.Sprintf("%d,%d", from+1, n)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines trims the common prefix and suffix of a and b and diffs the rest by longest common
+// subsequence.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	ops = append(ops, lcsDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+func lcsDiff(a, b []string) []diffOp {
+	var ops []diffOp
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, diffOp{'+', l})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
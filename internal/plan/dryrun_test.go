@@ -0,0 +1,70 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\n"
+
+	want := strings.Join([]string{
+		"--- old",
+		"+++ new",
+		"@@ -1,5 +1,5 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		" d",
+		" e",
+		"@@ -9,4 +9,5 @@",
+		" i",
+		" j",
+		" k",
+		"-l",
+		"+L",
+		"+m",
+		"",
+	}, "\n")
+	if got := UnifiedDiff("old", "new", from, to); got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+	if got := UnifiedDiff("old", "new", from, from); got != "" {
+		t.Errorf("expected no diff for equal content, got:\n%s", got)
+	}
+}
+
+func TestDryRunOutput_WriteDiff(t *testing.T) {
+	out := &DryRunOutput{
+		Dir: ".kubexm/dryrun/demo",
+		Files: []RenderedFile{
+			{Host: "node2", Path: "/etc/containerd/config.toml", State: FileUnchanged},
+			{Host: "node1", Path: "/etc/haproxy/haproxy.cfg", State: FileNew, Diff: "--- /dev/null\n+++ haproxy.cfg\n@@ -0,0 +1,1 @@\n+global\n"},
+			{Host: "node1", Path: "/etc/kubernetes/kubeadm-init-config.yaml", State: FileUnknown, Message: "no connection to host"},
+		},
+		Errors: []string{"GenerateKubeVipManifest on node1: kube-vip is not enabled"},
+	}
+	out.Sort()
+	if out.Files[0].Path != "/etc/haproxy/haproxy.cfg" || out.Files[2].Host != "node2" {
+		t.Errorf("expected files sorted by host and path, got %+v", out.Files)
+	}
+
+	var b strings.Builder
+	out.WriteDiff(&b)
+	got := b.String()
+	for _, want := range []string{
+		"3 files rendered to .kubexm/dryrun/demo: 1 new, 0 changed, 1 unchanged, 1 unknown\n",
+		"+global\n",
+		"?? node1:/etc/kubernetes/kubeadm-init-config.yaml: no connection to host\n",
+		"!! GenerateKubeVipManifest on node1: kube-vip is not enabled\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "config.toml") {
+		t.Errorf("expected unchanged files to be left out, got:\n%s", got)
+	}
+}
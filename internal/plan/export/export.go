@@ -0,0 +1,101 @@
+// Package export renders execution graphs as diagrams, for reviewing a plan before it runs or for
+// documenting what a pipeline does.
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+)
+
+// ToDOT renders g in Graphviz DOT format. Nodes are labeled with their step and target hosts,
+// optional nodes are dashed, and edges point from a dependency to the node that waits for it.
+func ToDOT(g *plan.ExecutionGraph) (string, error) {
+	planned, err := plan.NewPlannedGraph(g)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(planned.Name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range planned.Nodes {
+		attrs := "label=" + dotQuote(strings.Join(labelLines(n), "\n"))
+		if n.Optional {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(string(n.ID)), attrs)
+	}
+	for _, n := range planned.Nodes {
+		for _, dep := range n.Dependencies {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(string(dep)), dotQuote(string(n.ID)))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// ToMermaid renders g as a Mermaid flowchart. Node IDs are replaced by n1, n2, ... in execution
+// order because Mermaid reserves some words and characters; the original ID is the first line of
+// each label.
+func ToMermaid(g *plan.ExecutionGraph) (string, error) {
+	planned, err := plan.NewPlannedGraph(g)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make(map[plan.NodeID]string, len(planned.Nodes))
+	for i, n := range planned.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i+1)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	var optional []string
+	for _, n := range planned.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.ID], mermaidEscape(strings.Join(labelLines(n), "\n")))
+		if n.Optional {
+			optional = append(optional, ids[n.ID])
+		}
+	}
+	for _, n := range planned.Nodes {
+		for _, dep := range n.Dependencies {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[dep], ids[n.ID])
+		}
+	}
+	if len(optional) > 0 {
+		b.WriteString("  classDef optional stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&b, "  class %s optional\n", strings.Join(optional, ","))
+	}
+	return b.String(), nil
+}
+
+// labelLines returns the node ID, its step if named differently, and its hosts.
+func labelLines(n plan.PlannedNode) []string {
+	lines := []string{string(n.ID)}
+	if n.Step != "" && n.Step != string(n.ID) {
+		lines = append(lines, n.Step)
+	}
+	if len(n.Hosts) > 0 {
+		lines = append(lines, strings.Join(n.Hosts, ", "))
+	}
+	return lines
+}
+
+// dotQuote returns s as a DOT quoted string; newlines become centered line breaks.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// mermaidEscape makes s safe inside a quoted Mermaid label; newlines become line breaks.
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "<", "#lt;")
+	s = strings.ReplaceAll(s, ">", "#gt;")
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
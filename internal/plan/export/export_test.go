@@ -0,0 +1,78 @@
+package export
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func newExportTestGraph() *plan.ExecutionGraph {
+	g := plan.NewExecutionGraph("CreateCluster")
+	g.Nodes["InstallContainerd"] = &plan.ExecutionNode{Name: "InstallContainerd", StepName: "InstallContainerd", Hostnames: []string{"master1", "worker1"}}
+	g.Nodes["InitMaster"] = &plan.ExecutionNode{Name: "InitMaster", StepName: "KubeadmInit", Hostnames: []string{"master1"}, Dependencies: []plan.NodeID{"InstallContainerd"}}
+	g.Nodes["JoinWorker"] = &plan.ExecutionNode{Name: "JoinWorker", StepName: "KubeadmJoin", Hostnames: []string{"worker1"}, Dependencies: []plan.NodeID{"InitMaster", "InstallContainerd"}}
+	g.Nodes["Metrics \"Server\""] = &plan.ExecutionNode{Name: "Metrics \"Server\"", StepName: "HelmInstall<metrics>", Hostnames: []string{"master1"}, Dependencies: []plan.NodeID{"InitMaster"}, Optional: true}
+	g.CalculateEntryAndExitNodes()
+	return g
+}
+
+// assertGolden compares got with testdata/name, or rewrites the file when -update is set.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s (run with -update to accept):\n%s", path, got)
+	}
+}
+
+func TestToDOT(t *testing.T) {
+	got, err := ToDOT(newExportTestGraph())
+	if err != nil {
+		t.Fatalf("ToDOT failed: %v", err)
+	}
+	assertGolden(t, "create_cluster.dot.golden", got)
+}
+
+func TestToMermaid(t *testing.T) {
+	got, err := ToMermaid(newExportTestGraph())
+	if err != nil {
+		t.Fatalf("ToMermaid failed: %v", err)
+	}
+	assertGolden(t, "create_cluster.mmd.golden", got)
+}
+
+func TestExport_EmptyGraph(t *testing.T) {
+	g := plan.NewExecutionGraph("Empty")
+	if got, err := ToDOT(g); err != nil || got != "digraph \"Empty\" {\n  rankdir=LR;\n  node [shape=box];\n}\n" {
+		t.Errorf("unexpected DOT for an empty graph: %q, %v", got, err)
+	}
+	if got, err := ToMermaid(g); err != nil || got != "flowchart LR\n" {
+		t.Errorf("unexpected Mermaid for an empty graph: %q, %v", got, err)
+	}
+}
+
+func TestExport_CyclicGraph(t *testing.T) {
+	g := plan.NewExecutionGraph("Cycle")
+	g.Nodes["a"] = &plan.ExecutionNode{Name: "a", Dependencies: []plan.NodeID{"b"}}
+	g.Nodes["b"] = &plan.ExecutionNode{Name: "b", Dependencies: []plan.NodeID{"a"}}
+	for name, render := range map[string]func(*plan.ExecutionGraph) (string, error){"dot": ToDOT, "mermaid": ToMermaid} {
+		if _, err := render(g); err == nil || !strings.Contains(err.Error(), "cyclic") {
+			t.Errorf("%s: expected a cycle error, got %v", name, err)
+		}
+	}
+}
@@ -139,6 +139,13 @@ func (ef *ExecutionFragment) CalculateEntryAndExitNodes() {
 	ef.ExitNodes = UniqueNodeIDs(ef.ExitNodes)
 }
 
+// MarkOptional marks every node of the fragment as optional.
+func (ef *ExecutionFragment) MarkOptional() {
+	for _, node := range ef.Nodes {
+		node.Optional = true
+	}
+}
+
 func (ef *ExecutionFragment) GetNode(id NodeID) *ExecutionNode {
 	return ef.Nodes[id]
 }
@@ -3,6 +3,7 @@ package plan
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
@@ -36,11 +37,32 @@ type ExecutionNode struct {
 	ModuleName   string `json:"moduleName,omitempty"`
 	TaskName     string `json:"taskName,omitempty"`
 
+	// Optional marks nodes of an optional module. Their failure is recorded as a warning
+	// instead of failing the graph, and does not block nodes outside the optional module.
+	Optional bool `json:"optional,omitempty"`
+
+	// AlwaysRun nodes run once all of their dependencies have finished, even if some of them
+	// failed or were skipped, e.g. to remove what those nodes created. Their own dependents are
+	// still skipped in that case.
+	AlwaysRun bool `json:"alwaysRun,omitempty"`
+
 	// Condition is a function that determines if this node should be executed.
 	// It is not serialized to JSON.
 	Condition func(ctx runtime.ExecutionContext) (bool, error) `json:"-"`
 
-	// TODO: Add fields for retry strategy, timeout overrides for this specific node, etc.
+	// Retry overrides the executor's default retry policy for this node. Nil uses the default.
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// TODO: Add fields for timeout overrides for this specific node, etc.
+}
+
+// StepType returns the name of the Go type of the node's step. Unlike StepName, which names a single
+// instance of a step (e.g. "DrainNode-worker1"), it is the same for every node running that kind of step.
+func (n *ExecutionNode) StepType() string {
+	if n == nil || n.Step == nil {
+		return ""
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", n.Step), "*")
 }
 
 func NewExecutionGraph(name string) *ExecutionGraph {
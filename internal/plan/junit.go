@@ -0,0 +1,115 @@
+package plan
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JUnit report: the graph is one test suite, every node on every host is a test case whose class
+// name is the node, so CI systems group the hosts of a node together. Nodes that never reached a
+// host are a single test case named after the node.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes r to w as JUnit XML. The log file of a host, if any, is attached to its test
+// case with the [[ATTACHMENT|path]] convention understood by Jenkins and GitLab.
+func (r *RunReport) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{Name: r.GraphName, Time: junitSeconds(r.Duration)}
+	if !r.StartTime.IsZero() {
+		suite.Timestamp = r.StartTime.UTC().Format(time.RFC3339)
+	}
+	for _, node := range r.Nodes {
+		if len(node.Hosts) == 0 {
+			suite.Cases = append(suite.Cases, junitCase(node.Name, r.GraphName, node.Status, node.Duration, node.Message, ""))
+			continue
+		}
+		for _, host := range node.Hosts {
+			suite.Cases = append(suite.Cases, junitCase(host.Host, node.Name, host.Status, host.Duration, host.Message, host.LogFile))
+		}
+	}
+	for _, tc := range suite.Cases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	doc := junitTestSuites{
+		Name:     r.GraphName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitCase(name, className string, status Status, duration, message, logFile string) junitTestCase {
+	tc := junitTestCase{Name: name, ClassName: className, Time: junitSeconds(duration)}
+	switch status {
+	case StatusFailed:
+		tc.Failure = &junitMessage{Message: message, Text: message}
+	case StatusSkipped, StatusPending:
+		tc.Skipped = &junitMessage{Message: message}
+	}
+	if logFile != "" {
+		tc.SystemOut = fmt.Sprintf("log: %s\n[[ATTACHMENT|%s]]", logFile, logFile)
+	}
+	return tc
+}
+
+// junitSeconds converts a report duration such as "1m30s" into seconds.
+func junitSeconds(duration string) string {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return "0"
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,74 @@
+package plan
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PreviewState is what the precheck of a node predicts before anything is executed.
+type PreviewState string
+
+const (
+	// PreviewSatisfied means the precheck reported the step as already done.
+	PreviewSatisfied PreviewState = "Satisfied"
+	// PreviewWouldChange means the step would run and change the host.
+	PreviewWouldChange PreviewState = "WouldChange"
+	// PreviewSkipped means the node's condition is not met, so it would not run.
+	PreviewSkipped PreviewState = "Skipped"
+	// PreviewUnknown means the precheck failed, so the outcome cannot be predicted.
+	PreviewUnknown PreviewState = "Unknown"
+)
+
+// HostPreview is the precheck outcome of a node on one host.
+type HostPreview struct {
+	State   PreviewState `json:"state" yaml:"state"`
+	Message string       `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// NodePreview is the precheck outcome of a node across its hosts.
+type NodePreview struct {
+	NodeName string                  `json:"nodeName,omitempty" yaml:"nodeName,omitempty"`
+	StepName string                  `json:"stepName,omitempty" yaml:"stepName,omitempty"`
+	State    PreviewState            `json:"state" yaml:"state"`
+	Hosts    map[string]*HostPreview `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+}
+
+// Preview maps every node of a graph to what its precheck predicts. Prechecks of later nodes are
+// evaluated against the current state of the hosts, so a node that depends on changes made by an
+// earlier node may be reported as WouldChange even though it ends up satisfied.
+type Preview map[NodeID]*NodePreview
+
+// AggregateState derives the node state from its hosts: Unknown if any host is unknown, WouldChange
+// if any host would change, and Satisfied otherwise.
+func (np *NodePreview) AggregateState() {
+	state := PreviewSatisfied
+	for _, hp := range np.Hosts {
+		switch hp.State {
+		case PreviewUnknown:
+			np.State = PreviewUnknown
+			return
+		case PreviewWouldChange:
+			state = PreviewWouldChange
+		}
+	}
+	np.State = state
+}
+
+// NodesIn returns the IDs of the nodes in state, sorted.
+func (p Preview) NodesIn(state PreviewState) []NodeID {
+	var ids []NodeID
+	for id, np := range p {
+		if np.State == state {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Summary returns a one-line count of nodes per state.
+func (p Preview) Summary() string {
+	return fmt.Sprintf("%d would change, %d satisfied, %d skipped, %d unknown",
+		len(p.NodesIn(PreviewWouldChange)), len(p.NodesIn(PreviewSatisfied)),
+		len(p.NodesIn(PreviewSkipped)), len(p.NodesIn(PreviewUnknown)))
+}
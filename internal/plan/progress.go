@@ -0,0 +1,140 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+)
+
+// DurationHistory supplies how long a step type usually takes, based on earlier runs.
+type DurationHistory interface {
+	ExpectedDuration(stepType string) (time.Duration, bool)
+}
+
+// Progress is a snapshot of how far the execution of a graph has come.
+type Progress struct {
+	Completed int
+	Total     int
+	Percent   float64
+	// Estimated is true when Remaining and ETA are derived from historical durations.
+	Estimated bool
+	Remaining time.Duration
+	ETA       time.Time
+}
+
+func (p Progress) String() string {
+	s := fmt.Sprintf("%.0f%% (%d/%d nodes)", p.Percent, p.Completed, p.Total)
+	if p.Estimated {
+		s += fmt.Sprintf(", ETA %s (%s remaining)", p.ETA.Format("15:04:05"), p.Remaining.Round(time.Second))
+	}
+	return s
+}
+
+// ProgressTracker estimates the progress of a graph while its nodes finish. With a DurationHistory,
+// the percentage is weighted by expected node durations and the remaining time is the longest
+// chain of unfinished nodes. Without any history it falls back to counting nodes.
+type ProgressTracker struct {
+	graph    *ExecutionGraph
+	expected map[NodeID]time.Duration
+	total    time.Duration
+	done     map[NodeID]bool
+}
+
+// NewProgressTracker creates a tracker for g. Nodes whose step type has no history are expected to take
+// the average of the nodes that do; history may be nil.
+func NewProgressTracker(g *ExecutionGraph, history DurationHistory) *ProgressTracker {
+	t := &ProgressTracker{graph: g, done: make(map[NodeID]bool)}
+	if history == nil {
+		return t
+	}
+
+	known := make(map[NodeID]time.Duration)
+	var sum time.Duration
+	for id, node := range g.Nodes {
+		if d, ok := history.ExpectedDuration(node.StepType()); ok {
+			known[id] = d
+			sum += d
+		}
+	}
+	if len(known) == 0 {
+		return t
+	}
+
+	average := sum / time.Duration(len(known))
+	t.expected = make(map[NodeID]time.Duration, len(g.Nodes))
+	for id := range g.Nodes {
+		d, ok := known[id]
+		if !ok {
+			d = average
+		}
+		t.expected[id] = d
+		t.total += d
+	}
+	return t
+}
+
+// MarkDone records that a node finished, whatever its status.
+func (t *ProgressTracker) MarkDone(id NodeID) {
+	t.done[id] = true
+}
+
+// Snapshot returns the progress at now.
+func (t *ProgressTracker) Snapshot(now time.Time) Progress {
+	p := Progress{Completed: len(t.done), Total: len(t.graph.Nodes)}
+	if p.Total == 0 {
+		p.Percent = 100
+		return p
+	}
+	if t.expected == nil || t.total <= 0 {
+		p.Percent = float64(p.Completed) * 100 / float64(p.Total)
+		return p
+	}
+
+	var finished time.Duration
+	for id := range t.done {
+		finished += t.expected[id]
+	}
+	p.Percent = float64(finished) * 100 / float64(t.total)
+	p.Estimated = true
+	p.Remaining = t.criticalPath()
+	p.ETA = now.Add(p.Remaining)
+	return p
+}
+
+// criticalPath returns the expected duration of the longest dependency chain of unfinished nodes.
+func (t *ProgressTracker) criticalPath() time.Duration {
+	memo := make(map[NodeID]time.Duration, len(t.graph.Nodes))
+	visiting := make(map[NodeID]bool)
+	var finish func(id NodeID) time.Duration
+	finish = func(id NodeID) time.Duration {
+		if d, ok := memo[id]; ok {
+			return d
+		}
+		node, ok := t.graph.Nodes[id]
+		if !ok || visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		var longestDep time.Duration
+		for _, dep := range node.Dependencies {
+			if d := finish(dep); d > longestDep {
+				longestDep = d
+			}
+		}
+		visiting[id] = false
+
+		d := longestDep
+		if !t.done[id] {
+			d += t.expected[id]
+		}
+		memo[id] = d
+		return d
+	}
+
+	var longest time.Duration
+	for id := range t.graph.Nodes {
+		if d := finish(id); d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
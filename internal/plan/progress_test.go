@@ -0,0 +1,103 @@
+package plan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/step"
+)
+
+type fakeDurationHistory map[string]time.Duration
+
+func (h fakeDurationHistory) ExpectedDuration(stepType string) (time.Duration, bool) {
+	d, ok := h[stepType]
+	return d, ok
+}
+
+// Step types of the progress test graph. Only their type names are used.
+type (
+	preflightStep         struct{ step.Step }
+	installEtcdStep       struct{ step.Step }
+	installContainerdStep struct{ step.Step }
+	kubeadmInitStep       struct{ step.Step }
+)
+
+// newProgressTestGraph builds preflight -> (etcd, runtime) -> kubeadm-init.
+func newProgressTestGraph() *ExecutionGraph {
+	g := NewExecutionGraph("CreateCluster")
+	g.Nodes["preflight"] = &ExecutionNode{Name: "preflight", StepName: "Preflight", Step: &preflightStep{}}
+	g.Nodes["etcd"] = &ExecutionNode{Name: "etcd", StepName: "InstallEtcd", Step: &installEtcdStep{}, Dependencies: []NodeID{"preflight"}}
+	g.Nodes["runtime"] = &ExecutionNode{Name: "runtime", StepName: "InstallContainerd", Step: &installContainerdStep{}, Dependencies: []NodeID{"preflight"}}
+	g.Nodes["init"] = &ExecutionNode{Name: "init", StepName: "KubeadmInit", Step: &kubeadmInitStep{}, Dependencies: []NodeID{"etcd", "runtime"}}
+	return g
+}
+
+func TestProgressTracker_WithHistory(t *testing.T) {
+	history := fakeDurationHistory{
+		"plan.preflightStep":         10 * time.Second,
+		"plan.installEtcdStep":       60 * time.Second,
+		"plan.installContainerdStep": 30 * time.Second,
+		"plan.kubeadmInitStep":       100 * time.Second,
+	}
+	tracker := NewProgressTracker(newProgressTestGraph(), history)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	p := tracker.Snapshot(now)
+	if !p.Estimated || p.Remaining != 170*time.Second {
+		t.Fatalf("expected the critical path of 170s before starting, got %+v", p)
+	}
+
+	tracker.MarkDone("preflight")
+	tracker.MarkDone("runtime")
+	p = tracker.Snapshot(now)
+	if p.Remaining != 160*time.Second {
+		t.Errorf("expected etcd and init to remain (160s), got %s", p.Remaining)
+	}
+	if !p.ETA.Equal(now.Add(160 * time.Second)) {
+		t.Errorf("expected ETA %s, got %s", now.Add(160*time.Second), p.ETA)
+	}
+	if p.Percent != 20 {
+		t.Errorf("expected 20%% of expected work done, got %.1f", p.Percent)
+	}
+	if p.Completed != 2 || p.Total != 4 {
+		t.Errorf("expected 2/4 nodes, got %d/%d", p.Completed, p.Total)
+	}
+
+	tracker.MarkDone("etcd")
+	tracker.MarkDone("init")
+	p = tracker.Snapshot(now)
+	if p.Percent != 100 || p.Remaining != 0 {
+		t.Errorf("expected a finished graph, got %+v", p)
+	}
+}
+
+func TestProgressTracker_PartialHistory(t *testing.T) {
+	tracker := NewProgressTracker(newProgressTestGraph(), fakeDurationHistory{
+		"plan.installEtcdStep":       60 * time.Second,
+		"plan.installContainerdStep": 20 * time.Second,
+	})
+	p := tracker.Snapshot(time.Now())
+	// Steps without history are expected to take the 40s average: 40 + 60 + 40.
+	if !p.Estimated || p.Remaining != 140*time.Second {
+		t.Errorf("expected 140s remaining, got %+v", p)
+	}
+}
+
+func TestProgressTracker_NoHistory(t *testing.T) {
+	for name, history := range map[string]DurationHistory{"nil history": nil, "empty history": fakeDurationHistory{}} {
+		t.Run(name, func(t *testing.T) {
+			tracker := NewProgressTracker(newProgressTestGraph(), history)
+			tracker.MarkDone("preflight")
+			p := tracker.Snapshot(time.Now())
+			if p.Estimated || !p.ETA.IsZero() {
+				t.Errorf("expected no ETA without history, got %+v", p)
+			}
+			if p.Percent != 25 {
+				t.Errorf("expected node-count progress of 25%%, got %.1f", p.Percent)
+			}
+			if got := p.String(); got != "25% (1/4 nodes)" {
+				t.Errorf("unexpected progress string %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PlannedNode is the reviewable form of an execution node: what runs, where, and after what.
+type PlannedNode struct {
+	ID           NodeID   `json:"id"`
+	Name         string   `json:"name"`
+	Step         string   `json:"step,omitempty"`
+	Hosts        []string `json:"hosts"`
+	Dependencies []NodeID `json:"dependencies"`
+	Module       string   `json:"module,omitempty"`
+	Task         string   `json:"task,omitempty"`
+	Optional     bool     `json:"optional,omitempty"`
+	AlwaysRun    bool     `json:"alwaysRun,omitempty"`
+}
+
+// PlannedGraph is an execution graph flattened for review. Nodes are in execution order.
+type PlannedGraph struct {
+	Name       string        `json:"name"`
+	EntryNodes []NodeID      `json:"entryNodes"`
+	ExitNodes  []NodeID      `json:"exitNodes"`
+	Nodes      []PlannedNode `json:"nodes"`
+}
+
+// NewPlannedGraph flattens g. It fails if g has a dependency cycle or a dangling dependency.
+func NewPlannedGraph(g *ExecutionGraph) (*PlannedGraph, error) {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	pg := &PlannedGraph{
+		Name:       g.Name,
+		EntryNodes: UniqueNodeIDs(g.EntryNodes),
+		ExitNodes:  UniqueNodeIDs(g.ExitNodes),
+		Nodes:      make([]PlannedNode, 0, len(order)),
+	}
+	for _, id := range order {
+		node := g.Nodes[id]
+		pn := PlannedNode{
+			ID:           id,
+			Name:         node.Name,
+			Step:         node.StepName,
+			Hosts:        node.HostNames(),
+			Dependencies: UniqueNodeIDs(node.Dependencies),
+			Module:       node.ModuleName,
+			Task:         node.TaskName,
+			Optional:     node.Optional,
+			AlwaysRun:    node.AlwaysRun,
+		}
+		if pn.Step == "" && node.Step != nil && node.Step.Meta() != nil {
+			pn.Step = node.Step.Meta().Name
+		}
+		pg.Nodes = append(pg.Nodes, pn)
+	}
+	return pg, nil
+}
+
+// HostNames returns the names of the hosts the node runs on, falling back to Hostnames for nodes
+// that were loaded without their hosts.
+func (n *ExecutionNode) HostNames() []string {
+	if len(n.Hosts) == 0 {
+		return append([]string{}, n.Hostnames...)
+	}
+	names := make([]string, 0, len(n.Hosts))
+	for _, h := range n.Hosts {
+		if h != nil {
+			names = append(names, h.GetName())
+		}
+	}
+	return names
+}
+
+// TopologicalOrder returns the node IDs so that every node comes after all of its dependencies.
+// Nodes that become ready together are ordered by ID, so the order is the same on every call.
+func (g *ExecutionGraph) TopologicalOrder() ([]NodeID, error) {
+	inDegree := make(map[NodeID]int, len(g.Nodes))
+	dependents := make(map[NodeID][]NodeID, len(g.Nodes))
+	for id, node := range g.Nodes {
+		if _, ok := inDegree[id]; !ok {
+			inDegree[id] = 0
+		}
+		for _, dep := range UniqueNodeIDs(node.Dependencies) {
+			if _, exists := g.Nodes[dep]; !exists {
+				return nil, fmt.Errorf("node '%s' has a dependency on non-existent node '%s'", id, dep)
+			}
+			dependents[dep] = append(dependents[dep], id)
+			inDegree[id]++
+		}
+	}
+
+	var ready []NodeID
+	for id, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, id)
+		}
+	}
+	order := make([]NodeID, 0, len(g.Nodes))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return ready[i] < ready[j] })
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, next := range dependents[id] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if len(order) != len(g.Nodes) {
+		return nil, fmt.Errorf("cyclic dependency detected in the execution graph (ordered %d nodes, expected %d)", len(order), len(g.Nodes))
+	}
+	return order, nil
+}
+
+// WriteText prints the graph as a numbered list of nodes in execution order, with the hosts each
+// node runs on and the nodes it waits for.
+func (pg *PlannedGraph) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan: %s (%d nodes)\n", pg.Name, len(pg.Nodes))
+	for i, n := range pg.Nodes {
+		fmt.Fprintf(&b, "\n%3d. %s", i+1, n.ID)
+		if n.Step != "" && n.Step != string(n.ID) {
+			fmt.Fprintf(&b, " [%s]", n.Step)
+		}
+		if n.Optional {
+			b.WriteString(" (optional)")
+		}
+		if n.AlwaysRun {
+			b.WriteString(" (always runs)")
+		}
+		b.WriteString("\n")
+		hosts := "-"
+		if len(n.Hosts) > 0 {
+			hosts = strings.Join(n.Hosts, ", ")
+		}
+		fmt.Fprintf(&b, "     hosts:      %s\n", hosts)
+		if len(n.Dependencies) > 0 {
+			deps := make([]string, len(n.Dependencies))
+			for j, d := range n.Dependencies {
+				deps[j] = string(d)
+			}
+			fmt.Fprintf(&b, "     depends on: %s\n", strings.Join(deps, ", "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
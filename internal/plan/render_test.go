@@ -0,0 +1,92 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newRenderTestGraph() *ExecutionGraph {
+	g := NewExecutionGraph("CreateCluster")
+	g.Nodes["InstallContainerd"] = &ExecutionNode{Name: "InstallContainerd", StepName: "InstallContainerd", Hostnames: []string{"master1", "worker1"}}
+	g.Nodes["InitMaster"] = &ExecutionNode{Name: "InitMaster", StepName: "KubeadmInit", Hostnames: []string{"master1"}, Dependencies: []NodeID{"InstallContainerd"}}
+	g.Nodes["JoinWorker"] = &ExecutionNode{Name: "JoinWorker", StepName: "KubeadmJoin", Hostnames: []string{"worker1"}, Dependencies: []NodeID{"InitMaster", "InstallContainerd"}}
+	g.Nodes["MetricsServer"] = &ExecutionNode{Name: "MetricsServer", StepName: "MetricsServer", Hostnames: []string{"master1"}, Dependencies: []NodeID{"InitMaster"}, Optional: true}
+	g.CalculateEntryAndExitNodes()
+	return g
+}
+
+func TestNewPlannedGraph(t *testing.T) {
+	pg, err := NewPlannedGraph(newRenderTestGraph())
+	if err != nil {
+		t.Fatalf("NewPlannedGraph failed: %v", err)
+	}
+	var order []NodeID
+	for _, n := range pg.Nodes {
+		order = append(order, n.ID)
+	}
+	expected := []NodeID{"InstallContainerd", "InitMaster", "JoinWorker", "MetricsServer"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected order %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v, got %v", expected, order)
+		}
+	}
+
+	data, err := json.Marshal(pg)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `{"id":"JoinWorker","name":"JoinWorker","step":"KubeadmJoin","hosts":["worker1"],"dependencies":["InitMaster","InstallContainerd"]}`) {
+		t.Errorf("expected JSON to carry hosts and dependencies, got %s", data)
+	}
+}
+
+func TestNewPlannedGraph_InvalidGraph(t *testing.T) {
+	g := NewExecutionGraph("Cycle")
+	g.Nodes["a"] = &ExecutionNode{Name: "a", Dependencies: []NodeID{"b"}}
+	g.Nodes["b"] = &ExecutionNode{Name: "b", Dependencies: []NodeID{"a"}}
+	if _, err := NewPlannedGraph(g); err == nil || !strings.Contains(err.Error(), "cyclic") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+
+	g = NewExecutionGraph("Dangling")
+	g.Nodes["a"] = &ExecutionNode{Name: "a", Dependencies: []NodeID{"missing"}}
+	if _, err := NewPlannedGraph(g); err == nil || !strings.Contains(err.Error(), "non-existent node 'missing'") {
+		t.Errorf("expected a missing dependency error, got %v", err)
+	}
+}
+
+func TestPlannedGraph_WriteText(t *testing.T) {
+	pg, err := NewPlannedGraph(newRenderTestGraph())
+	if err != nil {
+		t.Fatalf("NewPlannedGraph failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := pg.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	expected := `Plan: CreateCluster (4 nodes)
+
+  1. InstallContainerd
+     hosts:      master1, worker1
+
+  2. InitMaster [KubeadmInit]
+     hosts:      master1
+     depends on: InstallContainerd
+
+  3. JoinWorker [KubeadmJoin]
+     hosts:      worker1
+     depends on: InitMaster, InstallContainerd
+
+  4. MetricsServer (optional)
+     hosts:      master1
+     depends on: InitMaster
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected text output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,330 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// RunReport is the archived form of a GraphExecutionResult. It is written by WriteReport so CI
+// jobs can keep a structured record of a run next to a short human-readable summary.
+type RunReport struct {
+	GraphName   string        `json:"graphName"`
+	Status      Status        `json:"status"`
+	Message     string        `json:"message,omitempty"`
+	StartTime   time.Time     `json:"startTime"`
+	EndTime     time.Time     `json:"endTime"`
+	Duration    string        `json:"duration"`
+	Summary     ReportSummary `json:"summary"`
+	SummaryText string        `json:"summaryText"`
+	Nodes       []NodeReport  `json:"nodes"`
+	Hosts       []HostSummary `json:"hosts"`
+	Artifacts   []string      `json:"artifacts,omitempty"`
+	Preview     Preview       `json:"preview,omitempty"`
+	DryRun      *DryRunOutput `json:"dryRun,omitempty"`
+	Warnings    []string      `json:"warnings,omitempty"`
+	// NoOp is set when the run succeeded without changing any host: every node that ran was already
+	// satisfied according to its precheck.
+	NoOp bool `json:"noOp"`
+}
+
+// ReportSummary counts node outcomes of a run.
+type ReportSummary struct {
+	TotalNodes     int      `json:"totalNodes"`
+	SucceededNodes int      `json:"succeededNodes"`
+	FailedNodes    int      `json:"failedNodes"`
+	SkippedNodes   int      `json:"skippedNodes"`
+	TotalHosts     int      `json:"totalHosts"`
+	FailedHosts    []string `json:"failedHosts,omitempty"`
+	// SatisfiedNodes lists the succeeded nodes whose precheck found every host already done, so
+	// they changed nothing.
+	SatisfiedNodes []string `json:"satisfiedNodes,omitempty"`
+}
+
+// NodeReport is the outcome of one execution node and of each host it ran on.
+type NodeReport struct {
+	Name      string       `json:"name"`
+	Step      string       `json:"step,omitempty"`
+	Status    Status       `json:"status"`
+	Duration  string       `json:"duration"`
+	Message   string       `json:"message,omitempty"`
+	Satisfied bool         `json:"satisfied,omitempty"`
+	Hosts     []HostReport `json:"hosts,omitempty"`
+}
+
+// HostReport is the outcome of one node on one host.
+type HostReport struct {
+	Host      string   `json:"host"`
+	Status    Status   `json:"status"`
+	Duration  string   `json:"duration"`
+	Message   string   `json:"message,omitempty"`
+	Artifacts []string `json:"artifacts,omitempty"`
+	LogFile   string   `json:"logFile,omitempty"`
+	Satisfied bool     `json:"satisfied,omitempty"`
+}
+
+// HostSummary aggregates all node results of a single host.
+type HostSummary struct {
+	Host        string   `json:"host"`
+	Status      Status   `json:"status"`
+	Steps       int      `json:"steps"`
+	FailedSteps []string `json:"failedSteps,omitempty"`
+}
+
+// NewRunReport builds a report from result. Nodes are ordered by start time and hosts by name.
+func NewRunReport(result *GraphExecutionResult) *RunReport {
+	report := &RunReport{
+		GraphName: result.GraphName,
+		Status:    result.Status,
+		Message:   result.Message,
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		Duration:  durationString(result.StartTime, result.EndTime),
+		Nodes:     []NodeReport{},
+		Hosts:     []HostSummary{},
+		Preview:   result.Preview,
+		DryRun:    result.DryRun,
+		Warnings:  result.Warnings,
+	}
+
+	nodeResults := make([]*NodeResult, 0, len(result.NodeResults))
+	for _, nr := range result.NodeResults {
+		if nr != nil {
+			nodeResults = append(nodeResults, nr)
+		}
+	}
+	sort.SliceStable(nodeResults, func(i, j int) bool {
+		if !nodeResults[i].StartTime.Equal(nodeResults[j].StartTime) {
+			return nodeResults[i].StartTime.Before(nodeResults[j].StartTime)
+		}
+		return nodeResults[i].NodeName < nodeResults[j].NodeName
+	})
+
+	hosts := make(map[string]*HostSummary)
+	changed := false
+	for _, nr := range nodeResults {
+		node := NodeReport{
+			Name:      nr.NodeName,
+			Step:      nr.StepName,
+			Status:    nr.Status,
+			Duration:  durationString(nr.StartTime, nr.EndTime),
+			Message:   nr.Message,
+			Satisfied: nr.Satisfied(),
+		}
+		report.Summary.TotalNodes++
+		switch nr.Status {
+		case StatusSuccess:
+			report.Summary.SucceededNodes++
+			if node.Satisfied {
+				report.Summary.SatisfiedNodes = append(report.Summary.SatisfiedNodes, nr.NodeName)
+			} else if len(nr.HostResults) > 0 {
+				changed = true
+			}
+		case StatusFailed:
+			report.Summary.FailedNodes++
+		case StatusSkipped:
+			report.Summary.SkippedNodes++
+		}
+
+		hostNames := make([]string, 0, len(nr.HostResults))
+		for name := range nr.HostResults {
+			hostNames = append(hostNames, name)
+		}
+		sort.Strings(hostNames)
+		for _, name := range hostNames {
+			hr := nr.HostResults[name]
+			if hr == nil {
+				continue
+			}
+			artifacts := hostArtifacts(hr)
+			node.Hosts = append(node.Hosts, HostReport{
+				Host:      name,
+				Status:    hr.Status,
+				Duration:  durationString(hr.StartTime, hr.EndTime),
+				Message:   hr.Message,
+				Artifacts: artifacts,
+				LogFile:   hr.LogFile,
+				Satisfied: hr.Satisfied,
+			})
+			report.Artifacts = append(report.Artifacts, artifacts...)
+
+			summary, ok := hosts[name]
+			if !ok {
+				summary = &HostSummary{Host: name, Status: StatusSuccess}
+				hosts[name] = summary
+			}
+			summary.Steps++
+			if hr.Status == StatusFailed {
+				summary.Status = StatusFailed
+				summary.FailedSteps = append(summary.FailedSteps, nr.NodeName)
+			}
+		}
+		report.Nodes = append(report.Nodes, node)
+	}
+
+	hostNames := make([]string, 0, len(hosts))
+	for name := range hosts {
+		hostNames = append(hostNames, name)
+	}
+	sort.Strings(hostNames)
+	for _, name := range hostNames {
+		report.Hosts = append(report.Hosts, *hosts[name])
+		if hosts[name].Status == StatusFailed {
+			report.Summary.FailedHosts = append(report.Summary.FailedHosts, name)
+		}
+	}
+	report.Summary.TotalHosts = len(hostNames)
+	report.NoOp = result.Status == StatusSuccess && result.DryRun == nil && !changed && report.Summary.FailedNodes == 0
+	report.SummaryText = report.summaryText()
+	return report
+}
+
+// summaryText renders the short human-readable part of the report.
+func (r *RunReport) summaryText() string {
+	var b strings.Builder
+	status := string(r.Status)
+	if r.Status == StatusSuccess && len(r.Warnings) > 0 {
+		status = fmt.Sprintf("%s with %d warning(s)", r.Status, len(r.Warnings))
+	}
+	fmt.Fprintf(&b, "%s: %s in %s\n", r.GraphName, status, r.Duration)
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&b, "  WARNING: %s\n", warning)
+	}
+	if r.Message != "" {
+		fmt.Fprintf(&b, "Message: %s\n", r.Message)
+	}
+	fmt.Fprintf(&b, "Nodes: %d total, %d succeeded, %d failed, %d skipped\n",
+		r.Summary.TotalNodes, r.Summary.SucceededNodes, r.Summary.FailedNodes, r.Summary.SkippedNodes)
+	if len(r.Summary.SatisfiedNodes) > 0 {
+		fmt.Fprintf(&b, "Already satisfied: %d of %d node(s) skipped by their precheck\n", len(r.Summary.SatisfiedNodes), r.Summary.TotalNodes)
+	}
+	if r.NoOp {
+		fmt.Fprintf(&b, "No changes: every host already matched the desired state\n")
+	}
+	fmt.Fprintf(&b, "Hosts: %d total, %d failed\n", r.Summary.TotalHosts, len(r.Summary.FailedHosts))
+	failedLogs := make(map[string][]string)
+	for _, node := range r.Nodes {
+		for _, host := range node.Hosts {
+			if host.Status == StatusFailed && host.LogFile != "" {
+				failedLogs[host.Host] = append(failedLogs[host.Host], host.LogFile)
+			}
+		}
+	}
+	for _, host := range r.Hosts {
+		if host.Status == StatusFailed {
+			fmt.Fprintf(&b, "  %s failed: %s\n", host.Host, strings.Join(host.FailedSteps, ", "))
+			for _, logFile := range failedLogs[host.Host] {
+				fmt.Fprintf(&b, "    log: %s\n", logFile)
+			}
+		}
+	}
+	if len(r.Artifacts) > 0 {
+		fmt.Fprintf(&b, "Artifacts: %d\n", len(r.Artifacts))
+	}
+	if r.DryRun != nil {
+		fmt.Fprintf(&b, "Dry run: %s\n", r.DryRun.Summary())
+	}
+	return b.String()
+}
+
+// WriteTable writes one row per node and host to w. Nodes that never reached a host, such as those
+// skipped after an upstream failure, get a single row with their own status and message.
+func (r *RunReport) WriteTable(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"NODE", "HOST", "STATUS", "DURATION", "MESSAGE"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoMergeCells(true)
+	table.SetRowLine(false)
+	for _, node := range r.Nodes {
+		if len(node.Hosts) == 0 {
+			table.Append([]string{node.Name, "-", string(node.Status), node.Duration, node.Message})
+			continue
+		}
+		for _, host := range node.Hosts {
+			status := string(host.Status)
+			if host.Satisfied {
+				status = string(PreviewSatisfied)
+			}
+			table.Append([]string{node.Name, host.Host, status, host.Duration, host.Message})
+		}
+	}
+	table.Render()
+}
+
+// Formats accepted by WriteReportAs.
+const (
+	ReportFormatJSON  = "json"
+	ReportFormatJUnit = "junit"
+	ReportFormatSARIF = "sarif"
+)
+
+// ReportFormats lists the formats accepted by WriteReportAs.
+var ReportFormats = []string{ReportFormatJSON, ReportFormatJUnit, ReportFormatSARIF}
+
+// WriteReport writes the report of result to path as indented JSON, creating parent directories as needed.
+func WriteReport(path string, result *GraphExecutionResult) error {
+	return WriteReportAs(path, ReportFormatJSON, result)
+}
+
+// WriteReportAs writes the report of result to path in format, one of ReportFormats, creating
+// parent directories as needed.
+func WriteReportAs(path, format string, result *GraphExecutionResult) error {
+	if result == nil {
+		return fmt.Errorf("no execution result to report")
+	}
+	report := NewRunReport(result)
+	var buf bytes.Buffer
+	switch format {
+	case ReportFormatJSON, "":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode run report: %w", err)
+		}
+		buf.Write(append(data, '\n'))
+	case ReportFormatJUnit:
+		if err := report.WriteJUnit(&buf); err != nil {
+			return err
+		}
+	case ReportFormatSARIF:
+		if err := report.WriteSARIF(&buf); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported report format '%s', must be one of %v", format, ReportFormats)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write run report %s: %w", path, err)
+	}
+	return nil
+}
+
+func hostArtifacts(hr *HostResult) []string {
+	if hr.Metadata == nil {
+		return nil
+	}
+	artifacts, _ := hr.Metadata["artifacts"].([]string)
+	return artifacts
+}
+
+func durationString(start, end time.Time) string {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return "0s"
+	}
+	return end.Sub(start).Round(time.Millisecond).String()
+}
@@ -0,0 +1,322 @@
+package plan
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReport(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	result := &GraphExecutionResult{
+		GraphName: "CreateCluster",
+		StartTime: start,
+		EndTime:   start.Add(90 * time.Second),
+		Status:    StatusSuccess,
+		NodeResults: map[NodeID]*NodeResult{
+			"preflight": {
+				NodeName:  "Preflight",
+				StepName:  "CheckNodeResources",
+				Status:    StatusSuccess,
+				StartTime: start,
+				EndTime:   start.Add(10 * time.Second),
+				HostResults: map[string]*HostResult{
+					"node1": {HostName: "node1", Status: StatusSuccess, StartTime: start, EndTime: start.Add(5 * time.Second)},
+					"node2": {HostName: "node2", Status: StatusSuccess, StartTime: start, EndTime: start.Add(8 * time.Second)},
+				},
+			},
+			"backup": {
+				NodeName:  "BackupPKI",
+				StepName:  "FetchPKI",
+				Status:    StatusSuccess,
+				StartTime: start.Add(10 * time.Second),
+				EndTime:   start.Add(20 * time.Second),
+				HostResults: map[string]*HostResult{
+					"node1": {
+						HostName:  "node1",
+						Status:    StatusSuccess,
+						StartTime: start.Add(10 * time.Second),
+						EndTime:   start.Add(20 * time.Second),
+						Metadata:  map[string]interface{}{"artifacts": []string{"/work/pki.tar.gz"}},
+					},
+				},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "reports", "run.json")
+	if err := WriteReport(path, result); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected report file to be written: %v", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	for _, key := range []string{"graphName", "status", "startTime", "endTime", "duration", "summary", "summaryText", "nodes", "hosts", "artifacts"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected top-level field %q in report", key)
+		}
+	}
+
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Status != StatusSuccess || report.Duration != "1m30s" {
+		t.Errorf("expected a successful 1m30s run, got %s in %s", report.Status, report.Duration)
+	}
+	if report.Summary.TotalNodes != 2 || report.Summary.SucceededNodes != 2 || report.Summary.TotalHosts != 2 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+	if len(report.Nodes) != 2 || report.Nodes[0].Name != "Preflight" || len(report.Nodes[0].Hosts) != 2 {
+		t.Errorf("expected nodes in start order with per-host results, got %+v", report.Nodes)
+	}
+	if len(report.Hosts) != 2 || report.Hosts[0].Host != "node1" || report.Hosts[0].Steps != 2 {
+		t.Errorf("expected per-host summaries, got %+v", report.Hosts)
+	}
+	if len(report.Artifacts) != 1 || report.Artifacts[0] != "/work/pki.tar.gz" {
+		t.Errorf("expected artifact paths to be collected, got %v", report.Artifacts)
+	}
+	if !strings.Contains(report.SummaryText, "CreateCluster: Success in 1m30s") {
+		t.Errorf("unexpected summary text:\n%s", report.SummaryText)
+	}
+}
+
+func TestNewRunReport_FailedHosts(t *testing.T) {
+	start := time.Now()
+	result := &GraphExecutionResult{
+		GraphName: "CreateCluster",
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Status:    StatusFailed,
+		Message:   "node InstallKubelet failed",
+		NodeResults: map[NodeID]*NodeResult{
+			"kubelet": {
+				NodeName: "InstallKubelet",
+				Status:   StatusFailed,
+				HostResults: map[string]*HostResult{
+					"node1": {HostName: "node1", Status: StatusSuccess},
+					"node2": {HostName: "node2", Status: StatusFailed, Message: "Run failed: exit status 1", LogFile: ".kubexm/logs/run1/InstallKubelet/node2.log"},
+				},
+			},
+		},
+	}
+
+	report := NewRunReport(result)
+	if report.Summary.FailedNodes != 1 || len(report.Summary.FailedHosts) != 1 || report.Summary.FailedHosts[0] != "node2" {
+		t.Errorf("expected node2 to be reported as failed, got %+v", report.Summary)
+	}
+	if !strings.Contains(report.SummaryText, "node2 failed: InstallKubelet") {
+		t.Errorf("expected failed host in summary text, got:\n%s", report.SummaryText)
+	}
+	if !strings.Contains(report.SummaryText, "log: .kubexm/logs/run1/InstallKubelet/node2.log") {
+		t.Errorf("expected the log file of the failed host in summary text, got:\n%s", report.SummaryText)
+	}
+}
+
+func TestRunReport_WriteTable(t *testing.T) {
+	start := time.Now()
+	result := &GraphExecutionResult{
+		GraphName: "VerifyCluster",
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Status:    StatusFailed,
+		NodeResults: map[NodeID]*NodeResult{
+			"dns": {
+				NodeName:  "CheckDNSResolution",
+				Status:    StatusFailed,
+				StartTime: start,
+				HostResults: map[string]*HostResult{
+					"master1": {HostName: "master1", Status: StatusFailed, Message: "cannot resolve kubernetes.default.svc.cluster.local"},
+				},
+			},
+			"cleanup": {
+				NodeName:  "RemoveVerifyWorkload",
+				Status:    StatusSkipped,
+				StartTime: start.Add(time.Second),
+				Message:   "Skipped due to upstream failure/skip of node 'dns'",
+			},
+		},
+	}
+
+	var buf strings.Builder
+	NewRunReport(result).WriteTable(&buf)
+	out := buf.String()
+	for _, want := range []string{"NODE", "CheckDNSResolution", "master1", "cannot resolve kubernetes.default.svc.cluster.local", "RemoveVerifyWorkload", "Skipped due to upstream"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in table, got:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "CheckDNSResolution") > strings.Index(out, "RemoveVerifyWorkload") {
+		t.Errorf("expected rows in start order, got:\n%s", out)
+	}
+}
+
+func TestNewRunReport_SatisfiedNodes(t *testing.T) {
+	start := time.Now()
+	result := &GraphExecutionResult{
+		GraphName: "CreateCluster",
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Status:    StatusSuccess,
+		NodeResults: map[NodeID]*NodeResult{
+			"runtime": {
+				NodeName:  "InstallContainerd",
+				Status:    StatusSuccess,
+				StartTime: start,
+				HostResults: map[string]*HostResult{
+					"node1": {HostName: "node1", Status: StatusSuccess, Satisfied: true},
+					"node2": {HostName: "node2", Status: StatusSuccess, Satisfied: true},
+				},
+			},
+			"kubelet": {
+				NodeName:  "InstallKubelet",
+				Status:    StatusSuccess,
+				StartTime: start.Add(time.Millisecond),
+				HostResults: map[string]*HostResult{
+					"node1": {HostName: "node1", Status: StatusSuccess, Satisfied: true},
+					"node2": {HostName: "node2", Status: StatusSuccess},
+				},
+			},
+		},
+	}
+
+	report := NewRunReport(result)
+	if len(report.Summary.SatisfiedNodes) != 1 || report.Summary.SatisfiedNodes[0] != "InstallContainerd" {
+		t.Errorf("expected only InstallContainerd to be satisfied, got %v", report.Summary.SatisfiedNodes)
+	}
+	if report.NoOp {
+		t.Error("expected a run that changed node2 not to be a no-op")
+	}
+	if !strings.Contains(report.SummaryText, "Already satisfied: 1 of 2 node(s)") {
+		t.Errorf("unexpected summary text:\n%s", report.SummaryText)
+	}
+
+	result.NodeResults["kubelet"].HostResults["node2"].Satisfied = true
+	report = NewRunReport(result)
+	if !report.NoOp || !strings.Contains(report.SummaryText, "No changes") {
+		t.Errorf("expected a run with every node satisfied to be a no-op, got:\n%s", report.SummaryText)
+	}
+	var buf strings.Builder
+	report.WriteTable(&buf)
+	if !strings.Contains(buf.String(), "Satisfied") {
+		t.Errorf("expected satisfied hosts in the table, got:\n%s", buf.String())
+	}
+}
+
+func failedRunResult(start time.Time) *GraphExecutionResult {
+	return &GraphExecutionResult{
+		GraphName: "CreateCluster",
+		StartTime: start,
+		EndTime:   start.Add(2 * time.Second),
+		Status:    StatusFailed,
+		Warnings:  []string{"optional node 'InstallAddon' failed"},
+		NodeResults: map[NodeID]*NodeResult{
+			"kubelet": {
+				NodeName:  "InstallKubelet",
+				StepName:  "InstallKubeletBinary",
+				Status:    StatusFailed,
+				StartTime: start,
+				EndTime:   start.Add(time.Second),
+				HostResults: map[string]*HostResult{
+					"node1": {HostName: "node1", Status: StatusSuccess, StartTime: start, EndTime: start.Add(500 * time.Millisecond)},
+					"node2": {HostName: "node2", Status: StatusFailed, Message: "Run failed: exit status 1", LogFile: ".kubexm/logs/run1/InstallKubelet/node2.log", StartTime: start, EndTime: start.Add(time.Second)},
+				},
+			},
+			"join": {
+				NodeName:  "JoinWorkers",
+				Status:    StatusSkipped,
+				StartTime: start.Add(time.Second),
+				Message:   "Skipped due to upstream failure/skip of node 'kubelet'",
+			},
+		},
+	}
+}
+
+func TestWriteReportAs_JUnit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.xml")
+	if err := WriteReportAs(path, ReportFormatJUnit, failedRunResult(time.Now())); err != nil {
+		t.Fatalf("WriteReportAs failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, data)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 {
+		t.Errorf("expected 3 tests with 1 failure and 1 skip, got %d/%d/%d", suites.Tests, suites.Failures, suites.Skipped)
+	}
+	cases := suites.Suites[0].Cases
+	if cases[0].ClassName != "InstallKubelet" || cases[0].Name != "node1" || cases[0].Time != "0.500" {
+		t.Errorf("expected a test case per node and host, got %+v", cases[0])
+	}
+	failed := cases[1]
+	if failed.Failure == nil || failed.Failure.Message != "Run failed: exit status 1" {
+		t.Errorf("expected the failure message of node2, got %+v", failed)
+	}
+	if !strings.Contains(failed.SystemOut, "[[ATTACHMENT|.kubexm/logs/run1/InstallKubelet/node2.log]]") {
+		t.Errorf("expected the log file to be attached, got %q", failed.SystemOut)
+	}
+	if cases[2].Name != "JoinWorkers" || cases[2].Skipped == nil {
+		t.Errorf("expected a node without hosts to be a single skipped test case, got %+v", cases[2])
+	}
+}
+
+func TestWriteReportAs_SARIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.sarif")
+	if err := WriteReportAs(path, ReportFormatSARIF, failedRunResult(time.Now())); err != nil {
+		t.Fatalf("WriteReportAs failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("expected a single SARIF 2.1.0 run, got %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "InstallKubeletBinary" {
+		t.Errorf("expected the failed step as the only rule, got %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected one error and one warning, got %+v", run.Results)
+	}
+	failure := run.Results[0]
+	if failure.Level != "error" || failure.Message.Text != "InstallKubelet failed on node2: Run failed: exit status 1" {
+		t.Errorf("unexpected failure result: %+v", failure)
+	}
+	if len(failure.Locations) != 1 || failure.Locations[0].PhysicalLocation.ArtifactLocation.URI != ".kubexm/logs/run1/InstallKubelet/node2.log" {
+		t.Errorf("expected the failure to point at the log file, got %+v", failure.Locations)
+	}
+	if run.Results[1].Level != "warning" {
+		t.Errorf("expected the run warning as a warning result, got %+v", run.Results[1])
+	}
+}
+
+func TestWriteReportAs_UnknownFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.txt")
+	if err := WriteReportAs(path, "yaml", failedRunResult(time.Now())); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written, got %v", err)
+	}
+}
@@ -21,6 +21,12 @@ type GraphExecutionResult struct {
 	Status      Status                 `json:"status,omitempty" yaml:"status,omitempty"`
 	NodeResults map[NodeID]*NodeResult `json:"nodeResults,omitempty" yaml:"nodeResults,omitempty"`
 	Message     string                 `json:"message,omitempty" yaml:"message,omitempty"`
+	// Preview holds the precheck outcome of every node when the graph was previewed before execution.
+	Preview Preview `json:"preview,omitempty" yaml:"preview,omitempty"`
+	// DryRun holds the files rendered by a dry run and how they differ from the hosts.
+	DryRun *DryRunOutput `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+	// Warnings lists failures of optional nodes, which do not fail the run.
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
 }
 
 func NewGraphExecutionResult(graphName string) *GraphExecutionResult {
@@ -38,9 +44,15 @@ func (ger *GraphExecutionResult) Finalize(status Status, message string) {
 	ger.Message = message
 }
 
+// HasWarnings reports whether the run completed with failures of optional nodes.
+func (ger *GraphExecutionResult) HasWarnings() bool {
+	return len(ger.Warnings) > 0
+}
+
 type NodeResult struct {
 	NodeName    string                 `json:"nodeName,omitempty" yaml:"nodeName,omitempty"`
 	StepName    string                 `json:"stepName,omitempty" yaml:"stepName,omitempty"`
+	StepType    string                 `json:"stepType,omitempty" yaml:"stepType,omitempty"`
 	Status      Status                 `json:"status,omitempty" yaml:"status,omitempty"`
 	StartTime   time.Time              `json:"startTime,omitempty" yaml:"startTime,omitempty"`
 	EndTime     time.Time              `json:"endTime,omitempty" yaml:"endTime,omitempty"`
@@ -77,6 +89,20 @@ func (nr *NodeResult) AggregateStatus() {
 	nr.Status = finalStatus
 }
 
+// Satisfied reports whether the node ran on at least one host and its precheck found every host
+// already in the desired state, so the node changed nothing.
+func (nr *NodeResult) Satisfied() bool {
+	if nr.Status != StatusSuccess || len(nr.HostResults) == 0 {
+		return false
+	}
+	for _, hr := range nr.HostResults {
+		if hr == nil || !hr.Satisfied {
+			return false
+		}
+	}
+	return true
+}
+
 type HostResult struct {
 	HostName  string                 `json:"hostName,omitempty" yaml:"hostName"`
 	Status    Status                 `json:"status,omitempty" yaml:"status,omitempty"`
@@ -86,6 +112,10 @@ type HostResult struct {
 	Metadata  map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	StartTime time.Time              `json:"startTime,omitempty" yaml:"startTime,omitempty"`
 	EndTime   time.Time              `json:"endTime,omitempty" yaml:"endTime,omitempty"`
+	// LogFile is the file that holds the log output of the step on this host, if one was kept.
+	LogFile string `json:"logFile,omitempty" yaml:"logFile,omitempty"`
+	// Satisfied is set when the precheck reported the step as already done, so Run was not called.
+	Satisfied bool `json:"satisfied,omitempty" yaml:"satisfied,omitempty"`
 }
 
 func NewHostResult(hostName string) *HostResult {
@@ -0,0 +1,72 @@
+package plan
+
+import (
+	"math"
+	"regexp"
+	"time"
+)
+
+// RetryPolicy controls how often a failed step is run again on a host before the node fails.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of runs, including the first one. Values below 2 disable retries.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// BaseDelay is the wait before the first retry; it doubles with every further attempt.
+	BaseDelay time.Duration `json:"baseDelay,omitempty"`
+	// MaxDelay caps the wait between two attempts. Zero means no cap.
+	MaxDelay time.Duration `json:"maxDelay,omitempty"`
+	// RetryOn lists regular expressions matched against the error of a failed run. Only matching
+	// errors are retried; when empty, DefaultRetryOn is used.
+	RetryOn []string `json:"retryOn,omitempty"`
+}
+
+// DefaultRetryOn matches the transient failures retried when a policy lists no RetryOn patterns:
+// dropped or stalled SSH connections, network timeouts and package manager lock contention.
+var DefaultRetryOn = []string{
+	`failed to connect to host`,
+	`connection to host .* stalled`,
+	`(?i)connection (reset|refused|timed out)`,
+	`(?i)broken pipe`,
+	`(?i)unexpected EOF`,
+	`(?i)i/o timeout`,
+	`(?i)no route to host`,
+	`(?i)could not get lock`,
+	`(?i)unable to acquire the dpkg`,
+	`(?i)holding the (yum|dnf) lock`,
+}
+
+// Backoff returns the wait before the given retry, where retry 1 is the second attempt.
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	if retry < 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < retry; i++ {
+		if (p.MaxDelay > 0 && delay >= p.MaxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Retryable reports whether err may be retried under the policy. Patterns that do not compile
+// never match.
+func (p *RetryPolicy) Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	patterns := p.RetryOn
+	if len(patterns) == 0 {
+		patterns = DefaultRetryOn
+	}
+	msg := err.Error()
+	for _, pattern := range patterns {
+		if matched, matchErr := regexp.MatchString(pattern, msg); matchErr == nil && matched {
+			return true
+		}
+	}
+	return false
+}
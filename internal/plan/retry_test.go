@@ -0,0 +1,45 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 6, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	expected := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for retry, want := range expected {
+		if got := p.Backoff(retry); got != want {
+			t.Errorf("Backoff(%d) = %s, expected %s", retry, got, want)
+		}
+	}
+	if got := (&RetryPolicy{BaseDelay: time.Second}).Backoff(40); got <= 0 {
+		t.Errorf("expected an uncapped backoff to stay positive, got %s", got)
+	}
+}
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		retryOn  []string
+		err      error
+		expected bool
+	}{
+		{name: "nil error", err: nil},
+		{name: "transient error without matchers", err: errors.New("ssh: unexpected EOF"), expected: true},
+		{name: "package lock without matchers", err: errors.New("E: Could not get lock /var/lib/dpkg/lock-frontend"), expected: true},
+		{name: "other error without matchers", err: errors.New("permission denied")},
+		{name: "matching pattern", retryOn: []string{`ssh: .*EOF`, `Could not get lock`}, err: errors.New("ssh: unexpected EOF"), expected: true},
+		{name: "no matching pattern", retryOn: []string{`Could not get lock`}, err: errors.New("permission denied")},
+		{name: "invalid pattern never matches", retryOn: []string{`(`}, err: errors.New("(")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &RetryPolicy{MaxAttempts: 3, RetryOn: tt.retryOn}
+			if got := p.Retryable(tt.err); got != tt.expected {
+				t.Errorf("Retryable(%v) = %v, expected %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SARIF report: every failed host of a node is an error result and every warning of the run a
+// warning result, so code scanning UIs list kubexm failures next to those of linters. Rules are
+// the steps (or nodes, when a node has no step) that produced a result.
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId,omitempty"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteSARIF writes the failures and warnings of r to w as a SARIF 2.1.0 log. A failed host
+// points at its log file, if it has one.
+func (r *RunReport) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "kubexm"}},
+		Results: []sarifResult{},
+	}
+	rules := make(map[string]bool)
+	addRule := func(id, description string) {
+		if !rules[id] {
+			rules[id] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+		}
+	}
+
+	for _, node := range r.Nodes {
+		if node.Status != StatusFailed {
+			continue
+		}
+		ruleID := node.Step
+		if ruleID == "" {
+			ruleID = node.Name
+		}
+		addRule(ruleID, fmt.Sprintf("Step %s of %s failed", ruleID, r.GraphName))
+
+		failedHosts := 0
+		for _, host := range node.Hosts {
+			if host.Status != StatusFailed {
+				continue
+			}
+			failedHosts++
+			result := sarifResult{
+				RuleID:     ruleID,
+				Level:      "error",
+				Message:    sarifMessage{Text: sarifText(fmt.Sprintf("%s failed on %s", node.Name, host.Host), host.Message)},
+				Properties: map[string]interface{}{"node": node.Name, "host": host.Host, "duration": host.Duration},
+			}
+			if host.LogFile != "" {
+				result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: host.LogFile}}}}
+			}
+			run.Results = append(run.Results, result)
+		}
+		if failedHosts == 0 {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:     ruleID,
+				Level:      "error",
+				Message:    sarifMessage{Text: sarifText(fmt.Sprintf("%s failed", node.Name), node.Message)},
+				Properties: map[string]interface{}{"node": node.Name, "duration": node.Duration},
+			})
+		}
+	}
+	for _, warning := range r.Warnings {
+		run.Results = append(run.Results, sarifResult{Level: "warning", Message: sarifMessage{Text: warning}})
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode SARIF report: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func sarifText(summary, message string) string {
+	if message == "" {
+		return summary
+	}
+	return summary + ": " + message
+}
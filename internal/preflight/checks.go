@@ -0,0 +1,332 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/util/cni"
+)
+
+// dataDir is the filesystem holding etcd data, container images and kubelet state.
+const dataDir = "/var/lib"
+
+// DefaultChecks returns the checks run before a cluster is planned.
+func DefaultChecks() []Check {
+	return []Check{
+		{Name: common.PreflightCheckCPU, Description: "CPU cores meet the role minimum", Run: checkCPU},
+		{Name: common.PreflightCheckMemory, Description: "Memory meets the role minimum", Run: checkMemory},
+		{Name: common.PreflightCheckKernelVersion, Description: "Kernel is recent enough", Run: checkKernelVersion},
+		{Name: common.PreflightCheckKernelModules, Description: "Required kernel modules are available", Run: checkKernelModules},
+		{Name: common.PreflightCheckSwap, Description: "Swap is off", Run: checkSwap},
+		{Name: common.PreflightCheckPorts, Description: "Ports used by Kubernetes are free or held by their component", Run: checkPorts},
+		{Name: common.PreflightCheckDiskSpace, Description: "Enough free space in " + dataDir, Run: checkDiskSpace},
+		{Name: common.PreflightCheckTimeSync, Description: "Clock is synchronized", Run: checkTimeSync},
+		{Name: common.PreflightCheckCgroup, Description: "cgroup version", Run: checkCgroup},
+		{Name: common.PreflightCheckSELinux, Description: "SELinux state", Run: checkSELinux},
+		{Name: common.PreflightCheckRuntime, Description: "No other container runtime is running", Run: checkRuntime},
+	}
+}
+
+func (t *Target) thresholds() (string, *v1alpha1.ResourceThresholds) {
+	if t.isControlPlane() {
+		return common.RoleControlPlane, t.preflightSpec().ControlPlaneResources
+	}
+	return common.RoleWorker, t.preflightSpec().WorkerResources
+}
+
+func checkCPU(ctx context.Context, t *Target) (Status, string, error) {
+	role, thresholds := t.thresholds()
+	cores := t.Facts.TotalCPU.Value()
+	if thresholds == nil {
+		return StatusPass, fmt.Sprintf("%d cores", cores), nil
+	}
+	return compareResource(cores, thresholds.MinCPUCores, thresholds.RecommendedCPUCores, role, "CPU cores")
+}
+
+func checkMemory(ctx context.Context, t *Target) (Status, string, error) {
+	role, thresholds := t.thresholds()
+	memoryMB := t.Facts.TotalMemory.Value() / (1024 * 1024)
+	if thresholds == nil {
+		return StatusPass, fmt.Sprintf("%d MB", memoryMB), nil
+	}
+	return compareResource(memoryMB, thresholds.MinMemoryMB, thresholds.RecommendedMemoryMB, role, "MB memory")
+}
+
+// compareResource fails below minimum and warns below recommended; nil thresholds are not checked.
+func compareResource[T int32 | uint64](found int64, minimum, recommended *T, role, unit string) (Status, string, error) {
+	switch {
+	case minimum != nil && found < int64(*minimum):
+		return StatusFail, fmt.Sprintf("%d %s, %s nodes require at least %d", found, unit, role, *minimum), nil
+	case recommended != nil && found < int64(*recommended):
+		return StatusWarn, fmt.Sprintf("%d %s, %d recommended for %s nodes", found, unit, *recommended, role), nil
+	}
+	return StatusPass, fmt.Sprintf("%d %s", found, unit), nil
+}
+
+func checkKernelVersion(ctx context.Context, t *Target) (Status, string, error) {
+	minimum, feature := common.MinKernelVersion, "Kubernetes"
+	if network := t.Cluster.Spec.Network; network != nil && network.Plugin == string(common.CNITypeCilium) {
+		minimum, feature = common.MinKernelVersionCilium, network.Plugin
+	}
+	switch {
+	case !runner.KernelAtLeast(t.Facts, minimum):
+		return StatusFail, fmt.Sprintf("kernel %s, %s requires at least %s", t.Facts.Kernel, feature, minimum), nil
+	case !runner.KernelAtLeast(t.Facts, common.RecommendedKernelVersion):
+		return StatusWarn, fmt.Sprintf("kernel %s, %s or newer is recommended", t.Facts.Kernel, common.RecommendedKernelVersion), nil
+	}
+	return StatusPass, "kernel " + t.Facts.Kernel, nil
+}
+
+// requiredKernelModules returns the modules the container runtime, kube-proxy and the CNI plugin load.
+func requiredKernelModules(cluster *v1alpha1.Cluster) []string {
+	modules := []string{common.KernelModuleBrNetfilter, "overlay"}
+	modules = append(modules, v1alpha1.KubeProxyKernelModules(cluster.Spec)...)
+	if cluster.Spec.Network != nil {
+		if provider, err := cni.NewProvider(cluster.Spec.Network); err == nil {
+			for _, module := range provider.RequiredKernelModules() {
+				if !slices.Contains(modules, module) {
+					modules = append(modules, module)
+				}
+			}
+		}
+	}
+	return modules
+}
+
+func checkKernelModules(ctx context.Context, t *Target) (Status, string, error) {
+	var missing []string
+	for _, module := range requiredKernelModules(t.Cluster) {
+		// A dry run succeeds for loaded, loadable and built-in modules alike.
+		if _, err := t.run(ctx, "modprobe --dry-run "+module); err != nil {
+			missing = append(missing, module)
+		}
+	}
+	if len(missing) > 0 {
+		return StatusFail, "missing modules: " + strings.Join(missing, ", "), nil
+	}
+	return StatusPass, "all modules available", nil
+}
+
+func checkSwap(ctx context.Context, t *Target) (Status, string, error) {
+	out, err := t.run(ctx, "cat /proc/swaps")
+	if err != nil {
+		return StatusFail, "", fmt.Errorf("failed to read /proc/swaps: %w", err)
+	}
+	devices := activeSwapDevices(out)
+	if len(devices) == 0 {
+		return StatusPass, "swap is off", nil
+	}
+	if disable := t.preflightSpec().DisableSwap; disable == nil || *disable {
+		return StatusWarn, fmt.Sprintf("swap is on (%s), it will be turned off", strings.Join(devices, ", ")), nil
+	}
+	return StatusFail, fmt.Sprintf("swap is on (%s) and preflight.disableSwap is false", strings.Join(devices, ", ")), nil
+}
+
+// activeSwapDevices parses /proc/swaps, whose first line is a header.
+func activeSwapDevices(procSwaps string) []string {
+	var devices []string
+	for i, line := range strings.Split(procSwaps, "\n") {
+		if fields := strings.Fields(line); i > 0 && len(fields) > 0 {
+			devices = append(devices, fields[0])
+		}
+	}
+	return devices
+}
+
+// componentPort is a port a Kubernetes component listens on. Process is the command name ss reports
+// for it, which the kernel truncates to 15 characters.
+type componentPort struct {
+	Port    int
+	Process string
+}
+
+// requiredPorts returns the ports the components installed on the target listen on.
+func (t *Target) requiredPorts() []componentPort {
+	ports := []componentPort{{common.KubeletDefaultPort, "kubelet"}}
+	if t.isControlPlane() {
+		apiServerPort := common.KubeAPIServerDefaultPort
+		if endpoint := t.Cluster.Spec.ControlPlaneEndpoint; endpoint != nil && endpoint.Port != 0 {
+			apiServerPort = endpoint.Port
+		}
+		ports = append(ports,
+			componentPort{apiServerPort, "kube-apiserver"},
+			componentPort{common.KubeControllerManagerDefaultPort, "kube-controller"},
+			componentPort{common.KubeSchedulerDefaultPort, "kube-scheduler"})
+	}
+	if t.Host.IsRole(common.RoleEtcd) {
+		ports = append(ports, componentPort{common.EtcdDefaultClientPort, "etcd"}, componentPort{common.EtcdDefaultPeerPort, "etcd"})
+	}
+	return ports
+}
+
+// checkPorts fails on ports taken by anything but the component that belongs there, so that
+// re-applying the configuration to a running cluster passes.
+func checkPorts(ctx context.Context, t *Target) (Status, string, error) {
+	out, err := t.runSudo(ctx, "ss -Htlnp")
+	if err != nil {
+		return StatusWarn, "cannot list listening ports: " + err.Error(), nil
+	}
+	listening := listeningPorts(out)
+	var busy, owned []string
+	for _, required := range t.requiredPorts() {
+		process, ok := listening[required.Port]
+		switch {
+		case !ok:
+		case process == required.Process:
+			owned = append(owned, fmt.Sprintf("%d (%s)", required.Port, process))
+		default:
+			busy = append(busy, strconv.Itoa(required.Port))
+		}
+	}
+	if len(busy) > 0 {
+		return StatusFail, "ports in use: " + strings.Join(busy, ", "), nil
+	}
+	if len(owned) > 0 {
+		return StatusPass, "ports held by cluster components: " + strings.Join(owned, ", "), nil
+	}
+	return StatusPass, "all ports free", nil
+}
+
+// listeningPorts parses the output of "ss -Htlnp", whose fourth column is the local address and
+// whose last column names the owning process as users:(("name",pid=...,fd=...)). The owner is
+// empty when ss cannot tell.
+func listeningPorts(ssOutput string) map[int]string {
+	ports := make(map[int]string)
+	for _, line := range strings.Split(ssOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		address := fields[3]
+		port, err := strconv.Atoi(address[strings.LastIndex(address, ":")+1:])
+		if err != nil {
+			continue
+		}
+		process := ""
+		if _, users, found := strings.Cut(line, `users:(("`); found {
+			process, _, _ = strings.Cut(users, `"`)
+		}
+		if _, seen := ports[port]; !seen || ports[port] == "" {
+			ports[port] = process
+		}
+	}
+	return ports
+}
+
+func checkDiskSpace(ctx context.Context, t *Target) (Status, string, error) {
+	out, err := t.run(ctx, "df -Pk "+dataDir)
+	if err != nil {
+		return StatusFail, "", fmt.Errorf("failed to check free space of %s: %w", dataDir, err)
+	}
+	availableGB, err := availableDiskGB(out)
+	if err != nil {
+		return StatusFail, "", err
+	}
+	if availableGB < common.DefaultMinDiskGB {
+		return StatusFail, fmt.Sprintf("%d GB free in %s, at least %d GB required", availableGB, dataDir, common.DefaultMinDiskGB), nil
+	}
+	return StatusPass, fmt.Sprintf("%d GB free in %s", availableGB, dataDir), nil
+}
+
+// availableDiskGB parses the output of "df -Pk", whose fourth column is the available space in KB.
+func availableDiskGB(dfOutput string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(dfOutput), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected df output: %q", dfOutput)
+	}
+	availableKB, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q", dfOutput)
+	}
+	return availableKB / (1024 * 1024), nil
+}
+
+func checkTimeSync(ctx context.Context, t *Target) (Status, string, error) {
+	if synced, err := t.run(ctx, "timedatectl show --property=NTPSynchronized --value"); err == nil {
+		if synced == "yes" {
+			return StatusPass, "clock synchronized", nil
+		}
+		return StatusWarn, "clock is not synchronized, certificates and etcd leases may be affected", nil
+	}
+	if _, err := t.run(ctx, "chronyc -n tracking"); err == nil {
+		return StatusPass, "chrony is tracking", nil
+	}
+	return StatusWarn, "unable to determine time synchronization", nil
+}
+
+func checkCgroup(ctx context.Context, t *Target) (Status, string, error) {
+	fsType, err := t.run(ctx, "stat -fc %T /sys/fs/cgroup/")
+	if err != nil {
+		return StatusFail, "", fmt.Errorf("failed to detect the cgroup version: %w", err)
+	}
+	if fsType == "cgroup2fs" {
+		return StatusPass, "cgroup v2", nil
+	}
+	return StatusWarn, "cgroup v1, which Kubernetes only maintains and will drop", nil
+}
+
+func checkSELinux(ctx context.Context, t *Target) (Status, string, error) {
+	mode, err := t.run(ctx, "getenforce")
+	if err != nil || !strings.EqualFold(mode, common.EnforceSELinuxMode) {
+		return StatusPass, "not enforcing", nil
+	}
+	if disable := t.preflightSpec().DisableSelinux; disable == nil || *disable {
+		return StatusWarn, "enforcing, it will be switched to " + common.PermissiveSELinuxMode, nil
+	}
+	return StatusWarn, "enforcing, the container runtime needs matching SELinux policies", nil
+}
+
+// runtimeServices maps each container runtime to its service; docker and cri-dockerd run on top of containerd.
+var runtimeServices = []struct {
+	Runtime common.ContainerRuntimeType
+	Service string
+}{
+	{common.RuntimeTypeContainerd, common.ContainerdServiceName},
+	{common.RuntimeTypeDocker, common.DockerServiceName},
+	{common.RuntimeTypeCRIO, common.CrioServiceName},
+	{common.RuntimeTypeIsula, common.IsuladServiceName},
+}
+
+func checkRuntime(ctx context.Context, t *Target) (Status, string, error) {
+	desired := common.RuntimeTypeContainerd
+	if k8s := t.Cluster.Spec.Kubernetes; k8s != nil && k8s.ContainerRuntime != nil && k8s.ContainerRuntime.Type != "" {
+		desired = k8s.ContainerRuntime.Type
+	}
+	var active []common.ContainerRuntimeType
+	for _, rs := range runtimeServices {
+		isActive, err := t.Runner.IsServiceActive(ctx, t.Conn, t.Facts, rs.Service)
+		if err != nil {
+			return StatusFail, "", fmt.Errorf("failed to determine status of %s: %w", rs.Service, err)
+		}
+		if isActive {
+			active = append(active, rs.Runtime)
+		}
+	}
+	return evaluateRuntimes(desired, active)
+}
+
+// evaluateRuntimes fails when a runtime other than desired is running. containerd is part of a docker
+// installation, so it does not conflict with docker.
+func evaluateRuntimes(desired common.ContainerRuntimeType, active []common.ContainerRuntimeType) (Status, string, error) {
+	var conflicting []string
+	for _, rt := range active {
+		if rt == desired || (desired == common.RuntimeTypeDocker && rt == common.RuntimeTypeContainerd) {
+			continue
+		}
+		conflicting = append(conflicting, string(rt))
+	}
+	switch {
+	case len(conflicting) > 0:
+		return StatusFail, fmt.Sprintf("%s running, but the cluster uses %s", strings.Join(conflicting, ", "), desired), nil
+	case len(active) > 0:
+		return StatusPass, fmt.Sprintf("%s already running", desired), nil
+	}
+	return StatusPass, "no container runtime running", nil
+}
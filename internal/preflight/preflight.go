@@ -0,0 +1,236 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// Status is the outcome of one check on one host.
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+	// StatusIgnored is a failure of a check listed in --ignore-preflight-errors.
+	StatusIgnored Status = "IGNORED"
+	// StatusSkipped is a check listed in spec.preflight.skipChecks, which is not run at all.
+	StatusSkipped Status = "SKIPPED"
+)
+
+// Target is a host a check runs against, with the connection and facts gathered when the runtime was built.
+type Target struct {
+	Host    remotefw.Host
+	Conn    connector.Connector
+	Runner  runner.Runner
+	Facts   *runner.Facts
+	Cluster *v1alpha1.Cluster
+}
+
+// run executes cmd on the target and returns its trimmed stdout.
+func (t *Target) run(ctx context.Context, cmd string) (string, error) {
+	return t.exec(ctx, cmd, false)
+}
+
+// runSudo is run for commands that need root, such as looking up the owners of sockets.
+func (t *Target) runSudo(ctx context.Context, cmd string) (string, error) {
+	return t.exec(ctx, cmd, true)
+}
+
+func (t *Target) exec(ctx context.Context, cmd string, sudo bool) (string, error) {
+	result, err := t.Runner.Run(ctx, t.Conn, cmd, sudo)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+func (t *Target) isControlPlane() bool {
+	return t.Host.IsRole(common.RoleMaster) || t.Host.IsRole(common.RoleControlPlane)
+}
+
+func (t *Target) preflightSpec() *v1alpha1.Preflight {
+	if t.Cluster == nil || t.Cluster.Spec == nil || t.Cluster.Spec.Preflight == nil {
+		return &v1alpha1.Preflight{}
+	}
+	return t.Cluster.Spec.Preflight
+}
+
+// Check is a single preflight check. Run returns the status and a short message for the table;
+// an error means the check could not be evaluated and counts as a failure.
+type Check struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, t *Target) (Status, string, error)
+}
+
+// Result is the outcome of one check on one host.
+type Result struct {
+	Host    string
+	Check   string
+	Status  Status
+	Message string
+}
+
+// Options control which checks run and which failures are tolerated.
+type Options struct {
+	// SkipChecks are not run at all.
+	SkipChecks []string
+	// IgnoreErrors turns failures of these checks into IGNORED results. "all" ignores every check.
+	IgnoreErrors []string
+}
+
+func (o Options) skipped(check string) bool {
+	return slices.Contains(o.SkipChecks, check) || slices.Contains(o.SkipChecks, common.PreflightCheckAll)
+}
+
+func (o Options) ignored(check string) bool {
+	return slices.Contains(o.IgnoreErrors, check) || slices.Contains(o.IgnoreErrors, common.PreflightCheckAll)
+}
+
+// ValidateCheckNames returns an error naming the entries of names that are not preflight checks.
+func ValidateCheckNames(names []string) error {
+	var unknown []string
+	for _, name := range names {
+		if !slices.Contains(common.SupportedChecks, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown preflight checks %s, must be one of %s", strings.Join(unknown, ", "), strings.Join(common.SupportedChecks, ", "))
+	}
+	return nil
+}
+
+// Report holds the results of a preflight run, ordered by host and then by check.
+type Report struct {
+	Results []Result
+}
+
+// Failures returns the results that fail the run.
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Err returns an error listing every failed check, or nil if the run passed.
+func (r *Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(failures))
+	for _, f := range failures {
+		lines = append(lines, fmt.Sprintf("[%s] %s: %s", f.Host, f.Check, f.Message))
+	}
+	return fmt.Errorf("%d preflight check(s) failed, use --ignore-preflight-errors to continue anyway:\n%s",
+		len(failures), strings.Join(lines, "\n"))
+}
+
+// PrintTable writes one row per host and check to w.
+func (r *Report) PrintTable(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"HOST", "CHECK", "STATUS", "MESSAGE"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoMergeCells(true)
+	table.SetRowLine(false)
+	for _, result := range r.Results {
+		table.Append([]string{result.Host, result.Check, string(result.Status), result.Message})
+	}
+	table.Render()
+}
+
+// Run executes checks on every target. Hosts are checked in parallel and the checks of one host in order.
+func Run(ctx context.Context, targets []*Target, checks []Check, opts Options) *Report {
+	perHost := make([][]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target *Target) {
+			defer wg.Done()
+			perHost[i] = runHost(ctx, target, checks, opts)
+		}(i, target)
+	}
+	wg.Wait()
+
+	report := &Report{}
+	for _, results := range perHost {
+		report.Results = append(report.Results, results...)
+	}
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].Host < report.Results[j].Host
+	})
+	return report
+}
+
+func runHost(ctx context.Context, target *Target, checks []Check, opts Options) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		result := Result{Host: target.Host.GetName(), Check: check.Name}
+		if opts.skipped(check.Name) {
+			result.Status, result.Message = StatusSkipped, "listed in preflight.skipChecks"
+			results = append(results, result)
+			continue
+		}
+
+		status, message, err := check.Run(ctx, target)
+		if err != nil {
+			status, message = StatusFail, err.Error()
+		}
+		if status == StatusFail && opts.ignored(check.Name) {
+			status = StatusIgnored
+		}
+		result.Status, result.Message = status, message
+		results = append(results, result)
+	}
+	return results
+}
+
+// TargetsFromContext returns a target for every host of the runtime, using the connections and facts
+// gathered while it was built.
+func TargetsFromContext(rtCtx *runtime.Context) ([]*Target, error) {
+	hosts := rtCtx.GetHostsByRole("")
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].GetName() < hosts[j].GetName() })
+
+	targets := make([]*Target, 0, len(hosts))
+	for _, host := range hosts {
+		conn, err := runtime.ForHost(rtCtx, host).GetCurrentHostConnector()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connector for host %s: %w", host.GetName(), err)
+		}
+		facts, err := rtCtx.GetHostFacts(host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get facts for host %s: %w", host.GetName(), err)
+		}
+		targets = append(targets, &Target{
+			Host:    host,
+			Conn:    conn,
+			Runner:  rtCtx.GetRunner(),
+			Facts:   facts,
+			Cluster: rtCtx.GetClusterConfig(),
+		})
+	}
+	return targets, nil
+}
@@ -0,0 +1,174 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+// fakeRunner answers Run with canned output per command; commands without output fail.
+type fakeRunner struct {
+	runner.Runner
+	outputs map[string]string
+}
+
+func (r *fakeRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	out, ok := r.outputs[cmd]
+	if !ok {
+		return nil, fmt.Errorf("command failed: %s", cmd)
+	}
+	return &runner.CommandResult{Stdout: out, Success: true}, nil
+}
+
+func newTarget(name string, roles []string, outputs map[string]string) *Target {
+	roleTable := make(map[string]bool)
+	for _, role := range roles {
+		roleTable[role] = true
+	}
+	return &Target{
+		Host:    connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Roles: roles, RoleTable: roleTable}),
+		Runner:  &fakeRunner{outputs: outputs},
+		Facts:   &runner.Facts{Kernel: "5.15.0-91-generic"},
+		Cluster: &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Preflight: &v1alpha1.Preflight{}}},
+	}
+}
+
+func TestRun(t *testing.T) {
+	checks := []Check{
+		{Name: common.PreflightCheckSwap, Run: func(ctx context.Context, t *Target) (Status, string, error) {
+			if t.Host.GetName() == "node1" {
+				return StatusFail, "swap is on", nil
+			}
+			return StatusPass, "swap is off", nil
+		}},
+		{Name: common.PreflightCheckPorts, Run: func(ctx context.Context, t *Target) (Status, string, error) {
+			return StatusFail, "", fmt.Errorf("ss not found")
+		}},
+		{Name: common.PreflightCheckCgroup, Run: func(ctx context.Context, t *Target) (Status, string, error) {
+			return StatusWarn, "cgroup v1", nil
+		}},
+		{Name: common.PreflightCheckSELinux, Run: func(ctx context.Context, t *Target) (Status, string, error) {
+			panic("skipped checks must not run")
+		}},
+	}
+	targets := []*Target{newTarget("node1", nil, nil), newTarget("master1", []string{common.RoleMaster}, nil)}
+	report := Run(context.Background(), targets, checks, Options{
+		SkipChecks:   []string{common.PreflightCheckSELinux},
+		IgnoreErrors: []string{common.PreflightCheckPorts},
+	})
+
+	if len(report.Results) != 8 || report.Results[0].Host != "master1" || report.Results[4].Host != "node1" {
+		t.Fatalf("expected the results grouped by host in name order, got %+v", report.Results)
+	}
+	want := []Status{StatusFail, StatusIgnored, StatusWarn, StatusSkipped}
+	for i, status := range want {
+		if got := report.Results[4+i].Status; got != status {
+			t.Errorf("node1 %s: expected %s, got %s", report.Results[4+i].Check, status, got)
+		}
+	}
+	if report.Results[5].Message != "ss not found" {
+		t.Errorf("expected the check error as message, got %q", report.Results[5].Message)
+	}
+
+	err := report.Err()
+	if err == nil || !strings.Contains(err.Error(), "[node1] swap: swap is on") || strings.Contains(err.Error(), "master1") {
+		t.Errorf("expected only the swap failure of node1, got %v", err)
+	}
+	var table bytes.Buffer
+	report.PrintTable(&table)
+	if !strings.Contains(table.String(), "IGNORED") || !strings.Contains(table.String(), "cgroup v1") {
+		t.Errorf("unexpected table:\n%s", table.String())
+	}
+
+	report = Run(context.Background(), targets, checks, Options{IgnoreErrors: []string{common.PreflightCheckAll}, SkipChecks: []string{common.PreflightCheckSELinux}})
+	if err := report.Err(); err != nil {
+		t.Errorf("expected 'all' to ignore every failure, got %v", err)
+	}
+}
+
+func TestValidateCheckNames(t *testing.T) {
+	if err := ValidateCheckNames([]string{"ports", "all"}); err != nil {
+		t.Errorf("expected known checks to be accepted, got %v", err)
+	}
+	if err := ValidateCheckNames([]string{"swap", "port"}); err == nil || !strings.Contains(err.Error(), "unknown preflight checks port") {
+		t.Errorf("expected 'port' to be rejected, got %v", err)
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	ss := "LISTEN 0 4096 127.0.0.1:10248 0.0.0.0:*\nLISTEN 0 4096 *:2379 *:* users:((\"docker-proxy\",pid=812,fd=4))\nLISTEN 0 128 [::]:22 [::]:*"
+	master := newTarget("master1", []string{common.RoleMaster, common.RoleEtcd}, map[string]string{"ss -Htlnp": ss})
+	status, message, _ := checkPorts(context.Background(), master)
+	if status != StatusFail || message != "ports in use: 2379" {
+		t.Errorf("expected the etcd client port to be reported, got %s %q", status, message)
+	}
+	worker := newTarget("node1", []string{common.RoleWorker}, map[string]string{"ss -Htlnp": ss})
+	if status, _, _ := checkPorts(context.Background(), worker); status != StatusPass {
+		t.Errorf("workers do not run etcd, got %s", status)
+	}
+
+	running := "LISTEN 0 4096 *:10250 *:* users:((\"kubelet\",pid=901,fd=20))\nLISTEN 0 4096 *:2379 *:* users:((\"etcd\",pid=700,fd=7))"
+	member := newTarget("master1", []string{common.RoleEtcd}, map[string]string{"ss -Htlnp": running})
+	status, message, _ = checkPorts(context.Background(), member)
+	if status != StatusPass || message != "ports held by cluster components: 10250 (kubelet), 2379 (etcd)" {
+		t.Errorf("expected ports held by the cluster's own components to pass, got %s %q", status, message)
+	}
+}
+
+func TestCheckSwapAndKernelModules(t *testing.T) {
+	target := newTarget("node1", nil, map[string]string{
+		"cat /proc/swaps":             "Filename Type Size Used Priority\n/dev/dm-1 partition 2097148 0 -2",
+		"modprobe --dry-run overlay":  "",
+		"modprobe --dry-run ip_vs_rr": "",
+	})
+	if status, message, _ := checkSwap(context.Background(), target); status != StatusWarn || !strings.Contains(message, "/dev/dm-1") {
+		t.Errorf("expected swap to be turned off later, got %s %q", status, message)
+	}
+	disable := false
+	target.Cluster.Spec.Preflight.DisableSwap = &disable
+	if status, _, _ := checkSwap(context.Background(), target); status != StatusFail {
+		t.Errorf("expected swap to fail when it is kept on, got %s", status)
+	}
+
+	target.Cluster.Spec.Kubernetes = &v1alpha1.Kubernetes{KubeProxy: &v1alpha1.KubeProxyConfig{Mode: common.KubeProxyModeIPTables}}
+	status, message, _ := checkKernelModules(context.Background(), target)
+	if status != StatusFail || message != "missing modules: br_netfilter" {
+		t.Errorf("expected only br_netfilter to be missing, got %s %q", status, message)
+	}
+}
+
+func TestAvailableDiskGB(t *testing.T) {
+	df := "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 104806400 52428800 52377600 51% /"
+	if gb, err := availableDiskGB(df); err != nil || gb != 49 {
+		t.Errorf("expected 49 GB, got %d (err %v)", gb, err)
+	}
+	if _, err := availableDiskGB("df: /var/lib: No such file or directory"); err == nil {
+		t.Error("expected an error for unexpected output")
+	}
+}
+
+func TestEvaluateRuntimes(t *testing.T) {
+	tests := []struct {
+		desired common.ContainerRuntimeType
+		active  []common.ContainerRuntimeType
+		want    Status
+	}{
+		{common.RuntimeTypeContainerd, nil, StatusPass},
+		{common.RuntimeTypeContainerd, []common.ContainerRuntimeType{common.RuntimeTypeContainerd}, StatusPass},
+		{common.RuntimeTypeDocker, []common.ContainerRuntimeType{common.RuntimeTypeContainerd, common.RuntimeTypeDocker}, StatusPass},
+		{common.RuntimeTypeContainerd, []common.ContainerRuntimeType{common.RuntimeTypeContainerd, common.RuntimeTypeDocker}, StatusFail},
+		{common.RuntimeTypeCRIO, []common.ContainerRuntimeType{common.RuntimeTypeContainerd}, StatusFail},
+	}
+	for _, tt := range tests {
+		if got, message, _ := evaluateRuntimes(tt.desired, tt.active); got != tt.want {
+			t.Errorf("desired %s with %v active: expected %s, got %s (%s)", tt.desired, tt.active, tt.want, got, message)
+		}
+	}
+}
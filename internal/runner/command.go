@@ -56,6 +56,30 @@ func (r *defaultRunner) OriginRun(ctx context.Context, conn connector.Connector,
 	return string(stdout), string(stderr), nil
 }
 
+// ExecWithExitCode runs cmd and reports its exit code even when it is zero. A non-zero exit code is not
+// treated as an error, so callers can interpret it themselves (e.g. diff exits with 1 when inputs differ);
+// err is only set when the command could not be run or its exit code is unknown, in which case exitCode is -1.
+func (r *defaultRunner) ExecWithExitCode(ctx context.Context, conn connector.Connector, cmd string, opts *connector.ExecOptions) (stdout, stderr string, exitCode int, err error) {
+	if conn == nil {
+		return "", "", -1, fmt.Errorf("connector cannot be nil")
+	}
+	if opts == nil {
+		opts = &connector.ExecOptions{}
+	}
+
+	stdoutBytes, stderrBytes, execErr := conn.Exec(ctx, cmd, opts)
+	stdout, stderr = string(stdoutBytes), string(stderrBytes)
+	if execErr == nil {
+		return stdout, stderr, 0, nil
+	}
+
+	var cmdErr *connector.CommandError
+	if errors.As(execErr, &cmdErr) && cmdErr.ExitCode >= 0 {
+		return stdout, stderr, cmdErr.ExitCode, nil
+	}
+	return stdout, stderr, -1, fmt.Errorf("command '%s' failed: %w", cmd, execErr)
+}
+
 func (r *defaultRunner) MustRun(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*CommandResult, error) {
 	result, err := r.Run(ctx, conn, cmd, sudo)
 	if err != nil {
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+func TestExecWithExitCode(t *testing.T) {
+	ctx := context.Background()
+	conn, err := connector.NewLocalConnector()
+	if err != nil {
+		t.Fatalf("Failed to create LocalConnector: %v", err)
+	}
+	if err := conn.Connect(ctx, connector.ConnectionCfg{}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	r := NewRunner()
+
+	tests := []struct {
+		name         string
+		cmd          string
+		expectedCode int
+		expectedOut  string
+	}{
+		{name: "success", cmd: "echo same", expectedCode: 0, expectedOut: "same"},
+		{name: "exit code 1", cmd: "echo differs; exit 1", expectedCode: 1, expectedOut: "differs"},
+		{name: "exit code 2", cmd: "echo broken >&2; exit 2", expectedCode: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode, err := r.ExecWithExitCode(ctx, conn, tt.cmd, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exitCode != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d", tt.expectedCode, exitCode)
+			}
+			if got := strings.TrimSpace(stdout); got != tt.expectedOut {
+				t.Errorf("expected stdout %q, got %q", tt.expectedOut, got)
+			}
+		})
+	}
+
+	if _, _, exitCode, err := r.ExecWithExitCode(ctx, nil, "true", nil); err == nil || exitCode != -1 {
+		t.Errorf("expected an error and exit code -1 for a nil connector, got %d, %v", exitCode, err)
+	}
+}
+
+type fakeDiffConnector struct {
+	connector.Connector
+	exitCode int
+	stdout   string
+	commands []string
+}
+
+func (c *fakeDiffConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.commands = append(c.commands, cmd)
+	if !strings.HasPrefix(cmd, "kubectl diff") || c.exitCode == 0 {
+		return []byte(c.stdout), nil, nil
+	}
+	return []byte(c.stdout), []byte("error"), &connector.CommandError{Cmd: cmd, ExitCode: c.exitCode, Stdout: c.stdout}
+}
+
+func (c *fakeDiffConnector) WriteFile(ctx context.Context, content []byte, destPath string, opts *connector.FileTransferOptions) error {
+	return nil
+}
+
+func TestKubectlDiff(t *testing.T) {
+	tests := []struct {
+		name            string
+		exitCode        int
+		expectedHasDiff bool
+		expectErr       bool
+	}{
+		{name: "no differences", exitCode: 0},
+		{name: "differences found", exitCode: 1, expectedHasDiff: true},
+		{name: "diff failed", exitCode: 2, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeDiffConnector{exitCode: tt.exitCode, stdout: "-  replicas: 1\n+  replicas: 3\n"}
+			diff, hasDiff, err := NewRunner().KubectlDiff(context.Background(), conn, KubectlDiffOptions{
+				FileContent:    "kind: Deployment",
+				KubeconfigPath: "/etc/kubernetes/admin.conf",
+			})
+			if tt.expectErr {
+				if err == nil || !strings.Contains(err.Error(), "exit code 2") {
+					t.Fatalf("expected an exit code 2 error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if hasDiff != tt.expectedHasDiff {
+				t.Errorf("expected hasDiff %v, got %v", tt.expectedHasDiff, hasDiff)
+			}
+			if diff == "" {
+				t.Error("expected the diff output to be returned")
+			}
+			if !strings.Contains(conn.commands[0], "--kubeconfig /etc/kubernetes/admin.conf") {
+				t.Errorf("expected the kubeconfig to be passed, got %q", conn.commands[0])
+			}
+		})
+	}
+}
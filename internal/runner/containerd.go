@@ -10,11 +10,10 @@ import (
 	"github.com/mensylisir/kubexm/internal/logger"
 	"github.com/mensylisir/kubexm/internal/templates"
 	"github.com/mensylisir/kubexm/internal/tool"
-	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
+	"net/url"
 	"os"
 	"path/filepath"
-	"reflect"
 	"regexp"
 	"strings"
 	"text/template"
@@ -507,12 +506,11 @@ func (r *defaultRunner) GetContainerdConfig(ctx context.Context, conn connector.
 		return &ContainerdConfigOptions{}, nil
 	}
 
-	var opts ContainerdConfigOptions
-	if err := toml.Unmarshal(configContentBytes, &opts); err != nil {
+	cfg, err := parseContainerdConfig(configContentBytes)
+	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse containerd config TOML from %s", containerdConfigPath)
 	}
-
-	return &opts, nil
+	return cfg.options()
 }
 
 func (r *defaultRunner) EnsureDefaultContainerdConfig(ctx context.Context, conn connector.Connector, facts *Facts) error {
@@ -595,100 +593,11 @@ func (r *defaultRunner) ConfigureContainerd(ctx context.Context, conn connector.
 		return errors.Wrapf(err, "failed to read containerd config at %s for merging", containerdConfigPath)
 	}
 
-	var modifiedContentBytes = currentContentBytes
-	var modified bool = false
-
-	updateValue := func(path string, value interface{}) error {
-		if value == nil {
-			return nil
-		}
-		v := reflect.ValueOf(value)
-		if v.Kind() == reflect.Ptr {
-			if v.IsNil() {
-				return nil
-			}
-			value = v.Elem().Interface()
-		}
-
-		newBytes, err := tool.SetTomlValue(modifiedContentBytes, path, value)
-		if err != nil {
-			return errors.Wrapf(err, "failed to set containerd config value for path '%s'", path)
-		}
-
-		if !bytes.Equal(modifiedContentBytes, newBytes) {
-			modifiedContentBytes = newBytes
-			modified = true
-		}
-		return nil
-	}
-
-	if err := updateValue("version", opts.Version); err != nil {
-		return err
-	}
-	if err := updateValue("root", opts.Root); err != nil {
-		return err
-	}
-	if err := updateValue("state", opts.State); err != nil {
-		return err
-	}
-	if err := updateValue("oom_score", opts.OOMScore); err != nil {
-		return err
-	}
-	if err := updateValue("disabled_plugins", opts.DisabledPlugins); err != nil {
+	modifiedContentBytes, err := r.RenderContainerdConfig(opts, currentContentBytes)
+	if err != nil {
 		return err
 	}
-
-	if opts.GRPC != nil {
-		if err := updateValue("grpc.address", opts.GRPC.Address); err != nil {
-			return err
-		}
-		if err := updateValue("grpc.uid", opts.GRPC.UID); err != nil {
-			return err
-		}
-		if err := updateValue("grpc.gid", opts.GRPC.GID); err != nil {
-			return err
-		}
-		if err := updateValue("grpc.max_recv_message_size", opts.GRPC.MaxRecvMsgSize); err != nil {
-			return err
-		}
-		if err := updateValue("grpc.max_send_message_size", opts.GRPC.MaxSendMsgSize); err != nil {
-			return err
-		}
-	}
-
-	if opts.Metrics != nil {
-		if err := updateValue("metrics.address", opts.Metrics.Address); err != nil {
-			return err
-		}
-		if err := updateValue("metrics.grpc_histogram", opts.Metrics.GRPCHistogram); err != nil {
-			return err
-		}
-	}
-
-	if opts.PluginConfigs != nil {
-		for pluginName, pluginConfig := range *opts.PluginConfigs {
-			configMap, ok := pluginConfig.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			for key, val := range configMap {
-				path := fmt.Sprintf(`plugins."%s".%s`, pluginName, key)
-				if err := updateValue(path, val); err != nil {
-					return err
-				}
-			}
-		}
-	}
-
-	if opts.RegistryMirrors != nil {
-		for registry, endpoints := range opts.RegistryMirrors {
-			path := fmt.Sprintf(`plugins."io.containerd.grpc.v1.cri".registry.mirrors."%s".endpoint`, registry)
-			if err := updateValue(path, endpoints); err != nil {
-				return err
-			}
-		}
-	}
+	modified := !bytes.Equal(currentContentBytes, modifiedContentBytes)
 
 	if modified {
 		if err := r.WriteFile(ctx, conn, modifiedContentBytes, containerdConfigPath, "0644", true); err != nil {
@@ -720,6 +629,72 @@ func (r *defaultRunner) ConfigureContainerd(ctx context.Context, conn connector.
 	return nil
 }
 
+// RenderContainerdConfig merges opts into the current containerd config and validates the
+// result without touching any host, so a change can be previewed before it is applied. Settings
+// not covered by opts are preserved, and rendering the same options twice yields the same bytes.
+func (r *defaultRunner) RenderContainerdConfig(opts ContainerdConfigOptions, current []byte) ([]byte, error) {
+	if err := validateContainerdConfigOptions(opts); err != nil {
+		return nil, errors.Wrap(err, "invalid containerd config options")
+	}
+
+	modifiedContentBytes, err := renderTomlConfig(current, func(cfg *tomlConfig) error {
+		return (&containerdConfig{tomlConfig: cfg}).apply(opts)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge containerd config options")
+	}
+
+	if err := validateContainerdConfigContent(modifiedContentBytes); err != nil {
+		return nil, errors.Wrap(err, "rendered containerd config is invalid")
+	}
+	return modifiedContentBytes, nil
+}
+
+// validateContainerdConfigOptions rejects option values that containerd would refuse at startup.
+func validateContainerdConfigOptions(opts ContainerdConfigOptions) error {
+	if opts.Version != nil && (*opts.Version < 1 || *opts.Version > 3) {
+		return fmt.Errorf("unsupported config version %d, must be 1, 2 or 3", *opts.Version)
+	}
+	if opts.Root != nil && !filepath.IsAbs(*opts.Root) {
+		return fmt.Errorf("root %q must be an absolute path", *opts.Root)
+	}
+	if opts.State != nil && !filepath.IsAbs(*opts.State) {
+		return fmt.Errorf("state %q must be an absolute path", *opts.State)
+	}
+	if opts.OOMScore != nil && (*opts.OOMScore < -1000 || *opts.OOMScore > 1000) {
+		return fmt.Errorf("oom_score %d must be between -1000 and 1000", *opts.OOMScore)
+	}
+	if opts.GRPC != nil && opts.GRPC.Address != nil && !filepath.IsAbs(*opts.GRPC.Address) {
+		return fmt.Errorf("grpc.address %q must be an absolute socket path", *opts.GRPC.Address)
+	}
+	for registry, endpoints := range opts.RegistryMirrors {
+		if strings.TrimSpace(registry) == "" {
+			return fmt.Errorf("registry mirror name cannot be empty")
+		}
+		for _, endpoint := range endpoints {
+			u, err := url.Parse(endpoint)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("mirror endpoint %q for registry %s must be an http(s) URL", endpoint, registry)
+			}
+		}
+	}
+	return nil
+}
+
+// validateContainerdConfigContent checks that content is well-formed TOML whose top-level
+// settings have the types containerd expects.
+func validateContainerdConfigContent(content []byte) error {
+	cfg, err := parseContainerdConfig(content)
+	if err != nil {
+		return err
+	}
+	opts, err := cfg.options()
+	if err != nil {
+		return err
+	}
+	return validateContainerdConfigOptions(*opts)
+}
+
 func (r *defaultRunner) ConfigureCrictl(ctx context.Context, conn connector.Connector, opts CrictlConfigOptions, configFilePath string) error {
 	if conn == nil {
 		return errors.New("connector cannot be nil")
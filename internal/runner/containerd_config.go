@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// containerdConfig is a parsed containerd config.toml. Plugin names such as
+// io.containerd.grpc.v1.cri are single path segments, so they are never split on their dots.
+type containerdConfig struct {
+	*tomlConfig
+}
+
+func parseContainerdConfig(content []byte) (*containerdConfig, error) {
+	cfg, err := parseTomlConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	return &containerdConfig{tomlConfig: cfg}, nil
+}
+
+// apply merges opts into the config. Fields left nil in opts are not touched.
+func (c *containerdConfig) apply(opts ContainerdConfigOptions) error {
+	type setting struct {
+		path  []string
+		value interface{}
+	}
+	settings := []setting{
+		{[]string{"version"}, opts.Version},
+		{[]string{"root"}, opts.Root},
+		{[]string{"state"}, opts.State},
+		{[]string{"oom_score"}, opts.OOMScore},
+		{[]string{"disabled_plugins"}, opts.DisabledPlugins},
+	}
+	if opts.GRPC != nil {
+		settings = append(settings,
+			setting{[]string{"grpc", "address"}, opts.GRPC.Address},
+			setting{[]string{"grpc", "uid"}, opts.GRPC.UID},
+			setting{[]string{"grpc", "gid"}, opts.GRPC.GID},
+			setting{[]string{"grpc", "max_recv_message_size"}, opts.GRPC.MaxRecvMsgSize},
+			setting{[]string{"grpc", "max_send_message_size"}, opts.GRPC.MaxSendMsgSize},
+		)
+	}
+	if opts.Metrics != nil {
+		settings = append(settings,
+			setting{[]string{"metrics", "address"}, opts.Metrics.Address},
+			setting{[]string{"metrics", "grpc_histogram"}, opts.Metrics.GRPCHistogram},
+		)
+	}
+	for _, s := range settings {
+		v := reflect.ValueOf(s.value)
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			continue
+		}
+		if err := c.set(v.Elem().Interface(), s.path...); err != nil {
+			return err
+		}
+	}
+
+	if opts.PluginConfigs != nil {
+		for _, name := range sortedKeys(*opts.PluginConfigs) {
+			pluginConfig, ok := (*opts.PluginConfigs)[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("config for plugin %s must be a table, got %T", name, (*opts.PluginConfigs)[name])
+			}
+			if err := c.merge(pluginConfig, "plugins", name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, registry := range sortedKeys(opts.RegistryMirrors) {
+		path := []string{"plugins", common.ContainerdPluginCRI, "registry", "mirrors", registry, "endpoint"}
+		if err := c.set(opts.RegistryMirrors[registry], path...); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(opts.Runtimes) {
+		rt := opts.Runtimes[name]
+		path := []string{"plugins", common.ContainerdPluginCRI, "containerd", "runtimes", name}
+		if rt.RuntimeType != "" {
+			if err := c.set(rt.RuntimeType, append(path, "runtime_type")...); err != nil {
+				return err
+			}
+		}
+		if len(rt.Options) > 0 {
+			if err := c.merge(rt.Options, append(path, "options")...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// options decodes the config into ContainerdConfigOptions, including the registry mirrors and
+// runtimes of the CRI plugin.
+func (c *containerdConfig) options() (*ContainerdConfigOptions, error) {
+	content, err := c.marshal()
+	if err != nil {
+		return nil, err
+	}
+	var opts ContainerdConfigOptions
+	if err := toml.Unmarshal(content, &opts); err != nil {
+		return nil, fmt.Errorf("failed to decode containerd config: %w", err)
+	}
+
+	if mirrors, ok := c.get("plugins", common.ContainerdPluginCRI, "registry", "mirrors"); ok {
+		if mirrorTable, ok := mirrors.(map[string]interface{}); ok {
+			opts.RegistryMirrors = make(map[string][]string, len(mirrorTable))
+			for registry, mirror := range mirrorTable {
+				mirrorConfig, _ := mirror.(map[string]interface{})
+				endpoints, _ := mirrorConfig["endpoint"].([]interface{})
+				for _, endpoint := range endpoints {
+					if s, ok := endpoint.(string); ok {
+						opts.RegistryMirrors[registry] = append(opts.RegistryMirrors[registry], s)
+					}
+				}
+			}
+		}
+	}
+	if runtimes, ok := c.get("plugins", common.ContainerdPluginCRI, "containerd", "runtimes"); ok {
+		if runtimeTable, ok := runtimes.(map[string]interface{}); ok {
+			opts.Runtimes = make(map[string]ContainerdRuntimeOptions, len(runtimeTable))
+			for name, value := range runtimeTable {
+				rt, _ := value.(map[string]interface{})
+				runtimeType, _ := rt["runtime_type"].(string)
+				runtimeOptions, _ := rt["options"].(map[string]interface{})
+				opts.Runtimes[name] = ContainerdRuntimeOptions{RuntimeType: runtimeType, Options: runtimeOptions}
+			}
+		}
+	}
+	return &opts, nil
+}
@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const containerdRegistryHostsFile = "hosts.toml"
+
+// RenderContainerdRegistryHosts renders a containerd hosts.toml. The output only depends on opts,
+// so callers can compare it with the file on the host to decide whether anything changed.
+func (r *defaultRunner) RenderContainerdRegistryHosts(opts ContainerdRegistryHostsOptions) ([]byte, error) {
+	if err := validateContainerdRegistryHostsOptions(opts); err != nil {
+		return nil, errors.Wrap(err, "invalid registry hosts options")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "server = %q\n", opts.Server)
+	for _, host := range opts.Hosts {
+		fmt.Fprintf(&b, "\n[host.%q]\n", host.URL)
+		quoted := make([]string, 0, len(host.Capabilities))
+		for _, c := range host.Capabilities {
+			quoted = append(quoted, fmt.Sprintf("%q", c))
+		}
+		fmt.Fprintf(&b, "  capabilities = [%s]\n", strings.Join(quoted, ", "))
+		if host.SkipVerify {
+			b.WriteString("  skip_verify = true\n")
+		}
+		if host.CAFile != "" {
+			fmt.Fprintf(&b, "  ca = %q\n", host.CAFile)
+		}
+		if host.ClientCert != "" {
+			fmt.Fprintf(&b, "  client = [[%q, %q]]\n", host.ClientCert, host.ClientKey)
+		}
+		if auth := containerdBasicAuth(host); auth != "" {
+			fmt.Fprintf(&b, "  [host.%q.header]\n", host.URL)
+			fmt.Fprintf(&b, "    Authorization = [%q]\n", "Basic "+auth)
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+func validateContainerdRegistryHostsOptions(opts ContainerdRegistryHostsOptions) error {
+	if err := validateRegistryHostURL(opts.Server); err != nil {
+		return errors.Wrap(err, "server")
+	}
+	for _, host := range opts.Hosts {
+		if err := validateRegistryHostURL(host.URL); err != nil {
+			return errors.Wrap(err, "host")
+		}
+		if (host.ClientCert == "") != (host.ClientKey == "") {
+			return fmt.Errorf("host %s: client certificate and key must be set together", host.URL)
+		}
+		if host.Username != "" && host.Password == "" {
+			return fmt.Errorf("host %s: username is set without a password", host.URL)
+		}
+	}
+	return nil
+}
+
+func validateRegistryHostURL(raw string) error {
+	if raw == "" {
+		return errors.New("URL cannot be empty")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URL %q", raw)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("URL %q must be an absolute http or https URL", raw)
+	}
+	return nil
+}
+
+// containerdBasicAuth returns the base64 credentials of host, preferring username and password.
+func containerdBasicAuth(host ContainerdRegistryHost) string {
+	if host.Username != "" {
+		return base64.StdEncoding.EncodeToString([]byte(host.Username + ":" + host.Password))
+	}
+	return host.Auth
+}
+
+// ConfigureContainerdRegistryHosts writes <certsDir>/<registry>/hosts.toml when its content differs
+// and reports whether it did. Containerd reads hosts.toml on every pull, so a restart is only
+// needed when config_path itself was just turned on; restartService is there for that case.
+func (r *defaultRunner) ConfigureContainerdRegistryHosts(ctx context.Context, conn connector.Connector, facts *Facts, certsDir, registry string, opts ContainerdRegistryHostsOptions, restartService bool) (bool, error) {
+	if conn == nil {
+		return false, errors.New("connector cannot be nil")
+	}
+	if certsDir == "" || registry == "" {
+		return false, errors.New("certsDir and registry cannot be empty")
+	}
+	rendered, err := r.RenderContainerdRegistryHosts(opts)
+	if err != nil {
+		return false, err
+	}
+
+	path := filepath.Join(certsDir, registry, containerdRegistryHostsFile)
+	exists, err := r.Exists(ctx, conn, path)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check for %s", path)
+	}
+	if exists {
+		current, err := r.ReadFile(ctx, conn, path)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to read %s", path)
+		}
+		if string(current) == string(rendered) {
+			return false, nil
+		}
+	}
+
+	if err := r.Mkdirp(ctx, conn, filepath.Dir(path), "0755", true); err != nil {
+		return false, errors.Wrapf(err, "failed to create directory for %s", path)
+	}
+	if err := r.WriteFile(ctx, conn, rendered, path, "0644", true); err != nil {
+		return false, errors.Wrapf(err, "failed to write %s", path)
+	}
+	if !restartService {
+		return true, nil
+	}
+	if facts == nil {
+		if facts, err = r.GatherFacts(ctx, conn); err != nil {
+			return true, errors.Wrap(err, "failed to gather facts for containerd restart")
+		}
+	}
+	if err := r.RestartService(ctx, conn, facts, "containerd"); err != nil {
+		return true, errors.Wrap(err, "failed to restart containerd")
+	}
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestRenderContainerdRegistryHosts(t *testing.T) {
+	r := NewRunner()
+	opts := ContainerdRegistryHostsOptions{
+		Server: "https://registry-1.docker.io",
+		Hosts: []ContainerdRegistryHost{
+			{URL: "https://mirror.example.com", Capabilities: []string{"pull", "resolve"}, CAFile: "/certs/ca.crt", Username: "admin", Password: "secret"},
+			{URL: "http://10.0.0.10:5000", Capabilities: []string{"pull", "resolve"}, SkipVerify: true},
+		},
+	}
+
+	rendered, err := r.RenderContainerdRegistryHosts(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  ca = "/certs/ca.crt"
+  [host."https://mirror.example.com".header]
+    Authorization = ["Basic YWRtaW46c2VjcmV0"]
+
+[host."http://10.0.0.10:5000"]
+  capabilities = ["pull", "resolve"]
+  skip_verify = true
+`
+	if string(rendered) != expected {
+		t.Errorf("unexpected hosts.toml:\n%s\nexpected:\n%s", rendered, expected)
+	}
+	var parsed map[string]interface{}
+	if err := toml.Unmarshal(rendered, &parsed); err != nil {
+		t.Fatalf("rendered hosts.toml is not valid TOML: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts ContainerdRegistryHostsOptions
+		want string
+	}{
+		{"missing server", ContainerdRegistryHostsOptions{}, "server"},
+		{"relative host", ContainerdRegistryHostsOptions{Server: "https://docker.io", Hosts: []ContainerdRegistryHost{{URL: "mirror.example.com"}}}, "absolute http or https URL"},
+		{"client cert without key", ContainerdRegistryHostsOptions{Server: "https://docker.io", Hosts: []ContainerdRegistryHost{{URL: "https://m.example.com", ClientCert: "/c.crt"}}}, "client certificate"},
+		{"username without password", ContainerdRegistryHostsOptions{Server: "https://docker.io", Hosts: []ContainerdRegistryHost{{URL: "https://m.example.com", Username: "admin"}}}, "without a password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.RenderContainerdRegistryHosts(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
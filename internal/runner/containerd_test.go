@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const baseContainerdConfig = `version = 2
+root = "/var/lib/containerd"
+
+[grpc]
+  address = "/run/containerd/containerd.sock"
+`
+
+func TestRenderContainerdConfig(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		current     string
+		opts        ContainerdConfigOptions
+		expectedErr string
+		check       func(t *testing.T, cfg map[string]interface{})
+	}{
+		{
+			name:    "valid options produce parseable TOML",
+			current: baseContainerdConfig,
+			opts: ContainerdConfigOptions{
+				Root:            strPtr("/data/containerd"),
+				OOMScore:        intPtr(-999),
+				RegistryMirrors: map[string][]string{"docker.io": {"https://mirror.example.com"}},
+			},
+			check: func(t *testing.T, cfg map[string]interface{}) {
+				if cfg["root"] != "/data/containerd" {
+					t.Errorf("expected root to be updated, got %v", cfg["root"])
+				}
+				if cfg["version"] != int64(2) {
+					t.Errorf("expected version to be preserved, got %v", cfg["version"])
+				}
+			},
+		},
+		{
+			name:    "empty current config",
+			current: "",
+			opts:    ContainerdConfigOptions{Version: intPtr(2)},
+			check: func(t *testing.T, cfg map[string]interface{}) {
+				if cfg["version"] != int64(2) {
+					t.Errorf("expected version 2, got %v", cfg["version"])
+				}
+			},
+		},
+		{
+			name:        "unsupported version",
+			current:     baseContainerdConfig,
+			opts:        ContainerdConfigOptions{Version: intPtr(7)},
+			expectedErr: "unsupported config version",
+		},
+		{
+			name:        "relative root",
+			current:     baseContainerdConfig,
+			opts:        ContainerdConfigOptions{Root: strPtr("var/lib/containerd")},
+			expectedErr: "must be an absolute path",
+		},
+		{
+			name:        "oom score out of range",
+			current:     baseContainerdConfig,
+			opts:        ContainerdConfigOptions{OOMScore: intPtr(5000)},
+			expectedErr: "oom_score",
+		},
+		{
+			name:        "mirror endpoint without scheme",
+			current:     baseContainerdConfig,
+			opts:        ContainerdConfigOptions{RegistryMirrors: map[string][]string{"docker.io": {"mirror.example.com"}}},
+			expectedErr: "must be an http(s) URL",
+		},
+		{
+			name:        "current config is not valid TOML",
+			current:     "version = [",
+			opts:        ContainerdConfigOptions{},
+			expectedErr: "containerd",
+		},
+	}
+
+	r := NewRunner()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := r.RenderContainerdConfig(tt.opts, []byte(tt.current))
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var cfg map[string]interface{}
+			if err := toml.Unmarshal(rendered, &cfg); err != nil {
+				t.Fatalf("rendered config is not valid TOML: %v\n%s", err, rendered)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
+
+const criContainerdConfig = `version = 2
+
+[plugins]
+  [plugins."io.containerd.grpc.v1.cri"]
+    sandbox_image = "registry.k8s.io/pause:3.9"
+    [plugins."io.containerd.grpc.v1.cri".containerd]
+      default_runtime_name = "runc"
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc]
+        runtime_type = "io.containerd.runc.v2"
+        [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+          SystemdCgroup = true
+`
+
+func TestRenderContainerdConfig_RoundTrip(t *testing.T) {
+	r := NewRunner()
+	opts := ContainerdConfigOptions{
+		RegistryMirrors: map[string][]string{"docker.io": {"https://mirror.example.com"}},
+		PluginConfigs: &map[string]interface{}{
+			"io.containerd.grpc.v1.cri": map[string]interface{}{
+				"enable_unprivileged_ports": true,
+				"containerd":                map[string]interface{}{"snapshotter": "overlayfs"},
+			},
+		},
+		Runtimes: map[string]ContainerdRuntimeOptions{
+			"nvidia": {RuntimeType: "io.containerd.runc.v2", Options: map[string]interface{}{"BinaryName": "/usr/bin/nvidia-container-runtime"}},
+		},
+	}
+
+	rendered, err := r.RenderContainerdConfig(opts, []byte(criContainerdConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := parseContainerdConfig(rendered)
+	if err != nil {
+		t.Fatalf("rendered config is not valid TOML: %v\n%s", err, rendered)
+	}
+	cri := []string{"plugins", "io.containerd.grpc.v1.cri"}
+	expected := []struct {
+		path  []string
+		value interface{}
+	}{
+		{append(cri, "sandbox_image"), "registry.k8s.io/pause:3.9"},
+		{append(cri, "enable_unprivileged_ports"), true},
+		{append(cri, "containerd", "default_runtime_name"), "runc"},
+		{append(cri, "containerd", "snapshotter"), "overlayfs"},
+		{append(cri, "containerd", "runtimes", "runc", "options", "SystemdCgroup"), true},
+		{append(cri, "containerd", "runtimes", "nvidia", "options", "BinaryName"), "/usr/bin/nvidia-container-runtime"},
+	}
+	for _, e := range expected {
+		if got, ok := cfg.get(e.path...); !ok || got != e.value {
+			t.Errorf("expected %s = %v, got %v", tomlPath(e.path), e.value, got)
+		}
+	}
+	if plugins, _ := cfg.get("plugins"); len(plugins.(map[string]interface{})) != 1 {
+		t.Errorf("expected the CRI plugin name to stay a single key, got %v", plugins)
+	}
+
+	read, err := cfg.options()
+	if err != nil {
+		t.Fatalf("failed to read options back: %v", err)
+	}
+	if got := read.RegistryMirrors["docker.io"]; len(got) != 1 || got[0] != "https://mirror.example.com" {
+		t.Errorf("expected the docker.io mirror to be read back, got %v", read.RegistryMirrors)
+	}
+	if read.Runtimes["runc"].RuntimeType != "io.containerd.runc.v2" || read.Runtimes["nvidia"].Options["BinaryName"] == nil {
+		t.Errorf("expected both runtimes to be read back, got %+v", read.Runtimes)
+	}
+
+	again, err := r.RenderContainerdConfig(opts, rendered)
+	if err != nil {
+		t.Fatalf("unexpected error on second render: %v", err)
+	}
+	if string(again) != string(rendered) {
+		t.Errorf("expected rendering to be idempotent, got:\n%s\nthen:\n%s", rendered, again)
+	}
+	unchanged, err := r.RenderContainerdConfig(ContainerdConfigOptions{}, []byte(criContainerdConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(unchanged) != criContainerdConfig {
+		t.Errorf("expected a config without changes to be kept byte for byte, got:\n%s", unchanged)
+	}
+}
+
+func TestRenderContainerdConfig_PathConflict(t *testing.T) {
+	current := "version = 2\n[plugins]\n  \"io.containerd.grpc.v1.cri\" = \"disabled\"\n"
+	_, err := NewRunner().RenderContainerdConfig(ContainerdConfigOptions{
+		RegistryMirrors: map[string][]string{"docker.io": {"https://mirror.example.com"}},
+	}, []byte(current))
+	if err == nil || !strings.Contains(err.Error(), `plugins."io.containerd.grpc.v1.cri" is a string, not a table`) {
+		t.Fatalf("expected a path conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const crioServiceName = "crio"
+
+var (
+	crioCgroupManagers = map[string]bool{"systemd": true, "cgroupfs": true}
+	crioLogLevels      = map[string]bool{"fatal": true, "panic": true, "error": true, "warn": true, "info": true, "debug": true, "trace": true}
+	crioRuntimeTypes   = map[string]bool{"oci": true, "vm": true, "pod": true}
+)
+
+// RenderCRIOConfig merges opts into a CRI-O config file and returns the result. Settings not
+// covered by opts are preserved; current is returned unchanged if it already matches.
+func (r *defaultRunner) RenderCRIOConfig(opts CRIOConfigOptions, current []byte) ([]byte, error) {
+	if err := validateCRIOConfigOptions(opts); err != nil {
+		return nil, errors.Wrap(err, "invalid CRI-O config options")
+	}
+	rendered, err := renderTomlConfig(current, func(cfg *tomlConfig) error {
+		return applyCRIOConfigOptions(cfg, opts)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge CRI-O config options")
+	}
+	return rendered, nil
+}
+
+func applyCRIOConfigOptions(cfg *tomlConfig, opts CRIOConfigOptions) error {
+	type setting struct {
+		path  []string
+		value *string
+	}
+	for _, s := range []setting{
+		{[]string{"crio", "root"}, opts.Root},
+		{[]string{"crio", "runroot"}, opts.RunRoot},
+		{[]string{"crio", "storage_driver"}, opts.StorageDriver},
+		{[]string{"crio", "runtime", "log_level"}, opts.LogLevel},
+		{[]string{"crio", "runtime", "log_filter"}, opts.LogFilter},
+		{[]string{"crio", "runtime", "cgroup_manager"}, opts.CgroupManager},
+		{[]string{"crio", "runtime", "conmon"}, opts.Conmon},
+		{[]string{"crio", "runtime", "default_runtime"}, opts.DefaultRuntime},
+		{[]string{"crio", "image", "pause_image"}, opts.PauseImage},
+		{[]string{"crio", "image", "signature_policy"}, opts.SignaturePolicy},
+		{[]string{"crio", "network", "network_dir"}, opts.NetworkDir},
+	} {
+		if s.value == nil {
+			continue
+		}
+		if err := cfg.set(*s.value, s.path...); err != nil {
+			return err
+		}
+	}
+	if len(opts.StorageOptions) > 0 {
+		if err := cfg.set(opts.StorageOptions, "crio", "storage_option"); err != nil {
+			return err
+		}
+	}
+	if len(opts.PluginDirs) > 0 {
+		if err := cfg.set(opts.PluginDirs, "crio", "network", "plugin_dirs"); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(opts.Runtimes) {
+		rt := opts.Runtimes[name]
+		values := make(map[string]interface{})
+		for key, value := range map[string]string{
+			"runtime_path": rt.RuntimePath,
+			"runtime_type": rt.RuntimeType,
+			"runtime_root": rt.RuntimeRoot,
+			"monitor_path": rt.MonitorPath,
+		} {
+			if value != "" {
+				values[key] = value
+			}
+		}
+		if err := cfg.merge(values, "crio", "runtime", "runtimes", name); err != nil {
+			return err
+		}
+	}
+	if len(opts.Extra) > 0 {
+		if err := cfg.merge(opts.Extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCRIOConfigOptions rejects option values that CRI-O would refuse at startup.
+func validateCRIOConfigOptions(opts CRIOConfigOptions) error {
+	for name, path := range map[string]*string{"root": opts.Root, "runroot": opts.RunRoot, "network_dir": opts.NetworkDir} {
+		if path != nil && !filepath.IsAbs(*path) {
+			return fmt.Errorf("%s %q must be an absolute path", name, *path)
+		}
+	}
+	if opts.LogLevel != nil && !crioLogLevels[*opts.LogLevel] {
+		return fmt.Errorf("unsupported log_level %q", *opts.LogLevel)
+	}
+	if opts.CgroupManager != nil && !crioCgroupManagers[*opts.CgroupManager] {
+		return fmt.Errorf("unsupported cgroup_manager %q, must be systemd or cgroupfs", *opts.CgroupManager)
+	}
+	for name, rt := range opts.Runtimes {
+		if rt.RuntimeType != "" && !crioRuntimeTypes[rt.RuntimeType] {
+			return fmt.Errorf("runtime %s has unsupported runtime_type %q, must be oci, vm or pod", name, rt.RuntimeType)
+		}
+		if rt.RuntimePath != "" && !filepath.IsAbs(rt.RuntimePath) {
+			return fmt.Errorf("runtime %s has runtime_path %q, which must be an absolute path", name, rt.RuntimePath)
+		}
+	}
+	if opts.DefaultRuntime != nil && len(opts.Runtimes) > 0 {
+		if _, ok := opts.Runtimes[*opts.DefaultRuntime]; !ok {
+			return fmt.Errorf("default_runtime %q is not one of the configured runtimes", *opts.DefaultRuntime)
+		}
+	}
+	return nil
+}
+
+// RenderCRIORegistriesConfig merges opts into a containers-registries.conf file. Each registry
+// replaces an existing [[registry]] entry with the same prefix, other entries are kept.
+func (r *defaultRunner) RenderCRIORegistriesConfig(opts CRIORegistriesOptions, current []byte) ([]byte, error) {
+	if err := validateCRIORegistriesOptions(opts); err != nil {
+		return nil, errors.Wrap(err, "invalid registries options")
+	}
+	rendered, err := renderTomlConfig(current, func(cfg *tomlConfig) error {
+		if _, ok := cfg.get("registries"); ok {
+			return fmt.Errorf("the file uses the v1 [registries.*] format, which cannot be mixed with [[registry]] entries")
+		}
+		if len(opts.UnqualifiedSearchRegistries) > 0 {
+			if err := cfg.set(opts.UnqualifiedSearchRegistries, "unqualified-search-registries"); err != nil {
+				return err
+			}
+		}
+		if len(opts.Registries) == 0 {
+			return nil
+		}
+		existing, _ := cfg.get("registry")
+		entries, ok := existing.([]interface{})
+		if existing != nil && !ok {
+			return fmt.Errorf("registry is a %T, not an array of tables", existing)
+		}
+		for _, reg := range opts.Registries {
+			entry := crioRegistryEntry(reg)
+			replaced := false
+			for i, e := range entries {
+				if m, ok := e.(map[string]interface{}); ok && crioRegistryPrefix(m) == entry["prefix"] {
+					entries[i], replaced = entry, true
+					break
+				}
+			}
+			if !replaced {
+				entries = append(entries, entry)
+			}
+		}
+		return cfg.set(entries, "registry")
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge registries config")
+	}
+	return rendered, nil
+}
+
+// crioRegistryEntry builds the [[registry]] table for reg. The prefix defaults to the location,
+// as it does in containers-registries.conf.
+func crioRegistryEntry(reg CRIORegistry) map[string]interface{} {
+	prefix := reg.Prefix
+	if prefix == "" {
+		prefix = reg.Location
+	}
+	entry := map[string]interface{}{"prefix": prefix}
+	if reg.Location != "" {
+		entry["location"] = reg.Location
+	}
+	if reg.Insecure {
+		entry["insecure"] = true
+	}
+	if reg.Blocked {
+		entry["blocked"] = true
+	}
+	if len(reg.Mirrors) > 0 {
+		mirrors := make([]interface{}, 0, len(reg.Mirrors))
+		for _, m := range reg.Mirrors {
+			mirror := map[string]interface{}{"location": m.Location}
+			if m.Insecure {
+				mirror["insecure"] = true
+			}
+			mirrors = append(mirrors, mirror)
+		}
+		entry["mirror"] = mirrors
+	}
+	return entry
+}
+
+func crioRegistryPrefix(entry map[string]interface{}) string {
+	if prefix, _ := entry["prefix"].(string); prefix != "" {
+		return prefix
+	}
+	location, _ := entry["location"].(string)
+	return location
+}
+
+func validateCRIORegistriesOptions(opts CRIORegistriesOptions) error {
+	for i, reg := range opts.Registries {
+		if reg.Prefix == "" && reg.Location == "" {
+			return fmt.Errorf("registry %d needs a prefix or a location", i)
+		}
+		for _, m := range reg.Mirrors {
+			if m.Location == "" {
+				return fmt.Errorf("mirror of registry %s needs a location", crioRegistryEntry(reg)["prefix"])
+			}
+		}
+	}
+	return nil
+}
+
+func (r *defaultRunner) ConfigureCRIO(ctx context.Context, conn connector.Connector, facts *Facts, opts CRIOConfigOptions, configPath string, restartService bool) error {
+	return r.updateCRIOFile(ctx, conn, facts, configPath, restartService, func(current []byte) ([]byte, error) {
+		return r.RenderCRIOConfig(opts, current)
+	})
+}
+
+func (r *defaultRunner) ConfigureCRIORegistries(ctx context.Context, conn connector.Connector, facts *Facts, opts CRIORegistriesOptions, configPath string, restartService bool) error {
+	return r.updateCRIOFile(ctx, conn, facts, configPath, restartService, func(current []byte) ([]byte, error) {
+		return r.RenderCRIORegistriesConfig(opts, current)
+	})
+}
+
+// updateCRIOFile renders the file at path from its current content, writes it back only if it
+// changed and then restarts CRI-O if asked to.
+func (r *defaultRunner) updateCRIOFile(ctx context.Context, conn connector.Connector, facts *Facts, path string, restartService bool, render func([]byte) ([]byte, error)) error {
+	if conn == nil {
+		return errors.New("connector cannot be nil")
+	}
+	if path == "" {
+		return errors.New("config path cannot be empty")
+	}
+
+	var current []byte
+	exists, err := r.Exists(ctx, conn, path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for %s", path)
+	}
+	if exists {
+		if current, err = r.ReadFile(ctx, conn, path); err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+	}
+	rendered, err := render(current)
+	if err != nil {
+		return errors.Wrapf(err, "failed to render %s", path)
+	}
+	if exists && string(rendered) == string(current) {
+		return nil
+	}
+
+	if err := r.Mkdirp(ctx, conn, filepath.Dir(path), "0755", true); err != nil {
+		return errors.Wrapf(err, "failed to create directory for %s", path)
+	}
+	if err := r.WriteFile(ctx, conn, rendered, path, "0644", true); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	if !restartService {
+		return nil
+	}
+	if facts == nil {
+		if facts, err = r.GatherFacts(ctx, conn); err != nil {
+			return errors.Wrap(err, "failed to gather facts for CRI-O restart")
+		}
+	}
+	if err := r.RestartService(ctx, conn, facts, crioServiceName); err != nil {
+		return errors.Wrap(err, "failed to restart CRI-O")
+	}
+	return nil
+}
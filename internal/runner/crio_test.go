@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+const crioDropIn = `[crio.image]
+  pause_image = "registry.k8s.io/pause:3.9"
+
+[crio.runtime]
+  cgroup_manager = "systemd"
+  default_runtime = "runc"
+
+[crio.runtime.runtimes.runc]
+  runtime_path = "/usr/local/bin/runc"
+  runtime_root = "/run/runc"
+`
+
+func TestRenderCRIOConfig_RoundTrip(t *testing.T) {
+	r := NewRunner()
+	logLevel, root, networkDir := "info", "/data/containers/storage", "/etc/cni/net.d"
+	opts := CRIOConfigOptions{
+		Root:       &root,
+		LogLevel:   &logLevel,
+		NetworkDir: &networkDir,
+		PluginDirs: []string{"/opt/cni/bin"},
+		Runtimes: map[string]CRIORuntimeOptions{
+			"kata": {RuntimePath: "/usr/bin/kata-runtime", RuntimeType: "vm"},
+		},
+		Extra: map[string]interface{}{
+			"crio": map[string]interface{}{"metrics": map[string]interface{}{"enable_metrics": true}},
+		},
+	}
+
+	rendered, err := r.RenderCRIOConfig(opts, []byte(crioDropIn))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := parseTomlConfig(rendered)
+	if err != nil {
+		t.Fatalf("rendered config is not valid TOML: %v\n%s", err, rendered)
+	}
+	expected := []struct {
+		path  []string
+		value interface{}
+	}{
+		{[]string{"crio", "root"}, root},
+		{[]string{"crio", "image", "pause_image"}, "registry.k8s.io/pause:3.9"},
+		{[]string{"crio", "runtime", "log_level"}, logLevel},
+		{[]string{"crio", "runtime", "cgroup_manager"}, "systemd"},
+		{[]string{"crio", "runtime", "runtimes", "runc", "runtime_root"}, "/run/runc"},
+		{[]string{"crio", "runtime", "runtimes", "kata", "runtime_type"}, "vm"},
+		{[]string{"crio", "network", "network_dir"}, networkDir},
+		{[]string{"crio", "metrics", "enable_metrics"}, true},
+	}
+	for _, e := range expected {
+		if got, ok := cfg.get(e.path...); !ok || got != e.value {
+			t.Errorf("expected %s = %v, got %v", tomlPath(e.path), e.value, got)
+		}
+	}
+
+	again, err := r.RenderCRIOConfig(opts, rendered)
+	if err != nil {
+		t.Fatalf("unexpected error on second render: %v", err)
+	}
+	if string(again) != string(rendered) {
+		t.Errorf("rendering twice changed the config:\n%s\n---\n%s", rendered, again)
+	}
+	if unchanged, _ := r.RenderCRIOConfig(CRIOConfigOptions{}, []byte(crioDropIn)); string(unchanged) != crioDropIn {
+		t.Errorf("empty options should leave the config untouched, got:\n%s", unchanged)
+	}
+}
+
+func TestRenderCRIOConfig_Validation(t *testing.T) {
+	r := NewRunner()
+	relative, cgroup, level, def := "var/lib/containers", "none", "verbose", "crun"
+	tests := []struct {
+		name string
+		opts CRIOConfigOptions
+		want string
+	}{
+		{"relative root", CRIOConfigOptions{Root: &relative}, "absolute path"},
+		{"bad cgroup manager", CRIOConfigOptions{CgroupManager: &cgroup}, "cgroup_manager"},
+		{"bad log level", CRIOConfigOptions{LogLevel: &level}, "log_level"},
+		{"bad runtime type", CRIOConfigOptions{Runtimes: map[string]CRIORuntimeOptions{"runc": {RuntimeType: "shim"}}}, "runtime_type"},
+		{"unknown default runtime", CRIOConfigOptions{DefaultRuntime: &def, Runtimes: map[string]CRIORuntimeOptions{"runc": {}}}, "default_runtime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.RenderCRIOConfig(tt.opts, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRenderCRIORegistriesConfig(t *testing.T) {
+	r := NewRunner()
+	current := `unqualified-search-registries = ["docker.io"]
+
+[[registry]]
+prefix = "quay.io"
+location = "quay.io"
+
+[[registry]]
+prefix = "docker.io"
+location = "docker.io"
+`
+	opts := CRIORegistriesOptions{
+		UnqualifiedSearchRegistries: []string{"registry.local:5000", "docker.io"},
+		Registries: []CRIORegistry{
+			{Prefix: "docker.io", Location: "docker.io", Mirrors: []CRIORegistryMirror{{Location: "registry.local:5000", Insecure: true}}},
+			{Location: "registry.local:5000", Insecure: true},
+		},
+	}
+
+	rendered, err := r.RenderCRIORegistriesConfig(opts, []byte(current))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := parseTomlConfig(rendered)
+	if err != nil {
+		t.Fatalf("rendered config is not valid TOML: %v\n%s", err, rendered)
+	}
+	registries, _ := cfg.get("registry")
+	entries, _ := registries.([]interface{})
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 registries, got %d:\n%s", len(entries), rendered)
+	}
+	prefixes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		prefixes = append(prefixes, crioRegistryPrefix(e.(map[string]interface{})))
+	}
+	if got := strings.Join(prefixes, ","); got != "quay.io,docker.io,registry.local:5000" {
+		t.Errorf("unexpected registry order %s", got)
+	}
+	mirrors, _ := entries[1].(map[string]interface{})["mirror"].([]interface{})
+	if len(mirrors) != 1 || mirrors[0].(map[string]interface{})["insecure"] != true {
+		t.Errorf("expected one insecure mirror for docker.io, got %v", mirrors)
+	}
+
+	again, err := r.RenderCRIORegistriesConfig(opts, rendered)
+	if err != nil {
+		t.Fatalf("unexpected error on second render: %v", err)
+	}
+	if string(again) != string(rendered) {
+		t.Errorf("rendering twice changed the config:\n%s\n---\n%s", rendered, again)
+	}
+
+	if _, err := r.RenderCRIORegistriesConfig(opts, []byte("[registries.insecure]\nregistries = []\n")); err == nil {
+		t.Error("expected an error for a v1 registries.conf")
+	}
+	if _, err := r.RenderCRIORegistriesConfig(CRIORegistriesOptions{Registries: []CRIORegistry{{Insecure: true}}}, nil); err == nil {
+		t.Error("expected an error for a registry without prefix or location")
+	}
+}
@@ -0,0 +1,289 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultEtcdctlTimeout         = 2 * time.Minute
+	DefaultEtcdctlSnapshotTimeout = 15 * time.Minute
+	DefaultEtcdctlDefragTimeout   = 10 * time.Minute
+)
+
+// etcdctlCommand builds an etcdctl command line with the connection flags from opts and every
+// argument shell quoted. etcdctl 3.4+ speaks the v3 API by default, so ETCDCTL_API is not set;
+// an env prefix would also break the sudo wrapping of the connector.
+func etcdctlCommand(opts EtcdctlOptions, args ...string) string {
+	binary := opts.EtcdctlPath
+	if binary == "" {
+		binary = "etcdctl"
+	}
+	parts := []string{connector.ShellEscape(binary)}
+	if len(opts.Endpoints) > 0 {
+		parts = append(parts, "--endpoints="+connector.ShellEscape(strings.Join(opts.Endpoints, ",")))
+	}
+	if opts.CACert != "" {
+		parts = append(parts, "--cacert="+connector.ShellEscape(opts.CACert))
+	}
+	if opts.Cert != "" {
+		parts = append(parts, "--cert="+connector.ShellEscape(opts.Cert))
+	}
+	if opts.Key != "" {
+		parts = append(parts, "--key="+connector.ShellEscape(opts.Key))
+	}
+	if opts.DialTimeout > 0 {
+		parts = append(parts, "--dial-timeout="+opts.DialTimeout.String())
+	}
+	if opts.CommandTimeout > 0 {
+		parts = append(parts, "--command-timeout="+opts.CommandTimeout.String())
+	}
+	for _, arg := range args {
+		parts = append(parts, connector.ShellEscape(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r *defaultRunner) execEtcdctl(ctx context.Context, conn connector.Connector, opts EtcdctlOptions, timeout time.Duration, args ...string) ([]byte, []byte, error) {
+	if conn == nil {
+		return nil, nil, errors.New("connector cannot be nil")
+	}
+	return conn.Exec(ctx, etcdctlCommand(opts, args...), &connector.ExecOptions{Sudo: opts.Sudo, Timeout: timeout})
+}
+
+func (r *defaultRunner) EtcdMemberList(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdMember, error) {
+	stdout, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, "member", "list", "-w", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list etcd members. Stderr: %s", string(stderr))
+	}
+	var out struct {
+		Members []EtcdMember `json:"members"`
+	}
+	if err := json.Unmarshal(stdout, &out); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse etcd member list. Output: %s", string(stdout))
+	}
+	return out.Members, nil
+}
+
+func (r *defaultRunner) EtcdMemberAdd(ctx context.Context, conn connector.Connector, name string, peerURLs []string, learner bool, opts EtcdctlOptions) (*EtcdMember, error) {
+	if name == "" {
+		return nil, errors.New("member name cannot be empty")
+	}
+	if len(peerURLs) == 0 {
+		return nil, errors.New("at least one peer URL is required")
+	}
+	args := []string{"member", "add", name, "--peer-urls=" + strings.Join(peerURLs, ",")}
+	if learner {
+		args = append(args, "--learner")
+	}
+	args = append(args, "-w", "json")
+	stdout, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to add etcd member %s. Stderr: %s", name, string(stderr))
+	}
+	var out struct {
+		Member EtcdMember `json:"member"`
+	}
+	if err := json.Unmarshal(stdout, &out); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse etcd member add output. Output: %s", string(stdout))
+	}
+	// The new member has no name until it starts and joins the cluster.
+	if out.Member.Name == "" {
+		out.Member.Name = name
+	}
+	return &out.Member, nil
+}
+
+func (r *defaultRunner) EtcdMemberRemove(ctx context.Context, conn connector.Connector, memberID uint64, opts EtcdctlOptions) error {
+	if memberID == 0 {
+		return errors.New("member ID cannot be zero")
+	}
+	_, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, "member", "remove", FormatEtcdMemberID(memberID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove etcd member %s. Stderr: %s", FormatEtcdMemberID(memberID), string(stderr))
+	}
+	return nil
+}
+
+// EtcdEndpointHealth returns the health of every endpoint. etcdctl exits non-zero when an
+// endpoint is unhealthy, so the parsed results are returned together with the error whenever
+// the output could be read.
+func (r *defaultRunner) EtcdEndpointHealth(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdEndpointHealth, error) {
+	args := []string{"endpoint", "health", "-w", "json"}
+	if opts.Cluster {
+		args = append(args, "--cluster")
+	}
+	stdout, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, args...)
+	var health []EtcdEndpointHealth
+	if jsonErr := json.Unmarshal(stdout, &health); jsonErr != nil {
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check etcd endpoint health. Stderr: %s", string(stderr))
+		}
+		return nil, errors.Wrapf(jsonErr, "failed to parse etcd endpoint health. Output: %s", string(stdout))
+	}
+	if err != nil {
+		var unhealthy []string
+		for _, h := range health {
+			if !h.Health {
+				unhealthy = append(unhealthy, h.Endpoint)
+			}
+		}
+		return health, errors.Wrapf(err, "unhealthy etcd endpoints: %s", strings.Join(unhealthy, ", "))
+	}
+	return health, nil
+}
+
+func (r *defaultRunner) EtcdEndpointStatus(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdEndpointStatus, error) {
+	args := []string{"endpoint", "status", "-w", "json"}
+	if opts.Cluster {
+		args = append(args, "--cluster")
+	}
+	stdout, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get etcd endpoint status. Stderr: %s", string(stderr))
+	}
+	return parseEtcdEndpointStatus(stdout)
+}
+
+func parseEtcdEndpointStatus(output []byte) ([]EtcdEndpointStatus, error) {
+	var raw []struct {
+		Endpoint string `json:"Endpoint"`
+		Status   struct {
+			Header struct {
+				MemberID uint64 `json:"member_id"`
+			} `json:"header"`
+			Version   string   `json:"version"`
+			DBSize    int64    `json:"dbSize"`
+			Leader    uint64   `json:"leader"`
+			RaftIndex uint64   `json:"raftIndex"`
+			RaftTerm  uint64   `json:"raftTerm"`
+			IsLearner bool     `json:"isLearner"`
+			Errors    []string `json:"errors"`
+		} `json:"Status"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse etcd endpoint status. Output: %s", string(output))
+	}
+	statuses := make([]EtcdEndpointStatus, 0, len(raw))
+	for _, s := range raw {
+		statuses = append(statuses, EtcdEndpointStatus{
+			Endpoint:  s.Endpoint,
+			MemberID:  s.Status.Header.MemberID,
+			LeaderID:  s.Status.Leader,
+			Version:   s.Status.Version,
+			DBSize:    s.Status.DBSize,
+			RaftTerm:  s.Status.RaftTerm,
+			RaftIndex: s.Status.RaftIndex,
+			IsLearner: s.Status.IsLearner,
+			Errors:    s.Status.Errors,
+		})
+	}
+	return statuses, nil
+}
+
+// EtcdSnapshotSave writes a snapshot of the first endpoint in opts to snapshotPath on the host.
+func (r *defaultRunner) EtcdSnapshotSave(ctx context.Context, conn connector.Connector, snapshotPath string, opts EtcdctlOptions) error {
+	if snapshotPath == "" {
+		return errors.New("snapshot path cannot be empty")
+	}
+	if len(opts.Endpoints) > 1 {
+		return errors.New("etcd snapshot save requires exactly one endpoint")
+	}
+	_, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlSnapshotTimeout, "snapshot", "save", snapshotPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save etcd snapshot to %s. Stderr: %s", snapshotPath, string(stderr))
+	}
+	return nil
+}
+
+// EtcdSnapshotStatus reads a snapshot file on the host and fails when it is not a valid snapshot.
+func (r *defaultRunner) EtcdSnapshotStatus(ctx context.Context, conn connector.Connector, snapshotPath string, opts EtcdctlOptions) (*EtcdSnapshotStatus, error) {
+	if snapshotPath == "" {
+		return nil, errors.New("snapshot path cannot be empty")
+	}
+	// snapshot status works on the local file only, so no connection flags are passed.
+	local := EtcdctlOptions{EtcdctlPath: opts.EtcdctlPath, Sudo: opts.Sudo}
+	stdout, stderr, err := r.execEtcdctl(ctx, conn, local, DefaultEtcdctlTimeout, "snapshot", "status", snapshotPath, "-w", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read etcd snapshot %s. Stderr: %s", snapshotPath, string(stderr))
+	}
+	var status EtcdSnapshotStatus
+	if err := json.Unmarshal(stdout, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse etcd snapshot status. Output: %s", string(stdout))
+	}
+	return &status, nil
+}
+
+// EtcdMoveLeader transfers leadership to targetMemberID. etcd only accepts the request from the
+// current leader, so opts.Endpoints must include it.
+func (r *defaultRunner) EtcdMoveLeader(ctx context.Context, conn connector.Connector, targetMemberID uint64, opts EtcdctlOptions) error {
+	if targetMemberID == 0 {
+		return errors.New("target member ID cannot be zero")
+	}
+	_, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, "move-leader", FormatEtcdMemberID(targetMemberID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to move etcd leader to %s. Stderr: %s", FormatEtcdMemberID(targetMemberID), string(stderr))
+	}
+	return nil
+}
+
+var etcdAlarmTypes = map[int]string{1: "NOSPACE", 2: "CORRUPT"}
+
+func (r *defaultRunner) EtcdAlarmList(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdAlarm, error) {
+	stdout, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, "alarm", "list", "-w", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list etcd alarms. Stderr: %s", string(stderr))
+	}
+	var out struct {
+		Alarms []struct {
+			MemberID uint64 `json:"memberID"`
+			Alarm    int    `json:"alarm"`
+		} `json:"alarms"`
+	}
+	if err := json.Unmarshal(stdout, &out); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse etcd alarm list. Output: %s", string(stdout))
+	}
+	alarms := make([]EtcdAlarm, 0, len(out.Alarms))
+	for _, a := range out.Alarms {
+		name, ok := etcdAlarmTypes[a.Alarm]
+		if !ok {
+			name = strconv.Itoa(a.Alarm)
+		}
+		alarms = append(alarms, EtcdAlarm{MemberID: a.MemberID, Alarm: name})
+	}
+	return alarms, nil
+}
+
+func (r *defaultRunner) EtcdAlarmDisarm(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) error {
+	_, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlTimeout, "alarm", "disarm")
+	if err != nil {
+		return errors.Wrapf(err, "failed to disarm etcd alarms. Stderr: %s", string(stderr))
+	}
+	return nil
+}
+
+// EtcdDefragment defragments the endpoints in opts, or every member when opts.Cluster is set.
+// Defragmentation blocks the member while it runs, so callers should go one member at a time.
+func (r *defaultRunner) EtcdDefragment(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) error {
+	args := []string{"defrag"}
+	if opts.Cluster {
+		args = append(args, "--cluster")
+	}
+	_, stderr, err := r.execEtcdctl(ctx, conn, opts, DefaultEtcdctlDefragTimeout, args...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to defragment etcd. Stderr: %s", string(stderr))
+	}
+	return nil
+}
+
+// FormatEtcdMemberID renders a member ID the way etcdctl prints and accepts it.
+func FormatEtcdMemberID(id uint64) string {
+	return fmt.Sprintf("%x", id)
+}
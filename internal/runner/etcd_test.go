@@ -0,0 +1,189 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+// fakeEtcdctlConnector answers etcdctl commands with the output registered for the first
+// matching subcommand and records every command it receives.
+type fakeEtcdctlConnector struct {
+	connector.Connector
+	outputs  map[string]string
+	fail     map[string]bool
+	commands []string
+	sudo     []bool
+}
+
+func (c *fakeEtcdctlConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.commands = append(c.commands, cmd)
+	c.sudo = append(c.sudo, opts.Sudo)
+	for sub, out := range c.outputs {
+		if strings.Contains(cmd, sub) {
+			if c.fail[sub] {
+				return []byte(out), []byte("exit status 1"), errors.New("exit status 1")
+			}
+			return []byte(out), nil, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func testEtcdctlOptions() EtcdctlOptions {
+	return EtcdctlOptions{
+		EtcdctlPath: "/usr/local/bin/etcdctl",
+		Endpoints:   []string{"https://10.0.0.1:2379", "https://10.0.0.2:2379"},
+		CACert:      "/etc/etcd/pki/ca.pem",
+		Cert:        "/etc/etcd/pki/admin-node1.pem",
+		Key:         "/etc/etcd/pki/admin-node1-key.pem",
+		Sudo:        true,
+	}
+}
+
+func TestEtcdctlCommand(t *testing.T) {
+	opts := testEtcdctlOptions()
+	got := etcdctlCommand(opts, "member", "remove", "8e9e05c52164694d")
+	want := "'/usr/local/bin/etcdctl' --endpoints='https://10.0.0.1:2379,https://10.0.0.2:2379' --cacert='/etc/etcd/pki/ca.pem' " +
+		"--cert='/etc/etcd/pki/admin-node1.pem' --key='/etc/etcd/pki/admin-node1-key.pem' 'member' 'remove' '8e9e05c52164694d'"
+	if got != want {
+		t.Errorf("unexpected command:\n got: %s\nwant: %s", got, want)
+	}
+	if got := etcdctlCommand(EtcdctlOptions{}, "version"); got != "'etcdctl' 'version'" {
+		t.Errorf("expected etcdctl from PATH without connection flags, got %s", got)
+	}
+}
+
+func TestEtcdMembers(t *testing.T) {
+	r := NewRunner()
+	ctx := context.Background()
+	conn := &fakeEtcdctlConnector{outputs: map[string]string{
+		"'list'": `{"header":{"cluster_id":14841639068965178418},"members":[` +
+			`{"ID":10276657743932975437,"name":"node1","peerURLs":["https://10.0.0.1:2380"],"clientURLs":["https://10.0.0.1:2379"]},` +
+			`{"ID":5,"name":"","peerURLs":["https://10.0.0.3:2380"],"isLearner":true}]}`,
+		"'add'": `{"header":{},"member":{"ID":5,"peerURLs":["https://10.0.0.3:2380"],"isLearner":true},"members":[]}`,
+	}}
+
+	members, err := r.EtcdMemberList(ctx, conn, testEtcdctlOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 2 || members[0].ID != 10276657743932975437 || members[0].Name != "node1" || !members[1].IsLearner {
+		t.Errorf("unexpected members: %+v", members)
+	}
+	if !strings.HasSuffix(conn.commands[0], "'member' 'list' '-w' 'json'") || !conn.sudo[0] {
+		t.Errorf("unexpected member list command: %s", conn.commands[0])
+	}
+
+	member, err := r.EtcdMemberAdd(ctx, conn, "node3", []string{"https://10.0.0.3:2380"}, true, testEtcdctlOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if member.ID != 5 || member.Name != "node3" {
+		t.Errorf("unexpected added member: %+v", member)
+	}
+	if !strings.HasSuffix(conn.commands[1], "'member' 'add' 'node3' '--peer-urls=https://10.0.0.3:2380' '--learner' '-w' 'json'") {
+		t.Errorf("unexpected member add command: %s", conn.commands[1])
+	}
+
+	if err := r.EtcdMemberRemove(ctx, conn, 10276657743932975437, testEtcdctlOptions()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(conn.commands[2], "'member' 'remove' '8e9e05c52164694d'") {
+		t.Errorf("expected the member ID in hex, got: %s", conn.commands[2])
+	}
+	if err := r.EtcdMemberRemove(ctx, conn, 0, testEtcdctlOptions()); err == nil {
+		t.Error("expected an error for a zero member ID")
+	}
+}
+
+func TestEtcdEndpoints(t *testing.T) {
+	r := NewRunner()
+	ctx := context.Background()
+	conn := &fakeEtcdctlConnector{
+		outputs: map[string]string{
+			"'health'": `[{"endpoint":"https://10.0.0.1:2379","health":true,"took":"2.1ms"},` +
+				`{"endpoint":"https://10.0.0.2:2379","health":false,"took":"5s","error":"context deadline exceeded"}]`,
+			"'status'": `[{"Endpoint":"https://10.0.0.1:2379","Status":{"header":{"member_id":11},"version":"3.5.9","dbSize":20480,"leader":12,"raftIndex":40,"raftTerm":3}},` +
+				`{"Endpoint":"https://10.0.0.2:2379","Status":{"header":{"member_id":12},"version":"3.5.9","dbSize":24576,"leader":12,"raftIndex":40,"raftTerm":3}}]`,
+		},
+		fail: map[string]bool{"'health'": true},
+	}
+
+	opts := testEtcdctlOptions()
+	opts.Cluster = true
+	health, err := r.EtcdEndpointHealth(ctx, conn, opts)
+	if err == nil || !strings.Contains(err.Error(), "https://10.0.0.2:2379") {
+		t.Errorf("expected an error naming the unhealthy endpoint, got %v", err)
+	}
+	if len(health) != 2 || !health[0].Health || health[1].Error != "context deadline exceeded" {
+		t.Errorf("unexpected endpoint health: %+v", health)
+	}
+	if !strings.HasSuffix(conn.commands[0], "'endpoint' 'health' '-w' 'json' '--cluster'") {
+		t.Errorf("unexpected endpoint health command: %s", conn.commands[0])
+	}
+
+	statuses, err := r.EtcdEndpointStatus(ctx, conn, testEtcdctlOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].IsLeader() || !statuses[1].IsLeader() || statuses[1].DBSize != 24576 {
+		t.Errorf("unexpected endpoint status: %+v", statuses)
+	}
+}
+
+func TestEtcdSnapshotAndMaintenance(t *testing.T) {
+	r := NewRunner()
+	ctx := context.Background()
+	conn := &fakeEtcdctlConnector{outputs: map[string]string{
+		"'snapshot' 'status'": `{"hash":3472158441,"revision":1024,"totalKey":512,"totalSize":2097152}`,
+		"'alarm' 'list'":      `{"header":{},"alarms":[{"memberID":11,"alarm":1},{"memberID":12,"alarm":2}]}`,
+	}}
+
+	opts := testEtcdctlOptions()
+	if err := r.EtcdSnapshotSave(ctx, conn, "/var/backups/etcd/snap.db", opts); err == nil {
+		t.Error("expected snapshot save to refuse several endpoints")
+	}
+	opts.Endpoints = opts.Endpoints[:1]
+	if err := r.EtcdSnapshotSave(ctx, conn, "/var/backups/etcd/snap.db", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status, err := r.EtcdSnapshotStatus(ctx, conn, "/var/backups/etcd/snap.db", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Revision != 1024 || status.TotalKey != 512 || status.Hash != 3472158441 {
+		t.Errorf("unexpected snapshot status: %+v", status)
+	}
+	if cmd := conn.commands[len(conn.commands)-1]; strings.Contains(cmd, "--endpoints") {
+		t.Errorf("expected snapshot status to run without connection flags, got: %s", cmd)
+	}
+
+	alarms, err := r.EtcdAlarmList(ctx, conn, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(alarms) != 2 || alarms[0].Alarm != "NOSPACE" || alarms[1].Alarm != "CORRUPT" || alarms[1].MemberID != 12 {
+		t.Errorf("unexpected alarms: %+v", alarms)
+	}
+
+	if err := r.EtcdMoveLeader(ctx, conn, 255, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.EtcdDefragment(ctx, conn, EtcdctlOptions{Cluster: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.EtcdAlarmDisarm(ctx, conn, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tail := conn.commands[len(conn.commands)-3:]
+	for i, want := range []string{"'move-leader' 'ff'", "'etcdctl' 'defrag' '--cluster'", "'alarm' 'disarm'"} {
+		if !strings.HasSuffix(tail[i], want) {
+			t.Errorf("expected command ending in %s, got: %s", want, tail[i])
+		}
+	}
+}
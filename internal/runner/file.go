@@ -97,6 +97,51 @@ func (r *defaultRunner) ExistsWithOptions(ctx context.Context, conn connector.Co
 	return stat.IsExist, nil
 }
 
+// ExistsBatch reports the existence of every path using a single remote shell loop instead of
+// one round-trip per path.
+func (r *defaultRunner) ExistsBatch(ctx context.Context, conn connector.Connector, paths []string) (map[string]bool, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("connector cannot be nil")
+	}
+	if len(paths) == 0 {
+		return map[string]bool{}, nil
+	}
+	quoted := make([]string, 0, len(paths))
+	for _, p := range paths {
+		quoted = append(quoted, connector.ShellEscape(p))
+	}
+	cmd := fmt.Sprintf(`for p in %s; do if [ -e "$p" ]; then echo 1; else echo 0; fi; done`, strings.Join(quoted, " "))
+	stdout, stderr, err := r.RunWithOptions(ctx, conn, cmd, &connector.ExecOptions{Sudo: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existence of %d paths: %w (stderr: %s)", len(paths), err, string(stderr))
+	}
+	return parseExistsBatchOutput(paths, string(stdout))
+}
+
+// parseExistsBatchOutput maps the one-flag-per-line output of ExistsBatch back to the paths,
+// relying on the shell loop preserving order so that paths with spaces or newlines are safe.
+func parseExistsBatchOutput(paths []string, output string) (map[string]bool, error) {
+	var lines []string
+	if trimmed := strings.TrimSpace(output); trimmed != "" {
+		lines = strings.Split(trimmed, "\n")
+	}
+	if len(lines) != len(paths) {
+		return nil, fmt.Errorf("unexpected existence check output: expected %d lines, got %d", len(paths), len(lines))
+	}
+	result := make(map[string]bool, len(paths))
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case "1":
+			result[paths[i]] = true
+		case "0":
+			result[paths[i]] = false
+		default:
+			return nil, fmt.Errorf("unexpected existence check output for path %s: %q", paths[i], line)
+		}
+	}
+	return result, nil
+}
+
 func (r *defaultRunner) IsDir(ctx context.Context, conn connector.Connector, path string) (bool, error) {
 	if conn == nil {
 		return false, fmt.Errorf("connector cannot be nil")
@@ -170,6 +215,9 @@ func (r *defaultRunner) Mkdirp(ctx context.Context, conn connector.Connector, pa
 	if conn == nil {
 		return fmt.Errorf("connector cannot be nil")
 	}
+	if isWindows(conn) {
+		return conn.Mkdir(ctx, path, permissions)
+	}
 	cmd := fmt.Sprintf("mkdir -p %s", path)
 	_, _, err := r.RunWithOptions(ctx, conn, cmd, &connector.ExecOptions{Sudo: sudo})
 	if err != nil {
@@ -359,6 +407,9 @@ func (r *defaultRunner) Remove(ctx context.Context, conn connector.Connector, pa
 	if conn == nil {
 		return fmt.Errorf("connector cannot be nil")
 	}
+	if isWindows(conn) {
+		return conn.Remove(ctx, path, connector.RemoveOptions{Recursive: recursive, IgnoreNotExist: true})
+	}
 	cmd := "rm -f"
 	if recursive {
 		cmd += "r"
@@ -378,6 +429,10 @@ func (r *defaultRunner) Chmod(ctx context.Context, conn connector.Connector, pat
 	if permissions == "" {
 		return fmt.Errorf("permissions cannot be empty for Chmod")
 	}
+	if isWindows(conn) {
+		// Windows has no POSIX permissions; files inherit the ACLs of their directory.
+		return nil
+	}
 	cmd := fmt.Sprintf("chmod %s %s", permissions, path)
 	_, stderr, err := r.RunWithOptions(ctx, conn, cmd, &connector.ExecOptions{Sudo: sudo})
 	if err != nil {
@@ -35,6 +35,9 @@ func (r *defaultRunner) HelmInstall(ctx context.Context, conn connector.Connecto
 	if opts.CreateNamespace {
 		cmdArgs = append(cmdArgs, "--create-namespace")
 	}
+	if opts.Repo != "" {
+		cmdArgs = append(cmdArgs, "--repo", opts.Repo)
+	}
 	if opts.Version != "" {
 		cmdArgs = append(cmdArgs, "--version", opts.Version)
 	}
@@ -444,6 +447,9 @@ func (r *defaultRunner) HelmUpgrade(ctx context.Context, conn connector.Connecto
 	if opts.Install {
 		cmdArgs = append(cmdArgs, "--install")
 	}
+	if opts.Repo != "" {
+		cmdArgs = append(cmdArgs, "--repo", opts.Repo)
+	}
 	if opts.Namespace != "" {
 		cmdArgs = append(cmdArgs, "--namespace", opts.Namespace)
 	}
@@ -37,6 +37,14 @@ type Facts struct {
 	PackageManager   *PackageInfo
 	InitSystem       *ServiceInfo
 	DefaultInterface string
+	GPUs             []GPUDevice
+	NvidiaSMIPresent bool
+	ContainerRuntime *ContainerRuntimeInfo
+}
+
+// HasGPU reports whether at least one GPU/accelerator was detected on the host.
+func (f *Facts) HasGPU() bool {
+	return f != nil && hasGPU(f.GPUs, f.NvidiaSMIPresent)
 }
 
 type CPUInfo struct {
@@ -66,6 +74,19 @@ type SecurityProfile struct {
 	SELinuxStatus   string `json:"seLinuxStatus"`
 }
 
+type GPUVendor string
+
+const (
+	GPUVendorNVIDIA GPUVendor = "nvidia"
+	GPUVendorAMD    GPUVendor = "amd"
+)
+
+type GPUDevice struct {
+	Vendor     GPUVendor `json:"vendor"`
+	Model      string    `json:"model"`
+	PCIAddress string    `json:"pciAddress"`
+}
+
 type HostFacts struct {
 	OS                *connector.OS
 	Hostname          string
@@ -83,6 +104,39 @@ type HostFacts struct {
 	Security          *SecurityProfile
 	SwapOn            bool
 	KernelModules     map[string]bool
+	GPUs              []GPUDevice
+	NvidiaSMIPresent  bool
+	ContainerRuntime  *ContainerRuntimeInfo
+}
+
+// HasGPU reports whether at least one GPU/accelerator was detected on the host.
+func (f *HostFacts) HasGPU() bool {
+	return f != nil && hasGPU(f.GPUs, f.NvidiaSMIPresent)
+}
+
+// HasGPUVendor reports whether a GPU of the given vendor was detected on the host.
+func (f *HostFacts) HasGPUVendor(vendor GPUVendor) bool {
+	if f == nil {
+		return false
+	}
+	for _, gpu := range f.GPUs {
+		if gpu.Vendor == vendor {
+			return true
+		}
+	}
+	return vendor == GPUVendorNVIDIA && f.NvidiaSMIPresent
+}
+
+func hasGPU(gpus []GPUDevice, nvidiaSMIPresent bool) bool {
+	return len(gpus) > 0 || nvidiaSMIPresent
+}
+
+// ContainerRuntimeInfo describes the container runtime found listening on a host. Facts leave it nil
+// when no runtime socket is present.
+type ContainerRuntimeInfo struct {
+	Type    string `json:"type"`
+	Version string `json:"version,omitempty"`
+	Socket  string `json:"socket"`
 }
 
 type PackageManagerType string
@@ -108,6 +162,7 @@ const (
 	InitSystemUnknown InitSystemType = "unknown"
 	InitSystemSystemd InitSystemType = "systemd"
 	InitSystemSysV    InitSystemType = "sysvinit"
+	InitSystemWindows InitSystemType = "windows"
 )
 
 type ServiceInfo struct {
@@ -127,6 +182,7 @@ type Runner interface {
 	DetermineSudo(ctx context.Context, conn connector.Connector, path string) (bool, error)
 	Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*CommandResult, error)
 	OriginRun(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (string, string, error)
+	ExecWithExitCode(ctx context.Context, conn connector.Connector, cmd string, opts *connector.ExecOptions) (stdout, stderr string, exitCode int, err error)
 	MustRun(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*CommandResult, error)
 	Check(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (bool, error)
 	VerifyChecksum(ctx context.Context, conn connector.Connector, filePath, expectedChecksum, checksumType string, sudo bool) error
@@ -142,6 +198,7 @@ type Runner interface {
 	ListArchiveContents(ctx context.Context, conn connector.Connector, facts *Facts, archivePath string, sudo bool) ([]string, error)
 	Exists(ctx context.Context, conn connector.Connector, path string) (bool, error)
 	ExistsWithOptions(ctx context.Context, conn connector.Connector, path string, opts *connector.StatOptions) (bool, error)
+	ExistsBatch(ctx context.Context, conn connector.Connector, paths []string) (map[string]bool, error)
 	IsDir(ctx context.Context, conn connector.Connector, path string) (bool, error)
 	IsDirWithOptions(ctx context.Context, conn connector.Connector, path string, opts *connector.StatOptions) (bool, error)
 	ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error)
@@ -175,6 +232,7 @@ type Runner interface {
 	IsServiceActive(ctx context.Context, conn connector.Connector, facts *Facts, serviceName string) (bool, error)
 	IsServiceEnabled(ctx context.Context, conn connector.Connector, facts *Facts, serviceName string) (bool, error)
 	DaemonReload(ctx context.Context, conn connector.Connector, facts *Facts) error
+	InstallWindowsService(ctx context.Context, conn connector.Connector, serviceName, displayName, binPath string, args []string) error
 	Render(ctx context.Context, conn connector.Connector, tmpl *template.Template, data interface{}, destPath, permissions string, sudo bool) error
 	UserExists(ctx context.Context, conn connector.Connector, username string) (bool, error)
 	GroupExists(ctx context.Context, conn connector.Connector, groupname string) (bool, error)
@@ -287,6 +345,10 @@ type Runner interface {
 	CtrImportImage(ctx context.Context, conn connector.Connector, namespace, filePath string, allPlatforms bool) error
 	CtrExportImage(ctx context.Context, conn connector.Connector, namespace, imageName, outputFilePath string, allPlatforms bool) error
 	CtrContainerInfo(ctx context.Context, conn connector.Connector, namespace, containerID string) (*CtrContainerInfo, error)
+	NerdctlRun(ctx context.Context, conn connector.Connector, namespace string, options ContainerCreateOptions) (string, error)
+	NerdctlPull(ctx context.Context, conn connector.Connector, namespace, imageName string) error
+	NerdctlLoad(ctx context.Context, conn connector.Connector, namespace, filePath string) error
+	NerdctlComposeUp(ctx context.Context, conn connector.Connector, namespace, composeFile, projectName string) error
 	CrictlListImages(ctx context.Context, conn connector.Connector, filters map[string]string) ([]CrictlImageInfo, error)
 	CrictlPullImage(ctx context.Context, conn connector.Connector, imageName string, authCreds string, sandboxConfigPath string) error
 	CrictlRemoveImage(ctx context.Context, conn connector.Connector, imageName string) error
@@ -317,8 +379,15 @@ type Runner interface {
 	EnsureDefaultContainerdConfig(ctx context.Context, conn connector.Connector, facts *Facts) error
 	GetContainerdConfig(ctx context.Context, conn connector.Connector) (*ContainerdConfigOptions, error)
 	ConfigureContainerd(ctx context.Context, conn connector.Connector, facts *Facts, opts ContainerdConfigOptions, restartService bool) error
+	RenderContainerdConfig(opts ContainerdConfigOptions, current []byte) ([]byte, error)
 	EnsureContainerdService(ctx context.Context, conn connector.Connector, facts *Facts) error
 	ConfigureContainerdDropIn(ctx context.Context, conn connector.Connector, facts *Facts, content string) error
+	RenderCRIOConfig(opts CRIOConfigOptions, current []byte) ([]byte, error)
+	ConfigureCRIO(ctx context.Context, conn connector.Connector, facts *Facts, opts CRIOConfigOptions, configPath string, restartService bool) error
+	RenderCRIORegistriesConfig(opts CRIORegistriesOptions, current []byte) ([]byte, error)
+	RenderContainerdRegistryHosts(opts ContainerdRegistryHostsOptions) ([]byte, error)
+	ConfigureContainerdRegistryHosts(ctx context.Context, conn connector.Connector, facts *Facts, certsDir, registry string, opts ContainerdRegistryHostsOptions, restartService bool) (bool, error)
+	ConfigureCRIORegistries(ctx context.Context, conn connector.Connector, facts *Facts, opts CRIORegistriesOptions, configPath string, restartService bool) error
 	HelmInstall(ctx context.Context, conn connector.Connector, releaseName, chartPath string, opts HelmInstallOptions) error
 	HelmUninstall(ctx context.Context, conn connector.Connector, releaseName string, opts HelmUninstallOptions) error
 	HelmList(ctx context.Context, conn connector.Connector, opts HelmListOptions) ([]HelmReleaseInfo, error)
@@ -339,12 +408,13 @@ type Runner interface {
 	HelmDependencyUpdate(ctx context.Context, conn connector.Connector, chartPath string, opts HelmDependencyOptions) error
 	HelmLint(ctx context.Context, conn connector.Connector, chartPath string, opts HelmLintOptions) (string, error)
 	KubectlApply(ctx context.Context, conn connector.Connector, opts KubectlApplyOptions) (string, error)
+	KubectlDiff(ctx context.Context, conn connector.Connector, opts KubectlDiffOptions) (diff string, hasDiff bool, err error)
 	KubectlGet(ctx context.Context, conn connector.Connector, resourceType string, resourceName string, opts KubectlGetOptions) (string, error)
 	KubectlDescribe(ctx context.Context, conn connector.Connector, resourceType string, resourceName string, opts KubectlDescribeOptions) (string, error)
 	KubectlDelete(ctx context.Context, conn connector.Connector, resourceType string, resourceName string, opts KubectlDeleteOptions) error
 	KubectlLogs(ctx context.Context, conn connector.Connector, podName string, opts KubectlLogOptions) (string, error)
 	KubectlExec(ctx context.Context, conn connector.Connector, podName string, opts KubectlExecOptions, command ...string) (string, error)
-	KubectlVersion(ctx context.Context, conn connector.Connector) (*KubectlVersionInfo, error)
+	KubectlVersion(ctx context.Context, conn connector.Connector, opts KubectlVersionOptions) (*KubectlVersionInfo, error)
 	KubectlClusterInfo(ctx context.Context, conn connector.Connector, kubeconfigPath string) (string, error)
 	KubectlGetNodes(ctx context.Context, conn connector.Connector, opts KubectlGetOptions) ([]KubectlNodeInfo, error)
 	KubectlGetPods(ctx context.Context, conn connector.Connector, opts KubectlGetOptions) ([]KubectlPodInfo, error)
@@ -385,6 +455,17 @@ type Runner interface {
 	KubectlLabel(ctx context.Context, conn connector.Connector, resourceType, resourceName string, labels map[string]string, overwrite bool, opts KubectlLabelOptions) error
 	KubectlAnnotate(ctx context.Context, conn connector.Connector, resourceType, resourceName string, annotations map[string]string, overwrite bool, opts KubectlAnnotateOptions) error
 	KubectlPatch(ctx context.Context, conn connector.Connector, resourceType, resourceName string, patchType, patchContent string, opts KubectlPatchOptions) error
+	EtcdMemberList(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdMember, error)
+	EtcdMemberAdd(ctx context.Context, conn connector.Connector, name string, peerURLs []string, learner bool, opts EtcdctlOptions) (*EtcdMember, error)
+	EtcdMemberRemove(ctx context.Context, conn connector.Connector, memberID uint64, opts EtcdctlOptions) error
+	EtcdEndpointHealth(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdEndpointHealth, error)
+	EtcdEndpointStatus(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdEndpointStatus, error)
+	EtcdSnapshotSave(ctx context.Context, conn connector.Connector, snapshotPath string, opts EtcdctlOptions) error
+	EtcdSnapshotStatus(ctx context.Context, conn connector.Connector, snapshotPath string, opts EtcdctlOptions) (*EtcdSnapshotStatus, error)
+	EtcdMoveLeader(ctx context.Context, conn connector.Connector, targetMemberID uint64, opts EtcdctlOptions) error
+	EtcdAlarmList(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) ([]EtcdAlarm, error)
+	EtcdAlarmDisarm(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) error
+	EtcdDefragment(ctx context.Context, conn connector.Connector, opts EtcdctlOptions) error
 }
 
 type HelmInstallOptions struct {
@@ -392,6 +473,7 @@ type HelmInstallOptions struct {
 	KubeconfigPath  string
 	ValuesFiles     []string
 	SetValues       []string
+	Repo            string
 	Version         string
 	CreateNamespace bool
 	Wait            bool
@@ -615,6 +697,16 @@ type KubectlApplyOptions struct {
 	Sudo           bool
 }
 
+type KubectlDiffOptions struct {
+	KubeconfigPath string
+	Namespace      string
+	ServerSide     bool
+	Filenames      []string
+	FileContent    string
+	Recursive      bool
+	Sudo           bool
+}
+
 type KubectlGetOptions struct {
 	KubeconfigPath string
 	Namespace      string
@@ -717,6 +809,11 @@ type KubectlNodeInfo struct {
 		PodCIDR       string `json:"podCIDR"`
 		ProviderID    string `json:"providerID"`
 		Unschedulable bool   `json:"unschedulable,omitempty"`
+		Taints        []struct {
+			Key    string `json:"key"`
+			Value  string `json:"value,omitempty"`
+			Effect string `json:"effect"`
+		} `json:"taints,omitempty"`
 	} `json:"spec"`
 	Status struct {
 		Capacity    map[string]string `json:"capacity"`
@@ -952,6 +1049,82 @@ type ContainerdConfigOptions struct {
 	DisabledPlugins *[]string                `toml:"disabled_plugins,omitempty" json:"disabled_plugins,omitempty"`
 	PluginConfigs   *map[string]interface{}  `toml:"plugins,omitempty" json:"plugins,omitempty"`
 	RegistryMirrors map[string][]string      `toml:"-" json:"-"`
+	// Runtimes are merged into the runtimes table of the CRI plugin, keyed by runtime name.
+	Runtimes map[string]ContainerdRuntimeOptions `toml:"-" json:"-"`
+}
+
+type ContainerdRuntimeOptions struct {
+	RuntimeType string                 `json:"runtime_type,omitempty"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+}
+
+// CRIOConfigOptions are merged into a CRI-O config file such as crio.conf or a crio.conf.d drop-in.
+// Nil and empty fields leave the corresponding setting untouched.
+type CRIOConfigOptions struct {
+	Root            *string
+	RunRoot         *string
+	StorageDriver   *string
+	StorageOptions  []string
+	LogLevel        *string
+	LogFilter       *string
+	CgroupManager   *string
+	Conmon          *string
+	DefaultRuntime  *string
+	Runtimes        map[string]CRIORuntimeOptions
+	PauseImage      *string
+	SignaturePolicy *string
+	NetworkDir      *string
+	PluginDirs      []string
+	// Extra is deep-merged into the document last, for settings without a dedicated field.
+	Extra map[string]interface{}
+}
+
+type CRIORuntimeOptions struct {
+	RuntimePath string
+	RuntimeType string
+	RuntimeRoot string
+	MonitorPath string
+}
+
+// CRIORegistriesOptions are merged into a containers-registries.conf(5) file in the v2 format.
+// Registries replace existing entries with the same prefix.
+type CRIORegistriesOptions struct {
+	UnqualifiedSearchRegistries []string
+	Registries                  []CRIORegistry
+}
+
+type CRIORegistry struct {
+	Prefix   string
+	Location string
+	Insecure bool
+	Blocked  bool
+	Mirrors  []CRIORegistryMirror
+}
+
+type CRIORegistryMirror struct {
+	Location string
+	Insecure bool
+}
+
+// ContainerdRegistryHostsOptions describe the hosts.toml of one registry below the containerd
+// config_path directory. Hosts are tried in order before Server, the upstream registry.
+type ContainerdRegistryHostsOptions struct {
+	Server string
+	Hosts  []ContainerdRegistryHost
+}
+
+type ContainerdRegistryHost struct {
+	URL          string
+	Capabilities []string
+	SkipVerify   bool
+	CAFile       string
+	ClientCert   string
+	ClientKey    string
+	// Username and Password, or Auth as base64 encoded "user:password", are sent as a basic
+	// Authorization header.
+	Username string
+	Password string
+	Auth     string
 }
 
 type ContainerdGRPCConfig struct {
@@ -1683,6 +1856,12 @@ type KubectlScaleOptions struct {
 	// Add other common scale flags if needed
 }
 
+// KubectlVersionOptions defines options for kubectl version.
+type KubectlVersionOptions struct {
+	KubeconfigPath string
+	Sudo           bool
+}
+
 // KubectlConfigViewOptions defines options for kubectl config view.
 type KubectlConfigViewOptions struct {
 	KubeconfigPath string
@@ -1763,3 +1942,64 @@ type CrictlConfigOptions struct {
 	Debug             *bool  `yaml:"debug,omitempty" json:"debug,omitempty"`
 	PullImageOnCreate *bool  `yaml:"pull-image-on-create,omitempty" json:"pull-image-on-create,omitempty"`
 }
+
+// EtcdctlOptions selects the etcdctl binary, the endpoints and the client TLS material used by
+// the Etcd* runner methods.
+type EtcdctlOptions struct {
+	EtcdctlPath string
+	Endpoints   []string
+	CACert      string
+	Cert        string
+	Key         string
+	// Cluster runs endpoint commands against every member listed by the cluster instead of
+	// only Endpoints.
+	Cluster        bool
+	DialTimeout    time.Duration
+	CommandTimeout time.Duration
+	Sudo           bool
+}
+
+type EtcdMember struct {
+	ID         uint64   `json:"ID"`
+	Name       string   `json:"name"`
+	PeerURLs   []string `json:"peerURLs"`
+	ClientURLs []string `json:"clientURLs"`
+	IsLearner  bool     `json:"isLearner"`
+}
+
+type EtcdEndpointHealth struct {
+	Endpoint string `json:"endpoint"`
+	Health   bool   `json:"health"`
+	Took     string `json:"took"`
+	Error    string `json:"error"`
+}
+
+type EtcdEndpointStatus struct {
+	Endpoint  string
+	MemberID  uint64
+	LeaderID  uint64
+	Version   string
+	DBSize    int64
+	RaftTerm  uint64
+	RaftIndex uint64
+	IsLearner bool
+	Errors    []string
+}
+
+// IsLeader reports whether the endpoint is the current raft leader.
+func (s EtcdEndpointStatus) IsLeader() bool {
+	return s.MemberID != 0 && s.MemberID == s.LeaderID
+}
+
+type EtcdSnapshotStatus struct {
+	Hash      uint32 `json:"hash"`
+	Revision  int64  `json:"revision"`
+	TotalKey  int    `json:"totalKey"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+type EtcdAlarm struct {
+	MemberID uint64
+	// Alarm is NOSPACE or CORRUPT.
+	Alarm string
+}
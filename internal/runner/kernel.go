@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var kernelVersionRegex = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// KernelVersion is the numeric part of a Linux kernel release, e.g. 5.15.0 for "5.15.0-86-generic".
+type KernelVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseKernelVersion parses a kernel release as printed by `uname -r`. Distro suffixes such as
+// "-86-generic" or ".el7.x86_64" are ignored, and a missing minor or patch number counts as 0.
+func ParseKernelVersion(release string) (KernelVersion, error) {
+	m := kernelVersionRegex.FindStringSubmatch(strings.TrimPrefix(strings.TrimSpace(release), "v"))
+	if m == nil {
+		return KernelVersion{}, fmt.Errorf("invalid kernel version '%s'", release)
+	}
+	var parts [3]int
+	for i, s := range m[1:] {
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return KernelVersion{}, fmt.Errorf("invalid kernel version '%s': %w", release, err)
+		}
+		parts[i] = n
+	}
+	return KernelVersion{Major: parts[0], Minor: parts[1], Patch: parts[2]}, nil
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than other.
+func (v KernelVersion) Compare(other KernelVersion) int {
+	for _, d := range [3]int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v KernelVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// KernelAtLeast reports whether the kernel in facts is at least minimum, e.g. "5.8".
+// Missing or unparsable versions never satisfy the requirement.
+func KernelAtLeast(facts *Facts, minimum string) bool {
+	if facts == nil {
+		return false
+	}
+	release := facts.Kernel
+	if release == "" && facts.OS != nil {
+		release = facts.OS.Kernel
+	}
+	actual, err := ParseKernelVersion(release)
+	if err != nil {
+		return false
+	}
+	required, err := ParseKernelVersion(minimum)
+	if err != nil {
+		return false
+	}
+	return actual.Compare(required) >= 0
+}
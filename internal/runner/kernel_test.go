@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+func TestParseKernelVersion(t *testing.T) {
+	tests := []struct {
+		release  string
+		expected KernelVersion
+		wantErr  bool
+	}{
+		{release: "5.15.0-86-generic", expected: KernelVersion{5, 15, 0}},
+		{release: "3.10.0-1160.el7.x86_64", expected: KernelVersion{3, 10, 0}},
+		{release: "4.18.0-513.5.1.el8_9.x86_64", expected: KernelVersion{4, 18, 0}},
+		{release: "6.1.55+", expected: KernelVersion{6, 1, 55}},
+		{release: "6.8.0-rc3", expected: KernelVersion{6, 8, 0}},
+		{release: "5.10.0-60.18.0.50.oe2203.aarch64\n", expected: KernelVersion{5, 10, 0}},
+		{release: "5.8", expected: KernelVersion{5, 8, 0}},
+		{release: "6", expected: KernelVersion{6, 0, 0}},
+		{release: "", wantErr: true},
+		{release: "generic", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.release, func(t *testing.T) {
+			v, err := ParseKernelVersion(tt.release)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && v != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestKernelAtLeast(t *testing.T) {
+	tests := []struct {
+		name     string
+		facts    *Facts
+		minimum  string
+		expected bool
+	}{
+		{name: "newer minor", facts: &Facts{Kernel: "5.15.0-86-generic"}, minimum: "5.8", expected: true},
+		{name: "equal", facts: &Facts{Kernel: "5.8.0-63-generic"}, minimum: "5.8", expected: true},
+		{name: "older minor", facts: &Facts{Kernel: "5.4.0-150-generic"}, minimum: "5.8", expected: false},
+		{name: "minor compared numerically", facts: &Facts{Kernel: "5.10.0"}, minimum: "5.8", expected: true},
+		{name: "older major", facts: &Facts{Kernel: "3.10.0-1160.el7.x86_64"}, minimum: "4.19.57", expected: false},
+		{name: "patch below threshold", facts: &Facts{Kernel: "4.19.56"}, minimum: "4.19.57", expected: false},
+		{name: "patch above threshold", facts: &Facts{Kernel: "4.19.90-24.4.v2101.ky10.x86_64"}, minimum: "4.19.57", expected: true},
+		{name: "falls back to OS kernel", facts: &Facts{OS: &connector.OS{Kernel: "6.1.0-13-amd64"}}, minimum: "5.8", expected: true},
+		{name: "unknown kernel", facts: &Facts{}, minimum: "5.8", expected: false},
+		{name: "nil facts", facts: nil, minimum: "5.8", expected: false},
+		{name: "invalid minimum", facts: &Facts{Kernel: "5.15.0"}, minimum: "latest", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KernelAtLeast(tt.facts, tt.minimum); got != tt.expected {
+				t.Errorf("KernelAtLeast(%v, %q) = %v, want %v", tt.facts, tt.minimum, got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package runner
 
 import (
 	"context"
+	"crypto"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"os"
@@ -89,6 +92,72 @@ func (r *defaultRunner) KubectlApply(ctx context.Context, conn connector.Connect
 	return string(stdout), nil
 }
 
+// KubectlDiff shows the difference between the live objects and the given manifests. kubectl diff exits
+// with 0 when nothing differs, 1 when there are differences and greater than 1 when it fails.
+func (r *defaultRunner) KubectlDiff(ctx context.Context, conn connector.Connector, opts KubectlDiffOptions) (string, bool, error) {
+	if conn == nil {
+		return "", false, errors.New("connector cannot be nil")
+	}
+	if len(opts.Filenames) == 0 && opts.FileContent == "" {
+		return "", false, errors.New("Filenames or FileContent must be provided")
+	}
+	if helpers.ContainsString(opts.Filenames, "-") && opts.FileContent == "" {
+		return "", false, errors.New("FileContent must be provided with filename '-'")
+	}
+
+	filenames := opts.Filenames
+	if opts.FileContent != "" {
+		remoteTempPath := fmt.Sprintf("/tmp/kubexm-diff-%d.yaml", time.Now().UnixNano())
+		if err := r.WriteFile(ctx, conn, []byte(opts.FileContent), remoteTempPath, "0600", opts.Sudo); err != nil {
+			return "", false, errors.Wrapf(err, "failed to upload manifest to temporary file %s", remoteTempPath)
+		}
+		defer func() {
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := r.Remove(cleanupCtx, conn, remoteTempPath, opts.Sudo, false); err != nil {
+				r.logger.Errorf("Warning: failed to clean up temporary file %s: %v", remoteTempPath, err)
+			}
+		}()
+		filenames = append([]string{}, opts.Filenames...)
+		filenames = append(filenames, remoteTempPath)
+	}
+
+	cmdArgs := []string{"kubectl", "diff"}
+	for _, filename := range filenames {
+		if filename == "-" {
+			continue
+		}
+		cmdArgs = append(cmdArgs, "-f", filename)
+	}
+	if opts.Recursive {
+		cmdArgs = append(cmdArgs, "--recursive")
+	}
+	if opts.ServerSide {
+		cmdArgs = append(cmdArgs, "--server-side")
+	}
+	if opts.Namespace != "" {
+		cmdArgs = append(cmdArgs, "--namespace", opts.Namespace)
+	}
+	if opts.KubeconfigPath != "" {
+		cmdArgs = append(cmdArgs, "--kubeconfig", opts.KubeconfigPath)
+	}
+
+	cmd := strings.Join(cmdArgs, " ")
+	execOptions := &connector.ExecOptions{Sudo: opts.Sudo, Timeout: DefaultKubectlTimeout}
+	stdout, stderr, exitCode, err := r.ExecWithExitCode(ctx, conn, cmd, execOptions)
+	if err != nil {
+		return "", false, errors.Wrap(err, "kubectl diff failed")
+	}
+	switch exitCode {
+	case 0:
+		return stdout, false, nil
+	case 1:
+		return stdout, true, nil
+	default:
+		return "", false, errors.Errorf("kubectl diff failed with exit code %d. Stdout: %s, Stderr: %s", exitCode, stdout, stderr)
+	}
+}
+
 func (r *defaultRunner) KubectlGet(ctx context.Context, conn connector.Connector, resourceType, resourceName string, opts KubectlGetOptions) (string, error) {
 	if conn == nil {
 		return "", errors.New("connector cannot be nil")
@@ -241,11 +310,15 @@ func (r *defaultRunner) KubectlDelete(ctx context.Context, conn connector.Connec
 	return nil
 }
 
-func (r *defaultRunner) KubectlVersion(ctx context.Context, conn connector.Connector) (*KubectlVersionInfo, error) {
+func (r *defaultRunner) KubectlVersion(ctx context.Context, conn connector.Connector, opts KubectlVersionOptions) (*KubectlVersionInfo, error) {
 	if conn == nil {
 		return nil, errors.New("connector cannot be nil")
 	}
-	stdout, stderr, err := conn.Exec(ctx, "kubectl version -o json", &connector.ExecOptions{Sudo: false, Timeout: 30 * time.Second})
+	cmdArgs := []string{"kubectl", "version", "-o", "json"}
+	if opts.KubeconfigPath != "" {
+		cmdArgs = append(cmdArgs, "--kubeconfig", opts.KubeconfigPath)
+	}
+	stdout, stderr, err := conn.Exec(ctx, strings.Join(cmdArgs, " "), &connector.ExecOptions{Sudo: opts.Sudo, Timeout: 30 * time.Second})
 	if err != nil {
 		var versionInfo KubectlVersionInfo
 		if len(stdout) > 0 && json.Unmarshal(stdout, &versionInfo) == nil && versionInfo.ClientVersion.GitVersion != "" {
@@ -1064,6 +1137,10 @@ func (r *defaultRunner) KubectlCreateSecretDockerRegistry(ctx context.Context, c
 	return nil
 }
 
+// KubectlCreateSecretTLS creates a TLS secret from certPath and keyPath. Before kubectl is invoked the
+// pair is validated: both files must be PEM encoded, the key must belong to the certificate and the
+// certificate must be within its validity period. Paths that do not exist on the host but exist on the
+// local machine are uploaded to a temporary file first.
 func (r *defaultRunner) KubectlCreateSecretTLS(ctx context.Context, conn connector.Connector, namespace, name, certPath, keyPath string, opts KubectlCreateOptions) error {
 	if conn == nil {
 		return errors.New("connector cannot be nil")
@@ -1071,10 +1148,40 @@ func (r *defaultRunner) KubectlCreateSecretTLS(ctx context.Context, conn connect
 	if name == "" || certPath == "" || keyPath == "" {
 		return errors.New("name, certPath, keyPath required")
 	}
+
+	var uploaded []string
+	defer func() {
+		for _, remoteTempPath := range uploaded {
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := r.Remove(cleanupCtx, conn, remoteTempPath, opts.Sudo, false); err != nil {
+				r.logger.Errorf("Warning: failed to clean up temporary file %s: %v", remoteTempPath, err)
+			}
+			cancel()
+		}
+	}()
+
+	certPEM, remoteCertPath, err := r.resolveTLSSecretFile(ctx, conn, certPath, "cert", opts.Sudo)
+	if err != nil {
+		return err
+	}
+	if remoteCertPath != certPath {
+		uploaded = append(uploaded, remoteCertPath)
+	}
+	keyPEM, remoteKeyPath, err := r.resolveTLSSecretFile(ctx, conn, keyPath, "key", opts.Sudo)
+	if err != nil {
+		return err
+	}
+	if remoteKeyPath != keyPath {
+		uploaded = append(uploaded, remoteKeyPath)
+	}
+	if err := validateTLSKeyPair(certPEM, keyPEM, time.Now()); err != nil {
+		return errors.Wrapf(err, "invalid TLS pair for secret %s (cert %s, key %s)", name, certPath, keyPath)
+	}
+
 	var cmdArgs []string
 	cmdArgs = append(cmdArgs, "kubectl", "create", "secret", "tls", name)
-	cmdArgs = append(cmdArgs, fmt.Sprintf("--cert=%s", certPath))
-	cmdArgs = append(cmdArgs, fmt.Sprintf("--key=%s", keyPath))
+	cmdArgs = append(cmdArgs, fmt.Sprintf("--cert=%s", remoteCertPath))
+	cmdArgs = append(cmdArgs, fmt.Sprintf("--key=%s", remoteKeyPath))
 	if namespace != "" {
 		cmdArgs = append(cmdArgs, "--namespace", namespace)
 	}
@@ -1095,6 +1202,35 @@ func (r *defaultRunner) KubectlCreateSecretTLS(ctx context.Context, conn connect
 	return nil
 }
 
+// resolveTLSSecretFile returns the content of path and the path kubectl should use on the host. A file
+// that only exists locally is uploaded to a temporary path, which the caller is responsible for removing.
+func (r *defaultRunner) resolveTLSSecretFile(ctx context.Context, conn connector.Connector, path, kind string, sudo bool) ([]byte, string, error) {
+	exists, err := r.Exists(ctx, conn, path)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to check TLS %s file %s", kind, path)
+	}
+	if exists {
+		content, err := r.ReadFileWithOptions(ctx, conn, path, &connector.FileTransferOptions{Sudo: sudo})
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to read TLS %s file %s", kind, path)
+		}
+		return content, path, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", errors.Errorf("TLS %s file %s not found on the host or locally", kind, path)
+		}
+		return nil, "", errors.Wrapf(err, "failed to read local TLS %s file %s", kind, path)
+	}
+	remotePath := fmt.Sprintf("/tmp/kubexm-tls-%s-%d.pem", kind, time.Now().UnixNano())
+	if err := r.WriteFile(ctx, conn, content, remotePath, "0600", sudo); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to upload TLS %s file %s to %s", kind, path, remotePath)
+	}
+	return content, remotePath, nil
+}
+
 func (r *defaultRunner) KubectlCreateConfigMap(ctx context.Context, conn connector.Connector, namespace, name string, fromLiterals map[string]string, fromFiles map[string]string, fromEnvFile string, opts KubectlCreateOptions) error {
 	if conn == nil {
 		return errors.New("connector cannot be nil")
@@ -1723,3 +1859,48 @@ func KubectlGetResources[T any](r *defaultRunner, ctx context.Context, conn conn
 	}
 	return list.Items, nil
 }
+
+// validateTLSKeyPair checks that certPEM and keyPEM hold a PEM certificate and a PEM private key whose
+// public key matches the certificate, and that the certificate is valid at now.
+func validateTLSKeyPair(certPEM, keyPEM []byte, now time.Time) error {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil || certBlock.Type != "CERTIFICATE" {
+		return errors.New("certificate is not a PEM encoded CERTIFICATE block")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse certificate")
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil || !strings.HasSuffix(keyBlock.Type, "PRIVATE KEY") {
+		return errors.New("key is not a PEM encoded PRIVATE KEY block")
+	}
+	var key crypto.Signer
+	if parsed, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes); err == nil {
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return errors.Errorf("unsupported private key type %T", parsed)
+		}
+		key = signer
+	} else if rsaKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes); err == nil {
+		key = rsaKey
+	} else if ecKey, err := x509.ParseECPrivateKey(keyBlock.Bytes); err == nil {
+		key = ecKey
+	} else {
+		return errors.New("failed to parse private key as PKCS#8, PKCS#1 or EC key")
+	}
+
+	certPublicKey, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !certPublicKey.Equal(key.Public()) {
+		return errors.Errorf("private key does not match the certificate for %q", cert.Subject.CommonName)
+	}
+
+	if now.After(cert.NotAfter) {
+		return errors.Errorf("certificate %q expired at %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return errors.Errorf("certificate %q is not valid before %s", cert.Subject.CommonName, cert.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
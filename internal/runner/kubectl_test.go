@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+// fakeTLSConnector holds remote files in memory and records executed commands.
+type fakeTLSConnector struct {
+	connector.Connector
+	files    map[string][]byte
+	commands []string
+}
+
+func (c *fakeTLSConnector) Stat(ctx context.Context, path string) (*connector.FileStat, error) {
+	_, ok := c.files[path]
+	return &connector.FileStat{Name: filepath.Base(path), IsExist: ok}, nil
+}
+
+func (c *fakeTLSConnector) ReadFileWithOptions(ctx context.Context, path string, opts *connector.FileTransferOptions) ([]byte, error) {
+	return c.files[path], nil
+}
+
+func (c *fakeTLSConnector) WriteFile(ctx context.Context, content []byte, destPath string, opts *connector.FileTransferOptions) error {
+	c.files[destPath] = content
+	return nil
+}
+
+func (c *fakeTLSConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.commands = append(c.commands, cmd)
+	return nil, nil, nil
+}
+
+func newTestTLSPair(t *testing.T, notBefore, notAfter time.Time) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ingress.example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestKubectlCreateSecretTLS(t *testing.T) {
+	now := time.Now()
+	validCert, validKey := newTestTLSPair(t, now.Add(-time.Hour), now.Add(24*time.Hour))
+	_, otherKey := newTestTLSPair(t, now.Add(-time.Hour), now.Add(24*time.Hour))
+	expiredCert, expiredKey := newTestTLSPair(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+	tests := []struct {
+		name          string
+		cert          []byte
+		key           []byte
+		expectedError string
+	}{
+		{name: "valid pair", cert: validCert, key: validKey},
+		{name: "mismatched key", cert: validCert, key: otherKey, expectedError: "private key does not match the certificate"},
+		{name: "expired certificate", cert: expiredCert, key: expiredKey, expectedError: "expired at"},
+		{name: "not PEM", cert: []byte("not a certificate"), key: validKey, expectedError: "not a PEM encoded CERTIFICATE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			certPath := filepath.Join(dir, "tls.crt")
+			keyPath := filepath.Join(dir, "tls.key")
+			if err := os.WriteFile(certPath, tt.cert, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(keyPath, tt.key, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			conn := &fakeTLSConnector{files: map[string][]byte{}}
+			err := NewRunner().KubectlCreateSecretTLS(context.Background(), conn, "default", "ingress-tls", certPath, keyPath, KubectlCreateOptions{Validate: true})
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				for _, cmd := range conn.commands {
+					if strings.HasPrefix(cmd, "kubectl create secret") {
+						t.Errorf("expected no secret to be created, got %q", cmd)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(conn.commands) == 0 || !strings.HasPrefix(conn.commands[0], "kubectl create secret tls ingress-tls --cert=/tmp/kubexm-tls-cert-") {
+				t.Fatalf("expected kubectl to use the uploaded cert, got %v", conn.commands)
+			}
+			if strings.Contains(conn.commands[0], certPath) {
+				t.Errorf("expected the local path to be replaced by the uploaded path, got %q", conn.commands[0])
+			}
+		})
+	}
+}
+
+// fakeVersionConnector answers kubectl version and records how it was invoked.
+type fakeVersionConnector struct {
+	connector.Connector
+	cmd  string
+	sudo bool
+}
+
+func (c *fakeVersionConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.cmd, c.sudo = cmd, opts.Sudo
+	return []byte(`{"clientVersion":{"gitVersion":"v1.28.5"},"serverVersion":{"gitVersion":"v1.28.5"}}`), nil, nil
+}
+
+func TestKubectlVersion(t *testing.T) {
+	conn := &fakeVersionConnector{}
+	info, err := NewRunner().KubectlVersion(context.Background(), conn, KubectlVersionOptions{KubeconfigPath: "/etc/kubernetes/admin.conf", Sudo: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conn.cmd != "kubectl version -o json --kubeconfig /etc/kubernetes/admin.conf" || !conn.sudo {
+		t.Errorf("expected the admin kubeconfig to be read with sudo, got %q sudo=%v", conn.cmd, conn.sudo)
+	}
+	if info.ServerVersion == nil || info.ServerVersion.GitVersion != "v1.28.5" {
+		t.Errorf("expected the server version to be parsed, got %+v", info.ServerVersion)
+	}
+}
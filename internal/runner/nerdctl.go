@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultNerdctlTimeout        = 2 * time.Minute
+	DefaultNerdctlPullTimeout    = 15 * time.Minute
+	DefaultNerdctlComposeTimeout = 10 * time.Minute
+)
+
+// nerdctlCommand builds a nerdctl command line with every argument shell quoted. An empty
+// namespace leaves the choice to nerdctl, which uses "default".
+func nerdctlCommand(namespace string, args ...string) string {
+	parts := []string{"nerdctl"}
+	if namespace != "" {
+		parts = append(parts, "--namespace", connector.ShellEscape(namespace))
+	}
+	for _, arg := range args {
+		parts = append(parts, connector.ShellEscape(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// NerdctlRun starts a detached container with nerdctl and returns its ID. It takes the same options
+// as CreateContainer, so steps written for Docker can run on containerd-only hosts.
+func (r *defaultRunner) NerdctlRun(ctx context.Context, conn connector.Connector, namespace string, options ContainerCreateOptions) (string, error) {
+	if conn == nil {
+		return "", errors.New("connector cannot be nil")
+	}
+	args, err := nerdctlRunArgs(options)
+	if err != nil {
+		return "", err
+	}
+	stdout, stderr, err := conn.Exec(ctx, nerdctlCommand(namespace, args...), &connector.ExecOptions{Sudo: true, Timeout: DefaultNerdctlTimeout})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to run container from image %s. Stderr: %s", options.ImageName, string(stderr))
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+func nerdctlRunArgs(options ContainerCreateOptions) ([]string, error) {
+	if strings.TrimSpace(options.ImageName) == "" {
+		return nil, errors.New("options.ImageName cannot be empty")
+	}
+	if len(options.VolumesFrom) > 0 {
+		return nil, errors.New("nerdctl does not support volumes-from")
+	}
+	if options.HealthCheck != nil {
+		return nil, errors.New("nerdctl does not support container health checks")
+	}
+
+	args := []string{"run", "-d"}
+	if strings.TrimSpace(options.ContainerName) != "" {
+		args = append(args, "--name", options.ContainerName)
+	}
+	for _, portMapping := range options.Ports {
+		var p string
+		if strings.TrimSpace(portMapping.HostIP) != "" {
+			p += portMapping.HostIP + ":"
+		}
+		if strings.TrimSpace(portMapping.HostPort) != "" {
+			p += portMapping.HostPort + ":"
+		}
+		p += portMapping.ContainerPort
+		if strings.TrimSpace(portMapping.Protocol) != "" {
+			p += "/" + portMapping.Protocol
+		}
+		args = append(args, "-p", p)
+	}
+	for _, volumeMount := range options.Volumes {
+		if strings.TrimSpace(volumeMount.Source) == "" || strings.TrimSpace(volumeMount.Destination) == "" {
+			return nil, errors.New("volume source and destination cannot be empty")
+		}
+		v := volumeMount.Source + ":" + volumeMount.Destination
+		if strings.TrimSpace(volumeMount.Mode) != "" {
+			v += ":" + volumeMount.Mode
+		}
+		args = append(args, "-v", v)
+	}
+	for _, envVar := range options.EnvVars {
+		if strings.TrimSpace(envVar) != "" {
+			args = append(args, "-e", envVar)
+		}
+	}
+	if options.WorkingDir != "" {
+		args = append(args, "-w", options.WorkingDir)
+	}
+	if options.User != "" {
+		args = append(args, "-u", options.User)
+	}
+	if strings.TrimSpace(options.RestartPolicy) != "" {
+		args = append(args, "--restart", options.RestartPolicy)
+	}
+	if options.NetworkMode != "" {
+		args = append(args, "--network", options.NetworkMode)
+	}
+	for _, host := range options.ExtraHosts {
+		args = append(args, "--add-host", host)
+	}
+	for _, key := range sortedKeys(options.Labels) {
+		args = append(args, "--label", key+"="+options.Labels[key])
+	}
+	if options.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, capability := range options.CapAdd {
+		args = append(args, "--cap-add", capability)
+	}
+	for _, capability := range options.CapDrop {
+		args = append(args, "--cap-drop", capability)
+	}
+	for _, opt := range options.SecurityOpt {
+		args = append(args, "--security-opt", opt)
+	}
+	for _, key := range sortedKeys(options.Sysctls) {
+		args = append(args, "--sysctl", key+"="+options.Sysctls[key])
+	}
+	for _, server := range options.DNSServers {
+		args = append(args, "--dns", server)
+	}
+	for _, domain := range options.DNSSearchDomains {
+		args = append(args, "--dns-search", domain)
+	}
+	if options.Resources.CPUShares > 0 {
+		args = append(args, "--cpu-shares", strconv.FormatInt(options.Resources.CPUShares, 10))
+	}
+	if options.Resources.NanoCPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(float64(options.Resources.NanoCPUs)/1e9, 'f', -1, 64))
+	}
+	if options.Resources.Memory > 0 {
+		args = append(args, "--memory", strconv.FormatInt(options.Resources.Memory, 10))
+	}
+	if options.Resources.PidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.FormatInt(options.Resources.PidsLimit, 10))
+	}
+	if options.Resources.BlkioWeight > 0 {
+		args = append(args, "--blkio-weight", strconv.FormatUint(uint64(options.Resources.BlkioWeight), 10))
+	}
+	if options.AutoRemove {
+		args = append(args, "--rm")
+	}
+
+	// As with docker, the entrypoint flag only takes the executable; the remaining entrypoint
+	// arguments go in front of the command.
+	command := options.Command
+	if len(options.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", options.Entrypoint[0])
+		command = append(append([]string{}, options.Entrypoint[1:]...), options.Command...)
+	}
+	args = append(args, options.ImageName)
+	return append(args, command...), nil
+}
+
+func (r *defaultRunner) NerdctlPull(ctx context.Context, conn connector.Connector, namespace, imageName string) error {
+	if conn == nil {
+		return errors.New("connector cannot be nil")
+	}
+	if strings.TrimSpace(imageName) == "" {
+		return errors.New("imageName cannot be empty")
+	}
+	_, stderr, err := conn.Exec(ctx, nerdctlCommand(namespace, "pull", imageName), &connector.ExecOptions{Sudo: true, Timeout: DefaultNerdctlPullTimeout})
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull image %s. Stderr: %s", imageName, string(stderr))
+	}
+	return nil
+}
+
+// NerdctlLoad imports an image archive created by docker save or nerdctl save.
+func (r *defaultRunner) NerdctlLoad(ctx context.Context, conn connector.Connector, namespace, filePath string) error {
+	if conn == nil {
+		return errors.New("connector cannot be nil")
+	}
+	if strings.TrimSpace(filePath) == "" {
+		return errors.New("filePath cannot be empty")
+	}
+	_, stderr, err := conn.Exec(ctx, nerdctlCommand(namespace, "load", "-i", filePath), &connector.ExecOptions{Sudo: true, Timeout: DefaultNerdctlPullTimeout})
+	if err != nil {
+		return errors.Wrapf(err, "failed to load images from %s. Stderr: %s", filePath, string(stderr))
+	}
+	return nil
+}
+
+// NerdctlComposeUp starts the services of a compose file in the background. projectName is
+// optional; nerdctl derives it from the directory of the compose file when it is empty.
+func (r *defaultRunner) NerdctlComposeUp(ctx context.Context, conn connector.Connector, namespace, composeFile, projectName string) error {
+	if conn == nil {
+		return errors.New("connector cannot be nil")
+	}
+	if strings.TrimSpace(composeFile) == "" {
+		return errors.New("composeFile cannot be empty")
+	}
+	args := []string{"compose", "-f", composeFile}
+	if projectName != "" {
+		args = append(args, "-p", projectName)
+	}
+	args = append(args, "up", "-d")
+	_, stderr, err := conn.Exec(ctx, nerdctlCommand(namespace, args...), &connector.ExecOptions{Sudo: true, Timeout: DefaultNerdctlComposeTimeout})
+	if err != nil {
+		return errors.Wrapf(err, "failed to start compose project from %s. Stderr: %s", composeFile, string(stderr))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+type fakeNerdctlConnector struct {
+	connector.Connector
+	commands []string
+}
+
+func (c *fakeNerdctlConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.commands = append(c.commands, cmd)
+	return []byte("3f2a9c\n"), nil, nil
+}
+
+func TestNerdctlCommands(t *testing.T) {
+	r := NewRunner()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		run      func(conn connector.Connector) error
+		expected string
+	}{
+		{
+			name: "run with ports, volumes and entrypoint",
+			run: func(conn connector.Connector) error {
+				id, err := r.NerdctlRun(ctx, conn, "", ContainerCreateOptions{
+					ImageName:     "registry:2",
+					ContainerName: "registry",
+					Ports:         []ContainerPortMapping{{HostPort: "5000", ContainerPort: "5000"}},
+					Volumes:       []ContainerMount{{Source: "registry-data", Destination: "/var/lib/registry"}},
+					Labels:        map[string]string{"b": "2", "a": "1"},
+					RestartPolicy: "always",
+					Entrypoint:    []string{"/bin/registry", "serve"},
+					Command:       []string{"/etc/docker/registry/config.yml"},
+				})
+				if err == nil && id != "3f2a9c" {
+					t.Errorf("expected container ID 3f2a9c, got %q", id)
+				}
+				return err
+			},
+			expected: "nerdctl 'run' '-d' '--name' 'registry' '-p' '5000:5000' '-v' 'registry-data:/var/lib/registry' '--restart' 'always' '--label' 'a=1' '--label' 'b=2' '--entrypoint' '/bin/registry' 'registry:2' 'serve' '/etc/docker/registry/config.yml'",
+		},
+		{
+			name:     "pull in the k8s.io namespace",
+			run:      func(conn connector.Connector) error { return r.NerdctlPull(ctx, conn, "k8s.io", "registry:2") },
+			expected: "nerdctl --namespace 'k8s.io' 'pull' 'registry:2'",
+		},
+		{
+			name:     "load",
+			run:      func(conn connector.Connector) error { return r.NerdctlLoad(ctx, conn, "", "/tmp/images.tar") },
+			expected: "nerdctl 'load' '-i' '/tmp/images.tar'",
+		},
+		{
+			name: "compose up",
+			run: func(conn connector.Connector) error {
+				return r.NerdctlComposeUp(ctx, conn, "", "/opt/harbor/docker-compose.yml", "harbor")
+			},
+			expected: "nerdctl 'compose' '-f' '/opt/harbor/docker-compose.yml' '-p' 'harbor' 'up' '-d'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeNerdctlConnector{}
+			if err := tt.run(conn); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(conn.commands) != 1 || conn.commands[0] != tt.expected {
+				t.Errorf("expected command\n%s\ngot\n%v", tt.expected, conn.commands)
+			}
+		})
+	}
+
+	if _, err := r.NerdctlRun(ctx, &fakeNerdctlConnector{}, "", ContainerCreateOptions{ImageName: "busybox", VolumesFrom: []string{"data"}}); err == nil {
+		t.Error("expected an error for volumes-from")
+	}
+}
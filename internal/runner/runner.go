@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/mensylisir/kubexm/internal/logger"
 	"github.com/mensylisir/kubexm/internal/tool"
@@ -17,6 +18,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/connector"
 	"golang.org/x/sync/errgroup"
 )
@@ -50,6 +52,9 @@ func (r *defaultRunner) GatherFacts(ctx context.Context, conn connector.Connecto
 		OS:     osInfo,
 		Kernel: osInfo.Kernel,
 	}
+	if osInfo.ID == common.OSWindows {
+		return r.gatherWindowsFacts(ctx, conn, facts)
+	}
 	factCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	g, gCtx := errgroup.WithContext(factCtx)
@@ -60,6 +65,8 @@ func (r *defaultRunner) GatherFacts(ctx context.Context, conn connector.Connecto
 	interfaceChan := make(chan string, 1)
 	ipv6Chan := make(chan string, 1)
 	disksChan := make(chan []DiskInfo, 1)
+	gpuChan := make(chan []GPUDevice, 1)
+	nvidiaSMIChan := make(chan bool, 1)
 
 	g.Go(func() error {
 		defer close(hostnameChan)
@@ -220,6 +227,18 @@ func (r *defaultRunner) GatherFacts(ctx context.Context, conn connector.Connecto
 		return nil
 	})
 
+	g.Go(func() error {
+		defer close(gpuChan)
+		gpuChan <- r.detectPCIGPUs(gCtx, conn)
+		return nil
+	})
+
+	g.Go(func() error {
+		defer close(nvidiaSMIChan)
+		nvidiaSMIChan <- r.nvidiaSMIPresent(gCtx, conn)
+		return nil
+	})
+
 	if err := g.Wait(); err != nil {
 		return facts, fmt.Errorf("failed during concurrent fact gathering: %w", err)
 	}
@@ -231,6 +250,8 @@ func (r *defaultRunner) GatherFacts(ctx context.Context, conn connector.Connecto
 	facts.DefaultInterface = <-interfaceChan
 	facts.IPv6Default = <-ipv6Chan
 	facts.Disks = <-disksChan
+	facts.GPUs = <-gpuChan
+	facts.NvidiaSMIPresent = <-nvidiaSMIChan
 
 	var totalDiskSize int64
 	for _, disk := range facts.Disks {
@@ -250,6 +271,8 @@ func (r *defaultRunner) GatherFacts(ctx context.Context, conn connector.Connecto
 		r.logger.Errorf("%v Warning: failed to detect init system for host %s: %v\n", os.Stderr, facts.Hostname, err)
 	}
 
+	facts.ContainerRuntime = r.detectContainerRuntime(ctx, conn)
+
 	return facts, nil
 }
 
@@ -397,6 +420,49 @@ func (r *defaultRunner) detectHostInitSystem(ctx context.Context, conn connector
 	return nil, fmt.Errorf("unable to detect a supported init system (systemd, sysvinit) on OS ID: %s", facts.OS.ID)
 }
 
+// containerRuntimeProbes lists the runtimes detectContainerRuntime looks for. Docker comes before containerd
+// because a Docker host also runs containerd underneath it.
+var containerRuntimeProbes = []struct {
+	runtimeType string
+	socket      string
+	versionCmd  string
+}{
+	{runtimeType: string(common.RuntimeTypeCRIO), socket: strings.TrimPrefix(common.CRIODefaultEndpoint, "unix://"), versionCmd: "crio --version"},
+	{runtimeType: string(common.RuntimeTypeDocker), socket: strings.TrimPrefix(common.DockerSocketPath, "unix://"), versionCmd: "docker --version"},
+	{runtimeType: string(common.RuntimeTypeContainerd), socket: strings.TrimPrefix(common.ContainerdSocketPath, "unix://"), versionCmd: "containerd --version"},
+}
+
+var runtimeVersionRegex = regexp.MustCompile(`v?(\d+\.\d+\.\d+[0-9A-Za-z.+~-]*)`)
+
+// detectContainerRuntime returns the runtime whose socket is present on the host, or nil if there is none.
+// The version is taken from the runtime's version command and left empty if it cannot be determined.
+func (r *defaultRunner) detectContainerRuntime(ctx context.Context, conn connector.Connector) *ContainerRuntimeInfo {
+	for _, probe := range containerRuntimeProbes {
+		if _, _, err := conn.Exec(ctx, fmt.Sprintf("test -S %s", probe.socket), nil); err != nil {
+			continue
+		}
+		info := &ContainerRuntimeInfo{Type: probe.runtimeType, Socket: probe.socket}
+		stdout, _, err := conn.Exec(ctx, probe.versionCmd, nil)
+		if err != nil {
+			r.logger.Debug("Failed to determine container runtime version", "runtime", probe.runtimeType, "error", err)
+			return info
+		}
+		info.Version = parseRuntimeVersion(string(stdout))
+		return info
+	}
+	return nil
+}
+
+// parseRuntimeVersion extracts the first semantic version from the output of a runtime version command, e.g.
+// "containerd github.com/containerd/containerd v1.7.2 0cae528d" or "Docker version 24.0.5, build ced0996".
+func parseRuntimeVersion(output string) string {
+	match := runtimeVersionRegex.FindStringSubmatch(output)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimRight(match[1], ".-")
+}
+
 func (r *defaultRunner) DeployAndEnableService(ctx context.Context, conn connector.Connector, facts *Facts, serviceName, configContent, configPath, permissions string, templateData interface{}) error {
 	if conn == nil {
 		return fmt.Errorf("connector cannot be nil for DeployAndEnableService")
@@ -434,6 +500,17 @@ func (r *defaultRunner) DeployAndEnableService(ctx context.Context, conn connect
 	if effectivePermissions == "" {
 		effectivePermissions = "0644"
 	}
+
+	var previousContent []byte
+	if exists, _ := r.Exists(ctx, conn, configPath); exists {
+		previous, err := conn.ReadFileWithOptions(ctx, configPath, &connector.FileTransferOptions{Sudo: true})
+		if err != nil {
+			return fmt.Errorf("failed to read existing configuration file %s for service %s: %w", configPath, serviceName, err)
+		}
+		previousContent = previous
+	}
+	wasEnabled, _ := r.IsServiceEnabled(ctx, conn, facts, serviceName)
+
 	if err := r.WriteFile(ctx, conn, contentBytes, configPath, effectivePermissions, true); err != nil {
 		return fmt.Errorf("failed to write configuration file %s for service %s: %w", configPath, serviceName, err)
 	}
@@ -447,12 +524,57 @@ func (r *defaultRunner) DeployAndEnableService(ctx context.Context, conn connect
 	}
 
 	if err := r.RestartService(ctx, conn, facts, serviceName); err != nil {
-		return fmt.Errorf("failed to restart service %s: %w", serviceName, err)
+		startErr := fmt.Errorf("failed to restart service %s: %w", serviceName, err)
+		logs := r.serviceLogs(ctx, conn, facts, serviceName)
+		if rbErr := r.rollbackServiceDeployment(ctx, conn, facts, serviceName, configPath, effectivePermissions, previousContent, wasEnabled); rbErr != nil {
+			startErr = fmt.Errorf("%w (rollback failed: %v)", startErr, rbErr)
+		}
+		if logs != "" {
+			return fmt.Errorf("%w\nservice logs:\n%s", startErr, logs)
+		}
+		return startErr
 	}
 
 	return nil
 }
 
+// rollbackServiceDeployment undoes a DeployAndEnableService whose service failed to start: the
+// configuration file is restored to its previous content (or removed if it did not exist), the
+// service is disabled again if it was not enabled before, and the init system is reloaded.
+func (r *defaultRunner) rollbackServiceDeployment(ctx context.Context, conn connector.Connector, facts *Facts, serviceName, configPath, permissions string, previousContent []byte, wasEnabled bool) error {
+	var errs []error
+	if !wasEnabled {
+		if err := r.DisableService(ctx, conn, facts, serviceName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if previousContent != nil {
+		if err := r.WriteFile(ctx, conn, previousContent, configPath, permissions, true); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", configPath, err))
+		}
+	} else if err := r.Remove(ctx, conn, configPath, true, false); err != nil {
+		errs = append(errs, err)
+	}
+	if err := r.DaemonReload(ctx, conn, facts); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// serviceLogs returns the most recent journal entries of a systemd service, or an empty string
+// when they cannot be collected.
+func (r *defaultRunner) serviceLogs(ctx context.Context, conn connector.Connector, facts *Facts, serviceName string) string {
+	if facts.InitSystem == nil || facts.InitSystem.Type != InitSystemSystemd {
+		return ""
+	}
+	cmd := fmt.Sprintf("journalctl -u %s -n 50 --no-pager", serviceName)
+	stdout, _, err := r.RunWithOptions(ctx, conn, cmd, &connector.ExecOptions{Sudo: true})
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(stdout))
+}
+
 func (r *defaultRunner) Reboot(ctx context.Context, conn connector.Connector, timeout time.Duration) error {
 	if conn == nil {
 		return fmt.Errorf("connector cannot be nil for Reboot")
@@ -636,6 +758,8 @@ func (r *defaultRunner) GatherHostFacts(ctx context.Context, conn connector.Conn
 	netChan := make(chan []NetworkInterface, 1)
 	securityChan := make(chan *SecurityProfile, 1)
 	swapChan := make(chan bool, 1)
+	gpuChan := make(chan []GPUDevice, 1)
+	nvidiaSMIChan := make(chan bool, 1)
 
 	g.Go(func() error {
 		defer close(hostnameChan)
@@ -823,6 +947,18 @@ func (r *defaultRunner) GatherHostFacts(ctx context.Context, conn connector.Conn
 		return nil
 	})
 
+	g.Go(func() error {
+		defer close(gpuChan)
+		gpuChan <- r.detectPCIGPUs(gCtx, conn)
+		return nil
+	})
+
+	g.Go(func() error {
+		defer close(nvidiaSMIChan)
+		nvidiaSMIChan <- r.nvidiaSMIPresent(gCtx, conn)
+		return nil
+	})
+
 	modulesToCheck := []string{"br_netfilter", "overlay", "ip_vs"}
 	for _, mod := range modulesToCheck {
 		cmd := fmt.Sprintf("lsmod | grep -w ^%s", mod)
@@ -843,6 +979,8 @@ func (r *defaultRunner) GatherHostFacts(ctx context.Context, conn connector.Conn
 	facts.NetworkInterfaces = <-netChan
 	facts.Security = <-securityChan
 	facts.SwapOn = <-swapChan
+	facts.GPUs = <-gpuChan
+	facts.NvidiaSMIPresent = <-nvidiaSMIChan
 
 	var totalDiskSize int64
 	for _, disk := range facts.Disks {
@@ -862,6 +1000,8 @@ func (r *defaultRunner) GatherHostFacts(ctx context.Context, conn connector.Conn
 		r.logger.Warn("Failed to detect init system", "host", facts.Hostname, "error", err)
 	}
 
+	facts.ContainerRuntime = r.detectContainerRuntime(ctx, conn)
+
 	return facts, nil
 }
 
@@ -1003,6 +1143,56 @@ func parseNetworkInterfaces(output string, osID string) ([]NetworkInterface, err
 	return interfaces, nil
 }
 
+var (
+	lspciGPUClassRe = regexp.MustCompile(`(?i)^(\S+)\s+(VGA compatible controller|3D controller|Display controller|Processing accelerators)(?:\s+\[[0-9a-f]+\])?:\s+(.+)$`)
+	lspciRevRe      = regexp.MustCompile(`\s+\(rev [0-9a-f]+\)$`)
+)
+
+// detectPCIGPUs lists the GPU/accelerator devices lspci reports, none if lspci is not installed.
+func (r *defaultRunner) detectPCIGPUs(ctx context.Context, conn connector.Connector) []GPUDevice {
+	stdout, _, err := conn.Exec(ctx, "lspci", nil)
+	if err != nil {
+		r.logger.Debug("lspci not available, skipping PCI accelerator detection", "error", err)
+		return []GPUDevice{}
+	}
+	return parseLspciGPUs(string(stdout))
+}
+
+func (r *defaultRunner) nvidiaSMIPresent(ctx context.Context, conn connector.Connector) bool {
+	_, err := r.LookPath(ctx, conn, "nvidia-smi")
+	return err == nil
+}
+
+// parseLspciGPUs extracts NVIDIA and AMD GPU/accelerator devices from plain `lspci` output.
+// Devices from other vendors (e.g. onboard Intel or ASPEED BMC graphics) are ignored.
+func parseLspciGPUs(output string) []GPUDevice {
+	gpus := []GPUDevice{}
+	for _, line := range strings.Split(output, "\n") {
+		matches := lspciGPUClassRe.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) != 4 {
+			continue
+		}
+		description := lspciRevRe.ReplaceAllString(strings.TrimSpace(matches[3]), "")
+		lower := strings.ToLower(description)
+
+		var vendor GPUVendor
+		switch {
+		case strings.HasPrefix(lower, "nvidia"):
+			vendor = GPUVendorNVIDIA
+		case strings.HasPrefix(lower, "advanced micro devices"), strings.HasPrefix(lower, "amd"), strings.HasPrefix(lower, "ati "):
+			vendor = GPUVendorAMD
+		default:
+			continue
+		}
+		gpus = append(gpus, GPUDevice{
+			Vendor:     vendor,
+			Model:      description,
+			PCIAddress: matches[1],
+		})
+	}
+	return gpus
+}
+
 func parseSELinuxStatus(output string) string {
 	re := regexp.MustCompile(`Current mode:\s+(\w+)`)
 	matches := re.FindStringSubmatch(output)
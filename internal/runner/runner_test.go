@@ -0,0 +1,243 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+func TestParseLspciGPUs(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []GPUDevice
+	}{
+		{
+			name: "NVIDIA data center GPU next to BMC graphics",
+			output: `00:00.0 Host bridge: Intel Corporation Device 09a2 (rev 04)
+02:00.0 VGA compatible controller: ASPEED Technology, Inc. ASPEED Graphics Family (rev 52)
+3b:00.0 3D controller: NVIDIA Corporation GA100 [A100 PCIe 40GB] (rev a1)
+5e:00.0 Ethernet controller: Intel Corporation Ethernet Controller X710 for 10GbE SFP+ (rev 02)`,
+			expected: []GPUDevice{
+				{Vendor: GPUVendorNVIDIA, Model: "NVIDIA Corporation GA100 [A100 PCIe 40GB]", PCIAddress: "3b:00.0"},
+			},
+		},
+		{
+			name:   "AMD accelerator",
+			output: `c1:00.0 Display controller: Advanced Micro Devices, Inc. [AMD/ATI] Aldebaran/MI200 [Instinct MI250X] (rev 01)`,
+			expected: []GPUDevice{
+				{Vendor: GPUVendorAMD, Model: "Advanced Micro Devices, Inc. [AMD/ATI] Aldebaran/MI200 [Instinct MI250X]", PCIAddress: "c1:00.0"},
+			},
+		},
+		{
+			name: "host without GPUs",
+			output: `00:00.0 Host bridge: Intel Corporation 440FX - 82441FX PMC [Natoma] (rev 02)
+00:02.0 VGA compatible controller: Cirrus Logic GD 5446
+00:03.0 Ethernet controller: Red Hat, Inc. Virtio network device`,
+			expected: []GPUDevice{},
+		},
+		{
+			name:     "empty output",
+			output:   "",
+			expected: []GPUDevice{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLspciGPUs(tt.output)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d GPUs, got %d: %+v", len(tt.expected), len(got), got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("GPU %d: expected %+v, got %+v", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHostFacts_HasGPU(t *testing.T) {
+	var nilFacts *HostFacts
+	if nilFacts.HasGPU() {
+		t.Error("nil facts should not report a GPU")
+	}
+
+	facts := &HostFacts{GPUs: parseLspciGPUs("3b:00.0 3D controller: NVIDIA Corporation GA100 [A100 PCIe 40GB] (rev a1)")}
+	if !facts.HasGPU() || !facts.HasGPUVendor(GPUVendorNVIDIA) {
+		t.Error("expected NVIDIA GPU to be reported")
+	}
+	if facts.HasGPUVendor(GPUVendorAMD) {
+		t.Error("did not expect an AMD GPU to be reported")
+	}
+
+	if (&HostFacts{}).HasGPU() {
+		t.Error("host without devices should not report a GPU")
+	}
+	if !(&HostFacts{NvidiaSMIPresent: true}).HasGPUVendor(GPUVendorNVIDIA) {
+		t.Error("nvidia-smi presence should imply an NVIDIA GPU")
+	}
+}
+
+func TestParseExistsBatchOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		paths       []string
+		output      string
+		expected    map[string]bool
+		expectError bool
+	}{
+		{
+			name:   "mix of existing and missing paths",
+			paths:  []string{"/etc/kubernetes/admin.conf", "/etc/kubernetes/missing.conf", "/var/lib/etcd"},
+			output: "1\n0\n1\n",
+			expected: map[string]bool{
+				"/etc/kubernetes/admin.conf":   true,
+				"/etc/kubernetes/missing.conf": false,
+				"/var/lib/etcd":                true,
+			},
+		},
+		{
+			name:     "path containing spaces",
+			paths:    []string{"/opt/my dir/file"},
+			output:   "0\n",
+			expected: map[string]bool{"/opt/my dir/file": false},
+		},
+		{
+			name:        "truncated output",
+			paths:       []string{"/a", "/b"},
+			output:      "1\n",
+			expectError: true,
+		},
+		{
+			name:        "empty output",
+			paths:       []string{"/a"},
+			output:      "",
+			expectError: true,
+		},
+		{
+			name:        "unexpected token",
+			paths:       []string{"/a"},
+			output:      "yes\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExistsBatchOutput(tt.paths, tt.output)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d entries, got %d: %v", len(tt.expected), len(got), got)
+			}
+			for path, exists := range tt.expected {
+				if got[path] != exists {
+					t.Errorf("path %s: expected exists=%v, got %v", path, exists, got[path])
+				}
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := connector.ShellEscape("/opt/it's here"); got != `'/opt/it'\''s here'` {
+		t.Errorf("unexpected quoting: %s", got)
+	}
+}
+
+type fakeRuntimeConnector struct {
+	connector.Connector
+	outputs map[string]string
+}
+
+func (c *fakeRuntimeConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	out, ok := c.outputs[cmd]
+	if !ok {
+		return nil, nil, errors.New("exit status 1")
+	}
+	return []byte(out), nil, nil
+}
+
+func TestDetectContainerRuntime(t *testing.T) {
+	tests := []struct {
+		name            string
+		outputs         map[string]string
+		expectedType    string
+		expectedVersion string
+	}{
+		{
+			name: "containerd active",
+			outputs: map[string]string{
+				"test -S /run/containerd/containerd.sock": "",
+				"containerd --version":                    "containerd github.com/containerd/containerd v1.7.2 0cae528dd6cb557f7201036e9f43420650207b58\n",
+			},
+			expectedType:    "containerd",
+			expectedVersion: "1.7.2",
+		},
+		{
+			name: "docker is preferred over its containerd",
+			outputs: map[string]string{
+				"test -S /var/run/docker.sock":            "",
+				"test -S /run/containerd/containerd.sock": "",
+				"docker --version":                        "Docker version 24.0.5, build ced0996\n",
+			},
+			expectedType:    "docker",
+			expectedVersion: "24.0.5",
+		},
+		{
+			name: "cri-o with unknown version",
+			outputs: map[string]string{
+				"test -S /var/run/crio/crio.sock": "",
+			},
+			expectedType: "cri-o",
+		},
+		{
+			name:    "no runtime",
+			outputs: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner().(*defaultRunner)
+			info := r.detectContainerRuntime(context.Background(), &fakeRuntimeConnector{outputs: tt.outputs})
+			if tt.expectedType == "" {
+				if info != nil {
+					t.Fatalf("expected no runtime, got %+v", info)
+				}
+				return
+			}
+			if info == nil {
+				t.Fatalf("expected runtime %s, got none", tt.expectedType)
+			}
+			if info.Type != tt.expectedType || info.Version != tt.expectedVersion {
+				t.Errorf("expected %s %q, got %s %q", tt.expectedType, tt.expectedVersion, info.Type, info.Version)
+			}
+		})
+	}
+}
+
+func TestParseRuntimeVersion(t *testing.T) {
+	tests := map[string]string{
+		"containerd containerd.io 1.6.21 3dce8eb055cbb6872793272b4f20ed16117344f8": "1.6.21",
+		"crio version 1.27.1\nGitCommit: 3c57c6c":                                  "1.27.1",
+		"Docker version 25.0.0-rc.1, build 1d7d9f0":                                "25.0.0-rc.1",
+		"command not found": "",
+	}
+	for output, expected := range tests {
+		if got := parseRuntimeVersion(output); got != expected {
+			t.Errorf("parseRuntimeVersion(%q) = %q, expected %q", output, got, expected)
+		}
+	}
+}
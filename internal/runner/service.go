@@ -122,7 +122,7 @@ func (r *defaultRunner) IsServiceActive(ctx context.Context, conn connector.Conn
 	svcInfo := facts.InitSystem
 	cmd := fmt.Sprintf(svcInfo.IsActiveCmd, serviceName)
 
-	if svcInfo.Type == InitSystemSystemd {
+	if svcInfo.Type == InitSystemSystemd || svcInfo.Type == InitSystemWindows {
 		return r.Check(ctx, conn, cmd, false)
 	} else if svcInfo.Type == InitSystemSysV {
 		stdout, _, execErr := r.RunWithOptions(ctx, conn, cmd, &connector.ExecOptions{Sudo: false})
@@ -187,6 +187,10 @@ func (r *defaultRunner) IsServiceEnabled(ctx context.Context, conn connector.Con
 		cmd := fmt.Sprintf("ls /etc/rc?.d/S* | grep -qE '/S[0-9]+%s$'", serviceName)
 		return r.Check(ctx, conn, cmd, false)
 
+	case InitSystemWindows:
+		cmd := fmt.Sprintf("if ((Get-Service -Name '%s').StartType -ne 'Automatic') { exit 1 }", serviceName)
+		return r.Check(ctx, conn, cmd, false)
+
 	default:
 		return false, fmt.Errorf("IsServiceEnabled not implemented for init system type: %s", svcInfo.Type)
 	}
@@ -202,8 +206,8 @@ func (r *defaultRunner) DaemonReload(ctx context.Context, conn connector.Connect
 	svcInfo := facts.InitSystem
 
 	if svcInfo.DaemonReloadCmd == "" {
-		if svcInfo.Type == InitSystemSysV {
-			return nil // No-op for basic SysV
+		if svcInfo.Type == InitSystemSysV || svcInfo.Type == InitSystemWindows {
+			return nil // No-op for basic SysV and the Windows service manager
 		}
 		return fmt.Errorf("daemon-reload command not defined for init system type: %s", svcInfo.Type)
 	}
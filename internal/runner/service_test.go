@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+type fakeServiceConnector struct {
+	connector.Connector
+	files      map[string]string
+	failStart  bool
+	enabled    bool
+	commands   []string
+	journalOut string
+}
+
+func (c *fakeServiceConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.commands = append(c.commands, cmd)
+	switch {
+	case strings.HasPrefix(cmd, "systemctl is-enabled"):
+		if c.enabled {
+			return nil, nil, nil
+		}
+		return nil, nil, &connector.CommandError{Cmd: cmd, ExitCode: 1}
+	case strings.HasPrefix(cmd, "systemctl enable"):
+		c.enabled = true
+	case strings.HasPrefix(cmd, "systemctl disable"):
+		c.enabled = false
+	case strings.HasPrefix(cmd, "systemctl restart") && c.failStart:
+		return nil, []byte("Job failed"), &connector.CommandError{Cmd: cmd, ExitCode: 1, Stderr: "Job failed"}
+	case strings.HasPrefix(cmd, "journalctl"):
+		return []byte(c.journalOut), nil, nil
+	case strings.HasPrefix(cmd, "rm -f "):
+		delete(c.files, strings.TrimPrefix(cmd, "rm -f "))
+	}
+	return nil, nil, nil
+}
+
+func (c *fakeServiceConnector) Stat(ctx context.Context, path string) (*connector.FileStat, error) {
+	_, ok := c.files[path]
+	return &connector.FileStat{Name: path, IsExist: ok}, nil
+}
+
+func (c *fakeServiceConnector) ReadFileWithOptions(ctx context.Context, path string, opts *connector.FileTransferOptions) ([]byte, error) {
+	content, ok := c.files[path]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+	return []byte(content), nil
+}
+
+func (c *fakeServiceConnector) WriteFile(ctx context.Context, content []byte, destPath string, opts *connector.FileTransferOptions) error {
+	c.files[destPath] = string(content)
+	return nil
+}
+
+func (c *fakeServiceConnector) ran(prefix string) bool {
+	for _, cmd := range c.commands {
+		if strings.HasPrefix(cmd, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func systemdFacts() *Facts {
+	return &Facts{InitSystem: &ServiceInfo{
+		Type:            InitSystemSystemd,
+		StartCmd:        "systemctl start %s",
+		StopCmd:         "systemctl stop %s",
+		EnableCmd:       "systemctl enable %s",
+		DisableCmd:      "systemctl disable %s",
+		RestartCmd:      "systemctl restart %s",
+		IsActiveCmd:     "systemctl is-active --quiet %s",
+		DaemonReloadCmd: "systemctl daemon-reload",
+	}}
+}
+
+func TestDeployAndEnableService(t *testing.T) {
+	const unitPath = "/etc/systemd/system/demo.service"
+	tests := []struct {
+		name            string
+		existing        map[string]string
+		failStart       bool
+		expectErr       bool
+		expectFile      bool
+		expectedContent string
+		expectEnabled   bool
+	}{
+		{
+			name:            "service starts",
+			existing:        map[string]string{},
+			expectFile:      true,
+			expectedContent: "[Service]\nExecStart=/usr/bin/demo\n",
+			expectEnabled:   true,
+		},
+		{
+			name:       "failed start removes newly deployed unit",
+			existing:   map[string]string{},
+			failStart:  true,
+			expectErr:  true,
+			expectFile: false,
+		},
+		{
+			name:            "failed start restores previous unit",
+			existing:        map[string]string{unitPath: "[Service]\nExecStart=/usr/bin/demo-old\n"},
+			failStart:       true,
+			expectErr:       true,
+			expectFile:      true,
+			expectedContent: "[Service]\nExecStart=/usr/bin/demo-old\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeServiceConnector{
+				files:      tt.existing,
+				failStart:  tt.failStart,
+				journalOut: "demo.service: Main process exited, code=exited, status=1/FAILURE",
+			}
+			r := NewRunner()
+			err := r.DeployAndEnableService(context.Background(), conn, systemdFacts(), "demo",
+				"[Service]\nExecStart=/usr/bin/demo\n", unitPath, "0644", nil)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error for a failed start")
+				}
+				if !strings.Contains(err.Error(), "failed to restart service demo") || !strings.Contains(err.Error(), "status=1/FAILURE") {
+					t.Errorf("expected start failure with service logs, got: %v", err)
+				}
+				if conn.enabled {
+					t.Error("expected service to be disabled again after a failed start")
+				}
+				if !conn.ran("systemctl daemon-reload") {
+					t.Error("expected a daemon-reload during rollback")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			content, exists := conn.files[unitPath]
+			if exists != tt.expectFile {
+				t.Fatalf("expected unit file present=%v, got %v", tt.expectFile, exists)
+			}
+			if tt.expectFile && content != tt.expectedContent {
+				t.Errorf("unexpected unit content:\n%s", content)
+			}
+			if !tt.expectErr && conn.enabled != tt.expectEnabled {
+				t.Errorf("expected enabled=%v, got %v", tt.expectEnabled, conn.enabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlConfig is a parsed TOML config file such as containerd's config.toml or CRI-O's crio.conf.
+// The document is kept as the generic tree produced by the TOML decoder, so settings kubexm does
+// not model survive a read-modify-write unchanged. Keys are addressed as separate path segments.
+type tomlConfig struct {
+	tree map[string]interface{}
+}
+
+func parseTomlConfig(content []byte) (*tomlConfig, error) {
+	tree := make(map[string]interface{})
+	if err := toml.Unmarshal(content, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+	return &tomlConfig{tree: tree}, nil
+}
+
+// table returns the table at path, creating missing tables on the way. It fails if a key on the
+// path holds something other than a table.
+func (c *tomlConfig) table(path ...string) (map[string]interface{}, error) {
+	current := c.tree
+	for i, key := range path {
+		next, ok := current[key]
+		if !ok {
+			created := make(map[string]interface{})
+			current[key] = created
+			current = created
+			continue
+		}
+		nextTable, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is a %T, not a table", tomlPath(path[:i+1]), next)
+		}
+		current = nextTable
+	}
+	return current, nil
+}
+
+// get returns the value at path, if every key on it exists.
+func (c *tomlConfig) get(path ...string) (interface{}, bool) {
+	var current interface{} = c.tree
+	for _, key := range path {
+		table, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = table[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// set stores value at path, replacing whatever was there.
+func (c *tomlConfig) set(value interface{}, path ...string) error {
+	parent, err := c.table(path[:len(path)-1]...)
+	if err != nil {
+		return err
+	}
+	normalized, err := normalizeTomlValue(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", tomlPath(path), err)
+	}
+	parent[path[len(path)-1]] = normalized
+	return nil
+}
+
+// merge deep-merges values into the table at path: nested tables are merged key by key and any
+// other value replaces the existing one.
+func (c *tomlConfig) merge(values map[string]interface{}, path ...string) error {
+	dst, err := c.table(path...)
+	if err != nil {
+		return err
+	}
+	normalized, err := normalizeTomlValue(values)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", tomlPath(path), err)
+	}
+	mergeTomlTables(dst, normalized.(map[string]interface{}))
+	return nil
+}
+
+func mergeTomlTables(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcTable, srcIsTable := value.(map[string]interface{})
+		dstTable, dstIsTable := dst[key].(map[string]interface{})
+		if srcIsTable && dstIsTable {
+			mergeTomlTables(dstTable, srcTable)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// marshal encodes the config with sorted keys and indented tables, so equal configs always
+// produce identical bytes.
+func (c *tomlConfig) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.SetIndentTables(true)
+	if err := enc.Encode(c.tree); err != nil {
+		return nil, fmt.Errorf("failed to encode containerd config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderTomlConfig parses current, lets apply modify it and returns the encoded result. current
+// is returned as is when apply leaves the document unchanged, so a file that is already up to date
+// keeps its formatting and comments and is never rewritten.
+func renderTomlConfig(current []byte, apply func(*tomlConfig) error) ([]byte, error) {
+	cfg, err := parseTomlConfig(current)
+	if err != nil {
+		return nil, err
+	}
+	original, err := cfg.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(cfg); err != nil {
+		return nil, err
+	}
+	modified, err := cfg.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(original, modified) {
+		return current, nil
+	}
+	return modified, nil
+}
+
+// normalizeTomlValue converts value to the types the TOML decoder produces (int64, []interface{},
+// map[string]interface{}, ...), so a merged tree can be compared with a freshly parsed one.
+func normalizeTomlValue(value interface{}) (interface{}, error) {
+	content, err := toml.Marshal(map[string]interface{}{"v": value})
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	if err := toml.Unmarshal(content, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded["v"], nil
+}
+
+// tomlPath formats path as a dotted TOML key for error messages, quoting keys that contain dots.
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = key
+		if strings.ContainsAny(key, ". ") {
+			keys[i] = strconv.Quote(key)
+		}
+	}
+	return strings.Join(keys, ".")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
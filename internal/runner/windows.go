@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/tool"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// windowsServiceInfo drives Windows services through the PowerShell service cmdlets. The
+// connector runs every command under PowerShell, so the templates are plain cmdlet calls.
+var windowsServiceInfo = &ServiceInfo{
+	Type:        InitSystemWindows,
+	StartCmd:    "Start-Service -Name '%s'",
+	StopCmd:     "Stop-Service -Name '%s' -Force",
+	EnableCmd:   "Set-Service -Name '%s' -StartupType Automatic",
+	DisableCmd:  "Set-Service -Name '%s' -StartupType Disabled",
+	RestartCmd:  "Restart-Service -Name '%s' -Force",
+	IsActiveCmd: "if ((Get-Service -Name '%s').Status -ne 'Running') { exit 1 }",
+}
+
+// windowsFactsScript prints the host facts GatherFacts needs as KEY=VALUE lines. The default
+// route lookup is optional so hosts without one still report the rest.
+const windowsFactsScript = `"HOSTNAME=$([System.Net.Dns]::GetHostName())"
+"CPU=$env:NUMBER_OF_PROCESSORS"
+"MEMORY=$((Get-CimInstance Win32_ComputerSystem).TotalPhysicalMemory)"
+try {
+  $route = Get-NetRoute -DestinationPrefix '0.0.0.0/0' | Sort-Object RouteMetric | Select-Object -First 1
+  if ($route) {
+    $ip = Get-NetIPAddress -AddressFamily IPv4 -InterfaceIndex $route.InterfaceIndex | Select-Object -First 1
+    "IPV4=$($ip.IPAddress)"
+    "INTERFACE=$($route.InterfaceAlias)"
+  }
+} catch {}
+"CONTAINERD=$(Test-Path '` + common.ContainerdWindowsPipe + `')"`
+
+// gatherWindowsFacts collects facts from a Windows host in a single PowerShell round trip.
+// Package manager and disk facts are left empty; nothing on a Windows worker uses them.
+func (r *defaultRunner) gatherWindowsFacts(ctx context.Context, conn connector.Connector, facts *Facts) (*Facts, error) {
+	stdout, _, err := conn.Exec(ctx, windowsFactsScript, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to gather Windows facts: %w", err)
+	}
+	values := parseWindowsFacts(string(stdout))
+
+	facts.Hostname = values["HOSTNAME"]
+	if facts.Hostname == "" {
+		return nil, fmt.Errorf("failed to get hostname from Windows host")
+	}
+	if cpu := values["CPU"]; cpu != "" {
+		q, parseErr := tool.ParseCPU(cpu)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse CPU count %q: %w", cpu, parseErr)
+		}
+		facts.TotalCPU = *q
+	}
+	if mem := values["MEMORY"]; mem != "" {
+		q, parseErr := resource.ParseQuantity(mem)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse memory size %q: %w", mem, parseErr)
+		}
+		facts.TotalMemory = q
+	}
+	facts.IPv4Default = values["IPV4"]
+	facts.DefaultInterface = values["INTERFACE"]
+	facts.InitSystem = windowsServiceInfo
+	if strings.EqualFold(values["CONTAINERD"], "true") {
+		facts.ContainerRuntime = &ContainerRuntimeInfo{
+			Type:   string(common.RuntimeTypeContainerd),
+			Socket: common.ContainerdWindowsEndpoint,
+		}
+	}
+	return facts, nil
+}
+
+func parseWindowsFacts(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// InstallWindowsService registers binPath (with args) as an automatically started Windows service
+// that is restarted on failure. An existing service of the same name is stopped and replaced, since
+// Windows PowerShell cannot change the command line of an existing service.
+func (r *defaultRunner) InstallWindowsService(ctx context.Context, conn connector.Connector, serviceName, displayName, binPath string, args []string) error {
+	if conn == nil {
+		return fmt.Errorf("connector cannot be nil")
+	}
+	if strings.TrimSpace(serviceName) == "" {
+		return fmt.Errorf("serviceName cannot be empty")
+	}
+	if strings.TrimSpace(binPath) == "" {
+		return fmt.Errorf("binPath cannot be empty")
+	}
+	if displayName == "" {
+		displayName = serviceName
+	}
+	cmdLine := WindowsServiceCommandLine(binPath, args)
+	script := fmt.Sprintf(`$name = %[1]s
+if (Get-Service -Name $name -ErrorAction SilentlyContinue) {
+  Stop-Service -Name $name -Force
+  & sc.exe delete $name | Out-Null
+}
+New-Service -Name $name -DisplayName %[2]s -BinaryPathName %[3]s -StartupType Automatic | Out-Null
+& sc.exe failure $name reset= 0 actions= restart/10000 | Out-Null`,
+		connector.PowerShellQuote(serviceName), connector.PowerShellQuote(displayName), connector.PowerShellQuote(cmdLine))
+	if _, _, err := r.RunWithOptions(ctx, conn, script, &connector.ExecOptions{}); err != nil {
+		return fmt.Errorf("failed to install Windows service %s: %w", serviceName, err)
+	}
+	return nil
+}
+
+// isWindows reports whether conn manages a Windows host, on which the POSIX file commands of the
+// runner are replaced by the PowerShell ones of the connector.
+func isWindows(conn connector.Connector) bool {
+	_, ok := conn.(*connector.WindowsConnector)
+	return ok
+}
+
+// WindowsPath joins elem with backslashes into a path on a Windows host, such as C:\k\kubelet.exe.
+// Unlike filepath.Join it does not depend on the OS kubexm itself runs on.
+func WindowsPath(elem ...string) string {
+	parts := make([]string, 0, len(elem))
+	for _, e := range elem {
+		if e = strings.Trim(strings.ReplaceAll(e, "/", `\`), `\`); e != "" {
+			parts = append(parts, e)
+		}
+	}
+	return strings.Join(parts, `\`)
+}
+
+// WindowsServiceCommandLine returns the command line InstallWindowsService registers for binPath
+// and args, as reported back by the PathName of the service.
+func WindowsServiceCommandLine(binPath string, args []string) string {
+	cmdLine := `"` + binPath + `"`
+	if len(args) > 0 {
+		cmdLine += " " + strings.Join(args, " ")
+	}
+	return cmdLine
+}
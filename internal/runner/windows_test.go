@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+)
+
+type fakeWindowsConnector struct {
+	connector.Connector
+	factsOut string
+	running  map[string]bool
+	commands []string
+}
+
+func (c *fakeWindowsConnector) IsConnected() bool { return true }
+
+func (c *fakeWindowsConnector) GetOS(ctx context.Context) (*connector.OS, error) {
+	return &connector.OS{ID: "windows", VersionID: "10.0.20348", Arch: "amd64", Kernel: "10.0.20348"}, nil
+}
+
+func (c *fakeWindowsConnector) Exec(ctx context.Context, cmd string, opts *connector.ExecOptions) ([]byte, []byte, error) {
+	c.commands = append(c.commands, cmd)
+	switch {
+	case cmd == windowsFactsScript:
+		return []byte(c.factsOut), nil, nil
+	case strings.HasPrefix(cmd, "if ((Get-Service"):
+		for name, running := range c.running {
+			if strings.Contains(cmd, "'"+name+"'") && running {
+				return nil, nil, nil
+			}
+		}
+		return nil, nil, &connector.CommandError{Cmd: cmd, ExitCode: 1}
+	}
+	return nil, nil, nil
+}
+
+func TestGatherFacts_Windows(t *testing.T) {
+	conn := &fakeWindowsConnector{factsOut: "HOSTNAME=win-worker-1\r\nCPU=4\r\nMEMORY=17179869184\r\n" +
+		"IPV4=10.0.0.20\r\nINTERFACE=Ethernet 2\r\nCONTAINERD=True\r\n"}
+	facts, err := NewRunner().GatherFacts(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("GatherFacts failed: %v", err)
+	}
+	if facts.Hostname != "win-worker-1" || facts.IPv4Default != "10.0.0.20" || facts.DefaultInterface != "Ethernet 2" {
+		t.Errorf("unexpected host facts: %+v", facts)
+	}
+	if facts.TotalCPU.Value() != 4 || facts.TotalMemory.Value() != 17179869184 {
+		t.Errorf("unexpected resources: cpu=%s memory=%s", facts.TotalCPU.String(), facts.TotalMemory.String())
+	}
+	if facts.InitSystem == nil || facts.InitSystem.Type != InitSystemWindows {
+		t.Errorf("expected the windows init system, got %+v", facts.InitSystem)
+	}
+	if facts.ContainerRuntime == nil || facts.ContainerRuntime.Socket != "npipe:////./pipe/containerd-containerd" {
+		t.Errorf("expected containerd on its named pipe, got %+v", facts.ContainerRuntime)
+	}
+	if facts.PackageManager != nil {
+		t.Errorf("expected no package manager on windows, got %+v", facts.PackageManager)
+	}
+}
+
+func TestWindowsServiceManagement(t *testing.T) {
+	conn := &fakeWindowsConnector{running: map[string]bool{"containerd": true}}
+	facts := &Facts{InitSystem: windowsServiceInfo}
+	r := NewRunner()
+	ctx := context.Background()
+
+	if active, err := r.IsServiceActive(ctx, conn, facts, "containerd"); err != nil || !active {
+		t.Errorf("expected containerd to be active, got %v (err: %v)", active, err)
+	}
+	if err := r.StartService(ctx, conn, facts, "kubelet"); err != nil {
+		t.Fatalf("StartService failed: %v", err)
+	}
+	if last := conn.commands[len(conn.commands)-1]; last != "Start-Service -Name 'kubelet'" {
+		t.Errorf("expected Start-Service, got %q", last)
+	}
+	if err := r.DaemonReload(ctx, conn, facts); err != nil {
+		t.Errorf("expected daemon-reload to be a no-op on windows, got %v", err)
+	}
+
+	conn.commands = nil
+	if err := r.InstallWindowsService(ctx, conn, "kubelet", "", `C:\k\kubelet.exe`, []string{"--windows-service", "--config=C:\\k\\config.yaml"}); err != nil {
+		t.Fatalf("InstallWindowsService failed: %v", err)
+	}
+	script := conn.commands[0]
+	for _, want := range []string{
+		"$name = 'kubelet'",
+		`-BinaryPathName '"C:\k\kubelet.exe" --windows-service --config=C:\k\config.yaml' -StartupType Automatic`,
+		"sc.exe failure $name",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected install script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestWindowsPath(t *testing.T) {
+	tests := []struct {
+		elem []string
+		want string
+	}{
+		{elem: []string{`C:\k`, "kubelet.exe"}, want: `C:\k\kubelet.exe`},
+		{elem: []string{`C:\var\lib\kubelet\`, "etc/kubernetes", "pki"}, want: `C:\var\lib\kubelet\etc\kubernetes\pki`},
+		{elem: []string{`C:\k`, ""}, want: `C:\k`},
+	}
+	for _, tt := range tests {
+		if got := WindowsPath(tt.elem...); got != tt.want {
+			t.Errorf("WindowsPath(%q) = %q, want %q", tt.elem, got, tt.want)
+		}
+	}
+}
@@ -22,6 +22,7 @@ type CoreServiceContext interface {
 type ClusterQueryContext interface {
 	GetClusterConfig() *v1alpha1.Cluster
 	GetHostsByRole(role string) []remotefw.Host
+	GetWindowsHostsByRole(role string) []remotefw.Host
 	GetHostFacts(host remotefw.Host) (*runner.Facts, error)
 	GetControlNode() (remotefw.Host, error)
 }
@@ -59,4 +60,5 @@ type GlobalSettingsContext interface {
 	ShouldIgnoreErr() bool
 	GetGlobalConnectionTimeout() time.Duration
 	IsOfflineMode() bool
+	GetArtifactsBundlePath() string
 }
@@ -9,11 +9,11 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/validation"
 	"github.com/mensylisir/kubexm/internal/cache"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/config"
 	"github.com/mensylisir/kubexm/internal/connector"
-	"github.com/mensylisir/kubexm/internal/errors/validation"
 	"github.com/mensylisir/kubexm/internal/logger"
 	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/netutil"
@@ -34,6 +34,9 @@ type Builder struct {
 	skipHostConnect        bool
 	controlConnector       connector.Connector
 	skipConfigValidation   bool
+	preview                bool
+	artifactsBundle        string
+	eventHandlers          []EventHandler
 }
 
 func (b *Builder) WithRunID(runID string) *Builder {
@@ -63,6 +66,26 @@ func (b *Builder) WithSkipHostConnect(skip bool) *Builder {
 	return b
 }
 
+// WithPreview makes executors built from this context preview all prechecks before execution.
+func (b *Builder) WithPreview(preview bool) *Builder {
+	b.preview = preview
+	return b
+}
+
+// WithEventHandler makes executors built from this context report their progress to handler.
+// Every handler added this way receives all events.
+func (b *Builder) WithEventHandler(handler EventHandler) *Builder {
+	b.eventHandlers = append(b.eventHandlers, handler)
+	return b
+}
+
+// WithArtifactsBundle installs from the offline bundle at path, as written by "kubexm artifacts
+// export". A bundle always puts the run in offline mode.
+func (b *Builder) WithArtifactsBundle(path string) *Builder {
+	b.artifactsBundle = path
+	return b
+}
+
 func (b *Builder) WithControlConnector(conn connector.Connector) *Builder {
 	b.controlConnector = conn
 	return b
@@ -146,10 +169,6 @@ func (b *Builder) Build(ctx context.Context) (*Context, func(), error) {
 	connectorFactory := connector.NewFactory()
 	runnerSvc := runner.NewRunner()
 	//execEngine := engine.NewExecutor()
-	cleanupFunc := func() {
-		log.Info("Shutting down connection pool...")
-		connectionPool.Shutdown()
-	}
 	pipelineCache := cache.NewPipelineCache()
 	moduleCache := cache.NewModuleCache(pipelineCache)
 	taskCache := cache.NewTaskCache(moduleCache)
@@ -167,7 +186,10 @@ func (b *Builder) Build(ctx context.Context) (*Context, func(), error) {
 		GlobalVerbose:           currentClusterConfig.Spec.Global.Verbose,
 		GlobalIgnoreErr:         currentClusterConfig.Spec.Global.IgnoreErr,
 		GlobalConnectionTimeout: currentClusterConfig.Spec.Global.ConnectionTimeout,
-		GlobalOfflineMode:       currentClusterConfig.Spec.Global.OfflineMode,
+		GlobalOfflineMode:       currentClusterConfig.Spec.Global.OfflineMode || b.artifactsBundle != "",
+		GlobalArtifactsBundle:   b.artifactsBundle,
+		GlobalPreview:           b.preview,
+		Events:                  MultiEventHandler(b.eventHandlers...),
 
 		PipelineCache: pipelineCache,
 		ModuleCache:   moduleCache,
@@ -188,6 +210,11 @@ func (b *Builder) Build(ctx context.Context) (*Context, func(), error) {
 		TaskState:     NewStateBag(),
 		RunID:         runID,
 	}
+	cleanupFunc := func() {
+		runtimeCtx.closeHostConnections()
+		log.Info("Shutting down connection pool...")
+		connectionPool.Shutdown()
+	}
 
 	if b.skipHostConnect {
 		if err := b.initializeHostsWithoutConnect(runtimeCtx, connectorFactory); err != nil {
@@ -215,16 +242,23 @@ func (b *Builder) getOrParseConfig() (*v1alpha1.Cluster, error) {
 		if b.skipConfigValidation {
 			return b.clusterConfig, nil
 		}
-		v1alpha1.SetDefaults_Cluster(b.clusterConfig)
-		verrs := validation.ValidationErrors{}
-		v1alpha1.Validate_Cluster(b.clusterConfig, &verrs)
-		if verrs.HasErrors() {
-			return nil, fmt.Errorf("validation failed for pre-loaded cluster configuration: %s", verrs.Error())
+		if err := validation.DefaultAndValidate(b.clusterConfig); err != nil {
+			return nil, fmt.Errorf("validation failed for pre-loaded cluster configuration:\n%s", err.Error())
 		}
 		return b.clusterConfig, nil
 	}
 	if b.configFilepath != "" {
-		return config.ParseFromFile(b.configFilepath)
+		cfg, err := config.ParseFromFile(b.configFilepath)
+		if err != nil {
+			return nil, err
+		}
+		if b.skipConfigValidation {
+			return cfg, nil
+		}
+		if err := validation.Validate(cfg); err != nil {
+			return nil, fmt.Errorf("validation failed for cluster configuration %s:\n%s", b.configFilepath, err.Error())
+		}
+		return cfg, nil
 	}
 	return nil, fmt.Errorf("RuntimeBuilder requires either a config file path or a pre-loaded config object")
 }
@@ -297,6 +331,7 @@ func (b *Builder) initializeHostsWithoutConnect(rc *Context, factory connector.F
 
 	rc.hostInfoMu.Lock()
 	rc.hostInfoMap[controlHost.GetName()] = &HostRuntimeInfo{
+		Host:  controlHost,
 		Conn:  controlConn,
 		Facts: nil,
 	}
@@ -324,6 +359,7 @@ func (b *Builder) buildControlNodeSpec() (v1alpha1.HostSpec, error) {
 	spec := v1alpha1.HostSpec{
 		Name:    common.ControlNodeHostName,
 		Address: ip,
+		Type:    string(common.HostTypeLocal),
 		Roles:   []string{common.ControlNodeRole},
 		RoleTable: map[string]bool{
 			common.ControlNodeRole: true,
@@ -350,6 +386,7 @@ func (b *Builder) initializeSingleHost(ctx context.Context, hostCfg v1alpha1.Hos
 	if err != nil {
 		return nil, err
 	}
+	connCfg.CommandWrapper = b.clusterConfig.Spec.Global.CommandWrapper
 	if err := conn.Connect(ctx, connCfg); err != nil {
 		return nil, fmt.Errorf("host %s: connection failed: %w", hostCfg.Name, err)
 	}
@@ -33,6 +33,12 @@ type Context struct {
 	GlobalIgnoreErr         bool
 	GlobalConnectionTimeout time.Duration
 	GlobalOfflineMode       bool
+	// GlobalArtifactsBundle is the offline bundle to install from, empty unless one was given.
+	GlobalArtifactsBundle string
+	// GlobalPreview makes the engine evaluate all prechecks before executing a graph.
+	GlobalPreview bool
+	// Events, if set, receives the progress of every graph the engine executes.
+	Events EventHandler
 
 	PipelineCache cache.PipelineCache
 	ModuleCache   cache.ModuleCache
@@ -157,6 +163,13 @@ func (c *Context) SetRuntimeConfig(config map[string]interface{}) *Context {
 	return &newCtx
 }
 
+// WithLogger returns a copy of the context that logs through log.
+func (c *Context) WithLogger(log *logger.Logger) *Context {
+	newCtx := *c
+	newCtx.Logger = log
+	return &newCtx
+}
+
 // Data Bus Implementation
 
 // Export exports a key-value pair to the specified scope.
@@ -272,33 +285,49 @@ func (c *Context) IsOfflineMode() bool {
 	return c.GlobalOfflineMode
 }
 
+func (c *Context) GetArtifactsBundlePath() string {
+	return c.GlobalArtifactsBundle
+}
+
 func (c *Context) GetRunID() string {
 	return c.RunID
 }
 
+// GetHostsByRole returns the Linux hosts with the given role, or every Linux host if role is empty.
+// Windows hosts are left out, as the steps planned for these hosts are Linux ones; tasks preparing
+// Windows hosts pick them with GetWindowsHostsByRole.
 func (c *Context) GetHostsByRole(role string) []remotefw.Host {
+	return c.hostsByRole(role, false)
+}
+
+// GetWindowsHostsByRole returns the Windows hosts with the given role, or every Windows host if role
+// is empty.
+func (c *Context) GetWindowsHostsByRole(role string) []remotefw.Host {
+	return c.hostsByRole(role, true)
+}
+
+func (c *Context) hostsByRole(role string, windows bool) []remotefw.Host {
 	c.hostInfoMu.RLock()
 	defer c.hostInfoMu.RUnlock()
-	if c.hostInfoMap == nil {
-		return nil
-	}
 	var hosts []remotefw.Host
-	if role == "" {
-		hosts = make([]remotefw.Host, 0, len(c.hostInfoMap))
-		for _, hri := range c.hostInfoMap {
+	for _, hri := range c.hostInfoMap {
+		if (hri.Host.GetHostSpec().OS == common.OSWindows) != windows {
+			continue
+		}
+		if role == "" || hasRole(hri.Host, role) {
 			hosts = append(hosts, hri.Host)
 		}
-		return hosts
 	}
-	for _, hri := range c.hostInfoMap {
-		for _, r := range hri.Host.GetRoles() {
-			if r == role {
-				hosts = append(hosts, hri.Host)
-				break
-			}
+	return hosts
+}
+
+func hasRole(host remotefw.Host, role string) bool {
+	for _, r := range host.GetRoles() {
+		if r == role {
+			return true
 		}
 	}
-	return hosts
+	return false
 }
 
 func (c *Context) GetHostFacts(host remotefw.Host) (*runner.Facts, error) {
@@ -419,7 +448,29 @@ func (c *Context) GetHostDir(hostname string) string {
 	return filepath.Join(c.GetClusterWorkDir(), hostname)
 }
 
+// closeHostConnections closes the connector of every host. Pooled connectors release their shared
+// connection back to the pool, which closes it on shutdown.
+func (c *Context) closeHostConnections() {
+	if c.hostInfoMap == nil {
+		return
+	}
+	c.hostInfoMu.Lock()
+	defer c.hostInfoMu.Unlock()
+	for name, hri := range c.hostInfoMap {
+		if hri == nil || hri.Conn == nil {
+			continue
+		}
+		if err := hri.Conn.Close(); err != nil {
+			c.Logger.Warnf("Failed to close connection to host %s: %v", name, err)
+		}
+		hri.Conn = nil
+	}
+}
+
 func (c *Context) GetCurrentHostConnector() (connector.Connector, error) {
+	if c.hostInfoMu == nil {
+		return nil, fmt.Errorf("hostInfoMap is not initialized")
+	}
 	c.hostInfoMu.RLock()
 	defer c.hostInfoMu.RUnlock()
 	if c.currentHost == nil {
@@ -1,7 +1,11 @@
 package runtime
 
 import (
+	"sync"
 	"testing"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
 )
 
 func TestContext_Scoping(t *testing.T) {
@@ -118,3 +122,39 @@ func TestContext_Directories(t *testing.T) {
 		t.Errorf("GetGlobalWorkDir mismatch")
 	}
 }
+
+type closeRecordingConnector struct {
+	connector.Connector
+	closed int
+}
+
+func (c *closeRecordingConnector) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestContext_CloseHostConnections(t *testing.T) {
+	master := &closeRecordingConnector{}
+	worker := &closeRecordingConnector{}
+	ctx := &Context{
+		Logger:     logger.Get(),
+		hostInfoMu: &sync.RWMutex{},
+		hostInfoMap: map[string]*HostRuntimeInfo{
+			"master1": {Conn: master},
+			"worker1": {Conn: worker},
+			"offline": {},
+		},
+	}
+
+	ctx.closeHostConnections()
+	ctx.closeHostConnections()
+
+	if master.closed != 1 || worker.closed != 1 {
+		t.Errorf("expected every connector to be closed exactly once, got master=%d worker=%d", master.closed, worker.closed)
+	}
+	for name, hri := range ctx.hostInfoMap {
+		if hri.Conn != nil {
+			t.Errorf("expected the connector of %s to be dropped", name)
+		}
+	}
+}
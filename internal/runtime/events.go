@@ -0,0 +1,99 @@
+package runtime
+
+import "time"
+
+// EventType identifies what happened during the execution of a graph.
+type EventType string
+
+const (
+	// EventGraphStarted is sent once before any node runs. Nodes lists the whole graph.
+	EventGraphStarted EventType = "GraphStarted"
+	// EventNodeScheduled is sent when all dependencies of a node are met and it is queued for a worker.
+	EventNodeScheduled EventType = "NodeScheduled"
+	// EventNodeStarted is sent when a node begins to run on its hosts.
+	EventNodeStarted EventType = "NodeStarted"
+	// EventHostStarted and EventHostFinished bracket the step of a node on one host.
+	EventHostStarted  EventType = "HostStarted"
+	EventHostFinished EventType = "HostFinished"
+	// EventHostOutputChunk carries one entry of the log output of a step on a host in Message.
+	EventHostOutputChunk EventType = "HostOutputChunk"
+	// EventNodeFinished is sent for every node, including nodes skipped without running and
+	// nodes already completed by a resumed run.
+	EventNodeFinished EventType = "NodeFinished"
+	// EventGraphFinished is sent once after the last node.
+	EventGraphFinished EventType = "GraphFinished"
+)
+
+// EventTypes lists every event type in the order they occur for a node.
+var EventTypes = []EventType{
+	EventGraphStarted, EventNodeScheduled, EventNodeStarted, EventHostStarted,
+	EventHostOutputChunk, EventHostFinished, EventNodeFinished, EventGraphFinished,
+}
+
+// EventNode describes a node of the graph in an EventGraphStarted event.
+type EventNode struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Phase string   `json:"phase,omitempty"`
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// Event reports progress of a graph execution. Status holds a plan.Status value; fields that do
+// not apply to the event type are empty.
+type Event struct {
+	Type     EventType `json:"type"`
+	Time     time.Time `json:"time"`
+	Graph    string    `json:"graph,omitempty"`
+	NodeID   string    `json:"nodeID,omitempty"`
+	NodeName string    `json:"nodeName,omitempty"`
+	Phase    string    `json:"phase,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	// Nodes is set on EventGraphStarted, in execution order.
+	Nodes []EventNode `json:"nodes,omitempty"`
+}
+
+// EventHandler receives execution events. It is called from the executor's worker goroutines and
+// must be safe for concurrent use; it should return quickly.
+type EventHandler func(Event)
+
+// MultiEventHandler returns a handler that passes every event to each of handlers in turn.
+// Nil handlers are ignored; with none left it returns nil.
+func MultiEventHandler(handlers ...EventHandler) EventHandler {
+	var subscribers []EventHandler
+	for _, h := range handlers {
+		if h != nil {
+			subscribers = append(subscribers, h)
+		}
+	}
+	switch len(subscribers) {
+	case 0:
+		return nil
+	case 1:
+		return subscribers[0]
+	}
+	return func(ev Event) {
+		for _, h := range subscribers {
+			h(ev)
+		}
+	}
+}
+
+// WithEventHandler returns a copy of the context whose events are also passed to handler.
+func (c *Context) WithEventHandler(handler EventHandler) *Context {
+	newCtx := *c
+	newCtx.Events = MultiEventHandler(c.Events, handler)
+	return &newCtx
+}
+
+// Emit passes ev to the context's event handler, if one is set, stamping it with the current time.
+func (c *Context) Emit(ev Event) {
+	if c.Events == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	c.Events(ev)
+}
@@ -0,0 +1,134 @@
+// Package runtimetest provides runtime fixtures shared by the unit tests of steps, tasks and the
+// engine.
+package runtimetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/cache"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// Context is a minimal runtime.ExecutionContext for step tests. Only the accessors below are
+// implemented; any other method panics on the nil embedded interface, which points the test at
+// the context method it still has to fake.
+type Context struct {
+	runtime.ExecutionContext
+	Runner  runner.Runner
+	Host    remotefw.Host
+	Conn    connector.Connector
+	Cluster *v1alpha1.Cluster
+	// Hosts is filtered by role and OS in GetHostsByRole and GetWindowsHostsByRole. Defaults to
+	// Host alone.
+	Hosts         []remotefw.Host
+	Facts         *runner.Facts
+	WorkDir       string
+	GlobalWorkDir string
+	TaskCache     cache.TaskCache
+	PipelineCache cache.PipelineCache
+	RunID         string
+	PipelineName  string
+}
+
+func (c *Context) GetLogger() *logger.Logger             { return logger.Get() }
+func (c *Context) GetRunner() runner.Runner              { return c.Runner }
+func (c *Context) GetHost() remotefw.Host                { return c.Host }
+func (c *Context) GoContext() context.Context            { return context.Background() }
+func (c *Context) GetStepExecutionID() string            { return "test" }
+func (c *Context) GetRunID() string                      { return c.RunID }
+func (c *Context) GetPipelineName() string               { return c.PipelineName }
+func (c *Context) GetModuleName() string                 { return "" }
+func (c *Context) GetHostWorkDir() string                { return c.WorkDir }
+func (c *Context) GetGlobalWorkDir() string              { return c.GlobalWorkDir }
+func (c *Context) GetUploadDir() string                  { return "/tmp/kubexm" }
+func (c *Context) GetTaskCache() cache.TaskCache         { return c.TaskCache }
+func (c *Context) GetPipelineCache() cache.PipelineCache { return c.PipelineCache }
+
+func (c *Context) GetCurrentHostConnector() (connector.Connector, error) {
+	return c.Conn, nil
+}
+
+// GetClusterConfig returns Cluster, or a cluster with an empty spec if none is set.
+func (c *Context) GetClusterConfig() *v1alpha1.Cluster {
+	if c.Cluster != nil {
+		return c.Cluster
+	}
+	return &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{}}
+}
+
+// GetHostFacts returns Facts for every host, or empty facts if none are set.
+func (c *Context) GetHostFacts(host remotefw.Host) (*runner.Facts, error) {
+	if c.Facts != nil {
+		return c.Facts, nil
+	}
+	return &runner.Facts{}, nil
+}
+
+func (c *Context) GetHostsByRole(role string) []remotefw.Host {
+	return c.hostsByRole(role, false)
+}
+
+func (c *Context) GetWindowsHostsByRole(role string) []remotefw.Host {
+	return c.hostsByRole(role, true)
+}
+
+func (c *Context) hostsByRole(role string, windows bool) []remotefw.Host {
+	hosts := c.Hosts
+	if hosts == nil && c.Host != nil {
+		hosts = []remotefw.Host{c.Host}
+	}
+	var selected []remotefw.Host
+	for _, h := range hosts {
+		if (h.GetHostSpec().OS == common.OSWindows) != windows {
+			continue
+		}
+		if role == "" {
+			selected = append(selected, h)
+			continue
+		}
+		for _, r := range h.GetRoles() {
+			if r == role {
+				selected = append(selected, h)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// NopConnector accepts the control node connection without touching the local machine.
+type NopConnector struct {
+	connector.Connector
+}
+
+func (c *NopConnector) Connect(ctx context.Context, cfg connector.ConnectionCfg) error { return nil }
+func (c *NopConnector) Close() error                                                   { return nil }
+
+// NewRuntime builds a runtime.Context for cfg without connecting to any host, for task tests that
+// only plan. The runtime is cleaned up when the test ends.
+func NewRuntime(t *testing.T, cfg *v1alpha1.Cluster) *runtime.Context {
+	t.Helper()
+	return NewRuntimeFromBuilder(t, runtime.NewBuilderFromConfig(cfg))
+}
+
+// NewRuntimeFromBuilder is NewRuntime for tests that set further options on the builder.
+func NewRuntimeFromBuilder(t *testing.T, b *runtime.Builder) *runtime.Context {
+	t.Helper()
+	ctx, cleanup, err := b.
+		WithSkipHostConnect(true).
+		WithSkipConfigValidation(true).
+		WithControlConnector(&NopConnector{}).
+		Build(context.Background())
+	if err != nil {
+		t.Fatalf("failed to build runtime: %v", err)
+	}
+	t.Cleanup(cleanup)
+	return ctx
+}
@@ -0,0 +1,189 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+)
+
+// FileName is the name of the stats file kept in the work directory.
+const FileName = "step-stats.json"
+
+// DefaultWindow is the number of most recent durations kept per step type.
+const DefaultWindow = 20
+
+// StepStats holds the recorded durations of one step type, oldest first.
+type StepStats struct {
+	DurationsSeconds []float64 `json:"durationsSeconds"`
+}
+
+// Store holds per-step-type durations recorded across runs, keyed by plan.NodeResult.StepType. It satisfies plan.DurationHistory.
+type Store struct {
+	Version int                   `json:"version"`
+	Steps   map[string]*StepStats `json:"steps"`
+
+	path   string
+	window int
+}
+
+// StepSummary describes the recorded durations of one step type.
+type StepSummary struct {
+	Step    string
+	Samples int
+	Mean    time.Duration
+	Max     time.Duration
+}
+
+// Path returns the location of the stats file in workDir.
+func Path(workDir string) string {
+	return filepath.Join(workDir, FileName)
+}
+
+// Load reads the stats file at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{Version: 1, Steps: make(map[string]*StepStats), path: path, window: DefaultWindow}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read stats file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stats file %s: %w", path, err)
+	}
+	if s.Steps == nil {
+		s.Steps = make(map[string]*StepStats)
+	}
+	return s, nil
+}
+
+// ExpectedDuration returns the mean recorded duration of stepType.
+func (s *Store) ExpectedDuration(stepType string) (time.Duration, bool) {
+	if s == nil {
+		return 0, false
+	}
+	st, ok := s.Steps[stepType]
+	if !ok || len(st.DurationsSeconds) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, d := range st.DurationsSeconds {
+		sum += d
+	}
+	return time.Duration(sum / float64(len(st.DurationsSeconds)) * float64(time.Second)), true
+}
+
+// SetWindow changes how many recent durations are kept per step type. Older samples are dropped on the next Record.
+func (s *Store) SetWindow(n int) {
+	if n > 0 {
+		s.window = n
+	}
+}
+
+// Record appends the duration of every successful node in result to the stats of its step type.
+// A node's duration is that of its slowest host, since hosts run in parallel. Hosts whose precheck
+// found the step already done are ignored, as Run never executed there.
+func (s *Store) Record(result *plan.GraphExecutionResult) {
+	if s == nil || result == nil {
+		return
+	}
+	window := s.window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	for _, nr := range result.NodeResults {
+		if nr == nil || nr.Status != plan.StatusSuccess || nr.StepType == "" || nr.Satisfied() {
+			continue
+		}
+		d, ok := nodeDuration(nr)
+		if !ok {
+			continue
+		}
+		st, ok := s.Steps[nr.StepType]
+		if !ok {
+			st = &StepStats{}
+			s.Steps[nr.StepType] = st
+		}
+		st.DurationsSeconds = append(st.DurationsSeconds, d.Seconds())
+		if over := len(st.DurationsSeconds) - window; over > 0 {
+			st.DurationsSeconds = append([]float64(nil), st.DurationsSeconds[over:]...)
+		}
+	}
+}
+
+func nodeDuration(nr *plan.NodeResult) (time.Duration, bool) {
+	var longest time.Duration
+	found := false
+	for _, hr := range nr.HostResults {
+		if hr == nil || hr.Satisfied || hr.StartTime.IsZero() || hr.EndTime.Before(hr.StartTime) {
+			continue
+		}
+		if d := hr.EndTime.Sub(hr.StartTime); !found || d > longest {
+			longest = d
+			found = true
+		}
+	}
+	return longest, found
+}
+
+// Save writes the store back to the file it was loaded from.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return fmt.Errorf("stats store has no file path")
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats tmp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to rename stats tmp file: %w", err)
+	}
+	return nil
+}
+
+// Slowest returns the n step types with the highest mean duration, slowest first.
+// If n is zero or negative, all steps are returned.
+func (s *Store) Slowest(n int) []StepSummary {
+	summaries := make([]StepSummary, 0, len(s.Steps))
+	for name, st := range s.Steps {
+		if st == nil || len(st.DurationsSeconds) == 0 {
+			continue
+		}
+		mean, _ := s.ExpectedDuration(name)
+		var max float64
+		for _, d := range st.DurationsSeconds {
+			if d > max {
+				max = d
+			}
+		}
+		summaries = append(summaries, StepSummary{
+			Step:    name,
+			Samples: len(st.DurationsSeconds),
+			Mean:    mean,
+			Max:     time.Duration(max * float64(time.Second)),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Mean != summaries[j].Mean {
+			return summaries[i].Mean > summaries[j].Mean
+		}
+		return summaries[i].Step < summaries[j].Step
+	})
+	if n > 0 && len(summaries) > n {
+		summaries = summaries[:n]
+	}
+	return summaries
+}
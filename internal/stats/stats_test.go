@@ -0,0 +1,145 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+)
+
+// newTestRunResult returns a graph result whose nodes took the given durations on their slowest host.
+func newTestRunResult(durations map[string]time.Duration) *plan.GraphExecutionResult {
+	result := plan.NewGraphExecutionResult("CreateCluster")
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for stepType, d := range durations {
+		nr := plan.NewNodeResult(stepType+"-node", stepType+"-node1")
+		nr.StepType = stepType
+		nr.Status = plan.StatusSuccess
+		fast := plan.NewHostResult("node1")
+		fast.StartTime, fast.EndTime = start, start.Add(d/2)
+		slow := plan.NewHostResult("node2")
+		slow.StartTime, slow.EndTime = start, start.Add(d)
+		nr.HostResults = map[string]*plan.HostResult{"node1": fast, "node2": slow}
+		result.NodeResults[plan.NodeID(stepType)] = nr
+	}
+	return result
+}
+
+func TestStore_RecordAppendsDurations(t *testing.T) {
+	path := Path(t.TempDir())
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load empty store: %v", err)
+	}
+
+	store.Record(newTestRunResult(map[string]time.Duration{"InstallEtcd": 60 * time.Second}))
+	failed := newTestRunResult(map[string]time.Duration{"KubeadmInit": time.Hour})
+	failed.NodeResults["KubeadmInit"].Status = plan.StatusFailed
+	store.Record(failed)
+	if err := store.Save(); err != nil {
+		t.Fatalf("failed to save store: %v", err)
+	}
+
+	store, err = Load(path)
+	if err != nil {
+		t.Fatalf("failed to reload store: %v", err)
+	}
+	store.Record(newTestRunResult(map[string]time.Duration{"InstallEtcd": 30 * time.Second}))
+
+	got := store.Steps["InstallEtcd"].DurationsSeconds
+	if len(got) != 2 || got[0] != 60 || got[1] != 30 {
+		t.Errorf("expected durations [60 30] from the slowest host of each run, got %v", got)
+	}
+	if _, ok := store.Steps["KubeadmInit"]; ok {
+		t.Errorf("did not expect durations of a failed node to be recorded")
+	}
+	if d, ok := store.ExpectedDuration("InstallEtcd"); !ok || d != 45*time.Second {
+		t.Errorf("expected a mean of 45s, got %s (ok=%v)", d, ok)
+	}
+}
+
+func TestStore_RecordGroupsByStepTypeAndSkipsSatisfiedHosts(t *testing.T) {
+	store, err := Load(Path(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	result := plan.NewGraphExecutionResult("UpgradeCluster")
+	for i, name := range []string{"DrainNode-worker1", "DrainNode-worker2", "DrainNode-worker3"} {
+		nr := plan.NewNodeResult(name, name)
+		nr.StepType = "kubernetes.DrainNodeStep"
+		nr.Status = plan.StatusSuccess
+		hr := plan.NewHostResult("master1")
+		hr.StartTime, hr.EndTime = start, start.Add(time.Duration(i+1)*10*time.Second)
+		// The last node was already drained: its precheck short-circuited Run.
+		hr.Satisfied = i == 2
+		nr.HostResults = map[string]*plan.HostResult{"master1": hr}
+		result.NodeResults[plan.NodeID(name)] = nr
+	}
+	partial := plan.NewNodeResult("UpgradeKubelet-worker1", "UpgradeKubelet-worker1")
+	partial.StepType = "kubernetes.UpgradeKubeletStep"
+	partial.Status = plan.StatusSuccess
+	ran := plan.NewHostResult("worker1")
+	ran.StartTime, ran.EndTime = start, start.Add(40*time.Second)
+	done := plan.NewHostResult("worker2")
+	done.StartTime, done.EndTime, done.Satisfied = start, start.Add(90*time.Second), true
+	partial.HostResults = map[string]*plan.HostResult{"worker1": ran, "worker2": done}
+	result.NodeResults["UpgradeKubelet-worker1"] = partial
+	store.Record(result)
+
+	if len(store.Steps) != 2 {
+		t.Fatalf("expected durations keyed by the two step types, got %v", store.Steps)
+	}
+	if got := store.Steps["kubernetes.DrainNodeStep"].DurationsSeconds; len(got) != 2 {
+		t.Errorf("expected the two drains that ran to be recorded under one step type, got %v", got)
+	}
+	if got := store.Steps["kubernetes.UpgradeKubeletStep"].DurationsSeconds; len(got) != 1 || got[0] != 40 {
+		t.Errorf("expected only the host that ran to count (40s), got %v", got)
+	}
+}
+
+func TestStore_RecordRollingWindow(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetWindow(3)
+	for i := 1; i <= 5; i++ {
+		store.Record(newTestRunResult(map[string]time.Duration{"Preflight": time.Duration(i) * time.Second}))
+	}
+	got := store.Steps["Preflight"].DurationsSeconds
+	if len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Errorf("expected only the 3 most recent durations [3 4 5], got %v", got)
+	}
+}
+
+func TestStore_Slowest(t *testing.T) {
+	store, err := Load(Path(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Record(newTestRunResult(map[string]time.Duration{
+		"Preflight":         10 * time.Second,
+		"InstallEtcd":       60 * time.Second,
+		"InstallContainerd": 30 * time.Second,
+	}))
+	store.Record(newTestRunResult(map[string]time.Duration{
+		"Preflight":         20 * time.Second,
+		"InstallContainerd": 90 * time.Second,
+	}))
+
+	slowest := store.Slowest(2)
+	if len(slowest) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", slowest)
+	}
+	if slowest[0].Step != "InstallContainerd" || slowest[0].Mean != 60*time.Second || slowest[0].Max != 90*time.Second || slowest[0].Samples != 2 {
+		t.Errorf("expected InstallContainerd (mean 60s, max 90s, 2 samples) first, got %+v", slowest[0])
+	}
+	if slowest[1].Step != "InstallEtcd" {
+		t.Errorf("expected InstallEtcd second, got %+v", slowest[1])
+	}
+	if all := store.Slowest(0); len(all) != 3 || all[2].Step != "Preflight" {
+		t.Errorf("expected all three steps with Preflight last, got %+v", all)
+	}
+}
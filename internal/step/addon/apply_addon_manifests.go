@@ -0,0 +1,168 @@
+package addon
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// ApplyAddonManifestsStep applies the manifests of a user addon. URLs are applied by kubectl directly;
+// local files and the manifests of Directory are read on the control node and applied in name order.
+type ApplyAddonManifestsStep struct {
+	step.Base
+	Namespace           string
+	Paths               []string
+	Directory           string
+	AdminKubeconfigPath string
+}
+
+type ApplyAddonManifestsStepBuilder struct {
+	step.Builder[ApplyAddonManifestsStepBuilder, *ApplyAddonManifestsStep]
+}
+
+func NewApplyAddonManifestsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ApplyAddonManifestsStepBuilder {
+	s := &ApplyAddonManifestsStep{
+		AdminKubeconfigPath: filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Apply addon manifests", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(ApplyAddonManifestsStepBuilder).Init(s)
+	return b
+}
+
+func (b *ApplyAddonManifestsStepBuilder) WithNamespace(namespace string) *ApplyAddonManifestsStepBuilder {
+	b.Step.Namespace = namespace
+	return b
+}
+
+func (b *ApplyAddonManifestsStepBuilder) WithManifests(paths []string, directory string) *ApplyAddonManifestsStepBuilder {
+	b.Step.Paths = paths
+	b.Step.Directory = directory
+	return b
+}
+
+func (s *ApplyAddonManifestsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func isManifestURL(path string) bool {
+	u, err := url.ParseRequestURI(path)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// manifests returns the URLs to apply and the content of the local manifests, joined into one document stream.
+func (s *ApplyAddonManifestsStep) manifests() ([]string, string, error) {
+	var urls, files []string
+	for _, path := range s.Paths {
+		if isManifestURL(path) {
+			urls = append(urls, path)
+		} else {
+			files = append(files, path)
+		}
+	}
+	if s.Directory != "" {
+		entries, err := os.ReadDir(s.Directory)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read manifest directory %s: %w", s.Directory, err)
+		}
+		var dirFiles []string
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					dirFiles = append(dirFiles, filepath.Join(s.Directory, entry.Name()))
+				}
+			}
+		}
+		if len(dirFiles) == 0 {
+			return nil, "", fmt.Errorf("no manifests found in directory %s", s.Directory)
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+
+	var docs []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read manifest %s: %w", file, err)
+		}
+		docs = append(docs, strings.TrimSpace(string(content)))
+	}
+	return urls, strings.Join(docs, "\n---\n"), nil
+}
+
+func (s *ApplyAddonManifestsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	if _, _, err := s.manifests(); err != nil {
+		return false, err
+	}
+	// kubectl apply is idempotent, so the manifests are always applied.
+	return false, nil
+}
+
+func (s *ApplyAddonManifestsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	urls, content, err := s.manifests()
+	if err != nil {
+		result.MarkFailed(err, "failed to read addon manifests")
+		return result, err
+	}
+
+	opts := runner.KubectlApplyOptions{
+		KubeconfigPath: s.AdminKubeconfigPath,
+		Namespace:      s.Namespace,
+		Validate:       true,
+		Sudo:           s.Sudo,
+	}
+	if len(urls) > 0 {
+		urlOpts := opts
+		urlOpts.Filenames = urls
+		logger.Infof("Applying %d remote manifests.", len(urls))
+		if _, err := runnerSvc.KubectlApply(ctx.GoContext(), conn, urlOpts); err != nil {
+			result.MarkFailed(err, "failed to apply remote addon manifests")
+			return result, err
+		}
+	}
+	if content != "" {
+		opts.Filenames = []string{"-"}
+		opts.FileContent = content
+		logger.Info("Applying local addon manifests.")
+		if _, err := runnerSvc.KubectlApply(ctx.GoContext(), conn, opts); err != nil {
+			result.MarkFailed(err, "failed to apply local addon manifests")
+			return result, err
+		}
+	}
+
+	result.MarkCompleted("addon manifests applied")
+	return result, nil
+}
+
+func (s *ApplyAddonManifestsStep) Rollback(ctx runtime.ExecutionContext) error {
+	// Applied objects may be shared with other addons, so they are left in place; cleanup removes them.
+	return nil
+}
+
+var _ step.Step = (*ApplyAddonManifestsStep)(nil)
@@ -0,0 +1,44 @@
+package addon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyAddonManifestsStep_Manifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20-deployment.yaml": "kind: Deployment\n",
+		"10-namespace.yml":   "kind: Namespace\n",
+		"README.md":          "not a manifest",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	extra := filepath.Join(t.TempDir(), "crd.yaml")
+	if err := os.WriteFile(extra, []byte("kind: CustomResourceDefinition\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ApplyAddonManifestsStep{Paths: []string{"https://example.com/operator.yaml", extra}, Directory: dir}
+	urls, content, err := s.manifests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 1 || urls[0] != "https://example.com/operator.yaml" {
+		t.Errorf("expected the URL to be applied by kubectl, got %v", urls)
+	}
+	want := "kind: CustomResourceDefinition\n---\nkind: Namespace\n---\nkind: Deployment"
+	if content != want {
+		t.Errorf("expected the local manifests in name order\n got: %q\nwant: %q", content, want)
+	}
+
+	empty := &ApplyAddonManifestsStep{Directory: t.TempDir()}
+	if _, _, err := empty.manifests(); err == nil || !strings.Contains(err.Error(), "no manifests found") {
+		t.Errorf("expected an empty directory to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package addon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// DeployAddonChartStep installs or upgrades the chart of a user addon from a chart repository, an
+// OCI reference or a chart path on the host. Values files are read on the control node and uploaded
+// next to the release before the install.
+type DeployAddonChartStep struct {
+	step.Base
+	ReleaseName         string
+	Chart               string
+	Repo                string
+	Version             string
+	Namespace           string
+	ValuesFiles         []string
+	SetValues           []string
+	RemoteValuesDir     string
+	AdminKubeconfigPath string
+}
+
+type DeployAddonChartStepBuilder struct {
+	step.Builder[DeployAddonChartStepBuilder, *DeployAddonChartStep]
+}
+
+func NewDeployAddonChartStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DeployAddonChartStepBuilder {
+	s := &DeployAddonChartStep{
+		AdminKubeconfigPath: filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Install or upgrade an addon Helm chart", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(DeployAddonChartStepBuilder).Init(s)
+	return b
+}
+
+func (b *DeployAddonChartStepBuilder) WithRelease(namespace, releaseName string) *DeployAddonChartStepBuilder {
+	b.Step.Namespace = namespace
+	b.Step.ReleaseName = releaseName
+	return b
+}
+
+// WithChart sets the chart to install. With a repo the chart is looked up in that repository by name,
+// otherwise it is an OCI reference or a chart path on the host.
+func (b *DeployAddonChartStepBuilder) WithChart(chart, repo, version string) *DeployAddonChartStepBuilder {
+	b.Step.Chart = chart
+	b.Step.Repo = repo
+	b.Step.Version = version
+	return b
+}
+
+func (b *DeployAddonChartStepBuilder) WithValues(valuesFiles []string, setValues []string) *DeployAddonChartStepBuilder {
+	b.Step.ValuesFiles = valuesFiles
+	b.Step.SetValues = setValues
+	return b
+}
+
+func (b *DeployAddonChartStepBuilder) WithRemoteValuesDir(dir string) *DeployAddonChartStepBuilder {
+	b.Step.RemoteValuesDir = dir
+	return b
+}
+
+func (s *DeployAddonChartStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *DeployAddonChartStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	if s.ReleaseName == "" || s.Chart == "" {
+		return false, fmt.Errorf("release name and chart must be set in step %s", s.Base.Meta.Name)
+	}
+	if len(s.ValuesFiles) > 0 && s.RemoteValuesDir == "" {
+		return false, fmt.Errorf("no remote directory for the values files of step %s", s.Base.Meta.Name)
+	}
+	// helm upgrade applies changed values and versions, so the chart is always installed.
+	return false, nil
+}
+
+func (s *DeployAddonChartStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	var remoteValuesFiles []string
+	if len(s.ValuesFiles) > 0 {
+		if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, s.RemoteValuesDir, "0700", s.Sudo); err != nil {
+			result.MarkFailed(err, fmt.Sprintf("failed to create remote directory %s", s.RemoteValuesDir))
+			return result, err
+		}
+	}
+	for i, file := range s.ValuesFiles {
+		remoteFile := filepath.Join(s.RemoteValuesDir, fmt.Sprintf("%s-values-%d.yaml", s.ReleaseName, i))
+		if err := helpers.UploadFile(ctx, conn, file, remoteFile, "0600", s.Sudo); err != nil {
+			result.MarkFailed(err, fmt.Sprintf("failed to upload values file %s", file))
+			return result, err
+		}
+		remoteValuesFiles = append(remoteValuesFiles, remoteFile)
+	}
+
+	opts := runner.HelmInstallOptions{
+		Namespace:       s.Namespace,
+		KubeconfigPath:  s.AdminKubeconfigPath,
+		ValuesFiles:     remoteValuesFiles,
+		SetValues:       quoteSetValues(s.SetValues),
+		Repo:            s.Repo,
+		Version:         s.Version,
+		CreateNamespace: true,
+		Wait:            true,
+		Timeout:         s.Base.Timeout,
+		Atomic:          true,
+		Sudo:            s.Sudo,
+	}
+
+	release, err := runnerSvc.HelmStatus(ctx.GoContext(), conn, s.ReleaseName, runner.HelmStatusOptions{
+		Namespace: s.Namespace, KubeconfigPath: s.AdminKubeconfigPath, Sudo: s.Sudo,
+	})
+	if err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to get the status of release %s", s.ReleaseName))
+		return result, err
+	}
+	if release == nil {
+		logger.Infof("Installing chart %s as release %s in namespace %s.", s.Chart, s.ReleaseName, s.Namespace)
+		err = runnerSvc.HelmInstall(ctx.GoContext(), conn, s.ReleaseName, s.Chart, opts)
+	} else {
+		logger.Infof("Upgrading release %s in namespace %s to chart %s.", s.ReleaseName, s.Namespace, s.Chart)
+		err = runnerSvc.HelmUpgrade(ctx.GoContext(), conn, s.ReleaseName, s.Chart, runner.HelmUpgradeOptions{HelmInstallOptions: opts, Install: true})
+	}
+	if err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to install chart %s", s.Chart))
+		return result, err
+	}
+
+	result.MarkCompleted(fmt.Sprintf("release %s installed", s.ReleaseName))
+	return result, nil
+}
+
+func (s *DeployAddonChartStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		logger.Errorf("Failed to get connector for rollback: %v", err)
+		return nil
+	}
+	if err := ctx.GetRunner().HelmUninstall(ctx.GoContext(), conn, s.ReleaseName, runner.HelmUninstallOptions{
+		Namespace: s.Namespace, KubeconfigPath: s.AdminKubeconfigPath, Sudo: s.Sudo,
+	}); err != nil {
+		logger.Warnf("Failed to uninstall release %s during rollback: %v", s.ReleaseName, err)
+	}
+	return nil
+}
+
+// quoteSetValues single-quotes key=value chart values, as the runner passes them to helm through a shell.
+func quoteSetValues(values []string) []string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(value, "'", `'\''`)+"'")
+	}
+	return quoted
+}
+
+var _ step.Step = (*DeployAddonChartStep)(nil)
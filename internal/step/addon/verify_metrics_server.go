@@ -0,0 +1,117 @@
+package addon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// VerifyMetricsServerStep waits until `kubectl top nodes` returns data, proving metrics-server can scrape the kubelets.
+type VerifyMetricsServerStep struct {
+	step.Base
+	Retries             int
+	Delay               time.Duration
+	AdminKubeconfigPath string
+}
+
+type VerifyMetricsServerStepBuilder struct {
+	step.Builder[VerifyMetricsServerStepBuilder, *VerifyMetricsServerStep]
+}
+
+func NewVerifyMetricsServerStepBuilder(ctx runtime.ExecutionContext, instanceName string) *VerifyMetricsServerStepBuilder {
+	s := &VerifyMetricsServerStep{
+		Retries: common.DefaultMetricsServerVerifyRetries,
+		Delay:   common.DefaultMetricsServerVerifyDelay,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Verify metrics-server serves node metrics", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+	s.AdminKubeconfigPath = filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+
+	b := new(VerifyMetricsServerStepBuilder).Init(s)
+	return b
+}
+
+func (b *VerifyMetricsServerStepBuilder) WithRetries(retries int) *VerifyMetricsServerStepBuilder {
+	b.Step.Retries = retries
+	return b
+}
+
+func (b *VerifyMetricsServerStepBuilder) WithDelay(delay time.Duration) *VerifyMetricsServerStepBuilder {
+	b.Step.Delay = delay
+	return b
+}
+
+func (s *VerifyMetricsServerStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *VerifyMetricsServerStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *VerifyMetricsServerStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	// Metrics only become available after the first scrape, so an empty or failing answer is retried.
+	cmd := fmt.Sprintf("kubectl top nodes --no-headers --kubeconfig %s", s.AdminKubeconfigPath)
+	var lastErr error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.GoContext().Done():
+				result.MarkFailed(ctx.GoContext().Err(), "cancelled while waiting for node metrics")
+				return result, ctx.GoContext().Err()
+			case <-time.After(s.Delay):
+			}
+		}
+		runResult, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo)
+		if err != nil {
+			lastErr = err
+			logger.Debug("Node metrics not available yet.", "attempt", attempt+1, "error", err)
+			continue
+		}
+		if nodes := countNonEmptyLines(runResult.Stdout); nodes > 0 {
+			logger.Infof("metrics-server reports metrics for %d node(s).", nodes)
+			result.MarkCompleted(fmt.Sprintf("metrics-server reports metrics for %d node(s)", nodes))
+			return result, nil
+		}
+		lastErr = fmt.Errorf("kubectl top nodes returned no data")
+	}
+
+	err = fmt.Errorf("metrics-server did not report node metrics after %d attempt(s): %w", s.Retries+1, lastErr)
+	result.MarkFailed(err, "metrics-server verification failed")
+	return result, err
+}
+
+func (s *VerifyMetricsServerStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+func countNonEmptyLines(output string) int {
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+var _ step.Step = (*VerifyMetricsServerStep)(nil)
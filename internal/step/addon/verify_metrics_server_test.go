@@ -0,0 +1,73 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+type fakeTopNodesRunner struct {
+	runner.Runner
+	dataAfterCalls int
+	commands       []string
+}
+
+func (r *fakeTopNodesRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	r.commands = append(r.commands, cmd)
+	if r.dataAfterCalls == 0 || len(r.commands) < r.dataAfterCalls {
+		return nil, errors.New("error: Metrics API not available")
+	}
+	return &runner.CommandResult{Stdout: "master1   120m   6%   1024Mi   27%\n"}, nil
+}
+
+func TestVerifyMetricsServerStep(t *testing.T) {
+	tests := []struct {
+		name           string
+		dataAfterCalls int
+		expectedCalls  int
+		expectedStatus types.StepStatus
+	}{
+		{name: "metrics served on first attempt", dataAfterCalls: 1, expectedCalls: 1, expectedStatus: types.StepStatusCompleted},
+		{name: "metrics served after retries", dataAfterCalls: 3, expectedCalls: 3, expectedStatus: types.StepStatusCompleted},
+		{name: "metrics never served", dataAfterCalls: 0, expectedCalls: 4, expectedStatus: types.StepStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeTopNodesRunner{dataAfterCalls: tt.dataAfterCalls}
+			ctx := &runtimetest.Context{
+				Runner: r,
+				Host:   connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1"}),
+			}
+			s, err := NewVerifyMetricsServerStepBuilder(ctx, "VerifyMetricsServer").
+				WithRetries(3).WithDelay(time.Millisecond).Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+
+			result, err := s.Run(ctx)
+			if result.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q (err: %v)", tt.expectedStatus, result.Status, err)
+			}
+			if (tt.expectedStatus == types.StepStatusFailed) != (err != nil) {
+				t.Errorf("unexpected error result: %v", err)
+			}
+			if len(r.commands) != tt.expectedCalls {
+				t.Errorf("expected %d kubectl calls, got %d", tt.expectedCalls, len(r.commands))
+			}
+			for _, cmd := range r.commands {
+				if !strings.HasPrefix(cmd, "kubectl top nodes") {
+					t.Errorf("expected kubectl top nodes, got %q", cmd)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package addon
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// WaitForRolloutStep waits until an addon workload, e.g. "deployment/ingress-nginx-controller", has rolled out,
+// or meets Condition when one is set.
+type WaitForRolloutStep struct {
+	step.Base
+	Namespace           string
+	Resource            string
+	Condition           string
+	AdminKubeconfigPath string
+}
+
+type WaitForRolloutStepBuilder struct {
+	step.Builder[WaitForRolloutStepBuilder, *WaitForRolloutStep]
+}
+
+func NewWaitForRolloutStepBuilder(ctx runtime.ExecutionContext, instanceName string) *WaitForRolloutStepBuilder {
+	s := &WaitForRolloutStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Wait for an addon workload to become ready", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+	s.AdminKubeconfigPath = filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+
+	b := new(WaitForRolloutStepBuilder).Init(s)
+	return b
+}
+
+func (b *WaitForRolloutStepBuilder) WithResource(namespace, resource string) *WaitForRolloutStepBuilder {
+	b.Step.Namespace = namespace
+	b.Step.Resource = resource
+	return b
+}
+
+// WithCondition waits for the resource to report the given condition, e.g. "Available", instead of its rollout.
+func (b *WaitForRolloutStepBuilder) WithCondition(condition string) *WaitForRolloutStepBuilder {
+	b.Step.Condition = condition
+	return b
+}
+
+func (s *WaitForRolloutStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *WaitForRolloutStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	if s.Resource == "" {
+		return false, fmt.Errorf("no resource to wait for in step %s", s.Base.Meta.Name)
+	}
+	return false, nil
+}
+
+func (s *WaitForRolloutStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	cmd := fmt.Sprintf("kubectl rollout status %s --namespace %s --timeout=%s --kubeconfig %s",
+		s.Resource, s.Namespace, s.Base.Timeout, s.AdminKubeconfigPath)
+	if s.Condition != "" {
+		cmd = fmt.Sprintf("kubectl wait %s --for=condition=%s --namespace %s --timeout=%s --kubeconfig %s",
+			s.Resource, s.Condition, s.Namespace, s.Base.Timeout, s.AdminKubeconfigPath)
+	}
+	logger.Infof("Waiting for %s in namespace %s to become ready.", s.Resource, s.Namespace)
+	if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		err = fmt.Errorf("%s in namespace %s did not become ready within %s: %w", s.Resource, s.Namespace, s.Base.Timeout, err)
+		result.MarkFailed(err, "addon workload is not ready")
+		return result, err
+	}
+
+	result.MarkCompleted(fmt.Sprintf("%s is ready", s.Resource))
+	return result, nil
+}
+
+func (s *WaitForRolloutStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*WaitForRolloutStep)(nil)
@@ -1,10 +1,10 @@
 package binary
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +15,7 @@ import (
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
 	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
 	"github.com/mensylisir/kubexm/internal/types"
 )
@@ -109,7 +110,7 @@ func (s *DownloadBinariesStep) Run(ctx runtime.ExecutionContext) (*types.StepRes
 			workerLogger := logger.With("worker", workerID)
 			for b := range jobs {
 				workerLogger.Info("Starting download.", "binary", b.FileName(), "arch", b.Arch)
-				err := s.downloadAndVerify(workerLogger, b)
+				err := s.downloadAndVerify(ctx.GoContext(), workerLogger, b)
 				if err != nil {
 					workerLogger.Error(err, "Failed to download.", "binary", b.FileName())
 					errChan <- fmt.Errorf("failed to download %s for %s: %w", b.FileName(), b.Arch, err)
@@ -144,7 +145,7 @@ func (s *DownloadBinariesStep) Run(ctx runtime.ExecutionContext) (*types.StepRes
 	return result, nil
 }
 
-func (s *DownloadBinariesStep) downloadAndVerify(logger *logger.Logger, b *binary.Binary) error {
+func (s *DownloadBinariesStep) downloadAndVerify(ctx context.Context, logger *logger.Logger, b *binary.Binary) error {
 	destPath := b.FilePath()
 
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
@@ -168,51 +169,10 @@ func (s *DownloadBinariesStep) downloadAndVerify(logger *logger.Logger, b *binar
 		logger.Warn("File exists but checksum mismatches. Re-downloading.", "file", b.FileName())
 	}
 
-	url := b.URL()
-	logger.Debug("Downloading from URL.", "url", url)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("http get failed for %s: %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status for %s: %s", url, resp.Status)
-	}
-
-	tmpFile, err := os.Create(destPath + ".tmp")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer tmpFile.Close()
-
-	_, err = io.Copy(tmpFile, resp.Body)
-	if err != nil {
-		os.Remove(tmpFile.Name())
-		return fmt.Errorf("failed to copy response body to file: %w", err)
-	}
-
-	if err := tmpFile.Sync(); err != nil {
-		os.Remove(tmpFile.Name())
-		return fmt.Errorf("failed to sync temp file to disk: %w", err)
-	}
-
-	if err := os.Rename(tmpFile.Name(), destPath); err != nil {
-		return fmt.Errorf("failed to rename temp file to final destination: %w", err)
-	}
-
-	if b.Checksum() != "" && b.Checksum() != "dummy-etcd-checksum-val" {
-		logger.Debug("Verifying checksum of downloaded file.", "file", b.FileName())
-		match, err := verifyChecksum(destPath, b.Checksum())
-		if err != nil {
-			return fmt.Errorf("failed to verify checksum for downloaded file %s: %w", destPath, err)
-		}
-		if !match {
-			return fmt.Errorf("checksum mismatch for downloaded file %s", destPath)
-		}
-		logger.Debug("Checksum verified successfully.", "file", b.FileName())
+	if err := helpers.DownloadBinary(ctx, logger, b, false); err != nil {
+		return err
 	}
+	logger.Debug("Downloaded file.", "file", b.FileName())
 
 	return nil
 }
@@ -8,15 +8,31 @@ import (
 type Builder[B any, T Step] struct {
 	Step  T
 	Error error
+	self  *B
 }
 
+// Init returns a new concrete builder B for step. It is called on a throwaway value, as in
+// new(XBuilder).Init(s); only the returned builder is used afterwards.
 func (b *Builder[B, T]) Init(step T) *B {
-	b.Step = step
-	return b.this()
+	self := new(B)
+	embedded, ok := any(self).(interface{ builder() *Builder[B, T] })
+	if !ok {
+		panic(fmt.Sprintf("step builder %T does not embed step.Builder", self))
+	}
+	base := embedded.builder()
+	base.Step = step
+	base.self = self
+	return self
+}
+
+// builder is promoted to the concrete builders embedding Builder, which lets Init reach it.
+func (b *Builder[B, T]) builder() *Builder[B, T] {
+	return b
 }
 
+// this returns the concrete builder that embeds b.
 func (b *Builder[B, T]) this() *B {
-	return any(b).(*B)
+	return b.self
 }
 
 func (b *Builder[B, T]) Build() (T, error) {
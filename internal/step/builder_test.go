@@ -0,0 +1,69 @@
+package step
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/spec"
+)
+
+var errFake = errors.New("fake builder error")
+
+type fakeStep struct {
+	Base
+}
+
+func (s *fakeStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// fakeStepBuilder does not embed Builder as its first field, so Init must not assume the two
+// share an address.
+type fakeStepBuilder struct {
+	image string
+	Builder[fakeStepBuilder, *fakeStep]
+}
+
+func (b *fakeStepBuilder) WithImage(image string) *fakeStepBuilder {
+	b.image = image
+	return b
+}
+
+func TestBuilder_ChainsOnTheConcreteBuilder(t *testing.T) {
+	s := &fakeStep{}
+	b := new(fakeStepBuilder).Init(s).
+		WithImage("pause:3.9").
+		WithName("Fake").
+		WithDescription("fake step").
+		WithSudo(true).
+		WithTimeout(time.Minute).
+		WithIgnoreError(true)
+
+	if b.image != "pause:3.9" {
+		t.Errorf("expected the concrete builder field to survive chaining, got %q", b.image)
+	}
+	built, err := b.Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+	if built != s {
+		t.Fatal("expected Build to return the step passed to Init")
+	}
+	base := built.GetBase()
+	if base.Meta.Name != "Fake" || base.Meta.Description != "fake step" || !base.Sudo || base.Timeout != time.Minute || !base.IgnoreError {
+		t.Errorf("expected the builder options on the step, got %+v", *base)
+	}
+}
+
+func TestBuilder_BuildReturnsRecordedError(t *testing.T) {
+	b := new(fakeStepBuilder).Init(&fakeStep{})
+	b.Error = errFake
+
+	if _, err := b.Build(); err == nil {
+		t.Fatal("expected Build to fail when the builder recorded an error")
+	}
+	if b.Err() != errFake {
+		t.Errorf("expected Err to return the recorded error, got %v", b.Err())
+	}
+}
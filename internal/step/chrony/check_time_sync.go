@@ -105,14 +105,20 @@ func (s *VerifyTimeSyncStep) checkSyncStatus(ctx runtime.ExecutionContext) (bool
 
 	chronyCmd := "chronyc tracking"
 	if _, err := runner.Run(ctx.GoContext(), conn, "command -v chronyc", s.Sudo); err == nil {
-		runResult, err := runner.Run(ctx.GoContext(), conn, chronyCmd, s.Sudo)
-		if err != nil {
-			return false, fmt.Errorf("failed to execute 'chronyc tracking': %w", err)
+		// chronyc cannot reach chronyd when time is synced by another daemon.
+		if runResult, err := runner.Run(ctx.GoContext(), conn, chronyCmd, s.Sudo); err == nil {
+			return strings.Contains(runResult.Stdout, "Leap status     : Normal"), nil
 		}
-		if strings.Contains(runResult.Stdout, "Leap status     : Normal") {
+	}
+
+	timedatectlCmd := "timedatectl show -p NTPSynchronized --value"
+	if runResult, err := runner.Run(ctx.GoContext(), conn, timedatectlCmd, s.Sudo); err == nil {
+		if strings.TrimSpace(runResult.Stdout) == "yes" {
 			return true, nil
 		}
-		return false, nil
+		if _, err := runner.Run(ctx.GoContext(), conn, "systemctl is-active systemd-timesyncd", s.Sudo); err == nil {
+			return false, nil
+		}
 	}
 
 	ntpstatCmd := "ntpstat"
@@ -123,7 +129,7 @@ func (s *VerifyTimeSyncStep) checkSyncStatus(ctx runtime.ExecutionContext) (bool
 		return false, nil
 	}
 
-	return false, fmt.Errorf("neither 'chronyc' nor 'ntpstat' command found")
+	return false, fmt.Errorf("neither chrony, systemd-timesyncd nor 'ntpstat' found")
 }
 
 func (s *VerifyTimeSyncStep) Rollback(ctx runtime.ExecutionContext) error {
@@ -0,0 +1,283 @@
+package chrony
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/templates"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const (
+	timesyncdConfigPath  = "/etc/systemd/timesyncd.conf.d/kubexm.conf"
+	timesyncdServiceName = "systemd-timesyncd"
+)
+
+// ConfigureTimeSyncStep makes a host sync its clock with spec.system.ntpServers through chrony or
+// systemd-timesyncd, installing the daemon if needed. A host that is itself listed in ntpServers
+// serves time to the other hosts of the cluster with chrony. Host names of the cluster in
+// ntpServers are replaced by their addresses, as /etc/hosts may not list them yet.
+type ConfigureTimeSyncStep struct {
+	step.Base
+	Service    string
+	NTPServers []string
+
+	originalConfig []byte
+	configPath     string
+}
+
+type ConfigureTimeSyncStepBuilder struct {
+	step.Builder[ConfigureTimeSyncStepBuilder, *ConfigureTimeSyncStep]
+}
+
+func NewConfigureTimeSyncStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigureTimeSyncStepBuilder {
+	s := &ConfigureTimeSyncStep{
+		Service: common.TimeSyncServiceChrony,
+	}
+	if system := ctx.GetClusterConfig().Spec.System; system != nil {
+		s.NTPServers = system.NTPServers
+		if system.TimeSync != nil && system.TimeSync.Service != "" {
+			s.Service = system.TimeSync.Service
+		}
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Configure %s to sync time with %v", instanceName, s.Service, s.NTPServers)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+	return new(ConfigureTimeSyncStepBuilder).Init(s)
+}
+
+func (s *ConfigureTimeSyncStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// chronyConfPath and chronyServiceName return where the Debian family keeps chrony apart from
+// the other distributions.
+func chronyConfPath(osID string) string {
+	if isDebianFamily(osID) {
+		return "/etc/chrony/chrony.conf"
+	}
+	return "/etc/chrony.conf"
+}
+
+func chronyServiceName(osID string) string {
+	if isDebianFamily(osID) {
+		return "chrony"
+	}
+	return "chronyd"
+}
+
+func isDebianFamily(osID string) bool {
+	for _, id := range common.DebianFamilyDistributions {
+		if strings.EqualFold(id, osID) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveNTPServers returns servers with the host names of the cluster in addresses replaced by
+// their address, leaving out self.
+func resolveNTPServers(servers []string, addresses map[string]string, self string) []string {
+	var resolved []string
+	for _, server := range servers {
+		if server == self {
+			continue
+		}
+		if address, ok := addresses[server]; ok {
+			server = address
+		}
+		resolved = append(resolved, server)
+	}
+	return resolved
+}
+
+// renderTimesyncdConfig returns a timesyncd drop-in that syncs with servers.
+func renderTimesyncdConfig(servers []string) string {
+	return fmt.Sprintf("# Generated by KubeXM. DO NOT EDIT.\n[Time]\nNTP=%s\n", strings.Join(servers, " "))
+}
+
+// plan returns the service, configuration file and its content for the current host.
+func (s *ConfigureTimeSyncStep) plan(ctx runtime.ExecutionContext, facts *runner.Facts) (service, configPath, content string, err error) {
+	osID := ""
+	if facts.OS != nil {
+		osID = facts.OS.ID
+	}
+	self := ctx.GetHost().GetName()
+	addresses := make(map[string]string)
+	var allowNetworks []string
+	for _, host := range ctx.GetHostsByRole("") {
+		addresses[host.GetName()] = host.GetInternalAddress()
+		allowNetworks = append(allowNetworks, host.GetInternalAddress())
+	}
+	servers := resolveNTPServers(s.NTPServers, addresses, self)
+	isServer := len(servers) != len(s.NTPServers)
+
+	if s.Service == common.TimeSyncServiceTimesyncd {
+		if isServer {
+			ctx.GetLogger().Warn("systemd-timesyncd cannot serve time, the host only syncs with the other NTP servers.", "host", self)
+		}
+		return timesyncdServiceName, timesyncdConfigPath, renderTimesyncdConfig(servers), nil
+	}
+
+	var data interface{} = ChronyClientTemplateData{NTPServers: servers}
+	templateName := "chrony/chrony.client.conf.tmpl"
+	if isServer {
+		data = ChronyServerTemplateData{UpstreamServers: servers, AllowNetworks: allowNetworks}
+		templateName = "chrony/chrony.server.conf.tmpl"
+	}
+	tmpl, err := templates.Get(templateName)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get chrony template: %w", err)
+	}
+	content, err = templates.Render(tmpl, data)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to render chrony config: %w", err)
+	}
+	return chronyServiceName(osID), chronyConfPath(osID), content, nil
+}
+
+func (s *ConfigureTimeSyncStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		return false, err
+	}
+	service, configPath, content, err := s.plan(ctx, facts)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := runnerSvc.ReadFile(ctx.GoContext(), conn, configPath)
+	if err != nil || string(current) != content {
+		return false, nil
+	}
+	if active, err := runnerSvc.IsServiceActive(ctx.GoContext(), conn, facts, service); err != nil || !active {
+		return false, nil
+	}
+	logger.Infof("%s is already configured and running.", service)
+	return true, nil
+}
+
+func (s *ConfigureTimeSyncStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "failed to get host facts")
+		return result, err
+	}
+	service, configPath, content, err := s.plan(ctx, facts)
+	if err != nil {
+		result.MarkFailed(err, "failed to render time sync configuration")
+		return result, err
+	}
+
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, "systemctl cat "+service, s.Sudo); err != nil {
+		pkg := "chrony"
+		if s.Service == common.TimeSyncServiceTimesyncd {
+			pkg = "systemd-timesyncd"
+		}
+		logger.Infof("Installing %s...", pkg)
+		if err := runnerSvc.InstallPackages(ctx.GoContext(), conn, facts, pkg); err != nil {
+			err = fmt.Errorf("failed to install %s: %w", pkg, err)
+			result.MarkFailed(err, "failed to install time sync daemon")
+			return result, err
+		}
+	}
+
+	s.configPath = configPath
+	original, err := runnerSvc.ReadFile(ctx.GoContext(), conn, configPath)
+	if err != nil && !os.IsNotExist(err) && !strings.Contains(err.Error(), "No such file or directory") {
+		result.MarkFailed(err, "failed to read time sync configuration")
+		return result, err
+	}
+	s.originalConfig = original
+
+	if s.Service == common.TimeSyncServiceTimesyncd {
+		// Two daemons adjusting the clock fight each other.
+		chronyService := chronyServiceName("")
+		if facts.OS != nil {
+			chronyService = chronyServiceName(facts.OS.ID)
+		}
+		if active, _ := runnerSvc.IsServiceActive(ctx.GoContext(), conn, facts, chronyService); active {
+			logger.Infof("Disabling %s in favor of systemd-timesyncd.", chronyService)
+			if _, err := runnerSvc.Run(ctx.GoContext(), conn, "systemctl disable --now "+chronyService, s.Sudo); err != nil {
+				result.MarkFailed(err, "failed to disable chrony")
+				return result, err
+			}
+		}
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, "mkdir -p /etc/systemd/timesyncd.conf.d", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to create timesyncd drop-in directory")
+			return result, err
+		}
+	}
+
+	logger.Infof("Writing %s configuration to %s", service, configPath)
+	if err := runnerSvc.WriteFile(ctx.GoContext(), conn, []byte(content), configPath, "0644", s.Sudo); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", configPath, err)
+		result.MarkFailed(err, "failed to write time sync configuration")
+		return result, err
+	}
+	if s.Service == common.TimeSyncServiceTimesyncd {
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, "timedatectl set-ntp true", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to enable NTP synchronization")
+			return result, err
+		}
+	}
+	if err := runnerSvc.EnableService(ctx.GoContext(), conn, facts, service); err != nil {
+		result.MarkFailed(err, "failed to enable time sync service")
+		return result, err
+	}
+	if err := runnerSvc.RestartService(ctx.GoContext(), conn, facts, service); err != nil {
+		err = fmt.Errorf("failed to restart %s: %w", service, err)
+		result.MarkFailed(err, "failed to restart time sync service")
+		return result, err
+	}
+
+	logger.Infof("%s configured to sync time.", service)
+	result.MarkCompleted("time synchronization configured")
+	return result, nil
+}
+
+func (s *ConfigureTimeSyncStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	if s.configPath == "" {
+		return nil
+	}
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	if s.originalConfig == nil {
+		err = runnerSvc.Remove(ctx.GoContext(), conn, s.configPath, s.Sudo, false)
+	} else {
+		err = runnerSvc.WriteFile(ctx.GoContext(), conn, s.originalConfig, s.configPath, "0644", s.Sudo)
+	}
+	if err != nil {
+		logger.Warnf("Failed to restore %s: %v", s.configPath, err)
+	}
+	return nil
+}
+
+var _ step.Step = (*ConfigureTimeSyncStep)(nil)
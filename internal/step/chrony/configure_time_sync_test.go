@@ -0,0 +1,34 @@
+package chrony
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveNTPServers(t *testing.T) {
+	addresses := map[string]string{"node1": "10.0.0.1", "node2": "10.0.0.2"}
+	servers := []string{"node1", "ntp.aliyun.com", "node2"}
+
+	if got, want := resolveNTPServers(servers, addresses, "node3"), []string{"10.0.0.1", "ntp.aliyun.com", "10.0.0.2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveNTPServers() for a client = %v, want %v", got, want)
+	}
+	if got, want := resolveNTPServers(servers, addresses, "node1"), []string{"ntp.aliyun.com", "10.0.0.2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveNTPServers() for a server = %v, want %v", got, want)
+	}
+}
+
+func TestChronyPaths(t *testing.T) {
+	if chronyConfPath("ubuntu") != "/etc/chrony/chrony.conf" || chronyServiceName("debian") != "chrony" {
+		t.Error("Debian family hosts should use the chrony package layout of Debian")
+	}
+	if chronyConfPath("rocky") != "/etc/chrony.conf" || chronyServiceName("centos") != "chronyd" {
+		t.Error("other hosts should use the chrony package layout of Red Hat")
+	}
+}
+
+func TestRenderTimesyncdConfig(t *testing.T) {
+	want := "# Generated by KubeXM. DO NOT EDIT.\n[Time]\nNTP=10.0.0.1 ntp.aliyun.com\n"
+	if got := renderTimesyncdConfig([]string{"10.0.0.1", "ntp.aliyun.com"}); got != want {
+		t.Errorf("renderTimesyncdConfig() = %q, want %q", got, want)
+	}
+}
@@ -2,10 +2,8 @@ package common
 
 import (
 	"fmt"
-	"net"
-	"strconv"
-	"time"
 
+	"github.com/mensylisir/kubexm/internal/connector"
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
@@ -13,7 +11,8 @@ import (
 	"github.com/mensylisir/kubexm/internal/types"
 )
 
-// CheckConnectivityStep checks SSH connectivity to a host.
+// CheckConnectivityStep checks SSH connectivity to a host, reporting which stage
+// (resolve, dial, handshake or auth) failed when the host is unreachable.
 type CheckConnectivityStep struct {
 	step.Base
 	Host remotefw.Host
@@ -47,23 +46,22 @@ func (s *CheckConnectivityStep) Run(ctx runtime.ExecutionContext) (*types.StepRe
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), s.Host)
 	log := ctx.GetLogger()
 
-	addr := net.JoinHostPort(s.Host.GetAddress(), strconv.Itoa(s.Host.GetPort()))
-
-	// Check TCP connection (SSH port)
-	timeout := 5 * time.Second
-	start := time.Now()
-	conn, err := net.DialTimeout("tcp", addr, timeout)
-	elapsed := time.Since(start)
-
-	if err != nil {
-		log.Error(err, "Failed to connect", "host", s.Host.GetAddress(), "port", s.Host.GetPort())
-		result.MarkFailed(err, fmt.Sprintf("cannot connect to %s:%d", s.Host.GetAddress(), s.Host.GetPort()))
+	probe := connector.Probe(ctx.GoContext(), s.Host)
+	if !probe.OK() {
+		err := fmt.Errorf("%s", probe.Reason())
+		log.Error(probe.Err, "Host unreachable", "host", s.Host.GetAddress(), "port", s.Host.GetPort(),
+			"stage", probe.Stage, "timedOut", probe.TimedOut, "elapsed", probe.Total)
+		result.MarkFailed(err, fmt.Sprintf("cannot connect to %s:%d: %s stage failed", s.Host.GetAddress(), s.Host.GetPort(), probe.Stage))
 		return result, err
 	}
-	conn.Close()
 
-	log.Info("Host reachable", "host", s.Host.GetAddress(), "port", s.Host.GetPort(), "elapsed", elapsed)
-	result.MarkCompleted(fmt.Sprintf("Connected to %s:%d in %v", s.Host.GetAddress(), s.Host.GetPort(), elapsed))
+	if probe.Local {
+		log.Info("Local host, skipping SSH check", "host", s.Host.GetName())
+		result.MarkCompleted(probe.Reason())
+		return result, nil
+	}
+	log.Info("Host reachable", "host", s.Host.GetAddress(), "port", s.Host.GetPort(), "elapsed", probe.Total)
+	result.MarkCompleted(fmt.Sprintf("Connected to %s:%d in %v", s.Host.GetAddress(), s.Host.GetPort(), probe.Total))
 	return result, nil
 }
 
@@ -11,14 +11,15 @@ import (
 
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
+	rn "github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
 	"github.com/mensylisir/kubexm/internal/step/helpers"
 	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
-	"github.com/mensylisir/kubexm/internal/util/images"
 	"github.com/mensylisir/kubexm/internal/templates"
 	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/images"
 )
 
 const (
@@ -44,6 +45,11 @@ type ConfigureContainerdStep struct {
 	Cni             CniConfig
 	RegistryMirrors map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig
 	RegistryConfigs map[v1alpha1.ServerAddress]v1alpha1.AuthConfig
+	// RegistryConfigPath is set when registries use hosts.toml files. Mirrors and TLS settings then
+	// live in those files and only auth entries stay in config.toml.
+	RegistryConfigPath string
+	BackupRetention    int
+	lastBackup         string
 }
 
 type ConfigureContainerdStepBuilder struct {
@@ -81,11 +87,13 @@ func NewConfigureContainerdStepBuilder(ctx runtime.ExecutionContext, instanceNam
 		Root:            common.ContainerdDefaultRoot,
 		State:           common.ContainerdDefaultState,
 		Grpc:            GrpcConfig{Address: strings.TrimPrefix(common.ContainerdDefaultEndpoint, "unix://")},
-		SystemdCgroup:   common.CgroupDriverSystemd,
+		SystemdCgroup:   systemdCgroupValue(common.CgroupDriverSystemd),
 		SandboxImage:    sandboxImage,
 		Cni:             CniConfig{BinDir: common.DefaultCNIBin, ConfDir: common.DefaultCNIConfDirTarget},
-		RegistryMirrors: make(map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig),
-		RegistryConfigs: make(map[v1alpha1.ServerAddress]v1alpha1.AuthConfig),
+		BackupRetention: common.DefaultConfigBackupRetention,
+	}
+	if retention := cfg.Kubernetes.ContainerRuntime.ConfigBackupRetention; retention != nil {
+		s.BackupRetention = *retention
 	}
 
 	if containerdCfg != nil {
@@ -102,10 +110,30 @@ func NewConfigureContainerdStepBuilder(ctx runtime.ExecutionContext, instanceNam
 			s.Grpc.Address = strings.TrimPrefix(containerdCfg.Endpoint, "unix://")
 		}
 		if containerdCfg.CgroupDriver != nil {
-			s.SystemdCgroup = *containerdCfg.CgroupDriver
+			s.SystemdCgroup = systemdCgroupValue(*containerdCfg.CgroupDriver)
 		}
 	}
 
+	s.RegistryMirrors, s.RegistryConfigs = collectRegistryConfig(cfg, containerdCfg)
+	if s.RegistryConfigPath = containerdCfg.RegistryHostsDir(); s.RegistryConfigPath != "" {
+		s.RegistryMirrors, s.RegistryConfigs = nil, inlineRegistryAuths(s.RegistryConfigs)
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Configure containerd", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 1 * time.Minute
+
+	b := new(ConfigureContainerdStepBuilder).Init(s)
+	return b
+}
+
+// collectRegistryConfig merges the cluster-wide registry settings with the containerd specific mirrors and
+// configs, the latter taking precedence.
+func collectRegistryConfig(cfg *v1alpha1.ClusterSpec, containerdCfg *v1alpha1.Containerd) (map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig, map[v1alpha1.ServerAddress]v1alpha1.AuthConfig) {
+	mirrors := make(map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig)
+	configs := make(map[v1alpha1.ServerAddress]v1alpha1.AuthConfig)
 	if cfg.Registry != nil && cfg.Registry.MirroringAndRewriting != nil && cfg.Registry.MirroringAndRewriting.PrivateRegistry != "" {
 		privateRegistryHost := cfg.Registry.MirroringAndRewriting.PrivateRegistry
 		if u, err := url.Parse("scheme://" + privateRegistryHost); err == nil {
@@ -116,10 +144,14 @@ func NewConfigureContainerdStepBuilder(ctx runtime.ExecutionContext, instanceNam
 		_, hasExplicitAuth := cfg.Registry.Auths[string(serverAddr)]
 
 		if !hasExplicitAuth {
-			s.RegistryConfigs[serverAddr] = v1alpha1.AuthConfig{
+			configs[serverAddr] = v1alpha1.AuthConfig{
 				TLS: nil,
 			}
 		}
+		// A registry served over plain HTTP is only reachable through an explicit http:// endpoint.
+		if cfg.Registry.IsPlainHTTP(privateRegistryHost) {
+			mirrors[serverAddr] = v1alpha1.MirrorConfig{Endpoints: []string{"http://" + privateRegistryHost}}
+		}
 	}
 
 	if cfg.Registry != nil && len(cfg.Registry.Auths) > 0 {
@@ -140,26 +172,40 @@ func NewConfigureContainerdStepBuilder(ctx runtime.ExecutionContext, instanceNam
 			if auth.PlainHTTP != nil && *auth.PlainHTTP {
 				authConfig.TLS = nil
 			}
-			s.RegistryConfigs[serverAddr] = authConfig
+			configs[serverAddr] = authConfig
 		}
 	}
 	if containerdCfg != nil && containerdCfg.Registry != nil {
 		for server, mirror := range containerdCfg.Registry.Mirrors {
-			s.RegistryMirrors[server] = mirror
+			mirrors[server] = mirror
 		}
 		for server, config := range containerdCfg.Registry.Configs {
-			s.RegistryConfigs[server] = config
+			configs[server] = config
 		}
 	}
+	return mirrors, configs
+}
 
-	s.Base.Meta.Name = instanceName
-	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Configure containerd", s.Base.Meta.Name)
-	s.Base.Sudo = false
-	s.Base.IgnoreError = false
-	s.Base.Timeout = 1 * time.Minute
+// inlineRegistryAuths keeps only the auth part of the registry configs, which is what config.toml may
+// still carry once mirrors and TLS settings have moved to hosts.toml.
+func inlineRegistryAuths(configs map[v1alpha1.ServerAddress]v1alpha1.AuthConfig) map[v1alpha1.ServerAddress]v1alpha1.AuthConfig {
+	auths := make(map[v1alpha1.ServerAddress]v1alpha1.AuthConfig)
+	for server, config := range configs {
+		if config.Auth != nil {
+			auths[server] = v1alpha1.AuthConfig{Auth: config.Auth}
+		}
+	}
+	return auths
+}
 
-	b := new(ConfigureContainerdStepBuilder).Init(s)
-	return b
+// systemdCgroupValue maps the configured cgroup driver onto the boolean expected by the runc SystemdCgroup option.
+func systemdCgroupValue(driver string) string {
+	switch driver {
+	case common.CgroupDriverSystemd, "true":
+		return "true"
+	default:
+		return "false"
+	}
 }
 
 func (s *ConfigureContainerdStep) Meta() *spec.StepMeta {
@@ -183,6 +229,28 @@ func (s *ConfigureContainerdStep) renderContent() (string, error) {
 	return buf.String(), nil
 }
 
+// Render returns the validated containerd config that Run writes to TargetPath.
+func (s *ConfigureContainerdStep) Render(ctx runtime.ExecutionContext) (string, error) {
+	content, err := s.renderContent()
+	if err != nil {
+		return "", err
+	}
+	validated, err := ctx.GetRunner().RenderContainerdConfig(rn.ContainerdConfigOptions{}, []byte(content))
+	if err != nil {
+		return "", fmt.Errorf("rendered containerd config is invalid: %w", err)
+	}
+	return string(validated), nil
+}
+
+// RenderFiles returns the containerd config of Render at TargetPath.
+func (s *ConfigureContainerdStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.Render(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: s.TargetPath, Content: []byte(content)}}, nil
+}
+
 func (s *ConfigureContainerdStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	runner := ctx.GetRunner()
@@ -240,6 +308,22 @@ func (s *ConfigureContainerdStep) Run(ctx runtime.ExecutionContext) (*types.Step
 		result.MarkFailed(err, "failed to render content")
 		return result, err
 	}
+	validated, err := runner.RenderContainerdConfig(rn.ContainerdConfigOptions{}, []byte(content))
+	if err != nil {
+		result.MarkFailed(err, "rendered containerd config is invalid")
+		return result, err
+	}
+	content = string(validated)
+
+	backupPath, err := helpers.BackupRemoteConfig(ctx, conn, s.TargetPath, s.BackupRetention, s.Sudo)
+	if err != nil {
+		result.MarkFailed(err, "failed to back up containerd config")
+		return result, err
+	}
+	if backupPath != "" {
+		s.lastBackup = backupPath
+		logger.Info("Backed up existing containerd config file.", "backup", backupPath)
+	}
 
 	logger.Info("Writing containerd config file.", "path", s.TargetPath)
 	if err := helpers.WriteContentToRemote(ctx, conn, content, s.TargetPath, "0644", s.Sudo); err != nil {
@@ -259,6 +343,14 @@ func (s *ConfigureContainerdStep) Rollback(ctx runtime.ExecutionContext) error {
 		return nil
 	}
 
+	if s.lastBackup != "" {
+		logger.Warn("Rolling back by restoring backup.", "path", s.TargetPath, "backup", s.lastBackup)
+		if err := helpers.RestoreRemoteConfigBackup(ctx, conn, s.TargetPath, s.lastBackup, s.Sudo); err != nil {
+			logger.Error(err, "Failed to restore config backup during rollback.", "backup", s.lastBackup)
+		}
+		return nil
+	}
+
 	logger.Warn("Rolling back by removing.", "path", s.TargetPath)
 	if err := runner.Remove(ctx.GoContext(), conn, s.TargetPath, s.Sudo, false); err != nil {
 		if !strings.Contains(err.Error(), "no such file or directory") {
@@ -269,4 +361,23 @@ func (s *ConfigureContainerdStep) Rollback(ctx runtime.ExecutionContext) error {
 	return nil
 }
 
+// ListBackups returns the timestamped backups of the containerd config file, newest first.
+func (s *ConfigureContainerdStep) ListBackups(ctx runtime.ExecutionContext) ([]string, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ListRemoteConfigBackups(ctx, conn, s.TargetPath, s.Sudo)
+}
+
+// RestoreBackup replaces the containerd config file with one of the backups returned by ListBackups.
+func (s *ConfigureContainerdStep) RestoreBackup(ctx runtime.ExecutionContext, backupPath string) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	return helpers.RestoreRemoteConfigBackup(ctx, conn, s.TargetPath, backupPath, s.Sudo)
+}
+
 var _ step.Step = (*ConfigureContainerdStep)(nil)
+var _ step.Renderer = (*ConfigureContainerdStep)(nil)
@@ -0,0 +1,201 @@
+package containerd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+type fakeBackupRunner struct {
+	runner.Runner
+	files    map[string]string
+	uploaded string
+}
+
+func (r *fakeBackupRunner) Exists(ctx context.Context, conn connector.Connector, path string) (bool, error) {
+	_, ok := r.files[path]
+	return ok, nil
+}
+
+func (r *fakeBackupRunner) ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error) {
+	return []byte(r.files[path]), nil
+}
+
+func (r *fakeBackupRunner) Mkdirp(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeBackupRunner) Upload(ctx context.Context, conn connector.Connector, srcPath, destPath string, sudo bool) error {
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	r.uploaded = string(content)
+	return nil
+}
+
+func (r *fakeBackupRunner) Move(ctx context.Context, conn connector.Connector, src, dest string, sudo bool) error {
+	r.files[dest] = r.uploaded
+	return nil
+}
+
+func (r *fakeBackupRunner) Chmod(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeBackupRunner) CopyFile(ctx context.Context, conn connector.Connector, src, dest string, recursive bool, sudo bool) error {
+	r.files[dest] = r.files[src]
+	return nil
+}
+
+func (r *fakeBackupRunner) Remove(ctx context.Context, conn connector.Connector, path string, sudo bool, recursive bool) error {
+	delete(r.files, path)
+	return nil
+}
+
+func (r *fakeBackupRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	dir := strings.TrimPrefix(cmd, "ls -1 ")
+	var names []string
+	for path := range r.files {
+		if filepath.Dir(path) == dir {
+			names = append(names, filepath.Base(path))
+		}
+	}
+	sort.Strings(names)
+	return &runner.CommandResult{Stdout: strings.Join(names, "\n")}, nil
+}
+
+func (r *fakeBackupRunner) backups(path string) []string {
+	var backups []string
+	for p := range r.files {
+		if strings.HasPrefix(p, path+".") && strings.HasSuffix(p, ".bak") {
+			backups = append(backups, p)
+		}
+	}
+	return backups
+}
+
+func newTestConfigureContainerdStep(retention int) *ConfigureContainerdStep {
+	s := &ConfigureContainerdStep{
+		TargetPath:      common.ContainerdDefaultConfigFile,
+		Root:            common.ContainerdDefaultRoot,
+		State:           common.ContainerdDefaultState,
+		Grpc:            GrpcConfig{Address: "/run/containerd/containerd.sock"},
+		SystemdCgroup:   "true",
+		SandboxImage:    "registry.k8s.io/pause:3.9",
+		Cni:             CniConfig{BinDir: common.DefaultCNIBin, ConfDir: common.DefaultCNIConfDirTarget},
+		RegistryMirrors: map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig{},
+		RegistryConfigs: map[v1alpha1.ServerAddress]v1alpha1.AuthConfig{},
+		BackupRetention: retention,
+	}
+	s.Base.Meta.Name = "ConfigureContainerd"
+	return s
+}
+
+func TestConfigureContainerdStep_BackupOnChange(t *testing.T) {
+	r := &fakeBackupRunner{files: map[string]string{common.ContainerdDefaultConfigFile: testContainerdConfig}}
+	ctx := &runtimetest.Context{
+		Runner:  r,
+		Host:    connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+		WorkDir: t.TempDir(),
+	}
+	s := newTestConfigureContainerdStep(3)
+
+	result, err := s.Run(ctx)
+	if err != nil || result.Status != types.StepStatusCompleted {
+		t.Fatalf("run failed: status=%q err=%v", result.Status, err)
+	}
+	backups := r.backups(common.ContainerdDefaultConfigFile)
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	if r.files[backups[0]] != testContainerdConfig {
+		t.Errorf("backup does not hold the previous config:\n%s", r.files[backups[0]])
+	}
+	if r.files[common.ContainerdDefaultConfigFile] == testContainerdConfig {
+		t.Error("expected the config file to be rewritten")
+	}
+
+	listed, err := s.ListBackups(ctx)
+	if err != nil || len(listed) != 1 || listed[0] != backups[0] {
+		t.Fatalf("expected ListBackups to return %v, got %v (err=%v)", backups, listed, err)
+	}
+	if err := s.RestoreBackup(ctx, listed[0]); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if r.files[common.ContainerdDefaultConfigFile] != testContainerdConfig {
+		t.Error("expected restore to bring back the previous config")
+	}
+	if err := s.RestoreBackup(ctx, "/etc/containerd/other.toml"); err == nil {
+		t.Error("expected restoring a non-backup path to fail")
+	}
+}
+
+func TestConfigureContainerdStep_BackupRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention int
+		runs      int
+		expected  int
+	}{
+		{name: "prunes beyond retention", retention: 2, runs: 4, expected: 2},
+		{name: "keeps all within retention", retention: 5, runs: 3, expected: 3},
+		{name: "zero retention disables backups", retention: 0, runs: 2, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeBackupRunner{files: map[string]string{common.ContainerdDefaultConfigFile: testContainerdConfig}}
+			ctx := &runtimetest.Context{
+				Runner:  r,
+				Host:    connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+				WorkDir: t.TempDir(),
+			}
+			s := newTestConfigureContainerdStep(tt.retention)
+
+			var newest string
+			for i := 0; i < tt.runs; i++ {
+				if _, err := s.Run(ctx); err != nil {
+					t.Fatalf("run %d failed: %v", i, err)
+				}
+				newest = s.lastBackup
+			}
+			backups := r.backups(common.ContainerdDefaultConfigFile)
+			if len(backups) != tt.expected {
+				t.Fatalf("expected %d backups, got %d: %v", tt.expected, len(backups), backups)
+			}
+			if tt.expected > 0 {
+				if _, ok := r.files[newest]; !ok {
+					t.Errorf("newest backup %s was pruned", newest)
+				}
+			}
+		})
+	}
+}
+
+func (r *fakeBackupRunner) RenderContainerdConfig(opts runner.ContainerdConfigOptions, current []byte) ([]byte, error) {
+	return runner.NewRunner().RenderContainerdConfig(opts, current)
+}
+
+func TestSystemdCgroupValue(t *testing.T) {
+	for driver, want := range map[string]string{
+		common.CgroupDriverSystemd:  "true",
+		"true":                      "true",
+		common.CgroupDriverCgroupfs: "false",
+		"":                          "false",
+	} {
+		if got := systemdCgroupValue(driver); got != want {
+			t.Errorf("systemdCgroupValue(%q) = %q, want %q", driver, got, want)
+		}
+	}
+}
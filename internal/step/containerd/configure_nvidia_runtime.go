@@ -0,0 +1,268 @@
+package containerd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const containerdRuncV2RuntimeType = "io.containerd.runc.v2"
+
+// ConfigureNvidiaRuntimeStep registers the nvidia runtime in the containerd CRI plugin on hosts
+// where an NVIDIA GPU has been detected. Hosts without a GPU are left untouched.
+type ConfigureNvidiaRuntimeStep struct {
+	step.Base
+	TargetPath    string
+	BinaryName    string
+	SetAsDefault  bool
+	SystemdCgroup bool
+	Enabled       bool
+}
+
+type ConfigureNvidiaRuntimeStepBuilder struct {
+	step.Builder[ConfigureNvidiaRuntimeStepBuilder, *ConfigureNvidiaRuntimeStep]
+}
+
+func NewConfigureNvidiaRuntimeStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigureNvidiaRuntimeStepBuilder {
+	s := &ConfigureNvidiaRuntimeStep{
+		TargetPath:    common.ContainerdDefaultConfigFile,
+		BinaryName:    common.DefaultNvidiaRuntimeBinary,
+		SystemdCgroup: true,
+		Enabled:       true,
+	}
+
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg != nil && clusterCfg.Spec != nil && clusterCfg.Spec.Kubernetes != nil &&
+		clusterCfg.Spec.Kubernetes.ContainerRuntime != nil && clusterCfg.Spec.Kubernetes.ContainerRuntime.Containerd != nil {
+		containerdCfg := clusterCfg.Spec.Kubernetes.ContainerRuntime.Containerd
+		if containerdCfg.ConfigPath != nil && *containerdCfg.ConfigPath != "" {
+			s.TargetPath = *containerdCfg.ConfigPath
+		}
+		if containerdCfg.CgroupDriver != nil {
+			s.SystemdCgroup = systemdCgroupValue(*containerdCfg.CgroupDriver) == "true"
+		}
+		if nvidiaCfg := containerdCfg.NvidiaRuntime; nvidiaCfg != nil {
+			if nvidiaCfg.Enabled != nil {
+				s.Enabled = *nvidiaCfg.Enabled
+			}
+			if nvidiaCfg.BinaryName != "" {
+				s.BinaryName = nvidiaCfg.BinaryName
+			}
+			s.SetAsDefault = nvidiaCfg.SetAsDefault
+		}
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Configure containerd nvidia runtime on GPU nodes", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(ConfigureNvidiaRuntimeStepBuilder).Init(s)
+	return b
+}
+
+func (s *ConfigureNvidiaRuntimeStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *ConfigureNvidiaRuntimeStep) hasNvidiaGPU(ctx runtime.ExecutionContext, conn runner.Connector) (bool, error) {
+	facts, err := ctx.GetRunner().GatherHostFacts(ctx.GoContext(), conn)
+	if err != nil {
+		return false, fmt.Errorf("failed to gather host facts for GPU detection: %w", err)
+	}
+	return facts.HasGPUVendor(runner.GPUVendorNVIDIA), nil
+}
+
+// Merge returns content with the nvidia runtime registered the way Run writes it, or content
+// unchanged when the step is disabled or the current host has no NVIDIA GPU.
+func (s *ConfigureNvidiaRuntimeStep) Merge(ctx runtime.ExecutionContext, content string) (string, error) {
+	if !s.Enabled {
+		return content, nil
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", err
+	}
+	hasGPU, err := s.hasNvidiaGPU(ctx, conn)
+	if err != nil || !hasGPU {
+		return content, err
+	}
+	merged, _, err := mergeNvidiaRuntimeConfig([]byte(content), s.BinaryName, s.SystemdCgroup, s.SetAsDefault)
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
+
+func (s *ConfigureNvidiaRuntimeStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if !s.Enabled {
+		logger.Info("Nvidia runtime configuration is disabled. Step is done.")
+		return true, nil
+	}
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+
+	hasGPU, err := s.hasNvidiaGPU(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+	if !hasGPU {
+		logger.Info("No NVIDIA GPU detected on host. Step is done.")
+		return true, nil
+	}
+
+	current, err := ctx.GetRunner().ReadFile(ctx.GoContext(), conn, s.TargetPath)
+	if err != nil {
+		logger.Info("Containerd config file could not be read. Step needs to run.", "path", s.TargetPath)
+		return false, nil
+	}
+	_, changed, err := mergeNvidiaRuntimeConfig(current, s.BinaryName, s.SystemdCgroup, s.SetAsDefault)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		logger.Info("Nvidia runtime is already configured in containerd. Step is done.", "path", s.TargetPath)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *ConfigureNvidiaRuntimeStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	if !s.Enabled {
+		result.MarkSkipped("nvidia runtime configuration is disabled")
+		return result, nil
+	}
+	hasGPU, err := s.hasNvidiaGPU(ctx, conn)
+	if err != nil {
+		result.MarkFailed(err, "failed to detect GPUs")
+		return result, err
+	}
+	if !hasGPU {
+		logger.Info("No NVIDIA GPU detected on host, skipping nvidia runtime configuration.")
+		result.MarkSkipped("no NVIDIA GPU detected")
+		return result, nil
+	}
+
+	current, err := runnerSvc.ReadFile(ctx.GoContext(), conn, s.TargetPath)
+	if err != nil {
+		result.MarkFailed(err, "failed to read containerd config")
+		return result, fmt.Errorf("failed to read containerd config '%s': %w", s.TargetPath, err)
+	}
+	merged, changed, err := mergeNvidiaRuntimeConfig(current, s.BinaryName, s.SystemdCgroup, s.SetAsDefault)
+	if err != nil {
+		result.MarkFailed(err, "failed to merge nvidia runtime into containerd config")
+		return result, err
+	}
+	if !changed {
+		result.MarkCompleted("nvidia runtime already configured")
+		return result, nil
+	}
+
+	logger.Info("Registering nvidia runtime in containerd config.", "path", s.TargetPath, "binary", s.BinaryName, "default", s.SetAsDefault)
+	if err := helpers.WriteContentToRemote(ctx, conn, string(merged), s.TargetPath, "0644", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to write containerd config")
+		return result, err
+	}
+
+	facts, err := runnerSvc.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		result.MarkFailed(err, "failed to gather facts")
+		return result, fmt.Errorf("failed to gather facts to restart containerd: %w", err)
+	}
+	active, err := runnerSvc.IsServiceActive(ctx.GoContext(), conn, facts, containerdServiceName)
+	if err != nil {
+		result.MarkFailed(err, "failed to determine containerd service status")
+		return result, fmt.Errorf("failed to determine containerd service status: %w", err)
+	}
+	if active {
+		logger.Info("Restarting containerd to load the nvidia runtime.")
+		if err := runnerSvc.RestartService(ctx.GoContext(), conn, facts, containerdServiceName); err != nil {
+			result.MarkFailed(err, "failed to restart containerd service")
+			return result, fmt.Errorf("failed to restart containerd service: %w", err)
+		}
+	}
+
+	result.SetMetadata("binaryName", s.BinaryName)
+	result.SetMetadata("setAsDefault", s.SetAsDefault)
+	result.MarkCompleted("nvidia runtime configured successfully")
+	return result, nil
+}
+
+func (s *ConfigureNvidiaRuntimeStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Nvidia runtime configuration is rewritten by ConfigureContainerd on rollback, no specific action.")
+	return nil
+}
+
+// mergeNvidiaRuntimeConfig adds the nvidia runtime table to a containerd version 2 config and reports whether
+// anything changed. Keys are walked segment by segment because the CRI plugin key itself contains dots.
+func mergeNvidiaRuntimeConfig(current []byte, binaryName string, systemdCgroup, setAsDefault bool) ([]byte, bool, error) {
+	cfg := make(map[string]interface{})
+	if err := toml.Unmarshal(current, &cfg); err != nil {
+		return nil, false, fmt.Errorf("failed to parse containerd config: %w", err)
+	}
+
+	criContainerd := ensureTomlTable(cfg, "plugins", common.ContainerdPluginCRI, "containerd")
+	runtimes := ensureTomlTable(criContainerd, "runtimes")
+	nvidia := ensureTomlTable(runtimes, common.ContainerdNvidiaRuntimeName)
+	options := ensureTomlTable(nvidia, "options")
+
+	changed := false
+	set := func(table map[string]interface{}, key string, value interface{}) {
+		if existing, ok := table[key]; !ok || existing != value {
+			table[key] = value
+			changed = true
+		}
+	}
+	set(nvidia, "runtime_type", containerdRuncV2RuntimeType)
+	set(options, "BinaryName", binaryName)
+	set(options, "SystemdCgroup", systemdCgroup)
+	if setAsDefault {
+		set(criContainerd, "default_runtime_name", common.ContainerdNvidiaRuntimeName)
+	}
+
+	if !changed {
+		return current, false, nil
+	}
+	out, err := toml.Marshal(cfg)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode containerd config: %w", err)
+	}
+	return out, true, nil
+}
+
+func ensureTomlTable(root map[string]interface{}, keys ...string) map[string]interface{} {
+	current := root
+	for _, key := range keys {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	return current
+}
+
+var _ step.Step = (*ConfigureNvidiaRuntimeStep)(nil)
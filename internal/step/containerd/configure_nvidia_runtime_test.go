@@ -0,0 +1,214 @@
+package containerd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const testContainerdConfig = `version = 2
+
+[plugins]
+  [plugins."io.containerd.grpc.v1.cri"]
+    sandbox_image = "registry.k8s.io/pause:3.9"
+    [plugins."io.containerd.grpc.v1.cri".containerd]
+      default_runtime_name = "runc"
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes]
+        [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc]
+          runtime_type = "io.containerd.runc.v2"
+          [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+            SystemdCgroup = true
+`
+
+type fakeNvidiaRunner struct {
+	runner.Runner
+	hostFacts *runner.HostFacts
+	files     map[string]string
+	uploaded  string
+	restarted bool
+}
+
+func (r *fakeNvidiaRunner) GatherHostFacts(ctx context.Context, conn connector.Connector) (*runner.HostFacts, error) {
+	return r.hostFacts, nil
+}
+
+func (r *fakeNvidiaRunner) GatherFacts(ctx context.Context, conn connector.Connector) (*runner.Facts, error) {
+	return &runner.Facts{}, nil
+}
+
+func (r *fakeNvidiaRunner) ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error) {
+	return []byte(r.files[path]), nil
+}
+
+func (r *fakeNvidiaRunner) Mkdirp(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeNvidiaRunner) Upload(ctx context.Context, conn connector.Connector, srcPath, destPath string, sudo bool) error {
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	r.uploaded = string(content)
+	return nil
+}
+
+func (r *fakeNvidiaRunner) Move(ctx context.Context, conn connector.Connector, src, dest string, sudo bool) error {
+	r.files[dest] = r.uploaded
+	return nil
+}
+
+func (r *fakeNvidiaRunner) Chmod(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeNvidiaRunner) Remove(ctx context.Context, conn connector.Connector, path string, sudo bool, recursive bool) error {
+	return nil
+}
+
+func (r *fakeNvidiaRunner) IsServiceActive(ctx context.Context, conn connector.Connector, facts *runner.Facts, serviceName string) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeNvidiaRunner) RestartService(ctx context.Context, conn connector.Connector, facts *runner.Facts, serviceName string) error {
+	r.restarted = true
+	return nil
+}
+
+func (r *fakeNvidiaRunner) RenderContainerdRegistryHosts(opts runner.ContainerdRegistryHostsOptions) ([]byte, error) {
+	return runner.NewRunner().RenderContainerdRegistryHosts(opts)
+}
+
+func newNvidiaTestContext(t *testing.T, facts *runner.HostFacts) (*runtimetest.Context, *fakeNvidiaRunner) {
+	r := &fakeNvidiaRunner{
+		hostFacts: facts,
+		files:     map[string]string{"/etc/containerd/config.toml": testContainerdConfig},
+	}
+	ctx := &runtimetest.Context{
+		Runner:  r,
+		Host:    connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+		WorkDir: t.TempDir(),
+	}
+	return ctx, r
+}
+
+func TestConfigureNvidiaRuntimeStep_GPUNode(t *testing.T) {
+	ctx, r := newNvidiaTestContext(t, &runner.HostFacts{
+		GPUs: []runner.GPUDevice{{Vendor: runner.GPUVendorNVIDIA, Model: "NVIDIA Corporation GA100", PCIAddress: "3b:00.0"}},
+	})
+	s, err := NewConfigureNvidiaRuntimeStepBuilder(ctx, "ConfigureNvidiaRuntime").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	done, err := s.Precheck(ctx)
+	if err != nil || done {
+		t.Fatalf("expected precheck to require a run, got done=%v err=%v", done, err)
+	}
+	result, err := s.Run(ctx)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Status != types.StepStatusCompleted {
+		t.Fatalf("expected step to succeed, got %q", result.Status)
+	}
+	if !r.restarted {
+		t.Error("expected containerd to be restarted")
+	}
+
+	var cfg map[string]interface{}
+	if err := toml.Unmarshal([]byte(r.files["/etc/containerd/config.toml"]), &cfg); err != nil {
+		t.Fatalf("written config is not valid TOML: %v", err)
+	}
+	criContainerd := ensureTomlTable(cfg, "plugins", "io.containerd.grpc.v1.cri", "containerd")
+	nvidia, ok := criContainerd["runtimes"].(map[string]interface{})["nvidia"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("nvidia runtime table missing from config:\n%s", r.files["/etc/containerd/config.toml"])
+	}
+	if nvidia["runtime_type"] != "io.containerd.runc.v2" {
+		t.Errorf("unexpected runtime_type %v", nvidia["runtime_type"])
+	}
+	if got := nvidia["options"].(map[string]interface{})["BinaryName"]; got != "/usr/bin/nvidia-container-runtime" {
+		t.Errorf("unexpected BinaryName %v", got)
+	}
+	if criContainerd["default_runtime_name"] != "runc" {
+		t.Errorf("default runtime should be unchanged, got %v", criContainerd["default_runtime_name"])
+	}
+
+	done, err = s.Precheck(ctx)
+	if err != nil || !done {
+		t.Errorf("expected precheck to report done after run, got done=%v err=%v", done, err)
+	}
+}
+
+func TestConfigureNvidiaRuntimeStep_NonGPUNode(t *testing.T) {
+	ctx, r := newNvidiaTestContext(t, &runner.HostFacts{})
+	s, err := NewConfigureNvidiaRuntimeStepBuilder(ctx, "ConfigureNvidiaRuntime").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	done, err := s.Precheck(ctx)
+	if err != nil || !done {
+		t.Fatalf("expected precheck to skip non-GPU host, got done=%v err=%v", done, err)
+	}
+	result, err := s.Run(ctx)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Status != types.StepStatusSkipped {
+		t.Errorf("expected step to be skipped, got %q", result.Status)
+	}
+	if r.files["/etc/containerd/config.toml"] != testContainerdConfig || r.restarted {
+		t.Error("config should not be touched on a host without a GPU")
+	}
+}
+
+func TestMergeNvidiaRuntimeConfig_SetAsDefault(t *testing.T) {
+	merged, changed, err := mergeNvidiaRuntimeConfig([]byte(testContainerdConfig), "/usr/local/bin/nvidia-container-runtime", true, true)
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected merge to report a change")
+	}
+	if !strings.Contains(string(merged), "default_runtime_name = 'nvidia'") {
+		t.Errorf("expected nvidia to be the default runtime:\n%s", merged)
+	}
+
+	_, changed, err = mergeNvidiaRuntimeConfig(merged, "/usr/local/bin/nvidia-container-runtime", true, true)
+	if err != nil || changed {
+		t.Errorf("expected second merge to be a no-op, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestConfigureNvidiaRuntimeStep_Merge(t *testing.T) {
+	ctx, _ := newNvidiaTestContext(t, &runner.HostFacts{
+		GPUs: []runner.GPUDevice{{Vendor: runner.GPUVendorNVIDIA, Model: "NVIDIA Corporation GA100", PCIAddress: "3b:00.0"}},
+	})
+	s, err := NewConfigureNvidiaRuntimeStepBuilder(ctx, "ConfigureNvidiaRuntime").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+	merged, err := s.Merge(ctx, testContainerdConfig)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want, _, _ := mergeNvidiaRuntimeConfig([]byte(testContainerdConfig), s.BinaryName, s.SystemdCgroup, s.SetAsDefault)
+	if merged != string(want) {
+		t.Errorf("Merge() on a GPU node = %q, want %q", merged, want)
+	}
+
+	ctx, _ = newNvidiaTestContext(t, &runner.HostFacts{})
+	if merged, err := s.Merge(ctx, testContainerdConfig); err != nil || merged != testContainerdConfig {
+		t.Errorf("Merge() on a node without a GPU = %q, %v, want the config unchanged", merged, err)
+	}
+}
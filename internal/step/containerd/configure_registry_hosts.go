@@ -0,0 +1,360 @@
+package containerd
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	registryHostsFileName = "hosts.toml"
+	dockerHubRegistry     = "docker.io"
+	dockerHubRegistryURL  = "https://registry-1.docker.io"
+)
+
+var (
+	registryHostsPullScope = []string{"pull", "resolve"}
+	registryHostsFullScope = []string{"pull", "resolve", "push"}
+)
+
+// ConfigureRegistryHostsStep writes one hosts.toml per configured registry below the containerd
+// config_path directory and points the CRI plugin at that directory. Containerd is restarted only
+// if a file actually changed and the service is already running.
+type ConfigureRegistryHostsStep struct {
+	step.Base
+	TargetPath      string
+	CertsDir        string
+	RegistryMirrors map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig
+	RegistryConfigs map[v1alpha1.ServerAddress]v1alpha1.AuthConfig
+}
+
+type ConfigureRegistryHostsStepBuilder struct {
+	step.Builder[ConfigureRegistryHostsStepBuilder, *ConfigureRegistryHostsStep]
+}
+
+func NewConfigureRegistryHostsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigureRegistryHostsStepBuilder {
+	s := &ConfigureRegistryHostsStep{
+		TargetPath: common.ContainerdDefaultConfigFile,
+	}
+
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg != nil && clusterCfg.Spec != nil {
+		var containerdCfg *v1alpha1.Containerd
+		if clusterCfg.Spec.Kubernetes != nil && clusterCfg.Spec.Kubernetes.ContainerRuntime != nil {
+			containerdCfg = clusterCfg.Spec.Kubernetes.ContainerRuntime.Containerd
+		}
+		if containerdCfg != nil {
+			if containerdCfg.ConfigPath != nil && *containerdCfg.ConfigPath != "" {
+				s.TargetPath = *containerdCfg.ConfigPath
+			}
+		}
+		s.CertsDir = containerdCfg.RegistryHostsDir()
+		s.RegistryMirrors, s.RegistryConfigs = collectRegistryConfig(clusterCfg.Spec, containerdCfg)
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Configure containerd registry hosts.toml files", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(ConfigureRegistryHostsStepBuilder).Init(s)
+	return b
+}
+
+func (b *ConfigureRegistryHostsStepBuilder) WithCertsDir(dir string) *ConfigureRegistryHostsStepBuilder {
+	b.Step.CertsDir = dir
+	return b
+}
+
+func (b *ConfigureRegistryHostsStepBuilder) WithRegistryMirrors(mirrors map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig) *ConfigureRegistryHostsStepBuilder {
+	b.Step.RegistryMirrors = mirrors
+	return b
+}
+
+func (b *ConfigureRegistryHostsStepBuilder) WithRegistryConfigs(configs map[v1alpha1.ServerAddress]v1alpha1.AuthConfig) *ConfigureRegistryHostsStepBuilder {
+	b.Step.RegistryConfigs = configs
+	return b
+}
+
+func (s *ConfigureRegistryHostsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// hostsFiles returns the desired hosts.toml content keyed by remote path.
+func (s *ConfigureRegistryHostsStep) hostsFiles(ctx runtime.ExecutionContext) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, server := range registryServers(s.RegistryMirrors, s.RegistryConfigs) {
+		path := filepath.Join(s.CertsDir, string(server), registryHostsFileName)
+		content, err := ctx.GetRunner().RenderContainerdRegistryHosts(registryHostsOptions(server, s.RegistryMirrors[server], s.RegistryConfigs))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render hosts.toml for registry %s: %w", server, err)
+		}
+		files[path] = string(content)
+	}
+	return files, nil
+}
+
+// pendingChanges returns the hosts.toml files whose remote content differs and the merged main config,
+// which is nil if config_path is already set up.
+func (s *ConfigureRegistryHostsStep) pendingChanges(ctx runtime.ExecutionContext) (map[string]string, []byte, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, nil, err
+	}
+	runnerSvc := ctx.GetRunner()
+
+	files, err := s.hostsFiles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	changedFiles := make(map[string]string)
+	for path, content := range files {
+		current, err := runnerSvc.ReadFile(ctx.GoContext(), conn, path)
+		if err != nil || string(current) != content {
+			changedFiles[path] = content
+		}
+	}
+
+	current, err := runnerSvc.ReadFile(ctx.GoContext(), conn, s.TargetPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read containerd config '%s': %w", s.TargetPath, err)
+	}
+	merged, changed, err := mergeRegistryConfigPath(current, s.CertsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !changed {
+		merged = nil
+	}
+	return changedFiles, merged, nil
+}
+
+func (s *ConfigureRegistryHostsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.CertsDir == "" {
+		logger.Info("Registry config_path is not configured. Step is done.")
+		return true, nil
+	}
+
+	changedFiles, merged, err := s.pendingChanges(ctx)
+	if err != nil {
+		logger.Info("Registry hosts configuration could not be compared. Step needs to run.", "error", err)
+		return false, nil
+	}
+	if len(changedFiles) == 0 && merged == nil {
+		logger.Info("Registry hosts.toml files and config_path are up to date. Step is done.", "dir", s.CertsDir)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *ConfigureRegistryHostsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	if s.CertsDir == "" {
+		result.MarkSkipped("registry config_path is not configured")
+		return result, nil
+	}
+
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	changedFiles, merged, err := s.pendingChanges(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to compute registry hosts configuration")
+		return result, err
+	}
+	if len(changedFiles) == 0 && merged == nil {
+		result.MarkCompleted("registry hosts configuration already up to date")
+		return result, nil
+	}
+
+	paths := make([]string, 0, len(changedFiles))
+	for path := range changedFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		logger.Info("Writing registry hosts file.", "path", path)
+		if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, filepath.Dir(path), "0755", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to create registry hosts directory")
+			return result, fmt.Errorf("failed to create directory for '%s': %w", path, err)
+		}
+		if err := helpers.WriteContentToRemote(ctx, conn, changedFiles[path], path, "0644", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to write registry hosts file")
+			return result, err
+		}
+	}
+	if merged != nil {
+		logger.Info("Setting registry config_path in containerd config.", "path", s.TargetPath, "configPath", s.CertsDir)
+		if err := helpers.WriteContentToRemote(ctx, conn, string(merged), s.TargetPath, "0644", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to write containerd config")
+			return result, err
+		}
+	}
+
+	facts, err := runnerSvc.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		result.MarkFailed(err, "failed to gather facts")
+		return result, fmt.Errorf("failed to gather facts to restart containerd: %w", err)
+	}
+	active, err := runnerSvc.IsServiceActive(ctx.GoContext(), conn, facts, containerdServiceName)
+	if err != nil {
+		result.MarkFailed(err, "failed to determine containerd service status")
+		return result, fmt.Errorf("failed to determine containerd service status: %w", err)
+	}
+	if active {
+		logger.Info("Restarting containerd to load the registry hosts configuration.")
+		if err := runnerSvc.RestartService(ctx.GoContext(), conn, facts, containerdServiceName); err != nil {
+			result.MarkFailed(err, "failed to restart containerd service")
+			return result, fmt.Errorf("failed to restart containerd service: %w", err)
+		}
+	}
+
+	result.SetMetadata("configPath", s.CertsDir)
+	result.SetMetadata("changedFiles", paths)
+	result.MarkCompleted("registry hosts configured successfully")
+	return result, nil
+}
+
+func (s *ConfigureRegistryHostsStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Registry hosts configuration is rewritten by ConfigureContainerd on rollback, no specific action.")
+	return nil
+}
+
+// registryServers returns every registry that has a mirror or a config entry, sorted for stable output.
+func registryServers(mirrors map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig, configs map[v1alpha1.ServerAddress]v1alpha1.AuthConfig) []v1alpha1.ServerAddress {
+	seen := make(map[v1alpha1.ServerAddress]struct{})
+	var servers []v1alpha1.ServerAddress
+	for server := range mirrors {
+		seen[server] = struct{}{}
+		servers = append(servers, server)
+	}
+	for server := range configs {
+		if _, ok := seen[server]; !ok {
+			servers = append(servers, server)
+		}
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i] < servers[j] })
+	return servers
+}
+
+// registryHostsOptions builds the hosts.toml of a single registry. Mirror endpoints are tried in order
+// before the upstream server; TLS settings are taken from the config of the endpoint's own host,
+// falling back to the config of the registry being mirrored. Credentials are only ever sent to the
+// host they were configured for.
+func registryHostsOptions(server v1alpha1.ServerAddress, mirror v1alpha1.MirrorConfig, configs map[v1alpha1.ServerAddress]v1alpha1.AuthConfig) runner.ContainerdRegistryHostsOptions {
+	opts := runner.ContainerdRegistryHostsOptions{Server: registryServerURL(server)}
+
+	fallbackTLS := configs[server].TLS
+	if len(mirror.Endpoints) == 0 {
+		opts.Hosts = append(opts.Hosts, registryHost(registryServerURL(server), registryHostsFullScope, fallbackTLS, configs[server].Auth))
+		return opts
+	}
+	for _, endpoint := range mirror.Endpoints {
+		tls := fallbackTLS
+		var auth *v1alpha1.ContainerdRegistryAuth
+		if u, err := url.Parse(endpoint); err == nil {
+			if cfg, ok := configs[v1alpha1.ServerAddress(u.Host)]; ok {
+				if cfg.TLS != nil {
+					tls = cfg.TLS
+				}
+				auth = cfg.Auth
+			}
+		}
+		opts.Hosts = append(opts.Hosts, registryHost(endpoint, registryHostsPullScope, tls, auth))
+	}
+	return opts
+}
+
+func registryHost(hostURL string, capabilities []string, tls *v1alpha1.TLSConfig, auth *v1alpha1.ContainerdRegistryAuth) runner.ContainerdRegistryHost {
+	host := runner.ContainerdRegistryHost{URL: hostURL, Capabilities: capabilities}
+	if tls != nil {
+		host.SkipVerify = tls.InsecureSkipVerify
+		host.CAFile = tls.CAFile
+		if tls.CertFile != "" && tls.KeyFile != "" {
+			host.ClientCert, host.ClientKey = tls.CertFile, tls.KeyFile
+		}
+	}
+	if auth != nil {
+		host.Username, host.Password, host.Auth = auth.Username, auth.Password, auth.Auth
+	}
+	return host
+}
+
+func registryServerURL(server v1alpha1.ServerAddress) string {
+	if server == dockerHubRegistry {
+		return dockerHubRegistryURL
+	}
+	if strings.Contains(string(server), "://") {
+		return string(server)
+	}
+	return "https://" + string(server)
+}
+
+// mergeRegistryConfigPath sets the CRI registry config_path in a containerd version 2 config and drops the
+// inline mirrors and TLS settings, which containerd refuses to combine with config_path. Auth entries are kept.
+func mergeRegistryConfigPath(current []byte, certsDir string) ([]byte, bool, error) {
+	cfg := make(map[string]interface{})
+	if err := toml.Unmarshal(current, &cfg); err != nil {
+		return nil, false, fmt.Errorf("failed to parse containerd config: %w", err)
+	}
+
+	registry := ensureTomlTable(cfg, "plugins", common.ContainerdPluginCRI, "registry")
+	changed := false
+	if existing, ok := registry["config_path"]; !ok || existing != certsDir {
+		registry["config_path"] = certsDir
+		changed = true
+	}
+	if _, ok := registry["mirrors"]; ok {
+		delete(registry, "mirrors")
+		changed = true
+	}
+	if configs, ok := registry["configs"].(map[string]interface{}); ok {
+		for name, entry := range configs {
+			table, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := table["tls"]; ok {
+				delete(table, "tls")
+				changed = true
+			}
+			if len(table) == 0 {
+				delete(configs, name)
+			}
+		}
+		if len(configs) == 0 {
+			delete(registry, "configs")
+		}
+	}
+
+	if !changed {
+		return current, false, nil
+	}
+	out, err := toml.Marshal(cfg)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode containerd config: %w", err)
+	}
+	return out, true, nil
+}
+
+var _ step.Step = (*ConfigureRegistryHostsStep)(nil)
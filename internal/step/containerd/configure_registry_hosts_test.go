@@ -0,0 +1,166 @@
+package containerd
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestConfigureRegistryHostsStep(t *testing.T) {
+	tests := []struct {
+		name           string
+		mirrors        map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig
+		configs        map[v1alpha1.ServerAddress]v1alpha1.AuthConfig
+		expectedPath   string
+		expectedHosts  string
+		expectRestart  bool
+		expectedStatus types.StepStatus
+	}{
+		{
+			name: "mirror with skip TLS verify",
+			mirrors: map[v1alpha1.ServerAddress]v1alpha1.MirrorConfig{
+				"docker.io": {Endpoints: []string{"https://mirror.example.com"}},
+			},
+			configs: map[v1alpha1.ServerAddress]v1alpha1.AuthConfig{
+				"mirror.example.com": {TLS: &v1alpha1.TLSConfig{InsecureSkipVerify: true}},
+			},
+			expectedPath: "/etc/containerd/certs.d/docker.io/hosts.toml",
+			expectedHosts: `server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  skip_verify = true
+`,
+			expectRestart:  true,
+			expectedStatus: types.StepStatusCompleted,
+		},
+		{
+			name: "private registry with client certificates",
+			configs: map[v1alpha1.ServerAddress]v1alpha1.AuthConfig{
+				"registry.local:5000": {TLS: &v1alpha1.TLSConfig{CAFile: "/certs/ca.crt", CertFile: "/certs/tls.crt", KeyFile: "/certs/tls.key"}},
+			},
+			expectedPath: "/etc/containerd/certs.d/registry.local:5000/hosts.toml",
+			expectedHosts: `server = "https://registry.local:5000"
+
+[host."https://registry.local:5000"]
+  capabilities = ["pull", "resolve", "push"]
+  ca = "/certs/ca.crt"
+  client = [["/certs/tls.crt", "/certs/tls.key"]]
+`,
+			expectRestart:  true,
+			expectedStatus: types.StepStatusCompleted,
+		},
+		{
+			name: "private registry with basic auth",
+			configs: map[v1alpha1.ServerAddress]v1alpha1.AuthConfig{
+				"registry.local:5000": {Auth: &v1alpha1.ContainerdRegistryAuth{Username: "admin", Password: "secret"}},
+			},
+			expectedPath: "/etc/containerd/certs.d/registry.local:5000/hosts.toml",
+			expectedHosts: `server = "https://registry.local:5000"
+
+[host."https://registry.local:5000"]
+  capabilities = ["pull", "resolve", "push"]
+  [host."https://registry.local:5000".header]
+    Authorization = ["Basic YWRtaW46c2VjcmV0"]
+`,
+			expectRestart:  true,
+			expectedStatus: types.StepStatusCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, r := newNvidiaTestContext(t, nil)
+			s, err := NewConfigureRegistryHostsStepBuilder(ctx, "ConfigureRegistryHosts").
+				WithCertsDir("/etc/containerd/certs.d").
+				WithRegistryMirrors(tt.mirrors).
+				WithRegistryConfigs(tt.configs).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+
+			result, err := s.Run(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.expectedStatus {
+				t.Fatalf("expected status %q, got %q", tt.expectedStatus, result.Status)
+			}
+			if got := r.files[tt.expectedPath]; got != tt.expectedHosts {
+				t.Errorf("unexpected %s content:\n%s\nexpected:\n%s", tt.expectedPath, got, tt.expectedHosts)
+			}
+			if r.restarted != tt.expectRestart {
+				t.Errorf("expected restarted=%v, got %v", tt.expectRestart, r.restarted)
+			}
+
+			var cfg map[string]interface{}
+			if err := toml.Unmarshal([]byte(r.files["/etc/containerd/config.toml"]), &cfg); err != nil {
+				t.Fatalf("written config is not valid TOML: %v", err)
+			}
+			registry := cfg["plugins"].(map[string]interface{})["io.containerd.grpc.v1.cri"].(map[string]interface{})["registry"].(map[string]interface{})
+			if registry["config_path"] != "/etc/containerd/certs.d" {
+				t.Errorf("expected config_path to be set, got %v", registry["config_path"])
+			}
+
+			r.restarted = false
+			isDone, err := s.Precheck(ctx)
+			if err != nil || !isDone {
+				t.Errorf("expected precheck to report done after run, got done=%v err=%v", isDone, err)
+			}
+			result, err = s.Run(ctx)
+			if err != nil || result.Status != types.StepStatusCompleted || r.restarted {
+				t.Errorf("expected a second run to be a no-op without restart, got status=%q restarted=%v err=%v", result.Status, r.restarted, err)
+			}
+		})
+	}
+}
+
+func TestMergeRegistryConfigPath(t *testing.T) {
+	current := `version = 2
+
+[plugins]
+  [plugins."io.containerd.grpc.v1.cri"]
+    [plugins."io.containerd.grpc.v1.cri".registry]
+      [plugins."io.containerd.grpc.v1.cri".registry.mirrors]
+        [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+          endpoint = ["https://mirror.example.com"]
+      [plugins."io.containerd.grpc.v1.cri".registry.configs]
+        [plugins."io.containerd.grpc.v1.cri".registry.configs."registry.local".auth]
+          username = "admin"
+        [plugins."io.containerd.grpc.v1.cri".registry.configs."registry.local".tls]
+          insecure_skip_verify = true
+`
+	merged, changed, err := mergeRegistryConfigPath([]byte(current), "/etc/containerd/certs.d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected config to change")
+	}
+
+	var cfg map[string]interface{}
+	if err := toml.Unmarshal(merged, &cfg); err != nil {
+		t.Fatalf("merged config is not valid TOML: %v", err)
+	}
+	registry := cfg["plugins"].(map[string]interface{})["io.containerd.grpc.v1.cri"].(map[string]interface{})["registry"].(map[string]interface{})
+	if registry["config_path"] != "/etc/containerd/certs.d" {
+		t.Errorf("expected config_path to be set, got %v", registry["config_path"])
+	}
+	if _, ok := registry["mirrors"]; ok {
+		t.Error("expected inline mirrors to be removed")
+	}
+	entry := registry["configs"].(map[string]interface{})["registry.local"].(map[string]interface{})
+	if _, ok := entry["tls"]; ok {
+		t.Error("expected inline tls settings to be removed")
+	}
+	if _, ok := entry["auth"]; !ok {
+		t.Error("expected auth settings to be kept")
+	}
+
+	if _, changed, err := mergeRegistryConfigPath(merged, "/etc/containerd/certs.d"); err != nil || changed {
+		t.Errorf("expected second merge to be a no-op, got changed=%v err=%v", changed, err)
+	}
+}
@@ -2,10 +2,7 @@ package containerd
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/runtime"
@@ -14,7 +11,6 @@ import (
 	"github.com/mensylisir/kubexm/internal/step/helpers"
 	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
 	"github.com/mensylisir/kubexm/internal/types"
-	"github.com/schollz/progressbar/v3"
 )
 
 type DownloadCNIPluginsStep struct {
@@ -149,65 +145,12 @@ func (s *DownloadCNIPluginsStep) Run(ctx runtime.ExecutionContext) (*types.StepR
 func (s *DownloadCNIPluginsStep) downloadFile(ctx runtime.ExecutionContext, binaryInfo *binary.Binary) error {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
 
-	destDir := filepath.Dir(binaryInfo.FilePath())
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory '%s': %w", destDir, err)
+	logger.Info("Downloading CNI plugins.", "arch", binaryInfo.Arch, "version", binaryInfo.Version, "url")
+	if err := helpers.DownloadBinary(ctx.GoContext(), logger, binaryInfo, true); err != nil {
+		return err
 	}
-
-	req, err := http.NewRequestWithContext(ctx.GoContext(), "GET", binaryInfo.URL(), nil)
-	if err != nil {
-		return fmt.Errorf("failed to create http request: %w", err)
-	}
-
-	logger.Info("Downloading CNI plugins.", "arch", binaryInfo.Arch, "version", binaryInfo.Version, "url", binaryInfo.URL())
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status code %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(binaryInfo.FilePath())
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer out.Close()
-
-	bar := progressbar.NewOptions64(
-		resp.ContentLength,
-		progressbar.OptionSetDescription(fmt.Sprintf("Downloading %s", filepath.Base(binaryInfo.FilePath()))),
-		progressbar.OptionSetWriter(os.Stderr),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionSetWidth(40),
-		progressbar.OptionThrottle(65*time.Millisecond),
-		progressbar.OptionOnCompletion(func() { fmt.Fprint(os.Stderr, "\n") }),
-		progressbar.OptionSpinnerType(14),
-		progressbar.OptionFullWidth(),
-	)
-
-	_, err = io.Copy(io.MultiWriter(out, bar), resp.Body)
-	if err != nil {
-		_ = bar.Clear()
-		_ = out.Close()
-		_ = os.Remove(binaryInfo.FilePath())
-		return fmt.Errorf("failed to write to destination file: %w", err)
-	}
-	_ = bar.Finish()
-
 	logger.Info("Successfully downloaded.", "path", binaryInfo.FilePath())
 
-	match, err := helpers.VerifyLocalFileChecksum(binaryInfo.FilePath(), binaryInfo.Checksum())
-	if err != nil {
-		return fmt.Errorf("failed to verify checksum after download: %w", err)
-	}
-	if !match {
-		return fmt.Errorf("checksum mismatch after download for '%s'. Expected '%s'", binaryInfo.FilePath(), binaryInfo.Checksum())
-	}
-	logger.Info("Checksum verification successful.")
-
 	return nil
 }
 
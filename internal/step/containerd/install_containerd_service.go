@@ -85,6 +85,15 @@ func (s *InstallContainerdServiceStep) renderContent() (string, error) {
 	return buf.String(), nil
 }
 
+// RenderFiles returns the containerd unit file at TargetPath.
+func (s *InstallContainerdServiceStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.renderContent()
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: s.TargetPath, Content: []byte(content)}}, nil
+}
+
 func (s *InstallContainerdServiceStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	runner := ctx.GetRunner()
@@ -186,3 +195,4 @@ func (s *InstallContainerdServiceStep) Rollback(ctx runtime.ExecutionContext) er
 }
 
 var _ step.Step = (*InstallContainerdServiceStep)(nil)
+var _ step.Renderer = (*InstallContainerdServiceStep)(nil)
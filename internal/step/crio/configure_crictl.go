@@ -57,7 +57,7 @@ func NewConfigureCrictlStepBuilder(ctx runtime.ExecutionContext, instanceName st
 
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Configure crictl CLI", s.Base.Meta.Name)
-	s.Base.Sudo = false
+	s.Base.Sudo = true
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 1 * time.Minute
 
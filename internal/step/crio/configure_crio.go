@@ -8,7 +8,9 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -16,6 +18,7 @@ import (
 	"github.com/mensylisir/kubexm/internal/util/images"
 	"github.com/mensylisir/kubexm/internal/templates"
 	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
 )
 
 const (
@@ -33,6 +36,7 @@ type templateData struct {
 type ConfigureCrioStep struct {
 	step.Base
 	Data       templateData
+	Options    runner.CRIOConfigOptions
 	TargetPath string
 }
 
@@ -58,8 +62,11 @@ func NewConfigureCrioStepBuilder(ctx runtime.ExecutionContext, instanceName stri
 	}
 	data.PauseImage = pauseImage.FullName()
 
+	var opts runner.CRIOConfigOptions
+	var extraErr error
 	if cfg.Kubernetes.ContainerRuntime != nil && cfg.Kubernetes.ContainerRuntime.Crio != nil {
 		userCfg := cfg.Kubernetes.ContainerRuntime.Crio
+		opts, extraErr = crioConfigOptions(userCfg)
 		if userCfg.Pause != "" {
 			data.PauseImage = userCfg.Pause
 		}
@@ -78,6 +85,7 @@ func NewConfigureCrioStepBuilder(ctx runtime.ExecutionContext, instanceName stri
 
 	s := &ConfigureCrioStep{
 		Data:       data,
+		Options:    opts,
 		TargetPath: filepath.Join(common.CRIODefaultConfDir, "crio.conf.d", "00-kubexm-crio.conf"),
 	}
 
@@ -88,14 +96,53 @@ func NewConfigureCrioStepBuilder(ctx runtime.ExecutionContext, instanceName stri
 	s.Base.Timeout = 1 * time.Minute
 
 	b := new(ConfigureCrioStepBuilder).Init(s)
+	if extraErr != nil {
+		b.Error = extraErr
+	}
 	return b
 }
 
+// crioConfigOptions maps the user's CRI-O settings onto the runner options that are merged over
+// the rendered template. The pause image, cgroup manager and runtime paths are already part of
+// the template data.
+func crioConfigOptions(userCfg *v1alpha1.Crio) (runner.CRIOConfigOptions, error) {
+	opts := runner.CRIOConfigOptions{
+		Root:           userCfg.Root,
+		RunRoot:        userCfg.Runroot,
+		StorageDriver:  userCfg.StorageDriver,
+		StorageOptions: userCfg.StorageOption,
+		LogLevel:       userCfg.LogLevel,
+		Conmon:         userCfg.Conmon,
+		NetworkDir:     userCfg.NetworkDir,
+		PluginDirs:     userCfg.PluginDirs,
+	}
+	if userCfg.LogFilter != "" {
+		opts.LogFilter = &userCfg.LogFilter
+	}
+	if len(userCfg.Runtimes) > 0 {
+		opts.Runtimes = make(map[string]runner.CRIORuntimeOptions, len(userCfg.Runtimes))
+		for name, rt := range userCfg.Runtimes {
+			opts.Runtimes[name] = runner.CRIORuntimeOptions{
+				RuntimePath: rt.RuntimePath,
+				RuntimeType: rt.RuntimeType,
+				RuntimeRoot: rt.RuntimeRoot,
+			}
+		}
+	}
+	if strings.TrimSpace(userCfg.ExtraTomlConfig) != "" {
+		if err := toml.Unmarshal([]byte(userCfg.ExtraTomlConfig), &opts.Extra); err != nil {
+			return opts, fmt.Errorf("failed to parse crio extraTomlConfig: %w", err)
+		}
+	}
+	return opts, nil
+}
+
 func (s *ConfigureCrioStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
-func (s *ConfigureCrioStep) renderContent() (string, error) {
+// renderContent renders the drop-in template and merges the user's settings over it.
+func (s *ConfigureCrioStep) renderContent(ctx runtime.ExecutionContext) (string, error) {
 	tmplStr, err := templates.Get(crioConfigTemplatePath)
 	if err != nil {
 		return "", err
@@ -109,7 +156,11 @@ func (s *ConfigureCrioStep) renderContent() (string, error) {
 	if err := tmpl.Execute(&buf, s.Data); err != nil {
 		return "", fmt.Errorf("failed to render crio config template: %w", err)
 	}
-	return buf.String(), nil
+	content, err := ctx.GetRunner().RenderCRIOConfig(s.Options, buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
 }
 
 func (s *ConfigureCrioStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
@@ -120,7 +171,7 @@ func (s *ConfigureCrioStep) Precheck(ctx runtime.ExecutionContext) (isDone bool,
 		return false, err
 	}
 
-	expectedContent, err := s.renderContent()
+	expectedContent, err := s.renderContent(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to render expected content for precheck: %w", err)
 	}
@@ -164,7 +215,7 @@ func (s *ConfigureCrioStep) Run(ctx runtime.ExecutionContext) (*types.StepResult
 		return result, fmt.Errorf("failed to create CRI-O config directory '%s': %w", targetDir, err)
 	}
 
-	content, err := s.renderContent()
+	content, err := s.renderContent(ctx)
 	if err != nil {
 		result.MarkFailed(err, "failed to render content")
 		return result, err
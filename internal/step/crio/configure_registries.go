@@ -6,29 +6,23 @@ import (
 	"bytes"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
-	"text/template"
 	"time"
 
-	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
 	"github.com/mensylisir/kubexm/internal/step/helpers"
-	"github.com/mensylisir/kubexm/internal/templates"
 	"github.com/mensylisir/kubexm/internal/types"
 )
 
-type registriesTemplateData struct {
-	UnqualifiedSearchRegistries []string
-	InsecureRegistries          []string
-	Registries                  []v1alpha1.RegistryMirror
-}
-
 type ConfigureRegistriesStep struct {
 	step.Base
-	Data       registriesTemplateData
+	Options    runner.CRIORegistriesOptions
 	TargetPath string
 }
 
@@ -44,48 +38,50 @@ func NewConfigureRegistriesStepBuilder(ctx runtime.ExecutionContext, instanceNam
 	}
 	userCrioCfg := cfg.Kubernetes.ContainerRuntime.Crio
 
-	data := registriesTemplateData{}
+	opts := runner.CRIORegistriesOptions{}
 
 	searchRegistries := common.DefaultUnqualifiedSearchRegistries
 	if cfg.Registry != nil && cfg.Registry.MirroringAndRewriting != nil && cfg.Registry.MirroringAndRewriting.PrivateRegistry != "" {
 		privateRegistryHost := cfg.Registry.MirroringAndRewriting.PrivateRegistry
 		searchRegistries = prependIfNotExist(searchRegistries, privateRegistryHost)
+		if _, hasAuth := cfg.Registry.Auths[privateRegistryHost]; !hasAuth && cfg.Registry.IsPlainHTTP(privateRegistryHost) {
+			opts.Registries = append(opts.Registries, runner.CRIORegistry{Location: privateRegistryHost, Insecure: true})
+		}
 	}
 	if userCrioCfg.Registry != nil && len(userCrioCfg.Registry.UnqualifiedSearchRegistries) > 0 {
 		searchRegistries = append(searchRegistries, userCrioCfg.Registry.UnqualifiedSearchRegistries...)
 	}
-	data.UnqualifiedSearchRegistries = searchRegistries
+	opts.UnqualifiedSearchRegistries = helpers.RemoveDuplicates(searchRegistries)
 
-	insecureRegistries := []string{}
 	if cfg.Registry != nil && len(cfg.Registry.Auths) > 0 {
-		for server, auth := range cfg.Registry.Auths {
+		servers := make([]string, 0, len(cfg.Registry.Auths))
+		for server := range cfg.Registry.Auths {
+			servers = append(servers, server)
+		}
+		sort.Strings(servers)
+		for _, server := range servers {
+			auth := cfg.Registry.Auths[server]
 			isPlainHTTP := auth.PlainHTTP != nil && *auth.PlainHTTP
 			isSkipTLSVerify := auth.SkipTLSVerify != nil && *auth.SkipTLSVerify
 			if isPlainHTTP || isSkipTLSVerify {
-				insecureRegistries = append(insecureRegistries, server)
+				opts.Registries = append(opts.Registries, runner.CRIORegistry{Location: server, Insecure: true})
 			}
 		}
 	}
 
-	mirrors := make([]v1alpha1.RegistryMirror, 0)
-
-	if userCrioCfg.Registry != nil && len(userCrioCfg.Registry.Registries) > 0 {
+	if userCrioCfg.Registry != nil {
 		for _, registry := range userCrioCfg.Registry.Registries {
-			if registry.Prefix == "" {
-				if registry.Insecure != nil && *registry.Insecure {
-					insecureRegistries = append(insecureRegistries, registry.Location)
-				}
-			} else {
-				mirrors = append(mirrors, registry)
-			}
+			opts.Registries = append(opts.Registries, runner.CRIORegistry{
+				Prefix:   registry.Prefix,
+				Location: registry.Location,
+				Insecure: registry.Insecure != nil && *registry.Insecure,
+				Blocked:  registry.Blocked != nil && *registry.Blocked,
+			})
 		}
 	}
 
-	data.InsecureRegistries = helpers.RemoveDuplicates(insecureRegistries)
-	data.Registries = mirrors
-
 	s := &ConfigureRegistriesStep{
-		Data:       data,
+		Options:    opts,
 		TargetPath: common.RegistriesDefaultConfigFile,
 	}
 
@@ -112,57 +108,44 @@ func (s *ConfigureRegistriesStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
-func (s *ConfigureRegistriesStep) renderContent() (string, error) {
-	tmplStr, err := templates.Get("crio/registries.conf.tmpl")
+// renderContent merges the registries into the current file, so entries the distribution or an
+// administrator added for other prefixes are kept.
+func (s *ConfigureRegistriesStep) renderContent(ctx runtime.ExecutionContext, conn connector.Connector) (current []byte, rendered []byte, err error) {
+	runner := ctx.GetRunner()
+	exists, err := runner.Exists(ctx.GoContext(), conn, s.TargetPath)
 	if err != nil {
-		return "", err
+		return nil, nil, fmt.Errorf("failed to check for config file '%s': %w", s.TargetPath, err)
 	}
-	tmpl, err := template.New("registries.conf").Parse(tmplStr)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse registries config template: %w", err)
+	if exists {
+		current, err = runner.ReadFile(ctx.GoContext(), conn, s.TargetPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file '%s': %w", s.TargetPath, err)
+		}
 	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, s.Data); err != nil {
-		return "", fmt.Errorf("failed to render registries config template: %w", err)
+	rendered, err = runner.RenderCRIORegistriesConfig(s.Options, current)
+	if err != nil {
+		return nil, nil, err
 	}
-	return buf.String(), nil
+	return current, rendered, nil
 }
 
 func (s *ConfigureRegistriesStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		return false, err
 	}
 
-	expectedContent, err := s.renderContent()
+	current, expected, err := s.renderContent(ctx, conn)
 	if err != nil {
 		return false, fmt.Errorf("failed to render expected content for precheck: %w", err)
 	}
-
-	exists, err := runner.Exists(ctx.GoContext(), conn, s.TargetPath)
-	if err != nil {
-		return false, fmt.Errorf("failed to check for config file '%s': %w", s.TargetPath, err)
-	}
-
-	if exists {
-		remoteContent, err := runner.ReadFile(ctx.GoContext(), conn, s.TargetPath)
-		if err != nil {
-			logger.Warn(err, "Config file exists but failed to read, will overwrite.", "path", s.TargetPath)
-			return false, nil
-		}
-
-		if string(remoteContent) == expectedContent {
-			logger.Info("Registries config file already exists and content matches. Step is done.", "path", s.TargetPath)
-			return true, nil
-		}
-
-		logger.Info("Registries config file exists but content differs. Step needs to run.", "path", s.TargetPath)
-		return false, nil
+	if current != nil && bytes.Equal(current, expected) {
+		logger.Info("Registries config file already contains the configured registries. Step is done.", "path", s.TargetPath)
+		return true, nil
 	}
 
-	logger.Info("Registries config file does not exist. Configuration is required.", "path", s.TargetPath)
+	logger.Info("Registries config file is missing or differs. Configuration is required.", "path", s.TargetPath)
 	return false, nil
 }
 
@@ -182,14 +165,14 @@ func (s *ConfigureRegistriesStep) Run(ctx runtime.ExecutionContext) (*types.Step
 		return result, fmt.Errorf("failed to create registries config directory '%s': %w", targetDir, err)
 	}
 
-	content, err := s.renderContent()
+	_, content, err := s.renderContent(ctx, conn)
 	if err != nil {
 		result.MarkFailed(err, "failed to render content")
 		return result, err
 	}
 
 	logger.Info("Writing registries config file.", "path", s.TargetPath)
-	if err := helpers.WriteContentToRemote(ctx, conn, content, s.TargetPath, "0644", s.Sudo); err != nil {
+	if err := helpers.WriteContentToRemote(ctx, conn, string(content), s.TargetPath, "0644", s.Sudo); err != nil {
 		result.MarkFailed(err, "failed to write registries config")
 		return result, err
 	}
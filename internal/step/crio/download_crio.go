@@ -2,10 +2,7 @@ package crio
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/runtime"
@@ -14,7 +11,6 @@ import (
 	"github.com/mensylisir/kubexm/internal/step/helpers"
 	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
 	"github.com/mensylisir/kubexm/internal/types"
-	"github.com/schollz/progressbar/v3"
 )
 
 const ComponentCrio = "crio"
@@ -177,66 +173,12 @@ func (s *DownloadCrioStep) Run(ctx runtime.ExecutionContext) (*types.StepResult,
 func (s *DownloadCrioStep) downloadFile(ctx runtime.ExecutionContext, binaryInfo *binary.Binary) error {
 	logger := ctx.GetLogger()
 
-	destDir := filepath.Dir(binaryInfo.FilePath())
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory '%s': %w", destDir, err)
+	logger.Infof("Downloading CRI-O (arch: %s, version: %s) ...", binaryInfo.Arch, binaryInfo.Version)
+	if err := helpers.DownloadBinary(ctx.GoContext(), logger, binaryInfo, true); err != nil {
+		return err
 	}
-
-	req, err := http.NewRequestWithContext(ctx.GoContext(), "GET", binaryInfo.URL(), nil)
-	if err != nil {
-		return fmt.Errorf("failed to create http request: %w", err)
-	}
-
-	logger.Infof("Downloading CRI-O (arch: %s, version: %s) from %s ...", binaryInfo.Arch, binaryInfo.Version, binaryInfo.URL())
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status code %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(binaryInfo.FilePath())
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer out.Close()
-
-	bar := progressbar.NewOptions64(
-		resp.ContentLength,
-		progressbar.OptionSetDescription(fmt.Sprintf("Downloading %s", filepath.Base(binaryInfo.FilePath()))),
-		progressbar.OptionSetWriter(os.Stderr),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionSetWidth(40),
-		progressbar.OptionThrottle(65*time.Millisecond),
-		progressbar.OptionOnCompletion(func() { fmt.Fprint(os.Stderr, "\n") }),
-		progressbar.OptionSpinnerType(14),
-		progressbar.OptionFullWidth(),
-	)
-
-	_, err = io.Copy(io.MultiWriter(out, bar), resp.Body)
-	if err != nil {
-		_ = bar.Clear()
-		_ = out.Close()
-		_ = os.Remove(binaryInfo.FilePath())
-		return fmt.Errorf("failed to write to destination file: %w", err)
-	}
-	_ = bar.Finish()
-
 	logger.Infof("Successfully downloaded to %s", binaryInfo.FilePath())
 
-	match, err := helpers.VerifyLocalFileChecksum(binaryInfo.FilePath(), binaryInfo.Checksum())
-	if err != nil {
-		return fmt.Errorf("failed to verify checksum after download: %w", err)
-	}
-	if !match {
-		_ = os.Remove(binaryInfo.FilePath())
-		return fmt.Errorf("checksum mismatch after download for '%s'. Expected '%s'", binaryInfo.FilePath(), binaryInfo.Checksum())
-	}
-	logger.Info("Checksum verification successful.")
-
 	return nil
 }
 
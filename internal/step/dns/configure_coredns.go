@@ -0,0 +1,371 @@
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/templates"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+// ConfigureCoreDNSStep customizes the CoreDNS installed by kubeadm with spec.dns.coredns: the
+// coredns ConfigMap gets a Corefile rendered from the upstream forwarders, stub domains, rewrite
+// rules and extra hosts, and the Deployment is given a fixed number of replicas or scaled by
+// cluster-proportional-autoscaler. CoreDNS is then restarted, and if it does not roll out the
+// previous Corefile and replicas are put back.
+type ConfigureCoreDNSStep struct {
+	step.Base
+	Corefile            string
+	Replicas            *int
+	Autoscaler          *v1alpha1.CoreDNSAutoscaler
+	AutoscalerImage     string
+	RemoteDir           string
+	AdminKubeconfigPath string
+
+	applied            bool
+	originalCorefile   string
+	originalReplicas   string
+	autoscalerExisted  bool
+	autoscalerManifest string
+}
+
+type ConfigureCoreDNSStepBuilder struct {
+	step.Builder[ConfigureCoreDNSStepBuilder, *ConfigureCoreDNSStep]
+}
+
+func NewConfigureCoreDNSStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigureCoreDNSStepBuilder {
+	s := &ConfigureCoreDNSStep{
+		RemoteDir:           filepath.Join(ctx.GetUploadDir(), ctx.GetHost().GetName(), "coredns"),
+		AdminKubeconfigPath: filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Customize the CoreDNS configuration and replicas", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	clusterCfg := ctx.GetClusterConfig()
+	coreDNS := &v1alpha1.CoreDNS{}
+	dnsEtcHosts := ""
+	if clusterCfg.Spec.DNS != nil {
+		dnsEtcHosts = clusterCfg.Spec.DNS.DNSEtcHosts
+		if clusterCfg.Spec.DNS.CoreDNS != nil {
+			coreDNS = clusterCfg.Spec.DNS.CoreDNS
+		}
+	}
+	clusterDomain := common.DefaultClusterLocal
+	if clusterCfg.Spec.Kubernetes != nil && clusterCfg.Spec.Kubernetes.DNSDomain != "" {
+		clusterDomain = clusterCfg.Spec.Kubernetes.DNSDomain
+	}
+	corefile, err := renderCorefile(clusterDomain, dnsEtcHosts, coreDNS)
+	if err != nil {
+		ctx.GetLogger().Errorf("Failed to render the Corefile: %v", err)
+	}
+	s.Corefile = corefile
+	s.Replicas = coreDNS.Replicas
+	if coreDNS.Autoscaler != nil && coreDNS.Autoscaler.Enabled != nil && *coreDNS.Autoscaler.Enabled {
+		s.Autoscaler = coreDNS.Autoscaler
+		s.AutoscalerImage = images.NewImageProvider(ctx).GetImage("cluster-proportional-autoscaler").FullName()
+	}
+
+	b := new(ConfigureCoreDNSStepBuilder).Init(s)
+	return b
+}
+
+func (s *ConfigureCoreDNSStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+type corefileData struct {
+	ClusterDomain string
+	Hosts         []string
+	Upstreams     []string
+	CoreDNS       *v1alpha1.CoreDNS
+}
+
+// renderCorefile returns the Corefile of CoreDNS for cfg. The entries of dnsEtcHosts, in the
+// format of /etc/hosts, are served inline by the hosts plugin.
+func renderCorefile(clusterDomain, dnsEtcHosts string, cfg *v1alpha1.CoreDNS) (string, error) {
+	data := corefileData{ClusterDomain: clusterDomain, CoreDNS: cfg}
+	for _, line := range strings.Split(dnsEtcHosts, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			data.Hosts = append(data.Hosts, line)
+		}
+	}
+	if upstream := cfg.UpstreamForwarding; upstream != nil {
+		if len(upstream.StaticServers) > 0 {
+			data.Upstreams = upstream.StaticServers
+		} else if upstream.UseNodeResolvConf == nil || *upstream.UseNodeResolvConf {
+			data.Upstreams = []string{"/etc/resolv.conf"}
+		}
+	} else {
+		data.Upstreams = []string{"/etc/resolv.conf"}
+	}
+
+	content, err := templates.Get("dns/Corefile.tmpl")
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New("Corefile").Funcs(template.FuncMap{
+		"join":   strings.Join,
+		"indent": indentLines,
+	}).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Corefile.tmpl: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render Corefile.tmpl: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func indentLines(n int, s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = strings.Repeat(" ", n) + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCorefileConfigMap returns the coredns ConfigMap holding corefile.
+func renderCorefileConfigMap(corefile string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + common.CoreDNSConfigMapName +
+		"\n  namespace: kube-system\ndata:\n  Corefile: |\n" + indentLines(4, corefile) + "\n"
+}
+
+func (s *ConfigureCoreDNSStep) renderAutoscaler() (string, error) {
+	content, err := templates.Get("dns/coredns-autoscaler.yaml.tmpl")
+	if err != nil {
+		return "", err
+	}
+	return templates.Render(content, map[string]interface{}{
+		"Name":            common.CoreDNSAutoscalerName,
+		"ConfigMapName":   common.CoreDNSAutoscalerConfigMapName,
+		"Image":           s.AutoscalerImage,
+		"MinReplicas":     s.Autoscaler.MinReplicas,
+		"MaxReplicas":     s.Autoscaler.MaxReplicas,
+		"CoresPerReplica": s.Autoscaler.CoresPerReplica,
+		"NodesPerReplica": s.Autoscaler.NodesPerReplica,
+	})
+}
+
+func (s *ConfigureCoreDNSStep) kubectl(args string) string {
+	return fmt.Sprintf("kubectl --kubeconfig %s --namespace kube-system %s", s.AdminKubeconfigPath, args)
+}
+
+// current returns the Corefile and replicas of the running CoreDNS and whether the autoscaler is deployed.
+func (s *ConfigureCoreDNSStep) current(ctx runtime.ExecutionContext) (string, string, bool, error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", "", false, err
+	}
+	corefile, err := runner.Run(ctx.GoContext(), conn, s.kubectl("get configmap "+common.CoreDNSConfigMapName+" -o jsonpath='{.data.Corefile}'"), s.Sudo)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get the CoreDNS ConfigMap: %w", err)
+	}
+	replicas, err := runner.Run(ctx.GoContext(), conn, s.kubectl("get deployment "+common.CoreDNSDeploymentName+" -o jsonpath='{.spec.replicas}'"), s.Sudo)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get the CoreDNS Deployment: %w", err)
+	}
+	autoscaler, _ := runner.Check(ctx.GoContext(), conn, s.kubectl("get deployment "+common.CoreDNSAutoscalerName), s.Sudo)
+	return corefile.Stdout, strings.TrimSpace(replicas.Stdout), autoscaler, nil
+}
+
+func (s *ConfigureCoreDNSStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.Corefile == "" {
+		return false, fmt.Errorf("no Corefile was rendered for step %s", s.Base.Meta.Name)
+	}
+	corefile, replicas, autoscaler, err := s.current(ctx)
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(corefile) != strings.TrimSpace(s.Corefile) || autoscaler != (s.Autoscaler != nil) {
+		return false, nil
+	}
+	if s.Autoscaler == nil && s.Replicas != nil && replicas != strconv.Itoa(*s.Replicas) {
+		return false, nil
+	}
+	logger.Info("CoreDNS is already customized.")
+	return true, nil
+}
+
+func (s *ConfigureCoreDNSStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	s.originalCorefile, s.originalReplicas, s.autoscalerExisted, err = s.current(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to read the current CoreDNS configuration")
+		return result, err
+	}
+	if err := runner.Mkdirp(ctx.GoContext(), conn, s.RemoteDir, "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to create remote dir")
+		return result, err
+	}
+
+	s.applied = true
+	if err := s.apply(ctx); err != nil {
+		logger.Errorf("Failed to customize CoreDNS, restoring its previous configuration: %v", err)
+		if restoreErr := s.restore(ctx); restoreErr != nil {
+			logger.Errorf("Failed to restore the previous CoreDNS configuration: %v", restoreErr)
+		}
+		result.MarkFailed(err, "failed to customize CoreDNS")
+		return result, err
+	}
+
+	logger.Info("CoreDNS customized and rolled out.")
+	result.MarkCompleted("CoreDNS customized")
+	return result, nil
+}
+
+// apply writes the ConfigMap, scales CoreDNS and waits for it to roll out with the new Corefile.
+func (s *ConfigureCoreDNSStep) apply(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+
+	if err := s.applyCorefile(ctx, s.Corefile); err != nil {
+		return err
+	}
+
+	s.autoscalerManifest = filepath.Join(s.RemoteDir, "coredns-autoscaler.yaml")
+	switch {
+	case s.Autoscaler != nil:
+		manifest, err := s.renderAutoscaler()
+		if err != nil {
+			return fmt.Errorf("failed to render the CoreDNS autoscaler: %w", err)
+		}
+		if err := helpers.WriteContentToRemote(ctx, conn, manifest, s.autoscalerManifest, "0644", s.Sudo); err != nil {
+			return fmt.Errorf("failed to upload the CoreDNS autoscaler manifest: %w", err)
+		}
+		logger.Infof("Deploying %s.", common.CoreDNSAutoscalerName)
+		if _, err := runner.Run(ctx.GoContext(), conn, s.kubectl("apply -f "+s.autoscalerManifest), s.Sudo); err != nil {
+			return fmt.Errorf("failed to deploy the CoreDNS autoscaler: %w", err)
+		}
+	case s.autoscalerExisted:
+		// The replicas are fixed again, so the autoscaler must not scale them any more.
+		logger.Infof("Removing %s.", common.CoreDNSAutoscalerName)
+		if _, err := runner.Run(ctx.GoContext(), conn, s.kubectl("delete deployment "+common.CoreDNSAutoscalerName+" --ignore-not-found=true"), s.Sudo); err != nil {
+			return fmt.Errorf("failed to remove the CoreDNS autoscaler: %w", err)
+		}
+		fallthrough
+	default:
+		if s.Replicas != nil {
+			if err := s.scale(ctx, strconv.Itoa(*s.Replicas)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.restart(ctx)
+}
+
+func (s *ConfigureCoreDNSStep) applyCorefile(ctx runtime.ExecutionContext, corefile string) error {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	manifest := filepath.Join(s.RemoteDir, "coredns-configmap.yaml")
+	if err := helpers.WriteContentToRemote(ctx, conn, renderCorefileConfigMap(corefile), manifest, "0644", s.Sudo); err != nil {
+		return fmt.Errorf("failed to upload the CoreDNS ConfigMap: %w", err)
+	}
+	if _, err := runner.Run(ctx.GoContext(), conn, s.kubectl("apply -f "+manifest), s.Sudo); err != nil {
+		return fmt.Errorf("failed to apply the CoreDNS ConfigMap: %w", err)
+	}
+	return nil
+}
+
+func (s *ConfigureCoreDNSStep) scale(ctx runtime.ExecutionContext, replicas string) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	cmd := s.kubectl(fmt.Sprintf("scale deployment %s --replicas=%s", common.CoreDNSDeploymentName, replicas))
+	if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		return fmt.Errorf("failed to scale CoreDNS to %s replicas: %w", replicas, err)
+	}
+	return nil
+}
+
+// restart restarts CoreDNS so that the Corefile is loaded at once rather than by the reload
+// plugin, and waits for the new pods to be ready, which fails when the Corefile is invalid.
+func (s *ConfigureCoreDNSStep) restart(ctx runtime.ExecutionContext) error {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	deployment := "deployment/" + common.CoreDNSDeploymentName
+	if _, err := runner.Run(ctx.GoContext(), conn, s.kubectl("rollout restart "+deployment), s.Sudo); err != nil {
+		return fmt.Errorf("failed to restart CoreDNS: %w", err)
+	}
+	if _, err := runner.Run(ctx.GoContext(), conn, s.kubectl(fmt.Sprintf("rollout status %s --timeout=5m", deployment)), s.Sudo); err != nil {
+		return fmt.Errorf("CoreDNS did not roll out with the new configuration: %w", err)
+	}
+	return nil
+}
+
+// restore puts back the Corefile and replicas CoreDNS had before Run and removes the autoscaler
+// Run deployed. An autoscaler Run removed is not deployed again.
+func (s *ConfigureCoreDNSStep) restore(ctx runtime.ExecutionContext) error {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	if err := s.applyCorefile(ctx, s.originalCorefile); err != nil {
+		return err
+	}
+	if s.Autoscaler != nil && !s.autoscalerExisted {
+		if _, err := runner.Run(ctx.GoContext(), conn, s.kubectl("delete -f "+s.autoscalerManifest+" --ignore-not-found=true"), s.Sudo); err != nil {
+			return fmt.Errorf("failed to remove the CoreDNS autoscaler: %w", err)
+		}
+	}
+	if s.originalReplicas != "" {
+		if err := s.scale(ctx, s.originalReplicas); err != nil {
+			return err
+		}
+	}
+	return s.restart(ctx)
+}
+
+func (s *ConfigureCoreDNSStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	if !s.applied {
+		return nil
+	}
+	logger.Warn("Restoring the previous CoreDNS configuration.")
+	if err := s.restore(ctx); err != nil {
+		logger.Errorf("Failed to restore the previous CoreDNS configuration: %v", err)
+	}
+	return nil
+}
+
+var _ step.Step = (*ConfigureCoreDNSStep)(nil)
@@ -0,0 +1,73 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
+)
+
+func TestRenderCorefile_Default(t *testing.T) {
+	cfg := &v1alpha1.CoreDNS{}
+	v1alpha1.SetDefaults_CoreDNS(cfg)
+
+	corefile, err := renderCorefile("cluster.local", "", cfg)
+	if err != nil {
+		t.Fatalf("renderCorefile() error = %v", err)
+	}
+	for _, want := range []string{
+		"kubernetes cluster.local in-addr.arpa ip6.arpa {",
+		"    forward . /etc/resolv.conf {\n        policy random\n        max_concurrent 1000\n    }",
+		"    cache 30\n    loop\n    reload\n    loadbalance\n}",
+	} {
+		if !strings.Contains(corefile, want) {
+			t.Errorf("Corefile does not contain %q:\n%s", want, corefile)
+		}
+	}
+	if strings.Contains(corefile, "hosts {") || strings.Contains(corefile, "rewrite") {
+		t.Errorf("default Corefile has hosts or rewrite entries:\n%s", corefile)
+	}
+}
+
+func TestRenderCorefile_Customized(t *testing.T) {
+	cfg := &v1alpha1.CoreDNS{
+		UpstreamForwarding: &v1alpha1.UpstreamForwardingConfig{
+			StaticServers:     []string{"10.0.0.2", "10.0.0.3"},
+			UseNodeResolvConf: helpers.BoolPtr(false),
+			Policy:            "sequential",
+		},
+		ExternalZones: []v1alpha1.ExternalZone{{
+			Zones:       []string{"corp.example.com", "lab.example.com"},
+			Nameservers: []string{"192.168.10.53"},
+			Cache:       60,
+		}},
+		Rewrite: []v1alpha1.RewriteRule{{FromPattern: "api.example.com", ToTemplate: "api.default.svc.cluster.local"}},
+	}
+	corefile, err := renderCorefile("cluster.local", "192.168.1.250 gitlab.example.com\n# comment\n\n", cfg)
+	if err != nil {
+		t.Fatalf("renderCorefile() error = %v", err)
+	}
+	for _, want := range []string{
+		"    hosts {\n        192.168.1.250 gitlab.example.com\n        fallthrough\n    }",
+		"    rewrite name api.example.com api.default.svc.cluster.local",
+		"    forward . 10.0.0.2 10.0.0.3 {\n        policy sequential\n    }",
+		"}\ncorp.example.com lab.example.com {\n    errors\n    cache 60\n    forward . 192.168.10.53\n}",
+	} {
+		if !strings.Contains(corefile, want) {
+			t.Errorf("Corefile does not contain %q:\n%s", want, corefile)
+		}
+	}
+	if strings.Contains(corefile, "# comment") {
+		t.Errorf("Corefile contains a comment of dnsEtcHosts:\n%s", corefile)
+	}
+}
+
+func TestRenderCorefileConfigMap(t *testing.T) {
+	got := renderCorefileConfigMap(".:53 {\n    errors\n}\n")
+	want := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: coredns\n  namespace: kube-system\n" +
+		"data:\n  Corefile: |\n    .:53 {\n        errors\n    }\n"
+	if got != want {
+		t.Errorf("renderCorefileConfigMap() = %q, want %q", got, want)
+	}
+}
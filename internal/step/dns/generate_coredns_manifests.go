@@ -125,26 +125,12 @@ func (s *GenerateCoreDNSArtifactsStep) Run(ctx runtime.ExecutionContext) (*types
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
 
 	logger.Info("Rendering Corefile from template...")
-	corefileTemplateContent, err := templates.Get("dns/Corefile.tmpl")
+	corefile, err := renderCorefile(s.ClusterDomain, s.DNSEtcHosts, s.CoreDNSConfig)
 	if err != nil {
-		result.MarkFailed(err, "failed to get embedded Corefile.tmpl")
+		result.MarkFailed(err, "failed to render Corefile")
 		return result, err
 	}
-
-	corefileTmpl, err := template.New("Corefile").Funcs(template.FuncMap{
-		"join": strings.Join,
-	}).Parse(corefileTemplateContent)
-	if err != nil {
-		result.MarkFailed(err, "failed to parse Corefile.tmpl")
-		return result, err
-	}
-
-	var corefileBuffer bytes.Buffer
-	if err := corefileTmpl.Execute(&corefileBuffer, s); err != nil {
-		result.MarkFailed(err, "failed to render Corefile.tmpl")
-		return result, err
-	}
-	s.Corefile = corefileBuffer.String()
+	s.Corefile = corefile
 
 	logger.Info("Rendering main CoreDNS deployment manifest...")
 	mainTemplateContent, err := templates.Get("dns/coredns.yaml.tmpl")
@@ -39,8 +39,10 @@ type daemonConfigForRender struct {
 
 type ConfigureDockerStep struct {
 	step.Base
-	FinalConfig    daemonConfigForRender
-	ConfigFilePath string
+	FinalConfig     daemonConfigForRender
+	ConfigFilePath  string
+	BackupRetention int
+	lastBackup      string
 }
 
 type ConfigureDockerStepBuilder struct {
@@ -148,8 +150,12 @@ func NewConfigureDockerStepBuilder(ctx runtime.ExecutionContext, instanceName st
 	}
 
 	s := &ConfigureDockerStep{
-		ConfigFilePath: DefaultDockerDaemonJSONPath,
-		FinalConfig:    finalConfig,
+		ConfigFilePath:  DefaultDockerDaemonJSONPath,
+		FinalConfig:     finalConfig,
+		BackupRetention: common.DefaultConfigBackupRetention,
+	}
+	if retention := clusterCfgSpec.Kubernetes.ContainerRuntime.ConfigBackupRetention; retention != nil {
+		s.BackupRetention = *retention
 	}
 
 	s.Base.Meta.Name = instanceName
@@ -225,6 +231,16 @@ func (s *ConfigureDockerStep) Run(ctx runtime.ExecutionContext) (*types.StepResu
 		return result, fmt.Errorf("failed to create remote directory %s: %w", remoteDir, err)
 	}
 
+	backupPath, err := helpers.BackupRemoteConfig(ctx, conn, s.ConfigFilePath, s.BackupRetention, s.Sudo)
+	if err != nil {
+		result.MarkFailed(err, "failed to back up Docker daemon.json")
+		return result, err
+	}
+	if backupPath != "" {
+		s.lastBackup = backupPath
+		logger.Info("Backed up existing Docker daemon.json file.", "backup", backupPath)
+	}
+
 	logger.Info("Writing Docker daemon.json file.", "path", s.ConfigFilePath)
 	err = helpers.WriteContentToRemote(ctx, conn, string(configContent), s.ConfigFilePath, "0644", s.Sudo)
 	if err != nil {
@@ -245,6 +261,14 @@ func (s *ConfigureDockerStep) Rollback(ctx runtime.ExecutionContext) error {
 		return nil
 	}
 
+	if s.lastBackup != "" {
+		logger.Warnf("Rolling back by restoring %s from %s", s.ConfigFilePath, s.lastBackup)
+		if err := helpers.RestoreRemoteConfigBackup(ctx, conn, s.ConfigFilePath, s.lastBackup, s.Sudo); err != nil {
+			logger.Error(err, "Failed to restore Docker daemon.json backup during rollback.")
+		}
+		return nil
+	}
+
 	logger.Warnf("Rolling back by removing %s", s.ConfigFilePath)
 	if err := runner.Remove(ctx.GoContext(), conn, s.ConfigFilePath, s.Sudo, true); err != nil {
 		logger.Error(err, "Failed to remove Docker daemon.json during rollback.")
@@ -252,4 +276,22 @@ func (s *ConfigureDockerStep) Rollback(ctx runtime.ExecutionContext) error {
 	return nil
 }
 
+// ListBackups returns the timestamped backups of daemon.json, newest first.
+func (s *ConfigureDockerStep) ListBackups(ctx runtime.ExecutionContext) ([]string, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ListRemoteConfigBackups(ctx, conn, s.ConfigFilePath, s.Sudo)
+}
+
+// RestoreBackup replaces daemon.json with one of the backups returned by ListBackups.
+func (s *ConfigureDockerStep) RestoreBackup(ctx runtime.ExecutionContext, backupPath string) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	return helpers.RestoreRemoteConfigBackup(ctx, conn, s.ConfigFilePath, backupPath, s.Sudo)
+}
+
 var _ step.Step = (*ConfigureDockerStep)(nil)
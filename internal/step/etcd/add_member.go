@@ -1,7 +1,6 @@
 package etcd
 
 import (
-	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -9,23 +8,13 @@ import (
 
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
 	"github.com/mensylisir/kubexm/internal/types"
 )
 
-type MemberInfo struct {
-	ID         uint64   `json:"ID"`
-	Name       string   `json:"name"`
-	PeerURLs   []string `json:"peerURLs"`
-	ClientURLs []string `json:"clientURLs"`
-}
-
-type MemberListOutput struct {
-	Members []*MemberInfo `json:"members"`
-}
-
 type AddEtcdMemberStep struct {
 	step.Base
 	NewNode           remotefw.Host
@@ -71,24 +60,18 @@ func (s *AddEtcdMemberStep) Precheck(ctx runtime.ExecutionContext) (isDone bool,
 		return false, fmt.Errorf("NewNode is not specified for AddEtcdMemberStep")
 	}
 
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		return false, err
 	}
 
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
-	listCmd := fmt.Sprintf("ETCDCTL_API=3 %s member list --cacert %s --cert %s --key %s",
-		s.EtcdctlBinaryPath, caPath, certPath, keyPath)
-
-	stdout, stderr, err := runner.OriginRun(ctx.GoContext(), conn, listCmd, s.Sudo)
+	members, err := ctx.GetRunner().EtcdMemberList(ctx.GoContext(), conn, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo))
 	if err != nil {
-		logger.Warn("Failed to list etcd members during precheck, proceeding with run phase.", "error", err, "stderr", stderr)
+		logger.Warn("Failed to list etcd members during precheck, proceeding with run phase.", "error", err)
 		return false, nil
 	}
 
-	newNodePeerURL := getPeerURL(s.NewNode)
-	if strings.Contains(stdout, newNodePeerURL) {
+	if findMemberByPeerURL(members, getPeerURL(s.NewNode)) != nil {
 		logger.Info("New member is already part of the etcd cluster. Step is done.", "member", s.NewNode.GetName())
 		return true, nil
 	}
@@ -107,7 +90,6 @@ func (s *AddEtcdMemberStep) Run(ctx runtime.ExecutionContext) (*types.StepResult
 		return result, err
 	}
 
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		result.MarkFailed(err, "Failed to get connector")
@@ -116,25 +98,20 @@ func (s *AddEtcdMemberStep) Run(ctx runtime.ExecutionContext) (*types.StepResult
 
 	newNodeName := s.NewNode.GetName()
 	newNodePeerURL := getPeerURL(s.NewNode)
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
-
-	addCmd := fmt.Sprintf("ETCDCTL_API=3 %s member add %s --peer-urls=%s --cacert %s --cert %s --key %s",
-		s.EtcdctlBinaryPath, newNodeName, newNodePeerURL, caPath, certPath, keyPath)
 
 	logger.Info("Executing 'etcdctl member add' on current node...", "newMember", newNodeName, "peerURL", newNodePeerURL)
-	_, stderr, err := runner.OriginRun(ctx.GoContext(), conn, addCmd, s.Sudo)
+	member, err := ctx.GetRunner().EtcdMemberAdd(ctx.GoContext(), conn, newNodeName, []string{newNodePeerURL}, false, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo))
 	if err != nil {
-		if strings.Contains(stderr, "member already exists") {
+		if strings.Contains(err.Error(), "already exists") {
 			logger.Warn("etcd member already exists, but precheck did not detect it. Treating as success.", "member", newNodeName)
 			result.MarkCompleted("Member already exists")
 			return result, nil
 		}
-		err = fmt.Errorf("failed to add etcd member: %w, stderr: %s", err, stderr)
 		result.MarkFailed(err, "Failed to add etcd member")
 		return result, err
 	}
 
-	logger.Info("New member has been successfully registered in the etcd cluster.", "member", newNodeName)
+	logger.Info("New member has been successfully registered in the etcd cluster.", "member", newNodeName, "id", runner.FormatEtcdMemberID(member.ID))
 	result.MarkCompleted("Member added successfully")
 	return result, nil
 }
@@ -147,57 +124,37 @@ func (s *AddEtcdMemberStep) Rollback(ctx runtime.ExecutionContext) error {
 		return nil
 	}
 
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		logger.Error(err, "Failed to get connector for rollback")
 		return nil
 	}
 
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
-
-	logger.Info("Listing members to find the ID of the new member for removal...", "member", s.NewNode.GetName())
-	listCmd := fmt.Sprintf("ETCDCTL_API=3 %s member list --cacert %s --cert %s --key %s --write-out=json",
-		s.EtcdctlBinaryPath, caPath, certPath, keyPath)
+	opts := etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo)
+	newNodeName := s.NewNode.GetName()
 
-	stdout, stderr, err := runner.OriginRun(ctx.GoContext(), conn, listCmd, s.Sudo)
+	logger.Info("Listing members to find the ID of the new member for removal...", "member", newNodeName)
+	members, err := ctx.GetRunner().EtcdMemberList(ctx.GoContext(), conn, opts)
 	if err != nil {
-		logger.Error(err, "Failed to list etcd members during rollback, unable to remove member.", "stderr", stderr)
+		logger.Error(err, "Failed to list etcd members during rollback, unable to remove member.")
 		return nil
 	}
 
-	var memberList MemberListOutput
-	if err := json.Unmarshal([]byte(stdout), &memberList); err != nil {
-		logger.Error(err, "Failed to parse member list JSON during rollback.", "output", stdout)
-		return nil
-	}
-
-	var memberID uint64
-	newNodeName := s.NewNode.GetName()
-	for _, member := range memberList.Members {
-		if member.Name == newNodeName {
-			memberID = member.ID
-			break
-		}
-	}
-
-	if memberID == 0 {
+	// A member that never started has no name yet, so it is matched by its peer URL.
+	member := findMemberByPeerURL(members, getPeerURL(s.NewNode))
+	if member == nil {
 		logger.Warn("Could not find the new member in the cluster list, it might have been removed already. Rollback is considered complete.", "member", newNodeName)
 		return nil
 	}
 
-	memberIDHex := fmt.Sprintf("%x", memberID)
-	removeCmd := fmt.Sprintf("ETCDCTL_API=3 %s member remove %s --cacert %s --cert %s --key %s",
-		s.EtcdctlBinaryPath, memberIDHex, caPath, certPath, keyPath)
-
+	memberIDHex := runner.FormatEtcdMemberID(member.ID)
 	logger.Warn("Executing 'etcdctl member remove' to roll back the addition...", "member", newNodeName, "id", memberIDHex)
-	_, stderr, err = runner.OriginRun(ctx.GoContext(), conn, removeCmd, s.Sudo)
-	if err != nil {
-		if strings.Contains(stderr, "member not found") {
+	if err := ctx.GetRunner().EtcdMemberRemove(ctx.GoContext(), conn, member.ID, opts); err != nil {
+		if strings.Contains(err.Error(), "member not found") {
 			logger.Warn("Member was not found during removal, it might have been removed by another process. Rollback is considered complete.", "id", memberIDHex)
 			return nil
 		}
-		logger.Error(err, "Failed to remove etcd member during rollback.", "id", memberIDHex, "stderr", stderr)
+		logger.Error(err, "Failed to remove etcd member during rollback.", "id", memberIDHex)
 	} else {
 		logger.Info("Successfully removed member from the cluster during rollback.", "member", newNodeName, "id", memberIDHex)
 	}
@@ -213,6 +170,31 @@ func getPeerURL(node remotefw.Host) string {
 	return fmt.Sprintf("https://%s:2380", peerAddress)
 }
 
+func findMemberByPeerURL(members []runner.EtcdMember, peerURL string) *runner.EtcdMember {
+	for i := range members {
+		for _, u := range members[i].PeerURLs {
+			if u == peerURL {
+				return &members[i]
+			}
+		}
+	}
+	return nil
+}
+
+// etcdctlOptions returns the etcdctl connection options for the current host. Without endpoints
+// etcdctl talks to the local member.
+func etcdctlOptions(ctx runtime.ExecutionContext, etcdctlPath string, sudo bool, endpoints ...string) runner.EtcdctlOptions {
+	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
+	return runner.EtcdctlOptions{
+		EtcdctlPath: etcdctlPath,
+		Endpoints:   endpoints,
+		CACert:      caPath,
+		Cert:        certPath,
+		Key:         keyPath,
+		Sudo:        sudo,
+	}
+}
+
 func getEtcdctlCertPaths(ctx runtime.ExecutionContext, nodeName string) (caPath, certPath, keyPath string) {
 	etcdType := ""
 	if cfg := ctx.GetClusterConfig(); cfg != nil && cfg.Spec.Etcd != nil {
@@ -225,11 +207,8 @@ func getEtcdctlCertPaths(ctx runtime.ExecutionContext, nodeName string) (caPath,
 		certPath = "/etc/kubernetes/pki/etcd/server.crt"
 		keyPath = "/etc/kubernetes/pki/etcd/server.key"
 	} else if etcdType == string(common.EtcdDeploymentTypeExternal) {
-		// External etcd: cert paths must be explicitly configured by user
-		// We cannot assume any default path
-		caPath = "/etc/etcd/pki/ca.pem"
-		certPath = filepath.Join(common.DefaultEtcdPKIDir, fmt.Sprintf(common.EtcdAdminCertFileNamePattern, nodeName))
-		keyPath = filepath.Join(common.DefaultEtcdPKIDir, fmt.Sprintf(common.EtcdAdminKeyFileNamePattern, nodeName))
+		// External etcd: the user supplied client certificates distributed to the masters
+		caPath, certPath, keyPath = ctx.GetClusterConfig().Spec.Etcd.External.RemoteCertPaths()
 	} else {
 		// kubexm-deployed etcd uses /etc/etcd/pki/ with per-node .pem certificates
 		caPath = filepath.Join(common.DefaultEtcdPKIDir, common.EtcdCaPemFileName)
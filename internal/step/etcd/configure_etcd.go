@@ -141,6 +141,15 @@ func (s *ConfigureEtcdStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
+// RenderFiles returns the etcd.conf.yaml of the current host in RemoteConfDir.
+func (s *ConfigureEtcdStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.renderConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: filepath.Join(s.RemoteConfDir, "etcd.conf.yaml"), Content: content}}, nil
+}
+
 func (s *ConfigureEtcdStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Precheck")
 	content, err := s.renderConfig(ctx)
@@ -255,15 +264,7 @@ func (s *ConfigureEtcdStep) renderConfig(ctx runtime.ExecutionContext) ([]byte,
 		return nil, fmt.Errorf("failed to get embedded etcd config template: %w", err)
 	}
 
-	var initialCluster []string
-	for _, node := range s.EtcdNodes {
-		peerAddress := node.GetInternalAddress()
-		if peerAddress == "" {
-			peerAddress = node.GetAddress()
-		}
-		initialCluster = append(initialCluster, fmt.Sprintf("%s=https://%s:2380", node.GetName(), peerAddress))
-	}
-	initialClusterStr := strings.Join(initialCluster, ",")
+	initialClusterStr := etcdInitialCluster(s.EtcdNodes)
 
 	listenAddress := currentHost.GetInternalAddress()
 	if listenAddress == "" {
@@ -303,4 +304,23 @@ func (s *ConfigureEtcdStep) renderConfig(ctx runtime.ExecutionContext) ([]byte,
 	return buffer.Bytes(), nil
 }
 
+// etcdPeerURL is the peer URL a member is listed under in the initial cluster.
+func etcdPeerURL(node remotefw.Host) string {
+	peerAddress := node.GetInternalAddress()
+	if peerAddress == "" {
+		peerAddress = node.GetAddress()
+	}
+	return fmt.Sprintf("https://%s:2380", peerAddress)
+}
+
+// etcdInitialCluster renders the --initial-cluster value for nodes.
+func etcdInitialCluster(nodes []remotefw.Host) string {
+	var initialCluster []string
+	for _, node := range nodes {
+		initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", node.GetName(), etcdPeerURL(node)))
+	}
+	return strings.Join(initialCluster, ",")
+}
+
 var _ step.Step = (*ConfigureEtcdStep)(nil)
+var _ step.Renderer = (*ConfigureEtcdStep)(nil)
@@ -3,7 +3,6 @@ package etcd
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/common"
@@ -53,7 +52,6 @@ func (s *DefragmentEtcdStep) Precheck(ctx runtime.ExecutionContext) (isDone bool
 func (s *DefragmentEtcdStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		result.MarkFailed(err, "Failed to get connector")
@@ -62,31 +60,15 @@ func (s *DefragmentEtcdStep) Run(ctx runtime.ExecutionContext) (*types.StepResul
 
 	nodeName := ctx.GetHost().GetName()
 	logger.Info("Starting defragmentation on etcd member...", "node", nodeName)
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, nodeName)
-	endpoint := "https://127.0.0.1:2379"
-
-	defragCmd := fmt.Sprintf("ETCDCTL_API=3 %s defrag --endpoints=%s --cacert=%s --cert=%s --key=%s",
-		s.EtcdctlBinaryPath,
-		endpoint,
-		caPath,
-		certPath,
-		keyPath,
-	)
-
-	stdout, stderr, err := runner.OriginRun(ctx.GoContext(), conn, defragCmd, s.Sudo)
-	if err != nil {
-		err = fmt.Errorf("failed to defragment etcd member %s: %w, stderr: %s", nodeName, err, stderr)
-		result.MarkFailed(err, "Defragmentation failed")
-		return result, err
-	}
 
-	if !strings.Contains(stdout, "Finished defragmenting") {
-		err = fmt.Errorf("defragmentation command ran without error, but success message was not found. Output: %s", stdout)
-		result.MarkFailed(err, "Defragmentation did not complete")
+	opts := etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo, "https://127.0.0.1:2379")
+	if err := ctx.GetRunner().EtcdDefragment(ctx.GoContext(), conn, opts); err != nil {
+		err = fmt.Errorf("failed to defragment etcd member %s: %w", nodeName, err)
+		result.MarkFailed(err, "Defragmentation failed")
 		return result, err
 	}
 
-	logger.Info("Successfully defragmented etcd member.", "node", nodeName, "output", stdout)
+	logger.Info("Successfully defragmented etcd member.", "node", nodeName)
 	result.MarkCompleted("Defragmentation completed successfully")
 	return result, nil
 }
@@ -21,6 +21,8 @@ type GenerateEtcdCAStep struct {
 	LocalCertsDir string
 	CADuration    time.Duration
 	Permission    string
+	// ExternalCA, when set, is imported instead of generating a self-signed CA.
+	ExternalCA *helpers.ExternalCA
 }
 
 type GenerateEtcdCAStepBuilder struct {
@@ -68,6 +70,11 @@ func (b *GenerateEtcdCAStepBuilder) WithPermission(permission string) *GenerateE
 	return b
 }
 
+func (b *GenerateEtcdCAStepBuilder) WithExternalCA(ca *helpers.ExternalCA) *GenerateEtcdCAStepBuilder {
+	b.Step.ExternalCA = ca
+	return b
+}
+
 func (s *GenerateEtcdCAStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
@@ -86,6 +93,10 @@ func (s *GenerateEtcdCAStep) Precheck(ctx runtime.ExecutionContext) (isDone bool
 		logger.Info("ETCD CA private key not found. Generation is required.")
 		return false, nil
 	}
+	if s.ExternalCA != nil && !s.ExternalCA.IsImported(caCertPath) {
+		logger.Info("ETCD CA differs from the configured external CA. Import is required.")
+		return false, nil
+	}
 
 	logger.Info("ETCD CA certificate and key already exist. Step is done.")
 	return true, nil
@@ -107,6 +118,18 @@ func (s *GenerateEtcdCAStep) Run(ctx runtime.ExecutionContext) (*types.StepResul
 		return result, err
 	}
 
+	if s.ExternalCA != nil {
+		logger.Infof("Importing external ETCD CA '%s'...", s.ExternalCA.Cert.Subject.CommonName)
+		if err := s.ExternalCA.Import(s.LocalCertsDir, common.EtcdCaPemFileName, common.EtcdCaKeyPemFileName); err != nil {
+			err = fmt.Errorf("failed to import external ETCD CA: %w", err)
+			result.MarkFailed(err, "Failed to import external ETCD CA")
+			return result, err
+		}
+		logger.Info("ETCD CA is ready.")
+		result.MarkCompleted("External CA imported successfully")
+		return result, nil
+	}
+
 	logger.Info("Ensuring ETCD CA certificate and key exist...")
 	_, _, err = helpers.NewCertificateAuthority(s.LocalCertsDir, common.EtcdCaPemFileName, common.EtcdCaKeyPemFileName, s.CADuration)
 	if err != nil {
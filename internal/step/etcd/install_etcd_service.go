@@ -81,6 +81,15 @@ func (s *InstallEtcdServiceStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
+// RenderFiles returns the etcd unit file at ServicePath.
+func (s *InstallEtcdServiceStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.renderServiceFile()
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: s.ServicePath, Content: content}}, nil
+}
+
 func (s *InstallEtcdServiceStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Precheck")
 
@@ -223,3 +232,4 @@ func (s *InstallEtcdServiceStep) renderServiceFile() ([]byte, error) {
 }
 
 var _ step.Step = (*InstallEtcdServiceStep)(nil)
+var _ step.Renderer = (*InstallEtcdServiceStep)(nil)
@@ -1,7 +1,6 @@
 package etcd
 
 import (
-	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -9,6 +8,7 @@ import (
 
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -61,24 +61,18 @@ func (s *RemoveEtcdMemberStep) Precheck(ctx runtime.ExecutionContext) (isDone bo
 		return false, fmt.Errorf("NodeToRemove is not specified for RemoveEtcdMemberStep")
 	}
 
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		return false, err
 	}
 
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
-	listCmd := fmt.Sprintf("ETCDCTL_API=3 %s member list --cacert %s --cert %s --key %s",
-		s.EtcdctlBinaryPath, caPath, certPath, keyPath)
-
-	stdout, stderr, err := runner.OriginRun(ctx.GoContext(), conn, listCmd, s.Sudo)
+	members, err := ctx.GetRunner().EtcdMemberList(ctx.GoContext(), conn, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo))
 	if err != nil {
-		logger.Warn("Failed to list etcd members during precheck, proceeding with run phase.", "error", err, "stderr", stderr)
+		logger.Warn("Failed to list etcd members during precheck, proceeding with run phase.", "error", err)
 		return false, nil
 	}
 
-	nodeToRemovePeerURL := getPeerURL(s.NodeToRemove)
-	if !strings.Contains(stdout, nodeToRemovePeerURL) {
+	if findMemberByPeerURL(members, getPeerURL(s.NodeToRemove)) == nil {
 		logger.Info("Target member is not part of the etcd cluster. Step is done.", "member", s.NodeToRemove.GetName())
 		return true, nil
 	}
@@ -97,67 +91,53 @@ func (s *RemoveEtcdMemberStep) Run(ctx runtime.ExecutionContext) (*types.StepRes
 		return result, err
 	}
 
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		result.MarkFailed(err, "Failed to get connector")
 		return result, err
 	}
 
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
-
-	logger.Info("Listing members to find the ID of the member to remove...", "member", s.NodeToRemove.GetName())
-	listCmd := fmt.Sprintf("ETCDCTL_API=3 %s member list --cacert %s --cert %s --key %s --write-out=json",
-		s.EtcdctlBinaryPath, caPath, certPath, keyPath)
+	opts := etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo)
+	nodeToRemoveName := s.NodeToRemove.GetName()
 
-	stdout, stderr, err := runner.OriginRun(ctx.GoContext(), conn, listCmd, s.Sudo)
+	logger.Info("Listing members to find the ID of the member to remove...", "member", nodeToRemoveName)
+	members, err := ctx.GetRunner().EtcdMemberList(ctx.GoContext(), conn, opts)
 	if err != nil {
-		err = fmt.Errorf("failed to list etcd members: %w, stderr: %s", err, stderr)
 		result.MarkFailed(err, "Failed to list members")
 		return result, err
 	}
 
-	var memberList MemberListOutput
-	if err := json.Unmarshal([]byte(stdout), &memberList); err != nil {
-		err = fmt.Errorf("failed to parse member list JSON: %w, output: %s", err, stdout)
-		result.MarkFailed(err, "Failed to parse member list")
-		return result, err
-	}
-
-	var memberID uint64
-	nodeToRemoveName := s.NodeToRemove.GetName()
-	for _, member := range memberList.Members {
-		if member.Name == nodeToRemoveName {
-			memberID = member.ID
+	var member *runner.EtcdMember
+	for i := range members {
+		if members[i].Name == nodeToRemoveName {
+			member = &members[i]
 			break
 		}
 	}
+	if member == nil {
+		member = findMemberByPeerURL(members, getPeerURL(s.NodeToRemove))
+	}
 
-	if memberID == 0 {
+	if member == nil {
 		logger.Warn("Could not find the target member in the cluster list, it might have been removed already. Step is successful.", "member", nodeToRemoveName)
 		result.MarkCompleted("Member not found in cluster")
 		return result, nil
 	}
 
-	if len(memberList.Members) <= 2 {
+	if len(members) <= 2 {
 		err = fmt.Errorf("cannot remove member from a cluster with 2 or fewer members. This would lead to a loss of quorum")
 		result.MarkFailed(err, "Cannot remove member - would lose quorum")
 		return result, err
 	}
 
-	memberIDHex := fmt.Sprintf("%x", memberID)
-	removeCmd := fmt.Sprintf("ETCDCTL_API=3 %s member remove %s --cacert %s --cert %s --key %s",
-		s.EtcdctlBinaryPath, memberIDHex, caPath, certPath, keyPath)
-
+	memberIDHex := runner.FormatEtcdMemberID(member.ID)
 	logger.Info("Executing 'etcdctl member remove'...", "member", nodeToRemoveName, "id", memberIDHex)
-	_, stderr, err = runner.OriginRun(ctx.GoContext(), conn, removeCmd, s.Sudo)
-	if err != nil {
-		if strings.Contains(stderr, "member not found") {
+	if err := ctx.GetRunner().EtcdMemberRemove(ctx.GoContext(), conn, member.ID, opts); err != nil {
+		if strings.Contains(err.Error(), "member not found") {
 			logger.Warn("Member was not found during removal, it might have been removed by another process. Step is successful.", "id", memberIDHex)
 			result.MarkCompleted("Member already removed")
 			return result, nil
 		}
-		err = fmt.Errorf("failed to remove etcd member: %w, stderr: %s", err, stderr)
 		result.MarkFailed(err, "Failed to remove member")
 		return result, err
 	}
@@ -175,29 +155,23 @@ func (s *RemoveEtcdMemberStep) Rollback(ctx runtime.ExecutionContext) error {
 		return nil
 	}
 
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		logger.Error(err, "Failed to get connector for rollback")
 		return nil
 	}
 
-	nodeToAddBack := s.NodeToRemove
-	nodeToAddBackName := nodeToAddBack.GetName()
-	nodeToAddBackPeerURL := getPeerURL(nodeToAddBack)
-	caPath, certPath, keyPath := getEtcdctlCertPaths(ctx, ctx.GetHost().GetName())
-
-	addCmd := fmt.Sprintf("ETCDCTL_API=3 %s member add %s --peer-urls=%s --cacert %s --cert %s --key %s",
-		s.EtcdctlBinaryPath, nodeToAddBackName, nodeToAddBackPeerURL, caPath, certPath, keyPath)
+	nodeToAddBackName := s.NodeToRemove.GetName()
+	nodeToAddBackPeerURL := getPeerURL(s.NodeToRemove)
 
 	logger.Warn("Rolling back by re-adding the removed member...", "member", nodeToAddBackName)
-	_, stderr, err := runner.OriginRun(ctx.GoContext(), conn, addCmd, s.Sudo)
+	_, err = ctx.GetRunner().EtcdMemberAdd(ctx.GoContext(), conn, nodeToAddBackName, []string{nodeToAddBackPeerURL}, false, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo))
 	if err != nil {
-		if strings.Contains(stderr, "member already exists") {
+		if strings.Contains(err.Error(), "already exists") {
 			logger.Warn("Member already exists, rollback seems complete.", "member", nodeToAddBackName)
 			return nil
 		}
-		logger.Error(err, "Failed to re-add etcd member during rollback.", "stderr", stderr)
+		logger.Error(err, "Failed to re-add etcd member during rollback.")
 	} else {
 		logger.Info("Successfully re-added member to the cluster during rollback.", "member", nodeToAddBackName)
 	}
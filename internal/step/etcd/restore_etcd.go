@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -14,12 +15,14 @@ import (
 
 type RestoreEtcdStep struct {
 	step.Base
-	LocalSnapshotPath string
-	RemoteTempDir     string
-	DataDir           string
-	EtcdctlBinaryPath string
-	EtcdUser          string
-	EtcdGroup         string
+	LocalSnapshotPath   string
+	RemoteTempDir       string
+	DataDir             string
+	EtcdctlBinaryPath   string
+	EtcdUser            string
+	EtcdGroup           string
+	EtcdNodes           []remotefw.Host
+	InitialClusterToken string
 }
 
 type RestoreEtcdStepBuilder struct {
@@ -34,6 +37,9 @@ func NewRestoreEtcdStepBuilder(ctx runtime.ExecutionContext, instanceName string
 		EtcdctlBinaryPath: filepath.Join(common.DefaultBinDir, "etcdctl"),
 		EtcdUser:          "etcd",
 		EtcdGroup:         "etcd",
+		// Must match ConfigureEtcdStep so that the restored member IDs agree with the rendered config.
+		EtcdNodes:           ctx.GetHostsByRole(common.RoleEtcd),
+		InitialClusterToken: "kubexm-etcd-cluster",
 	}
 
 	s.Base.Meta.Name = instanceName
@@ -76,6 +82,16 @@ func (b *RestoreEtcdStepBuilder) WithEtcdGroup(group string) *RestoreEtcdStepBui
 	return b
 }
 
+func (b *RestoreEtcdStepBuilder) WithEtcdNodes(nodes []remotefw.Host) *RestoreEtcdStepBuilder {
+	b.Step.EtcdNodes = nodes
+	return b
+}
+
+func (b *RestoreEtcdStepBuilder) WithInitialClusterToken(token string) *RestoreEtcdStepBuilder {
+	b.Step.InitialClusterToken = token
+	return b
+}
+
 func (s *RestoreEtcdStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
@@ -127,6 +143,15 @@ func (s *RestoreEtcdStep) Run(ctx runtime.ExecutionContext) (*types.StepResult,
 		ctx.GetHost().GetName(),
 		restoredDataDirName,
 	)
+	if len(s.EtcdNodes) > 0 {
+		// Every member restores the same snapshot with the same membership, so the rebuilt
+		// cluster forms from the restored data instead of the members of the old cluster.
+		restoreCmd += fmt.Sprintf(" --initial-cluster %s --initial-cluster-token %s --initial-advertise-peer-urls %s",
+			etcdInitialCluster(s.EtcdNodes),
+			s.InitialClusterToken,
+			etcdPeerURL(ctx.GetHost()),
+		)
+	}
 
 	logger.Info("Restoring etcd data from snapshot...")
 	if _, stderr, err := runner.OriginRun(ctx.GoContext(), conn, restoreCmd, s.Sudo); err != nil {
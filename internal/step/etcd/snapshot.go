@@ -0,0 +1,301 @@
+package etcd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const (
+	etcdSnapshotPrefix = "etcd-snapshot-"
+	etcdSnapshotSuffix = ".db"
+	// etcdSnapshotTimeFormat sorts lexically in chronological order and is safe in file names.
+	etcdSnapshotTimeFormat = "20060102T150405Z"
+)
+
+// LocalEtcdSnapshotDir is the directory on the control machine that holds the snapshots taken by SaveEtcdSnapshotStep.
+func LocalEtcdSnapshotDir(ctx runtime.ExecutionContext) string {
+	return filepath.Join(ctx.GetClusterWorkDir(), common.DefaultEtcdDir, "snapshots")
+}
+
+// EtcdSnapshotFileName returns the timestamped snapshot file name for t, e.g. etcd-snapshot-20240501T100000Z.db.
+func EtcdSnapshotFileName(t time.Time) string {
+	return etcdSnapshotPrefix + t.UTC().Format(etcdSnapshotTimeFormat) + etcdSnapshotSuffix
+}
+
+// SaveEtcdSnapshotStep takes a snapshot from the current etcd leader, verifies it and fetches it
+// to the control machine. The local path is stored in the pipeline cache under common.CacheKeyEtcdSnapshotPath.
+type SaveEtcdSnapshotStep struct {
+	step.Base
+	EtcdNodes         []remotefw.Host
+	EtcdctlBinaryPath string
+	RemoteBackupDir   string
+	LocalBackupDir    string
+	SnapshotFileName  string
+}
+
+type SaveEtcdSnapshotStepBuilder struct {
+	step.Builder[SaveEtcdSnapshotStepBuilder, *SaveEtcdSnapshotStep]
+}
+
+func NewSaveEtcdSnapshotStepBuilder(ctx runtime.ExecutionContext, instanceName string) *SaveEtcdSnapshotStepBuilder {
+	s := &SaveEtcdSnapshotStep{
+		EtcdNodes:         ctx.GetHostsByRole(common.RoleEtcd),
+		EtcdctlBinaryPath: filepath.Join(common.DefaultBinDir, "etcdctl"),
+		RemoteBackupDir:   common.DefaultEtcdBackupDir,
+		LocalBackupDir:    LocalEtcdSnapshotDir(ctx),
+		SnapshotFileName:  EtcdSnapshotFileName(time.Now()),
+	}
+	if cfg := ctx.GetClusterConfig(); cfg != nil && cfg.Spec.Etcd != nil && cfg.Spec.Etcd.BackupConfig != nil && cfg.Spec.Etcd.BackupConfig.BackupDir != nil {
+		s.RemoteBackupDir = *cfg.Spec.Etcd.BackupConfig.BackupDir
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Save an etcd snapshot from the leader and fetch it to the control machine", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 15 * time.Minute
+
+	b := new(SaveEtcdSnapshotStepBuilder).Init(s)
+	return b
+}
+
+func (b *SaveEtcdSnapshotStepBuilder) WithEtcdNodes(nodes []remotefw.Host) *SaveEtcdSnapshotStepBuilder {
+	b.Step.EtcdNodes = nodes
+	return b
+}
+
+func (b *SaveEtcdSnapshotStepBuilder) WithRemoteBackupDir(path string) *SaveEtcdSnapshotStepBuilder {
+	b.Step.RemoteBackupDir = path
+	return b
+}
+
+func (b *SaveEtcdSnapshotStepBuilder) WithLocalBackupDir(path string) *SaveEtcdSnapshotStepBuilder {
+	b.Step.LocalBackupDir = path
+	return b
+}
+
+func (b *SaveEtcdSnapshotStepBuilder) WithEtcdctlBinaryPath(path string) *SaveEtcdSnapshotStepBuilder {
+	b.Step.EtcdctlBinaryPath = path
+	return b
+}
+
+func (s *SaveEtcdSnapshotStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *SaveEtcdSnapshotStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	if len(s.EtcdNodes) == 0 {
+		return false, fmt.Errorf("no etcd nodes found to take a snapshot from")
+	}
+	return false, nil
+}
+
+func (s *SaveEtcdSnapshotStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "Failed to get connector")
+		return result, err
+	}
+
+	var endpoints []string
+	for _, node := range s.EtcdNodes {
+		endpoints = append(endpoints, fmt.Sprintf("https://%s:2379", node.GetAddress()))
+	}
+	statuses, err := runner.EtcdEndpointStatus(ctx.GoContext(), conn, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo, endpoints...))
+	if err != nil {
+		result.MarkFailed(err, "Failed to query endpoint status")
+		return result, err
+	}
+	leader, err := findEtcdLeaderEndpoint(statuses)
+	if err != nil {
+		result.MarkFailed(err, "Failed to find etcd leader")
+		return result, err
+	}
+	logger.Infof("Taking snapshot from etcd leader %s.", leader)
+
+	if err := runner.Mkdirp(ctx.GoContext(), conn, s.RemoteBackupDir, "0700", s.Sudo); err != nil {
+		err = fmt.Errorf("failed to create remote backup directory %s: %w", s.RemoteBackupDir, err)
+		result.MarkFailed(err, "Failed to create backup directory")
+		return result, err
+	}
+
+	remotePath := filepath.Join(s.RemoteBackupDir, s.SnapshotFileName)
+	if err := runner.EtcdSnapshotSave(ctx.GoContext(), conn, remotePath, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo, leader)); err != nil {
+		result.MarkFailed(err, "Failed to save snapshot")
+		return result, err
+	}
+	defer func() {
+		if err := runner.Remove(ctx.GoContext(), conn, remotePath, s.Sudo, false); err != nil {
+			logger.Warnf("Failed to remove remote snapshot %s: %v", remotePath, err)
+		}
+	}()
+
+	snapshotStatus, err := runner.EtcdSnapshotStatus(ctx.GoContext(), conn, remotePath, etcdctlOptions(ctx, s.EtcdctlBinaryPath, s.Sudo))
+	if err != nil {
+		err = fmt.Errorf("snapshot %s failed verification: %w", remotePath, err)
+		result.MarkFailed(err, "Snapshot verification failed")
+		return result, err
+	}
+	logger.Infof("Snapshot %s verified: revision %d, %d keys, %d bytes.", remotePath, snapshotStatus.Revision, snapshotStatus.TotalKey, snapshotStatus.TotalSize)
+
+	if err := os.MkdirAll(s.LocalBackupDir, 0700); err != nil {
+		err = fmt.Errorf("failed to create local backup directory %s: %w", s.LocalBackupDir, err)
+		result.MarkFailed(err, "Failed to create local backup directory")
+		return result, err
+	}
+	localPath := filepath.Join(s.LocalBackupDir, s.SnapshotFileName)
+	if err := runner.Fetch(ctx.GoContext(), conn, remotePath, localPath, s.Sudo); err != nil {
+		err = fmt.Errorf("failed to fetch etcd snapshot to %s: %w", localPath, err)
+		result.MarkFailed(err, "Failed to fetch snapshot")
+		return result, err
+	}
+
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyEtcdSnapshotPath, ctx.GetRunID()), localPath)
+	logger.Infof("Etcd snapshot saved to %s.", localPath)
+	result.SetMetadata("snapshotPath", localPath)
+	result.MarkCompleted("Snapshot saved successfully")
+	return result, nil
+}
+
+func (s *SaveEtcdSnapshotStep) Rollback(ctx runtime.ExecutionContext) error {
+	localPath := filepath.Join(s.LocalBackupDir, s.SnapshotFileName)
+	if err := os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+		ctx.GetLogger().Warnf("Failed to remove local snapshot %s during rollback: %v", localPath, err)
+	}
+	return nil
+}
+
+// findEtcdLeaderEndpoint returns the endpoint whose member is the current raft leader.
+func findEtcdLeaderEndpoint(statuses []runner.EtcdEndpointStatus) (string, error) {
+	for _, st := range statuses {
+		if st.IsLeader() {
+			return st.Endpoint, nil
+		}
+	}
+	return "", fmt.Errorf("none of the %d etcd endpoints reported itself as leader", len(statuses))
+}
+
+// PruneEtcdSnapshotsStep removes the oldest snapshots in LocalBackupDir so that at most Retain remain.
+// It runs on the control machine.
+type PruneEtcdSnapshotsStep struct {
+	step.Base
+	LocalBackupDir string
+	Retain         int
+}
+
+type PruneEtcdSnapshotsStepBuilder struct {
+	step.Builder[PruneEtcdSnapshotsStepBuilder, *PruneEtcdSnapshotsStep]
+}
+
+func NewPruneEtcdSnapshotsStepBuilder(ctx runtime.ExecutionContext, instanceName string, retain int) *PruneEtcdSnapshotsStepBuilder {
+	s := &PruneEtcdSnapshotsStep{
+		LocalBackupDir: LocalEtcdSnapshotDir(ctx),
+		Retain:         retain,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Keep the newest %d etcd snapshots on the control machine", s.Base.Meta.Name, retain)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = true
+	s.Base.Timeout = time.Minute
+
+	b := new(PruneEtcdSnapshotsStepBuilder).Init(s)
+	return b
+}
+
+func (b *PruneEtcdSnapshotsStepBuilder) WithLocalBackupDir(path string) *PruneEtcdSnapshotsStepBuilder {
+	b.Step.LocalBackupDir = path
+	return b
+}
+
+func (s *PruneEtcdSnapshotsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *PruneEtcdSnapshotsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return s.Retain <= 0, nil
+}
+
+func (s *PruneEtcdSnapshotsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
+
+	removed, err := pruneEtcdSnapshots(s.LocalBackupDir, s.Retain)
+	if err != nil {
+		result.MarkFailed(err, "Failed to prune etcd snapshots")
+		return result, err
+	}
+	for _, path := range removed {
+		logger.Infof("Removed old etcd snapshot %s.", path)
+	}
+	result.MarkCompleted(fmt.Sprintf("Removed %d old snapshot(s)", len(removed)))
+	return result, nil
+}
+
+func (s *PruneEtcdSnapshotsStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+// listEtcdSnapshots returns the snapshots in dir, newest first.
+func listEtcdSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshots []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, etcdSnapshotPrefix) || !strings.HasSuffix(name, etcdSnapshotSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, etcdSnapshotPrefix), etcdSnapshotSuffix)
+		if _, err := time.Parse(etcdSnapshotTimeFormat, stamp); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, filepath.Join(dir, name))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(snapshots)))
+	return snapshots, nil
+}
+
+// pruneEtcdSnapshots removes all but the newest retain snapshots in dir and returns the removed paths.
+func pruneEtcdSnapshots(dir string, retain int) ([]string, error) {
+	if retain <= 0 {
+		return nil, nil
+	}
+	snapshots, err := listEtcdSnapshots(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd snapshots in %s: %w", dir, err)
+	}
+	if len(snapshots) <= retain {
+		return nil, nil
+	}
+	var removed []string
+	for _, path := range snapshots[retain:] {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove etcd snapshot %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+var _ step.Step = (*SaveEtcdSnapshotStep)(nil)
+var _ step.Step = (*PruneEtcdSnapshotsStep)(nil)
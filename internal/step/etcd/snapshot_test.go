@@ -0,0 +1,60 @@
+package etcd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+func TestFindEtcdLeaderEndpoint(t *testing.T) {
+	statuses := []runner.EtcdEndpointStatus{
+		{Endpoint: "https://10.0.0.11:2379", MemberID: 11, LeaderID: 12},
+		{Endpoint: "https://10.0.0.12:2379", MemberID: 12, LeaderID: 12},
+		{Endpoint: "https://10.0.0.13:2379", MemberID: 13, LeaderID: 12},
+	}
+	leader, err := findEtcdLeaderEndpoint(statuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leader != "https://10.0.0.12:2379" {
+		t.Errorf("expected leader https://10.0.0.12:2379, got %s", leader)
+	}
+
+	if _, err := findEtcdLeaderEndpoint([]runner.EtcdEndpointStatus{{Endpoint: "https://10.0.0.11:2379", MemberID: 11}}); err == nil {
+		t.Error("expected an error when no endpoint is the leader")
+	}
+}
+
+func TestPruneEtcdSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var names []string
+	for i := 0; i < 4; i++ {
+		names = append(names, EtcdSnapshotFileName(base.Add(time.Duration(i)*time.Hour)))
+	}
+	for _, name := range append(names, "etcd-snapshot-latest.db", "notes.txt") {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := pruneEtcdSnapshots(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || filepath.Base(removed[0]) != names[1] || filepath.Base(removed[1]) != names[0] {
+		t.Errorf("expected the two oldest snapshots to be removed, got %v", removed)
+	}
+	for _, name := range []string{names[2], names[3], "etcd-snapshot-latest.db", "notes.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be kept: %v", name, err)
+		}
+	}
+
+	if removed, err := pruneEtcdSnapshots(filepath.Join(dir, "missing"), 2); err != nil || len(removed) != 0 {
+		t.Errorf("expected a missing directory to be a no-op, got %v, %v", removed, err)
+	}
+}
@@ -3,11 +3,11 @@ package etcd
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -17,10 +17,11 @@ import (
 
 type WaitClusterHealthyStep struct {
 	step.Base
-	etcdNodes      []remotefw.Host
-	remoteCertsDir string
-	checkTimeout   time.Duration
-	checkInterval  time.Duration
+	etcdNodes         []remotefw.Host
+	etcdctlBinaryPath string
+	remoteCertsDir    string
+	checkTimeout      time.Duration
+	checkInterval     time.Duration
 }
 
 type WaitClusterHealthyStepBuilder struct {
@@ -29,9 +30,10 @@ type WaitClusterHealthyStepBuilder struct {
 
 func NewWaitClusterHealthyStepBuilder(ctx runtime.ExecutionContext, instanceName string) *WaitClusterHealthyStepBuilder {
 	s := &WaitClusterHealthyStep{
-		remoteCertsDir: etcd.DefaultRemoteEtcdCertsDir,
-		checkTimeout:   2 * time.Minute,
-		checkInterval:  5 * time.Second,
+		etcdctlBinaryPath: filepath.Join(common.DefaultBinDir, "etcdctl"),
+		remoteCertsDir:    etcd.DefaultRemoteEtcdCertsDir,
+		checkTimeout:      2 * time.Minute,
+		checkInterval:     5 * time.Second,
 	}
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = "Wait for the etcd cluster to become healthy"
@@ -42,6 +44,11 @@ func NewWaitClusterHealthyStepBuilder(ctx runtime.ExecutionContext, instanceName
 	return b
 }
 
+func (b *WaitClusterHealthyStepBuilder) WithEtcdctlBinaryPath(path string) *WaitClusterHealthyStepBuilder {
+	b.Step.etcdctlBinaryPath = path
+	return b
+}
+
 func (s *WaitClusterHealthyStep) WithCheckTimeout(timeout time.Duration) *WaitClusterHealthyStep {
 	s.checkTimeout = timeout
 	return s
@@ -69,7 +76,6 @@ func (s *WaitClusterHealthyStep) Precheck(ctx runtime.ExecutionContext) (isDone
 func (s *WaitClusterHealthyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
-	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		result.MarkFailed(err, "Failed to get connector")
@@ -80,14 +86,16 @@ func (s *WaitClusterHealthyStep) Run(ctx runtime.ExecutionContext) (*types.StepR
 	for _, node := range s.etcdNodes {
 		endpoints = append(endpoints, fmt.Sprintf("https://%s:2379", node.GetAddress()))
 	}
-	endpointsStr := strings.Join(endpoints, ",")
 	nodeName := ctx.GetHost().GetName()
-	cmd := fmt.Sprintf("etcdctl endpoint health --cluster --endpoints=%s --cacert=%s --cert=%s --key=%s",
-		endpointsStr,
-		filepath.Join(s.remoteCertsDir, common.EtcdCaPemFileName),
-		filepath.Join(s.remoteCertsDir, fmt.Sprintf(common.EtcdAdminCertFileNamePattern, nodeName)),
-		filepath.Join(s.remoteCertsDir, fmt.Sprintf(common.EtcdAdminKeyFileNamePattern, nodeName)),
-	)
+	opts := runner.EtcdctlOptions{
+		EtcdctlPath: s.etcdctlBinaryPath,
+		Endpoints:   endpoints,
+		CACert:      filepath.Join(s.remoteCertsDir, common.EtcdCaPemFileName),
+		Cert:        filepath.Join(s.remoteCertsDir, fmt.Sprintf(common.EtcdAdminCertFileNamePattern, nodeName)),
+		Key:         filepath.Join(s.remoteCertsDir, fmt.Sprintf(common.EtcdAdminKeyFileNamePattern, nodeName)),
+		Cluster:     true,
+		Sudo:        s.Sudo,
+	}
 
 	logger.Infof("Waiting up to %v for etcd cluster to become healthy...", s.checkTimeout)
 
@@ -103,19 +111,15 @@ func (s *WaitClusterHealthyStep) Run(ctx runtime.ExecutionContext) (*types.StepR
 			return result, err
 		case <-ticker.C:
 			logger.Info("Checking etcd cluster health...")
-			runResult, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo)
-			if err != nil {
+			health, err := ctx.GetRunner().EtcdEndpointHealth(ctx.GoContext(), conn, opts)
+			if err != nil && len(health) == 0 {
 				logger.Warnf("Health check command failed: %v. Retrying in %v...", err, s.checkInterval)
 				continue
 			}
 
-			output := runResult.Stdout
-			logger.Debugf("Health check output:\n%s", output)
-
-			lines := strings.Split(strings.TrimSpace(output), "\n")
 			healthyEndpoints := 0
-			for _, line := range lines {
-				if strings.Contains(line, "is healthy") {
+			for _, h := range health {
+				if h.Health {
 					healthyEndpoints++
 				}
 			}
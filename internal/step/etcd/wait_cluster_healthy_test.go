@@ -0,0 +1,48 @@
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+type fakeEtcdHealthRunner struct {
+	runner.Runner
+	opts runner.EtcdctlOptions
+}
+
+func (r *fakeEtcdHealthRunner) EtcdEndpointHealth(ctx context.Context, conn connector.Connector, opts runner.EtcdctlOptions) ([]runner.EtcdEndpointHealth, error) {
+	r.opts = opts
+	return []runner.EtcdEndpointHealth{{Endpoint: opts.Endpoints[0], Health: true}}, nil
+}
+
+func TestWaitClusterHealthyStep_UsesEtcdctlBinaryPath(t *testing.T) {
+	r := &fakeEtcdHealthRunner{}
+	ctx := &runtimetest.Context{
+		Runner: r,
+		Host:   connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "etcd1", Address: "10.0.0.11", Roles: []string{common.RoleEtcd}}),
+	}
+	s, err := NewWaitClusterHealthyStepBuilder(ctx, "WaitEtcdHealthy").WithEtcdctlBinaryPath("/opt/etcd/bin/etcdctl").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+	s.WithCheckInterval(time.Millisecond)
+
+	if _, err := s.Precheck(ctx); err != nil {
+		t.Fatalf("precheck failed: %v", err)
+	}
+	result, err := s.Run(ctx)
+	if result.Status != types.StepStatusCompleted {
+		t.Fatalf("expected status %q, got %q (err: %v)", types.StepStatusCompleted, result.Status, err)
+	}
+	if r.opts.EtcdctlPath != "/opt/etcd/bin/etcdctl" {
+		t.Errorf("expected etcdctl path /opt/etcd/bin/etcdctl, got %q", r.opts.EtcdctlPath)
+	}
+}
@@ -83,6 +83,9 @@ var componentBinaryBOMs = map[string][]ComponentBinaryBOM{
 	ComponentYq: {
 		{KubeVersionConstraints: ">= 0.0.0", Version: "v4.44.1"},
 	},
+	ComponentSonobuoy: {
+		{KubeVersionConstraints: ">= 0.0.0", Version: "v0.57.2"},
+	},
 }
 
 // GetBinaryVersionFromBOM 根据 K8s 版本从 BOM 中查找推荐的组件版本。
@@ -15,60 +15,85 @@ var defaultKnownBinaryDetails = map[string]BinaryDetailSpec{
 		DefaultOS:        "linux",
 	},
 	ComponentKubeadm: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubeadm",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubeadm",
-		FileNameTemplate: "kubeadm",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubeadm",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubeadm",
+		FileNameTemplate:  "kubeadm",
+		IsArchive:         false,
+		DefaultOS:         "linux",
 	},
 	ComponentKubelet: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubelet",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubelet",
-		FileNameTemplate: "kubelet",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubelet",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubelet",
+		FileNameTemplate:  "kubelet",
+		IsArchive:         false,
+		DefaultOS:         "linux",
+	},
+	ComponentKubeadmWindows: {
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubeadm.exe",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubeadm.exe",
+		FileNameTemplate:  "kubeadm.exe",
+		IsArchive:         false,
+		DefaultOS:         "windows",
+	},
+	ComponentKubeletWindows: {
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubelet.exe",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubelet.exe",
+		FileNameTemplate:  "kubelet.exe",
+		IsArchive:         false,
+		DefaultOS:         "windows",
 	},
 	ComponentKubectl: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
-		FileNameTemplate: "kubectl",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kubectl",
+		FileNameTemplate:  "kubectl",
+		IsArchive:         false,
+		DefaultOS:         "linux",
 	},
 	ComponentKubeProxy: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-proxy",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-proxy",
-		FileNameTemplate: "kube-proxy",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-proxy",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-proxy",
+		FileNameTemplate:  "kube-proxy",
+		IsArchive:         false,
+		DefaultOS:         "linux",
 	},
 	ComponentKubeScheduler: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-scheduler",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-scheduler",
-		FileNameTemplate: "kube-scheduler",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-scheduler",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-scheduler",
+		FileNameTemplate:  "kube-scheduler",
+		IsArchive:         false,
+		DefaultOS:         "linux",
 	},
 	ComponentKubeControllerManager: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-controller-manager",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-controller-manager",
-		FileNameTemplate: "kube-controller-manager",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-controller-manager",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-controller-manager",
+		FileNameTemplate:  "kube-controller-manager",
+		IsArchive:         false,
+		DefaultOS:         "linux",
 	},
 	ComponentKubeApiServer: {
-		BinaryType:       KUBE,
-		URLTemplate:      "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-apiserver",
-		CNURLTemplate:    "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-apiserver",
-		FileNameTemplate: "kube-apiserver",
-		IsArchive:        false,
-		DefaultOS:        "linux",
+		BinaryType:        KUBE,
+		URLTemplate:       "https://dl.k8s.io/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-apiserver",
+		ChecksumURLSuffix: ".sha256",
+		CNURLTemplate:     "https://kubernetes-release.pek3b.qingstor.com/release/{{.Version}}/bin/{{.OS}}/{{.Arch}}/kube-apiserver",
+		FileNameTemplate:  "kube-apiserver",
+		IsArchive:         false,
+		DefaultOS:         "linux",
 	},
 	ComponentKubeCNI: {
 		BinaryType:       CNI,
@@ -79,12 +104,13 @@ var defaultKnownBinaryDetails = map[string]BinaryDetailSpec{
 		DefaultOS:        "linux",
 	},
 	ComponentHelm: {
-		BinaryType:       HELM,
-		URLTemplate:      "https://get.helm.sh/helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
-		CNURLTemplate:    "https://kubernetes-helm.pek3b.qingstor.com/linux-{{.Arch}}/{{.Version}}/helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
-		FileNameTemplate: "helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
-		IsArchive:        true,
-		DefaultOS:        "linux",
+		BinaryType:        HELM,
+		URLTemplate:       "https://get.helm.sh/helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
+		ChecksumURLSuffix: ".sha256sum",
+		CNURLTemplate:     "https://kubernetes-helm.pek3b.qingstor.com/linux-{{.Arch}}/{{.Version}}/helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
+		FileNameTemplate:  "helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
+		IsArchive:         true,
+		DefaultOS:         "linux",
 	},
 	ComponentDocker: {
 		BinaryType:          DOCKER,
@@ -203,6 +229,13 @@ var defaultKnownBinaryDetails = map[string]BinaryDetailSpec{
 		IsArchive:        false,
 		DefaultOS:        "linux",
 	},
+	ComponentSonobuoy: {
+		BinaryType:       TOOLS,
+		URLTemplate:      "https://github.com/vmware-tanzu/sonobuoy/releases/download/{{.Version}}/sonobuoy_{{.VersionNoV}}_{{.OS}}_{{.Arch}}.tar.gz",
+		FileNameTemplate: "sonobuoy_{{.VersionNoV}}_{{.OS}}_{{.Arch}}.tar.gz",
+		IsArchive:        true,
+		DefaultOS:        "linux",
+	},
 	ComponentCrio: {
 		BinaryType:          CRIO,
 		URLTemplate:         "https://storage.googleapis.com/cri-o/artifacts/cri-o.{{.Arch}}.v{{.VersionNoV}}.tar.gz",
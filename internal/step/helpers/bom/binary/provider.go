@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 )
@@ -53,6 +54,7 @@ func (p *BinaryProvider) GetBinary(name, arch string) (*Binary, error) {
 	} else {
 		switch name {
 		case ComponentKubeadm, ComponentKubelet, ComponentKubectl, ComponentKubeProxy,
+			ComponentKubeadmWindows, ComponentKubeletWindows,
 			ComponentKubeScheduler, ComponentKubeControllerManager, ComponentKubeApiServer,
 			ComponentK3s, ComponentK8e:
 			finalVersion = kubeVersion
@@ -84,6 +86,7 @@ func (p *BinaryProvider) GetBinary(name, arch string) (*Binary, error) {
 		Version:       finalVersion,
 		Arch:          arch,
 		Zone:          GetZone(),
+		sources:       p.getSources(name),
 		meta:          meta,
 		workDir:       p.ctx.GetGlobalWorkDir(),
 		clusterName:   cfg.Name,
@@ -114,6 +117,18 @@ func (p *BinaryProvider) getManagedBinaryNames() []string {
 	return names
 }
 
+// getSources 返回用户为组件配置的下载源，组件级配置优先于 default，未配置时返回 nil 由 Zone 决定。
+func (p *BinaryProvider) getSources(name string) []v1alpha1.BinarySource {
+	cfg := p.ctx.GetClusterConfig().Spec.BinarySources
+	if cfg == nil {
+		return nil
+	}
+	if sources, ok := cfg.Components[name]; ok && len(sources) > 0 {
+		return sources
+	}
+	return cfg.Default
+}
+
 // getUserSpecifiedVersion 从 ClusterConfig 中获取用户为特定组件指定的版本。
 func (p *BinaryProvider) getUserSpecifiedVersion(name string) string {
 	cfg := p.ctx.GetClusterConfig().Spec
@@ -182,7 +197,10 @@ func (p *BinaryProvider) isBinaryEnabled(name string) (bool, error) {
 		return strings.EqualFold(cfg.Etcd.Type, string(common.EtcdDeploymentTypeKubexm)), nil
 
 	case ComponentKubeProxy:
-		return cfg.Kubernetes.KubeProxy == nil || cfg.Kubernetes.KubeProxy.Enable == nil || *cfg.Kubernetes.KubeProxy.Enable, nil
+		return v1alpha1.KubeProxyEnabled(cfg), nil
+
+	case ComponentKubeadmWindows, ComponentKubeletWindows:
+		return v1alpha1.HasWindowsHosts(cfg), nil
 
 	// --- 容器运行时相关 ---
 	case ComponentContainerd, ComponentRunc, ComponentCriCtl:
@@ -212,7 +230,7 @@ func (p *BinaryProvider) isBinaryEnabled(name string) (bool, error) {
 		return cfg.Network.Plugin == string(common.CNITypeCalico), nil
 
 	// --- 工具链, 通常总是需要下载以备不时之需 ---
-	case ComponentHelm, ComponentCompose, ComponentBuildx, ComponentJq, ComponentYq:
+	case ComponentHelm, ComponentCompose, ComponentBuildx, ComponentJq, ComponentYq, ComponentSonobuoy:
 		return true, nil
 
 	// --- 本地部署的应用 ---
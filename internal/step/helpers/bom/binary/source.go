@@ -0,0 +1,121 @@
+package binary
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/util"
+)
+
+// DownloadCandidate 是某个下载源给出的一个下载地址。
+type DownloadCandidate struct {
+	// Source 是下载源类型，如 official、aliyun。
+	Source string
+	URL    string
+	// ChecksumURL 是该下载源提供的校验和文件地址，为空表示该下载源不提供。
+	ChecksumURL string
+}
+
+// mirrorURLTemplates 是各公共镜像站为组件提供的下载地址模板。
+// 镜像站只同步了部分组件，不在表中的组件会跳过该下载源。
+var mirrorURLTemplates = map[string]map[string]string{
+	common.BinarySourceAliyun: {
+		ComponentDocker: "https://mirrors.aliyun.com/docker-ce/linux/static/stable/{{.ArchAlias}}/docker-{{.VersionNoV}}.tgz",
+	},
+	common.BinarySourceHuawei: {
+		ComponentDocker: "https://mirrors.huaweicloud.com/docker-ce/linux/static/stable/{{.ArchAlias}}/docker-{{.VersionNoV}}.tgz",
+		ComponentHelm:   "https://mirrors.huaweicloud.com/helm/{{.Version}}/helm-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
+		ComponentEtcd:   "https://mirrors.huaweicloud.com/etcd/{{.Version}}/etcd-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz",
+	},
+}
+
+// Sources 返回按顺序尝试的下载源。未配置 binarySources 时，KXZONE=cn 先尝试 cn 再回退到 official。
+func (b *Binary) Sources() []v1alpha1.BinarySource {
+	if len(b.sources) > 0 {
+		return b.sources
+	}
+	if strings.ToLower(b.Zone) == "cn" {
+		return []v1alpha1.BinarySource{{Type: common.BinarySourceCN}, {Type: common.BinarySourceOfficial}}
+	}
+	return []v1alpha1.BinarySource{{Type: common.BinarySourceOfficial}}
+}
+
+// DownloadCandidates 按下载源顺序返回所有可用的下载地址，不提供该组件的下载源会被跳过。
+func (b *Binary) DownloadCandidates() []DownloadCandidate {
+	var candidates []DownloadCandidate
+	seen := make(map[string]bool)
+	for _, src := range b.Sources() {
+		c, ok := b.candidateFor(src)
+		if !ok || seen[c.URL] {
+			continue
+		}
+		seen[c.URL] = true
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+func (b *Binary) candidateFor(src v1alpha1.BinarySource) (DownloadCandidate, bool) {
+	c := DownloadCandidate{Source: src.Type}
+	baseURL := strings.TrimRight(src.BaseURL, "/")
+
+	switch src.Type {
+	case common.BinarySourceOfficial:
+		c.URL = b.render(b.meta.URLTemplate)
+		c.ChecksumURL = b.officialChecksumURL()
+	case common.BinarySourceCN:
+		c.URL = b.render(b.meta.CNURLTemplate)
+	case common.BinarySourceAliyun, common.BinarySourceHuawei:
+		c.URL = b.render(mirrorURLTemplates[src.Type][b.ComponentName])
+	case common.BinarySourceCustom:
+		if baseURL == "" {
+			return c, false
+		}
+		c.URL = rebaseURL(baseURL, b.render(b.meta.URLTemplate))
+		c.ChecksumURL = rebaseURL(baseURL, b.officialChecksumURL())
+	case common.BinarySourceLocal:
+		if baseURL == "" {
+			return c, false
+		}
+		relDir := filepath.ToSlash(b.componentDirUnder(""))
+		c.URL = baseURL + "/" + path.Join(relDir, b.FileName())
+		c.ChecksumURL = c.URL + common.BinaryChecksumFileSuffix
+	}
+	return c, c.URL != ""
+}
+
+func (b *Binary) render(tmpl string) string {
+	if tmpl == "" {
+		return ""
+	}
+	rendered, _ := util.RenderTemplate(tmpl, b.templateData())
+	return rendered
+}
+
+func (b *Binary) officialChecksumURL() string {
+	if b.meta.ChecksumURLSuffix == "" {
+		return ""
+	}
+	officialURL := b.render(b.meta.URLTemplate)
+	if officialURL == "" {
+		return ""
+	}
+	return officialURL + b.meta.ChecksumURLSuffix
+}
+
+// rebaseURL 把上游地址的 host 和 path 挂到 baseURL 之下，例如
+// https://dl.k8s.io/release/... 变为 <baseURL>/dl.k8s.io/release/...，便于一个代理同时镜像多个上游。
+func rebaseURL(baseURL, upstream string) string {
+	if upstream == "" {
+		return ""
+	}
+	u, err := url.Parse(upstream)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return baseURL + "/" + u.Host + u.Path
+}
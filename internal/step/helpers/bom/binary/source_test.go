@@ -0,0 +1,95 @@
+package binary
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+func TestBinary_DownloadCandidates(t *testing.T) {
+	newBinary := func(name, version, zone string, sources ...v1alpha1.BinarySource) *Binary {
+		return &Binary{
+			ComponentName: name,
+			Version:       version,
+			Arch:          "amd64",
+			Zone:          zone,
+			sources:       sources,
+			meta:          defaultKnownBinaryDetails[name],
+			workDir:       "/opt/kubexm",
+			clusterName:   "demo",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		binary *Binary
+		want   []DownloadCandidate
+	}{
+		{
+			name:   "official by default with published checksum",
+			binary: newBinary(ComponentKubelet, "v1.30.4", ""),
+			want: []DownloadCandidate{{
+				Source:      common.BinarySourceOfficial,
+				URL:         "https://dl.k8s.io/release/v1.30.4/bin/linux/amd64/kubelet",
+				ChecksumURL: "https://dl.k8s.io/release/v1.30.4/bin/linux/amd64/kubelet.sha256",
+			}},
+		},
+		{
+			name:   "cn zone falls back to official",
+			binary: newBinary(ComponentEtcd, "v3.5.13", "cn"),
+			want: []DownloadCandidate{
+				{Source: common.BinarySourceCN, URL: "https://kubernetes-release.pek3b.qingstor.com/etcd/release/download/v3.5.13/etcd-v3.5.13-linux-amd64.tar.gz"},
+				{Source: common.BinarySourceOfficial, URL: "https://github.com/coreos/etcd/releases/download/v3.5.13/etcd-v3.5.13-linux-amd64.tar.gz"},
+			},
+		},
+		{
+			name: "mirror without the component is skipped",
+			binary: newBinary(ComponentHelm, "v3.14.4", "",
+				v1alpha1.BinarySource{Type: common.BinarySourceAliyun},
+				v1alpha1.BinarySource{Type: common.BinarySourceHuawei},
+			),
+			want: []DownloadCandidate{
+				{Source: common.BinarySourceHuawei, URL: "https://mirrors.huaweicloud.com/helm/v3.14.4/helm-v3.14.4-linux-amd64.tar.gz"},
+			},
+		},
+		{
+			name: "custom rebases upstream host and path",
+			binary: newBinary(ComponentHelm, "v3.14.4", "",
+				v1alpha1.BinarySource{Type: common.BinarySourceCustom, BaseURL: "https://nexus.example.com/repository/raw/"},
+			),
+			want: []DownloadCandidate{{
+				Source:      common.BinarySourceCustom,
+				URL:         "https://nexus.example.com/repository/raw/get.helm.sh/helm-v3.14.4-linux-amd64.tar.gz",
+				ChecksumURL: "https://nexus.example.com/repository/raw/get.helm.sh/helm-v3.14.4-linux-amd64.tar.gz.sha256sum",
+			}},
+		},
+		{
+			name: "local follows the work directory layout",
+			binary: newBinary(ComponentContainerd, "1.7.13", "",
+				v1alpha1.BinarySource{Type: common.BinarySourceLocal, BaseURL: "http://10.0.0.1:8080"},
+				v1alpha1.BinarySource{Type: common.BinarySourceOfficial},
+			),
+			want: []DownloadCandidate{
+				{
+					Source:      common.BinarySourceLocal,
+					URL:         "http://10.0.0.1:8080/container_runtime/containerd/1.7.13/amd64/containerd-1.7.13-linux-amd64.tar.gz",
+					ChecksumURL: "http://10.0.0.1:8080/container_runtime/containerd/1.7.13/amd64/containerd-1.7.13-linux-amd64.tar.gz.sha256",
+				},
+				{Source: common.BinarySourceOfficial, URL: "https://github.com/containerd/containerd/releases/download/v1.7.13/containerd-1.7.13-linux-amd64.tar.gz"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.binary.DownloadCandidates()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DownloadCandidates() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			if tt.binary.URL() != tt.want[0].URL {
+				t.Errorf("URL() = %s, want the first candidate %s", tt.binary.URL(), tt.want[0].URL)
+			}
+		})
+	}
+}
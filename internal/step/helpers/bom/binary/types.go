@@ -1,6 +1,7 @@
 package binary
 
 import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/util" // 确保引入您的 util 包
 	"path/filepath"
@@ -35,6 +36,8 @@ const (
 	ComponentEtcd                  = "etcd"
 	ComponentKubeadm               = "kubeadm"
 	ComponentKubelet               = "kubelet"
+	ComponentKubeadmWindows        = "kubeadm-windows"
+	ComponentKubeletWindows        = "kubelet-windows"
 	ComponentKubectl               = "kubectl"
 	ComponentKubeProxy             = "kube-proxy"
 	ComponentKubeScheduler         = "kube-scheduler"
@@ -58,6 +61,7 @@ const (
 	ComponentBuildx                = "buildx"
 	ComponentJq                    = "jq"
 	ComponentYq                    = "yq"
+	ComponentSonobuoy              = "sonobuoy"
 )
 
 const (
@@ -75,6 +79,8 @@ type BinaryDetailSpec struct {
 	IsArchive           bool
 	DefaultOS           string
 	ComponentNameForDir string
+	// ChecksumURLSuffix 追加到官方下载地址后即为上游发布的校验和文件地址，为空表示上游不提供。
+	ChecksumURLSuffix string
 }
 
 // --- 核心对象模型: Binary ---
@@ -88,6 +94,8 @@ type Binary struct {
 	Arch          string
 	Zone          string
 	checksum      string
+	// sources 是按顺序尝试的下载源，为空时按 Zone 选择默认下载源。
+	sources []v1alpha1.BinarySource
 
 	// --- 元数据 (从 details map 注入) ---
 	meta BinaryDetailSpec
@@ -99,14 +107,13 @@ type Binary struct {
 
 // --- 公共方法 ---
 
-// URL 返回计算出的最终下载地址。
+// URL 返回首选下载源的下载地址，其余下载源见 DownloadCandidates。
 func (b *Binary) URL() string {
-	urlTmpl := b.meta.URLTemplate
-	if strings.ToLower(b.Zone) == "cn" && b.meta.CNURLTemplate != "" {
-		urlTmpl = b.meta.CNURLTemplate
+	candidates := b.DownloadCandidates()
+	if len(candidates) == 0 {
+		return ""
 	}
-	url, _ := util.RenderTemplate(urlTmpl, b.templateData())
-	return url
+	return candidates[0].URL
 }
 
 // FileName 返回计算出的最终文件名。
@@ -179,7 +186,11 @@ func (b *Binary) componentDir() string {
 	}
 	//kubexmRoot := filepath.Join(b.workDir, common.KubexmRootDirName)
 	//clusterBaseDir := filepath.Join(b.workDir, b.clusterName)
-	clusterBaseDir := b.workDir
+	return b.componentDirUnder(b.workDir)
+}
+
+// componentDirUnder 计算组件在 clusterBaseDir 下的存储目录，本地文件服务器沿用同样的目录布局。
+func (b *Binary) componentDirUnder(clusterBaseDir string) string {
 	var typeSpecificBaseDir string
 
 	switch b.meta.BinaryType {
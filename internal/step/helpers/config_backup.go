@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+const (
+	configBackupSuffix = ".bak"
+	// configBackupTimeFormat sorts lexically in chronological order and is safe in file names.
+	configBackupTimeFormat = "2006-01-02T15-04-05.000000000"
+)
+
+// ConfigBackupPath returns the timestamped backup path for a config file,
+// e.g. /etc/containerd/config.toml.2024-05-01T10-00-00.000000000.bak.
+func ConfigBackupPath(path string, t time.Time) string {
+	return fmt.Sprintf("%s.%s%s", path, t.UTC().Format(configBackupTimeFormat), configBackupSuffix)
+}
+
+// BackupRemoteConfig copies an existing remote config file to a timestamped backup next to it
+// and prunes the oldest backups so that at most retention remain. A retention of zero disables
+// backups. It returns the path of the new backup, or an empty string if nothing was backed up.
+func BackupRemoteConfig(ctx runtime.ExecutionContext, conn runner.Connector, path string, retention int, sudo bool) (string, error) {
+	if retention <= 0 {
+		return "", nil
+	}
+	runnerSvc := ctx.GetRunner()
+	exists, err := runnerSvc.Exists(ctx.GoContext(), conn, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for config file %s: %w", path, err)
+	}
+	if !exists {
+		return "", nil
+	}
+
+	backupPath := ConfigBackupPath(path, time.Now())
+	if err := runnerSvc.CopyFile(ctx.GoContext(), conn, path, backupPath, false, sudo); err != nil {
+		return "", fmt.Errorf("failed to back up %s to %s: %w", path, backupPath, err)
+	}
+
+	backups, err := ListRemoteConfigBackups(ctx, conn, path, sudo)
+	if err != nil {
+		return backupPath, err
+	}
+	for _, stale := range configBackupsToPrune(backups, retention) {
+		if err := runnerSvc.Remove(ctx.GoContext(), conn, stale, sudo, false); err != nil {
+			return backupPath, fmt.Errorf("failed to prune config backup %s: %w", stale, err)
+		}
+	}
+	return backupPath, nil
+}
+
+// ListRemoteConfigBackups returns the backups of a remote config file, newest first.
+func ListRemoteConfigBackups(ctx runtime.ExecutionContext, conn runner.Connector, path string, sudo bool) ([]string, error) {
+	dir := filepath.Dir(path)
+	result, err := ctx.GetRunner().Run(ctx.GoContext(), conn, fmt.Sprintf("ls -1 %s", dir), sudo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config backups in %s: %w", dir, err)
+	}
+	return parseConfigBackups(path, strings.Split(result.Stdout, "\n")), nil
+}
+
+// RestoreRemoteConfigBackup copies a backup previously created by BackupRemoteConfig over the config file.
+func RestoreRemoteConfigBackup(ctx runtime.ExecutionContext, conn runner.Connector, path, backupPath string, sudo bool) error {
+	if len(parseConfigBackups(path, []string{filepath.Base(backupPath)})) == 0 || filepath.Dir(backupPath) != filepath.Dir(path) {
+		return fmt.Errorf("%s is not a backup of %s", backupPath, path)
+	}
+	runnerSvc := ctx.GetRunner()
+	exists, err := runnerSvc.Exists(ctx.GoContext(), conn, backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to check for config backup %s: %w", backupPath, err)
+	}
+	if !exists {
+		return fmt.Errorf("config backup %s does not exist", backupPath)
+	}
+	if err := runnerSvc.CopyFile(ctx.GoContext(), conn, backupPath, path, false, sudo); err != nil {
+		return fmt.Errorf("failed to restore %s from %s: %w", path, backupPath, err)
+	}
+	return nil
+}
+
+// parseConfigBackups picks the backups of path out of a directory listing and returns their
+// full paths, newest first.
+func parseConfigBackups(path string, names []string) []string {
+	dir := filepath.Dir(path)
+	prefix := filepath.Base(path) + "."
+	var backups []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, configBackupSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), configBackupSuffix)
+		if _, err := time.Parse(configBackupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups
+}
+
+// configBackupsToPrune returns the backups beyond the retention count. backups must be newest first.
+func configBackupsToPrune(backups []string, retention int) []string {
+	if retention <= 0 || len(backups) <= retention {
+		return nil
+	}
+	return backups[retention:]
+}
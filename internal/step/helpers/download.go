@@ -0,0 +1,218 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mensylisir/kubexm/internal/asset/store"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
+)
+
+// DownloadBinary downloads b to b.FilePath(), trying its download candidates in source order. The
+// expected checksum is the BOM checksum, or else the one published by the source; a candidate that
+// fails to download or does not match it is skipped in favour of the next one.
+func DownloadBinary(ctx context.Context, log *logger.Logger, b *binary.Binary, showProgress bool) error {
+	destPath := b.FilePath()
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory '%s': %w", filepath.Dir(destPath), err)
+	}
+
+	cache := artifactCache(log)
+	ref := binaryCacheRef(b)
+	if cache != nil && linkFromCache(log, cache, b, ref, destPath) {
+		return nil
+	}
+
+	candidates := b.DownloadCandidates()
+	if len(candidates) == 0 {
+		return fmt.Errorf("no download source provides %s %s", b.ComponentName, b.Version)
+	}
+
+	var failures []string
+	for _, c := range candidates {
+		err := downloadCandidate(ctx, log, b, c, destPath, showProgress)
+		if err == nil {
+			removePartialDownloads(destPath, candidates)
+			if cache != nil {
+				if _, err := cache.Put(destPath, ref); err != nil {
+					log.Warnf("Failed to add %s to the artifact cache: %v", b.FileName(), err)
+				}
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Warnf("Failed to download %s from %s source %s: %v", b.FileName(), c.Source, c.URL, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", c.Source, err))
+	}
+	return fmt.Errorf("failed to download %s from every source: %s", b.FileName(), strings.Join(failures, "; "))
+}
+
+func downloadCandidate(ctx context.Context, log *logger.Logger, b *binary.Binary, c binary.DownloadCandidate, destPath string, showProgress bool) error {
+	expected := b.Checksum()
+	if expected == "" && c.ChecksumURL != "" {
+		checksum, err := fetchChecksum(ctx, c.ChecksumURL, b.FileName())
+		if err != nil {
+			log.Warnf("Failed to fetch checksum for %s from %s, verification will be skipped: %v", b.FileName(), c.ChecksumURL, err)
+		}
+		expected = checksum
+	}
+
+	// Partial downloads are kept per source so a later run can resume them.
+	tmpPath := partialDownloadPath(destPath, c.Source)
+	log.Debugf("Downloading %s from %s source %s", b.FileName(), c.Source, c.URL)
+	if err := fetchFile(ctx, log, c.URL, tmpPath, showProgress); err != nil {
+		return err
+	}
+
+	match, err := VerifyLocalFileChecksum(tmpPath, expected)
+	if err != nil {
+		return fmt.Errorf("failed to verify checksum after download: %w", err)
+	}
+	if !match {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("checksum mismatch, expected '%s'", expected)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return fmt.Errorf("failed to move download to '%s': %w", destPath, err)
+	}
+	if expected != "" {
+		log.Debugf("Checksum of %s verified against %s.", b.FileName(), expected)
+	}
+	return nil
+}
+
+var (
+	artifactCacheOnce  sync.Once
+	artifactCacheStore *store.Store
+)
+
+// artifactCache returns the artifact cache shared by all runs and clusters, or nil if it cannot
+// be opened, in which case binaries are simply downloaded.
+func artifactCache(log *logger.Logger) *store.Store {
+	artifactCacheOnce.Do(func() {
+		s, err := store.OpenDefault()
+		if err != nil {
+			log.Warnf("Artifact cache is disabled: %v", err)
+			return
+		}
+		artifactCacheStore = s
+	})
+	return artifactCacheStore
+}
+
+func binaryCacheRef(b *binary.Binary) string {
+	return path.Join("binary", b.ComponentName, b.Version, b.Arch, b.FileName())
+}
+
+// linkFromCache places a cached copy of b at destPath. A BOM checksum is looked up by digest; without
+// one, the blob last downloaded for the same component, version, arch and file name is used.
+func linkFromCache(log *logger.Logger, cache *store.Store, b *binary.Binary, ref, destPath string) bool {
+	digest := b.Checksum()
+	if digest == "" || strings.HasPrefix(digest, "dummy-") {
+		entry, ok := cache.Lookup(ref)
+		if !ok {
+			return false
+		}
+		digest = entry.Digest
+	} else if !cache.Has(digest) {
+		return false
+	}
+	if err := cache.Link(digest, destPath, ref); err != nil {
+		log.Warnf("Failed to use cached %s, downloading it instead: %v", b.FileName(), err)
+		return false
+	}
+	log.Infof("Using cached %s (sha256:%s) from %s", b.FileName(), digest, cache.Root())
+	return true
+}
+
+// removePartialDownloads drops partial downloads other sources left behind once the file is complete.
+func removePartialDownloads(destPath string, candidates []binary.DownloadCandidate) {
+	for _, c := range candidates {
+		tmpPath := partialDownloadPath(destPath, c.Source)
+		_ = os.Remove(tmpPath)
+		_ = os.Remove(tmpPath + segmentStateSuffix)
+	}
+}
+
+func partialDownloadPath(destPath, source string) string {
+	return destPath + ".part-" + source
+}
+
+func fetchChecksum(ctx context.Context, checksumURL, fileName string) (string, error) {
+	resp, err := httpGet(ctx, checksumURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	checksum, err := ParseChecksumManifest(io.LimitReader(resp.Body, 1<<20), fileName)
+	if err != nil {
+		return "", err
+	}
+	if checksum == "" {
+		return "", fmt.Errorf("no checksum for %s in %s", fileName, checksumURL)
+	}
+	return checksum, nil
+}
+
+func httpGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed with status code %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// ParseChecksumManifest returns the sha256 of fileName from a checksum manifest. It accepts a bare
+// hash, as published next to each Kubernetes binary, and sha256sum output with one
+// "<hash>  <file>" line per file. It returns "" if the manifest has no entry for fileName.
+func ParseChecksumManifest(r io.Reader, fileName string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 1 && isSHA256(fields[0]):
+			return strings.ToLower(fields[0]), nil
+		case len(fields) >= 2 && isSHA256(fields[0]):
+			name := strings.TrimPrefix(fields[len(fields)-1], "*")
+			if path.Base(name) == fileName {
+				return strings.ToLower(fields[0]), nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksum manifest: %w", err)
+	}
+	return "", nil
+}
+
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
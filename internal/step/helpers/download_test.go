@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChecksumManifest(t *testing.T) {
+	const (
+		sum1 = "9b3c4853a19e5590916a815a5195b05a76953f938f30997b6a6556e40d0469b8"
+		sum2 = "422e54143a41198642a8a5e3a804e8d3809618059048a946e537c093a127a518"
+	)
+	tests := []struct {
+		name     string
+		manifest string
+		fileName string
+		want     string
+	}{
+		{name: "bare hash", manifest: sum1 + "\n", fileName: "kubelet", want: sum1},
+		{name: "sha256sum line", manifest: sum1 + "  helm-v3.14.4-linux-amd64.tar.gz\n", fileName: "helm-v3.14.4-linux-amd64.tar.gz", want: sum1},
+		{
+			name:     "multi-file manifest with binary marker and paths",
+			manifest: sum1 + "  etcd-v3.5.13-linux-arm64.tar.gz\n" + strings.ToUpper(sum2) + " *release/etcd-v3.5.13-linux-amd64.tar.gz\n",
+			fileName: "etcd-v3.5.13-linux-amd64.tar.gz",
+			want:     sum2,
+		},
+		{name: "no entry for file", manifest: sum1 + "  other.tar.gz\n", fileName: "kubelet", want: ""},
+		{name: "not a checksum", manifest: "<html>404</html>\n", fileName: "kubelet", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChecksumManifest(strings.NewReader(tt.manifest), tt.fileName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
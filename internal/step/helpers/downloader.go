@@ -0,0 +1,389 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/logger"
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/sync/errgroup"
+)
+
+// Tunables of fetchFile; variables so tests can use small files.
+var (
+	downloadSegmentMinSize   int64 = 16 << 20
+	downloadSegments               = 4
+	downloadRetries                = 3
+	downloadRetryBackoff           = 2 * time.Second
+	downloadProgressInterval       = 10 * time.Second
+)
+
+// downloadSlots limits the number of HTTP streams open at once across all downloads, so parallel
+// steps each splitting their files into segments do not flood the network.
+var downloadSlots = make(chan struct{}, common.DefaultDownloadConcurrency)
+
+func acquireDownloadSlot(ctx context.Context) (release func(), err error) {
+	select {
+	case downloadSlots <- struct{}{}:
+		return func() { <-downloadSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// downloadProgress counts downloaded bytes from all segments of one file and reports them to the
+// progress bar, if any, and periodically to the logger.
+type downloadProgress struct {
+	name  string
+	total int64
+	done  atomic.Int64
+	bar   *progressbar.ProgressBar
+}
+
+func (p *downloadProgress) add(n int) {
+	p.done.Add(int64(n))
+	if p.bar != nil {
+		_ = p.bar.Add(n)
+	}
+}
+
+func (p *downloadProgress) report(ctx context.Context, log *logger.Logger) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(downloadProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				got := p.done.Load()
+				if p.total > 0 {
+					log.Infof("Downloading %s: %d%% (%d/%d MiB)", p.name, got*100/p.total, got>>20, p.total>>20)
+				} else {
+					log.Infof("Downloading %s: %d MiB", p.name, got>>20)
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// countingWriter writes to w and counts the bytes into a downloadProgress.
+type countingWriter struct {
+	w        io.Writer
+	progress *downloadProgress
+	written  *int64
+}
+
+func (pw *countingWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.progress.add(n)
+	atomic.AddInt64(pw.written, int64(n))
+	return n, err
+}
+
+// fetchFile downloads url to dest. Servers that accept byte ranges get large files fetched in
+// parallel segments; otherwise a single stream is used. Failed streams are retried from where they
+// stopped, and a partial dest left by an earlier run is resumed rather than downloaded again.
+func fetchFile(ctx context.Context, log *logger.Logger, url, dest string, showProgress bool) error {
+	size, ranged := probeDownload(ctx, url)
+
+	progress := &downloadProgress{name: filepath.Base(dest), total: size}
+	if showProgress {
+		progress.bar = progressbar.NewOptions64(
+			size,
+			progressbar.OptionSetDescription(fmt.Sprintf("Downloading %s", filepath.Base(dest))),
+			progressbar.OptionSetWriter(os.Stderr),
+			progressbar.OptionShowBytes(true),
+			progressbar.OptionSetWidth(40),
+			progressbar.OptionThrottle(65*time.Millisecond),
+			progressbar.OptionOnCompletion(func() { fmt.Fprint(os.Stderr, "\n") }),
+			progressbar.OptionSpinnerType(14),
+			progressbar.OptionFullWidth(),
+		)
+	}
+	stop := progress.report(ctx, log)
+	defer stop()
+
+	var err error
+	if ranged && size >= downloadSegmentMinSize && downloadSegments > 1 {
+		log.Debugf("Downloading %s in %d segments of %s", url, downloadSegments, dest)
+		err = fetchSegmented(ctx, log, url, dest, size, progress)
+	} else {
+		err = fetchStream(ctx, log, url, dest, ranged, progress)
+	}
+	if progress.bar != nil {
+		if err != nil {
+			_ = progress.bar.Clear()
+		} else {
+			_ = progress.bar.Finish()
+		}
+	}
+	return err
+}
+
+// probeDownload returns the size of url and whether the server serves byte ranges for it. A failed
+// probe is not an error: the download then falls back to a single stream.
+func probeDownload(ctx context.Context, url string) (int64, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return -1, false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return -1, false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return -1, false
+	}
+	return resp.ContentLength, resp.ContentLength > 0 && resp.Header.Get("Accept-Ranges") == "bytes"
+}
+
+// fetchStream downloads url over one stream, appending to a partial dest with a range request when
+// the server supports it.
+func fetchStream(ctx context.Context, log *logger.Logger, url, dest string, ranged bool, progress *downloadProgress) error {
+	release, err := acquireDownloadSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var lastErr error
+	for attempt := 0; attempt <= downloadRetries; attempt++ {
+		if attempt > 0 {
+			log.Warnf("Retrying download of %s (attempt %d/%d): %v", url, attempt, downloadRetries, lastErr)
+			if err := sleepContext(ctx, downloadRetryBackoff*time.Duration(attempt)); err != nil {
+				return err
+			}
+		}
+		lastErr = fetchStreamOnce(ctx, url, dest, ranged, progress)
+		if lastErr == nil || ctx.Err() != nil {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+func fetchStreamOnce(ctx context.Context, url, dest string, ranged bool, progress *downloadProgress) error {
+	var offset int64
+	if fi, err := os.Stat(dest); err == nil && ranged {
+		offset = fi.Size()
+	}
+	if progress.total > 0 && offset == progress.total {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, or there was nothing to resume: start over.
+		flags |= os.O_TRUNC
+		offset = 0
+	default:
+		return fmt.Errorf("download failed with status code %d", resp.StatusCode)
+	}
+
+	out, err := os.OpenFile(dest, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer out.Close()
+
+	progress.done.Store(offset)
+	if progress.bar != nil {
+		_ = progress.bar.Set64(offset)
+	}
+	var written int64
+	if _, err := io.Copy(&countingWriter{w: out, progress: progress, written: &written}, resp.Body); err != nil {
+		return fmt.Errorf("failed to write to destination file: %w", err)
+	}
+	return out.Close()
+}
+
+const segmentStateSuffix = ".segments"
+
+// segmentState is persisted next to a segmented download so an interrupted run can resume it.
+type segmentState struct {
+	Size     int64   `json:"size"`
+	Starts   []int64 `json:"starts"`
+	Ends     []int64 `json:"ends"`
+	Received []int64 `json:"received"`
+}
+
+func newSegmentState(size int64, segments int) *segmentState {
+	s := &segmentState{Size: size}
+	chunk := size / int64(segments)
+	for i := 0; i < segments; i++ {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == segments-1 {
+			end = size - 1
+		}
+		s.Starts = append(s.Starts, start)
+		s.Ends = append(s.Ends, end)
+		s.Received = append(s.Received, 0)
+	}
+	return s
+}
+
+func loadSegmentState(path string, size int64) *segmentState {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var s segmentState
+	if err := json.Unmarshal(data, &s); err != nil || s.Size != size ||
+		len(s.Starts) == 0 || len(s.Starts) != len(s.Ends) || len(s.Starts) != len(s.Received) {
+		return nil
+	}
+	return &s
+}
+
+// fetchSegmented downloads url into dest as parallel ranged segments, each retried from its own
+// offset. Progress is saved to dest.segments so a later run resumes the segments still missing.
+func fetchSegmented(ctx context.Context, log *logger.Logger, url, dest string, size int64, progress *downloadProgress) error {
+	statePath := dest + segmentStateSuffix
+	state := loadSegmentState(statePath, size)
+	if fi, err := os.Stat(dest); state == nil || err != nil || fi.Size() != size {
+		state = newSegmentState(size, downloadSegments)
+	}
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer out.Close()
+	if err := out.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate destination file: %w", err)
+	}
+
+	var mu sync.Mutex
+	saveState := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		snapshot := segmentState{Size: state.Size, Starts: state.Starts, Ends: state.Ends, Received: make([]int64, len(state.Received))}
+		for i := range state.Received {
+			snapshot.Received[i] = atomic.LoadInt64(&state.Received[i])
+		}
+		if data, err := json.Marshal(snapshot); err == nil {
+			_ = os.WriteFile(statePath, data, 0644)
+		}
+	}
+
+	for i := range state.Received {
+		progress.done.Add(state.Received[i])
+	}
+	if progress.bar != nil {
+		_ = progress.bar.Set64(progress.done.Load())
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range state.Starts {
+		i := i
+		g.Go(func() error {
+			defer saveState()
+			return fetchSegment(gctx, log, url, out, state, i, progress)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write to destination file: %w", err)
+	}
+	_ = os.Remove(statePath)
+	return nil
+}
+
+func fetchSegment(ctx context.Context, log *logger.Logger, url string, out *os.File, state *segmentState, i int, progress *downloadProgress) error {
+	release, err := acquireDownloadSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var lastErr error
+	for attempt := 0; attempt <= downloadRetries; attempt++ {
+		start := state.Starts[i] + atomic.LoadInt64(&state.Received[i])
+		if start > state.Ends[i] {
+			return nil
+		}
+		if attempt > 0 {
+			log.Warnf("Retrying segment %d of %s from byte %d (attempt %d/%d): %v", i, url, start, attempt, downloadRetries, lastErr)
+			if err := sleepContext(ctx, downloadRetryBackoff*time.Duration(attempt)); err != nil {
+				return err
+			}
+		}
+		lastErr = fetchRange(ctx, url, out, start, state.Ends[i], &state.Received[i], progress)
+		if lastErr == nil || ctx.Err() != nil {
+			return lastErr
+		}
+	}
+	return fmt.Errorf("segment %d: %w", i, lastErr)
+}
+
+func fetchRange(ctx context.Context, url string, out *os.File, start, end int64, received *int64, progress *downloadProgress) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("range request failed with status code %d", resp.StatusCode)
+	}
+
+	w := &countingWriter{w: io.NewOffsetWriter(out, start), progress: progress, written: received}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, end-start+1))
+	if err != nil {
+		return fmt.Errorf("failed to write to destination file: %w", err)
+	}
+	if n != end-start+1 {
+		return errors.New("connection closed before the segment was complete")
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/logger"
+)
+
+func useSmallSegments(t *testing.T) {
+	t.Helper()
+	minSize, backoff := downloadSegmentMinSize, downloadRetryBackoff
+	downloadSegmentMinSize, downloadRetryBackoff = 64<<10, time.Millisecond
+	t.Cleanup(func() { downloadSegmentMinSize, downloadRetryBackoff = minSize, backoff })
+}
+
+func randomContent(t *testing.T, size int) []byte {
+	t.Helper()
+	content := make([]byte, size)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// rangeServer serves content with byte range support and records the Range header of every GET.
+// Requests whose range starts at cutAt get only half of their bytes once, as if the connection dropped.
+type rangeServer struct {
+	content []byte
+	cutAt   int64
+
+	mu     sync.Mutex
+	ranges []string
+	cut    bool
+}
+
+func (s *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+	}
+	var start, end int64 = 0, int64(len(s.content)) - 1
+	if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err == nil || start > 0 {
+		s.mu.Lock()
+		cut := !s.cut && s.cutAt > 0 && start == s.cutAt
+		if cut {
+			s.cut = true
+		}
+		s.mu.Unlock()
+		if cut {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(s.content)))
+			w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(s.content[start : start+(end-start+1)/2])
+			return
+		}
+	}
+	http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(s.content))
+}
+
+func (s *rangeServer) requestedRanges() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func TestFetchFile_Segmented(t *testing.T) {
+	useSmallSegments(t)
+	content := randomContent(t, 1<<20)
+	segment := int64(len(content) / downloadSegments)
+	srv := &rangeServer{content: content, cutAt: 2 * segment}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "containerd.tar.gz")
+	if err := fetchFile(context.Background(), logger.Get(), ts.URL, dest, false); err != nil {
+		t.Fatalf("fetchFile failed: %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("downloaded content differs from the served content")
+	}
+	if _, err := os.Stat(dest + segmentStateSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the segment state to be removed after a complete download, got %v", err)
+	}
+
+	ranges := srv.requestedRanges()
+	if len(ranges) != downloadSegments+1 {
+		t.Fatalf("expected %d segment requests plus one retry, got %v", downloadSegments, ranges)
+	}
+	resumed := fmt.Sprintf("bytes=%d-%d", 2*segment+segment/2, 3*segment-1)
+	found := false
+	for _, r := range ranges {
+		found = found || r == resumed
+	}
+	if !found {
+		t.Errorf("expected the dropped segment to resume with %q, got %v", resumed, ranges)
+	}
+}
+
+func TestFetchFile_ResumesPartialStream(t *testing.T) {
+	content := randomContent(t, 32<<10)
+	srv := &rangeServer{content: content}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "kubelet")
+	if err := os.WriteFile(dest, content[:10000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fetchFile(context.Background(), logger.Get(), ts.URL, dest, false); err != nil {
+		t.Fatalf("fetchFile failed: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, content) {
+		t.Fatal("resumed content differs from the served content")
+	}
+	if ranges := srv.requestedRanges(); len(ranges) != 1 || ranges[0] != "bytes=10000-" {
+		t.Errorf("expected a single request resuming at byte 10000, got %v", ranges)
+	}
+}
+
+func TestFetchFile_WithoutRangeSupport(t *testing.T) {
+	useSmallSegments(t)
+	content := randomContent(t, 256<<10)
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests++
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		_, _ = w.Write(content)
+	}))
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "etcd.tar.gz")
+	if err := os.WriteFile(dest, []byte(strings.Repeat("x", 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fetchFile(context.Background(), logger.Get(), ts.URL, dest, false); err != nil {
+		t.Fatalf("fetchFile failed: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, content) {
+		t.Fatal("expected a stale partial file to be replaced by a full download")
+	}
+	if requests != 1 {
+		t.Errorf("expected a single stream, got %d requests", requests)
+	}
+}
@@ -0,0 +1,162 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+// ExternalCA is a user supplied CA that signs leaf certificates in place of a self-signed one.
+type ExternalCA struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+	// Chain holds the issuers of Cert up to the root; it is empty for a root CA.
+	Chain []*x509.Certificate
+}
+
+// ParseExternalCA parses a CA certificate, its private key and the chain of its issuers, and checks
+// that they can be used to sign certificates: the certificate must be a valid CA at this moment, the
+// key must belong to it and it must verify up to a self-signed root. Extra certificates after the
+// first one in certPEM are treated as part of the chain.
+func ParseExternalCA(certPEM, keyPEM, chainPEM []byte) (*ExternalCA, error) {
+	certs, err := decodeCertificates(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA certificate: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in CA certificate")
+	}
+	chain, err := decodeCertificates(chainPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA chain: %w", err)
+	}
+	ca := &ExternalCA{Cert: certs[0], Chain: append(certs[1:], chain...)}
+
+	if ca.Key, err = decodeCAKey(keyPEM); err != nil {
+		return nil, fmt.Errorf("invalid CA private key: %w", err)
+	}
+	if err := ca.validate(time.Now()); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// LoadExternalCA reads and validates the CA configured by cfg. It returns nil if cfg is nil.
+func LoadExternalCA(cfg *v1alpha1.ExternalCA) (*ExternalCA, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	certPEM, keyPEM, chainPEM, err := cfg.PEM()
+	if err != nil {
+		return nil, err
+	}
+	return ParseExternalCA(certPEM, keyPEM, chainPEM)
+}
+
+func (ca *ExternalCA) validate(now time.Time) error {
+	cert := ca.Cert
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return fmt.Errorf("certificate '%s' is not a CA", cert.Subject.CommonName)
+	}
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("CA '%s' is not allowed to sign certificates", cert.Subject.CommonName)
+	}
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return fmt.Errorf("CA '%s' is only valid from %s to %s", cert.Subject.CommonName, cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !pub.Equal(ca.Key.Public()) {
+		return fmt.Errorf("private key does not match CA '%s'", cert.Subject.CommonName)
+	}
+
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, c := range append([]*x509.Certificate{cert}, ca.Chain...) {
+		if bytes.Equal(c.RawSubject, c.RawIssuer) && c.CheckSignatureFrom(c) == nil {
+			roots.AddCert(c)
+		} else {
+			intermediates.AddCert(c)
+		}
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("CA '%s' does not chain to a root in the provided chain: %w", cert.Subject.CommonName, err)
+	}
+	return nil
+}
+
+// Import writes the CA into pkiPath under the given file names so later steps load it like a
+// generated one. The certificate file is followed by the chain so it can be used as a trust bundle.
+func (ca *ExternalCA) Import(pkiPath, certFileName, keyFileName string) error {
+	var bundle bytes.Buffer
+	for _, c := range append([]*x509.Certificate{ca.Cert}, ca.Chain...) {
+		if err := pem.Encode(&bundle, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw}); err != nil {
+			return fmt.Errorf("failed to encode CA certificate: %w", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(pkiPath, certFileName), bundle.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", certFileName, err)
+	}
+	return WriteKey(pkiPath, keyFileName, ca.Key)
+}
+
+// IsImported reports whether the certificate at certPath is this CA.
+func (ca *ExternalCA) IsImported(certPath string) bool {
+	cert, err := LoadCertificate(certPath)
+	return err == nil && cert.Equal(ca.Cert)
+}
+
+func decodeCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type '%s'", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+}
+
+// decodeCAKey accepts SEC 1 and PKCS #8 encoded keys. Only ECDSA keys are supported since every
+// certificate kubexm signs is loaded with an ECDSA CA key.
+func decodeCAKey(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key algorithm %T, only ECDSA CA keys are supported", key)
+		}
+		return ecKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s', only ECDSA CA keys are supported", block.Type)
+	}
+}
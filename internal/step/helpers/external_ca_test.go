@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	certPEM []byte
+	keyPEM  []byte
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string, parent *testCA, notAfter time.Time) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	certPEM, _ := EncodeCertPEM(der)
+	keyPEM, _ := EncodeKeyPEM(key)
+	return &testCA{certPEM: certPEM, keyPEM: keyPEM, cert: cert, key: key}
+}
+
+func TestParseExternalCA(t *testing.T) {
+	valid := time.Now().Add(24 * time.Hour)
+	root := newTestCA(t, "corp-root", nil, valid)
+	intermediate := newTestCA(t, "corp-etcd", root, valid)
+	other := newTestCA(t, "other-root", nil, valid)
+	expired := newTestCA(t, "expired", nil, time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		name    string
+		cert    []byte
+		key     []byte
+		chain   []byte
+		wantErr bool
+	}{
+		{name: "root CA", cert: root.certPEM, key: root.keyPEM},
+		{name: "intermediate with chain", cert: intermediate.certPEM, key: intermediate.keyPEM, chain: root.certPEM},
+		{name: "intermediate bundled with its root", cert: append(intermediate.certPEM, root.certPEM...), key: intermediate.keyPEM},
+		{name: "intermediate without chain", cert: intermediate.certPEM, key: intermediate.keyPEM, wantErr: true},
+		{name: "intermediate with wrong chain", cert: intermediate.certPEM, key: intermediate.keyPEM, chain: other.certPEM, wantErr: true},
+		{name: "key of another CA", cert: root.certPEM, key: other.keyPEM, wantErr: true},
+		{name: "expired CA", cert: expired.certPEM, key: expired.keyPEM, wantErr: true},
+		{name: "key in place of certificate", cert: root.keyPEM, key: root.keyPEM, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExternalCA(tt.cert, tt.key, tt.chain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseExternalCA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExternalCA_Import(t *testing.T) {
+	valid := time.Now().Add(24 * time.Hour)
+	root := newTestCA(t, "corp-root", nil, valid)
+	intermediate := newTestCA(t, "corp-kubernetes", root, valid)
+	ca, err := ParseExternalCA(intermediate.certPEM, intermediate.keyPEM, root.certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := ca.Import(dir, "ca.crt", "ca.key"); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !ca.IsImported(filepath.Join(dir, "ca.crt")) {
+		t.Error("expected the imported certificate to match the external CA")
+	}
+	bundle, err := IsCertificateBundle(filepath.Join(dir, "ca.crt"))
+	if err != nil || !bundle {
+		t.Errorf("expected the CA file to carry the chain, got bundle=%v err=%v", bundle, err)
+	}
+
+	// Leaf certificates are signed with the imported CA as if it had been generated.
+	cert, key, err := LoadCertificateAuthority(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	if err != nil {
+		t.Fatalf("LoadCertificateAuthority failed: %v", err)
+	}
+	if !cert.Equal(intermediate.cert) || !key.Equal(intermediate.key) {
+		t.Error("loaded CA differs from the imported one")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"strings"
 )
 
 func GetFirstIP(n *net.IPNet) (net.IP, error) {
@@ -72,6 +73,7 @@ func GetIPAtIndex(n *net.IPNet, index int64) (net.IP, error) {
 }
 
 func GetDNSIPFromCIDR(serviceCIDR string) (string, error) {
+	serviceCIDR = PrimaryCIDR(serviceCIDR)
 	_, ipNet, err := net.ParseCIDR(serviceCIDR)
 	if err != nil {
 		return "", fmt.Errorf("无效的 CIDR 地址 '%s': %w", serviceCIDR, err)
@@ -92,6 +94,7 @@ func GetDNSIPFromCIDR(serviceCIDR string) (string, error) {
 }
 
 func GetFirstIPFromCIDR(cidr string) (string, error) {
+	cidr = PrimaryCIDR(cidr)
 	_, ipNet, err := net.ParseCIDR(cidr)
 	if err != nil {
 		return "", fmt.Errorf("无效的 CIDR 地址 '%s': %w", cidr, err)
@@ -109,3 +112,56 @@ func GetFirstIPFromCIDR(cidr string) (string, error) {
 
 	return resultIP.String(), nil
 }
+
+// PrimaryCIDR returns the first CIDR of a possibly dual-stack CIDR list such as "10.96.0.0/12,fd00:10:96::/108".
+// Kubernetes derives the primary IP family, the kubernetes service IP and the cluster DNS IP from it.
+func PrimaryCIDR(cidrs string) string {
+	return strings.TrimSpace(strings.Split(cidrs, ",")[0])
+}
+
+// IsDualStackCIDR reports whether cidrs lists both an IPv4 and an IPv6 CIDR.
+func IsDualStackCIDR(cidrs string) bool {
+	var hasIPv4, hasIPv6 bool
+	for _, cidr := range strings.Split(cidrs, ",") {
+		ip, _, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return false
+		}
+		if ip.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+	return hasIPv4 && hasIPv6
+}
+
+// NodeIPsForCIDRs picks one address per IP family of cidrs from a comma-separated address list and orders
+// them like cidrs, so the kubelet's primary node IP matches the cluster's primary IP family. Addresses of a
+// family the cluster does not use are dropped; if none match, the first address is returned.
+func NodeIPsForCIDRs(addresses, cidrs string) string {
+	var candidates []net.IP
+	for _, raw := range strings.Split(addresses, ",") {
+		if ip := net.ParseIP(strings.TrimSpace(raw)); ip != nil {
+			candidates = append(candidates, ip)
+		}
+	}
+
+	var nodeIPs []string
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidrIP, _, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			continue
+		}
+		for _, ip := range candidates {
+			if (ip.To4() != nil) == (cidrIP.To4() != nil) {
+				nodeIPs = append(nodeIPs, ip.String())
+				break
+			}
+		}
+	}
+	if len(nodeIPs) == 0 {
+		return strings.TrimSpace(strings.Split(addresses, ",")[0])
+	}
+	return strings.Join(nodeIPs, ",")
+}
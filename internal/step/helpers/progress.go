@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"io"
+	"sync"
+)
+
+type hostProgress struct {
+	downloaded int64
+	total      int64
+}
+
+// ProgressAggregator sums the download progress reported by many hosts concurrently into a
+// single overall view, e.g. to drive one CLI progress bar while binaries are distributed in
+// parallel. All methods are safe for concurrent use.
+type ProgressAggregator struct {
+	mu       sync.Mutex
+	hosts    map[string]*hostProgress
+	onUpdate func(downloaded, total int64)
+}
+
+// NewProgressAggregator returns an aggregator. onUpdate, if not nil, is called with the new
+// overall totals after every change; it is invoked while the aggregator is locked, so it must
+// be quick and must not call back into the aggregator.
+func NewProgressAggregator(onUpdate func(downloaded, total int64)) *ProgressAggregator {
+	return &ProgressAggregator{
+		hosts:    make(map[string]*hostProgress),
+		onUpdate: onUpdate,
+	}
+}
+
+func (a *ProgressAggregator) host(name string) *hostProgress {
+	p, ok := a.hosts[name]
+	if !ok {
+		p = &hostProgress{}
+		a.hosts[name] = p
+	}
+	return p
+}
+
+// SetTotal records the expected number of bytes for a host.
+func (a *ProgressAggregator) SetTotal(host string, total int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.host(host).total = total
+	a.notify()
+}
+
+// Add records n more downloaded bytes for a host.
+func (a *ProgressAggregator) Add(host string, n int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.host(host).downloaded += n
+	a.notify()
+}
+
+// Update replaces the progress of a host, matching callbacks that report absolute values.
+func (a *ProgressAggregator) Update(host string, downloaded, total int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p := a.host(host)
+	p.downloaded = downloaded
+	p.total = total
+	a.notify()
+}
+
+// Host returns the progress recorded for a single host.
+func (a *ProgressAggregator) Host(host string) (downloaded, total int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if p, ok := a.hosts[host]; ok {
+		return p.downloaded, p.total
+	}
+	return 0, 0
+}
+
+// Totals returns the downloaded and expected bytes summed over all hosts.
+func (a *ProgressAggregator) Totals() (downloaded, total int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.totals()
+}
+
+// Percent returns the overall progress in the range [0, 100]. It is 0 until a total is known.
+func (a *ProgressAggregator) Percent() float64 {
+	downloaded, total := a.Totals()
+	if total <= 0 {
+		return 0
+	}
+	if downloaded >= total {
+		return 100
+	}
+	return float64(downloaded) * 100 / float64(total)
+}
+
+// Writer returns an io.Writer that counts the bytes written to it as downloaded by host, so it
+// can be combined with io.MultiWriter or io.TeeReader in a copy loop.
+func (a *ProgressAggregator) Writer(host string) io.Writer {
+	return &progressWriter{aggregator: a, host: host}
+}
+
+func (a *ProgressAggregator) totals() (downloaded, total int64) {
+	for _, p := range a.hosts {
+		downloaded += p.downloaded
+		total += p.total
+	}
+	return downloaded, total
+}
+
+func (a *ProgressAggregator) notify() {
+	if a.onUpdate != nil {
+		a.onUpdate(a.totals())
+	}
+}
+
+type progressWriter struct {
+	aggregator *ProgressAggregator
+	host       string
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.aggregator.Add(w.host, int64(len(p)))
+	return len(p), nil
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestProgressAggregator_ConcurrentUpdates(t *testing.T) {
+	const (
+		hosts     = 16
+		chunks    = 200
+		chunkSize = 512
+	)
+	var lastDownloaded, lastTotal int64
+	agg := NewProgressAggregator(func(downloaded, total int64) {
+		if downloaded < lastDownloaded {
+			t.Errorf("overall progress went backwards: %d -> %d", lastDownloaded, downloaded)
+		}
+		lastDownloaded, lastTotal = downloaded, total
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < hosts; i++ {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			agg.SetTotal(host, chunks*chunkSize)
+			src := bytes.NewReader(make([]byte, chunks*chunkSize))
+			buf := make([]byte, chunkSize)
+			if _, err := io.CopyBuffer(io.Discard, io.TeeReader(src, agg.Writer(host)), buf); err != nil {
+				t.Errorf("copy for %s failed: %v", host, err)
+			}
+		}(fmt.Sprintf("node%d", i))
+	}
+	wg.Wait()
+
+	downloaded, total := agg.Totals()
+	expected := int64(hosts * chunks * chunkSize)
+	if downloaded != expected || total != expected {
+		t.Errorf("expected totals %d/%d, got %d/%d", expected, expected, downloaded, total)
+	}
+	if lastDownloaded != expected || lastTotal != expected {
+		t.Errorf("expected last update %d/%d, got %d/%d", expected, expected, lastDownloaded, lastTotal)
+	}
+	if agg.Percent() != 100 {
+		t.Errorf("expected 100%%, got %v", agg.Percent())
+	}
+	if d, tot := agg.Host("node3"); d != chunks*chunkSize || tot != chunks*chunkSize {
+		t.Errorf("unexpected per-host progress %d/%d", d, tot)
+	}
+}
+
+func TestProgressAggregator_Percent(t *testing.T) {
+	agg := NewProgressAggregator(nil)
+	if agg.Percent() != 0 {
+		t.Errorf("expected 0%% without totals, got %v", agg.Percent())
+	}
+	agg.Update("node1", 25, 100)
+	agg.Update("node2", 75, 100)
+	if agg.Percent() != 50 {
+		t.Errorf("expected 50%%, got %v", agg.Percent())
+	}
+	agg.Update("node1", 100, 100)
+	if agg.Percent() != 87.5 {
+		t.Errorf("expected 87.5%%, got %v", agg.Percent())
+	}
+}
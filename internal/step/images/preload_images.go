@@ -0,0 +1,231 @@
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+// PreloadImagesStep makes sure every image the current host needs is present in its container
+// runtime before kubeadm init/join. Images with a tarball in ArchiveDir on the control node are
+// uploaded and loaded; the rest are pulled. Loaded images are re-tagged to the name the cluster
+// manifests use, so a private registry rewrite does not cause a second pull.
+type PreloadImagesStep struct {
+	step.Base
+	RuntimeType common.ContainerRuntimeType
+	ArchiveDir  string
+}
+
+type PreloadImagesStepBuilder struct {
+	step.Builder[PreloadImagesStepBuilder, *PreloadImagesStep]
+}
+
+func NewPreloadImagesStepBuilder(ctx runtime.ExecutionContext, instanceName string) *PreloadImagesStepBuilder {
+	s := &PreloadImagesStep{
+		RuntimeType: common.RuntimeTypeContainerd,
+	}
+	if cfg := ctx.GetClusterConfig(); cfg != nil && cfg.Spec.Kubernetes != nil {
+		if cfg.Spec.Kubernetes.ContainerRuntime != nil && cfg.Spec.Kubernetes.ContainerRuntime.Type != "" {
+			s.RuntimeType = cfg.Spec.Kubernetes.ContainerRuntime.Type
+		}
+		if cfg.Spec.Kubernetes.ImagePreload != nil {
+			s.ArchiveDir = cfg.Spec.Kubernetes.ImagePreload.ArchiveDir
+		}
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Preload cluster images into the container runtime", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 60 * time.Minute
+
+	b := new(PreloadImagesStepBuilder).Init(s)
+	return b
+}
+
+func (b *PreloadImagesStepBuilder) WithArchiveDir(dir string) *PreloadImagesStepBuilder {
+	b.Step.ArchiveDir = dir
+	return b
+}
+
+func (s *PreloadImagesStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *PreloadImagesStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, fmt.Errorf("failed to get connector for host %s: %w", ctx.GetHost().GetName(), err)
+	}
+
+	missing, err := s.missingImages(ctx, conn)
+	if err != nil {
+		logger.Warn("Failed to list existing images, assuming they need to be preloaded.", "error", err)
+		return false, nil
+	}
+	if len(missing) > 0 {
+		logger.Info("Some images are not present on the host, step needs to run.", "missing", len(missing))
+		return false, nil
+	}
+	logger.Info("All required images already exist on the host. Step considered done.")
+	return true, nil
+}
+
+func (s *PreloadImagesStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	missing, err := s.missingImages(ctx, conn)
+	if err != nil {
+		result.MarkFailed(err, "failed to list existing images")
+		return result, err
+	}
+
+	arch := ctx.GetHost().GetArch()
+	var loaded, pulled int
+	for _, img := range missing {
+		archive := s.localArchive(img, arch)
+		if archive != "" {
+			logger.Info("Loading image from archive.", "image", img.RuntimeName(), "archive", archive)
+			if err := s.loadImage(ctx, conn, img, archive); err != nil {
+				result.MarkFailed(err, fmt.Sprintf("failed to load image %s", img.RuntimeName()))
+				return result, err
+			}
+			loaded++
+			continue
+		}
+
+		logger.Info("Pulling image.", "image", img.RuntimeName())
+		if err := s.pullImage(ctx, conn, img.RuntimeName()); err != nil {
+			result.MarkFailed(err, fmt.Sprintf("failed to pull image %s", img.RuntimeName()))
+			return result, err
+		}
+		pulled++
+	}
+
+	logger.Info("Images preloaded.", "loaded", loaded, "pulled", pulled)
+	result.MarkCompleted(fmt.Sprintf("Preloaded %d images (%d loaded from archives, %d pulled)", loaded+pulled, loaded, pulled))
+	return result, nil
+}
+
+func (s *PreloadImagesStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Rollback for PreloadImagesStep is a no-op (images are not removed).")
+	return nil
+}
+
+// missingImages returns the images the host needs that its runtime does not have yet, sorted by name.
+func (s *PreloadImagesStep) missingImages(ctx runtime.ExecutionContext, conn connector.Connector) ([]*images.Image, error) {
+	required := images.NewImageProvider(ctx).GetImagesForHost(ctx.GetHost())
+	sort.Slice(required, func(i, j int) bool { return required[i].RuntimeName() < required[j].RuntimeName() })
+
+	runnerSvc := ctx.GetRunner()
+	var missing []*images.Image
+	if s.RuntimeType == common.RuntimeTypeDocker {
+		for _, img := range required {
+			exists, err := runnerSvc.ImageExists(ctx.GoContext(), conn, img.RuntimeName())
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				missing = append(missing, img)
+			}
+		}
+		return missing, nil
+	}
+
+	existing, err := runnerSvc.CrictlListImages(ctx.GoContext(), conn, nil)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool)
+	for _, img := range existing {
+		for _, tag := range img.RepoTags {
+			present[tag] = true
+		}
+	}
+	for _, img := range required {
+		if !present[img.RuntimeName()] {
+			missing = append(missing, img)
+		}
+	}
+	return missing, nil
+}
+
+// localArchive returns the path of the image tarball for arch on the control node, or "" when there
+// is none or the runtime cannot import archives.
+func (s *PreloadImagesStep) localArchive(img *images.Image, arch string) string {
+	if s.ArchiveDir == "" {
+		return ""
+	}
+	if s.RuntimeType != common.RuntimeTypeContainerd && s.RuntimeType != common.RuntimeTypeDocker {
+		return ""
+	}
+	archive := filepath.Join(s.ArchiveDir, images.ArchiveFileName(img, arch))
+	if _, err := os.Stat(archive); err != nil {
+		return ""
+	}
+	return archive
+}
+
+func (s *PreloadImagesStep) loadImage(ctx runtime.ExecutionContext, conn connector.Connector, img *images.Image, archive string) error {
+	runnerSvc := ctx.GetRunner()
+	uploadDir := ctx.GetUploadDir()
+	remoteArchive := filepath.Join(uploadDir, filepath.Base(archive))
+
+	if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, uploadDir, "0755", false); err != nil {
+		return fmt.Errorf("failed to ensure remote upload directory %s exists: %w", uploadDir, err)
+	}
+	if err := runnerSvc.Upload(ctx.GoContext(), conn, archive, remoteArchive, false); err != nil {
+		return fmt.Errorf("failed to upload image archive %s: %w", archive, err)
+	}
+	defer runnerSvc.Remove(ctx.GoContext(), conn, remoteArchive, false, false)
+
+	source, target := img.OriginalFullName(), img.RuntimeName()
+	if s.RuntimeType == common.RuntimeTypeDocker {
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, "docker load -i "+remoteArchive, s.Sudo); err != nil {
+			return fmt.Errorf("failed to load image archive %s: %w", remoteArchive, err)
+		}
+		if source != target {
+			if _, err := runnerSvc.Run(ctx.GoContext(), conn, fmt.Sprintf("docker tag %s %s", source, target), s.Sudo); err != nil {
+				return fmt.Errorf("failed to tag image %s as %s: %w", source, target, err)
+			}
+		}
+		return nil
+	}
+
+	if err := runnerSvc.CtrImportImage(ctx.GoContext(), conn, common.ContainerdK8sNamespace, remoteArchive, false); err != nil {
+		return err
+	}
+	if source != target {
+		return runnerSvc.CtrTagImage(ctx.GoContext(), conn, common.ContainerdK8sNamespace, source, target)
+	}
+	return nil
+}
+
+func (s *PreloadImagesStep) pullImage(ctx runtime.ExecutionContext, conn connector.Connector, name string) error {
+	runnerSvc := ctx.GetRunner()
+	if s.RuntimeType == common.RuntimeTypeDocker {
+		return runnerSvc.PullImage(ctx.GoContext(), conn, name)
+	}
+	// crictl pulls through the CRI, so registry mirrors and credentials configured for the runtime apply.
+	return runnerSvc.CrictlPullImage(ctx.GoContext(), conn, name, "", "")
+}
+
+var _ step.Step = (*PreloadImagesStep)(nil)
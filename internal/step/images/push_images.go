@@ -212,6 +212,9 @@ func (s *PushImagesStep) copyToRegistry(ctx runtime.ExecutionContext, ociRef str
 			}
 		}
 	}
+	if ctx.GetClusterConfig().Spec.Registry.IsPlainHTTP(privateRegistryHost) {
+		skipVerify = true
+	}
 	if skipVerify {
 		args = append(args, "--dest-tls-verify=false")
 	}
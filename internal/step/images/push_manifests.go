@@ -175,6 +175,9 @@ func (s *PushManifestListStep) createAndPushManifest(ctx runtime.ExecutionContex
 			}
 		}
 	}
+	if ctx.GetClusterConfig().Spec.Registry.IsPlainHTTP(privateRegistryHost) {
+		skipVerify = true
+	}
 	if skipVerify {
 		pushArgs = append(pushArgs, "--tls-verify=false")
 	}
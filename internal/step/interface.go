@@ -18,6 +18,9 @@ type Step interface {
 	// 返回 (true, nil) 表示已经完成，无需执行
 	// 返回 (false, nil) 表示需要执行
 	// 返回 (_, error) 表示检查失败
+	// 引擎在每台主机上执行 Run 之前都会调用 Precheck；返回 true 时不调用 Run，
+	// 该主机记为已满足（HostResult.Satisfied），因此对健康集群重复执行 apply 是快速的空操作。
+	// Precheck 必须只读且可重复调用，执行前的预览（preview）也会调用它。
 	Precheck(ctx runtime.ExecutionContext) (bool, error)
 
 	// Validate 验证Step的配置是否正确
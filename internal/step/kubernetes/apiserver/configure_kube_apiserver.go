@@ -68,7 +68,12 @@ func NewConfigureKubeAPIServerStepBuilder(ctx runtime.ExecutionContext, instance
 
 	etcdNodes := ctx.GetHostsByRole(common.RoleEtcd)
 	var etcdEndpoints []string
-	if len(etcdNodes) == 0 {
+	if etcdSpec := ctx.GetClusterConfig().Spec.Etcd; etcdSpec != nil && etcdSpec.Type == string(common.EtcdDeploymentTypeExternal) && etcdSpec.External != nil {
+		etcdEndpoints = etcdSpec.External.Endpoints
+		s.EtcdPKIDir = common.DefaultExternalEtcdPKIDir
+		s.EtcdClientCertFile = common.ExternalEtcdClientCertFileName
+		s.EtcdClientKeyFile = common.ExternalEtcdClientKeyFileName
+	} else if len(etcdNodes) == 0 {
 		ctx.GetLogger().Warn("no etcd nodes found in cluster configuration, etcd servers will be empty")
 	} else {
 		for _, etcdNode := range etcdNodes {
@@ -130,9 +135,12 @@ func (s *ConfigureKubeAPIServerStep) renderConfig(ctx runtime.ExecutionContext)
 	if s.AdvertiseAddress == "" {
 		s.AdvertiseAddress = currentNode.GetAddress()
 	}
-	firstEtcdNodeName := ctx.GetHostsByRole(common.RoleEtcd)[0].GetName()
-	s.EtcdClientCertFile = fmt.Sprintf(common.EtcdNodeCertFileNamePattern, firstEtcdNodeName)
-	s.EtcdClientKeyFile = fmt.Sprintf(common.EtcdNodeKeyFileNamePattern, firstEtcdNodeName)
+	// External etcd client certificates are set up front; otherwise use the certificate of the first etcd member.
+	if etcdNodes := ctx.GetHostsByRole(common.RoleEtcd); s.EtcdPKIDir != common.DefaultExternalEtcdPKIDir && len(etcdNodes) > 0 {
+		firstEtcdNodeName := etcdNodes[0].GetName()
+		s.EtcdClientCertFile = fmt.Sprintf(common.EtcdNodeCertFileNamePattern, firstEtcdNodeName)
+		s.EtcdClientKeyFile = fmt.Sprintf(common.EtcdNodeKeyFileNamePattern, firstEtcdNodeName)
+	}
 
 	tmplContent, err := templates.Get("kubernetes/kube-apiserver.yaml.tmpl")
 	if err != nil {
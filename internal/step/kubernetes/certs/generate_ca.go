@@ -19,6 +19,9 @@ type GenerateKubeCAStep struct {
 	step.Base
 	LocalCertsDir string
 	CADuration    time.Duration
+	// ExternalCA and ExternalFrontProxyCA, when set, are imported instead of generating self-signed CAs.
+	ExternalCA           *helpers.ExternalCA
+	ExternalFrontProxyCA *helpers.ExternalCA
 }
 
 type GenerateKubeCAStepBuilder struct {
@@ -51,6 +54,16 @@ func (b *GenerateKubeCAStepBuilder) WithCADuration(duration time.Duration) *Gene
 	return b
 }
 
+func (b *GenerateKubeCAStepBuilder) WithExternalCA(ca *helpers.ExternalCA) *GenerateKubeCAStepBuilder {
+	b.Step.ExternalCA = ca
+	return b
+}
+
+func (b *GenerateKubeCAStepBuilder) WithExternalFrontProxyCA(ca *helpers.ExternalCA) *GenerateKubeCAStepBuilder {
+	b.Step.ExternalFrontProxyCA = ca
+	return b
+}
+
 func (s *GenerateKubeCAStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
@@ -70,6 +83,14 @@ func (s *GenerateKubeCAStep) Precheck(ctx runtime.ExecutionContext) (isDone bool
 			return false, nil
 		}
 	}
+	if s.ExternalCA != nil && !s.ExternalCA.IsImported(filepath.Join(s.LocalCertsDir, common.CACertFileName)) {
+		logger.Info("Kubernetes CA differs from the configured external CA. Import is required.")
+		return false, nil
+	}
+	if s.ExternalFrontProxyCA != nil && !s.ExternalFrontProxyCA.IsImported(filepath.Join(s.LocalCertsDir, common.FrontProxyCACertFileName)) {
+		logger.Info("Front-proxy CA differs from the configured external CA. Import is required.")
+		return false, nil
+	}
 
 	logger.Info("All required Kubernetes root CAs and service account keys already exist. Step is done.")
 	return true, nil
@@ -87,7 +108,7 @@ func (s *GenerateKubeCAStep) Run(ctx runtime.ExecutionContext) (*types.StepResul
 
 	logger.Info("Ensuring main Kubernetes CA exists...")
 	mainCASubject := pkix.Name{CommonName: "kubernetes"}
-	_, _, err := helpers.NewCertificateAuthorityWithSubject(s.LocalCertsDir, common.CACertFileName, common.CAKeyFileName, mainCASubject, s.CADuration)
+	err := s.ensureCA(s.ExternalCA, common.CACertFileName, common.CAKeyFileName, mainCASubject)
 	if err != nil {
 		err = fmt.Errorf("failed to setup main Kubernetes CA: %w", err)
 		result.MarkFailed(err, "failed to setup main CA")
@@ -96,7 +117,7 @@ func (s *GenerateKubeCAStep) Run(ctx runtime.ExecutionContext) (*types.StepResul
 
 	logger.Info("Ensuring front-proxy CA exists...")
 	fpCASubject := pkix.Name{CommonName: "front-proxy-ca"}
-	_, _, err = helpers.NewCertificateAuthorityWithSubject(s.LocalCertsDir, common.FrontProxyCACertFileName, common.FrontProxyCAKeyFileName, fpCASubject, s.CADuration)
+	err = s.ensureCA(s.ExternalFrontProxyCA, common.FrontProxyCACertFileName, common.FrontProxyCAKeyFileName, fpCASubject)
 	if err != nil {
 		err = fmt.Errorf("failed to setup front-proxy CA: %w", err)
 		result.MarkFailed(err, "failed to setup front-proxy CA")
@@ -115,6 +136,15 @@ func (s *GenerateKubeCAStep) Run(ctx runtime.ExecutionContext) (*types.StepResul
 	return result, nil
 }
 
+// ensureCA imports the external CA if one is configured, otherwise it loads or generates a self-signed one.
+func (s *GenerateKubeCAStep) ensureCA(external *helpers.ExternalCA, certFileName, keyFileName string, subject pkix.Name) error {
+	if external != nil {
+		return external.Import(s.LocalCertsDir, certFileName, keyFileName)
+	}
+	_, _, err := helpers.NewCertificateAuthorityWithSubject(s.LocalCertsDir, certFileName, keyFileName, subject, s.CADuration)
+	return err
+}
+
 func (s *GenerateKubeCAStep) Rollback(ctx runtime.ExecutionContext) error {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Rollback")
 
@@ -0,0 +1,61 @@
+package conformance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CleanupSonobuoyStep deletes the Sonobuoy namespace and the cluster-scoped resources of the run.
+type CleanupSonobuoyStep struct {
+	step.Base
+}
+
+type CleanupSonobuoyStepBuilder struct {
+	step.Builder[CleanupSonobuoyStepBuilder, *CleanupSonobuoyStep]
+}
+
+func NewCleanupSonobuoyStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CleanupSonobuoyStepBuilder {
+	s := &CleanupSonobuoyStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Remove Sonobuoy resources from the cluster", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = true
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(CleanupSonobuoyStepBuilder).Init(s)
+	return b
+}
+
+func (s *CleanupSonobuoyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CleanupSonobuoyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CleanupSonobuoyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, sonobuoyCommand("delete", "--all", "--wait"), s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to delete sonobuoy resources")
+		return result, err
+	}
+	result.MarkCompleted("sonobuoy resources removed")
+	return result, nil
+}
+
+func (s *CleanupSonobuoyStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CleanupSonobuoyStep)(nil)
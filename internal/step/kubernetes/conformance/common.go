@@ -0,0 +1,171 @@
+package conformance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+const (
+	// ModeCertifiedConformance runs the suite required for CNCF certification.
+	ModeCertifiedConformance = "certified-conformance"
+	// ModeQuick runs a single conformance test, useful to check that Sonobuoy itself works.
+	ModeQuick = "quick"
+	// ModeNonDisruptiveConformance skips the conformance tests that disrupt running workloads.
+	ModeNonDisruptiveConformance = "non-disruptive-conformance"
+
+	sonobuoyBinary = "sonobuoy"
+	e2ePlugin      = "e2e"
+)
+
+// Options controls how the conformance suite is run. They are passed from the command line
+// down to the steps.
+type Options struct {
+	Mode string
+	// SonobuoyImage and ConformanceImage override the images Sonobuoy deploys, e.g. to pull
+	// them from a private registry in offline environments.
+	SonobuoyImage    string
+	ConformanceImage string
+	Timeout          time.Duration
+	// OutputDir is the local directory the results tarball is fetched to.
+	OutputDir string
+	// KeepResources leaves the Sonobuoy namespace in place after the results are fetched.
+	KeepResources bool
+}
+
+// DefaultOptions returns the options used when nothing is overridden.
+func DefaultOptions() Options {
+	return Options{
+		Mode:    ModeCertifiedConformance,
+		Timeout: 3 * time.Hour,
+	}
+}
+
+// ValidModes lists the values accepted for Options.Mode.
+func ValidModes() []string {
+	return []string{ModeCertifiedConformance, ModeNonDisruptiveConformance, ModeQuick}
+}
+
+// Summary is the outcome of the e2e plugin, read from the results tarball.
+type Summary struct {
+	Status      string   `json:"status"`
+	Total       int      `json:"total"`
+	Passed      int      `json:"passed"`
+	Failed      int      `json:"failed"`
+	Skipped     int      `json:"skipped"`
+	FailedTests []string `json:"failedTests,omitempty"`
+	ResultsFile string   `json:"resultsFile"`
+}
+
+// Succeeded reports whether the suite ran to completion without failed tests.
+func (s *Summary) Succeeded() bool {
+	return s.Status == "passed" && s.Failed == 0
+}
+
+// String renders the summary for the terminal, listing every failed test.
+func (s *Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Conformance: %s\n", s.Status)
+	fmt.Fprintf(&b, "Tests: %d total, %d passed, %d failed, %d skipped\n", s.Total, s.Passed, s.Failed, s.Skipped)
+	if len(s.FailedTests) > 0 {
+		b.WriteString("Failed tests:\n")
+		for _, test := range s.FailedTests {
+			fmt.Fprintf(&b, "  - %s\n", test)
+		}
+	}
+	if s.ResultsFile != "" {
+		fmt.Fprintf(&b, "Results: %s\n", s.ResultsFile)
+	}
+	return b.String()
+}
+
+// parseResultsReport parses the output of `sonobuoy results <tarball> --plugin e2e`.
+func parseResultsReport(output string) (*Summary, error) {
+	summary := &Summary{}
+	inFailed := false
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inFailed {
+			if line == "" {
+				inFailed = false
+				continue
+			}
+			summary.FailedTests = append(summary.FailedTests, line)
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Status":
+			summary.Status = value
+			found = true
+		case "Total":
+			summary.Total, _ = strconv.Atoi(value)
+		case "Passed":
+			summary.Passed, _ = strconv.Atoi(value)
+		case "Failed":
+			summary.Failed, _ = strconv.Atoi(value)
+		case "Skipped":
+			summary.Skipped, _ = strconv.Atoi(value)
+		case "Failed tests":
+			inFailed = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no plugin status in sonobuoy results output")
+	}
+	return summary, nil
+}
+
+// runStatus is the part of `sonobuoy status --json` the steps look at.
+type runStatus struct {
+	Status  string `json:"status"`
+	Plugins []struct {
+		Plugin       string `json:"plugin"`
+		Status       string `json:"status"`
+		ResultStatus string `json:"result-status"`
+	} `json:"plugins"`
+}
+
+func parseRunStatus(output string) (*runStatus, error) {
+	status := &runStatus{}
+	if err := json.Unmarshal([]byte(output), status); err != nil {
+		return nil, fmt.Errorf("failed to parse sonobuoy status: %w", err)
+	}
+	return status, nil
+}
+
+// done reports whether the run finished, successfully or not. Sonobuoy reports "complete" once
+// results are available and "failed" when a plugin could not run.
+func (s *runStatus) done() bool {
+	return s.Status == "complete" || s.Status == "failed"
+}
+
+func adminKubeconfigPath() string {
+	return filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+}
+
+func sonobuoyPath() string {
+	return filepath.Join(common.DefaultBinDir, sonobuoyBinary)
+}
+
+// sonobuoyCommand builds a sonobuoy invocation against the admin kubeconfig.
+func sonobuoyCommand(args ...string) string {
+	return fmt.Sprintf("%s %s --kubeconfig %s", sonobuoyPath(), strings.Join(args, " "), adminKubeconfigPath())
+}
+
+func defaultOutputDir(ctx runtime.ExecutionContext) string {
+	return filepath.Join(ctx.GetGlobalWorkDir(), "conformance")
+}
@@ -0,0 +1,93 @@
+package conformance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const failedResultsOutput = `Plugin: e2e
+Status: failed
+Total: 7213
+Passed: 380
+Failed: 2
+Skipped: 6831
+
+Failed tests:
+[sig-network] Services should serve endpoints on same port and different protocols [Conformance]
+[sig-node] Pods should be updated [NodeConformance] [Conformance]
+
+Run Details:
+API Server version: v1.29.0
+Node health: 3/3 (100%)
+`
+
+func TestParseResultsReport(t *testing.T) {
+	summary, err := parseResultsReport(failedResultsOutput)
+	if err != nil {
+		t.Fatalf("parseResultsReport failed: %v", err)
+	}
+	if summary.Status != "failed" || summary.Total != 7213 || summary.Passed != 380 || summary.Failed != 2 || summary.Skipped != 6831 {
+		t.Errorf("unexpected counts: %+v", summary)
+	}
+	want := []string{
+		"[sig-network] Services should serve endpoints on same port and different protocols [Conformance]",
+		"[sig-node] Pods should be updated [NodeConformance] [Conformance]",
+	}
+	if !reflect.DeepEqual(summary.FailedTests, want) {
+		t.Errorf("expected failed tests %v, got %v", want, summary.FailedTests)
+	}
+	if summary.Succeeded() {
+		t.Error("expected a run with failed tests not to succeed")
+	}
+	if out := summary.String(); !strings.Contains(out, "2 failed") || !strings.Contains(out, "  - [sig-node] Pods should be updated") {
+		t.Errorf("unexpected summary text:\n%s", out)
+	}
+
+	passed, err := parseResultsReport("Plugin: e2e\nStatus: passed\nTotal: 1\nPassed: 1\nFailed: 0\nSkipped: 0\n")
+	if err != nil {
+		t.Fatalf("parseResultsReport failed: %v", err)
+	}
+	if !passed.Succeeded() || len(passed.FailedTests) != 0 {
+		t.Errorf("expected a passing summary, got %+v", passed)
+	}
+
+	if _, err := parseResultsReport("no results here"); err == nil {
+		t.Error("expected an error for output without a status")
+	}
+}
+
+func TestParseRunStatus(t *testing.T) {
+	status, err := parseRunStatus(`{"plugins":[{"plugin":"e2e","node":"global","status":"running"}],"status":"running"}`)
+	if err != nil {
+		t.Fatalf("parseRunStatus failed: %v", err)
+	}
+	if status.done() || len(status.Plugins) != 1 || status.Plugins[0].Plugin != e2ePlugin {
+		t.Errorf("expected a running e2e plugin, got %+v", status)
+	}
+
+	for _, s := range []string{"complete", "failed"} {
+		status, err := parseRunStatus(`{"status":"` + s + `"}`)
+		if err != nil || !status.done() {
+			t.Errorf("expected status %q to be done, got %+v (%v)", s, status, err)
+		}
+	}
+
+	if _, err := parseRunStatus("ERRO[0000] No sonobuoy pod found"); err == nil {
+		t.Error("expected an error for non-JSON output")
+	}
+}
+
+func TestRunConformanceStep_RunArgs(t *testing.T) {
+	s := &RunConformanceStep{Options: Options{Mode: ModeQuick}}
+	if got := strings.Join(s.runArgs(), " "); got != "run --mode quick" {
+		t.Errorf("unexpected args %q", got)
+	}
+
+	s.Options.SonobuoyImage = "registry.local/sonobuoy:v0.57.2"
+	s.Options.ConformanceImage = "registry.local/conformance:v1.29.0"
+	want := "run --mode quick --sonobuoy-image registry.local/sonobuoy:v0.57.2 --kube-conformance-image registry.local/conformance:v1.29.0"
+	if got := strings.Join(s.runArgs(), " "); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
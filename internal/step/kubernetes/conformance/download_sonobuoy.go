@@ -0,0 +1,92 @@
+package conformance
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// DownloadSonobuoyStep downloads the sonobuoy archive on the control node for the architecture of
+// the host that runs the suite. An archive prepared by `kubexm download` is reused.
+type DownloadSonobuoyStep struct {
+	step.Base
+	Arch string
+}
+
+type DownloadSonobuoyStepBuilder struct {
+	step.Builder[DownloadSonobuoyStepBuilder, *DownloadSonobuoyStep]
+}
+
+func NewDownloadSonobuoyStepBuilder(ctx runtime.ExecutionContext, instanceName, arch string) *DownloadSonobuoyStepBuilder {
+	s := &DownloadSonobuoyStep{Arch: arch}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Download sonobuoy for %s", s.Base.Meta.Name, arch)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(DownloadSonobuoyStepBuilder).Init(s)
+	return b
+}
+
+func (s *DownloadSonobuoyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *DownloadSonobuoyStep) binaryInfo(ctx runtime.ExecutionContext) (*binary.Binary, error) {
+	binaryInfo, err := binary.NewBinaryProvider(ctx).GetBinary(binary.ComponentSonobuoy, s.Arch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sonobuoy binary info: %w", err)
+	}
+	if binaryInfo == nil {
+		return nil, fmt.Errorf("sonobuoy is unexpectedly disabled for arch %s", s.Arch)
+	}
+	return binaryInfo, nil
+}
+
+func (s *DownloadSonobuoyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	binaryInfo, err := s.binaryInfo(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(binaryInfo.FilePath()); err != nil {
+		return false, nil
+	}
+	match, err := helpers.VerifyLocalFileChecksum(binaryInfo.FilePath(), binaryInfo.Checksum())
+	if err != nil || !match {
+		return false, nil
+	}
+	ctx.GetLogger().Infof("sonobuoy archive %s already exists.", binaryInfo.FilePath())
+	return true, nil
+}
+
+func (s *DownloadSonobuoyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
+
+	binaryInfo, err := s.binaryInfo(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to resolve sonobuoy binary")
+		return result, err
+	}
+	logger.Infof("Downloading sonobuoy (arch: %s, version: %s) ...", binaryInfo.Arch, binaryInfo.Version)
+	if err := helpers.DownloadBinary(ctx.GoContext(), logger, binaryInfo, true); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to download sonobuoy for arch %s", s.Arch))
+		return result, err
+	}
+	result.MarkCompleted(fmt.Sprintf("sonobuoy %s downloaded to %s", binaryInfo.Version, binaryInfo.FilePath()))
+	return result, nil
+}
+
+func (s *DownloadSonobuoyStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*DownloadSonobuoyStep)(nil)
@@ -0,0 +1,113 @@
+package conformance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// FetchConformanceResultsStep retrieves the results tarball of the finished run, copies it to the
+// control machine and stores a Summary of the e2e plugin in the pipeline cache. Failed tests do not
+// fail the step; the caller decides what to do with the summary.
+type FetchConformanceResultsStep struct {
+	step.Base
+	OutputDir string
+}
+
+type FetchConformanceResultsStepBuilder struct {
+	step.Builder[FetchConformanceResultsStepBuilder, *FetchConformanceResultsStep]
+}
+
+func NewFetchConformanceResultsStepBuilder(ctx runtime.ExecutionContext, instanceName, outputDir string) *FetchConformanceResultsStepBuilder {
+	if outputDir == "" {
+		outputDir = defaultOutputDir(ctx)
+	}
+	s := &FetchConformanceResultsStep{OutputDir: outputDir}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Fetch and summarize conformance results", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(FetchConformanceResultsStepBuilder).Init(s)
+	return b
+}
+
+func (s *FetchConformanceResultsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *FetchConformanceResultsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *FetchConformanceResultsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	remoteDir := filepath.Join(ctx.GetUploadDir(), "conformance")
+	if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, remoteDir, "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to create remote directory '%s'", remoteDir))
+		return result, err
+	}
+	res, err := runnerSvc.Run(ctx.GoContext(), conn, sonobuoyCommand("retrieve", remoteDir), s.Sudo)
+	if err != nil {
+		result.MarkFailed(err, "failed to retrieve sonobuoy results")
+		return result, err
+	}
+	// retrieve prints the path of the tarball it wrote.
+	remoteTarball := strings.TrimSpace(res.Stdout)
+	if remoteTarball == "" {
+		err := fmt.Errorf("sonobuoy retrieve did not report a results file")
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+
+	res, err = runnerSvc.Run(ctx.GoContext(), conn, fmt.Sprintf("%s results %s --plugin %s", sonobuoyPath(), remoteTarball, e2ePlugin), s.Sudo)
+	if err != nil {
+		result.MarkFailed(err, "failed to read sonobuoy results")
+		return result, err
+	}
+	summary, err := parseResultsReport(res.Stdout)
+	if err != nil {
+		result.MarkFailed(err, "failed to parse sonobuoy results")
+		return result, err
+	}
+
+	if err := os.MkdirAll(s.OutputDir, 0755); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to create output directory '%s'", s.OutputDir))
+		return result, err
+	}
+	localTarball := filepath.Join(s.OutputDir, filepath.Base(remoteTarball))
+	if err := runnerSvc.Fetch(ctx.GoContext(), conn, remoteTarball, localTarball, s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to fetch the results tarball")
+		return result, err
+	}
+	summary.ResultsFile = localTarball
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyConformanceSummary, ctx.GetRunID()), summary)
+
+	logger.Info("Conformance results fetched.", "status", summary.Status, "failed", summary.Failed, "file", localTarball)
+	result.AddArtifact(localTarball)
+	result.MarkCompleted(fmt.Sprintf("e2e %s: %d passed, %d failed, %d skipped", summary.Status, summary.Passed, summary.Failed, summary.Skipped))
+	return result, nil
+}
+
+func (s *FetchConformanceResultsStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*FetchConformanceResultsStep)(nil)
@@ -0,0 +1,133 @@
+package conformance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// InstallSonobuoyStep uploads the sonobuoy archive to the host and extracts the binary into the
+// system bin directory.
+type InstallSonobuoyStep struct {
+	step.Base
+	InstallPath string
+}
+
+type InstallSonobuoyStepBuilder struct {
+	step.Builder[InstallSonobuoyStepBuilder, *InstallSonobuoyStep]
+}
+
+func NewInstallSonobuoyStepBuilder(ctx runtime.ExecutionContext, instanceName string) *InstallSonobuoyStepBuilder {
+	s := &InstallSonobuoyStep{InstallPath: common.DefaultBinDir}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Install sonobuoy binary", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(InstallSonobuoyStepBuilder).Init(s)
+	return b
+}
+
+func (s *InstallSonobuoyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *InstallSonobuoyStep) binaryInfo(ctx runtime.ExecutionContext) (*binary.Binary, error) {
+	arch := ctx.GetHost().GetArch()
+	binaryInfo, err := binary.NewBinaryProvider(ctx).GetBinary(binary.ComponentSonobuoy, arch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sonobuoy binary info: %w", err)
+	}
+	if binaryInfo == nil {
+		return nil, fmt.Errorf("sonobuoy is unexpectedly disabled for arch %s", arch)
+	}
+	return binaryInfo, nil
+}
+
+func (s *InstallSonobuoyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	binaryInfo, err := s.binaryInfo(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	res, err := ctx.GetRunner().Run(ctx.GoContext(), conn, fmt.Sprintf("%s version --short", filepath.Join(s.InstallPath, sonobuoyBinary)), false)
+	if err != nil {
+		return false, nil
+	}
+	if strings.TrimSpace(res.Stdout) == binaryInfo.Version {
+		ctx.GetLogger().Infof("sonobuoy %s is already installed.", binaryInfo.Version)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *InstallSonobuoyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	binaryInfo, err := s.binaryInfo(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to resolve sonobuoy binary")
+		return result, err
+	}
+	localPath := binaryInfo.FilePath()
+	if _, err := os.Stat(localPath); err != nil {
+		err = fmt.Errorf("local sonobuoy archive '%s' not found: %w", localPath, err)
+		result.MarkFailed(err, "sonobuoy archive not found")
+		return result, err
+	}
+
+	uploadDir := filepath.Join(ctx.GetUploadDir(), fmt.Sprintf("sonobuoy-install-%d", time.Now().UnixNano()))
+	if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, uploadDir, "0755", false); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to create remote upload directory '%s'", uploadDir))
+		return result, err
+	}
+	defer func() {
+		_ = runnerSvc.Remove(ctx.GoContext(), conn, uploadDir, false, true)
+	}()
+
+	remoteArchive := filepath.Join(uploadDir, binaryInfo.FileName())
+	if err := runnerSvc.Upload(ctx.GoContext(), conn, localPath, remoteArchive, false); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to upload '%s'", localPath))
+		return result, err
+	}
+	if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, s.InstallPath, "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to create install directory '%s'", s.InstallPath))
+		return result, err
+	}
+	cmd := fmt.Sprintf("tar -xzf %s -C %s %s && chmod 0755 %s",
+		remoteArchive, s.InstallPath, sonobuoyBinary, filepath.Join(s.InstallPath, sonobuoyBinary))
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to extract sonobuoy")
+		return result, err
+	}
+
+	logger.Infof("Installed sonobuoy %s to %s", binaryInfo.Version, s.InstallPath)
+	result.MarkCompleted(fmt.Sprintf("sonobuoy %s installed", binaryInfo.Version))
+	return result, nil
+}
+
+func (s *InstallSonobuoyStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*InstallSonobuoyStep)(nil)
@@ -0,0 +1,125 @@
+package conformance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// RunConformanceStep starts the Sonobuoy e2e plugin and polls its status until the run completes.
+// Polling rather than `sonobuoy run --wait` keeps each remote command short, which matters for a
+// suite that takes hours.
+type RunConformanceStep struct {
+	step.Base
+	Options      Options
+	PollInterval time.Duration
+}
+
+type RunConformanceStepBuilder struct {
+	step.Builder[RunConformanceStepBuilder, *RunConformanceStep]
+}
+
+func NewRunConformanceStepBuilder(ctx runtime.ExecutionContext, instanceName string, opts Options) *RunConformanceStepBuilder {
+	if opts.Mode == "" {
+		opts.Mode = ModeCertifiedConformance
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultOptions().Timeout
+	}
+	s := &RunConformanceStep{
+		Options:      opts,
+		PollInterval: 30 * time.Second,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Run the %s suite with Sonobuoy", s.Base.Meta.Name, opts.Mode)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = opts.Timeout + 10*time.Minute
+
+	b := new(RunConformanceStepBuilder).Init(s)
+	return b
+}
+
+func (s *RunConformanceStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *RunConformanceStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *RunConformanceStep) runArgs() []string {
+	args := []string{"run", "--mode", s.Options.Mode}
+	if s.Options.SonobuoyImage != "" {
+		args = append(args, "--sonobuoy-image", s.Options.SonobuoyImage)
+	}
+	if s.Options.ConformanceImage != "" {
+		args = append(args, "--kube-conformance-image", s.Options.ConformanceImage)
+	}
+	return args
+}
+
+func (s *RunConformanceStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	// Sonobuoy refuses to start while a previous run exists, remove leftovers first.
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, sonobuoyCommand("delete", "--all", "--wait"), s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to remove a previous sonobuoy run")
+		return result, err
+	}
+	logger.Info("Starting conformance run.", "mode", s.Options.Mode)
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, sonobuoyCommand(s.runArgs()...), s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to start sonobuoy")
+		return result, err
+	}
+
+	start := time.Now()
+	deadline := start.Add(s.Options.Timeout)
+	var status *runStatus
+	for {
+		res, err := runnerSvc.Run(ctx.GoContext(), conn, sonobuoyCommand("status", "--json"), s.Sudo)
+		if err == nil {
+			status, err = parseRunStatus(res.Stdout)
+		}
+		if err != nil {
+			logger.Warnf("Failed to read sonobuoy status: %v", err)
+		} else if status.done() {
+			break
+		} else {
+			logger.Infof("Conformance run is %s (%v elapsed).", status.Status, time.Since(start).Round(time.Second))
+		}
+		if time.Now().After(deadline) {
+			err := fmt.Errorf("conformance run did not finish within %v", s.Options.Timeout)
+			result.MarkFailed(err, err.Error())
+			return result, err
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			result.MarkFailed(ctx.GoContext().Err(), "conformance run interrupted")
+			return result, ctx.GoContext().Err()
+		case <-time.After(s.PollInterval):
+		}
+	}
+
+	duration := time.Since(start).Round(time.Second)
+	logger.Info("Conformance run finished.", "status", status.Status, "duration", duration)
+	result.MarkCompleted(fmt.Sprintf("sonobuoy run %s after %v", status.Status, duration))
+	return result, nil
+}
+
+func (s *RunConformanceStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*RunConformanceStep)(nil)
@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CollectDriftStep queries the live cluster from a control plane node, compares it with the
+// desired configuration, adds the config drift found by CompareConfigStep on each host and stores
+// the resulting Report in the pipeline cache.
+type CollectDriftStep struct {
+	step.Base
+	KubeconfigPath string
+}
+
+type CollectDriftStepBuilder struct {
+	step.Builder[CollectDriftStepBuilder, *CollectDriftStep]
+}
+
+func NewCollectDriftStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CollectDriftStepBuilder {
+	s := &CollectDriftStep{
+		KubeconfigPath: filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Compare desired configuration with live cluster state", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(CollectDriftStepBuilder).Init(s)
+	return b
+}
+
+func (s *CollectDriftStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CollectDriftStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CollectDriftStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	nodes, err := runnerSvc.KubectlGetNodes(ctx.GoContext(), conn, runner.KubectlGetOptions{KubeconfigPath: s.KubeconfigPath, Sudo: s.Sudo})
+	if err != nil {
+		result.MarkFailed(err, "failed to list nodes")
+		return result, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	version, err := runnerSvc.KubectlVersion(ctx.GoContext(), conn, runner.KubectlVersionOptions{KubeconfigPath: s.KubeconfigPath, Sudo: s.Sudo})
+	if err != nil && version == nil {
+		result.MarkFailed(err, "failed to query kubernetes version")
+		return result, fmt.Errorf("failed to query kubernetes version: %w", err)
+	}
+	releases, err := runnerSvc.HelmList(ctx.GoContext(), conn, runner.HelmListOptions{KubeconfigPath: s.KubeconfigPath, AllNamespaces: true})
+	if err != nil {
+		logger.Warn(err, "Failed to list helm releases, addon drift will not be reported.")
+		releases = nil
+	}
+
+	clusterCfg := ctx.GetClusterConfig()
+	desired := DesiredStateFromConfig(clusterCfg)
+	live := LiveStateFromCluster(nodes, version, releases)
+	if releases == nil {
+		live.Addons = desired.Addons
+	}
+	report := Compare(clusterCfg.Name, desired, live)
+	for _, host := range clusterCfg.Spec.Hosts {
+		if cached, ok := ctx.GetPipelineCache().Get(fmt.Sprintf(common.CacheKeyHostConfigDrift, ctx.GetRunID(), host.Name)); ok {
+			if items, ok := cached.([]Item); ok {
+				report.Merge(items)
+			}
+		}
+	}
+
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyClusterDriftReport, ctx.GetRunID()), report)
+	if report.HasDrift() {
+		logger.Warnf("Detected %d difference(s) between desired and live cluster state.", len(report.Items))
+	} else {
+		logger.Info("Live cluster state matches the desired configuration.")
+	}
+
+	result.SetMetadata("driftCount", len(report.Items))
+	result.MarkCompleted("drift report generated")
+	return result, nil
+}
+
+func (s *CollectDriftStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Rollback is not applicable for a read-only step.")
+	return nil
+}
+
+var _ step.Step = (*CollectDriftStep)(nil)
@@ -0,0 +1,159 @@
+package drift
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/containerd"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubelet"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CompareConfigStep renders the node config files of the current host and compares them with the
+// live files, read-only. The drifted files are stored in the pipeline cache for CollectDriftStep.
+type CompareConfigStep struct {
+	step.Base
+	Renderers []step.Renderer
+}
+
+type CompareConfigStepBuilder struct {
+	step.Builder[CompareConfigStepBuilder, *CompareConfigStep]
+}
+
+func NewCompareConfigStepBuilder(ctx runtime.ExecutionContext, instanceName string, renderers ...step.Renderer) *CompareConfigStepBuilder {
+	s := &CompareConfigStep{
+		Renderers: renderers,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Compare rendered node config files with the live files", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(CompareConfigStepBuilder).Init(s)
+	return b
+}
+
+// NodeConfigRenderers returns the steps rendering the containerd and kubelet config files and
+// systemd units of the host of ctx.
+func NodeConfigRenderers(ctx runtime.ExecutionContext, withContainerd, withKubelet bool) ([]step.Renderer, error) {
+	var renderers []step.Renderer
+	if withContainerd {
+		configRenderer, err := newContainerdConfigRenderer(ctx, "RenderContainerdConfig")
+		if err != nil {
+			return nil, err
+		}
+		serviceStep, err := containerd.NewInstallContainerdServiceStepBuilder(ctx, "RenderContainerdService").Build()
+		if err != nil {
+			return nil, err
+		}
+		renderers = append(renderers, configRenderer, serviceStep)
+	}
+	if withKubelet {
+		configStep, err := kubelet.NewCreateKubeletConfigYAMLStepBuilder(ctx, "RenderKubeletConfig").Build()
+		if err != nil {
+			return nil, err
+		}
+		serviceStep, err := kubelet.NewInstallKubeletServiceStepBuilder(ctx, "RenderKubeletService").Build()
+		if err != nil {
+			return nil, err
+		}
+		dropInStep, err := kubelet.NewInstallKubeletDropInStepBuilder(ctx, "RenderKubeletDropIn").Build()
+		if err != nil {
+			return nil, err
+		}
+		renderers = append(renderers, configStep, serviceStep, dropInStep)
+	}
+	return renderers, nil
+}
+
+func (s *CompareConfigStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CompareConfigStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CompareConfigStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+
+	items, err := s.compare(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to compare config files")
+		return result, err
+	}
+	hostName := ctx.GetHost().GetName()
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyHostConfigDrift, ctx.GetRunID(), hostName), items)
+	if len(items) > 0 {
+		logger.Warnf("%d config file(s) differ from the desired configuration.", len(items))
+	} else {
+		logger.Info("Config files match the desired configuration.")
+	}
+
+	result.SetMetadata("driftCount", len(items))
+	result.MarkCompleted("config files compared")
+	return result, nil
+}
+
+// compare returns a config Item for every rendered file that is missing on the host or differs from it.
+func (s *CompareConfigStep) compare(ctx runtime.ExecutionContext) ([]Item, error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, err
+	}
+	hostName := ctx.GetHost().GetName()
+
+	var items []Item
+	for _, renderer := range s.Renderers {
+		files, err := renderer.RenderFiles(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render desired config: %w", err)
+		}
+		for _, file := range files {
+			exists, err := runner.Exists(ctx.GoContext(), conn, file.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check for config file '%s': %w", file.Path, err)
+			}
+			item := Item{Category: CategoryConfig, Object: hostName, Field: file.Path, Desired: checksum(file.Content)}
+			if !exists {
+				item.Live = "<missing>"
+				item.Diff = plan.UnifiedDiff("/dev/null", "desired:"+file.Path, "", string(file.Content))
+				items = append(items, item)
+				continue
+			}
+			live, err := runner.ReadFile(ctx.GoContext(), conn, file.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read config file '%s': %w", file.Path, err)
+			}
+			item.Diff = plan.UnifiedDiff(hostName+":"+file.Path, "desired:"+file.Path, string(live), string(file.Content))
+			if item.Diff == "" {
+				continue
+			}
+			item.Live = checksum(live)
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// checksum is a short sha256 of content, enough to tell two renderings apart in the report.
+func checksum(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))[:19]
+}
+
+func (s *CompareConfigStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Rollback is not applicable for a read-only step.")
+	return nil
+}
+
+var _ step.Step = (*CompareConfigStep)(nil)
@@ -0,0 +1,69 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/cache"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/step"
+)
+
+type staticRenderer []step.RenderedFile
+
+func (r staticRenderer) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	return r, nil
+}
+
+func TestCompareConfigStep_ReportsDriftedAndMissingFiles(t *testing.T) {
+	r := &fakeNodeRunner{files: map[string]string{
+		"/etc/containerd/config.toml":            "version = 2\nsandbox_image = \"pause:3.6\"\n",
+		"/etc/systemd/system/containerd.service": "[Service]\n",
+	}}
+	pipelineCache := cache.NewPipelineCache()
+	ctx := &runtimetest.Context{
+		Runner:        r,
+		Host:          connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+		PipelineCache: pipelineCache,
+		RunID:         "run-1",
+	}
+	renderer := staticRenderer{
+		{Path: "/etc/containerd/config.toml", Content: []byte("version = 2\nsandbox_image = \"pause:3.9\"\n")},
+		{Path: "/etc/systemd/system/containerd.service", Content: []byte("[Service]\n")},
+		{Path: "/var/lib/kubelet/config.yaml", Content: []byte("kind: KubeletConfiguration\n")},
+	}
+	s, err := NewCompareConfigStepBuilder(ctx, "CompareNodeConfig", renderer).Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+	if _, err := s.Run(ctx); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(r.writes) != 0 {
+		t.Errorf("expected the host to be left untouched, got writes %v", r.writes)
+	}
+
+	cached, ok := pipelineCache.Get(fmt.Sprintf(common.CacheKeyHostConfigDrift, "run-1", "node1"))
+	if !ok {
+		t.Fatal("expected the config drift of node1 in the pipeline cache")
+	}
+	items := cached.([]Item)
+	if len(items) != 2 {
+		t.Fatalf("expected the drifted and the missing file, got %+v", items)
+	}
+	changed, missing := items[0], items[1]
+	if changed.Field != "/etc/containerd/config.toml" || changed.Category != CategoryConfig || changed.Live == changed.Desired {
+		t.Errorf("unexpected item for the drifted file: %+v", changed)
+	}
+	if !strings.Contains(changed.Diff, "-sandbox_image = \"pause:3.6\"\n+sandbox_image = \"pause:3.9\"\n") {
+		t.Errorf("expected the diff of the sandbox image, got:\n%s", changed.Diff)
+	}
+	if missing.Field != "/var/lib/kubelet/config.yaml" || missing.Live != "<missing>" {
+		t.Errorf("unexpected item for the missing file: %+v", missing)
+	}
+}
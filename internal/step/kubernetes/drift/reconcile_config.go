@@ -0,0 +1,258 @@
+package drift
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/cache"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/containerd"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubelet"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const (
+	ComponentContainerd = "containerd"
+	ComponentKubelet    = "kubelet"
+)
+
+// ConfigRenderer renders the desired content of a node config file for the current host.
+type ConfigRenderer func(ctx runtime.ExecutionContext) (string, error)
+
+// ConfigDrift records a node config file that differed from the desired configuration and was rewritten.
+type ConfigDrift struct {
+	Node      string `json:"node"`
+	Component string `json:"component"`
+	Path      string `json:"path"`
+	Desired   string `json:"desired"`
+	Live      string `json:"live"`
+}
+
+// ReconcileConfigStep compares the checksum of a live node config file with the desired rendering and,
+// only when they differ, rewrites the file and restarts the owning service.
+type ReconcileConfigStep struct {
+	step.Base
+	Component       string
+	TargetPath      string
+	ServiceName     string
+	Render          ConfigRenderer
+	BackupRetention int
+}
+
+type ReconcileConfigStepBuilder struct {
+	step.Builder[ReconcileConfigStepBuilder, *ReconcileConfigStep]
+}
+
+func NewReconcileConfigStepBuilder(ctx runtime.ExecutionContext, instanceName, component, targetPath, serviceName string, render ConfigRenderer) *ReconcileConfigStepBuilder {
+	s := &ReconcileConfigStep{
+		Component:       component,
+		TargetPath:      targetPath,
+		ServiceName:     serviceName,
+		Render:          render,
+		BackupRetention: common.DefaultConfigBackupRetention,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Reconcile drifted %s config %s", s.Base.Meta.Name, component, targetPath)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(ReconcileConfigStepBuilder).Init(s)
+	return b
+}
+
+// NewReconcileContainerdConfigStepBuilder reconciles the containerd config.toml rendered by ConfigureContainerdStep,
+// including the nvidia runtime ConfigureNvidiaRuntimeStep adds on GPU nodes.
+func NewReconcileContainerdConfigStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ReconcileConfigStepBuilder {
+	renderer, err := newContainerdConfigRenderer(ctx, instanceName)
+	if err != nil {
+		return nil
+	}
+	b := NewReconcileConfigStepBuilder(ctx, instanceName, ComponentContainerd, renderer.config.TargetPath, common.ContainerdDefaultServiceName, renderer.Render)
+	b.Step.BackupRetention = renderer.config.BackupRetention
+	return b
+}
+
+// containerdConfigRenderer renders config.toml the way the containerd install task leaves it: the
+// config of ConfigureContainerdStep with the nvidia runtime of ConfigureNvidiaRuntimeStep merged in.
+type containerdConfigRenderer struct {
+	config *containerd.ConfigureContainerdStep
+	nvidia *containerd.ConfigureNvidiaRuntimeStep
+}
+
+func newContainerdConfigRenderer(ctx runtime.ExecutionContext, instanceName string) (*containerdConfigRenderer, error) {
+	configBuilder := containerd.NewConfigureContainerdStepBuilder(ctx, instanceName)
+	if configBuilder == nil {
+		return nil, fmt.Errorf("cannot render the containerd config")
+	}
+	configStep, err := configBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+	nvidiaStep, err := containerd.NewConfigureNvidiaRuntimeStepBuilder(ctx, instanceName).Build()
+	if err != nil {
+		return nil, err
+	}
+	return &containerdConfigRenderer{config: configStep, nvidia: nvidiaStep}, nil
+}
+
+func (r *containerdConfigRenderer) Render(ctx runtime.ExecutionContext) (string, error) {
+	content, err := r.config.Render(ctx)
+	if err != nil {
+		return "", err
+	}
+	return r.nvidia.Merge(ctx, content)
+}
+
+func (r *containerdConfigRenderer) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := r.Render(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: r.config.TargetPath, Content: []byte(content)}}, nil
+}
+
+// NewReconcileKubeletConfigStepBuilder reconciles the kubelet config.yaml rendered by CreateKubeletConfigYAMLStep.
+func NewReconcileKubeletConfigStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ReconcileConfigStepBuilder {
+	configStep, err := kubelet.NewCreateKubeletConfigYAMLStepBuilder(ctx, instanceName).Build()
+	if err != nil {
+		return nil
+	}
+	return NewReconcileConfigStepBuilder(ctx, instanceName, ComponentKubelet, configStep.RemoteConfigYAMLFile, common.KubeletServiceName, configStep.Render)
+}
+
+func (s *ReconcileConfigStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// checksums returns the desired content with its checksum and the checksum of the live file,
+// which is empty when the file does not exist.
+func (s *ReconcileConfigStep) checksums(ctx runtime.ExecutionContext) (desired, desiredSum, liveSum string, err error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	desired, err = s.Render(ctx)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to render desired %s config: %w", s.Component, err)
+	}
+	desiredSum = fmt.Sprintf("%x", sha256.Sum256([]byte(desired)))
+
+	exists, err := runner.Exists(ctx.GoContext(), conn, s.TargetPath)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to check for config file '%s': %w", s.TargetPath, err)
+	}
+	if !exists {
+		return desired, desiredSum, "", nil
+	}
+	live, err := runner.ReadFile(ctx.GoContext(), conn, s.TargetPath)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read config file '%s': %w", s.TargetPath, err)
+	}
+	return desired, desiredSum, fmt.Sprintf("%x", sha256.Sum256(live)), nil
+}
+
+func (s *ReconcileConfigStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	_, desiredSum, liveSum, err := s.checksums(ctx)
+	if err != nil {
+		return false, err
+	}
+	if desiredSum == liveSum {
+		logger.Info("Config file matches the desired configuration.", "component", s.Component, "path", s.TargetPath)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *ReconcileConfigStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	desired, desiredSum, liveSum, err := s.checksums(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to compare config checksums")
+		return result, err
+	}
+	if desiredSum == liveSum {
+		result.MarkCompleted(fmt.Sprintf("%s config is in sync", s.Component))
+		return result, nil
+	}
+
+	logger.Warn("Config file drifted from the desired configuration, reconciling.",
+		"component", s.Component, "path", s.TargetPath, "desired", desiredSum, "live", liveSum)
+	if backupPath, err := helpers.BackupRemoteConfig(ctx, conn, s.TargetPath, s.BackupRetention, s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to back up drifted config")
+		return result, err
+	} else if backupPath != "" {
+		logger.Info("Backed up drifted config file.", "backup", backupPath)
+	}
+	if err := runner.Mkdirp(ctx.GoContext(), conn, filepath.Dir(s.TargetPath), "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to create config directory")
+		return result, err
+	}
+	if err := runner.WriteFile(ctx.GoContext(), conn, []byte(desired), s.TargetPath, "0644", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to rewrite drifted config")
+		return result, err
+	}
+
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "failed to gather host facts")
+		return result, err
+	}
+	if err := runner.RestartService(ctx.GoContext(), conn, facts, s.ServiceName); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to restart %s", s.ServiceName))
+		return result, err
+	}
+
+	drift := &ConfigDrift{Node: ctx.GetHost().GetName(), Component: s.Component, Path: s.TargetPath, Desired: desiredSum, Live: liveSum}
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyNodeConfigDrift, ctx.GetRunID(), drift.Node, s.Component), drift)
+
+	result.SetMetadata("drifted", true)
+	result.MarkCompleted(fmt.Sprintf("%s config drift reconciled and %s restarted", s.Component, s.ServiceName))
+	return result, nil
+}
+
+func (s *ReconcileConfigStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Drifted configs are backed up before being rewritten; no automatic rollback is performed.")
+	return nil
+}
+
+// ConfigDrifts returns the node config drift reconciled during a run, sorted by node and component.
+func ConfigDrifts(pipelineCache cache.PipelineCache, runID string) []ConfigDrift {
+	prefix := fmt.Sprintf("kubexm.run[%s].node[", runID)
+	var drifts []ConfigDrift
+	pipelineCache.Range(func(key string, value interface{}) bool {
+		if d, ok := value.(*ConfigDrift); ok && strings.HasPrefix(key, prefix) {
+			drifts = append(drifts, *d)
+		}
+		return true
+	})
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Node != drifts[j].Node {
+			return drifts[i].Node < drifts[j].Node
+		}
+		return drifts[i].Component < drifts[j].Component
+	})
+	return drifts
+}
+
+var _ step.Step = (*ReconcileConfigStep)(nil)
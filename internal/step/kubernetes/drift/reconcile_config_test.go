@@ -0,0 +1,106 @@
+package drift
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/cache"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+type fakeNodeRunner struct {
+	runner.Runner
+	files    map[string]string
+	writes   []string
+	restarts []string
+}
+
+func (r *fakeNodeRunner) Exists(ctx context.Context, conn connector.Connector, path string) (bool, error) {
+	_, ok := r.files[path]
+	return ok, nil
+}
+
+func (r *fakeNodeRunner) ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error) {
+	return []byte(r.files[path]), nil
+}
+
+func (r *fakeNodeRunner) CopyFile(ctx context.Context, conn connector.Connector, src, dest string, recursive bool, sudo bool) error {
+	r.files[dest] = r.files[src]
+	return nil
+}
+
+func (r *fakeNodeRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	return &runner.CommandResult{}, nil
+}
+
+func (r *fakeNodeRunner) Mkdirp(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeNodeRunner) WriteFile(ctx context.Context, conn connector.Connector, content []byte, destPath, permissions string, sudo bool) error {
+	r.files[destPath] = string(content)
+	r.writes = append(r.writes, destPath)
+	return nil
+}
+
+func (r *fakeNodeRunner) RestartService(ctx context.Context, conn connector.Connector, facts *runner.Facts, serviceName string) error {
+	r.restarts = append(r.restarts, serviceName)
+	return nil
+}
+
+func TestReconcileConfigStep_OnlyDriftedNodeIsRewritten(t *testing.T) {
+	const path = "/etc/containerd/config.toml"
+	desired := func(ctx runtime.ExecutionContext) (string, error) {
+		return "version = 2\nsandbox_image = \"pause:3.9\"\n", nil
+	}
+
+	pipelineCache := cache.NewPipelineCache()
+	nodes := map[string]*fakeNodeRunner{
+		"in-sync": {files: map[string]string{path: "version = 2\nsandbox_image = \"pause:3.9\"\n"}},
+		"drifted": {files: map[string]string{path: "version = 2\nsandbox_image = \"pause:3.6\"\n"}},
+	}
+
+	for _, name := range []string{"in-sync", "drifted"} {
+		r := nodes[name]
+		ctx := &runtimetest.Context{
+			Runner:        r,
+			Host:          connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Address: "10.0.0.1"}),
+			PipelineCache: pipelineCache,
+			RunID:         "run-1",
+		}
+		s, err := NewReconcileConfigStepBuilder(ctx, "ReconcileContainerdConfig", ComponentContainerd, path, "containerd", desired).Build()
+		if err != nil {
+			t.Fatalf("failed to build step: %v", err)
+		}
+
+		done, err := s.Precheck(ctx)
+		if err != nil {
+			t.Fatalf("%s: precheck failed: %v", name, err)
+		}
+		if done {
+			continue
+		}
+		if _, err := s.Run(ctx); err != nil {
+			t.Fatalf("%s: run failed: %v", name, err)
+		}
+	}
+
+	if len(nodes["in-sync"].writes) != 0 || len(nodes["in-sync"].restarts) != 0 {
+		t.Errorf("in-sync node should not be touched, got writes %v and restarts %v", nodes["in-sync"].writes, nodes["in-sync"].restarts)
+	}
+	if len(nodes["drifted"].writes) != 1 || nodes["drifted"].writes[0] != path {
+		t.Errorf("drifted node should have %s rewritten once, got %v", path, nodes["drifted"].writes)
+	}
+	if len(nodes["drifted"].restarts) != 1 || nodes["drifted"].restarts[0] != "containerd" {
+		t.Errorf("drifted node should have containerd restarted once, got %v", nodes["drifted"].restarts)
+	}
+
+	drifts := ConfigDrifts(pipelineCache, "run-1")
+	if len(drifts) != 1 || drifts[0].Node != "drifted" || drifts[0].Component != ComponentContainerd {
+		t.Errorf("expected only the drifted node to be reported, got %+v", drifts)
+	}
+}
@@ -0,0 +1,259 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+const (
+	CategoryNode    = "node"
+	CategoryVersion = "version"
+	CategoryAddon   = "addon"
+	CategoryConfig  = "config"
+)
+
+// Item describes a single difference between the desired configuration and the live cluster.
+type Item struct {
+	Category string `json:"category"`
+	Object   string `json:"object"`
+	Field    string `json:"field"`
+	Desired  string `json:"desired"`
+	Live     string `json:"live"`
+	// Diff is the unified diff from the live to the desired file for config items.
+	Diff string `json:"diff,omitempty"`
+}
+
+// Report is the result of comparing the desired cluster state against the live cluster.
+type Report struct {
+	ClusterName string `json:"clusterName"`
+	Items       []Item `json:"items"`
+}
+
+// NodeState holds the node attributes that are compared for drift.
+type NodeState struct {
+	Labels         map[string]string
+	Taints         []string
+	KubeletVersion string
+	// ContainerRuntime is "<type>://<version>", as reported in the node status.
+	ContainerRuntime string
+}
+
+// ClusterState is a normalized view of either the desired or the live cluster.
+type ClusterState struct {
+	KubernetesVersion string
+	Nodes             map[string]NodeState
+	Addons            []string
+}
+
+// DesiredStateFromConfig builds the desired state from the cluster configuration.
+// Only addons installed from a chart are tracked, since they can be matched against helm releases.
+func DesiredStateFromConfig(cluster *v1alpha1.Cluster) *ClusterState {
+	state := &ClusterState{Nodes: make(map[string]NodeState)}
+	if cluster == nil || cluster.Spec == nil {
+		return state
+	}
+	if cluster.Spec.Kubernetes != nil {
+		state.KubernetesVersion = cluster.Spec.Kubernetes.Version
+	}
+	containerRuntime := desiredContainerRuntime(cluster.Spec.Kubernetes)
+	for _, host := range cluster.Spec.Hosts {
+		node := NodeState{Labels: host.Labels, KubeletVersion: state.KubernetesVersion, ContainerRuntime: containerRuntime}
+		for _, taint := range host.Taints {
+			node.Taints = append(node.Taints, formatTaint(taint.Key, taint.Value, taint.Effect))
+		}
+		state.Nodes[host.Name] = node
+	}
+	for _, addon := range cluster.Spec.Addons {
+		if addon.Enabled == nil || !*addon.Enabled {
+			continue
+		}
+		for _, source := range addon.Sources {
+			if source.Chart != nil && source.Chart.Name != "" {
+				state.Addons = append(state.Addons, source.Chart.Name)
+			}
+		}
+	}
+	return state
+}
+
+// LiveStateFromCluster builds the live state from kubectl and helm query results.
+func LiveStateFromCluster(nodes []runner.KubectlNodeInfo, version *runner.KubectlVersionInfo, releases []runner.HelmReleaseInfo) *ClusterState {
+	state := &ClusterState{Nodes: make(map[string]NodeState)}
+	if version != nil && version.ServerVersion != nil {
+		state.KubernetesVersion = version.ServerVersion.GitVersion
+	}
+	for _, node := range nodes {
+		live := NodeState{
+			Labels:           node.Metadata.Labels,
+			KubeletVersion:   node.Status.NodeInfo.KubeletVersion,
+			ContainerRuntime: node.Status.NodeInfo.ContainerRuntimeVersion,
+		}
+		for _, taint := range node.Spec.Taints {
+			live.Taints = append(live.Taints, formatTaint(taint.Key, taint.Value, taint.Effect))
+		}
+		state.Nodes[node.Metadata.Name] = live
+	}
+	for _, release := range releases {
+		state.Addons = append(state.Addons, release.Name)
+	}
+	return state
+}
+
+// Compare reports every desired attribute that is missing or different in the live state.
+// Live-only labels, taints and releases are not reported because the cluster adds its own.
+func Compare(clusterName string, desired, live *ClusterState) *Report {
+	report := &Report{ClusterName: clusterName}
+
+	if desired.KubernetesVersion != "" && live.KubernetesVersion != "" &&
+		normalizeVersion(desired.KubernetesVersion) != normalizeVersion(live.KubernetesVersion) {
+		report.add(CategoryVersion, "kube-apiserver", "version", desired.KubernetesVersion, live.KubernetesVersion)
+	}
+
+	for _, name := range sortedKeys(desired.Nodes) {
+		want := desired.Nodes[name]
+		got, ok := live.Nodes[name]
+		if !ok {
+			report.add(CategoryNode, name, "registered", "true", "false")
+			continue
+		}
+		for _, key := range sortedKeys(want.Labels) {
+			liveValue, found := got.Labels[key]
+			if !found {
+				report.add(CategoryNode, name, "label "+key, want.Labels[key], "<missing>")
+			} else if liveValue != want.Labels[key] {
+				report.add(CategoryNode, name, "label "+key, want.Labels[key], liveValue)
+			}
+		}
+		for _, taint := range want.Taints {
+			if !containsString(got.Taints, taint) {
+				report.add(CategoryNode, name, "taint", taint, "<missing>")
+			}
+		}
+		if want.KubeletVersion != "" && got.KubeletVersion != "" &&
+			normalizeVersion(want.KubeletVersion) != normalizeVersion(got.KubeletVersion) {
+			report.add(CategoryVersion, name, "kubelet", want.KubeletVersion, got.KubeletVersion)
+		}
+		if want.ContainerRuntime != "" && got.ContainerRuntime != "" && !sameContainerRuntime(want.ContainerRuntime, got.ContainerRuntime) {
+			report.add(CategoryVersion, name, "container runtime", want.ContainerRuntime, got.ContainerRuntime)
+		}
+	}
+
+	for _, addon := range desired.Addons {
+		if !containsString(live.Addons, addon) {
+			report.add(CategoryAddon, addon, "release", "installed", "<missing>")
+		}
+	}
+	return report
+}
+
+// HasDrift reports whether any difference was found.
+func (r *Report) HasDrift() bool {
+	return r != nil && len(r.Items) > 0
+}
+
+// Merge appends items, such as the config drift collected on each host, to the report.
+func (r *Report) Merge(items []Item) {
+	r.Items = append(r.Items, items...)
+}
+
+// String renders the report as a human-readable table, followed by the diff of every drifted config file.
+func (r *Report) String() string {
+	if !r.HasDrift() {
+		return fmt.Sprintf("No drift detected for cluster '%s'; apply would be a no-op.\n", r.ClusterName)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Drift detected for cluster '%s' (%d difference(s)):\n", r.ClusterName, len(r.Items))
+	for _, item := range r.Items {
+		fmt.Fprintf(&b, "  [%s] %s %s: desired=%s live=%s\n", item.Category, item.Object, item.Field, item.Desired, item.Live)
+	}
+	for _, item := range r.Items {
+		if item.Diff == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n")
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(item.Diff, "\n"), "\n") {
+			fmt.Fprintf(&b, "    %s", line)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+	return b.String()
+}
+
+func (r *Report) add(category, object, field, desired, live string) {
+	r.Items = append(r.Items, Item{Category: category, Object: object, Field: field, Desired: desired, Live: live})
+}
+
+// desiredContainerRuntime returns the configured runtime as "<type>://<version>", or "" when the
+// version is left to the BOM and cannot be compared.
+func desiredContainerRuntime(k8s *v1alpha1.Kubernetes) string {
+	if k8s == nil || k8s.ContainerRuntime == nil || k8s.ContainerRuntime.Type == "" {
+		return ""
+	}
+	cr := k8s.ContainerRuntime
+	version := cr.Version
+	if version == "" {
+		switch cr.Type {
+		case common.RuntimeTypeContainerd:
+			if cr.Containerd != nil {
+				version = cr.Containerd.Version
+			}
+		case common.RuntimeTypeDocker:
+			if cr.Docker != nil {
+				version = cr.Docker.Version
+			}
+		case common.RuntimeTypeCRIO:
+			if cr.Crio != nil {
+				version = cr.Crio.Version
+			}
+		}
+	}
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s", cr.Type, version)
+}
+
+// sameContainerRuntime compares two "<type>://<version>" strings, ignoring version prefixes and suffixes.
+func sameContainerRuntime(desired, live string) bool {
+	wantType, wantVersion, _ := strings.Cut(desired, "://")
+	gotType, gotVersion, _ := strings.Cut(live, "://")
+	return wantType == gotType && normalizeVersion(wantVersion) == normalizeVersion(gotVersion)
+}
+
+func formatTaint(key, value, effect string) string {
+	if value == "" {
+		return fmt.Sprintf("%s:%s", key, effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", key, value, effect)
+}
+
+func normalizeVersion(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexAny(v, "+-"); idx >= 0 {
+		v = v[:idx]
+	}
+	return v
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,169 @@
+package drift
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+const testNodesJSON = `[
+  {
+    "metadata": {"name": "master1", "labels": {"kubernetes.io/hostname": "master1", "tier": "frontend"}},
+    "spec": {"taints": [{"key": "node-role.kubernetes.io/control-plane", "effect": "NoSchedule"}]},
+    "status": {"nodeInfo": {"kubeletVersion": "v1.28.2"}}
+  },
+  {
+    "metadata": {"name": "worker1", "labels": {"kubernetes.io/hostname": "worker1", "gpu": "true"}},
+    "spec": {},
+    "status": {"nodeInfo": {"kubeletVersion": "v1.27.6"}}
+  }
+]`
+
+func testDesiredCluster() *v1alpha1.Cluster {
+	enabled := true
+	return &v1alpha1.Cluster{
+		Spec: &v1alpha1.ClusterSpec{
+			Kubernetes: &v1alpha1.Kubernetes{Version: "v1.28.2"},
+			Hosts: []v1alpha1.HostSpec{
+				{
+					Name:   "master1",
+					Labels: map[string]string{"tier": "frontend"},
+					Taints: []v1alpha1.TaintSpec{{Key: "node-role.kubernetes.io/control-plane", Effect: "NoSchedule"}},
+				},
+				{Name: "worker1", Labels: map[string]string{"gpu": "false"}},
+			},
+			Addons: []v1alpha1.Addon{
+				{Name: "metrics", Enabled: &enabled, Sources: []v1alpha1.AddonSource{{Chart: &v1alpha1.ChartSource{Name: "metrics-server"}}}},
+			},
+		},
+	}
+}
+
+func testLiveState(t *testing.T, serverVersion string) *ClusterState {
+	t.Helper()
+	var nodes []runner.KubectlNodeInfo
+	if err := json.Unmarshal([]byte(testNodesJSON), &nodes); err != nil {
+		t.Fatalf("failed to decode test nodes: %v", err)
+	}
+	version := &runner.KubectlVersionInfo{}
+	if err := json.Unmarshal([]byte(`{"serverVersion": {"gitVersion": "`+serverVersion+`"}}`), version); err != nil {
+		t.Fatalf("failed to decode test version: %v", err)
+	}
+	releases := []runner.HelmReleaseInfo{{Name: "metrics-server", Namespace: "kube-system"}}
+	return LiveStateFromCluster(nodes, version, releases)
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverVersion string
+		expected      []Item
+	}{
+		{
+			name:          "node label and kubelet version drift",
+			serverVersion: "v1.28.2",
+			expected: []Item{
+				{Category: CategoryNode, Object: "worker1", Field: "label gpu", Desired: "false", Live: "true"},
+				{Category: CategoryVersion, Object: "worker1", Field: "kubelet", Desired: "v1.28.2", Live: "v1.27.6"},
+			},
+		},
+		{
+			name:          "apiserver version drift",
+			serverVersion: "v1.29.0",
+			expected: []Item{
+				{Category: CategoryVersion, Object: "kube-apiserver", Field: "version", Desired: "v1.28.2", Live: "v1.29.0"},
+				{Category: CategoryNode, Object: "worker1", Field: "label gpu", Desired: "false", Live: "true"},
+				{Category: CategoryVersion, Object: "worker1", Field: "kubelet", Desired: "v1.28.2", Live: "v1.27.6"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Compare("test", DesiredStateFromConfig(testDesiredCluster()), testLiveState(t, tt.serverVersion))
+			if len(report.Items) != len(tt.expected) {
+				t.Fatalf("expected %d items, got %d: %+v", len(tt.expected), len(report.Items), report.Items)
+			}
+			for i := range tt.expected {
+				if report.Items[i] != tt.expected[i] {
+					t.Errorf("item %d: expected %+v, got %+v", i, tt.expected[i], report.Items[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompare_NoDrift(t *testing.T) {
+	desired := DesiredStateFromConfig(testDesiredCluster())
+	live := testLiveState(t, "v1.28.2+k3s1")
+	desired.Nodes["worker1"] = NodeState{Labels: map[string]string{"gpu": "true"}}
+
+	report := Compare("test", desired, live)
+	if report.HasDrift() {
+		t.Fatalf("expected no drift, got %+v", report.Items)
+	}
+	if !strings.Contains(report.String(), "No drift detected") || !strings.Contains(report.String(), "apply would be a no-op") {
+		t.Errorf("unexpected report output: %s", report.String())
+	}
+}
+
+func TestCompare_MissingNodeAndAddon(t *testing.T) {
+	desired := DesiredStateFromConfig(testDesiredCluster())
+	desired.Nodes["worker2"] = NodeState{}
+	live := testLiveState(t, "v1.28.2")
+	live.Addons = nil
+
+	report := Compare("test", desired, live)
+	out := report.String()
+	for _, want := range []string{"[node] worker2 registered", "[addon] metrics-server release"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompare_ContainerRuntimeVersion(t *testing.T) {
+	cluster := testDesiredCluster()
+	cluster.Spec.Kubernetes.ContainerRuntime = &v1alpha1.ContainerRuntime{
+		Type:       common.RuntimeTypeContainerd,
+		Containerd: &v1alpha1.Containerd{Version: "1.7.13"},
+	}
+	desired := DesiredStateFromConfig(cluster)
+	live := testLiveState(t, "v1.28.2")
+	master := live.Nodes["master1"]
+	master.ContainerRuntime = "containerd://1.7.13"
+	live.Nodes["master1"] = master
+	worker := live.Nodes["worker1"]
+	worker.ContainerRuntime = "containerd://1.6.28"
+	live.Nodes["worker1"] = worker
+
+	report := Compare("test", desired, live)
+	var found []Item
+	for _, item := range report.Items {
+		if item.Field == "container runtime" {
+			found = append(found, item)
+		}
+	}
+	expected := Item{Category: CategoryVersion, Object: "worker1", Field: "container runtime", Desired: "containerd://1.7.13", Live: "containerd://1.6.28"}
+	if len(found) != 1 || found[0] != expected {
+		t.Errorf("expected only %+v, got %+v", expected, found)
+	}
+}
+
+func TestReport_StringIncludesConfigDiff(t *testing.T) {
+	report := &Report{ClusterName: "test"}
+	report.Merge([]Item{{
+		Category: CategoryConfig, Object: "worker1", Field: "/etc/containerd/config.toml",
+		Desired: "sha256:aaaa", Live: "sha256:bbbb", Diff: "--- a\n+++ b\n@@ -1,1 +1,1 @@\n-old\n+new\n",
+	}})
+	out := report.String()
+	for _, want := range []string{"[config] worker1 /etc/containerd/config.toml", "    -old\n    +new\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+}
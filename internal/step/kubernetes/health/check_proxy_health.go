@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -46,6 +47,10 @@ func (s *VerifyKubeProxyHealthStep) Meta() *spec.StepMeta {
 
 func (s *VerifyKubeProxyHealthStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if !v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec) {
+		logger.Info("kube-proxy is not deployed in this cluster. Step is done.")
+		return true, nil
+	}
 	logger.Info("Starting precheck: verifying required commands are available...")
 
 	conn, err := ctx.GetCurrentHostConnector()
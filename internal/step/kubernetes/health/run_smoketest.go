@@ -0,0 +1,323 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+const (
+	smokeTestNamespace = "kubexm-smoketest"
+	smokeTestName      = "kubexm-smoketest"
+	// smokeTestPort is the port busybox httpd listens on in the smoke-test pod.
+	smokeTestPort         = 8080
+	defaultSmokeTestImage = "docker.io/library/busybox:1.36.1"
+)
+
+const smokeTestManifestTemplate = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Namespace }}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Name }}
+    spec:
+      containers:
+      - name: httpd
+        image: {{ .Image }}
+        command: ["sh", "-c", "mkdir -p /www && echo {{ .Name }} > /www/index.html && exec httpd -f -p {{ .Port }} -h /www"]
+        ports:
+        - containerPort: {{ .Port }}
+        readinessProbe:
+          httpGet:
+            path: /
+            port: {{ .Port }}
+          periodSeconds: 2
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  selector:
+    app: {{ .Name }}
+  ports:
+  - port: 80
+    targetPort: {{ .Port }}
+`
+
+// RunSmokeTestStep deploys a tiny HTTP server behind a Service, verifies that the pod becomes Ready,
+// the Service gets endpoints and its cluster IP answers from the node, and removes the workload again
+// regardless of the outcome.
+type RunSmokeTestStep struct {
+	step.Base
+	Namespace      string
+	Name           string
+	Image          string
+	Port           int
+	KubeconfigPath string
+	PollInterval   time.Duration
+	ReadyTimeout   time.Duration
+}
+
+type RunSmokeTestStepBuilder struct {
+	step.Builder[RunSmokeTestStepBuilder, *RunSmokeTestStep]
+}
+
+func NewRunSmokeTestStepBuilder(ctx runtime.ExecutionContext, instanceName string) *RunSmokeTestStepBuilder {
+	s := &RunSmokeTestStep{
+		Namespace:      smokeTestNamespace,
+		Name:           smokeTestName,
+		Image:          defaultSmokeTestImage,
+		Port:           smokeTestPort,
+		KubeconfigPath: filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+		PollInterval:   5 * time.Second,
+		ReadyTimeout:   3 * time.Minute,
+	}
+	if image := images.NewImageProvider(ctx).GetImage("busybox"); image != nil {
+		s.Image = image.FullName()
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Deploy a smoke-test workload and verify it is scheduled and served", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(RunSmokeTestStepBuilder).Init(s)
+	return b
+}
+
+func (b *RunSmokeTestStepBuilder) WithPolling(interval, timeout time.Duration) *RunSmokeTestStepBuilder {
+	b.Step.PollInterval = interval
+	b.Step.ReadyTimeout = timeout
+	return b
+}
+
+func (s *RunSmokeTestStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *RunSmokeTestStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *RunSmokeTestStep) renderManifest() (string, error) {
+	tmpl, err := template.New("smoketest").Parse(smokeTestManifestTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse smoke-test manifest template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return "", fmt.Errorf("failed to render smoke-test manifest: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (s *RunSmokeTestStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	manifest, err := s.renderManifest()
+	if err != nil {
+		result.MarkFailed(err, "failed to render smoke-test manifest")
+		return result, err
+	}
+
+	logger.Info("Deploying smoke-test workload.", "namespace", s.Namespace, "image", s.Image)
+	if _, err := runnerSvc.KubectlApply(ctx.GoContext(), conn, runner.KubectlApplyOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Filenames:      []string{"-"},
+		FileContent:    manifest,
+		Sudo:           s.Sudo,
+	}); err != nil {
+		result.MarkFailed(err, "failed to deploy smoke-test workload")
+		return result, fmt.Errorf("failed to deploy smoke-test workload: %w", err)
+	}
+	defer s.teardown(ctx, conn)
+
+	if err := s.waitFor(ctx, func() (bool, error) { return s.podReady(ctx, conn) }); err != nil {
+		err = fmt.Errorf("smoke-test pod did not become Ready within %v: %w", s.ReadyTimeout, err)
+		result.MarkFailed(err, "smoke test failed: pod not Ready")
+		return result, err
+	}
+	logger.Info("Smoke-test pod is Ready.")
+
+	if err := s.waitFor(ctx, func() (bool, error) { return s.serviceHasEndpoints(ctx, conn) }); err != nil {
+		err = fmt.Errorf("smoke-test service got no endpoints within %v: %w", s.ReadyTimeout, err)
+		result.MarkFailed(err, "smoke test failed: service has no endpoints")
+		return result, err
+	}
+	logger.Info("Smoke-test service has endpoints.")
+
+	if err := s.waitFor(ctx, func() (bool, error) { return s.serviceServes(ctx, conn) }); err != nil {
+		err = fmt.Errorf("smoke-test service did not answer within %v: %w", s.ReadyTimeout, err)
+		result.MarkFailed(err, "smoke test failed: service not reachable")
+		return result, err
+	}
+	logger.Info("Smoke-test service answered on its cluster IP. Smoke test passed.")
+
+	result.MarkCompleted("smoke test passed")
+	return result, nil
+}
+
+func (s *RunSmokeTestStep) waitFor(ctx runtime.ExecutionContext, check func() (bool, error)) error {
+	deadline := time.Now().Add(s.ReadyTimeout)
+	var lastErr error
+	for {
+		ok, err := check()
+		if ok {
+			return nil
+		}
+		lastErr = err
+		if time.Now().After(deadline) {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("condition not met")
+			}
+			return lastErr
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			return ctx.GoContext().Err()
+		case <-time.After(s.PollInterval):
+		}
+	}
+}
+
+func (s *RunSmokeTestStep) podReady(ctx runtime.ExecutionContext, conn runner.Connector) (bool, error) {
+	pods, err := ctx.GetRunner().KubectlGetPods(ctx.GoContext(), conn, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Namespace:      s.Namespace,
+		Selector:       "app=" + s.Name,
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == "Ready" && cond.Status == "True" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (s *RunSmokeTestStep) serviceHasEndpoints(ctx runtime.ExecutionContext, conn runner.Connector) (bool, error) {
+	raw, err := ctx.GetRunner().KubectlGet(ctx.GoContext(), conn, "endpoints", s.Name, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Namespace:      s.Namespace,
+		OutputFormat:   "json",
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		return false, err
+	}
+	var endpoints struct {
+		Subsets []struct {
+			Addresses []struct {
+				IP string `json:"ip"`
+			} `json:"addresses"`
+		} `json:"subsets"`
+	}
+	if err := json.Unmarshal([]byte(raw), &endpoints); err != nil {
+		return false, fmt.Errorf("failed to parse endpoints: %w", err)
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// serviceServes fetches the page of the smoke-test pod through the cluster IP of its Service from
+// the node, which only works once kube-proxy or the CNI plugin routes Service traffic.
+func (s *RunSmokeTestStep) serviceServes(ctx runtime.ExecutionContext, conn runner.Connector) (bool, error) {
+	raw, err := ctx.GetRunner().KubectlGet(ctx.GoContext(), conn, "service", s.Name, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Namespace:      s.Namespace,
+		OutputFormat:   "json",
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		return false, err
+	}
+	var svc struct {
+		Spec struct {
+			ClusterIP string `json:"clusterIP"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(raw), &svc); err != nil {
+		return false, fmt.Errorf("failed to parse service: %w", err)
+	}
+	if svc.Spec.ClusterIP == "" {
+		return false, fmt.Errorf("service %s/%s has no cluster IP", s.Namespace, s.Name)
+	}
+	url := "http://" + net.JoinHostPort(svc.Spec.ClusterIP, "80") + "/"
+	out, err := ctx.GetRunner().Run(ctx.GoContext(), conn, "curl -sf --max-time 5 "+url, false)
+	if err != nil {
+		return false, err
+	}
+	if !strings.Contains(out.Stdout, s.Name) {
+		return false, fmt.Errorf("unexpected response from %s: %q", url, out.Stdout)
+	}
+	return true, nil
+}
+
+func (s *RunSmokeTestStep) teardown(ctx runtime.ExecutionContext, conn runner.Connector) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Teardown")
+	if err := ctx.GetRunner().KubectlDelete(ctx.GoContext(), conn, "namespace", s.Namespace, runner.KubectlDeleteOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		IgnoreNotFound: true,
+		Sudo:           s.Sudo,
+	}); err != nil {
+		logger.Warn(err, "Failed to remove smoke-test workload.", "namespace", s.Namespace)
+		return
+	}
+	logger.Info("Smoke-test workload removed.", "namespace", s.Namespace)
+}
+
+func (s *RunSmokeTestStep) Rollback(ctx runtime.ExecutionContext) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+	s.teardown(ctx, conn)
+	return nil
+}
+
+var _ step.Step = (*RunSmokeTestStep)(nil)
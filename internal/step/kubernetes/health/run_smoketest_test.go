@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+type fakeSmokeTestRunner struct {
+	runner.Runner
+	readyAfterPolls int
+	podPolls        int
+	endpointsJSON   string
+	curlOutput      string
+	curled          []string
+	applied         string
+	deleted         []string
+}
+
+func (r *fakeSmokeTestRunner) KubectlApply(ctx context.Context, conn connector.Connector, opts runner.KubectlApplyOptions) (string, error) {
+	r.applied = opts.FileContent
+	return "", nil
+}
+
+func (r *fakeSmokeTestRunner) KubectlGetPods(ctx context.Context, conn connector.Connector, opts runner.KubectlGetOptions) ([]runner.KubectlPodInfo, error) {
+	r.podPolls++
+	pod := runner.KubectlPodInfo{}
+	pod.Status.Phase = "Pending"
+	if r.readyAfterPolls > 0 && r.podPolls >= r.readyAfterPolls {
+		pod.Status.Phase = "Running"
+		pod.Status.Conditions = append(pod.Status.Conditions, struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		}{Type: "Ready", Status: "True"})
+	}
+	return []runner.KubectlPodInfo{pod}, nil
+}
+
+func (r *fakeSmokeTestRunner) KubectlGet(ctx context.Context, conn connector.Connector, resourceType, resourceName string, opts runner.KubectlGetOptions) (string, error) {
+	if resourceType == "service" {
+		return `{"spec": {"clusterIP": "10.96.0.42"}}`, nil
+	}
+	return r.endpointsJSON, nil
+}
+
+func (r *fakeSmokeTestRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	r.curled = append(r.curled, cmd)
+	if r.curlOutput == "" {
+		return &runner.CommandResult{ExitCode: 7}, fmt.Errorf("curl: (7) Failed to connect")
+	}
+	return &runner.CommandResult{Stdout: r.curlOutput}, nil
+}
+
+func (r *fakeSmokeTestRunner) KubectlDelete(ctx context.Context, conn connector.Connector, resourceType, resourceName string, opts runner.KubectlDeleteOptions) error {
+	r.deleted = append(r.deleted, resourceType+"/"+resourceName)
+	return nil
+}
+
+func TestRunSmokeTestStep(t *testing.T) {
+	tests := []struct {
+		name            string
+		readyAfterPolls int
+		endpointsJSON   string
+		curlOutput      string
+		expectedStatus  types.StepStatus
+		expectedErr     string
+	}{
+		{
+			name:            "pod becomes Ready and service answers",
+			readyAfterPolls: 3,
+			endpointsJSON:   `{"subsets": [{"addresses": [{"ip": "10.244.1.5"}]}]}`,
+			curlOutput:      smokeTestName + "\n",
+			expectedStatus:  types.StepStatusCompleted,
+		},
+		{
+			name:            "service has endpoints but does not answer",
+			readyAfterPolls: 1,
+			endpointsJSON:   `{"subsets": [{"addresses": [{"ip": "10.244.1.5"}]}]}`,
+			expectedStatus:  types.StepStatusFailed,
+			expectedErr:     "did not answer",
+		},
+		{
+			name:            "pod never becomes Ready",
+			readyAfterPolls: 0,
+			endpointsJSON:   `{"subsets": []}`,
+			expectedStatus:  types.StepStatusFailed,
+			expectedErr:     "did not become Ready",
+		},
+		{
+			name:            "service never gets endpoints",
+			readyAfterPolls: 1,
+			endpointsJSON:   `{"subsets": []}`,
+			expectedStatus:  types.StepStatusFailed,
+			expectedErr:     "got no endpoints",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeSmokeTestRunner{readyAfterPolls: tt.readyAfterPolls, endpointsJSON: tt.endpointsJSON, curlOutput: tt.curlOutput}
+			ctx := &runtimetest.Context{
+				Runner: r,
+				Host:   connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1"}),
+				Cluster: &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+					Kubernetes: &v1alpha1.Kubernetes{Version: "v1.28.2"},
+					Registry:   &v1alpha1.Registry{MirroringAndRewriting: &v1alpha1.RegistryMirroringAndRewriting{}},
+				}},
+			}
+			s, err := NewRunSmokeTestStepBuilder(ctx, "RunSmokeTest").
+				WithPolling(time.Millisecond, 50*time.Millisecond).Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+
+			result, err := s.Run(ctx)
+			if result.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, result.Status)
+			}
+			if tt.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if !strings.Contains(r.applied, "kind: Deployment") || !strings.Contains(r.applied, "kind: Service") {
+				t.Errorf("expected a Deployment and Service to be applied, got:\n%s", r.applied)
+			}
+			if tt.curlOutput != "" && (len(r.curled) == 0 || !strings.Contains(r.curled[0], "http://10.96.0.42:80/")) {
+				t.Errorf("expected the service cluster IP to be fetched, got %v", r.curled)
+			}
+			if len(r.deleted) != 1 || r.deleted[0] != "namespace/"+smokeTestNamespace {
+				t.Errorf("expected smoke-test namespace to be torn down, got %v", r.deleted)
+			}
+		})
+	}
+}
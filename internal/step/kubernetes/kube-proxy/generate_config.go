@@ -21,6 +21,8 @@ type CreateKubeProxyConfigYAMLStep struct {
 	KubeconfigPath       string
 	ClusterCIDR          string
 	Mode                 string
+	IPVSScheduler        string
+	ExcludeCIDRs         []string
 	FeatureGates         map[string]bool
 	RemoteConfigYAMLFile string
 }
@@ -46,6 +48,15 @@ func NewCreateKubeProxyConfigYAMLStepBuilder(ctx runtime.ExecutionContext, insta
 	if k8sSpec.KubeProxy.Mode != "" {
 		s.Mode = k8sSpec.KubeProxy.Mode
 	}
+	if s.Mode == common.KubeProxyModeIPVS {
+		s.IPVSScheduler = common.DefaultIPVSScheduler
+		if ipvs := k8sSpec.KubeProxy.IPVS; ipvs != nil {
+			if ipvs.Scheduler != "" {
+				s.IPVSScheduler = ipvs.Scheduler
+			}
+			s.ExcludeCIDRs = ipvs.ExcludeCIDRs
+		}
+	}
 	if k8sSpec.KubeProxy.FeatureGates != nil {
 		s.FeatureGates = k8sSpec.KubeProxy.FeatureGates
 	}
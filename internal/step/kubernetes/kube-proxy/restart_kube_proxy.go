@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -42,6 +43,10 @@ func (s *RestartKubeProxyStep) Meta() *spec.StepMeta {
 
 func (s *RestartKubeProxyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if !v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec) {
+		logger.Info("kube-proxy is not deployed in this cluster. Step is done.")
+		return true, nil
+	}
 	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
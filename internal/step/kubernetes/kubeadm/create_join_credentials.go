@@ -0,0 +1,121 @@
+package kubeadm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CreateJoinCredentialsStep issues a bootstrap token on an existing control plane node so that new
+// nodes can join a running cluster. With ControlPlane set it also re-uploads the control plane
+// certificates under a fresh certificate key. The credentials are cached under the same keys as
+// the output of KubeadmInit, so the join config steps work unchanged.
+type CreateJoinCredentialsStep struct {
+	step.Base
+	ControlPlane bool
+	TokenTTL     time.Duration
+}
+
+type CreateJoinCredentialsStepBuilder struct {
+	step.Builder[CreateJoinCredentialsStepBuilder, *CreateJoinCredentialsStep]
+}
+
+func NewCreateJoinCredentialsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CreateJoinCredentialsStepBuilder {
+	s := &CreateJoinCredentialsStep{
+		TokenTTL: 2 * time.Hour,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Create kubeadm join credentials for new nodes", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+	b := new(CreateJoinCredentialsStepBuilder).Init(s)
+	return b
+}
+
+// WithControlPlane also uploads the control plane certificates, which joining masters need.
+func (b *CreateJoinCredentialsStepBuilder) WithControlPlane(controlPlane bool) *CreateJoinCredentialsStepBuilder {
+	b.Step.ControlPlane = controlPlane
+	return b
+}
+
+func (s *CreateJoinCredentialsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CreateJoinCredentialsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CreateJoinCredentialsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	tokenCmd := fmt.Sprintf("kubeadm token create --print-join-command --ttl %s", s.TokenTTL)
+	var certKey string
+	if s.ControlPlane {
+		keyResult, err := runner.Run(ctx.GoContext(), conn, "kubeadm certs certificate-key", s.Sudo)
+		if err != nil {
+			result.MarkFailed(err, "failed to generate certificate key")
+			return result, fmt.Errorf("failed to generate certificate key: %w", err)
+		}
+		certKey = strings.TrimSpace(keyResult.Stdout)
+		uploadCmd := fmt.Sprintf("kubeadm init phase upload-certs --upload-certs --certificate-key %s", certKey)
+		if _, err := runner.Run(ctx.GoContext(), conn, uploadCmd, s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to upload control plane certificates")
+			return result, fmt.Errorf("failed to upload control plane certificates: %w", err)
+		}
+		tokenCmd = fmt.Sprintf("%s --certificate-key %s", tokenCmd, certKey)
+	}
+
+	runResult, err := runner.Run(ctx.GoContext(), conn, tokenCmd, s.Sudo)
+	if err != nil {
+		result.MarkFailed(err, "failed to create bootstrap token")
+		return result, fmt.Errorf("failed to create bootstrap token: %w", err)
+	}
+	token, err := helpers.ParseTokenFromOutput(runResult.Stdout)
+	if err != nil {
+		result.MarkFailed(err, "failed to parse bootstrap token")
+		return result, err
+	}
+	caCertHash, err := helpers.ParseCaCertHashFromOutput(runResult.Stdout)
+	if err != nil {
+		result.MarkFailed(err, "failed to parse ca cert hash")
+		return result, err
+	}
+
+	// Use the KubeadmInit task name so the join config steps find the credentials.
+	cacheKey := fmt.Sprintf(common.CacheKubeadmInitToken, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), "KubeadmInit")
+	ctx.GetTaskCache().Set(cacheKey, token)
+	cacheKey = fmt.Sprintf(common.CacheKubeadmInitCACertHash, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), "KubeadmInit")
+	ctx.GetTaskCache().Set(cacheKey, caCertHash)
+	if s.ControlPlane {
+		cacheKey = fmt.Sprintf(common.CacheKubeadmInitCertKey, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), "KubeadmInit")
+		ctx.GetTaskCache().Set(cacheKey, certKey)
+	}
+
+	logger.Info("Join credentials created.", "controlPlane", s.ControlPlane, "ttl", s.TokenTTL)
+	result.MarkCompleted("join credentials created")
+	return result, nil
+}
+
+func (s *CreateJoinCredentialsStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Bootstrap tokens expire on their own; no rollback is performed.")
+	return nil
+}
+
+var _ step.Step = (*CreateJoinCredentialsStep)(nil)
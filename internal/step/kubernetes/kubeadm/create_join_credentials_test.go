@@ -0,0 +1,97 @@
+package kubeadm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/cache"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+const (
+	testJoinToken   = "abcdef.0123456789abcdef"
+	testCACertHash  = "1111111111111111111111111111111111111111111111111111111111111111"
+	testCertKeyHash = "2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// fakeKubeadmRunner answers the kubeadm commands used to create join credentials.
+type fakeKubeadmRunner struct {
+	runner.Runner
+	commands []string
+}
+
+func (r *fakeKubeadmRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	r.commands = append(r.commands, cmd)
+	switch {
+	case cmd == "kubeadm certs certificate-key":
+		return &runner.CommandResult{Stdout: testCertKeyHash + "\n"}, nil
+	case strings.HasPrefix(cmd, "kubeadm token create"):
+		out := fmt.Sprintf("kubeadm join 10.0.0.1:6443 --token %s --discovery-token-ca-cert-hash sha256:%s", testJoinToken, testCACertHash)
+		return &runner.CommandResult{Stdout: out}, nil
+	}
+	return &runner.CommandResult{}, nil
+}
+
+func TestCreateJoinCredentialsStep_Run(t *testing.T) {
+	tests := []struct {
+		name         string
+		controlPlane bool
+		wantCommands int
+	}{
+		{name: "workers only", controlPlane: false, wantCommands: 1},
+		{name: "with control plane", controlPlane: true, wantCommands: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeKubeadmRunner{}
+			taskCache := cache.NewTaskCache(cache.NewModuleCache(cache.NewPipelineCache()))
+			ctx := &runtimetest.Context{
+				Runner:       r,
+				Host:         connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1"}),
+				TaskCache:    taskCache,
+				RunID:        "run",
+				PipelineName: "AddNodes",
+			}
+			s, err := NewCreateJoinCredentialsStepBuilder(ctx, "CreateJoinCredentials").WithControlPlane(tt.controlPlane).Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+			if _, err := s.Run(ctx); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if len(r.commands) != tt.wantCommands {
+				t.Fatalf("expected %d commands, got %v", tt.wantCommands, r.commands)
+			}
+			tokenCmd := r.commands[len(r.commands)-1]
+			if hasKey := strings.Contains(tokenCmd, "--certificate-key "+testCertKeyHash); hasKey != tt.controlPlane {
+				t.Errorf("unexpected certificate key handling in %q", tokenCmd)
+			}
+
+			want := map[string]string{
+				common.CacheKubeadmInitToken:      testJoinToken,
+				common.CacheKubeadmInitCACertHash: testCACertHash,
+			}
+			if tt.controlPlane {
+				want[common.CacheKubeadmInitCertKey] = testCertKeyHash
+			}
+			for format, value := range want {
+				key := fmt.Sprintf(format, "run", "AddNodes", "", "KubeadmInit")
+				got, ok := taskCache.Get(key)
+				if !ok || got != value {
+					t.Errorf("expected cache %q to hold %q, got %v", key, value, got)
+				}
+			}
+			if _, ok := taskCache.Get(fmt.Sprintf(common.CacheKubeadmInitCertKey, "run", "AddNodes", "", "KubeadmInit")); ok != tt.controlPlane {
+				t.Errorf("certificate key cached = %v, want %v", ok, tt.controlPlane)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package kubeadm
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// windowsWorkerBinaries are the components installed on Windows workers.
+var windowsWorkerBinaries = []string{binary.ComponentKubeadmWindows, binary.ComponentKubeletWindows}
+
+// DownloadWindowsBinariesStep downloads kubeadm.exe and kubelet.exe on the control node for every
+// architecture of the Windows hosts.
+type DownloadWindowsBinariesStep struct {
+	step.Base
+}
+
+type DownloadWindowsBinariesStepBuilder struct {
+	step.Builder[DownloadWindowsBinariesStepBuilder, *DownloadWindowsBinariesStep]
+}
+
+func NewDownloadWindowsBinariesStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DownloadWindowsBinariesStepBuilder {
+	s := &DownloadWindowsBinariesStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Download Windows kubeadm and kubelet binaries", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(DownloadWindowsBinariesStepBuilder).Init(s)
+	return b
+}
+
+func (s *DownloadWindowsBinariesStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// requiredBinaries returns the Windows binaries of every architecture the Windows hosts run.
+func (s *DownloadWindowsBinariesStep) requiredBinaries(ctx runtime.ExecutionContext) ([]*binary.Binary, error) {
+	archs := make(map[string]bool)
+	for _, host := range ctx.GetWindowsHostsByRole("") {
+		archs[host.GetArch()] = true
+	}
+
+	provider := binary.NewBinaryProvider(ctx)
+	var binaries []*binary.Binary
+	for arch := range archs {
+		for _, name := range windowsWorkerBinaries {
+			binaryInfo, err := provider.GetBinary(name, arch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s info for arch %s: %w", name, arch, err)
+			}
+			if binaryInfo == nil {
+				return nil, fmt.Errorf("%s binary info not found for arch %s in BOM", name, arch)
+			}
+			binaries = append(binaries, binaryInfo)
+		}
+	}
+	return binaries, nil
+}
+
+func isValidLocalBinary(binaryInfo *binary.Binary) bool {
+	if _, err := os.Stat(binaryInfo.FilePath()); err != nil {
+		return false
+	}
+	match, err := helpers.VerifyLocalFileChecksum(binaryInfo.FilePath(), binaryInfo.Checksum())
+	return err == nil && match
+}
+
+func (s *DownloadWindowsBinariesStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Precheck")
+
+	binaries, err := s.requiredBinaries(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, binaryInfo := range binaries {
+		if !isValidLocalBinary(binaryInfo) {
+			logger.Infof("%s (arch: %s) is missing or invalid at %s. Download is required.", binaryInfo.ComponentName, binaryInfo.Arch, binaryInfo.FilePath())
+			return false, nil
+		}
+	}
+	logger.Info("All required Windows binaries already exist and are valid.")
+	return true, nil
+}
+
+func (s *DownloadWindowsBinariesStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
+
+	binaries, err := s.requiredBinaries(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to get Windows binary info")
+		return result, err
+	}
+
+	for _, binaryInfo := range binaries {
+		if isValidLocalBinary(binaryInfo) {
+			logger.Infof("Skipping download of %s for arch %s, file already exists and is valid.", binaryInfo.ComponentName, binaryInfo.Arch)
+			continue
+		}
+		logger.Infof("Downloading %s (arch: %s, version: %s) ...", binaryInfo.ComponentName, binaryInfo.Arch, binaryInfo.Version)
+		if err := helpers.DownloadBinary(ctx.GoContext(), logger, binaryInfo, true); err != nil {
+			err = fmt.Errorf("failed to download %s for arch %s: %w", binaryInfo.ComponentName, binaryInfo.Arch, err)
+			result.MarkFailed(err, "failed to download Windows binary")
+			return result, err
+		}
+	}
+
+	logger.Info("All required Windows binaries have been downloaded successfully.")
+	result.MarkCompleted("Windows binaries downloaded successfully")
+	return result, nil
+}
+
+func (s *DownloadWindowsBinariesStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Rollback")
+
+	binaries, err := s.requiredBinaries(ctx)
+	if err != nil {
+		logger.Errorf("Failed to get Windows binary info during rollback: %v", err)
+		return nil
+	}
+	for _, binaryInfo := range binaries {
+		logger.Warnf("Rolling back by deleting downloaded file: %s", binaryInfo.FilePath())
+		_ = os.Remove(binaryInfo.FilePath())
+	}
+	return nil
+}
+
+var _ step.Step = (*DownloadWindowsBinariesStep)(nil)
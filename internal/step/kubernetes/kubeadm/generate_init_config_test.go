@@ -0,0 +1,99 @@
+package kubeadm
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/step"
+)
+
+func newInitConfigTestContext(podCIDR, serviceCIDR, internalAddress string) *runtimetest.Context {
+	hostSpec := v1alpha1.HostSpec{Name: "master1", Address: "192.168.1.10", InternalAddress: internalAddress}
+	cluster := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+		Hosts:      []v1alpha1.HostSpec{hostSpec},
+		RoleGroups: &v1alpha1.RoleGroupsSpec{Master: []string{"master1"}, Etcd: []string{"master1"}},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.28.2", Scheduler: &v1alpha1.SchedulerConfig{}},
+		Network:    &v1alpha1.Network{KubePodsCIDR: podCIDR, KubeServiceCIDR: serviceCIDR},
+	}}
+	v1alpha1.SetDefaults_Cluster(cluster)
+	return &runtimetest.Context{Cluster: cluster, Host: connector.NewHostFromSpec(hostSpec)}
+}
+
+func TestGenerateInitConfigStep_DualStack(t *testing.T) {
+	ctx := newInitConfigTestContext("10.244.0.0/16,fd00:10:244::/56", "10.96.0.0/12,fd00:10:96::/108", "192.168.1.10,fd00::10")
+	s, err := NewGenerateInitConfigStepBuilder(ctx, "GenerateInitConfig").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	content, err := s.renderContent(ctx)
+	if err != nil {
+		t.Fatalf("failed to render kubeadm config: %v", err)
+	}
+	rendered := string(content)
+
+	for _, want := range []string{
+		"podSubnet: 10.244.0.0/16,fd00:10:244::/56",
+		"serviceSubnet: 10.96.0.0/12,fd00:10:96::/108",
+		"clusterCIDR: 10.244.0.0/16,fd00:10:244::/56",
+		"advertiseAddress: 192.168.1.10",
+		`"node-ip": "192.168.1.10,fd00::10"`,
+		`"node-cidr-mask-size-ipv4": "24"`,
+		`"node-cidr-mask-size-ipv6": "64"`,
+		`- "10.96.0.1"`,
+		`- "fd00::10"`,
+		"- https://192.168.1.10:2379",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("expected kubeadm config to contain %q, got:\n%s", want, rendered)
+		}
+	}
+	if strings.Contains(rendered, `"node-cidr-mask-size": `) {
+		t.Errorf("expected the single-stack node-cidr-mask-size to be dropped for dual-stack, got:\n%s", rendered)
+	}
+}
+
+func TestGenerateInitConfigStep_SingleStack(t *testing.T) {
+	ctx := newInitConfigTestContext("10.244.0.0/16", "10.96.0.0/12", "192.168.1.10,fd00::10")
+	s, err := NewGenerateInitConfigStepBuilder(ctx, "GenerateInitConfig").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	content, err := s.renderContent(ctx)
+	if err != nil {
+		t.Fatalf("failed to render kubeadm config: %v", err)
+	}
+	rendered := string(content)
+
+	if !strings.Contains(rendered, "advertiseAddress: 192.168.1.10\n") {
+		t.Errorf("expected the IPv4 address to be advertised, got:\n%s", rendered)
+	}
+	if strings.Contains(rendered, `"node-ip"`) {
+		t.Errorf("expected no node-ip override for a single-stack cluster, got:\n%s", rendered)
+	}
+}
+
+func TestGenerateJoinWorkerConfigStep_RenderFilesWithoutToken(t *testing.T) {
+	ctx := newInitConfigTestContext("10.244.0.0/16", "10.96.0.0/12", "192.168.1.10")
+	s, err := NewGenerateJoinWorkerConfigStepBuilder(ctx, "GenerateJoinWorkerConfig").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	files, err := s.RenderFiles(ctx)
+	if err != nil {
+		t.Fatalf("failed to render join config: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != filepath.Join(common.KubernetesConfigDir, common.KubeadmJoinWorkerConfigFileName) {
+		t.Fatalf("expected the join config at its kubeadm path, got %+v", files)
+	}
+	if !strings.Contains(string(files[0].Content), step.RenderPlaceholder) {
+		t.Errorf("expected the bootstrap token placeholder, got:\n%s", files[0].Content)
+	}
+}
@@ -11,8 +11,8 @@ import (
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
 	"github.com/mensylisir/kubexm/internal/step/helpers"
-	"github.com/mensylisir/kubexm/internal/templates"
 	"github.com/mensylisir/kubexm/internal/types"
+	kubeadmconfig "github.com/mensylisir/kubexm/internal/util/kubeadm"
 )
 
 type GenerateJoinMasterConfigStep struct {
@@ -36,30 +36,7 @@ func (s *GenerateJoinMasterConfigStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
-type JoinMasterTemplateData struct {
-	Discovery        DiscoveryTemplate
-	ControlPlane     ControlPlaneTemplate
-	NodeRegistration NodeRegistrationTemplate
-}
-
-type DiscoveryTemplate struct {
-	APIServerEndpoint string
-	BootstrapToken    string
-}
-
-type ControlPlaneTemplate struct {
-	AdvertiseAddress string
-	BindPort         int
-	CertificateKey   string
-}
-
 func (s *GenerateJoinMasterConfigStep) renderContent(ctx runtime.ExecutionContext) ([]byte, error) {
-	cluster := ctx.GetClusterConfig()
-	currentHost := ctx.GetHost()
-
-	data := JoinMasterTemplateData{}
-
-	data.Discovery.APIServerEndpoint = fmt.Sprintf("%s:%d", cluster.Spec.ControlPlaneEndpoint.Domain, cluster.Spec.ControlPlaneEndpoint.Port)
 	// IMPORTANT: Use fixed task name "KubeadmInit" for cache keys, not the current task name.
 	// The token/certKey are written by BootstrapFirstMasterTask.KubeadmInit step,
 	// and read by JoinMastersTask.GenerateJoinMasterConfig step.
@@ -73,7 +50,6 @@ func (s *GenerateJoinMasterConfigStep) renderContent(ctx runtime.ExecutionContex
 	if !ok {
 		return nil, fmt.Errorf("cached bootstrap token is not a string")
 	}
-	data.Discovery.BootstrapToken = token
 
 	cacheKey = fmt.Sprintf(common.CacheKubeadmInitCertKey, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), "KubeadmInit")
 	certKeyVal, found := ctx.GetTaskCache().Get(cacheKey)
@@ -84,48 +60,30 @@ func (s *GenerateJoinMasterConfigStep) renderContent(ctx runtime.ExecutionContex
 	if !ok {
 		return nil, fmt.Errorf("cached certificate key is not a string")
 	}
-	data.ControlPlane.CertificateKey = certKey
-
-	data.ControlPlane.AdvertiseAddress = currentHost.GetInternalAddress()
-	data.ControlPlane.BindPort = common.DefaultAPIServerPort
-
-	var cgroupDriver string
-	switch cluster.Spec.Kubernetes.ContainerRuntime.Type {
-	case common.RuntimeTypeContainerd:
-		data.NodeRegistration.CRISocket = common.ContainerdDefaultEndpoint
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Containerd.CgroupDriver
-	case common.RuntimeTypeCRIO:
-		data.NodeRegistration.CRISocket = common.CRIODefaultEndpoint
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Crio.CgroupDriver
-	case common.RuntimeTypeDocker:
-		data.NodeRegistration.CRISocket = common.CriDockerdSocketPath
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Docker.CgroupDriver
-	case common.RuntimeTypeIsula:
-		data.NodeRegistration.CRISocket = common.IsuladDefaultEndpoint
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Isulad.CgroupDriver
-	default:
-		data.NodeRegistration.CRISocket = common.ContainerdDefaultEndpoint
-		cgroupDriver = common.CgroupDriverSystemd
-	}
 
-	data.NodeRegistration.KubeletExtraArgs = map[string]string{
-		"cgroup-driver": cgroupDriver,
-	}
-	if cluster.Spec.Kubernetes.Kubelet.ExtraArgs != nil {
-		for k, v := range cluster.Spec.Kubernetes.Kubelet.ExtraArgs {
-			data.NodeRegistration.KubeletExtraArgs[k] = v
-		}
+	generator, err := newConfigGenerator(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return generator.JoinConfigFile(ctx.GetHost().GetName(), kubeadmconfig.JoinOptions{Token: token, CertificateKey: certKey, ControlPlane: true})
+}
 
-	templateContent, err := templates.Get("kubernetes/kubeadm/kubeadm-join-master-config.tmpl")
+// RenderFiles returns the join config of the current host. The bootstrap token and certificate key
+// only exist once KubeadmInit has run, so they are rendered as step.RenderPlaceholder.
+func (s *GenerateJoinMasterConfigStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	generator, err := newConfigGenerator(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get kubeadm join master template: %w", err)
+		return nil, err
 	}
-	renderedConfig, err := templates.Render(templateContent, data)
+	content, err := generator.JoinConfigFile(ctx.GetHost().GetName(), kubeadmconfig.JoinOptions{
+		Token:          step.RenderPlaceholder,
+		CertificateKey: step.RenderPlaceholder,
+		ControlPlane:   true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to render kubeadm join master template: %w", err)
+		return nil, err
 	}
-	return []byte(renderedConfig), nil
+	return []step.RenderedFile{{Path: filepath.Join(common.KubernetesConfigDir, common.KubeadmJoinMasterConfigFileName), Content: content}}, nil
 }
 
 func (s *GenerateJoinMasterConfigStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
@@ -213,3 +171,4 @@ func (s *GenerateJoinMasterConfigStep) Rollback(ctx runtime.ExecutionContext) er
 }
 
 var _ step.Step = (*GenerateJoinMasterConfigStep)(nil)
+var _ step.Renderer = (*GenerateJoinMasterConfigStep)(nil)
@@ -11,8 +11,8 @@ import (
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
 	"github.com/mensylisir/kubexm/internal/step/helpers"
-	"github.com/mensylisir/kubexm/internal/templates"
 	"github.com/mensylisir/kubexm/internal/types"
+	kubeadmconfig "github.com/mensylisir/kubexm/internal/util/kubeadm"
 )
 
 type GenerateJoinWorkerConfigStep struct {
@@ -36,68 +36,48 @@ func (s *GenerateJoinWorkerConfigStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
-type JoinWorkerTemplateData struct {
-	Discovery        DiscoveryTemplate
-	NodeRegistration NodeRegistrationTemplate
-}
-
-func (s *GenerateJoinWorkerConfigStep) renderContent(ctx runtime.ExecutionContext) ([]byte, error) {
-	cluster := ctx.GetClusterConfig()
-
-	data := JoinWorkerTemplateData{}
-
-	data.Discovery.APIServerEndpoint = fmt.Sprintf("%s:%d", cluster.Spec.ControlPlaneEndpoint.Domain, cluster.Spec.ControlPlaneEndpoint.Port)
+// workerBootstrapToken returns the bootstrap token workers join with.
+func workerBootstrapToken(ctx runtime.ExecutionContext) (string, error) {
 	// IMPORTANT: Use fixed task name "KubeadmInit" for cache keys, not the current task name.
-	// The token is written by BootstrapFirstMasterTask.KubeadmInit step,
-	// and read by JoinWorkersTask.GenerateJoinWorkerConfig step.
+	// The token is written by BootstrapFirstMasterTask.KubeadmInit step, and read by the
+	// GenerateJoinWorkerConfig and KubeadmJoinWindowsWorker steps.
 	cacheKey := fmt.Sprintf(common.CacheKubeadmInitToken, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), "KubeadmInit")
 	tokenVal, found := ctx.GetTaskCache().Get(cacheKey)
 	if !found {
-		return nil, fmt.Errorf("bootstrap token not found in task cache with key '%s' (did KubeadmInit step run successfully?)", cacheKey)
+		return "", fmt.Errorf("bootstrap token not found in task cache with key '%s' (did KubeadmInit step run successfully?)", cacheKey)
 	}
 	token, ok := tokenVal.(string)
 	if !ok {
-		return nil, fmt.Errorf("cached bootstrap token is not a string")
-	}
-	data.Discovery.BootstrapToken = token
-
-	var cgroupDriver string
-	switch cluster.Spec.Kubernetes.ContainerRuntime.Type {
-	case common.RuntimeTypeContainerd:
-		data.NodeRegistration.CRISocket = common.ContainerdDefaultEndpoint
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Containerd.CgroupDriver
-	case common.RuntimeTypeCRIO:
-		data.NodeRegistration.CRISocket = common.CRIODefaultEndpoint
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Crio.CgroupDriver
-	case common.RuntimeTypeDocker:
-		data.NodeRegistration.CRISocket = common.CriDockerdSocketPath
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Docker.CgroupDriver
-	case common.RuntimeTypeIsula:
-		data.NodeRegistration.CRISocket = common.IsuladDefaultEndpoint
-		cgroupDriver = *cluster.Spec.Kubernetes.ContainerRuntime.Isulad.CgroupDriver
-	default:
-		data.NodeRegistration.CRISocket = common.ContainerdDefaultEndpoint
-		cgroupDriver = common.CgroupDriverSystemd
-	}
-
-	data.NodeRegistration.KubeletExtraArgs = map[string]string{
-		"cgroup-driver": cgroupDriver,
-	}
-	if cluster.Spec.Kubernetes.Kubelet.ExtraArgs != nil {
-		for k, v := range cluster.Spec.Kubernetes.Kubelet.ExtraArgs {
-			data.NodeRegistration.KubeletExtraArgs[k] = v
-		}
-	}
-
-	templateContent, err := templates.Get("kubernetes/kubeadm-join-worker-config.tmpl")
+		return "", fmt.Errorf("cached bootstrap token is not a string")
+	}
+	return token, nil
+}
+
+func (s *GenerateJoinWorkerConfigStep) renderContent(ctx runtime.ExecutionContext) ([]byte, error) {
+	token, err := workerBootstrapToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	generator, err := newConfigGenerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return generator.JoinConfigFile(ctx.GetHost().GetName(), kubeadmconfig.JoinOptions{Token: token})
+}
+
+// RenderFiles returns the join config of the current host. The bootstrap token only exists once
+// KubeadmInit has run, so it is rendered as step.RenderPlaceholder.
+func (s *GenerateJoinWorkerConfigStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	generator, err := newConfigGenerator(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get kubeadm join worker template: %w", err)
+		return nil, err
 	}
-	renderedConfig, err := templates.Render(templateContent, data)
+	content, err := generator.JoinConfigFile(ctx.GetHost().GetName(), kubeadmconfig.JoinOptions{Token: step.RenderPlaceholder})
 	if err != nil {
-		return nil, fmt.Errorf("failed to render kubeadm join worker template: %w", err)
+		return nil, err
 	}
-	return []byte(renderedConfig), nil
+	return []step.RenderedFile{{Path: filepath.Join(common.KubernetesConfigDir, common.KubeadmJoinWorkerConfigFileName), Content: content}}, nil
 }
 
 func (s *GenerateJoinWorkerConfigStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
@@ -185,3 +165,4 @@ func (s *GenerateJoinWorkerConfigStep) Rollback(ctx runtime.ExecutionContext) er
 }
 
 var _ step.Step = (*GenerateJoinWorkerConfigStep)(nil)
+var _ step.Renderer = (*GenerateJoinWorkerConfigStep)(nil)
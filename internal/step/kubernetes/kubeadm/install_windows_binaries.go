@@ -0,0 +1,164 @@
+package kubeadm
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/step/helpers/bom/binary"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// InstallWindowsBinariesStep uploads kubeadm.exe and kubelet.exe to common.WindowsBinDir.
+type InstallWindowsBinariesStep struct {
+	step.Base
+}
+
+type InstallWindowsBinariesStepBuilder struct {
+	step.Builder[InstallWindowsBinariesStepBuilder, *InstallWindowsBinariesStep]
+}
+
+func NewInstallWindowsBinariesStepBuilder(ctx runtime.ExecutionContext, instanceName string) *InstallWindowsBinariesStepBuilder {
+	s := &InstallWindowsBinariesStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Install Windows kubeadm and kubelet binaries", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(InstallWindowsBinariesStepBuilder).Init(s)
+	return b
+}
+
+func (s *InstallWindowsBinariesStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *InstallWindowsBinariesStep) binaries(ctx runtime.ExecutionContext) ([]*binary.Binary, error) {
+	provider := binary.NewBinaryProvider(ctx)
+	arch := ctx.GetHost().GetArch()
+	var binaries []*binary.Binary
+	for _, name := range windowsWorkerBinaries {
+		binaryInfo, err := provider.GetBinary(name, arch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s binary info: %w", name, err)
+		}
+		if binaryInfo == nil {
+			return nil, fmt.Errorf("%s is unexpectedly disabled for arch %s", name, arch)
+		}
+		if _, err := os.Stat(binaryInfo.FilePath()); err != nil {
+			return nil, fmt.Errorf("local source file '%s' not found, ensure assets were prepared (kubexm download or Preflight PrepareAssets/ExtractBundle)", binaryInfo.FilePath())
+		}
+		binaries = append(binaries, binaryInfo)
+	}
+	return binaries, nil
+}
+
+func (s *InstallWindowsBinariesStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	binaries, err := s.binaries(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, binaryInfo := range binaries {
+		targetPath := runner.WindowsPath(common.WindowsBinDir, binaryInfo.FileName())
+		exists, err := ctx.GetRunner().Exists(ctx.GoContext(), conn, targetPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to check for file '%s': %w", targetPath, err)
+		}
+		if !exists {
+			logger.Infof("Target file '%s' is missing. Installation is required.", targetPath)
+			return false, nil
+		}
+		localHash, err := helpers.GetLocalFileSHA256(binaryInfo.FilePath())
+		if err != nil {
+			return false, fmt.Errorf("failed to calculate sha256 for local file %s: %w", binaryInfo.FilePath(), err)
+		}
+		remoteHash, err := conn.GetFileChecksum(ctx.GoContext(), targetPath, "sha256")
+		if err != nil || remoteHash != localHash {
+			logger.Infof("Target file '%s' is outdated. Installation is required.", targetPath)
+			return false, nil
+		}
+	}
+	logger.Info("Windows binaries are already installed and up-to-date. Step is done.")
+	return true, nil
+}
+
+func (s *InstallWindowsBinariesStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	binaries, err := s.binaries(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to get Windows binary info")
+		return result, err
+	}
+
+	// A running kubelet.exe cannot be overwritten, so it is stopped before an upgrade; kubeadm
+	// join or the service installed next starts it again.
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "failed to get host facts")
+		return result, err
+	}
+	if active, _ := runnerSvc.IsServiceActive(ctx.GoContext(), conn, facts, common.WindowsKubeletServiceName); active {
+		logger.Info("Stopping the kubelet service to replace its binary.")
+		if err := runnerSvc.StopService(ctx.GoContext(), conn, facts, common.WindowsKubeletServiceName); err != nil {
+			result.MarkFailed(err, "failed to stop kubelet service")
+			return result, err
+		}
+	}
+
+	if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, common.WindowsBinDir, "", false); err != nil {
+		err = fmt.Errorf("failed to create remote install directory '%s': %w", common.WindowsBinDir, err)
+		result.MarkFailed(err, "failed to create remote install directory")
+		return result, err
+	}
+	for _, binaryInfo := range binaries {
+		targetPath := runner.WindowsPath(common.WindowsBinDir, binaryInfo.FileName())
+		logger.Infof("Uploading %s to %s:%s", binaryInfo.FileName(), ctx.GetHost().GetName(), targetPath)
+		if err := runnerSvc.Upload(ctx.GoContext(), conn, binaryInfo.FilePath(), targetPath, false); err != nil {
+			err = fmt.Errorf("failed to upload '%s' to '%s': %w", binaryInfo.FilePath(), targetPath, err)
+			result.MarkFailed(err, "failed to upload Windows binary")
+			return result, err
+		}
+	}
+
+	result.MarkCompleted("Windows binaries installed successfully")
+	return result, nil
+}
+
+func (s *InstallWindowsBinariesStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+
+	for _, name := range []string{"kubeadm.exe", "kubelet.exe"} {
+		targetPath := runner.WindowsPath(common.WindowsBinDir, name)
+		logger.Warnf("Rolling back by removing: %s", targetPath)
+		if err := ctx.GetRunner().Remove(ctx.GoContext(), conn, targetPath, false, false); err != nil {
+			logger.Errorf("Failed to remove '%s' during rollback: %v", targetPath, err)
+		}
+	}
+	return nil
+}
+
+var _ step.Step = (*InstallWindowsBinariesStep)(nil)
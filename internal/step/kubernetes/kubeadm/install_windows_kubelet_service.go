@@ -0,0 +1,151 @@
+package kubeadm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	kubeadmconfig "github.com/mensylisir/kubexm/internal/util/kubeadm"
+)
+
+// InstallWindowsKubeletServiceStep registers kubelet.exe as the kubelet Windows service. It is not
+// started here: kubeadm join writes the kubelet configuration and then starts the service itself.
+type InstallWindowsKubeletServiceStep struct {
+	step.Base
+}
+
+type InstallWindowsKubeletServiceStepBuilder struct {
+	step.Builder[InstallWindowsKubeletServiceStepBuilder, *InstallWindowsKubeletServiceStep]
+}
+
+func NewInstallWindowsKubeletServiceStepBuilder(ctx runtime.ExecutionContext, instanceName string) *InstallWindowsKubeletServiceStepBuilder {
+	s := &InstallWindowsKubeletServiceStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Install kubelet Windows service", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(InstallWindowsKubeletServiceStepBuilder).Init(s)
+	return b
+}
+
+func (s *InstallWindowsKubeletServiceStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func windowsKubeletBinPath() string {
+	return runner.WindowsPath(common.WindowsBinDir, "kubelet.exe")
+}
+
+// windowsKubeletArgs mirrors the kubelet flags of the upstream sig-windows kubeadm scripts. The
+// Linux-only cgroup and resolv.conf handling is turned off. The service runs kubelet.exe directly and
+// never reads the kubeadm-flags.env written by kubeadm join, so the kubeletExtraArgs of the join
+// configuration are appended here instead.
+func windowsKubeletArgs(extraArgs []kubeadmconfig.Arg) []string {
+	args := []string{
+		"--windows-service",
+		"--cert-dir=" + runner.WindowsPath(common.WindowsKubeletDir, "pki"),
+		"--config=" + runner.WindowsPath(common.WindowsKubeletDir, "config.yaml"),
+		"--bootstrap-kubeconfig=" + runner.WindowsPath(common.WindowsKubernetesConfigDir, "bootstrap-kubelet.conf"),
+		"--kubeconfig=" + runner.WindowsPath(common.WindowsKubernetesConfigDir, common.KubeletKubeconfigFileName),
+		"--container-runtime-endpoint=" + common.ContainerdWindowsEndpoint,
+		"--cgroups-per-qos=false",
+		`--enforce-node-allocatable=""`,
+		`--resolv-conf=""`,
+	}
+	for _, arg := range extraArgs {
+		value := arg.Value
+		if value == "" || strings.ContainsAny(value, " \t") {
+			value = `"` + value + `"`
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", arg.Name, value))
+	}
+	return args
+}
+
+func (s *InstallWindowsKubeletServiceStep) kubeletArgs(ctx runtime.ExecutionContext) ([]string, error) {
+	generator, err := newConfigGenerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	extraArgs, err := generator.KubeletExtraArgs(ctx.GetHost().GetName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubelet extra args: %w", err)
+	}
+	return windowsKubeletArgs(extraArgs), nil
+}
+
+func (s *InstallWindowsKubeletServiceStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	args, err := s.kubeletArgs(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	script := fmt.Sprintf("(Get-CimInstance Win32_Service -Filter \"Name='%s'\").PathName", common.WindowsKubeletServiceName)
+	stdout, _, err := ctx.GetRunner().RunWithOptions(ctx.GoContext(), conn, script, &connector.ExecOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to query the kubelet service: %w", err)
+	}
+	if strings.TrimSpace(string(stdout)) == runner.WindowsServiceCommandLine(windowsKubeletBinPath(), args) {
+		logger.Info("kubelet service is already installed with the expected command line. Step is done.")
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *InstallWindowsKubeletServiceStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	args, err := s.kubeletArgs(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to build kubelet arguments")
+		return result, err
+	}
+
+	logger.Infof("Registering %s as the %s service", windowsKubeletBinPath(), common.WindowsKubeletServiceName)
+	if err := ctx.GetRunner().InstallWindowsService(ctx.GoContext(), conn, common.WindowsKubeletServiceName, "kubelet", windowsKubeletBinPath(), args); err != nil {
+		result.MarkFailed(err, "failed to install kubelet service")
+		return result, err
+	}
+
+	result.MarkCompleted("kubelet service installed")
+	return result, nil
+}
+
+func (s *InstallWindowsKubeletServiceStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+
+	script := fmt.Sprintf(`if (Get-Service -Name '%[1]s' -ErrorAction SilentlyContinue) {
+  Stop-Service -Name '%[1]s' -Force
+  & sc.exe delete '%[1]s' | Out-Null
+}`, common.WindowsKubeletServiceName)
+	logger.Warnf("Rolling back by removing the %s service", common.WindowsKubeletServiceName)
+	if _, _, err := ctx.GetRunner().RunWithOptions(ctx.GoContext(), conn, script, &connector.ExecOptions{}); err != nil {
+		logger.Errorf("Failed to remove the kubelet service during rollback: %v", err)
+	}
+	return nil
+}
+
+var _ step.Step = (*InstallWindowsKubeletServiceStep)(nil)
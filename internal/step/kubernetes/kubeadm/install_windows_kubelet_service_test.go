@@ -0,0 +1,64 @@
+package kubeadm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+type fakeWindowsServiceRunner struct {
+	runner.Runner
+	binPath string
+	args    []string
+}
+
+func (r *fakeWindowsServiceRunner) InstallWindowsService(ctx context.Context, conn connector.Connector, serviceName, displayName, binPath string, args []string) error {
+	r.binPath = binPath
+	r.args = args
+	return nil
+}
+
+func TestInstallWindowsKubeletServiceStep_PassesKubeletExtraArgs(t *testing.T) {
+	hostSpec := v1alpha1.HostSpec{Name: "win1", Address: "192.168.1.20", InternalAddress: "192.168.1.20,fd00::20", Roles: []string{common.RoleWorker}, OS: common.OSWindows}
+	cluster := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+		Hosts: []v1alpha1.HostSpec{hostSpec},
+		Kubernetes: &v1alpha1.Kubernetes{
+			Version: "v1.30.4",
+			Kubelet: &v1alpha1.KubeletConfig{ExtraArgs: map[string]string{"max-pods": "50", "node-labels": "os=windows"}},
+		},
+		Network: &v1alpha1.Network{KubePodsCIDR: "10.244.0.0/16,fd00:10:244::/56", KubeServiceCIDR: "10.96.0.0/12,fd00:10:96::/108"},
+	}}
+	v1alpha1.SetDefaults_Cluster(cluster)
+	r := &fakeWindowsServiceRunner{}
+	ctx := &runtimetest.Context{Cluster: cluster, Host: connector.NewHostFromSpec(hostSpec), Runner: r}
+
+	s, err := NewInstallWindowsKubeletServiceStepBuilder(ctx, "InstallWindowsKubeletService").Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+	if _, err := s.Run(ctx); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	cmdLine := runner.WindowsServiceCommandLine(r.binPath, r.args)
+	for _, want := range []string{
+		"--windows-service",
+		"--container-runtime-endpoint=" + common.ContainerdWindowsEndpoint,
+		"--max-pods=50",
+		"--node-labels=os=windows",
+		"--node-ip=192.168.1.20,fd00::20",
+	} {
+		if !strings.Contains(cmdLine, " "+want) {
+			t.Errorf("expected the kubelet service command line to contain %q, got %s", want, cmdLine)
+		}
+	}
+	if strings.Contains(cmdLine, "cgroup-driver") {
+		t.Errorf("expected no cgroup driver on Windows, got %s", cmdLine)
+	}
+}
@@ -0,0 +1,138 @@
+package kubeadm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	kubeadmconfig "github.com/mensylisir/kubexm/internal/util/kubeadm"
+)
+
+// KubeadmJoinWindowsWorkerStep writes the join configuration of a Windows worker and runs
+// kubeadm.exe join with it.
+type KubeadmJoinWindowsWorkerStep struct {
+	step.Base
+}
+
+type KubeadmJoinWindowsWorkerStepBuilder struct {
+	step.Builder[KubeadmJoinWindowsWorkerStepBuilder, *KubeadmJoinWindowsWorkerStep]
+}
+
+func NewKubeadmJoinWindowsWorkerStepBuilder(ctx runtime.ExecutionContext, instanceName string) *KubeadmJoinWindowsWorkerStepBuilder {
+	s := &KubeadmJoinWindowsWorkerStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Join a new Windows worker node to the cluster", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(KubeadmJoinWindowsWorkerStepBuilder).Init(s)
+	return b
+}
+
+func (s *KubeadmJoinWindowsWorkerStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func windowsJoinConfigPath() string {
+	return runner.WindowsPath(common.WindowsKubernetesConfigDir, common.KubeadmJoinWorkerConfigFileName)
+}
+
+func windowsKubeadmBinPath() string {
+	return runner.WindowsPath(common.WindowsBinDir, "kubeadm.exe")
+}
+
+func (s *KubeadmJoinWindowsWorkerStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+
+	kubeletConfPath := runner.WindowsPath(common.WindowsKubernetesConfigDir, common.KubeletKubeconfigFileName)
+	exists, err := ctx.GetRunner().Exists(ctx.GoContext(), conn, kubeletConfPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for file '%s': %w", kubeletConfPath, err)
+	}
+	if exists {
+		logger.Info("This node has already joined the cluster as a worker (kubelet.conf exists). Step is done.")
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *KubeadmJoinWindowsWorkerStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	token, err := workerBootstrapToken(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to get bootstrap token")
+		return result, err
+	}
+	generator, err := newConfigGenerator(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to create kubeadm config generator")
+		return result, err
+	}
+	content, err := generator.JoinConfigFile(ctx.GetHost().GetName(), kubeadmconfig.JoinOptions{Token: token})
+	if err != nil {
+		result.MarkFailed(err, "failed to render join worker config")
+		return result, err
+	}
+
+	configPath := windowsJoinConfigPath()
+	logger.Infof("Uploading join-worker config to %s:%s", ctx.GetHost().GetName(), configPath)
+	if err := runnerSvc.WriteFile(ctx.GoContext(), conn, content, configPath, "", false); err != nil {
+		err = fmt.Errorf("failed to upload kubeadm config file: %w", err)
+		result.MarkFailed(err, "failed to upload kubeadm config file")
+		return result, err
+	}
+
+	cmd := fmt.Sprintf("& '%s' join --config '%s'", windowsKubeadmBinPath(), configPath)
+	logger.Infof("Running command: %s", cmd)
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, cmd, false); err != nil {
+		err = fmt.Errorf("kubeadm join windows worker failed: %w", err)
+		result.MarkFailed(err, "kubeadm join windows worker failed")
+		return result, err
+	}
+
+	logger.Info("Successfully joined the cluster as a Windows worker node.")
+	result.MarkCompleted("joined cluster as Windows worker node")
+	return result, nil
+}
+
+func (s *KubeadmJoinWindowsWorkerStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+
+	cmd := fmt.Sprintf("& '%s' reset --cri-socket '%s' --force", windowsKubeadmBinPath(), common.ContainerdWindowsEndpoint)
+	logger.Warnf("Rolling back by running command: %s", cmd)
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, cmd, false); err != nil {
+		logger.Warnf("kubeadm reset command failed, but continuing rollback: %v", err)
+	}
+
+	configPath := windowsJoinConfigPath()
+	logger.Warnf("Rolling back by removing: %s", configPath)
+	if err := runnerSvc.Remove(ctx.GoContext(), conn, configPath, false, false); err != nil {
+		logger.Errorf("Failed to remove '%s' during rollback: %v", configPath, err)
+	}
+	return nil
+}
+
+var _ step.Step = (*KubeadmJoinWindowsWorkerStep)(nil)
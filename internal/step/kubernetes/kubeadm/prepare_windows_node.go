@@ -0,0 +1,130 @@
+package kubeadm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// windowsKubeletPKILink is where the kubelet looks up the cluster CA on Windows; it links to
+// common.WindowsKubernetesPKIDir as in the upstream PrepareNode.ps1.
+var windowsKubeletPKILink = runner.WindowsPath(common.WindowsKubeletDir, "etc", "kubernetes", "pki")
+
+// PrepareWindowsNodeStep lays out the directories kubeadm and the kubelet expect on a Windows worker
+// and opens the kubelet port. containerd is not installed by kubexm on Windows and must already serve
+// CRI on its named pipe.
+type PrepareWindowsNodeStep struct {
+	step.Base
+}
+
+type PrepareWindowsNodeStepBuilder struct {
+	step.Builder[PrepareWindowsNodeStepBuilder, *PrepareWindowsNodeStep]
+}
+
+func NewPrepareWindowsNodeStepBuilder(ctx runtime.ExecutionContext, instanceName string) *PrepareWindowsNodeStepBuilder {
+	s := &PrepareWindowsNodeStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Prepare Windows node for kubeadm join", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(PrepareWindowsNodeStepBuilder).Init(s)
+	return b
+}
+
+func (s *PrepareWindowsNodeStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func windowsNodeDirs() []string {
+	return []string{
+		common.WindowsBinDir,
+		common.WindowsKubernetesPKIDir,
+		common.WindowsKubeletDir,
+		runner.WindowsPath(common.WindowsKubeletDir, "etc", "kubernetes"),
+	}
+}
+
+func (s *PrepareWindowsNodeStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		return false, fmt.Errorf("failed to get host facts: %w", err)
+	}
+	if facts.ContainerRuntime == nil || facts.ContainerRuntime.Socket != common.ContainerdWindowsEndpoint {
+		return false, fmt.Errorf("containerd is not serving CRI on %s; install containerd on Windows host %s before joining it", common.ContainerdWindowsPipe, ctx.GetHost().GetName())
+	}
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	script := fmt.Sprintf("if (-not (Test-Path -LiteralPath '%s') -or -not (Get-NetFirewallRule -Name '%s' -ErrorAction SilentlyContinue)) { exit 1 }",
+		windowsKubeletPKILink, common.WindowsKubeletFirewallRule)
+	if _, _, err := ctx.GetRunner().RunWithOptions(ctx.GoContext(), conn, script, &connector.ExecOptions{}); err != nil {
+		logger.Info("Windows node is not prepared yet. Step needs to run.")
+		return false, nil
+	}
+	logger.Info("Windows node is already prepared. Step is done.")
+	return true, nil
+}
+
+func (s *PrepareWindowsNodeStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	for _, dir := range windowsNodeDirs() {
+		if err := runner.Mkdirp(ctx.GoContext(), conn, dir, "", false); err != nil {
+			err = fmt.Errorf("failed to create directory '%s': %w", dir, err)
+			result.MarkFailed(err, "failed to create directory")
+			return result, err
+		}
+	}
+
+	script := fmt.Sprintf(`if (-not (Test-Path -LiteralPath '%[1]s')) {
+  New-Item -ItemType SymbolicLink -Path '%[1]s' -Value '%[2]s' | Out-Null
+}
+if (-not (Get-NetFirewallRule -Name '%[3]s' -ErrorAction SilentlyContinue)) {
+  New-NetFirewallRule -Name '%[3]s' -DisplayName 'kubelet (kubexm)' -Direction Inbound -Protocol TCP -LocalPort %[4]d -Action Allow | Out-Null
+}`, windowsKubeletPKILink, common.WindowsKubernetesPKIDir, common.WindowsKubeletFirewallRule, common.KubeletDefaultPort)
+	logger.Info("Linking the kubelet PKI directory and opening the kubelet port.")
+	if _, _, err := runner.RunWithOptions(ctx.GoContext(), conn, script, &connector.ExecOptions{}); err != nil {
+		err = fmt.Errorf("failed to prepare Windows node: %w", err)
+		result.MarkFailed(err, "failed to prepare Windows node")
+		return result, err
+	}
+
+	result.MarkCompleted("Windows node prepared")
+	return result, nil
+}
+
+func (s *PrepareWindowsNodeStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+
+	script := fmt.Sprintf("Remove-NetFirewallRule -Name '%s' -ErrorAction SilentlyContinue", common.WindowsKubeletFirewallRule)
+	logger.Warnf("Rolling back by removing firewall rule %s", common.WindowsKubeletFirewallRule)
+	if _, _, err := ctx.GetRunner().RunWithOptions(ctx.GoContext(), conn, script, &connector.ExecOptions{}); err != nil {
+		logger.Errorf("Failed to remove firewall rule during rollback: %v", err)
+	}
+	return nil
+}
+
+var _ step.Step = (*PrepareWindowsNodeStep)(nil)
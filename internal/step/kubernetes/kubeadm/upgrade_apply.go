@@ -15,6 +15,8 @@ import (
 
 type KubeadmUpgradeApplyStep struct {
 	step.Base
+	// TargetVersion, if set, is used instead of the version saved by the upgrade plan step.
+	TargetVersion string
 }
 
 type KubeadmUpgradeApplyStepBuilder struct {
@@ -33,6 +35,11 @@ func NewKubeadmUpgradeApplyStepBuilder(ctx runtime.ExecutionContext, instanceNam
 	return b
 }
 
+func (b *KubeadmUpgradeApplyStepBuilder) WithTargetVersion(v string) *KubeadmUpgradeApplyStepBuilder {
+	b.Step.TargetVersion = v
+	return b
+}
+
 func (s *KubeadmUpgradeApplyStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
@@ -40,10 +47,7 @@ func (s *KubeadmUpgradeApplyStep) Meta() *spec.StepMeta {
 func (s *KubeadmUpgradeApplyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	logger.Info("Starting precheck for kubeadm upgrade apply...")
-	_, ok := ctx.GetTaskCache().Get(
-		fmt.Sprintf(common.CacheKeyTargetVersion, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), ctx.GetTaskName()),
-	)
-	if !ok {
+	if _, ok := s.targetVersion(ctx); !ok {
 		return false, fmt.Errorf("precheck failed: target version not found in cache. The 'upgrade plan' step must run first")
 	}
 
@@ -62,9 +66,7 @@ func (s *KubeadmUpgradeApplyStep) Run(ctx runtime.ExecutionContext) (*types.Step
 		return result, err
 	}
 
-	targetVersion, ok := ctx.GetTaskCache().Get(
-		fmt.Sprintf(common.CacheKeyTargetVersion, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), ctx.GetTaskName()),
-	)
+	targetVersion, ok := s.targetVersion(ctx)
 	if !ok {
 		err := fmt.Errorf("could not retrieve target version from cache")
 		result.MarkFailed(err, "could not retrieve target version")
@@ -113,6 +115,15 @@ func (s *KubeadmUpgradeApplyStep) Run(ctx runtime.ExecutionContext) (*types.Step
 	return result, nil
 }
 
+func (s *KubeadmUpgradeApplyStep) targetVersion(ctx runtime.ExecutionContext) (interface{}, bool) {
+	if s.TargetVersion != "" {
+		return s.TargetVersion, true
+	}
+	return ctx.GetTaskCache().Get(
+		fmt.Sprintf(common.CacheKeyTargetVersion, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), ctx.GetTaskName()),
+	)
+}
+
 func (s *KubeadmUpgradeApplyStep) Rollback(ctx runtime.ExecutionContext) error {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
 	logger.Warn("Rollback for 'kubeadm upgrade apply' is not performed by this step.")
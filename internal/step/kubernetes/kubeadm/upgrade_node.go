@@ -16,6 +16,8 @@ import (
 
 type KubeadmUpgradeNodeStep struct {
 	step.Base
+	// TargetVersion, if set, is used instead of the version saved by the upgrade plan step.
+	TargetVersion string
 }
 
 type KubeadmUpgradeNodeStepBuilder struct {
@@ -34,6 +36,11 @@ func NewKubeadmUpgradeNodeStepBuilder(ctx runtime.ExecutionContext, instanceName
 	return b
 }
 
+func (b *KubeadmUpgradeNodeStepBuilder) WithTargetVersion(v string) *KubeadmUpgradeNodeStepBuilder {
+	b.Step.TargetVersion = v
+	return b
+}
+
 func (s *KubeadmUpgradeNodeStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
@@ -42,11 +49,15 @@ func (s *KubeadmUpgradeNodeStep) Precheck(ctx runtime.ExecutionContext) (isDone
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	logger.Info("Starting precheck for 'kubeadm upgrade node'...")
 
-	targetVersion, ok := ctx.GetTaskCache().Get(
-		fmt.Sprintf(common.CacheKeyTargetVersion, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), ctx.GetTaskName()),
-	)
-	if !ok {
-		return false, fmt.Errorf("precheck failed: target version not found in cache")
+	var targetVersion interface{} = s.TargetVersion
+	if s.TargetVersion == "" {
+		var ok bool
+		targetVersion, ok = ctx.GetTaskCache().Get(
+			fmt.Sprintf(common.CacheKeyTargetVersion, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetModuleName(), ctx.GetTaskName()),
+		)
+		if !ok {
+			return false, fmt.Errorf("precheck failed: target version not found in cache")
+		}
 	}
 	targetVer, err := version.ParseGeneric(targetVersion.(string))
 	if err != nil {
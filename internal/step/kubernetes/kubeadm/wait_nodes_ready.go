@@ -0,0 +1,116 @@
+package kubeadm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// WaitNodesReadyStep waits on a control plane node until the given nodes are registered and report
+// Ready, which also means the CNI plugin is running on them.
+type WaitNodesReadyStep struct {
+	step.Base
+	NodeNames    []string
+	WaitTimeout  time.Duration
+	PollInterval time.Duration
+}
+
+type WaitNodesReadyStepBuilder struct {
+	step.Builder[WaitNodesReadyStepBuilder, *WaitNodesReadyStep]
+}
+
+func NewWaitNodesReadyStepBuilder(ctx runtime.ExecutionContext, instanceName string, nodeNames []string) *WaitNodesReadyStepBuilder {
+	s := &WaitNodesReadyStep{
+		NodeNames:    nodeNames,
+		WaitTimeout:  10 * time.Minute,
+		PollInterval: 5 * time.Second,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Wait for nodes %s to become Ready", s.Base.Meta.Name, strings.Join(nodeNames, ", "))
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = s.WaitTimeout + time.Minute
+	b := new(WaitNodesReadyStepBuilder).Init(s)
+	return b
+}
+
+func (s *WaitNodesReadyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *WaitNodesReadyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return len(s.NodeNames) == 0, nil
+}
+
+func (s *WaitNodesReadyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	// kubectl wait fails at once with NotFound for a Node the kubelet has not registered yet, so
+	// wait for every Node object to exist first and spend the rest of the timeout on readiness.
+	kubectl := "kubectl --kubeconfig " + filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+	deadline := time.Now().Add(s.WaitTimeout)
+	logger.Info("Waiting for nodes to register.", "nodes", s.NodeNames)
+	for _, name := range s.NodeNames {
+		if err := s.waitRegistered(ctx, conn, kubectl, name, deadline); err != nil {
+			result.MarkFailed(err, "node did not register")
+			return result, err
+		}
+	}
+
+	nodes := make([]string, 0, len(s.NodeNames))
+	for _, name := range s.NodeNames {
+		nodes = append(nodes, "node/"+name)
+	}
+	remaining := time.Until(deadline).Round(time.Second)
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+	cmd := fmt.Sprintf("%s wait --for=condition=Ready %s --timeout=%s", kubectl, strings.Join(nodes, " "), remaining)
+	logger.Info("Waiting for nodes to become Ready.", "nodes", s.NodeNames)
+	if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		result.MarkFailed(err, "nodes did not become Ready")
+		return result, fmt.Errorf("nodes %s did not become Ready: %w", strings.Join(s.NodeNames, ", "), err)
+	}
+
+	result.MarkCompleted("all nodes are Ready")
+	return result, nil
+}
+
+// waitRegistered polls until the Node object of name exists or the deadline passes.
+func (s *WaitNodesReadyStep) waitRegistered(ctx runtime.ExecutionContext, conn connector.Connector, kubectl, name string, deadline time.Time) error {
+	cmd := fmt.Sprintf("%s get node %s -o name", kubectl, name)
+	for {
+		_, err := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo)
+		if err == nil {
+			return nil
+		}
+		if !time.Now().Add(s.PollInterval).Before(deadline) {
+			return fmt.Errorf("node %s did not register within %v: %w", name, s.WaitTimeout, err)
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			return ctx.GoContext().Err()
+		case <-time.After(s.PollInterval):
+		}
+	}
+}
+
+func (s *WaitNodesReadyStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*WaitNodesReadyStep)(nil)
@@ -0,0 +1,74 @@
+package kubeadm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// fakeNodeRegistrationRunner reports a Node as NotFound until it was looked up registerAfter times.
+type fakeNodeRegistrationRunner struct {
+	runner.Runner
+	registerAfter int
+	lookups       int
+	commands      []string
+}
+
+func (r *fakeNodeRegistrationRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	r.commands = append(r.commands, cmd)
+	if strings.Contains(cmd, " get node ") {
+		r.lookups++
+		if r.registerAfter == 0 || r.lookups < r.registerAfter {
+			return &runner.CommandResult{ExitCode: 1}, fmt.Errorf(`Error from server (NotFound): nodes "worker1" not found`)
+		}
+	}
+	return &runner.CommandResult{}, nil
+}
+
+func TestWaitNodesReadyStep_WaitsForRegistration(t *testing.T) {
+	tests := []struct {
+		name           string
+		registerAfter  int
+		expectedStatus types.StepStatus
+		expectWait     bool
+	}{
+		{name: "node registers after a few polls", registerAfter: 3, expectedStatus: types.StepStatusCompleted, expectWait: true},
+		{name: "node never registers", registerAfter: 0, expectedStatus: types.StepStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeNodeRegistrationRunner{registerAfter: tt.registerAfter}
+			ctx := &runtimetest.Context{
+				Runner: r,
+				Host:   connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1"}),
+			}
+			s, err := NewWaitNodesReadyStepBuilder(ctx, "WaitNodesReady", []string{"worker1"}).Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+			s.WaitTimeout = 100 * time.Millisecond
+			s.PollInterval = time.Millisecond
+
+			result, err := s.Run(ctx)
+			if result.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q (err: %v)", tt.expectedStatus, result.Status, err)
+			}
+			waited := strings.Contains(r.commands[len(r.commands)-1], "wait --for=condition=Ready node/worker1")
+			if waited != tt.expectWait {
+				t.Errorf("expected kubectl wait to run: %v, commands: %v", tt.expectWait, r.commands)
+			}
+			if tt.expectWait && r.lookups != tt.registerAfter {
+				t.Errorf("expected %d lookups before waiting, got %d", tt.registerAfter, r.lookups)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
@@ -51,7 +52,7 @@ func NewCreateKubeletConfigYAMLStepBuilder(ctx runtime.ExecutionContext, instanc
 	clusterCfg := ctx.GetClusterConfig()
 	k8sSpec := clusterCfg.Spec.Kubernetes
 
-	_, serviceNet, _ := net.ParseCIDR(clusterCfg.Spec.Network.KubeServiceCIDR)
+	_, serviceNet, _ := net.ParseCIDR(helpers.PrimaryCIDR(clusterCfg.Spec.Network.KubeServiceCIDR))
 	dnsIP, _ := helpers.GetIPAtIndex(serviceNet, 10)
 
 	s := &CreateKubeletConfigYAMLStep{
@@ -77,6 +78,10 @@ func NewCreateKubeletConfigYAMLStepBuilder(ctx runtime.ExecutionContext, instanc
 		RemoteConfigYAMLFile:             common.KubeletConfigYAMLPathTarget,
 	}
 
+	// With NodeLocal DNSCache the pods query the cache on their node, which forwards to CoreDNS.
+	if localDNS := v1alpha1.NodeLocalDNSIP(clusterCfg.Spec); localDNS != "" {
+		s.ClusterDNSIP = localDNS
+	}
 	if k8sSpec.DNSDomain != "" {
 		s.ClusterDomain = k8sSpec.DNSDomain
 	}
@@ -209,6 +214,20 @@ func (s *CreateKubeletConfigYAMLStep) render(ctx runtime.ExecutionContext) (stri
 	return buffer.String(), nil
 }
 
+// Render returns the kubelet config.yaml that Run writes for the current host.
+func (s *CreateKubeletConfigYAMLStep) Render(ctx runtime.ExecutionContext) (string, error) {
+	return s.render(ctx)
+}
+
+// RenderFiles returns the kubelet config.yaml of Render at RemoteConfigYAMLFile.
+func (s *CreateKubeletConfigYAMLStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.render(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: s.RemoteConfigYAMLFile, Content: []byte(content)}}, nil
+}
+
 func (s *CreateKubeletConfigYAMLStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	runner := ctx.GetRunner()
@@ -293,3 +312,4 @@ func (s *CreateKubeletConfigYAMLStep) Rollback(ctx runtime.ExecutionContext) err
 }
 
 var _ step.Step = (*CreateKubeletConfigYAMLStep)(nil)
+var _ step.Renderer = (*CreateKubeletConfigYAMLStep)(nil)
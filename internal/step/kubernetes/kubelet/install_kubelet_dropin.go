@@ -96,6 +96,10 @@ func (s *InstallKubeletDropInStep) render(ctx runtime.ExecutionContext) (string,
 	if s.NodeIP == "" {
 		s.NodeIP = host.GetAddress()
 	}
+	// On dual-stack clusters the kubelet needs one node IP per family, primary family first.
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg.Spec.Network != nil && clusterCfg.Spec.Network.KubePodsCIDR != "" {
+		s.NodeIP = helpers.NodeIPsForCIDRs(s.NodeIP, clusterCfg.Spec.Network.KubePodsCIDR)
+	}
 	// Validate NodeIP is not empty to prevent kubelet registration failures
 	if s.NodeIP == "" {
 		return "", fmt.Errorf("node IP address is empty for host %s, cannot configure kubelet", host.GetName())
@@ -106,7 +110,7 @@ func (s *InstallKubeletDropInStep) render(ctx runtime.ExecutionContext) (string,
 	// This is consistent with the kubexm deployment model where all certs are pre-generated
 	s.KubeconfigArgs = fmt.Sprintf("--kubeconfig=%s", common.KubeletKubeconfigPathTarget)
 
-	tmplContent, err := templates.Get("kubernetes/kubelet-dropin-10-kubexm.conf.tmpl")
+	tmplContent, err := templates.Get("kubernetes/kubelet/kubelet-dropin-10-kubexm.conf.tmpl")
 	if err != nil {
 		return "", fmt.Errorf("failed to get kubelet drop-in template: %w", err)
 	}
@@ -124,6 +128,15 @@ func (s *InstallKubeletDropInStep) render(ctx runtime.ExecutionContext) (string,
 	return buffer.String(), nil
 }
 
+// RenderFiles returns the kubelet drop-in at RemoteDropInFile.
+func (s *InstallKubeletDropInStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.render(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: s.RemoteDropInFile, Content: []byte(content)}}, nil
+}
+
 func (s *InstallKubeletDropInStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	runner := ctx.GetRunner()
@@ -207,3 +220,4 @@ func (s *InstallKubeletDropInStep) Rollback(ctx runtime.ExecutionContext) error
 }
 
 var _ step.Step = (*InstallKubeletDropInStep)(nil)
+var _ step.Renderer = (*InstallKubeletDropInStep)(nil)
@@ -0,0 +1,63 @@
+package kubelet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+func TestInstallKubeletDropInStep_NodeIP(t *testing.T) {
+	tests := []struct {
+		name            string
+		podCIDR         string
+		internalAddress string
+		expectedNodeIP  string
+	}{
+		{
+			name:            "dual-stack with IPv4 primary",
+			podCIDR:         "10.244.0.0/16,fd00:10:244::/56",
+			internalAddress: "192.168.1.10,fd00::10",
+			expectedNodeIP:  "--node-ip=192.168.1.10,fd00::10\"",
+		},
+		{
+			name:            "dual-stack with IPv6 primary",
+			podCIDR:         "fd00:10:244::/56,10.244.0.0/16",
+			internalAddress: "192.168.1.10,fd00::10",
+			expectedNodeIP:  "--node-ip=fd00::10,192.168.1.10\"",
+		},
+		{
+			name:            "single-stack keeps the matching family",
+			podCIDR:         "10.244.0.0/16",
+			internalAddress: "192.168.1.10,fd00::10",
+			expectedNodeIP:  "--node-ip=192.168.1.10\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostSpec := v1alpha1.HostSpec{Name: "node1", Address: "192.168.1.10", InternalAddress: tt.internalAddress}
+			cluster := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+				Hosts:      []v1alpha1.HostSpec{hostSpec},
+				Kubernetes: &v1alpha1.Kubernetes{Version: "v1.28.2"},
+				Network:    &v1alpha1.Network{KubePodsCIDR: tt.podCIDR, KubeServiceCIDR: "10.96.0.0/12"},
+			}}
+			v1alpha1.SetDefaults_Cluster(cluster)
+			ctx := &runtimetest.Context{Cluster: cluster, Host: connector.NewHostFromSpec(hostSpec)}
+
+			s, err := NewInstallKubeletDropInStepBuilder(ctx, "InstallKubeletDropIn").Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+			content, err := s.render(ctx)
+			if err != nil {
+				t.Fatalf("failed to render drop-in: %v", err)
+			}
+			if !strings.Contains(content, tt.expectedNodeIP) {
+				t.Errorf("expected drop-in to contain %q, got:\n%s", tt.expectedNodeIP, content)
+			}
+		})
+	}
+}
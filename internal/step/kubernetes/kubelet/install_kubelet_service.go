@@ -21,6 +21,7 @@ const (
 )
 
 var _ step.Step = (*InstallKubeletServiceStep)(nil)
+var _ step.Renderer = (*InstallKubeletServiceStep)(nil)
 
 type InstallKubeletServiceStep struct {
 	step.Base
@@ -86,6 +87,15 @@ func (s *InstallKubeletServiceStep) renderContent(ctx runtime.ExecutionContext)
 	return buf.String(), nil
 }
 
+// RenderFiles returns the kubelet unit file at TargetPath.
+func (s *InstallKubeletServiceStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.renderContent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: s.TargetPath, Content: []byte(content)}}, nil
+}
+
 func (s *InstallKubeletServiceStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	runner := ctx.GetRunner()
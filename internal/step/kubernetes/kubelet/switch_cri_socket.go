@@ -0,0 +1,218 @@
+package kubelet
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+var (
+	criEndpointFlagPattern  = regexp.MustCompile(`(--container-runtime-endpoint[= ])[^\s"']+`)
+	criEndpointFieldPattern = regexp.MustCompile(`(?m)^(\s*containerRuntimeEndpoint:\s*)\S+`)
+)
+
+// SwitchKubeletCRISocketStep points the kubelet at a different CRI socket, e.g. when migrating a node from
+// docker/cri-dockerd to containerd. It rewrites the endpoint wherever kubexm or kubeadm may have set it and
+// restarts the kubelet. The node must be drained before this step runs.
+type SwitchKubeletCRISocketStep struct {
+	step.Base
+	Endpoint string
+	Files    []string
+	previous map[string]string
+}
+
+type SwitchKubeletCRISocketStepBuilder struct {
+	step.Builder[SwitchKubeletCRISocketStepBuilder, *SwitchKubeletCRISocketStep]
+}
+
+func NewSwitchKubeletCRISocketStepBuilder(ctx runtime.ExecutionContext, instanceName string) *SwitchKubeletCRISocketStepBuilder {
+	s := &SwitchKubeletCRISocketStep{
+		Endpoint: common.ContainerdDefaultEndpoint,
+		Files: []string{
+			filepath.Join(common.KubeletSystemdDropinDirTarget, "10-kubexm.conf"),
+			common.KubeletFlagsEnvPathTarget,
+			common.KubeletConfigYAMLPathTarget,
+		},
+	}
+
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg != nil && clusterCfg.Spec != nil && clusterCfg.Spec.Kubernetes != nil &&
+		clusterCfg.Spec.Kubernetes.ContainerRuntime != nil && clusterCfg.Spec.Kubernetes.ContainerRuntime.Containerd != nil {
+		if endpoint := clusterCfg.Spec.Kubernetes.ContainerRuntime.Containerd.Endpoint; endpoint != "" {
+			s.Endpoint = endpoint
+		}
+	}
+	if !strings.Contains(s.Endpoint, "://") {
+		s.Endpoint = "unix://" + s.Endpoint
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Switch kubelet CRI socket to %s", s.Base.Meta.Name, s.Endpoint)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(SwitchKubeletCRISocketStepBuilder).Init(s)
+	return b
+}
+
+func (b *SwitchKubeletCRISocketStepBuilder) WithEndpoint(endpoint string) *SwitchKubeletCRISocketStepBuilder {
+	b.Step.Endpoint = endpoint
+	return b
+}
+
+func (b *SwitchKubeletCRISocketStepBuilder) WithFiles(files ...string) *SwitchKubeletCRISocketStepBuilder {
+	b.Step.Files = files
+	return b
+}
+
+func (s *SwitchKubeletCRISocketStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// pendingChanges returns the current and rewritten content of every existing file that still
+// references another CRI endpoint, keyed by path.
+func (s *SwitchKubeletCRISocketStep) pendingChanges(ctx runtime.ExecutionContext) (current, updated map[string]string, err error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current = make(map[string]string)
+	updated = make(map[string]string)
+	for _, path := range s.Files {
+		exists, err := runner.Exists(ctx.GoContext(), conn, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check for %s: %w", path, err)
+		}
+		if !exists {
+			continue
+		}
+		content, err := runner.ReadFile(ctx.GoContext(), conn, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if switched, changed := switchCRIEndpoint(string(content), s.Endpoint); changed {
+			current[path] = string(content)
+			updated[path] = switched
+		}
+	}
+	return current, updated, nil
+}
+
+func (s *SwitchKubeletCRISocketStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	_, updated, err := s.pendingChanges(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(updated) == 0 {
+		logger.Infof("Kubelet already uses CRI endpoint %s. Step is done.", s.Endpoint)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *SwitchKubeletCRISocketStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	current, updated, err := s.pendingChanges(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to inspect kubelet configuration")
+		return result, err
+	}
+	if len(updated) == 0 {
+		result.MarkCompleted("kubelet already uses the requested CRI endpoint")
+		return result, nil
+	}
+
+	s.previous = make(map[string]string)
+	for _, path := range s.Files {
+		content, ok := updated[path]
+		if !ok {
+			continue
+		}
+		logger.Infof("Switching CRI endpoint in %s to %s", path, s.Endpoint)
+		if err := helpers.WriteContentToRemote(ctx, conn, content, path, "0644", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to write kubelet configuration")
+			return result, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		s.previous[path] = current[path]
+	}
+
+	if err := s.restartKubelet(ctx); err != nil {
+		result.MarkFailed(err, "failed to restart kubelet")
+		return result, err
+	}
+
+	result.SetMetadata("endpoint", s.Endpoint)
+	result.MarkCompleted("kubelet CRI socket switched successfully")
+	return result, nil
+}
+
+func (s *SwitchKubeletCRISocketStep) restartKubelet(ctx runtime.ExecutionContext) error {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	facts, err := runner.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		return fmt.Errorf("failed to gather facts: %w", err)
+	}
+	if err := runner.DaemonReload(ctx.GoContext(), conn, facts); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+	if err := runner.RestartService(ctx.GoContext(), conn, facts, common.KubeletServiceName); err != nil {
+		return fmt.Errorf("failed to restart kubelet: %w", err)
+	}
+	return nil
+}
+
+func (s *SwitchKubeletCRISocketStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	if len(s.previous) == 0 {
+		logger.Info("No kubelet configuration was changed, nothing to roll back.")
+		return nil
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		logger.Errorf("Failed to get connector for rollback: %v", err)
+		return nil
+	}
+	for path, content := range s.previous {
+		logger.Warnf("Restoring previous CRI endpoint in %s", path)
+		if err := helpers.WriteContentToRemote(ctx, conn, content, path, "0644", s.Sudo); err != nil {
+			logger.Errorf("Failed to restore %s during rollback: %v", path, err)
+		}
+	}
+	if err := s.restartKubelet(ctx); err != nil {
+		logger.Errorf("Failed to restart kubelet during rollback: %v", err)
+	}
+	return nil
+}
+
+// switchCRIEndpoint replaces the CRI endpoint in a kubelet systemd drop-in, kubeadm flags file or
+// KubeletConfiguration and reports whether anything changed.
+func switchCRIEndpoint(content, endpoint string) (string, bool) {
+	switched := criEndpointFlagPattern.ReplaceAllString(content, "${1}"+endpoint)
+	switched = criEndpointFieldPattern.ReplaceAllString(switched, "${1}"+endpoint)
+	return switched, switched != content
+}
+
+var _ step.Step = (*SwitchKubeletCRISocketStep)(nil)
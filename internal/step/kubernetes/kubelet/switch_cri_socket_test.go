@@ -0,0 +1,181 @@
+package kubelet
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const (
+	testDropInPath   = "/etc/systemd/system/kubelet.service.d/10-kubexm.conf"
+	testFlagsEnvPath = "/var/lib/kubelet/kubeadm-flags.env"
+)
+
+const testDockerDropIn = `# This drop-in file is generated by KubeXM.
+[Service]
+  Environment="KUBELET_CONFIG_ARGS=--config=/var/lib/kubelet/config.yaml"
+  Environment="KUBELET_OTHER_ARGS=--cgroup-driver=systemd --container-runtime=remote --container-runtime-endpoint=unix:///var/run/cri-dockerd.sock --pod-infra-container-image=registry.k8s.io/pause:3.9 --node-ip=10.0.0.1"
+`
+
+type fakeCRISocketRunner struct {
+	runner.Runner
+	files     map[string]string
+	uploaded  string
+	restarted []string
+}
+
+func (r *fakeCRISocketRunner) Exists(ctx context.Context, conn connector.Connector, path string) (bool, error) {
+	_, ok := r.files[path]
+	return ok, nil
+}
+
+func (r *fakeCRISocketRunner) ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error) {
+	return []byte(r.files[path]), nil
+}
+
+func (r *fakeCRISocketRunner) Mkdirp(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeCRISocketRunner) Upload(ctx context.Context, conn connector.Connector, srcPath, destPath string, sudo bool) error {
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	r.uploaded = string(content)
+	return nil
+}
+
+func (r *fakeCRISocketRunner) Move(ctx context.Context, conn connector.Connector, src, dest string, sudo bool) error {
+	r.files[dest] = r.uploaded
+	return nil
+}
+
+func (r *fakeCRISocketRunner) Chmod(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeCRISocketRunner) Remove(ctx context.Context, conn connector.Connector, path string, sudo bool, recursive bool) error {
+	return nil
+}
+
+func (r *fakeCRISocketRunner) GatherFacts(ctx context.Context, conn connector.Connector) (*runner.Facts, error) {
+	return &runner.Facts{}, nil
+}
+
+func (r *fakeCRISocketRunner) DaemonReload(ctx context.Context, conn connector.Connector, facts *runner.Facts) error {
+	return nil
+}
+
+func (r *fakeCRISocketRunner) RestartService(ctx context.Context, conn connector.Connector, facts *runner.Facts, serviceName string) error {
+	r.restarted = append(r.restarted, serviceName)
+	return nil
+}
+
+func TestSwitchKubeletCRISocketStep(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		expectRestart bool
+	}{
+		{
+			name: "drop-in and kubeadm flags point at cri-dockerd",
+			files: map[string]string{
+				testDropInPath:   testDockerDropIn,
+				testFlagsEnvPath: `KUBELET_KUBEADM_ARGS="--container-runtime-endpoint unix:///var/run/cri-dockerd.sock --pod-infra-container-image=registry.k8s.io/pause:3.9"`,
+			},
+			expectRestart: true,
+		},
+		{
+			name:          "drop-in already points at containerd",
+			files:         map[string]string{testDropInPath: strings.ReplaceAll(testDockerDropIn, "unix:///var/run/cri-dockerd.sock", "unix:///run/containerd/containerd.sock")},
+			expectRestart: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeCRISocketRunner{files: tt.files}
+			ctx := &runtimetest.Context{
+				Runner:  r,
+				Host:    connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+				WorkDir: t.TempDir(),
+			}
+			s, err := NewSwitchKubeletCRISocketStepBuilder(ctx, "SwitchKubeletCRISocket").Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+
+			isDone, err := s.Precheck(ctx)
+			if err != nil {
+				t.Fatalf("unexpected precheck error: %v", err)
+			}
+			if isDone == tt.expectRestart {
+				t.Errorf("expected precheck done=%v, got %v", !tt.expectRestart, isDone)
+			}
+
+			result, err := s.Run(ctx)
+			if err != nil || result.Status != types.StepStatusCompleted {
+				t.Fatalf("expected step to complete, got status=%q err=%v", result.Status, err)
+			}
+			for path, content := range r.files {
+				if strings.Contains(content, "cri-dockerd.sock") {
+					t.Errorf("%s still references cri-dockerd:\n%s", path, content)
+				}
+				if !strings.Contains(content, "unix:///run/containerd/containerd.sock") {
+					t.Errorf("%s does not reference the containerd socket:\n%s", path, content)
+				}
+			}
+			if !strings.Contains(r.files[testDropInPath], "--node-ip=10.0.0.1\"") {
+				t.Errorf("expected the remaining kubelet args to be preserved, got:\n%s", r.files[testDropInPath])
+			}
+			if restarted := len(r.restarted) > 0; restarted != tt.expectRestart {
+				t.Errorf("expected kubelet restart=%v, got %v", tt.expectRestart, r.restarted)
+			}
+		})
+	}
+}
+
+func TestSwitchCRIEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "flag with equals sign",
+			content:  `--container-runtime=remote --container-runtime-endpoint=unix:///var/run/cri-dockerd.sock --node-ip=10.0.0.1"`,
+			expected: `--container-runtime=remote --container-runtime-endpoint=unix:///run/containerd/containerd.sock --node-ip=10.0.0.1"`,
+			changed:  true,
+		},
+		{
+			name:     "kubelet configuration field",
+			content:  "kind: KubeletConfiguration\ncontainerRuntimeEndpoint: unix:///var/run/cri-dockerd.sock\n",
+			expected: "kind: KubeletConfiguration\ncontainerRuntimeEndpoint: unix:///run/containerd/containerd.sock\n",
+			changed:  true,
+		},
+		{
+			name:     "no endpoint configured",
+			content:  "kind: KubeletConfiguration\ncgroupDriver: systemd\n",
+			expected: "kind: KubeletConfiguration\ncgroupDriver: systemd\n",
+			changed:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := switchCRIEndpoint(tt.content, "unix:///run/containerd/containerd.sock")
+			if got != tt.expected || changed != tt.changed {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tt.expected, tt.changed, got, changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package kubelet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// UseLocalAPIServerProxyStep points the kubelet of a joined worker at the apiserver proxy running
+// on the node itself. Workers join through a master because the proxy static pod only starts once
+// kubelet runs; afterwards every request goes through 127.0.0.1 and is balanced across all apiservers.
+type UseLocalAPIServerProxyStep struct {
+	step.Base
+	KubeconfigPath string
+	ProxyPort      int
+	ServiceName    string
+}
+
+type UseLocalAPIServerProxyStepBuilder struct {
+	step.Builder[UseLocalAPIServerProxyStepBuilder, *UseLocalAPIServerProxyStep]
+}
+
+func NewUseLocalAPIServerProxyStepBuilder(ctx runtime.ExecutionContext, instanceName string) *UseLocalAPIServerProxyStepBuilder {
+	port := ctx.GetClusterConfig().Spec.ControlPlaneEndpoint.Port
+	if port == 0 {
+		port = common.DefaultAPIServerPort
+	}
+	s := &UseLocalAPIServerProxyStep{
+		KubeconfigPath: common.KubeletKubeconfigPathTarget,
+		ProxyPort:      port,
+		ServiceName:    "kubelet.service",
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Point kubelet at the local apiserver proxy", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(UseLocalAPIServerProxyStepBuilder).Init(s)
+	return b
+}
+
+func (s *UseLocalAPIServerProxyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *UseLocalAPIServerProxyStep) serverURL() string {
+	return fmt.Sprintf("https://127.0.0.1:%d", s.ProxyPort)
+}
+
+func (s *UseLocalAPIServerProxyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	cmd := fmt.Sprintf("grep -qE '^\\s*server: %s$' %s", s.serverURL(), s.KubeconfigPath)
+	if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		return false, nil
+	}
+	ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName()).Infof("kubelet already uses %s.", s.serverURL())
+	return true, nil
+}
+
+func (s *UseLocalAPIServerProxyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	facts, err := runner.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		result.MarkFailed(err, "failed to gather facts")
+		return result, err
+	}
+
+	logger.Infof("Waiting for the local apiserver proxy to listen on port %d...", s.ProxyPort)
+	if err := runner.WaitForPort(ctx.GoContext(), conn, facts, s.ProxyPort, s.Timeout/2); err != nil {
+		err = fmt.Errorf("local apiserver proxy is not listening on port %d: %w", s.ProxyPort, err)
+		result.MarkFailed(err, "local apiserver proxy is not running")
+		return result, err
+	}
+
+	cmd := fmt.Sprintf("sed -i -E 's#^(\\s*server:).*#\\1 %s#' %s", s.serverURL(), s.KubeconfigPath)
+	if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to update the server of %s", s.KubeconfigPath))
+		return result, err
+	}
+
+	if err := runner.RestartService(ctx.GoContext(), conn, facts, s.ServiceName); err != nil {
+		err = fmt.Errorf("failed to restart service '%s' on host %s: %w", s.ServiceName, ctx.GetHost().GetName(), err)
+		result.MarkFailed(err, "failed to restart kubelet")
+		return result, err
+	}
+
+	logger.Infof("kubelet now reaches the apiservers through %s.", s.serverURL())
+	result.MarkCompleted("kubelet points at the local apiserver proxy")
+	return result, nil
+}
+
+func (s *UseLocalAPIServerProxyStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Rollback for UseLocalAPIServerProxyStep is a no-op, the proxy serves the same apiservers.")
+	return nil
+}
+
+var _ step.Step = (*UseLocalAPIServerProxyStep)(nil)
@@ -0,0 +1,93 @@
+package perform
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+type DeleteNodeStep struct {
+	step.Base
+	TargetNodeName string
+}
+
+type DeleteNodeStepBuilder struct {
+	step.Builder[DeleteNodeStepBuilder, *DeleteNodeStep]
+}
+
+func NewDeleteNodeStepBuilder(ctx runtime.ExecutionContext, instanceName string, targetNodeName string) *DeleteNodeStepBuilder {
+	s := &DeleteNodeStep{
+		TargetNodeName: targetNodeName,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("Delete the Node object of '%s' from the cluster", targetNodeName)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(DeleteNodeStepBuilder).Init(s)
+	return b
+}
+
+func (s *DeleteNodeStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *DeleteNodeStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "exec_host", ctx.GetHost().GetName(), "target_node", s.TargetNodeName, "phase", "Precheck")
+
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+
+	checkCmd := fmt.Sprintf("kubectl --kubeconfig /etc/kubernetes/admin.conf get node %s --ignore-not-found -o name", s.TargetNodeName)
+	runResult, err := runner.Run(ctx.GoContext(), conn, checkCmd, s.Sudo)
+	if err != nil {
+		return false, fmt.Errorf("precheck failed: cannot look up node '%s': %w", s.TargetNodeName, err)
+	}
+
+	if strings.TrimSpace(runResult.Stdout) == "" {
+		logger.Infof("Node '%s' is not registered in the cluster. Step is done.", s.TargetNodeName)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *DeleteNodeStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "exec_host", ctx.GetHost().GetName(), "target_node", s.TargetNodeName, "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	logger.Infof("Deleting node '%s'...", s.TargetNodeName)
+	deleteCmd := fmt.Sprintf("kubectl --kubeconfig /etc/kubernetes/admin.conf delete node %s --ignore-not-found", s.TargetNodeName)
+
+	if _, err := runner.Run(ctx.GoContext(), conn, deleteCmd, s.Sudo); err != nil {
+		err = fmt.Errorf("failed to delete node '%s': %w", s.TargetNodeName, err)
+		result.MarkFailed(err, "failed to delete node")
+		return result, err
+	}
+
+	logger.Infof("Node '%s' deleted successfully.", s.TargetNodeName)
+	result.MarkCompleted("node deleted successfully")
+	return result, nil
+}
+
+func (s *DeleteNodeStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "exec_host", ctx.GetHost().GetName(), "target_node", s.TargetNodeName, "phase", "Rollback")
+	logger.Warn("A deleted Node object cannot be restored; the kubelet re-registers it if the node rejoins.")
+	return nil
+}
+
+var _ step.Step = (*DeleteNodeStep)(nil)
@@ -0,0 +1,141 @@
+package perform
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// WaitNodeReadyOnRuntimeStep waits until a node reports Ready and its kubelet reports the expected
+// container runtime, e.g. "containerd" for a containerRuntimeVersion of "containerd://1.7.13".
+type WaitNodeReadyOnRuntimeStep struct {
+	step.Base
+	TargetNodeName  string
+	ExpectedRuntime string
+	PollInterval    time.Duration
+	WaitTimeout     time.Duration
+}
+
+type WaitNodeReadyOnRuntimeStepBuilder struct {
+	step.Builder[WaitNodeReadyOnRuntimeStepBuilder, *WaitNodeReadyOnRuntimeStep]
+}
+
+func NewWaitNodeReadyOnRuntimeStepBuilder(ctx runtime.ExecutionContext, instanceName string, targetNodeName string, expectedRuntime string) *WaitNodeReadyOnRuntimeStepBuilder {
+	s := &WaitNodeReadyOnRuntimeStep{
+		TargetNodeName:  targetNodeName,
+		ExpectedRuntime: expectedRuntime,
+		PollInterval:    5 * time.Second,
+		WaitTimeout:     5 * time.Minute,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("Wait for node '%s' to become Ready on %s", targetNodeName, expectedRuntime)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = s.WaitTimeout + 1*time.Minute
+
+	b := new(WaitNodeReadyOnRuntimeStepBuilder).Init(s)
+	return b
+}
+
+func (b *WaitNodeReadyOnRuntimeStepBuilder) WithPolling(interval, timeout time.Duration) *WaitNodeReadyOnRuntimeStepBuilder {
+	b.Step.PollInterval = interval
+	b.Step.WaitTimeout = timeout
+	b.Step.Base.Timeout = timeout + 1*time.Minute
+	return b
+}
+
+func (s *WaitNodeReadyOnRuntimeStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// nodeStatus returns whether the node is Ready and the runtime reported by its kubelet.
+func (s *WaitNodeReadyOnRuntimeStep) nodeStatus(ctx runtime.ExecutionContext) (bool, string, error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, "", err
+	}
+
+	checkCmd := fmt.Sprintf("kubectl --kubeconfig /etc/kubernetes/admin.conf get node %s "+
+		"-o jsonpath='{.status.conditions[?(@.type==\"Ready\")].status} {.status.nodeInfo.containerRuntimeVersion}'", s.TargetNodeName)
+	runResult, err := runner.Run(ctx.GoContext(), conn, checkCmd, s.Sudo)
+	if err != nil {
+		return false, "", fmt.Errorf("cannot get status of node '%s': %w", s.TargetNodeName, err)
+	}
+	return parseNodeRuntimeStatus(runResult.Stdout)
+}
+
+func (s *WaitNodeReadyOnRuntimeStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "exec_host", ctx.GetHost().GetName(), "target_node", s.TargetNodeName, "phase", "Precheck")
+	ready, runtimeName, err := s.nodeStatus(ctx)
+	if err != nil {
+		return false, nil
+	}
+	if ready && runtimeName == s.ExpectedRuntime {
+		logger.Infof("Node '%s' is already Ready on %s. Step is done.", s.TargetNodeName, s.ExpectedRuntime)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *WaitNodeReadyOnRuntimeStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "exec_host", ctx.GetHost().GetName(), "target_node", s.TargetNodeName, "phase", "Run")
+
+	logger.Infof("Waiting for node '%s' to become Ready on %s (timeout: %v)...", s.TargetNodeName, s.ExpectedRuntime, s.WaitTimeout)
+	deadline := time.Now().Add(s.WaitTimeout)
+	var lastState string
+	for {
+		ready, runtimeName, err := s.nodeStatus(ctx)
+		if err == nil && ready && runtimeName == s.ExpectedRuntime {
+			break
+		}
+		if err != nil {
+			lastState = err.Error()
+		} else {
+			lastState = fmt.Sprintf("ready=%t runtime=%q", ready, runtimeName)
+		}
+		if time.Now().After(deadline) {
+			err := fmt.Errorf("node '%s' did not become Ready on %s within %v (last state: %s)", s.TargetNodeName, s.ExpectedRuntime, s.WaitTimeout, lastState)
+			result.MarkFailed(err, "node did not become Ready on the expected runtime")
+			return result, err
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			result.MarkFailed(ctx.GoContext().Err(), "wait for node was cancelled")
+			return result, ctx.GoContext().Err()
+		case <-time.After(s.PollInterval):
+		}
+	}
+
+	logger.Infof("Node '%s' is Ready on %s.", s.TargetNodeName, s.ExpectedRuntime)
+	result.MarkCompleted("node is Ready on the expected runtime")
+	return result, nil
+}
+
+func (s *WaitNodeReadyOnRuntimeStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "exec_host", ctx.GetHost().GetName(), "target_node", s.TargetNodeName, "phase", "Rollback")
+	logger.Info("Waiting for a node is a read-only operation, nothing to roll back.")
+	return nil
+}
+
+// parseNodeRuntimeStatus parses "<ReadyStatus> <runtime>://<version>" as printed by the jsonpath query.
+func parseNodeRuntimeStatus(output string) (bool, string, error) {
+	fields := strings.Fields(strings.Trim(strings.TrimSpace(output), "'"))
+	if len(fields) == 0 {
+		return false, "", fmt.Errorf("empty node status")
+	}
+	ready := fields[0] == "True"
+	var runtimeName string
+	if len(fields) > 1 {
+		runtimeName, _, _ = strings.Cut(fields[1], "://")
+	}
+	return ready, runtimeName, nil
+}
+
+var _ step.Step = (*WaitNodeReadyOnRuntimeStep)(nil)
@@ -0,0 +1,148 @@
+package verify
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckAPIServerLatencyStep sends a series of requests to /readyz through the control plane endpoint
+// and fails when their average latency exceeds MaxAverage.
+type CheckAPIServerLatencyStep struct {
+	step.Base
+	Endpoint   string
+	Samples    int
+	MaxAverage time.Duration
+}
+
+type CheckAPIServerLatencyStepBuilder struct {
+	step.Builder[CheckAPIServerLatencyStepBuilder, *CheckAPIServerLatencyStep]
+}
+
+func NewCheckAPIServerLatencyStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckAPIServerLatencyStepBuilder {
+	s := &CheckAPIServerLatencyStep{
+		Endpoint:   apiServerEndpoint(ctx),
+		Samples:    10,
+		MaxAverage: 500 * time.Millisecond,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Measure API server request latency", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(CheckAPIServerLatencyStepBuilder).Init(s)
+	return b
+}
+
+func (b *CheckAPIServerLatencyStepBuilder) WithMaxAverage(maxAverage time.Duration) *CheckAPIServerLatencyStepBuilder {
+	b.Step.MaxAverage = maxAverage
+	return b
+}
+
+// apiServerEndpoint returns the control plane endpoint as host:port, preferring the domain name.
+func apiServerEndpoint(ctx runtime.ExecutionContext) string {
+	host, port := "127.0.0.1", common.DefaultAPIServerPort
+	if cfg := ctx.GetClusterConfig(); cfg != nil && cfg.Spec != nil && cfg.Spec.ControlPlaneEndpoint != nil {
+		endpoint := cfg.Spec.ControlPlaneEndpoint
+		if endpoint.Domain != "" {
+			host = endpoint.Domain
+		} else if endpoint.Address != "" {
+			host = endpoint.Address
+		}
+		if endpoint.Port != 0 {
+			port = endpoint.Port
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (s *CheckAPIServerLatencyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckAPIServerLatencyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+// parseLatencies parses the per-request seconds printed by curl's %{time_total}, one per line.
+func parseLatencies(output string) ([]time.Duration, error) {
+	var latencies []time.Duration
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected latency value %q: %w", line, err)
+		}
+		latencies = append(latencies, time.Duration(seconds*float64(time.Second)).Round(time.Microsecond))
+	}
+	if len(latencies) == 0 {
+		return nil, fmt.Errorf("no latency samples")
+	}
+	return latencies, nil
+}
+
+func summarizeLatencies(latencies []time.Duration) (avg, max time.Duration) {
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+		if l > max {
+			max = l
+		}
+	}
+	return total / time.Duration(len(latencies)), max
+}
+
+func (s *CheckAPIServerLatencyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	// --fail turns an unready API server into a failed sample instead of a fast one.
+	cmd := fmt.Sprintf("for i in $(seq %d); do curl -sk --fail -o /dev/null -m 5 -w '%%{time_total}\\n' https://%s/readyz || exit 1; done",
+		s.Samples, s.Endpoint)
+	res, err := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo)
+	if err != nil {
+		err = fmt.Errorf("API server %s did not answer /readyz: %w", s.Endpoint, err)
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+	latencies, err := parseLatencies(res.Stdout)
+	if err != nil {
+		result.MarkFailed(err, "failed to parse API server latencies")
+		return result, err
+	}
+	avg, max := summarizeLatencies(latencies)
+	summary := fmt.Sprintf("%d requests to %s: avg %v, max %v", len(latencies), s.Endpoint,
+		avg.Round(time.Millisecond), max.Round(time.Millisecond))
+	if avg > s.MaxAverage {
+		err := fmt.Errorf("API server latency too high (%s, limit %v)", summary, s.MaxAverage)
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+
+	logger.Info("API server latency measured.", "average", avg, "max", max)
+	result.MarkCompleted(summary)
+	return result, nil
+}
+
+func (s *CheckAPIServerLatencyStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CheckAPIServerLatencyStep)(nil)
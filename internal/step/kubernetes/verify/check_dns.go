@@ -0,0 +1,102 @@
+package verify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckDNSResolutionStep resolves the kubernetes and verification Services from inside a verification
+// pod, which exercises CoreDNS and the pod's resolv.conf.
+type CheckDNSResolutionStep struct {
+	step.Base
+	Names          []string
+	KubeconfigPath string
+	PollInterval   time.Duration
+	Timeout        time.Duration
+}
+
+type CheckDNSResolutionStepBuilder struct {
+	step.Builder[CheckDNSResolutionStepBuilder, *CheckDNSResolutionStep]
+}
+
+func NewCheckDNSResolutionStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckDNSResolutionStepBuilder {
+	domain := dnsDomain(ctx)
+	s := &CheckDNSResolutionStep{
+		Names: []string{
+			"kubernetes.default.svc." + domain,
+			fmt.Sprintf("%s.%s.svc.%s", verifyName, verifyNamespace, domain),
+		},
+		KubeconfigPath: adminKubeconfigPath(),
+		PollInterval:   5 * time.Second,
+		Timeout:        time.Minute,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Verify that cluster DNS resolves Services", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(CheckDNSResolutionStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckDNSResolutionStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckDNSResolutionStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CheckDNSResolutionStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	pods, err := readyVerifyPods(ctx, conn, s.KubeconfigPath)
+	if err != nil || len(pods) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no Ready verification pod in namespace %s", verifyNamespace)
+		}
+		result.MarkFailed(err, "no pod to resolve names from")
+		return result, err
+	}
+	pod := pods[0]
+
+	for _, name := range s.Names {
+		// Retry for a short while, CoreDNS may still be syncing a Service that was just created.
+		err := waitFor(ctx, s.PollInterval, s.Timeout, func() (bool, error) {
+			_, err := ctx.GetRunner().KubectlExec(ctx.GoContext(), conn, pod.Metadata.Name, runner.KubectlExecOptions{
+				KubeconfigPath: s.KubeconfigPath,
+				Namespace:      verifyNamespace,
+				Sudo:           s.Sudo,
+			}, "nslookup", name)
+			return err == nil, err
+		})
+		if err != nil {
+			err = fmt.Errorf("pod %s on node %s cannot resolve %s: %w", pod.Metadata.Name, pod.Spec.NodeName, name, err)
+			result.MarkFailed(err, fmt.Sprintf("cannot resolve %s", name))
+			return result, err
+		}
+		logger.Info("Resolved name from a verification pod.", "name", name, "pod", pod.Metadata.Name)
+	}
+
+	result.MarkCompleted(fmt.Sprintf("resolved %d names", len(s.Names)))
+	return result, nil
+}
+
+func (s *CheckDNSResolutionStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CheckDNSResolutionStep)(nil)
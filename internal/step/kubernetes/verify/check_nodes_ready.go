@@ -0,0 +1,124 @@
+package verify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckNodesReadyStep verifies that every master and worker host is registered as a node and Ready.
+type CheckNodesReadyStep struct {
+	step.Base
+	ExpectedNodes  []string
+	KubeconfigPath string
+}
+
+type CheckNodesReadyStepBuilder struct {
+	step.Builder[CheckNodesReadyStepBuilder, *CheckNodesReadyStep]
+}
+
+func NewCheckNodesReadyStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckNodesReadyStepBuilder {
+	expected := make(map[string]bool)
+	for _, role := range []string{common.RoleMaster, common.RoleWorker} {
+		for _, host := range ctx.GetHostsByRole(role) {
+			expected[host.GetName()] = true
+		}
+	}
+	s := &CheckNodesReadyStep{KubeconfigPath: adminKubeconfigPath()}
+	for name := range expected {
+		s.ExpectedNodes = append(s.ExpectedNodes, name)
+	}
+	sort.Strings(s.ExpectedNodes)
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Verify that all nodes are registered and Ready", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(CheckNodesReadyStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckNodesReadyStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckNodesReadyStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CheckNodesReadyStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	nodes, err := ctx.GetRunner().KubectlGetNodes(ctx.GoContext(), conn, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		result.MarkFailed(err, "failed to list nodes")
+		return result, err
+	}
+
+	missing, notReady := evaluateNodes(s.ExpectedNodes, nodes)
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "not registered: "+strings.Join(missing, ", "))
+	}
+	if len(notReady) > 0 {
+		problems = append(problems, "not Ready: "+strings.Join(notReady, ", "))
+	}
+	if len(problems) > 0 {
+		err := fmt.Errorf("nodes %s", strings.Join(problems, "; "))
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+
+	logger.Info("All nodes are Ready.", "nodes", len(nodes))
+	result.MarkCompleted(fmt.Sprintf("%d nodes Ready", len(nodes)))
+	return result, nil
+}
+
+// evaluateNodes returns the expected nodes that are not registered and the registered nodes that are not Ready.
+func evaluateNodes(expected []string, nodes []runner.KubectlNodeInfo) (missing, notReady []string) {
+	registered := make(map[string]bool)
+	for _, node := range nodes {
+		registered[node.Metadata.Name] = true
+		ready := false
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == "Ready" && cond.Status == "True" {
+				ready = true
+			}
+		}
+		if !ready {
+			notReady = append(notReady, node.Metadata.Name)
+		}
+	}
+	for _, name := range expected {
+		if !registered[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(notReady)
+	return missing, notReady
+}
+
+func (s *CheckNodesReadyStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CheckNodesReadyStep)(nil)
@@ -0,0 +1,225 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const (
+	pvcVerifyNamespace         = "kubexm-verify-storage"
+	defaultStorageClassKey     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassKey = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// A pod consumes the claim so that StorageClasses with WaitForFirstConsumer binding bind it as well.
+const pvcVerifyManifestTemplate = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Namespace }}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  accessModes:
+  - ReadWriteOnce
+  resources:
+    requests:
+      storage: 16Mi
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  containers:
+  - name: consumer
+    image: {{ .Image }}
+    command: ["sh", "-c", "touch /data/ok && exec sleep 3600"]
+    volumeMounts:
+    - name: data
+      mountPath: /data
+  volumes:
+  - name: data
+    persistentVolumeClaim:
+      claimName: {{ .Name }}
+`
+
+// CheckPVCBindingStep creates a PersistentVolumeClaim without a storage class and waits until the default
+// StorageClass binds it. Clusters without a default StorageClass pass with a note, as storage is optional.
+type CheckPVCBindingStep struct {
+	step.Base
+	Namespace      string
+	Name           string
+	Image          string
+	KubeconfigPath string
+	PollInterval   time.Duration
+	BindTimeout    time.Duration
+}
+
+type CheckPVCBindingStepBuilder struct {
+	step.Builder[CheckPVCBindingStepBuilder, *CheckPVCBindingStep]
+}
+
+func NewCheckPVCBindingStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckPVCBindingStepBuilder {
+	s := &CheckPVCBindingStep{
+		Namespace:      pvcVerifyNamespace,
+		Name:           verifyName,
+		Image:          verifyImage(ctx),
+		KubeconfigPath: adminKubeconfigPath(),
+		PollInterval:   5 * time.Second,
+		BindTimeout:    3 * time.Minute,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Verify that the default StorageClass binds a PVC", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 6 * time.Minute
+
+	b := new(CheckPVCBindingStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckPVCBindingStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckPVCBindingStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+// defaultStorageClass returns the name of the StorageClass annotated as default, if any.
+func defaultStorageClass(classes []map[string]interface{}) string {
+	for _, class := range classes {
+		metadata, _ := class["metadata"].(map[string]interface{})
+		annotations, _ := metadata["annotations"].(map[string]interface{})
+		if annotations[defaultStorageClassKey] == "true" || annotations[betaDefaultStorageClassKey] == "true" {
+			name, _ := metadata["name"].(string)
+			return name
+		}
+	}
+	return ""
+}
+
+func (s *CheckPVCBindingStep) renderManifest() (string, error) {
+	tmpl, err := template.New("pvc").Parse(pvcVerifyManifestTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse PVC manifest template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return "", fmt.Errorf("failed to render PVC manifest: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (s *CheckPVCBindingStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	classes, err := runnerSvc.KubectlGetResourceList(ctx.GoContext(), conn, "storageclass", runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		result.MarkFailed(err, "failed to list StorageClasses")
+		return result, err
+	}
+	storageClass := defaultStorageClass(classes)
+	if storageClass == "" {
+		logger.Warn("No default StorageClass, skipping the PVC check.")
+		result.MarkCompleted("no default StorageClass, PVC binding not checked")
+		return result, nil
+	}
+
+	manifest, err := s.renderManifest()
+	if err != nil {
+		result.MarkFailed(err, "failed to render PVC manifest")
+		return result, err
+	}
+	if err := deleteNamespace(ctx, conn, s.KubeconfigPath, s.Namespace); err != nil {
+		result.MarkFailed(err, "failed to remove a previous PVC check")
+		return result, err
+	}
+	if _, err := runnerSvc.KubectlApply(ctx.GoContext(), conn, runner.KubectlApplyOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Filenames:      []string{"-"},
+		FileContent:    manifest,
+		Sudo:           s.Sudo,
+	}); err != nil {
+		result.MarkFailed(err, "failed to create the test PVC")
+		return result, err
+	}
+	defer func() {
+		if err := deleteNamespace(ctx, conn, s.KubeconfigPath, s.Namespace); err != nil {
+			logger.Warnf("Failed to remove namespace %s: %v", s.Namespace, err)
+		}
+	}()
+
+	phase := ""
+	err = waitFor(ctx, s.PollInterval, s.BindTimeout, func() (bool, error) {
+		phase, err = s.claimPhase(ctx, conn)
+		return phase == "Bound", err
+	})
+	if err != nil {
+		err = fmt.Errorf("PVC not bound by StorageClass %s within %v (phase %q): %w", storageClass, s.BindTimeout, phase, err)
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+
+	logger.Info("Test PVC bound.", "storageClass", storageClass)
+	result.MarkCompleted(fmt.Sprintf("PVC bound by default StorageClass %s", storageClass))
+	return result, nil
+}
+
+func (s *CheckPVCBindingStep) claimPhase(ctx runtime.ExecutionContext, conn runner.Connector) (string, error) {
+	raw, err := ctx.GetRunner().KubectlGet(ctx.GoContext(), conn, "pvc", s.Name, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Namespace:      s.Namespace,
+		OutputFormat:   "json",
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		return "", err
+	}
+	var pvc struct {
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(raw), &pvc); err != nil {
+		return "", fmt.Errorf("failed to parse PVC: %w", err)
+	}
+	return pvc.Status.Phase, nil
+}
+
+func (s *CheckPVCBindingStep) Rollback(ctx runtime.ExecutionContext) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+	if err := deleteNamespace(ctx, conn, s.KubeconfigPath, s.Namespace); err != nil {
+		ctx.GetLogger().Warnf("Failed to remove namespace %s: %v", s.Namespace, err)
+	}
+	return nil
+}
+
+var _ step.Step = (*CheckPVCBindingStep)(nil)
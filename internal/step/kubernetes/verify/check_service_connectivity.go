@@ -0,0 +1,163 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckServiceConnectivityStep fetches the verification Service from every verification pod, and the
+// pod on the next node from each pod, so that both Service routing and pod traffic between nodes are covered.
+type CheckServiceConnectivityStep struct {
+	step.Base
+	Port           int
+	KubeconfigPath string
+	RequestTimeout time.Duration
+}
+
+type CheckServiceConnectivityStepBuilder struct {
+	step.Builder[CheckServiceConnectivityStepBuilder, *CheckServiceConnectivityStep]
+}
+
+func NewCheckServiceConnectivityStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckServiceConnectivityStepBuilder {
+	s := &CheckServiceConnectivityStep{
+		Port:           verifyHTTPPort,
+		KubeconfigPath: adminKubeconfigPath(),
+		RequestTimeout: 5 * time.Second,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Verify Service and cross-node pod connectivity", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(CheckServiceConnectivityStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckServiceConnectivityStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckServiceConnectivityStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+// connectivityProbe is one HTTP request made from a verification pod.
+type connectivityProbe struct {
+	Pod         string
+	Node        string
+	URL         string
+	Description string
+}
+
+// connectivityProbes returns a request to serviceIP from every pod and a request from every pod to the
+// pod that follows it, so each node talks to another node once without probing every pair.
+func connectivityProbes(pods []runner.KubectlPodInfo, serviceIP string, port int) []connectivityProbe {
+	var probes []connectivityProbe
+	for i, pod := range pods {
+		probes = append(probes, connectivityProbe{
+			Pod:         pod.Metadata.Name,
+			Node:        pod.Spec.NodeName,
+			URL:         "http://" + net.JoinHostPort(serviceIP, "80"),
+			Description: fmt.Sprintf("%s -> service %s", pod.Spec.NodeName, serviceIP),
+		})
+		if len(pods) < 2 {
+			continue
+		}
+		next := pods[(i+1)%len(pods)]
+		probes = append(probes, connectivityProbe{
+			Pod:         pod.Metadata.Name,
+			Node:        pod.Spec.NodeName,
+			URL:         "http://" + net.JoinHostPort(next.Status.PodIP, strconv.Itoa(port)),
+			Description: fmt.Sprintf("%s -> pod on %s", pod.Spec.NodeName, next.Spec.NodeName),
+		})
+	}
+	return probes
+}
+
+func (s *CheckServiceConnectivityStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	pods, err := readyVerifyPods(ctx, conn, s.KubeconfigPath)
+	if err != nil || len(pods) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no Ready verification pod in namespace %s", verifyNamespace)
+		}
+		result.MarkFailed(err, "no pod to connect from")
+		return result, err
+	}
+	serviceIP, err := s.serviceIP(ctx, conn)
+	if err != nil {
+		result.MarkFailed(err, "failed to get the verification Service")
+		return result, err
+	}
+
+	probes := connectivityProbes(pods, serviceIP, s.Port)
+	var failed []string
+	for _, probe := range probes {
+		_, err := ctx.GetRunner().KubectlExec(ctx.GoContext(), conn, probe.Pod, runner.KubectlExecOptions{
+			KubeconfigPath: s.KubeconfigPath,
+			Namespace:      verifyNamespace,
+			Sudo:           s.Sudo,
+		}, "wget", "-q", "-O", "/dev/null", "-T", strconv.Itoa(int(s.RequestTimeout.Seconds())), probe.URL)
+		if err != nil {
+			logger.Warn("Connectivity probe failed.", "probe", probe.Description, "error", err)
+			failed = append(failed, probe.Description)
+		}
+	}
+	if len(failed) > 0 {
+		err := fmt.Errorf("%d of %d connectivity probes failed: %s", len(failed), len(probes), strings.Join(failed, "; "))
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+
+	logger.Info("All connectivity probes succeeded.", "probes", len(probes))
+	result.MarkCompleted(fmt.Sprintf("%d connectivity probes across %d nodes succeeded", len(probes), len(pods)))
+	return result, nil
+}
+
+func (s *CheckServiceConnectivityStep) serviceIP(ctx runtime.ExecutionContext, conn runner.Connector) (string, error) {
+	raw, err := ctx.GetRunner().KubectlGet(ctx.GoContext(), conn, "service", verifyName, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Namespace:      verifyNamespace,
+		OutputFormat:   "json",
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		return "", err
+	}
+	var svc struct {
+		Spec struct {
+			ClusterIP string `json:"clusterIP"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(raw), &svc); err != nil {
+		return "", fmt.Errorf("failed to parse service: %w", err)
+	}
+	if svc.Spec.ClusterIP == "" {
+		return "", fmt.Errorf("service %s/%s has no cluster IP", verifyNamespace, verifyName)
+	}
+	return svc.Spec.ClusterIP, nil
+}
+
+func (s *CheckServiceConnectivityStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CheckServiceConnectivityStep)(nil)
@@ -0,0 +1,103 @@
+package verify
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+const (
+	verifyNamespace = "kubexm-verify"
+	verifyName      = "kubexm-verify"
+	// verifyHTTPPort is the port busybox httpd listens on in the verification pods.
+	verifyHTTPPort     = 8080
+	defaultVerifyImage = "docker.io/library/busybox:1.36.1"
+)
+
+func adminKubeconfigPath() string {
+	return filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+}
+
+// verifyImage returns the busybox image of the verification workloads. It provides httpd,
+// wget and nslookup, so a single image covers every check.
+func verifyImage(ctx runtime.ExecutionContext) string {
+	if image := images.NewImageProvider(ctx).GetImage("busybox"); image != nil {
+		return image.FullName()
+	}
+	return defaultVerifyImage
+}
+
+func dnsDomain(ctx runtime.ExecutionContext) string {
+	if cfg := ctx.GetClusterConfig(); cfg != nil && cfg.Spec != nil && cfg.Spec.Kubernetes != nil && cfg.Spec.Kubernetes.DNSDomain != "" {
+		return cfg.Spec.Kubernetes.DNSDomain
+	}
+	return common.DefaultClusterLocal
+}
+
+// waitFor calls check every interval until it succeeds or timeout has passed, returning the last error.
+func waitFor(ctx runtime.ExecutionContext, interval, timeout time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := check()
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = fmt.Errorf("condition not met")
+			}
+			return err
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			return ctx.GoContext().Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func podReady(pod runner.KubectlPodInfo) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "Ready" && cond.Status == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// readyVerifyPods returns the Ready pods of the verification DaemonSet.
+func readyVerifyPods(ctx runtime.ExecutionContext, conn runner.Connector, kubeconfigPath string) ([]runner.KubectlPodInfo, error) {
+	pods, err := ctx.GetRunner().KubectlGetPods(ctx.GoContext(), conn, runner.KubectlGetOptions{
+		KubeconfigPath: kubeconfigPath,
+		Namespace:      verifyNamespace,
+		Selector:       "app=" + verifyName,
+		Sudo:           true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ready []runner.KubectlPodInfo
+	for _, pod := range pods {
+		if podReady(pod) {
+			ready = append(ready, pod)
+		}
+	}
+	return ready, nil
+}
+
+// deleteNamespace removes namespace and everything in it and waits until it is gone, so it can be
+// created again right away. A namespace that does not exist is ignored.
+func deleteNamespace(ctx runtime.ExecutionContext, conn runner.Connector, kubeconfigPath, namespace string) error {
+	return ctx.GetRunner().KubectlDelete(ctx.GoContext(), conn, "namespace", namespace, runner.KubectlDeleteOptions{
+		KubeconfigPath: kubeconfigPath,
+		IgnoreNotFound: true,
+		Wait:           true,
+		Timeout:        2 * time.Minute,
+		Sudo:           true,
+	})
+}
@@ -0,0 +1,205 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// The DaemonSet tolerates every taint so that each node, control plane included, runs one pod
+// serving its own name over HTTP.
+const verifyWorkloadManifestTemplate = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Namespace }}
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  selector:
+    matchLabels:
+      app: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Name }}
+    spec:
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: httpd
+        image: {{ .Image }}
+        command: ["sh", "-c", "mkdir -p /www && hostname > /www/index.html && exec httpd -f -p {{ .Port }} -h /www"]
+        ports:
+        - containerPort: {{ .Port }}
+        readinessProbe:
+          httpGet:
+            path: /
+            port: {{ .Port }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  selector:
+    app: {{ .Name }}
+  ports:
+  - port: 80
+    targetPort: {{ .Port }}
+`
+
+// DeployVerifyWorkloadStep deploys the verification DaemonSet and Service and waits until a pod runs
+// Ready on every node. Leftovers of an interrupted run are removed first.
+type DeployVerifyWorkloadStep struct {
+	step.Base
+	Namespace      string
+	Name           string
+	Image          string
+	Port           int
+	KubeconfigPath string
+	PollInterval   time.Duration
+	ReadyTimeout   time.Duration
+}
+
+type DeployVerifyWorkloadStepBuilder struct {
+	step.Builder[DeployVerifyWorkloadStepBuilder, *DeployVerifyWorkloadStep]
+}
+
+func NewDeployVerifyWorkloadStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DeployVerifyWorkloadStepBuilder {
+	s := &DeployVerifyWorkloadStep{
+		Namespace:      verifyNamespace,
+		Name:           verifyName,
+		Image:          verifyImage(ctx),
+		Port:           verifyHTTPPort,
+		KubeconfigPath: adminKubeconfigPath(),
+		PollInterval:   5 * time.Second,
+		ReadyTimeout:   3 * time.Minute,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Deploy a verification pod on every node", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 6 * time.Minute
+
+	b := new(DeployVerifyWorkloadStepBuilder).Init(s)
+	return b
+}
+
+func (b *DeployVerifyWorkloadStepBuilder) WithPolling(interval, timeout time.Duration) *DeployVerifyWorkloadStepBuilder {
+	b.Step.PollInterval = interval
+	b.Step.ReadyTimeout = timeout
+	return b
+}
+
+func (s *DeployVerifyWorkloadStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *DeployVerifyWorkloadStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *DeployVerifyWorkloadStep) renderManifest() (string, error) {
+	tmpl, err := template.New("verify").Parse(verifyWorkloadManifestTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse verification manifest template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return "", fmt.Errorf("failed to render verification manifest: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (s *DeployVerifyWorkloadStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	if err := deleteNamespace(ctx, conn, s.KubeconfigPath, s.Namespace); err != nil {
+		result.MarkFailed(err, "failed to remove a previous verification workload")
+		return result, err
+	}
+	manifest, err := s.renderManifest()
+	if err != nil {
+		result.MarkFailed(err, "failed to render verification manifest")
+		return result, err
+	}
+	logger.Info("Deploying verification workload.", "namespace", s.Namespace, "image", s.Image)
+	if _, err := ctx.GetRunner().KubectlApply(ctx.GoContext(), conn, runner.KubectlApplyOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Filenames:      []string{"-"},
+		FileContent:    manifest,
+		Sudo:           s.Sudo,
+	}); err != nil {
+		result.MarkFailed(err, "failed to deploy verification workload")
+		return result, fmt.Errorf("failed to deploy verification workload: %w", err)
+	}
+
+	var ready, desired int
+	err = waitFor(ctx, s.PollInterval, s.ReadyTimeout, func() (bool, error) {
+		ready, desired, err = s.daemonSetStatus(ctx, conn)
+		return err == nil && desired > 0 && ready == desired, err
+	})
+	if err != nil {
+		err = fmt.Errorf("verification pods not Ready within %v (%d/%d Ready): %w", s.ReadyTimeout, ready, desired, err)
+		result.MarkFailed(err, err.Error())
+		return result, err
+	}
+
+	logger.Info("Verification pods are Ready.", "pods", ready)
+	result.MarkCompleted(fmt.Sprintf("%d verification pods Ready", ready))
+	return result, nil
+}
+
+func (s *DeployVerifyWorkloadStep) daemonSetStatus(ctx runtime.ExecutionContext, conn runner.Connector) (ready, desired int, err error) {
+	raw, err := ctx.GetRunner().KubectlGet(ctx.GoContext(), conn, "daemonset", s.Name, runner.KubectlGetOptions{
+		KubeconfigPath: s.KubeconfigPath,
+		Namespace:      s.Namespace,
+		OutputFormat:   "json",
+		Sudo:           s.Sudo,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	var ds struct {
+		Status struct {
+			DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+			NumberReady            int `json:"numberReady"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(raw), &ds); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse daemonset status: %w", err)
+	}
+	return ds.Status.NumberReady, ds.Status.DesiredNumberScheduled, nil
+}
+
+func (s *DeployVerifyWorkloadStep) Rollback(ctx runtime.ExecutionContext) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+	if err := deleteNamespace(ctx, conn, s.KubeconfigPath, s.Namespace); err != nil {
+		ctx.GetLogger().Warnf("Failed to remove verification workload: %v", err)
+	}
+	return nil
+}
+
+var _ step.Step = (*DeployVerifyWorkloadStep)(nil)
@@ -0,0 +1,66 @@
+package verify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// RemoveVerifyWorkloadStep deletes the namespace of the verification workload.
+type RemoveVerifyWorkloadStep struct {
+	step.Base
+	Namespace      string
+	KubeconfigPath string
+}
+
+type RemoveVerifyWorkloadStepBuilder struct {
+	step.Builder[RemoveVerifyWorkloadStepBuilder, *RemoveVerifyWorkloadStep]
+}
+
+func NewRemoveVerifyWorkloadStepBuilder(ctx runtime.ExecutionContext, instanceName string) *RemoveVerifyWorkloadStepBuilder {
+	s := &RemoveVerifyWorkloadStep{
+		Namespace:      verifyNamespace,
+		KubeconfigPath: adminKubeconfigPath(),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Remove the verification workload", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = true
+	s.Base.Timeout = 3 * time.Minute
+
+	b := new(RemoveVerifyWorkloadStepBuilder).Init(s)
+	return b
+}
+
+func (s *RemoveVerifyWorkloadStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *RemoveVerifyWorkloadStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *RemoveVerifyWorkloadStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	if err := deleteNamespace(ctx, conn, s.KubeconfigPath, s.Namespace); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to delete namespace %s", s.Namespace))
+		return result, err
+	}
+	result.MarkCompleted(fmt.Sprintf("namespace %s removed", s.Namespace))
+	return result, nil
+}
+
+func (s *RemoveVerifyWorkloadStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*RemoveVerifyWorkloadStep)(nil)
@@ -0,0 +1,88 @@
+package verify
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runner"
+)
+
+func TestEvaluateNodes(t *testing.T) {
+	var nodes []runner.KubectlNodeInfo
+	if err := json.Unmarshal([]byte(`[
+		{"metadata": {"name": "master1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "worker1"}, "status": {"conditions": [{"type": "Ready", "status": "False"}]}}
+	]`), &nodes); err != nil {
+		t.Fatalf("failed to decode nodes: %v", err)
+	}
+
+	missing, notReady := evaluateNodes([]string{"master1", "worker1", "worker2"}, nodes)
+	if !reflect.DeepEqual(missing, []string{"worker2"}) {
+		t.Errorf("expected worker2 to be missing, got %v", missing)
+	}
+	if !reflect.DeepEqual(notReady, []string{"worker1"}) {
+		t.Errorf("expected worker1 to be NotReady, got %v", notReady)
+	}
+}
+
+func TestConnectivityProbes(t *testing.T) {
+	var pods []runner.KubectlPodInfo
+	if err := json.Unmarshal([]byte(`[
+		{"metadata": {"name": "a"}, "spec": {"nodeName": "node1"}, "status": {"podIP": "10.0.1.2"}},
+		{"metadata": {"name": "b"}, "spec": {"nodeName": "node2"}, "status": {"podIP": "10.0.2.2"}},
+		{"metadata": {"name": "c"}, "spec": {"nodeName": "node3"}, "status": {"podIP": "10.0.3.2"}}
+	]`), &pods); err != nil {
+		t.Fatalf("failed to decode pods: %v", err)
+	}
+
+	probes := connectivityProbes(pods, "10.96.0.20", 8080)
+	if len(probes) != 6 {
+		t.Fatalf("expected a service and a pod probe per pod, got %d: %+v", len(probes), probes)
+	}
+	if probes[0].Pod != "a" || probes[0].URL != "http://10.96.0.20:80" {
+		t.Errorf("expected pod a to call the service first, got %+v", probes[0])
+	}
+	if probes[5].Pod != "c" || probes[5].URL != "http://10.0.1.2:8080" {
+		t.Errorf("expected the last pod to call the first one, got %+v", probes[5])
+	}
+
+	if single := connectivityProbes(pods[:1], "10.96.0.20", 8080); len(single) != 1 {
+		t.Errorf("expected only the service probe for a single node, got %+v", single)
+	}
+}
+
+func TestParseLatencies(t *testing.T) {
+	latencies, err := parseLatencies("0.010\n0.030\n\n0.020\n")
+	if err != nil {
+		t.Fatalf("parseLatencies failed: %v", err)
+	}
+	avg, max := summarizeLatencies(latencies)
+	if avg != 20*time.Millisecond || max != 30*time.Millisecond {
+		t.Errorf("expected avg 20ms and max 30ms, got %v and %v", avg, max)
+	}
+
+	if _, err := parseLatencies(""); err == nil {
+		t.Error("expected an error without samples")
+	}
+	if _, err := parseLatencies("0.01\nfailed\n"); err == nil {
+		t.Error("expected an error for a malformed sample")
+	}
+}
+
+func TestDefaultStorageClass(t *testing.T) {
+	classes := []map[string]interface{}{
+		{"metadata": map[string]interface{}{"name": "nfs"}},
+		{"metadata": map[string]interface{}{
+			"name":        "local-path",
+			"annotations": map[string]interface{}{defaultStorageClassKey: "true"},
+		}},
+	}
+	if got := defaultStorageClass(classes); got != "local-path" {
+		t.Errorf("expected local-path, got %q", got)
+	}
+	if got := defaultStorageClass(classes[:1]); got != "" {
+		t.Errorf("expected no default StorageClass, got %q", got)
+	}
+}
@@ -0,0 +1,271 @@
+package libvirt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// ProvisionVMStep creates a VM on the current host, a libvirt hypervisor: a copy-on-write disk
+// backed by the template image, a cloud-init seed ISO setting the host name and the SSH keys of
+// the login user, and the domain itself, which is started right away.
+type ProvisionVMStep struct {
+	step.Base
+	VM             v1alpha1.LibvirtVM
+	Pool           string
+	Image          string
+	ImageFormat    string
+	OSVariant      string
+	Network        string
+	Bridge         string
+	User           string
+	AuthorizedKeys []string
+}
+
+type ProvisionVMStepBuilder struct {
+	step.Builder[ProvisionVMStepBuilder, *ProvisionVMStep]
+}
+
+func NewProvisionVMStepBuilder(ctx runtime.ExecutionContext, instanceName string, vm v1alpha1.LibvirtVM) *ProvisionVMStepBuilder {
+	s := &ProvisionVMStep{VM: vm}
+	if cfg := ctx.GetClusterConfig(); cfg.Spec.Infrastructure != nil && cfg.Spec.Infrastructure.Libvirt != nil {
+		l := cfg.Spec.Infrastructure.Libvirt
+		s.Pool = l.Pool
+		s.Image = l.Image
+		s.ImageFormat = l.ImageFormat
+		s.OSVariant = l.OSVariant
+		s.Network = l.Network
+		s.Bridge = l.Bridge
+		s.User = l.User
+		s.AuthorizedKeys = l.SSHAuthorizedKeys
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Create and start VM %s", s.Base.Meta.Name, vm.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+
+	b := new(ProvisionVMStepBuilder).Init(s)
+	return b
+}
+
+func (b *ProvisionVMStepBuilder) WithAuthorizedKeys(keys []string) *ProvisionVMStepBuilder {
+	b.Step.AuthorizedKeys = keys
+	return b
+}
+
+func (s *ProvisionVMStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *ProvisionVMStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.Image == "" {
+		return false, fmt.Errorf("no template image is configured for VM %s", s.VM.Name)
+	}
+	if len(s.AuthorizedKeys) == 0 {
+		return false, fmt.Errorf("no SSH key is configured for user %s of VM %s", s.User, s.VM.Name)
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	exists, err := ctx.GetRunner().VMExists(ctx.GoContext(), conn, s.VM.Name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for VM %s: %w", s.VM.Name, err)
+	}
+	if exists {
+		logger.Infof("VM %s already exists.", s.VM.Name)
+	}
+	return exists, nil
+}
+
+func (s *ProvisionVMStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "Failed to get connector")
+		return result, err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "Failed to get hypervisor facts")
+		return result, err
+	}
+
+	if err := runnerSvc.CreateVolume(ctx.GoContext(), conn, s.Pool, diskVolume(s.VM.Name), s.VM.DiskSizeGB, "qcow2", s.Image, s.ImageFormat); err != nil {
+		result.MarkFailed(err, "Failed to create VM disk")
+		return result, err
+	}
+	disk, err := diskPath(ctx.GoContext(), runnerSvc, conn, s.Pool, s.VM.Name)
+	if err != nil {
+		result.MarkFailed(err, "Failed to find VM disk")
+		return result, err
+	}
+
+	userData, err := RenderCloudInitUserData(s.VM.Name, s.User, s.AuthorizedKeys, s.Bridge != "")
+	if err != nil {
+		result.MarkFailed(err, "Failed to render cloud-init user-data")
+		return result, err
+	}
+	isoPath := seedISOPath(disk, s.VM.Name)
+	if err := runnerSvc.CreateCloudInitISO(ctx.GoContext(), conn, s.VM.Name, isoPath, userData, RenderCloudInitMetaData(s.VM.Name), ""); err != nil {
+		result.MarkFailed(err, "Failed to create cloud-init seed")
+		return result, err
+	}
+
+	nic := runner.VMNetworkInterface{Type: "network", Source: s.Network}
+	if s.Bridge != "" {
+		nic = runner.VMNetworkInterface{Type: "bridge", Source: s.Bridge}
+	}
+	if err := runnerSvc.CreateVM(ctx.GoContext(), conn, libvirtFacts(facts), s.VM.Name, s.VM.MemoryMB, s.VM.VCPUs, s.OSVariant,
+		[]string{disk}, []runner.VMNetworkInterface{nic}, "none", isoPath, nil, nil); err != nil {
+		result.MarkFailed(err, "Failed to create VM")
+		return result, err
+	}
+
+	logger.Infof("VM %s started with %d vCPUs, %d MiB memory and a %d GiB disk.", s.VM.Name, s.VM.VCPUs, s.VM.MemoryMB, s.VM.DiskSizeGB)
+	result.MarkCompleted("VM created")
+	return result, nil
+}
+
+func (s *ProvisionVMStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	if err := RemoveVM(ctx.GoContext(), ctx.GetRunner(), conn, s.Pool, s.VM.Name); err != nil {
+		logger.Warnf("Failed to remove VM %s: %v", s.VM.Name, err)
+	}
+	return nil
+}
+
+// RemoveVM stops and undefines a VM created by ProvisionVMStep, with its snapshots, and deletes
+// its disk volume and cloud-init seed. Parts that are already gone are skipped; the other parts
+// are removed even if one of them fails.
+func RemoveVM(ctx context.Context, r runner.Runner, conn connector.Connector, pool, vmName string) error {
+	var errs []error
+	exists, err := r.VMExists(ctx, conn, vmName)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if exists {
+		if err := r.DestroyVM(ctx, conn, vmName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop VM %s: %w", vmName, err))
+		}
+		if err := r.UndefineVM(ctx, conn, vmName, true, false, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to undefine VM %s: %w", vmName, err))
+		}
+	}
+	if path, err := diskPath(ctx, r, conn, pool, vmName); err == nil {
+		if err := r.Remove(ctx, conn, seedISOPath(path, vmName), true, false); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove the cloud-init seed of VM %s: %w", vmName, err))
+		}
+		if err := r.DeleteVolume(ctx, conn, pool, diskVolume(vmName)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete volume %s: %w", diskVolume(vmName), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// diskVolume returns the name of the disk volume of a VM in its pool.
+func diskVolume(vmName string) string {
+	return vmName + ".qcow2"
+}
+
+// diskPath returns the file of the disk volume of a VM on the hypervisor.
+func diskPath(ctx context.Context, r runner.Runner, conn connector.Connector, pool, vmName string) (string, error) {
+	res, err := r.Run(ctx, conn, fmt.Sprintf("virsh vol-path --pool %s %s", pool, diskVolume(vmName)), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the path of volume %s in pool %s: %w", diskVolume(vmName), pool, err)
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// seedISOPath places the cloud-init seed of a VM next to its disk, where libvirt can read it.
+func seedISOPath(diskPath, vmName string) string {
+	return filepath.Join(filepath.Dir(diskPath), vmName+"-cidata.iso")
+}
+
+// libvirtFacts returns facts whose OS architecture is named the way QEMU and libvirt name it.
+func libvirtFacts(facts *runner.Facts) *runner.Facts {
+	if facts == nil || facts.OS == nil {
+		return facts
+	}
+	out := *facts
+	os := *facts.OS
+	switch os.Arch {
+	case "amd64":
+		os.Arch = "x86_64"
+	case "arm64":
+		os.Arch = "aarch64"
+	}
+	out.OS = &os
+	return &out
+}
+
+type cloudInitUser struct {
+	Name              string   `yaml:"name"`
+	Sudo              string   `yaml:"sudo,omitempty"`
+	Shell             string   `yaml:"shell,omitempty"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys"`
+}
+
+type cloudInitUserData struct {
+	Hostname        string        `yaml:"hostname"`
+	ManageEtcHosts  bool          `yaml:"manage_etc_hosts"`
+	DisableRoot     bool          `yaml:"disable_root"`
+	SSHPasswordAuth bool          `yaml:"ssh_pwauth"`
+	Users           []interface{} `yaml:"users"`
+	Packages        []string      `yaml:"packages,omitempty"`
+	RunCmd          [][]string    `yaml:"runcmd,omitempty"`
+}
+
+// RenderCloudInitUserData returns the #cloud-config user-data of a VM: its host name and user
+// logging in with keys only. With guestAgent, qemu-guest-agent is installed so that libvirt can
+// read the address of a bridged VM, which it does not hand out itself.
+func RenderCloudInitUserData(hostname, user string, authorizedKeys []string, guestAgent bool) (string, error) {
+	data := cloudInitUserData{
+		Hostname:       hostname,
+		ManageEtcHosts: true,
+		DisableRoot:    user != "root",
+	}
+	if user == "root" {
+		data.Users = []interface{}{cloudInitUser{Name: "root", SSHAuthorizedKeys: authorizedKeys}}
+	} else {
+		data.Users = []interface{}{"default", cloudInitUser{Name: user, Sudo: "ALL=(ALL) NOPASSWD:ALL", Shell: "/bin/bash", SSHAuthorizedKeys: authorizedKeys}}
+	}
+	if guestAgent {
+		data.Packages = []string{"qemu-guest-agent"}
+		data.RunCmd = [][]string{{"systemctl", "enable", "--now", "qemu-guest-agent"}}
+	}
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return "#cloud-config\n" + string(out), nil
+}
+
+// RenderCloudInitMetaData returns the meta-data of a VM, whose instance id is its name.
+func RenderCloudInitMetaData(hostname string) string {
+	return fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", hostname, hostname)
+}
+
+var _ step.Step = (*ProvisionVMStep)(nil)
@@ -0,0 +1,68 @@
+package libvirt
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderCloudInitUserData(t *testing.T) {
+	out, err := RenderCloudInitUserData("worker1", "ubuntu", []string{"ssh-ed25519 AAAA key"}, true)
+	if err != nil {
+		t.Fatalf("RenderCloudInitUserData() error = %v", err)
+	}
+	if !strings.HasPrefix(out, "#cloud-config\n") {
+		t.Fatalf("user-data does not start with #cloud-config:\n%s", out)
+	}
+	var data struct {
+		Hostname string        `yaml:"hostname"`
+		Users    []interface{} `yaml:"users"`
+		Packages []string      `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("user-data is not valid YAML: %v", err)
+	}
+	if data.Hostname != "worker1" || len(data.Users) != 2 || data.Users[0] != "default" {
+		t.Errorf("hostname = %s, users = %v", data.Hostname, data.Users)
+	}
+	user, _ := data.Users[1].(map[string]interface{})
+	if user["name"] != "ubuntu" || user["sudo"] != "ALL=(ALL) NOPASSWD:ALL" {
+		t.Errorf("user = %v", user)
+	}
+	if strings.Join(data.Packages, ",") != "qemu-guest-agent" {
+		t.Errorf("packages = %v, want the guest agent", data.Packages)
+	}
+
+	root, err := RenderCloudInitUserData("cp1", "root", []string{"ssh-ed25519 AAAA key"}, false)
+	if err != nil {
+		t.Fatalf("RenderCloudInitUserData() error = %v", err)
+	}
+	if !strings.Contains(root, "disable_root: false") || strings.Contains(root, "default") || strings.Contains(root, "packages") {
+		t.Errorf("root user-data =\n%s", root)
+	}
+}
+
+func TestParseDomIfAddr(t *testing.T) {
+	lease := ` Name       MAC address          Protocol     Address
+-------------------------------------------------------------------------------
+ vnet0      52:54:00:6b:29:01    ipv4         192.168.122.31/24
+`
+	if got := parseDomIfAddr(lease); got != "192.168.122.31" {
+		t.Errorf("lease address = %q", got)
+	}
+
+	agent := ` Name       MAC address          Protocol     Address
+-------------------------------------------------------------------------------
+ lo         00:00:00:00:00:00    ipv4         127.0.0.1/8
+ -          -                    ipv6         ::1/128
+ enp1s0     52:54:00:6b:29:02    ipv6         fe80::5054:ff:fe6b:2902/64
+ -          -                    ipv4         10.0.0.52/24
+`
+	if got := parseDomIfAddr(agent); got != "10.0.0.52" {
+		t.Errorf("agent address = %q", got)
+	}
+	if got := parseDomIfAddr(""); got != "" {
+		t.Errorf("address of empty output = %q", got)
+	}
+}
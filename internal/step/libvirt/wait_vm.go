@@ -0,0 +1,144 @@
+package libvirt
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// WaitForVMStep waits, on the hypervisor of a VM, until the VM has an IPv4 address and sshd
+// accepts connections on it. The address is stored in the pipeline cache under
+// common.CacheKeyVMAddress. Addresses on a libvirt network come from its DHCP leases; a bridged
+// VM reports them through qemu-guest-agent.
+type WaitForVMStep struct {
+	step.Base
+	VMName      string
+	GuestAgent  bool
+	WaitTimeout time.Duration
+}
+
+type WaitForVMStepBuilder struct {
+	step.Builder[WaitForVMStepBuilder, *WaitForVMStep]
+}
+
+func NewWaitForVMStepBuilder(ctx runtime.ExecutionContext, instanceName, vmName string) *WaitForVMStepBuilder {
+	s := &WaitForVMStep{
+		VMName:      vmName,
+		WaitTimeout: common.DefaultLibvirtWaitTimeout,
+	}
+	if cfg := ctx.GetClusterConfig(); cfg.Spec.Infrastructure != nil && cfg.Spec.Infrastructure.Libvirt != nil {
+		l := cfg.Spec.Infrastructure.Libvirt
+		s.GuestAgent = l.Bridge != ""
+		if l.WaitTimeout > 0 {
+			s.WaitTimeout = l.WaitTimeout
+		}
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Wait for VM %s to accept SSH connections", s.Base.Meta.Name, vmName)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = s.WaitTimeout + time.Minute
+
+	b := new(WaitForVMStepBuilder).Init(s)
+	return b
+}
+
+func (s *WaitForVMStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// Precheck never skips the step: the address has to be looked up on every run.
+func (s *WaitForVMStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *WaitForVMStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "Failed to get connector")
+		return result, err
+	}
+
+	deadline := time.Now().Add(s.WaitTimeout)
+	address := ""
+	for {
+		if address == "" {
+			address = s.lookupAddress(ctx, conn)
+			if address != "" {
+				logger.Infof("VM %s got address %s, waiting for sshd.", s.VMName, address)
+			}
+		}
+		if address != "" {
+			probe := fmt.Sprintf("timeout 3 bash -c '</dev/tcp/%s/22'", address)
+			if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, probe, false); err == nil {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			err := fmt.Errorf("VM %s did not accept SSH connections within %s", s.VMName, s.WaitTimeout)
+			if address == "" {
+				err = fmt.Errorf("VM %s got no IPv4 address within %s", s.VMName, s.WaitTimeout)
+			}
+			result.MarkFailed(err, "Timed out waiting for VM")
+			return result, err
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			result.MarkFailed(ctx.GoContext().Err(), "Cancelled while waiting for VM")
+			return result, ctx.GoContext().Err()
+		case <-time.After(common.LibvirtVMPollInterval):
+		}
+	}
+
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyVMAddress, ctx.GetRunID(), s.VMName), address)
+	logger.Infof("VM %s is reachable over SSH at %s.", s.VMName, address)
+	result.SetMetadata("address", address)
+	result.MarkCompleted("VM is reachable")
+	return result, nil
+}
+
+func (s *WaitForVMStep) lookupAddress(ctx runtime.ExecutionContext, conn connector.Connector) string {
+	source := "lease"
+	if s.GuestAgent {
+		source = "agent"
+	}
+	res, err := ctx.GetRunner().Run(ctx.GoContext(), conn, fmt.Sprintf("virsh domifaddr %s --source %s", s.VMName, source), s.Sudo)
+	if err != nil {
+		// The guest agent does not answer until the VM has booted.
+		return ""
+	}
+	return parseDomIfAddr(res.Stdout)
+}
+
+func (s *WaitForVMStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+// parseDomIfAddr returns the first IPv4 address of `virsh domifaddr` output that is not a
+// loopback address.
+func parseDomIfAddr(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[len(fields)-2] != "ipv4" {
+			continue
+		}
+		addr, _, _ := strings.Cut(fields[len(fields)-1], "/")
+		if ip := net.ParseIP(addr); ip != nil && !ip.IsLoopback() {
+			return addr
+		}
+	}
+	return ""
+}
+
+var _ step.Step = (*WaitForVMStep)(nil)
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckVIPStep waits until the control plane VIP accepts TCP connections from the host.
+// The load balancer accepts connections on the frontend even while no apiserver backend is up,
+// so the check passes as soon as keepalived holds the VIP and the load balancer is listening.
+type CheckVIPStep struct {
+	step.Base
+	VIP      string
+	Port     int
+	Interval time.Duration
+}
+
+type CheckVIPStepBuilder struct {
+	step.Builder[CheckVIPStepBuilder, *CheckVIPStep]
+}
+
+func NewCheckVIPStepBuilder(ctx runtime.ExecutionContext, instanceName, vip string, port int) *CheckVIPStepBuilder {
+	s := &CheckVIPStep{VIP: vip, Port: port, Interval: 5 * time.Second}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Check that the VIP %s is reachable", s.Base.Meta.Name, net.JoinHostPort(vip, strconv.Itoa(port)))
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 3 * time.Minute
+	return new(CheckVIPStepBuilder).Init(s)
+}
+
+func (b *CheckVIPStepBuilder) WithInterval(interval time.Duration) *CheckVIPStepBuilder {
+	b.Step.Interval = interval
+	return b
+}
+
+func (s *CheckVIPStep) Meta() *spec.StepMeta { return &s.Base.Meta }
+
+func (s *CheckVIPStep) Precheck(ctx runtime.ExecutionContext) (bool, error) {
+	return false, nil
+}
+
+func (s *CheckVIPStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName())
+
+	if s.VIP == "" || s.Port <= 0 {
+		err := fmt.Errorf("invalid VIP endpoint %q:%d", s.VIP, s.Port)
+		result.MarkFailed(err, "VIP endpoint is not configured")
+		return result, err
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	endpoint := net.JoinHostPort(s.VIP, strconv.Itoa(s.Port))
+	cmd := fmt.Sprintf("nc -z -w 3 %s %d", s.VIP, s.Port)
+	deadline := time.Now().Add(s.Timeout)
+	for {
+		_, lastErr := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo)
+		if lastErr == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			err := fmt.Errorf("VIP %s is not reachable from %s after %v: %w", endpoint, ctx.GetHost().GetName(), s.Timeout, lastErr)
+			result.MarkFailed(err, "VIP health check failed")
+			return result, err
+		}
+		logger.Debugf("VIP %s not reachable yet, retrying in %v", endpoint, s.Interval)
+		select {
+		case <-ctx.GoContext().Done():
+			result.MarkFailed(ctx.GoContext().Err(), "VIP health check cancelled")
+			return result, ctx.GoContext().Err()
+		case <-time.After(s.Interval):
+		}
+	}
+
+	logger.Infof("VIP %s is reachable.", endpoint)
+	result.MarkCompleted(fmt.Sprintf("VIP %s is reachable", endpoint))
+	return result, nil
+}
+
+func (s *CheckVIPStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CheckVIPStep)(nil)
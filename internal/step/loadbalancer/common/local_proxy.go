@@ -0,0 +1,37 @@
+package common
+
+import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+// LocalProxyEnabled reports whether the cluster uses the "localhost load balancer" topology,
+// where every worker runs its own proxy to all apiservers and reaches them through 127.0.0.1.
+func LocalProxyEnabled(cluster *v1alpha1.Cluster) bool {
+	if cluster == nil || cluster.Spec == nil || cluster.Spec.ControlPlaneEndpoint == nil {
+		return false
+	}
+	ha := cluster.Spec.ControlPlaneEndpoint.HighAvailability
+	if ha == nil || ha.Enabled == nil || !*ha.Enabled ||
+		ha.Internal == nil || ha.Internal.Enabled == nil || !*ha.Internal.Enabled {
+		return false
+	}
+	return ha.Internal.Type == string(common.InternalLBTypeHAProxy) || ha.Internal.Type == string(common.InternalLBTypeNginx)
+}
+
+// LocalProxyHosts returns the workers that need a local apiserver proxy. Masters are left out as
+// their own apiserver already listens on the endpoint port.
+func LocalProxyHosts(workers, masters []remotefw.Host) []remotefw.Host {
+	isMaster := make(map[string]bool, len(masters))
+	for _, m := range masters {
+		isMaster[m.GetName()] = true
+	}
+	hosts := make([]remotefw.Host, 0, len(workers))
+	for _, w := range workers {
+		if !isMaster[w.GetName()] {
+			hosts = append(hosts, w)
+		}
+	}
+	return hosts
+}
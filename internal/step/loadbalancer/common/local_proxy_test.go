@@ -0,0 +1,45 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+func TestLocalProxyEnabled(t *testing.T) {
+	enabled := true
+	cluster := func(lbType common.InternalLoadBalancerType) *v1alpha1.Cluster {
+		return &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{ControlPlaneEndpoint: &v1alpha1.ControlPlaneEndpointSpec{
+			HighAvailability: &v1alpha1.HighAvailability{
+				Enabled:  &enabled,
+				Internal: &v1alpha1.InternalLoadBalancerConfig{Enabled: &enabled, Type: string(lbType)},
+			},
+		}}}
+	}
+
+	if !LocalProxyEnabled(cluster(common.InternalLBTypeNginx)) || !LocalProxyEnabled(cluster(common.InternalLBTypeHAProxy)) {
+		t.Error("expected nginx and haproxy internal load balancers to use a local proxy")
+	}
+	if LocalProxyEnabled(cluster(common.InternalLBTypeKubeVIP)) {
+		t.Error("kube-vip does not run a proxy on the workers")
+	}
+	if LocalProxyEnabled(&v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{}}) {
+		t.Error("expected no local proxy without a control plane endpoint")
+	}
+}
+
+func TestLocalProxyHosts(t *testing.T) {
+	host := func(name string) remotefw.Host {
+		return connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Address: "10.0.0.1"})
+	}
+	masters := []remotefw.Host{host("master1"), host("master2")}
+	workers := []remotefw.Host{host("master2"), host("worker1"), host("worker2")}
+
+	got := LocalProxyHosts(workers, masters)
+	if len(got) != 2 || got[0].GetName() != "worker1" || got[1].GetName() != "worker2" {
+		t.Errorf("expected only the dedicated workers, got %v", got)
+	}
+}
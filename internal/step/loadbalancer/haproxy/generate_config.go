@@ -81,6 +81,15 @@ func (s *GenerateHAProxyConfigStep) renderContent(ctx runtime.ExecutionContext)
 	return []byte(renderedConfig), nil
 }
 
+// RenderFiles returns the haproxy.cfg balancing the API servers of all masters.
+func (s *GenerateHAProxyConfigStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.renderContent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: filepath.Join(common.HAProxyDefaultConfDirTarget, "haproxy.cfg"), Content: content}}, nil
+}
+
 func (s *GenerateHAProxyConfigStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	runner := ctx.GetRunner()
@@ -168,3 +177,4 @@ func (s *GenerateHAProxyConfigStep) Rollback(ctx runtime.ExecutionContext) error
 }
 
 var _ step.Step = (*GenerateHAProxyConfigStep)(nil)
+var _ step.Renderer = (*GenerateHAProxyConfigStep)(nil)
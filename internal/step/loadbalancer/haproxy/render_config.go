@@ -105,6 +105,10 @@ func (s *RenderHAProxyConfigStep) BuildTemplateData(ctx runtime.ExecutionContext
 
 func (s *RenderHAProxyConfigStep) RenderContent(ctx runtime.ExecutionContext) (string, error) {
 	if s.TemplateData == nil {
+		s.TemplateData = &HAProxyConfigTemplateData{}
+	}
+	// The builder pre-allocates empty template data, so build it unless the caller filled it in.
+	if len(s.TemplateData.BackendServers) == 0 {
 		if err := s.BuildTemplateData(ctx); err != nil {
 			return "", err
 		}
@@ -133,11 +137,12 @@ frontend kubernetes-apiserver
   default_backend kubernetes-apiserver
 
 backend kubernetes-apiserver
-  option httpchk
-  http-check expect status 200
   mode tcp
+  balance roundrobin
+  option httpchk GET /healthz
+  http-check expect status 200
 {{ range .BackendServers }}
-  server {{ .Name }} {{ .Address }}:{{ .Port }} check inter 2000 fall 3 rise 2
+  server {{ .Name }} {{ .Address }}:{{ .Port }} check check-ssl verify none inter 2000 fall 3 rise 2
 {{ end }}
 `
 
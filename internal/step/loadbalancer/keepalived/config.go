@@ -3,6 +3,7 @@ package keepalived
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/common"
@@ -24,14 +25,57 @@ type GenerateKeepalivedConfigStepBuilder struct {
 }
 
 type KeepalivedConfigData struct {
-	State               string
-	Interface           string
-	VirtualRouterID     int
-	Priority            int
-	AuthenticationPass  string
-	UnicastSrcIP        string
-	UnicastPeers        []string
-	VirtualIP           string
+	State              string
+	Interface          string
+	VirtualRouterID    int
+	Priority           int
+	AdvertInt          int
+	AuthenticationPass string
+	UnicastSrcIP       string
+	UnicastPeers       []string
+	VirtualIP          string
+	TrackScript        string
+	TrackWeight        int
+}
+
+// vrrpStateAndPriority derives the VRRP state and priority of a load balancer node from its
+// position in the loadbalancer role: the first node is the MASTER and every following node is
+// a BACKUP with a lower priority, so the failover order follows the inventory order.
+func vrrpStateAndPriority(index int) (string, int) {
+	if index <= 0 {
+		return common.DefaultKeepaliveMaster, common.DefaultKeepalivedPriorityMaster
+	}
+	priority := common.DefaultKeepalivedPriorityMaster - index*common.DefaultKeepalivedPriorityStep
+	if priority < 1 {
+		priority = 1
+	}
+	return common.DefaultKeepaliveBackup, priority
+}
+
+// trackScriptFor returns the command keepalived uses to check that the local load balancer
+// process is alive.
+func trackScriptFor(lbType string) string {
+	if lbType == string(common.ExternalLBTypeKubexmKN) {
+		return "pidof nginx"
+	}
+	return "pidof haproxy"
+}
+
+// keepalivedConfigKey returns the task state key of the rendered config of a host, as every
+// node renders its own state and priority.
+func keepalivedConfigKey(hostName string) string {
+	return "keepalived.config." + hostName
+}
+
+// vipWithPrefix appends a host prefix length to the VIP unless it already carries one.
+func vipWithPrefix(vip string) string {
+	if strings.Contains(vip, "/") {
+		return vip
+	}
+	if strings.Contains(vip, ":") {
+		return vip + "/128"
+	}
+	return vip + "/32"
 }
 
 func NewGenerateKeepalivedConfigStepBuilder(ctx runtime.ExecutionContext, instanceName string) *GenerateKeepalivedConfigStepBuilder {
@@ -52,31 +96,77 @@ func (s *GenerateKeepalivedConfig) Precheck(ctx runtime.ExecutionContext) (bool,
 func (s *GenerateKeepalivedConfig) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 	cluster := ctx.GetClusterConfig()
-	facts, _ := ctx.GetHostFacts(ctx.GetHost())
+	currentHost := ctx.GetHost()
 	lbNodes := ctx.GetHostsByRole(common.RoleLoadBalancer)
 
-	state, priority := "BACKUP", 100
-	if len(lbNodes) > 0 && ctx.GetHost().GetName() == lbNodes[0].GetName() {
-		state, priority = "MASTER", 100
+	facts, err := ctx.GetHostFacts(currentHost)
+	if err != nil {
+		result.MarkFailed(err, "failed to get host facts")
+		return result, err
 	}
 
-	peers := make([]string, 0)
-	for _, node := range lbNodes {
-		if node.GetName() != ctx.GetHost().GetName() {
-			peers = append(peers, node.GetInternalAddress())
+	data := KeepalivedConfigData{
+		Interface:          facts.DefaultInterface,
+		VirtualRouterID:    common.DefaultKeepalivedVRID,
+		AdvertInt:          common.DefaultKeepalivedAdvertInt,
+		AuthenticationPass: common.DefaultKeepalivedAuthPass,
+		UnicastSrcIP:       currentHost.GetInternalAddress(),
+		UnicastPeers:       make([]string, 0, len(lbNodes)),
+		VirtualIP:          cluster.Spec.ControlPlaneEndpoint.Address,
+		TrackWeight:        common.DefaultKeepalivedTrackWeight,
+	}
+
+	index := -1
+	for i, node := range lbNodes {
+		if node.GetName() == currentHost.GetName() {
+			index = i
+			continue
+		}
+		data.UnicastPeers = append(data.UnicastPeers, node.GetInternalAddress())
+	}
+	if index < 0 {
+		err := fmt.Errorf("host %s does not have the %s role", currentHost.GetName(), common.RoleLoadBalancer)
+		result.MarkFailed(err, "keepalived can only be configured on load balancer nodes")
+		return result, err
+	}
+	data.State, data.Priority = vrrpStateAndPriority(index)
+
+	if ha := cluster.Spec.ControlPlaneEndpoint.HighAvailability; ha != nil && ha.External != nil {
+		data.TrackScript = trackScriptFor(ha.External.Type)
+		if ha.External.Keepalived != nil && len(ha.External.Keepalived.VRRPInstances) > 0 {
+			instance := ha.External.Keepalived.VRRPInstances[0]
+			if instance.Interface != "" {
+				data.Interface = instance.Interface
+			}
+			if instance.VirtualRouterID > 0 {
+				data.VirtualRouterID = instance.VirtualRouterID
+			}
+			if instance.AdvertInt != nil && *instance.AdvertInt > 0 {
+				data.AdvertInt = *instance.AdvertInt
+			}
+			if instance.Auth != nil && instance.Auth.AuthPass != "" {
+				data.AuthenticationPass = instance.Auth.AuthPass
+			}
+			if len(instance.VirtualIPs) > 0 && instance.VirtualIPs[0] != "" {
+				data.VirtualIP = instance.VirtualIPs[0]
+			}
 		}
 	}
+	if data.TrackScript == "" {
+		data.TrackScript = trackScriptFor("")
+	}
 
-	data := KeepalivedConfigData{
-		State:               state,
-		Interface:           facts.DefaultInterface,
-		VirtualRouterID:     60,
-		Priority:            priority,
-		AuthenticationPass:  "1111",
-		UnicastSrcIP:        facts.IPv4Default,
-		UnicastPeers:        peers,
-		VirtualIP:           cluster.Spec.ControlPlaneEndpoint.Address,
+	if data.Interface == "" {
+		err := fmt.Errorf("cannot determine the VRRP interface of host %s", currentHost.GetName())
+		result.MarkFailed(err, "set the interface of the first keepalived vrrp instance")
+		return result, err
 	}
+	if data.VirtualIP == "" {
+		err := fmt.Errorf("controlPlaneEndpoint address is required to configure the keepalived VIP")
+		result.MarkFailed(err, "missing VIP")
+		return result, err
+	}
+	data.VirtualIP = vipWithPrefix(data.VirtualIP)
 
 	templateContent, err := templates.Get("loadbalancer/keepalived/keepalived.conf.tmpl")
 	if err != nil {
@@ -92,7 +182,7 @@ func (s *GenerateKeepalivedConfig) Run(ctx runtime.ExecutionContext) (*types.Ste
 
 	// Publish to DataBus for downstream steps
 	dm := runtime.NewDataManager(ctx)
-	dm.Publish("keepalived.config", renderedConfig)
+	dm.Publish(keepalivedConfigKey(currentHost.GetName()), renderedConfig)
 
 	result.MarkCompleted("Keepalived config generated successfully")
 	return result, nil
@@ -134,13 +224,18 @@ func (s *DeployKeepalivedConfig) Run(ctx runtime.ExecutionContext) (*types.StepR
 	conn, _ := ctx.GetCurrentHostConnector()
 	dm := runtime.NewDataManager(ctx)
 
-	config, _ := dm.Subscribe("keepalived.config")
+	config, ok := dm.SubscribeString(keepalivedConfigKey(ctx.GetHost().GetName()))
+	if !ok {
+		err := fmt.Errorf("no rendered keepalived config found for host %s", ctx.GetHost().GetName())
+		result.MarkFailed(err, "keepalived config was not generated")
+		return result, err
+	}
 
 	remoteConfigDir := common.KeepalivedDefaultConfDirTarget
 	ctx.GetRunner().Mkdirp(ctx.GoContext(), conn, remoteConfigDir, "0755", true)
 
 	remoteConfigPath := filepath.Join(remoteConfigDir, common.DefaultKeepalivedConfig)
-	err := helpers.WriteContentToRemote(ctx, conn, config.(string), remoteConfigPath, "0644", true)
+	err := helpers.WriteContentToRemote(ctx, conn, config, remoteConfigPath, "0644", true)
 	if err != nil {
 		result.MarkFailed(err, "failed to deploy keepalived config")
 		return result, err
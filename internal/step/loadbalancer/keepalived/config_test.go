@@ -0,0 +1,72 @@
+package keepalived
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/templates"
+)
+
+func TestVRRPStateAndPriority(t *testing.T) {
+	state, priority := vrrpStateAndPriority(0)
+	if state != common.DefaultKeepaliveMaster || priority != common.DefaultKeepalivedPriorityMaster {
+		t.Errorf("first node: got %s/%d", state, priority)
+	}
+	prev := priority
+	for i := 1; i < 4; i++ {
+		state, priority = vrrpStateAndPriority(i)
+		if state != common.DefaultKeepaliveBackup {
+			t.Errorf("node %d: expected BACKUP, got %s", i, state)
+		}
+		if priority >= prev {
+			t.Errorf("node %d: priority %d is not lower than %d", i, priority, prev)
+		}
+		prev = priority
+	}
+	// A failing track script must drop the MASTER below the first BACKUP.
+	_, backup := vrrpStateAndPriority(1)
+	if common.DefaultKeepalivedPriorityMaster+common.DefaultKeepalivedTrackWeight >= backup {
+		t.Errorf("track weight %d does not trigger a failover", common.DefaultKeepalivedTrackWeight)
+	}
+	if _, priority = vrrpStateAndPriority(1000); priority != 1 {
+		t.Errorf("expected the priority to be floored at 1, got %d", priority)
+	}
+}
+
+func TestKeepalivedTemplate(t *testing.T) {
+	content, err := templates.Get("loadbalancer/keepalived/keepalived.conf.tmpl")
+	if err != nil {
+		t.Fatalf("failed to get template: %v", err)
+	}
+	rendered, err := templates.Render(content, KeepalivedConfigData{
+		State:              common.DefaultKeepaliveBackup,
+		Interface:          "eth0",
+		VirtualRouterID:    common.DefaultKeepalivedVRID,
+		Priority:           100,
+		AdvertInt:          1,
+		AuthenticationPass: common.DefaultKeepalivedAuthPass,
+		UnicastSrcIP:       "10.0.0.11",
+		UnicastPeers:       []string{"10.0.0.12"},
+		VirtualIP:          vipWithPrefix("10.0.0.100"),
+		TrackScript:        trackScriptFor(string(common.ExternalLBTypeKubexmKN)),
+		TrackWeight:        common.DefaultKeepalivedTrackWeight,
+	})
+	if err != nil {
+		t.Fatalf("failed to render template: %v", err)
+	}
+	for _, want := range []string{
+		`script "pidof nginx"`,
+		"weight -20",
+		"state BACKUP",
+		"priority 100",
+		"10.0.0.100/32 dev eth0",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered config does not contain %q:\n%s", want, rendered)
+		}
+	}
+	if vipWithPrefix("10.0.0.100/24") != "10.0.0.100/24" || vipWithPrefix("fd00::10") != "fd00::10/128" {
+		t.Error("unexpected VIP prefix handling")
+	}
+}
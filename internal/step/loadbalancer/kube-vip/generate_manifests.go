@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
@@ -43,6 +44,12 @@ type KubeVipTemplateData struct {
 	Interface        string
 	KubeVipImage     string
 	LoadBalancerPort int
+	Mode             string
+	BGPRouterID      string
+	BGPAS            uint32
+	BGPPeerAddress   string
+	BGPPeerAS        uint32
+	BGPSourceIP      string
 }
 
 func (s *GenerateKubeVipManifestStep) renderContent(ctx runtime.ExecutionContext) ([]byte, error) {
@@ -81,12 +88,26 @@ func (s *GenerateKubeVipManifestStep) renderContent(ctx runtime.ExecutionContext
 
 	var iface *string
 	var vipOverride *string
+	mode := common.DefaultKubeVIPMode
+	var bgpCfg *v1alpha1.KubeVIPBGPConfig
 	if haCfg.External != nil && haCfg.External.KubeVIP != nil {
 		iface = haCfg.External.KubeVIP.Interface
 		vipOverride = haCfg.External.KubeVIP.VIP
+		if haCfg.External.KubeVIP.Mode != nil && *haCfg.External.KubeVIP.Mode != "" {
+			mode = *haCfg.External.KubeVIP.Mode
+		}
+		bgpCfg = haCfg.External.KubeVIP.BGPConfig
+	}
+	if mode == common.KubeVIPModeBGP && bgpCfg == nil {
+		return nil, fmt.Errorf("kube-vip BGP mode requires bgpConfig")
 	}
 	if iface == nil {
-		iface = &facts.DefaultInterface
+		// In BGP mode the VIP is advertised by the routers, so it is bound to the loopback.
+		if mode == common.KubeVIPModeBGP {
+			iface = helpers.StrPtr("lo")
+		} else {
+			iface = &facts.DefaultInterface
+		}
 	}
 	if iface == nil || *iface == "" {
 		return nil, fmt.Errorf("network interface for kube-vip is not specified in config and could not be determined from host facts")
@@ -102,6 +123,17 @@ func (s *GenerateKubeVipManifestStep) renderContent(ctx runtime.ExecutionContext
 		Interface:        *iface,
 		KubeVipImage:     kubeVipImageRef,
 		LoadBalancerPort: clusterCfg.Spec.ControlPlaneEndpoint.Port,
+		Mode:             mode,
+	}
+	if mode == common.KubeVIPModeBGP {
+		data.BGPRouterID = bgpCfg.RouterID
+		if data.BGPRouterID == "" {
+			data.BGPRouterID = ctx.GetHost().GetInternalAddress()
+		}
+		data.BGPAS = bgpCfg.ASN
+		data.BGPPeerAddress = bgpCfg.PeerAddress
+		data.BGPPeerAS = bgpCfg.PeerASN
+		data.BGPSourceIP = bgpCfg.SourceAddress
 	}
 
 	templatePath := "loadbalancer/kube-vip/kube-vip-yaml.tmpl"
@@ -118,6 +150,15 @@ func (s *GenerateKubeVipManifestStep) renderContent(ctx runtime.ExecutionContext
 	return []byte(renderedContent), nil
 }
 
+// RenderFiles returns the kube-vip static pod manifest.
+func (s *GenerateKubeVipManifestStep) RenderFiles(ctx runtime.ExecutionContext) ([]step.RenderedFile, error) {
+	content, err := s.renderContent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []step.RenderedFile{{Path: filepath.Join(common.KubernetesManifestsDir, "kube-vip.yaml"), Content: content}}, nil
+}
+
 func (s *GenerateKubeVipManifestStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 	remoteManifestPath := filepath.Join(common.KubernetesManifestsDir, "kube-vip.yaml")
@@ -198,3 +239,4 @@ func (s *GenerateKubeVipManifestStep) Rollback(ctx runtime.ExecutionContext) err
 }
 
 var _ step.Step = (*GenerateKubeVipManifestStep)(nil)
+var _ step.Renderer = (*GenerateKubeVipManifestStep)(nil)
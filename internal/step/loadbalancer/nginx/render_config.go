@@ -85,12 +85,22 @@ func (s *RenderNginxConfigStep) BuildTemplateData(ctx runtime.ExecutionContext)
 
 func (s *RenderNginxConfigStep) RenderContent(ctx runtime.ExecutionContext) (string, error) {
 	if s.TemplateData == nil {
+		s.TemplateData = &NginxConfigTemplateData{}
+	}
+	// The builder pre-allocates empty template data, so build it unless the caller filled it in.
+	if len(s.TemplateData.UpstreamServers) == 0 {
 		if err := s.BuildTemplateData(ctx); err != nil {
 			return "", err
 		}
 	}
 
-	tmpl := `stream {
+	tmpl := `worker_processes auto;
+
+events {
+    worker_connections 16384;
+}
+
+stream {
     log /var/log/nginx/stream.log;
     error_log /var/log/nginx/stream_error.log;
 
@@ -1,36 +1,25 @@
 package calico
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
-	"text/template"
 	"time"
 
-	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
-	"github.com/mensylisir/kubexm/internal/util/helm"
-	"github.com/mensylisir/kubexm/internal/util/images"
-	"github.com/mensylisir/kubexm/internal/templates"
+	networkcommon "github.com/mensylisir/kubexm/internal/step/network/common"
 	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/cni"
+	"github.com/mensylisir/kubexm/internal/util/helm"
 )
 
 type GenerateCalicoValuesStep struct {
 	step.Base
-	Registry             string
-	IPPools              []v1alpha1.CalicoIPPool
-	VethMTU              int
-	LogSeverityScreen    string
-	TyphaEnabled         bool
-	TyphaReplicas        int
-	TyphaNodeSelector    map[string]string
-	OperatorImage        string
-	OperatorNodeSelector map[string]string
-	OperatorTolerations  []map[string]string
+	Provider cni.Provider
+	Options  cni.Options
 }
 
 type GenerateCalicoValuesStepBuilder struct {
@@ -41,94 +30,21 @@ func NewGenerateCalicoValuesStepBuilder(ctx runtime.ExecutionContext, instanceNa
 	if ctx.GetClusterConfig().Spec.Network.Plugin != string(common.CNITypeCalico) {
 		return nil
 	}
+	provider, opts, err := networkcommon.NewCNIProvider(ctx)
+	if err != nil {
+		return nil
+	}
 
-	s := &GenerateCalicoValuesStep{}
+	s := &GenerateCalicoValuesStep{
+		Provider: provider,
+		Options:  opts,
+	}
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Generate Calico Helm values file from configuration", s.Base.Meta.Name)
 	s.Base.Sudo = false
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 2 * time.Minute
 
-	clusterCfg := ctx.GetClusterConfig()
-	imageProvider := images.NewImageProvider(ctx)
-
-	s.OperatorNodeSelector = map[string]string{"kubernetes.io/os": "linux"}
-	s.OperatorTolerations = []map[string]string{
-		{"key": "node-role.kubernetes.io/control-plane", "operator": "Exists", "effect": "NoSchedule"},
-	}
-	s.VethMTU = 1440
-	s.LogSeverityScreen = "Info"
-	s.TyphaNodeSelector = map[string]string{"node-role.kubernetes.io/control-plane": ""}
-
-	if clusterCfg.Spec.Registry != nil && clusterCfg.Spec.Registry.MirroringAndRewriting != nil {
-		s.Registry = clusterCfg.Spec.Registry.MirroringAndRewriting.PrivateRegistry
-	}
-
-	operatorImg := imageProvider.GetImage("tigera-operator")
-	if operatorImg == nil {
-		return nil
-	}
-	s.OperatorImage = operatorImg.FullName()
-	defaultNat := true
-	defaultBlockSize := 26
-	defaultPool := v1alpha1.CalicoIPPool{
-		CIDR:          clusterCfg.Spec.Network.KubePodsCIDR,
-		Encapsulation: "VXLAN",
-		NatOutgoing:   &defaultNat,
-		BlockSize:     &defaultBlockSize,
-	}
-	userCalicoCfg := clusterCfg.Spec.Network.Calico
-	if userCalicoCfg != nil {
-		if userCalicoCfg.Networking != nil {
-			if userCalicoCfg.Networking.VethMTU != nil {
-				s.VethMTU = *userCalicoCfg.Networking.VethMTU
-			}
-			if userCalicoCfg.Networking.IPIPMode != "" {
-				defaultPool.Encapsulation = "IPIP"
-			}
-			if userCalicoCfg.Networking.VXLANMode != "" {
-				defaultPool.Encapsulation = "VXLAN"
-			}
-		}
-		if userCalicoCfg.IPAM != nil && len(userCalicoCfg.IPAM.Pools) > 0 {
-			s.IPPools = userCalicoCfg.IPAM.Pools
-			for i := range s.IPPools {
-				if s.IPPools[i].Encapsulation == "" {
-					s.IPPools[i].Encapsulation = defaultPool.Encapsulation
-				}
-				if s.IPPools[i].NatOutgoing == nil {
-					s.IPPools[i].NatOutgoing = &defaultNat
-				}
-				if s.IPPools[i].BlockSize == nil {
-					s.IPPools[i].BlockSize = &defaultBlockSize
-				}
-			}
-		} else {
-			s.IPPools = []v1alpha1.CalicoIPPool{defaultPool}
-		}
-		if userCalicoCfg.FelixConfiguration != nil && userCalicoCfg.FelixConfiguration.LogSeverityScreen != "" {
-			s.LogSeverityScreen = userCalicoCfg.FelixConfiguration.LogSeverityScreen
-		}
-		if userCalicoCfg.TyphaDeployment != nil {
-			if userCalicoCfg.TyphaDeployment.Replicas != nil {
-				s.TyphaReplicas = *userCalicoCfg.TyphaDeployment.Replicas
-			}
-			if userCalicoCfg.TyphaDeployment.NodeSelector != nil {
-				s.TyphaNodeSelector = userCalicoCfg.TyphaDeployment.NodeSelector
-			}
-			if userCalicoCfg.TyphaDeployment.Enabled != nil {
-				s.TyphaEnabled = *userCalicoCfg.TyphaDeployment.Enabled
-			}
-		}
-	} else {
-		s.IPPools = []v1alpha1.CalicoIPPool{defaultPool}
-	}
-	if userCalicoCfg == nil || userCalicoCfg.TyphaDeployment == nil || userCalicoCfg.TyphaDeployment.Enabled == nil {
-		if len(ctx.GetHostsByRole(common.RoleKubernetes)) > 50 {
-			s.TyphaEnabled = true
-		}
-	}
-
 	b := new(GenerateCalicoValuesStepBuilder).Init(s)
 	return b
 }
@@ -173,20 +89,13 @@ func (s *GenerateCalicoValuesStep) Run(ctx runtime.ExecutionContext) (*types.Ste
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
 
-	valuesTemplateContent, err := templates.Get("cni/calico/values.yaml.tmpl")
-	if err != nil {
-		result.MarkFailed(err, "failed to get embedded calico values.yaml.tmpl")
+	if err := s.Provider.Validate(); err != nil {
+		result.MarkFailed(err, "invalid calico network configuration")
 		return result, err
 	}
-
-	tmpl, err := template.New("calicoValues").Parse(valuesTemplateContent)
+	values, err := s.Provider.HelmValues(s.Options)
 	if err != nil {
-		result.MarkFailed(err, "failed to parse calico values.yaml.tmpl")
-		return result, err
-	}
-	var valuesBuffer bytes.Buffer
-	if err := tmpl.Execute(&valuesBuffer, s); err != nil {
-		result.MarkFailed(err, "failed to render calico values.yaml.tmpl")
+		result.MarkFailed(err, "failed to render calico values")
 		return result, err
 	}
 
@@ -203,7 +112,7 @@ func (s *GenerateCalicoValuesStep) Run(ctx runtime.ExecutionContext) (*types.Ste
 		return result, err
 	}
 
-	if err := os.WriteFile(localPath, valuesBuffer.Bytes(), 0644); err != nil {
+	if err := os.WriteFile(localPath, values, 0644); err != nil {
 		result.MarkFailed(err, fmt.Sprintf("failed to write generated values file to %s", localPath))
 		return result, err
 	}
@@ -1,41 +1,25 @@
 package cilium
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
-	"text/template"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
-	"github.com/mensylisir/kubexm/internal/util/helm"
-	"github.com/mensylisir/kubexm/internal/util/images"
-	"github.com/mensylisir/kubexm/internal/templates"
+	networkcommon "github.com/mensylisir/kubexm/internal/step/network/common"
 	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/cni"
+	"github.com/mensylisir/kubexm/internal/util/helm"
 )
 
 type GenerateCiliumValuesStep struct {
 	step.Base
-	ImageRepository         string
-	ImageTag                string
-	OperatorImageRepository string
-	OperatorImageTag        string
-	Tunnel                  string
-	IpamMode                string
-	KubeProxyReplacement    string
-	BpfMasquerade           bool
-	HubbleEnabled           bool
-	HubbleUiEnabled         bool
-	HubbleRelayEnabled      bool
-	IdentityAllocationMode  string
-	EncryptionEnabled       bool
-	BandwidthManagerEnabled bool
-	AutoDirectNodeRoutes    bool
-	OperatorReplicas        int
+	Provider cni.Provider
+	Options  cni.Options
 }
 
 type GenerateCiliumValuesStepBuilder struct {
@@ -46,76 +30,21 @@ func NewGenerateCiliumValuesStepBuilder(ctx runtime.ExecutionContext, instanceNa
 	if ctx.GetClusterConfig().Spec.Network.Plugin != string(common.CNITypeCilium) {
 		return nil
 	}
+	provider, opts, err := networkcommon.NewCNIProvider(ctx)
+	if err != nil {
+		return nil
+	}
 
-	s := &GenerateCiliumValuesStep{}
+	s := &GenerateCiliumValuesStep{
+		Provider: provider,
+		Options:  opts,
+	}
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Generate Cilium Helm values file from configuration", s.Base.Meta.Name)
 	s.Base.Sudo = false
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 2 * time.Minute
 
-	clusterCfg := ctx.GetClusterConfig()
-	imageProvider := images.NewImageProvider(ctx)
-	ciliumImage := imageProvider.GetImage("cilium")
-	operatorImage := imageProvider.GetImage("cilium-operator-generic")
-
-	if ciliumImage == nil || operatorImage == nil {
-		return nil
-	}
-	s.ImageRepository = ciliumImage.FullNameWithoutTag()
-	s.ImageTag = ciliumImage.Tag()
-	s.OperatorImageRepository = operatorImage.FullNameWithoutTag()
-	s.OperatorImageTag = operatorImage.Tag()
-
-	s.Tunnel = "vxlan"
-	s.IpamMode = "kubernetes"
-	s.KubeProxyReplacement = "probe"
-	s.BpfMasquerade = true
-	s.HubbleEnabled = true
-	s.HubbleUiEnabled = true
-	s.IdentityAllocationMode = "crd"
-	s.EncryptionEnabled = false
-	s.BandwidthManagerEnabled = false
-	s.OperatorReplicas = 1
-	s.HubbleRelayEnabled = true
-	s.AutoDirectNodeRoutes = false
-	userCiliumCfg := clusterCfg.Spec.Network.Cilium
-	if userCiliumCfg != nil {
-		if userCiliumCfg.Network != nil {
-			if userCiliumCfg.Network.TunnelingMode != "" {
-				s.Tunnel = userCiliumCfg.Network.TunnelingMode
-			}
-			if userCiliumCfg.Network.IPAMMode != "" {
-				s.IpamMode = userCiliumCfg.Network.IPAMMode
-			}
-		}
-		if userCiliumCfg.KubeProxy != nil {
-			if userCiliumCfg.KubeProxy.ReplacementMode != "" {
-				s.KubeProxyReplacement = userCiliumCfg.KubeProxy.ReplacementMode
-			}
-			if userCiliumCfg.KubeProxy.EnableBPFMasquerade != nil {
-				s.BpfMasquerade = *userCiliumCfg.KubeProxy.EnableBPFMasquerade
-			}
-		}
-		if userCiliumCfg.Hubble != nil {
-			s.HubbleEnabled = userCiliumCfg.Hubble.Enable
-			s.HubbleUiEnabled = userCiliumCfg.Hubble.EnableUI
-		}
-		if userCiliumCfg.Security != nil {
-			if userCiliumCfg.Security.IdentityAllocationMode != "" {
-				s.IdentityAllocationMode = userCiliumCfg.Security.IdentityAllocationMode
-			}
-			if userCiliumCfg.Security.EnableEncryption != nil {
-				s.EncryptionEnabled = *userCiliumCfg.Security.EnableEncryption
-			}
-		}
-		if userCiliumCfg.Performance != nil {
-			if userCiliumCfg.Performance.EnableBandwidthManager != nil {
-				s.BandwidthManagerEnabled = *userCiliumCfg.Performance.EnableBandwidthManager
-			}
-		}
-	}
-
 	b := new(GenerateCiliumValuesStepBuilder).Init(s)
 	return b
 }
@@ -164,20 +93,13 @@ func (s *GenerateCiliumValuesStep) Run(ctx runtime.ExecutionContext) (*types.Ste
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
 
-	valuesTemplateContent, err := templates.Get("cni/cilium/values.yaml.tmpl")
-	if err != nil {
-		result.MarkFailed(err, "failed to get embedded cilium values.yaml.tmpl")
+	if err := s.Provider.Validate(); err != nil {
+		result.MarkFailed(err, "invalid cilium network configuration")
 		return result, err
 	}
-
-	tmpl, err := template.New("ciliumValues").Parse(valuesTemplateContent)
+	values, err := s.Provider.HelmValues(s.Options)
 	if err != nil {
-		result.MarkFailed(err, "failed to parse cilium values.yaml.tmpl")
-		return result, err
-	}
-	var valuesBuffer bytes.Buffer
-	if err := tmpl.Execute(&valuesBuffer, s); err != nil {
-		result.MarkFailed(err, "failed to render cilium values.yaml.tmpl")
+		result.MarkFailed(err, "failed to render cilium values")
 		return result, err
 	}
 
@@ -194,7 +116,7 @@ func (s *GenerateCiliumValuesStep) Run(ctx runtime.ExecutionContext) (*types.Ste
 		return result, err
 	}
 
-	if err := os.WriteFile(localPath, valuesBuffer.Bytes(), 0644); err != nil {
+	if err := os.WriteFile(localPath, values, 0644); err != nil {
 		result.MarkFailed(err, fmt.Sprintf("failed to write generated values file to %s", localPath))
 		return result, err
 	}
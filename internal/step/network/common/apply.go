@@ -15,6 +15,8 @@ type ApplyManifestStep struct {
 	step.Base
 	ManifestContent string
 	Namespace       string
+	// Kubeconfig is passed to kubectl when set; kubectl then runs with sudo as kubeconfigs are root-owned.
+	Kubeconfig string
 }
 
 type ApplyManifestStepBuilder struct {
@@ -34,6 +36,11 @@ func NewApplyManifestStepBuilder(ctx runtime.ExecutionContext, instanceName, man
 	return new(ApplyManifestStepBuilder).Init(s)
 }
 
+func (b *ApplyManifestStepBuilder) WithKubeconfig(kubeconfig string) *ApplyManifestStepBuilder {
+	b.Step.Kubeconfig = kubeconfig
+	return b
+}
+
 func (s *ApplyManifestStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
@@ -64,9 +71,12 @@ func (s *ApplyManifestStep) Run(ctx runtime.ExecutionContext) (*types.StepResult
 	if s.Namespace != "" {
 		applyCmd = fmt.Sprintf("kubectl apply -f %s -n %s", tempFile, s.Namespace)
 	}
+	if s.Kubeconfig != "" {
+		applyCmd += " --kubeconfig " + s.Kubeconfig
+	}
 
 	logger.Infof("Applying manifest: %s", applyCmd)
-	if _, err := runner.Run(ctx.GoContext(), conn, applyCmd, false); err != nil {
+	if _, err := runner.Run(ctx.GoContext(), conn, applyCmd, s.Kubeconfig != ""); err != nil {
 		result.MarkFailed(err, "failed to apply manifest")
 		return result, err
 	}
@@ -89,9 +99,12 @@ func (s *ApplyManifestStep) Rollback(ctx runtime.ExecutionContext) error {
 	if s.Namespace != "" {
 		deleteCmd = fmt.Sprintf("kubectl delete -f %s -n %s --ignore-not-found", tempFile, s.Namespace)
 	}
+	if s.Kubeconfig != "" {
+		deleteCmd += " --kubeconfig " + s.Kubeconfig
+	}
 
 	logger.Warnf("Rolling back by deleting manifest: %s", deleteCmd)
-	runner.Run(ctx.GoContext(), conn, deleteCmd, false)
+	runner.Run(ctx.GoContext(), conn, deleteCmd, s.Kubeconfig != "")
 	return nil
 }
 
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/util/cni"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+// NewCNIProvider returns the provider of the configured network plugin together with the options it
+// renders with, its images resolved through the image BOM.
+func NewCNIProvider(ctx runtime.ExecutionContext) (cni.Provider, cni.Options, error) {
+	clusterCfg := ctx.GetClusterConfig()
+	provider, err := cni.NewProvider(clusterCfg.Spec.Network)
+	if err != nil {
+		return nil, cni.Options{}, err
+	}
+
+	opts := cni.Options{
+		Images:    make(map[string]string),
+		NodeCount: len(ctx.GetHostsByRole(common.RoleKubernetes)),
+	}
+	if clusterCfg.Spec.Registry != nil && clusterCfg.Spec.Registry.MirroringAndRewriting != nil {
+		opts.Registry = clusterCfg.Spec.Registry.MirroringAndRewriting.PrivateRegistry
+	}
+	if !v1alpha1.KubeProxyEnabled(clusterCfg.Spec) {
+		opts.APIServerHost, opts.APIServerPort = apiServerEndpoint(ctx)
+	}
+	imageProvider := images.NewImageProvider(ctx)
+	for _, name := range provider.RequiredImages() {
+		if img := imageProvider.GetImage(name); img != nil {
+			opts.Images[name] = img.FullName()
+		}
+	}
+	return provider, opts, nil
+}
+
+// apiServerEndpoint returns the address nodes reach the apiserver on without going through the
+// kubernetes Service: the control plane endpoint, or the first master when none is configured.
+func apiServerEndpoint(ctx runtime.ExecutionContext) (string, int) {
+	port := common.DefaultAPIServerPort
+	if cp := ctx.GetClusterConfig().Spec.ControlPlaneEndpoint; cp != nil {
+		if cp.Port != 0 {
+			port = cp.Port
+		}
+		if cp.Domain != "" {
+			return cp.Domain, port
+		}
+		if cp.Address != "" {
+			return cp.Address, port
+		}
+	}
+	if masters := ctx.GetHostsByRole(common.RoleMaster); len(masters) > 0 {
+		return masters[0].GetInternalAddress(), port
+	}
+	return "", port
+}
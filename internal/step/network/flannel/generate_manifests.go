@@ -1,34 +1,25 @@
 package flannel
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
-	"text/template"
 	"time"
 
-	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
-	"github.com/mensylisir/kubexm/internal/util/helm"
-	"github.com/mensylisir/kubexm/internal/util/images"
-	"github.com/mensylisir/kubexm/internal/templates"
+	networkcommon "github.com/mensylisir/kubexm/internal/step/network/common"
 	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/cni"
+	"github.com/mensylisir/kubexm/internal/util/helm"
 )
 
 type GenerateFlannelValuesStep struct {
 	step.Base
-	ImageFlannelRepo   string
-	ImageFlannelTag    string
-	ImageCNIPluginRepo string
-	ImageCNIPluginTag  string
-	PodCIDR            string
-	BackendType        string
-	BackendVXLAN       *v1alpha1.FlannelVXLANConfig
-	BackendIPsec       *v1alpha1.FlannelIPsecConfig
+	Provider cni.Provider
+	Options  cni.Options
 }
 
 type GenerateFlannelValuesStepBuilder struct {
@@ -39,43 +30,21 @@ func NewGenerateFlannelValuesStepBuilder(ctx runtime.ExecutionContext, instanceN
 	if ctx.GetClusterConfig().Spec.Network.Plugin != string(common.CNITypeFlannel) {
 		return nil
 	}
-
-	imageProvider := images.NewImageProvider(ctx)
-	flannelImage := imageProvider.GetImage("flannel")
-	cniPluginImage := imageProvider.GetImage("flannel-cni-plugin")
-
-	if flannelImage == nil || cniPluginImage == nil {
+	provider, opts, err := networkcommon.NewCNIProvider(ctx)
+	if err != nil {
 		return nil
 	}
 
-	s := &GenerateFlannelValuesStep{}
+	s := &GenerateFlannelValuesStep{
+		Provider: provider,
+		Options:  opts,
+	}
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Generate Flannel Helm values file from configuration", s.Base.Meta.Name)
 	s.Base.Sudo = false
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 2 * time.Minute
 
-	s.ImageFlannelRepo = flannelImage.FullNameWithoutTag()
-	s.ImageFlannelTag = flannelImage.Tag()
-	s.ImageCNIPluginRepo = cniPluginImage.FullNameWithoutTag()
-	s.ImageCNIPluginTag = cniPluginImage.Tag()
-
-	clusterCfg := ctx.GetClusterConfig()
-	s.PodCIDR = clusterCfg.Spec.Network.KubePodsCIDR
-	userFlannelCfg := clusterCfg.Spec.Network.Flannel
-	if userFlannelCfg != nil && userFlannelCfg.Backend != nil {
-		backendCfg := userFlannelCfg.Backend
-		s.BackendType = backendCfg.Type
-		s.BackendVXLAN = backendCfg.VXLAN
-		s.BackendIPsec = backendCfg.IPsec
-	}
-	if s.BackendType == "" {
-		s.BackendType = "vxlan"
-	}
-	if s.PodCIDR == "" {
-		s.PodCIDR = common.DefaultKubePodsCIDR
-	}
-
 	b := new(GenerateFlannelValuesStepBuilder).Init(s)
 	return b
 }
@@ -124,20 +93,13 @@ func (s *GenerateFlannelValuesStep) Run(ctx runtime.ExecutionContext) (*types.St
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Run")
 
-	valuesTemplateContent, err := templates.Get("cni/flannel/values.yaml.tmpl")
-	if err != nil {
-		result.MarkFailed(err, "failed to get embedded flannel values.yaml.tmpl")
+	if err := s.Provider.Validate(); err != nil {
+		result.MarkFailed(err, "invalid flannel network configuration")
 		return result, err
 	}
-
-	tmpl, err := template.New("flannelValues").Parse(valuesTemplateContent)
+	values, err := s.Provider.HelmValues(s.Options)
 	if err != nil {
-		result.MarkFailed(err, "failed to parse flannel values.yaml.tmpl")
-		return result, err
-	}
-	var valuesBuffer bytes.Buffer
-	if err := tmpl.Execute(&valuesBuffer, s); err != nil {
-		result.MarkFailed(err, "failed to render flannel values.yaml.tmpl")
+		result.MarkFailed(err, "failed to render flannel values")
 		return result, err
 	}
 
@@ -154,7 +116,7 @@ func (s *GenerateFlannelValuesStep) Run(ctx runtime.ExecutionContext) (*types.St
 		return result, err
 	}
 
-	if err := os.WriteFile(localPath, valuesBuffer.Bytes(), 0644); err != nil {
+	if err := os.WriteFile(localPath, values, 0644); err != nil {
 		result.MarkFailed(err, fmt.Sprintf("failed to write generated values file to %s", localPath))
 		return result, err
 	}
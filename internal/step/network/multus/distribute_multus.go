@@ -28,16 +28,14 @@ type DistributeMultusArtifactsStepBuilder struct {
 
 func NewDistributeMultusArtifactsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DistributeMultusArtifactsStepBuilder {
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == helpers.BoolPtr(false) {
+	if !IsEnabled(cfg) {
 		return nil
 	}
 
 	helmProvider := helm.NewHelmProvider(ctx)
 	chart := helmProvider.GetChart(string(common.CNITypeMultus))
 	if chart == nil {
-		if cfg.Spec.Network.Multus.Installation.Enabled == helpers.BoolPtr(true) {
-			ctx.GetLogger().Errorf("Error: Multus is enabled but chart info is not found for K8s version %s\n %v", cfg.Spec.Kubernetes.Version, os.Stderr)
-		}
+		ctx.GetLogger().Errorf("Error: Multus is enabled but chart info is not found for K8s version %s\n %v", cfg.Spec.Kubernetes.Version, os.Stderr)
 		return nil
 	}
 
@@ -83,7 +81,7 @@ func (s *DistributeMultusArtifactsStep) Precheck(ctx runtime.ExecutionContext) (
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
 
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == helpers.BoolPtr(false) {
+	if !IsEnabled(cfg) {
 		logger.Info("Multus is not enabled, skipping.")
 		return true, nil
 	}
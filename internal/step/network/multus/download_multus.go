@@ -28,7 +28,7 @@ type DownloadMultusChartStepBuilder struct {
 
 func NewDownloadMultusChartStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DownloadMultusChartStepBuilder {
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == nil || !*cfg.Spec.Network.Multus.Installation.Enabled {
+	if !IsEnabled(cfg) {
 		return nil
 	}
 
@@ -49,7 +49,7 @@ func (s *DownloadMultusChartStep) Meta() *spec.StepMeta {
 
 func (s *DownloadMultusChartStep) getChartAndPath(ctx runtime.ExecutionContext) (*helm.HelmChart, string, error) {
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == nil || !*cfg.Spec.Network.Multus.Installation.Enabled {
+	if !IsEnabled(cfg) {
 		return nil, "", fmt.Errorf("Multus is not enabled")
 	}
 
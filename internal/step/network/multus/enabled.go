@@ -0,0 +1,18 @@
+package multus
+
+import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+// IsEnabled reports whether Multus is installed on top of the primary CNI plugin, through either
+// network.multus.enabled or network.multus.installation.enabled.
+func IsEnabled(cluster *v1alpha1.Cluster) bool {
+	if cluster == nil || cluster.Spec == nil || cluster.Spec.Network == nil || cluster.Spec.Network.Multus == nil {
+		return false
+	}
+	multus := cluster.Spec.Network.Multus
+	if multus.Installation != nil && multus.Installation.Enabled != nil {
+		return *multus.Installation.Enabled
+	}
+	return multus.Enabled != nil && *multus.Enabled
+}
@@ -31,7 +31,7 @@ type GenerateMultusValuesStepBuilder struct {
 
 func NewGenerateMultusValuesStepBuilder(ctx runtime.ExecutionContext, instanceName string) *GenerateMultusValuesStepBuilder {
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == nil || !*cfg.Spec.Network.Multus.Installation.Enabled {
+	if !IsEnabled(cfg) {
 		return nil
 	}
 
@@ -91,7 +91,7 @@ func (s *GenerateMultusValuesStep) Precheck(ctx runtime.ExecutionContext) (isDon
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "phase", "Precheck")
 
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == nil || !*cfg.Spec.Network.Multus.Installation.Enabled {
+	if !IsEnabled(cfg) {
 		logger.Info("Multus is not enabled, skipping.")
 		return true, nil
 	}
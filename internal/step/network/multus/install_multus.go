@@ -31,7 +31,7 @@ type InstallMultusHelmChartStepBuilder struct {
 
 func NewInstallMultusHelmChartStepBuilder(ctx runtime.ExecutionContext, instanceName string) *InstallMultusHelmChartStepBuilder {
 	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus == nil || cfg.Spec.Network.Multus.Installation.Enabled == nil || !*cfg.Spec.Network.Multus.Installation.Enabled {
+	if !IsEnabled(cfg) {
 		return nil
 	}
 
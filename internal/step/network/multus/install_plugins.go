@@ -0,0 +1,125 @@
+package multus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// InstallMultusPluginsStep copies the CNI plugin binaries listed in network.multus.additionalPlugins
+// to the CNI bin directory, so secondary networks can use plugins the standard bundle lacks.
+type InstallMultusPluginsStep struct {
+	step.Base
+	Plugins         []string
+	RemoteCNIBinDir string
+	Permission      string
+}
+
+type InstallMultusPluginsStepBuilder struct {
+	step.Builder[InstallMultusPluginsStepBuilder, *InstallMultusPluginsStep]
+}
+
+func NewInstallMultusPluginsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *InstallMultusPluginsStepBuilder {
+	cfg := ctx.GetClusterConfig()
+	if !IsEnabled(cfg) || len(cfg.Spec.Network.Multus.AdditionalPlugins) == 0 {
+		return nil
+	}
+
+	s := &InstallMultusPluginsStep{
+		Plugins:         cfg.Spec.Network.Multus.AdditionalPlugins,
+		RemoteCNIBinDir: common.DefaultCNIBinDirTarget,
+		Permission:      "0755",
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Install additional CNI plugins for Multus", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	b := new(InstallMultusPluginsStepBuilder).Init(s)
+	return b
+}
+
+func (s *InstallMultusPluginsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *InstallMultusPluginsStep) remotePath(plugin string) string {
+	return filepath.Join(s.RemoteCNIBinDir, filepath.Base(plugin))
+}
+
+func (s *InstallMultusPluginsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+
+	for _, plugin := range s.Plugins {
+		if _, err := os.Stat(plugin); err != nil {
+			return false, fmt.Errorf("additional CNI plugin %s not found on the control node: %w", plugin, err)
+		}
+		done, err := helpers.CheckRemoteFileIntegrity(ctx, plugin, s.remotePath(plugin), s.Sudo)
+		if err != nil {
+			return false, err
+		}
+		if !done {
+			logger.Infof("CNI plugin %s is missing or outdated.", s.remotePath(plugin))
+			return false, nil
+		}
+	}
+
+	logger.Info("All additional CNI plugins are installed and up-to-date.")
+	return true, nil
+}
+
+func (s *InstallMultusPluginsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	if err := runner.Mkdirp(ctx.GoContext(), conn, s.RemoteCNIBinDir, "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, fmt.Sprintf("failed to create remote CNI bin directory '%s'", s.RemoteCNIBinDir))
+		return result, err
+	}
+
+	for _, plugin := range s.Plugins {
+		logger.Infof("Installing CNI plugin %s to %s", plugin, s.remotePath(plugin))
+		if err := helpers.UploadFile(ctx, conn, plugin, s.remotePath(plugin), s.Permission, s.Sudo); err != nil {
+			result.MarkFailed(err, fmt.Sprintf("failed to install CNI plugin %s", filepath.Base(plugin)))
+			return result, err
+		}
+	}
+
+	logger.Info("Additional CNI plugins installed successfully.")
+	result.MarkCompleted("Additional CNI plugins installed")
+	return result, nil
+}
+
+func (s *InstallMultusPluginsStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		logger.Errorf("Failed to get connector for rollback: %v", err)
+		return nil
+	}
+
+	for _, plugin := range s.Plugins {
+		if err := runner.Remove(ctx.GoContext(), conn, s.remotePath(plugin), s.Sudo, false); err != nil {
+			logger.Warnf("Failed to remove CNI plugin %s during rollback: %v", s.remotePath(plugin), err)
+		}
+	}
+	return nil
+}
+
+var _ step.Step = (*InstallMultusPluginsStep)(nil)
@@ -56,12 +56,13 @@ func (s *ExtractBundleStep) Precheck(ctx runtime.ExecutionContext) (isDone bool,
 		return false, fmt.Errorf("offline bundle path is not specified")
 	}
 
-	if _, err := os.Stat(s.BundlePath); os.IsNotExist(err) {
-		return false, fmt.Errorf("offline bundle not found at specified path: %s", s.BundlePath)
+	fingerprint, err := bundleFingerprint(s.BundlePath)
+	if err != nil {
+		return false, err
 	}
 
-	if _, err := os.Stat(s.markerFilePath); err == nil {
-		logger.Info("Offline bundle appears to be already extracted. Skipping extraction.", "markerFile", s.markerFilePath)
+	if marker, err := os.ReadFile(s.markerFilePath); err == nil && string(marker) == fingerprint {
+		logger.Info("Offline bundle is already extracted. Skipping extraction.", "markerFile", s.markerFilePath)
 		return true, nil
 	}
 
@@ -85,7 +86,12 @@ func (s *ExtractBundleStep) Run(ctx runtime.ExecutionContext) (*types.StepResult
 		return result, err
 	}
 
-	if err := os.WriteFile(s.markerFilePath, []byte(time.Now().String()), 0644); err != nil {
+	fingerprint, err := bundleFingerprint(s.BundlePath)
+	if err != nil {
+		result.MarkFailed(err, "failed to read offline bundle")
+		return result, err
+	}
+	if err := os.WriteFile(s.markerFilePath, []byte(fingerprint), 0644); err != nil {
 		logger.Warn("Failed to write extraction marker file.", "error", err)
 	}
 
@@ -100,4 +106,21 @@ func (s *ExtractBundleStep) Rollback(ctx runtime.ExecutionContext) error {
 	return nil
 }
 
+// bundleFingerprint identifies a bundle by path, size and modification time, so the marker file
+// left by an extraction does not hide a different bundle given later.
+func bundleFingerprint(bundlePath string) (string, error) {
+	absPath, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve offline bundle path %s: %w", bundlePath, err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("offline bundle not found at specified path: %s", bundlePath)
+		}
+		return "", fmt.Errorf("failed to stat offline bundle %s: %w", bundlePath, err)
+	}
+	return fmt.Sprintf("%s %d %d", absPath, info.Size(), info.ModTime().Unix()), nil
+}
+
 var _ step.Step = (*ExtractBundleStep)(nil)
@@ -0,0 +1,97 @@
+package offline
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+func writeTestBundle(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create bundle: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "asset.txt", Mode: 0644, Size: int64(len(content)), ModTime: modTime}); err != nil {
+		t.Fatalf("failed to write tar header: %v", err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatalf("failed to write tar content: %v", err)
+	}
+	for _, c := range []interface{ Close() error }{tw, gz, f} {
+		if err := c.Close(); err != nil {
+			t.Fatalf("failed to close bundle: %v", err)
+		}
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set bundle mtime: %v", err)
+	}
+}
+
+func TestExtractBundleStep_MarkerMatchesOnlyTheExtractedBundle(t *testing.T) {
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	bundlePath := filepath.Join(dir, "kubexm-bundle.tar.gz")
+	extracted := filepath.Join(workDir, "asset.txt")
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeTestBundle(t, bundlePath, "first", modTime)
+
+	ctx := &runtimetest.Context{
+		Host:          connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "control", Address: "127.0.0.1"}),
+		GlobalWorkDir: workDir,
+	}
+	s, err := NewExtractBundleStepBuilder(ctx, "ExtractBundle").WithBundlePath(bundlePath).Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	assertPrecheck := func(t *testing.T, want bool) {
+		t.Helper()
+		done, err := s.Precheck(ctx)
+		if err != nil {
+			t.Fatalf("precheck failed: %v", err)
+		}
+		if done != want {
+			t.Fatalf("expected precheck done=%v, got %v", want, done)
+		}
+	}
+	assertExtracted := func(t *testing.T, want string) {
+		t.Helper()
+		got, err := os.ReadFile(extracted)
+		if err != nil {
+			t.Fatalf("failed to read extracted file: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("expected extracted content %q, got %q", want, got)
+		}
+	}
+
+	assertPrecheck(t, false)
+	if _, err := s.Run(ctx); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	assertExtracted(t, "first")
+
+	t.Run("same bundle is not extracted again", func(t *testing.T) {
+		assertPrecheck(t, true)
+	})
+
+	t.Run("changed bundle is extracted again", func(t *testing.T) {
+		writeTestBundle(t, bundlePath, "second bundle", modTime.Add(time.Minute))
+		assertPrecheck(t, false)
+		if _, err := s.Run(ctx); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		assertExtracted(t, "second bundle")
+		assertPrecheck(t, true)
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/step/helpers"
+	lbcommon "github.com/mensylisir/kubexm/internal/step/loadbalancer/common"
 
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/types"
@@ -101,6 +102,13 @@ func (s *UpdateEtcHostsStep) generateHostsEntries(ctx runtime.ExecutionContext)
 		if address == "" {
 			address = "127.0.0.1"
 		}
+		// With a local apiserver proxy, workers join through the first master: the proxy static pod
+		// only starts with kubelet, which is switched to 127.0.0.1 once the node has joined.
+		if address == "127.0.0.1" && lbcommon.LocalProxyEnabled(cluster) && !ctx.GetHost().IsRole(common.RoleMaster) {
+			if masters := ctx.GetHostsByRole(common.RoleMaster); len(masters) > 0 {
+				address = masters[0].GetInternalAddress()
+			}
+		}
 		entryMap[address] = append(entryMap[address], domain)
 	}
 
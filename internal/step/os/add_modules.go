@@ -8,11 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/types"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/util/cni"
 	"github.com/pkg/errors"
 )
 
@@ -33,7 +35,7 @@ func NewLoadKernelModulesStepBuilder(ctx runtime.ExecutionContext, instanceName
 
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Load kernel modules", s.Base.Meta.Name)
-	s.Base.Sudo = false
+	s.Base.Sudo = true
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 3 * time.Minute
 
@@ -51,14 +53,19 @@ func (s *LoadKernelModulesStep) getRequiredModules(ctx runtime.ExecutionContext)
 	required := map[string]bool{
 		"br_netfilter": true,
 		"overlay":      true,
-		"ip_vs":        true,
-		"ip_vs_rr":     true,
-		"ip_vs_wrr":    true,
-		"ip_vs_sh":     true,
-		"nf_conntrack": true,
 		"iscsi_tcp":    true,
 	}
 
+	for _, module := range v1alpha1.KubeProxyKernelModules(cluster.Spec) {
+		required[module] = true
+	}
+
+	if provider, err := cni.NewProvider(cluster.Spec.Network); err == nil {
+		for _, module := range provider.RequiredKernelModules() {
+			required[module] = true
+		}
+	}
+
 	if cluster.Spec.System != nil && len(cluster.Spec.System.Modules) > 0 {
 		for _, module := range cluster.Spec.System.Modules {
 			required[module] = true
@@ -86,27 +93,26 @@ func (s *LoadKernelModulesStep) Precheck(ctx runtime.ExecutionContext) (isDone b
 		return false, err
 	}
 
-	requiredModules := s.getRequiredModules(ctx)
-
-	for _, module := range requiredModules {
-		checkCmd := fmt.Sprintf("lsmod | grep -w ^%s", module)
-		isLoaded, _ := runner.Check(ctx.GoContext(), conn, checkCmd, s.Sudo)
+	// Modules that the kernel does not ship are skipped by Run, so only the available ones are
+	// expected to be loaded.
+	var availableModulesOnSystem []string
+	for _, module := range s.getRequiredModules(ctx) {
+		exists, _ := runner.Check(ctx.GoContext(), conn, fmt.Sprintf("modinfo %s", module), s.Sudo)
+		if !exists {
+			continue
+		}
+		availableModulesOnSystem = append(availableModulesOnSystem, module)
+		isLoaded, err := runner.IsModuleLoaded(ctx.GoContext(), conn, module)
+		if err != nil {
+			return false, err
+		}
 		if !isLoaded {
 			logger.Infof("Kernel module '%s' is not currently loaded. Will ensure configuration.", module)
 			return false, nil
 		}
 	}
+	logger.Info("All available kernel modules are loaded.")
 
-	logger.Info("All required kernel modules seem to be loaded.")
-
-	expectedModules := s.getRequiredModules(ctx)
-	var availableModulesOnSystem []string
-	for _, module := range expectedModules {
-		exists, _ := runner.Check(ctx.GoContext(), conn, fmt.Sprintf("modinfo %s", module), s.Sudo)
-		if exists {
-			availableModulesOnSystem = append(availableModulesOnSystem, module)
-		}
-	}
 	sort.Strings(availableModulesOnSystem)
 	expectedContent := strings.Join(availableModulesOnSystem, "\n") + "\n"
 
@@ -139,6 +145,11 @@ func (s *LoadKernelModulesStep) Run(ctx runtime.ExecutionContext) (*types.StepRe
 		result.MarkFailed(err, "step failed"); return result, err
 	}
 
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "failed to get host facts"); return result, err
+	}
+
 	filePath := s.getModulesConfFilePath()
 	requiredModules := s.getRequiredModules(ctx)
 	fileExists, err := runner.Exists(ctx.GoContext(), conn, filePath)
@@ -160,9 +171,8 @@ func (s *LoadKernelModulesStep) Run(ctx runtime.ExecutionContext) (*types.StepRe
 
 		if exists {
 			logger.Infof("Kernel module '%s' is available. Loading with 'modprobe'...", module)
-			loadCmd := fmt.Sprintf("modprobe %s", module)
-			if runResult, err := runner.Run(ctx.GoContext(), conn, loadCmd, s.Sudo); err != nil {
-				logger.Warnf("Could not modprobe '%s'. It might be built-in. Output: %s", module, runResult.Stdout)
+			if err := runner.LoadModule(ctx.GoContext(), conn, facts, module); err != nil {
+				logger.Warnf("Could not modprobe '%s'. It might be built-in. Error: %v", module, err)
 			}
 			availableModules = append(availableModules, module)
 		} else {
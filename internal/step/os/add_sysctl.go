@@ -12,6 +12,7 @@ import (
 	"github.com/mensylisir/kubexm/internal/types"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/util/cni"
 	"github.com/pkg/errors"
 )
 
@@ -31,7 +32,7 @@ func NewConfigureSysctlStepBuilder(ctx runtime.ExecutionContext, instanceName st
 
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Configure kernel parameters (sysctl)", s.Base.Meta.Name)
-	s.Base.Sudo = false
+	s.Base.Sudo = true
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 2 * time.Minute
 
@@ -79,6 +80,17 @@ func (s *ConfigureSysctlStep) generateExpectedContent(ctx runtime.ExecutionConte
 		params["net.ipv6.neigh.default.retrans_time_ms"] = "1000"
 	}
 
+	if provider, err := cni.NewProvider(cluster.Spec.Network); err == nil {
+		for key, value := range provider.RequiredSysctls() {
+			params[key] = value
+		}
+	}
+
+	// Backup load balancer nodes must be able to bind the VIP before keepalived moves it to them.
+	if ctx.GetHost().IsRole(common.RoleLoadBalancer) {
+		params["net.ipv4.ip_nonlocal_bind"] = "1"
+	}
+
 	if cluster.Spec.System != nil && cluster.Spec.System.SysctlParams != nil {
 		for key, value := range cluster.Spec.System.SysctlParams {
 			params[key] = value
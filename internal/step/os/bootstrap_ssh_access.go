@@ -0,0 +1,219 @@
+package os
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"golang.org/x/crypto/ssh"
+)
+
+const bootstrapKeyComment = "kubexm"
+
+// sshKeyMu serializes loadOrGenerateSSHKey, so that steps running on several hosts at once all
+// use the key the first of them generates.
+var sshKeyMu sync.Mutex
+
+// BootstrapSSHAccessStep installs an SSH public key into the target user's authorized_keys
+// using an initial password credential, verifies that key-based login works, and then
+// switches the host and its connector over to key authentication.
+// When PrivateKeyPath does not exist on the control node a new ed25519 key pair is generated there.
+type BootstrapSSHAccessStep struct {
+	step.Base
+	Password       string
+	PrivateKeyPath string
+
+	newConnector func() connector.Connector
+}
+
+type BootstrapSSHAccessStepBuilder struct {
+	step.Builder[BootstrapSSHAccessStepBuilder, *BootstrapSSHAccessStep]
+}
+
+func NewBootstrapSSHAccessStepBuilder(ctx runtime.ExecutionContext, instanceName, password, privateKeyPath string) *BootstrapSSHAccessStepBuilder {
+	cs := &BootstrapSSHAccessStep{
+		Password:       password,
+		PrivateKeyPath: privateKeyPath,
+		newConnector: func() connector.Connector {
+			return connector.NewSSHConnector(nil)
+		},
+	}
+	cs.Base.Meta.Name = instanceName
+	cs.Base.Meta.Description = fmt.Sprintf("[%s]>>Install SSH key [%s] for passwordless access", instanceName, privateKeyPath)
+	cs.Base.Sudo = false
+	cs.Base.IgnoreError = false
+	cs.Base.Timeout = 2 * time.Minute
+	return new(BootstrapSSHAccessStepBuilder).Init(cs)
+}
+
+func (s *BootstrapSSHAccessStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *BootstrapSSHAccessStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.PrivateKeyPath == "" {
+		return false, fmt.Errorf("precheck: private key path must be set")
+	}
+
+	cfg := s.baseConnectionCfg(ctx)
+	if cfg.Password == "" && cfg.PrivateKeyPath == s.PrivateKeyPath {
+		logger.Info("Host already uses key-based authentication.", "key", s.PrivateKeyPath)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *BootstrapSSHAccessStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+
+	privateKey, publicKey, err := loadOrGenerateSSHKey(s.PrivateKeyPath)
+	if err != nil {
+		result.MarkFailed(err, "failed to prepare SSH key")
+		return result, err
+	}
+
+	baseCfg := s.baseConnectionCfg(ctx)
+	passwordCfg := baseCfg
+	if s.Password != "" {
+		passwordCfg.Password = s.Password
+	}
+	if passwordCfg.Password == "" {
+		err := fmt.Errorf("no initial password credential for host %s", ctx.GetHost().GetName())
+		result.MarkFailed(err, "missing password")
+		return result, err
+	}
+	passwordCfg.PrivateKey = nil
+	passwordCfg.PrivateKeyPath = ""
+
+	passwordConn := s.newConnector()
+	if err := passwordConn.Connect(ctx.GoContext(), passwordCfg); err != nil {
+		err = fmt.Errorf("failed to connect with password: %w", err)
+		result.MarkFailed(err, "password authentication failed")
+		return result, err
+	}
+	defer passwordConn.Close()
+
+	logger.Info("Installing public key into authorized_keys.", "user", passwordCfg.User)
+	if _, err := runnerSvc.Run(ctx.GoContext(), passwordConn, authorizedKeysInstallCmd(publicKey), false); err != nil {
+		err = fmt.Errorf("failed to install public key: %w", err)
+		result.MarkFailed(err, "failed to install public key")
+		return result, err
+	}
+
+	keyCfg := baseCfg
+	keyCfg.Password = ""
+	keyCfg.PrivateKey = privateKey
+	keyCfg.PrivateKeyPath = s.PrivateKeyPath
+
+	logger.Info("Verifying key-based login.")
+	keyConn := s.newConnector()
+	if err := keyConn.Connect(ctx.GoContext(), keyCfg); err != nil {
+		err = fmt.Errorf("key-based login failed after installing the public key: %w", err)
+		result.MarkFailed(err, "key authentication verification failed")
+		return result, err
+	}
+	_, verifyErr := runnerSvc.Run(ctx.GoContext(), keyConn, "true", false)
+	keyConn.Close()
+	if verifyErr != nil {
+		err := fmt.Errorf("key-based login failed after installing the public key: %w", verifyErr)
+		result.MarkFailed(err, "key authentication verification failed")
+		return result, err
+	}
+
+	host := ctx.GetHost()
+	host.SetPassword("")
+	host.SetPrivateKey("")
+	host.SetPrivateKeyPath(s.PrivateKeyPath)
+
+	if conn, err := ctx.GetCurrentHostConnector(); err == nil {
+		_ = conn.Close()
+		if err := conn.Connect(ctx.GoContext(), keyCfg); err != nil {
+			err = fmt.Errorf("failed to reconnect with key authentication: %w", err)
+			result.MarkFailed(err, "failed to switch connector to key authentication")
+			return result, err
+		}
+	}
+
+	logger.Info("Host switched to key-based authentication.", "key", s.PrivateKeyPath)
+	result.MarkCompleted("step completed successfully")
+	return result, nil
+}
+
+func (s *BootstrapSSHAccessStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Warn("Rollback for BootstrapSSHAccessStep is a no-op. The installed public key is left in authorized_keys.")
+	return nil
+}
+
+// baseConnectionCfg returns the configuration of the host's current connector, falling back to
+// one derived from the host spec when no connector is available.
+func (s *BootstrapSSHAccessStep) baseConnectionCfg(ctx runtime.ExecutionContext) connector.ConnectionCfg {
+	if conn, err := ctx.GetCurrentHostConnector(); err == nil {
+		return conn.GetConnectionConfig()
+	}
+	cfg, _ := connector.NewFactory().NewConnectionCfg(ctx.GetHost(), 0)
+	return cfg
+}
+
+// authorizedKeysInstallCmd appends publicKey to ~/.ssh/authorized_keys unless it is already present.
+func authorizedKeysInstallCmd(publicKey string) string {
+	return fmt.Sprintf("mkdir -p ~/.ssh && chmod 700 ~/.ssh && touch ~/.ssh/authorized_keys && chmod 600 ~/.ssh/authorized_keys && "+
+		"(grep -qxF '%[1]s' ~/.ssh/authorized_keys || echo '%[1]s' >> ~/.ssh/authorized_keys)", publicKey)
+}
+
+// loadOrGenerateSSHKey reads the private key at path, generating a new ed25519 key pair (path and
+// path.pub) when the file does not exist. It returns the PEM private key and the authorized_keys line.
+func loadOrGenerateSSHKey(path string) ([]byte, string, error) {
+	sshKeyMu.Lock()
+	defer sshKeyMu.Unlock()
+	keyBytes, err := os.ReadFile(path)
+	generated := false
+	if os.IsNotExist(err) {
+		_, key, genErr := ed25519.GenerateKey(rand.Reader)
+		if genErr != nil {
+			return nil, "", fmt.Errorf("failed to generate SSH key: %w", genErr)
+		}
+		block, genErr := ssh.MarshalPrivateKey(key, bootstrapKeyComment)
+		if genErr != nil {
+			return nil, "", fmt.Errorf("failed to encode SSH key: %w", genErr)
+		}
+		keyBytes = pem.EncodeToMemory(block)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, "", fmt.Errorf("failed to create directory for SSH key %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, keyBytes, 0600); err != nil {
+			return nil, "", fmt.Errorf("failed to write SSH key %s: %w", path, err)
+		}
+		generated = true
+	} else if err != nil {
+		return nil, "", fmt.Errorf("failed to read SSH key %s: %w", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse SSH key %s: %w", path, err)
+	}
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))) + " " + bootstrapKeyComment
+	if generated {
+		if err := os.WriteFile(path+".pub", []byte(publicKey+"\n"), 0644); err != nil {
+			return nil, "", fmt.Errorf("failed to write SSH public key %s.pub: %w", path, err)
+		}
+	}
+	return keyBytes, publicKey, nil
+}
+
+var _ step.Step = (*BootstrapSSHAccessStep)(nil)
@@ -0,0 +1,135 @@
+package os
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+type fakeBootstrapConnector struct {
+	connector.Connector
+	name       string
+	cfg        connector.ConnectionCfg
+	connectErr error
+	connected  []connector.ConnectionCfg
+}
+
+func (c *fakeBootstrapConnector) Connect(ctx context.Context, cfg connector.ConnectionCfg) error {
+	c.connected = append(c.connected, cfg)
+	if c.connectErr != nil {
+		return c.connectErr
+	}
+	c.cfg = cfg
+	return nil
+}
+
+func (c *fakeBootstrapConnector) Close() error                                 { return nil }
+func (c *fakeBootstrapConnector) GetConnectionConfig() connector.ConnectionCfg { return c.cfg }
+
+type fakeBootstrapRunner struct {
+	runner.Runner
+	commands map[string][]string
+}
+
+func (r *fakeBootstrapRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	name := conn.(*fakeBootstrapConnector).name
+	r.commands[name] = append(r.commands[name], cmd)
+	return &runner.CommandResult{}, nil
+}
+
+func newBootstrapTestStep(t *testing.T, keyConnectErr error) (*BootstrapSSHAccessStep, *runtimetest.Context, map[string]*fakeBootstrapConnector) {
+	t.Helper()
+	host := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "192.168.1.10", Port: 22, User: "root", Password: "initial"})
+	ctx := &runtimetest.Context{
+		Host:   host,
+		Conn:   &fakeBootstrapConnector{name: "current", cfg: connector.ConnectionCfg{Host: "192.168.1.10", Port: 22, User: "root", Password: "initial"}},
+		Runner: &fakeBootstrapRunner{commands: map[string][]string{}},
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "ssh", "id_ed25519")
+	s, err := NewBootstrapSSHAccessStepBuilder(ctx, "BootstrapSSHAccess", "", keyPath).Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+
+	created := map[string]*fakeBootstrapConnector{}
+	names := []string{"password", "key"}
+	s.newConnector = func() connector.Connector {
+		c := &fakeBootstrapConnector{name: names[len(created)]}
+		if c.name == "key" {
+			c.connectErr = keyConnectErr
+		}
+		created[c.name] = c
+		return c
+	}
+	return s, ctx, created
+}
+
+func TestBootstrapSSHAccessStep_InstallsKeyAndSwitchesToKeyAuth(t *testing.T) {
+	s, ctx, created := newBootstrapTestStep(t, nil)
+
+	if done, err := s.Precheck(ctx); err != nil || done {
+		t.Fatalf("expected precheck to require the step, got done=%v err=%v", done, err)
+	}
+	if _, err := s.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	pub, err := os.ReadFile(s.PrivateKeyPath + ".pub")
+	if err != nil {
+		t.Fatalf("expected a public key to be generated: %v", err)
+	}
+	publicKey := strings.TrimSpace(string(pub))
+
+	passwordCfg := created["password"].connected[0]
+	if passwordCfg.Password != "initial" || passwordCfg.PrivateKeyPath != "" {
+		t.Errorf("expected the initial password credential to be used, got %+v", passwordCfg)
+	}
+	installCmds := ctx.Runner.(*fakeBootstrapRunner).commands["password"]
+	if len(installCmds) != 1 || !strings.Contains(installCmds[0], "~/.ssh/authorized_keys") || !strings.Contains(installCmds[0], publicKey) {
+		t.Fatalf("expected the public key to be installed into authorized_keys, got %v", installCmds)
+	}
+
+	keyCfg := created["key"].connected[0]
+	if keyCfg.Password != "" || keyCfg.PrivateKeyPath != s.PrivateKeyPath || len(keyCfg.PrivateKey) == 0 {
+		t.Errorf("expected key-only credentials for verification, got %+v", keyCfg)
+	}
+	if cmds := ctx.Runner.(*fakeBootstrapRunner).commands["key"]; len(cmds) != 1 {
+		t.Errorf("expected a command to be run over the key-authenticated connection, got %v", cmds)
+	}
+
+	if cfg := ctx.Conn.GetConnectionConfig(); cfg.Password != "" || cfg.PrivateKeyPath != s.PrivateKeyPath {
+		t.Errorf("expected the host connector to switch to key auth, got %+v", cfg)
+	}
+	if ctx.Host.GetPassword() != "" || ctx.Host.GetPrivateKeyPath() != s.PrivateKeyPath {
+		t.Errorf("expected host credentials to switch to the key, got password=%q key=%q", ctx.Host.GetPassword(), ctx.Host.GetPrivateKeyPath())
+	}
+	if done, err := s.Precheck(ctx); err != nil || !done {
+		t.Errorf("expected precheck to report done after switching, got done=%v err=%v", done, err)
+	}
+}
+
+func TestBootstrapSSHAccessStep_KeyVerificationFails(t *testing.T) {
+	s, ctx, created := newBootstrapTestStep(t, errors.New("ssh: unable to authenticate"))
+
+	if _, err := s.Run(ctx); err == nil || !strings.Contains(err.Error(), "key-based login failed") {
+		t.Fatalf("expected a key verification error, got %v", err)
+	}
+	if len(created["key"].connected) != 1 {
+		t.Errorf("expected a key-auth login to be attempted")
+	}
+	if ctx.Host.GetPassword() != "initial" {
+		t.Errorf("expected host credentials to be left unchanged on failure")
+	}
+	if cfg := ctx.Conn.GetConnectionConfig(); cfg.Password != "initial" {
+		t.Errorf("expected the host connector to keep password auth on failure, got %+v", cfg)
+	}
+}
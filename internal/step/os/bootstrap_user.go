@@ -0,0 +1,163 @@
+package os
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// BootstrapUserStep creates a dedicated login user for kubexm over the host's current connection,
+// typically as root or the initial user of an image. The user's password is unusable, it is
+// authorized with the public key of PrivateKeyPath and it may run any command through sudo
+// without a password. Once a login as the user works, the host and its connector are switched
+// over to it, so that all later steps run as the user.
+type BootstrapUserStep struct {
+	step.Base
+	User           string
+	PrivateKeyPath string
+
+	newConnector func() connector.Connector
+}
+
+type BootstrapUserStepBuilder struct {
+	step.Builder[BootstrapUserStepBuilder, *BootstrapUserStep]
+}
+
+func NewBootstrapUserStepBuilder(ctx runtime.ExecutionContext, instanceName, user, privateKeyPath string) *BootstrapUserStepBuilder {
+	cs := &BootstrapUserStep{
+		User:           user,
+		PrivateKeyPath: privateKeyPath,
+		newConnector: func() connector.Connector {
+			return connector.NewSSHConnector(nil)
+		},
+	}
+	cs.Base.Meta.Name = instanceName
+	cs.Base.Meta.Description = fmt.Sprintf("[%s]>>Create login user [%s] with key [%s]", instanceName, user, privateKeyPath)
+	cs.Base.Sudo = true
+	cs.Base.IgnoreError = false
+	cs.Base.Timeout = 3 * time.Minute
+	return new(BootstrapUserStepBuilder).Init(cs)
+}
+
+func (s *BootstrapUserStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *BootstrapUserStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.User == "" || s.PrivateKeyPath == "" {
+		return false, fmt.Errorf("precheck: user and private key path must be set")
+	}
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, fmt.Errorf("precheck: failed to get connector for host %s: %w", ctx.GetHost().GetName(), err)
+	}
+	cfg := conn.GetConnectionConfig()
+	if cfg.User == s.User && cfg.Password == "" && cfg.PrivateKeyPath == s.PrivateKeyPath {
+		logger.Info("Host is already accessed as the bootstrap user.", "user", s.User)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *BootstrapUserStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get host connector")
+		return result, err
+	}
+	privateKey, publicKey, err := loadOrGenerateSSHKey(s.PrivateKeyPath)
+	if err != nil {
+		result.MarkFailed(err, "failed to prepare SSH key")
+		return result, err
+	}
+
+	logger.Info("Creating user.", "user", s.User, "as", conn.GetConnectionConfig().User)
+	if err := runnerSvc.AddUser(ctx.GoContext(), conn, s.User, "", "/bin/bash", "", true, false); err != nil {
+		result.MarkFailed(err, "failed to create user")
+		return result, err
+	}
+	// '*' rather than a locked '!' password: sshd refuses key logins of locked accounts when it
+	// does not use PAM.
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, fmt.Sprintf("usermod -p '*' %s", s.User), s.Sudo); err != nil {
+		err = fmt.Errorf("failed to disable the password of user %s: %w", s.User, err)
+		result.MarkFailed(err, "failed to disable password")
+		return result, err
+	}
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, userAuthorizedKeysInstallCmd(s.User, publicKey), s.Sudo); err != nil {
+		err = fmt.Errorf("failed to install public key for user %s: %w", s.User, err)
+		result.MarkFailed(err, "failed to install public key")
+		return result, err
+	}
+	if err := runnerSvc.ConfigureSudoer(ctx.GoContext(), conn, s.User, fmt.Sprintf("%s ALL=(ALL) NOPASSWD:ALL\n", s.User)); err != nil {
+		err = fmt.Errorf("failed to configure sudo for user %s: %w", s.User, err)
+		result.MarkFailed(err, "failed to configure sudoer")
+		return result, err
+	}
+
+	userCfg := conn.GetConnectionConfig()
+	userCfg.User = s.User
+	userCfg.Password = ""
+	userCfg.PrivateKey = privateKey
+	userCfg.PrivateKeyPath = s.PrivateKeyPath
+
+	logger.Info("Verifying login and sudo as the new user.")
+	userConn := s.newConnector()
+	if err := userConn.Connect(ctx.GoContext(), userCfg); err != nil {
+		err = fmt.Errorf("login as %s failed: %w", s.User, err)
+		result.MarkFailed(err, "login verification failed")
+		return result, err
+	}
+	_, verifyErr := runnerSvc.Run(ctx.GoContext(), userConn, "true", true)
+	userConn.Close()
+	if verifyErr != nil {
+		err := fmt.Errorf("user %s cannot run commands through sudo: %w", s.User, verifyErr)
+		result.MarkFailed(err, "sudo verification failed")
+		return result, err
+	}
+
+	host := ctx.GetHost()
+	host.SetUser(s.User)
+	host.SetPassword("")
+	host.SetPrivateKey("")
+	host.SetPrivateKeyPath(s.PrivateKeyPath)
+
+	_ = conn.Close()
+	if err := conn.Connect(ctx.GoContext(), userCfg); err != nil {
+		err = fmt.Errorf("failed to reconnect as %s: %w", s.User, err)
+		result.MarkFailed(err, "failed to switch connector to the new user")
+		return result, err
+	}
+
+	logger.Info("Host switched to the bootstrap user.", "user", s.User)
+	result.MarkCompleted("step completed successfully")
+	return result, nil
+}
+
+func (s *BootstrapUserStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Warn("Rollback for BootstrapUserStep is a no-op. The user is left on the host.", "user", s.User)
+	return nil
+}
+
+// userAuthorizedKeysInstallCmd appends publicKey to the authorized_keys of user unless it is
+// already present, and gives the user its .ssh directory. It is meant to be run through sudo.
+func userAuthorizedKeysInstallCmd(user, publicKey string) string {
+	script := fmt.Sprintf(`home=$(getent passwd %[1]s | cut -d: -f6) && group=$(id -gn %[1]s) && `+
+		`mkdir -p "$home/.ssh" && touch "$home/.ssh/authorized_keys" && `+
+		`(grep -qxF "%[2]s" "$home/.ssh/authorized_keys" || echo "%[2]s" >> "$home/.ssh/authorized_keys") && `+
+		`chown -R %[1]s:"$group" "$home/.ssh" && chmod 700 "$home/.ssh" && chmod 600 "$home/.ssh/authorized_keys"`, user, publicKey)
+	return fmt.Sprintf("sh -c '%s'", script)
+}
+
+var _ step.Step = (*BootstrapUserStep)(nil)
@@ -0,0 +1,90 @@
+package os
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+func (r *fakeBootstrapRunner) AddUser(ctx context.Context, conn connector.Connector, username, group, shell string, homeDir string, createHome bool, systemUser bool) error {
+	name := conn.(*fakeBootstrapConnector).name
+	r.commands[name] = append(r.commands[name], "useradd "+username)
+	return nil
+}
+
+func (r *fakeBootstrapRunner) ConfigureSudoer(ctx context.Context, conn connector.Connector, sudoerName, content string) error {
+	name := conn.(*fakeBootstrapConnector).name
+	r.commands[name] = append(r.commands[name], fmt.Sprintf("sudoer %s: %s", sudoerName, strings.TrimSpace(content)))
+	return nil
+}
+
+func newBootstrapUserTestStep(t *testing.T, userConnectErr error) (*BootstrapUserStep, *runtimetest.Context, *fakeBootstrapConnector) {
+	t.Helper()
+	host := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "192.168.1.10", Port: 22, User: "root", Password: "initial"})
+	ctx := &runtimetest.Context{
+		Host:   host,
+		Conn:   &fakeBootstrapConnector{name: "current", cfg: connector.ConnectionCfg{Host: "192.168.1.10", Port: 22, User: "root", Password: "initial"}},
+		Runner: &fakeBootstrapRunner{commands: map[string][]string{}},
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "ssh", "id_ed25519")
+	s, err := NewBootstrapUserStepBuilder(ctx, "BootstrapUser", "kubexm", keyPath).Build()
+	if err != nil {
+		t.Fatalf("failed to build step: %v", err)
+	}
+	userConn := &fakeBootstrapConnector{name: "user", connectErr: userConnectErr}
+	s.newConnector = func() connector.Connector { return userConn }
+	return s, ctx, userConn
+}
+
+func TestBootstrapUserStep_CreatesUserAndSwitchesToIt(t *testing.T) {
+	s, ctx, userConn := newBootstrapUserTestStep(t, nil)
+
+	if done, err := s.Precheck(ctx); err != nil || done {
+		t.Fatalf("expected precheck to require the step, got done=%v err=%v", done, err)
+	}
+	if _, err := s.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	cmds := ctx.Runner.(*fakeBootstrapRunner).commands["current"]
+	if len(cmds) != 4 || cmds[0] != "useradd kubexm" || cmds[1] != "usermod -p '*' kubexm" ||
+		!strings.Contains(cmds[2], "authorized_keys") || cmds[3] != "sudoer kubexm: kubexm ALL=(ALL) NOPASSWD:ALL" {
+		t.Fatalf("unexpected commands over the initial connection: %v", cmds)
+	}
+
+	userCfg := userConn.connected[0]
+	if userCfg.User != "kubexm" || userCfg.Password != "" || userCfg.PrivateKeyPath != s.PrivateKeyPath || len(userCfg.PrivateKey) == 0 {
+		t.Errorf("expected a key login as the new user, got %+v", userCfg)
+	}
+	if cfg := ctx.Conn.GetConnectionConfig(); cfg.User != "kubexm" || cfg.Password != "" {
+		t.Errorf("expected the host connector to switch to the new user, got %+v", cfg)
+	}
+	if ctx.Host.GetUser() != "kubexm" || ctx.Host.GetPassword() != "" || ctx.Host.GetPrivateKeyPath() != s.PrivateKeyPath {
+		t.Errorf("expected host credentials to switch to the new user, got user=%q key=%q", ctx.Host.GetUser(), ctx.Host.GetPrivateKeyPath())
+	}
+	if done, err := s.Precheck(ctx); err != nil || !done {
+		t.Errorf("expected precheck to report done after switching, got done=%v err=%v", done, err)
+	}
+}
+
+func TestBootstrapUserStep_LoginFails(t *testing.T) {
+	s, ctx, _ := newBootstrapUserTestStep(t, errors.New("ssh: unable to authenticate"))
+
+	if _, err := s.Run(ctx); err == nil || !strings.Contains(err.Error(), "login as kubexm failed") {
+		t.Fatalf("expected a login error, got %v", err)
+	}
+	if ctx.Host.GetUser() != "root" {
+		t.Errorf("expected the host user to be left unchanged on failure")
+	}
+	if cfg := ctx.Conn.GetConnectionConfig(); cfg.User != "root" {
+		t.Errorf("expected the host connector to keep the initial user on failure, got %+v", cfg)
+	}
+}
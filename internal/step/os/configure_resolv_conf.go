@@ -0,0 +1,319 @@
+package os
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pkg/errors"
+)
+
+// The ways the resolver of a host is configured.
+const (
+	resolverBackendSystemdResolved = "systemd-resolved"
+	resolverBackendNetworkManager  = "NetworkManager"
+	resolverBackendFile            = "file"
+)
+
+var _ step.Step = (*ConfigureResolvConfStep)(nil)
+
+// ConfigureResolvConfStep makes the resolver of a host use the nameservers, search domains and
+// options of spec.extra.resolves. Where systemd-resolved owns /etc/resolv.conf, as on Ubuntu, they
+// go to a resolved.conf drop-in. Where NetworkManager runs, as on the RHEL family, it is told to
+// leave /etc/resolv.conf alone, which is then written directly like on the other hosts. Settings
+// left empty keep the ones the host already has.
+type ConfigureResolvConfStep struct {
+	step.Base
+	Nameservers []string
+	Searches    []string
+	Options     []string
+
+	// originalFiles holds the content of the files written by Run, nil for the ones that did not
+	// exist; originalResolvConfLink is the target /etc/resolv.conf pointed to before it was replaced.
+	originalFiles          map[string][]byte
+	originalResolvConfLink string
+	restartedService       string
+}
+
+type ConfigureResolvConfStepBuilder struct {
+	step.Builder[ConfigureResolvConfStepBuilder, *ConfigureResolvConfStep]
+}
+
+func NewConfigureResolvConfStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigureResolvConfStepBuilder {
+	s := &ConfigureResolvConfStep{}
+	if extra := ctx.GetClusterConfig().Spec.Extra; extra != nil && extra.ResolvConf != nil {
+		s.Nameservers = extra.ResolvConf.Nameservers
+		s.Searches = extra.ResolvConf.Searches
+		s.Options = extra.ResolvConf.Options
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Configure the resolver with nameservers %v and search domains %v", s.Base.Meta.Name, s.Nameservers, s.Searches)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(ConfigureResolvConfStepBuilder).Init(s)
+	return b
+}
+
+func (s *ConfigureResolvConfStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// resolverBackend returns what configures the resolver of a host, given whether systemd-resolved
+// and NetworkManager are running and the file /etc/resolv.conf resolves to.
+func resolverBackend(resolvedActive bool, resolvConfTarget string, networkManagerActive bool) string {
+	if resolvedActive && strings.HasPrefix(resolvConfTarget, "/run/systemd/resolve/") {
+		return resolverBackendSystemdResolved
+	}
+	if networkManagerActive {
+		return resolverBackendNetworkManager
+	}
+	return resolverBackendFile
+}
+
+// renderResolvedDropIn returns a resolved.conf drop-in using nameservers and searches.
+func renderResolvedDropIn(nameservers, searches []string) string {
+	var b strings.Builder
+	b.WriteString("# Generated by KubeXM. DO NOT EDIT.\n[Resolve]\n")
+	if len(nameservers) > 0 {
+		fmt.Fprintf(&b, "DNS=%s\n", strings.Join(nameservers, " "))
+	}
+	if len(searches) > 0 {
+		fmt.Fprintf(&b, "Domains=%s\n", strings.Join(searches, " "))
+	}
+	return b.String()
+}
+
+// mergeResolvConf returns the resolv.conf current would become with nameservers, searches and
+// options, keeping the entries of current for the ones left empty. A domain entry is dropped
+// when searches are given, as the last of domain and search wins.
+func mergeResolvConf(current string, nameservers, searches, options []string) string {
+	var keptNameservers, keptSearches, keptOptions []string
+	for _, line := range strings.Split(current, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			keptNameservers = append(keptNameservers, fields[1])
+		case "search", "domain":
+			keptSearches = fields[1:]
+		case "options":
+			keptOptions = append(keptOptions, fields[1:]...)
+		}
+	}
+	if len(nameservers) > 0 {
+		keptNameservers = nameservers
+	}
+	if len(searches) > 0 {
+		keptSearches = searches
+	}
+	if len(options) > 0 {
+		keptOptions = options
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by KubeXM. DO NOT EDIT.\n")
+	for _, ns := range keptNameservers {
+		fmt.Fprintf(&b, "nameserver %s\n", ns)
+	}
+	if len(keptSearches) > 0 {
+		fmt.Fprintf(&b, "search %s\n", strings.Join(keptSearches, " "))
+	}
+	if len(keptOptions) > 0 {
+		fmt.Fprintf(&b, "options %s\n", strings.Join(keptOptions, " "))
+	}
+	return b.String()
+}
+
+// plan returns the resolver backend of the host and the content of the files to write for it.
+func (s *ConfigureResolvConfStep) plan(ctx runtime.ExecutionContext) (string, map[string]string, error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", nil, err
+	}
+	resolvedActive, _ := runner.Check(ctx.GoContext(), conn, "systemctl is-active --quiet systemd-resolved", s.Sudo)
+	networkManagerActive, _ := runner.Check(ctx.GoContext(), conn, "systemctl is-active --quiet NetworkManager", s.Sudo)
+	target := common.ResolvConfPath
+	if res, err := runner.Run(ctx.GoContext(), conn, "readlink -f "+common.ResolvConfPath, s.Sudo); err == nil && strings.TrimSpace(res.Stdout) != "" {
+		target = strings.TrimSpace(res.Stdout)
+	}
+
+	backend := resolverBackend(resolvedActive, target, networkManagerActive)
+	if backend == resolverBackendSystemdResolved {
+		return backend, map[string]string{common.SystemdResolvedDropInPath: renderResolvedDropIn(s.Nameservers, s.Searches)}, nil
+	}
+
+	current, err := runner.ReadFile(ctx.GoContext(), conn, common.ResolvConfPath)
+	if err != nil && !os.IsNotExist(err) && !strings.Contains(err.Error(), "No such file or directory") {
+		return "", nil, errors.Wrapf(err, "failed to read %s", common.ResolvConfPath)
+	}
+	files := map[string]string{common.ResolvConfPath: mergeResolvConf(string(current), s.Nameservers, s.Searches, s.Options)}
+	if backend == resolverBackendNetworkManager {
+		files[common.NetworkManagerDNSDropInPath] = "# Generated by KubeXM. DO NOT EDIT.\n[main]\ndns=none\n"
+	}
+	return backend, files, nil
+}
+
+func (s *ConfigureResolvConfStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if len(s.Nameservers) == 0 && len(s.Searches) == 0 && len(s.Options) == 0 {
+		return true, nil
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	backend, files, err := s.plan(ctx)
+	if err != nil {
+		return false, err
+	}
+	for path, content := range files {
+		current, err := ctx.GetRunner().ReadFile(ctx.GoContext(), conn, path)
+		if err != nil || string(current) != content {
+			return false, nil
+		}
+	}
+	logger.Infof("The resolver is already configured through %s.", backend)
+	return true, nil
+}
+
+func (s *ConfigureResolvConfStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	backend, files, err := s.plan(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to plan the resolver configuration")
+		return result, err
+	}
+	logger.Infof("Configuring the resolver through %s.", backend)
+
+	if backend == resolverBackendSystemdResolved && len(s.Options) > 0 {
+		logger.Warnf("systemd-resolved has no equivalent for resolver options %v, they are not applied.", s.Options)
+	}
+
+	s.originalFiles = make(map[string][]byte)
+	for path := range files {
+		original, err := runner.ReadFile(ctx.GoContext(), conn, path)
+		if err != nil {
+			original = nil
+		}
+		s.originalFiles[path] = original
+	}
+
+	// NetworkManager must stop managing /etc/resolv.conf before it is written, or it rewrites it.
+	if dropIn, ok := files[common.NetworkManagerDNSDropInPath]; ok {
+		if err := s.writeFile(ctx, common.NetworkManagerDNSDropInPath, dropIn); err != nil {
+			result.MarkFailed(err, "failed to configure NetworkManager")
+			return result, err
+		}
+		if _, err := runner.Run(ctx.GoContext(), conn, "systemctl reload NetworkManager", s.Sudo); err != nil {
+			err = errors.Wrap(err, "failed to reload NetworkManager")
+			result.MarkFailed(err, "failed to reload NetworkManager")
+			return result, err
+		}
+		s.restartedService = "NetworkManager"
+	}
+
+	if content, ok := files[common.ResolvConfPath]; ok {
+		// A symlinked /etc/resolv.conf belongs to another manager, e.g. resolvconf, that would
+		// overwrite it, so it is replaced by a regular file.
+		if res, err := runner.Run(ctx.GoContext(), conn, "readlink "+common.ResolvConfPath, s.Sudo); err == nil && strings.TrimSpace(res.Stdout) != "" {
+			s.originalResolvConfLink = strings.TrimSpace(res.Stdout)
+			if err := runner.Remove(ctx.GoContext(), conn, common.ResolvConfPath, s.Sudo, false); err != nil {
+				err = errors.Wrapf(err, "failed to remove the symlink %s", common.ResolvConfPath)
+				result.MarkFailed(err, "failed to replace resolv.conf")
+				return result, err
+			}
+		}
+		if err := s.writeFile(ctx, common.ResolvConfPath, content); err != nil {
+			result.MarkFailed(err, "failed to write resolv.conf")
+			return result, err
+		}
+	}
+
+	if dropIn, ok := files[common.SystemdResolvedDropInPath]; ok {
+		if err := s.writeFile(ctx, common.SystemdResolvedDropInPath, dropIn); err != nil {
+			result.MarkFailed(err, "failed to configure systemd-resolved")
+			return result, err
+		}
+		if _, err := runner.Run(ctx.GoContext(), conn, "systemctl restart systemd-resolved", s.Sudo); err != nil {
+			err = errors.Wrap(err, "failed to restart systemd-resolved")
+			result.MarkFailed(err, "failed to restart systemd-resolved")
+			return result, err
+		}
+		s.restartedService = "systemd-resolved"
+	}
+
+	logger.Info("Resolver configured.")
+	result.MarkCompleted("resolver configured through " + backend)
+	return result, nil
+}
+
+func (s *ConfigureResolvConfStep) writeFile(ctx runtime.ExecutionContext, path, content string) error {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := runner.Mkdirp(ctx.GoContext(), conn, dir, "0755", s.Sudo); err != nil {
+		return errors.Wrapf(err, "failed to create %s", dir)
+	}
+	if err := runner.WriteFile(ctx.GoContext(), conn, []byte(content), path, "0644", s.Sudo); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return nil
+}
+
+func (s *ConfigureResolvConfStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	if s.originalFiles == nil {
+		return nil
+	}
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+
+	for path, original := range s.originalFiles {
+		if path == common.ResolvConfPath && s.originalResolvConfLink != "" {
+			if _, err := runner.Run(ctx.GoContext(), conn, fmt.Sprintf("ln -sfn %s %s", s.originalResolvConfLink, path), s.Sudo); err != nil {
+				logger.Warnf("Failed to restore the symlink %s -> %s: %v", path, s.originalResolvConfLink, err)
+			}
+			continue
+		}
+		if original == nil {
+			err = runner.Remove(ctx.GoContext(), conn, path, s.Sudo, false)
+		} else {
+			err = runner.WriteFile(ctx.GoContext(), conn, original, path, "0644", s.Sudo)
+		}
+		if err != nil {
+			logger.Warnf("Failed to restore %s: %v", path, err)
+		}
+	}
+	if s.restartedService != "" {
+		if _, err := runner.Run(ctx.GoContext(), conn, "systemctl restart "+s.restartedService, s.Sudo); err != nil {
+			logger.Warnf("Failed to restart %s: %v", s.restartedService, err)
+		}
+	}
+	return nil
+}
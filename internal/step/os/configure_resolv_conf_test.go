@@ -0,0 +1,63 @@
+package os
+
+import "testing"
+
+func TestResolverBackend(t *testing.T) {
+	tests := []struct {
+		resolvedActive       bool
+		target               string
+		networkManagerActive bool
+		want                 string
+	}{
+		{true, "/run/systemd/resolve/stub-resolv.conf", false, resolverBackendSystemdResolved},
+		{true, "/run/systemd/resolve/stub-resolv.conf", true, resolverBackendSystemdResolved},
+		{true, "/etc/resolv.conf", true, resolverBackendNetworkManager},
+		{false, "/run/systemd/resolve/resolv.conf", false, resolverBackendFile},
+		{false, "/run/resolvconf/resolv.conf", false, resolverBackendFile},
+	}
+	for _, tt := range tests {
+		if got := resolverBackend(tt.resolvedActive, tt.target, tt.networkManagerActive); got != tt.want {
+			t.Errorf("resolverBackend(%v, %q, %v) = %q, want %q", tt.resolvedActive, tt.target, tt.networkManagerActive, got, tt.want)
+		}
+	}
+}
+
+func TestRenderResolvedDropIn(t *testing.T) {
+	want := "# Generated by KubeXM. DO NOT EDIT.\n[Resolve]\nDNS=10.0.0.2 10.0.0.3\nDomains=corp.example.com\n"
+	if got := renderResolvedDropIn([]string{"10.0.0.2", "10.0.0.3"}, []string{"corp.example.com"}); got != want {
+		t.Errorf("renderResolvedDropIn() = %q, want %q", got, want)
+	}
+	want = "# Generated by KubeXM. DO NOT EDIT.\n[Resolve]\nDomains=corp.example.com\n"
+	if got := renderResolvedDropIn(nil, []string{"corp.example.com"}); got != want {
+		t.Errorf("renderResolvedDropIn() without nameservers = %q, want %q", got, want)
+	}
+}
+
+func TestMergeResolvConf(t *testing.T) {
+	current := "# Generated by NetworkManager\n" +
+		"domain lab.local\n" +
+		"nameserver 192.168.1.1\n" +
+		"nameserver 192.168.1.2\n" +
+		"options rotate\n"
+
+	want := "# Generated by KubeXM. DO NOT EDIT.\n" +
+		"nameserver 10.0.0.2\n" +
+		"search corp.example.com\n" +
+		"options rotate\n"
+	if got := mergeResolvConf(current, []string{"10.0.0.2"}, []string{"corp.example.com"}, nil); got != want {
+		t.Errorf("mergeResolvConf() = %q, want %q", got, want)
+	}
+
+	want = "# Generated by KubeXM. DO NOT EDIT.\n" +
+		"nameserver 192.168.1.1\n" +
+		"nameserver 192.168.1.2\n" +
+		"search lab.local\n" +
+		"options timeout:2 attempts:3\n"
+	if got := mergeResolvConf(current, nil, nil, []string{"timeout:2", "attempts:3"}); got != want {
+		t.Errorf("mergeResolvConf() keeping nameservers = %q, want %q", got, want)
+	}
+
+	if got := mergeResolvConf(want, nil, nil, []string{"timeout:2", "attempts:3"}); got != want {
+		t.Errorf("mergeResolvConf() on its own output = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,241 @@
+package os
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pkg/errors"
+)
+
+const selinuxConfigPath = "/etc/selinux/config"
+
+var _ step.Step = (*ConfigureSELinuxStep)(nil)
+
+// ConfigureSELinuxStep puts SELinux in the mode of spec.system.selinux, both for the running
+// system and in /etc/selinux/config for the next boot. Hosts without SELinux are left alone.
+type ConfigureSELinuxStep struct {
+	step.Base
+	Mode                         string
+	originalSelinuxConfigContent string
+	originalEnforceStatus        string
+}
+
+type ConfigureSELinuxStepBuilder struct {
+	step.Builder[ConfigureSELinuxStepBuilder, *ConfigureSELinuxStep]
+}
+
+func NewConfigureSELinuxStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigureSELinuxStepBuilder {
+	s := &ConfigureSELinuxStep{
+		Mode: desiredSELinuxMode(ctx.GetClusterConfig()),
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Configure SELinux mode [%s]", s.Base.Meta.Name, s.Mode)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(ConfigureSELinuxStepBuilder).Init(s)
+	return b
+}
+
+// desiredSELinuxMode returns the SELinux mode the hosts are put in, or "" if SELinux is left alone.
+func desiredSELinuxMode(cluster *v1alpha1.Cluster) string {
+	if cluster == nil || cluster.Spec == nil {
+		return ""
+	}
+	if cluster.Spec.System != nil && cluster.Spec.System.SELinux != "" {
+		return cluster.Spec.System.SELinux
+	}
+	if cluster.Spec.Preflight != nil && cluster.Spec.Preflight.DisableSelinux != nil && *cluster.Spec.Preflight.DisableSelinux {
+		return common.DisabledSELinuxMode
+	}
+	return ""
+}
+
+func (s *ConfigureSELinuxStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// currentState returns the running SELinux mode and the content of its configuration file. The
+// mode is empty when SELinux is not installed.
+func (s *ConfigureSELinuxStep) currentState(ctx runtime.ExecutionContext) (string, string, error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := runner.LookPath(ctx.GoContext(), conn, "getenforce"); err != nil {
+		return "", "", nil
+	}
+	runResult, err := runner.Run(ctx.GoContext(), conn, "getenforce", s.Sudo)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to check SELinux status with 'getenforce'")
+	}
+	configBytes, err := runner.ReadFile(ctx.GoContext(), conn, selinuxConfigPath)
+	if err != nil && !os.IsNotExist(err) && !strings.Contains(err.Error(), "No such file or directory") {
+		return "", "", errors.Wrapf(err, "failed to read SELinux config at '%s'", selinuxConfigPath)
+	}
+	return strings.ToLower(strings.TrimSpace(runResult.Stdout)), string(configBytes), nil
+}
+
+func (s *ConfigureSELinuxStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.Mode == "" {
+		logger.Info("SELinux is not managed for this cluster.")
+		return true, nil
+	}
+
+	current, config, err := s.currentState(ctx)
+	if err != nil {
+		return false, err
+	}
+	if current == "" {
+		logger.Info("SELinux command 'getenforce' not found. Assuming SELinux is not installed.")
+		return true, nil
+	}
+
+	if selinuxEnforceArg(current, s.Mode) == "" && (config == "" || configuredSELinuxMode(config) == s.Mode) {
+		logger.Infof("SELinux is already configured for '%s' mode (currently '%s').", s.Mode, current)
+		return true, nil
+	}
+	logger.Infof("SELinux is in '%s' mode and needs to be set to '%s'.", current, s.Mode)
+	return false, nil
+}
+
+func (s *ConfigureSELinuxStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "step failed")
+		return result, err
+	}
+
+	current, config, err := s.currentState(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to read SELinux state")
+		return result, err
+	}
+	if current == "" {
+		result.MarkCompleted("SELinux is not installed")
+		return result, nil
+	}
+	s.originalEnforceStatus = current
+	s.originalSelinuxConfigContent = config
+
+	if arg := selinuxEnforceArg(current, s.Mode); arg != "" {
+		logger.Infof("Switching the running system to '%s' mode with 'setenforce %s'...", s.Mode, arg)
+		if _, err := runner.Run(ctx.GoContext(), conn, "setenforce "+arg, s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to run setenforce")
+			return result, err
+		}
+	}
+
+	if config == "" {
+		logger.Warnf("SELinux config file '%s' not found. The mode will not persist across reboots.", selinuxConfigPath)
+	} else if configuredSELinuxMode(config) != s.Mode {
+		logger.Infof("Setting SELINUX=%s in %s...", s.Mode, selinuxConfigPath)
+		if err := helpers.WriteContentToRemote(ctx, conn, setSELinuxConfigMode(config, s.Mode), selinuxConfigPath, "0644", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to update SELinux config")
+			return result, err
+		}
+	}
+
+	switch {
+	case current == common.DisabledSELinuxMode && s.Mode == common.EnforceSELinuxMode:
+		// Files created while SELinux was disabled have no labels and must be relabeled at boot.
+		if _, err := runner.Run(ctx.GoContext(), conn, "touch /.autorelabel", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to schedule SELinux relabeling")
+			return result, err
+		}
+		logger.Warn("SELinux is disabled in the running kernel. A reboot, which relabels the filesystem, is required to enforce it.")
+	case current == common.DisabledSELinuxMode && s.Mode != common.DisabledSELinuxMode:
+		logger.Warnf("SELinux is disabled in the running kernel. A reboot is required for '%s' mode.", s.Mode)
+	case current != common.DisabledSELinuxMode && s.Mode == common.DisabledSELinuxMode:
+		logger.Warn("SELinux is permissive until the next reboot disables it completely.")
+	}
+
+	logger.Infof("SELinux configured for '%s' mode.", s.Mode)
+	result.MarkCompleted("step completed successfully")
+	return result, nil
+}
+
+func (s *ConfigureSELinuxStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+
+	if s.originalSelinuxConfigContent != "" {
+		logger.Infof("Restoring original %s...", selinuxConfigPath)
+		if err := helpers.WriteContentToRemote(ctx, conn, s.originalSelinuxConfigContent, selinuxConfigPath, "0644", s.Sudo); err != nil {
+			return errors.Wrapf(err, "failed to restore '%s'", selinuxConfigPath)
+		}
+	}
+	if arg := selinuxEnforceArg(s.originalEnforceStatus, s.Mode); arg != "" {
+		// Undo the setenforce of Run.
+		restoreArg := map[string]string{"0": "1", "1": "0"}[arg]
+		if _, err := runner.Run(ctx.GoContext(), conn, "setenforce "+restoreArg, s.Sudo); err != nil {
+			logger.Warnf("Failed to restore SELinux '%s' mode. Error: %v", s.originalEnforceStatus, err)
+		}
+	}
+	return nil
+}
+
+// selinuxEnforceArg returns the setenforce argument that moves a running system from the current
+// mode towards mode, or "" if nothing can or needs to be done without a reboot. A disabled
+// SELinux stays disabled, and disabling it is approximated by the permissive mode.
+func selinuxEnforceArg(current, mode string) string {
+	switch {
+	case current == common.DisabledSELinuxMode || current == "":
+		return ""
+	case mode == common.EnforceSELinuxMode && current != common.EnforceSELinuxMode:
+		return "1"
+	case mode != common.EnforceSELinuxMode && current == common.EnforceSELinuxMode:
+		return "0"
+	default:
+		return ""
+	}
+}
+
+// configuredSELinuxMode returns the SELINUX setting of an /etc/selinux/config file.
+func configuredSELinuxMode(config string) string {
+	mode := ""
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if value, ok := strings.CutPrefix(line, "SELINUX="); ok {
+			mode = strings.ToLower(strings.Trim(strings.TrimSpace(value), `"'`))
+		}
+	}
+	return mode
+}
+
+// setSELinuxConfigMode returns an /etc/selinux/config file with its SELINUX setting set to mode.
+func setSELinuxConfigMode(config, mode string) string {
+	lines := strings.Split(strings.TrimRight(config, "\n"), "\n")
+	found := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "SELINUX=") {
+			lines[i] = "SELINUX=" + mode
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, "SELINUX="+mode)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,69 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+func TestDesiredSELinuxMode(t *testing.T) {
+	disable := true
+	keep := false
+	tests := []struct {
+		name    string
+		cluster *v1alpha1.Cluster
+		want    string
+	}{
+		{"nil cluster", nil, ""},
+		{"system mode wins", &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+			System:    &v1alpha1.SystemSpec{SELinux: "enforcing"},
+			Preflight: &v1alpha1.Preflight{DisableSelinux: &disable},
+		}}, "enforcing"},
+		{"preflight disables", &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+			Preflight: &v1alpha1.Preflight{DisableSelinux: &disable},
+		}}, "disabled"},
+		{"left alone", &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+			Preflight: &v1alpha1.Preflight{DisableSelinux: &keep},
+		}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := desiredSELinuxMode(tt.cluster); got != tt.want {
+				t.Errorf("desiredSELinuxMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSELinuxEnforceArg(t *testing.T) {
+	tests := []struct {
+		current, mode, want string
+	}{
+		{"enforcing", "permissive", "0"},
+		{"enforcing", "disabled", "0"},
+		{"permissive", "enforcing", "1"},
+		{"permissive", "disabled", ""},
+		{"enforcing", "enforcing", ""},
+		{"disabled", "enforcing", ""},
+	}
+	for _, tt := range tests {
+		if got := selinuxEnforceArg(tt.current, tt.mode); got != tt.want {
+			t.Errorf("selinuxEnforceArg(%q, %q) = %q, want %q", tt.current, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSetSELinuxConfigMode(t *testing.T) {
+	config := "# This file controls the state of SELinux\nSELINUX=enforcing\nSELINUXTYPE=targeted\n"
+	want := "# This file controls the state of SELinux\nSELINUX=permissive\nSELINUXTYPE=targeted\n"
+	got := setSELinuxConfigMode(config, "permissive")
+	if got != want {
+		t.Errorf("setSELinuxConfigMode() = %q, want %q", got, want)
+	}
+	if mode := configuredSELinuxMode(got); mode != "permissive" {
+		t.Errorf("configuredSELinuxMode() = %q, want permissive", mode)
+	}
+	if got := setSELinuxConfigMode("SELINUXTYPE=targeted", "disabled"); got != "SELINUXTYPE=targeted\nSELINUX=disabled\n" {
+		t.Errorf("setSELinuxConfigMode() without a SELINUX line = %q", got)
+	}
+}
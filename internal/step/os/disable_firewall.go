@@ -2,16 +2,21 @@ package os
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
-	"github.com/mensylisir/kubexm/internal/types"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
 )
 
+// DisableFirewallStep stops and disables the host firewall: firewalld on the Red Hat family, ufw
+// on the Debian family and both of them on other distributions.
 type DisableFirewallStep struct {
 	step.Base
+	disabledFirewalls []string
 }
 
 type DisableFirewallStepBuilder struct {
@@ -23,7 +28,7 @@ func NewDisableFirewallStepBuilder(ctx runtime.ExecutionContext, instanceName st
 
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Disable Firewall", s.Base.Meta.Name)
-	s.Base.Sudo = false
+	s.Base.Sudo = true
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 2 * time.Minute
 
@@ -35,30 +40,79 @@ func (s *DisableFirewallStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
-func (s *DisableFirewallStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
-	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+// firewallsForDistribution returns the firewall services that may run on a distribution.
+func firewallsForDistribution(osID string) []string {
+	switch {
+	case IsIn(osID, common.RedHatFamilyDistributions):
+		return []string{"firewalld"}
+	case IsIn(osID, common.DebianFamilyDistributions):
+		return []string{"ufw"}
+	default:
+		return knownFirewallServices
+	}
+}
+
+// activeFirewalls returns the firewall services that are active on the current host.
+func (s *DisableFirewallStep) activeFirewalls(ctx runtime.ExecutionContext) ([]string, error) {
 	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	osID := ""
+	if facts, err := ctx.GetHostFacts(ctx.GetHost()); err == nil && facts.OS != nil {
+		osID = strings.ToLower(facts.OS.ID)
 	}
 
-	// Check if firewalld is active
-	runResult, err := runner.Run(ctx.GoContext(), conn, "systemctl is-active firewalld", s.Sudo)
-	if err != nil {
-		// If command fails, it might mean firewalld is not installed or service not found, which is fine.
-		// Or it returns non-zero exit code if not active.
-		logger.Debugf("systemctl is-active firewalld returned error (likely not active): %v", err)
-		return true, nil
+	var active []string
+	for _, firewall := range firewallsForDistribution(osID) {
+		switch firewall {
+		case "firewalld":
+			// is-active exits non-zero when the service is inactive or not installed.
+			res, err := runner.Run(ctx.GoContext(), conn, "systemctl is-active firewalld", s.Sudo)
+			if err == nil && strings.TrimSpace(res.Stdout) == "active" {
+				active = append(active, firewall)
+			}
+		case "ufw":
+			if _, err := runner.LookPath(ctx.GoContext(), conn, "ufw"); err != nil {
+				continue
+			}
+			res, err := runner.Run(ctx.GoContext(), conn, "ufw status", s.Sudo)
+			if err == nil && isUFWActive(res.Stdout) {
+				active = append(active, firewall)
+			}
+		}
 	}
+	return active, nil
+}
 
-	if runResult.Stdout == "active" {
-		logger.Info("Firewalld is active, needs to be disabled.")
-		return false, nil
+// isUFWActive reports whether the output of 'ufw status' shows an active firewall.
+func isUFWActive(status string) bool {
+	for _, line := range strings.Split(status, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "Status:"); ok {
+			return strings.TrimSpace(value) == "active"
+		}
 	}
+	return false
+}
 
-	logger.Info("Firewalld is not active.")
-	return true, nil
+func (s *DisableFirewallStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if preflight := ctx.GetClusterConfig().Spec.Preflight; preflight != nil && preflight.DisableFirewalld != nil && !*preflight.DisableFirewalld {
+		logger.Info("Disabling the firewall is turned off in spec.preflight.")
+		return true, nil
+	}
+
+	active, err := s.activeFirewalls(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(active) == 0 {
+		logger.Info("No firewall is active.")
+		return true, nil
+	}
+	logger.Infof("Firewall %s is active and needs to be disabled.", strings.Join(active, ", "))
+	return false, nil
 }
 
 func (s *DisableFirewallStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
@@ -68,24 +122,34 @@ func (s *DisableFirewallStep) Run(ctx runtime.ExecutionContext) (*types.StepResu
 	runner := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
-		result.MarkFailed(err, "step failed"); return result, err
+		result.MarkFailed(err, "step failed")
+		return result, err
 	}
 
-	cmds := []string{
-		"systemctl stop firewalld",
-		"systemctl disable firewalld",
+	active, err := s.activeFirewalls(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to detect the firewall")
+		return result, err
 	}
 
-	for _, cmd := range cmds {
+	s.disabledFirewalls = nil
+	for _, firewall := range active {
+		cmd := "systemctl disable --now firewalld"
+		if firewall == "ufw" {
+			cmd = "ufw disable"
+		}
 		logger.Infof("Executing: %s", cmd)
 		if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
-			// Ignore error if service doesn't exist
-			logger.Warnf("Failed to execute %s: %v (ignoring)", cmd, err)
+			err = fmt.Errorf("failed to disable %s: %w", firewall, err)
+			result.MarkFailed(err, "failed to disable firewall")
+			return result, err
 		}
+		s.disabledFirewalls = append(s.disabledFirewalls, firewall)
 	}
 
 	logger.Info("Firewall disabled successfully.")
-	result.MarkCompleted("step completed successfully"); return result, nil
+	result.MarkCompleted("step completed successfully")
+	return result, nil
 }
 
 func (s *DisableFirewallStep) Rollback(ctx runtime.ExecutionContext) error {
@@ -96,18 +160,16 @@ func (s *DisableFirewallStep) Rollback(ctx runtime.ExecutionContext) error {
 		return err
 	}
 
-	logger.Info("Rolling back: Enabling firewalld...")
-	cmds := []string{
-		"systemctl enable firewalld",
-		"systemctl start firewalld",
-	}
-
-	for _, cmd := range cmds {
+	for _, firewall := range s.disabledFirewalls {
+		cmd := "systemctl enable --now firewalld"
+		if firewall == "ufw" {
+			cmd = "ufw --force enable"
+		}
+		logger.Infof("Rolling back: %s", cmd)
 		if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
 			logger.Warnf("Failed to execute %s during rollback: %v", cmd, err)
 		}
 	}
-
 	return nil
 }
 
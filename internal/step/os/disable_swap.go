@@ -2,22 +2,29 @@ package os
 
 import (
 	"fmt"
-	"github.com/mensylisir/kubexm/internal/step/helpers"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
-	"github.com/mensylisir/kubexm/internal/types"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
 )
 
+const fstabPath = "/etc/fstab"
+
 var _ step.Step = (*DisableSwapStep)(nil)
 
+// DisableSwapStep turns swap off and keeps it off across reboots: the swap entries of /etc/fstab
+// are commented out and, on systemd hosts, swap.target is masked so that swap partitions found
+// by the systemd GPT generator are not activated either.
 type DisableSwapStep struct {
 	step.Base
 	originalFstabContent string
+	maskedSwapTarget     bool
 }
 
 type DisableSwapStepBuilder struct {
@@ -29,7 +36,7 @@ func NewDisableSwapStepBuilder(ctx runtime.ExecutionContext, instanceName string
 
 	s.Base.Meta.Name = instanceName
 	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Disable swap", s.Base.Meta.Name)
-	s.Base.Sudo = false
+	s.Base.Sudo = true
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 1 * time.Minute
 
@@ -41,10 +48,30 @@ func (s *DisableSwapStep) Meta() *spec.StepMeta {
 	return &s.Base.Meta
 }
 
+func (s *DisableSwapStep) isSystemd(ctx runtime.ExecutionContext) bool {
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	return err == nil && facts.InitSystem != nil && facts.InitSystem.Type == runner.InitSystemSystemd
+}
+
+func (s *DisableSwapStep) readFstab(ctx runtime.ExecutionContext) (string, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", err
+	}
+	fstabBytes, err := ctx.GetRunner().ReadFile(ctx.GoContext(), conn, fstabPath)
+	if err != nil {
+		if os.IsNotExist(err) || strings.Contains(err.Error(), "No such file or directory") {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", fstabPath, err)
+	}
+	return string(fstabBytes), nil
+}
+
 func (s *DisableSwapStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
-	runner := ctx.GetRunner()
-	if !*ctx.GetClusterConfig().Spec.Preflight.DisableSwap {
+	runnerSvc := ctx.GetRunner()
+	if preflight := ctx.GetClusterConfig().Spec.Preflight; preflight != nil && preflight.DisableSwap != nil && !*preflight.DisableSwap {
 		return true, nil
 	}
 	conn, err := ctx.GetCurrentHostConnector()
@@ -52,100 +79,120 @@ func (s *DisableSwapStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, e
 		return false, err
 	}
 
-	runResult, _ := runner.Run(ctx.GoContext(), conn, "swapon --show", s.Sudo)
+	runResult, err := runnerSvc.Run(ctx.GoContext(), conn, "swapon --show", s.Sudo)
+	if err != nil {
+		return false, nil
+	}
+	if strings.TrimSpace(runResult.Stdout) != "" {
+		logger.Info("Swap is enabled and needs to be disabled.")
+		return false, nil
+	}
 
-	if strings.TrimSpace(runResult.Stdout) == "" {
-		logger.Info("Swap is already disabled.")
-		return true, nil
+	fstab, err := s.readFstab(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, changed := commentOutSwapEntries(fstab); changed {
+		logger.Info("Swap is off but still enabled in /etc/fstab.")
+		return false, nil
+	}
+	if s.isSystemd(ctx) {
+		res, _ := runnerSvc.Run(ctx.GoContext(), conn, "systemctl is-enabled swap.target", s.Sudo)
+		if res == nil || strings.TrimSpace(res.Stdout) != "masked" {
+			logger.Info("Swap is off but swap.target is not masked.")
+			return false, nil
+		}
 	}
 
-	logger.Info("Swap is enabled and needs to be disabled.")
-	return false, nil
+	logger.Info("Swap is already disabled.")
+	return true, nil
 }
 
 func (s *DisableSwapStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
 	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
 
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
-	runner := ctx.GetRunner()
+	runnerSvc := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
-		result.MarkFailed(err, "step failed"); return result, err
+		result.MarkFailed(err, "step failed")
+		return result, err
 	}
 
 	logger.Info("Turning off swap with 'swapoff -a'...")
-	if _, err := runner.Run(ctx.GoContext(), conn, "swapoff -a", s.Sudo); err != nil {
-		result.MarkFailed(err, "failed to execute 'swapoff -a'"); return result, err
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, "swapoff -a", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to execute 'swapoff -a'")
+		return result, err
 	}
 
-	fstabPath := "/etc/fstab"
-	logger.Info("Disabling swap permanently in /etc/fstab...")
-
-	fstabBytes, err := runner.ReadFile(ctx.GoContext(), conn, fstabPath)
+	fstab, err := s.readFstab(ctx)
 	if err != nil {
-		if os.IsNotExist(err) || strings.Contains(err.Error(), "No such file or directory") {
-			logger.Warn("/etc/fstab not found, skipping permanent disablement.")
-			s.originalFstabContent = ""
-	result.MarkCompleted("step completed successfully"); return result, nil
-		}
-		result.MarkFailed(err, "failed to read /etc/fstab"); return result, err
+		result.MarkFailed(err, "failed to read /etc/fstab")
+		return result, err
 	}
-	s.originalFstabContent = string(fstabBytes)
-
-	lines := strings.Split(s.originalFstabContent, "\n")
-	var newLines []string
-	changed := false
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if strings.Contains(trimmedLine, "swap") && !strings.HasPrefix(trimmedLine, "#") {
-			newLines = append(newLines, "#"+line)
-			changed = true
-			logger.Infof("Commenting out fstab line: '%s'", trimmedLine)
-		} else {
-			newLines = append(newLines, line)
+	if newFstab, changed := commentOutSwapEntries(fstab); changed {
+		s.originalFstabContent = fstab
+		if err := helpers.WriteContentToRemote(ctx, conn, newFstab, fstabPath, "0644", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to write updated /etc/fstab")
+			return result, err
 		}
+		logger.Info("/etc/fstab updated to disable swap on boot.")
 	}
 
-	if changed {
-		newFstabContent := strings.Join(newLines, "\n")
-		err = helpers.WriteContentToRemote(ctx, conn, newFstabContent, fstabPath, "0644", s.Sudo)
-		if err != nil {
-			result.MarkFailed(err, "failed to write updated /etc/fstab"); return result, err
+	if s.isSystemd(ctx) {
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, "systemctl mask swap.target", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to mask swap.target")
+			return result, err
 		}
-		logger.Info("/etc/fstab updated to disable swap on boot.")
-	} else {
-		logger.Info("/etc/fstab already has swap entries commented out. No changes made.")
+		s.maskedSwapTarget = true
 	}
 
 	logger.Info("Swap disabled successfully.")
-	result.MarkCompleted("step completed successfully"); return result, nil
+	result.MarkCompleted("step completed successfully")
+	return result, nil
 }
 
 func (s *DisableSwapStep) Rollback(ctx runtime.ExecutionContext) error {
 	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
-	runner := ctx.GetRunner()
+	runnerSvc := ctx.GetRunner()
 	conn, err := ctx.GetCurrentHostConnector()
 	if err != nil {
 		return err
 	}
 
-	if s.originalFstabContent == "" {
-		logger.Warn("No original /etc/fstab content was saved, skipping fstab rollback.")
-	} else {
+	if s.originalFstabContent != "" {
 		logger.Info("Restoring original /etc/fstab...")
-		err = helpers.WriteContentToRemote(ctx, conn, s.originalFstabContent, "/etc/fstab", "0644", s.Sudo)
-		if err != nil {
+		if err := helpers.WriteContentToRemote(ctx, conn, s.originalFstabContent, fstabPath, "0644", s.Sudo); err != nil {
 			return err
 		}
-		logger.Info("/etc/fstab restored.")
+	}
+	if s.maskedSwapTarget {
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, "systemctl unmask swap.target", s.Sudo); err != nil {
+			logger.Warnf("Failed to unmask swap.target during rollback. Error: %v", err)
+		}
 	}
 
 	logger.Info("Attempting to turn swap back on with 'swapon -a'...")
-	if _, err := runner.Run(ctx.GoContext(), conn, "swapon -a", s.Sudo); err != nil {
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, "swapon -a", s.Sudo); err != nil {
 		logger.Warnf("Command 'swapon -a' failed during rollback, this might be expected. Error: %v", err)
-	} else {
-		logger.Info("Swap has been re-enabled.")
 	}
-
 	return nil
 }
+
+// commentOutSwapEntries returns fstab with its active swap entries commented out, and whether
+// there were any. An entry is a swap entry when its filesystem type is swap.
+func commentOutSwapEntries(fstab string) (string, bool) {
+	lines := strings.Split(fstab, "\n")
+	changed := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if fields := strings.Fields(trimmed); len(fields) >= 3 && fields[2] == "swap" {
+			lines[i] = "#" + line
+			changed = true
+		}
+	}
+	return strings.Join(lines, "\n"), changed
+}
@@ -0,0 +1,31 @@
+package os
+
+import "testing"
+
+func TestCommentOutSwapEntries(t *testing.T) {
+	fstab := "UUID=abc / xfs defaults 0 0\n" +
+		"/dev/mapper/rl-swap none swap defaults 0 0\n" +
+		"#/swapfile none swap sw 0 0\n" +
+		"/srv/swapdata /data ext4 defaults 0 0\n"
+	want := "UUID=abc / xfs defaults 0 0\n" +
+		"#/dev/mapper/rl-swap none swap defaults 0 0\n" +
+		"#/swapfile none swap sw 0 0\n" +
+		"/srv/swapdata /data ext4 defaults 0 0\n"
+
+	got, changed := commentOutSwapEntries(fstab)
+	if !changed || got != want {
+		t.Errorf("commentOutSwapEntries() = %q, %v, want %q, true", got, changed, want)
+	}
+	if again, changed := commentOutSwapEntries(got); changed || again != want {
+		t.Errorf("commentOutSwapEntries() on a disabled fstab = %q, %v, want it unchanged", again, changed)
+	}
+}
+
+func TestIsUFWActive(t *testing.T) {
+	if !isUFWActive("Status: active\n\nTo Action From\n") {
+		t.Error("isUFWActive() = false for an active firewall")
+	}
+	if isUFWActive("Status: inactive\n") {
+		t.Error("isUFWActive() = true for an inactive firewall")
+	}
+}
@@ -0,0 +1,195 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const (
+	sshdConfigPath       = "/etc/ssh/sshd_config"
+	sshdConfigBackupPath = "/etc/ssh/sshd_config.kubexm.bak"
+	sshdReloadCmd        = "sh -c 'systemctl reload sshd || systemctl reload ssh'"
+)
+
+// HardenSSHStep disables root logins and password authentication in sshd. The settings are kept
+// in a block at the top of sshd_config, because sshd uses the first value it reads for a keyword
+// and they must win over the rest of the file and the drop-ins it includes.
+type HardenSSHStep struct {
+	step.Base
+	DisableRootLogin              bool
+	DisablePasswordAuthentication bool
+}
+
+type HardenSSHStepBuilder struct {
+	step.Builder[HardenSSHStepBuilder, *HardenSSHStep]
+}
+
+func NewHardenSSHStepBuilder(ctx runtime.ExecutionContext, instanceName string, disableRootLogin, disablePasswordAuthentication bool) *HardenSSHStepBuilder {
+	cs := &HardenSSHStep{
+		DisableRootLogin:              disableRootLogin,
+		DisablePasswordAuthentication: disablePasswordAuthentication,
+	}
+	cs.Base.Meta.Name = instanceName
+	cs.Base.Meta.Description = fmt.Sprintf("[%s]>>Harden sshd configuration", instanceName)
+	cs.Base.Sudo = true
+	cs.Base.IgnoreError = false
+	cs.Base.Timeout = 2 * time.Minute
+	return new(HardenSSHStepBuilder).Init(cs)
+}
+
+func (s *HardenSSHStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// directives returns the sshd settings of the managed block.
+func (s *HardenSSHStep) directives() []string {
+	var directives []string
+	if s.DisableRootLogin {
+		directives = append(directives, "PermitRootLogin no")
+	}
+	if s.DisablePasswordAuthentication {
+		directives = append(directives, "PasswordAuthentication no", "ChallengeResponseAuthentication no")
+	}
+	return directives
+}
+
+func (s *HardenSSHStep) readConfig(ctx runtime.ExecutionContext) (string, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", err
+	}
+	res, err := ctx.GetRunner().Run(ctx.GoContext(), conn, "cat "+sshdConfigPath, s.Sudo)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", sshdConfigPath, err)
+	}
+	return res.Stdout, nil
+}
+
+func (s *HardenSSHStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	current, err := s.readConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(renderSSHDConfig(current, s.directives())) == strings.TrimSpace(current) {
+		logger.Info("sshd is already configured.")
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *HardenSSHStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get host connector")
+		return result, err
+	}
+	current, err := s.readConfig(ctx)
+	if err != nil {
+		result.MarkFailed(err, "failed to read sshd configuration")
+		return result, err
+	}
+
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, fmt.Sprintf("cp -p %s %s", sshdConfigPath, sshdConfigBackupPath), s.Sudo); err != nil {
+		err = fmt.Errorf("failed to back up %s: %w", sshdConfigPath, err)
+		result.MarkFailed(err, "failed to back up sshd configuration")
+		return result, err
+	}
+	content := renderSSHDConfig(current, s.directives())
+	if err := runnerSvc.WriteFile(ctx.GoContext(), conn, []byte(content), sshdConfigPath, "0644", s.Sudo); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", sshdConfigPath, err)
+		result.MarkFailed(err, "failed to write sshd configuration")
+		return result, err
+	}
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, "sshd -t", s.Sudo); err != nil {
+		err = fmt.Errorf("sshd rejected the new configuration: %w", err)
+		if restoreErr := s.restore(ctx); restoreErr != nil {
+			logger.Error(restoreErr, "Failed to restore the sshd configuration.")
+		}
+		result.MarkFailed(err, "invalid sshd configuration")
+		return result, err
+	}
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, sshdReloadCmd, s.Sudo); err != nil {
+		err = fmt.Errorf("failed to reload sshd: %w", err)
+		result.MarkFailed(err, "failed to reload sshd")
+		return result, err
+	}
+
+	logger.Info("sshd hardened.", "settings", strings.Join(s.directives(), ", "))
+	result.MarkCompleted("step completed successfully")
+	return result, nil
+}
+
+// restore puts back the configuration saved by Run.
+func (s *HardenSSHStep) restore(ctx runtime.ExecutionContext) error {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	_, err = ctx.GetRunner().Run(ctx.GoContext(), conn, fmt.Sprintf("cp -p %s %s", sshdConfigBackupPath, sshdConfigPath), s.Sudo)
+	return err
+}
+
+func (s *HardenSSHStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		logger.Error(err, "Failed to get connector for rollback.")
+		return nil
+	}
+	if exists, _ := ctx.GetRunner().Exists(ctx.GoContext(), conn, sshdConfigBackupPath); !exists {
+		return nil
+	}
+	if err := s.restore(ctx); err != nil {
+		logger.Warn("Failed to restore the sshd configuration.", "error", err)
+		return nil
+	}
+	if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, sshdReloadCmd, s.Sudo); err != nil {
+		logger.Warn("Failed to reload sshd after restoring its configuration.", "error", err)
+	}
+	return nil
+}
+
+// renderSSHDConfig returns config with its kubexm block replaced by one holding directives at the
+// top of the file, or removed when there are none.
+func renderSSHDConfig(config string, directives []string) string {
+	var kept []string
+	inBlock := false
+	for _, line := range strings.SplitAfter(config, "\n") {
+		switch strings.TrimSpace(line) {
+		case common.BootstrapSSHDConfigBegin:
+			inBlock = true
+			continue
+		case common.BootstrapSSHDConfigEnd:
+			inBlock = false
+			continue
+		}
+		if !inBlock && line != "" {
+			kept = append(kept, line)
+		}
+	}
+
+	var b strings.Builder
+	if len(directives) > 0 {
+		b.WriteString(common.BootstrapSSHDConfigBegin + "\n")
+		for _, d := range directives {
+			b.WriteString(d + "\n")
+		}
+		b.WriteString(common.BootstrapSSHDConfigEnd + "\n")
+	}
+	b.WriteString(strings.Join(kept, ""))
+	return b.String()
+}
+
+var _ step.Step = (*HardenSSHStep)(nil)
@@ -0,0 +1,37 @@
+package os
+
+import (
+	"testing"
+)
+
+func TestRenderSSHDConfig(t *testing.T) {
+	original := "Include /etc/ssh/sshd_config.d/*.conf\nPermitRootLogin yes\n"
+	hardened := "# BEGIN kubexm managed settings\nPermitRootLogin no\nPasswordAuthentication no\n# END kubexm managed settings\n" + original
+
+	got := renderSSHDConfig(original, []string{"PermitRootLogin no", "PasswordAuthentication no"})
+	if got != hardened {
+		t.Errorf("renderSSHDConfig() = %q, want %q", got, hardened)
+	}
+	if again := renderSSHDConfig(got, []string{"PermitRootLogin no", "PasswordAuthentication no"}); again != hardened {
+		t.Errorf("rendering twice = %q, want %q", again, hardened)
+	}
+
+	want := "# BEGIN kubexm managed settings\nPermitRootLogin no\n# END kubexm managed settings\n" + original
+	if got := renderSSHDConfig(hardened, []string{"PermitRootLogin no"}); got != want {
+		t.Errorf("replacing the block = %q, want %q", got, want)
+	}
+	if got := renderSSHDConfig(hardened, nil); got != original {
+		t.Errorf("removing the block = %q, want %q", got, original)
+	}
+}
+
+func TestUserAuthorizedKeysInstallCmd(t *testing.T) {
+	got := userAuthorizedKeysInstallCmd("kubexm", "ssh-ed25519 AAAA kubexm")
+	want := `sh -c 'home=$(getent passwd kubexm | cut -d: -f6) && group=$(id -gn kubexm) && ` +
+		`mkdir -p "$home/.ssh" && touch "$home/.ssh/authorized_keys" && ` +
+		`(grep -qxF "ssh-ed25519 AAAA kubexm" "$home/.ssh/authorized_keys" || echo "ssh-ed25519 AAAA kubexm" >> "$home/.ssh/authorized_keys") && ` +
+		`chown -R kubexm:"$group" "$home/.ssh" && chmod 700 "$home/.ssh" && chmod 600 "$home/.ssh/authorized_keys"'`
+	if got != want {
+		t.Errorf("userAuthorizedKeysInstallCmd() = %s, want %s", got, want)
+	}
+}
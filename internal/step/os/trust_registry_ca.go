@@ -0,0 +1,294 @@
+package os
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// TrustRegistryCAStep installs the CA certificate of a private registry into the OS trust store and into
+// the certs.d directory of the container runtime. For containerd the registry's hosts.toml is also made
+// to reference the CA, so pulls succeed without restarting the runtime.
+type TrustRegistryCAStep struct {
+	step.Base
+	LocalCACertPath string
+	Registry        string
+	RuntimeType     common.ContainerRuntimeType
+	CertsDir        string
+}
+
+type TrustRegistryCAStepBuilder struct {
+	step.Builder[TrustRegistryCAStepBuilder, *TrustRegistryCAStep]
+}
+
+func NewTrustRegistryCAStepBuilder(ctx runtime.ExecutionContext, instanceName, localCACertPath, registry string) *TrustRegistryCAStepBuilder {
+	s := &TrustRegistryCAStep{
+		LocalCACertPath: localCACertPath,
+		Registry:        registryHost(registry),
+		RuntimeType:     common.RuntimeTypeContainerd,
+		CertsDir:        common.ContainerdDefaultCertsDir,
+	}
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg != nil && clusterCfg.Spec != nil &&
+		clusterCfg.Spec.Kubernetes != nil && clusterCfg.Spec.Kubernetes.ContainerRuntime != nil {
+		runtimeCfg := clusterCfg.Spec.Kubernetes.ContainerRuntime
+		if runtimeCfg.Type != "" {
+			s.RuntimeType = runtimeCfg.Type
+		}
+		if runtimeCfg.Containerd != nil && runtimeCfg.Containerd.Registry != nil && runtimeCfg.Containerd.Registry.ConfigPath != "" {
+			s.CertsDir = runtimeCfg.Containerd.Registry.ConfigPath
+		}
+	}
+	switch s.RuntimeType {
+	case common.RuntimeTypeDocker:
+		s.CertsDir = common.DockerDefaultCertsDir
+	case common.RuntimeTypeCRIO:
+		s.CertsDir = common.CRIODefaultCertsDir
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Trust the CA certificate of registry %s", s.Base.Meta.Name, s.Registry)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(TrustRegistryCAStepBuilder).Init(s)
+	return b
+}
+
+func (b *TrustRegistryCAStepBuilder) WithRuntimeType(runtimeType common.ContainerRuntimeType) *TrustRegistryCAStepBuilder {
+	b.Step.RuntimeType = runtimeType
+	return b
+}
+
+func (b *TrustRegistryCAStepBuilder) WithCertsDir(dir string) *TrustRegistryCAStepBuilder {
+	b.Step.CertsDir = dir
+	return b
+}
+
+func (s *TrustRegistryCAStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// caTrustStore returns the anchor directory and refresh command of the OS trust store on the host.
+func caTrustStore(facts *runner.Facts) (string, string, error) {
+	if facts == nil || facts.PackageManager == nil {
+		return "", "", fmt.Errorf("package manager facts are not available, cannot determine the CA trust store")
+	}
+	switch facts.PackageManager.Type {
+	case runner.PackageManagerApt:
+		return common.DebianCATrustDir, common.DebianCATrustUpdateCmd, nil
+	case runner.PackageManagerYum, runner.PackageManagerDnf:
+		return common.RHELCATrustDir, common.RHELCATrustUpdateCmd, nil
+	default:
+		return "", "", fmt.Errorf("unsupported package manager '%s' for CA trust store", facts.PackageManager.Type)
+	}
+}
+
+func (s *TrustRegistryCAStep) trustStoreCertPath(dir string) string {
+	name := strings.NewReplacer(":", "_", "/", "_").Replace(s.Registry)
+	return filepath.Join(dir, fmt.Sprintf("kubexm-registry-%s.crt", name))
+}
+
+func (s *TrustRegistryCAStep) runtimeCertPath() string {
+	return filepath.Join(s.CertsDir, s.Registry, common.RegistryCACertName)
+}
+
+func (s *TrustRegistryCAStep) hostsFilePath() string {
+	return filepath.Join(s.CertsDir, s.Registry, "hosts.toml")
+}
+
+// desiredFiles returns the remote files this step manages, keyed by path, and the trust store refresh command.
+func (s *TrustRegistryCAStep) desiredFiles(ctx runtime.ExecutionContext, caContent string) (map[string]string, string, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, "", err
+	}
+	runnerSvc := ctx.GetRunner()
+	facts, err := runnerSvc.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to gather facts: %w", err)
+	}
+	trustDir, updateCmd, err := caTrustStore(facts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	files := map[string]string{
+		s.trustStoreCertPath(trustDir): caContent,
+		s.runtimeCertPath():            caContent,
+	}
+	if s.RuntimeType == common.RuntimeTypeContainerd {
+		current, _ := runnerSvc.ReadFile(ctx.GoContext(), conn, s.hostsFilePath())
+		hosts, err := registryHostsWithCA(current, s.Registry, s.runtimeCertPath())
+		if err != nil {
+			return nil, "", err
+		}
+		files[s.hostsFilePath()] = hosts
+	}
+	return files, updateCmd, nil
+}
+
+func (s *TrustRegistryCAStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	caContent, err := os.ReadFile(s.LocalCACertPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read registry CA certificate '%s': %w", s.LocalCACertPath, err)
+	}
+	files, _, err := s.desiredFiles(ctx, string(caContent))
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	for path, content := range files {
+		current, err := ctx.GetRunner().ReadFile(ctx.GoContext(), conn, path)
+		if err != nil || string(current) != content {
+			logger.Info("Registry CA is not trusted yet. Step needs to run.", "path", path)
+			return false, nil
+		}
+	}
+	logger.Info("Registry CA is already trusted by the OS and the container runtime. Step is done.")
+	return true, nil
+}
+
+func (s *TrustRegistryCAStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+
+	caContent, err := os.ReadFile(s.LocalCACertPath)
+	if err != nil {
+		err = fmt.Errorf("failed to read registry CA certificate '%s': %w", s.LocalCACertPath, err)
+		result.MarkFailed(err, "failed to read registry CA certificate")
+		return result, err
+	}
+	files, updateCmd, err := s.desiredFiles(ctx, string(caContent))
+	if err != nil {
+		result.MarkFailed(err, "failed to determine registry CA locations")
+		return result, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		logger.Info("Writing registry CA file.", "path", path)
+		if err := runnerSvc.Mkdirp(ctx.GoContext(), conn, filepath.Dir(path), "0755", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to create registry CA directory")
+			return result, fmt.Errorf("failed to create directory for '%s': %w", path, err)
+		}
+		if err := helpers.WriteContentToRemote(ctx, conn, files[path], path, "0644", s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to write registry CA file")
+			return result, err
+		}
+	}
+
+	logger.Info("Refreshing the OS CA trust store.", "command", updateCmd)
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, updateCmd, s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to refresh the CA trust store")
+		return result, fmt.Errorf("failed to run '%s': %w", updateCmd, err)
+	}
+
+	result.SetMetadata("registry", s.Registry)
+	result.SetMetadata("files", paths)
+	result.MarkCompleted(fmt.Sprintf("CA of registry %s trusted", s.Registry))
+	return result, nil
+}
+
+func (s *TrustRegistryCAStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+	facts, err := runnerSvc.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		logger.Warnf("Failed to gather facts during rollback: %v", err)
+		return nil
+	}
+	trustDir, updateCmd, err := caTrustStore(facts)
+	if err != nil {
+		logger.Warnf("Skipping trust store rollback: %v", err)
+		return nil
+	}
+
+	for _, path := range []string{s.trustStoreCertPath(trustDir), s.runtimeCertPath()} {
+		if err := runnerSvc.Remove(ctx.GoContext(), conn, path, s.Sudo, false); err != nil {
+			logger.Errorf("Failed to remove '%s' during rollback: %v", path, err)
+		}
+	}
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, updateCmd, s.Sudo); err != nil {
+		logger.Errorf("Failed to refresh the CA trust store during rollback: %v", err)
+	}
+	return nil
+}
+
+// registryHostsWithCA returns the hosts.toml of registry with caPath set as its CA. An existing file keeps
+// its hosts and only gains the ca entries; without one, a file for the registry itself is rendered.
+func registryHostsWithCA(current []byte, registry, caPath string) (string, error) {
+	if strings.TrimSpace(string(current)) == "" {
+		server := "https://" + registry
+		return fmt.Sprintf("server = %q\nca = %q\n\n[host.%q]\n  capabilities = [\"pull\", \"resolve\", \"push\"]\n  ca = %q\n",
+			server, caPath, server, caPath), nil
+	}
+
+	cfg := make(map[string]interface{})
+	if err := toml.Unmarshal(current, &cfg); err != nil {
+		return "", fmt.Errorf("failed to parse registry hosts file: %w", err)
+	}
+	if cfg["ca"] == caPath {
+		return string(current), nil
+	}
+	cfg["ca"] = caPath
+	if hosts, ok := cfg["host"].(map[string]interface{}); ok {
+		for _, entry := range hosts {
+			if table, ok := entry.(map[string]interface{}); ok {
+				if _, hasCA := table["ca"]; !hasCA {
+					table["ca"] = caPath
+				}
+			}
+		}
+	}
+	out, err := toml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry hosts file: %w", err)
+	}
+	return string(out), nil
+}
+
+// registryHost strips the scheme and path from a registry reference, keeping host[:port].
+func registryHost(registry string) string {
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	if u, err := url.Parse(registry); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return registry
+}
+
+var _ step.Step = (*TrustRegistryCAStep)(nil)
@@ -0,0 +1,184 @@
+package os
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const testRegistryCA = "-----BEGIN CERTIFICATE-----\nMIIBtest\n-----END CERTIFICATE-----\n"
+
+type fakeTrustCARunner struct {
+	runner.Runner
+	packageManager runner.PackageManagerType
+	files          map[string]string
+	uploaded       string
+	commands       []string
+}
+
+func (r *fakeTrustCARunner) GatherFacts(ctx context.Context, conn connector.Connector) (*runner.Facts, error) {
+	return &runner.Facts{PackageManager: &runner.PackageInfo{Type: r.packageManager}}, nil
+}
+
+func (r *fakeTrustCARunner) ReadFile(ctx context.Context, conn connector.Connector, path string) ([]byte, error) {
+	content, ok := r.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (r *fakeTrustCARunner) Mkdirp(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeTrustCARunner) Upload(ctx context.Context, conn connector.Connector, srcPath, destPath string, sudo bool) error {
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	r.uploaded = string(content)
+	return nil
+}
+
+func (r *fakeTrustCARunner) Move(ctx context.Context, conn connector.Connector, src, dest string, sudo bool) error {
+	r.files[dest] = r.uploaded
+	return nil
+}
+
+func (r *fakeTrustCARunner) Chmod(ctx context.Context, conn connector.Connector, path, permissions string, sudo bool) error {
+	return nil
+}
+
+func (r *fakeTrustCARunner) Remove(ctx context.Context, conn connector.Connector, path string, sudo bool, recursive bool) error {
+	return nil
+}
+
+func (r *fakeTrustCARunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	r.commands = append(r.commands, cmd)
+	return &runner.CommandResult{}, nil
+}
+
+func TestTrustRegistryCAStep(t *testing.T) {
+	tests := []struct {
+		name              string
+		packageManager    runner.PackageManagerType
+		runtimeType       common.ContainerRuntimeType
+		existingHosts     string
+		expectedTrustPath string
+		expectedCommand   string
+		expectedRuntimeCA string
+	}{
+		{
+			name:              "debian family with containerd",
+			packageManager:    runner.PackageManagerApt,
+			runtimeType:       common.RuntimeTypeContainerd,
+			expectedTrustPath: "/usr/local/share/ca-certificates/kubexm-registry-registry.local_5000.crt",
+			expectedCommand:   "update-ca-certificates",
+			expectedRuntimeCA: "/etc/containerd/certs.d/registry.local:5000/ca.crt",
+		},
+		{
+			name:           "rhel family with an existing containerd hosts file",
+			packageManager: runner.PackageManagerDnf,
+			runtimeType:    common.RuntimeTypeContainerd,
+			existingHosts: `server = "https://registry.local:5000"
+
+[host."https://mirror.local"]
+  capabilities = ["pull", "resolve"]
+`,
+			expectedTrustPath: "/etc/pki/ca-trust/source/anchors/kubexm-registry-registry.local_5000.crt",
+			expectedCommand:   "update-ca-trust extract",
+			expectedRuntimeCA: "/etc/containerd/certs.d/registry.local:5000/ca.crt",
+		},
+		{
+			name:              "rhel family with docker",
+			packageManager:    runner.PackageManagerYum,
+			runtimeType:       common.RuntimeTypeDocker,
+			expectedTrustPath: "/etc/pki/ca-trust/source/anchors/kubexm-registry-registry.local_5000.crt",
+			expectedCommand:   "update-ca-trust extract",
+			expectedRuntimeCA: "/etc/docker/certs.d/registry.local:5000/ca.crt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caPath := filepath.Join(t.TempDir(), "ca.crt")
+			if err := os.WriteFile(caPath, []byte(testRegistryCA), 0644); err != nil {
+				t.Fatal(err)
+			}
+			hostsPath := "/etc/containerd/certs.d/registry.local:5000/hosts.toml"
+			r := &fakeTrustCARunner{packageManager: tt.packageManager, files: map[string]string{}}
+			if tt.existingHosts != "" {
+				r.files[hostsPath] = tt.existingHosts
+			}
+			ctx := &runtimetest.Context{
+				Runner:  r,
+				Host:    connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+				WorkDir: t.TempDir(),
+				Cluster: &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Kubernetes: &v1alpha1.Kubernetes{
+					ContainerRuntime: &v1alpha1.ContainerRuntime{Type: tt.runtimeType},
+				}}},
+			}
+
+			s, err := NewTrustRegistryCAStepBuilder(ctx, "TrustRegistryCA", caPath, "https://registry.local:5000").Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+			result, err := s.Run(ctx)
+			if err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+			if result.Status != types.StepStatusCompleted {
+				t.Fatalf("expected step to complete, got %q", result.Status)
+			}
+
+			if r.files[tt.expectedTrustPath] != testRegistryCA {
+				t.Errorf("expected CA in trust store at %s, files: %v", tt.expectedTrustPath, r.files)
+			}
+			if len(r.commands) != 1 || r.commands[0] != tt.expectedCommand {
+				t.Errorf("expected trust store refresh %q, got %v", tt.expectedCommand, r.commands)
+			}
+			if r.files[tt.expectedRuntimeCA] != testRegistryCA {
+				t.Errorf("expected CA for the runtime at %s, files: %v", tt.expectedRuntimeCA, r.files)
+			}
+
+			hosts, hasHosts := r.files[hostsPath]
+			if tt.runtimeType != common.RuntimeTypeContainerd {
+				if hasHosts {
+					t.Errorf("did not expect a containerd hosts file for %s", tt.runtimeType)
+				}
+				return
+			}
+			cfg := make(map[string]interface{})
+			if err := toml.Unmarshal([]byte(hosts), &cfg); err != nil {
+				t.Fatalf("hosts file is not valid TOML: %v\n%s", err, hosts)
+			}
+			if cfg["ca"] != tt.expectedRuntimeCA {
+				t.Errorf("expected hosts file to reference %s, got:\n%s", tt.expectedRuntimeCA, hosts)
+			}
+			for name, entry := range cfg["host"].(map[string]interface{}) {
+				if entry.(map[string]interface{})["ca"] != tt.expectedRuntimeCA {
+					t.Errorf("expected host %s to reference the CA, got:\n%s", name, hosts)
+				}
+			}
+			if tt.existingHosts != "" && !strings.Contains(hosts, "mirror.local") {
+				t.Errorf("expected existing hosts to be kept, got:\n%s", hosts)
+			}
+
+			done, err := s.Precheck(ctx)
+			if err != nil || !done {
+				t.Errorf("expected precheck to report done after run, got done=%v err=%v", done, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util"
+)
+
+// CollectCertExpirationStep reads every certificate below Dirs and the client certificates
+// embedded in Kubeconfigs on the node and stores their expiration in the pipeline cache under
+// common.CacheKeyHostCertExpiration. Kubeconfigs missing on the node are ignored.
+type CollectCertExpirationStep struct {
+	step.Base
+	Dirs        []string
+	Kubeconfigs []string
+}
+
+type CollectCertExpirationStepBuilder struct {
+	step.Builder[CollectCertExpirationStepBuilder, *CollectCertExpirationStep]
+}
+
+func NewCollectCertExpirationStepBuilder(ctx runtime.ExecutionContext, instanceName string, dirs []string) *CollectCertExpirationStepBuilder {
+	s := &CollectCertExpirationStep{
+		Dirs: dirs,
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Collect certificate expiration from %v", instanceName, dirs)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+	return new(CollectCertExpirationStepBuilder).Init(s)
+}
+
+func (b *CollectCertExpirationStepBuilder) WithKubeconfigs(paths []string) *CollectCertExpirationStepBuilder {
+	b.Step.Kubeconfigs = paths
+	return b
+}
+
+func (s *CollectCertExpirationStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CollectCertExpirationStep) Precheck(ctx runtime.ExecutionContext) (bool, error) {
+	return false, nil
+}
+
+func (s *CollectCertExpirationStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get host connector")
+		return result, err
+	}
+
+	// sudo only applies to the first command of a pipeline, so the whole listing runs in one shell.
+	cmd := "sh -c " + util.ShellEscape(listCertsScript(s.Dirs, s.Kubeconfigs))
+	stdout, _, err := ctx.GetRunner().OriginRun(ctx.GoContext(), conn, cmd, s.Base.Sudo)
+	if err != nil {
+		err = fmt.Errorf("failed to read certificates on host %s: %w", ctx.GetHost().GetName(), err)
+		result.MarkFailed(err, "failed to read certificates")
+		return result, err
+	}
+
+	certs := ParseCertListing(ctx.GetHost().GetName(), []byte(stdout), time.Now())
+	ctx.GetPipelineCache().Set(fmt.Sprintf(common.CacheKeyHostCertExpiration, ctx.GetRunID(), ctx.GetHost().GetName()), certs)
+	logger.Infof("Collected expiration of %d certificate(s).", len(certs))
+
+	result.SetMetadata("certificateCount", len(certs))
+	result.MarkCompleted("certificate expiration collected")
+	return result, nil
+}
+
+func (s *CollectCertExpirationStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CollectCertExpirationStep)(nil)
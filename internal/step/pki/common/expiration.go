@@ -0,0 +1,158 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// certListingMarker prefixes the path of every certificate file in the output of listCertsScript.
+const certListingMarker = "==> "
+
+// CertExpiration describes one certificate found on a node.
+type CertExpiration struct {
+	Host      string        `json:"host"`
+	Path      string        `json:"path"`
+	Subject   string        `json:"subject"`
+	Issuer    string        `json:"issuer"`
+	NotAfter  time.Time     `json:"notAfter"`
+	ExpiresIn time.Duration `json:"expiresIn"`
+	IsCA      bool          `json:"isCA"`
+	ParseErr  string        `json:"parseError,omitempty"`
+}
+
+// Expired reports whether the certificate is no longer valid or could not be read.
+func (c CertExpiration) Expired() bool {
+	return c.ParseErr != "" || c.ExpiresIn <= 0
+}
+
+// ParseCertListing parses the output of listCertsScript, a "==> <path>" line followed by the file
+// content for every certificate file. Only the first certificate of a bundle is reported. Kubeconfig
+// files (*.conf) report the client certificate embedded for each of their users.
+func ParseCertListing(host string, output []byte, now time.Time) []CertExpiration {
+	var (
+		certs   []CertExpiration
+		path    string
+		content bytes.Buffer
+	)
+	flush := func() {
+		if path == "" {
+			return
+		}
+		if filepath.Ext(path) == ".conf" {
+			certs = append(certs, parseKubeconfigCerts(host, path, content.Bytes(), now)...)
+			return
+		}
+		entry := CertExpiration{Host: host, Path: path}
+		block, _ := pem.Decode(content.Bytes())
+		if block == nil || block.Type != "CERTIFICATE" {
+			// Key files and other PEM content that happen to match the file name patterns are skipped.
+			if block == nil {
+				entry.ParseErr = "no PEM certificate found"
+				certs = append(certs, entry)
+			}
+			return
+		}
+		certs = append(certs, fillCertExpiration(entry, block.Bytes, now))
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, certListingMarker) {
+			flush()
+			path = strings.TrimSpace(strings.TrimPrefix(line, certListingMarker))
+			content.Reset()
+			continue
+		}
+		content.WriteString(line)
+		content.WriteByte('\n')
+	}
+	flush()
+	return certs
+}
+
+// parseKubeconfigCerts reports the client-certificate-data of every user in a kubeconfig. Users
+// that reference a certificate file instead, like the rotating kubelet client, are skipped. The
+// user name is appended to the path when the kubeconfig has more than one.
+func parseKubeconfigCerts(host, path string, data []byte, now time.Time) []CertExpiration {
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return []CertExpiration{{Host: host, Path: path, ParseErr: err.Error()}}
+	}
+	users := make([]string, 0, len(config.AuthInfos))
+	for name, authInfo := range config.AuthInfos {
+		if authInfo != nil && len(authInfo.ClientCertificateData) > 0 {
+			users = append(users, name)
+		}
+	}
+	sort.Strings(users)
+
+	var certs []CertExpiration
+	for _, name := range users {
+		entry := CertExpiration{Host: host, Path: path}
+		if len(config.AuthInfos) > 1 {
+			entry.Path = path + "#" + name
+		}
+		block, _ := pem.Decode(config.AuthInfos[name].ClientCertificateData)
+		if block == nil || block.Type != "CERTIFICATE" {
+			entry.ParseErr = "no PEM certificate found in client-certificate-data"
+			certs = append(certs, entry)
+			continue
+		}
+		certs = append(certs, fillCertExpiration(entry, block.Bytes, now))
+	}
+	return certs
+}
+
+// fillCertExpiration sets the certificate fields of entry from the DER certificate der.
+func fillCertExpiration(entry CertExpiration, der []byte, now time.Time) CertExpiration {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		entry.ParseErr = err.Error()
+		return entry
+	}
+	entry.Subject = cert.Subject.CommonName
+	entry.Issuer = cert.Issuer.CommonName
+	entry.NotAfter = cert.NotAfter
+	entry.IsCA = cert.IsCA
+	entry.ExpiresIn = cert.NotAfter.Sub(now)
+	return entry
+}
+
+// SortCertExpirations orders certificates by host and then by the time left until they expire.
+func SortCertExpirations(certs []CertExpiration) {
+	sort.SliceStable(certs, func(i, j int) bool {
+		if certs[i].Host != certs[j].Host {
+			return certs[i].Host < certs[j].Host
+		}
+		if certs[i].Expired() != certs[j].Expired() {
+			return certs[i].Expired()
+		}
+		if !certs[i].NotAfter.Equal(certs[j].NotAfter) {
+			return certs[i].NotAfter.Before(certs[j].NotAfter)
+		}
+		return certs[i].Path < certs[j].Path
+	})
+}
+
+// listCertsScript prints every certificate file below dirs, skipping private keys, followed by
+// the kubeconfigs that exist on the node.
+func listCertsScript(dirs, kubeconfigs []string) string {
+	script := "find " + strings.Join(dirs, " ") +
+		` -type f \( -name '*.crt' -o -name '*.pem' \) ! -name '*key*' 2>/dev/null | sort | ` +
+		`while read -r f; do echo "` + certListingMarker + `$f"; cat "$f"; done`
+	if len(kubeconfigs) > 0 {
+		script += "; for f in " + strings.Join(kubeconfigs, " ") +
+			`; do [ -f "$f" ] && { echo "` + certListingMarker + `$f"; cat "$f"; }; done; true`
+	}
+	return script
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCertPEM(t *testing.T, cn string, notAfter time.Time, isCA bool) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestParseCertListing(t *testing.T) {
+	now := time.Now()
+	listing := strings.Join([]string{
+		"==> /etc/kubernetes/pki/apiserver.crt",
+		testCertPEM(t, "kube-apiserver", now.Add(10*24*time.Hour), false),
+		"==> /etc/kubernetes/pki/ca.crt",
+		testCertPEM(t, "kubernetes", now.Add(3650*24*time.Hour), true),
+		"==> /etc/kubernetes/pki/sa.pem",
+		"-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE\n-----END PUBLIC KEY-----",
+		"==> /etc/kubernetes/pki/broken.crt",
+		"not a certificate",
+		"==> /etc/kubernetes/pki/front-proxy-client.crt",
+		testCertPEM(t, "front-proxy-client", now.Add(-time.Hour), false),
+	}, "\n")
+
+	certs := ParseCertListing("master-1", []byte(listing), now)
+	if len(certs) != 4 {
+		t.Fatalf("expected 4 certificates, got %d: %+v", len(certs), certs)
+	}
+	for _, c := range certs {
+		if c.Host != "master-1" {
+			t.Errorf("expected host master-1, got %q", c.Host)
+		}
+	}
+	if certs[0].Subject != "kube-apiserver" || certs[0].Expired() || certs[0].ExpiresIn <= 9*24*time.Hour {
+		t.Errorf("unexpected apiserver entry: %+v", certs[0])
+	}
+	if !certs[1].IsCA {
+		t.Errorf("expected ca.crt to be a CA: %+v", certs[1])
+	}
+	if certs[2].Path != "/etc/kubernetes/pki/broken.crt" || certs[2].ParseErr == "" || !certs[2].Expired() {
+		t.Errorf("expected broken.crt to be reported as unreadable: %+v", certs[2])
+	}
+	if !certs[3].Expired() {
+		t.Errorf("expected front-proxy-client.crt to be expired: %+v", certs[3])
+	}
+
+	SortCertExpirations(certs)
+	var order []string
+	for _, c := range certs {
+		order = append(order, c.Path[strings.LastIndex(c.Path, "/")+1:])
+	}
+	want := "broken.crt,front-proxy-client.crt,apiserver.crt,ca.crt"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("expected order %s, got %s", want, got)
+	}
+}
+
+func TestParseCertListing_Kubeconfig(t *testing.T) {
+	now := time.Now()
+	certData := base64.StdEncoding.EncodeToString([]byte(testCertPEM(t, "kubernetes-admin", now.Add(-time.Hour), false)))
+	listing := strings.Join([]string{
+		"==> /etc/kubernetes/admin.conf",
+		"apiVersion: v1",
+		"kind: Config",
+		"users:",
+		"- name: kubernetes-admin",
+		"  user:",
+		"    client-certificate-data: " + certData,
+		"    client-key-data: a2V5",
+		"==> /etc/kubernetes/kubelet.conf",
+		"apiVersion: v1",
+		"kind: Config",
+		"users:",
+		"- name: default-auth",
+		"  user:",
+		"    client-certificate: /var/lib/kubelet/pki/kubelet-client-current.pem",
+	}, "\n")
+
+	certs := ParseCertListing("master-1", []byte(listing), now)
+	if len(certs) != 1 {
+		t.Fatalf("expected only the embedded client certificate, got %d: %+v", len(certs), certs)
+	}
+	if certs[0].Path != "/etc/kubernetes/admin.conf" || certs[0].Subject != "kubernetes-admin" || !certs[0].Expired() {
+		t.Errorf("unexpected admin.conf entry: %+v", certs[0])
+	}
+}
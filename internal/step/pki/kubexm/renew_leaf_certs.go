@@ -307,7 +307,7 @@ func (s *BinaryRenewAllLeafCertsStep) getAPIServerAltNames(ctx runtime.Execution
 		IPs: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("0:0:0:0:0:0:0:1")},
 	}
 
-	_, serviceNet, err := net.ParseCIDR(s.ClusterSpec.Network.KubeServiceCIDR)
+	_, serviceNet, err := net.ParseCIDR(helpers.PrimaryCIDR(s.ClusterSpec.Network.KubeServiceCIDR))
 	if err != nil {
 		return nil, fmt.Errorf("invalid service CIDR '%s': %w", s.ClusterSpec.Network.KubeServiceCIDR, err)
 	}
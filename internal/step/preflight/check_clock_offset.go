@@ -0,0 +1,186 @@
+package preflight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckClockOffsetStep measures the offset between the clock of a host and its NTP source, as
+// reported by chrony or systemd-timesyncd, and fails when it exceeds spec.system.timeSync.maxOffset.
+// Hosts that report no NTP source are compared with the machine running kubexm instead, which only
+// warns: that clock is not necessarily synchronized itself.
+type CheckClockOffsetStep struct {
+	step.Base
+	MaxOffset time.Duration
+}
+
+type CheckClockOffsetStepBuilder struct {
+	step.Builder[CheckClockOffsetStepBuilder, *CheckClockOffsetStep]
+}
+
+func NewCheckClockOffsetStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckClockOffsetStepBuilder {
+	s := &CheckClockOffsetStep{
+		MaxOffset: common.DefaultMaxClockOffset,
+	}
+	if system := ctx.GetClusterConfig().Spec.System; system != nil && system.TimeSync != nil && system.TimeSync.MaxOffset > 0 {
+		s.MaxOffset = system.TimeSync.MaxOffset
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("Check that the clock offset of the node is under %s", s.MaxOffset)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 1 * time.Minute
+
+	b := new(CheckClockOffsetStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckClockOffsetStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckClockOffsetStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CheckClockOffsetStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "Failed to get host connector")
+		return result, err
+	}
+
+	if offset, source, ok := s.ntpOffset(ctx); ok {
+		logger.Info("Clock offset of the host.", "offset", offset.String(), "source", source)
+		if offset.Abs() > s.MaxOffset {
+			err = fmt.Errorf("the clock of host %s is off by %s from its NTP source according to %s, more than the allowed %s",
+				ctx.GetHost().GetName(), offset, source, s.MaxOffset)
+			result.MarkFailed(err, "Clock offset too large")
+			return result, err
+		}
+		result.MarkCompleted(fmt.Sprintf("Clock offset %s from the NTP source", offset))
+		return result, nil
+	}
+
+	before := time.Now()
+	runResult, err := runner.Run(ctx.GoContext(), conn, "date +%s.%N", s.Sudo)
+	after := time.Now()
+	if err != nil {
+		err = fmt.Errorf("failed to read the clock of the host: %w", err)
+		result.MarkFailed(err, "Failed to read host clock")
+		return result, err
+	}
+	offset, uncertainty, err := clockOffset(before, after, runResult.Stdout)
+	if err != nil {
+		result.MarkFailed(err, "Failed to parse host clock")
+		return result, err
+	}
+
+	logger.Info("Clock offset of the host from the machine running kubexm.", "offset", offset.String(), "uncertainty", uncertainty.String())
+	if offset.Abs()-uncertainty > s.MaxOffset {
+		logger.Warn("The host reports no NTP source and its clock differs from the machine running kubexm; sync the clocks of the hosts with NTP.",
+			"offset", offset.String(), "uncertainty", uncertainty.String(), "maxOffset", s.MaxOffset.String())
+	}
+	result.MarkCompleted(fmt.Sprintf("No NTP source, clock offset %s (±%s) from the machine running kubexm", offset, uncertainty))
+	return result, nil
+}
+
+// ntpOffset returns the offset of the host clock from its NTP source and the daemon reporting it.
+// It reports false when neither chrony nor systemd-timesyncd is tracking a source.
+func (s *CheckClockOffsetStep) ntpOffset(ctx runtime.ExecutionContext) (time.Duration, string, bool) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return 0, "", false
+	}
+	if runResult, err := runner.Run(ctx.GoContext(), conn, "chronyc tracking", s.Sudo); err == nil {
+		if offset, ok := parseChronyOffset(runResult.Stdout); ok {
+			return offset, "chrony", true
+		}
+	}
+	if runResult, err := runner.Run(ctx.GoContext(), conn, "timedatectl timesync-status", s.Sudo); err == nil {
+		if offset, ok := parseTimesyncOffset(runResult.Stdout); ok {
+			return offset, "systemd-timesyncd", true
+		}
+	}
+	return 0, "", false
+}
+
+// parseChronyOffset reads the "System time" line of "chronyc tracking", such as
+// "System time     : 0.000004130 seconds slow of NTP time". A fast clock has a positive offset.
+func parseChronyOffset(out string) (time.Duration, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "System time" {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) < 3 {
+			return 0, false
+		}
+		seconds, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, false
+		}
+		offset := time.Duration(seconds * float64(time.Second)).Round(time.Microsecond)
+		if fields[2] == "slow" {
+			offset = -offset
+		}
+		return offset, true
+	}
+	return 0, false
+}
+
+// parseTimesyncOffset reads the "Offset" line of "timedatectl timesync-status", such as
+// "      Offset: -1.139ms", which is only present once a server has answered.
+func parseTimesyncOffset(out string) (time.Duration, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "Offset" {
+			continue
+		}
+		offset, err := time.ParseDuration(strings.TrimSpace(value))
+		return offset, err == nil
+	}
+	return 0, false
+}
+
+// clockOffset returns the offset of the remote clock, read as seconds since the epoch between
+// before and after, and the uncertainty of that offset, half of the round trip.
+func clockOffset(before, after time.Time, remote string) (time.Duration, time.Duration, error) {
+	remote = strings.TrimSpace(remote)
+	secPart, nsecPart, _ := strings.Cut(remote, ".")
+	sec, err := strconv.ParseInt(secPart, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected output of 'date': %q", remote)
+	}
+	var nsec int64
+	if nsecPart != "" {
+		nsecPart = (nsecPart + "000000000")[:9]
+		if nsec, err = strconv.ParseInt(nsecPart, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("unexpected output of 'date': %q", remote)
+		}
+	}
+	roundTrip := after.Sub(before)
+	midpoint := before.Add(roundTrip / 2)
+	offset := time.Unix(sec, nsec).Sub(midpoint).Round(time.Millisecond)
+	return offset, (roundTrip / 2).Round(time.Millisecond), nil
+}
+
+func (s *CheckClockOffsetStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+var _ step.Step = (*CheckClockOffsetStep)(nil)
@@ -0,0 +1,61 @@
+package preflight
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockOffset(t *testing.T) {
+	before := time.Unix(1700000000, 0)
+	after := before.Add(200 * time.Millisecond)
+
+	tests := []struct {
+		remote      string
+		offset      time.Duration
+		expectError bool
+	}{
+		{remote: "1700000000.100000000\n", offset: 0},
+		{remote: "1700000002.350000000\n", offset: 2250 * time.Millisecond},
+		{remote: "1699999999.6\n", offset: -500 * time.Millisecond},
+		{remote: "1700000001", offset: 900 * time.Millisecond},
+		{remote: "1700000000.%N", expectError: true},
+		{remote: "", expectError: true},
+	}
+	for _, tt := range tests {
+		offset, uncertainty, err := clockOffset(before, after, tt.remote)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("clockOffset(%q) expected an error", tt.remote)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("clockOffset(%q) unexpected error: %v", tt.remote, err)
+			continue
+		}
+		if offset != tt.offset || uncertainty != 100*time.Millisecond {
+			t.Errorf("clockOffset(%q) = %s ± %s, want %s ± 100ms", tt.remote, offset, uncertainty, tt.offset)
+		}
+	}
+}
+
+func TestParseNTPOffset(t *testing.T) {
+	chrony := "Reference ID    : C0A80101 (ntp1)\nStratum         : 3\nSystem time     : 0.000004130 seconds slow of NTP time\nLast offset     : -0.000001234 seconds\n"
+	if offset, ok := parseChronyOffset(chrony); !ok || offset != -4*time.Microsecond {
+		t.Errorf("parseChronyOffset() = %s, %v, want -4µs", offset, ok)
+	}
+	if offset, ok := parseChronyOffset("System time     : 0.750000000 seconds fast of NTP time"); !ok || offset != 750*time.Millisecond {
+		t.Errorf("parseChronyOffset() = %s, %v, want 750ms", offset, ok)
+	}
+	if _, ok := parseChronyOffset("506 Cannot talk to daemon"); ok {
+		t.Error("parseChronyOffset() expected no offset without chronyd")
+	}
+
+	timesync := "       Server: 192.168.1.1 (ntp1)\nPoll interval: 34min 8s (min: 32s; max 34min 8s)\n       Offset: -1.139ms\n        Delay: 296us\n"
+	if offset, ok := parseTimesyncOffset(timesync); !ok || offset != -1139*time.Microsecond {
+		t.Errorf("parseTimesyncOffset() = %s, %v, want -1.139ms", offset, ok)
+	}
+	if _, ok := parseTimesyncOffset("       Server: n/a\nPoll interval: 0 (min: 32s; max 34min 8s)\n"); ok {
+		t.Error("parseTimesyncOffset() expected no offset before a server answered")
+	}
+}
@@ -0,0 +1,198 @@
+package preflight
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// CheckExternalEtcdConnectivityStep verifies that every endpoint of the external etcd cluster
+// accepts TCP connections from the node.
+type CheckExternalEtcdConnectivityStep struct {
+	step.Base
+}
+
+type CheckExternalEtcdConnectivityStepBuilder struct {
+	step.Builder[CheckExternalEtcdConnectivityStepBuilder, *CheckExternalEtcdConnectivityStep]
+}
+
+func NewCheckExternalEtcdConnectivityStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckExternalEtcdConnectivityStepBuilder {
+	s := &CheckExternalEtcdConnectivityStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = "Check that the external etcd endpoints are reachable"
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 2 * time.Minute
+
+	b := new(CheckExternalEtcdConnectivityStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckExternalEtcdConnectivityStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckExternalEtcdConnectivityStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CheckExternalEtcdConnectivityStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+
+	ext := externalEtcdConfig(ctx.GetClusterConfig().Spec)
+	if ext == nil {
+		result.MarkCompleted("etcd is not external, nothing to check")
+		return result, nil
+	}
+
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "Failed to get host connector")
+		return result, err
+	}
+
+	var failed []string
+	for _, endpoint := range ext.Endpoints {
+		host, port, err := parseExternalEtcdEndpoint(endpoint)
+		if err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		cmd := fmt.Sprintf("nc -z -w 3 %s %d", host, port)
+		if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+			failed = append(failed, fmt.Sprintf("cannot connect from %s to external etcd endpoint %s", ctx.GetHost().GetName(), endpoint))
+			continue
+		}
+		logger.Infof("External etcd endpoint %s is reachable.", endpoint)
+	}
+
+	if len(failed) > 0 {
+		err = fmt.Errorf("external etcd connectivity check failed: %s", strings.Join(failed, "; "))
+		result.MarkFailed(err, "External etcd is not reachable")
+		return result, err
+	}
+	result.MarkCompleted("All external etcd endpoints are reachable")
+	return result, nil
+}
+
+func (s *CheckExternalEtcdConnectivityStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+// CheckExternalEtcdCertsStep runs on the control machine and verifies that the configured external
+// etcd CA, client certificate and key can be loaded before they are copied to the masters.
+type CheckExternalEtcdCertsStep struct {
+	step.Base
+}
+
+type CheckExternalEtcdCertsStepBuilder struct {
+	step.Builder[CheckExternalEtcdCertsStepBuilder, *CheckExternalEtcdCertsStep]
+}
+
+func NewCheckExternalEtcdCertsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckExternalEtcdCertsStepBuilder {
+	s := &CheckExternalEtcdCertsStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = "Check the external etcd client certificates"
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 1 * time.Minute
+
+	b := new(CheckExternalEtcdCertsStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckExternalEtcdCertsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckExternalEtcdCertsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CheckExternalEtcdCertsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+
+	ext := externalEtcdConfig(ctx.GetClusterConfig().Spec)
+	if ext == nil {
+		result.MarkCompleted("etcd is not external, nothing to check")
+		return result, nil
+	}
+	if err := checkExternalEtcdCerts(ext); err != nil {
+		result.MarkFailed(err, "Invalid external etcd certificates")
+		return result, err
+	}
+	result.MarkCompleted("External etcd certificates are valid")
+	return result, nil
+}
+
+func (s *CheckExternalEtcdCertsStep) Rollback(ctx runtime.ExecutionContext) error {
+	return nil
+}
+
+func externalEtcdConfig(clusterSpec *v1alpha1.ClusterSpec) *v1alpha1.ExternalEtcdConfig {
+	if clusterSpec == nil || clusterSpec.Etcd == nil || clusterSpec.Etcd.Type != string(common.EtcdDeploymentTypeExternal) {
+		return nil
+	}
+	return clusterSpec.Etcd.External
+}
+
+// parseExternalEtcdEndpoint returns the host and port of an etcd client URL, defaulting to the etcd client port.
+func parseExternalEtcdEndpoint(endpoint string) (string, int, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return "", 0, fmt.Errorf("invalid external etcd endpoint '%s'", endpoint)
+	}
+	if u.Port() == "" {
+		return u.Hostname(), common.DefaultEtcdClientPort, nil
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in external etcd endpoint '%s'", endpoint)
+	}
+	return u.Hostname(), port, nil
+}
+
+func checkExternalEtcdCerts(ext *v1alpha1.ExternalEtcdConfig) error {
+	if ext.CAFile != "" {
+		caPEM, err := os.ReadFile(ext.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read external etcd CA file: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("external etcd CA file %s does not contain a PEM certificate", ext.CAFile)
+		}
+	}
+	if (ext.CertFile == "") != (ext.KeyFile == "") {
+		return fmt.Errorf("external etcd certFile and keyFile must be set together")
+	}
+	if ext.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(ext.CertFile, ext.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load external etcd client certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse external etcd client certificate: %w", err)
+		}
+		if time.Now().After(cert.NotAfter) {
+			return fmt.Errorf("external etcd client certificate %s expired on %s", ext.CertFile, cert.NotAfter.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+var _ step.Step = (*CheckExternalEtcdConnectivityStep)(nil)
+var _ step.Step = (*CheckExternalEtcdCertsStep)(nil)
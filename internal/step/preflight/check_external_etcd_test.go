@@ -0,0 +1,94 @@
+package preflight
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+func TestParseExternalEtcdEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		host     string
+		port     int
+		wantErr  bool
+	}{
+		{endpoint: "https://10.0.0.1:2379", host: "10.0.0.1", port: 2379},
+		{endpoint: "http://etcd.example.com", host: "etcd.example.com", port: 2379},
+		{endpoint: "https://[fd00::1]:12379", host: "fd00::1", port: 12379},
+		{endpoint: "10.0.0.1:2379", wantErr: true},
+	}
+	for _, tt := range tests {
+		host, port, err := parseExternalEtcdEndpoint(tt.endpoint)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.endpoint)
+			}
+			continue
+		}
+		if err != nil || host != tt.host || port != tt.port {
+			t.Errorf("%s: got (%s, %d, %v), want (%s, %d)", tt.endpoint, host, port, err, tt.host, tt.port)
+		}
+	}
+}
+
+func writeTestKeyPair(t *testing.T, dir string, notAfter time.Time) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "etcd-client"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestCheckExternalEtcdCerts(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, time.Now().Add(24*time.Hour))
+
+	if err := checkExternalEtcdCerts(&v1alpha1.ExternalEtcdConfig{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Errorf("expected valid certificates, got %v", err)
+	}
+	if err := checkExternalEtcdCerts(&v1alpha1.ExternalEtcdConfig{CAFile: keyFile}); err == nil {
+		t.Error("expected an error for a CA file without certificates")
+	}
+	if err := checkExternalEtcdCerts(&v1alpha1.ExternalEtcdConfig{CertFile: certFile}); err == nil {
+		t.Error("expected an error when keyFile is missing")
+	}
+
+	expiredDir := t.TempDir()
+	certFile, keyFile = writeTestKeyPair(t, expiredDir, time.Now().Add(-time.Hour))
+	if err := checkExternalEtcdCerts(&v1alpha1.ExternalEtcdConfig{CertFile: certFile, KeyFile: keyFile}); err == nil {
+		t.Error("expected an error for an expired client certificate")
+	}
+}
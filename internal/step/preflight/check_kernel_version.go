@@ -2,10 +2,10 @@ package preflight
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -15,10 +15,12 @@ import (
 
 var _ step.Step = (*CheckKernelVersionStep)(nil)
 
-// CheckKernelVersionStep verifies the kernel version meets minimum requirements.
+// CheckKernelVersionStep verifies the kernel version meets the minimum required by the enabled features.
 type CheckKernelVersionStep struct {
 	step.Base
 	MinVersion *string
+	// Feature names what requires MinVersion, for error messages.
+	Feature string
 }
 
 type CheckKernelVersionStepBuilder struct {
@@ -32,7 +34,28 @@ func NewCheckKernelVersionStepBuilder(ctx runtime.ExecutionContext, instanceName
 	s.Base.Sudo = false
 	s.Base.IgnoreError = false
 	s.Base.Timeout = 1 * time.Minute
-	return new(CheckKernelVersionStepBuilder).Init(s)
+	b := new(CheckKernelVersionStepBuilder).Init(s)
+
+	if network := ctx.GetClusterConfig().Spec.Network; network != nil && network.Plugin == string(common.CNITypeCilium) {
+		b.WithMinVersion(string(common.CNITypeCilium), common.MinKernelVersionCilium)
+	}
+	return b
+}
+
+// WithMinVersion requires at least version for feature, keeping the stricter of several requirements.
+func (b *CheckKernelVersionStepBuilder) WithMinVersion(feature, version string) *CheckKernelVersionStepBuilder {
+	required, err := runner.ParseKernelVersion(version)
+	if err != nil {
+		return b
+	}
+	if b.Step.MinVersion != nil {
+		if current, err := runner.ParseKernelVersion(*b.Step.MinVersion); err == nil && current.Compare(required) >= 0 {
+			return b
+		}
+	}
+	b.Step.MinVersion = &version
+	b.Step.Feature = feature
+	return b
 }
 
 func (s *CheckKernelVersionStep) Meta() *spec.StepMeta {
@@ -51,75 +74,24 @@ func (s *CheckKernelVersionStep) checkRequirement(ctx runtime.ExecutionContext)
 	}
 
 	if s.MinVersion == nil {
-		logger.Info("Minimum kernel version not configured, skipping check.")
+		logger.Info("No enabled feature requires a minimum kernel version, skipping check.")
 		return nil
 	}
 	minVersion := *s.MinVersion
 
-	actualVersion, err := s.getActualKernelVersion(ctx)
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
 	if err != nil {
-		return errors.Wrap(err, "failed to determine kernel version")
+		return errors.Wrap(err, "failed to gather host facts")
 	}
 
-	if !versionMeetsMinimum(actualVersion, minVersion) {
-		return errors.Errorf("kernel version requirement not met: minimum required is '%s', but found '%s'", minVersion, actualVersion)
+	if !runner.KernelAtLeast(facts, minVersion) {
+		return errors.Errorf("kernel version requirement not met: %s requires at least '%s', but found '%s'", s.Feature, minVersion, facts.Kernel)
 	}
 
-	logger.Infof("Kernel version check passed: '%s' >= '%s'.", actualVersion, minVersion)
+	logger.Infof("Kernel version check passed: '%s' >= '%s'.", facts.Kernel, minVersion)
 	return nil
 }
 
-func (s *CheckKernelVersionStep) getActualKernelVersion(ctx runtime.ExecutionContext) (string, error) {
-	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName())
-	conn, err := ctx.GetCurrentHostConnector()
-	if err != nil {
-		return "", errors.Wrap(err, "failed to get host connector")
-	}
-
-	runner := ctx.GetRunner()
-	runResult, err := runner.Run(ctx.GoContext(), conn, "uname -r", s.Sudo)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to execute 'uname -r'")
-	}
-
-	// uname -r returns e.g. "3.10.0-1127.el7.x86_64"
-	version := strings.TrimSpace(runResult.Stdout)
-	// Strip distro-specific suffixes like ".el7.x86_64"
-	if idx := strings.Index(version, "-"); idx > 0 {
-		version = version[:idx]
-	}
-
-	logger.Debugf("Detected kernel version: %s", version)
-	return version, nil
-}
-
-// versionMeetsMinimum checks if actual >= minimum using semantic version comparison.
-func versionMeetsMinimum(actual, minimum string) bool {
-	actualParts := parseKernelVersion(actual)
-	minParts := parseKernelVersion(minimum)
-
-	for i := 0; i < 3; i++ {
-		if actualParts[i] < minParts[i] {
-			return false
-		}
-		if actualParts[i] > minParts[i] {
-			return true
-		}
-	}
-	return true
-}
-
-func parseKernelVersion(v string) [3]int {
-	var parts [3]int
-	segments := strings.Split(v, ".")
-	for i := 0; i < len(segments) && i < 3; i++ {
-		if n, err := strconv.Atoi(segments[i]); err == nil {
-			parts[i] = n
-		}
-	}
-	return parts
-}
-
 func (s *CheckKernelVersionStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
 	checkErr := s.checkRequirement(ctx)
 	if checkErr == nil {
@@ -0,0 +1,177 @@
+package preflight
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+// ResourceCheckLevel is the outcome of comparing a node against its resource thresholds.
+type ResourceCheckLevel string
+
+const (
+	ResourceCheckPass ResourceCheckLevel = "pass"
+	ResourceCheckWarn ResourceCheckLevel = "warn"
+	ResourceCheckFail ResourceCheckLevel = "fail"
+)
+
+// NodeResourceReport describes how a node's CPU and memory compare to the thresholds of its role.
+type NodeResourceReport struct {
+	Role     string
+	CPUCores int64
+	MemoryMB uint64
+	Level    ResourceCheckLevel
+	Warnings []string
+	Errors   []string
+}
+
+// CheckNodeResourcesStep compares the CPU and memory reported by GatherFacts with the thresholds
+// configured for the node's role. Control-plane nodes use preflight.controlPlaneResources and all
+// other nodes use preflight.workerResources.
+type CheckNodeResourcesStep struct {
+	step.Base
+	ControlPlane *v1alpha1.ResourceThresholds
+	Worker       *v1alpha1.ResourceThresholds
+	SkipChecks   []string
+}
+
+type CheckNodeResourcesStepBuilder struct {
+	step.Builder[CheckNodeResourcesStepBuilder, *CheckNodeResourcesStep]
+}
+
+func NewCheckNodeResourcesStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckNodeResourcesStepBuilder {
+	s := &CheckNodeResourcesStep{}
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg != nil && clusterCfg.Spec != nil && clusterCfg.Spec.Preflight != nil {
+		s.ControlPlane = clusterCfg.Spec.Preflight.ControlPlaneResources
+		s.Worker = clusterCfg.Spec.Preflight.WorkerResources
+		s.SkipChecks = clusterCfg.Spec.Preflight.SkipChecks
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Check node CPU and memory against role thresholds", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 1 * time.Minute
+
+	b := new(CheckNodeResourcesStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckNodeResourcesStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckNodeResourcesStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *CheckNodeResourcesStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+
+	facts, err := s.facts(ctx)
+	if err != nil {
+		result.MarkFailed(err, "Failed to gather host facts")
+		return result, err
+	}
+
+	role, thresholds := s.thresholdsFor(ctx.GetHost())
+	cpuCores := facts.TotalCPU.Value()
+	memoryMB := uint64(facts.TotalMemory.Value()) / (1024 * 1024)
+	report := evaluateNodeResources(role, cpuCores, memoryMB, thresholds,
+		slices.Contains(s.SkipChecks, "cpu"), slices.Contains(s.SkipChecks, "memory"))
+
+	result.SetMetadata("role", report.Role)
+	result.SetMetadata("cpuCores", report.CPUCores)
+	result.SetMetadata("memoryMB", report.MemoryMB)
+	result.SetMetadata("level", string(report.Level))
+	result.SetMetadata("warnings", report.Warnings)
+	for _, warning := range report.Warnings {
+		logger.Warn(warning)
+	}
+
+	if report.Level == ResourceCheckFail {
+		err := fmt.Errorf("host %s does not meet the %s resource requirements: %s", ctx.GetHost().GetName(), report.Role, strings.Join(report.Errors, "; "))
+		result.MarkFailed(err, "Node resource check failed")
+		return result, err
+	}
+
+	logger.Info("Node resource check finished.", "role", report.Role, "cpu", report.CPUCores, "memoryMB", report.MemoryMB, "level", report.Level)
+	result.MarkCompleted(fmt.Sprintf("Node resource check %s", report.Level))
+	return result, nil
+}
+
+func (s *CheckNodeResourcesStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("No action to roll back for a check-only step.")
+	return nil
+}
+
+// facts returns the facts gathered when the runtime was built, gathering them again if they are unavailable.
+func (s *CheckNodeResourcesStep) facts(ctx runtime.ExecutionContext) (*runner.Facts, error) {
+	if facts, err := ctx.GetHostFacts(ctx.GetHost()); err == nil && facts != nil {
+		return facts, nil
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host connector: %w", err)
+	}
+	facts, err := ctx.GetRunner().GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to gather facts: %w", err)
+	}
+	return facts, nil
+}
+
+func (s *CheckNodeResourcesStep) thresholdsFor(host remotefw.Host) (string, *v1alpha1.ResourceThresholds) {
+	if host.IsRole(common.RoleMaster) || host.IsRole(common.RoleControlPlane) {
+		return common.RoleControlPlane, s.ControlPlane
+	}
+	return common.RoleWorker, s.Worker
+}
+
+// evaluateNodeResources compares cpuCores and memoryMB with thresholds. Values below the minimum fail
+// the node, values below the recommended size only warn.
+func evaluateNodeResources(role string, cpuCores int64, memoryMB uint64, thresholds *v1alpha1.ResourceThresholds, skipCPU, skipMemory bool) NodeResourceReport {
+	report := NodeResourceReport{Role: role, CPUCores: cpuCores, MemoryMB: memoryMB, Level: ResourceCheckPass}
+	if thresholds == nil {
+		return report
+	}
+
+	if !skipCPU {
+		switch {
+		case thresholds.MinCPUCores != nil && cpuCores < int64(*thresholds.MinCPUCores):
+			report.Errors = append(report.Errors, fmt.Sprintf("%d CPU cores found, %s nodes require at least %d", cpuCores, role, *thresholds.MinCPUCores))
+		case thresholds.RecommendedCPUCores != nil && cpuCores < int64(*thresholds.RecommendedCPUCores):
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%d CPU cores found, %d are recommended for %s nodes", cpuCores, *thresholds.RecommendedCPUCores, role))
+		}
+	}
+	if !skipMemory {
+		switch {
+		case thresholds.MinMemoryMB != nil && memoryMB < *thresholds.MinMemoryMB:
+			report.Errors = append(report.Errors, fmt.Sprintf("%d MB memory found, %s nodes require at least %d MB", memoryMB, role, *thresholds.MinMemoryMB))
+		case thresholds.RecommendedMemoryMB != nil && memoryMB < *thresholds.RecommendedMemoryMB:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%d MB memory found, %d MB is recommended for %s nodes", memoryMB, *thresholds.RecommendedMemoryMB, role))
+		}
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+		report.Level = ResourceCheckFail
+	case len(report.Warnings) > 0:
+		report.Level = ResourceCheckWarn
+	}
+	return report
+}
+
+var _ step.Step = (*CheckNodeResourcesStep)(nil)
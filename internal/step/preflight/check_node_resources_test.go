@@ -0,0 +1,91 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func newNodeResourcesContext(role string, cpu, memory string) *runtimetest.Context {
+	cluster := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Preflight: &v1alpha1.Preflight{}}}
+	v1alpha1.SetDefaults_Preflight(cluster.Spec.Preflight)
+	host := connector.NewHostFromSpec(v1alpha1.HostSpec{
+		Name:      "node1",
+		Address:   "192.168.1.10",
+		Roles:     []string{role},
+		RoleTable: map[string]bool{role: true},
+	})
+	return &runtimetest.Context{
+		Cluster: cluster,
+		Host:    host,
+		Facts:   &runner.Facts{TotalCPU: resource.MustParse(cpu), TotalMemory: resource.MustParse(memory)},
+	}
+}
+
+func TestCheckNodeResourcesStep_RoleThresholds(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		expectedStatus types.StepStatus
+		expectedLevel  string
+	}{
+		{name: "1-CPU control-plane node fails", role: common.RoleMaster, expectedStatus: types.StepStatusFailed, expectedLevel: "fail"},
+		{name: "1-CPU worker node passes with a warning", role: common.RoleWorker, expectedStatus: types.StepStatusCompleted, expectedLevel: "warn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newNodeResourcesContext(tt.role, "1", "4Gi")
+			s, err := NewCheckNodeResourcesStepBuilder(ctx, "CheckNodeResources").Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+
+			result, err := s.Run(ctx)
+			if result.Status != tt.expectedStatus {
+				t.Fatalf("expected status %s, got %s (err: %v)", tt.expectedStatus, result.Status, err)
+			}
+			if tt.expectedStatus == types.StepStatusFailed && (err == nil || !strings.Contains(err.Error(), "control-plane nodes require at least 2")) {
+				t.Errorf("expected a control-plane CPU error, got %v", err)
+			}
+			if level := result.Metadata["level"]; level != tt.expectedLevel {
+				t.Errorf("expected level %q, got %v", tt.expectedLevel, level)
+			}
+		})
+	}
+}
+
+func TestEvaluateNodeResources(t *testing.T) {
+	thresholds := &v1alpha1.ResourceThresholds{}
+	v1alpha1.SetDefaults_ResourceThresholds(thresholds, 2, 1700, 4, 4096)
+
+	tests := []struct {
+		name          string
+		cpu           int64
+		memoryMB      uint64
+		skipCPU       bool
+		expectedLevel ResourceCheckLevel
+	}{
+		{name: "meets recommendations", cpu: 8, memoryMB: 16384, expectedLevel: ResourceCheckPass},
+		{name: "below recommended memory", cpu: 4, memoryMB: 2048, expectedLevel: ResourceCheckWarn},
+		{name: "below minimum memory", cpu: 4, memoryMB: 1024, expectedLevel: ResourceCheckFail},
+		{name: "below minimum CPU", cpu: 1, memoryMB: 8192, expectedLevel: ResourceCheckFail},
+		{name: "CPU check skipped", cpu: 1, memoryMB: 8192, skipCPU: true, expectedLevel: ResourceCheckPass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := evaluateNodeResources(common.RoleControlPlane, tt.cpu, tt.memoryMB, thresholds, tt.skipCPU, false)
+			if report.Level != tt.expectedLevel {
+				t.Errorf("expected level %s, got %s (errors: %v, warnings: %v)", tt.expectedLevel, report.Level, report.Errors, report.Warnings)
+			}
+		})
+	}
+}
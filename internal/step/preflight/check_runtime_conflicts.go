@@ -0,0 +1,215 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+const criDockerdRuntimeName = "cri-dockerd"
+
+// containerRuntimeProbe describes how to detect one container runtime on a host.
+type containerRuntimeProbe struct {
+	Name    string
+	Service string
+	Socket  string
+}
+
+var containerRuntimeProbes = []containerRuntimeProbe{
+	{Name: string(common.RuntimeTypeDocker), Service: common.DockerServiceName, Socket: strings.TrimPrefix(common.DefaultDockerEndpoint, "unix://")},
+	{Name: criDockerdRuntimeName, Service: common.CniDockerdServiceName, Socket: common.CriDockerdSocketPath},
+	{Name: string(common.RuntimeTypeContainerd), Service: common.ContainerdServiceName, Socket: strings.TrimPrefix(common.ContainerdDefaultEndpoint, "unix://")},
+	{Name: string(common.RuntimeTypeCRIO), Service: common.CrioServiceName, Socket: strings.TrimPrefix(common.CRIODefaultEndpoint, "unix://")},
+	{Name: string(common.RuntimeTypeIsula), Service: common.IsuladServiceName, Socket: strings.TrimPrefix(common.IsuladDefaultEndpoint, "unix://")},
+}
+
+// RuntimeConflictReport lists the container runtimes found on a host. Errors must be resolved before
+// installing; warnings point at a likely source of confusion but do not block the installation.
+type RuntimeConflictReport struct {
+	Active   []string
+	Warnings []string
+	Errors   []string
+}
+
+// HasConflicts reports whether the host has blocking runtime conflicts.
+func (r *RuntimeConflictReport) HasConflicts() bool {
+	return len(r.Errors) > 0
+}
+
+// CheckContainerRuntimeConflictsStep detects hosts on which more than one container runtime is active or
+// where sockets of other runtimes are left behind, which makes it ambiguous which runtime the kubelet and
+// crictl talk to.
+type CheckContainerRuntimeConflictsStep struct {
+	step.Base
+	DesiredRuntime string
+}
+
+type CheckContainerRuntimeConflictsStepBuilder struct {
+	step.Builder[CheckContainerRuntimeConflictsStepBuilder, *CheckContainerRuntimeConflictsStep]
+}
+
+func NewCheckContainerRuntimeConflictsStepBuilder(ctx runtime.ExecutionContext, instanceName string) *CheckContainerRuntimeConflictsStepBuilder {
+	s := &CheckContainerRuntimeConflictsStep{
+		DesiredRuntime: string(common.RuntimeTypeContainerd),
+	}
+	if clusterCfg := ctx.GetClusterConfig(); clusterCfg != nil && clusterCfg.Spec != nil && clusterCfg.Spec.Kubernetes != nil &&
+		clusterCfg.Spec.Kubernetes.ContainerRuntime != nil && clusterCfg.Spec.Kubernetes.ContainerRuntime.Type != "" {
+		s.DesiredRuntime = string(clusterCfg.Spec.Kubernetes.ContainerRuntime.Type)
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = "Check that no conflicting container runtimes are active on the node"
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 1 * time.Minute
+
+	b := new(CheckContainerRuntimeConflictsStepBuilder).Init(s)
+	return b
+}
+
+func (s *CheckContainerRuntimeConflictsStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *CheckContainerRuntimeConflictsStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+// detect collects which runtime services are active and which runtime sockets exist on the host.
+func (s *CheckContainerRuntimeConflictsStep) detect(ctx runtime.ExecutionContext) (active, sockets map[string]bool, err error) {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil, nil, err
+	}
+	facts, err := runner.GatherFacts(ctx.GoContext(), conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to gather facts: %w", err)
+	}
+
+	active = make(map[string]bool)
+	paths := make([]string, 0, len(containerRuntimeProbes))
+	for _, probe := range containerRuntimeProbes {
+		isActive, err := runner.IsServiceActive(ctx.GoContext(), conn, facts, probe.Service)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to determine status of %s: %w", probe.Service, err)
+		}
+		active[probe.Name] = isActive
+		paths = append(paths, probe.Socket)
+	}
+
+	found, err := runner.ExistsBatch(ctx.GoContext(), conn, paths)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check for container runtime sockets: %w", err)
+	}
+	sockets = make(map[string]bool)
+	for _, probe := range containerRuntimeProbes {
+		sockets[probe.Name] = found[probe.Socket]
+	}
+	return active, sockets, nil
+}
+
+func (s *CheckContainerRuntimeConflictsStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+
+	active, sockets, err := s.detect(ctx)
+	if err != nil {
+		result.MarkFailed(err, "Failed to detect container runtimes")
+		return result, err
+	}
+
+	report := analyzeRuntimeConflicts(s.DesiredRuntime, active, sockets)
+	for _, warning := range report.Warnings {
+		logger.Warn(warning)
+	}
+	result.SetMetadata("activeRuntimes", report.Active)
+	result.SetMetadata("warnings", report.Warnings)
+	if report.HasConflicts() {
+		err := fmt.Errorf("conflicting container runtimes on host %s: %s", ctx.GetHost().GetName(), strings.Join(report.Errors, "; "))
+		result.MarkFailed(err, "Conflicting container runtimes detected")
+		return result, err
+	}
+
+	logger.Info("No conflicting container runtimes detected.", "active", report.Active)
+	result.MarkCompleted("No conflicting container runtimes detected")
+	return result, nil
+}
+
+func (s *CheckContainerRuntimeConflictsStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("No action to roll back for a check-only step.")
+	return nil
+}
+
+// analyzeRuntimeConflicts turns the detected runtimes into a report. Docker ships with and runs on top of
+// containerd, so docker, cri-dockerd and containerd count as one runtime family; two active families are
+// an error. Runtimes other than the desired one and sockets left behind by stopped runtimes are warnings.
+func analyzeRuntimeConflicts(desired string, active, sockets map[string]bool) RuntimeConflictReport {
+	var report RuntimeConflictReport
+	for _, probe := range containerRuntimeProbes {
+		if active[probe.Name] {
+			report.Active = append(report.Active, probe.Name)
+		}
+	}
+
+	family := func(name string) string {
+		switch name {
+		case string(common.RuntimeTypeDocker), criDockerdRuntimeName, string(common.RuntimeTypeContainerd):
+			return string(common.RuntimeTypeDocker)
+		}
+		return name
+	}
+	var families []string
+	seen := make(map[string]bool)
+	for _, name := range report.Active {
+		if f := family(name); !seen[f] {
+			seen[f] = true
+			families = append(families, name)
+		}
+	}
+	if len(families) > 1 {
+		report.Errors = append(report.Errors, fmt.Sprintf("multiple container runtimes are active (%s); stop and disable all but %s",
+			strings.Join(report.Active, ", "), desired))
+	}
+
+	dockerActive := active[string(common.RuntimeTypeDocker)]
+	containerdActive := active[string(common.RuntimeTypeContainerd)]
+	if dockerActive && containerdActive {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("docker and a standalone containerd are both active; make sure the kubelet and crictl use the %s socket", desired))
+	}
+	if len(families) == 1 {
+		for _, name := range report.Active {
+			isDockerPart := name == string(common.RuntimeTypeDocker) || name == criDockerdRuntimeName
+			switch {
+			case name == desired:
+			case desired == string(common.RuntimeTypeDocker) && family(name) == family(desired):
+				// cri-dockerd and containerd are part of a docker installation.
+			case desired == string(common.RuntimeTypeContainerd) && isDockerPart && containerdActive:
+				// Already reported as docker running next to containerd.
+			default:
+				report.Warnings = append(report.Warnings, fmt.Sprintf("container runtime %s is active but the cluster is configured for %s", name, desired))
+			}
+		}
+	}
+	for _, probe := range containerRuntimeProbes {
+		if !sockets[probe.Name] || active[probe.Name] {
+			continue
+		}
+		// Docker may drive containerd and socket-activate cri-dockerd without their services being active.
+		if dockerActive && family(probe.Name) == string(common.RuntimeTypeDocker) {
+			continue
+		}
+		report.Warnings = append(report.Warnings, fmt.Sprintf("socket %s of inactive runtime %s exists and may be picked up instead of the %s socket",
+			probe.Socket, probe.Name, desired))
+	}
+	return report
+}
+
+var _ step.Step = (*CheckContainerRuntimeConflictsStep)(nil)
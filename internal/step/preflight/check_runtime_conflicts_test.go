@@ -0,0 +1,108 @@
+package preflight
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	"github.com/mensylisir/kubexm/internal/types"
+)
+
+type fakeRuntimeConflictRunner struct {
+	runner.Runner
+	activeServices map[string]bool
+	existing       map[string]bool
+}
+
+func (r *fakeRuntimeConflictRunner) GatherFacts(ctx context.Context, conn connector.Connector) (*runner.Facts, error) {
+	return &runner.Facts{}, nil
+}
+
+func (r *fakeRuntimeConflictRunner) IsServiceActive(ctx context.Context, conn connector.Connector, facts *runner.Facts, serviceName string) (bool, error) {
+	return r.activeServices[serviceName], nil
+}
+
+func (r *fakeRuntimeConflictRunner) ExistsBatch(ctx context.Context, conn connector.Connector, paths []string) (map[string]bool, error) {
+	found := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		found[path] = r.existing[path]
+	}
+	return found, nil
+}
+
+func TestCheckContainerRuntimeConflictsStep(t *testing.T) {
+	tests := []struct {
+		name             string
+		activeServices   map[string]bool
+		existing         map[string]bool
+		expectedStatus   types.StepStatus
+		expectedErr      []string
+		expectedWarnings int
+	}{
+		{
+			name:           "docker and crio both active",
+			activeServices: map[string]bool{common.DockerServiceName: true, common.CrioServiceName: true},
+			existing:       map[string]bool{"/var/run/docker.sock": true, "/var/run/crio/crio.sock": true},
+			expectedStatus: types.StepStatusFailed,
+			expectedErr:    []string{"multiple container runtimes are active", "docker", "cri-o"},
+		},
+		{
+			name:             "docker next to standalone containerd",
+			activeServices:   map[string]bool{common.DockerServiceName: true, common.ContainerdServiceName: true},
+			existing:         map[string]bool{"/var/run/docker.sock": true, "/run/containerd/containerd.sock": true},
+			expectedStatus:   types.StepStatusCompleted,
+			expectedWarnings: 1,
+		},
+		{
+			name:             "stale crio socket next to containerd",
+			activeServices:   map[string]bool{common.ContainerdServiceName: true},
+			existing:         map[string]bool{"/run/containerd/containerd.sock": true, "/var/run/crio/crio.sock": true},
+			expectedStatus:   types.StepStatusCompleted,
+			expectedWarnings: 1,
+		},
+		{
+			name:           "clean host",
+			activeServices: map[string]bool{},
+			existing:       map[string]bool{},
+			expectedStatus: types.StepStatusCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &runtimetest.Context{
+				Runner: &fakeRuntimeConflictRunner{activeServices: tt.activeServices, existing: tt.existing},
+				Host:   connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"}),
+				Cluster: &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{Kubernetes: &v1alpha1.Kubernetes{
+					ContainerRuntime: &v1alpha1.ContainerRuntime{Type: common.RuntimeTypeContainerd},
+				}}},
+			}
+			s, err := NewCheckContainerRuntimeConflictsStepBuilder(ctx, "CheckContainerRuntimeConflicts").Build()
+			if err != nil {
+				t.Fatalf("failed to build step: %v", err)
+			}
+
+			result, err := s.Run(ctx)
+			if result.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, result.Status)
+			}
+			for _, want := range tt.expectedErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error containing %q, got %v", want, err)
+				}
+			}
+			if len(tt.expectedErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			warnings, _ := result.Metadata["warnings"].([]string)
+			if len(warnings) != tt.expectedWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.expectedWarnings, warnings)
+			}
+		})
+	}
+}
@@ -74,7 +74,21 @@ func (s *CheckTimeSyncStep) Run(ctx runtime.ExecutionContext) (*types.StepResult
 		return result, nil
 	}
 
-	logger.Info("'chronyc' not found or failed, falling back to 'ntpstat'...")
+	logger.Info("'chronyc' not found or failed, falling back to 'timedatectl'...")
+	if runResult, err := runner.Run(ctx.GoContext(), conn, "timedatectl show -p NTPSynchronized --value", s.Sudo); err == nil {
+		if strings.TrimSpace(runResult.Stdout) == "yes" {
+			logger.Info("Time is synchronized according to 'timedatectl'.")
+			result.MarkCompleted("Time is synchronized")
+			return result, nil
+		}
+		if _, err := runner.Run(ctx.GoContext(), conn, "systemctl is-active systemd-timesyncd", s.Sudo); err == nil {
+			err = fmt.Errorf("NTP time synchronization is not healthy according to systemd-timesyncd")
+			result.MarkFailed(err, "NTP time synchronization is not healthy")
+			return result, err
+		}
+	}
+
+	logger.Info("Falling back to 'ntpstat'...")
 	ntpstatCmd := "ntpstat"
 	if _, err := runner.Run(ctx.GoContext(), conn, ntpstatCmd, s.Sudo); err == nil {
 
@@ -0,0 +1,82 @@
+package preflight
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pkg/errors"
+)
+
+var _ step.Step = (*DetectGPUStep)(nil)
+
+// DetectGPUStep surfaces GPU-capable nodes so that downstream steps (device plugin,
+// container runtime configuration) can target them. It never fails the run on hosts
+// without accelerators; it only records what it found in the pipeline cache.
+type DetectGPUStep struct {
+	step.Base
+}
+
+type DetectGPUStepBuilder struct {
+	step.Builder[DetectGPUStepBuilder, *DetectGPUStep]
+}
+
+func NewDetectGPUStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DetectGPUStepBuilder {
+	s := &DetectGPUStep{}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Detect GPU/accelerator devices", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = true
+	s.Base.Timeout = 1 * time.Minute
+	return new(DetectGPUStepBuilder).Init(s)
+}
+
+func (s *DetectGPUStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *DetectGPUStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *DetectGPUStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		err = errors.Wrap(err, "failed to get host facts")
+		result.MarkFailed(err, "GPU detection failed")
+		return result, err
+	}
+
+	cacheKey := fmt.Sprintf(common.CacheKeyHostGPUDevices, ctx.GetRunID(), ctx.GetPipelineName(), ctx.GetHost().GetName())
+	ctx.GetPipelineCache().Set(cacheKey, facts.GPUs)
+
+	if !facts.HasGPU() {
+		logger.Info("No GPU/accelerator devices detected.")
+		result.MarkCompleted("no GPU detected")
+		return result, nil
+	}
+
+	for _, gpu := range facts.GPUs {
+		logger.Info("Detected GPU device.", "vendor", gpu.Vendor, "model", gpu.Model, "pci", gpu.PCIAddress)
+	}
+	if facts.NvidiaSMIPresent {
+		logger.Info("nvidia-smi is present, NVIDIA driver appears to be installed.")
+	}
+	result.SetMetadata("gpus", facts.GPUs)
+	result.SetMetadata("nvidiaSMIPresent", facts.NvidiaSMIPresent)
+	result.MarkCompleted(fmt.Sprintf("GPU-capable node: %d device(s) detected", len(facts.GPUs)))
+	return result, nil
+}
+
+func (s *DetectGPUStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("No action to roll back for a detection-only step.")
+	return nil
+}
@@ -2,11 +2,11 @@ package preflight
 
 import (
 	"fmt"
-	"net"
 	"regexp"
 	"time"
 
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1/helpers"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step"
@@ -111,8 +111,7 @@ func (s *LintClusterSpecStep) validateCIDR(name, cidr string) []string {
 		errs = append(errs, fmt.Sprintf("%s is not defined", name))
 		return errs
 	}
-	_, _, err := net.ParseCIDR(cidr)
-	if err != nil {
+	if _, err := helpers.ParseDualStackCIDRs(cidr); err != nil {
 		errs = append(errs, fmt.Sprintf("invalid %s '%s': %v", name, cidr, err))
 	}
 	return errs
@@ -125,14 +124,18 @@ func (s *LintClusterSpecStep) validateCIDROverlap() error {
 		return nil
 	}
 
-	_, podNet, err1 := net.ParseCIDR(podCIDR)
-	_, svcNet, err2 := net.ParseCIDR(svcCIDR)
+	podNets, err1 := helpers.ParseDualStackCIDRs(podCIDR)
+	svcNets, err2 := helpers.ParseDualStackCIDRs(svcCIDR)
 	if err1 != nil || err2 != nil {
 		return nil
 	}
 
-	if podNet.Contains(svcNet.IP) || svcNet.Contains(podNet.IP) {
-		return fmt.Errorf("PodCIDR (%s) and ServiceCIDR (%s) overlap", podCIDR, svcCIDR)
+	for _, podNet := range podNets {
+		for _, svcNet := range svcNets {
+			if helpers.NetworksOverlap(podNet, svcNet) {
+				return fmt.Errorf("PodCIDR (%s) and ServiceCIDR (%s) overlap", podNet, svcNet)
+			}
+		}
 	}
 	return nil
 }
@@ -0,0 +1,302 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+const localRegistryServicePath = "/etc/systemd/system/" + common.LocalRegistryServiceName + ".service"
+
+const localRegistryServiceTemplate = `[Unit]
+Description=Kubexm local image registry
+After=network-online.target {{.RuntimeService}}
+Requires={{.RuntimeService}}
+
+[Service]
+Type=simple
+ExecStartPre=-{{.StopCommand}}
+ExecStart={{.ExecStart}}
+ExecStop={{.StopCommand}}
+Restart=always
+RestartSec=10s
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// DeployLocalRegistryStep runs the registry:2 image as a systemd managed container on the current
+// host, for clusters with registry.type local. The image comes from the saved images on the control
+// node when they have it and is pulled otherwise. Docker hosts run it with docker, containerd hosts
+// with ctr in a namespace of its own so it never shows up as a Kubernetes image.
+type DeployLocalRegistryStep struct {
+	step.Base
+	RuntimeType common.ContainerRuntimeType
+	Image       string
+	Port        int
+	DataRoot    string
+	ImagesDir   string
+}
+
+type DeployLocalRegistryStepBuilder struct {
+	step.Builder[DeployLocalRegistryStepBuilder, *DeployLocalRegistryStep]
+}
+
+func NewDeployLocalRegistryStepBuilder(ctx runtime.ExecutionContext, instanceName string) *DeployLocalRegistryStepBuilder {
+	s := &DeployLocalRegistryStep{
+		RuntimeType: common.RuntimeTypeContainerd,
+		Port:        common.DefaultLocalRegistryPort,
+		ImagesDir:   filepath.Join(ctx.GetGlobalWorkDir(), "images"),
+	}
+	cfg := ctx.GetClusterConfig()
+	if cfg.Spec.Kubernetes != nil && cfg.Spec.Kubernetes.ContainerRuntime != nil && cfg.Spec.Kubernetes.ContainerRuntime.Type != "" {
+		s.RuntimeType = cfg.Spec.Kubernetes.ContainerRuntime.Type
+	}
+	if cfg.Spec.Registry != nil && cfg.Spec.Registry.LocalDeployment != nil {
+		s.Port = cfg.Spec.Registry.LocalDeployment.Port
+		s.DataRoot = cfg.Spec.Registry.LocalDeployment.DataRoot
+	}
+	if img := images.NewImageProvider(ctx).GetImage(images.LocalRegistryImageName); img != nil {
+		s.Image = img.OriginalFullName()
+	}
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Deploy the local image registry", s.Base.Meta.Name)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 15 * time.Minute
+
+	b := new(DeployLocalRegistryStepBuilder).Init(s)
+	return b
+}
+
+func (s *DeployLocalRegistryStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *DeployLocalRegistryStep) healthCheckCommand() string {
+	return fmt.Sprintf("curl -sf -o /dev/null http://127.0.0.1:%d/v2/", s.Port)
+}
+
+func (s *DeployLocalRegistryStep) renderServiceContent() (string, error) {
+	data := map[string]string{}
+	if s.RuntimeType == common.RuntimeTypeDocker {
+		docker := filepath.Join(common.DefaultBinDir, "docker")
+		data["RuntimeService"] = "docker.service"
+		data["StopCommand"] = fmt.Sprintf("%s rm -f %s", docker, common.LocalRegistryServiceName)
+		data["ExecStart"] = fmt.Sprintf("%s run --rm --name %s -p %d:5000 -v %s:%s %s",
+			docker, common.LocalRegistryServiceName, s.Port, s.DataRoot, common.LocalRegistryContainerStoragePath, s.Image)
+	} else {
+		ctr := fmt.Sprintf("%s -n %s", filepath.Join(common.DefaultBinDir, "ctr"), common.LocalRegistryContainerdNamespace)
+		data["RuntimeService"] = "containerd.service"
+		data["StopCommand"] = fmt.Sprintf("%s task kill -s SIGKILL %s", ctr, common.LocalRegistryServiceName)
+		data["ExecStart"] = fmt.Sprintf("%s run --rm --net-host --env REGISTRY_HTTP_ADDR=0.0.0.0:%d --mount type=bind,src=%s,dst=%s,options=rbind:rw %s %s",
+			ctr, s.Port, s.DataRoot, common.LocalRegistryContainerStoragePath, s.Image, common.LocalRegistryServiceName)
+	}
+
+	tmpl, err := template.New("localRegistryService").Parse(localRegistryServiceTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (s *DeployLocalRegistryStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.Image == "" {
+		return false, fmt.Errorf("no registry image is defined for the local registry")
+	}
+	if s.RuntimeType != common.RuntimeTypeDocker && s.RuntimeType != common.RuntimeTypeContainerd {
+		return false, fmt.Errorf("the local registry needs docker or containerd, but the host runs %s", s.RuntimeType)
+	}
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	if _, err := ctx.GetRunner().Run(ctx.GoContext(), conn, s.healthCheckCommand(), false); err == nil {
+		logger.Info("Local registry is already serving.", "port", s.Port)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *DeployLocalRegistryStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	if err := s.ensureImage(ctx, conn); err != nil {
+		result.MarkFailed(err, "failed to provide the registry image")
+		return result, err
+	}
+
+	if err := runner.Mkdirp(ctx.GoContext(), conn, s.DataRoot, "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to create registry data directory")
+		return result, err
+	}
+
+	serviceContent, err := s.renderServiceContent()
+	if err != nil {
+		result.MarkFailed(err, "failed to render service content")
+		return result, err
+	}
+	logger.Infof("Writing %s", localRegistryServicePath)
+	if err := helpers.WriteContentToRemote(ctx, conn, serviceContent, localRegistryServicePath, "0644", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to write local registry service file")
+		return result, err
+	}
+
+	for _, cmd := range []string{
+		"systemctl daemon-reload",
+		"systemctl enable " + common.LocalRegistryServiceName,
+		"systemctl restart " + common.LocalRegistryServiceName,
+	} {
+		if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+			result.MarkFailed(err, fmt.Sprintf("failed to run '%s'", cmd))
+			return result, err
+		}
+	}
+
+	if err := s.waitForRegistry(ctx, conn); err != nil {
+		result.MarkFailed(err, "local registry did not become ready")
+		return result, err
+	}
+	logger.Info("Local registry is serving.", "port", s.Port)
+	result.MarkCompleted(fmt.Sprintf("local registry is serving on port %d", s.Port))
+	return result, nil
+}
+
+// ensureImage makes the registry image available to the runtime. The saved images on the control
+// node are preferred, so offline installs never reach out to Docker Hub.
+func (s *DeployLocalRegistryStep) ensureImage(ctx runtime.ExecutionContext, conn connector.Connector) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName())
+	runner := ctx.GetRunner()
+
+	present, err := s.imagePresent(ctx, conn)
+	if err == nil && present {
+		return nil
+	}
+
+	archive, err := s.exportSavedImage(ctx.GetHost().GetArch())
+	if err != nil {
+		logger.Warn("Registry image is not in the saved images, pulling it instead.", "image", s.Image, "error", err)
+		if s.RuntimeType == common.RuntimeTypeDocker {
+			return runner.PullImage(ctx.GoContext(), conn, s.Image)
+		}
+		return runner.CtrPullImage(ctx.GoContext(), conn, common.LocalRegistryContainerdNamespace, s.Image, false, "")
+	}
+	defer os.Remove(archive)
+
+	uploadDir := ctx.GetUploadDir()
+	remoteArchive := filepath.Join(uploadDir, filepath.Base(archive))
+	if err := runner.Mkdirp(ctx.GoContext(), conn, uploadDir, "0755", false); err != nil {
+		return fmt.Errorf("failed to ensure remote upload directory %s exists: %w", uploadDir, err)
+	}
+	if err := runner.Upload(ctx.GoContext(), conn, archive, remoteArchive, false); err != nil {
+		return fmt.Errorf("failed to upload registry image archive: %w", err)
+	}
+	defer runner.Remove(ctx.GoContext(), conn, remoteArchive, false, false)
+
+	logger.Info("Loading registry image from the saved images.", "image", s.Image)
+	if s.RuntimeType == common.RuntimeTypeDocker {
+		if _, err := runner.Run(ctx.GoContext(), conn, "docker load -i "+remoteArchive, s.Sudo); err != nil {
+			return fmt.Errorf("failed to load registry image archive %s: %w", remoteArchive, err)
+		}
+		return nil
+	}
+	return runner.CtrImportImage(ctx.GoContext(), conn, common.LocalRegistryContainerdNamespace, remoteArchive, false)
+}
+
+func (s *DeployLocalRegistryStep) imagePresent(ctx runtime.ExecutionContext, conn connector.Connector) (bool, error) {
+	runner := ctx.GetRunner()
+	if s.RuntimeType == common.RuntimeTypeDocker {
+		return runner.ImageExists(ctx.GoContext(), conn, s.Image)
+	}
+	existing, err := runner.CtrListImages(ctx.GoContext(), conn, common.LocalRegistryContainerdNamespace)
+	if err != nil {
+		return false, err
+	}
+	for _, img := range existing {
+		if img.Name == s.Image {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// exportSavedImage copies the registry image for arch out of the OCI layout filled by SaveImagesStep
+// into a docker archive, which both docker load and ctr import accept.
+func (s *DeployLocalRegistryStep) exportSavedImage(arch string) (string, error) {
+	if _, err := os.Stat(s.ImagesDir); err != nil {
+		return "", err
+	}
+	if _, err := exec.LookPath("skopeo"); err != nil {
+		return "", fmt.Errorf("skopeo command not found in PATH")
+	}
+	f, err := os.CreateTemp("", "kubexm-registry-*.tar")
+	if err != nil {
+		return "", err
+	}
+	archive := f.Name()
+	f.Close()
+	os.Remove(archive)
+
+	src := fmt.Sprintf("oci:%s:%s-%s", s.ImagesDir, s.Image, arch)
+	dest := fmt.Sprintf("docker-archive:%s:%s", archive, s.Image)
+	if output, err := exec.Command("skopeo", "copy", src, dest).CombinedOutput(); err != nil {
+		os.Remove(archive)
+		return "", fmt.Errorf("failed to export %s: %w\nOutput: %s", src, err, strings.TrimSpace(string(output)))
+	}
+	return archive, nil
+}
+
+func (s *DeployLocalRegistryStep) waitForRegistry(ctx runtime.ExecutionContext, conn connector.Connector) error {
+	var lastErr error
+	for i := 0; i < 30; i++ {
+		if _, lastErr = ctx.GetRunner().Run(ctx.GoContext(), conn, s.healthCheckCommand(), false); lastErr == nil {
+			return nil
+		}
+		select {
+		case <-ctx.GoContext().Done():
+			return ctx.GoContext().Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+	return fmt.Errorf("registry on port %d is not answering: %w", s.Port, lastErr)
+}
+
+func (s *DeployLocalRegistryStep) Rollback(ctx runtime.ExecutionContext) error {
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return nil
+	}
+	_, _ = runner.Run(ctx.GoContext(), conn, "systemctl disable --now "+common.LocalRegistryServiceName, s.Sudo)
+	_ = runner.Remove(ctx.GoContext(), conn, localRegistryServicePath, s.Sudo, false)
+	return nil
+}
+
+var _ step.Step = (*DeployLocalRegistryStep)(nil)
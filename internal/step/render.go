@@ -0,0 +1,23 @@
+package step
+
+import (
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+// RenderPlaceholder stands in for values that only exist once earlier steps have run, such as a
+// bootstrap token, when a file is rendered without running them.
+const RenderPlaceholder = "<generated-at-install-time>"
+
+// RenderedFile is a file a step writes on its host.
+type RenderedFile struct {
+	// Path is the absolute path of the file on the host.
+	Path    string
+	Content []byte
+}
+
+// Renderer is implemented by steps that write files rendered from the cluster config. RenderFiles
+// returns the files Run would write on the current host without changing the host, so a dry run can
+// show them and diff them against what the host has.
+type Renderer interface {
+	RenderFiles(ctx runtime.ExecutionContext) ([]RenderedFile, error)
+}
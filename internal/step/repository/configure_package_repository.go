@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	yumReposDir       = "/etc/yum.repos.d"
+	aptSourcesList    = "/etc/apt/sources.list"
+	aptSourcesListDir = "/etc/apt/sources.list.d"
+	aptKeyringsDir    = "/etc/apt/keyrings"
+)
+
+// ConfigurePackageRepositoryStep points the package manager of a host at the repositories of
+// spec.system.packageRepository: the OS installation ISO, mounted on the host, and internal
+// mirrors. Unless told otherwise, the other repositories of the host are disabled by renaming
+// their files, so that installing packages does not try to reach the internet.
+type ConfigurePackageRepositoryStep struct {
+	step.Base
+	Repository *v1alpha1.PackageRepositorySpec
+
+	mountedISO      bool
+	createdRepoFile bool
+}
+
+type ConfigurePackageRepositoryStepBuilder struct {
+	step.Builder[ConfigurePackageRepositoryStepBuilder, *ConfigurePackageRepositoryStep]
+}
+
+func NewConfigurePackageRepositoryStepBuilder(ctx runtime.ExecutionContext, instanceName string) *ConfigurePackageRepositoryStepBuilder {
+	s := &ConfigurePackageRepositoryStep{
+		Repository: packageRepositorySpec(ctx.GetClusterConfig()),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Configure local package repositories", instanceName)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 10 * time.Minute
+	return new(ConfigurePackageRepositoryStepBuilder).Init(s)
+}
+
+func (s *ConfigurePackageRepositoryStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func packageRepositorySpec(cluster *v1alpha1.Cluster) *v1alpha1.PackageRepositorySpec {
+	if cluster == nil || cluster.Spec == nil || cluster.Spec.System == nil {
+		return nil
+	}
+	return cluster.Spec.System.PackageRepository
+}
+
+// packageRepositoryFile returns the repository file kubexm writes for a package manager.
+func packageRepositoryFile(pm runner.PackageManagerType) (string, error) {
+	switch pm {
+	case runner.PackageManagerYum, runner.PackageManagerDnf:
+		return path.Join(yumReposDir, common.PackageRepositoryFileName+".repo"), nil
+	case runner.PackageManagerApt:
+		return path.Join(aptSourcesListDir, common.PackageRepositoryFileName+".list"), nil
+	default:
+		return "", fmt.Errorf("unsupported package manager '%s' for local package repositories", pm)
+	}
+}
+
+// externalRepositoryGlobs returns the files holding the other repositories of a host.
+func externalRepositoryGlobs(pm runner.PackageManagerType) []string {
+	if pm == runner.PackageManagerApt {
+		return []string{aptSourcesList, aptSourcesListDir + "/*.list", aptSourcesListDir + "/*.sources"}
+	}
+	return []string{yumReposDir + "/*.repo"}
+}
+
+// disableExternalRepositoriesCmd renames the repository files matching globs, except keep, so
+// that the package manager ignores them.
+func disableExternalRepositoriesCmd(globs []string, keep string) string {
+	return fmt.Sprintf(`sh -c 'for f in %s; do [ -f "$f" ] && [ "$f" != "%s" ] && mv "$f" "$f%s"; done; true'`,
+		strings.Join(globs, " "), keep, common.PackageRepositoryDisabledSuffix)
+}
+
+// restoreExternalRepositoriesCmd renames the repository files disabled by
+// disableExternalRepositoriesCmd back.
+func restoreExternalRepositoriesCmd(globs []string) string {
+	disabled := make([]string, 0, len(globs))
+	for _, glob := range globs {
+		disabled = append(disabled, glob+common.PackageRepositoryDisabledSuffix)
+	}
+	return fmt.Sprintf(`sh -c 'for f in %s; do [ -f "$f" ] && mv "$f" "${f%%%s}"; done; true'`,
+		strings.Join(disabled, " "), common.PackageRepositoryDisabledSuffix)
+}
+
+// refreshCacheCmd returns the command that reloads the repository metadata.
+func refreshCacheCmd(pm runner.PackageManagerType) string {
+	if pm == runner.PackageManagerApt {
+		return "apt-get update"
+	}
+	return fmt.Sprintf("sh -c '%[1]s clean all && %[1]s makecache'", pm)
+}
+
+func mirrorKeyringPath(mirror v1alpha1.PackageMirror) string {
+	return path.Join(aptKeyringsDir, fmt.Sprintf("kubexm-%s.asc", mirror.Name))
+}
+
+// renderYumRepoFile returns a yum repository file with a repository for every directory of the
+// ISO mounted at mountPath that has repository metadata, and one for every mirror. isoDirs are
+// relative to mountPath, "" for an ISO with its metadata at the top.
+func renderYumRepoFile(mountPath string, isoDirs []string, mirrors []v1alpha1.PackageMirror) string {
+	var b strings.Builder
+	for _, dir := range isoDirs {
+		id, name := "kubexm-iso", "kubexm ISO"
+		if dir != "" {
+			id, name = id+"-"+dir, name+" "+dir
+		}
+		fmt.Fprintf(&b, "[%s]\nname=%s\nbaseurl=file://%s\nenabled=1\ngpgcheck=0\n\n", id, name, path.Join(mountPath, dir))
+	}
+	for _, mirror := range mirrors {
+		fmt.Fprintf(&b, "[%s]\nname=%s\nbaseurl=%s\nenabled=1\n", mirror.Name, mirror.Name, mirror.URL)
+		if mirror.GPGKey != "" {
+			fmt.Fprintf(&b, "gpgcheck=1\ngpgkey=%s\n\n", mirror.GPGKey)
+		} else {
+			b.WriteString("gpgcheck=0\n\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// renderAptSourcesList returns an apt sources list with the ISO mounted at mountPath, when
+// isoComponents is not empty, and every mirror. Suites default to codename.
+func renderAptSourcesList(mountPath, codename string, isoComponents []string, mirrors []v1alpha1.PackageMirror) string {
+	var b strings.Builder
+	if len(isoComponents) > 0 {
+		fmt.Fprintf(&b, "deb [trusted=yes] file://%s %s %s\n", mountPath, codename, strings.Join(isoComponents, " "))
+	}
+	for _, mirror := range mirrors {
+		suite := mirror.Suite
+		if suite == "" {
+			suite = codename
+		}
+		components := mirror.Components
+		if len(components) == 0 {
+			components = []string{"main"}
+		}
+		option := "trusted=yes"
+		if mirror.GPGKey != "" {
+			option = "signed-by=" + mirrorKeyringPath(mirror)
+		}
+		fmt.Fprintf(&b, "deb [%s] %s %s %s\n", option, mirror.URL, suite, strings.Join(components, " "))
+	}
+	return b.String()
+}
+
+// renderRepoFile returns the repository file for the host, with the repositories found on the
+// mounted ISO.
+func (s *ConfigurePackageRepositoryStep) renderRepoFile(ctx runtime.ExecutionContext, facts *runner.Facts) (string, error) {
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return "", err
+	}
+	repo := s.Repository
+
+	var isoEntries []string
+	if repo.ISO != "" {
+		var cmd string
+		if facts.PackageManager.Type == runner.PackageManagerApt {
+			codename := ""
+			if facts.OS != nil {
+				codename = facts.OS.Codename
+			}
+			cmd = fmt.Sprintf("find %s -mindepth 1 -maxdepth 1 -type d -printf '%%f\\n'", path.Join(repo.MountPath, "dists", codename))
+		} else {
+			cmd = fmt.Sprintf("find %s -maxdepth 2 -type d -name repodata -printf '%%h\\n'", repo.MountPath)
+		}
+		res, err := runnerSvc.Run(ctx.GoContext(), conn, cmd, s.Sudo)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to list the repositories of ISO '%s'", repo.ISO)
+		}
+		for _, line := range strings.Split(res.Stdout, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if facts.PackageManager.Type != runner.PackageManagerApt {
+				line = strings.TrimPrefix(strings.TrimPrefix(line, repo.MountPath), "/")
+			}
+			isoEntries = append(isoEntries, line)
+		}
+		if len(isoEntries) == 0 {
+			return "", fmt.Errorf("ISO '%s' has no package repository for this host", repo.ISO)
+		}
+		sort.Strings(isoEntries)
+	}
+
+	if facts.PackageManager.Type == runner.PackageManagerApt {
+		codename := ""
+		if facts.OS != nil {
+			codename = facts.OS.Codename
+		}
+		return renderAptSourcesList(repo.MountPath, codename, isoEntries, repo.Mirrors), nil
+	}
+	return renderYumRepoFile(repo.MountPath, isoEntries, repo.Mirrors), nil
+}
+
+func (s *ConfigurePackageRepositoryStep) isISOMounted(ctx runtime.ExecutionContext) (bool, error) {
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	return ctx.GetRunner().Check(ctx.GoContext(), conn, "mountpoint -q "+s.Repository.MountPath, s.Sudo)
+}
+
+func (s *ConfigurePackageRepositoryStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Precheck")
+	if s.Repository == nil {
+		return true, nil
+	}
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return false, err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		return false, err
+	}
+	repoFile, err := packageRepositoryFile(facts.PackageManager.Type)
+	if err != nil {
+		return false, err
+	}
+
+	if s.Repository.ISO != "" {
+		mounted, err := s.isISOMounted(ctx)
+		if err != nil || !mounted {
+			return false, nil
+		}
+	}
+	expected, err := s.renderRepoFile(ctx, facts)
+	if err != nil {
+		return false, err
+	}
+	current, err := runnerSvc.ReadFile(ctx.GoContext(), conn, repoFile)
+	if err != nil || string(current) != expected {
+		return false, nil
+	}
+	if *s.Repository.DisableExternal {
+		for _, glob := range externalRepositoryGlobs(facts.PackageManager.Type) {
+			check := fmt.Sprintf(`sh -c 'for f in %s; do [ -f "$f" ] && [ "$f" != "%s" ] && exit 0; done; exit 1'`, glob, repoFile)
+			if found, _ := runnerSvc.Check(ctx.GoContext(), conn, check, s.Sudo); found {
+				return false, nil
+			}
+		}
+	}
+	logger.Info("Local package repositories are already configured.")
+	return true, nil
+}
+
+func (s *ConfigurePackageRepositoryStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "failed to get host facts")
+		return result, err
+	}
+	repoFile, err := packageRepositoryFile(facts.PackageManager.Type)
+	if err != nil {
+		result.MarkFailed(err, "unsupported package manager")
+		return result, err
+	}
+	repo := s.Repository
+
+	if repo.ISO != "" {
+		mounted, err := s.isISOMounted(ctx)
+		if err != nil {
+			result.MarkFailed(err, "failed to check the ISO mount")
+			return result, err
+		}
+		if !mounted {
+			logger.Info("Mounting ISO.", "iso", repo.ISO, "path", repo.MountPath)
+			cmd := fmt.Sprintf("sh -c 'mkdir -p %s && mount -o loop,ro %s %s'", repo.MountPath, repo.ISO, repo.MountPath)
+			if _, err := runnerSvc.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+				err = errors.Wrapf(err, "failed to mount ISO '%s'", repo.ISO)
+				result.MarkFailed(err, "failed to mount ISO")
+				return result, err
+			}
+			s.mountedISO = true
+		}
+	}
+
+	if facts.PackageManager.Type == runner.PackageManagerApt {
+		for _, mirror := range repo.Mirrors {
+			if mirror.GPGKey == "" {
+				continue
+			}
+			cmd := fmt.Sprintf("sh -c 'mkdir -p %s && curl -fsSL %s -o %s'", aptKeyringsDir, mirror.GPGKey, mirrorKeyringPath(mirror))
+			if _, err := runnerSvc.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+				err = errors.Wrapf(err, "failed to fetch the key of mirror '%s'", mirror.Name)
+				result.MarkFailed(err, "failed to fetch mirror key")
+				return result, err
+			}
+		}
+	}
+
+	content, err := s.renderRepoFile(ctx, facts)
+	if err != nil {
+		result.MarkFailed(err, "failed to render repository file")
+		return result, err
+	}
+	exists, err := runnerSvc.Exists(ctx.GoContext(), conn, repoFile)
+	if err != nil {
+		result.MarkFailed(err, "failed to check repository file")
+		return result, err
+	}
+	s.createdRepoFile = !exists
+	if err := runnerSvc.WriteFile(ctx.GoContext(), conn, []byte(content), repoFile, "0644", s.Sudo); err != nil {
+		err = errors.Wrapf(err, "failed to write '%s'", repoFile)
+		result.MarkFailed(err, "failed to write repository file")
+		return result, err
+	}
+
+	if *repo.DisableExternal {
+		logger.Info("Disabling the other package repositories.")
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, disableExternalRepositoriesCmd(externalRepositoryGlobs(facts.PackageManager.Type), repoFile), s.Sudo); err != nil {
+			result.MarkFailed(err, "failed to disable external repositories")
+			return result, err
+		}
+	}
+
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, refreshCacheCmd(facts.PackageManager.Type), s.Sudo); err != nil {
+		err = errors.Wrap(err, "failed to load the metadata of the local package repositories")
+		result.MarkFailed(err, "failed to refresh package cache")
+		return result, err
+	}
+
+	logger.Info("Local package repositories configured.", "file", repoFile)
+	result.MarkCompleted("local package repositories configured")
+	return result, nil
+}
+
+func (s *ConfigurePackageRepositoryStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	if s.Repository == nil {
+		return nil
+	}
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		return err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		return err
+	}
+	repoFile, err := packageRepositoryFile(facts.PackageManager.Type)
+	if err != nil {
+		return nil
+	}
+
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, restoreExternalRepositoriesCmd(externalRepositoryGlobs(facts.PackageManager.Type)), s.Sudo); err != nil {
+		logger.Warnf("Failed to restore the package repositories: %v", err)
+	}
+	if s.createdRepoFile {
+		if err := runnerSvc.Remove(ctx.GoContext(), conn, repoFile, s.Sudo, false); err != nil {
+			logger.Warnf("Failed to remove '%s': %v", repoFile, err)
+		}
+	}
+	if s.mountedISO {
+		if _, err := runnerSvc.Run(ctx.GoContext(), conn, "umount "+s.Repository.MountPath, s.Sudo); err != nil {
+			logger.Warnf("Failed to unmount '%s': %v", s.Repository.MountPath, err)
+		}
+	}
+	return nil
+}
+
+var _ step.Step = (*ConfigurePackageRepositoryStep)(nil)
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+func TestRenderYumRepoFile(t *testing.T) {
+	mirrors := []v1alpha1.PackageMirror{
+		{Name: "epel", URL: "http://mirror.lab/epel/9/x86_64"},
+		{Name: "docker", URL: "http://mirror.lab/docker", GPGKey: "http://mirror.lab/docker/gpg"},
+	}
+	want := "[kubexm-iso-AppStream]\nname=kubexm ISO AppStream\nbaseurl=file:///mnt/kubexm-iso/AppStream\nenabled=1\ngpgcheck=0\n\n" +
+		"[kubexm-iso-BaseOS]\nname=kubexm ISO BaseOS\nbaseurl=file:///mnt/kubexm-iso/BaseOS\nenabled=1\ngpgcheck=0\n\n" +
+		"[epel]\nname=epel\nbaseurl=http://mirror.lab/epel/9/x86_64\nenabled=1\ngpgcheck=0\n\n" +
+		"[docker]\nname=docker\nbaseurl=http://mirror.lab/docker\nenabled=1\ngpgcheck=1\ngpgkey=http://mirror.lab/docker/gpg\n"
+
+	if got := renderYumRepoFile("/mnt/kubexm-iso", []string{"AppStream", "BaseOS"}, mirrors); got != want {
+		t.Errorf("renderYumRepoFile() = %q, want %q", got, want)
+	}
+
+	want = "[kubexm-iso]\nname=kubexm ISO\nbaseurl=file:///mnt/kubexm-iso\nenabled=1\ngpgcheck=0\n"
+	if got := renderYumRepoFile("/mnt/kubexm-iso", []string{""}, nil); got != want {
+		t.Errorf("renderYumRepoFile() for a flat ISO = %q, want %q", got, want)
+	}
+}
+
+func TestRenderAptSourcesList(t *testing.T) {
+	mirrors := []v1alpha1.PackageMirror{
+		{Name: "ubuntu", URL: "http://mirror.lab/ubuntu", Components: []string{"main", "universe"}},
+		{Name: "docker", URL: "http://mirror.lab/docker", Suite: "stable", GPGKey: "http://mirror.lab/docker/gpg"},
+	}
+	want := "deb [trusted=yes] file:///mnt/kubexm-iso jammy main restricted\n" +
+		"deb [trusted=yes] http://mirror.lab/ubuntu jammy main universe\n" +
+		"deb [signed-by=/etc/apt/keyrings/kubexm-docker.asc] http://mirror.lab/docker stable main\n"
+
+	if got := renderAptSourcesList("/mnt/kubexm-iso", "jammy", []string{"main", "restricted"}, mirrors); got != want {
+		t.Errorf("renderAptSourcesList() = %q, want %q", got, want)
+	}
+}
+
+func TestExternalRepositoriesCmds(t *testing.T) {
+	globs := []string{"/etc/yum.repos.d/*.repo"}
+	disable := `sh -c 'for f in /etc/yum.repos.d/*.repo; do [ -f "$f" ] && [ "$f" != "/etc/yum.repos.d/kubexm-local.repo" ] && mv "$f" "$f.kubexm-disabled"; done; true'`
+	if got := disableExternalRepositoriesCmd(globs, "/etc/yum.repos.d/kubexm-local.repo"); got != disable {
+		t.Errorf("disableExternalRepositoriesCmd() = %s", got)
+	}
+	restore := `sh -c 'for f in /etc/yum.repos.d/*.repo.kubexm-disabled; do [ -f "$f" ] && mv "$f" "${f%.kubexm-disabled}"; done; true'`
+	if got := restoreExternalRepositoriesCmd(globs); got != restore {
+		t.Errorf("restoreExternalRepositoriesCmd() = %s", got)
+	}
+}
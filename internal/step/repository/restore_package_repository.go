@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/pkg/errors"
+)
+
+// RestorePackageRepositoryStep undoes ConfigurePackageRepositoryStep once the packages are
+// installed: the repositories it disabled are enabled again, its repository file is removed and
+// the ISO is unmounted.
+type RestorePackageRepositoryStep struct {
+	step.Base
+	Repository *v1alpha1.PackageRepositorySpec
+}
+
+type RestorePackageRepositoryStepBuilder struct {
+	step.Builder[RestorePackageRepositoryStepBuilder, *RestorePackageRepositoryStep]
+}
+
+func NewRestorePackageRepositoryStepBuilder(ctx runtime.ExecutionContext, instanceName string) *RestorePackageRepositoryStepBuilder {
+	s := &RestorePackageRepositoryStep{
+		Repository: packageRepositorySpec(ctx.GetClusterConfig()),
+	}
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s] >> Restore the original package repositories", instanceName)
+	s.Base.Sudo = true
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+	return new(RestorePackageRepositoryStepBuilder).Init(s)
+}
+
+func (s *RestorePackageRepositoryStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+func (s *RestorePackageRepositoryStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return s.Repository == nil, nil
+}
+
+func (s *RestorePackageRepositoryStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runnerSvc := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get current host connector")
+		return result, err
+	}
+	facts, err := ctx.GetHostFacts(ctx.GetHost())
+	if err != nil {
+		result.MarkFailed(err, "failed to get host facts")
+		return result, err
+	}
+	repoFile, err := packageRepositoryFile(facts.PackageManager.Type)
+	if err != nil {
+		result.MarkFailed(err, "unsupported package manager")
+		return result, err
+	}
+
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, restoreExternalRepositoriesCmd(externalRepositoryGlobs(facts.PackageManager.Type)), s.Sudo); err != nil {
+		err = errors.Wrap(err, "failed to enable the original package repositories")
+		result.MarkFailed(err, "failed to restore repositories")
+		return result, err
+	}
+	if err := runnerSvc.Remove(ctx.GoContext(), conn, repoFile, s.Sudo, false); err != nil {
+		err = errors.Wrapf(err, "failed to remove '%s'", repoFile)
+		result.MarkFailed(err, "failed to remove repository file")
+		return result, err
+	}
+	if s.Repository.ISO != "" {
+		if mounted, _ := runnerSvc.Check(ctx.GoContext(), conn, "mountpoint -q "+s.Repository.MountPath, s.Sudo); mounted {
+			if _, err := runnerSvc.Run(ctx.GoContext(), conn, "umount "+s.Repository.MountPath, s.Sudo); err != nil {
+				err = errors.Wrapf(err, "failed to unmount '%s'", s.Repository.MountPath)
+				result.MarkFailed(err, "failed to unmount ISO")
+				return result, err
+			}
+		}
+	}
+
+	// The original repositories may not be reachable from here, so a failed refresh only leaves
+	// a stale cache behind.
+	if _, err := runnerSvc.Run(ctx.GoContext(), conn, refreshCacheCmd(facts.PackageManager.Type), s.Sudo); err != nil {
+		logger.Warnf("Failed to refresh the package cache: %v", err)
+	}
+
+	logger.Info("Original package repositories restored.")
+	result.MarkCompleted("original package repositories restored")
+	return result, nil
+}
+
+func (s *RestorePackageRepositoryStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	logger.Info("Rollback for RestorePackageRepositoryStep is a no-op.")
+	return nil
+}
+
+var _ step.Step = (*RestorePackageRepositoryStep)(nil)
@@ -0,0 +1,158 @@
+package localpathprovisioner
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/templates"
+	"github.com/mensylisir/kubexm/internal/types"
+	"github.com/mensylisir/kubexm/internal/util/images"
+)
+
+const manifestTemplate = "storage/local-path-provisioner/local-path-storage.yaml.tmpl"
+
+// InstallLocalPathProvisionerStep renders the local-path-provisioner manifests, uploads them and applies them with kubectl.
+type InstallLocalPathProvisionerStep struct {
+	step.Base
+	Namespace           string
+	StoragePath         string
+	StorageClassName    string
+	IsDefaultClass      bool
+	ProvisionerImage    string
+	HelperImage         string
+	RemoteManifestPath  string
+	AdminKubeconfigPath string
+}
+
+type InstallLocalPathProvisionerStepBuilder struct {
+	step.Builder[InstallLocalPathProvisionerStepBuilder, *InstallLocalPathProvisionerStep]
+}
+
+func NewInstallLocalPathProvisionerStepBuilder(ctx runtime.ExecutionContext, instanceName string) *InstallLocalPathProvisionerStepBuilder {
+	s := &InstallLocalPathProvisionerStep{
+		Namespace:        common.DefaultLocalPathProvisionerNamespace,
+		StoragePath:      common.DefaultLocalPathProvisionerStoragePath,
+		StorageClassName: common.DefaultLocalPathProvisionerStorageClassName,
+		IsDefaultClass:   common.DefaultLocalPathProvisionerIsDefaultClass,
+	}
+	if storage := ctx.GetClusterConfig().Spec.Storage; storage != nil && storage.LocalPathProvisioner != nil {
+		cfg := storage.LocalPathProvisioner
+		if cfg.StoragePath != nil {
+			s.StoragePath = *cfg.StoragePath
+		}
+		if cfg.StorageClassName != nil {
+			s.StorageClassName = *cfg.StorageClassName
+		}
+		if cfg.IsDefaultClass != nil {
+			s.IsDefaultClass = *cfg.IsDefaultClass
+		}
+	}
+
+	imageProvider := images.NewImageProvider(ctx)
+	provisionerImage := imageProvider.GetImage("local-path-provisioner")
+	helperImage := imageProvider.GetImage("local-path-helper")
+	if provisionerImage == nil || helperImage == nil {
+		ctx.GetLogger().Errorf("local-path-provisioner is enabled but its images are not found in BOM for K8s version %s", ctx.GetClusterConfig().Spec.Kubernetes.Version)
+		return nil
+	}
+	s.ProvisionerImage = provisionerImage.FullName()
+	s.HelperImage = helperImage.FullName()
+
+	s.Base.Meta.Name = instanceName
+	s.Base.Meta.Description = fmt.Sprintf("[%s]>>Install or upgrade local-path-provisioner by applying its manifests", s.Base.Meta.Name)
+	s.Base.Sudo = false
+	s.Base.IgnoreError = false
+	s.Base.Timeout = 5 * time.Minute
+
+	s.RemoteManifestPath = filepath.Join(ctx.GetUploadDir(), common.AddonLocalPathProvisioner, "local-path-storage.yaml")
+	s.AdminKubeconfigPath = filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+
+	b := new(InstallLocalPathProvisionerStepBuilder).Init(s)
+	return b
+}
+
+func (s *InstallLocalPathProvisionerStep) Meta() *spec.StepMeta {
+	return &s.Base.Meta
+}
+
+// renderManifest renders the provisioner manifests from the step configuration.
+func (s *InstallLocalPathProvisionerStep) renderManifest() ([]byte, error) {
+	content, err := templates.Get(manifestTemplate)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("localPathStorage").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", manifestTemplate, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *InstallLocalPathProvisionerStep) Precheck(ctx runtime.ExecutionContext) (isDone bool, err error) {
+	return false, nil
+}
+
+func (s *InstallLocalPathProvisionerStep) Run(ctx runtime.ExecutionContext) (*types.StepResult, error) {
+	result := types.NewStepResult(s.Base.Meta.Name, ctx.GetStepExecutionID(), ctx.GetHost())
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Run")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		result.MarkFailed(err, "failed to get connector")
+		return result, err
+	}
+
+	manifest, err := s.renderManifest()
+	if err != nil {
+		result.MarkFailed(err, "failed to render local-path-provisioner manifests")
+		return result, err
+	}
+	if err := runner.Mkdirp(ctx.GoContext(), conn, filepath.Dir(s.RemoteManifestPath), "0755", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to create remote manifest directory")
+		return result, err
+	}
+	if err := runner.WriteFile(ctx.GoContext(), conn, manifest, s.RemoteManifestPath, "0644", s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to upload local-path-provisioner manifests")
+		return result, err
+	}
+
+	cmd := fmt.Sprintf("kubectl apply -f %s --kubeconfig %s", s.RemoteManifestPath, s.AdminKubeconfigPath)
+	logger.Info("Applying local-path-provisioner manifests.", "command", cmd, "storagePath", s.StoragePath, "defaultClass", s.IsDefaultClass)
+	if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		result.MarkFailed(err, "failed to apply local-path-provisioner manifests")
+		return result, err
+	}
+
+	result.MarkCompleted(fmt.Sprintf("local-path-provisioner installed with StorageClass %s", s.StorageClassName))
+	return result, nil
+}
+
+func (s *InstallLocalPathProvisionerStep) Rollback(ctx runtime.ExecutionContext) error {
+	logger := ctx.GetLogger().With("step", s.Base.Meta.Name, "host", ctx.GetHost().GetName(), "phase", "Rollback")
+	runner := ctx.GetRunner()
+	conn, err := ctx.GetCurrentHostConnector()
+	if err != nil {
+		logger.Error(err, "Failed to get connector for rollback.")
+		return nil
+	}
+
+	cmd := fmt.Sprintf("kubectl delete -f %s --kubeconfig %s --ignore-not-found=true", s.RemoteManifestPath, s.AdminKubeconfigPath)
+	logger.Warn("Rolling back by deleting local-path-provisioner resources.")
+	if _, err := runner.Run(ctx.GoContext(), conn, cmd, s.Sudo); err != nil {
+		logger.Error(err, "Failed to delete local-path-provisioner resources.")
+	}
+	return nil
+}
+
+var _ step.Step = (*InstallLocalPathProvisionerStep)(nil)
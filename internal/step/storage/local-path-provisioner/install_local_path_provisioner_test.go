@@ -0,0 +1,93 @@
+package localpathprovisioner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type manifestObject struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Data map[string]string `yaml:"data"`
+}
+
+func decodeManifest(t *testing.T, manifest []byte) map[string]manifestObject {
+	t.Helper()
+	objects := map[string]manifestObject{}
+	decoder := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var obj manifestObject
+		err := decoder.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("rendered manifest is not valid YAML: %v", err)
+		}
+		objects[obj.Kind+"/"+obj.Metadata.Name] = obj
+	}
+	return objects
+}
+
+func TestRenderManifest(t *testing.T) {
+	tests := []struct {
+		name             string
+		isDefaultClass   bool
+		expectAnnotation bool
+	}{
+		{name: "default class", isDefaultClass: true, expectAnnotation: true},
+		{name: "not the default class", isDefaultClass: false, expectAnnotation: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &InstallLocalPathProvisionerStep{
+				Namespace:        "local-path-storage",
+				StoragePath:      "/data/local-path",
+				StorageClassName: "fast-local",
+				IsDefaultClass:   tt.isDefaultClass,
+				ProvisionerImage: "registry.local/rancher/local-path-provisioner:v0.0.28",
+				HelperImage:      "registry.local/library/busybox:1.36.1",
+			}
+			manifest, err := s.renderManifest()
+			if err != nil {
+				t.Fatalf("renderManifest failed: %v", err)
+			}
+			objects := decodeManifest(t, manifest)
+
+			configMap, ok := objects["ConfigMap/local-path-config"]
+			if !ok {
+				t.Fatalf("expected the provisioner ConfigMap, got %v", objects)
+			}
+			var config struct {
+				NodePathMap []struct {
+					Node  string   `json:"node"`
+					Paths []string `json:"paths"`
+				} `json:"nodePathMap"`
+			}
+			if err := json.Unmarshal([]byte(configMap.Data["config.json"]), &config); err != nil {
+				t.Fatalf("config.json is not valid JSON: %v", err)
+			}
+			if len(config.NodePathMap) != 1 || len(config.NodePathMap[0].Paths) != 1 || config.NodePathMap[0].Paths[0] != "/data/local-path" {
+				t.Errorf("expected the configured storage path in config.json, got %+v", config.NodePathMap)
+			}
+
+			storageClass, ok := objects["StorageClass/fast-local"]
+			if !ok {
+				t.Fatalf("expected the fast-local StorageClass, got %v", objects)
+			}
+			got := storageClass.Metadata.Annotations["storageclass.kubernetes.io/is-default-class"] == "true"
+			if got != tt.expectAnnotation {
+				t.Errorf("expected default-class annotation=%v, got annotations %v", tt.expectAnnotation, storageClass.Metadata.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package addon
+
+import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+// certManagerChartValues installs the cert-manager CRDs with the chart, so Issuers and Certificates
+// can be created as soon as the webhook is ready.
+func certManagerChartValues(cluster *v1alpha1.Cluster) ([]string, error) {
+	return []string{"installCRDs=true"}, nil
+}
@@ -0,0 +1,100 @@
+package addon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	addonstep "github.com/mensylisir/kubexm/internal/step/addon"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// InstallChartAddonTask installs a built-in addon from the chart of its definition, then waits
+// for the workloads listed in the definition's readiness checks to roll out.
+type InstallChartAddonTask struct {
+	task.Base
+	Addon      *v1alpha1.Addon
+	Definition Definition
+}
+
+func NewInstallChartAddonTask(addon *v1alpha1.Addon, def Definition) task.Task {
+	return &InstallChartAddonTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        fmt.Sprintf("InstallAddon-%s", addon.Name),
+				Description: fmt.Sprintf("Install the '%s' addon and wait for it to become ready", addon.Name),
+			},
+		},
+		Addon:      addon,
+		Definition: def,
+	}
+}
+
+func (t *InstallChartAddonTask) Name() string        { return t.Meta.Name }
+func (t *InstallChartAddonTask) Description() string { return t.Meta.Description }
+
+func (t *InstallChartAddonTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return t.Addon != nil && (t.Addon.Enabled == nil || *t.Addon.Enabled), nil
+}
+
+func (t *InstallChartAddonTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	if t.Definition.Values != nil {
+		values, err := t.Definition.Values(ctx.GetClusterConfig())
+		if err != nil {
+			return nil, err
+		}
+		t.Addon.Sources[0].Chart.Values = values
+	}
+
+	fragment, err := NewInstallAddonTask(t.Addon).Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Definition.Readiness) == 0 {
+		return fragment, nil
+	}
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to verify %s", t.Addon.Name)
+	}
+	host := masterHosts[0]
+	execCtx := runtime.ForHost(ctx.ForTask(t.Name()), host)
+
+	timeout := time.Duration(common.DefaultAddonTimeoutSeconds) * time.Second
+	if t.Addon.TimeoutSeconds != nil && *t.Addon.TimeoutSeconds > 0 {
+		timeout = time.Duration(*t.Addon.TimeoutSeconds) * time.Second
+	}
+
+	installExitNodes := fragment.ExitNodes
+	for _, resource := range t.Definition.Readiness {
+		nodeName := fmt.Sprintf("WaitReady-%s-%s", t.Addon.Name, strings.ReplaceAll(resource, "/", "-"))
+		waitStep, err := addonstep.NewWaitForRolloutStepBuilder(execCtx, nodeName).
+			WithResource(t.Definition.Namespace, resource).
+			WithTimeout(timeout).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		waitNodeID, err := fragment.AddNode(&plan.ExecutionNode{Name: nodeName, Step: waitStep, Hosts: []remotefw.Host{host}})
+		if err != nil {
+			return nil, err
+		}
+		for _, exitNode := range installExitNodes {
+			if err := fragment.AddDependency(exitNode, waitNodeID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*InstallChartAddonTask)(nil)
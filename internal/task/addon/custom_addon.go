@@ -0,0 +1,163 @@
+package addon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	addonstep "github.com/mensylisir/kubexm/internal/step/addon"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// InstallCustomAddonTask installs an addon declared with its own sources from the first master:
+// charts from a repository, an OCI reference or a path, and manifests from URLs, files or a local
+// directory, one source after another. The workloads of addon.wait are checked last.
+type InstallCustomAddonTask struct {
+	task.Base
+	Addon *v1alpha1.Addon
+}
+
+func NewInstallCustomAddonTask(addon *v1alpha1.Addon) task.Task {
+	return &InstallCustomAddonTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        fmt.Sprintf("InstallAddon-%s", addon.Name),
+				Description: fmt.Sprintf("Install the custom '%s' addon to the cluster", addon.Name),
+			},
+		},
+		Addon: addon,
+	}
+}
+
+func (t *InstallCustomAddonTask) Name() string        { return t.Meta.Name }
+func (t *InstallCustomAddonTask) Description() string { return t.Meta.Description }
+
+func (t *InstallCustomAddonTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return t.Addon != nil && (t.Addon.Enabled == nil || *t.Addon.Enabled), nil
+}
+
+func (t *InstallCustomAddonTask) timeout() time.Duration {
+	if t.Addon.TimeoutSeconds != nil && *t.Addon.TimeoutSeconds > 0 {
+		return time.Duration(*t.Addon.TimeoutSeconds) * time.Second
+	}
+	return time.Duration(common.DefaultAddonTimeoutSeconds) * time.Second
+}
+
+// chartRef returns the chart helm installs and the repository it is looked up in. OCI charts are
+// referenced directly and repository charts are installed with --repo, so no repository is added on the host.
+func chartRef(chart *v1alpha1.ChartSource) (string, string) {
+	switch {
+	case chart.Path != "":
+		return chart.Path, ""
+	case strings.HasPrefix(chart.Repo, "oci://"):
+		return strings.TrimSuffix(chart.Repo, "/") + "/" + chart.Name, ""
+	default:
+		return chart.Name, chart.Repo
+	}
+}
+
+func (t *InstallCustomAddonTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to install addon %s", t.Addon.Name)
+	}
+	host := masterHosts[0]
+	hosts := []remotefw.Host{host}
+	execCtx := runtime.ForHost(ctx.ForTask(t.Name()), host)
+
+	var previous plan.NodeID
+
+	for i := range t.Addon.Sources {
+		source := &t.Addon.Sources[i]
+		var nodeName string
+		var node *plan.ExecutionNode
+		switch {
+		case source.Chart != nil:
+			nodeName = fmt.Sprintf("InstallChart-%s-%d", t.Addon.Name, i)
+			var valuesFiles []string
+			if source.Chart.ValuesFile != "" {
+				valuesFiles = append(valuesFiles, source.Chart.ValuesFile)
+			}
+			valuesFiles = append(valuesFiles, source.Chart.ValuesFiles...)
+			releaseName := source.Chart.Name
+			if releaseName == "" {
+				releaseName = t.Addon.Name
+			}
+			chart, repo := chartRef(source.Chart)
+			installStep, err := addonstep.NewDeployAddonChartStepBuilder(execCtx, nodeName).
+				WithRelease(source.Namespace, releaseName).
+				WithChart(chart, repo, source.Chart.Version).
+				WithValues(valuesFiles, append(append([]string{}, source.Chart.Values...), t.Addon.Values...)).
+				WithRemoteValuesDir(filepath.Join(execCtx.GetUploadDir(), "addons", t.Addon.Name)).
+				WithTimeout(t.timeout()).
+				Build()
+			if err != nil {
+				return nil, err
+			}
+			node = &plan.ExecutionNode{Name: nodeName, Step: installStep, Hosts: hosts}
+		case source.Yaml != nil:
+			nodeName = fmt.Sprintf("ApplyManifests-%s-%d", t.Addon.Name, i)
+			applyStep, err := addonstep.NewApplyAddonManifestsStepBuilder(execCtx, nodeName).
+				WithNamespace(source.Namespace).
+				WithManifests(source.Yaml.Path, source.Yaml.Directory).
+				WithTimeout(t.timeout()).
+				Build()
+			if err != nil {
+				return nil, err
+			}
+			node = &plan.ExecutionNode{Name: nodeName, Step: applyStep, Hosts: hosts}
+		default:
+			return nil, fmt.Errorf("addon '%s' source %d has no supported type (yaml or chart)", t.Addon.Name, i)
+		}
+
+		nodeID, err := fragment.AddNode(node)
+		if err != nil {
+			return nil, err
+		}
+		if previous != "" {
+			if err := fragment.AddDependency(previous, nodeID); err != nil {
+				return nil, err
+			}
+		}
+		previous = nodeID
+	}
+
+	if wait := t.Addon.Wait; wait != nil && previous != "" {
+		namespace := wait.Namespace
+		if namespace == "" {
+			namespace = t.Addon.Sources[0].Namespace
+		}
+		for _, resource := range wait.Resources {
+			nodeName := fmt.Sprintf("WaitReady-%s-%s", t.Addon.Name, strings.ReplaceAll(resource, "/", "-"))
+			waitStep, err := addonstep.NewWaitForRolloutStepBuilder(execCtx, nodeName).
+				WithResource(namespace, resource).
+				WithCondition(wait.Condition).
+				WithTimeout(t.timeout()).
+				Build()
+			if err != nil {
+				return nil, err
+			}
+			waitNodeID, err := fragment.AddNode(&plan.ExecutionNode{Name: nodeName, Step: waitStep, Hosts: hosts})
+			if err != nil {
+				return nil, err
+			}
+			if err := fragment.AddDependency(previous, waitNodeID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*InstallCustomAddonTask)(nil)
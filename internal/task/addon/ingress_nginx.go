@@ -0,0 +1,119 @@
+package addon
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	addonstep "github.com/mensylisir/kubexm/internal/step/addon"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// InstallIngressNginxTask installs the pinned ingress-nginx chart exposed as configured in
+// spec.gateway.ingressNginx, then waits for the controller to roll out.
+type InstallIngressNginxTask struct {
+	task.Base
+	Addon *v1alpha1.Addon
+}
+
+func NewInstallIngressNginxTask(addon *v1alpha1.Addon) task.Task {
+	return &InstallIngressNginxTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        fmt.Sprintf("InstallAddon-%s", addon.Name),
+				Description: "Install the ingress-nginx controller and wait for it to become ready",
+			},
+		},
+		Addon: addon,
+	}
+}
+
+func (t *InstallIngressNginxTask) Name() string        { return t.Meta.Name }
+func (t *InstallIngressNginxTask) Description() string { return t.Meta.Description }
+
+func (t *InstallIngressNginxTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return t.Addon != nil && (t.Addon.Enabled == nil || *t.Addon.Enabled), nil
+}
+
+func (t *InstallIngressNginxTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	cfg := &v1alpha1.IngressNginxSpec{}
+	if gw := ctx.GetClusterConfig().Spec.Gateway; gw != nil && gw.IngressNginx != nil {
+		cfg = gw.IngressNginx
+	}
+	v1alpha1.SetDefaults_IngressNginxSpec(cfg)
+	t.Addon.Sources[0].Chart.Values = ingressNginxChartValues(cfg)
+
+	fragment, err := NewInstallAddonTask(t.Addon).Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to verify %s", common.AddonIngressNginx)
+	}
+	host := masterHosts[0]
+
+	// The chart names the controller after the release, which is the chart name for addon installs.
+	controller := "deployment/ingress-nginx-controller"
+	if cfg.ServiceType == common.IngressNginxServiceTypeHostNetwork {
+		controller = "daemonset/ingress-nginx-controller"
+	}
+	waitStep, err := addonstep.NewWaitForRolloutStepBuilder(runtime.ForHost(ctx.ForTask(t.Name()), host), "WaitIngressNginxReady").
+		WithResource(t.Addon.Sources[0].Namespace, controller).
+		WithTimeout(common.DefaultIngressNginxReadyTimeout).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	installExitNodes := fragment.ExitNodes
+	waitNodeID, err := fragment.AddNode(&plan.ExecutionNode{Name: "WaitIngressNginxReady", Step: waitStep, Hosts: []remotefw.Host{host}})
+	if err != nil {
+		return nil, err
+	}
+	for _, exitNode := range installExitNodes {
+		if err := fragment.AddDependency(exitNode, waitNodeID); err != nil {
+			return nil, err
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// ingressNginxChartValues returns the chart values exposing the controller with the configured service type and ports.
+func ingressNginxChartValues(cfg *v1alpha1.IngressNginxSpec) []string {
+	httpPort, httpsPort := *cfg.HTTPPort, *cfg.HTTPSPort
+	switch cfg.ServiceType {
+	case common.IngressNginxServiceTypeHostNetwork:
+		// A DaemonSet on the host network serves every node directly, so no Service is needed.
+		return []string{
+			"controller.kind=DaemonSet",
+			"controller.hostNetwork=true",
+			"controller.dnsPolicy=ClusterFirstWithHostNet",
+			"controller.service.enabled=false",
+			fmt.Sprintf("controller.containerPort.http=%d", httpPort),
+			fmt.Sprintf("controller.containerPort.https=%d", httpsPort),
+			fmt.Sprintf("controller.extraArgs.http-port=%d", httpPort),
+			fmt.Sprintf("controller.extraArgs.https-port=%d", httpsPort),
+		}
+	case common.IngressNginxServiceTypeLoadBalancer:
+		return []string{
+			"controller.service.type=LoadBalancer",
+			fmt.Sprintf("controller.service.ports.http=%d", httpPort),
+			fmt.Sprintf("controller.service.ports.https=%d", httpsPort),
+		}
+	default:
+		return []string{
+			"controller.service.type=NodePort",
+			fmt.Sprintf("controller.service.nodePorts.http=%d", httpPort),
+			fmt.Sprintf("controller.service.nodePorts.https=%d", httpsPort),
+		}
+	}
+}
+
+var _ task.Task = (*InstallIngressNginxTask)(nil)
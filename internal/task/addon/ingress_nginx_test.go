@@ -0,0 +1,151 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runner"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	addonstep "github.com/mensylisir/kubexm/internal/step/addon"
+	"github.com/mensylisir/kubexm/internal/step/helm"
+)
+
+func planIngressNginx(t *testing.T, gateway *v1alpha1.IngressNginxSpec) *plan.ExecutionFragment {
+	t.Helper()
+	ctx := newAddonTestContext(t, v1alpha1.Addon{Name: common.AddonIngressNginx})
+	if gateway != nil {
+		v1alpha1.SetDefaults_IngressNginxSpec(gateway)
+		ctx.GetClusterConfig().Spec.Gateway.IngressNginx = gateway
+	}
+	addonTask, err := NewAddonTask(&ctx.GetClusterConfig().Spec.Addons[0])
+	if err != nil {
+		t.Fatalf("NewAddonTask failed: %v", err)
+	}
+	fragment, err := addonTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	return fragment
+}
+
+func TestInstallIngressNginxTask_ServiceTypeAndPorts(t *testing.T) {
+	port := func(p int32) *int32 { return &p }
+	tests := []struct {
+		name         string
+		gateway      *v1alpha1.IngressNginxSpec
+		expectedArgs []string
+		waitsFor     string
+	}{
+		{
+			name:         "defaults to NodePort",
+			expectedArgs: []string{"controller.service.type=NodePort", "controller.service.nodePorts.http=30080", "controller.service.nodePorts.https=30443"},
+			waitsFor:     "deployment/ingress-nginx-controller",
+		},
+		{
+			name:         "NodePort with custom ports",
+			gateway:      &v1alpha1.IngressNginxSpec{ServiceType: common.IngressNginxServiceTypeNodePort, HTTPPort: port(31080), HTTPSPort: port(31443)},
+			expectedArgs: []string{"controller.service.type=NodePort", "controller.service.nodePorts.http=31080", "controller.service.nodePorts.https=31443"},
+			waitsFor:     "deployment/ingress-nginx-controller",
+		},
+		{
+			name:         "LoadBalancer",
+			gateway:      &v1alpha1.IngressNginxSpec{ServiceType: common.IngressNginxServiceTypeLoadBalancer, HTTPSPort: port(8443)},
+			expectedArgs: []string{"controller.service.type=LoadBalancer", "controller.service.ports.http=80", "controller.service.ports.https=8443"},
+			waitsFor:     "deployment/ingress-nginx-controller",
+		},
+		{
+			name:         "HostNetwork",
+			gateway:      &v1alpha1.IngressNginxSpec{ServiceType: common.IngressNginxServiceTypeHostNetwork, HTTPPort: port(8080)},
+			expectedArgs: []string{"controller.hostNetwork=true", "controller.kind=DaemonSet", "controller.containerPort.http=8080", "controller.extraArgs.http-port=8080", "controller.containerPort.https=443"},
+			waitsFor:     "daemonset/ingress-nginx-controller",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragment := planIngressNginx(t, tt.gateway)
+
+			installStep := fragment.Nodes["InstallChart-ingress-nginx-helm-0"].Step.(*helm.InstallChartStep)
+			if installStep.Version != common.DefaultIngressNginxChartVersion {
+				t.Errorf("expected chart version pinned to %s, got %q", common.DefaultIngressNginxChartVersion, installStep.Version)
+			}
+			args := strings.Join(installStep.ExtraArgs, " ")
+			for _, expected := range tt.expectedArgs {
+				if !strings.Contains(args, fmt.Sprintf("--set '%s'", expected)) {
+					t.Errorf("expected %q in the chart args, got %q", expected, args)
+				}
+			}
+
+			waitNode, ok := fragment.Nodes["WaitIngressNginxReady"]
+			if !ok {
+				t.Fatalf("expected a WaitIngressNginxReady node, got %v", fragment.Nodes)
+			}
+			if len(waitNode.Dependencies) != 1 || waitNode.Dependencies[0] != "InstallChart-ingress-nginx-helm-0" {
+				t.Errorf("expected the readiness wait to run after the chart install, got %v", waitNode.Dependencies)
+			}
+			if waitStep, ok := waitNode.Step.(*addonstep.WaitForRolloutStep); !ok || waitStep.Resource != tt.waitsFor {
+				t.Errorf("expected the readiness wait on %s, got %+v", tt.waitsFor, waitNode.Step)
+			}
+		})
+	}
+}
+
+// fakeHelmRunner answers helm queries as if ingress-nginx had already been installed.
+type fakeHelmRunner struct {
+	runner.Runner
+	deployedVersion string
+}
+
+func (r *fakeHelmRunner) LookPath(ctx context.Context, conn connector.Connector, file string) (string, error) {
+	return "/usr/local/bin/" + file, nil
+}
+
+func (r *fakeHelmRunner) Run(ctx context.Context, conn connector.Connector, cmd string, sudo bool) (*runner.CommandResult, error) {
+	switch {
+	case cmd == "helm repo list":
+		return &runner.CommandResult{Stdout: "NAME\tURL\ningress-nginx\t" + common.DefaultIngressNginxChartRepo + "\n"}, nil
+	case strings.HasPrefix(cmd, "helm status ingress-nginx"):
+		return &runner.CommandResult{Stdout: fmt.Sprintf(`{"name":"ingress-nginx","info":{"status":"deployed"},"chart":{"metadata":{"version":"%s"}}}`, r.deployedVersion)}, nil
+	}
+	return nil, errors.New("unexpected command: " + cmd)
+}
+
+func TestInstallIngressNginxTask_RerunIsNoop(t *testing.T) {
+	tests := []struct {
+		name            string
+		deployedVersion string
+		expectDone      bool
+	}{
+		{name: "pinned version already deployed", deployedVersion: common.DefaultIngressNginxChartVersion, expectDone: true},
+		{name: "older version deployed", deployedVersion: "4.9.0", expectDone: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragment := planIngressNginx(t, nil)
+			ctx := &runtimetest.Context{
+				Runner: &fakeHelmRunner{deployedVersion: tt.deployedVersion},
+				Host:   connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1"}),
+			}
+
+			repoDone, err := fragment.Nodes["AddRepo-ingress-nginx-helm-0"].Step.Precheck(ctx)
+			if err != nil || !repoDone {
+				t.Errorf("expected the existing helm repo to be kept, got done=%v err=%v", repoDone, err)
+			}
+			installDone, err := fragment.Nodes["InstallChart-ingress-nginx-helm-0"].Step.Precheck(ctx)
+			if err != nil {
+				t.Fatalf("install precheck failed: %v", err)
+			}
+			if installDone != tt.expectDone {
+				t.Errorf("expected install precheck done=%v, got %v", tt.expectDone, installDone)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package addon
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/remotefw"
@@ -219,7 +220,7 @@ func (t *InstallAddonTask) planHelmSource(ctx *runtime.Context, index int, sourc
 		WithNamespace(namespace).
 		WithVersion(helm.Version).
 		WithValuesFile(helm.ValuesFile).
-		WithExtraArgs(helm.Values).
+		WithExtraArgs(helmSetArgs(append(append([]string{}, helm.Values...), t.Addon.Values...))).
 		Build()
 	if err != nil {
 		return nil, err
@@ -234,3 +235,12 @@ func (t *InstallAddonTask) planHelmSource(ctx *runtime.Context, index int, sourc
 	frag.CalculateEntryAndExitNodes()
 	return frag, nil
 }
+
+// helmSetArgs turns the key=value chart values of an addon source into quoted --set arguments.
+func helmSetArgs(values []string) []string {
+	args := make([]string, 0, len(values))
+	for _, value := range values {
+		args = append(args, "--set '"+strings.ReplaceAll(value, "'", `'\''`)+"'")
+	}
+	return args
+}
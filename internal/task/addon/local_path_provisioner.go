@@ -0,0 +1,66 @@
+package addon
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	localpathstep "github.com/mensylisir/kubexm/internal/step/storage/local-path-provisioner"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// InstallLocalPathProvisionerTask applies the local-path-provisioner manifests from the first master,
+// using the node storage path and StorageClass settings of spec.storage.localPathProvisioner.
+type InstallLocalPathProvisionerTask struct {
+	task.Base
+	Addon *v1alpha1.Addon
+}
+
+func NewInstallLocalPathProvisionerTask(addon *v1alpha1.Addon) task.Task {
+	return &InstallLocalPathProvisionerTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        fmt.Sprintf("InstallAddon-%s", addon.Name),
+				Description: "Install local-path-provisioner and its StorageClass",
+			},
+		},
+		Addon: addon,
+	}
+}
+
+func (t *InstallLocalPathProvisionerTask) Name() string        { return t.Meta.Name }
+func (t *InstallLocalPathProvisionerTask) Description() string { return t.Meta.Description }
+
+func (t *InstallLocalPathProvisionerTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return t.Addon != nil && (t.Addon.Enabled == nil || *t.Addon.Enabled), nil
+}
+
+func (t *InstallLocalPathProvisionerTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to install %s", common.AddonLocalPathProvisioner)
+	}
+	host := masterHosts[0]
+
+	builder := localpathstep.NewInstallLocalPathProvisionerStepBuilder(runtime.ForHost(execCtx, host), "InstallLocalPathProvisioner")
+	if builder == nil {
+		return nil, fmt.Errorf("images for %s are not available for this Kubernetes version", common.AddonLocalPathProvisioner)
+	}
+	installStep, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	fragment.AddNode(&plan.ExecutionNode{Name: "InstallLocalPathProvisioner", Step: installStep, Hosts: []remotefw.Host{host}})
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*InstallLocalPathProvisionerTask)(nil)
@@ -0,0 +1,115 @@
+package addon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	addonstep "github.com/mensylisir/kubexm/internal/step/addon"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// InstallMetricsServerTask installs the metrics-server chart with the kubelet flags of
+// spec.kubernetes.addons.metricsServer, then checks that `kubectl top nodes` returns data.
+type InstallMetricsServerTask struct {
+	task.Base
+	Addon *v1alpha1.Addon
+}
+
+func NewInstallMetricsServerTask(addon *v1alpha1.Addon) task.Task {
+	return &InstallMetricsServerTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        fmt.Sprintf("InstallAddon-%s", addon.Name),
+				Description: "Install metrics-server and verify node metrics are served",
+			},
+		},
+		Addon: addon,
+	}
+}
+
+func (t *InstallMetricsServerTask) Name() string        { return t.Meta.Name }
+func (t *InstallMetricsServerTask) Description() string { return t.Meta.Description }
+
+func (t *InstallMetricsServerTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return t.Addon != nil && (t.Addon.Enabled == nil || *t.Addon.Enabled), nil
+}
+
+func (t *InstallMetricsServerTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	var cfg *v1alpha1.MetricsServerConfig
+	if k8s := ctx.GetClusterConfig().Spec.Kubernetes; k8s != nil && k8s.Addons != nil {
+		cfg = k8s.Addons.MetricsServer
+	}
+	t.Addon.Sources[0].Chart.Values = metricsServerChartValues(cfg)
+
+	fragment, err := NewInstallAddonTask(t.Addon).Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to verify %s", common.AddonMetricsServer)
+	}
+	host := masterHosts[0]
+
+	retries, delay := common.DefaultMetricsServerVerifyRetries, common.DefaultMetricsServerVerifyDelay
+	if t.Addon.Retries != nil && *t.Addon.Retries > 0 {
+		retries = int(*t.Addon.Retries)
+		if t.Addon.Delay != nil {
+			delay = time.Duration(*t.Addon.Delay) * time.Second
+		}
+	}
+	verifyStep, err := addonstep.NewVerifyMetricsServerStepBuilder(runtime.ForHost(ctx.ForTask(t.Name()), host), "VerifyMetricsServer").
+		WithRetries(retries).
+		WithDelay(delay).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	installExitNodes := fragment.ExitNodes
+	verifyNodeID, err := fragment.AddNode(&plan.ExecutionNode{Name: "VerifyMetricsServer", Step: verifyStep, Hosts: []remotefw.Host{host}})
+	if err != nil {
+		return nil, err
+	}
+	for _, exitNode := range installExitNodes {
+		if err := fragment.AddDependency(exitNode, verifyNodeID); err != nil {
+			return nil, err
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// metricsServerChartValues returns the chart values adding the configured kubelet flags to the metrics-server args.
+func metricsServerChartValues(cfg *v1alpha1.MetricsServerConfig) []string {
+	var args []string
+	insecureTLS := common.DefaultMetricsServerKubeletInsecureTLS
+	if cfg != nil && cfg.KubeletInsecureTLS != nil {
+		insecureTLS = *cfg.KubeletInsecureTLS
+	}
+	if insecureTLS {
+		args = append(args, "--kubelet-insecure-tls")
+	}
+	addressTypes := common.DefaultMetricsServerPreferredAddressTypes
+	if cfg != nil && len(cfg.PreferredAddressTypes) > 0 {
+		addressTypes = cfg.PreferredAddressTypes
+	}
+	// Helm splits --set values on commas, so the address type list has to be escaped.
+	args = append(args, "--kubelet-preferred-address-types="+strings.Join(addressTypes, `\,`))
+
+	values := make([]string, 0, len(args))
+	for i, arg := range args {
+		values = append(values, fmt.Sprintf("args[%d]=%s", i, arg))
+	}
+	return values
+}
+
+var _ task.Task = (*InstallMetricsServerTask)(nil)
@@ -0,0 +1,62 @@
+package addon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/step/helm"
+)
+
+func TestInstallMetricsServerTask_Plan(t *testing.T) {
+	tests := []struct {
+		name        string
+		insecureTLS bool
+	}{
+		{name: "kubelet insecure TLS enabled", insecureTLS: true},
+		{name: "kubelet insecure TLS disabled", insecureTLS: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newAddonTestContext(t, v1alpha1.Addon{Name: common.AddonMetricsServer})
+			insecureTLS := tt.insecureTLS
+			ctx.GetClusterConfig().Spec.Kubernetes.Addons.MetricsServer.KubeletInsecureTLS = &insecureTLS
+
+			addonTask, err := NewAddonTask(&ctx.GetClusterConfig().Spec.Addons[0])
+			if err != nil {
+				t.Fatalf("NewAddonTask failed: %v", err)
+			}
+			fragment, err := addonTask.Plan(ctx)
+			if err != nil {
+				t.Fatalf("plan failed: %v", err)
+			}
+
+			installNode, ok := fragment.Nodes["InstallChart-metrics-server-helm-0"]
+			if !ok {
+				t.Fatalf("expected the metrics-server chart install node, got %v", fragment.Nodes)
+			}
+			installStep, ok := installNode.Step.(*helm.InstallChartStep)
+			if !ok {
+				t.Fatalf("expected an InstallChartStep, got %T", installNode.Step)
+			}
+			args := strings.Join(installStep.ExtraArgs, " ")
+			if got := strings.Contains(args, "--kubelet-insecure-tls"); got != tt.insecureTLS {
+				t.Errorf("expected --kubelet-insecure-tls injected=%v, got args %q", tt.insecureTLS, args)
+			}
+			if !strings.Contains(args, `--kubelet-preferred-address-types=InternalIP\,ExternalIP\,Hostname`) {
+				t.Errorf("expected the preferred address types in the chart args, got %q", args)
+			}
+
+			verifyNode, ok := fragment.Nodes["VerifyMetricsServer"]
+			if !ok {
+				t.Fatalf("expected a VerifyMetricsServer node, got %v", fragment.Nodes)
+			}
+			if len(verifyNode.Dependencies) != 1 || verifyNode.Dependencies[0] != plan.NodeID("InstallChart-metrics-server-helm-0") {
+				t.Errorf("expected verification to run after the chart install, got dependencies %v", verifyNode.Dependencies)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package addon
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// nfsProvisionerChartValues points nfs-subdir-external-provisioner at the export of spec.storage.nfs
+// and names its StorageClass after spec.storage.nfs.storageClassName.
+func nfsProvisionerChartValues(cluster *v1alpha1.Cluster) ([]string, error) {
+	var nfs *v1alpha1.NFSConfig
+	if cluster.Spec.Storage != nil {
+		nfs = cluster.Spec.Storage.NFS
+	}
+	if nfs == nil || nfs.Server == "" || nfs.Path == "" {
+		return nil, fmt.Errorf("addon %s requires spec.storage.nfs.server and spec.storage.nfs.path", common.AddonNFSProvisioner)
+	}
+	values := []string{
+		"nfs.server=" + nfs.Server,
+		"nfs.path=" + nfs.Path,
+	}
+	if nfs.StorageClassName != nil && *nfs.StorageClassName != "" {
+		values = append(values, "storageClass.name="+*nfs.StorageClassName)
+	}
+	return values, nil
+}
@@ -0,0 +1,136 @@
+package addon
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// Installer builds the task installing an addon from its entry in the cluster config.
+// When the addon's definition has a chart, the addon's sources are already set to it.
+type Installer func(addon *v1alpha1.Addon) task.Task
+
+// Definition declares how a built-in addon is installed when its entry in spec.addons defines no sources.
+// Addons deployed from a chart set Namespace and Chart; addons that need their own steps set Installer,
+// and may also set a chart for it to install.
+// The images an addon pulls are declared in common.AddonImages, next to the image BOM that resolves them.
+type Definition struct {
+	Namespace string
+	Chart     *v1alpha1.ChartSource
+	// Values returns the chart values derived from the cluster config. The addon's own values are applied after them.
+	Values func(cluster *v1alpha1.Cluster) ([]string, error)
+	// Readiness lists the workloads, as kind/name in Namespace, that must roll out before the addon is ready.
+	Readiness []string
+	Installer Installer
+}
+
+var (
+	definitionsMu sync.RWMutex
+	definitions   = map[string]Definition{
+		common.AddonMetricsServer: {
+			Namespace: common.DefaultMetricsServerNamespace,
+			Chart: &v1alpha1.ChartSource{
+				Name: common.DefaultMetricsServerChartName, Repo: common.DefaultMetricsServerChartRepo, Version: common.DefaultMetricsServerChartVersion,
+			},
+			Installer: NewInstallMetricsServerTask,
+		},
+		common.AddonIngressNginx: {
+			Namespace: common.DefaultIngressNginxNamespace,
+			Chart: &v1alpha1.ChartSource{
+				Name: common.DefaultIngressNginxChartName, Repo: common.DefaultIngressNginxChartRepo, Version: common.DefaultIngressNginxChartVersion,
+			},
+			Installer: NewInstallIngressNginxTask,
+		},
+		common.AddonDashboard: {
+			Namespace: common.DefaultDashboardNamespace,
+			Chart: &v1alpha1.ChartSource{
+				Name: common.DefaultDashboardChartName, Repo: common.DefaultDashboardChartRepo, Version: common.DefaultDashboardChartVersion,
+			},
+			Readiness: []string{"deployment/kubernetes-dashboard-api", "deployment/kubernetes-dashboard-web"},
+		},
+		common.AddonLocalPathProvisioner: {Installer: NewInstallLocalPathProvisionerTask},
+		common.AddonCertManager: {
+			Namespace: common.DefaultCertManagerNamespace,
+			Chart: &v1alpha1.ChartSource{
+				Name: common.DefaultCertManagerChartName, Repo: common.DefaultCertManagerChartRepo, Version: common.DefaultCertManagerChartVersion,
+			},
+			Values:    certManagerChartValues,
+			Readiness: []string{"deployment/cert-manager", "deployment/cert-manager-cainjector", "deployment/cert-manager-webhook"},
+		},
+		common.AddonNFSProvisioner: {
+			Namespace: common.DefaultNFSProvisionerNamespace,
+			Chart: &v1alpha1.ChartSource{
+				Name: common.DefaultNFSProvisionerChartName, Repo: common.DefaultNFSProvisionerChartRepo, Version: common.DefaultNFSProvisionerChartVersion,
+			},
+			Values:    nfsProvisionerChartValues,
+			Readiness: []string{"deployment/" + common.DefaultNFSProvisionerChartName},
+		},
+	}
+)
+
+func init() {
+	v1alpha1.RegisteredAddons = RegisteredAddons
+}
+
+// Register registers the definition of an addon name, replacing any existing one.
+func Register(name string, def Definition) {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	definitions[name] = def
+}
+
+// Lookup returns the definition registered for an addon name.
+func Lookup(name string) (Definition, bool) {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	def, ok := definitions[name]
+	return def, ok
+}
+
+// RegisteredAddons returns the names of all registered addons, sorted.
+func RegisteredAddons() []string {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewAddonTask returns the task installing addon. Addons with their own sources are custom addons
+// installed from those sources; otherwise the registered definition for the addon name is used.
+func NewAddonTask(addon *v1alpha1.Addon) (task.Task, error) {
+	if len(addon.Sources) > 0 {
+		return NewInstallCustomAddonTask(addon), nil
+	}
+	def, ok := Lookup(addon.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown addon '%s' without sources, registered addons are: %v", addon.Name, RegisteredAddons())
+	}
+	if def.Installer == nil && def.Chart == nil {
+		return nil, fmt.Errorf("addon '%s' is registered without a chart or an installer", addon.Name)
+	}
+	setDefaultSource(addon, def)
+	if def.Installer != nil {
+		return def.Installer(addon), nil
+	}
+	return NewInstallChartAddonTask(addon, def), nil
+}
+
+// setDefaultSource points addon at the chart of its definition, if it has one. The source is set on
+// the addon itself, as the addon steps read their sources from the cluster config.
+func setDefaultSource(addon *v1alpha1.Addon, def Definition) {
+	if def.Chart == nil {
+		return
+	}
+	chart := *def.Chart
+	wait := true
+	chart.Wait = &wait
+	addon.Sources = []v1alpha1.AddonSource{{Namespace: def.Namespace, Chart: &chart}}
+}
@@ -0,0 +1,208 @@
+package addon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	addonstep "github.com/mensylisir/kubexm/internal/step/addon"
+	helmstep "github.com/mensylisir/kubexm/internal/step/helm"
+)
+
+func newAddonTestContext(t *testing.T, addons ...v1alpha1.Addon) *runtime.Context {
+	t.Helper()
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "addons"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts:      []v1alpha1.HostSpec{{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster}}},
+		Addons:     addons,
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.29.3"},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+
+	return runtimetest.NewRuntime(t, cfg)
+}
+
+func TestNewAddonTask_RegisteredAddonPlansChartInstall(t *testing.T) {
+	ctx := newAddonTestContext(t, v1alpha1.Addon{Name: common.AddonMetricsServer})
+	addon := &ctx.GetClusterConfig().Spec.Addons[0]
+
+	addonTask, err := NewAddonTask(addon)
+	if err != nil {
+		t.Fatalf("NewAddonTask failed: %v", err)
+	}
+	if len(addon.Sources) != 1 || addon.Sources[0].Chart == nil || addon.Sources[0].Chart.Repo != common.DefaultMetricsServerChartRepo {
+		t.Fatalf("expected the default metrics-server chart source, got %+v", addon.Sources)
+	}
+
+	fragment, err := addonTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	for _, id := range []string{"AddRepo-metrics-server-helm-0", "InstallChart-metrics-server-helm-0"} {
+		if !fragment.HasNode(plan.NodeID(id)) {
+			t.Errorf("expected node %s in the fragment, got %v", id, fragment.Nodes)
+		}
+	}
+}
+
+func TestNewAddonTask_UnknownAddonRejected(t *testing.T) {
+	_, err := NewAddonTask(&v1alpha1.Addon{Name: "not-an-addon"})
+	if err == nil || !strings.Contains(err.Error(), "not-an-addon") {
+		t.Fatalf("expected an unknown addon error, got %v", err)
+	}
+}
+
+func TestNewAddonTask_LocalPathProvisionerAppliesManifests(t *testing.T) {
+	ctx := newAddonTestContext(t, v1alpha1.Addon{Name: common.AddonLocalPathProvisioner})
+
+	addonTask, err := NewAddonTask(&ctx.GetClusterConfig().Spec.Addons[0])
+	if err != nil {
+		t.Fatalf("NewAddonTask failed: %v", err)
+	}
+	fragment, err := addonTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if len(fragment.Nodes) != 1 || !fragment.HasNode("InstallLocalPathProvisioner") {
+		t.Errorf("expected a single InstallLocalPathProvisioner node, got %v", fragment.Nodes)
+	}
+}
+
+func TestNewAddonTask_CertManagerWaitsForReadiness(t *testing.T) {
+	ctx := newAddonTestContext(t, v1alpha1.Addon{Name: common.AddonCertManager, Values: []string{"replicaCount=2"}})
+
+	addonTask, err := NewAddonTask(&ctx.GetClusterConfig().Spec.Addons[0])
+	if err != nil {
+		t.Fatalf("NewAddonTask failed: %v", err)
+	}
+	fragment, err := addonTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+
+	installID := plan.NodeID("InstallChart-cert-manager-helm-0")
+	install, ok := fragment.Nodes[installID]
+	if !ok {
+		t.Fatalf("expected node %s in the fragment, got %v", installID, fragment.Nodes)
+	}
+	installStep, ok := install.Step.(*helmstep.InstallChartStep)
+	if !ok {
+		t.Fatalf("expected a helm install step, got %T", install.Step)
+	}
+	args := strings.Join(installStep.ExtraArgs, " ")
+	if !strings.Contains(args, "--set 'installCRDs=true' --set 'replicaCount=2'") {
+		t.Errorf("expected the addon values after the default values, got %s", args)
+	}
+
+	for _, id := range []string{"WaitReady-cert-manager-deployment-cert-manager", "WaitReady-cert-manager-deployment-cert-manager-webhook"} {
+		node, ok := fragment.Nodes[plan.NodeID(id)]
+		if !ok {
+			t.Fatalf("expected node %s in the fragment, got %v", id, fragment.Nodes)
+		}
+		if len(node.Dependencies) != 1 || node.Dependencies[0] != installID {
+			t.Errorf("expected %s to wait for the chart install, got %v", id, node.Dependencies)
+		}
+	}
+}
+
+func TestNewAddonTask_NFSProvisionerRequiresExport(t *testing.T) {
+	ctx := newAddonTestContext(t, v1alpha1.Addon{Name: common.AddonNFSProvisioner})
+
+	addonTask, err := NewAddonTask(&ctx.GetClusterConfig().Spec.Addons[0])
+	if err != nil {
+		t.Fatalf("NewAddonTask failed: %v", err)
+	}
+	if _, err := addonTask.Plan(ctx); err == nil || !strings.Contains(err.Error(), "spec.storage.nfs.server") {
+		t.Fatalf("expected an error about the missing NFS export, got %v", err)
+	}
+
+	ctx.GetClusterConfig().Spec.Storage.NFS.Server = "10.0.0.50"
+	ctx.GetClusterConfig().Spec.Storage.NFS.Path = "/exports/k8s"
+	fragment, err := addonTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if !fragment.HasNode("WaitReady-nfs-subdir-external-provisioner-deployment-nfs-subdir-external-provisioner") {
+		t.Errorf("expected a readiness check for the provisioner, got %v", fragment.Nodes)
+	}
+}
+
+func TestNewAddonTask_CustomAddonInstallsSourcesInOrder(t *testing.T) {
+	manifests := t.TempDir()
+	ctx := newAddonTestContext(t, v1alpha1.Addon{
+		Name:   "my-operator",
+		Values: []string{"replicas=2"},
+		Sources: []v1alpha1.AddonSource{
+			{Namespace: "operators", Chart: &v1alpha1.ChartSource{Name: "operator", Repo: "oci://ghcr.io/example/charts", Version: "1.2.0"}},
+			{Namespace: "operators", Yaml: &v1alpha1.YamlSource{Directory: manifests}},
+		},
+		Wait: &v1alpha1.AddonWait{Resources: []string{"deployment/operator"}, Condition: "Available"},
+	})
+
+	addonTask, err := NewAddonTask(&ctx.GetClusterConfig().Spec.Addons[0])
+	if err != nil {
+		t.Fatalf("NewAddonTask failed: %v", err)
+	}
+	if _, ok := addonTask.(*InstallCustomAddonTask); !ok {
+		t.Fatalf("expected a custom addon task, got %T", addonTask)
+	}
+	fragment, err := addonTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+
+	install, ok := fragment.Nodes["InstallChart-my-operator-0"]
+	if !ok {
+		t.Fatalf("expected a chart install node, got %v", fragment.Nodes)
+	}
+	chartStep := install.Step.(*addonstep.DeployAddonChartStep)
+	if chartStep.Chart != "oci://ghcr.io/example/charts/operator" || chartStep.Repo != "" || chartStep.ReleaseName != "operator" {
+		t.Errorf("expected the OCI chart to be referenced directly, got %+v", chartStep)
+	}
+	if len(chartStep.SetValues) != 1 || chartStep.SetValues[0] != "replicas=2" {
+		t.Errorf("expected the addon values to be set, got %v", chartStep.SetValues)
+	}
+
+	apply := fragment.Nodes["ApplyManifests-my-operator-1"]
+	if apply == nil || len(apply.Dependencies) != 1 || apply.Dependencies[0] != "InstallChart-my-operator-0" {
+		t.Fatalf("expected the manifests to be applied after the chart, got %v", apply)
+	}
+	wait := fragment.Nodes["WaitReady-my-operator-deployment-operator"]
+	if wait == nil || len(wait.Dependencies) != 1 || wait.Dependencies[0] != "ApplyManifests-my-operator-1" {
+		t.Fatalf("expected the wait to follow the last source, got %v", wait)
+	}
+	if waitStep := wait.Step.(*addonstep.WaitForRolloutStep); waitStep.Condition != "Available" || waitStep.Namespace != "operators" {
+		t.Errorf("unexpected wait step %+v", waitStep)
+	}
+	if len(fragment.ExitNodes) != 1 || fragment.ExitNodes[0] != "WaitReady-my-operator-deployment-operator" {
+		t.Errorf("expected the wait to be the only exit node, got %v", fragment.ExitNodes)
+	}
+}
+
+func TestRegister_AddonValidatesWithoutSources(t *testing.T) {
+	const name = "my-registered-addon"
+	addon := &v1alpha1.Addon{Name: name}
+	verrs := &validation.ValidationErrors{}
+	v1alpha1.Validate_Addon(addon, verrs, "spec.addons[0]")
+	if verrs.IsEmpty() {
+		t.Fatal("expected an unregistered addon without sources to be rejected")
+	}
+
+	Register(name, Definition{Namespace: "default", Chart: &v1alpha1.ChartSource{Name: "chart", Repo: "https://charts.example.com"}})
+	t.Cleanup(func() {
+		definitionsMu.Lock()
+		defer definitionsMu.Unlock()
+		delete(definitions, name)
+	})
+	verrs = &validation.ValidationErrors{}
+	v1alpha1.Validate_Addon(addon, verrs, "spec.addons[0]")
+	if !verrs.IsEmpty() {
+		t.Errorf("expected a registered addon to validate without sources, got %v", verrs.Error())
+	}
+}
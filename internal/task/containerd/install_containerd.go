@@ -3,8 +3,8 @@ package containerd
 import (
 	"fmt"
 	"github.com/mensylisir/kubexm/internal/common"
-	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step/containerd"
@@ -77,6 +77,14 @@ func (t *DeployContainerdTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFra
 	if err != nil {
 		return nil, err
 	}
+	configureNvidiaRuntime, err := containerd.NewConfigureNvidiaRuntimeStepBuilder(runtimeCtx, "ConfigureNvidiaRuntime").Build()
+	if err != nil {
+		return nil, err
+	}
+	configureRegistryHosts, err := containerd.NewConfigureRegistryHostsStepBuilder(runtimeCtx, "ConfigureRegistryHosts").Build()
+	if err != nil {
+		return nil, err
+	}
 	installService, err := containerd.NewInstallContainerdServiceStepBuilder(runtimeCtx, "InstallContainerdService").Build()
 	if err != nil {
 		return nil, err
@@ -92,6 +100,8 @@ func (t *DeployContainerdTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFra
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallCNI", Step: installCNI, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallContainerd", Step: installContainerd, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureContainerd", Step: configureContainerd, Hosts: deployHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureNvidiaRuntime", Step: configureNvidiaRuntime, Hosts: deployHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureRegistryHosts", Step: configureRegistryHosts, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallContainerdService", Step: installService, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "StartContainerd", Step: startContainerd, Hosts: deployHosts})
 
@@ -100,7 +110,9 @@ func (t *DeployContainerdTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFra
 	fragment.AddDependency("InstallRunc", "ConfigureContainerd")
 	fragment.AddDependency("InstallCNI", "ConfigureContainerd")
 	fragment.AddDependency("InstallContainerd", "ConfigureContainerd")
-	fragment.AddDependency("ConfigureContainerd", "InstallContainerdService")
+	fragment.AddDependency("ConfigureContainerd", "ConfigureNvidiaRuntime")
+	fragment.AddDependency("ConfigureNvidiaRuntime", "ConfigureRegistryHosts")
+	fragment.AddDependency("ConfigureRegistryHosts", "InstallContainerdService")
 	fragment.AddDependency("InstallContainerdService", "StartContainerd")
 
 	// Downloads are handled centrally in Preflight PrepareAssets/ExtractBundle.
@@ -0,0 +1,203 @@
+package containerd
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/containerd"
+	"github.com/mensylisir/kubexm/internal/step/docker"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubelet"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/perform"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// MigrateDockerToContainerdTask moves nodes running the kubelet on docker/cri-dockerd over to containerd,
+// one node at a time so that the cluster never loses more than a single node of capacity.
+type MigrateDockerToContainerdTask struct {
+	task.Base
+}
+
+func NewMigrateDockerToContainerdTask() task.Task {
+	return &MigrateDockerToContainerdTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "MigrateDockerToContainerd",
+				Description: "Drain each node, replace docker/cri-dockerd with containerd and point the kubelet at the containerd socket",
+			},
+		},
+	}
+}
+
+func (t *MigrateDockerToContainerdTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *MigrateDockerToContainerdTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *MigrateDockerToContainerdTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return ctx.GetClusterConfig().Spec.Kubernetes.ContainerRuntime.Type == common.RuntimeTypeContainerd, nil
+}
+
+// runtimeMigrationSteps holds the steps migrating a single node. The ones operating on the node itself
+// run on that host, the kubectl based ones on the control node.
+type runtimeMigrationSteps struct {
+	Cordon              step.Step
+	Drain               step.Step
+	StopCriDockerd      step.Step
+	DisableCriDockerd   step.Step
+	StopDocker          step.Step
+	DisableDocker       step.Step
+	ConfigureContainerd step.Step
+	InstallService      step.Step
+	EnableContainerd    step.Step
+	StartContainerd     step.Step
+	SwitchCRISocket     step.Step
+	WaitNodeReady       step.Step
+	Uncordon            step.Step
+}
+
+func (t *MigrateDockerToContainerdTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	controlNode, err := ctx.GetControlNode()
+	if err != nil {
+		return nil, err
+	}
+	migrateHosts := uniqueHosts(append(ctx.GetHostsByRole(common.RoleMaster), ctx.GetHostsByRole(common.RoleWorker)...))
+	if len(migrateHosts) == 0 {
+		return nil, fmt.Errorf("no master or worker hosts found to migrate to containerd")
+	}
+
+	var previous plan.NodeID
+	for _, host := range migrateHosts {
+		steps, err := newRuntimeMigrationSteps(runtimeCtx, host.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create migration steps for %s: %w", host.GetName(), err)
+		}
+		last, err := addRuntimeMigrationNodes(fragment, previous, host, controlNode, steps)
+		if err != nil {
+			return nil, err
+		}
+		previous = last
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// uniqueHosts drops repeated hosts by name, so a host that is both master and worker is migrated once.
+func uniqueHosts(hosts []remotefw.Host) []remotefw.Host {
+	var unique []remotefw.Host
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if !seen[host.GetName()] {
+			seen[host.GetName()] = true
+			unique = append(unique, host)
+		}
+	}
+	return unique
+}
+
+func newRuntimeMigrationSteps(ctx runtime.ExecutionContext, nodeName string) (*runtimeMigrationSteps, error) {
+	var steps runtimeMigrationSteps
+	var err error
+	name := func(stage string) string { return fmt.Sprintf("%s-%s", stage, nodeName) }
+
+	if steps.Cordon, err = perform.NewCordonNodeStepBuilder(ctx, name("Cordon"), nodeName).Build(); err != nil {
+		return nil, err
+	}
+	if steps.Drain, err = perform.NewDrainNodeStepBuilder(ctx, name("Drain"), nodeName).Build(); err != nil {
+		return nil, err
+	}
+	if steps.StopCriDockerd, err = docker.NewStopCriDockerdStepBuilder(ctx, name("StopCriDockerd")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.DisableCriDockerd, err = docker.NewDisableCriDockerdStepBuilder(ctx, name("DisableCriDockerd")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.StopDocker, err = docker.NewStopDockerStepBuilder(ctx, name("StopDocker")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.DisableDocker, err = docker.NewDisableDockerStepBuilder(ctx, name("DisableDocker")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.ConfigureContainerd, err = containerd.NewConfigureContainerdStepBuilder(ctx, name("ConfigureContainerd")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.InstallService, err = containerd.NewInstallContainerdServiceStepBuilder(ctx, name("InstallContainerdService")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.EnableContainerd, err = containerd.NewEnableContainerdStepBuilder(ctx, name("EnableContainerd")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.StartContainerd, err = containerd.NewStartContainerdStepBuilder(ctx, name("StartContainerd")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.SwitchCRISocket, err = kubelet.NewSwitchKubeletCRISocketStepBuilder(ctx, name("SwitchKubeletCRISocket")).Build(); err != nil {
+		return nil, err
+	}
+	if steps.WaitNodeReady, err = perform.NewWaitNodeReadyOnRuntimeStepBuilder(ctx, name("WaitNodeReady"), nodeName, "containerd").Build(); err != nil {
+		return nil, err
+	}
+	if steps.Uncordon, err = perform.NewUncordonNodeStepBuilder(ctx, name("Uncordon"), nodeName).Build(); err != nil {
+		return nil, err
+	}
+	return &steps, nil
+}
+
+// addRuntimeMigrationNodes adds the migration of one node as a strict chain, so the node is always drained
+// before its runtime is touched and only uncordoned once it is Ready on containerd. The chain starts after
+// the node given by after, if any, and the ID of its last node is returned.
+func addRuntimeMigrationNodes(fragment *plan.ExecutionFragment, after plan.NodeID, host, controlNode remotefw.Host, steps *runtimeMigrationSteps) (plan.NodeID, error) {
+	onControl := []remotefw.Host{controlNode}
+	onHost := []remotefw.Host{host}
+	chain := []struct {
+		stage string
+		step  step.Step
+		hosts []remotefw.Host
+	}{
+		{"Cordon", steps.Cordon, onControl},
+		{"Drain", steps.Drain, onControl},
+		{"StopCriDockerd", steps.StopCriDockerd, onHost},
+		{"DisableCriDockerd", steps.DisableCriDockerd, onHost},
+		{"StopDocker", steps.StopDocker, onHost},
+		{"DisableDocker", steps.DisableDocker, onHost},
+		{"ConfigureContainerd", steps.ConfigureContainerd, onHost},
+		{"InstallContainerdService", steps.InstallService, onHost},
+		{"EnableContainerd", steps.EnableContainerd, onHost},
+		{"StartContainerd", steps.StartContainerd, onHost},
+		{"SwitchKubeletCRISocket", steps.SwitchCRISocket, onHost},
+		{"WaitNodeReady", steps.WaitNodeReady, onControl},
+		{"Uncordon", steps.Uncordon, onControl},
+	}
+
+	previous := after
+	for _, link := range chain {
+		id, err := fragment.AddNode(&plan.ExecutionNode{
+			Name:  fmt.Sprintf("%s-%s", link.stage, host.GetName()),
+			Step:  link.step,
+			Hosts: link.hosts,
+		})
+		if err != nil {
+			return "", err
+		}
+		if previous != "" {
+			if err := fragment.AddDependency(previous, id); err != nil {
+				return "", err
+			}
+		}
+		previous = id
+	}
+	return previous, nil
+}
+
+var _ task.Task = (*MigrateDockerToContainerdTask)(nil)
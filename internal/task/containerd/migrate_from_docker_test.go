@@ -0,0 +1,84 @@
+package containerd
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/connector"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+)
+
+// dependsOn reports whether node id transitively depends on ancestor.
+func dependsOn(fragment *plan.ExecutionFragment, id, ancestor plan.NodeID) bool {
+	for _, dep := range fragment.Nodes[id].Dependencies {
+		if dep == ancestor || dependsOn(fragment, dep, ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAddRuntimeMigrationNodesOrdering(t *testing.T) {
+	control := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "master1", Address: "10.0.0.1"})
+	nodes := []string{"node1", "node2"}
+
+	fragment := plan.NewExecutionFragment("MigrateDockerToContainerd")
+	var previous plan.NodeID
+	for _, name := range nodes {
+		host := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: name, Address: "10.0.0.2"})
+		last, err := addRuntimeMigrationNodes(fragment, previous, host, control, &runtimeMigrationSteps{})
+		if err != nil {
+			t.Fatalf("failed to add migration nodes for %s: %v", name, err)
+		}
+		previous = last
+	}
+
+	tests := []struct {
+		before plan.NodeID
+		after  plan.NodeID
+	}{
+		{"Cordon-node1", "Drain-node1"},
+		{"Drain-node1", "StopDocker-node1"},
+		{"Drain-node1", "SwitchKubeletCRISocket-node1"},
+		{"StartContainerd-node1", "SwitchKubeletCRISocket-node1"},
+		{"SwitchKubeletCRISocket-node1", "WaitNodeReady-node1"},
+		{"WaitNodeReady-node1", "Uncordon-node1"},
+		{"Uncordon-node1", "Cordon-node2"},
+		{"Drain-node2", "SwitchKubeletCRISocket-node2"},
+	}
+	for _, tt := range tests {
+		if !dependsOn(fragment, tt.after, tt.before) {
+			t.Errorf("expected %s to run before %s", tt.before, tt.after)
+		}
+		if dependsOn(fragment, tt.before, tt.after) {
+			t.Errorf("expected %s not to depend on %s", tt.before, tt.after)
+		}
+	}
+
+	if hosts := fragment.Nodes["Drain-node1"].Hosts; len(hosts) != 1 || hosts[0].GetName() != "master1" {
+		t.Errorf("expected drain to run on the control node, got %v", hosts)
+	}
+	if hosts := fragment.Nodes["SwitchKubeletCRISocket-node1"].Hosts; len(hosts) != 1 || hosts[0].GetName() != "node1" {
+		t.Errorf("expected the kubelet switch to run on the migrated node, got %v", hosts)
+	}
+}
+
+func TestUniqueHosts(t *testing.T) {
+	master := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node1", Address: "10.0.0.1"})
+	worker := connector.NewHostFromSpec(v1alpha1.HostSpec{Name: "node2", Address: "10.0.0.2"})
+	hosts := uniqueHosts([]remotefw.Host{master, worker, master})
+	if len(hosts) != 2 || hosts[0].GetName() != "node1" || hosts[1].GetName() != "node2" {
+		t.Fatalf("uniqueHosts() = %v, want node1 and node2 once each", hosts)
+	}
+
+	fragment := plan.NewExecutionFragment("MigrateDockerToContainerd")
+	var previous plan.NodeID
+	for _, host := range hosts {
+		last, err := addRuntimeMigrationNodes(fragment, previous, host, master, &runtimeMigrationSteps{})
+		if err != nil {
+			t.Fatalf("failed to add migration nodes for %s: %v", host.GetName(), err)
+		}
+		previous = last
+	}
+}
@@ -81,6 +81,10 @@ func (t *DeployCrioTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment,
 	if installCrictl == nil {
 		return nil, fmt.Errorf("install_crictl step returned nil - ensure CRI-O assets were prepared (kubexm download)")
 	}
+	configureCrictl, err := crio.NewConfigureCrictlStepBuilder(runtimeCtx, "ConfigureCrictl").Build()
+	if err != nil {
+		return nil, err
+	}
 	installPolicyJson, err := crio.NewInstallPolicyJsonStepBuilder(runtimeCtx, "InstallPolicyJson").Build()
 	if err != nil {
 		return nil, err
@@ -142,6 +146,7 @@ func (t *DeployCrioTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment,
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallCrio", Step: installCrio, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallCni", Step: installCni, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallCrictl", Step: installCrictl, Hosts: deployHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureCrictl", Step: configureCrictl, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallPolicyJson", Step: installPolicyJson, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallCrioEnv", Step: installCrioEnv, Hosts: deployHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureCrio", Step: configureCrio, Hosts: deployHosts})
@@ -154,6 +159,7 @@ func (t *DeployCrioTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment,
 	fragment.AddDependency("ExtractCrio", "InstallCrio")
 	fragment.AddDependency("ExtractCrio", "InstallCni")
 	fragment.AddDependency("ExtractCrio", "InstallCrictl")
+	fragment.AddDependency("InstallCrictl", "ConfigureCrictl")
 	fragment.AddDependency("ExtractCrio", "InstallPolicyJson")
 	fragment.AddDependency("ExtractCrio", "InstallCrioEnv")
 	fragment.AddDependency("InstallCrio", "ConfigureCrio")
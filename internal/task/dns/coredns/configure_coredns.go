@@ -0,0 +1,90 @@
+package coredns
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	dnsstep "github.com/mensylisir/kubexm/internal/step/dns"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// ConfigureCoreDNSTask applies spec.dns.coredns to the CoreDNS kubeadm installed, restoring its
+// previous configuration when CoreDNS does not come up with the new one.
+type ConfigureCoreDNSTask struct {
+	task.Base
+}
+
+func NewConfigureCoreDNSTask() task.Task {
+	return &ConfigureCoreDNSTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "ConfigureCoreDNS",
+				Description: "Customize the CoreDNS configuration, replicas and autoscaling",
+			},
+		},
+	}
+}
+
+func (t *ConfigureCoreDNSTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *ConfigureCoreDNSTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *ConfigureCoreDNSTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return coreDNSCustomized(ctx.GetClusterConfig().Spec.DNS), nil
+}
+
+// coreDNSCustomized reports whether dns asks for anything the CoreDNS of kubeadm does not have,
+// so that a cluster without DNS settings keeps the Corefile of kubeadm.
+func coreDNSCustomized(dns *v1alpha1.DNS) bool {
+	if dns == nil {
+		return false
+	}
+	if dns.DNSEtcHosts != "" {
+		return true
+	}
+	cfg := dns.CoreDNS
+	if cfg == nil {
+		return false
+	}
+	if cfg.Replicas != nil || len(cfg.Rewrite) > 0 || cfg.RewriteBlock != "" || cfg.AdditionalConfigs != "" || len(cfg.ExternalZones) > 0 {
+		return true
+	}
+	if cfg.Autoscaler != nil && cfg.Autoscaler.Enabled != nil && *cfg.Autoscaler.Enabled {
+		return true
+	}
+	if upstream := cfg.UpstreamForwarding; upstream != nil {
+		return len(upstream.StaticServers) > 0 ||
+			(upstream.Policy != "" && upstream.Policy != common.UpstreamForwardingConfigRandom)
+	}
+	return false
+}
+
+func (t *ConfigureCoreDNSTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to configure coredns")
+	}
+
+	configureCoreDNS, err := dnsstep.NewConfigureCoreDNSStepBuilder(runtimeCtx, "ConfigureCoreDNS").Build()
+	if err != nil {
+		return nil, err
+	}
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureCoreDNS", Step: configureCoreDNS, Hosts: []remotefw.Host{masterHosts[0]}})
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*ConfigureCoreDNSTask)(nil)
@@ -2,6 +2,7 @@ package nodelocandns
 
 import (
 	"fmt"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
@@ -35,10 +36,7 @@ func (t *DeployNodeLocalDNSTask) Description() string {
 }
 
 func (t *DeployNodeLocalDNSTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
-	if ctx.GetClusterConfig().Spec.DNS == nil || ctx.GetClusterConfig().Spec.DNS.NodeLocalDNS == nil {
-		return false, nil
-	}
-	return *ctx.GetClusterConfig().Spec.DNS.NodeLocalDNS.Enabled, nil
+	return v1alpha1.NodeLocalDNSIP(ctx.GetClusterConfig().Spec) != "", nil
 }
 
 func (t *DeployNodeLocalDNSTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
@@ -0,0 +1,81 @@
+package etcd
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	stepcommon "github.com/mensylisir/kubexm/internal/step/common"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// DistributeExternalEtcdCertsTask copies the client certificates of an external etcd cluster from the
+// control machine to every master, where kube-apiserver picks them up.
+type DistributeExternalEtcdCertsTask struct {
+	task.Base
+}
+
+func NewDistributeExternalEtcdCertsTask() task.Task {
+	return &DistributeExternalEtcdCertsTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "DistributeExternalEtcdCerts",
+				Description: "Copy the external etcd client certificates to the master nodes",
+			},
+		},
+	}
+}
+
+func (t *DistributeExternalEtcdCertsTask) Name() string        { return t.Meta.Name }
+func (t *DistributeExternalEtcdCertsTask) Description() string { return t.Meta.Description }
+func (t *DistributeExternalEtcdCertsTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	etcdSpec := ctx.GetClusterConfig().Spec.Etcd
+	if etcdSpec == nil || etcdSpec.Type != string(common.EtcdDeploymentTypeExternal) || etcdSpec.External == nil {
+		return false, nil
+	}
+	ext := etcdSpec.External
+	return ext.CAFile != "" || ext.CertFile != "" || ext.KeyFile != "", nil
+}
+
+func (t *DistributeExternalEtcdCertsTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masterHosts) == 0 {
+		return nil, fmt.Errorf("no master hosts found to distribute external etcd certificates to")
+	}
+
+	ext := ctx.GetClusterConfig().Spec.Etcd.External
+	caFile, certFile, keyFile := ext.RemoteCertPaths()
+	files := []struct {
+		name        string
+		local       string
+		remote      string
+		permissions string
+	}{
+		{name: "UploadExternalEtcdCA", local: ext.CAFile, remote: caFile, permissions: "0644"},
+		{name: "UploadExternalEtcdClientCert", local: ext.CertFile, remote: certFile, permissions: "0644"},
+		{name: "UploadExternalEtcdClientKey", local: ext.KeyFile, remote: keyFile, permissions: "0600"},
+	}
+	for _, f := range files {
+		if f.local == "" {
+			continue
+		}
+		uploadStep, err := stepcommon.NewUploadFileStepBuilder(execCtx, f.name, f.local, f.remote).
+			WithPermissions(f.permissions).
+			WithSudo(true).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s step: %w", f.name, err)
+		}
+		if _, err := fragment.AddNode(&plan.ExecutionNode{Name: f.name, Step: uploadStep, Hosts: masterHosts}); err != nil {
+			return nil, err
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
@@ -1,12 +1,15 @@
 package etcd
 
 import (
+	"fmt"
+
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	etcdcertsstep "github.com/mensylisir/kubexm/internal/step/etcd"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
 	"github.com/mensylisir/kubexm/internal/task"
 )
 
@@ -49,7 +52,14 @@ func (t *GenerateEtcdPKITask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFrag
 	}
 	executionHost := []remotefw.Host{controlNode}
 
-	generateEtcdCA, err := etcdcertsstep.NewGenerateEtcdCAStepBuilder(runtimeCtx, "GenerateEtcdCA").Build()
+	var externalCA *helpers.ExternalCA
+	if certs := ctx.GetClusterConfig().Spec.Certs; certs != nil && certs.ExternalCA != nil {
+		if externalCA, err = helpers.LoadExternalCA(certs.ExternalCA.Etcd); err != nil {
+			return nil, fmt.Errorf("invalid external etcd CA: %w", err)
+		}
+	}
+
+	generateEtcdCA, err := etcdcertsstep.NewGenerateEtcdCAStepBuilder(runtimeCtx, "GenerateEtcdCA").WithExternalCA(externalCA).Build()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,239 @@
+package etcd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/command"
+	"github.com/mensylisir/kubexm/internal/step/etcd"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/apiserver"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// BackupEtcdSnapshotTask saves a snapshot from the etcd leader to the control machine and
+// keeps the newest Retain snapshots there.
+type BackupEtcdSnapshotTask struct {
+	task.Base
+	Retain int
+}
+
+func NewBackupEtcdSnapshotTask(retain int) task.Task {
+	return &BackupEtcdSnapshotTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "BackupEtcdSnapshot",
+				Description: "Save an etcd snapshot from the leader to the control machine",
+			},
+		},
+		Retain: retain,
+	}
+}
+
+func (t *BackupEtcdSnapshotTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *BackupEtcdSnapshotTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *BackupEtcdSnapshotTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	etcdSpec := ctx.GetClusterConfig().Spec.Etcd
+	if etcdSpec == nil {
+		return false, nil
+	}
+	return etcdSpec.Type != string(common.EtcdDeploymentTypeExternal), nil
+}
+
+func (t *BackupEtcdSnapshotTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+
+	// kubeadm runs etcd as a static pod on every master.
+	etcdHosts := ctx.GetHostsByRole(common.RoleEtcd)
+	if ctx.GetClusterConfig().Spec.Etcd.Type == string(common.EtcdDeploymentTypeKubeadm) {
+		etcdHosts = ctx.GetHostsByRole(common.RoleMaster)
+	}
+	if len(etcdHosts) == 0 {
+		return nil, fmt.Errorf("no etcd hosts found to take a snapshot from")
+	}
+
+	saveStep, err := etcd.NewSaveEtcdSnapshotStepBuilder(runtime.ForHost(execCtx, etcdHosts[0]), "SaveEtcdSnapshot").
+		WithEtcdNodes(etcdHosts).Build()
+	if err != nil {
+		return nil, err
+	}
+	saveNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "SaveEtcdSnapshot", Step: saveStep, Hosts: []remotefw.Host{etcdHosts[0]}})
+
+	if t.Retain > 0 {
+		controlNode, err := ctx.GetControlNode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get control node: %w", err)
+		}
+		pruneStep, err := etcd.NewPruneEtcdSnapshotsStepBuilder(execCtx, "PruneEtcdSnapshots", t.Retain).Build()
+		if err != nil {
+			return nil, err
+		}
+		pruneNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "PruneEtcdSnapshots", Step: pruneStep, Hosts: []remotefw.Host{controlNode}})
+		fragment.AddDependency(saveNode, pruneNode)
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// RestoreEtcdClusterTask rebuilds a kubexm managed etcd cluster from a snapshot. kube-apiserver is
+// stopped on every master while etcd is down, every member restores the snapshot with the
+// membership of the configured etcd hosts, and the member configs are rendered again.
+type RestoreEtcdClusterTask struct {
+	task.Base
+	SnapshotPath string
+}
+
+func NewRestoreEtcdClusterTask(snapshotPath string) task.Task {
+	return &RestoreEtcdClusterTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "RestoreEtcdCluster",
+				Description: "Rebuild the etcd cluster from a snapshot",
+			},
+		},
+		SnapshotPath: snapshotPath,
+	}
+}
+
+func (t *RestoreEtcdClusterTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *RestoreEtcdClusterTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *RestoreEtcdClusterTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return true, nil
+}
+
+func (t *RestoreEtcdClusterTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	cfg := ctx.GetClusterConfig()
+
+	if cfg.Spec.Etcd == nil || cfg.Spec.Etcd.Type != string(common.EtcdDeploymentTypeKubexm) {
+		return nil, fmt.Errorf("etcd restore is only supported for etcd deployed by kubexm")
+	}
+	if t.SnapshotPath == "" {
+		return nil, fmt.Errorf("snapshot path is required for etcd restore")
+	}
+	etcdHosts := ctx.GetHostsByRole(common.RoleEtcd)
+	if len(etcdHosts) == 0 {
+		return nil, fmt.Errorf("no etcd hosts found to restore")
+	}
+	masterHosts := ctx.GetHostsByRole(common.RoleMaster)
+	binaryApiServer := cfg.Spec.Kubernetes != nil && cfg.Spec.Kubernetes.Type == string(common.KubernetesDeploymentTypeKubexm)
+
+	var stopApiServerNodes []plan.NodeID
+	if len(masterHosts) > 0 {
+		stopStep, err := t.stopApiServerStep(execCtx, binaryApiServer)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := fragment.AddNode(&plan.ExecutionNode{Name: "StopKubeApiServerForRestore", Step: stopStep, Hosts: masterHosts})
+		stopApiServerNodes = append(stopApiServerNodes, id)
+	}
+
+	stopEtcd, err := etcd.NewStopEtcdStepBuilder(execCtx, "StopEtcdForRestore").Build()
+	if err != nil {
+		return nil, err
+	}
+	stopEtcdNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "StopEtcdForRestore", Step: stopEtcd, Hosts: etcdHosts})
+	for _, id := range stopApiServerNodes {
+		fragment.AddDependency(id, stopEtcdNode)
+	}
+
+	dataDir := common.EtcdDefaultDataDirTarget
+	if cc := cfg.Spec.Etcd.ClusterConfig; cc != nil && cc.DataDir != nil && *cc.DataDir != "" {
+		dataDir = *cc.DataDir
+	}
+
+	configureEtcd, err := etcd.NewConfigureEtcdStepBuilder(execCtx, "ReconfigureEtcdAfterRestore").WithDataDir(dataDir).Build()
+	if err != nil {
+		return nil, err
+	}
+	configureNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "ReconfigureEtcdAfterRestore", Step: configureEtcd, Hosts: etcdHosts})
+
+	for _, host := range etcdHosts {
+		restoreName := fmt.Sprintf("RestoreEtcdSnapshot-%s", host.GetName())
+		restoreStep, err := etcd.NewRestoreEtcdStepBuilder(runtime.ForHost(execCtx, host), restoreName).
+			WithLocalSnapshotPath(t.SnapshotPath).
+			WithDataDir(dataDir).
+			WithEtcdNodes(etcdHosts).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create etcd restore step for %s: %w", host.GetName(), err)
+		}
+		restoreNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: restoreName, Step: restoreStep, Hosts: []remotefw.Host{host}})
+		fragment.AddDependency(stopEtcdNode, restoreNode)
+		fragment.AddDependency(restoreNode, configureNode)
+	}
+
+	startEtcd, err := etcd.NewStartEtcdStepBuilder(execCtx, "StartEtcdAfterRestore").Build()
+	if err != nil {
+		return nil, err
+	}
+	startEtcdNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "StartEtcdAfterRestore", Step: startEtcd, Hosts: etcdHosts})
+	fragment.AddDependency(configureNode, startEtcdNode)
+
+	waitHealthy, err := etcd.NewWaitClusterHealthyStepBuilder(runtime.ForHost(execCtx, etcdHosts[0]), "WaitEtcdHealthyAfterRestore").Build()
+	if err != nil {
+		return nil, err
+	}
+	waitNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "WaitEtcdHealthyAfterRestore", Step: waitHealthy, Hosts: []remotefw.Host{etcdHosts[0]}})
+	fragment.AddDependency(startEtcdNode, waitNode)
+
+	if len(masterHosts) > 0 {
+		startStep, err := t.startApiServerStep(execCtx, binaryApiServer)
+		if err != nil {
+			return nil, err
+		}
+		startNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: "StartKubeApiServerAfterRestore", Step: startStep, Hosts: masterHosts})
+		fragment.AddDependency(waitNode, startNode)
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// kubeadmApiServerManifestParking is where the kube-apiserver static pod manifest is moved while etcd is restored.
+var kubeadmApiServerManifestParking = filepath.Join(common.KubernetesConfigDir, "kube-apiserver.yaml.restore")
+
+func (t *RestoreEtcdClusterTask) stopApiServerStep(ctx runtime.ExecutionContext, binary bool) (step.Step, error) {
+	if binary {
+		return kube_apiserver.NewStopKubeApiServerStepBuilder(ctx, "StopKubeApiServerForRestore").Build()
+	}
+	manifest := filepath.Join(common.KubernetesManifestsDir, "kube-apiserver.yaml")
+	return command.NewCommandStepBuilder(ctx, "StopKubeApiServerForRestore",
+		fmt.Sprintf("mv %s %s", manifest, kubeadmApiServerManifestParking)).
+		WithCheck(fmt.Sprintf("test ! -f %s", manifest), true, 0).
+		WithRollback(fmt.Sprintf("mv %s %s", kubeadmApiServerManifestParking, manifest), true).
+		WithSudo(true).
+		Build()
+}
+
+func (t *RestoreEtcdClusterTask) startApiServerStep(ctx runtime.ExecutionContext, binary bool) (step.Step, error) {
+	if binary {
+		return kube_apiserver.NewStartKubeAPIServerStepBuilder(ctx, "StartKubeApiServerAfterRestore").Build()
+	}
+	manifest := filepath.Join(common.KubernetesManifestsDir, "kube-apiserver.yaml")
+	return command.NewCommandStepBuilder(ctx, "StartKubeApiServerAfterRestore",
+		fmt.Sprintf("mv %s %s", kubeadmApiServerManifestParking, manifest)).
+		WithCheck(fmt.Sprintf("test ! -f %s", kubeadmApiServerManifestParking), true, 0).
+		WithSudo(true).
+		Build()
+}
@@ -0,0 +1,74 @@
+package images
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	imagesstep "github.com/mensylisir/kubexm/internal/step/images"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// PreloadImagesTask puts every image the cluster needs into the container runtime of each
+// Kubernetes node, so kubeadm init/join and the first pods do not wait on registry pulls.
+type PreloadImagesTask struct {
+	task.Base
+}
+
+func NewPreloadImagesTask() task.Task {
+	return &PreloadImagesTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "PreloadImages",
+				Description: "Pull or load the cluster images on every node before kubeadm init/join",
+			},
+		},
+	}
+}
+
+func (t *PreloadImagesTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *PreloadImagesTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *PreloadImagesTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	cfg := ctx.GetClusterConfig()
+	if cfg.Spec.Kubernetes == nil || cfg.Spec.Kubernetes.ImagePreload == nil || cfg.Spec.Kubernetes.ImagePreload.Enabled == nil {
+		return common.DefaultImagePreloadEnabled, nil
+	}
+	return *cfg.Spec.Kubernetes.ImagePreload.Enabled, nil
+}
+
+func (t *PreloadImagesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	var hosts []remotefw.Host
+	seen := make(map[string]bool)
+	for _, host := range append(ctx.GetHostsByRole(common.RoleMaster), ctx.GetHostsByRole(common.RoleWorker)...) {
+		if !seen[host.GetName()] {
+			seen[host.GetName()] = true
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return fragment, nil
+	}
+
+	preloadStep, err := imagesstep.NewPreloadImagesStepBuilder(runtimeCtx, "PreloadImages").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build preload images step: %w", err)
+	}
+	fragment.AddNode(&plan.ExecutionNode{Name: "PreloadImages", Step: preloadStep, Hosts: hosts})
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*PreloadImagesTask)(nil)
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/conformance"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// ConformanceTask runs the Kubernetes conformance suite with Sonobuoy from the first master:
+// the binary is downloaded on the control node, installed on the master, and the results tarball
+// is fetched back to the control node once the run completes.
+type ConformanceTask struct {
+	task.Base
+	Options conformance.Options
+}
+
+func NewConformanceTask(opts conformance.Options) task.Task {
+	return &ConformanceTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "Conformance",
+				Description: "Run the conformance suite with Sonobuoy and fetch its results",
+			},
+		},
+		Options: opts,
+	}
+}
+
+func (t *ConformanceTask) Name() string        { return t.Meta.Name }
+func (t *ConformanceTask) Description() string { return t.Meta.Description }
+
+func (t *ConformanceTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	hosts := ctx.GetHostsByRole(common.RoleMaster)
+	return len(hosts) > 0, nil
+}
+
+func (t *ConformanceTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	masters := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masters) == 0 {
+		return fragment, nil
+	}
+	host := masters[0]
+	controlNode, err := ctx.GetControlNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get control node: %w", err)
+	}
+	hostCtx := runtime.ForHost(execCtx, host)
+
+	download, err := conformance.NewDownloadSonobuoyStepBuilder(execCtx, "DownloadSonobuoy", host.GetArch()).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sonobuoy download step: %w", err)
+	}
+	install, err := conformance.NewInstallSonobuoyStepBuilder(hostCtx, "InstallSonobuoy").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sonobuoy install step: %w", err)
+	}
+	run, err := conformance.NewRunConformanceStepBuilder(hostCtx, "RunConformance", t.Options).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conformance run step: %w", err)
+	}
+	fetch, err := conformance.NewFetchConformanceResultsStepBuilder(hostCtx, "FetchConformanceResults", t.Options.OutputDir).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conformance results step: %w", err)
+	}
+
+	downloadID, _ := fragment.AddNode(&plan.ExecutionNode{Name: "DownloadSonobuoy", Step: download, Hosts: []remotefw.Host{controlNode}})
+	installID, _ := fragment.AddNode(&plan.ExecutionNode{Name: "InstallSonobuoy", Step: install, Hosts: []remotefw.Host{host}})
+	runID, _ := fragment.AddNode(&plan.ExecutionNode{Name: "RunConformance", Step: run, Hosts: []remotefw.Host{host}})
+	fetchID, _ := fragment.AddNode(&plan.ExecutionNode{Name: "FetchConformanceResults", Step: fetch, Hosts: []remotefw.Host{host}})
+	fragment.AddDependency(downloadID, installID)
+	fragment.AddDependency(installID, runID)
+	fragment.AddDependency(runID, fetchID)
+
+	if !t.Options.KeepResources {
+		cleanup, err := conformance.NewCleanupSonobuoyStepBuilder(hostCtx, "CleanupSonobuoy").Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sonobuoy cleanup step: %w", err)
+		}
+		cleanupID, _ := fragment.AddNode(&plan.ExecutionNode{Name: "CleanupSonobuoy", Step: cleanup, Hosts: []remotefw.Host{host}})
+		fragment.AddDependency(fetchID, cleanupID)
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*ConformanceTask)(nil)
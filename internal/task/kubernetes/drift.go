@@ -0,0 +1,102 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/drift"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// DetectDriftTask compares the rendered node config files with the live files on every node, then
+// compares the desired configuration with the live cluster from the first control plane node.
+// As in ReconcileNodeConfigTask, the kubelet files are only compared for kubexm deployments.
+type DetectDriftTask struct {
+	task.Base
+}
+
+func NewDetectDriftTask() task.Task {
+	return &DetectDriftTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "DetectDrift",
+				Description: "Compare desired node labels, taints, versions, config files and addons with the live cluster",
+			},
+		},
+	}
+}
+
+func (t *DetectDriftTask) Name() string        { return t.Meta.Name }
+func (t *DetectDriftTask) Description() string { return t.Meta.Description }
+
+func (t *DetectDriftTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	hosts := ctx.GetHostsByRole(common.RoleControlPlane)
+	return len(hosts) > 0, nil
+}
+
+func (t *DetectDriftTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	hosts := ctx.GetHostsByRole(common.RoleControlPlane)
+	if len(hosts) == 0 {
+		return fragment, nil
+	}
+
+	host := hosts[0]
+	step, err := drift.NewCollectDriftStepBuilder(runtime.ForHost(execCtx, host), "CollectDrift").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create drift step: %w", err)
+	}
+
+	collectID, _ := fragment.AddNode(&plan.ExecutionNode{
+		Name:  "CollectDrift",
+		Step:  step,
+		Hosts: []remotefw.Host{host},
+	})
+
+	compareContainerd, compareKubelet := t.configComponents(ctx)
+	if compareContainerd || compareKubelet {
+		nodeHosts := append(ctx.GetHostsByRole(common.RoleMaster), ctx.GetHostsByRole(common.RoleWorker)...)
+		seen := make(map[string]bool)
+		for _, nodeHost := range nodeHosts {
+			if seen[nodeHost.GetName()] {
+				continue
+			}
+			seen[nodeHost.GetName()] = true
+			hostCtx := runtime.ForHost(execCtx, nodeHost)
+
+			renderers, err := drift.NodeConfigRenderers(hostCtx, compareContainerd, compareKubelet)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render node config for host %s: %w", nodeHost.GetName(), err)
+			}
+			name := fmt.Sprintf("CompareNodeConfig-%s", nodeHost.GetName())
+			compareStep, err := drift.NewCompareConfigStepBuilder(hostCtx, name, renderers...).Build()
+			if err != nil {
+				return nil, err
+			}
+			compareID, _ := fragment.AddNode(&plan.ExecutionNode{Name: name, Step: compareStep, Hosts: []remotefw.Host{nodeHost}})
+			if err := fragment.AddDependency(compareID, collectID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+func (t *DetectDriftTask) configComponents(ctx runtime.TaskContext) (compareContainerd, compareKubelet bool) {
+	k8s := ctx.GetClusterConfig().Spec.Kubernetes
+	if k8s == nil {
+		return false, false
+	}
+	compareContainerd = k8s.ContainerRuntime != nil && k8s.ContainerRuntime.Type == common.RuntimeTypeContainerd
+	compareKubelet = k8s.Type == string(common.KubernetesDeploymentTypeKubexm)
+	return compareContainerd, compareKubelet
+}
+
+var _ task.Task = (*DetectDriftTask)(nil)
@@ -0,0 +1,125 @@
+package kubeadm
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubeadm"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// JoinNewNodesTask joins nodes to a running cluster. Join credentials are created on a master that
+// is already part of the cluster, and the task finishes once every new node reports Ready.
+type JoinNewNodesTask struct {
+	task.Base
+	ExistingMaster remotefw.Host
+	NewMasters     []remotefw.Host
+	NewWorkers     []remotefw.Host
+}
+
+func NewJoinNewNodesTask(existingMaster remotefw.Host, newMasters, newWorkers []remotefw.Host) task.Task {
+	return &JoinNewNodesTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "JoinNewNodes",
+				Description: "Join new master and worker nodes to a running Kubernetes cluster",
+			},
+		},
+		ExistingMaster: existingMaster,
+		NewMasters:     newMasters,
+		NewWorkers:     newWorkers,
+	}
+}
+
+func (t *JoinNewNodesTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *JoinNewNodesTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *JoinNewNodesTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return len(t.NewMasters)+len(t.NewWorkers) > 0, nil
+}
+
+func (t *JoinNewNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	if len(t.NewMasters)+len(t.NewWorkers) == 0 {
+		ctx.GetLogger().Info("No new nodes to join, skipping task.")
+		return fragment, nil
+	}
+	if t.ExistingMaster == nil {
+		return nil, fmt.Errorf("an existing master is required to join new nodes")
+	}
+	existingMaster := []remotefw.Host{t.ExistingMaster}
+
+	createCredentials, err := kubeadm.NewCreateJoinCredentialsStepBuilder(runtimeCtx, "CreateJoinCredentials").
+		WithControlPlane(len(t.NewMasters) > 0).Build()
+	if err != nil {
+		return nil, err
+	}
+	fragment.AddNode(&plan.ExecutionNode{Name: "CreateJoinCredentials", Step: createCredentials, Hosts: existingMaster})
+
+	var newNodeNames []string
+	var joinExits []plan.NodeID
+	if len(t.NewMasters) > 0 {
+		generateJoinConfig, err := kubeadm.NewGenerateJoinMasterConfigStepBuilder(runtimeCtx, "GenerateJoinMasterConfig").Build()
+		if err != nil {
+			return nil, err
+		}
+		kubeadmJoin, err := kubeadm.NewKubeadmJoinMasterStepBuilder(runtimeCtx, "ExecuteKubeadmJoinMaster").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "GenerateJoinMasterConfig", Step: generateJoinConfig, Hosts: t.NewMasters})
+		fragment.AddNode(&plan.ExecutionNode{Name: "ExecuteKubeadmJoinMaster", Step: kubeadmJoin, Hosts: t.NewMasters})
+		fragment.AddDependency("CreateJoinCredentials", "GenerateJoinMasterConfig")
+		fragment.AddDependency("GenerateJoinMasterConfig", "ExecuteKubeadmJoinMaster")
+		joinExits = append(joinExits, "ExecuteKubeadmJoinMaster")
+		for _, h := range t.NewMasters {
+			newNodeNames = append(newNodeNames, h.GetName())
+		}
+	}
+	if len(t.NewWorkers) > 0 {
+		generateJoinConfig, err := kubeadm.NewGenerateJoinWorkerConfigStepBuilder(runtimeCtx, "GenerateJoinWorkerConfig").Build()
+		if err != nil {
+			return nil, err
+		}
+		kubeadmJoin, err := kubeadm.NewKubeadmJoinWorkerStepBuilder(runtimeCtx, "ExecuteKubeadmJoinWorker").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "GenerateJoinWorkerConfig", Step: generateJoinConfig, Hosts: t.NewWorkers})
+		fragment.AddNode(&plan.ExecutionNode{Name: "ExecuteKubeadmJoinWorker", Step: kubeadmJoin, Hosts: t.NewWorkers})
+		fragment.AddDependency("CreateJoinCredentials", "GenerateJoinWorkerConfig")
+		fragment.AddDependency("GenerateJoinWorkerConfig", "ExecuteKubeadmJoinWorker")
+		// Masters join first so workers never race a control plane member that is still coming up.
+		if len(t.NewMasters) > 0 {
+			fragment.AddDependency("ExecuteKubeadmJoinMaster", "GenerateJoinWorkerConfig")
+		}
+		joinExits = append(joinExits, "ExecuteKubeadmJoinWorker")
+		for _, h := range t.NewWorkers {
+			newNodeNames = append(newNodeNames, h.GetName())
+		}
+	}
+
+	waitReady, err := kubeadm.NewWaitNodesReadyStepBuilder(runtimeCtx, "WaitNewNodesReady", newNodeNames).Build()
+	if err != nil {
+		return nil, err
+	}
+	fragment.AddNode(&plan.ExecutionNode{Name: "WaitNewNodesReady", Step: waitReady, Hosts: existingMaster})
+	for _, exit := range joinExits {
+		fragment.AddDependency(exit, "WaitNewNodesReady")
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*JoinNewNodesTask)(nil)
@@ -0,0 +1,92 @@
+package kubeadm
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubeadm"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// JoinWindowsWorkersTask prepares the Windows workers, installs kubeadm and the kubelet service on
+// them and joins them to the cluster. containerd must already run on these hosts.
+type JoinWindowsWorkersTask struct {
+	task.Base
+}
+
+func NewJoinWindowsWorkersTask() task.Task {
+	return &JoinWindowsWorkersTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "JoinWindowsWorkers",
+				Description: "Prepare Windows worker nodes and join them to the Kubernetes cluster",
+			},
+		},
+	}
+}
+
+func (t *JoinWindowsWorkersTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *JoinWindowsWorkersTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *JoinWindowsWorkersTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return len(ctx.GetWindowsHostsByRole(common.RoleWorker)) > 0, nil
+}
+
+func (t *JoinWindowsWorkersTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	windowsHosts := ctx.GetWindowsHostsByRole(common.RoleWorker)
+	if len(windowsHosts) == 0 {
+		ctx.GetLogger().Info("No Windows worker nodes to join, skipping task.")
+		return fragment, nil
+	}
+	controlNode, err := ctx.GetControlNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get control node to download Windows binaries: %w", err)
+	}
+
+	download, err := kubeadm.NewDownloadWindowsBinariesStepBuilder(runtimeCtx, "DownloadWindowsBinaries").Build()
+	if err != nil {
+		return nil, err
+	}
+	prepare, err := kubeadm.NewPrepareWindowsNodeStepBuilder(runtimeCtx, "PrepareWindowsNode").Build()
+	if err != nil {
+		return nil, err
+	}
+	install, err := kubeadm.NewInstallWindowsBinariesStepBuilder(runtimeCtx, "InstallWindowsBinaries").Build()
+	if err != nil {
+		return nil, err
+	}
+	installKubeletSvc, err := kubeadm.NewInstallWindowsKubeletServiceStepBuilder(runtimeCtx, "InstallWindowsKubeletService").Build()
+	if err != nil {
+		return nil, err
+	}
+	join, err := kubeadm.NewKubeadmJoinWindowsWorkerStepBuilder(runtimeCtx, "ExecuteKubeadmJoinWindowsWorker").Build()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.AddNode(&plan.ExecutionNode{Name: "DownloadWindowsBinaries", Step: download, Hosts: []remotefw.Host{controlNode}})
+	fragment.AddNode(&plan.ExecutionNode{Name: "PrepareWindowsNode", Step: prepare, Hosts: windowsHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "InstallWindowsBinaries", Step: install, Hosts: windowsHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "InstallWindowsKubeletService", Step: installKubeletSvc, Hosts: windowsHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ExecuteKubeadmJoinWindowsWorker", Step: join, Hosts: windowsHosts})
+
+	fragment.AddDependency("DownloadWindowsBinaries", "InstallWindowsBinaries")
+	fragment.AddDependency("PrepareWindowsNode", "InstallWindowsBinaries")
+	fragment.AddDependency("InstallWindowsBinaries", "InstallWindowsKubeletService")
+	fragment.AddDependency("InstallWindowsKubeletService", "ExecuteKubeadmJoinWindowsWorker")
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
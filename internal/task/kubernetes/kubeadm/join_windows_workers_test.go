@@ -0,0 +1,50 @@
+package kubeadm
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+func TestJoinWindowsWorkersTask_Plan(t *testing.T) {
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "windows-workers"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts: []v1alpha1.HostSpec{
+			{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster, common.RoleEtcd}},
+			{Name: "worker1", Address: "10.0.0.2", Roles: []string{common.RoleWorker}},
+			{Name: "win1", Address: "10.0.0.3", Roles: []string{common.RoleWorker}, OS: common.OSWindows},
+		},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.30.4"},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+	ctx := runtimetest.NewRuntime(t, cfg)
+
+	for _, h := range ctx.GetHostsByRole(common.RoleWorker) {
+		if h.GetName() == "win1" {
+			t.Fatalf("expected the Linux worker tasks to leave out windows hosts")
+		}
+	}
+
+	joinWindows := NewJoinWindowsWorkersTask()
+	required, err := joinWindows.IsRequired(ctx)
+	if err != nil || !required {
+		t.Fatalf("expected the task to be required, got %v (err: %v)", required, err)
+	}
+	fragment, err := joinWindows.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	for _, id := range []string{"PrepareWindowsNode", "InstallWindowsBinaries", "InstallWindowsKubeletService", "ExecuteKubeadmJoinWindowsWorker"} {
+		node := fragment.Nodes[plan.NodeID(id)]
+		if node == nil || len(node.Hosts) != 1 || node.Hosts[0].GetName() != "win1" {
+			t.Errorf("expected %s to run on win1 only, got %+v", id, node)
+		}
+	}
+	if exits := fragment.ExitNodes; len(exits) != 1 || exits[0] != "ExecuteKubeadmJoinWindowsWorker" {
+		t.Errorf("expected the join to be the only exit node, got %v", exits)
+	}
+}
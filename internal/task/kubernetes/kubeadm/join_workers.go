@@ -6,6 +6,8 @@ import (
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubeadm"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubelet"
+	lbcommon "github.com/mensylisir/kubexm/internal/step/loadbalancer/common"
 	"github.com/mensylisir/kubexm/internal/task"
 )
 
@@ -61,6 +63,19 @@ func (t *JoinWorkersTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment
 
 	fragment.AddDependency("GenerateJoinWorkerConfig", "ExecuteKubeadmJoinWorker")
 
+	// Workers with a local apiserver proxy joined through a master, now move kubelet to the proxy.
+	if lbcommon.LocalProxyEnabled(ctx.GetClusterConfig()) {
+		proxyHosts := lbcommon.LocalProxyHosts(workerHosts, ctx.GetHostsByRole(common.RoleMaster))
+		if len(proxyHosts) > 0 {
+			useLocalProxy, err := kubelet.NewUseLocalAPIServerProxyStepBuilder(runtimeCtx, "UseLocalAPIServerProxy").Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: "UseLocalAPIServerProxy", Step: useLocalProxy, Hosts: proxyHosts})
+			fragment.AddDependency("ExecuteKubeadmJoinWorker", "UseLocalAPIServerProxy")
+		}
+	}
+
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
 }
@@ -0,0 +1,138 @@
+package kubeadm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/etcd"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubeadm"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/perform"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// RemoveNodeTask takes a single node out of a running cluster: it is cordoned and drained, removed
+// from the etcd member list if it runs an etcd member, reset with kubeadm and finally its Node
+// object is deleted. Cluster-side steps run on Operator, a master that stays in the cluster.
+type RemoveNodeTask struct {
+	task.Base
+	Target   remotefw.Host
+	Operator remotefw.Host
+}
+
+func NewRemoveNodeTask(target, operator remotefw.Host) task.Task {
+	return &RemoveNodeTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        fmt.Sprintf("RemoveNode-%s", target.GetName()),
+				Description: fmt.Sprintf("Drain, reset and delete node %s", target.GetName()),
+			},
+		},
+		Target:   target,
+		Operator: operator,
+	}
+}
+
+func (t *RemoveNodeTask) Name() string        { return t.Meta.Name }
+func (t *RemoveNodeTask) Description() string { return t.Meta.Description }
+
+func (t *RemoveNodeTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return t.Target != nil, nil
+}
+
+func (t *RemoveNodeTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	if t.Operator == nil {
+		return nil, fmt.Errorf("a master that stays in the cluster is required to remove node %s", t.Target.GetName())
+	}
+	nodeName := t.Target.GetName()
+	operator := []remotefw.Host{t.Operator}
+	cordonID := plan.NodeID("CordonNode-" + nodeName)
+	drainID := plan.NodeID("DrainNode-" + nodeName)
+	removeMemberID := plan.NodeID("RemoveEtcdMember-" + nodeName)
+	resetID := plan.NodeID("KubeadmReset-" + nodeName)
+	deleteID := plan.NodeID("DeleteNode-" + nodeName)
+
+	cordon, err := perform.NewCordonNodeStepBuilder(runtimeCtx, string(cordonID), nodeName).WithSudo(true).Build()
+	if err != nil {
+		return nil, err
+	}
+	drain, err := perform.NewDrainNodeStepBuilder(runtimeCtx, string(drainID), nodeName).WithSudo(true).Build()
+	if err != nil {
+		return nil, err
+	}
+	reset, err := kubeadm.NewKubeadmResetStepBuilder(runtimeCtx, string(resetID)).WithSudo(true).Build()
+	if err != nil {
+		return nil, err
+	}
+	deleteNode, err := perform.NewDeleteNodeStepBuilder(runtimeCtx, string(deleteID), nodeName).WithSudo(true).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.AddNode(&plan.ExecutionNode{Name: string(cordonID), Step: cordon, Hosts: operator})
+	fragment.AddNode(&plan.ExecutionNode{Name: string(drainID), Step: drain, Hosts: operator})
+	fragment.AddNode(&plan.ExecutionNode{Name: string(resetID), Step: reset, Hosts: []remotefw.Host{t.Target}})
+	fragment.AddNode(&plan.ExecutionNode{Name: string(deleteID), Step: deleteNode, Hosts: operator})
+	fragment.AddDependency(cordonID, drainID)
+
+	// The member must leave etcd before kubeadm reset wipes its data, or the cluster keeps waiting
+	// for a peer that will never come back.
+	beforeReset := drainID
+	if etcdOperator := t.etcdOperator(ctx); etcdOperator != nil {
+		removeMember, err := etcd.NewRemoveEtcdMemberStepBuilder(runtimeCtx, string(removeMemberID)).
+			WithNodeToRemove(t.Target).Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: string(removeMemberID), Step: removeMember, Hosts: []remotefw.Host{etcdOperator}})
+		fragment.AddDependency(drainID, removeMemberID)
+		beforeReset = removeMemberID
+	} else {
+		ctx.GetLogger().Info("Node does not run an etcd member, skipping etcd member removal.", "node", nodeName)
+	}
+	fragment.AddDependency(beforeReset, resetID)
+	fragment.AddDependency(resetID, deleteID)
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// etcdOperator returns the host that removes the target from the etcd member list, or nil when the
+// target is not a control plane node with an etcd member on it. With kubeadm etcd every master is a
+// member; with kubexm etcd only hosts that also carry the etcd role are.
+func (t *RemoveNodeTask) etcdOperator(ctx runtime.TaskContext) remotefw.Host {
+	roles := t.Target.GetRoles()
+	if !slices.Contains(roles, common.RoleMaster) {
+		return nil
+	}
+	etcdType := string(common.EtcdDeploymentTypeKubexm)
+	if cfg := ctx.GetClusterConfig(); cfg != nil && cfg.Spec.Etcd != nil && cfg.Spec.Etcd.Type != "" {
+		etcdType = cfg.Spec.Etcd.Type
+	}
+	switch etcdType {
+	case string(common.EtcdDeploymentTypeKubeadm):
+		return t.Operator
+	case string(common.EtcdDeploymentTypeKubexm):
+		if !slices.Contains(roles, common.RoleEtcd) {
+			return nil
+		}
+		members := ctx.GetHostsByRole(common.RoleEtcd)
+		sort.Slice(members, func(i, j int) bool { return members[i].GetName() < members[j].GetName() })
+		for _, h := range members {
+			if h.GetName() != t.Target.GetName() {
+				return h
+			}
+		}
+	}
+	return nil
+}
+
+var _ task.Task = (*RemoveNodeTask)(nil)
@@ -0,0 +1,97 @@
+package kubeadm
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+func newRemoveNodeTestContext(t *testing.T, etcdType string) *runtime.Context {
+	t.Helper()
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "remove-node"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts: []v1alpha1.HostSpec{
+			{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster, common.RoleEtcd}},
+			{Name: "master2", Address: "10.0.0.2", Roles: []string{common.RoleMaster, common.RoleEtcd}},
+			{Name: "master3", Address: "10.0.0.3", Roles: []string{common.RoleMaster}},
+			{Name: "worker1", Address: "10.0.0.4", Roles: []string{common.RoleWorker}},
+		},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.29.3"},
+		Etcd:       &v1alpha1.Etcd{Type: etcdType},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+
+	return runtimetest.NewRuntime(t, cfg)
+}
+
+func hostByName(t *testing.T, ctx *runtime.Context, name string) remotefw.Host {
+	t.Helper()
+	for _, h := range ctx.GetHostsByRole("") {
+		if h.GetName() == name {
+			return h
+		}
+	}
+	t.Fatalf("host %s not found", name)
+	return nil
+}
+
+func TestRemoveNodeTask_Plan(t *testing.T) {
+	tests := []struct {
+		name           string
+		etcdType       string
+		target         string
+		wantEtcdMember string
+	}{
+		{name: "worker", etcdType: string(common.EtcdDeploymentTypeKubeadm), target: "worker1"},
+		{name: "master with kubeadm etcd", etcdType: string(common.EtcdDeploymentTypeKubeadm), target: "master3", wantEtcdMember: "master1"},
+		{name: "master with kubexm etcd member", etcdType: string(common.EtcdDeploymentTypeKubexm), target: "master1", wantEtcdMember: "master2"},
+		{name: "master without kubexm etcd member", etcdType: string(common.EtcdDeploymentTypeKubexm), target: "master3"},
+		{name: "master with external etcd", etcdType: string(common.EtcdDeploymentTypeExternal), target: "master2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newRemoveNodeTestContext(t, tt.etcdType)
+			operatorName := "master1"
+			if tt.target == "master1" {
+				operatorName = "master2"
+			}
+			target := hostByName(t, ctx, tt.target)
+			fragment, err := NewRemoveNodeTask(target, hostByName(t, ctx, operatorName)).Plan(ctx)
+			if err != nil {
+				t.Fatalf("plan failed: %v", err)
+			}
+
+			reset := fragment.Nodes[plan.NodeID("KubeadmReset-"+tt.target)]
+			if reset == nil || len(reset.Hosts) != 1 || reset.Hosts[0].GetName() != tt.target {
+				t.Fatalf("expected kubeadm reset to run on %s, got %+v", tt.target, reset)
+			}
+			for _, id := range []string{"CordonNode-", "DrainNode-", "DeleteNode-"} {
+				node := fragment.Nodes[plan.NodeID(id+tt.target)]
+				if node == nil || node.Hosts[0].GetName() != operatorName {
+					t.Errorf("expected %s%s to run on %s, got %+v", id, tt.target, operatorName, node)
+				}
+			}
+
+			removeMember := fragment.Nodes[plan.NodeID("RemoveEtcdMember-"+tt.target)]
+			if tt.wantEtcdMember == "" {
+				if removeMember != nil {
+					t.Fatalf("expected no etcd member removal, got one on %s", removeMember.Hosts[0].GetName())
+				}
+				return
+			}
+			if removeMember == nil || removeMember.Hosts[0].GetName() != tt.wantEtcdMember {
+				t.Fatalf("expected etcd member removal on %s, got %+v", tt.wantEtcdMember, removeMember)
+			}
+			if len(reset.Dependencies) != 1 || reset.Dependencies[0] != plan.NodeID("RemoveEtcdMember-"+tt.target) {
+				t.Errorf("expected reset to wait for etcd member removal, got %v", reset.Dependencies)
+			}
+		})
+	}
+}
@@ -2,13 +2,17 @@ package kubeadm
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/mensylisir/kubexm/internal/common"
-	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	kubeadmstep "github.com/mensylisir/kubexm/internal/step/kubernetes/kubeadm"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubectl"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/kubelet"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/perform"
 	"github.com/mensylisir/kubexm/internal/task"
 )
 
@@ -63,37 +67,111 @@ func (t *UpgradeControlPlaneTask) Plan(ctx runtime.TaskContext) (*plan.Execution
 		ctx.GetLogger().Info("No control plane nodes found, skipping upgrade")
 		return fragment, nil
 	}
+	sort.Slice(controlPlaneNodes, func(i, j int) bool { return controlPlaneNodes[i].GetName() < controlPlaneNodes[j].GetName() })
 
-	firstMaster := controlPlaneNodes[0]
-	otherMasters := controlPlaneNodes[1:]
+	// Masters are upgraded strictly one at a time. The first one runs 'kubeadm upgrade apply', the
+	// others 'kubeadm upgrade node'; each then gets the new kubelet and kubectl while drained.
+	var previousExit plan.NodeID
+	for i, master := range controlPlaneNodes {
+		name := master.GetName()
+		hosts := []remotefw.Host{master}
 
-	// First master: run kubeadm upgrade apply
-	upgradeApplyStep, err := kubeadmstep.NewKubeadmUpgradeApplyStepBuilder(runtimeCtx, "UpgradeApply").Build()
-	if err != nil {
-		return nil, err
-	}
-	fragment.AddNode(&plan.ExecutionNode{
-		Name:  "UpgradeFirstMaster",
-		Step:  upgradeApplyStep,
-		Hosts: []remotefw.Host{firstMaster},
-	})
-
-	// Other masters: run kubeadm upgrade node sequentially
-	if len(otherMasters) > 0 {
-		upgradeNodeStep, err := kubeadmstep.NewKubeadmUpgradeNodeStepBuilder(runtimeCtx, "UpgradeNode").Build()
+		installKubeadm, err := kubeadmstep.NewInstallKubeadmStepBuilder(runtimeCtx, "UpgradeKubeadmBinary-"+name).Build()
 		if err != nil {
 			return nil, err
 		}
-		fragment.AddNode(&plan.ExecutionNode{
-			Name:  "UpgradeOtherMasters",
-			Step:  upgradeNodeStep,
-			Hosts: otherMasters,
-		})
-		fragment.AddDependency("UpgradeFirstMaster", "UpgradeOtherMasters")
+		fragment.AddNode(&plan.ExecutionNode{Name: "UpgradeKubeadmBinary-" + name, Step: installKubeadm, Hosts: hosts})
+		if previousExit != "" {
+			fragment.AddDependency(previousExit, plan.NodeID("UpgradeKubeadmBinary-"+name))
+		}
+
+		upgradeID := "UpgradeNode-" + name
+		if i == 0 {
+			upgradePlanStep, err := kubeadmstep.NewKubeadmUpgradePlanStepBuilder(runtimeCtx, "UpgradePlan").WithTargetVersion(t.targetVersion).WithSudo(true).Build()
+			if err != nil {
+				return nil, err
+			}
+			upgradeApplyStep, err := kubeadmstep.NewKubeadmUpgradeApplyStepBuilder(runtimeCtx, "UpgradeApply").WithTargetVersion(t.targetVersion).WithSudo(true).Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: "UpgradePlan", Step: upgradePlanStep, Hosts: hosts})
+			fragment.AddNode(&plan.ExecutionNode{Name: "UpgradeFirstMaster", Step: upgradeApplyStep, Hosts: hosts})
+			fragment.AddDependency(plan.NodeID("UpgradeKubeadmBinary-"+name), "UpgradePlan")
+			fragment.AddDependency("UpgradePlan", "UpgradeFirstMaster")
+			upgradeID = "UpgradeFirstMaster"
+		} else {
+			upgradeNodeStep, err := kubeadmstep.NewKubeadmUpgradeNodeStepBuilder(runtimeCtx, upgradeID).WithTargetVersion(t.targetVersion).WithSudo(true).Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: upgradeID, Step: upgradeNodeStep, Hosts: hosts})
+			fragment.AddDependency(plan.NodeID("UpgradeKubeadmBinary-"+name), plan.NodeID(upgradeID))
+		}
+
+		exit, err := planKubeletUpgrade(fragment, runtimeCtx, master, master, plan.NodeID(upgradeID))
+		if err != nil {
+			return nil, err
+		}
+		previousExit = exit
 	}
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
 }
 
+// planKubeletUpgrade adds the drain, kubelet and kubectl binary swap and uncordon of node to the
+// fragment after the given dependency. kubectl commands against the cluster run on operator. It
+// returns the ID of the final uncordon node.
+func planKubeletUpgrade(fragment *plan.ExecutionFragment, ctx runtime.ExecutionContext, node, operator remotefw.Host, after plan.NodeID) (plan.NodeID, error) {
+	name := node.GetName()
+	cordonID := "Cordon-" + name
+	drainID := "Drain-" + name
+	kubeletID := "UpgradeKubeletBinary-" + name
+	kubectlID := "UpgradeKubectlBinary-" + name
+	restartID := "RestartKubelet-" + name
+	uncordonID := "Uncordon-" + name
+
+	cordon, err := perform.NewCordonNodeStepBuilder(ctx, cordonID, name).WithSudo(true).Build()
+	if err != nil {
+		return "", err
+	}
+	drain, err := perform.NewDrainNodeStepBuilder(ctx, drainID, name).WithSudo(true).Build()
+	if err != nil {
+		return "", err
+	}
+	installKubelet, err := kubelet.NewInstallKubeletStepBuilder(ctx, kubeletID).Build()
+	if err != nil {
+		return "", err
+	}
+	installKubectl, err := kubectl.NewInstallKubectlStepBuilder(ctx, kubectlID).Build()
+	if err != nil {
+		return "", err
+	}
+	restartKubelet, err := kubelet.NewRestartKubeletStepBuilder(ctx, restartID).Build()
+	if err != nil {
+		return "", err
+	}
+	uncordon, err := perform.NewUncordonNodeStepBuilder(ctx, uncordonID, name).WithSudo(true).Build()
+	if err != nil {
+		return "", err
+	}
+
+	fragment.AddNode(&plan.ExecutionNode{Name: cordonID, Step: cordon, Hosts: []remotefw.Host{operator}})
+	fragment.AddNode(&plan.ExecutionNode{Name: drainID, Step: drain, Hosts: []remotefw.Host{operator}})
+	fragment.AddNode(&plan.ExecutionNode{Name: kubeletID, Step: installKubelet, Hosts: []remotefw.Host{node}})
+	fragment.AddNode(&plan.ExecutionNode{Name: kubectlID, Step: installKubectl, Hosts: []remotefw.Host{node}})
+	fragment.AddNode(&plan.ExecutionNode{Name: restartID, Step: restartKubelet, Hosts: []remotefw.Host{node}})
+	fragment.AddNode(&plan.ExecutionNode{Name: uncordonID, Step: uncordon, Hosts: []remotefw.Host{operator}})
+
+	fragment.AddDependency(after, plan.NodeID(cordonID))
+	fragment.AddDependency(plan.NodeID(cordonID), plan.NodeID(drainID))
+	fragment.AddDependency(plan.NodeID(drainID), plan.NodeID(kubeletID))
+	fragment.AddDependency(plan.NodeID(drainID), plan.NodeID(kubectlID))
+	fragment.AddDependency(plan.NodeID(kubeletID), plan.NodeID(restartID))
+	fragment.AddDependency(plan.NodeID(kubectlID), plan.NodeID(restartID))
+	fragment.AddDependency(plan.NodeID(restartID), plan.NodeID(uncordonID))
+	return plan.NodeID(uncordonID), nil
+}
+
 var _ task.Task = (*UpgradeControlPlaneTask)(nil)
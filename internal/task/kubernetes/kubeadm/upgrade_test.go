@@ -0,0 +1,111 @@
+package kubeadm
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+)
+
+func newUpgradeTestContext(t *testing.T) *runtime.Context {
+	t.Helper()
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "upgrade"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts: []v1alpha1.HostSpec{
+			{Name: "master2", Address: "10.0.0.2", Roles: []string{common.RoleMaster}},
+			{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster}},
+			{Name: "worker1", Address: "10.0.0.3", Roles: []string{common.RoleWorker}},
+			{Name: "worker2", Address: "10.0.0.4", Roles: []string{common.RoleWorker}},
+			{Name: "worker3", Address: "10.0.0.5", Roles: []string{common.RoleWorker}},
+		},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.29.3"},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+
+	return runtimetest.NewRuntime(t, cfg)
+}
+
+func TestUpgradeControlPlaneTask_Plan(t *testing.T) {
+	ctx := newUpgradeTestContext(t)
+	fragment, err := NewUpgradeControlPlaneTask("v1.30.0").Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+
+	apply := fragment.Nodes["UpgradeFirstMaster"]
+	if apply == nil || apply.Hosts[0].GetName() != "master1" {
+		t.Fatalf("expected kubeadm upgrade apply on master1, got %+v", apply)
+	}
+	if node := fragment.Nodes["UpgradeNode-master2"]; node == nil || node.Hosts[0].GetName() != "master2" {
+		t.Fatalf("expected kubeadm upgrade node on master2, got %+v", node)
+	}
+	next := fragment.Nodes["UpgradeKubeadmBinary-master2"]
+	if next == nil || !slices.Contains(next.Dependencies, plan.NodeID("Uncordon-master1")) {
+		t.Fatalf("expected master2 to wait for master1 to be uncordoned, got %+v", next)
+	}
+	if len(fragment.EntryNodes) != 1 || fragment.EntryNodes[0] != "UpgradeKubeadmBinary-master1" {
+		t.Errorf("expected master1 to be upgraded first, got entry nodes %v", fragment.EntryNodes)
+	}
+	if len(fragment.ExitNodes) != 1 || fragment.ExitNodes[0] != "Uncordon-master2" {
+		t.Errorf("expected the task to end with uncordoning master2, got exit nodes %v", fragment.ExitNodes)
+	}
+}
+
+func TestUpgradeWorkersTask_Plan(t *testing.T) {
+	tests := []struct {
+		name        string
+		batchSize   int
+		wantBatches [][]string
+	}{
+		{name: "one at a time", batchSize: 0, wantBatches: [][]string{{"worker1"}, {"worker2"}, {"worker3"}}},
+		{name: "batches of two", batchSize: 2, wantBatches: [][]string{{"worker1", "worker2"}, {"worker3"}}},
+		{name: "all at once", batchSize: 5, wantBatches: [][]string{{"worker1", "worker2", "worker3"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newUpgradeTestContext(t)
+			fragment, err := NewUpgradeWorkersTask("v1.30.0", tt.batchSize).Plan(ctx)
+			if err != nil {
+				t.Fatalf("plan failed: %v", err)
+			}
+
+			var previous []string
+			for i, want := range tt.wantBatches {
+				batchID := fmt.Sprintf("UpgradeWorkersBatch%d", i+1)
+				upgrade := fragment.Nodes[plan.NodeID(batchID)]
+				if upgrade == nil {
+					t.Fatalf("missing node %s", batchID)
+				}
+				var got []string
+				for _, h := range upgrade.Hosts {
+					got = append(got, h.GetName())
+				}
+				if !slices.Equal(got, want) {
+					t.Errorf("batch %d: expected %v, got %v", i+1, want, got)
+				}
+				kubeadm := fragment.Nodes[plan.NodeID("UpgradeKubeadmBinary-"+batchID)]
+				for _, name := range previous {
+					if !slices.Contains(kubeadm.Dependencies, plan.NodeID("Uncordon-"+name)) {
+						t.Errorf("batch %d does not wait for %s to be uncordoned: %v", i+1, name, kubeadm.Dependencies)
+					}
+				}
+				for _, name := range want {
+					if drain := fragment.Nodes[plan.NodeID("Drain-"+name)]; drain == nil || drain.Hosts[0].GetName() != "master1" {
+						t.Errorf("expected %s to be drained from master1, got %+v", name, drain)
+					}
+				}
+				previous = want
+			}
+			if _, ok := fragment.Nodes[plan.NodeID(fmt.Sprintf("UpgradeWorkersBatch%d", len(tt.wantBatches)+1))]; ok {
+				t.Errorf("expected %d batches", len(tt.wantBatches))
+			}
+		})
+	}
+}
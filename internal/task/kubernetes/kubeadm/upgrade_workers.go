@@ -2,23 +2,32 @@ package kubeadm
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	kubeadmstep "github.com/mensylisir/kubexm/internal/step/kubernetes/kubeadm"
 	"github.com/mensylisir/kubexm/internal/task"
 )
 
-// UpgradeWorkersTask handles upgrading Kubernetes worker nodes.
+// UpgradeWorkersTask handles upgrading Kubernetes worker nodes. Workers are upgraded in batches of
+// batchSize; the next batch starts only once every node of the previous one is uncordoned.
 type UpgradeWorkersTask struct {
 	task.Base
 	targetVersion string
+	batchSize     int
 }
 
-// NewUpgradeWorkersTask creates a new UpgradeWorkersTask.
-func NewUpgradeWorkersTask(targetVersion string) task.Task {
+// NewUpgradeWorkersTask creates a new UpgradeWorkersTask. A batchSize below 1 upgrades one worker
+// at a time.
+func NewUpgradeWorkersTask(targetVersion string, batchSize int) task.Task {
+	if batchSize < 1 {
+		batchSize = 1
+	}
 	return &UpgradeWorkersTask{
 		Base: task.Base{
 			Meta: spec.TaskMeta{
@@ -27,6 +36,7 @@ func NewUpgradeWorkersTask(targetVersion string) task.Task {
 			},
 		},
 		targetVersion: targetVersion,
+		batchSize:     batchSize,
 	}
 }
 
@@ -63,16 +73,49 @@ func (t *UpgradeWorkersTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragm
 		return fragment, nil
 	}
 
-	// Workers: run kubeadm upgrade node
-	upgradeNodeStep, err := kubeadmstep.NewKubeadmUpgradeNodeStepBuilder(runtimeCtx, "UpgradeWorkerNode").Build()
-	if err != nil {
-		return nil, err
+	// Workers that are also masters were already upgraded with the control plane.
+	masters := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masters) == 0 {
+		return nil, fmt.Errorf("a master is required to drain worker nodes")
 	}
-	fragment.AddNode(&plan.ExecutionNode{
-		Name:  "UpgradeWorkers",
-		Step:  upgradeNodeStep,
-		Hosts: workerNodes,
+	sort.Slice(masters, func(i, j int) bool { return masters[i].GetName() < masters[j].GetName() })
+	operator := masters[0]
+	workerNodes = slices.DeleteFunc(workerNodes, func(h remotefw.Host) bool {
+		return slices.Contains(h.GetRoles(), common.RoleMaster)
 	})
+	sort.Slice(workerNodes, func(i, j int) bool { return workerNodes[i].GetName() < workerNodes[j].GetName() })
+
+	var previousExits []plan.NodeID
+	for i := 0; i < len(workerNodes); i += t.batchSize {
+		batch := workerNodes[i:min(i+t.batchSize, len(workerNodes))]
+		batchID := fmt.Sprintf("UpgradeWorkersBatch%d", i/t.batchSize+1)
+
+		installKubeadm, err := kubeadmstep.NewInstallKubeadmStepBuilder(runtimeCtx, "UpgradeKubeadmBinary-"+batchID).Build()
+		if err != nil {
+			return nil, err
+		}
+		upgradeNodeStep, err := kubeadmstep.NewKubeadmUpgradeNodeStepBuilder(runtimeCtx, "UpgradeWorkerNode").WithTargetVersion(t.targetVersion).WithSudo(true).Build()
+		if err != nil {
+			return nil, err
+		}
+		kubeadmID := plan.NodeID("UpgradeKubeadmBinary-" + batchID)
+		upgradeID := plan.NodeID(batchID)
+		fragment.AddNode(&plan.ExecutionNode{Name: string(kubeadmID), Step: installKubeadm, Hosts: batch})
+		fragment.AddNode(&plan.ExecutionNode{Name: string(upgradeID), Step: upgradeNodeStep, Hosts: batch})
+		fragment.AddDependency(kubeadmID, upgradeID)
+		for _, prev := range previousExits {
+			fragment.AddDependency(prev, kubeadmID)
+		}
+
+		previousExits = nil
+		for _, worker := range batch {
+			exit, err := planKubeletUpgrade(fragment, runtimeCtx, worker, operator, upgradeID)
+			if err != nil {
+				return nil, err
+			}
+			previousExits = append(previousExits, exit)
+		}
+	}
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
@@ -1,6 +1,7 @@
 package kubexm
 
 import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
@@ -61,20 +62,10 @@ func (t *DeployWorkerNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFr
 	if err != nil {
 		return nil, err
 	}
-	installKubeProxy, err := proxystep.NewInstallKubeProxyStepBuilder(runtimeCtx, "InstallKubeProxyBinary").Build()
-	if err != nil {
-		return nil, err
-	}
-
 	createKubeletConfig, err := kubeletstep.NewCreateKubeletConfigYAMLStepBuilder(runtimeCtx, "CreateKubeletConfigYAMLForWorkers").Build()
 	if err != nil {
 		return nil, err
 	}
-	createKubeProxyConfig, err := proxystep.NewCreateKubeProxyConfigYAMLStepBuilder(runtimeCtx, "CreateKubeProxyConfigYAMLForWorkers").Build()
-	if err != nil {
-		return nil, err
-	}
-
 	installKubeletService, err := kubeletstep.NewInstallKubeletServiceStepBuilder(runtimeCtx, "InstallKubeletService").Build()
 	if err != nil {
 		return nil, err
@@ -91,32 +82,15 @@ func (t *DeployWorkerNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFr
 	if err != nil {
 		return nil, err
 	}
-	installProxyService, err := proxystep.NewInstallKubeProxyServiceStepBuilder(runtimeCtx, "InstallKubeProxyService").Build()
-	if err != nil {
-		return nil, err
-	}
-	enableProxy, err := proxystep.NewEnableKubeProxyStepBuilder(runtimeCtx, "EnableKubeProxyService").Build()
-	if err != nil {
-		return nil, err
-	}
-	startProxy, err := proxystep.NewStartKubeProxyStepBuilder(runtimeCtx, "StartKubeProxyService").Build()
-	if err != nil {
-		return nil, err
-	}
 
 	fragment.AddNode(&plan.ExecutionNode{Name: "DistributeCACertsToWorkers", Step: distributeCACerts, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "DistributeKubeletCredentials", Step: distributeKubeletConfig, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeletBinary", Step: installKubelet, Hosts: workerHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeProxyBinary", Step: installKubeProxy, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "CreateKubeletConfigYAMLForWorkers", Step: createKubeletConfig, Hosts: workerHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "CreateKubeProxyConfigYAMLForWorkers", Step: createKubeProxyConfig, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeletService", Step: installKubeletService, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeletDropIn", Step: installKubeletDropIn, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "EnableKubeletService", Step: enableKubelet, Hosts: workerHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "StartKubeletService", Step: startKubelet, Hosts: workerHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeProxyService", Step: installProxyService, Hosts: workerHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "EnableKubeProxyService", Step: enableProxy, Hosts: workerHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "StartKubeProxyService", Step: startProxy, Hosts: workerHosts})
 
 	fragment.AddDependency("InstallKubeletBinary", "InstallKubeletService")
 	fragment.AddDependency("DistributeCACertsToWorkers", "CreateKubeletConfigYAMLForWorkers")
@@ -126,12 +100,42 @@ func (t *DeployWorkerNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFr
 	fragment.AddDependency("InstallKubeletDropIn", "EnableKubeletService")
 	fragment.AddDependency("EnableKubeletService", "StartKubeletService")
 
-	fragment.AddDependency("InstallKubeProxyBinary", "InstallKubeProxyService")
-	fragment.AddDependency("CreateKubeProxyConfigYAMLForWorkers", "InstallKubeProxyService")
-	fragment.AddDependency("InstallKubeProxyService", "EnableKubeProxyService")
-	fragment.AddDependency("EnableKubeProxyService", "StartKubeProxyService")
-
-	fragment.AddDependency("StartKubeletService", "StartKubeProxyService")
+	// Without kube-proxy the CNI plugin, e.g. Cilium in strict replacement mode, load balances services.
+	if v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec) {
+		installKubeProxy, err := proxystep.NewInstallKubeProxyStepBuilder(runtimeCtx, "InstallKubeProxyBinary").Build()
+		if err != nil {
+			return nil, err
+		}
+		createKubeProxyConfig, err := proxystep.NewCreateKubeProxyConfigYAMLStepBuilder(runtimeCtx, "CreateKubeProxyConfigYAMLForWorkers").Build()
+		if err != nil {
+			return nil, err
+		}
+		installProxyService, err := proxystep.NewInstallKubeProxyServiceStepBuilder(runtimeCtx, "InstallKubeProxyService").Build()
+		if err != nil {
+			return nil, err
+		}
+		enableProxy, err := proxystep.NewEnableKubeProxyStepBuilder(runtimeCtx, "EnableKubeProxyService").Build()
+		if err != nil {
+			return nil, err
+		}
+		startProxy, err := proxystep.NewStartKubeProxyStepBuilder(runtimeCtx, "StartKubeProxyService").Build()
+		if err != nil {
+			return nil, err
+		}
+
+		fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeProxyBinary", Step: installKubeProxy, Hosts: workerHosts})
+		fragment.AddNode(&plan.ExecutionNode{Name: "CreateKubeProxyConfigYAMLForWorkers", Step: createKubeProxyConfig, Hosts: workerHosts})
+		fragment.AddNode(&plan.ExecutionNode{Name: "InstallKubeProxyService", Step: installProxyService, Hosts: workerHosts})
+		fragment.AddNode(&plan.ExecutionNode{Name: "EnableKubeProxyService", Step: enableProxy, Hosts: workerHosts})
+		fragment.AddNode(&plan.ExecutionNode{Name: "StartKubeProxyService", Step: startProxy, Hosts: workerHosts})
+
+		fragment.AddDependency("InstallKubeProxyBinary", "InstallKubeProxyService")
+		fragment.AddDependency("CreateKubeProxyConfigYAMLForWorkers", "InstallKubeProxyService")
+		fragment.AddDependency("InstallKubeProxyService", "EnableKubeProxyService")
+		fragment.AddDependency("EnableKubeProxyService", "StartKubeProxyService")
+
+		fragment.AddDependency("StartKubeletService", "StartKubeProxyService")
+	}
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
@@ -1,10 +1,13 @@
 package kubexm
 
 import (
+	"fmt"
+
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
 	kubecertsstep "github.com/mensylisir/kubexm/internal/step/kubernetes/certs"
 	kubeproxycertsstep "github.com/mensylisir/kubexm/internal/step/kubernetes/kube-proxy"
 	kubeletcertsstep "github.com/mensylisir/kubexm/internal/step/kubernetes/kubelet"
@@ -41,7 +44,20 @@ func (t *GenerateKubePKITask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFrag
 	}
 	executionHost := []remotefw.Host{controlNode}
 
-	generateKubeCA, err := kubecertsstep.NewGenerateKubeCAStepBuilder(runtimeCtx, "GenerateKubeCAs").Build()
+	var externalCA, externalFrontProxyCA *helpers.ExternalCA
+	if certs := ctx.GetClusterConfig().Spec.Certs; certs != nil && certs.ExternalCA != nil {
+		if externalCA, err = helpers.LoadExternalCA(certs.ExternalCA.Kubernetes); err != nil {
+			return nil, fmt.Errorf("invalid external Kubernetes CA: %w", err)
+		}
+		if externalFrontProxyCA, err = helpers.LoadExternalCA(certs.ExternalCA.FrontProxy); err != nil {
+			return nil, fmt.Errorf("invalid external front-proxy CA: %w", err)
+		}
+	}
+
+	generateKubeCA, err := kubecertsstep.NewGenerateKubeCAStepBuilder(runtimeCtx, "GenerateKubeCAs").
+		WithExternalCA(externalCA).
+		WithExternalFrontProxyCA(externalFrontProxyCA).
+		Build()
 	if err != nil {
 		return nil, err
 	}
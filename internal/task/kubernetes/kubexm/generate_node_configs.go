@@ -1,6 +1,7 @@
 package kubexm
 
 import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
@@ -44,13 +45,15 @@ func (t *GenerateNodeComponentConfigsTask) Plan(ctx runtime.TaskContext) (*plan.
 	if err != nil {
 		return nil, err
 	}
-	createKubeProxyConfig, err := kubeproxystep.NewCreateKubeProxyConfigYAMLStepBuilder(runtimeCtx, "CreateKubeProxyConfigYAML").Build()
-	if err != nil {
-		return nil, err
-	}
 
 	fragment.AddNode(&plan.ExecutionNode{Name: "CreateKubeletConfigYAML", Step: createKubeletConfig, Hosts: allHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "CreateKubeProxyConfigYAML", Step: createKubeProxyConfig, Hosts: allHosts})
+	if v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec) {
+		createKubeProxyConfig, err := kubeproxystep.NewCreateKubeProxyConfigYAMLStepBuilder(runtimeCtx, "CreateKubeProxyConfigYAML").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "CreateKubeProxyConfigYAML", Step: createKubeProxyConfig, Hosts: allHosts})
+	}
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
@@ -1,6 +1,7 @@
 package kubexm
 
 import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
@@ -50,13 +51,15 @@ func (t *GenerateNodeKubeconfigsTask) Plan(ctx runtime.TaskContext) (*plan.Execu
 	if err != nil {
 		return nil, err
 	}
-	generateKubeProxyKubeconfig, err := kubeproxystep.NewCreateKubeProxyKubeconfigStepBuilder(runtimeCtx, "GenerateKubeProxyKubeconfig").Build()
-	if err != nil {
-		return nil, err
-	}
 
 	fragment.AddNode(&plan.ExecutionNode{Name: "GenerateKubeletKubeconfig", Step: generateKubeletKubeconfig, Hosts: allHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "GenerateKubeProxyKubeconfig", Step: generateKubeProxyKubeconfig, Hosts: allHosts})
+	if v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec) {
+		generateKubeProxyKubeconfig, err := kubeproxystep.NewCreateKubeProxyKubeconfigStepBuilder(runtimeCtx, "GenerateKubeProxyKubeconfig").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "GenerateKubeProxyKubeconfig", Step: generateKubeProxyKubeconfig, Hosts: allHosts})
+	}
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
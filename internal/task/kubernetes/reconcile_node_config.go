@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/drift"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// ReconcileNodeConfigTask rewrites containerd and kubelet configs that drifted from the desired
+// configuration and restarts the affected services, leaving in-sync nodes untouched.
+// The kubelet config is only reconciled for kubexm deployments, as kubeadm owns it otherwise.
+type ReconcileNodeConfigTask struct {
+	task.Base
+}
+
+func NewReconcileNodeConfigTask() task.Task {
+	return &ReconcileNodeConfigTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "ReconcileNodeConfig",
+				Description: "Detect and reconcile containerd and kubelet config drift on every node",
+			},
+		},
+	}
+}
+
+func (t *ReconcileNodeConfigTask) Name() string        { return t.Meta.Name }
+func (t *ReconcileNodeConfigTask) Description() string { return t.Meta.Description }
+
+func (t *ReconcileNodeConfigTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	reconcileContainerd, reconcileKubelet := t.components(ctx)
+	return reconcileContainerd || reconcileKubelet, nil
+}
+
+func (t *ReconcileNodeConfigTask) components(ctx runtime.TaskContext) (reconcileContainerd, reconcileKubelet bool) {
+	k8s := ctx.GetClusterConfig().Spec.Kubernetes
+	if k8s == nil {
+		return false, false
+	}
+	reconcileContainerd = k8s.ContainerRuntime != nil && k8s.ContainerRuntime.Type == common.RuntimeTypeContainerd
+	reconcileKubelet = k8s.Type == string(common.KubernetesDeploymentTypeKubexm)
+	return reconcileContainerd, reconcileKubelet
+}
+
+func (t *ReconcileNodeConfigTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	reconcileContainerd, reconcileKubelet := t.components(ctx)
+
+	// Each host gets its own step instances, since the desired configs are rendered per host.
+	hosts := append(ctx.GetHostsByRole(common.RoleMaster), ctx.GetHostsByRole(common.RoleWorker)...)
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if seen[host.GetName()] {
+			continue
+		}
+		seen[host.GetName()] = true
+		hostCtx := runtime.ForHost(execCtx, host)
+
+		if reconcileContainerd {
+			name := fmt.Sprintf("ReconcileContainerdConfig-%s", host.GetName())
+			builder := drift.NewReconcileContainerdConfigStepBuilder(hostCtx, name)
+			if builder == nil {
+				return nil, fmt.Errorf("cannot render the containerd config for host %s", host.GetName())
+			}
+			reconcileStep, err := builder.Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: name, Step: reconcileStep, Hosts: []remotefw.Host{host}})
+		}
+		if reconcileKubelet {
+			name := fmt.Sprintf("ReconcileKubeletConfig-%s", host.GetName())
+			builder := drift.NewReconcileKubeletConfigStepBuilder(hostCtx, name)
+			if builder == nil {
+				return nil, fmt.Errorf("cannot render the kubelet config for host %s", host.GetName())
+			}
+			reconcileStep, err := builder.Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: name, Step: reconcileStep, Hosts: []remotefw.Host{host}})
+		}
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*ReconcileNodeConfigTask)(nil)
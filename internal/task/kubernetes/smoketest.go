@@ -0,0 +1,64 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/health"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// SmokeTestTask deploys and removes a throwaway workload from the first control plane node to prove
+// that the cluster schedules pods and serves them through a Service.
+type SmokeTestTask struct {
+	task.Base
+}
+
+func NewSmokeTestTask() task.Task {
+	return &SmokeTestTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "SmokeTest",
+				Description: "Deploy a smoke-test workload, verify it becomes Ready and served, then remove it",
+			},
+		},
+	}
+}
+
+func (t *SmokeTestTask) Name() string        { return t.Meta.Name }
+func (t *SmokeTestTask) Description() string { return t.Meta.Description }
+
+func (t *SmokeTestTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	hosts := ctx.GetHostsByRole(common.RoleControlPlane)
+	return len(hosts) > 0, nil
+}
+
+func (t *SmokeTestTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	hosts := ctx.GetHostsByRole(common.RoleControlPlane)
+	if len(hosts) == 0 {
+		return fragment, nil
+	}
+
+	host := hosts[0]
+	step, err := health.NewRunSmokeTestStepBuilder(runtime.ForHost(execCtx, host), "RunSmokeTest").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create smoke test step: %w", err)
+	}
+
+	nodeID, _ := fragment.AddNode(&plan.ExecutionNode{
+		Name:  "RunSmokeTest",
+		Step:  step,
+		Hosts: []remotefw.Host{host},
+	})
+	fragment.EntryNodes = []plan.NodeID{nodeID}
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*SmokeTestTask)(nil)
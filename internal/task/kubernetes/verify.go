@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step"
+	"github.com/mensylisir/kubexm/internal/step/kubernetes/verify"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// VerifyClusterTask runs the post-install checks from the first control plane node. Checks that do not
+// share state are independent nodes, so one failing check does not hide the result of the others.
+type VerifyClusterTask struct {
+	task.Base
+}
+
+func NewVerifyClusterTask() task.Task {
+	return &VerifyClusterTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "VerifyCluster",
+				Description: "Check nodes, DNS, Service connectivity, storage and API server latency",
+			},
+		},
+	}
+}
+
+func (t *VerifyClusterTask) Name() string        { return t.Meta.Name }
+func (t *VerifyClusterTask) Description() string { return t.Meta.Description }
+
+func (t *VerifyClusterTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	hosts := ctx.GetHostsByRole(common.RoleMaster)
+	return len(hosts) > 0, nil
+}
+
+func (t *VerifyClusterTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	masters := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masters) == 0 {
+		return fragment, nil
+	}
+	host := masters[0]
+	hostCtx := runtime.ForHost(execCtx, host)
+
+	nodesReady, err := verify.NewCheckNodesReadyStepBuilder(hostCtx, "CheckNodesReady").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nodes ready check: %w", err)
+	}
+	latency, err := verify.NewCheckAPIServerLatencyStepBuilder(hostCtx, "CheckAPIServerLatency").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API server latency check: %w", err)
+	}
+	pvc, err := verify.NewCheckPVCBindingStepBuilder(hostCtx, "CheckPVCBinding").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PVC binding check: %w", err)
+	}
+	deploy, err := verify.NewDeployVerifyWorkloadStepBuilder(hostCtx, "DeployVerifyWorkload").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification workload step: %w", err)
+	}
+	dns, err := verify.NewCheckDNSResolutionStepBuilder(hostCtx, "CheckDNSResolution").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DNS check: %w", err)
+	}
+	connectivity, err := verify.NewCheckServiceConnectivityStepBuilder(hostCtx, "CheckServiceConnectivity").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connectivity check: %w", err)
+	}
+	remove, err := verify.NewRemoveVerifyWorkloadStepBuilder(hostCtx, "RemoveVerifyWorkload").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification cleanup step: %w", err)
+	}
+
+	addNode := func(name string, s step.Step) plan.NodeID {
+		nodeID, _ := fragment.AddNode(&plan.ExecutionNode{
+			Name:  name,
+			Step:  s,
+			Hosts: []remotefw.Host{host},
+		})
+		return nodeID
+	}
+
+	addNode("CheckNodesReady", nodesReady)
+	addNode("CheckAPIServerLatency", latency)
+	addNode("CheckPVCBinding", pvc)
+	deployID := addNode("DeployVerifyWorkload", deploy)
+	dnsID := addNode("CheckDNSResolution", dns)
+	connectivityID := addNode("CheckServiceConnectivity", connectivity)
+	// The workload is removed whatever the checks that use it reported.
+	removeID, _ := fragment.AddNode(&plan.ExecutionNode{
+		Name:      "RemoveVerifyWorkload",
+		Step:      remove,
+		Hosts:     []remotefw.Host{host},
+		AlwaysRun: true,
+	})
+
+	fragment.AddDependency(deployID, dnsID)
+	fragment.AddDependency(deployID, connectivityID)
+	fragment.AddDependency(dnsID, removeID)
+	fragment.AddDependency(connectivityID, removeID)
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*VerifyClusterTask)(nil)
@@ -0,0 +1,101 @@
+package libvirt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/libvirt"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// ProvisionVMsTask creates every VM of spec.infrastructure.libvirt on its hypervisor and waits
+// until it can be reached over SSH. The hypervisors are the hosts of the runtime.
+type ProvisionVMsTask struct {
+	task.Base
+}
+
+func NewProvisionVMsTask() task.Task {
+	return &ProvisionVMsTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "ProvisionLibvirtVMs",
+				Description: "Create the VMs on the libvirt hypervisors and wait for SSH",
+			},
+		},
+	}
+}
+
+func (t *ProvisionVMsTask) Name() string        { return t.Meta.Name }
+func (t *ProvisionVMsTask) Description() string { return t.Meta.Description }
+
+func (t *ProvisionVMsTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	cfg := ctx.GetClusterConfig()
+	return cfg.Spec.Infrastructure != nil && cfg.Spec.Infrastructure.Libvirt != nil && len(cfg.Spec.Infrastructure.Libvirt.VMs) > 0, nil
+}
+
+func (t *ProvisionVMsTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	cfg := ctx.GetClusterConfig().Spec.Infrastructure.Libvirt
+
+	keys, err := authorizedKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	vms, err := cfg.ExpandVMs()
+	if err != nil {
+		return nil, err
+	}
+	hypervisors := make(map[string]remotefw.Host)
+	for _, host := range ctx.GetHostsByRole("") {
+		hypervisors[host.GetName()] = host
+	}
+
+	for _, vm := range vms {
+		hypervisor, ok := hypervisors[vm.Hypervisor]
+		if !ok {
+			return nil, fmt.Errorf("hypervisor '%s' of VM %s is not a known host", vm.Hypervisor, vm.Name)
+		}
+		hostCtx := runtime.ForHost(execCtx, hypervisor)
+
+		provisionName := fmt.Sprintf("ProvisionVM-%s", vm.Name)
+		provisionStep, err := libvirt.NewProvisionVMStepBuilder(hostCtx, provisionName, vm).WithAuthorizedKeys(keys).Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provision step for VM %s: %w", vm.Name, err)
+		}
+		provisionNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: provisionName, Step: provisionStep, Hosts: []remotefw.Host{hypervisor}})
+
+		waitName := fmt.Sprintf("WaitForVM-%s", vm.Name)
+		waitStep, err := libvirt.NewWaitForVMStepBuilder(hostCtx, waitName, vm.Name).Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create wait step for VM %s: %w", vm.Name, err)
+		}
+		waitNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: waitName, Step: waitStep, Hosts: []remotefw.Host{hypervisor}})
+		fragment.AddDependency(provisionNode, waitNode)
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+// authorizedKeys returns the keys to authorize on the VMs: the configured ones, or else the public
+// key next to the private key kubexm logs in with.
+func authorizedKeys(cfg *v1alpha1.LibvirtInfrastructure) ([]string, error) {
+	if len(cfg.SSHAuthorizedKeys) > 0 {
+		return cfg.SSHAuthorizedKeys, nil
+	}
+	pubKeyPath := cfg.PrivateKeyPath + ".pub"
+	data, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("no sshAuthorizedKeys are configured and the public key %s cannot be read: %w", pubKeyPath, err)
+	}
+	return []string{strings.TrimSpace(string(data))}, nil
+}
+
+var _ task.Task = (*ProvisionVMsTask)(nil)
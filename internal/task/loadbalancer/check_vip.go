@@ -0,0 +1,64 @@
+package loadbalancer
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	lbcommon "github.com/mensylisir/kubexm/internal/step/loadbalancer/common"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// CheckControlPlaneVIPTask 在 master 节点上检查 VIP 是否可达
+// 必须在 kubeadm init 使用 controlPlaneEndpoint 之前完成
+type CheckControlPlaneVIPTask struct {
+	task.Base
+}
+
+func NewCheckControlPlaneVIPTask() task.Task {
+	return &CheckControlPlaneVIPTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "CheckControlPlaneVIP",
+				Description: "Check that the control plane VIP is reachable from the master nodes",
+			},
+		},
+	}
+}
+
+func (t *CheckControlPlaneVIPTask) Name() string        { return t.Meta.Name }
+func (t *CheckControlPlaneVIPTask) Description() string { return t.Meta.Description }
+
+func (t *CheckControlPlaneVIPTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return (&InstallKeepalivedTask{}).IsRequired(ctx)
+}
+
+func (t *CheckControlPlaneVIPTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	masters := ctx.GetHostsByRole(common.RoleMaster)
+	if len(masters) == 0 {
+		return fragment, nil
+	}
+
+	cpEndpoint := ctx.GetClusterConfig().Spec.ControlPlaneEndpoint
+	if cpEndpoint.Address == "" {
+		return nil, fmt.Errorf("controlPlaneEndpoint address is required to check the VIP")
+	}
+	port := cpEndpoint.Port
+	if port == 0 {
+		port = common.DefaultAPIServerPort
+	}
+
+	checkVIP, err := lbcommon.NewCheckVIPStepBuilder(execCtx, "CheckControlPlaneVIP", cpEndpoint.Address, port).Build()
+	if err != nil {
+		return nil, err
+	}
+	fragment.AddNode(&plan.ExecutionNode{Name: "CheckControlPlaneVIP", Step: checkVIP, Hosts: masters})
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*CheckControlPlaneVIPTask)(nil)
@@ -14,11 +14,12 @@ import (
 
 // DeployHAProxyAsDaemonTask deploys HAProxy as a systemd service on load balancer nodes.
 // This task composes atomic steps:
-// 1. prepare_dirs - create necessary directories
-// 2. render_config - render HAProxy configuration
-// 3. copy_config - copy config to remote hosts
-// 4. enable_service - enable haproxy service
-// 5. restart_service - restart haproxy service
+// 1. install_package - install the haproxy package
+// 2. prepare_dirs - create necessary directories
+// 3. render_config - render HAProxy configuration
+// 4. copy_config - copy config to remote hosts, overwriting the packaged default
+// 5. enable_service - enable haproxy service
+// 6. restart_service - restart haproxy service
 type DeployHAProxyAsDaemonTask struct {
 	task.Base
 }
@@ -112,11 +113,11 @@ func (t *DeployHAProxyAsDaemonTask) Plan(ctx runtime.TaskContext) (*plan.Executi
 	fragment.AddNode(&plan.ExecutionNode{Name: "RestartHAProxyService", Step: restartService, Hosts: loadbalancerHosts})
 
 	// Dependencies
-	fragment.AddDependency("RenderHAProxyDaemonConfig", "PrepareHaproxyDaemonDirs")
-	fragment.AddDependency("CopyHAProxyDaemonConfig", "RenderHAProxyDaemonConfig")
-	fragment.AddDependency("InstallHAProxyPackage", "CopyHAProxyDaemonConfig")
-	fragment.AddDependency("EnableHAProxyService", "InstallHAProxyPackage")
-	fragment.AddDependency("RestartHAProxyService", "EnableHAProxyService")
+	fragment.AddDependency("InstallHAProxyPackage", "PrepareHaproxyDaemonDirs")
+	fragment.AddDependency("PrepareHaproxyDaemonDirs", "RenderHAProxyDaemonConfig")
+	fragment.AddDependency("RenderHAProxyDaemonConfig", "CopyHAProxyDaemonConfig")
+	fragment.AddDependency("CopyHAProxyDaemonConfig", "EnableHAProxyService")
+	fragment.AddDependency("EnableHAProxyService", "RestartHAProxyService")
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
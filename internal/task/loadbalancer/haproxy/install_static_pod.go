@@ -57,7 +57,7 @@ func (t *DeployHAProxyAsStaticPodTask) Plan(ctx runtime.TaskContext) (*plan.Exec
 	fragment := plan.NewExecutionFragment(t.Name())
 	runtimeCtx := ctx.ForTask(t.Name())
 
-	workerHosts := ctx.GetHostsByRole(pkgcommon.RoleWorker)
+	workerHosts := lbcommon.LocalProxyHosts(ctx.GetHostsByRole(pkgcommon.RoleWorker), ctx.GetHostsByRole(pkgcommon.RoleMaster))
 	if len(workerHosts) == 0 {
 		return fragment, nil
 	}
@@ -120,10 +120,10 @@ func (t *DeployHAProxyAsStaticPodTask) Plan(ctx runtime.TaskContext) (*plan.Exec
 	fragment.AddNode(&plan.ExecutionNode{Name: "CopyHAProxyPodManifest", Step: copyPod, Hosts: workerHosts})
 
 	// Set up dependencies: each step depends on the previous one completing
-	fragment.AddDependency("RenderHAProxyConfig", "PrepareHaproxyDirs")
-	fragment.AddDependency("CopyHAProxyConfig", "RenderHAProxyConfig")
-	fragment.AddDependency("RenderHAProxyPodManifest", "CopyHAProxyConfig")
-	fragment.AddDependency("CopyHAProxyPodManifest", "RenderHAProxyPodManifest")
+	fragment.AddDependency("PrepareHaproxyDirs", "RenderHAProxyConfig")
+	fragment.AddDependency("RenderHAProxyConfig", "CopyHAProxyConfig")
+	fragment.AddDependency("CopyHAProxyConfig", "RenderHAProxyPodManifest")
+	fragment.AddDependency("RenderHAProxyPodManifest", "CopyHAProxyPodManifest")
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
@@ -66,7 +66,7 @@ func (h *DeployHAProxyOnWorkersTask) Plan(ctx runtime.TaskContext) (*plan.Execut
 	fragment := plan.NewExecutionFragment(h.Name())
 	runtimeCtx := ctx.ForTask(h.Name())
 
-	workerHosts := ctx.GetHostsByRole(pkgcommon.RoleWorker)
+	workerHosts := lbcommon.LocalProxyHosts(ctx.GetHostsByRole(pkgcommon.RoleWorker), ctx.GetHostsByRole(pkgcommon.RoleMaster))
 	if len(workerHosts) == 0 {
 		return fragment, nil
 	}
@@ -136,12 +136,12 @@ func (h *DeployHAProxyOnWorkersTask) Plan(ctx runtime.TaskContext) (*plan.Execut
 	fragment.AddNode(&plan.ExecutionNode{Name: "RestartHAProxyWorkerService", Step: restartService, Hosts: workerHosts})
 
 	// Dependencies: config chain and service chain
-	fragment.AddDependency("RenderHAProxyWorkerConfig", "PrepareHaproxyWorkerDirs")
-	fragment.AddDependency("CopyHAProxyWorkerConfig", "RenderHAProxyWorkerConfig")
-	fragment.AddDependency("RenderHAProxyWorkerService", "CopyHAProxyWorkerConfig")
-	fragment.AddDependency("CopyHAProxyWorkerService", "RenderHAProxyWorkerService")
-	fragment.AddDependency("EnableHAProxyWorkerService", "CopyHAProxyWorkerService")
-	fragment.AddDependency("RestartHAProxyWorkerService", "EnableHAProxyWorkerService")
+	fragment.AddDependency("PrepareHaproxyWorkerDirs", "RenderHAProxyWorkerConfig")
+	fragment.AddDependency("RenderHAProxyWorkerConfig", "CopyHAProxyWorkerConfig")
+	fragment.AddDependency("CopyHAProxyWorkerConfig", "RenderHAProxyWorkerService")
+	fragment.AddDependency("RenderHAProxyWorkerService", "CopyHAProxyWorkerService")
+	fragment.AddDependency("CopyHAProxyWorkerService", "EnableHAProxyWorkerService")
+	fragment.AddDependency("EnableHAProxyWorkerService", "RestartHAProxyWorkerService")
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
@@ -39,10 +39,12 @@ func GetLoadBalancerTasks(ctx runtime.TaskContext) []task.Task {
 			// kubexm-kh: Keepalived + HAProxy
 			tasks = append(tasks, NewInstallKeepalivedTask())
 			tasks = append(tasks, haproxy.NewDeployHAProxyAsDaemonTask())
+			tasks = append(tasks, NewCheckControlPlaneVIPTask())
 		case string(common.ExternalLBTypeKubexmKN):
 			// kubexm-kn: Keepalived + Nginx
 			tasks = append(tasks, NewInstallKeepalivedTask())
 			tasks = append(tasks, nginx.NewDeployNginxAsDaemonTask())
+			tasks = append(tasks, NewCheckControlPlaneVIPTask())
 		case string(common.ExternalLBTypeKubeVIP):
 			// kube-vip: 独立部署
 			tasks = append(tasks, kubevip.NewDeployKubeVipTask())
@@ -14,11 +14,12 @@ import (
 
 // DeployNginxAsDaemonTask deploys NGINX as a systemd service on load balancer nodes.
 // This task composes atomic steps:
-// 1. prepare_dirs - create necessary directories
-// 2. render_config - render NGINX configuration
-// 3. copy_config - copy config to remote hosts
-// 4. enable_service - enable nginx service
-// 5. restart_service - restart nginx service
+// 1. install_package - install the nginx package
+// 2. prepare_dirs - create necessary directories
+// 3. render_config - render NGINX configuration
+// 4. copy_config - copy config to remote hosts, overwriting the packaged default
+// 5. enable_service - enable nginx service
+// 6. restart_service - restart nginx service
 type DeployNginxAsDaemonTask struct {
 	task.Base
 }
@@ -115,11 +116,11 @@ func (t *DeployNginxAsDaemonTask) Plan(ctx runtime.TaskContext) (*plan.Execution
 	fragment.AddNode(&plan.ExecutionNode{Name: "RestartNginxService", Step: restartService, Hosts: loadbalancerHosts})
 
 	// Dependencies
-	fragment.AddDependency("RenderNginxDaemonConfig", "PrepareNginxDaemonDirs")
-	fragment.AddDependency("CopyNginxDaemonConfig", "RenderNginxDaemonConfig")
-	fragment.AddDependency("InstallNginxPackage", "CopyNginxDaemonConfig")
-	fragment.AddDependency("EnableNginxService", "InstallNginxPackage")
-	fragment.AddDependency("RestartNginxService", "EnableNginxService")
+	fragment.AddDependency("InstallNginxPackage", "PrepareNginxDaemonDirs")
+	fragment.AddDependency("PrepareNginxDaemonDirs", "RenderNginxDaemonConfig")
+	fragment.AddDependency("RenderNginxDaemonConfig", "CopyNginxDaemonConfig")
+	fragment.AddDependency("CopyNginxDaemonConfig", "EnableNginxService")
+	fragment.AddDependency("EnableNginxService", "RestartNginxService")
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
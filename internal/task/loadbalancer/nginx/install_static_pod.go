@@ -57,7 +57,7 @@ func (t *DeployNginxAsStaticPodTask) Plan(ctx runtime.TaskContext) (*plan.Execut
 	fragment := plan.NewExecutionFragment(t.Name())
 	runtimeCtx := ctx.ForTask(t.Name())
 
-	workerHosts := ctx.GetHostsByRole(pkgcommon.RoleWorker)
+	workerHosts := lbcommon.LocalProxyHosts(ctx.GetHostsByRole(pkgcommon.RoleWorker), ctx.GetHostsByRole(pkgcommon.RoleMaster))
 	if len(workerHosts) == 0 {
 		return fragment, nil
 	}
@@ -113,10 +113,10 @@ func (t *DeployNginxAsStaticPodTask) Plan(ctx runtime.TaskContext) (*plan.Execut
 	fragment.AddNode(&plan.ExecutionNode{Name: "CopyNginxStaticPodManifest", Step: copyPod, Hosts: workerHosts})
 
 	// Set up dependencies
-	fragment.AddDependency("RenderNginxStaticPodConfig", "PrepareNginxStaticPodDirs")
-	fragment.AddDependency("CopyNginxStaticPodConfig", "RenderNginxStaticPodConfig")
-	fragment.AddDependency("RenderNginxStaticPodManifest", "CopyNginxStaticPodConfig")
-	fragment.AddDependency("CopyNginxStaticPodManifest", "RenderNginxStaticPodManifest")
+	fragment.AddDependency("PrepareNginxStaticPodDirs", "RenderNginxStaticPodConfig")
+	fragment.AddDependency("RenderNginxStaticPodConfig", "CopyNginxStaticPodConfig")
+	fragment.AddDependency("CopyNginxStaticPodConfig", "RenderNginxStaticPodManifest")
+	fragment.AddDependency("RenderNginxStaticPodManifest", "CopyNginxStaticPodManifest")
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
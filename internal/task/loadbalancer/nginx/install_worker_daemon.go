@@ -65,7 +65,7 @@ func (t *DeployNginxOnWorkersTask) Plan(ctx runtime.TaskContext) (*plan.Executio
 	fragment := plan.NewExecutionFragment(t.Name())
 	runtimeCtx := ctx.ForTask(t.Name())
 
-	workerHosts := ctx.GetHostsByRole(pkgcommon.RoleWorker)
+	workerHosts := lbcommon.LocalProxyHosts(ctx.GetHostsByRole(pkgcommon.RoleWorker), ctx.GetHostsByRole(pkgcommon.RoleMaster))
 	if len(workerHosts) == 0 {
 		return fragment, nil
 	}
@@ -122,11 +122,11 @@ func (t *DeployNginxOnWorkersTask) Plan(ctx runtime.TaskContext) (*plan.Executio
 	fragment.AddNode(&plan.ExecutionNode{Name: "RestartNginxWorkerService", Step: restartService, Hosts: workerHosts})
 
 	// Dependencies
-	fragment.AddDependency("RenderNginxWorkerConfig", "PrepareNginxWorkerDirs")
-	fragment.AddDependency("CopyNginxWorkerConfig", "RenderNginxWorkerConfig")
-	fragment.AddDependency("InstallNginxWorkerPackage", "CopyNginxWorkerConfig")
-	fragment.AddDependency("EnableNginxWorkerService", "InstallNginxWorkerPackage")
-	fragment.AddDependency("RestartNginxWorkerService", "EnableNginxWorkerService")
+	fragment.AddDependency("InstallNginxWorkerPackage", "PrepareNginxWorkerDirs")
+	fragment.AddDependency("PrepareNginxWorkerDirs", "RenderNginxWorkerConfig")
+	fragment.AddDependency("RenderNginxWorkerConfig", "CopyNginxWorkerConfig")
+	fragment.AddDependency("CopyNginxWorkerConfig", "EnableNginxWorkerService")
+	fragment.AddDependency("EnableNginxWorkerService", "RestartNginxWorkerService")
 
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
@@ -75,5 +75,32 @@ func (t *InstallNetworkPluginTask) Plan(ctx runtime.TaskContext) (*plan.Executio
 		return nil, fmt.Errorf("unsupported CNI plugin '%s': supported plugins are %v", plugin, supportedCNIs)
 	}
 
-	return subTask.Plan(ctx)
+	fragment, err := subTask.Plan(ctx)
+	if err != nil || plugin == string(common.CNITypeMultus) {
+		return fragment, err
+	}
+
+	// Multus delegates the default network to the primary plugin, so it is installed once that is running.
+	multusTask := multus.NewDeployMultusTask()
+	required, err := multusTask.IsRequired(ctx)
+	if err != nil || !required {
+		return fragment, err
+	}
+	multusFragment, err := multusTask.Plan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan multus: %w", err)
+	}
+
+	combined := plan.NewExecutionFragment(t.Name())
+	if err := combined.MergeFragment(fragment); err != nil {
+		return nil, err
+	}
+	if err := combined.MergeFragment(multusFragment); err != nil {
+		return nil, err
+	}
+	if err := plan.LinkFragments(combined, fragment.ExitNodes, multusFragment.EntryNodes); err != nil {
+		return nil, err
+	}
+	combined.CalculateEntryAndExitNodes()
+	return combined, nil
 }
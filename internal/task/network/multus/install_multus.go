@@ -2,13 +2,17 @@ package multus
 
 import (
 	"fmt"
+	"path/filepath"
+
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
+	networkcommon "github.com/mensylisir/kubexm/internal/step/network/common"
 	multusstep "github.com/mensylisir/kubexm/internal/step/network/multus"
 	"github.com/mensylisir/kubexm/internal/task"
+	"github.com/mensylisir/kubexm/internal/util/cni"
 )
 
 type DeployMultusTask struct {
@@ -35,13 +39,7 @@ func (t *DeployMultusTask) Description() string {
 }
 
 func (t *DeployMultusTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
-	cfg := ctx.GetClusterConfig()
-	if cfg.Spec.Network.Multus != nil &&
-		cfg.Spec.Network.Multus.Installation != nil &&
-		cfg.Spec.Network.Multus.Installation.Enabled != nil {
-		return *cfg.Spec.Network.Multus.Installation.Enabled, nil
-	}
-	return false, nil
+	return multusstep.IsEnabled(ctx.GetClusterConfig()), nil
 }
 
 func (t *DeployMultusTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
@@ -85,6 +83,31 @@ func (t *DeployMultusTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragmen
 	fragment.AddDependency("GenerateMultusValues", "DistributeMultusArtifacts")
 	fragment.AddDependency("DistributeMultusArtifacts", "InstallMultusChart")
 
+	// Plugins referenced by the attachments must be on every node before pods request them.
+	installPlugins, err := multusstep.NewInstallMultusPluginsStepBuilder(runtimeCtx, "InstallMultusPlugins").Build()
+	if err != nil {
+		return nil, err
+	}
+	if installPlugins != nil {
+		fragment.AddNode(&plan.ExecutionNode{Name: "InstallMultusPlugins", Step: installPlugins, Hosts: ctx.GetHostsByRole(common.RoleKubernetes)})
+		fragment.AddDependency("InstallMultusPlugins", "InstallMultusChart")
+	}
+
+	manifest, err := cni.NetworkAttachmentDefinitions(ctx.GetClusterConfig().Spec.Network.Multus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render network attachment definitions: %w", err)
+	}
+	if len(manifest) > 0 {
+		adminKubeconfig := filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName)
+		applyAttachments, err := networkcommon.NewApplyManifestStepBuilder(runtimeCtx, "ApplyNetworkAttachments", string(manifest), "").
+			WithKubeconfig(adminKubeconfig).Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "ApplyNetworkAttachments", Step: applyAttachments, Hosts: []remotefw.Host{executionHost}})
+		fragment.AddDependency("InstallMultusChart", "ApplyNetworkAttachments")
+	}
+
 	_ = controlNode
 	// Downloads are handled centrally in Preflight PrepareAssets/ExtractBundle.
 
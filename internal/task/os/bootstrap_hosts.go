@@ -0,0 +1,71 @@
+package os
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	osstep "github.com/mensylisir/kubexm/internal/step/os"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// BootstrapHostsTask creates the user of spec.bootstrap on every host and switches the runtime
+// over to it, then hardens sshd over the new login.
+type BootstrapHostsTask struct {
+	task.Base
+}
+
+func NewBootstrapHostsTask() task.Task {
+	return &BootstrapHostsTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "BootstrapHosts",
+				Description: "Create the kubexm login user and harden sshd on all nodes",
+			},
+		},
+	}
+}
+
+func (t *BootstrapHostsTask) Name() string        { return t.Meta.Name }
+func (t *BootstrapHostsTask) Description() string { return t.Meta.Description }
+
+func (t *BootstrapHostsTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return ctx.GetClusterConfig().Spec.Bootstrap != nil, nil
+}
+
+func (t *BootstrapHostsTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+	cfg := ctx.GetClusterConfig().Spec.Bootstrap
+	disableRootLogin := cfg.DisableRootLogin != nil && *cfg.DisableRootLogin
+	disablePasswordAuthentication := cfg.DisablePasswordAuthentication != nil && *cfg.DisablePasswordAuthentication
+
+	for _, host := range ctx.GetHostsByRole("") {
+		hosts := []remotefw.Host{host}
+
+		userName := fmt.Sprintf("BootstrapUser-%s", host.GetName())
+		userStep, err := osstep.NewBootstrapUserStepBuilder(execCtx, userName, cfg.User, cfg.PrivateKeyPath).Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bootstrap user step for host %s: %w", host.GetName(), err)
+		}
+		userNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: userName, Step: userStep, Hosts: hosts})
+
+		if !disableRootLogin && !disablePasswordAuthentication {
+			continue
+		}
+		hardenName := fmt.Sprintf("HardenSSH-%s", host.GetName())
+		hardenStep, err := osstep.NewHardenSSHStepBuilder(execCtx, hardenName, disableRootLogin, disablePasswordAuthentication).Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create harden sshd step for host %s: %w", host.GetName(), err)
+		}
+		hardenNode, _ := fragment.AddNode(&plan.ExecutionNode{Name: hardenName, Step: hardenStep, Hosts: hosts})
+		fragment.AddDependency(userNode, hardenNode)
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*BootstrapHostsTask)(nil)
@@ -0,0 +1,85 @@
+package os
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	commonstep "github.com/mensylisir/kubexm/internal/step/common"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// clusterPKIDirs are the certificate directories distributed to the nodes.
+var clusterPKIDirs = []string{
+	common.DefaultKubernetesPKIDir,
+	common.DefaultEtcdPKIDir,
+	common.DefaultEtcdPKISSLDir,
+}
+
+// CleanClusterPKITask removes the cluster certificates from all nodes and the PKI generated in the
+// local cluster work dir. Backups and checkpoints in the work dir are kept.
+type CleanClusterPKITask struct {
+	task.Base
+}
+
+func NewCleanClusterPKITask() task.Task {
+	return &CleanClusterPKITask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "CleanClusterPKI",
+				Description: "Remove cluster certificates from all nodes and generated PKI from the local work dir",
+			},
+		},
+	}
+}
+
+func (t *CleanClusterPKITask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *CleanClusterPKITask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *CleanClusterPKITask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return true, nil
+}
+
+func (t *CleanClusterPKITask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	allHosts := ctx.GetHostsByRole("")
+	if len(allHosts) > 0 {
+		for i, dir := range clusterPKIDirs {
+			name := fmt.Sprintf("RemoveNodePKI-%d", i)
+			removeStep, err := commonstep.NewDeleteFileStepBuilder(runtimeCtx, name, dir).WithSudo(true).WithRecursive(true).Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: name, Step: removeStep, Hosts: allHosts})
+		}
+	}
+
+	controlNode, err := ctx.GetControlNode()
+	if err != nil {
+		return nil, err
+	}
+	for i, dir := range []string{ctx.GetKubernetesCertsDir(), ctx.GetEtcdCertsDir()} {
+		name := fmt.Sprintf("RemoveGeneratedPKI-%d", i)
+		removeStep, err := commonstep.NewDeleteFileStepBuilder(runtimeCtx, name, dir).WithRecursive(true).Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: name, Step: removeStep, Hosts: []remotefw.Host{controlNode}})
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*CleanClusterPKITask)(nil)
@@ -20,7 +20,7 @@ func NewConfigureHostTask() task.Task {
 		Base: task.Base{
 			Meta: spec.TaskMeta{
 				Name:        "ConfigureHost",
-				Description: "Set hostname and update /etc/hosts on all nodes",
+				Description: "Set hostname, update /etc/hosts and configure the resolver on all nodes",
 			},
 		},
 	}
@@ -75,6 +75,17 @@ func (t *ConfigureHostTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragme
 		fragment.AddDependency(exitNodeID, updateEtcHostsNodeID)
 	}
 
+	// Step 3: Point the resolver at the nameservers and search domains of spec.extra.resolves
+	if extra := ctx.GetClusterConfig().Spec.Extra; extra != nil && extra.ResolvConf != nil &&
+		(len(extra.ResolvConf.Nameservers) > 0 || len(extra.ResolvConf.Searches) > 0 || len(extra.ResolvConf.Options) > 0) {
+		configureResolvConfStep, err := osstep.NewConfigureResolvConfStepBuilder(runtimeCtx, "ConfigureResolvConf").Build()
+		if err != nil {
+			return nil, err
+		}
+		configureResolvConfNodeID, _ := fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureResolvConf", Step: configureResolvConfStep, Hosts: allHosts})
+		fragment.AddDependency(updateEtcHostsNodeID, configureResolvConfNodeID)
+	}
+
 	fragment.CalculateEntryAndExitNodes()
 	return fragment, nil
 }
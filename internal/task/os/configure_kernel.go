@@ -32,7 +32,14 @@ func (t *ConfigureKernelTask) Description() string {
 }
 
 func (t *ConfigureKernelTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
-	return true, nil
+	return !skipConfigureOS(ctx), nil
+}
+
+// skipConfigureOS reports whether spec.system.skipConfigureOS leaves the OS settings of the hosts
+// to the user.
+func skipConfigureOS(ctx runtime.TaskContext) bool {
+	system := ctx.GetClusterConfig().Spec.System
+	return system != nil && system.SkipConfigureOS
 }
 
 func (t *ConfigureKernelTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
@@ -17,7 +17,7 @@ func NewDisableServicesTask() task.Task {
 		Base: task.Base{
 			Meta: spec.TaskMeta{
 				Name:        "DisableServices",
-				Description: "Disable swap, firewall, and SELinux on all nodes",
+				Description: "Disable swap and firewall and configure SELinux on all nodes",
 			},
 		},
 	}
@@ -32,7 +32,7 @@ func (t *DisableServicesTask) Description() string {
 }
 
 func (t *DisableServicesTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
-	return true, nil
+	return !skipConfigureOS(ctx), nil
 }
 
 func (t *DisableServicesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
@@ -48,7 +48,7 @@ func (t *DisableServicesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFrag
 	if err != nil {
 		return nil, err
 	}
-	disableSelinuxStep, err := osstep.NewDisableSelinuxStepBuilder(runtimeCtx, "DisableSelinux").Build()
+	configureSELinuxStep, err := osstep.NewConfigureSELinuxStepBuilder(runtimeCtx, "ConfigureSELinux").Build()
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +59,7 @@ func (t *DisableServicesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFrag
 
 	// These steps can run in parallel
 	fragment.AddNode(&plan.ExecutionNode{Name: "DisableSwap", Step: disableSwapStep, Hosts: allHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "DisableSelinux", Step: disableSelinuxStep, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureSELinux", Step: configureSELinuxStep, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "DisableFirewall", Step: disableFirewallStep, Hosts: allHosts})
 
 	fragment.CalculateEntryAndExitNodes()
@@ -5,6 +5,7 @@ import (
 	"github.com/mensylisir/kubexm/internal/runtime"
 	"github.com/mensylisir/kubexm/internal/spec"
 	packagesstep "github.com/mensylisir/kubexm/internal/step/packages"
+	repositorystep "github.com/mensylisir/kubexm/internal/step/repository"
 	"github.com/mensylisir/kubexm/internal/task"
 )
 
@@ -45,6 +46,7 @@ func (t *InstallPrerequisitesTask) Plan(ctx runtime.TaskContext) (*plan.Executio
 		return fragment, nil
 	}
 
+	var installNode plan.NodeID
 	if ctx.IsOfflineMode() {
 		ctx.GetLogger().Info("Offline mode detected. Planning steps for offline package installation.")
 
@@ -67,6 +69,7 @@ func (t *InstallPrerequisitesTask) Plan(ctx runtime.TaskContext) (*plan.Executio
 
 		fragment.AddDependency("DistributeOfflinePackages", "ExtractOfflinePackages")
 		fragment.AddDependency("ExtractOfflinePackages", "InstallOfflinePackages")
+		installNode = "InstallOfflinePackages"
 
 	} else {
 		ctx.GetLogger().Info("Online mode detected. Planning step for online package installation.")
@@ -77,6 +80,24 @@ func (t *InstallPrerequisitesTask) Plan(ctx runtime.TaskContext) (*plan.Executio
 		}
 
 		fragment.AddNode(&plan.ExecutionNode{Name: "InstallOnlinePackages", Step: installOnline, Hosts: allHosts})
+		installNode = "InstallOnlinePackages"
+	}
+
+	// Local repositories of spec.system.packageRepository are only in place while the packages
+	// are installed.
+	if system := ctx.GetClusterConfig().Spec.System; system != nil && system.PackageRepository != nil {
+		configureRepo, err := repositorystep.NewConfigurePackageRepositoryStepBuilder(runtimeCtx, "ConfigurePackageRepository").Build()
+		if err != nil {
+			return nil, err
+		}
+		restoreRepo, err := repositorystep.NewRestorePackageRepositoryStepBuilder(runtimeCtx, "RestorePackageRepository").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "ConfigurePackageRepository", Step: configureRepo, Hosts: allHosts})
+		fragment.AddNode(&plan.ExecutionNode{Name: "RestorePackageRepository", Step: restoreRepo, Hosts: allHosts})
+		fragment.AddDependency("ConfigurePackageRepository", installNode)
+		fragment.AddDependency(installNode, "RestorePackageRepository")
 	}
 
 	fragment.CalculateEntryAndExitNodes()
@@ -17,7 +17,7 @@ func NewPrepareOSNodesTask() task.Task {
 		Base: task.Base{
 			Meta: spec.TaskMeta{
 				Name:        "PrepareOSNodes",
-				Description: "Prepare all nodes with necessary OS settings (disable swap, selinux, configure sysctl, etc.)",
+				Description: "Prepare all nodes with necessary OS settings (disable swap, configure selinux and sysctl, etc.)",
 			},
 		},
 	}
@@ -32,7 +32,7 @@ func (t *PrepareOSNodesTask) Description() string {
 }
 
 func (t *PrepareOSNodesTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
-	return true, nil
+	return !skipConfigureOS(ctx), nil
 }
 
 func (t *PrepareOSNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
@@ -49,7 +49,7 @@ func (t *PrepareOSNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragm
 	if err != nil {
 		return nil, err
 	}
-	disableSelinux, err := osstep.NewDisableSelinuxStepBuilder(runtimeCtx, "DisableSelinux").Build()
+	configureSELinux, err := osstep.NewConfigureSELinuxStepBuilder(runtimeCtx, "ConfigureSELinux").Build()
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +75,7 @@ func (t *PrepareOSNodesTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragm
 	}
 
 	fragment.AddNode(&plan.ExecutionNode{Name: "DisableSwap", Step: disableSwap, Hosts: allHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "DisableSelinux", Step: disableSelinux, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureSELinux", Step: configureSELinux, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "DisableFirewall", Step: disableFirewall, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "UpdateEtcHosts", Step: updateEtcHosts, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "LoadKernelModules", Step: loadKernelModules, Hosts: allHosts})
@@ -0,0 +1,75 @@
+package os
+
+import (
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	"github.com/mensylisir/kubexm/internal/step/chrony"
+	osstep "github.com/mensylisir/kubexm/internal/step/os"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// SyncTimeTask sets the timezone of the hosts and syncs their clocks with spec.system.ntpServers
+// through chrony or systemd-timesyncd, waiting until every host is synchronized.
+type SyncTimeTask struct {
+	task.Base
+}
+
+func NewSyncTimeTask() task.Task {
+	return &SyncTimeTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "SyncTime",
+				Description: "Sync the clocks of all nodes with the NTP servers of the cluster",
+			},
+		},
+	}
+}
+
+func (t *SyncTimeTask) Name() string {
+	return t.Meta.Name
+}
+
+func (t *SyncTimeTask) Description() string {
+	return t.Meta.Description
+}
+
+func (t *SyncTimeTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	system := ctx.GetClusterConfig().Spec.System
+	return system != nil && len(system.NTPServers) > 0 && !skipConfigureOS(ctx), nil
+}
+
+func (t *SyncTimeTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	runtimeCtx := ctx.ForTask(t.Name())
+
+	allHosts := ctx.GetHostsByRole("")
+	if len(allHosts) == 0 {
+		return fragment, nil
+	}
+
+	configureTimezone, err := osstep.NewConfigureTimezoneStepBuilder(runtimeCtx, "ConfigureTimezone").Build()
+	if err != nil {
+		return nil, err
+	}
+	configureTimeSync, err := chrony.NewConfigureTimeSyncStepBuilder(runtimeCtx, "ConfigureTimeSync").Build()
+	if err != nil {
+		return nil, err
+	}
+	verifyTimeSync, err := chrony.NewVerifyTimeSyncStepBuilder(runtimeCtx, "VerifyTimeSync").Build()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureTimezone", Step: configureTimezone, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "ConfigureTimeSync", Step: configureTimeSync, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "VerifyTimeSync", Step: verifyTimeSync, Hosts: allHosts})
+
+	fragment.AddDependency("ConfigureTimezone", "ConfigureTimeSync")
+	fragment.AddDependency("ConfigureTimeSync", "VerifyTimeSync")
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*SyncTimeTask)(nil)
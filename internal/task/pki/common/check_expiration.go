@@ -0,0 +1,99 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	pkicommon "github.com/mensylisir/kubexm/internal/step/pki/common"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// CollectCertExpirationTask reads the control plane certificates and kubeconfig client
+// certificates on every master and the etcd certificates on every etcd node. Nodes are inspected in parallel and nothing is changed.
+type CollectCertExpirationTask struct {
+	task.Base
+}
+
+func NewCollectCertExpirationTask() task.Task {
+	return &CollectCertExpirationTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "CollectCertExpiration",
+				Description: "Collect the expiration of control plane and etcd certificates on every node",
+			},
+		},
+	}
+}
+
+func (t *CollectCertExpirationTask) Name() string        { return t.Meta.Name }
+func (t *CollectCertExpirationTask) Description() string { return t.Meta.Description }
+
+func (t *CollectCertExpirationTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	return len(certDirsByHost(ctx)) > 0, nil
+}
+
+func (t *CollectCertExpirationTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+
+	for _, hd := range certDirsByHost(ctx) {
+		name := fmt.Sprintf("CollectCertExpiration-%s", hd.host.GetName())
+		collectStep, err := pkicommon.NewCollectCertExpirationStepBuilder(execCtx, name, hd.dirs).
+			WithKubeconfigs(hd.kubeconfigs).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: name, Step: collectStep, Hosts: []remotefw.Host{hd.host}})
+	}
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+type hostCertDirs struct {
+	host        remotefw.Host
+	dirs        []string
+	kubeconfigs []string
+}
+
+// masterKubeconfigs are the kubeconfigs on a master whose embedded client certificates expire
+// with the control plane certificates.
+var masterKubeconfigs = []string{
+	filepath.Join(common.KubernetesConfigDir, common.AdminKubeconfigFileName),
+	filepath.Join(common.KubernetesConfigDir, common.ControllerManagerKubeconfigFileName),
+	filepath.Join(common.KubernetesConfigDir, common.SchedulerKubeconfigFileName),
+	filepath.Join(common.KubernetesConfigDir, common.KubeletKubeconfigFileName),
+}
+
+// certDirsByHost returns the certificate directories and kubeconfigs to inspect on each node, in
+// host order.
+func certDirsByHost(ctx runtime.TaskContext) []hostCertDirs {
+	var result []hostCertDirs
+	index := make(map[string]int)
+	add := func(hosts []remotefw.Host, dir string, kubeconfigs ...string) {
+		for _, h := range hosts {
+			i, ok := index[h.GetName()]
+			if !ok {
+				i = len(result)
+				index[h.GetName()] = i
+				result = append(result, hostCertDirs{host: h})
+			}
+			result[i].dirs = append(result[i].dirs, dir)
+			result[i].kubeconfigs = append(result[i].kubeconfigs, kubeconfigs...)
+		}
+	}
+
+	add(ctx.GetHostsByRole(common.RoleMaster), common.KubernetesPKIDir, masterKubeconfigs...)
+	if etcd := ctx.GetClusterConfig().Spec.Etcd; etcd != nil && etcd.Type == string(common.EtcdDeploymentTypeKubexm) {
+		add(ctx.GetHostsByRole(common.RoleEtcd), common.DefaultEtcdPKIDir)
+	}
+	return result
+}
+
+var _ task.Task = (*CollectCertExpirationTask)(nil)
@@ -1,124 +0,0 @@
-package preflight
-
-import (
-	"fmt"
-	"github.com/mensylisir/kubexm/internal/step"
-	"github.com/mensylisir/kubexm/internal/step/os"
-
-	"github.com/mensylisir/kubexm/internal/remotefw"
-	"github.com/mensylisir/kubexm/internal/plan"
-	"github.com/mensylisir/kubexm/internal/runtime"
-	"github.com/mensylisir/kubexm/internal/spec"
-	"github.com/mensylisir/kubexm/internal/step/chrony"
-	"github.com/mensylisir/kubexm/internal/task"
-)
-
-type ConfigureTimeTask struct {
-	task.Base
-}
-
-func NewConfigureTimeTask() task.Task {
-	return &ConfigureTimeTask{
-		Base: task.Base{
-			Meta: spec.TaskMeta{
-				Name:        "ConfigureNTP",
-				Description: "Configures chrony on all nodes based on the NTPServers spec and verifies time synchronization",
-			},
-		},
-	}
-}
-
-func (t *ConfigureTimeTask) Name() string {
-	return t.Meta.Name
-}
-
-func (t *ConfigureTimeTask) Description() string {
-	return t.Meta.Description
-}
-
-func (t *ConfigureTimeTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
-	clusterSpec := ctx.GetClusterConfig().Spec
-	if clusterSpec.System != nil && len(clusterSpec.System.NTPServers) > 0 {
-		return true, nil
-	}
-	ctx.GetLogger().Info("Skipping NTP configuration: No NTPServers defined in the cluster configuration.")
-	return false, nil
-}
-
-func (t *ConfigureTimeTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
-	fragment := plan.NewExecutionFragment(t.Name())
-
-	runtimeCtx := ctx.ForTask(t.Name())
-
-	allHosts := ctx.GetHostsByRole("")
-	ntpServers := ctx.GetClusterConfig().Spec.System.NTPServers
-
-	ntpServerHostNames := make(map[string]bool)
-	for _, server := range ntpServers {
-		ntpServerHostNames[server] = true
-	}
-
-	var lastNodeExitPoint plan.NodeID = ""
-
-	for _, host := range allHosts {
-		hostName := host.GetName()
-		hostList := []remotefw.Host{host}
-
-		configureTimezoneStep, err := os.NewConfigureTimezoneStepBuilder(runtimeCtx, fmt.Sprintf("ConfigureTimezoneFor%s", hostName)).Build()
-		if err != nil {
-			return nil, err
-		}
-
-		var configureStep step.Step
-		if ntpServerHostNames[hostName] {
-			configureStep, err = chrony.NewConfigureChronyAsServerStepBuilder(runtimeCtx, fmt.Sprintf("ConfigureChronyServerOn%s", hostName)).Build()
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			configureStep, err = chrony.NewConfigureChronyAsClientStepBuilder(runtimeCtx, fmt.Sprintf("ConfigureChronyClientOn%s", hostName)).Build()
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		enableStep, err := chrony.NewEnableChronyStepBuilder(runtimeCtx, fmt.Sprintf("EnableChronyOn%s", hostName)).Build()
-		if err != nil {
-			return nil, err
-		}
-		restartStep, err := chrony.NewRestartChronyStepBuilder(runtimeCtx, fmt.Sprintf("RestartChronyOn%s", hostName)).Build()
-		if err != nil {
-			return nil, err
-		}
-		verifyStep, err := chrony.NewVerifyTimeSyncStepBuilder(runtimeCtx, fmt.Sprintf("VerifyTimeSyncOn%s", hostName)).Build()
-		if err != nil {
-			return nil, err
-		}
-
-		configureTimezoneNode := &plan.ExecutionNode{Name: fmt.Sprintf("ConfigureTimezoneFor%s", hostName), Step: configureTimezoneStep, Hosts: hostList}
-		configureNode := &plan.ExecutionNode{Name: fmt.Sprintf("ConfigureChronyFor%s", hostName), Step: configureStep, Hosts: hostList}
-		enableNode := &plan.ExecutionNode{Name: fmt.Sprintf("EnableChronyFor%s", hostName), Step: enableStep, Hosts: hostList}
-		restartNode := &plan.ExecutionNode{Name: fmt.Sprintf("RestartChronyFor%s", hostName), Step: restartStep, Hosts: hostList}
-		verifyNode := &plan.ExecutionNode{Name: fmt.Sprintf("VerifyTimeSyncFor%s", hostName), Step: verifyStep, Hosts: hostList}
-
-		configureTimezoneID, _ := fragment.AddNode(configureTimezoneNode)
-		configureID, _ := fragment.AddNode(configureNode)
-		enableID, _ := fragment.AddNode(enableNode)
-		restartID, _ := fragment.AddNode(restartNode)
-		verifyID, _ := fragment.AddNode(verifyNode)
-
-		fragment.AddDependency(configureTimezoneID, configureID)
-		fragment.AddDependency(configureID, enableID)
-		fragment.AddDependency(enableID, restartID)
-		fragment.AddDependency(restartID, verifyID)
-		if lastNodeExitPoint != "" {
-			fragment.AddDependency(lastNodeExitPoint, configureID)
-		}
-		lastNodeExitPoint = verifyID
-	}
-
-	fragment.CalculateEntryAndExitNodes()
-	return fragment, nil
-}
-
-var _ task.Task = (*ConfigureTimeTask)(nil)
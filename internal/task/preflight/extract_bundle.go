@@ -56,6 +56,9 @@ func (t *ExtractBundleTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragme
 	}
 
 	finalBundlePath := t.BundlePath
+	if finalBundlePath == "" {
+		finalBundlePath = ctx.GetArtifactsBundlePath()
+	}
 	if finalBundlePath == "" {
 		finalBundlePath = filepath.Join(".", "kubexm-bundle.tar.gz")
 	}
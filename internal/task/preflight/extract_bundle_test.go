@@ -0,0 +1,66 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/runtime/runtimetest"
+	offlinestep "github.com/mensylisir/kubexm/internal/step/offline"
+)
+
+func TestExtractBundleTask_ArtifactsBundleEnablesOfflineMode(t *testing.T) {
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "offline"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts:      []v1alpha1.HostSpec{{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster}}},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.29.3"},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+	bundlePath := "/data/kubexm-bundle.tar.gz"
+
+	ctx := runtimetest.NewRuntimeFromBuilder(t, runtime.NewBuilderFromConfig(cfg).WithArtifactsBundle(bundlePath))
+	if cfg.Spec.Global != nil && cfg.Spec.Global.OfflineMode {
+		t.Fatalf("expected the test config to leave offlineMode unset")
+	}
+	if !ctx.IsOfflineMode() {
+		t.Fatalf("expected an artifacts bundle to enable offline mode")
+	}
+	if got := ctx.GetArtifactsBundlePath(); got != bundlePath {
+		t.Fatalf("expected bundle path %s, got %s", bundlePath, got)
+	}
+
+	extractTask := NewExtractBundleTask("")
+	required, err := extractTask.IsRequired(ctx)
+	if err != nil || !required {
+		t.Fatalf("expected the task to be required, got %v (err: %v)", required, err)
+	}
+	fragment, err := extractTask.Plan(ctx)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	node := fragment.Nodes["ExtractBundleOnControlNode"]
+	if node == nil {
+		t.Fatalf("expected an ExtractBundleOnControlNode node, got %v", fragment.Nodes)
+	}
+	extractStep, ok := node.Step.(*offlinestep.ExtractBundleStep)
+	if !ok || extractStep.BundlePath != bundlePath {
+		t.Fatalf("expected the step to extract %s, got %+v", bundlePath, node.Step)
+	}
+}
+
+func TestExtractBundleTask_NotRequiredWithoutOfflineMode(t *testing.T) {
+	cfg := &v1alpha1.Cluster{}
+	cfg.Name = "online"
+	cfg.Spec = &v1alpha1.ClusterSpec{
+		Hosts:      []v1alpha1.HostSpec{{Name: "master1", Address: "10.0.0.1", Roles: []string{common.RoleMaster}}},
+		Kubernetes: &v1alpha1.Kubernetes{Version: "v1.29.3"},
+	}
+	v1alpha1.SetDefaults_Cluster(cfg)
+
+	required, err := NewExtractBundleTask("").IsRequired(runtimetest.NewRuntime(t, cfg))
+	if err != nil || required {
+		t.Fatalf("expected the task to be skipped online, got %v (err: %v)", required, err)
+	}
+}
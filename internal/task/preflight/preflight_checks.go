@@ -1,6 +1,7 @@
 package preflight
 
 import (
+	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
 	"github.com/mensylisir/kubexm/internal/runtime"
@@ -66,11 +67,7 @@ func (t *PreflightChecksTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFrag
 	}
 
 	// Create builders for all the preflight steps
-	checkCPU, err := preflightstep.NewCheckCPUStepBuilder(runtimeCtx, "CheckMinCPUCores").Build()
-	if err != nil {
-		return nil, err
-	}
-	checkMemory, err := preflightstep.NewCheckMemoryStepBuilder(runtimeCtx, "CheckMinMemory").Build()
+	checkResources, err := preflightstep.NewCheckNodeResourcesStepBuilder(runtimeCtx, "CheckNodeResources").Build()
 	if err != nil {
 		return nil, err
 	}
@@ -98,18 +95,56 @@ func (t *PreflightChecksTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFrag
 	if err != nil {
 		return nil, err
 	}
+	detectGPU, err := preflightstep.NewDetectGPUStepBuilder(runtimeCtx, "DetectGPU").Build()
+	if err != nil {
+		return nil, err
+	}
+	checkRuntimeConflicts, err := preflightstep.NewCheckContainerRuntimeConflictsStepBuilder(runtimeCtx, "CheckContainerRuntimeConflicts").Build()
+	if err != nil {
+		return nil, err
+	}
+	checkKernelVersion, err := preflightstep.NewCheckKernelVersionStepBuilder(runtimeCtx, "CheckKernelVersion").Build()
+	if err != nil {
+		return nil, err
+	}
 
 	// Add nodes to the execution fragment for each check.
 	// Most checks run on all hosts. Linting and version compatibility only need control node.
-	fragment.AddNode(&plan.ExecutionNode{Name: "CheckMinCPUCores", Step: checkCPU, Hosts: allHosts})
-	fragment.AddNode(&plan.ExecutionNode{Name: "CheckMinMemory", Step: checkMemory, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "CheckNodeResources", Step: checkResources, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "CheckHostConnectivity", Step: checkConnectivity, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "CheckDNSConfig", Step: checkDNS, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "CheckRequiredCommands", Step: checkCommands, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "CheckTimeSync", Step: checkTimeSync, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "DetectGPU", Step: detectGPU, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "CheckContainerRuntimeConflicts", Step: checkRuntimeConflicts, Hosts: allHosts})
+	fragment.AddNode(&plan.ExecutionNode{Name: "CheckKernelVersion", Step: checkKernelVersion, Hosts: allHosts})
 	fragment.AddNode(&plan.ExecutionNode{Name: "LintClusterSpec", Step: lintSpec, Hosts: []remotefw.Host{controlNode}})
 	fragment.AddNode(&plan.ExecutionNode{Name: "CheckVersionCompatibility", Step: checkVersionCompat, Hosts: []remotefw.Host{controlNode}})
 
+	// Clock offsets are only gated when kubexm is asked to keep the clocks in sync.
+	if system := ctx.GetClusterConfig().Spec.System; system != nil && len(system.NTPServers) > 0 {
+		checkClockOffset, err := preflightstep.NewCheckClockOffsetStepBuilder(runtimeCtx, "CheckClockOffset").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "CheckClockOffset", Step: checkClockOffset, Hosts: allHosts})
+	}
+
+	if etcdSpec := ctx.GetClusterConfig().Spec.Etcd; etcdSpec != nil && etcdSpec.Type == string(common.EtcdDeploymentTypeExternal) {
+		checkExternalEtcdCerts, err := preflightstep.NewCheckExternalEtcdCertsStepBuilder(runtimeCtx, "CheckExternalEtcdCerts").Build()
+		if err != nil {
+			return nil, err
+		}
+		fragment.AddNode(&plan.ExecutionNode{Name: "CheckExternalEtcdCerts", Step: checkExternalEtcdCerts, Hosts: []remotefw.Host{controlNode}})
+		if masterHosts := ctx.GetHostsByRole(common.RoleMaster); len(masterHosts) > 0 {
+			checkExternalEtcd, err := preflightstep.NewCheckExternalEtcdConnectivityStepBuilder(runtimeCtx, "CheckExternalEtcdConnectivity").Build()
+			if err != nil {
+				return nil, err
+			}
+			fragment.AddNode(&plan.ExecutionNode{Name: "CheckExternalEtcdConnectivity", Step: checkExternalEtcd, Hosts: masterHosts})
+		}
+	}
+
 	// Since all these checks can run in parallel, we don't add any dependencies between them.
 	// The CalculateEntryAndExitNodes function will correctly identify all of them as entry points.
 	fragment.CalculateEntryAndExitNodes()
@@ -3,6 +3,7 @@ package reconfigure
 import (
 	"fmt"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	k8scommon "github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/plan"
@@ -316,6 +317,9 @@ func (t *ReconfigureProxyTask) Name() string        { return t.Meta.Name }
 func (t *ReconfigureProxyTask) Description() string { return t.Meta.Description }
 
 func (t *ReconfigureProxyTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	if !v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec) {
+		return false, nil
+	}
 	cpHosts := ctx.GetHostsByRole(k8scommon.RoleControlPlane)
 	workerHosts := ctx.GetHostsByRole(k8scommon.RoleWorker)
 	return len(cpHosts)+len(workerHosts) > 0, nil
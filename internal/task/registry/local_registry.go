@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/remotefw"
+	"github.com/mensylisir/kubexm/internal/runtime"
+	"github.com/mensylisir/kubexm/internal/spec"
+	registry "github.com/mensylisir/kubexm/internal/step/registry"
+	"github.com/mensylisir/kubexm/internal/task"
+)
+
+// DeployLocalRegistryTask runs the registry container on the first registry-role host when the
+// cluster is configured with registry.type local.
+type DeployLocalRegistryTask struct {
+	task.Base
+}
+
+func NewDeployLocalRegistryTask() task.Task {
+	return &DeployLocalRegistryTask{
+		Base: task.Base{
+			Meta: spec.TaskMeta{
+				Name:        "DeployLocalRegistry",
+				Description: "Run the local image registry on the registry host",
+			},
+		},
+	}
+}
+
+func (t *DeployLocalRegistryTask) Name() string        { return t.Meta.Name }
+func (t *DeployLocalRegistryTask) Description() string { return t.Meta.Description }
+
+func (t *DeployLocalRegistryTask) IsRequired(ctx runtime.TaskContext) (bool, error) {
+	cfg := ctx.GetClusterConfig()
+	return cfg != nil && cfg.Spec.Registry != nil && cfg.Spec.Registry.Type == common.RegistryDeployTypeLocal, nil
+}
+
+func (t *DeployLocalRegistryTask) Plan(ctx runtime.TaskContext) (*plan.ExecutionFragment, error) {
+	fragment := plan.NewExecutionFragment(t.Name())
+	execCtx := ctx.ForTask(t.Name())
+
+	// Only one registry is deployed; the mirror config of every node points at the first host of
+	// the registry role group.
+	var registryHost remotefw.Host
+	if cfg := ctx.GetClusterConfig(); cfg.Spec.RoleGroups != nil && len(cfg.Spec.RoleGroups.Registry) > 0 {
+		for _, host := range ctx.GetHostsByRole(common.RoleRegistry) {
+			if host.GetName() == cfg.Spec.RoleGroups.Registry[0] {
+				registryHost = host
+			}
+		}
+	}
+	if registryHost == nil {
+		return nil, fmt.Errorf("registry.type is local but no registry-role host was found")
+	}
+
+	hostCtx := runtime.ForHost(execCtx, registryHost)
+	deployStep, err := registry.NewDeployLocalRegistryStepBuilder(hostCtx, "DeployLocalRegistry").Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local registry deploy step: %w", err)
+	}
+	fragment.AddNode(&plan.ExecutionNode{
+		Name:  "DeployLocalRegistry",
+		Step:  deployStep,
+		Hosts: []remotefw.Host{registryHost},
+	})
+
+	fragment.CalculateEntryAndExitNodes()
+	return fragment, nil
+}
+
+var _ task.Task = (*DeployLocalRegistryTask)(nil)
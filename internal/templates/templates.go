@@ -39,6 +39,7 @@ import (
 //go:embed loadbalancer/kube-vip/*.tmpl
 //go:embed os/*.tmpl
 //go:embed repository/*.tmpl
+//go:embed storage/local-path-provisioner/*.tmpl
 //go:embed storage/longhorn/*.tmpl
 //go:embed storage/nfs/*.tmpl
 //go:embed storage/openebs-local/*.tmpl
@@ -0,0 +1,428 @@
+// Package ui renders the progress of pipeline executions on the terminal.
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/mensylisir/kubexm/internal/plan"
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+const (
+	refreshInterval = 100 * time.Millisecond
+	defaultWidth    = 120
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Progress displays the events of a graph execution. In live mode it keeps a display of the DAG
+// nodes grouped by phase at the bottom of the terminal, with a spinner for every running node and
+// host, and redraws it in place; anything written to the Progress itself, such as console logs, is
+// printed above it. Otherwise it prints one line per started and finished node, which suits logs
+// and CI output.
+//
+// Progress is driven by Handle, which can be passed to runtime.Builder.WithEventHandler.
+type Progress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	live  bool
+	width int
+	now   func() time.Time
+
+	graph    string
+	start    time.Time
+	status   string
+	phases   []*phaseState
+	byPhase  map[string]*phaseState
+	nodes    map[string]*nodeState
+	total    int
+	finished int
+
+	// drawn is the number of lines of the live display currently on screen.
+	drawn   int
+	frame   int
+	pending []byte
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+type phaseState struct {
+	name  string
+	nodes []*nodeState
+}
+
+type nodeState struct {
+	id       string
+	name     string
+	hosts    []string
+	host     map[string]string
+	status   string
+	message  string
+	started  time.Time
+	finished time.Time
+}
+
+// NewProgress returns a Progress writing to out. live selects the redrawn display, which needs
+// out to be a terminal; see IsTerminal.
+func NewProgress(out io.Writer, live bool) *Progress {
+	p := &Progress{
+		out:     out,
+		live:    live,
+		width:   defaultWidth,
+		now:     time.Now,
+		byPhase: make(map[string]*phaseState),
+		nodes:   make(map[string]*nodeState),
+	}
+	if f, ok := out.(*os.File); ok && live {
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 0 {
+			p.width = w
+		}
+	}
+	return p
+}
+
+// IsTerminal reports whether w is a terminal that can show the live display.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd())) && os.Getenv("TERM") != "dumb"
+}
+
+// Start begins redrawing the live display so spinners and elapsed times move between events.
+// It does nothing in plain mode.
+func (p *Progress) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.live || p.stop != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.stopped = make(chan struct{})
+	go func(stop, stopped chan struct{}) {
+		defer close(stopped)
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.frame++
+				p.redraw()
+				p.mu.Unlock()
+			}
+		}
+	}(p.stop, p.stopped)
+}
+
+// Stop ends the redrawing started by Start and leaves the final state of the display on screen.
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	stop, stopped := p.stop, p.stopped
+	p.stop, p.stopped = nil, nil
+	p.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) > 0 {
+		p.writeAbove(append(p.pending, '\n'))
+		p.pending = nil
+	}
+	p.redraw()
+	p.drawn = 0
+}
+
+// Write prints p above the live display, one complete line at a time. In plain mode it is
+// passed through unchanged.
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.live {
+		return p.out.Write(b)
+	}
+	p.pending = append(p.pending, b...)
+	idx := bytes.LastIndexByte(p.pending, '\n')
+	if idx < 0 {
+		return len(b), nil
+	}
+	lines := p.pending[:idx+1]
+	p.writeAbove(lines)
+	p.pending = append([]byte(nil), p.pending[idx+1:]...)
+	p.redraw()
+	return len(b), nil
+}
+
+// Handle records ev and updates the display. It is safe for concurrent use.
+func (p *Progress) Handle(ev runtime.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ev.Time.IsZero() {
+		ev.Time = p.now()
+	}
+
+	switch ev.Type {
+	case runtime.EventGraphStarted:
+		p.reset(ev)
+		p.printf(ev.Time, "START", "%s: %d nodes in %d phases", ev.Graph, p.total, len(p.phases))
+	case runtime.EventNodeStarted:
+		n := p.node(ev)
+		n.status = ev.Status
+		n.started = ev.Time
+		p.printf(ev.Time, "START", "%s on %s", nodeTitle(ev), strings.Join(n.hosts, ", "))
+	case runtime.EventHostStarted:
+		n := p.node(ev)
+		n.host[ev.Host] = ev.Status
+	case runtime.EventHostFinished:
+		n := p.node(ev)
+		n.host[ev.Host] = ev.Status
+		if ev.Status == string(plan.StatusFailed) {
+			p.printf(ev.Time, "FAILED", "%s on %s: %s", nodeTitle(ev), ev.Host, ev.Message)
+		}
+	case runtime.EventNodeFinished:
+		n := p.node(ev)
+		if n.finished.IsZero() {
+			p.finished++
+		}
+		n.status = ev.Status
+		n.message = ev.Message
+		n.finished = ev.Time
+		verb := "DONE"
+		switch ev.Status {
+		case string(plan.StatusFailed):
+			verb = "FAILED"
+		case string(plan.StatusSkipped):
+			verb = "SKIPPED"
+		}
+		if n.started.IsZero() {
+			p.printf(ev.Time, verb, "%s", nodeTitle(ev))
+		} else {
+			p.printf(ev.Time, verb, "%s (%s)", nodeTitle(ev), formatDuration(ev.Time.Sub(n.started)))
+		}
+	case runtime.EventGraphFinished:
+		p.status = ev.Status
+		p.printf(ev.Time, strings.ToUpper(ev.Status), "%s in %s", ev.Graph, formatDuration(ev.Time.Sub(p.start)))
+	default:
+		return
+	}
+	p.redraw()
+}
+
+// reset starts tracking the graph announced by ev. The display of a previous graph stays on
+// screen above the new one.
+func (p *Progress) reset(ev runtime.Event) {
+	p.drawn = 0
+	p.graph = ev.Graph
+	p.start = ev.Time
+	p.status = ""
+	p.phases = nil
+	p.byPhase = make(map[string]*phaseState)
+	p.nodes = make(map[string]*nodeState)
+	p.finished = 0
+	for _, n := range ev.Nodes {
+		p.addNode(n.ID, n.Name, n.Phase, n.Hosts)
+	}
+	p.total = len(ev.Nodes)
+}
+
+func (p *Progress) addNode(id, name, phase string, hosts []string) *nodeState {
+	n := &nodeState{id: id, name: name, hosts: hosts, host: make(map[string]string), status: string(plan.StatusPending)}
+	p.nodes[id] = n
+	ph, ok := p.byPhase[phase]
+	if !ok {
+		ph = &phaseState{name: phase}
+		p.byPhase[phase] = ph
+		p.phases = append(p.phases, ph)
+	}
+	ph.nodes = append(ph.nodes, n)
+	return n
+}
+
+// node returns the state of the node ev is about, adding it if the graph did not announce it.
+func (p *Progress) node(ev runtime.Event) *nodeState {
+	if n, ok := p.nodes[ev.NodeID]; ok {
+		return n
+	}
+	var hosts []string
+	if ev.Host != "" {
+		hosts = []string{ev.Host}
+	}
+	p.total++
+	return p.addNode(ev.NodeID, ev.NodeName, ev.Phase, hosts)
+}
+
+// printf writes a plain-mode line. It does nothing in live mode, where the display shows the
+// same information.
+func (p *Progress) printf(at time.Time, verb, format string, args ...interface{}) {
+	if p.live {
+		return
+	}
+	elapsed := time.Duration(0)
+	if !p.start.IsZero() {
+		elapsed = at.Sub(p.start)
+	}
+	fmt.Fprintf(p.out, "[%7s] %-7s %s\n", formatDuration(elapsed), verb, fmt.Sprintf(format, args...))
+}
+
+// writeAbove clears the live display and writes b in its place; the caller redraws afterwards.
+func (p *Progress) writeAbove(b []byte) {
+	p.clear()
+	_, _ = p.out.Write(b)
+}
+
+func (p *Progress) clear() {
+	if p.drawn == 0 {
+		return
+	}
+	fmt.Fprintf(p.out, "\r\x1b[%dA\x1b[J", p.drawn)
+	p.drawn = 0
+}
+
+func (p *Progress) redraw() {
+	if !p.live || p.graph == "" {
+		return
+	}
+	lines := p.render(p.now())
+	p.clear()
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(truncate(line, p.width-1))
+		b.WriteByte('\n')
+	}
+	_, _ = io.WriteString(p.out, b.String())
+	p.drawn = len(lines)
+}
+
+// render returns the lines of the live display: a header, one line per phase and, below its phase,
+// one line per running or failed node.
+func (p *Progress) render(now time.Time) []string {
+	elapsed := now.Sub(p.start)
+	header := fmt.Sprintf("%s  %d/%d nodes  %s", p.graph, p.finished, p.total, formatDuration(elapsed))
+	if p.status != "" {
+		header = fmt.Sprintf("%s  %s", header, p.status)
+	}
+	lines := []string{header}
+
+	nameWidth := 0
+	for _, ph := range p.phases {
+		if len(ph.name) > nameWidth {
+			nameWidth = len(ph.name)
+		}
+	}
+	for _, ph := range p.phases {
+		done, failed, running := 0, 0, 0
+		var first, last time.Time
+		for _, n := range ph.nodes {
+			switch {
+			case !n.finished.IsZero():
+				done++
+				if n.status == string(plan.StatusFailed) {
+					failed++
+				}
+				if n.finished.After(last) {
+					last = n.finished
+				}
+			case !n.started.IsZero():
+				running++
+			}
+			if !n.started.IsZero() && (first.IsZero() || n.started.Before(first)) {
+				first = n.started
+			}
+		}
+		symbol := "·"
+		switch {
+		case failed > 0:
+			symbol = "✗"
+		case done == len(ph.nodes):
+			symbol = "✓"
+		case running > 0:
+			symbol = p.spinner()
+		}
+		line := fmt.Sprintf("%s %-*s  %d/%d", symbol, nameWidth, ph.name, done, len(ph.nodes))
+		if !first.IsZero() {
+			end := now
+			if done == len(ph.nodes) {
+				end = last
+			}
+			line = fmt.Sprintf("%s  %s", line, formatDuration(end.Sub(first)))
+		}
+		lines = append(lines, line)
+
+		for _, n := range ph.nodes {
+			switch {
+			case n.status == string(plan.StatusFailed):
+				lines = append(lines, fmt.Sprintf("    ✗ %s: %s", n.name, firstLine(n.message)))
+			case n.finished.IsZero() && !n.started.IsZero():
+				lines = append(lines, fmt.Sprintf("    %s %s  %s  %s", p.spinner(), n.name, formatDuration(now.Sub(n.started)), p.hostStates(n)))
+			}
+		}
+	}
+	return lines
+}
+
+func (p *Progress) hostStates(n *nodeState) string {
+	parts := make([]string, 0, len(n.hosts))
+	for _, host := range n.hosts {
+		symbol := "·"
+		switch n.host[host] {
+		case string(plan.StatusRunning):
+			symbol = p.spinner()
+		case string(plan.StatusSuccess):
+			symbol = "✓"
+		case string(plan.StatusFailed):
+			symbol = "✗"
+		}
+		parts = append(parts, host+" "+symbol)
+	}
+	return strings.Join(parts, "  ")
+}
+
+func (p *Progress) spinner() string {
+	return spinnerFrames[p.frame%len(spinnerFrames)]
+}
+
+func nodeTitle(ev runtime.Event) string {
+	if ev.Phase == "" {
+		return ev.NodeName
+	}
+	return ev.Phase + " / " + ev.NodeName
+}
+
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return d.Round(time.Second).String()
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mensylisir/kubexm/internal/runtime"
+)
+
+func progressEvents(start time.Time) []runtime.Event {
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	return []runtime.Event{
+		{Type: runtime.EventGraphStarted, Time: at(0), Graph: "CreateCluster", Nodes: []runtime.EventNode{
+			{ID: "os", Name: "ConfigureOS", Phase: "Preflight", Hosts: []string{"node1", "node2"}},
+			{ID: "etcd", Name: "InstallEtcd", Phase: "Etcd", Hosts: []string{"node1", "node2"}},
+			{ID: "kubelet", Name: "InstallKubelet", Phase: "Kubernetes", Hosts: []string{"node1", "node2"}},
+		}},
+		{Type: runtime.EventNodeStarted, Time: at(1), NodeID: "os", NodeName: "ConfigureOS", Phase: "Preflight", Status: "Running"},
+		{Type: runtime.EventNodeFinished, Time: at(4), NodeID: "os", NodeName: "ConfigureOS", Phase: "Preflight", Status: "Success"},
+		{Type: runtime.EventNodeStarted, Time: at(5), NodeID: "etcd", NodeName: "InstallEtcd", Phase: "Etcd", Status: "Running"},
+		{Type: runtime.EventHostStarted, Time: at(5), NodeID: "etcd", NodeName: "InstallEtcd", Phase: "Etcd", Host: "node1", Status: "Running"},
+		{Type: runtime.EventHostStarted, Time: at(5), NodeID: "etcd", NodeName: "InstallEtcd", Phase: "Etcd", Host: "node2", Status: "Running"},
+		{Type: runtime.EventHostFinished, Time: at(8), NodeID: "etcd", NodeName: "InstallEtcd", Phase: "Etcd", Host: "node1", Status: "Success"},
+	}
+}
+
+func TestProgress_Plain(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, false)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := append(progressEvents(start),
+		runtime.Event{Type: runtime.EventHostFinished, Time: start.Add(9 * time.Second), NodeID: "etcd", NodeName: "InstallEtcd", Phase: "Etcd", Host: "node2", Status: "Failed", Message: "Run failed: exit status 1"},
+		runtime.Event{Type: runtime.EventNodeFinished, Time: start.Add(9 * time.Second), NodeID: "etcd", NodeName: "InstallEtcd", Phase: "Etcd", Status: "Failed"},
+		runtime.Event{Type: runtime.EventNodeFinished, Time: start.Add(9 * time.Second), NodeID: "kubelet", NodeName: "InstallKubelet", Phase: "Kubernetes", Status: "Skipped"},
+		runtime.Event{Type: runtime.EventGraphFinished, Time: start.Add(10 * time.Second), Graph: "CreateCluster", Status: "Failed"},
+	)
+	for _, ev := range events {
+		p.Handle(ev)
+	}
+
+	want := []string{
+		"[     0s] START   CreateCluster: 3 nodes in 3 phases",
+		"[     1s] START   Preflight / ConfigureOS on node1, node2",
+		"[     4s] DONE    Preflight / ConfigureOS (3s)",
+		"[     5s] START   Etcd / InstallEtcd on node1, node2",
+		"[     9s] FAILED  Etcd / InstallEtcd on node2: Run failed: exit status 1",
+		"[     9s] FAILED  Etcd / InstallEtcd (4s)",
+		"[     9s] SKIPPED Kubernetes / InstallKubelet",
+		"[    10s] FAILED  CreateCluster in 10s",
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected plain output:\n%s\nwant:\n%s", out.String(), strings.Join(want, "\n"))
+	}
+}
+
+func TestProgress_LiveRender(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, true)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return start.Add(10 * time.Second) }
+	for _, ev := range progressEvents(start) {
+		p.Handle(ev)
+	}
+
+	want := []string{
+		"CreateCluster  1/3 nodes  10s",
+		"✓ Preflight   1/1  3s",
+		"⠋ Etcd        0/1  5s",
+		"    ⠋ InstallEtcd  5s  node1 ✓  node2 ⠋",
+		"· Kubernetes  0/1",
+	}
+	if got := p.render(p.now()); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected display:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if p.drawn != len(want) {
+		t.Errorf("expected %d lines on screen, got %d", len(want), p.drawn)
+	}
+}
+
+func TestProgress_WriteAboveLiveDisplay(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, true)
+	start := time.Now()
+	p.Handle(progressEvents(start)[0])
+	out.Reset()
+
+	if _, err := p.Write([]byte("partial ")); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected an incomplete line to be held back, got %q", out.String())
+	}
+	if _, err := p.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	clear := "\r\x1b[4A\x1b[J"
+	if !strings.HasPrefix(got, clear+"partial line\n") {
+		t.Errorf("expected the display to be cleared before the log line, got %q", got)
+	}
+	if !strings.Contains(got, "CreateCluster  0/3 nodes") {
+		t.Errorf("expected the display to be redrawn below the log line, got %q", got)
+	}
+
+	p.Stop()
+	if p.drawn != 0 {
+		t.Errorf("expected the display to be left on screen after Stop")
+	}
+}
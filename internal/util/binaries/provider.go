@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
 	"github.com/mensylisir/kubexm/internal/runtime"
 )
@@ -182,7 +183,7 @@ func (p *BinaryProvider) isBinaryEnabled(name string) (bool, error) {
 		return strings.EqualFold(cfg.Etcd.Type, string(common.EtcdDeploymentTypeKubexm)), nil
 
 	case ComponentKubeProxy:
-		return cfg.Kubernetes.KubeProxy == nil || cfg.Kubernetes.KubeProxy.Enable == nil || *cfg.Kubernetes.KubeProxy.Enable, nil
+		return v1alpha1.KubeProxyEnabled(cfg), nil
 
 	// --- 容器运行时相关 ---
 	case ComponentContainerd, ComponentRunc, ComponentCriCtl:
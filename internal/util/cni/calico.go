@@ -0,0 +1,270 @@
+package cni
+
+import (
+	"net"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+const (
+	calicoOperatorImage = "tigera-operator"
+	// calicoTyphaNodeThreshold is the cluster size above which Typha is enabled unless configured explicitly.
+	calicoTyphaNodeThreshold = 50
+	calicoDefaultVethMTU     = 1440
+	calicoIPv6BlockSize      = 122
+)
+
+type calicoProvider struct {
+	network *v1alpha1.Network
+}
+
+type calicoIPPoolData struct {
+	CIDR          string
+	Encapsulation string
+	NatOutgoing   bool
+	BlockSize     int
+}
+
+type calicoValuesData struct {
+	Registry             string
+	IPPools              []calicoIPPoolData
+	VethMTU              int
+	LogSeverityScreen    string
+	TyphaEnabled         bool
+	TyphaReplicas        int
+	TyphaNodeSelector    map[string]string
+	OperatorImage        string
+	OperatorNodeSelector map[string]string
+	OperatorTolerations  []map[string]string
+}
+
+func (p *calicoProvider) Name() string {
+	return string(common.CNITypeCalico)
+}
+
+func (p *calicoProvider) config() *v1alpha1.CalicoConfig {
+	if p.network.Calico == nil {
+		return &v1alpha1.CalicoConfig{}
+	}
+	return p.network.Calico
+}
+
+// encapsulation maps the cluster-wide ipipMode/vxlanMode to the encapsulation of the IPv4 pools.
+// VXLAN is used when neither mode is set, as it works on networks that drop IP-in-IP traffic.
+func (p *calicoProvider) encapsulation() string {
+	networking := p.config().Networking
+	if networking == nil {
+		return common.CalicoIPPoolEncapsulationVXLAN
+	}
+	switch networking.VXLANMode {
+	case common.CalicoVXLANModeAlways:
+		return common.CalicoIPPoolEncapsulationVXLAN
+	case common.CalicoVXLANModeCrossSubnet:
+		return common.CalicoIPPoolEncapsulationVXLANCrossSubnet
+	}
+	switch networking.IPIPMode {
+	case common.CalicoIPIPModeAlways:
+		return common.CalicoIPPoolEncapsulationIPIP
+	case common.CalicoIPIPModeCrossSubnet:
+		return common.CalicoIPPoolEncapsulationIPIPCrossSubnet
+	case common.CalicoIPIPModeNever:
+		return common.CalicoIPPoolEncapsulationNone
+	}
+	return common.CalicoIPPoolEncapsulationVXLAN
+}
+
+// ipPools returns the configured pools with defaults applied, or one pool per pod CIDR when none are
+// configured so dual-stack clusters get an IPv4 and an IPv6 pool.
+func (p *calicoProvider) ipPools() []calicoIPPoolData {
+	encapsulation := p.encapsulation()
+	var pools []calicoIPPoolData
+	if ipam := p.config().IPAM; ipam != nil && len(ipam.Pools) > 0 {
+		for _, pool := range ipam.Pools {
+			if pool.Disabled != nil && *pool.Disabled {
+				continue
+			}
+			data := calicoIPPoolData{
+				CIDR:          pool.CIDR,
+				Encapsulation: pool.Encapsulation,
+				NatOutgoing:   pool.NatOutgoing == nil || *pool.NatOutgoing,
+				BlockSize:     defaultBlockSize(pool.CIDR),
+			}
+			if data.Encapsulation == "" {
+				data.Encapsulation = encapsulationFor(pool.CIDR, encapsulation)
+			}
+			if pool.BlockSize != nil {
+				data.BlockSize = *pool.BlockSize
+			}
+			pools = append(pools, data)
+		}
+		return pools
+	}
+
+	v4, v6 := podCIDRs(p.network)
+	for _, cidr := range []string{v4, v6} {
+		if cidr == "" {
+			continue
+		}
+		pools = append(pools, calicoIPPoolData{
+			CIDR:          cidr,
+			Encapsulation: encapsulationFor(cidr, encapsulation),
+			NatOutgoing:   common.CalicoIPPoolNatOutgoing,
+			BlockSize:     defaultBlockSize(cidr),
+		})
+	}
+	return pools
+}
+
+func isIPv6CIDR(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	return err == nil && ip.To4() == nil
+}
+
+// encapsulationFor switches IPv6 pools from IPIP to VXLAN, as IPIP cannot carry IPv6 traffic.
+func encapsulationFor(cidr, encapsulation string) string {
+	if isIPv6CIDR(cidr) && strings.HasPrefix(encapsulation, common.CalicoIPPoolEncapsulationIPIP) {
+		return common.CalicoIPPoolEncapsulationVXLAN
+	}
+	return encapsulation
+}
+
+func defaultBlockSize(cidr string) int {
+	if isIPv6CIDR(cidr) {
+		return calicoIPv6BlockSize
+	}
+	return common.DefaultCalicoIPPoolBlockSize
+}
+
+func (p *calicoProvider) Validate() error {
+	verrs := &validation.ValidationErrors{}
+	if networking := p.config().Networking; networking != nil {
+		ipip := networking.IPIPMode != "" && networking.IPIPMode != common.CalicoIPIPModeNever
+		vxlan := networking.VXLANMode != "" && networking.VXLANMode != common.CalicoVXLANModeNever
+		if ipip && vxlan {
+			verrs.Add("network.calico.networking: ipipMode '%s' and vxlanMode '%s' cannot both be enabled",
+				networking.IPIPMode, networking.VXLANMode)
+		}
+	}
+
+	podNets := parseCIDRs(p.network.KubePodsCIDR)
+	for _, pool := range p.ipPools() {
+		_, poolNet, err := net.ParseCIDR(pool.CIDR)
+		if err != nil {
+			verrs.Add("network.calico: invalid IP pool CIDR '%s': %v", pool.CIDR, err)
+			continue
+		}
+		prefix, bits := poolNet.Mask.Size()
+		if pool.BlockSize < prefix || pool.BlockSize > bits {
+			verrs.Add("network.calico: blockSize %d of IP pool %s must be between the pool prefix length %d and %d",
+				pool.BlockSize, pool.CIDR, prefix, bits)
+		}
+		if len(podNets) > 0 && !containedIn(poolNet, podNets) {
+			verrs.Add("network.calico: IP pool %s is not within kubePodsCIDR %s", pool.CIDR, p.network.KubePodsCIDR)
+		}
+	}
+
+	if verrs.HasErrors() {
+		return verrs
+	}
+	return nil
+}
+
+func parseCIDRs(cidrs string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range strings.Split(cidrs, ",") {
+		if _, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr)); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	return nets
+}
+
+func containedIn(inner *net.IPNet, outers []*net.IPNet) bool {
+	innerPrefix, innerBits := inner.Mask.Size()
+	for _, outer := range outers {
+		outerPrefix, outerBits := outer.Mask.Size()
+		if innerBits == outerBits && outerPrefix <= innerPrefix && outer.Contains(inner.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *calicoProvider) HelmValues(opts Options) ([]byte, error) {
+	operatorImage, err := imageFor(opts, calicoOperatorImage)
+	if err != nil {
+		return nil, err
+	}
+	cfg := p.config()
+	data := calicoValuesData{
+		Registry:             opts.Registry,
+		IPPools:              p.ipPools(),
+		VethMTU:              calicoDefaultVethMTU,
+		LogSeverityScreen:    common.DefaultCalicoFelixConfigurationLogSeverityScreen,
+		TyphaEnabled:         opts.NodeCount > calicoTyphaNodeThreshold,
+		TyphaNodeSelector:    map[string]string{"node-role.kubernetes.io/control-plane": ""},
+		OperatorImage:        operatorImage,
+		OperatorNodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+		OperatorTolerations: []map[string]string{
+			{"key": "node-role.kubernetes.io/control-plane", "operator": "Exists", "effect": "NoSchedule"},
+		},
+	}
+	if cfg.Networking != nil && cfg.Networking.VethMTU != nil {
+		data.VethMTU = *cfg.Networking.VethMTU
+	}
+	if cfg.FelixConfiguration != nil && cfg.FelixConfiguration.LogSeverityScreen != "" {
+		data.LogSeverityScreen = cfg.FelixConfiguration.LogSeverityScreen
+	}
+	if typha := cfg.TyphaDeployment; typha != nil {
+		if typha.Enabled != nil {
+			data.TyphaEnabled = *typha.Enabled
+		}
+		if typha.Replicas != nil {
+			data.TyphaReplicas = *typha.Replicas
+		}
+		if typha.NodeSelector != nil {
+			data.TyphaNodeSelector = typha.NodeSelector
+		}
+	}
+	return render("calico/values.yaml.tmpl", data)
+}
+
+func (p *calicoProvider) RequiredImages() []string {
+	return []string{calicoOperatorImage, "calico-cni", "calico-node", "calico-kube-controllers", "calico-typha",
+		"calico-flexvol", "calico-apiserver", "calico-key-cert-provisioner"}
+}
+
+func (p *calicoProvider) RequiredSysctls() map[string]string {
+	// Felix refuses to start with strict reverse path filtering (2), which drops workload traffic.
+	return map[string]string{
+		"net.ipv4.ip_forward":         "1",
+		"net.ipv4.conf.all.rp_filter": "1",
+	}
+}
+
+func (p *calicoProvider) RequiredKernelModules() []string {
+	modules := []string{"ip_set", "ip_tables", "xt_set", "xt_mark", "xt_multiport", "xt_conntrack", "ipt_rpfilter"}
+	for _, pool := range p.ipPools() {
+		switch {
+		case strings.HasPrefix(pool.Encapsulation, common.CalicoIPPoolEncapsulationIPIP):
+			modules = appendUnique(modules, "ipip")
+		case strings.HasPrefix(pool.Encapsulation, common.CalicoIPPoolEncapsulationVXLAN):
+			modules = appendUnique(modules, "vxlan")
+		}
+	}
+	return modules
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+var _ Provider = (*calicoProvider)(nil)
@@ -0,0 +1,190 @@
+package cni
+
+import (
+	"net"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+const (
+	ciliumImage         = "cilium"
+	ciliumOperatorImage = "cilium-operator-generic"
+	// ciliumIPv4MaskSize and ciliumIPv6MaskSize are the per-node pod ranges cut from the pod CIDR in cluster-pool IPAM.
+	ciliumIPv4MaskSize = 24
+	ciliumIPv6MaskSize = 120
+)
+
+type ciliumProvider struct {
+	network *v1alpha1.Network
+}
+
+type ciliumValuesData struct {
+	ImageRepository         string
+	ImageTag                string
+	OperatorImageRepository string
+	OperatorImageTag        string
+	RoutingMode             string
+	TunnelProtocol          string
+	IpamMode                string
+	PodCIDR                 string
+	PodCIDRv6               string
+	IPv4MaskSize            int
+	IPv6MaskSize            int
+	KubeProxyReplacement    bool
+	BpfMasquerade           bool
+	HubbleEnabled           bool
+	HubbleUiEnabled         bool
+	HubbleRelayEnabled      bool
+	IdentityAllocationMode  string
+	EncryptionEnabled       bool
+	BandwidthManagerEnabled bool
+	AutoDirectNodeRoutes    bool
+	OperatorReplicas        int
+	K8sServiceHost          string
+	K8sServicePort          int
+}
+
+func (p *ciliumProvider) Name() string {
+	return string(common.CNITypeCilium)
+}
+
+func (p *ciliumProvider) config() *v1alpha1.CiliumConfig {
+	if p.network.Cilium == nil {
+		return &v1alpha1.CiliumConfig{}
+	}
+	return p.network.Cilium
+}
+
+func (p *ciliumProvider) tunnelMode() string {
+	if network := p.config().Network; network != nil && network.TunnelingMode != "" {
+		return network.TunnelingMode
+	}
+	return common.DefaultTunnelingMode
+}
+
+func (p *ciliumProvider) ipamMode() string {
+	if network := p.config().Network; network != nil && network.IPAMMode != "" {
+		return network.IPAMMode
+	}
+	return common.CiliumIPAMKubernetesMode
+}
+
+func (p *ciliumProvider) Validate() error {
+	verrs := &validation.ValidationErrors{}
+	cfg := p.config()
+	if cfg.Security != nil && cfg.Security.IdentityAllocationMode == common.CiliumIdentKvstoreModes {
+		verrs.Add("network.cilium.security.identityAllocationMode: 'kvstore' needs an external kvstore, which is not supported; use 'crd'")
+	}
+	if p.ipamMode() == common.CiliumIPAMClusterPoolsMode {
+		for _, podNet := range parseCIDRs(p.network.KubePodsCIDR) {
+			prefix, bits := podNet.Mask.Size()
+			maskSize := ciliumIPv4MaskSize
+			if bits == 8*net.IPv6len {
+				maskSize = ciliumIPv6MaskSize
+			}
+			if prefix > maskSize {
+				verrs.Add("network.kubePodsCIDR: %s is smaller than the /%d per-node range cilium cluster-pool IPAM allocates", podNet, maskSize)
+			}
+		}
+	}
+	if verrs.HasErrors() {
+		return verrs
+	}
+	return nil
+}
+
+func (p *ciliumProvider) HelmValues(opts Options) ([]byte, error) {
+	agentRef, err := imageFor(opts, ciliumImage)
+	if err != nil {
+		return nil, err
+	}
+	operatorRef, err := imageFor(opts, ciliumOperatorImage)
+	if err != nil {
+		return nil, err
+	}
+	data := ciliumValuesData{
+		RoutingMode:            "tunnel",
+		TunnelProtocol:         p.tunnelMode(),
+		IpamMode:               p.ipamMode(),
+		IPv4MaskSize:           ciliumIPv4MaskSize,
+		IPv6MaskSize:           ciliumIPv6MaskSize,
+		BpfMasquerade:          common.DefaultEnableBPFMasqueradeEnable,
+		HubbleEnabled:          true,
+		HubbleUiEnabled:        true,
+		HubbleRelayEnabled:     true,
+		IdentityAllocationMode: common.DefaultIdentityAllocationMode,
+		OperatorReplicas:       1,
+		K8sServiceHost:         opts.APIServerHost,
+		K8sServicePort:         opts.APIServerPort,
+	}
+	data.ImageRepository, data.ImageTag = splitImage(agentRef)
+	data.OperatorImageRepository, data.OperatorImageTag = splitImage(operatorRef)
+	data.PodCIDR, data.PodCIDRv6 = podCIDRs(p.network)
+	// Without a tunnel every node must route the pod ranges of the others, which Cilium sets up
+	// itself when the nodes share an L2 segment.
+	if data.TunnelProtocol == common.CiliumTunnelDisabledModes {
+		data.RoutingMode = "native"
+		data.TunnelProtocol = ""
+		data.AutoDirectNodeRoutes = true
+	}
+
+	cfg := p.config()
+	kprMode := common.CiliumKPRProbeModes
+	if cfg.KubeProxy != nil {
+		if cfg.KubeProxy.ReplacementMode != "" {
+			kprMode = cfg.KubeProxy.ReplacementMode
+		}
+		if cfg.KubeProxy.EnableBPFMasquerade != nil {
+			data.BpfMasquerade = *cfg.KubeProxy.EnableBPFMasquerade
+		}
+	}
+	// Cilium 1.15 dropped the probe/strict modes; only strict fully replaces kube-proxy, and eBPF
+	// masquerading depends on the eBPF NodePort implementation that comes with it.
+	data.KubeProxyReplacement = kprMode == common.CiliumKPRStrictModes
+	data.BpfMasquerade = data.BpfMasquerade && data.KubeProxyReplacement
+	if cfg.Hubble != nil {
+		data.HubbleEnabled = cfg.Hubble.Enable
+		data.HubbleUiEnabled = cfg.Hubble.EnableUI
+		data.HubbleRelayEnabled = cfg.Hubble.Enable
+	}
+	if cfg.Security != nil {
+		if cfg.Security.IdentityAllocationMode != "" {
+			data.IdentityAllocationMode = cfg.Security.IdentityAllocationMode
+		}
+		if cfg.Security.EnableEncryption != nil {
+			data.EncryptionEnabled = *cfg.Security.EnableEncryption
+		}
+	}
+	if cfg.Performance != nil && cfg.Performance.EnableBandwidthManager != nil {
+		data.BandwidthManagerEnabled = *cfg.Performance.EnableBandwidthManager
+	}
+	return render("cilium/values.yaml.tmpl", data)
+}
+
+func (p *ciliumProvider) RequiredImages() []string {
+	return []string{ciliumImage, ciliumOperatorImage}
+}
+
+func (p *ciliumProvider) RequiredSysctls() map[string]string {
+	// Cilium routes replies through its own devices, which strict reverse path filtering drops.
+	return map[string]string{
+		"net.ipv4.ip_forward":             "1",
+		"net.ipv4.conf.all.rp_filter":     "0",
+		"net.ipv4.conf.default.rp_filter": "0",
+	}
+}
+
+func (p *ciliumProvider) RequiredKernelModules() []string {
+	modules := []string{"cls_bpf", "sch_ingress", "xt_socket", "ip_tables", "iptable_nat", "iptable_mangle", "iptable_raw", "iptable_filter"}
+	switch p.tunnelMode() {
+	case common.CiliumTunnelVxlanModes:
+		modules = append(modules, "vxlan")
+	case common.CiliumTunnelGeneveModes:
+		modules = append(modules, "geneve")
+	}
+	return modules
+}
+
+var _ Provider = (*ciliumProvider)(nil)
@@ -0,0 +1,103 @@
+package cni
+
+import (
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/errors/validation"
+)
+
+const (
+	flannelImage          = "flannel"
+	flannelCNIPluginImage = "flannel-cni-plugin"
+)
+
+type flannelProvider struct {
+	network *v1alpha1.Network
+}
+
+type flannelValuesData struct {
+	ImageFlannelRepo   string
+	ImageFlannelTag    string
+	ImageCNIPluginRepo string
+	ImageCNIPluginTag  string
+	PodCIDR            string
+	PodCIDRv6          string
+	BackendType        string
+	BackendVXLAN       *v1alpha1.FlannelVXLANConfig
+	BackendIPsec       *v1alpha1.FlannelIPsecConfig
+}
+
+func (p *flannelProvider) Name() string {
+	return string(common.CNITypeFlannel)
+}
+
+func (p *flannelProvider) backend() *v1alpha1.FlannelBackendConfig {
+	backend := &v1alpha1.FlannelBackendConfig{}
+	if p.network.Flannel != nil && p.network.Flannel.Backend != nil {
+		*backend = *p.network.Flannel.Backend
+	}
+	if backend.Type == "" {
+		backend.Type = common.DefaultFlannelBackendConfigType
+	}
+	return backend
+}
+
+func (p *flannelProvider) Validate() error {
+	verrs := &validation.ValidationErrors{}
+	backend := p.backend()
+	// Flannel only routes IPv6 pod traffic over the vxlan and host-gw backends.
+	if _, v6 := podCIDRs(p.network); v6 != "" &&
+		backend.Type != common.FlannelBackendConfigTypeVxlan && backend.Type != common.FlannelBackendConfigTypeHostGw {
+		verrs.Add("network.flannel.backend.type: backend '%s' does not support the IPv6 pod CIDR %s", backend.Type, v6)
+	}
+	if backend.Type == common.FlannelBackendConfigTypeIpsec && (backend.IPsec == nil || backend.IPsec.PSKSecretName == "") {
+		verrs.Add("network.flannel.backend.ipsec.pskSecretName: is required for the ipsec backend")
+	}
+	if verrs.HasErrors() {
+		return verrs
+	}
+	return nil
+}
+
+func (p *flannelProvider) HelmValues(opts Options) ([]byte, error) {
+	flannelRef, err := imageFor(opts, flannelImage)
+	if err != nil {
+		return nil, err
+	}
+	cniPluginRef, err := imageFor(opts, flannelCNIPluginImage)
+	if err != nil {
+		return nil, err
+	}
+	backend := p.backend()
+	data := flannelValuesData{
+		BackendType:  backend.Type,
+		BackendVXLAN: backend.VXLAN,
+		BackendIPsec: backend.IPsec,
+	}
+	data.ImageFlannelRepo, data.ImageFlannelTag = splitImage(flannelRef)
+	data.ImageCNIPluginRepo, data.ImageCNIPluginTag = splitImage(cniPluginRef)
+	// Flannel takes the IPv4 and IPv6 ranges of a dual-stack cluster as separate values.
+	data.PodCIDR, data.PodCIDRv6 = podCIDRs(p.network)
+	return render("flannel/values.yaml.tmpl", data)
+}
+
+func (p *flannelProvider) RequiredImages() []string {
+	return []string{flannelImage, flannelCNIPluginImage}
+}
+
+func (p *flannelProvider) RequiredSysctls() map[string]string {
+	return map[string]string{
+		"net.ipv4.ip_forward":                "1",
+		"net.bridge.bridge-nf-call-iptables": "1",
+	}
+}
+
+func (p *flannelProvider) RequiredKernelModules() []string {
+	modules := []string{"br_netfilter"}
+	if p.backend().Type == common.FlannelBackendConfigTypeVxlan {
+		modules = append(modules, "vxlan")
+	}
+	return modules
+}
+
+var _ Provider = (*flannelProvider)(nil)
@@ -0,0 +1,99 @@
+package cni
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+type networkAttachmentData struct {
+	Name      string
+	Namespace string
+	Config    string
+}
+
+// NetworkAttachmentDefinitions renders the NetworkAttachmentDefinitions of the configured secondary
+// networks as a multi-document manifest. It returns nil when no attachments are configured.
+func NetworkAttachmentDefinitions(multus *v1alpha1.MultusConfig) ([]byte, error) {
+	if multus == nil || len(multus.NetworkAttachments) == 0 {
+		return nil, nil
+	}
+	var data []networkAttachmentData
+	for _, attachment := range multus.NetworkAttachments {
+		config, err := attachmentConfig(attachment)
+		if err != nil {
+			return nil, fmt.Errorf("network attachment '%s': %w", attachment.Name, err)
+		}
+		namespace := attachment.Namespace
+		if namespace == "" {
+			namespace = common.DefaultMultusNetworkAttachmentNamespace
+		}
+		data = append(data, networkAttachmentData{
+			Name:      attachment.Name,
+			Namespace: namespace,
+			// The config is emitted as a single-quoted YAML scalar, in which quotes are escaped by doubling.
+			Config: strings.ReplaceAll(config, "'", "''"),
+		})
+	}
+	return render("multus/network-attachments.yaml.tmpl", data)
+}
+
+// attachmentConfig returns the CNI configuration of an attachment as compact JSON.
+func attachmentConfig(attachment v1alpha1.MultusNetworkAttachment) (string, error) {
+	if attachment.Config != "" {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(attachment.Config)); err != nil {
+			return "", fmt.Errorf("invalid CNI config: %w", err)
+		}
+		return compact.String(), nil
+	}
+
+	config := map[string]interface{}{
+		"cniVersion": common.DefaultMultusCNIVersion,
+		"name":       attachment.Name,
+		"type":       attachment.Type,
+	}
+	if attachment.Master != "" {
+		config["master"] = attachment.Master
+	}
+	if attachment.Mode != "" {
+		config["mode"] = attachment.Mode
+	}
+	if attachment.IPAM != nil {
+		config["ipam"] = attachmentIPAM(attachment.IPAM)
+	}
+	out, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// attachmentIPAM maps the IPAM settings to the keys each IPAM plugin reads; whereabouts uses its own names.
+func attachmentIPAM(ipam *v1alpha1.MultusAttachmentIPAM) map[string]interface{} {
+	ipamType := ipam.Type
+	if ipamType == "" {
+		ipamType = common.MultusIPAMTypeHostLocal
+	}
+	config := map[string]interface{}{"type": ipamType}
+	if ipamType == common.MultusIPAMTypeDHCP {
+		return config
+	}
+	keys := map[string]string{"subnet": "subnet", "rangeStart": "rangeStart", "rangeEnd": "rangeEnd"}
+	if ipamType == common.MultusIPAMTypeWhereabouts {
+		keys = map[string]string{"subnet": "range", "rangeStart": "range_start", "rangeEnd": "range_end"}
+	}
+	for field, value := range map[string]string{"subnet": ipam.Subnet, "rangeStart": ipam.RangeStart, "rangeEnd": ipam.RangeEnd} {
+		if value != "" {
+			config[keys[field]] = value
+		}
+	}
+	if ipam.Gateway != "" {
+		config["gateway"] = ipam.Gateway
+	}
+	return config
+}
@@ -0,0 +1,121 @@
+package cni
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/templates"
+)
+
+// Provider describes how a CNI plugin is installed from the network section of a cluster spec:
+// the Helm values of its chart and what the nodes need before its pods can start.
+type Provider interface {
+	// Name returns the plugin name as used in spec.network.plugin.
+	Name() string
+	// Validate checks the network section for settings the plugin cannot honour.
+	Validate() error
+	// HelmValues renders the values file the plugin chart is installed with.
+	HelmValues(opts Options) ([]byte, error)
+	// RequiredImages returns the image BOM names the plugin pulls.
+	RequiredImages() []string
+	// RequiredSysctls returns the kernel parameters the plugin datapath depends on.
+	RequiredSysctls() map[string]string
+	// RequiredKernelModules returns the kernel modules the plugin datapath depends on.
+	RequiredKernelModules() []string
+}
+
+// Options carries the values a Network section does not hold itself. Image names are resolved through
+// the image BOM, which needs an execution context, so callers look them up and pass them in.
+type Options struct {
+	// Images maps the names returned by RequiredImages to full image references.
+	Images map[string]string
+	// Registry is the private registry the plugin operator rewrites its own images to, if any.
+	Registry string
+	// NodeCount is the number of Kubernetes nodes, used to size control plane components.
+	NodeCount int
+	// APIServerHost and APIServerPort address the control plane directly. They are set when kube-proxy
+	// is not deployed, since nothing programs the kubernetes Service ClusterIP until the plugin runs.
+	APIServerHost string
+	APIServerPort int
+}
+
+type factory func(network *v1alpha1.Network) Provider
+
+var providers = map[string]factory{
+	string(common.CNITypeCalico):  func(n *v1alpha1.Network) Provider { return &calicoProvider{network: n} },
+	string(common.CNITypeFlannel): func(n *v1alpha1.Network) Provider { return &flannelProvider{network: n} },
+	string(common.CNITypeCilium):  func(n *v1alpha1.Network) Provider { return &ciliumProvider{network: n} },
+}
+
+// NewProvider returns the provider of the plugin selected in network.
+func NewProvider(network *v1alpha1.Network) (Provider, error) {
+	if network == nil {
+		return nil, fmt.Errorf("network configuration is nil")
+	}
+	newProvider, ok := providers[network.Plugin]
+	if !ok {
+		return nil, fmt.Errorf("no CNI provider for plugin '%s': supported plugins are %v", network.Plugin, SupportedPlugins())
+	}
+	return newProvider(network), nil
+}
+
+// SupportedPlugins returns the plugins that have a provider, sorted by name.
+func SupportedPlugins() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// podCIDRs splits a single- or dual-stack pod CIDR into its IPv4 and IPv6 ranges.
+func podCIDRs(network *v1alpha1.Network) (v4, v6 string) {
+	cidrs := network.KubePodsCIDR
+	if strings.TrimSpace(cidrs) == "" {
+		cidrs = common.DefaultKubePodsCIDR
+	}
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() == nil {
+			if v6 == "" {
+				v6 = cidr
+			}
+		} else if v4 == "" {
+			v4 = cidr
+		}
+	}
+	return v4, v6
+}
+
+// splitImage splits a full image reference into its repository and tag.
+func splitImage(ref string) (repository, tag string) {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+func imageFor(opts Options, name string) (string, error) {
+	ref := opts.Images[name]
+	if ref == "" {
+		return "", fmt.Errorf("image '%s' was not resolved", name)
+	}
+	return ref, nil
+}
+
+func render(templateName string, data interface{}) ([]byte, error) {
+	templateContent, err := templates.Get("cni/" + templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CNI template %s: %w", templateName, err)
+	}
+	rendered, err := templates.Render(templateContent, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render CNI template %s: %w", templateName, err)
+	}
+	return []byte(rendered), nil
+}
@@ -0,0 +1,223 @@
+package cni
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestNewProvider(t *testing.T) {
+	for _, plugin := range []string{"calico", "flannel", "cilium"} {
+		p, err := NewProvider(&v1alpha1.Network{Plugin: plugin})
+		if err != nil || p.Name() != plugin {
+			t.Errorf("expected a provider for %s, got %v (err %v)", plugin, p, err)
+		}
+	}
+	if _, err := NewProvider(&v1alpha1.Network{Plugin: string(common.CNITypeKubeOvn)}); err == nil {
+		t.Error("expected an error for a plugin without a provider")
+	}
+}
+
+func renderValues(t *testing.T, network *v1alpha1.Network) map[string]interface{} {
+	t.Helper()
+	return renderValuesWith(t, network, Options{})
+}
+
+func renderValuesWith(t *testing.T, network *v1alpha1.Network, opts Options) map[string]interface{} {
+	t.Helper()
+	p, err := NewProvider(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Images = map[string]string{}
+	for _, name := range p.RequiredImages() {
+		opts.Images[name] = "registry.local/kubexm/" + name + ":v1.0.0"
+	}
+	out, err := p.HelmValues(opts)
+	if err != nil {
+		t.Fatalf("failed to render values: %v", err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(out, &values); err != nil {
+		t.Fatalf("rendered values are not valid YAML: %v\n%s", err, out)
+	}
+	return values
+}
+
+func TestCalicoIPPools(t *testing.T) {
+	network := &v1alpha1.Network{
+		Plugin:       "calico",
+		KubePodsCIDR: "10.233.64.0/18,fd85:ee78:d8a6:8607::1:0000/112",
+		Calico: &v1alpha1.CalicoConfig{Networking: &v1alpha1.CalicoNetworking{
+			IPIPMode:  common.CalicoIPIPModeAlways,
+			VXLANMode: common.CalicoVXLANModeNever,
+		}},
+	}
+	pools := (&calicoProvider{network: network}).ipPools()
+	if len(pools) != 2 {
+		t.Fatalf("expected an IPv4 and an IPv6 pool, got %+v", pools)
+	}
+	if pools[0].Encapsulation != common.CalicoIPPoolEncapsulationIPIP || pools[0].BlockSize != common.DefaultCalicoIPPoolBlockSize {
+		t.Errorf("unexpected IPv4 pool %+v", pools[0])
+	}
+	if pools[1].Encapsulation != common.CalicoIPPoolEncapsulationVXLAN || pools[1].BlockSize != calicoIPv6BlockSize {
+		t.Errorf("expected the IPv6 pool to fall back to VXLAN, got %+v", pools[1])
+	}
+
+	network.Calico.Networking = &v1alpha1.CalicoNetworking{IPIPMode: common.CalicoIPIPModeNever, VXLANMode: common.CalicoVXLANModeCrossSubnet}
+	network.Calico.IPAM = &v1alpha1.CalicoIPAM{Pools: []v1alpha1.CalicoIPPool{{CIDR: "10.233.64.0/20", BlockSize: intPtr(24)}}}
+	values := renderValues(t, network)
+	pool := values["installation"].(map[string]interface{})["calicoNetwork"].(map[string]interface{})["ipPools"].([]interface{})[0].(map[string]interface{})
+	if pool["encapsulation"] != common.CalicoIPPoolEncapsulationVXLANCrossSubnet || pool["blockSize"] != 24 || pool["natOutgoing"] != "Enabled" {
+		t.Errorf("unexpected rendered pool %v", pool)
+	}
+}
+
+func TestCalicoValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		calico  *v1alpha1.CalicoConfig
+		wantErr string
+	}{
+		{name: "defaults"},
+		{
+			name:    "both encapsulations",
+			calico:  &v1alpha1.CalicoConfig{Networking: &v1alpha1.CalicoNetworking{IPIPMode: "Always", VXLANMode: "CrossSubnet"}},
+			wantErr: "cannot both be enabled",
+		},
+		{
+			name:    "block larger than the pool",
+			calico:  &v1alpha1.CalicoConfig{IPAM: &v1alpha1.CalicoIPAM{Pools: []v1alpha1.CalicoIPPool{{CIDR: "10.233.64.0/24", BlockSize: intPtr(22)}}}},
+			wantErr: "must be between the pool prefix length 24 and 32",
+		},
+		{
+			name:    "pool outside the pod CIDR",
+			calico:  &v1alpha1.CalicoConfig{IPAM: &v1alpha1.CalicoIPAM{Pools: []v1alpha1.CalicoIPPool{{CIDR: "192.168.0.0/16"}}}},
+			wantErr: "is not within kubePodsCIDR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := NewProvider(&v1alpha1.Network{Plugin: "calico", KubePodsCIDR: "10.233.64.0/18", Calico: tt.calico})
+			err := p.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFlannelValues(t *testing.T) {
+	network := &v1alpha1.Network{Plugin: "flannel", KubePodsCIDR: "fd85:ee78:d8a6:8607::1:0000/112,10.233.64.0/18"}
+	values := renderValues(t, network)
+	if values["podCidr"] != "10.233.64.0/18" || values["podCidrv6"] != "fd85:ee78:d8a6:8607::1:0000/112" {
+		t.Errorf("expected the pod CIDRs to be split by family, got %v and %v", values["podCidr"], values["podCidrv6"])
+	}
+	image := values["image"].(map[string]interface{})
+	if image["repository"] != "registry.local/kubexm/flannel" || image["tag"] != "v1.0.0" {
+		t.Errorf("unexpected flannel image %v", image)
+	}
+
+	network.Flannel = &v1alpha1.FlannelConfig{Backend: &v1alpha1.FlannelBackendConfig{Type: common.FlannelBackendConfigTypeIpsec}}
+	p, _ := NewProvider(network)
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "does not support the IPv6 pod CIDR") {
+		t.Errorf("expected ipsec with IPv6 to be rejected, got %v", err)
+	}
+}
+
+func TestCiliumValues(t *testing.T) {
+	network := &v1alpha1.Network{
+		Plugin:       "cilium",
+		KubePodsCIDR: "10.233.64.0/18",
+		Cilium: &v1alpha1.CiliumConfig{
+			Network:   &v1alpha1.CiliumNetworkConfig{TunnelingMode: common.CiliumTunnelDisabledModes, IPAMMode: common.CiliumIPAMClusterPoolsMode},
+			KubeProxy: &v1alpha1.CiliumKubeProxyConfig{ReplacementMode: common.CiliumKPRStrictModes},
+		},
+	}
+	values := renderValues(t, network)
+	if values["routingMode"] != "native" || values["ipv4NativeRoutingCIDR"] != "10.233.64.0/18" || values["autoDirectNodeRoutes"] != true {
+		t.Errorf("expected native routing over the pod CIDR, got %v", values)
+	}
+	if _, ok := values["tunnelProtocol"]; ok {
+		t.Error("tunnelProtocol must not be set with native routing")
+	}
+	operator := values["ipam"].(map[string]interface{})["operator"].(map[string]interface{})
+	if pools := operator["clusterPoolIPv4PodCIDRList"].([]interface{}); len(pools) != 1 || pools[0] != "10.233.64.0/18" {
+		t.Errorf("unexpected cluster pool %v", operator)
+	}
+	if values["kubeProxyReplacement"] != "true" {
+		t.Errorf("expected strict mode to replace kube-proxy, got %v", values["kubeProxyReplacement"])
+	}
+	if _, ok := values["k8sServiceHost"]; ok {
+		t.Error("k8sServiceHost must not be set without an apiserver address")
+	}
+	values = renderValuesWith(t, network, Options{APIServerHost: "lb.kubexm.internal", APIServerPort: 6443})
+	if values["k8sServiceHost"] != "lb.kubexm.internal" || values["k8sServicePort"] != 6443 {
+		t.Errorf("expected the agent to reach the apiserver directly, got host %v port %v", values["k8sServiceHost"], values["k8sServicePort"])
+	}
+
+	p, _ := NewProvider(&v1alpha1.Network{Plugin: "cilium", KubePodsCIDR: "10.233.64.0/26", Cilium: network.Cilium})
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "per-node range") {
+		t.Errorf("expected a pod CIDR smaller than a node range to be rejected, got %v", err)
+	}
+	if modules := p.RequiredKernelModules(); strings.Contains(strings.Join(modules, ","), "vxlan") {
+		t.Errorf("native routing does not need the vxlan module, got %v", modules)
+	}
+}
+
+func TestNetworkAttachmentDefinitions(t *testing.T) {
+	multus := &v1alpha1.MultusConfig{NetworkAttachments: []v1alpha1.MultusNetworkAttachment{
+		{
+			Name: "macvlan-storage", Namespace: "storage", Type: "macvlan", Master: "eth1", Mode: "bridge",
+			IPAM: &v1alpha1.MultusAttachmentIPAM{Type: common.MultusIPAMTypeWhereabouts, Subnet: "192.168.10.0/24", RangeStart: "192.168.10.100"},
+		},
+		{Name: "raw", Config: `{"cniVersion": "0.3.1", "type": "bridge", "bridge": "br-o'neil"}`},
+	}}
+	out, err := NetworkAttachmentDefinitions(multus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(out)))
+	var docs []map[string]interface{}
+	for {
+		doc := map[string]interface{}{}
+		if err := decoder.Decode(&doc); err != nil {
+			break
+		}
+		if len(doc) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected two NetworkAttachmentDefinitions, got %d:\n%s", len(docs), out)
+	}
+	meta := docs[0]["metadata"].(map[string]interface{})
+	config := docs[0]["spec"].(map[string]interface{})["config"].(string)
+	if docs[0]["kind"] != "NetworkAttachmentDefinition" || meta["namespace"] != "storage" {
+		t.Errorf("unexpected attachment %v", docs[0])
+	}
+	want := `{"cniVersion":"0.3.1","ipam":{"range":"192.168.10.0/24","range_start":"192.168.10.100","type":"whereabouts"},"master":"eth1","mode":"bridge","name":"macvlan-storage","type":"macvlan"}`
+	if config != want {
+		t.Errorf("unexpected CNI config\n got: %s\nwant: %s", config, want)
+	}
+	raw := docs[1]["spec"].(map[string]interface{})["config"].(string)
+	if raw != `{"cniVersion":"0.3.1","type":"bridge","bridge":"br-o'neil"}` || docs[1]["metadata"].(map[string]interface{})["namespace"] != "default" {
+		t.Errorf("unexpected raw attachment %v", docs[1])
+	}
+
+	if out, err := NetworkAttachmentDefinitions(&v1alpha1.MultusConfig{}); err != nil || out != nil {
+		t.Errorf("expected no manifest without attachments, got %q (err %v)", out, err)
+	}
+}
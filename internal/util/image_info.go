@@ -224,7 +224,7 @@ func GetImage(ctx runtime.ExecutionContext, name string) Image {
 		"kube-apiserver":            {RepoAddr: privateRegistry, Namespace: common.DefaultKubeImageNamespace, Repo: "kube-apiserver", Tag: ctx.GetClusterConfig().Spec.Kubernetes.Version, Group: common.RoleRegistry, Enable: true},
 		"kube-controller-manager":   {RepoAddr: privateRegistry, Namespace: common.DefaultKubeImageNamespace, Repo: "kube-controller-manager", Tag: ctx.GetClusterConfig().Spec.Kubernetes.Version, Group: common.RoleMaster, Enable: true},
 		"kube-scheduler":            {RepoAddr: privateRegistry, Namespace: common.DefaultKubeImageNamespace, Repo: "kube-scheduler", Tag: ctx.GetClusterConfig().Spec.Kubernetes.Version, Group: common.RoleMaster, Enable: true},
-		"kube-proxy":                {RepoAddr: privateRegistry, Namespace: common.DefaultKubeImageNamespace, Repo: "kube-proxy", Tag: ctx.GetClusterConfig().Spec.Kubernetes.Version, Group: common.RoleKubernetes, Enable: *ctx.GetClusterConfig().Spec.Kubernetes.KubeProxy.Enable},
+		"kube-proxy":                {RepoAddr: privateRegistry, Namespace: common.DefaultKubeImageNamespace, Repo: "kube-proxy", Tag: ctx.GetClusterConfig().Spec.Kubernetes.Version, Group: common.RoleKubernetes, Enable: v1alpha1.KubeProxyEnabled(ctx.GetClusterConfig().Spec)},
 		"coredns":                   {RepoAddr: privateRegistry, Namespace: "coredns", Repo: "coredns", Tag: corednsTag, Group: common.RoleKubernetes, Enable: true},
 		"k8s-dns-node-cache":        {RepoAddr: privateRegistry, Namespace: common.DefaultKubeImageNamespace, Repo: "k8s-dns-node-cache", Tag: "1.22.20", Group: common.RoleKubernetes, Enable: *ctx.GetClusterConfig().Spec.DNS.NodeLocalDNS.Enabled},
 		"calico-kube-controllers":   {RepoAddr: privateRegistry, Namespace: "calico", Repo: "kube-controllers", Tag: common.DefaultCalicoVersion, Group: common.RoleKubernetes, Enable: strings.EqualFold(ctx.GetClusterConfig().Spec.Network.Plugin, "calico")},
@@ -38,6 +38,9 @@ var componentImageBOMs = map[string][]ImageBOM{
 	"k8s-dns-node-cache": {
 		{KubeVersionConstraints: ">= 1.22.0", RepoAddr: "registry.k8s.io", Namespace: "", Repo: "k8s-dns-node-cache", Tag: "1.22.20"},
 	},
+	"cluster-proportional-autoscaler": {
+		{KubeVersionConstraints: ">= 1.22.0", RepoAddr: "registry.k8s.io", Namespace: "cpa", Repo: "cluster-proportional-autoscaler", Tag: "v1.8.9"},
+	},
 	"conformance": {
 		{KubeVersionConstraints: ">= 1.29.0", RepoAddr: "registry.k8s.io", Namespace: "", Repo: "conformance", Tag: "v1.29.0"},
 	},
@@ -84,15 +87,32 @@ var componentImageBOMs = map[string][]ImageBOM{
 	"csi-node-driver-registrar": {ImageBOM{KubeVersionConstraints: ">= 1.25.0", RepoAddr: "registry.k8s.io", Namespace: "sig-storage", Repo: "csi-node-driver-registrar", Tag: "v2.10.1"}},
 	"csi-resizer":               {ImageBOM{KubeVersionConstraints: ">= 1.25.0", RepoAddr: "registry.k8s.io", Namespace: "sig-storage", Repo: "csi-resizer", Tag: "v1.10.1"}},
 	"csi-snapshotter":           {ImageBOM{KubeVersionConstraints: ">= 1.25.0", RepoAddr: "registry.k8s.io", Namespace: "sig-storage", Repo: "csi-snapshotter", Tag: "v7.0.2"}},
+	"local-path-provisioner":    {ImageBOM{KubeVersionConstraints: ">= 1.22.0", RepoAddr: "docker.io", Namespace: "rancher", Repo: "local-path-provisioner", Tag: "v0.0.28"}},
+	"local-path-helper":         {ImageBOM{KubeVersionConstraints: ">= 1.22.0", RepoAddr: "docker.io", Namespace: "library", Repo: "busybox", Tag: "1.36.1"}},
 
 	// --- Load Balancer & Infra ---
 	"haproxy": {ImageBOM{KubeVersionConstraints: ">= 0.0.0", RepoAddr: "docker.io", Namespace: "library", Repo: "haproxy", Tag: "2.9.7-alpine"}},
 	"nginx":   {ImageBOM{KubeVersionConstraints: ">= 0.0.0", RepoAddr: "docker.io", Namespace: "library", Repo: "nginx", Tag: "1.25.5-alpine"}},
 	"kubevip": {ImageBOM{KubeVersionConstraints: ">= 0.0.0", RepoAddr: "ghcr.io", Namespace: "kube-vip", Repo: "kube-vip", Tag: "v0.8.0"}},
 
+	// --- Local Registry ---
+	"registry": {ImageBOM{KubeVersionConstraints: ">= 0.0.0", RepoAddr: "docker.io", Namespace: "library", Repo: "registry", Tag: "2.8.3"}},
+
+	// --- Cluster verification ---
+	"busybox": {ImageBOM{KubeVersionConstraints: ">= 0.0.0", RepoAddr: "docker.io", Namespace: "library", Repo: "busybox", Tag: "1.36.1"}},
+
 	// --- Addons ---
-	"kata-deploy":            {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "quay.io", Namespace: "kata-containers", Repo: "kata-deploy", Tag: "stable"}},
-	"node-feature-discovery": {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "registry.k8s.io", Namespace: "nfd", Repo: "node-feature-discovery", Tag: "v0.15.2"}},
+	"kata-deploy":                  {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "quay.io", Namespace: "kata-containers", Repo: "kata-deploy", Tag: "stable"}},
+	"node-feature-discovery":       {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "registry.k8s.io", Namespace: "nfd", Repo: "node-feature-discovery", Tag: "v0.15.2"}},
+	"cert-manager-controller":      {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "quay.io", Namespace: "jetstack", Repo: "cert-manager-controller", Tag: "v1.14.5"}},
+	"cert-manager-cainjector":      {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "quay.io", Namespace: "jetstack", Repo: "cert-manager-cainjector", Tag: "v1.14.5"}},
+	"cert-manager-webhook":         {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "quay.io", Namespace: "jetstack", Repo: "cert-manager-webhook", Tag: "v1.14.5"}},
+	"cert-manager-startupapicheck": {ImageBOM{KubeVersionConstraints: ">= 1.24.0", RepoAddr: "quay.io", Namespace: "jetstack", Repo: "cert-manager-startupapicheck", Tag: "v1.14.5"}},
+	"dashboard-api":                {ImageBOM{KubeVersionConstraints: ">= 1.21.0", RepoAddr: "docker.io", Namespace: "kubernetesui", Repo: "dashboard-api", Tag: "1.7.0"}},
+	"dashboard-auth":               {ImageBOM{KubeVersionConstraints: ">= 1.21.0", RepoAddr: "docker.io", Namespace: "kubernetesui", Repo: "dashboard-auth", Tag: "1.1.3"}},
+	"dashboard-web":                {ImageBOM{KubeVersionConstraints: ">= 1.21.0", RepoAddr: "docker.io", Namespace: "kubernetesui", Repo: "dashboard-web", Tag: "1.4.0"}},
+	"dashboard-metrics-scraper":    {ImageBOM{KubeVersionConstraints: ">= 1.21.0", RepoAddr: "docker.io", Namespace: "kubernetesui", Repo: "dashboard-metrics-scraper", Tag: "1.1.1"}},
+	"dashboard-kong":               {ImageBOM{KubeVersionConstraints: ">= 1.21.0", RepoAddr: "docker.io", Namespace: "library", Repo: "kong", Tag: "3.6"}},
 }
 
 // getImageBOM 是一个内部函数，用于从BOM中获取最匹配的镜像信息。
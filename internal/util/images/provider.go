@@ -1,9 +1,12 @@
 package images
 
 import (
+	"slices"
 	"strings"
 
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/remotefw"
 	"github.com/mensylisir/kubexm/internal/runtime"
 )
 
@@ -57,6 +60,37 @@ func (p *ImageProvider) GetImages() []*Image {
 	return enabledImages
 }
 
+// controlPlaneOnlyImages 是只运行在控制平面节点上的静态 Pod 镜像。
+var controlPlaneOnlyImages = map[string]bool{
+	"kube-apiserver":          true,
+	"kube-controller-manager": true,
+	"kube-scheduler":          true,
+	"etcd":                    true,
+}
+
+// LocalRegistryImageName 是本地镜像仓库自身的镜像。它由部署本地仓库的步骤单独加载，
+// 不能从它自己提供的仓库中拉取，所以不参与预加载。
+const LocalRegistryImageName = "registry"
+
+// GetImagesForHost 返回指定主机需要预加载的镜像列表。
+// 工作节点不需要控制平面的静态 Pod 镜像，其余镜像所有节点都需要。
+func (p *ImageProvider) GetImagesForHost(host remotefw.Host) []*Image {
+	isControlPlane := host.IsRole(common.RoleMaster) || host.IsRole(common.RoleControlPlane)
+	var hostImages []*Image
+	for _, name := range p.getManagedImageNames() {
+		if controlPlaneOnlyImages[name] && !isControlPlane {
+			continue
+		}
+		if name == LocalRegistryImageName {
+			continue
+		}
+		if image := p.GetImage(name); image != nil {
+			hostImages = append(hostImages, image)
+		}
+	}
+	return hostImages
+}
+
 // getManagedImageNames 返回BOM中管理的所有镜像组件名称列表。
 func (p *ImageProvider) getManagedImageNames() []string {
 	// 从 componentImageBOMs 的 keys 动态生成
@@ -73,14 +107,24 @@ func (p *ImageProvider) getManagedImageNames() []string {
 func (p *ImageProvider) isImageEnabled(name string) bool {
 	cfg := p.ctx.GetClusterConfig().Spec
 
+	// 由 spec.addons 启用的插件镜像
+	for addon, images := range common.AddonImages {
+		if addonEnabled(cfg.Addons, addon) && slices.Contains(images, name) {
+			return true
+		}
+	}
+
 	// 具体的启用/禁用判断
 	switch name {
 	case "etcd":
 		return strings.EqualFold(cfg.Etcd.Type, string(common.EtcdDeploymentTypeKubeadm))
 	case "kube-proxy":
-		return cfg.Kubernetes.KubeProxy.Enable == nil || *cfg.Kubernetes.KubeProxy.Enable
+		return v1alpha1.KubeProxyEnabled(cfg)
 	case "k8s-dns-node-cache":
 		return cfg.DNS.NodeLocalDNS != nil && cfg.DNS.NodeLocalDNS.Enabled != nil && *cfg.DNS.NodeLocalDNS.Enabled
+	case "cluster-proportional-autoscaler":
+		return cfg.DNS != nil && cfg.DNS.CoreDNS != nil && cfg.DNS.CoreDNS.Autoscaler != nil &&
+			cfg.DNS.CoreDNS.Autoscaler.Enabled != nil && *cfg.DNS.CoreDNS.Autoscaler.Enabled
 
 	// CNI Images
 	case "tigera-operator", "calico-cni", "calico-node", "calico-kube-controllers", "calico-apiserver", "calico-typha", "calico-flexvol", "calico-key-cert-provisioner", "calico-dikastes", "calico-envoy-gateway", "calico-envoy-proxy", "calico-envoy-ratelimit", "calico-goldmane", "calico-whisker", "calico-whisker-backend":
@@ -110,6 +154,15 @@ func (p *ImageProvider) isImageEnabled(name string) bool {
 	case "kubevip":
 		return cfg.ControlPlaneEndpoint.ExternalLoadBalancerType == common.ExternalLBTypeKubeVIP
 
+	case "local-path-provisioner", "local-path-helper", "cert-manager-controller", "cert-manager-cainjector", "cert-manager-webhook", "cert-manager-startupapicheck",
+		"dashboard-api", "dashboard-auth", "dashboard-web", "dashboard-metrics-scraper", "dashboard-kong":
+		return false
+
+	// Local Registry Image
+	case LocalRegistryImageName:
+		return cfg.Registry != nil && cfg.Registry.Type == common.RegistryDeployTypeLocal &&
+			cfg.Registry.LocalDeployment != nil && cfg.Registry.LocalDeployment.Type == common.RegistryTypeDockerRegistry
+
 	// Addon Images
 	case "kata-deploy":
 		return cfg.Kubernetes.Addons.Kata != nil && cfg.Kubernetes.Addons.Kata.Enabled != nil && *cfg.Kubernetes.Addons.Kata.Enabled
@@ -121,3 +174,13 @@ func (p *ImageProvider) isImageEnabled(name string) bool {
 		return true
 	}
 }
+
+// addonEnabled reports whether the named addon is listed in spec.addons and not disabled.
+func addonEnabled(addons []v1alpha1.Addon, name string) bool {
+	for _, addon := range addons {
+		if addon.Name == name {
+			return addon.Enabled == nil || *addon.Enabled
+		}
+	}
+	return false
+}
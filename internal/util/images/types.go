@@ -3,6 +3,7 @@ package images
 import (
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
 )
@@ -97,6 +98,23 @@ func (i *Image) OriginalFullName() string {
 	return path.Join(i.OriginalRepoAddr, i.OriginalNamespace, repoAndTag)
 }
 
+// RuntimeName 返回节点容器运行时中应当存在的镜像名称。
+// 配置了私有仓库时为 FullName，否则为 OriginalFullName，因为此时 FullName 不含仓库地址。
+func (i *Image) RuntimeName() string {
+	if i.privateRepoAddr == "" {
+		return i.OriginalFullName()
+	}
+	return i.FullName()
+}
+
+// ArchiveFileName 返回镜像在离线归档目录中的 tar 文件名，
+// e.g., "quay.io_calico_node_v3.28.0-amd64.tar"
+func ArchiveFileName(img *Image, arch string) string {
+	return archiveNameReplacer.Replace(img.OriginalFullName()) + "-" + arch + ".tar"
+}
+
+var archiveNameReplacer = strings.NewReplacer("/", "_", ":", "_", "@", "_")
+
 // --- 私有辅助方法 ---
 
 // finalNamespace 计算应用重写规则后的最终命名空间。
@@ -0,0 +1,25 @@
+package images
+
+import (
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+)
+
+func TestImageRuntimeNameAndArchive(t *testing.T) {
+	bom := &ImageBOM{RepoAddr: "quay.io", Namespace: "calico", Repo: "node", Tag: "v3.28.0"}
+
+	upstream := newImage(bom, &v1alpha1.RegistryMirroringAndRewriting{})
+	if got := upstream.RuntimeName(); got != "quay.io/calico/node:v3.28.0" {
+		t.Errorf("without a private registry expected the original name, got %q", got)
+	}
+
+	private := newImage(bom, &v1alpha1.RegistryMirroringAndRewriting{PrivateRegistry: "harbor.local", NamespaceOverride: "mirror"})
+	if got := private.RuntimeName(); got != "harbor.local/mirror/node:v3.28.0" {
+		t.Errorf("with a private registry expected the rewritten name, got %q", got)
+	}
+
+	if got := ArchiveFileName(private, "arm64"); got != "quay.io_calico_node_v3.28.0-arm64.tar" {
+		t.Errorf("unexpected archive file name %q", got)
+	}
+}
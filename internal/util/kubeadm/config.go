@@ -0,0 +1,659 @@
+package kubeadm
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"github.com/mensylisir/kubexm/internal/step/helpers"
+	"github.com/mensylisir/kubexm/internal/templates"
+)
+
+const fileHeader = "# Generated by kubexm. DO NOT EDIT.\n"
+
+const defaultComponentFeatureGates = "RotateKubeletServerCertificate=true,ExpandCSIVolumes=true,CSIStorageCapacity=true"
+
+// Options carries the values a ClusterSpec does not hold itself. Image names are resolved through the
+// image BOM, which needs an execution context, so callers look them up and pass them in.
+type Options struct {
+	ImageRepository string
+	CoreDNSImageTag string
+}
+
+// JoinOptions carries the credentials a node joins the cluster with.
+type JoinOptions struct {
+	Token string
+	// CertificateKey decrypts the certificates kubeadm init uploaded; only used when ControlPlane is set.
+	CertificateKey string
+	ControlPlane   bool
+}
+
+// Arg is a component flag. v1beta3 renders extra args as a map, v1beta4 as a list of name/value pairs.
+type Arg struct {
+	Name  string
+	Value string
+}
+
+type Volume struct {
+	Name      string
+	HostPath  string
+	MountPath string
+	ReadOnly  bool
+}
+
+type etcdData struct {
+	IsExternal bool
+	Endpoints  []string
+	CaFile     string
+	CertFile   string
+	KeyFile    string
+}
+
+type apiServerData struct {
+	ExtraArgs    []Arg
+	CertSANs     []string
+	ExtraVolumes []Volume
+}
+
+type componentData struct {
+	ExtraArgs []Arg
+}
+
+type nodeRegistrationData struct {
+	CRISocket        string
+	KubeletExtraArgs []Arg
+}
+
+type clusterConfigurationData struct {
+	APIVersion           string
+	ArgsAsList           bool
+	KubernetesVersion    string
+	ClusterName          string
+	ImageRepository      string
+	DNSImageTag          string
+	CertificatesDir      string
+	ControlPlaneEndpoint string
+	PodSubnet            string
+	ServiceSubnet        string
+	Etcd                 etcdData
+	APIServer            apiServerData
+	ControllerManager    componentData
+	Scheduler            componentData
+}
+
+type initConfigurationData struct {
+	APIVersion       string
+	ArgsAsList       bool
+	AdvertiseAddress string
+	BindPort         int
+	NodeRegistration nodeRegistrationData
+	SkipPhases       []string
+}
+
+type joinConfigurationData struct {
+	APIVersion        string
+	ArgsAsList        bool
+	APIServerEndpoint string
+	Token             string
+	ControlPlane      bool
+	AdvertiseAddress  string
+	BindPort          int
+	CertificateKey    string
+	NodeRegistration  nodeRegistrationData
+}
+
+type kubeletConfigurationData struct {
+	APIVersion                       string
+	CgroupDriver                     string
+	ClusterDNS                       string
+	ClusterDomain                    string
+	ContainerLogMaxSize              string
+	EvictionPressureTransitionPeriod string
+	ContainerLogMaxFiles             int
+	EvictionMaxPodGracePeriod        int
+	MaxPods                          int
+	PodPidsLimit                     int64
+	RotateCertificates               bool
+	SerializeImagePulls              bool
+	EvictionHard                     map[string]string
+	EvictionSoft                     map[string]string
+	EvictionSoftGracePeriod          map[string]string
+	KubeReserved                     map[string]string
+	SystemReserved                   map[string]string
+	FeatureGates                     map[string]bool
+}
+
+type kubeProxyConfigurationData struct {
+	APIVersion    string
+	Mode          string
+	ClusterCIDR   string
+	MasqueradeAll bool
+	MasqueradeBit int
+	MinSyncPeriod string
+	SyncPeriod    string
+	IPVSScheduler string
+	ExcludeCIDRs  []string
+}
+
+// Generator renders the kubeadm configuration documents of a cluster. The kubeadm API version is
+// picked once from the cluster's Kubernetes version; the kubelet and kube-proxy documents keep their
+// own API groups, which did not change between those kubeadm versions.
+type Generator struct {
+	spec              *v1alpha1.ClusterSpec
+	opts              Options
+	kubernetesVersion string
+	apiVersion        string
+}
+
+func NewGenerator(spec *v1alpha1.ClusterSpec, opts Options) (*Generator, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("cluster spec cannot be nil")
+	}
+	kubernetesVersion := common.DefaultK8sVersion
+	if spec.Kubernetes != nil && spec.Kubernetes.Version != "" {
+		kubernetesVersion = spec.Kubernetes.Version
+	}
+	apiVersion, err := APIVersionFor(kubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{spec: spec, opts: opts, kubernetesVersion: kubernetesVersion, apiVersion: apiVersion}, nil
+}
+
+// APIVersion returns the kubeadm API version the generator renders, e.g. kubeadm.k8s.io/v1beta4.
+func (g *Generator) APIVersion() string {
+	return g.apiVersion
+}
+
+// InitConfigFile renders the file passed to `kubeadm init --config` on nodeName: the cluster, init,
+// kube-proxy and kubelet documents. The kube-proxy document is left out when kube-proxy is not deployed.
+func (g *Generator) InitConfigFile(nodeName string) ([]byte, error) {
+	clusterCfg, err := g.ClusterConfiguration(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	initCfg, err := g.InitConfiguration(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	docs := [][]byte{clusterCfg, initCfg}
+	if v1alpha1.KubeProxyEnabled(g.spec) {
+		proxyCfg, err := g.KubeProxyConfiguration()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, proxyCfg)
+	}
+	kubeletCfg, err := g.KubeletConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	return configFile(append(docs, kubeletCfg)...), nil
+}
+
+// JoinConfigFile renders the file passed to `kubeadm join --config` on nodeName.
+func (g *Generator) JoinConfigFile(nodeName string, join JoinOptions) ([]byte, error) {
+	joinCfg, err := g.JoinConfiguration(nodeName, join)
+	if err != nil {
+		return nil, err
+	}
+	return configFile(joinCfg), nil
+}
+
+func configFile(docs ...[]byte) []byte {
+	var b strings.Builder
+	b.WriteString(fileHeader)
+	for _, doc := range docs {
+		b.WriteString("---\n")
+		b.WriteString(strings.TrimRight(string(doc), "\n"))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// ClusterConfiguration renders the ClusterConfiguration document. nodeName selects the etcd client
+// certificate when etcd is deployed by kubexm.
+func (g *Generator) ClusterConfiguration(nodeName string) ([]byte, error) {
+	k8sSpec := g.kubernetes()
+	data := clusterConfigurationData{
+		APIVersion:        g.apiVersion,
+		ArgsAsList:        g.argsAsList(),
+		KubernetesVersion: g.kubernetesVersion,
+		ClusterName:       g.clusterName(),
+		ImageRepository:   g.opts.ImageRepository,
+		DNSImageTag:       g.opts.CoreDNSImageTag,
+		CertificatesDir:   common.DefaultKubernetesPKIDir,
+		PodSubnet:         g.podSubnet(),
+		ServiceSubnet:     g.serviceSubnet(),
+	}
+	if cp := g.spec.ControlPlaneEndpoint; cp != nil {
+		data.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", cp.Domain, cp.Port)
+	}
+
+	etcdSpec := &v1alpha1.Etcd{}
+	if g.spec.Etcd != nil {
+		etcdSpec = g.spec.Etcd
+	}
+	if etcdSpec.Type == string(common.EtcdDeploymentTypeExternal) && etcdSpec.External != nil {
+		// The certificates are distributed below /etc/kubernetes/pki, which kubeadm already mounts into the apiserver pod.
+		caFile, certFile, keyFile := etcdSpec.External.RemoteCertPaths()
+		data.Etcd = etcdData{IsExternal: true, Endpoints: etcdSpec.External.Endpoints, CaFile: caFile, CertFile: certFile, KeyFile: keyFile}
+	} else if etcdSpec.Type == string(common.EtcdDeploymentTypeKubexm) {
+		var endpoints []string
+		for _, host := range g.hostsInGroup(g.roleGroups().Etcd) {
+			address := strings.Split(internalAddress(host), ",")[0]
+			endpoints = append(endpoints, "https://"+net.JoinHostPort(address, strconv.Itoa(common.EtcdDefaultClientPort)))
+		}
+		data.Etcd = etcdData{
+			IsExternal: true,
+			Endpoints:  endpoints,
+			CaFile:     filepath.Join(common.DefaultEtcdPKIDir, common.EtcdCaPemFileName),
+			CertFile:   filepath.Join(common.DefaultEtcdPKIDir, fmt.Sprintf(common.EtcdNodeCertFileNamePattern, nodeName)),
+			KeyFile:    filepath.Join(common.DefaultEtcdPKIDir, fmt.Sprintf(common.EtcdNodeKeyFileNamePattern, nodeName)),
+		}
+		// The apiserver pod reads the etcd client certificates from the host, so mount their directory.
+		data.APIServer.ExtraVolumes = []Volume{{
+			Name:      "etcd-certs",
+			HostPath:  common.DefaultEtcdPKIDir,
+			MountPath: common.DefaultEtcdPKIDir,
+			ReadOnly:  true,
+		}}
+	}
+
+	apiServerSpec := &v1alpha1.APIServerConfig{}
+	if k8sSpec.APIServer != nil {
+		apiServerSpec = k8sSpec.APIServer
+	}
+	apiServerArgs := helpers.MergeStringMaps(map[string]string{
+		"audit-log-maxage":    "30",
+		"audit-log-maxbackup": "10",
+		"audit-log-maxsize":   "100",
+		"audit-log-path":      common.DefaultAuditLogFile,
+		"bind-address":        "0.0.0.0",
+	}, apiServerSpec.ExtraArgs)
+	if audit := apiServerSpec.AuditConfig; audit != nil && audit.Enabled != nil && *audit.Enabled {
+		if audit.LogPath != "" {
+			apiServerArgs["audit-log-path"] = audit.LogPath
+		}
+		if audit.MaxAge != nil {
+			apiServerArgs["audit-log-maxage"] = strconv.Itoa(*audit.MaxAge)
+		}
+		if audit.MaxBackups != nil {
+			apiServerArgs["audit-log-maxbackup"] = strconv.Itoa(*audit.MaxBackups)
+		}
+		if audit.MaxSize != nil {
+			apiServerArgs["audit-log-maxsize"] = strconv.Itoa(*audit.MaxSize)
+		}
+		if audit.PolicyFile != "" {
+			apiServerArgs["audit-policy-file"] = audit.PolicyFile
+		}
+	}
+	apiServerArgs["feature-gates"] = mergeFeatureGates(defaultComponentFeatureGates, apiServerSpec.FeatureGates)
+	data.APIServer.ExtraArgs = sortedArgs(apiServerArgs)
+
+	sans := []string{"kubernetes", "kubernetes.default", "kubernetes.default.svc", "127.0.0.1", "localhost"}
+	if cp := g.spec.ControlPlaneEndpoint; cp != nil {
+		sans = append(sans, cp.Domain)
+	}
+	sans = append(sans, fmt.Sprintf("kubernetes.default.svc.%s", data.ClusterName))
+	kubernetesServiceIP, _ := helpers.GetFirstIPFromCIDR(data.ServiceSubnet)
+	sans = append(sans, kubernetesServiceIP)
+	for _, host := range g.spec.Hosts {
+		sans = append(sans, strings.Split(internalAddress(host), ",")...)
+		sans = append(sans, host.Name)
+	}
+	sans = append(sans, apiServerSpec.CertExtraSans...)
+	data.APIServer.CertSANs = helpers.UniqueStringSlice(sans)
+
+	controllerManagerSpec := &v1alpha1.ControllerManagerConfig{}
+	if k8sSpec.ControllerManager != nil {
+		controllerManagerSpec = k8sSpec.ControllerManager
+	}
+	controllerManagerArgs := helpers.MergeStringMaps(map[string]string{
+		"node-cidr-mask-size":      "24",
+		"bind-address":             "0.0.0.0",
+		"cluster-signing-duration": "87600h",
+	}, controllerManagerSpec.ExtraArgs)
+	if controllerManagerSpec.NodeCidrMaskSize != nil {
+		controllerManagerArgs["node-cidr-mask-size"] = strconv.Itoa(*controllerManagerSpec.NodeCidrMaskSize)
+	}
+	if helpers.IsDualStackCIDR(data.PodSubnet) {
+		// node-cidr-mask-size only applies to single-stack clusters; dual-stack needs a mask per family.
+		if _, ok := controllerManagerSpec.ExtraArgs["node-cidr-mask-size-ipv4"]; !ok {
+			controllerManagerArgs["node-cidr-mask-size-ipv4"] = controllerManagerArgs["node-cidr-mask-size"]
+		}
+		if _, ok := controllerManagerSpec.ExtraArgs["node-cidr-mask-size-ipv6"]; !ok {
+			controllerManagerArgs["node-cidr-mask-size-ipv6"] = "64"
+			if controllerManagerSpec.NodeCidrMaskSizeIPv6 != nil {
+				controllerManagerArgs["node-cidr-mask-size-ipv6"] = strconv.Itoa(*controllerManagerSpec.NodeCidrMaskSizeIPv6)
+			}
+		}
+		delete(controllerManagerArgs, "node-cidr-mask-size")
+	}
+	controllerManagerArgs["feature-gates"] = mergeFeatureGates(defaultComponentFeatureGates, controllerManagerSpec.FeatureGates)
+	data.ControllerManager.ExtraArgs = sortedArgs(controllerManagerArgs)
+
+	schedulerSpec := &v1alpha1.SchedulerConfig{}
+	if k8sSpec.Scheduler != nil {
+		schedulerSpec = k8sSpec.Scheduler
+	}
+	schedulerArgs := helpers.MergeStringMaps(map[string]string{"bind-address": "0.0.0.0"}, schedulerSpec.ExtraArgs)
+	schedulerArgs["feature-gates"] = mergeFeatureGates(defaultComponentFeatureGates, schedulerSpec.FeatureGates)
+	data.Scheduler.ExtraArgs = sortedArgs(schedulerArgs)
+
+	return render("cluster-configuration.tmpl", data)
+}
+
+// InitConfiguration renders the InitConfiguration document for the first control plane node.
+func (g *Generator) InitConfiguration(nodeName string) ([]byte, error) {
+	host, err := g.host(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	nodeIPs := g.nodeIPs(host)
+	data := initConfigurationData{
+		APIVersion:       g.apiVersion,
+		ArgsAsList:       g.argsAsList(),
+		AdvertiseAddress: nodeIPs[0],
+		BindPort:         common.DefaultAPIServerPort,
+		NodeRegistration: g.nodeRegistration(host, nodeIPs),
+	}
+	if !v1alpha1.KubeProxyEnabled(g.spec) {
+		data.SkipPhases = []string{common.KubeadmPhaseAddonKubeProxy}
+	}
+	return render("init-configuration.tmpl", data)
+}
+
+// JoinConfiguration renders the JoinConfiguration document for a worker, or for an additional control
+// plane node when join.ControlPlane is set.
+func (g *Generator) JoinConfiguration(nodeName string, join JoinOptions) ([]byte, error) {
+	host, err := g.host(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if join.Token == "" {
+		return nil, fmt.Errorf("bootstrap token is required to join node %s", nodeName)
+	}
+	if join.ControlPlane && join.CertificateKey == "" {
+		return nil, fmt.Errorf("certificate key is required to join control plane node %s", nodeName)
+	}
+	cp := g.spec.ControlPlaneEndpoint
+	if cp == nil {
+		return nil, fmt.Errorf("controlPlaneEndpoint is required to join node %s", nodeName)
+	}
+
+	nodeIPs := g.nodeIPs(host)
+	data := joinConfigurationData{
+		APIVersion:        g.apiVersion,
+		ArgsAsList:        g.argsAsList(),
+		APIServerEndpoint: fmt.Sprintf("%s:%d", cp.Domain, cp.Port),
+		Token:             join.Token,
+		NodeRegistration:  g.nodeRegistration(host, nodeIPs),
+	}
+	if join.ControlPlane {
+		data.ControlPlane = true
+		data.AdvertiseAddress = nodeIPs[0]
+		data.BindPort = common.DefaultAPIServerPort
+		data.CertificateKey = join.CertificateKey
+	}
+	return render("join-configuration.tmpl", data)
+}
+
+// KubeletExtraArgs returns the kubeletExtraArgs of nodeName's nodeRegistration, which kubeadm writes
+// to kubeadm-flags.env. Hosts whose kubelet does not read that file pass them on the command line.
+func (g *Generator) KubeletExtraArgs(nodeName string) ([]Arg, error) {
+	host, err := g.host(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return g.nodeRegistration(host, g.nodeIPs(host)).KubeletExtraArgs, nil
+}
+
+// KubeletConfiguration renders the KubeletConfiguration document shared by all nodes.
+func (g *Generator) KubeletConfiguration() ([]byte, error) {
+	kubeletSpec := &v1alpha1.KubeletConfig{}
+	if g.kubernetes().Kubelet != nil {
+		kubeletSpec = g.kubernetes().Kubelet
+	}
+	data := kubeletConfigurationData{
+		APIVersion:                       common.KubeletConfigAPIVersion,
+		CgroupDriver:                     g.cgroupDriver(),
+		ClusterDomain:                    g.clusterName(),
+		MaxPods:                          110,
+		PodPidsLimit:                     10000,
+		ContainerLogMaxFiles:             3,
+		ContainerLogMaxSize:              helpers.FirstNonEmpty(kubeletSpec.ContainerLogMaxSize, "5Mi"),
+		EvictionPressureTransitionPeriod: helpers.FirstNonEmpty(kubeletSpec.EvictionPressureTransitionPeriod, "30s"),
+		EvictionMaxPodGracePeriod:        120,
+		RotateCertificates:               true,
+		SerializeImagePulls:              true,
+		EvictionHard:                     helpers.MergeStringMaps(map[string]string{"memory.available": "5%", "pid.available": "10%"}, kubeletSpec.EvictionHard),
+		EvictionSoft:                     helpers.MergeStringMaps(map[string]string{"memory.available": "10%"}, kubeletSpec.EvictionSoft),
+		EvictionSoftGracePeriod:          helpers.MergeStringMaps(map[string]string{"memory.available": "2m"}, kubeletSpec.EvictionSoftGracePeriod),
+		KubeReserved:                     helpers.MergeStringMaps(map[string]string{"cpu": "200m", "memory": "250Mi"}, kubeletSpec.KubeReserved),
+		SystemReserved:                   kubeletSpec.SystemReserved,
+		FeatureGates:                     helpers.MergeBoolMaps(map[string]bool{"CSIStorageCapacity": true, "ExpandCSIVolumes": true, "RotateKubeletServerCertificate": true}, kubeletSpec.FeatureGates),
+	}
+	if kubeletSpec.MaxPods != nil {
+		data.MaxPods = *kubeletSpec.MaxPods
+	}
+	if kubeletSpec.PodPidsLimit != nil {
+		data.PodPidsLimit = int64(*kubeletSpec.PodPidsLimit)
+	}
+	if kubeletSpec.ContainerLogMaxFiles != nil {
+		data.ContainerLogMaxFiles = *kubeletSpec.ContainerLogMaxFiles
+	}
+	if kubeletSpec.EvictionMaxPodGracePeriod != nil {
+		data.EvictionMaxPodGracePeriod = *kubeletSpec.EvictionMaxPodGracePeriod
+	}
+
+	if localDNS := v1alpha1.NodeLocalDNSIP(g.spec); localDNS != "" {
+		data.ClusterDNS = localDNS
+	} else {
+		dnsIP, err := helpers.GetDNSIPFromCIDR(g.serviceSubnet())
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate DNS IP from service subnet: %w", err)
+		}
+		data.ClusterDNS = dnsIP
+	}
+	return render("kubelet-configuration.tmpl", data)
+}
+
+// KubeProxyConfiguration renders the KubeProxyConfiguration document.
+func (g *Generator) KubeProxyConfiguration() ([]byte, error) {
+	proxySpec := &v1alpha1.KubeProxyConfig{}
+	if g.kubernetes().KubeProxy != nil {
+		proxySpec = g.kubernetes().KubeProxy
+	}
+	data := kubeProxyConfigurationData{
+		APIVersion:    common.KubeProxyConfigAPIVersion,
+		Mode:          helpers.FirstNonEmpty(proxySpec.Mode, common.KubeProxyModeIPVS),
+		ClusterCIDR:   g.podSubnet(),
+		MasqueradeBit: 14,
+		MinSyncPeriod: "0s",
+		SyncPeriod:    "30s",
+	}
+	if proxySpec.MasqueradeAll != nil {
+		data.MasqueradeAll = *proxySpec.MasqueradeAll
+	}
+	if data.Mode == common.KubeProxyModeIPVS {
+		data.IPVSScheduler = common.DefaultIPVSScheduler
+		if ipvs := proxySpec.IPVS; ipvs != nil {
+			data.IPVSScheduler = helpers.FirstNonEmpty(ipvs.Scheduler, common.DefaultIPVSScheduler)
+			data.ExcludeCIDRs = ipvs.ExcludeCIDRs
+		}
+	}
+	return render("kube-proxy-configuration.tmpl", data)
+}
+
+func render(templateName string, data interface{}) ([]byte, error) {
+	templateContent, err := templates.Get("kubernetes/kubeadm/" + templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeadm template %s: %w", templateName, err)
+	}
+	rendered, err := templates.Render(templateContent, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render kubeadm template %s: %w", templateName, err)
+	}
+	return []byte(rendered), nil
+}
+
+func (g *Generator) argsAsList() bool {
+	return g.apiVersion != common.KubeadmAPIVersionV1Beta3
+}
+
+func (g *Generator) kubernetes() *v1alpha1.Kubernetes {
+	if g.spec.Kubernetes == nil {
+		return &v1alpha1.Kubernetes{}
+	}
+	return g.spec.Kubernetes
+}
+
+func (g *Generator) roleGroups() *v1alpha1.RoleGroupsSpec {
+	if g.spec.RoleGroups == nil {
+		return &v1alpha1.RoleGroupsSpec{}
+	}
+	return g.spec.RoleGroups
+}
+
+func (g *Generator) clusterName() string {
+	return helpers.FirstNonEmpty(g.kubernetes().ClusterName, common.DefaultClusterLocal)
+}
+
+func (g *Generator) podSubnet() string {
+	if g.spec.Network == nil {
+		return common.DefaultKubePodsCIDR
+	}
+	return helpers.FirstNonEmpty(g.spec.Network.KubePodsCIDR, common.DefaultKubePodsCIDR)
+}
+
+func (g *Generator) serviceSubnet() string {
+	if g.spec.Network == nil {
+		return common.DefaultKubeServiceCIDR
+	}
+	return helpers.FirstNonEmpty(g.spec.Network.KubeServiceCIDR, common.DefaultKubeServiceCIDR)
+}
+
+func (g *Generator) host(name string) (v1alpha1.HostSpec, error) {
+	for _, host := range g.spec.Hosts {
+		if host.Name == name {
+			return host, nil
+		}
+	}
+	return v1alpha1.HostSpec{}, fmt.Errorf("host %s is not defined in the cluster spec", name)
+}
+
+// hostsInGroup returns the hosts named in a role group, in the group's order.
+func (g *Generator) hostsInGroup(names []string) []v1alpha1.HostSpec {
+	var hosts []v1alpha1.HostSpec
+	for _, name := range names {
+		if host, err := g.host(name); err == nil {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// nodeIPs returns the addresses of host that match the cluster's IP families, primary family first.
+func (g *Generator) nodeIPs(host v1alpha1.HostSpec) []string {
+	return strings.Split(helpers.NodeIPsForCIDRs(internalAddress(host), g.podSubnet()), ",")
+}
+
+func internalAddress(host v1alpha1.HostSpec) string {
+	return helpers.FirstNonEmpty(host.InternalAddress, host.Address)
+}
+
+// nodeRegistration returns the CRI socket of the configured runtime and the kubelet flags. Dual-stack
+// nodes get one node-ip per family; single-stack nodes keep the address the kubelet detects itself.
+// Windows nodes run containerd on its named pipe and have no cgroups to pick a driver for.
+func (g *Generator) nodeRegistration(host v1alpha1.HostSpec, nodeIPs []string) nodeRegistrationData {
+	criSocket := g.criSocket()
+	args := map[string]string{"cgroup-driver": g.cgroupDriver()}
+	if host.OS == common.OSWindows {
+		criSocket = common.ContainerdWindowsEndpoint
+		args = map[string]string{}
+	}
+	if len(nodeIPs) > 1 {
+		args["node-ip"] = strings.Join(nodeIPs, ",")
+	}
+	if kubelet := g.kubernetes().Kubelet; kubelet != nil {
+		args = helpers.MergeStringMaps(args, kubelet.ExtraArgs)
+	}
+	return nodeRegistrationData{CRISocket: criSocket, KubeletExtraArgs: sortedArgs(args)}
+}
+
+func (g *Generator) criSocket() string {
+	crSpec := g.kubernetes().ContainerRuntime
+	if crSpec == nil {
+		return common.ContainerdDefaultEndpoint
+	}
+	switch crSpec.Type {
+	case common.RuntimeTypeCRIO:
+		return common.CRIODefaultEndpoint
+	case common.RuntimeTypeDocker:
+		return common.CriDockerdSocketPath
+	case common.RuntimeTypeIsula:
+		return common.IsuladDefaultEndpoint
+	}
+	return common.ContainerdDefaultEndpoint
+}
+
+func (g *Generator) cgroupDriver() string {
+	var driver *string
+	if crSpec := g.kubernetes().ContainerRuntime; crSpec != nil {
+		switch crSpec.Type {
+		case common.RuntimeTypeContainerd:
+			if crSpec.Containerd != nil {
+				driver = crSpec.Containerd.CgroupDriver
+			}
+		case common.RuntimeTypeCRIO:
+			if crSpec.Crio != nil {
+				driver = crSpec.Crio.CgroupDriver
+			}
+		case common.RuntimeTypeDocker:
+			if crSpec.Docker != nil {
+				driver = crSpec.Docker.CgroupDriver
+			}
+		case common.RuntimeTypeIsula:
+			if crSpec.Isulad != nil {
+				driver = crSpec.Isulad.CgroupDriver
+			}
+		}
+	}
+	if driver == nil || *driver == "" {
+		return common.CgroupDriverSystemd
+	}
+	return *driver
+}
+
+func mergeFeatureGates(defaults string, overrides map[string]bool) string {
+	gates := make(map[string]string)
+	for _, pair := range strings.Split(defaults, ",") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 && kv[0] != "" {
+			gates[kv[0]] = kv[1]
+		}
+	}
+	for k, v := range overrides {
+		gates[k] = strconv.FormatBool(v)
+	}
+	result := make([]string, 0, len(gates))
+	for k, v := range gates {
+		result = append(result, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
+func sortedArgs(args map[string]string) []Arg {
+	list := make([]Arg, 0, len(args))
+	for name, value := range args {
+		list = append(list, Arg{Name: name, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,375 @@
+package kubeadm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mensylisir/kubexm/internal/apis/kubexms/v1alpha1"
+	"github.com/mensylisir/kubexm/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAPIVersionFor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "v1.22.0", want: common.KubeadmAPIVersionV1Beta3},
+		{version: "v1.28.2", want: common.KubeadmAPIVersionV1Beta3},
+		{version: "1.30.9", want: common.KubeadmAPIVersionV1Beta3},
+		{version: "v1.31.0-rc.1", want: common.KubeadmAPIVersionV1Beta4},
+		{version: "v1.31.0", want: common.KubeadmAPIVersionV1Beta4},
+		{version: "v1.33.1", want: common.KubeadmAPIVersionV1Beta4},
+		{version: "v1.21.14", wantErr: true},
+		{version: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := APIVersionFor(tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func newTestSpec(version string) *v1alpha1.ClusterSpec {
+	cluster := &v1alpha1.Cluster{Spec: &v1alpha1.ClusterSpec{
+		Hosts: []v1alpha1.HostSpec{
+			{Name: "master1", Address: "192.168.1.10", InternalAddress: "192.168.1.10"},
+			{Name: "master2", Address: "192.168.1.11", InternalAddress: "192.168.1.11"},
+			{Name: "worker1", Address: "192.168.1.20", InternalAddress: "192.168.1.20"},
+		},
+		RoleGroups: &v1alpha1.RoleGroupsSpec{
+			Master: []string{"master1", "master2"},
+			Worker: []string{"worker1"},
+			Etcd:   []string{"master1", "master2"},
+		},
+		Kubernetes: &v1alpha1.Kubernetes{
+			Version:   version,
+			APIServer: &v1alpha1.APIServerConfig{ExtraArgs: map[string]string{"service-node-port-range": "30000-32767"}},
+			Kubelet:   &v1alpha1.KubeletConfig{ExtraArgs: map[string]string{"max-open-files": "1000000"}},
+		},
+	}}
+	v1alpha1.SetDefaults_Cluster(cluster)
+	return cluster.Spec
+}
+
+// decodeDocuments splits a rendered config file into its YAML documents, keyed by kind.
+func decodeDocuments(t *testing.T, content []byte) map[string]map[string]interface{} {
+	t.Helper()
+	docs := make(map[string]map[string]interface{})
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs
+			}
+			t.Fatalf("rendered config is not valid YAML: %v\n%s", err, content)
+		}
+		if doc == nil {
+			continue
+		}
+		kind, _ := doc["kind"].(string)
+		docs[kind] = doc
+	}
+}
+
+// extraArgs returns the extra args of a component as a map, whichever form the API version uses.
+func extraArgs(t *testing.T, component map[string]interface{}, key string, asList bool) map[string]string {
+	t.Helper()
+	args := make(map[string]string)
+	switch raw := component[key].(type) {
+	case map[string]interface{}:
+		if asList {
+			t.Fatalf("expected %s to be a list of name/value pairs, got a map", key)
+		}
+		for k, v := range raw {
+			args[k], _ = v.(string)
+		}
+	case []interface{}:
+		if !asList {
+			t.Fatalf("expected %s to be a map, got a list", key)
+		}
+		for _, item := range raw {
+			pair, _ := item.(map[string]interface{})
+			name, _ := pair["name"].(string)
+			args[name], _ = pair["value"].(string)
+		}
+	default:
+		t.Fatalf("unexpected %s: %#v", key, component[key])
+	}
+	return args
+}
+
+func TestGenerator_InitConfigFile(t *testing.T) {
+	tests := []struct {
+		version    string
+		apiVersion string
+	}{
+		{version: "v1.24.17", apiVersion: common.KubeadmAPIVersionV1Beta3},
+		{version: "v1.30.4", apiVersion: common.KubeadmAPIVersionV1Beta3},
+		{version: "v1.31.0", apiVersion: common.KubeadmAPIVersionV1Beta4},
+		{version: "v1.33.1", apiVersion: common.KubeadmAPIVersionV1Beta4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			g, err := NewGenerator(newTestSpec(tt.version), Options{ImageRepository: "registry.k8s.io", CoreDNSImageTag: "v1.11.1"})
+			if err != nil {
+				t.Fatalf("failed to create generator: %v", err)
+			}
+			if g.APIVersion() != tt.apiVersion {
+				t.Errorf("expected API version %s, got %s", tt.apiVersion, g.APIVersion())
+			}
+			content, err := g.InitConfigFile("master1")
+			if err != nil {
+				t.Fatalf("failed to render init config: %v", err)
+			}
+			if !strings.HasPrefix(string(content), fileHeader+"---\n") {
+				t.Errorf("expected the file to start with the kubexm header, got:\n%s", content)
+			}
+
+			docs := decodeDocuments(t, content)
+			for kind, apiVersion := range map[string]string{
+				"ClusterConfiguration":   tt.apiVersion,
+				"InitConfiguration":      tt.apiVersion,
+				"KubeProxyConfiguration": common.KubeProxyConfigAPIVersion,
+				"KubeletConfiguration":   common.KubeletConfigAPIVersion,
+			} {
+				doc, ok := docs[kind]
+				if !ok {
+					t.Fatalf("expected a %s document, got:\n%s", kind, content)
+				}
+				if doc["apiVersion"] != apiVersion {
+					t.Errorf("expected %s apiVersion %s, got %v", kind, apiVersion, doc["apiVersion"])
+				}
+			}
+
+			asList := tt.apiVersion == common.KubeadmAPIVersionV1Beta4
+			clusterCfg := docs["ClusterConfiguration"]
+			if clusterCfg["kubernetesVersion"] != tt.version {
+				t.Errorf("expected kubernetesVersion %s, got %v", tt.version, clusterCfg["kubernetesVersion"])
+			}
+			if dns, _ := clusterCfg["dns"].(map[string]interface{}); dns["type"] != nil {
+				t.Errorf("expected no dns.type, it is not part of %s", tt.apiVersion)
+			}
+			apiServer := clusterCfg["apiServer"].(map[string]interface{})
+			if args := extraArgs(t, apiServer, "extraArgs", asList); args["service-node-port-range"] != "30000-32767" || args["bind-address"] != "0.0.0.0" {
+				t.Errorf("unexpected apiserver extraArgs: %v", args)
+			}
+			for _, component := range []string{"controllerManager", "scheduler"} {
+				args := extraArgs(t, clusterCfg[component].(map[string]interface{}), "extraArgs", asList)
+				if args["feature-gates"] == "" {
+					t.Errorf("expected %s feature-gates, got %v", component, args)
+				}
+			}
+			etcd := clusterCfg["etcd"].(map[string]interface{})["external"].(map[string]interface{})
+			if endpoints := etcd["endpoints"].([]interface{}); len(endpoints) != 2 || endpoints[1] != "https://192.168.1.11:2379" {
+				t.Errorf("unexpected etcd endpoints: %v", endpoints)
+			}
+			if want := filepath.Join(common.DefaultEtcdPKIDir, fmt.Sprintf(common.EtcdNodeCertFileNamePattern, "master1")); etcd["certFile"] != want {
+				t.Errorf("expected etcd certFile %s, got %v", want, etcd["certFile"])
+			}
+
+			initCfg := docs["InitConfiguration"]
+			if endpoint := initCfg["localAPIEndpoint"].(map[string]interface{}); endpoint["advertiseAddress"] != "192.168.1.10" {
+				t.Errorf("unexpected advertiseAddress: %v", endpoint["advertiseAddress"])
+			}
+			registration := initCfg["nodeRegistration"].(map[string]interface{})
+			if args := extraArgs(t, registration, "kubeletExtraArgs", asList); args["max-open-files"] != "1000000" || args["cgroup-driver"] != common.CgroupDriverSystemd {
+				t.Errorf("unexpected kubeletExtraArgs: %v", args)
+			}
+		})
+	}
+}
+
+func TestGenerator_ExternalEtcd(t *testing.T) {
+	spec := newTestSpec("v1.31.0")
+	spec.RoleGroups.Etcd = nil
+	spec.Etcd.Type = string(common.EtcdDeploymentTypeExternal)
+	spec.Etcd.External = &v1alpha1.ExternalEtcdConfig{
+		Endpoints: []string{"https://10.0.0.1:2379", "https://10.0.0.2:2379"},
+		CAFile:    "/opt/etcd/ca.pem",
+		CertFile:  "/opt/etcd/client.pem",
+		KeyFile:   "/opt/etcd/client-key.pem",
+	}
+	g, err := NewGenerator(spec, Options{})
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	content, err := g.InitConfigFile("master1")
+	if err != nil {
+		t.Fatalf("failed to render init config: %v", err)
+	}
+	clusterCfg := decodeDocuments(t, content)["ClusterConfiguration"]
+	etcd := clusterCfg["etcd"].(map[string]interface{})["external"].(map[string]interface{})
+	if endpoints := etcd["endpoints"].([]interface{}); len(endpoints) != 2 || endpoints[0] != "https://10.0.0.1:2379" {
+		t.Errorf("unexpected etcd endpoints: %v", endpoints)
+	}
+	for key, name := range map[string]string{
+		"caFile":   common.ExternalEtcdCaFileName,
+		"certFile": common.ExternalEtcdClientCertFileName,
+		"keyFile":  common.ExternalEtcdClientKeyFileName,
+	} {
+		if want := filepath.Join(common.DefaultExternalEtcdPKIDir, name); etcd[key] != want {
+			t.Errorf("expected etcd %s %s, got %v", key, want, etcd[key])
+		}
+	}
+	if volumes := clusterCfg["apiServer"].(map[string]interface{})["extraVolumes"]; volumes != nil {
+		t.Errorf("expected no extra apiserver volumes for external etcd, got %v", volumes)
+	}
+
+	spec.Etcd.External = &v1alpha1.ExternalEtcdConfig{Endpoints: []string{"http://10.0.0.1:2379"}}
+	g, err = NewGenerator(spec, Options{})
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	content, err = g.InitConfigFile("master1")
+	if err != nil {
+		t.Fatalf("failed to render init config: %v", err)
+	}
+	etcd = decodeDocuments(t, content)["ClusterConfiguration"]["etcd"].(map[string]interface{})["external"].(map[string]interface{})
+	if _, ok := etcd["caFile"]; ok {
+		t.Errorf("expected no caFile for a plain http etcd, got:\n%s", content)
+	}
+}
+
+func TestGenerator_KubeProxy(t *testing.T) {
+	spec := newTestSpec("v1.31.0")
+	spec.Kubernetes.KubeProxy.IPVS = &v1alpha1.KubeProxyIPVSConfig{Scheduler: "lc", ExcludeCIDRs: []string{"10.10.0.0/16"}}
+	g, err := NewGenerator(spec, Options{})
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	content, err := g.InitConfigFile("master1")
+	if err != nil {
+		t.Fatalf("failed to render init config: %v", err)
+	}
+	docs := decodeDocuments(t, content)
+	proxyCfg := docs["KubeProxyConfiguration"]
+	if proxyCfg["mode"] != common.KubeProxyModeIPVS {
+		t.Errorf("expected mode %s, got %v", common.KubeProxyModeIPVS, proxyCfg["mode"])
+	}
+	ipvs, _ := proxyCfg["ipvs"].(map[string]interface{})
+	if cidrs, _ := ipvs["excludeCIDRs"].([]interface{}); ipvs["scheduler"] != "lc" || len(cidrs) != 1 || cidrs[0] != "10.10.0.0/16" {
+		t.Errorf("unexpected ipvs configuration: %v", ipvs)
+	}
+	if _, ok := docs["InitConfiguration"]["skipPhases"]; ok {
+		t.Errorf("expected no skipPhases while kube-proxy is deployed, got:\n%s", content)
+	}
+
+	spec.Kubernetes.KubeProxy = &v1alpha1.KubeProxyConfig{Mode: common.KubeProxyModeNone}
+	content, err = g.InitConfigFile("master1")
+	if err != nil {
+		t.Fatalf("failed to render init config: %v", err)
+	}
+	docs = decodeDocuments(t, content)
+	if _, ok := docs["KubeProxyConfiguration"]; ok {
+		t.Errorf("expected no KubeProxyConfiguration in mode none, got:\n%s", content)
+	}
+	if phases, _ := docs["InitConfiguration"]["skipPhases"].([]interface{}); len(phases) != 1 || phases[0] != common.KubeadmPhaseAddonKubeProxy {
+		t.Errorf("expected kubeadm to skip %s, got %v", common.KubeadmPhaseAddonKubeProxy, phases)
+	}
+}
+
+func TestGenerator_JoinConfigFile(t *testing.T) {
+	for _, version := range []string{"v1.28.2", "v1.32.0"} {
+		t.Run(version, func(t *testing.T) {
+			g, err := NewGenerator(newTestSpec(version), Options{})
+			if err != nil {
+				t.Fatalf("failed to create generator: %v", err)
+			}
+			asList := g.APIVersion() == common.KubeadmAPIVersionV1Beta4
+
+			content, err := g.JoinConfigFile("worker1", JoinOptions{Token: "abcdef.0123456789abcdef"})
+			if err != nil {
+				t.Fatalf("failed to render worker join config: %v", err)
+			}
+			worker := decodeDocuments(t, content)["JoinConfiguration"]
+			if worker == nil || worker["apiVersion"] != g.APIVersion() {
+				t.Fatalf("expected a %s JoinConfiguration, got:\n%s", g.APIVersion(), content)
+			}
+			if worker["controlPlane"] != nil {
+				t.Errorf("expected no controlPlane section for a worker, got:\n%s", content)
+			}
+			bootstrap := worker["discovery"].(map[string]interface{})["bootstrapToken"].(map[string]interface{})
+			if bootstrap["token"] != "abcdef.0123456789abcdef" {
+				t.Errorf("unexpected bootstrap token: %v", bootstrap["token"])
+			}
+			extraArgs(t, worker["nodeRegistration"].(map[string]interface{}), "kubeletExtraArgs", asList)
+
+			content, err = g.JoinConfigFile("master2", JoinOptions{Token: "abcdef.0123456789abcdef", CertificateKey: "0123abcd", ControlPlane: true})
+			if err != nil {
+				t.Fatalf("failed to render control plane join config: %v", err)
+			}
+			controlPlane, _ := decodeDocuments(t, content)["JoinConfiguration"]["controlPlane"].(map[string]interface{})
+			if controlPlane["certificateKey"] != "0123abcd" {
+				t.Errorf("expected the certificate key in controlPlane, got:\n%s", content)
+			}
+			if endpoint := controlPlane["localAPIEndpoint"].(map[string]interface{}); endpoint["advertiseAddress"] != "192.168.1.11" {
+				t.Errorf("unexpected advertiseAddress: %v", endpoint["advertiseAddress"])
+			}
+		})
+	}
+}
+
+func TestGenerator_JoinConfigFile_Windows(t *testing.T) {
+	spec := newTestSpec("v1.30.4")
+	spec.Hosts = append(spec.Hosts, v1alpha1.HostSpec{Name: "win1", Address: "192.168.1.30", OS: common.OSWindows})
+	spec.RoleGroups.Worker = append(spec.RoleGroups.Worker, "win1")
+	g, err := NewGenerator(spec, Options{})
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	content, err := g.JoinConfigFile("win1", JoinOptions{Token: "abcdef.0123456789abcdef"})
+	if err != nil {
+		t.Fatalf("failed to render windows join config: %v", err)
+	}
+	registration := decodeDocuments(t, content)["JoinConfiguration"]["nodeRegistration"].(map[string]interface{})
+	if registration["criSocket"] != common.ContainerdWindowsEndpoint {
+		t.Errorf("expected the containerd named pipe, got %v", registration["criSocket"])
+	}
+	args := extraArgs(t, registration, "kubeletExtraArgs", false)
+	if _, ok := args["cgroup-driver"]; ok {
+		t.Errorf("expected no cgroup-driver for a windows node, got %v", args)
+	}
+	if args["max-open-files"] != "1000000" {
+		t.Errorf("expected the configured kubelet args to be kept, got %v", args)
+	}
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	if _, err := NewGenerator(newTestSpec("v1.20.15"), Options{}); err == nil {
+		t.Error("expected an error for a Kubernetes version without v1beta3")
+	}
+
+	g, err := NewGenerator(newTestSpec("v1.31.2"), Options{})
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	if _, err := g.InitConfigFile("unknown"); err == nil {
+		t.Error("expected an error for a host that is not in the spec")
+	}
+	if _, err := g.JoinConfigFile("worker1", JoinOptions{}); err == nil {
+		t.Error("expected an error for a join without a token")
+	}
+	if _, err := g.JoinConfigFile("master2", JoinOptions{Token: "abcdef.0123456789abcdef", ControlPlane: true}); err == nil {
+		t.Error("expected an error for a control plane join without a certificate key")
+	}
+}
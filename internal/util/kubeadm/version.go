@@ -0,0 +1,30 @@
+package kubeadm
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mensylisir/kubexm/internal/common"
+)
+
+// APIVersionFor returns the kubeadm config API version to render for kubernetesVersion: v1beta4 from
+// Kubernetes 1.31 on and v1beta3 before that. Versions older than 1.22 have no v1beta3 and are rejected.
+func APIVersionFor(kubernetesVersion string) (string, error) {
+	v, err := semver.NewVersion(kubernetesVersion)
+	if err != nil {
+		return "", fmt.Errorf("invalid kubernetes version %q: %w", kubernetesVersion, err)
+	}
+	// Pre-releases such as 1.31.0-rc.1 ship the kubeadm of their minor, so compare without them.
+	core, err := v.SetPrerelease("")
+	if err != nil {
+		return "", fmt.Errorf("invalid kubernetes version %q: %w", kubernetesVersion, err)
+	}
+
+	switch {
+	case !core.LessThan(semver.MustParse(common.KubeadmV1Beta4MinK8sVersion)):
+		return common.KubeadmAPIVersionV1Beta4, nil
+	case !core.LessThan(semver.MustParse(common.KubeadmV1Beta3MinK8sVersion)):
+		return common.KubeadmAPIVersionV1Beta3, nil
+	}
+	return "", fmt.Errorf("kubernetes version %s is not supported, kubeadm config needs at least v%s", kubernetesVersion, common.KubeadmV1Beta3MinK8sVersion)
+}
@@ -4,13 +4,13 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"github.com/mensylisir/kubexm/internal/connector"
 )
 
+// ShellEscape quotes s as a single POSIX shell word, see connector.ShellEscape.
 func ShellEscape(s string) string {
-	if s == "" {
-		return "''"
-	}
-	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+	return connector.ShellEscape(s)
 }
 
 func ContainsString(slice []string, s string) bool {